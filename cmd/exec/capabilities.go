@@ -0,0 +1,27 @@
+package main
+
+import (
+	"get.porter.sh/porter/pkg/exec"
+	"github.com/spf13/cobra"
+)
+
+func buildCapabilitiesCommand(m *exec.Mixin) *cobra.Command {
+	opts := exec.CapabilitiesOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "capabilities",
+		Short: "Print the mixin protocol versions and commands supported by the mixin",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return m.PrintCapabilities(opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.RawFormat, "output", "o", "json",
+		"Specify an output format.  Allowed values: json, yaml")
+
+	return cmd
+}
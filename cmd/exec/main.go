@@ -68,6 +68,7 @@ func buildRootCommand(m *exec.Mixin, in io.Reader) *cobra.Command {
 
 	cmd.AddCommand(buildVersionCommand(m))
 	cmd.AddCommand(buildSchemaCommand(m))
+	cmd.AddCommand(buildCapabilitiesCommand(m))
 	cmd.AddCommand(buildBuildCommand(m))
 	cmd.AddCommand(buildLintCommand(m))
 	cmd.AddCommand(buildInstallCommand(m))
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"get.porter.sh/porter/pkg/porter"
+	"github.com/spf13/cobra"
+)
+
+func buildAgentCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.AgentOptions{}
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Run scheduled actions",
+		Long: `Run the actions scheduled with porter installation runs schedule create.
+
+The agent checks for schedules that are due each interval, and runs the scheduled action on the installation, which creates a run the same as porter installation invoke. A schedule that was due more than once since it was last checked, for example because the agent was not running, is only run once.
+The outcome of each run is recorded on the schedule, and a failed run does not stop the agent.
+
+Use --once to run the schedules that are due and exit, for example from an external scheduler. Only run one agent against a storage account, so that each schedule is run once.`,
+		Example: `  porter agent
+  porter agent --interval 5m --namespace dev
+  porter agent --once
+`,
+		Annotations: map[string]string{
+			"group": "meta",
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.RunAgent(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.DurationVar(&opts.Interval, "interval", porter.DefaultAgentInterval,
+		"How often to check for schedules that are due.")
+	f.BoolVar(&opts.Once, "once", false,
+		"Run the schedules that are due and then exit.")
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Only run the schedules in the specified namespace. Defaults to all namespaces.")
+
+	return cmd
+}
@@ -161,5 +161,10 @@ func buildLogsAlias(p *porter.Porter) *cobra.Command {
 	cmd.Annotations = map[string]string{
 		"group": "alias",
 	}
+
+	search := buildInstallationLogSearchCommand(p)
+	search.Example = strings.Replace(search.Example, "porter installation logs search", "porter logs search", -1)
+	cmd.AddCommand(search)
+
 	return cmd
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"get.porter.sh/porter/pkg/porter"
+	"github.com/spf13/cobra"
+)
+
+func buildAPICommands(p *porter.Porter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "api",
+		Short: "Serve the Porter API",
+		Long:  "Serve the Porter API, so that bundles can be run remotely.",
+		Annotations: map[string]string{
+			"group": "meta",
+		},
+	}
+
+	cmd.AddCommand(buildAPIServeCommand(p))
+
+	return cmd
+}
+
+func buildAPIServeCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.APIServeOptions{}
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run bundles for remote porter commands",
+		Long: `Serve the Porter API, which runs the bundles submitted by the porter install, upgrade, invoke and uninstall commands with the --remote flag.
+
+The bundles are run with the configuration, storage, secrets, drivers, parameter sets and credential sets of this server, so that the client does not need access to them.
+The events of each run, such as the logs of the bundle and the result, are streamed back to the client. Bundles are run one at a time. Request bodies larger than 1MiB are rejected.
+
+Clients must authenticate with the token specified with --token, by using the --remote-token flag. The token can also be set with the PORTER_API_TOKEN environment variable or the api-token config file setting, which keeps it out of the process list. Storage requests for clients are authorized as the api-subject of the authorization configuration, which defaults to porter-api. Use --tls-cert and --tls-key to serve https, which is recommended when the server is not only accessible from the local machine.
+
+The server also receives the push webhooks of Harbor, Azure Container Registry and GitHub Container Registry at /v1/webhooks/registry. When a tag is pushed that is a newer version of the bundle of an installation, the auto-upgrade-rules in the porter configuration file that match the installation upgrade it, and the webhook event is recorded on the run. Registries authenticate with the token as a bearer token, or GitHub webhooks may use it as the webhook secret.`,
+		Example: `  PORTER_API_TOKEN="$(cat token.txt)" porter api serve
+  porter api serve --listen :8443 --tls-cert server.crt --tls-key server.key`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(p)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.ServeAPI(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.Listen, "listen", porter.DefaultAPIListenAddress,
+		"Address that the server listens on.")
+	f.StringVar(&opts.Token, "token", "",
+		"Token that clients must present with --remote-token. Prefer PORTER_API_TOKEN or the api-token config file setting, which are not visible in the process list. Required.")
+	f.StringVar(&opts.TLSCert, "tls-cert", "",
+		"Path to the certificate used to serve https.")
+	f.StringVar(&opts.TLSKey, "tls-key", "",
+		"Path to the private key of the certificate specified with --tls-cert.")
+
+	// Allow configuring the --token flag with api-token, so that the token is
+	// not visible in the process list and does not conflict with other settings
+	cmd.Flag("token").Annotations = map[string][]string{
+		"viper-key": {"api-token"},
+	}
+
+	return cmd
+}
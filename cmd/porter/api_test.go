@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"get.porter.sh/porter/pkg/porter"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIServeValidate_Token(t *testing.T) {
+	// Do not run in parallel
+
+	testcases := []struct {
+		name      string
+		args      []string
+		envToken  string // the token set in the environment
+		wantError string
+	}{
+		{name: "no token", wantError: "--token is required"},
+		{name: "flag", args: []string{"--token=abc123"}},
+		{name: "environment", envToken: "abc123"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.envToken != "" {
+				os.Setenv("PORTER_API_TOKEN", tc.envToken)
+				defer os.Unsetenv("PORTER_API_TOKEN")
+			}
+
+			p := porter.NewTestPorter(t)
+			defer p.Close()
+
+			rootCmd := buildRootCommandFrom(p.Porter)
+
+			fullArgs := append([]string{"api", "serve"}, tc.args...)
+			rootCmd.SetArgs(fullArgs)
+			serveCmd, _, _ := rootCmd.Find(fullArgs)
+			serveCmd.RunE = func(cmd *cobra.Command, args []string) error {
+				// noop
+				return nil
+			}
+
+			err := rootCmd.Execute()
+			if tc.wantError == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.wantError)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"get.porter.sh/porter/pkg/pkgmgmt"
 	"get.porter.sh/porter/pkg/porter"
 	"github.com/spf13/cobra"
 )
@@ -49,6 +50,8 @@ func buildBundleBuildCommand(p *porter.Porter) *cobra.Command {
 		Short: "Build a bundle",
 		Long: `Builds the bundle in the current directory by generating a Dockerfile and a CNAB bundle.json, and then building the invocation image.
 
+The version and digest of the porter-runtime copied into the invocation image are recorded in the bundle metadata. Use --verify-runtime to verify the runtime against the checksums published for the current version of Porter, the build fails when the runtime does not match the published checksum.
+
 The docker driver builds the bundle image using the local Docker host. To use a remote Docker host, set the following environment variables:
   DOCKER_HOST (required)
   DOCKER_TLS_VERIFY (optional)
@@ -61,6 +64,7 @@ The docker driver builds the bundle image using the local Docker host. To use a
   porter build --file path/to/porter.yaml
   porter build --dir path/to/build/context
   porter build --custom version=0.2.0 --custom myapp.version=0.1.2
+  porter build --env prod
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return opts.Validate(p)
@@ -74,6 +78,8 @@ The docker driver builds the bundle image using the local Docker host. To use a
 	f.BoolVar(&opts.NoLint, "no-lint", false, "Do not run the linter")
 	f.StringVar(&opts.Name, "name", "", "Override the bundle name")
 	f.StringVar(&opts.Version, "version", "", "Override the bundle version")
+	f.StringVar(&opts.Environment, "env", "",
+		"Name of the environment from the environments section of the manifest to apply to the bundle. Defaults to the environment specified in the manifest.")
 	f.StringVarP(&opts.File, "file", "f", "",
 		"Path to the Porter manifest. The path is relative to the build context directory. Defaults to porter.yaml in the current directory.")
 	f.StringVarP(&opts.Dir, "dir", "d", "",
@@ -91,6 +97,10 @@ The docker driver builds the bundle image using the local Docker host. To use a
 		"Do not use the Docker cache when building the bundle's invocation image.")
 	f.StringArrayVar(&opts.Customs, "custom", nil,
 		"Define an individual key-value pair for the custom section in the form of NAME=VALUE. Use dot notation to specify a nested custom field. May be specified multiple times.")
+	f.BoolVar(&opts.VerifyRuntime, "verify-runtime", false,
+		"Verify the porter-runtime against the checksums published for the current version of Porter.")
+	f.StringVar(&opts.Mirror, "mirror", pkgmgmt.DefaultPackageMirror,
+		"Mirror of official Porter assets, used to retrieve the published checksums of the porter-runtime when --verify-runtime is specified")
 
 	// Allow configuring the --driver flag with build-driver, to avoid conflicts with other commands
 	cmd.Flag("driver").Annotations = map[string][]string{
@@ -142,6 +152,7 @@ Note: if overrides for registry/tag/reference are provided, this command only re
   porter bundle publish --file myapp/porter.yaml
   porter bundle publish --dir myapp
   porter bundle publish --archive /tmp/mybuns.tgz --reference myrepo/my-buns:0.1.0
+  porter bundle publish --archive /tmp/mybuns.tgz --reference myrepo/my-buns:0.1.0 --decrypt-key-ref secret://archive-key
   porter bundle publish --tag latest
   porter bundle publish --registry myregistry.com/myorg
 		`,
@@ -158,6 +169,7 @@ Note: if overrides for registry/tag/reference are provided, this command only re
 	f.StringVarP(&opts.Dir, "dir", "d", "",
 		"Path to the build context directory where all bundle assets are located.")
 	f.StringVarP(&opts.ArchiveFile, "archive", "a", "", "Path to the bundle archive in .tgz format")
+	f.StringVar(&opts.DecryptKeyRef, "decrypt-key-ref", "", "Reference to the key in the secret store used to decrypt an encrypted archive, for example secret://archive-key. Defaults to the key used to encrypt the archive.")
 	f.StringVar(&opts.Tag, "tag", "", "Override the Docker tag portion of the bundle reference, e.g. latest, v0.1.1")
 	f.StringVar(&opts.Registry, "registry", "", "Override the registry portion of the bundle reference, e.g. docker.io, myregistry.com/myorg")
 	addReferenceFlag(f, &opts.BundlePullOptions)
@@ -177,9 +189,12 @@ func buildBundleArchiveCommand(p *porter.Porter) *cobra.Command {
 	cmd := cobra.Command{
 		Use:   "archive FILENAME --reference PUBLISHED_BUNDLE",
 		Short: "Archive a bundle from a reference",
-		Long:  "Archives a bundle by generating a gzipped tar archive containing the bundle, invocation image and any referenced images.",
+		Long: `Archives a bundle by generating a gzipped tar archive containing the bundle, invocation image and any referenced images.
+
+Use --encrypt-key-ref to encrypt the archive with AES-256-GCM, using a base64 encoded 256-bit key from the secret store. Encrypted archives are decrypted by porter bundle publish --archive and porter bundle explain --archive.`,
 		Example: `  porter bundle archive mybun.tgz --reference ghcr.io/getporter/examples/porter-hello:v0.2.0
   porter bundle archive mybun.tgz --reference localhost:5000/ghcr.io/getporter/examples/porter-hello:v0.2.0 --force
+  porter bundle archive mybun.tgz --reference ghcr.io/getporter/examples/porter-hello:v0.2.0 --encrypt-key-ref secret://archive-key
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return opts.Validate(cmd.Context(), args, p)
@@ -189,7 +204,9 @@ func buildBundleArchiveCommand(p *porter.Porter) *cobra.Command {
 		},
 	}
 
-	addBundlePullFlags(cmd.Flags(), &opts.BundlePullOptions)
+	f := cmd.Flags()
+	addBundlePullFlags(f, &opts.BundlePullOptions)
+	f.StringVar(&opts.EncryptKeyRef, "encrypt-key-ref", "", "Reference to the key in the secret store used to encrypt the archive, for example secret://archive-key. The archive is not encrypted by default.")
 
 	return &cmd
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"get.porter.sh/porter/pkg/porter"
+	"github.com/spf13/cobra"
+)
+
+func buildCacheCommands(p *porter.Porter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Cache commands",
+		Long:  "Commands for managing the bundles cached by Porter.",
+		Annotations: map[string]string{
+			"group": "meta",
+		},
+	}
+
+	cmd.AddCommand(buildCacheGCCommand(p))
+
+	return cmd
+}
+
+func buildCacheGCCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.CacheGCOptions{}
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove cached bundles that are not used by an installation",
+		Long: `Remove cached bundles that are not referenced by any installation, in any namespace, and report the space that was reclaimed.
+
+A cached bundle is referenced when it matches the bundle reference or digest of an installation. Bundles that are removed are pulled again the next time that they are used.
+
+Use --images to also remove the invocation images of the removed bundles from the local container engine, unless the image is used by the last run of an installation or by a cached bundle that is kept.
+Use --dry-run to list what would be removed without removing it.`,
+		Example: `  porter cache gc --dry-run
+  porter cache gc --images
+  porter cache gc --images --output json`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintCacheGC(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&opts.DryRun, "dry-run", false,
+		"List the cached bundles and images that would be removed, without removing them.")
+	f.BoolVar(&opts.Images, "images", false,
+		"Also remove the invocation images of the removed bundles from the local container engine.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return cmd
+}
@@ -34,11 +34,14 @@ func buildCredentialsApplyCommand(p *porter.Porter) *cobra.Command {
 
 Supported file extensions: json and yaml.
 
+A yaml file may define multiple credential sets as separate documents, separated by ---. Documents with a schemaType other than CredentialSet are skipped, so the same file can define all the resources for an environment. Every document is validated before any changes are applied.
+
 You can use the generate and show commands to create the initial file:
   porter credentials generate mycreds --reference SOME_BUNDLE
   porter credentials show mycreds --output yaml > mycreds.yaml
 `,
-		Example: `  porter credentials apply mycreds.yaml`,
+		Example: `  porter credentials apply mycreds.yaml
+  porter credentials apply environment.yaml`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return opts.Validate(p.Context, args)
 		},
@@ -161,7 +164,7 @@ The results may also be filtered by associated labels and the namespace in which
 	f.StringVar(&opts.Name, "name", "",
 		"Filter the credential sets where the name contains the specified substring.")
 	f.StringSliceVarP(&opts.Labels, "label", "l", nil,
-		"Filter the credential sets by a label formatted as: KEY=VALUE. May be specified multiple times.")
+		"Filter the credential sets by a label selector formatted as KEY=VALUE, KEY!=VALUE, KEY (the label is set) or !KEY (the label is not set). May be specified multiple times.")
 	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
 		"Specify an output format.  Allowed values: plaintext, json, yaml")
 	f.Int64Var(&opts.Skip, "skip", 0,
@@ -17,6 +17,8 @@ func buildBundleExplainCommand(p *porter.Porter) *cobra.Command {
   porter bundle explain localhost:5000/ghcr.io/getporter/examples/porter-hello:v0.2.0 --insecure-registry --force
   porter bundle explain --file another/porter.yaml
   porter bundle explain --cnab-file some/bundle.json
+  porter bundle explain --archive mybuns.tgz
+  porter bundle explain --archive mybuns.tgz --decrypt-key-ref secret://archive-key
   porter bundle explain --action install
 		  `,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
@@ -32,6 +34,8 @@ func buildBundleExplainCommand(p *porter.Porter) *cobra.Command {
 	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
 		"Specify an output format.  Allowed values: plaintext, json, yaml")
 	f.StringVar(&opts.Action, "action", "", "Hide parameters and outputs that are not used by the specified action.")
+	f.StringVarP(&opts.ArchiveFile, "archive", "a", "", "Path to the bundle archive in .tgz format")
+	f.StringVar(&opts.DecryptKeyRef, "decrypt-key-ref", "", "Reference to the key in the secret store used to decrypt an encrypted archive, for example secret://archive-key. Defaults to the key used to encrypt the archive.")
 	addBundlePullFlags(f, &opts.BundlePullOptions)
 
 	return &cmd
@@ -2,6 +2,7 @@ package main
 
 import (
 	"get.porter.sh/porter/pkg/porter"
+	"get.porter.sh/porter/pkg/storage"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -24,10 +25,15 @@ func buildInstallationCommands(p *porter.Porter) *cobra.Command {
 	cmd.AddCommand(buildInstallationDeleteCommand(p))
 	cmd.AddCommand(buildInstallationLogCommands(p))
 	cmd.AddCommand(buildInstallationRunsCommands(p))
+	cmd.AddCommand(buildInstallationHistoryCommands(p))
+	cmd.AddCommand(buildInstallationSnapshotCommand(p))
+	cmd.AddCommand(buildInstallationGraphCommand(p))
 	cmd.AddCommand(buildInstallationInstallCommand(p))
 	cmd.AddCommand(buildInstallationUpgradeCommand(p))
 	cmd.AddCommand(buildInstallationInvokeCommand(p))
 	cmd.AddCommand(buildInstallationUninstallCommand(p))
+	cmd.AddCommand(buildInstallationImportCommand(p))
+	cmd.AddCommand(buildInstallationExportCommand(p))
 
 	return cmd
 }
@@ -44,13 +50,17 @@ A listing of bundles currently installed by Porter will be provided, along with
 Optionally filters the results name, which returns all results whose name contain the provided query.
 The results may also be filtered by associated labels and the namespace in which the installation is defined. 
 
+Use --group-by bundle to plan upgrades across a fleet of installations. The installations are counted by bundle and by the version of the bundle that was last run, along with when the installations were last modified.
+
 Optional output formats include json and yaml.`,
 		Example: `  porter installations list
   porter installations list -o json
   porter installations list --all-namespaces,
   porter installations list --label owner=myname --namespace dev
+  porter installations list --label team=red --label env!=prod
   porter installations list --name myapp
-  porter installations list --skip 2 --limit 2`,
+  porter installations list --skip 2 --limit 2
+  porter installations list --all-namespaces --group-by bundle`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return opts.Validate()
 		},
@@ -67,13 +77,15 @@ Optional output formats include json and yaml.`,
 	f.StringVar(&opts.Name, "name", "",
 		"Filter the installations where the name contains the specified substring.")
 	f.StringSliceVarP(&opts.Labels, "label", "l", nil,
-		"Filter the installations by a label formatted as: KEY=VALUE. May be specified multiple times.")
+		"Filter the installations by a label selector formatted as KEY=VALUE, KEY!=VALUE, KEY (the label is set) or !KEY (the label is not set). May be specified multiple times.")
 	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
 		"Specify an output format.  Allowed values: plaintext, json, yaml")
 	f.Int64Var(&opts.Skip, "skip", 0,
 		"Skip the number of installations by a certain amount. Defaults to 0.")
 	f.Int64Var(&opts.Limit, "limit", 0,
 		"Limit the number of installations by a certain amount. Defaults to 0.")
+	f.StringVar(&opts.GroupBy, "group-by", "",
+		"Count the installations of each bundle, and of each installed version of the bundle, instead of listing the installations. Skip and limit apply to the bundles. Allowed values: bundle")
 
 	return cmd
 }
@@ -118,12 +130,15 @@ The installation's bundle is automatically executed if changes are detected.
 
 When the namespace is not set in the file, the current namespace is used.
 
+A yaml file may define multiple installations as separate documents, separated by ---. Documents with a schemaType other than Installation are skipped, so the same file can define all the resources for an environment. Every document is validated before any installations are applied, and the installations are applied in the order they are defined.
+
 You can use the show command to create the initial file:
   porter installation show mybuns --output yaml > mybuns.yaml
 `,
 		Example: `  porter installation apply myapp.yaml
   porter installation apply myapp.yaml --dry-run
-  porter installation apply myapp.yaml --force`,
+  porter installation apply myapp.yaml --force
+  porter installation apply myapp.yaml --output ndjson`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return opts.Validate(p.Context, args)
 		},
@@ -139,6 +154,84 @@ You can use the show command to create the initial file:
 		"Force the bundle to be executed when no changes are detected.")
 	f.BoolVar(&opts.DryRun, "dry-run", false,
 		"Evaluate if the bundle would be executed based on the changes in the file.")
+	f.StringVarP(&opts.RawFormat, "output", "o", string(porter.ApplyDefaultFormat),
+		"Specify an output format. Use ndjson to print the events of each bundle run as newline delimited json as they happen. Allowed values: plaintext, ndjson")
+	return &cmd
+}
+
+func buildInstallationImportCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.InstallationImportOptions{}
+
+	cmd := cobra.Command{
+		Use:   "import SOURCE FILE",
+		Short: "Import a deployment managed by another tool as an installation",
+		Long: `Import a deployment that is managed by another tool, such as a helm release or terraform state, as an installation.
+
+The installation is recorded with a successful install run, and a bundle that wraps the existing tool is scaffolded so that the deployment can be managed by Porter going forward. Review the scaffolded porter.yaml, then build and publish the bundle and set the installation's bundle reference with porter installation apply.
+
+Installations exported with porter installation export are restored from the archive, along with their runs, results and outputs. Use this to move installations between storage backends.
+
+Allowed sources:
+  archive     An archive created by porter installation export. The installation keeps the namespace and name from the archive.
+  helm        The output of helm status RELEASE --output json. The installation is named after the release by default.
+  terraform   A terraform state file (version 4). The outputs of the state are saved as outputs of the installation. --name is required.`,
+		Example: `  helm status mysql --namespace db --output json > mysql.json
+  porter installation import helm mysql.json
+  porter installation import helm mysql.json --name mydb --namespace dev
+  porter installation import terraform terraform.tfstate --name infra --dir ./infra-bundle
+  porter installation import archive mysql.json`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.ImportInstallation(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.Name, "name", "",
+		"Name of the installation. Defaults to the name of the helm release and is required for terraform.")
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace in which the installation is created. Defaults to the global namespace. When importing an archive, the installation keeps its namespace and this must match it if specified.")
+	f.StringVar(&opts.Dir, "dir", "",
+		"Directory where the bundle is scaffolded. Defaults to a directory named after the installation.")
+
+	return &cmd
+}
+
+func buildInstallationExportCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.InstallationExportOptions{}
+
+	cmd := cobra.Command{
+		Use:   "export INSTALLATION",
+		Short: "Export an installation and its history to an archive",
+		Long: `Export an installation, and its runs, results, outputs and step results, to a self-contained archive.
+
+Import the archive with porter installation import archive to move the installation to a different storage backend. Sensitive values are not included in the archive, it references them by their key in the secret store instead. The referenced secrets are listed after the archive is written, and must be available in the secret store used by the Porter that imports the archive.
+
+Use --anonymize to share the history of an installation, for example with a vendor or support, without revealing internal names. The namespaces, installation names, registries, secret keys, and the names of parameters, credentials, outputs, credential sets and parameter sets are replaced with pseudonyms, and the mapping to the original names is written to a separate file. When the mapping file exists, its pseudonyms are reused so that names are replaced consistently across exports. Fields that may identify the installation but can't be replaced, such as labels, notes, change tickets, messages, logs, output values and the CI metadata of runs, are removed. Anonymized archives cannot be imported.`,
+		Example: `  porter installation export mysql
+  porter installation export mysql --namespace dev --file /tmp/mysql.json
+  porter installation export mysql --anonymize
+  porter installation export mysql --anonymize --mapping-file ~/support/mapping.json`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.ExportInstallation(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace in which the installation is defined. Defaults to the global namespace.")
+	f.StringVarP(&opts.File, "file", "f", "",
+		"Path where the archive is written. Defaults to INSTALLATION.json.")
+	f.BoolVar(&opts.Anonymize, "anonymize", false,
+		"Replace the names in the archive with pseudonyms, and remove the fields that may identify the installation.")
+	f.StringVar(&opts.MappingFile, "mapping-file", "",
+		"Path where the mapping from the pseudonyms to the original names is written. Existing pseudonyms in the file are reused. Defaults to FILE-mapping.json.")
+
 	return &cmd
 }
 
@@ -148,7 +241,9 @@ func buildInstallationDeleteCommand(p *porter.Porter) *cobra.Command {
 	cmd := cobra.Command{
 		Use:   "delete [INSTALLATION]",
 		Short: "Delete an installation",
-		Long:  "Deletes all records and outputs associated with an installation",
+		Long: `Deletes all records and outputs associated with an installation.
+
+When the namespace of the installation protects its installations from deletion, the installation is only deleted when --force is specified.`,
 		Example: `  porter installation delete
   porter installation delete wordpress
   porter installation delete --force
@@ -170,6 +265,74 @@ func buildInstallationDeleteCommand(p *porter.Porter) *cobra.Command {
 	return &cmd
 }
 
+func buildInstallationSnapshotCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.SnapshotOptions{}
+
+	cmd := cobra.Command{
+		Use:   "snapshot [INSTALLATION]",
+		Short: "Take a snapshot of the outputs of an installation",
+		Long: `Take a named snapshot of the current outputs of an installation, including the state of the bundle.
+
+The snapshot can be restored when the installation is upgraded or invoked with the --restore-snapshot flag, which passes the outputs from the snapshot to the bundle instead of the current outputs. Snapshots are deleted with the installation, and the runs that they reference are not removed when runs are pruned.`,
+		Example: `  porter installation snapshot
+  porter installation snapshot wordpress --namespace dev
+  porter installation snapshot wordpress --snapshot before-migration
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.SnapshotInstallation(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace in which the installation is defined. Defaults to the global namespace.")
+	f.StringVar(&opts.SnapshotName, "snapshot", "",
+		"Name of the snapshot. Defaults to the current time, for example 20220601-100203.")
+
+	return &cmd
+}
+
+func buildInstallationGraphCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.InstallationGraphOptions{}
+
+	cmd := cobra.Command{
+		Use:   "graph [INSTALLATION]",
+		Short: "Show the dependencies between installations",
+		Long: `Show the graph of the dependencies between installations, formed by the outputs of installations that are used to set the parameters of other installations.
+
+The dependencies are read from the most recent run of each installation, which records the parameters that were set from the outputs of other installations by the parameter sources of the bundle.
+When an installation is specified, only its dependencies and what consumes its outputs are shown, searching every namespace. Use --consumers to only show what consumes the outputs of the installation.
+
+Use --output dot to render the graph with graphviz, where the edges point from the installation that generated an output to the installation that consumes it.`,
+		Example: `  porter installations graph
+  porter installations graph --all-namespaces --output dot | dot -Tsvg > installations.svg
+  porter installations graph network --namespace platform --consumers
+  porter installations graph myapp --output json
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintInstallationGraph(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace of the installations in the graph, and of the specified installation. Defaults to the global namespace.")
+	f.BoolVar(&opts.AllNamespaces, "all-namespaces", false,
+		"Include the installations from all namespaces in the graph.")
+	f.BoolVar(&opts.Consumers, "consumers", false,
+		"Only show what consumes the outputs of the specified installation.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml, dot")
+
+	return &cmd
+}
+
 func buildInstallationRunsCommands(p *porter.Porter) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "runs",
@@ -179,20 +342,201 @@ func buildInstallationRunsCommands(p *porter.Porter) *cobra.Command {
 	}
 
 	cmd.AddCommand(buildInstallationRunsListCommand(p))
+	cmd.AddCommand(buildInstallationRunsShowCommand(p))
+	cmd.AddCommand(buildInstallationRunsInspectCommand(p))
+	cmd.AddCommand(buildInstallationRunsAnnotateCommand(p))
+	cmd.AddCommand(buildInstallationRunsMarkFailedCommand(p))
+	cmd.AddCommand(buildInstallationRunsPruneCommand(p))
+	cmd.AddCommand(buildInstallationRunsDiffCommand(p))
+	cmd.AddCommand(buildInstallationRunsEnvDiffCommand(p))
+	cmd.AddCommand(buildInstallationRunsVerifyCommand(p))
+	cmd.AddCommand(buildInstallationRunsScheduleCommands(p))
 
 	return cmd
 }
 
+func buildInstallationRunsScheduleCommands(p *porter.Porter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Commands for scheduling runs of an Installation",
+		Long: `Commands for scheduling runs of an Installation.
+
+A schedule runs an action on an installation, such as a custom backup action, on a recurring schedule defined by a cron expression. Schedules are run by porter agent, which must be running for the scheduled runs to be created.`,
+	}
+
+	cmd.AddCommand(buildInstallationRunsScheduleCreateCommand(p))
+	cmd.AddCommand(buildInstallationRunsScheduleListCommand(p))
+	cmd.AddCommand(buildInstallationRunsScheduleDeleteCommand(p))
+
+	return cmd
+}
+
+func buildInstallationRunsScheduleCreateCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.ScheduleCreateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "create INSTALLATION --action ACTION --cron EXPRESSION",
+		Short: "Schedule an action on an Installation",
+		Long: `Schedule an action to run on an Installation on a recurring schedule.
+
+The cron expression has five fields: minute, hour, day of month, month and day of week, and is evaluated in UTC. The macros @yearly, @monthly, @weekly, @daily and @hourly are also supported.
+The parameters and the parameter and credential sets of the schedule are used instead of those of the installation, the same as when the action is invoked with porter installation invoke. Parameters are stored unencrypted, use a parameter set for sensitive values.
+
+The schedule is named INSTALLATION-ACTION unless --name is specified.`,
+		Example: `  porter installation runs schedule create mysql --action backup --cron "0 2 * * *"
+  porter installation runs schedule create mysql --action backup --cron @daily --namespace dev --name nightly-backup
+  porter installation runs schedule create mysql --action backup --cron "30 1 * * sun" --param retention=30d --parameter-set backup-storage
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.CreateSchedule(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace in which the installation is defined. Defaults to the global namespace.")
+	f.StringVar(&opts.Name, "name", "",
+		"Name of the schedule. Defaults to INSTALLATION-ACTION.")
+	f.StringVar(&opts.Action, "action", "",
+		"Action to run on the installation.")
+	f.StringVar(&opts.Cron, "cron", "",
+		"Cron expression that determines when the action is run, evaluated in UTC.")
+	f.StringSliceVar(&opts.Params, "param", nil,
+		"Define an individual parameter in the form NAME=VALUE. May be specified multiple times.")
+	f.StringArrayVarP(&opts.ParameterSets, "parameter-set", "p", nil,
+		"Name of a parameter set for the action. May be specified multiple times.")
+	f.StringArrayVarP(&opts.CredentialSets, "credential-set", "c", nil,
+		"Credential set to use for the action. May be specified multiple times.")
+	f.BoolVar(&opts.Disabled, "disabled", false,
+		"Create the schedule without running it.")
+
+	return cmd
+}
+
+func buildInstallationRunsScheduleListCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.ScheduleListOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "list [INSTALLATION]",
+		Aliases: []string{"ls"},
+		Short:   "List the scheduled runs",
+		Long: `List the scheduled runs of Installations.
+
+When an installation is specified, only the schedules of that installation are listed.`,
+		Example: `  porter installation runs schedule list
+  porter installation runs schedule list mysql --namespace dev
+  porter installation runs schedule list --all-namespaces --output json
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintSchedules(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace in which the schedules are defined. Defaults to the global namespace.")
+	f.BoolVar(&opts.AllNamespaces, "all-namespaces", false,
+		"Include all namespaces in the results.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return cmd
+}
+
+func buildInstallationRunsScheduleDeleteCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.ScheduleDeleteOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "delete NAME",
+		Short: "Delete a schedule",
+		Long: `Delete a schedule so that its action is no longer run.
+
+The runs that were created by the schedule are not deleted.`,
+		Example: `  porter installation runs schedule delete mysql-backup
+  porter installation runs schedule delete nightly-backup --namespace dev
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.DeleteSchedule(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace in which the schedule is defined. Defaults to the global namespace.")
+
+	return cmd
+}
+
+func buildInstallationHistoryCommands(p *porter.Porter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Commands for working with the history of an Installation",
+		Long:  "Commands for working with the history of an Installation",
+	}
+
+	cmd.AddCommand(buildInstallationHistoryVerifyCommand(p))
+
+	return cmd
+}
+
+func buildInstallationHistoryVerifyCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.HistoryVerifyOptions{}
+
+	cmd := cobra.Command{
+		Use:   "verify INSTALLATION",
+		Short: "Verify that the run history of an Installation was not tampered with",
+		Long: `Verify the run ledger of an Installation.
+
+When the run ledger is enabled with run-ledger.enabled in the Porter config file, each run and result is recorded in an append-only ledger, and each entry in the ledger is chained to the previous entry with a hash, and optionally signed.
+This command checks that the entries are chained correctly, that their signatures are valid, and that the runs and results of the installation were not modified, removed, or added without being recorded in the ledger.
+
+The command fails when problems are found, or when the installation has no entries in the ledger.`,
+		Example: `  porter installation history verify mysql
+  porter installation history verify mysql --namespace dev --output json
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.VerifyInstallationHistory(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace in which the installation is defined. Defaults to the global namespace.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return &cmd
+}
+
 func buildInstallationRunsListCommand(p *porter.Porter) *cobra.Command {
 	opts := porter.RunListOptions{}
 
 	cmd := cobra.Command{
 		Use:   "list",
 		Short: "List runs of an Installation",
-		Long:  "List runs of an Installation",
+		Long: `List runs of an Installation.
+
+The runs may be filtered by action, by the status of the most recent result of the run, by when the run was created, and by label. Use --limit and --skip to page through the runs, starting from the most recent run.`,
 		Example: `  porter installation runs list [NAME] [--namespace NAMESPACE] [--output FORMAT]
 
   porter installations runs list --name myapp --namespace dev
+  porter installations runs list myapp --label ticket=OPS-1234
+  porter installations runs list myapp --action upgrade --status failed
+  porter installations runs list myapp --since 7d
+  porter installations runs list myapp --since 2022-01-01 --until 2022-02-01
+  porter installations runs list myapp --limit 10 --skip 10
 
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
@@ -206,6 +550,267 @@ func buildInstallationRunsListCommand(p *porter.Porter) *cobra.Command {
 	f := cmd.Flags()
 	f.StringVarP(&opts.Namespace, "namespace", "n", "",
 		"Namespace in which the installation is defined. Defaults to the global namespace.")
+	f.StringSliceVarP(&opts.Labels, "label", "l", nil,
+		"Filter the runs by a label selector formatted as KEY=VALUE, KEY!=VALUE, KEY (the label is set) or !KEY (the label is not set). May be specified multiple times.")
+	f.StringVar(&opts.Action, "action", "",
+		"Filter the runs by the action that was executed, for example upgrade.")
+	f.StringVar(&opts.Status, "status", "",
+		"Filter the runs by the status of their most recent result. Allowed values: succeeded, failed, canceled, running, pending, unknown, timedout")
+	f.StringVar(&opts.RawSince, "since", "",
+		"Only list runs created at or after a timestamp (2022-01-31T15:04:05Z), a date (2022-01-31) or a duration ago (12h, 7d).")
+	f.StringVar(&opts.RawUntil, "until", "",
+		"Only list runs created before a timestamp (2022-01-31T15:04:05Z), a date (2022-01-31) or a duration ago (12h, 7d).")
+	f.Int64Var(&opts.Skip, "skip", 0,
+		"Skip the number of runs by a certain amount, starting from the most recent run. Defaults to 0.")
+	f.Int64Var(&opts.Limit, "limit", 0,
+		"Limit the number of runs by a certain amount. Defaults to 0.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return &cmd
+}
+
+func buildInstallationRunsShowCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.RunShowOptions{}
+
+	cmd := cobra.Command{
+		Use:   "show RUN_ID",
+		Short: "Show a run of an Installation",
+		Long: `Show the details of a run of an Installation, such as the bundle, credential sets and parameter sets that were used.
+
+The result of each step executed by the run is included, with the mixin that executed the step, how long it took and whether it failed. Use --output json or yaml to also see the error and the end of the logs of each step. Steps are only recorded for bundles built with a version of Porter that reports step results.
+
+Use --outputs to include the outputs generated by the run. The values of sensitive outputs are masked, and are not retrieved from the secret store.
+Use --show-sensitive to reveal them. Revealing sensitive outputs must be allowed for the namespace of the installation with the allow-show-sensitive setting of the sensitive-output-policy in the Porter config file.`,
+		Example: `  porter installation runs show 01EZSWJXFATDE24XDHS5D5PWK6
+  porter installation runs show 01EZSWJXFATDE24XDHS5D5PWK6 --outputs
+  porter installation runs show 01EZSWJXFATDE24XDHS5D5PWK6 --outputs --show-sensitive --output json
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintInstallationRun(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&opts.Outputs, "outputs", false,
+		"Include the outputs generated by the run.")
+	f.BoolVar(&opts.ShowSensitive, "show-sensitive", false,
+		"Reveal the values of sensitive outputs. Requires --outputs.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return &cmd
+}
+
+func buildInstallationRunsInspectCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.RunInspectOptions{}
+
+	cmd := cobra.Command{
+		Use:   "inspect RUN_ID",
+		Short: "Inspect how a run of an Installation is stored",
+		Long: `Inspect how the values of a run of an Installation are stored.
+
+Use --show-sensitive-map to list the parameters and outputs of the run that are sensitive, either because the bundle marks them as sensitive or because of a sensitivity policy in the Porter config file, and the key of the secret in the secret store that each value is saved to. The secret store is not read or modified, so this can be used to preview which values are kept out of Porter's database before configuring a secrets plugin or a sensitivity policy. Values that were stored before a sensitivity policy applied to them are listed as not saved.`,
+		Example: `  porter installation runs inspect 01EZSWJXFATDE24XDHS5D5PWK6 --show-sensitive-map
+  porter installation runs inspect 01EZSWJXFATDE24XDHS5D5PWK6 --show-sensitive-map --output json
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.InspectInstallationRun(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&opts.ShowSensitiveMap, "show-sensitive-map", false,
+		"List the sensitive parameters and outputs of the run, and the keys of the secrets that they are saved to.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return &cmd
+}
+
+func buildInstallationRunsAnnotateCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.RunAnnotateOptions{}
+
+	cmd := cobra.Command{
+		Use:   "annotate RUN_ID",
+		Short: "Attach a note to a run of an Installation",
+		Long: `Attach a note to a run of an Installation.
+
+Notes are timestamped and stored with the run so that operational context, such as why a run was rolled back, lives with the deployment history. Notes are included when listing runs with --output json or yaml, and the notes for the most recent run are displayed by porter installation show.`,
+		Example: `  porter installation runs annotate 01EZSWJXFATDE24XDHS5D5PWK6 --note "rolled back manually, see INC-123"
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.AnnotateRun(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.Note, "note", "",
+		"The note to attach to the run.")
+
+	return &cmd
+}
+
+func buildInstallationRunsMarkFailedCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.RunMarkFailedOptions{}
+
+	cmd := cobra.Command{
+		Use:   "mark-failed RUN_ID",
+		Short: "Mark an interrupted run of an Installation as failed",
+		Long: `Mark a run of an Installation as failed when it was interrupted, for example because Porter was killed while the bundle was running.
+
+An interrupted run stays in the running state because its result was never recorded. Porter updates the heartbeat of a run while the bundle is running, and porter installation runs list shows runs whose heartbeat was not updated within 5 minutes as stale. Only runs that have not reported a heartbeat for the --stale-timeout can be marked failed, unless --force is specified.
+
+The status of the installation is updated when the run is the most recent run of the installation. The bundle is not run again, use porter upgrade or porter invoke to retry the action.`,
+		Example: `  porter installation runs mark-failed 01EZSWJXFATDE24XDHS5D5PWK6
+  porter installation runs mark-failed 01EZSWJXFATDE24XDHS5D5PWK6 --stale-timeout 1m
+  porter installation runs mark-failed 01EZSWJXFATDE24XDHS5D5PWK6 --force
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.MarkRunFailed(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.DurationVar(&opts.StaleTimeout, "stale-timeout", storage.RunStaleTimeout,
+		"How long since the last heartbeat of the run before it is considered interrupted.")
+	f.BoolVar(&opts.Force, "force", false,
+		"Mark the run as failed even when its heartbeat was recently updated and it may still be running.")
+
+	return &cmd
+}
+
+func buildInstallationRunsPruneCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.RunPruneOptions{}
+
+	cmd := cobra.Command{
+		Use:   "prune [NAME]",
+		Short: "Remove old runs of an Installation",
+		Long: `Remove old runs of an Installation, along with their results and outputs.
+
+Runs are kept when they match any of the retention rules: --keep-last, --keep-newer-than, or --keep-last-successful. At least one rule must be specified.
+
+Regardless of the rules, the most recent run, runs that have not completed, runs that generated the most recent value of an output, and runs that generated the outputs of a snapshot are always kept.`,
+		Example: `  porter installation runs prune myapp --keep-last 10
+  porter installation runs prune myapp --keep-newer-than 720h --keep-last-successful
+  porter installation runs prune myapp --namespace dev --keep-last 5 --dry-run
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args, p.Context)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PruneInstallationRuns(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace in which the installation is defined. Defaults to the global namespace.")
+	f.IntVar(&opts.KeepLast, "keep-last", 0,
+		"Number of most recent runs to keep.")
+	f.DurationVar(&opts.KeepNewerThan, "keep-newer-than", 0,
+		"Keep runs that were created within the specified duration, for example 720h.")
+	f.BoolVar(&opts.KeepLastSuccessful, "keep-last-successful", false,
+		"Keep the most recent successful run of each action, for example the last successful install and upgrade.")
+	f.BoolVar(&opts.DryRun, "dry-run", false,
+		"List the runs that would be removed without removing them.")
+
+	return &cmd
+}
+
+func buildInstallationRunsDiffCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.RunDiffOptions{}
+
+	cmd := cobra.Command{
+		Use:   "diff BEFORE_RUN_ID AFTER_RUN_ID",
+		Short: "Show the changes between two runs of an Installation",
+		Long: `Show the changes between two runs of an Installation.
+
+Compares the action, bundle reference and digest, credential sets, parameter sets, parameter overrides and outputs of the runs. Use porter installation runs list to find the run IDs.
+
+Sensitive parameters and outputs are resolved from the secret store and compared by their values, which are masked in the output.`,
+		Example: `  porter installation runs diff 01EZSWJXFATDE24XDHS5D5PWK6 01EZSWKFAS6AHZ89PYSE9W8RY8
+  porter installation runs diff 01EZSWJXFATDE24XDHS5D5PWK6 01EZSWKFAS6AHZ89PYSE9W8RY8 --output json
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintInstallationRunsDiff(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return &cmd
+}
+
+func buildInstallationRunsVerifyCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.RunVerifyOptions{}
+
+	cmd := cobra.Command{
+		Use:   "verify RUN_ID",
+		Short: "Verify that the bundle used by a run has not changed",
+		Long: `Verify that the bundle reference used by a run of an Installation still resolves to the same bundle.
+
+The digest of the bundle is recorded on each run. The bundle reference of the run is resolved again in the registry, and a warning is printed when its digest is different from the recorded digest, for example because the tag was pushed again with a different bundle.`,
+		Example: `  porter installation runs verify 01EZSWJXFATDE24XDHS5D5PWK6
+  porter installation runs verify 01EZSWJXFATDE24XDHS5D5PWK6 --output json
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintInstallationRunVerification(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&opts.InsecureRegistry, "insecure-registry", false,
+		"Don't require TLS for the registry")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return &cmd
+}
+
+func buildInstallationRunsEnvDiffCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.RunDiffOptions{}
+
+	cmd := cobra.Command{
+		Use:   "env-diff BEFORE_RUN_ID AFTER_RUN_ID",
+		Short: "Show the changes to the environment between two runs of an Installation",
+		Long: `Show the changes to the executor environment between two runs of an Installation.
+
+Compares the version of Porter, the driver and its version, the version of each mixin used to build the bundle, and the digest of each image in the bundle. Use this to find what changed in the environment between a run that succeeded and a run that failed. Use porter installation runs list to find the run IDs.
+
+The environment is only recorded for runs executed by this version of Porter or later.`,
+		Example: `  porter installation runs env-diff 01EZSWJXFATDE24XDHS5D5PWK6 01EZSWKFAS6AHZ89PYSE9W8RY8
+  porter installation runs env-diff 01EZSWJXFATDE24XDHS5D5PWK6 01EZSWKFAS6AHZ89PYSE9W8RY8 --output json
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintInstallationRunsEnvDiff(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
 	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
 		"Specify an output format.  Allowed values: plaintext, json, yaml")
 
@@ -239,8 +844,10 @@ The docker driver runs the bundle container using the local Docker host. To use
   porter installation install --credential-set azure --credential-set kubernetes
   porter installation install --driver debug
   porter installation install --label env=dev --label owner=myuser
+  porter installation install --output ndjson
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.TimeoutSpecified = cmd.Flags().Changed("timeout")
 			return opts.Validate(cmd.Context(), args, p)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -256,7 +863,7 @@ The docker driver runs the bundle container using the local Docker host. To use
 	f.StringVarP(&opts.Namespace, "namespace", "n", "",
 		"Create the installation in the specified namespace. Defaults to the global namespace.")
 	f.StringSliceVarP(&opts.Labels, "label", "l", nil,
-		"Associate the specified labels with the installation. May be specified multiple times.")
+		"Associate the specified labels with the installation and the run. May be specified multiple times.")
 	addBundleActionFlags(f, opts)
 
 	// Allow configuring the --driver flag with runtime-driver, to avoid conflicts with other commands
@@ -290,8 +897,11 @@ The docker driver runs the bundle container using the local Docker host. To use
   porter installation upgrade --parameter-set azure --param test-mode=true --param header-color=blue
   porter installation upgrade --credential-set azure --credential-set kubernetes
   porter installation upgrade --driver debug
+  porter installation upgrade --label ticket=OPS-1234
+  porter installation upgrade --restore-snapshot before-migration
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.TimeoutSpecified = cmd.Flags().Changed("timeout")
 			return opts.Validate(cmd.Context(), args, p)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -308,7 +918,11 @@ The docker driver runs the bundle container using the local Docker host. To use
 		"Namespace of the specified installation. Defaults to the global namespace.")
 	f.StringVar(&opts.Version, "version", "",
 		"Version to which the installation should be upgraded. This represents the version of the bundle, which assumes the convention of setting the bundle tag to its version.")
+	f.StringSliceVarP(&opts.Labels, "label", "l", nil,
+		"Associate the specified labels with the run. The labels of the installation are not changed. May be specified multiple times.")
 	addBundleActionFlags(f, opts)
+	f.StringVar(&opts.RestoreSnapshot, "restore-snapshot", "",
+		"Name of a snapshot of the installation whose outputs, including the state of the bundle, are passed to the bundle instead of the current outputs.")
 
 	// Allow configuring the --driver flag with runtime-driver, to avoid conflicts with other commands
 	cmd.Flag("driver").Annotations = map[string][]string{
@@ -341,8 +955,10 @@ The docker driver runs the bundle container using the local Docker host. To use
   porter installation invoke --action ACTION  --parameter-set azure --param test-mode=true --param header-color=blue
   porter installation invoke --action ACTION --credential-set azure --credential-set kubernetes
   porter installation invoke --action ACTION --driver debug
+  porter installation invoke --action ACTION --restore-snapshot before-migration
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.TimeoutSpecified = cmd.Flags().Changed("timeout")
 			return opts.Validate(cmd.Context(), args, p)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -359,6 +975,8 @@ The docker driver runs the bundle container using the local Docker host. To use
 		"Path to the CNAB bundle.json file.")
 	f.StringVarP(&opts.Namespace, "namespace", "n", "",
 		"Namespace of the specified installation. Defaults to the global namespace.")
+	f.StringVar(&opts.RestoreSnapshot, "restore-snapshot", "",
+		"Name of a snapshot of the installation whose outputs, including the state of the bundle, are passed to the bundle instead of the current outputs.")
 	addBundleActionFlags(f, opts)
 
 	// Allow configuring the --driver flag with runtime-driver, to avoid conflicts with other commands
@@ -396,6 +1014,7 @@ The docker driver runs the bundle container using the local Docker host. To use
   porter installation uninstall --force-delete
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.TimeoutSpecified = cmd.Flags().Changed("timeout")
 			return opts.Validate(cmd.Context(), args, p)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -437,10 +1056,30 @@ func addBundleActionFlags(f *pflag.FlagSet, actionOpts porter.BundleAction) {
 		"Define an individual parameter in the form NAME=VALUE. Overrides parameters otherwise set via --parameter-set. May be specified multiple times.")
 	f.StringArrayVarP(&opts.CredentialIdentifiers, "credential-set", "c", nil,
 		"Credential sets to use when running the bundle. It should be a named set of credentials and may be specified multiple times.")
-	f.StringVarP(&opts.Driver, "driver", "d", porter.DefaultDriver,
-		"Specify a driver to use. Allowed values: docker, debug")
+	f.StringVarP(&opts.Driver, "driver", "d", "",
+		"Specify a driver to use. Allowed values: docker, debug. Defaults to the default driver of the bundle, or docker.")
+	f.StringVar(&opts.DriverPolicy, "driver-policy", "",
+		"Controls what happens when the bundle does not support the driver. Allowed values: enforce, warn. Defaults to enforce.")
 	f.BoolVar(&opts.DebugMode, "debug", false,
 		"Run the bundle in debug mode.")
+	f.DurationVar(&opts.Timeout, "timeout", 0,
+		"Maximum amount of time that the bundle may run before it is stopped, for example 30m. When the timeout expires, the bundle is asked to stop gracefully and the run is recorded as timed out. Defaults to the timeout configured for the action, or no timeout.")
+	// Allow configuring the --timeout flag with runtime-timeout, to avoid conflicts with other commands
+	f.Lookup("timeout").Annotations = map[string][]string{
+		"viper-key": {"runtime-timeout"},
+	}
+	f.DurationVar(&opts.LockTimeout, "lock-timeout", 0,
+		"Maximum amount of time to wait for another operation on the installation, such as an upgrade, to complete, for example 5m. Defaults to failing immediately when the installation is being modified.")
+	f.StringArrayVar(&opts.EphemeralOutputs, "ephemeral-output", nil,
+		"Name of an output that should be printed when the bundle completes but never saved, for example a one-time token. May be specified multiple times.")
+	f.StringVar(&opts.ChangeTicket, "change-ticket", "",
+		"ID of a ticket in an external change-management system that is updated with the outcome of the run. Requires change-management to be configured.")
+	f.StringVarP(&opts.RawFormat, "output", "o", string(porter.ExecutionDefaultFormat),
+		"Specify an output format. Use ndjson to print the events of the run, such as log lines, steps, outputs and the result, as newline delimited json as they happen. Allowed values: plaintext, ndjson")
+	f.StringVar(&opts.Remote, "remote", "",
+		"URL of a porter api serve instance that runs the bundle instead of running it locally, for example https://porter.example.com. Parameter and credential sets are resolved by the server.")
+	f.StringVar(&opts.RemoteToken, "remote-token", "",
+		"Token used to authenticate with the server specified by --remote.")
 
 	// Gracefully support any renamed flags
 	f.StringArrayVar(&opts.CredentialIdentifiers, "cred", nil, "DEPRECATED")
@@ -17,6 +17,7 @@ func buildInstallationLogCommands(p *porter.Porter) *cobra.Command {
 	}
 
 	cmd.AddCommand(buildInstallationLogShowCommand(p))
+	cmd.AddCommand(buildInstallationLogSearchCommand(p))
 
 	return cmd
 }
@@ -50,3 +51,38 @@ Either display the logs from a specific run of a bundle with --run, or use --ins
 
 	return cmd
 }
+
+func buildInstallationLogSearchCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.LogsSearchOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "search",
+		Short: "Search the logs of runs",
+		Long: `Search the stored logs of runs for lines that match a regular expression.
+
+Searches the runs of an installation with --installation, or of every installation in the namespace. The logs persisted with --persist-logs are searched, and the step that wrote each matching line is identified from the results recorded for each step. When the logs of a run were not persisted, the logs recorded for each step are searched instead, which only include the end of the logs of each step.`,
+		Example: `  porter installation logs search --installation wordpress --grep "connection refused"
+  porter installation logs search --namespace dev --since 7d --grep "timeout|refused"
+  porter installation logs search --installation wordpress --since 12h --grep "error" --output json`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintLogsSearch(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace of the installations to search. Defaults to the global namespace.")
+	f.StringVarP(&opts.Installation, "installation", "i", "",
+		"The installation whose runs are searched. Defaults to every installation in the namespace.")
+	f.StringVar(&opts.RawSince, "since", "",
+		"Only search runs created within the specified duration, for example 7d or 12h. Defaults to all runs.")
+	f.StringVar(&opts.Grep, "grep", "",
+		"Regular expression that matching log lines must contain.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return cmd
+}
@@ -3,15 +3,19 @@ package main
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"os/signal"
 	"runtime/debug"
 	"strings"
 
 	"get.porter.sh/porter/pkg/cli"
 	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/failure"
 	"get.porter.sh/porter/pkg/porter"
+	"get.porter.sh/porter/pkg/printer"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"go.opentelemetry.io/otel/attribute"
@@ -22,6 +26,9 @@ var includeDocsCommand = false
 //go:embed helptext/usage.txt
 var usageText string
 
+//go:embed helptext/exit-codes.txt
+var exitCodesText string
+
 const (
 	// Indicates that config should not be loaded for this command.
 	// This is used for commands like help and version which should never
@@ -37,6 +44,13 @@ func main() {
 
 		rootCmd := buildRootCommandFrom(p)
 
+		// Delegate unknown commands to an external command, porter-NAME, when one is installed
+		if name, args, ok := getExternalCommand(rootCmd, os.Args[1:]); ok {
+			if cmdPath, found := p.FindExternalCommand(name); found {
+				return runExternalCommand(ctx, p, name, cmdPath, args)
+			}
+		}
+
 		// Trace the command that called porter, e.g. porter installation show
 		cmd, commandName, formattedCommand := getCalledCommand(rootCmd)
 
@@ -77,11 +91,17 @@ func main() {
 			}
 		}()
 
-		if err := rootCmd.ExecuteContext(ctx); err != nil {
+		err := rootCmd.ExecuteContext(ctx)
+		if p.Data.DebugStorageStats {
+			if statsErr := p.PrintStorageStats(); statsErr != nil {
+				log.Warnf("could not print the storage statistics: %s", statsErr)
+			}
+		}
+		if err != nil {
 			// Ideally we log all errors in the span that generated it,
 			// but as a failsafe, always log the error at the root span as well
 			log.Error(err)
-			return cli.ExitCodeErr
+			return cli.GetExitCode(err)
 		}
 		return cli.ExitCodeSuccess
 	}
@@ -116,6 +136,53 @@ func handleInterrupt(ctx context.Context, p *porter.Porter) (context.Context, fu
 	}
 }
 
+// getExternalCommand determines if the arguments call a command that is not
+// built into porter, returning the name of the command and its arguments.
+func getExternalCommand(rootCmd *cobra.Command, args []string) (string, []string, bool) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return "", nil, false
+	}
+
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == args[0] || cmd.HasAlias(args[0]) {
+			return "", nil, false
+		}
+	}
+
+	// cobra adds the help and completion commands when the root command is executed
+	if args[0] == "help" || args[0] == "completion" || strings.HasPrefix(args[0], cobra.ShellCompRequestCmd) {
+		return "", nil, false
+	}
+
+	return args[0], args[1:], true
+}
+
+// runExternalCommand executes an external command with the current porter
+// configuration, returning the exit code of the command.
+func runExternalCommand(ctx context.Context, p *porter.Porter, name string, cmdPath string, args []string) int {
+	ctx, err := p.Connect(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return cli.ExitCodeErr
+	}
+
+	ctx, log := p.StartRootSpan(ctx, "porter "+name, attribute.String("command", strings.Join(os.Args, " ")))
+	defer func() {
+		log.Close()
+		p.Close()
+	}()
+
+	if err = p.RunExternalCommand(ctx, cmdPath, args); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		log.Error(err)
+		return cli.ExitCodeErr
+	}
+	return cli.ExitCodeSuccess
+}
+
 func shouldSkipConfig(cmd *cobra.Command) bool {
 	if cmd.Name() == "help" {
 		return true
@@ -210,6 +277,9 @@ Try our QuickStart https://getporter.org/quickstart to learn how to use Porter.
 	// These flags are available for every command
 	globalFlags := cmd.PersistentFlags()
 	globalFlags.StringVar(&p.Data.Verbosity, "verbosity", config.DefaultVerbosity, "Threshold for printing messages to the console. Available values are: debug, info, warning, error.")
+	globalFlags.StringVar(&p.Data.OutputProfile, "output-profile", string(printer.ProfileDefault), "Controls color, progress, prompts and the format of tables printed to the console. Available values are: default, ci, quiet, porcelain.")
+	globalFlags.BoolVar(&p.Data.ReadOnly, "read-only", false, "Reject any change to Porter's storage, such as saving an installation or a run. Useful for jobs that should only read data.")
+	globalFlags.BoolVar(&p.Data.DebugStorageStats, "debug-storage-stats", false, "Print a summary of the queries made to the storage plugin and the bundle cache hit rate when the command completes.")
 	globalFlags.StringSliceVar(&p.Data.ExperimentalFlags, "experimental", nil, "Comma separated list of experimental features to enable. See https://getporter.org/configuration/#experimental-feature-flags for available feature flags.")
 
 	// Flags for just the porter command only, does not apply to sub-commands
@@ -219,13 +289,20 @@ Try our QuickStart https://getporter.org/quickstart to learn how to use Porter.
 	cmd.AddCommand(buildSchemaCommand(p))
 	cmd.AddCommand(buildStorageCommand(p))
 	cmd.AddCommand(buildRunCommand(p))
+	cmd.AddCommand(buildProbeCommand(p))
 	cmd.AddCommand(buildBundleCommands(p))
 	cmd.AddCommand(buildInstallationCommands(p))
 	cmd.AddCommand(buildMixinCommands(p))
 	cmd.AddCommand(buildPluginsCommands(p))
 	cmd.AddCommand(buildCredentialsCommands(p))
 	cmd.AddCommand(buildParametersCommands(p))
+	cmd.AddCommand(buildNamespacesCommands(p))
+	cmd.AddCommand(buildSecretsCommands(p))
+	cmd.AddCommand(buildAPICommands(p))
+	cmd.AddCommand(buildAgentCommand(p))
+	cmd.AddCommand(buildCacheCommands(p))
 	cmd.AddCommand(buildCompletionCommand(p))
+	cmd.AddCommand(buildExitCodesHelpTopic())
 
 	for _, alias := range buildAliasCommands(p) {
 		cmd.AddCommand(alias)
@@ -241,9 +318,41 @@ Try our QuickStart https://getporter.org/quickstart to learn how to use Porter.
 		cmd.AddCommand(buildDocsCommand(p))
 	}
 
+	// Invalid flags and arguments exit with a distinct exit code
+	cmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return failure.Validation(err)
+	})
+	classifyValidationErrors(cmd)
+
 	return cmd
 }
 
+// classifyValidationErrors classifies the errors returned by the PreRunE
+// function of each command, which validates its flags and arguments, as
+// validation failures.
+func classifyValidationErrors(cmd *cobra.Command) {
+	if preRun := cmd.PreRunE; preRun != nil {
+		cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+			return failure.Validation(preRun(cmd, args))
+		}
+	}
+	for _, child := range cmd.Commands() {
+		classifyValidationErrors(child)
+	}
+}
+
+// buildExitCodesHelpTopic explains the exit codes with porter help exit-codes.
+func buildExitCodesHelpTopic() *cobra.Command {
+	return &cobra.Command{
+		Use:   "exit-codes",
+		Short: "Exit codes returned by porter",
+		Long:  exitCodesText,
+		Annotations: map[string]string{
+			skipConfig: "",
+		},
+	}
+}
+
 func ShouldShowGroupCommands(cmd *cobra.Command, group string) bool {
 	for _, child := range cmd.Commands() {
 		if ShouldShowGroupCommand(child, group) {
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"get.porter.sh/porter/pkg/cli"
 	"get.porter.sh/porter/pkg/experimental"
 	"get.porter.sh/porter/pkg/porter"
 	"github.com/stretchr/testify/assert"
@@ -80,6 +81,39 @@ func TestHelp(t *testing.T) {
 	})
 }
 
+func TestHelp_ExitCodes(t *testing.T) {
+	var output bytes.Buffer
+	rootCmd := buildRootCommand()
+	rootCmd.SetArgs([]string{"help", "exit-codes"})
+	rootCmd.SetOut(&output)
+
+	err := rootCmd.Execute()
+	require.NoError(t, err)
+	assert.Contains(t, output.String(), "Policy denial")
+}
+
+func TestValidationExitCode(t *testing.T) {
+	testcases := []struct {
+		name string
+		args []string
+	}{
+		{name: "unknown flag", args: []string{"installation", "list", "--bogus"}},
+		{name: "invalid arguments", args: []string{"installation", "outputs", "find"}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := porter.NewTestPorter(t)
+			defer p.Close()
+
+			cmd := buildRootCommandFrom(p.Porter)
+			cmd.SetArgs(tc.args)
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Equal(t, cli.ExitCodeValidation, cli.GetExitCode(err))
+		})
+	}
+}
+
 func TestExperimentalFlags(t *testing.T) {
 	// do not run in parallel
 	expEnvVar := "PORTER_EXPERIMENTAL"
@@ -120,3 +154,32 @@ func TestExperimentalFlags(t *testing.T) {
 		assert.True(t, p.Config.IsFeatureEnabled(experimental.FlagNoopFeature))
 	})
 }
+
+func TestGetExternalCommand(t *testing.T) {
+	testcases := []struct {
+		name     string
+		args     []string
+		wantName string
+		wantArgs []string
+		wantOK   bool
+	}{
+		{name: "no args", args: nil},
+		{name: "flag", args: []string{"--version"}},
+		{name: "built-in command", args: []string{"install", "mybuns"}},
+		{name: "built-in alias", args: []string{"bundle", "build"}},
+		{name: "help", args: []string{"help", "install"}},
+		{name: "completion", args: []string{"__complete", "ins"}},
+		{name: "external command", args: []string{"audit", "--since", "1d"}, wantName: "audit", wantArgs: []string{"--since", "1d"}, wantOK: true},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			rootCmd := buildRootCommand()
+			name, args, ok := getExternalCommand(rootCmd, tc.args)
+			assert.Equal(t, tc.wantOK, ok)
+			assert.Equal(t, tc.wantName, name)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
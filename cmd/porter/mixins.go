@@ -24,6 +24,7 @@ func buildMixinCommands(p *porter.Porter) *cobra.Command {
 	cmd.AddCommand(BuildMixinUninstallCommand(p))
 	cmd.AddCommand(buildMixinsFeedCommand(p))
 	cmd.AddCommand(buildMixinsCreateCommand(p))
+	cmd.AddCommand(buildMixinsDoctorCommand(p))
 
 	return cmd
 }
@@ -86,10 +87,14 @@ func BuildMixinInstallCommand(p *porter.Porter) *cobra.Command {
 		Short: "Install a mixin",
 		Long: `Install a mixin.
 
-By default mixins are downloaded from the official Porter mixin feed at https://cdn.porter.sh/mixins/atom.xml. To download from a mirror, set the environment variable PORTER_MIRROR, or mirror in the Porter config file, with the value to replace https://cdn.porter.sh with.`,
+By default mixins are downloaded from the official Porter mixin feed at https://cdn.porter.sh/mixins/atom.xml. To download from a mirror, set the environment variable PORTER_MIRROR, or mirror in the Porter config file, with the value to replace https://cdn.porter.sh with.
+
+Mixins published as OCI artifacts are pulled from a registry with --reference, which may be a mirror of the original registry. The artifact must have a layer for each binary with the org.opencontainers.image.title annotation set to NAME-OS-ARCH, for example helm3-linux-amd64, and NAME-windows-amd64.exe for Windows. The --version flag is not used with --reference, specify the version with the tag of the reference instead. Use a digested reference to pin the mixin to a specific artifact. The binaries are always verified against the digests in the artifact.`,
 		Example: `  porter mixin install helm3 --feed-url https://mchorfa.github.io/porter-helm3/atom.xml
   porter mixin install azure --version v0.4.0-ralpha.1+dubonnet --url https://cdn.porter.sh/mixins/azure
-  porter mixin install kubernetes --version canary --url https://cdn.porter.sh/mixins/kubernetes`,
+  porter mixin install kubernetes --version canary --url https://cdn.porter.sh/mixins/kubernetes
+  porter mixin install helm3 --reference ghcr.io/example/mixins/helm3:v1.0.0
+  porter mixin install helm3 --reference localhost:5000/mixins/helm3@sha256:a881bbc015bade9f11d95a4244888d8e7fa8800f843b43c74cc07c7b7276b062 --insecure-registry`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return opts.Validate(args)
 		},
@@ -105,6 +110,10 @@ By default mixins are downloaded from the official Porter mixin feed at https://
 		"URL from where the mixin can be downloaded, for example https://github.com/org/proj/releases/downloads")
 	flags.StringVar(&opts.FeedURL, "feed-url", "",
 		"URL of an atom feed where the mixin can be downloaded. Defaults to the official Porter mixin feed.")
+	flags.StringVar(&opts.Reference, "reference", "",
+		"Reference to the mixin published as an OCI artifact, for example ghcr.io/example/mixins/helm3:v1.0.0")
+	flags.BoolVar(&opts.InsecureRegistry, "insecure-registry", false,
+		"Don't require TLS for the registry specified by --reference")
 	flags.StringVar(&opts.Mirror, "mirror", pkgmgmt.DefaultPackageMirror,
 		"Mirror of official Porter assets")
 	return cmd
@@ -229,3 +238,36 @@ You can also specify where to put the mixin directory. It will default to the cu
 
 	return cmd
 }
+
+func buildMixinsDoctorCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.MixinsDoctorOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "doctor [NAME]",
+		Short: "Diagnose problems with installed mixins",
+		Long: `Diagnose problems with installed mixins, so that a broken or partially installed mixin is found before it fails a build.
+
+Each mixin is checked that:
+* The mixin binary exists and is executable.
+* The mixin runtime binary, which is copied into the bundle, exists and is executable.
+* The mixin reports its version.
+* The mixin supports a version of the mixin protocol that Porter supports. A mixin that does not report its capabilities is a warning.
+* The mixin reports a valid manifest schema. A mixin that does not report a schema is a warning, because its steps are not validated.
+
+When a mixin name is not specified, every installed mixin is checked. The command fails when a problem is found with any mixin.`,
+		Example: `  porter mixins doctor
+  porter mixins doctor helm3
+  porter mixins doctor --output json`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.MixinsDoctor(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Output format, allowed values are: plaintext, json, yaml")
+
+	return cmd
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"get.porter.sh/porter/pkg/porter"
+	"github.com/spf13/cobra"
+)
+
+func buildNamespacesCommands(p *porter.Porter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "namespaces",
+		Aliases:     []string{"namespace", "ns"},
+		Annotations: map[string]string{"group": "resource"},
+		Short:       "Namespace commands",
+		Long: `Commands for managing namespaces.
+
+Namespaces do not need to be created before installations, credential sets or parameter sets are defined in them. Creating a namespace records metadata about it, such as its owners, the default credential and parameter sets for new installations, and protection against accidental deletion.`,
+	}
+
+	cmd.AddCommand(buildNamespaceCreateCommand(p))
+	cmd.AddCommand(buildNamespaceListCommand(p))
+	cmd.AddCommand(buildNamespaceDeleteCommand(p))
+
+	return cmd
+}
+
+func buildNamespaceCreateCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.NamespaceCreateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create a namespace",
+		Long: `Create a namespace with metadata that is used by Porter when working with the resources in the namespace.
+
+New installations in the namespace use the default credential and parameter sets when none are specified.
+When installations are protected, deleting an installation in the namespace requires --force, or --force-delete when uninstalling.
+When deletion is protected, deleting the namespace requires --force.`,
+		Example: `  porter namespaces create dev --description "Development environments" --owner platform-team
+  porter namespaces create prod --default-credential-set kubeconfig --default-parameter-set prod-defaults
+  porter namespaces create prod --protect-deletion --protect-installations --label env=prod`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.CreateNamespace(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.Description, "description", "",
+		"Description of the namespace.")
+	f.StringSliceVar(&opts.Owners, "owner", nil,
+		"Owner of the namespace, such as a team name or email address. May be specified multiple times.")
+	f.StringSliceVarP(&opts.Labels, "label", "l", nil,
+		"Associate the specified labels with the namespace. May be specified multiple times.")
+	f.StringSliceVar(&opts.DefaultCredentialSets, "default-credential-set", nil,
+		"Credential set used by new installations in the namespace when none are specified. May be specified multiple times.")
+	f.StringSliceVar(&opts.DefaultParameterSets, "default-parameter-set", nil,
+		"Parameter set used by new installations in the namespace when none are specified. May be specified multiple times.")
+	f.BoolVar(&opts.ProtectDeletion, "protect-deletion", false,
+		"Require --force to delete the namespace.")
+	f.BoolVar(&opts.ProtectInstallations, "protect-installations", false,
+		"Require --force to delete installations in the namespace.")
+
+	return cmd
+}
+
+func buildNamespaceListCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.NamespaceListOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List namespaces",
+		Long: `List namespaces that have been created.
+
+Optionally filters the results name, which returns all results whose name contain the provided query.
+The results may also be filtered by associated labels.`,
+		Example: `  porter namespaces list
+  porter namespaces list --name dev
+  porter namespaces list --label env=prod
+  porter namespaces list --output json`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintNamespaces(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.Name, "name", "",
+		"Filter the namespaces where the name contains the specified substring.")
+	f.StringSliceVarP(&opts.Labels, "label", "l", nil,
+		"Filter the namespaces by a label selector formatted as KEY=VALUE, KEY!=VALUE, KEY (the label is set) or !KEY (the label is not set). May be specified multiple times.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+	f.Int64Var(&opts.Skip, "skip", 0,
+		"Skip the number of namespaces by a certain amount. Defaults to 0.")
+	f.Int64Var(&opts.Limit, "limit", 0,
+		"Limit the number of namespaces by a certain amount. Defaults to 0.")
+
+	return cmd
+}
+
+func buildNamespaceDeleteCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.NamespaceDeleteOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "delete NAME",
+		Short: "Delete a namespace",
+		Long: `Delete a namespace.
+
+The installations, credential sets and parameter sets defined in the namespace are not deleted. A namespace that is protected from deletion, or that still has installations, is only deleted when --force is specified.`,
+		Example: `  porter namespaces delete dev
+  porter namespaces delete prod --force`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.DeleteNamespace(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&opts.Force, "force", false,
+		"Delete the namespace even when it is protected or still has installations.")
+
+	return cmd
+}
@@ -17,6 +17,9 @@ func buildInstallationOutputsCommands(p *porter.Porter) *cobra.Command {
 
 	cmd.AddCommand(buildBundleOutputShowCommand(p))
 	cmd.AddCommand(buildBundleOutputListCommand(p))
+	cmd.AddCommand(buildInstallationOutputFindCommand(p))
+	cmd.AddCommand(buildInstallationOutputVerifyCommand(p))
+	cmd.AddCommand(buildInstallationOutputHistoryCommand(p))
 
 	return cmd
 }
@@ -54,11 +57,14 @@ func buildBundleOutputShowCommand(p *porter.Porter) *cobra.Command {
 	opts := porter.OutputShowOptions{}
 
 	cmd := cobra.Command{
-		Use:   "show NAME [--installation|-i INSTALLATION]",
+		Use:   "show [INSTALLATION] NAME [--installation|-i INSTALLATION]",
 		Short: "Show the output of an installation",
-		Long:  "Show the output of an installation",
+		Long: `Show the output of an installation.
+
+The installation may be specified in the format NAMESPACE/NAME to show the output of an installation in another namespace. The output must be shared with the current namespace by a namespace policy in the Porter configuration file.`,
 		Example: `  porter installation output show kubeconfig
-    porter installation output show subscription-id --installation azure-mysql`,
+    porter installation output show subscription-id --installation azure-mysql
+    porter installation output show platform/mysql connstr --namespace dev`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return opts.Validate(args, p.Context)
 		},
@@ -75,3 +81,111 @@ func buildBundleOutputShowCommand(p *porter.Porter) *cobra.Command {
 
 	return &cmd
 }
+
+func buildInstallationOutputFindCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.OutputFindOptions{}
+
+	cmd := cobra.Command{
+		Use:   "find NAME",
+		Short: "Find the installations that expose an output",
+		Long: `Find the installations that expose an output with the specified name, for example to take an inventory of the installations deployed to a cluster.
+
+Only the most recent value of the output for each installation is considered. Use --value or --value-hash to find the installations whose output has that value. The values are compared by their hash, so the values are not read or printed. Sensitive outputs, and outputs saved by an earlier version of Porter, are only found by name.`,
+		Example: `  porter installation outputs find cluster
+  porter installation outputs find cluster --value prod-east --all-namespaces
+  porter installation outputs find cluster --value-hash sha256:5fb8fce78382d28d746e0af6610c85c3bbf7ef8934a36c5bc2124fee3cab8f58 -o json`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintFoundOutputs(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Filter the installations by namespace. Defaults to the global namespace.")
+	f.BoolVar(&opts.AllNamespaces, "all-namespaces", false,
+		"Include all namespaces in the results.")
+	f.StringVar(&opts.Value, "value", "",
+		"Only include installations where the most recent value of the output matches.")
+	f.StringVar(&opts.ValueHash, "value-hash", "",
+		"Only include installations where the most recent value of the output has the hash, formatted as sha256:HEX.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return &cmd
+}
+
+func buildInstallationOutputVerifyCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.OutputVerifyOptions{}
+
+	cmd := cobra.Command{
+		Use:   "verify [INSTALLATION] --contract FILE",
+		Short: "Verify installation outputs against a contract",
+		Long: `Verify that the outputs of the last run of an installation satisfy a contract provided by a consumer of the outputs, such as automation owned by another team.
+
+The contract is a json file that lists the expected outputs by name, with an optional type and json schema that the output value must satisfy. Outputs marked as optional are not required to be present. The command fails and prints the differences between the contract and the outputs when the contract is not satisfied.
+
+  {
+    "outputs": {
+      "kubeconfig": {"type": "file"},
+      "port": {"type": "integer", "schema": {"type": "integer", "minimum": 1024}},
+      "region": {"type": "string", "optional": true}
+    }
+  }`,
+		Example: `  porter installation outputs verify --contract contract.json
+  porter installation outputs verify mysql --contract contract.json --namespace dev
+  porter installation outputs verify mysql --contract contract.json -o json`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args, p.Context)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintOutputContractResult(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.Contract, "contract", "",
+		"Path to the contract file that the outputs must satisfy.")
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace in which the installation is defined. Defaults to the global namespace.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return &cmd
+}
+
+func buildInstallationOutputHistoryCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.OutputHistoryOptions{}
+
+	cmd := cobra.Command{
+		Use:   "history [INSTALLATION] NAME [--installation|-i INSTALLATION]",
+		Short: "Show the history of an output of an installation",
+		Long: `Show every value of an output of an installation, from oldest to newest, along with the run that generated it and whether it changed from the previous value.
+
+The values of sensitive outputs are masked unless --show-sensitive is specified, which resolves them from the secret store when allowed by the sensitive-output-policy in the Porter configuration file. Whether a masked value changed is not reported.`,
+		Example: `  porter installation output history kubeconfig
+  porter installation output history mysql connstr --namespace dev
+  porter installation output history password --installation mysql --show-sensitive
+  porter installation output history connstr -i mysql -o json`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args, p.Context)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintOutputHistory(cmd.Context(), &opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace in which the installation is defined. Defaults to the global namespace.")
+	f.StringVarP(&opts.Name, "installation", "i", "",
+		"Specify the installation to which the output belongs.")
+	f.BoolVar(&opts.ShowSensitive, "show-sensitive", false,
+		"Reveal the values of sensitive outputs, when allowed by the sensitive-output-policy.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return &cmd
+}
@@ -34,11 +34,14 @@ func buildParametersApplyCommand(p *porter.Porter) *cobra.Command {
 
 Supported file extensions: json and yaml.
 
+A yaml file may define multiple parameter sets as separate documents, separated by ---. Documents with a schemaType other than ParameterSet are skipped, so the same file can define all the resources for an environment. Every document is validated before any changes are applied.
+
 You can use the generate and show commands to create the initial file:
   porter parameters generate myparams --reference SOME_BUNDLE
   porter parameters show myparams --output yaml > myparams.yaml
 `,
-		Example: `  porter parameters apply myparams.yaml`,
+		Example: `  porter parameters apply myparams.yaml
+  porter parameters apply environment.yaml`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return opts.Validate(p.Context, args)
 		},
@@ -161,7 +164,7 @@ The results may also be filtered by associated labels and the namespace in which
 	f.StringVar(&opts.Name, "name", "",
 		"Filter the parameter sets where the name contains the specified substring.")
 	f.StringSliceVarP(&opts.Labels, "label", "l", nil,
-		"Filter the parameter sets by a label formatted as: KEY=VALUE. May be specified multiple times.")
+		"Filter the parameter sets by a label selector formatted as KEY=VALUE, KEY!=VALUE, KEY (the label is set) or !KEY (the label is not set). May be specified multiple times.")
 	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
 		"Specify an output format.  Allowed values: plaintext, json, yaml")
 	f.Int64Var(&opts.Skip, "skip", 0,
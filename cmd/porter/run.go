@@ -31,3 +31,33 @@ func buildRunCommand(p *porter.Porter) *cobra.Command {
 
 	return cmd
 }
+
+func buildProbeCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.NewProbeOptions(p.Config)
+	cmd := &cobra.Command{
+		Use:   "probe",
+		Short: "Probe the bundle with a read-only action",
+		Long: `Probe the bundle by executing a read-only action, such as status, and print the result as json.
+
+The probe skips the bootstrap of a full action: bundle images are not resolved, and bundle outputs, step results and state are not saved. Only custom actions that are declared with modifies: false may be probed.
+The command exits with a non-zero exit code when the probe fails.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.Probe(cmd.Context(), opts)
+		},
+		Hidden: true, // Hide runtime commands from the helptext
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&opts.File, "file", "f", "porter.yaml", "The porter configuration file (Defaults to porter.yaml)")
+	flags.StringVar(&opts.Action, "action", "", "The bundle action to probe (Defaults to CNAB_ACTION, or status when it is not set)")
+	flags.BoolVar(&opts.DebugMode, "debug", false, "Enable debug mode for the bundle")
+
+	cmd.Annotations = map[string]string{
+		"group": "runtime",
+	}
+
+	return cmd
+}
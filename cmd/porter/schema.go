@@ -6,15 +6,25 @@ import (
 )
 
 func buildSchemaCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.SchemaOptions{}
 	cmd := &cobra.Command{
 		Use:   "schema",
 		Short: "Print the JSON schema for the Porter manifest",
+		Long: `Print the JSON schema for the Porter manifest, including the schema of each installed mixin.
+
+The schema reported by each mixin is cached in PORTER_HOME and reused until the mixin binary changes.`,
+		Example: `  porter schema
+  porter schema --no-cache`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return p.PrintManifestSchema(cmd.Context())
+			return p.PrintManifestSchema(cmd.Context(), opts)
 		},
 	}
 	cmd.Annotations = map[string]string{
 		"group": "meta",
 	}
+
+	f := cmd.Flags()
+	f.BoolVar(&opts.NoCache, "no-cache", false,
+		"Query each mixin for its schema instead of using the cached schema.")
 	return cmd
 }
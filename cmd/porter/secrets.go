@@ -0,0 +1,66 @@
+package main
+
+import (
+	"get.porter.sh/porter/pkg/porter"
+	"github.com/spf13/cobra"
+)
+
+func buildSecretsCommands(p *porter.Porter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "secrets",
+		Aliases:     []string{"secret"},
+		Annotations: map[string]string{"group": "resource"},
+		Short:       "Secret commands",
+		Long:        "Commands for working with the secrets stored by the configured secrets plugin.",
+	}
+
+	cmd.AddCommand(buildSecretGenerateCommand(p))
+
+	return cmd
+}
+
+func buildSecretGenerateCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.SecretGenerateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "generate --name NAME --type TYPE",
+		Short: "Generate a secret value",
+		Long: `Generate a random secret value and save it with the configured secrets plugin.
+
+The following types of secrets may be generated:
+  password: A random password made of letters, digits and the symbols -_.~
+  rsa: An RSA key pair. The private key is encoded as PKCS #8 PEM, and the public key as PKIX PEM.
+  ssh: An ed25519 SSH key pair. The private key is in the OpenSSH format, and the public key in the authorized_keys format.
+
+The private key of a key pair is saved to the secret NAME, and the public key to the secret NAME-public.
+The generated value is not printed. Instead, Porter prints the parameter or credential set entries that reference the generated secrets.
+Generating a secret fails when the secret already exists, unless --force is specified.`,
+		Example: `  porter secrets generate --name db-password --type password
+  porter secrets generate --name db-password --type password --length 64
+  porter secrets generate --name signing-key --type rsa --bits 2048
+  porter secrets generate --name deploy-key --type ssh --output json
+  porter secrets generate --name db-password --type password --force`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.GenerateSecret(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.Name, "name", "",
+		"Name of the secret where the generated value is saved.")
+	f.StringVar(&opts.Type, "type", "",
+		"Type of secret to generate. Allowed values are: password, rsa, ssh.")
+	f.IntVar(&opts.Length, "length", 0,
+		"Number of characters in a generated password. Defaults to 32.")
+	f.IntVar(&opts.Bits, "bits", 0,
+		"Size of a generated RSA key in bits. Defaults to 4096.")
+	f.BoolVar(&opts.Force, "force", false,
+		"Overwrite existing secrets with the same name.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return cmd
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"get.porter.sh/porter/pkg/porter"
+	"get.porter.sh/porter/pkg/storage"
 	"github.com/spf13/cobra"
 )
 
@@ -18,6 +19,10 @@ func buildStorageCommand(p *porter.Porter) *cobra.Command {
 
 	cmd.AddCommand(buildStorageMigrateCommand(p))
 	cmd.AddCommand(buildStorageFixPermissionsCommand(p))
+	cmd.AddCommand(buildStorageSchemaCommand(p))
+	cmd.AddCommand(buildStorageRotateSecretsCommand(p))
+	cmd.AddCommand(buildStorageFsckCommand(p))
+	cmd.AddCommand(buildStorageCheckCommand(p))
 
 	return &cmd
 }
@@ -25,9 +30,15 @@ func buildStorageCommand(p *porter.Porter) *cobra.Command {
 func buildStorageMigrateCommand(p *porter.Porter) *cobra.Command {
 	var opts porter.MigrateStorageOptions
 	cmd := &cobra.Command{
-		Use:   "migrate --old-home OLD_PORTER_HOME [--old-account STORAGE_NAME] [--namespace NAMESPACE]",
-		Short: "Migrate data from v0.38 to v1",
-		Long: `Migrate data from Porter v0.38 into a v1 installation of Porter.
+		Use:   "migrate [--old-home OLD_PORTER_HOME] [--old-account STORAGE_NAME] [--namespace NAMESPACE]",
+		Short: "Migrate data to the current storage schema",
+		Long: `Migrate the data stored by Porter to the current storage schema.
+
+When --old-home is not specified, the runs saved by a previous version of Porter with an older schema are migrated to the current schema and saved.
+Runs are also migrated when they are read, so this is only required to update the stored documents. The status of each run that uses an older schema is reported.
+Use --dry-run to report the runs that would be migrated without saving them, and --namespace to only migrate the runs in a namespace.
+
+When --old-home is specified, the data from Porter v0.38 is migrated into a v1 installation of Porter.
 
 See https://getporter.org/storage-migrate for a full description of the migration process. Below is a summary:
 
@@ -43,7 +54,9 @@ This upgrades the data to the current storage schema, and does not change the da
 This command may be repeated if it fails, is interrupted when first run, or new v0 data has been added.
 Porter will restart the migration from the beginning and overwrite any previously migrated records.
 🚨 After you use Porter v1 with the migrated database, DO NOT RERUN THE MIGRATION because subsequent migrations will overwrite data in the v1 database.`,
-		Example: `  porter storage migrate --old-home ~/.porterv0
+		Example: `  porter storage migrate
+  porter storage migrate --dry-run --namespace dev --output json
+  porter storage migrate --old-home ~/.porterv0
   porter storage migrate --old-account my-azure --old-home ~/.porterv0
   porter storage migrate --namespace new-namespace --old-home ~/.porterv0
 `,
@@ -58,7 +71,11 @@ Porter will restart the migration from the beginning and overwrite any previousl
 	flags.StringVar(&opts.OldStorageAccount, "old-account", "",
 		"Name of the storage account in the old Porter configuration file containing the data that should be migrated. If unspecified, the default storage account is used.")
 	flags.StringVarP(&opts.Namespace, "namespace", "n", "",
-		"Destination namespace where the migrated data should be saved. By default, Porter migrates your data into the current namespace as configured by environment variables and your config file, otherwise the global namespace is used.")
+		"Destination namespace where the migrated data should be saved. By default, Porter migrates your data into the current namespace as configured by environment variables and your config file, otherwise the global namespace is used. When --old-home is not specified, only the runs in the namespace are migrated, defaults to all namespaces.")
+	flags.BoolVar(&opts.DryRun, "dry-run", false,
+		"Report the runs that would be migrated to the current schema without saving them. Cannot be used with --old-home.")
+	flags.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format when migrating runs to the current schema.  Allowed values: plaintext, json, yaml")
 	return cmd
 }
 
@@ -72,3 +89,134 @@ func buildStorageFixPermissionsCommand(p *porter.Porter) *cobra.Command {
 		},
 	}
 }
+
+func buildStorageSchemaCommand(p *porter.Porter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Inspect the schema of the data stored by Porter",
+	}
+
+	cmd.AddCommand(buildStorageSchemaShowCommand(p))
+	return cmd
+}
+
+func buildStorageSchemaShowCommand(p *porter.Porter) *cobra.Command {
+	var opts porter.StorageSchemaShowOptions
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the schema, size and indexes of the data stored by Porter",
+		Long: `Show the schema of the documents stored by Porter, and the number of documents, size and indexes of each collection in the storage backend.
+
+The schema recorded in the database is compared with the schema supported by this version of Porter, so this command works even when the database must be migrated with porter storage migrate.`,
+		Example: `  porter storage schema show
+  porter storage schema show --output json`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.ShowStorageSchema(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+	return cmd
+}
+
+func buildStorageRotateSecretsCommand(p *porter.Porter) *cobra.Command {
+	var opts porter.RotateSecretsOptions
+	cmd := &cobra.Command{
+		Use:   "rotate-secrets --to SECRETS_NAME [--from SECRETS_NAME]",
+		Short: "Copy sensitive installation data to a new secret store",
+		Long: `Copy the sensitive parameters and outputs that Porter saved to a secret store, for installations and their runs, to a new secret store.
+
+Use this command when the backing secret store changes, for example when moving to a new Vault mount or namespace. The secrets accounts are defined in the secrets section of the Porter config file.
+Each secret is created in the new secret store with the same key, so the installation data does not change. Secrets that were provided by users, for example with a parameter set, are not copied.
+
+The old secret store is not modified. After the secrets are rotated, set default-secrets in the config file to the new secrets account.
+If some secrets could not be rotated, the remaining secrets are still copied, and the command may be repeated.`,
+		Example: `  porter storage rotate-secrets --to new-vault
+  porter storage rotate-secrets --from old-vault --to new-vault
+  porter storage rotate-secrets --to new-vault --namespace dev --installation mysql`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.RotateSecrets(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.From, "from", "",
+		"Name of the secrets account in the Porter config file that currently stores the secrets. Defaults to the default secrets account.")
+	f.StringVar(&opts.To, "to", "",
+		"Name of the secrets account in the Porter config file that the secrets are copied to.")
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Only rotate the secrets of installations in the namespace. Defaults to all namespaces.")
+	f.StringVarP(&opts.Installation, "installation", "i", "",
+		"Only rotate the secrets of installations with this name.")
+	return cmd
+}
+
+func buildStorageCheckCommand(p *porter.Porter) *cobra.Command {
+	var opts porter.StorageCheckOptions
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check that the storage used by Porter is reachable",
+		Long: `Check that the default storage account, and the secondary storage account configured with storage-failover, can be reached.
+
+When the default storage account is unreachable and a secondary storage account is configured, Porter reads from the secondary storage account, and writes to it and queues the writes so that they are replayed to the default storage account when it is reachable again.
+This command reports the storage account in use and the number of queued writes, and replays the queued writes when the default storage account is reachable.
+The command fails when the default storage account is unreachable.`,
+		Example: `  porter storage check
+  porter storage check --output json`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.StorageCheck(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+	return cmd
+}
+
+func buildStorageFsckCommand(p *porter.Porter) *cobra.Command {
+	var opts porter.StorageFsckOptions
+	cmd := &cobra.Command{
+		Use:   "fsck",
+		Short: "Check and repair the installation data stored by Porter",
+		Long: `Check that the results and outputs of runs were saved completely, and that none of them are orphaned.
+
+A result is saved as pending until all of its outputs are saved. When Porter stops while saving a result, for example because it crashed, the result is left pending and the run history may be inconsistent.
+This command reports pending results, results without a run, outputs without a result, outputs that are missing part of their value, and chunks of output values without an output.
+
+Results that were created within the --grace-period may still be saving their outputs, so they are not checked until the grace period has passed.
+When --repair is specified, pending results are committed with a message that some outputs may be missing and applied to their installation, and the orphaned or incomplete documents are removed.
+The command fails when problems remain that were not repaired.`,
+		Example: `  porter storage fsck
+  porter storage fsck --repair
+  porter storage fsck --namespace dev --output json`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.StorageFsck(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&opts.Repair, "repair", false,
+		"Repair the problems that are found.")
+	f.DurationVar(&opts.PendingGracePeriod, "grace-period", storage.FsckPendingGracePeriod,
+		"How long after a pending result was created before it is considered interrupted.")
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Only check the installation data in the namespace. Defaults to all namespaces.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+	return cmd
+}
@@ -7,8 +7,11 @@ package main
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"go/build"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -103,6 +106,43 @@ func XBuildPorter() {
 	mg.Deps(copySchema)
 	releases.XBuildAll(PKG, "porter", "bin")
 	releases.PrepareMixinForPublish("porter")
+
+	info := releases.LoadMetadata()
+	mgx.Must(writeChecksums(filepath.Join("bin", info.Version)))
+}
+
+// writeChecksums saves the sha256 checksums of the porter binaries in the
+// directory to checksums.txt, in the format generated by sha256sum. The file
+// is published with the release, and porter build uses it to verify the
+// porter-runtime that is copied into bundles.
+func writeChecksums(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var checksums bytes.Buffer
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), "porter-") {
+			continue
+		}
+
+		f, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&checksums, "%s  %s\n", hex.EncodeToString(h.Sum(nil)), entry.Name())
+	}
+
+	checksumsPath := filepath.Join(dir, "checksums.txt")
+	log.Println("Writing", checksumsPath)
+	return os.WriteFile(checksumsPath, checksums.Bytes(), pkg.FileModeWritable)
 }
 
 // Cross-compile the exec mixin
@@ -0,0 +1,218 @@
+package archive
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// AlgorithmAES256GCM encrypts archives with AES-256-GCM.
+const AlgorithmAES256GCM = "AES-256-GCM"
+
+const (
+	// aesGCMNoncePrefixSize is the size of the random prefix of the nonce of
+	// each chunk. The remainder of the nonce is the chunk counter and a flag
+	// that marks the final chunk.
+	aesGCMNoncePrefixSize = 7
+)
+
+// aesGCMChunkSize is the size of the plaintext of each encrypted chunk.
+// Archives are encrypted in chunks so that they do not need to fit in memory.
+var aesGCMChunkSize = 64 * 1024
+
+func init() {
+	RegisterCipher(aesGCM{})
+}
+
+// aesGCM encrypts an archive with AES-256-GCM in chunks. The nonce of each
+// chunk includes its position, and a flag marking the last chunk, so that
+// chunks cannot be reordered, removed, or the archive truncated, without
+// decryption failing.
+//
+// The ciphertext is the random nonce prefix, followed by each chunk as its
+// length, a big endian uint32, and the sealed chunk.
+type aesGCM struct{}
+
+func (aesGCM) Algorithm() string {
+	return AlgorithmAES256GCM
+}
+
+func (aesGCM) KeySize() int {
+	return 32
+}
+
+func (c aesGCM) NewWriter(dst io.Writer, key []byte, additionalData []byte) (io.WriteCloser, error) {
+	gcm, err := c.newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &aesGCMWriter{
+		dst:            dst,
+		gcm:            gcm,
+		additionalData: additionalData,
+		buf:            make([]byte, 0, aesGCMChunkSize),
+	}
+	if _, err = io.ReadFull(rand.Reader, w.noncePrefix[:]); err != nil {
+		return nil, err
+	}
+	if _, err = dst.Write(w.noncePrefix[:]); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (c aesGCM) NewReader(src io.Reader, key []byte, additionalData []byte) (io.Reader, error) {
+	gcm, err := c.newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &aesGCMReader{
+		src:            bufio.NewReader(src),
+		gcm:            gcm,
+		additionalData: additionalData,
+	}
+	if _, err = io.ReadFull(r.src, r.noncePrefix[:]); err != nil {
+		return nil, fmt.Errorf("the encrypted archive is truncated: %w", err)
+	}
+	return r, nil
+}
+
+func (aesGCM) newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// aesGCMNonce returns the nonce of a chunk.
+func aesGCMNonce(prefix [aesGCMNoncePrefixSize]byte, counter uint32, final bool) []byte {
+	nonce := make([]byte, 0, aesGCMNoncePrefixSize+5)
+	nonce = append(nonce, prefix[:]...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if final {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+type aesGCMWriter struct {
+	dst            io.Writer
+	gcm            cipher.AEAD
+	additionalData []byte
+	noncePrefix    [aesGCMNoncePrefixSize]byte
+	counter        uint32
+	buf            []byte
+	closed         bool
+}
+
+func (w *aesGCMWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to a closed encrypted archive")
+	}
+
+	var n int
+	for len(p) > 0 {
+		// Only seal a full chunk once there is more data, so that the last
+		// chunk is always sealed as the final chunk when the writer is closed.
+		if len(w.buf) == cap(w.buf) {
+			if err := w.seal(false); err != nil {
+				return n, err
+			}
+		}
+
+		written := copy(w.buf[len(w.buf):cap(w.buf)], p)
+		w.buf = w.buf[:len(w.buf)+written]
+		p = p[written:]
+		n += written
+	}
+	return n, nil
+}
+
+// Close seals the final chunk. It does not close the destination.
+func (w *aesGCMWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.seal(true)
+}
+
+func (w *aesGCMWriter) seal(final bool) error {
+	if w.counter == ^uint32(0) {
+		return errors.New("the archive is too large to encrypt")
+	}
+
+	ciphertext := w.gcm.Seal(nil, aesGCMNonce(w.noncePrefix, w.counter, final), w.buf, w.additionalData)
+	w.counter++
+	w.buf = w.buf[:0]
+
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(ciphertext)))
+	if _, err := w.dst.Write(length[:]); err != nil {
+		return err
+	}
+	_, err := w.dst.Write(ciphertext)
+	return err
+}
+
+type aesGCMReader struct {
+	src            *bufio.Reader
+	gcm            cipher.AEAD
+	additionalData []byte
+	noncePrefix    [aesGCMNoncePrefixSize]byte
+	counter        uint32
+	plaintext      []byte
+	done           bool
+}
+
+func (r *aesGCMReader) Read(p []byte) (int, error) {
+	for len(r.plaintext) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.plaintext)
+	r.plaintext = r.plaintext[n:]
+	return n, nil
+}
+
+func (r *aesGCMReader) open() error {
+	var length [4]byte
+	if _, err := io.ReadFull(r.src, length[:]); err != nil {
+		return fmt.Errorf("the encrypted archive is truncated: %w", err)
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	if size < uint32(r.gcm.Overhead()) || size > uint32(aesGCMChunkSize+r.gcm.Overhead()) {
+		return errors.New("the encrypted archive is corrupt")
+	}
+
+	ciphertext := make([]byte, size)
+	if _, err := io.ReadFull(r.src, ciphertext); err != nil {
+		return fmt.Errorf("the encrypted archive is truncated: %w", err)
+	}
+
+	// The final chunk is the one at the end of the archive
+	_, err := r.src.Peek(1)
+	final := err == io.EOF
+
+	plaintext, err := r.gcm.Open(ciphertext[:0], aesGCMNonce(r.noncePrefix, r.counter, final), ciphertext, r.additionalData)
+	if err != nil {
+		return errors.New("could not decrypt the archive, either the key is incorrect or the archive was modified")
+	}
+	r.counter++
+	r.plaintext = plaintext
+	r.done = final
+	return nil
+}
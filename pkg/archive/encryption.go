@@ -0,0 +1,169 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	// KeyRefPrefix is the prefix of a reference to a key in the secret store,
+	// for example secret://archive-key.
+	KeyRefPrefix = "secret://"
+
+	// DefaultAlgorithm is the algorithm used to encrypt archives when one is
+	// not specified.
+	DefaultAlgorithm = AlgorithmAES256GCM
+
+	// encryptedArchiveVersion is the version of the encrypted archive format.
+	encryptedArchiveVersion = 1
+)
+
+// magic identifies an encrypted archive. It is followed by the header, on a
+// single line, and then the encrypted archive.
+var magic = []byte("porter-encrypted-archive\n")
+
+// ErrNotEncrypted indicates that an archive is not encrypted.
+var ErrNotEncrypted = errors.New("the archive is not encrypted")
+
+// Cipher encrypts and decrypts archives. Register additional algorithms with
+// RegisterCipher.
+type Cipher interface {
+	// Algorithm is the name of the algorithm, which is recorded in the header
+	// of the encrypted archive.
+	Algorithm() string
+
+	// KeySize is the size of the key in bytes.
+	KeySize() int
+
+	// NewWriter returns a writer that encrypts data written to it, and writes
+	// the ciphertext to dst. The writer must be closed to write the remaining
+	// ciphertext. The additional data is authenticated but not encrypted.
+	NewWriter(dst io.Writer, key []byte, additionalData []byte) (io.WriteCloser, error)
+
+	// NewReader returns a reader that decrypts the ciphertext read from src.
+	// Reads return an error when the ciphertext, or the additional data,
+	// was modified.
+	NewReader(src io.Reader, key []byte, additionalData []byte) (io.Reader, error)
+}
+
+var ciphers = map[string]Cipher{}
+
+// RegisterCipher makes an algorithm available for encrypting archives.
+func RegisterCipher(c Cipher) {
+	ciphers[c.Algorithm()] = c
+}
+
+// GetCipher returns the cipher registered for the algorithm.
+func GetCipher(algorithm string) (Cipher, error) {
+	c, ok := ciphers[algorithm]
+	if !ok {
+		algorithms := make([]string, 0, len(ciphers))
+		for name := range ciphers {
+			algorithms = append(algorithms, name)
+		}
+		sort.Strings(algorithms)
+		return nil, fmt.Errorf("unsupported archive encryption algorithm %q, the supported algorithms are: %s", algorithm, strings.Join(algorithms, ", "))
+	}
+	return c, nil
+}
+
+// Header describes how an archive was encrypted.
+type Header struct {
+	// Version of the encrypted archive format.
+	Version int `json:"version"`
+
+	// Algorithm used to encrypt the archive.
+	Algorithm string `json:"algorithm"`
+
+	// KeyRef is the reference to the key, in the secret store, that was used
+	// to encrypt the archive, for example secret://archive-key.
+	KeyRef string `json:"keyRef"`
+
+	// raw is the encoded header, which is authenticated with the archive.
+	raw []byte
+}
+
+// ParseKeyRef returns the name of the secret from a key reference of the
+// form secret://NAME.
+func ParseKeyRef(ref string) (string, error) {
+	name := strings.TrimPrefix(ref, KeyRefPrefix)
+	if name == ref || name == "" {
+		return "", fmt.Errorf("invalid key reference %q, the key must be a reference to a secret of the form %sNAME", ref, KeyRefPrefix)
+	}
+	return name, nil
+}
+
+// DecodeKey decodes the base64 encoded value of the secret that holds the key.
+func DecodeKey(c Cipher, ref string, value string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil || len(key) != c.KeySize() {
+		return nil, fmt.Errorf("the archive encryption key %s must be a base64 encoded %d-bit key, for example generated with: openssl rand -base64 %d", ref, c.KeySize()*8, c.KeySize())
+	}
+	return key, nil
+}
+
+// NewEncryptedWriter writes the header of an encrypted archive to dst, and
+// returns a writer that encrypts the archive written to it. The writer must be
+// closed to finish writing the encrypted archive.
+func NewEncryptedWriter(dst io.Writer, c Cipher, keyRef string, key []byte) (io.WriteCloser, error) {
+	header := Header{
+		Version:   encryptedArchiveVersion,
+		Algorithm: c.Algorithm(),
+		KeyRef:    keyRef,
+	}
+	raw, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	raw = append(raw, '\n')
+
+	if _, err = dst.Write(magic); err != nil {
+		return nil, err
+	}
+	if _, err = dst.Write(raw); err != nil {
+		return nil, err
+	}
+	return c.NewWriter(dst, key, raw)
+}
+
+// ReadHeader reads the header of an encrypted archive. ErrNotEncrypted is
+// returned, and nothing is read, when the archive is not encrypted.
+func ReadHeader(src *bufio.Reader) (Header, error) {
+	prefix, err := src.Peek(len(magic))
+	if err != nil || !bytes.Equal(prefix, magic) {
+		return Header{}, ErrNotEncrypted
+	}
+	src.Discard(len(magic))
+
+	raw, err := src.ReadBytes('\n')
+	if err != nil {
+		return Header{}, fmt.Errorf("error reading the header of the encrypted archive: %w", err)
+	}
+
+	var header Header
+	if err = json.Unmarshal(raw, &header); err != nil {
+		return Header{}, fmt.Errorf("error parsing the header of the encrypted archive: %w", err)
+	}
+	if header.Version != encryptedArchiveVersion {
+		return Header{}, fmt.Errorf("unsupported encrypted archive version %d, the archive may have been created by a newer version of Porter", header.Version)
+	}
+	header.raw = raw
+	return header, nil
+}
+
+// NewDecryptedReader returns a reader that decrypts the encrypted archive
+// following the header read from src.
+func NewDecryptedReader(src *bufio.Reader, header Header, key []byte) (io.Reader, error) {
+	c, err := GetCipher(header.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return c.NewReader(src, key, header.raw)
+}
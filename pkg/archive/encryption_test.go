@@ -0,0 +1,130 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encrypt(t *testing.T, key []byte, plaintext []byte) []byte {
+	c, err := GetCipher(DefaultAlgorithm)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := NewEncryptedWriter(&buf, c, "secret://archive-key", key)
+	require.NoError(t, err)
+	_, err = w.Write(plaintext)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func decrypt(key []byte, ciphertext []byte) (Header, []byte, error) {
+	src := bufio.NewReader(bytes.NewReader(ciphertext))
+	header, err := ReadHeader(src)
+	if err != nil {
+		return Header{}, nil, err
+	}
+
+	r, err := NewDecryptedReader(src, header, key)
+	if err != nil {
+		return header, nil, err
+	}
+	plaintext, err := io.ReadAll(r)
+	return header, plaintext, err
+}
+
+func newKey(t *testing.T) []byte {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return key
+}
+
+func TestEncryptedArchive(t *testing.T) {
+	// Use small chunks so that the archives are split into multiple chunks
+	defer func(size int) { aesGCMChunkSize = size }(aesGCMChunkSize)
+	aesGCMChunkSize = 16
+
+	key := newKey(t)
+
+	t.Run("round trip", func(t *testing.T) {
+		for _, size := range []int{0, 1, 16, 17, 100} {
+			plaintext := bytes.Repeat([]byte("a"), size)
+			header, got, err := decrypt(key, encrypt(t, key, plaintext))
+			require.NoError(t, err, "size %d", size)
+			assert.Equal(t, plaintext, got, "size %d", size)
+			assert.Equal(t, Header{Version: 1, Algorithm: AlgorithmAES256GCM, KeyRef: "secret://archive-key"}, Header{Version: header.Version, Algorithm: header.Algorithm, KeyRef: header.KeyRef})
+		}
+	})
+
+	t.Run("not encrypted", func(t *testing.T) {
+		src := bufio.NewReader(bytes.NewReader([]byte("not encrypted")))
+		_, err := ReadHeader(src)
+		require.ErrorIs(t, err, ErrNotEncrypted)
+
+		data, err := io.ReadAll(src)
+		require.NoError(t, err)
+		assert.Equal(t, "not encrypted", string(data), "nothing should be read from an archive that is not encrypted")
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		_, _, err := decrypt(newKey(t), encrypt(t, key, []byte("top secret")))
+		require.ErrorContains(t, err, "either the key is incorrect or the archive was modified")
+	})
+
+	t.Run("modified", func(t *testing.T) {
+		ciphertext := encrypt(t, key, bytes.Repeat([]byte("a"), 40))
+		ciphertext[len(ciphertext)-1] ^= 1
+		_, _, err := decrypt(key, ciphertext)
+		require.ErrorContains(t, err, "the archive was modified")
+	})
+
+	t.Run("modified header", func(t *testing.T) {
+		ciphertext := encrypt(t, key, []byte("top secret"))
+		ciphertext = bytes.Replace(ciphertext, []byte("archive-key"), []byte("archive-kez"), 1)
+		_, _, err := decrypt(key, ciphertext)
+		require.ErrorContains(t, err, "the archive was modified")
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		// Remove the final chunk, which holds the last 8 bytes
+		ciphertext := encrypt(t, key, bytes.Repeat([]byte("a"), 40))
+		ciphertext = ciphertext[:len(ciphertext)-(4+8+16)]
+		_, _, err := decrypt(key, ciphertext)
+		require.ErrorContains(t, err, "the archive was modified")
+	})
+}
+
+func TestParseKeyRef(t *testing.T) {
+	name, err := ParseKeyRef("secret://archive-key")
+	require.NoError(t, err)
+	assert.Equal(t, "archive-key", name)
+
+	for _, ref := range []string{"archive-key", "secret://", "env://ARCHIVE_KEY"} {
+		_, err = ParseKeyRef(ref)
+		require.ErrorContains(t, err, "invalid key reference", ref)
+	}
+}
+
+func TestDecodeKey(t *testing.T) {
+	c, err := GetCipher(AlgorithmAES256GCM)
+	require.NoError(t, err)
+
+	key := newKey(t)
+	got, err := DecodeKey(c, "secret://archive-key", base64.StdEncoding.EncodeToString(key)+"\n")
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+
+	_, err = DecodeKey(c, "secret://archive-key", base64.StdEncoding.EncodeToString(key[:16]))
+	require.EqualError(t, err, "the archive encryption key secret://archive-key must be a base64 encoded 256-bit key, for example generated with: openssl rand -base64 32")
+
+	_, err = GetCipher("age")
+	require.EqualError(t, err, `unsupported archive encryption algorithm "age", the supported algorithms are: AES-256-GCM`)
+}
@@ -0,0 +1,17 @@
+package plugins
+
+import "errors"
+
+const (
+	// PluginInterface for authorization. This first part of the
+	// three-part plugin key is only seen/used by the plugins when the host is
+	// communicating with the plugin and is not exposed to users.
+	PluginInterface = "authorization"
+
+	// PluginProtocolVersion is the currently supported plugin protocol version for authorization.
+	PluginProtocolVersion = 1
+)
+
+// ErrForbidden is the error to be returned by an authorization plugin when
+// the request is denied.
+var ErrForbidden = errors.New("forbidden")
@@ -0,0 +1,34 @@
+package plugins
+
+import "context"
+
+// AuthorizationProtocol is the interface that authorization plugins must
+// implement. This defines the protocol used to communicate with
+// authorization plugins.
+type AuthorizationProtocol interface {
+	// Authorize allows or denies a request to read or change the documents
+	// in a namespace. It returns an error wrapping ErrForbidden when the
+	// request is denied, and any other error when the request could not be
+	// evaluated.
+	Authorize(ctx context.Context, req AuthorizationRequest) error
+}
+
+// AuthorizationRequest describes an operation on the documents in a namespace.
+type AuthorizationRequest struct {
+	// Subject is the identity making the request.
+	Subject string
+
+	// Namespace of the documents.
+	Namespace string
+
+	// Verb is the action requested: read, write or delete.
+	Verb string
+
+	// Resource is the collection of the documents, such as installations,
+	// runs, results, outputs, credentials or parameters.
+	Resource string
+
+	// Name of the installation, credential set or parameter set, when the
+	// request is for a single document.
+	Name string
+}
@@ -0,0 +1,4 @@
+// Package plugins defines the protocol that authorization plugins implement,
+// so that an external policy service can allow or deny the requests that
+// Porter makes to its storage.
+package plugins
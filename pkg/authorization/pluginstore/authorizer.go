@@ -0,0 +1,129 @@
+package pluginstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"get.porter.sh/porter/pkg/authorization/plugins"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/failure"
+	"get.porter.sh/porter/pkg/plugins/pluggable"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+var _ storage.Authorizer = &Authorizer{}
+
+// Authorizer is a plugin-backed storage.Authorizer. It allows every request
+// when an authorization plugin is not configured.
+//
+// Connects just-in-time, but you must call Close to release resources.
+type Authorizer struct {
+	*config.Config
+
+	mu     sync.Mutex
+	plugin plugins.AuthorizationProtocol
+	conn   *pluggable.PluginConnection
+}
+
+func NewAuthorizer(c *config.Config) *Authorizer {
+	return &Authorizer{Config: c}
+}
+
+// NewAuthorizationPluginConfig for authorization plugins.
+func NewAuthorizationPluginConfig() pluggable.PluginTypeConfig {
+	return pluggable.PluginTypeConfig{
+		Interface: plugins.PluginInterface,
+		Plugin:    &Plugin{},
+		GetDefaultPluggable: func(c *config.Config) string {
+			if c.Data.Authorization.Plugin == "" {
+				return ""
+			}
+			return c.Data.Authorization.GetName()
+		},
+		GetPluggable: func(c *config.Config, name string) (pluggable.Entry, error) {
+			return c.Data.Authorization, nil
+		},
+		GetDefaultPlugin: func(c *config.Config) string {
+			return c.Data.Authorization.Plugin
+		},
+		ProtocolVersion: plugins.PluginProtocolVersion,
+	}
+}
+
+func (a *Authorizer) Authorize(ctx context.Context, req storage.AuthorizationRequest) error {
+	if a.Config == nil || a.Data.Authorization.Plugin == "" {
+		return nil
+	}
+
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := a.Connect(ctx); err != nil {
+		return span.Error(err)
+	}
+
+	if req.Subject == "" {
+		req.Subject = storage.NewConfigAuthorizer(a.Config).GetSubject()
+	}
+
+	err := a.plugin.Authorize(ctx, plugins.AuthorizationRequest{
+		Subject:   req.Subject,
+		Namespace: req.Namespace,
+		Verb:      string(req.Verb),
+		Resource:  req.Resource,
+		Name:      req.Name,
+	})
+	if errors.Is(err, plugins.ErrForbidden) {
+		return failure.PolicyDenied(storage.ErrForbidden{Request: req})
+	}
+	if err != nil {
+		return span.Error(fmt.Errorf("the authorization plugin could not authorize the request: %w", err))
+	}
+	return nil
+}
+
+// Connect initializes the plugin for use.
+// The plugin itself is responsible for ensuring it was called.
+// Close is called automatically when the plugin is used by Porter.
+func (a *Authorizer) Connect(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.plugin != nil {
+		return nil
+	}
+
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	l := pluggable.NewPluginLoader(a.Config)
+	conn, err := l.Load(ctx, NewAuthorizationPluginConfig())
+	if err != nil {
+		return span.Error(err)
+	}
+	a.conn = conn
+
+	plugin, ok := conn.GetClient().(plugins.AuthorizationProtocol)
+	if !ok {
+		conn.Close(ctx)
+		return span.Error(fmt.Errorf("the interface (%T) exposed by the %s plugin was not plugins.AuthorizationProtocol", conn.GetClient(), conn))
+	}
+	a.plugin = plugin
+
+	return nil
+}
+
+func (a *Authorizer) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.conn != nil {
+		a.conn.Close(context.Background())
+		a.conn = nil
+		a.plugin = nil
+	}
+	return nil
+}
@@ -0,0 +1,3 @@
+// Package pluginstore is an internal Porter package that authorizes storage
+// requests with an authorization plugin.
+package pluginstore
@@ -0,0 +1,72 @@
+package pluginstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"get.porter.sh/porter/pkg/authorization/plugins"
+	"get.porter.sh/porter/pkg/authorization/plugins/proto"
+)
+
+var _ plugins.AuthorizationProtocol = &GClient{}
+
+// GClient is a gRPC implementation of the authorization client.
+type GClient struct {
+	client proto.AuthorizationProtocolClient
+}
+
+func NewClient(client proto.AuthorizationProtocolClient) *GClient {
+	return &GClient{client}
+}
+
+func (m *GClient) Authorize(ctx context.Context, req plugins.AuthorizationRequest) error {
+	resp, err := m.client.Authorize(ctx, &proto.AuthorizeRequest{
+		Subject:   req.Subject,
+		Namespace: req.Namespace,
+		Verb:      req.Verb,
+		Resource:  req.Resource,
+		Name:      req.Name,
+	})
+	if err != nil {
+		return err
+	}
+	if !resp.Allowed {
+		if resp.Reason == "" {
+			return plugins.ErrForbidden
+		}
+		return fmt.Errorf("%s: %w", resp.Reason, plugins.ErrForbidden)
+	}
+	return nil
+}
+
+// GServer is a gRPC wrapper around an AuthorizationProtocol plugin
+type GServer struct {
+	impl plugins.AuthorizationProtocol
+	proto.UnsafeAuthorizationProtocolServer
+}
+
+func NewServer(impl plugins.AuthorizationProtocol) *GServer {
+	return &GServer{impl: impl}
+}
+
+func (m *GServer) Authorize(ctx context.Context, request *proto.AuthorizeRequest) (*proto.AuthorizeResponse, error) {
+	err := m.impl.Authorize(ctx, plugins.AuthorizationRequest{
+		Subject:   request.Subject,
+		Namespace: request.Namespace,
+		Verb:      request.Verb,
+		Resource:  request.Resource,
+		Name:      request.Name,
+	})
+	if errors.Is(err, plugins.ErrForbidden) {
+		resp := &proto.AuthorizeResponse{Allowed: false}
+		if err != plugins.ErrForbidden {
+			resp.Reason = err.Error()
+		}
+		return resp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &proto.AuthorizeResponse{Allowed: true}, nil
+}
@@ -0,0 +1,54 @@
+package pluginstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"get.porter.sh/porter/pkg/authorization/plugins"
+	"get.porter.sh/porter/pkg/authorization/plugins/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// serverClient calls the gRPC server directly, without a connection.
+type serverClient struct {
+	server *GServer
+}
+
+func (c serverClient) Authorize(ctx context.Context, in *proto.AuthorizeRequest, opts ...grpc.CallOption) (*proto.AuthorizeResponse, error) {
+	return c.server.Authorize(ctx, in)
+}
+
+type testAuthorizer func(req plugins.AuthorizationRequest) error
+
+func (a testAuthorizer) Authorize(ctx context.Context, req plugins.AuthorizationRequest) error {
+	return a(req)
+}
+
+func TestGClient_Authorize(t *testing.T) {
+	impl := testAuthorizer(func(req plugins.AuthorizationRequest) error {
+		if req.Namespace == "prod" {
+			return fmt.Errorf("%s may not %s %s in prod: %w", req.Subject, req.Verb, req.Resource, plugins.ErrForbidden)
+		}
+		if req.Namespace == "broken" {
+			return errors.New("policy service unavailable")
+		}
+		return nil
+	})
+	client := NewClient(serverClient{NewServer(impl)})
+	ctx := context.Background()
+
+	err := client.Authorize(ctx, plugins.AuthorizationRequest{Subject: "sally", Namespace: "dev", Verb: "read", Resource: "installations"})
+	require.NoError(t, err)
+
+	err = client.Authorize(ctx, plugins.AuthorizationRequest{Subject: "sally", Namespace: "prod", Verb: "write", Resource: "runs"})
+	require.ErrorIs(t, err, plugins.ErrForbidden)
+	assert.Contains(t, err.Error(), "sally may not write runs in prod")
+
+	err = client.Authorize(ctx, plugins.AuthorizationRequest{Subject: "sally", Namespace: "broken"})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, plugins.ErrForbidden, "errors from the plugin should not be treated as a denial")
+}
@@ -0,0 +1,32 @@
+package pluginstore
+
+import (
+	"context"
+
+	"get.porter.sh/porter/pkg/authorization/plugins"
+	"get.porter.sh/porter/pkg/authorization/plugins/proto"
+	"github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+)
+
+var _ plugin.GRPCPlugin = Plugin{}
+
+// Plugin is the shared implementation of an authorization plugin wrapper.
+type Plugin struct {
+	plugin.Plugin
+	impl plugins.AuthorizationProtocol
+}
+
+// NewPlugin creates an instance of an authorization plugin.
+func NewPlugin(impl plugins.AuthorizationProtocol) Plugin {
+	return Plugin{impl: impl}
+}
+
+func (p Plugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	proto.RegisterAuthorizationProtocolServer(s, NewServer(p.impl))
+	return nil
+}
+
+func (p Plugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, conn *grpc.ClientConn) (interface{}, error) {
+	return NewClient(proto.NewAuthorizationProtocolClient(conn)), nil
+}
@@ -40,6 +40,11 @@ var (
 	// PORTER_INIT_TOKEN controls where Porter's image initialization
 	// instructions are placed in the Dockerfile.
 	PORTER_INIT_TOKEN = "# PORTER_INIT"
+
+	// PORTER_TOOLS_TOKEN controls where the instructions that install the
+	// tools pinned in the manifest are placed in the Dockerfile. When it is not
+	// present, the tools are installed before the mixins.
+	PORTER_TOOLS_TOKEN = "# PORTER_TOOLS"
 )
 
 type Builder interface {
@@ -134,6 +134,12 @@ func (b *Builder) BuildInvocationImage(ctx context.Context, manifest *manifest.M
 	}
 
 	mode := progress.PrinterModeAuto // Auto writes to stderr regardless of what you pass in
+	profile := b.GetOutputProfile()
+	if profile.PlainProgress() {
+		mode = progress.PrinterModePlain
+	} else if !profile.Progress() {
+		mode = progress.PrinterModeQuiet
+	}
 	out := unstructuredLogger{log}
 	printer := progress.NewPrinter(ctx, out, os.Stderr, mode)
 	_, buildErr := buildx.Build(ctx, drivers, buildxOpts, dockerToBuildx{cli}, confutil.ConfigDir(cli), printer)
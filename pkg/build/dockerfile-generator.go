@@ -15,6 +15,7 @@ import (
 	"get.porter.sh/porter/pkg/mixin/query"
 	"get.porter.sh/porter/pkg/pkgmgmt"
 	"get.porter.sh/porter/pkg/templates"
+	"get.porter.sh/porter/pkg/tools"
 	"get.porter.sh/porter/pkg/tracing"
 )
 
@@ -269,15 +270,24 @@ func (g *DockerfileGenerator) replaceTokens(ctx context.Context, lines []string)
 		return nil, fmt.Errorf("error generating Dockerfile content for mixins: %w", err)
 	}
 
+	toolLines, err := tools.DockerfileLines(g.Manifest.Tools)
+	if err != nil {
+		return nil, fmt.Errorf("error generating Dockerfile content for tools: %w", err)
+	}
+
 	fromToken := g.getIndexOfToken(lines, "FROM")
 
 	substitutions := []struct {
 		token        string
 		lines        []string
 		defaultIndex int
+		// defaultToken is used instead of defaultIndex when it is set, and the
+		// lines are inserted before it when the token is not found
+		defaultToken string
 		replace      bool
 	}{
 		{token: PORTER_INIT_TOKEN, lines: g.buildInitSection(), defaultIndex: fromToken, replace: true},
+		{token: PORTER_TOOLS_TOKEN, lines: toolLines, defaultToken: PORTER_MIXINS_TOKEN, replace: true},
 		{token: PORTER_MIXINS_TOKEN, lines: mixinLines, defaultIndex: -1, replace: true},
 	}
 
@@ -287,6 +297,9 @@ func (g *DockerfileGenerator) replaceTokens(ctx context.Context, lines []string)
 		// If we can't find the token, use the default for that token
 		if index == -1 {
 			index = substitution.defaultIndex
+			if substitution.defaultToken != "" {
+				index = g.getIndexOfToken(lines, substitution.defaultToken)
+			}
 			substitution.replace = false
 		}
 
@@ -11,6 +11,7 @@ import (
 	"get.porter.sh/porter/pkg/mixin"
 	"get.porter.sh/porter/pkg/templates"
 	"get.porter.sh/porter/pkg/test"
+	"get.porter.sh/porter/pkg/tools"
 	"get.porter.sh/porter/tests"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -207,6 +208,34 @@ COPY mybin /cnab/app/
 	test.CompareGoldenFile(t, "testdata/missing-mixins-token-expected-output.Dockerfile", strings.Join(gotlines, "\n"))
 }
 
+func TestPorter_buildDockerfile_Tools(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	c.Data.BuildDriver = config.BuildDriverBuildkit
+	tmpl := templates.NewTemplates(c.Config)
+	configTpl, err := tmpl.GetManifest()
+	require.Nil(t, err)
+	c.TestContext.AddTestFileContents(configTpl, config.Name)
+
+	m, err := manifest.LoadManifestFrom(context.Background(), c.Config, config.Name)
+	require.NoError(t, err, "could not load manifest")
+	m.Tools = []tools.Pin{
+		{Name: "kubectl", Version: "v1.27.3", Checksums: map[string]string{
+			"amd64": "sha256:" + strings.Repeat("a", 64),
+			"arm64": "sha256:" + strings.Repeat("b", 64),
+		}},
+		{Name: "terraform", Version: "1.5.0"},
+	}
+
+	mp := mixin.NewTestMixinProvider()
+	g := NewDockerfileGenerator(c.Config, m, tmpl, mp)
+	gotlines, err := g.buildDockerfile(context.Background())
+	require.NoError(t, err)
+
+	test.CompareGoldenFile(t, "testdata/tools.Dockerfile", strings.Join(gotlines, "\n"))
+}
+
 func TestPorter_buildMixinsSection_mixinErr(t *testing.T) {
 	t.Parallel()
 
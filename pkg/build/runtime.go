@@ -0,0 +1,193 @@
+package build
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+const (
+	// RuntimeBinary is the name of the published porter binary that is copied
+	// into bundles as the porter-runtime.
+	RuntimeBinary = "porter-linux-amd64"
+
+	// RuntimeChecksumsFile is the name of the file published with each release
+	// of porter that contains the sha256 checksum of each binary.
+	RuntimeChecksumsFile = "checksums.txt"
+
+	// runtimeChecksumsTimeout is how long to wait for the published checksums.
+	runtimeChecksumsTimeout = 30 * time.Second
+)
+
+// ErrRuntimeChecksumMismatch is returned when the porter-runtime binary does
+// not match the checksum published for its version.
+var ErrRuntimeChecksumMismatch = errors.New("the porter-runtime binary does not match the published checksum")
+
+// RuntimeInfo describes the porter-runtime binary that is copied into a bundle.
+type RuntimeInfo struct {
+	// Version of porter that published the runtime.
+	Version string
+
+	// Digest of the runtime binary, for example sha256:abc123.
+	Digest string
+
+	// Verified indicates that the digest matched the checksum published for
+	// the version.
+	Verified bool
+}
+
+// GetRuntimePath returns the path to the porter-runtime binary that is copied into bundles.
+func GetRuntimePath(c *config.Config) (string, error) {
+	homeDir, err := c.GetHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, "runtimes", "porter-runtime"), nil
+}
+
+// GetRuntimeChecksumsURL returns the location of the checksums published for
+// a version of porter.
+func GetRuntimeChecksumsURL(mirror url.URL, version string) url.URL {
+	mirror.Path = path.Join(mirror.Path, version, RuntimeChecksumsFile)
+	return mirror
+}
+
+// GetRuntimeInfo calculates the digest of the porter-runtime binary that is
+// copied into bundles, without verifying it.
+func GetRuntimeInfo(c *config.Config) (RuntimeInfo, error) {
+	runtimePath, err := GetRuntimePath(c)
+	if err != nil {
+		return RuntimeInfo{}, err
+	}
+
+	digest, err := digestFile(c, runtimePath)
+	if err != nil {
+		return RuntimeInfo{}, err
+	}
+
+	return RuntimeInfo{Version: pkg.Version, Digest: "sha256:" + digest}, nil
+}
+
+// VerifyRuntime calculates the digest of the porter-runtime binary and
+// compares it to the checksum published for the current version of porter.
+//
+// When porter was not built for a release, or the checksums are not
+// published, the runtime is reported as unverified. An error is only
+// returned when the runtime cannot be read, or it does not match the
+// published checksum.
+func VerifyRuntime(ctx context.Context, c *config.Config, mirror url.URL) (RuntimeInfo, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	info, err := GetRuntimeInfo(c)
+	if err != nil {
+		return RuntimeInfo{}, span.Error(err)
+	}
+
+	if info.Version == "" {
+		span.Warn("Skipping verification of the porter-runtime because porter was not built for a release")
+		return info, nil
+	}
+
+	checksumsURL := GetRuntimeChecksumsURL(mirror, info.Version)
+	checksums, err := downloadChecksums(ctx, checksumsURL)
+	if err != nil {
+		span.Warnf("Skipping verification of the porter-runtime because the published checksums could not be retrieved: %s", err)
+		return info, nil
+	}
+
+	want, ok := checksums[RuntimeBinary]
+	if !ok {
+		span.Warnf("Skipping verification of the porter-runtime because %s does not contain a checksum for %s", checksumsURL.String(), RuntimeBinary)
+		return info, nil
+	}
+
+	digest := strings.TrimPrefix(info.Digest, "sha256:")
+	if !strings.EqualFold(want, digest) {
+		runtimePath, _ := GetRuntimePath(c)
+		return info, span.Error(fmt.Errorf("%w for porter %s: expected sha256:%s but %s has sha256:%s. Reinstall porter to restore the runtime",
+			ErrRuntimeChecksumMismatch, info.Version, want, runtimePath, digest))
+	}
+
+	span.Debugf("Verified the porter-runtime for porter %s against %s", info.Version, checksumsURL.String())
+	info.Verified = true
+	return info, nil
+}
+
+// digestFile returns the hex encoded sha256 checksum of a file.
+func digestFile(c *config.Config, path string) (string, error) {
+	f, err := c.FileSystem.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("could not open the porter-runtime at %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("could not read the porter-runtime at %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// downloadChecksums retrieves a published checksums file.
+func downloadChecksums(ctx context.Context, checksumsURL url.URL) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, checksumsURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating web request to %s: %w", checksumsURL.String(), err)
+	}
+	req.Header.Set("User-Agent", pkg.UserAgent())
+
+	client := &http.Client{Timeout: runtimeChecksumsTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading %s: %w", checksumsURL.String(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bad status returned when downloading %s (%d) %s", checksumsURL.String(), resp.StatusCode, resp.Status)
+	}
+
+	return ParseChecksums(resp.Body)
+}
+
+// ParseChecksums reads checksums in the format generated by sha256sum, with
+// one checksum and file name per line, and returns the checksums keyed by the
+// file name.
+func ParseChecksums(r io.Reader) (map[string]string, error) {
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid checksum on line %d: %q", lineNumber, line)
+		}
+
+		// sha256sum prefixes the file name with * when it was read in binary mode
+		name := strings.TrimPrefix(fields[1], "*")
+		checksums[name] = strings.ToLower(fields[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading checksums: %w", err)
+	}
+	return checksums, nil
+}
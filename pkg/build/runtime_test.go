@@ -0,0 +1,125 @@
+package build
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChecksums(t *testing.T) {
+	t.Parallel()
+
+	contents := `ABC123  porter-linux-amd64
+def456 *porter-windows-amd64.exe
+
+`
+	checksums, err := ParseChecksums(strings.NewReader(contents))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"porter-linux-amd64":       "abc123",
+		"porter-windows-amd64.exe": "def456",
+	}, checksums)
+
+	_, err = ParseChecksums(strings.NewReader("abc123"))
+	require.ErrorContains(t, err, `invalid checksum on line 1: "abc123"`)
+}
+
+func TestGetRuntimeChecksumsURL(t *testing.T) {
+	t.Parallel()
+
+	mirror, err := url.Parse("https://example.com/porter")
+	require.NoError(t, err)
+	u := GetRuntimeChecksumsURL(*mirror, "v1.2.3")
+	assert.Equal(t, "https://example.com/porter/v1.2.3/checksums.txt", u.String())
+}
+
+func TestGetRuntimeInfo(t *testing.T) {
+	// Do not run in parallel, the test modifies the version of porter
+	origVersion := pkg.Version
+	defer func() { pkg.Version = origVersion }()
+	pkg.Version = "v1.2.3"
+
+	runtime := []byte("porter runtime")
+	sum := sha256.Sum256(runtime)
+
+	c := config.NewTestConfig(t)
+	runtimePath, err := GetRuntimePath(c.Config)
+	require.NoError(t, err)
+	require.NoError(t, c.FileSystem.WriteFile(runtimePath, runtime, pkg.FileModeExecutable))
+
+	info, err := GetRuntimeInfo(c.Config)
+	require.NoError(t, err)
+	assert.Equal(t, RuntimeInfo{Version: "v1.2.3", Digest: "sha256:" + hex.EncodeToString(sum[:])}, info)
+}
+
+func TestVerifyRuntime(t *testing.T) {
+	// Do not run in parallel, the test modifies the version of porter
+
+	runtime := []byte("porter runtime")
+	sum := sha256.Sum256(runtime)
+	runtimeDigest := hex.EncodeToString(sum[:])
+
+	checksums := map[string]string{
+		"/v1.2.3/checksums.txt": runtimeDigest + "  porter-linux-amd64\n",
+		"/v1.2.4/checksums.txt": strings.Repeat("0", 64) + "  porter-linux-amd64\n",
+		"/v1.2.5/checksums.txt": runtimeDigest + "  porter-darwin-amd64\n",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contents, ok := checksums[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(contents))
+	}))
+	defer srv.Close()
+	mirror, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	origVersion := pkg.Version
+	defer func() { pkg.Version = origVersion }()
+
+	testcases := []struct {
+		name         string
+		version      string
+		wantVerified bool
+		wantErr      string
+	}{
+		{name: "verified", version: "v1.2.3", wantVerified: true},
+		{name: "checksum mismatch", version: "v1.2.4", wantErr: "does not match the published checksum"},
+		{name: "runtime not in checksums", version: "v1.2.5"},
+		{name: "checksums not published", version: "v1.2.6"},
+		{name: "development build", version: ""},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			pkg.Version = tc.version
+
+			c := config.NewTestConfig(t)
+			runtimePath, err := GetRuntimePath(c.Config)
+			require.NoError(t, err)
+			require.NoError(t, c.FileSystem.WriteFile(runtimePath, runtime, pkg.FileModeExecutable))
+
+			info, err := VerifyRuntime(context.Background(), c.Config, *mirror)
+			if tc.wantErr != "" {
+				require.ErrorIs(t, err, ErrRuntimeChecksumMismatch)
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, RuntimeInfo{Version: tc.version, Digest: "sha256:" + runtimeDigest, Verified: tc.wantVerified}, info)
+		})
+	}
+}
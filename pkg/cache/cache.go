@@ -11,6 +11,7 @@ import (
 	configadapter "get.porter.sh/porter/pkg/cnab/config-adapter"
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/encoding"
+	"get.porter.sh/porter/pkg/metrics"
 	"github.com/opencontainers/go-digest"
 )
 
@@ -18,6 +19,13 @@ type BundleCache interface {
 	FindBundle(tag cnab.OCIReference) (bun CachedBundle, found bool, err error)
 	StoreBundle(bundleRef cnab.BundleReference) (CachedBundle, error)
 	GetCacheDir() (string, error)
+
+	// ListBundles returns the bundles in the cache, sorted by their directory.
+	// Cache directories without metadata are returned without a reference.
+	ListBundles() ([]CachedBundle, error)
+
+	// RemoveBundle deletes a bundle from the cache.
+	RemoveBundle(cb CachedBundle) error
 }
 
 var _ BundleCache = &Cache{}
@@ -51,6 +59,7 @@ func (c *Cache) FindBundle(ref cnab.OCIReference) (CachedBundle, bool, error) {
 	if err != nil {
 		return CachedBundle{}, false, err
 	}
+	metrics.CountCacheLookup(found)
 	if !found {
 		return CachedBundle{}, false, nil
 	}
@@ -176,3 +185,68 @@ func (c *Cache) GetCacheDir() (string, error) {
 	}
 	return filepath.Join(home, "cache"), nil
 }
+
+// ListBundles returns the bundles in the cache, sorted by their directory.
+// Cache directories that do not have metadata, for example when they were
+// created by an older version of Porter, are returned without a reference
+// because they cannot be found by FindBundle.
+func (c *Cache) ListBundles() ([]CachedBundle, error) {
+	cacheDir, err := c.GetCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := c.FileSystem.DirExists(cacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to access the cache directory %s: %w", cacheDir, err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	entries, err := c.FileSystem.ReadDir(cacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the cache directory %s: %w", cacheDir, err)
+	}
+
+	bundles := make([]CachedBundle, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		cb := CachedBundle{cacheDir: filepath.Join(cacheDir, entry.Name())}
+		metaPath := cb.BuildMetadataPath()
+		metaExists, err := c.FileSystem.Exists(metaPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to access bundle metadata at %s: %w", metaPath, err)
+		}
+		if metaExists {
+			var meta Metadata
+			if err = encoding.UnmarshalFile(c.FileSystem, metaPath, &meta); err != nil {
+				return nil, fmt.Errorf("unable to parse cached bundle metadata at %s: %w", metaPath, err)
+			}
+			cb.Reference = meta.Reference
+			cb.Digest = meta.Digest
+
+			// A bundle that can't be read is still returned so that it can be removed
+			if _, err = cb.Load(c.Context); err != nil {
+				fmt.Fprintf(c.Err, "WARNING: could not load cached bundle %s: %s\n", cb.Reference, err)
+			}
+		}
+		bundles = append(bundles, cb)
+	}
+
+	return bundles, nil
+}
+
+// RemoveBundle deletes a bundle from the cache.
+func (c *Cache) RemoveBundle(cb CachedBundle) error {
+	if cb.cacheDir == "" {
+		return fmt.Errorf("the cache directory of bundle %s is not set", cb.Reference)
+	}
+	if err := c.FileSystem.RemoveAll(cb.cacheDir); err != nil {
+		return fmt.Errorf("unable to remove cached bundle %s at %s: %w", cb.Reference, cb.cacheDir, err)
+	}
+	return nil
+}
@@ -286,3 +286,32 @@ func TestCache_StoreBundle_Overwrite(t *testing.T) {
 	exists, _ = cfg.FileSystem.Exists(junkPath)
 	assert.False(t, exists, "the random file should have been deleted from the bundle cache")
 }
+
+func TestCache_ListBundles(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.NewTestConfig(t)
+	c := New(cfg.Config)
+
+	bundles, err := c.ListBundles()
+	require.NoError(t, err, "a missing cache directory should not be an error")
+	assert.Empty(t, bundles)
+
+	bun := cnab.NewBundle(bundle.Bundle{Name: "kubekahn", Version: "1.0.0"})
+	cb, err := c.StoreBundle(cnab.BundleReference{Reference: kahn1dot01, Definition: bun})
+	require.NoError(t, err)
+
+	bundles, err = c.ListBundles()
+	require.NoError(t, err)
+	require.Len(t, bundles, 1)
+	assert.Equal(t, kahn1dot01, bundles[0].Reference)
+	assert.Equal(t, "kubekahn", bundles[0].Definition.Name)
+
+	size, err := bundles[0].GetSize(cfg.Context)
+	require.NoError(t, err)
+	assert.Positive(t, size)
+
+	require.NoError(t, c.RemoveBundle(bundles[0]))
+	exists, _ := cfg.FileSystem.Exists(cb.GetCacheDir())
+	assert.False(t, exists, "the cache directory of the bundle should have been removed")
+}
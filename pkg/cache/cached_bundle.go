@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
 
 	"get.porter.sh/porter/pkg/cnab"
@@ -46,6 +47,29 @@ func (cb *CachedBundle) SetCacheDir(porterCacheDir string) {
 	cb.cacheDir = filepath.Join(porterCacheDir, cb.GetBundleID())
 }
 
+// GetCacheDir returns the bundle specific cache directory.
+func (cb *CachedBundle) GetCacheDir() string {
+	return cb.cacheDir
+}
+
+// GetSize returns the number of bytes used by the bundle in the cache.
+func (cb *CachedBundle) GetSize(cxt *portercontext.Context) (int64, error) {
+	var size int64
+	err := cxt.FileSystem.Walk(cb.cacheDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to determine the size of cached bundle %s at %s: %w", cb.Reference, cb.cacheDir, err)
+	}
+	return size, nil
+}
+
 // BuildBundlePath generates the potential location of the bundle.json, if it existed.
 func (cb *CachedBundle) BuildBundlePath() string {
 	return filepath.Join(cb.cacheDir, "cnab", "bundle.json")
@@ -36,3 +36,11 @@ func (c *TestCache) StoreBundle(bundleRef cnab.BundleReference) (CachedBundle, e
 func (c *TestCache) GetCacheDir() (string, error) {
 	return c.cache.GetCacheDir()
 }
+
+func (c *TestCache) ListBundles() ([]CachedBundle, error) {
+	return c.cache.ListBundles()
+}
+
+func (c *TestCache) RemoveBundle(cb CachedBundle) error {
+	return c.cache.RemoveBundle(cb)
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"get.porter.sh/porter/pkg/config"
 	"github.com/spf13/cobra"
@@ -12,14 +13,15 @@ import (
 
 // LoadHierarchicalConfig loads data with the following precedence:
 // * User set flag Flags (highest)
-// * Environment variables where --flag is assumed to be PORTER_FLAG
+// * Environment variables where --flag is assumed to be PORTER_FLAG,
+// and nested keys such as storage.devdb.url are PORTER_STORAGE_DEVDB_URL
 // * Config file
 // * Flag default (lowest)
 func LoadHierarchicalConfig(cmd *cobra.Command) config.DataStoreLoaderFunc {
-	return config.LoadFromViper(func(v *viper.Viper) {
+	return config.WithEnvironmentOverrides(config.LoadFromViper(func(v *viper.Viper) {
 		v.AutomaticEnv()
 		v.SetEnvPrefix("PORTER")
-		v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+		v.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
 
 		// Apply the configuration file value to the flag when the flag is not set
 		flags := cmd.Flags()
@@ -40,9 +42,12 @@ func LoadHierarchicalConfig(cmd *cobra.Command) config.DataStoreLoaderFunc {
 				// Apply viper to the flag
 				val := getFlagValue(v, viperKey)
 				flags.Set(f.Name, val)
+
+				// Only report flags as changed when they were specified on the command line
+				f.Changed = false
 			}
 		})
-	})
+	}))
 }
 
 func getFlagValue(v *viper.Viper, key string) string {
@@ -80,6 +85,11 @@ func getViperValue(flags *pflag.FlagSet, f *pflag.Flag) interface{} {
 		out, err = flags.GetStringSlice(f.Name)
 	case "stringArray":
 		out, err = flags.GetStringArray(f.Name)
+	case "duration":
+		// Store durations in their string representation, e.g. 1h30m, which is how they are defined in the config file
+		var d time.Duration
+		d, err = flags.GetDuration(f.Name)
+		out = d.String()
 	default:
 		panic(fmt.Errorf("unsupported type for conversion between flag %s and viper configuration: %T", f.Name, flagType))
 	}
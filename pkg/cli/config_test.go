@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
 	"get.porter.sh/porter/pkg/config"
 	"github.com/spf13/cobra"
@@ -156,3 +157,55 @@ func TestLoadHierarchicalConfig(t *testing.T) {
 		assert.Equal(t, "debug", c.Data.Verbosity, "config.Verbosity should have been set by the flag and not the env var or config")
 	})
 }
+
+func TestLoadHierarchicalConfig_Duration(t *testing.T) {
+	// Cannot be run in parallel because viper reads directly from env vars
+	buildCommand := func(c *config.Config, timeout *time.Duration) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().DurationVar(timeout, "timeout", 0, "timeout")
+		cmd.Flag("timeout").Annotations = map[string][]string{
+			"viper-key": {"runtime-timeout"},
+		}
+
+		cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+			_, err := c.Load(context.Background(), nil)
+			return err
+		}
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			return nil
+		}
+		c.DataLoader = LoadHierarchicalConfig(cmd)
+		return cmd
+	}
+
+	t.Run("timeout flag", func(t *testing.T) {
+		c := config.NewTestConfig(t)
+		c.SetHomeDir("/home/myuser/.porter")
+
+		var timeout time.Duration
+		cmd := buildCommand(c.Config, &timeout)
+		cmd.SetArgs([]string{"--timeout=1h30m"})
+		err := cmd.Execute()
+
+		require.NoError(t, err, "dataloader failed")
+		assert.Equal(t, 90*time.Minute, timeout, "the --timeout flag was not set correctly")
+		assert.True(t, cmd.Flags().Changed("timeout"), "the --timeout flag should be reported as changed when it is specified")
+		assert.Equal(t, "1h30m0s", c.Data.RuntimeTimeout, "config.RuntimeTimeout was not set correctly")
+	})
+
+	t.Run("timeout env var", func(t *testing.T) {
+		os.Setenv("PORTER_RUNTIME_TIMEOUT", "45s")
+		defer os.Unsetenv("PORTER_RUNTIME_TIMEOUT")
+
+		c := config.NewTestConfig(t)
+		c.SetHomeDir("/home/myuser/.porter")
+
+		var timeout time.Duration
+		cmd := buildCommand(c.Config, &timeout)
+		err := cmd.Execute()
+
+		require.NoError(t, err, "dataloader failed")
+		assert.Equal(t, 45*time.Second, timeout, "the --timeout flag was not set from the environment variable")
+		assert.False(t, cmd.Flags().Changed("timeout"), "the --timeout flag should not be reported as changed when it is set from the environment variable")
+	})
+}
@@ -1,5 +1,12 @@
 package cli
 
+import (
+	"errors"
+
+	"get.porter.sh/porter/pkg/failure"
+	"get.porter.sh/porter/pkg/printer"
+)
+
 const (
 	// ExitCodeSuccess indicates the program ran successfully.
 	ExitCodeSuccess = 0
@@ -9,4 +16,63 @@ const (
 
 	// ExitCodeInterrupt indicates the program was cancelled.
 	ExitCodeInterrupt = 2
+
+	// ExitCodePromptRequired indicates the program needed to prompt for input
+	// but prompts are disabled by the output profile.
+	ExitCodePromptRequired = 3
+
+	// ExitCodeValidation indicates the input to the program was invalid, and
+	// nothing was changed.
+	ExitCodeValidation = 4
+
+	// ExitCodeExecutionFailed indicates the bundle failed while it was run.
+	ExitCodeExecutionFailed = 5
+
+	// ExitCodePartialSuccess indicates the program changed some of the
+	// requested resources but failed to change the rest.
+	ExitCodePartialSuccess = 6
+
+	// ExitCodePolicyDenied indicates a policy in the configuration did not
+	// allow the operation.
+	ExitCodePolicyDenied = 7
+
+	// ExitCodeTimeout indicates an operation did not complete before its timeout.
+	ExitCodeTimeout = 8
+
+	// ExitCodeStorageUnavailable indicates the program could not connect to
+	// its storage.
+	ExitCodeStorageUnavailable = 9
 )
+
+// exitCodesByClass are the exit codes for each class of failure, in order of
+// precedence when an error has more than one class, e.g. a bundle that failed
+// because it timed out.
+var exitCodesByClass = []struct {
+	class failure.Class
+	code  int
+}{
+	{failure.ClassPolicyDenied, ExitCodePolicyDenied},
+	{failure.ClassTimeout, ExitCodeTimeout},
+	{failure.ClassStorageUnavailable, ExitCodeStorageUnavailable},
+	{failure.ClassValidation, ExitCodeValidation},
+	{failure.ClassPartialSuccess, ExitCodePartialSuccess},
+	{failure.ClassExecution, ExitCodeExecutionFailed},
+}
+
+// GetExitCode returns the exit code for the error returned by a command.
+func GetExitCode(err error) int {
+	if err == nil {
+		return ExitCodeSuccess
+	}
+
+	if errors.As(err, &printer.ErrPromptRequired{}) {
+		return ExitCodePromptRequired
+	}
+
+	for _, c := range exitCodesByClass {
+		if failure.Is(err, c.class) {
+			return c.code
+		}
+	}
+	return ExitCodeErr
+}
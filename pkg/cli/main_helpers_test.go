@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"get.porter.sh/porter/pkg/failure"
+	"get.porter.sh/porter/pkg/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExitCode(t *testing.T) {
+	testcases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: ExitCodeSuccess},
+		{name: "unclassified", err: errors.New("oops"), want: ExitCodeErr},
+		{name: "prompt required", err: fmt.Errorf("could not generate credentials: %w", printer.ErrPromptRequired{}), want: ExitCodePromptRequired},
+		{name: "validation", err: failure.Validation(errors.New("invalid --param")), want: ExitCodeValidation},
+		{name: "execution", err: failure.Execution(errors.New("the bundle failed")), want: ExitCodeExecutionFailed},
+		{name: "partial success", err: failure.PartialSuccess(errors.New("1 of 2 dependencies failed")), want: ExitCodePartialSuccess},
+		{name: "policy denied", err: failure.PolicyDenied(errors.New("denied")), want: ExitCodePolicyDenied},
+		{name: "timeout", err: fmt.Errorf("could not connect: %w", context.DeadlineExceeded), want: ExitCodeTimeout},
+		{name: "storage unavailable", err: failure.StorageUnavailable(errors.New("no mongo")), want: ExitCodeStorageUnavailable},
+		{name: "timed out bundle", err: failure.Execution(failure.Timeout(errors.New("the install action timed out"))), want: ExitCodeTimeout},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, GetExitCode(tc.err))
+		})
+	}
+}
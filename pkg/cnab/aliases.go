@@ -23,6 +23,11 @@ const (
 	StatusPending   = cnabclaims.StatusPending
 	StatusUnknown   = cnabclaims.StatusUnknown
 
+	// StatusTimedOut indicates that the bundle was stopped because it did not
+	// complete before the timeout. This is a Porter specific status that is
+	// not defined by the CNAB Spec.
+	StatusTimedOut = "timedout"
+
 	OutputInvocationImageLogs = cnabclaims.OutputInvocationImageLogs
 )
 
@@ -19,6 +19,7 @@ type TestRegistry struct {
 	MockListTags          func(ctx context.Context, ref cnab.OCIReference, opts RegistryOptions) ([]string, error)
 	MockPullImage         func(ctx context.Context, ref cnab.OCIReference, opts RegistryOptions) error
 	MockGetBundleMetadata func(ctx context.Context, ref cnab.OCIReference, opts RegistryOptions) (BundleMetadata, error)
+	MockGetImageMetadata  func(ctx context.Context, ref cnab.OCIReference, opts RegistryOptions) (ImageMetadata, error)
 	cache                 map[string]ImageSummary
 }
 
@@ -98,3 +99,11 @@ func (t TestRegistry) GetBundleMetadata(ctx context.Context, ref cnab.OCIReferen
 
 	return BundleMetadata{}, ErrNotFound{Reference: ref}
 }
+
+func (t TestRegistry) GetImageMetadata(ctx context.Context, ref cnab.OCIReference, opts RegistryOptions) (ImageMetadata, error) {
+	if t.MockGetImageMetadata != nil {
+		return t.MockGetImageMetadata(ctx, ref, opts)
+	}
+
+	return ImageMetadata{}, ErrNotFound{Reference: ref}
+}
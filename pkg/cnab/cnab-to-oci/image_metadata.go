@@ -0,0 +1,19 @@
+package cnabtooci
+
+import (
+	"get.porter.sh/porter/pkg/cnab"
+	"github.com/opencontainers/go-digest"
+)
+
+// ImageMetadata represents summary information about an image in a registry.
+type ImageMetadata struct {
+	// Reference to the image.
+	Reference cnab.OCIReference
+
+	// Digest of the image manifest.
+	Digest digest.Digest
+
+	// ConfigDigest is the digest of the image configuration, which is the
+	// image id used by Docker. It is empty when the reference is an image index.
+	ConfigDigest digest.Digest
+}
@@ -1,7 +1,9 @@
 package cnabtooci
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -20,6 +22,7 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/google/go-containerregistry/pkg/crane"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	"github.com/moby/term"
 	"github.com/opencontainers/go-digest"
@@ -147,6 +150,14 @@ func (r *Registry) PushBundle(ctx context.Context, bundleRef cnab.BundleReferenc
 	}
 	bundleRef.RelocationMap = rm
 
+	// Skip pushing the bundle when the destination already has the same bundle and relocation mapping.
+	// The images referenced by the bundle were already copied to the destination when needed by the fixup above.
+	if existingDigest, ok := r.isBundleUnchanged(ctx, bundleRef, resolver); ok {
+		bundleRef.Digest = existingDigest
+		log.Infof("Bundle %s is unchanged at the destination, skipping push, with digest %q\n", bundleRef.Reference, existingDigest)
+		return bundleRef, nil
+	}
+
 	d, err := remotes.Push(ctx, &bundleRef.Definition.Bundle, rm, bundleRef.Reference.Named, resolver, true)
 	if err != nil {
 		return cnab.BundleReference{}, log.Error(fmt.Errorf("error pushing the bundle to %s: %w", bundleRef.Reference, err))
@@ -157,6 +168,42 @@ func (r *Registry) PushBundle(ctx context.Context, bundleRef cnab.BundleReferenc
 	return bundleRef, nil
 }
 
+// isBundleUnchanged determines if the bundle at the destination is the same as
+// the bundle being pushed, returning the digest of the existing bundle.
+// Any error pulling the existing bundle means that it should be pushed.
+func (r *Registry) isBundleUnchanged(ctx context.Context, bundleRef cnab.BundleReference, resolver containerdRemotes.Resolver) (digest.Digest, bool) {
+	log := tracing.LoggerFromContext(ctx)
+
+	existing, existingReloMap, existingDigest, err := remotes.Pull(ctx, bundleRef.Reference.Named, resolver)
+	if err != nil {
+		log.Debugf("Could not compare with the existing bundle at %s: %s", bundleRef.Reference, err)
+		return "", false
+	}
+
+	existingData, err := json.Marshal(existing)
+	if err != nil {
+		return "", false
+	}
+	newData, err := json.Marshal(bundleRef.Definition.Bundle)
+	if err != nil {
+		return "", false
+	}
+	if !bytes.Equal(existingData, newData) {
+		return "", false
+	}
+
+	if len(existingReloMap) != len(bundleRef.RelocationMap) {
+		return "", false
+	}
+	for img, relocated := range bundleRef.RelocationMap {
+		if existingReloMap[img] != relocated {
+			return "", false
+		}
+	}
+
+	return existingDigest, true
+}
+
 // PushImage pushes the image from the Docker image cache to the specified location
 // the expected format of the image is REGISTRY/NAME:TAG.
 // Returns the image digest from the registry.
@@ -331,6 +378,35 @@ func (r *Registry) GetBundleMetadata(ctx context.Context, ref cnab.OCIReference,
 	}, nil
 }
 
+// GetImageMetadata returns information about an image in a registry
+// Use ErrNotFound to detect if the error is because the image is not in the registry.
+func (r *Registry) GetImageMetadata(ctx context.Context, ref cnab.OCIReference, opts RegistryOptions) (ImageMetadata, error) {
+	//lint:ignore SA4006 ignore unused context for now
+	ctx, span := tracing.StartSpan(ctx, attribute.String("reference", ref.String()))
+	defer span.EndSpan()
+
+	rawManifest, err := crane.Manifest(ref.String(), opts.toCraneOptions()...)
+	if err != nil {
+		if notFoundErr := asNotFoundError(err, ref); notFoundErr != nil {
+			return ImageMetadata{}, span.Error(notFoundErr)
+		}
+		return ImageMetadata{}, span.Errorf("error retrieving image metadata for %s: %w", ref.String(), err)
+	}
+
+	metadata := ImageMetadata{
+		Reference: ref,
+		Digest:    digest.FromBytes(rawManifest),
+	}
+
+	// Only an image manifest has a configuration, an image index does not
+	manifest, err := v1.ParseManifest(bytes.NewReader(rawManifest))
+	if err == nil && manifest.Config.Digest.Hex != "" {
+		metadata.ConfigDigest = digest.Digest(manifest.Config.Digest.String())
+	}
+
+	return metadata, nil
+}
+
 // asNotFoundError checks if the error is an HTTP 404 not found error, and if so returns a corresponding ErrNotFound instance.
 func asNotFoundError(err error, ref cnab.OCIReference) error {
 	var httpError *transport.Error
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"sort"
 	"strings"
 
 	"get.porter.sh/porter/pkg/cnab"
@@ -12,6 +13,7 @@ import (
 	"get.porter.sh/porter/pkg/experimental"
 	"get.porter.sh/porter/pkg/manifest"
 	"get.porter.sh/porter/pkg/mixin"
+	"get.porter.sh/porter/pkg/storage"
 	"get.porter.sh/porter/pkg/tracing"
 	"github.com/Masterminds/semver/v3"
 	"github.com/cnabio/cnab-go/bundle"
@@ -24,6 +26,10 @@ type ManifestConverter struct {
 	Manifest        *manifest.Manifest
 	ImageDigests    map[string]string
 	InstalledMixins []mixin.Metadata
+
+	// Runtime is the porter-runtime embedded in the invocation image, recorded
+	// in the porter stamp when set.
+	Runtime *RuntimeRecord
 }
 
 func NewManifestConverter(
@@ -81,7 +87,9 @@ func (c *ManifestConverter) ToBundle(ctx context.Context) (cnab.ExtendedBundle,
 
 	b.Custom[config.CustomPorterKey] = stamp
 
-	return b, nil
+	// Rebuild the bundle now that it is fully populated so that any
+	// precomputed lookups, such as parameter sensitivity, are up to date
+	return cnab.NewBundle(b.Bundle), nil
 }
 
 func (c *ManifestConverter) generateBundleMaintainers() []bundle.Maintainer {
@@ -353,6 +361,16 @@ func (c *ManifestConverter) buildDefaultPorterOutputs() []manifest.OutputDefinit
 				Comment:         cnab.PorterInternal,
 			},
 		},
+		{
+			Name: storage.StepResultsOutput,
+			Path: "/cnab/app/outputs/" + storage.StepResultsOutput,
+			Schema: definition.Schema{
+				ID:          "https://getporter.org/generated-bundle/#porter-step-results",
+				Description: "The results of each step executed by the bundle. Porter internal output that should not be set manually.",
+				Type:        "string",
+				Comment:     cnab.PorterInternal,
+			},
+		},
 	}
 }
 
@@ -436,8 +454,9 @@ func (c *ManifestConverter) generateDependenciesV1() (*depsv1.Dependencies, erro
 
 	for _, dep := range c.Manifest.Dependencies.Requires {
 		dependencyRef := depsv1.Dependency{
-			Name:   dep.Name,
-			Bundle: dep.Bundle.Reference,
+			Name:      dep.Name,
+			Bundle:    dep.Bundle.Reference,
+			DependsOn: dep.DependsOn,
 		}
 		if len(dep.Bundle.Version) > 0 {
 			dependencyRef.Version = &depsv1.DependencyVersion{
@@ -473,7 +492,9 @@ func (c *ManifestConverter) generateParameterSources(b *cnab.ExtendedBundle) cna
 		}
 
 		var pso cnab.ParameterSource
-		if p.Source.Dependency == "" {
+		if p.Source.Installation != "" {
+			pso = c.generateInstallationOutputParameterSource(p.Source)
+		} else if p.Source.Dependency == "" {
 			pso = c.generateOutputParameterSource(p.Source.Output)
 		} else {
 			ref := manifest.DependencyOutputReference{
@@ -592,6 +613,21 @@ func (c *ManifestConverter) generateDependencyOutputParameterSource(ref manifest
 	}
 }
 
+// generateInstallationOutputParameterSource builds a parameter source that connects the output of another installation to a parameter.
+func (c *ManifestConverter) generateInstallationOutputParameterSource(source manifest.ParameterSource) cnab.ParameterSource {
+	namespace, installation := source.GetInstallationReference()
+	return cnab.ParameterSource{
+		Priority: []string{cnab.ParameterSourceTypeInstallationOutput},
+		Sources: map[string]cnab.ParameterSourceDefinition{
+			cnab.ParameterSourceTypeInstallationOutput: cnab.InstallationOutputParameterSource{
+				Namespace:    namespace,
+				Installation: installation,
+				OutputName:   source.Output,
+			},
+		},
+	}
+}
+
 func toBool(value bool) *bool {
 	return &value
 }
@@ -614,6 +650,17 @@ func (c *ManifestConverter) generateCustomExtensions(b *cnab.ExtendedBundle) (ma
 		customExtensions[key] = value
 	}
 
+	// Add entries for user-specified required extensions, like docker
+	for _, ext := range c.Manifest.Required {
+		customExtensions[lookupExtensionKey(ext.Name)] = ext.Config
+	}
+
+	// Add the default configuration for the docker extension when it is
+	// detected from the mixins used by the bundle, instead of declared
+	if _, declared := customExtensions[cnab.DockerExtensionKey]; !declared && c.requiresDockerHost() {
+		customExtensions[cnab.DockerExtensionKey] = cnab.Docker{}
+	}
+
 	// Add the dependency extension
 	deps, depsExtKey, err := c.generateDependencies()
 	if err != nil {
@@ -629,35 +676,100 @@ func (c *ManifestConverter) generateCustomExtensions(b *cnab.ExtendedBundle) (ma
 		customExtensions[cnab.ParameterSourcesExtensionKey] = ps
 	}
 
-	// Add entries for user-specified required extensions, like docker
-	for _, ext := range c.Manifest.Required {
-		customExtensions[lookupExtensionKey(ext.Name)] = ext.Config
+	// Record the minimum versions of porter and mixins required by the bundle
+	constraints := c.Manifest.Constraints.ToBundleConstraints()
+	if !constraints.IsEmpty() {
+		customExtensions[cnab.ConstraintsExtensionKey] = constraints
+	}
+
+	// Record the driver that the bundle prefers, and the drivers that can run it
+	driver := c.Manifest.Driver.ToBundleDriverRequirements()
+	if !driver.IsEmpty() {
+		customExtensions[cnab.DriverExtensionKey] = driver
+	}
+
+	// Record the outputs that should never be persisted
+	ephemeralOutputs := c.generateEphemeralOutputs()
+	if len(ephemeralOutputs) > 0 {
+		customExtensions[cnab.EphemeralOutputsExtensionKey] = ephemeralOutputs
+	}
+
+	// Record the environment that was applied when the bundle was built
+	if c.Manifest.Environment != "" {
+		customExtensions[cnab.EnvironmentExtensionKey] = c.Manifest.Environment
 	}
 
 	return customExtensions, nil
 }
 
+// generateEphemeralOutputs returns the sorted names of the outputs that are
+// printed after the bundle runs but are never persisted.
+func (c *ManifestConverter) generateEphemeralOutputs() cnab.EphemeralOutputs {
+	var outputs cnab.EphemeralOutputs
+	for _, output := range c.Manifest.Outputs {
+		if output.Ephemeral {
+			outputs = append(outputs, output.Name)
+		}
+	}
+	sort.Strings(outputs)
+	return outputs
+}
+
+// generateRequiredExtensions returns the extensions that a runtime must
+// support to run the bundle. Extensions are detected from how the manifest
+// uses them, followed by any extensions declared in the required section of
+// the manifest. Each extension is only listed once.
 func (c *ManifestConverter) generateRequiredExtensions(b cnab.ExtendedBundle) []string {
 	requiredExtensions := []string{cnab.FileParameterExtensionKey}
+	appendExtension := func(key string) {
+		for _, existing := range requiredExtensions {
+			if existing == key {
+				return
+			}
+		}
+		requiredExtensions = append(requiredExtensions, key)
+	}
 
 	// Add the appropriate dependencies key if applicable
 	if b.HasDependenciesV1() {
-		requiredExtensions = append(requiredExtensions, cnab.DependenciesV1ExtensionKey)
+		appendExtension(cnab.DependenciesV1ExtensionKey)
 	}
 
 	// Add the appropriate parameter sources key if applicable
 	if b.HasParameterSources() {
-		requiredExtensions = append(requiredExtensions, cnab.ParameterSourcesExtensionKey)
+		appendExtension(cnab.ParameterSourcesExtensionKey)
+	}
+
+	// Require access to the docker host when a mixin uses it
+	if c.requiresDockerHost() {
+		appendExtension(cnab.DockerExtensionKey)
 	}
 
 	// Add all under required section of manifest
 	for _, ext := range c.Manifest.Required {
-		requiredExtensions = append(requiredExtensions, lookupExtensionKey(ext.Name))
+		appendExtension(lookupExtensionKey(ext.Name))
 	}
 
 	return requiredExtensions
 }
 
+// dockerHostMixins are mixins that connect to the Docker daemon, and can only
+// be used when the bundle is run with access to the Docker host.
+var dockerHostMixins = []string{"docker", "docker-compose"}
+
+// requiresDockerHost determines if the bundle uses a mixin that requires
+// access to the Docker host.
+func (c *ManifestConverter) requiresDockerHost() bool {
+	for _, m := range c.Manifest.Mixins {
+		for _, name := range dockerHostMixins {
+			if m.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // lookupExtensionKey is a helper method to return a full key matching a
 // supported extension, if applicable
 func lookupExtensionKey(name string) string {
@@ -87,7 +87,7 @@ func TestManifestConverter_ToBundle(t *testing.T) {
 
 	assert.True(t, bun.HasDependenciesV1(), "DependenciesV1 was not populated")
 
-	assert.Len(t, bun.Outputs, 1, "expected one output for the bundle state")
+	assert.Len(t, bun.Outputs, 2, "expected outputs for the bundle state and step results")
 }
 
 func TestManifestConverter_generateBundleCredentials(t *testing.T) {
@@ -309,6 +309,24 @@ func TestManifestConverter_generateBundleParametersSchema(t *testing.T) {
 	}
 }
 
+func TestManifestConverter_ToBundle_SensitiveParameters(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	c.TestContext.AddTestFile("testdata/porter-with-parameters.yaml", config.Name)
+
+	ctx := context.Background()
+	m, err := manifest.LoadManifestFrom(ctx, c.Config, config.Name)
+	require.NoError(t, err, "could not load manifest")
+
+	a := NewManifestConverter(c.Config, m, nil, nil)
+
+	bun, err := a.ToBundle(ctx)
+	require.NoError(t, err, "ToBundle failed")
+	assert.True(t, bun.IsSensitiveParameter("sensitive"), "the sensitivity of the parameters should reflect the generated bundle")
+	assert.False(t, bun.IsSensitiveParameter("porter-debug"))
+}
+
 func TestManifestConverter_buildDefaultPorterParameters(t *testing.T) {
 	t.Parallel()
 
@@ -460,7 +478,7 @@ func TestManifestConverter_generateBundleOutputs(t *testing.T) {
 
 	defs := make(definition.Definitions, len(a.Manifest.Outputs))
 	outputs := a.generateBundleOutputs(ctx, &defs)
-	require.Len(t, defs, 6)
+	require.Len(t, defs, 7)
 
 	wantOutputDefinitions := map[string]bundle.Output{
 		"output1": {
@@ -495,6 +513,11 @@ func TestManifestConverter_generateBundleOutputs(t *testing.T) {
 			Definition:  "porter-state",
 			Path:        "/cnab/app/outputs/porter-state",
 		},
+		"porter-step-results": {
+			Description: "The results of each step executed by the bundle. Porter internal output that should not be set manually.",
+			Definition:  "porter-step-results-output",
+			Path:        "/cnab/app/outputs/porter-step-results",
+		},
 	}
 
 	require.Equal(t, wantOutputDefinitions, outputs)
@@ -528,6 +551,12 @@ func TestManifestConverter_generateBundleOutputs(t *testing.T) {
 			Type:            "string",
 			ContentEncoding: "base64",
 		},
+		"porter-step-results-output": &definition.Schema{
+			ID:          "https://getporter.org/generated-bundle/#porter-step-results",
+			Comment:     "porter-internal",
+			Description: "The results of each step executed by the bundle. Porter internal output that should not be set manually.",
+			Type:        "string",
+		},
 	}
 
 	require.Equal(t, wantDefinitions, defs)
@@ -560,6 +589,7 @@ func TestManifestConverter_generateDependencies(t *testing.T) {
 					"1.x - 2,2.1 - 3.x",
 				},
 			},
+			DependsOn: []string{"mysql"},
 		}},
 	}
 
@@ -644,6 +674,28 @@ func TestManifestConverter_generateParameterSources(t *testing.T) {
 	assert.Equal(t, want, sources)
 }
 
+func TestManifestConverter_generateParameterSources_InstallationOutput(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	m := &manifest.Manifest{
+		Parameters: manifest.ParameterDefinitions{
+			"db-connstr":    {Name: "db-connstr", Source: manifest.ParameterSource{Installation: "platform/mysql", Output: "connstr"}},
+			"cache-connstr": {Name: "cache-connstr", Source: manifest.ParameterSource{Installation: "redis", Output: "connstr"}},
+		},
+	}
+	a := NewManifestConverter(c.Config, m, nil, nil)
+
+	b := cnab.NewBundle(bundle.Bundle{Definitions: definition.Definitions{}})
+	sources := a.generateParameterSources(&b)
+
+	want := cnab.ParameterSources{}
+	want.SetParameterFromInstallationOutput("db-connstr", "platform", "mysql", "connstr")
+	want.SetParameterFromInstallationOutput("cache-connstr", "", "redis", "connstr")
+	want.SetParameterFromOutput("porter-state", "porter-state")
+	assert.Equal(t, want, sources)
+}
+
 func TestNewManifestConverter_generateOutputWiringParameter(t *testing.T) {
 	t.Parallel()
 
@@ -761,6 +813,101 @@ func TestManifestConverter_generateRequiredExtensions(t *testing.T) {
 	assert.Equal(t, expected, bun.RequiredExtensions)
 }
 
+func TestManifestConverter_generateRequiredExtensions_DockerHost(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name         string
+		mixins       []manifest.MixinDeclaration
+		required     []manifest.RequiredExtension
+		wantRequired bool
+		wantDocker   interface{}
+	}{
+		{
+			name:   "no docker mixin",
+			mixins: []manifest.MixinDeclaration{{Name: "exec"}},
+		},
+		{
+			name:         "detected from the docker mixin",
+			mixins:       []manifest.MixinDeclaration{{Name: "exec"}, {Name: "docker"}},
+			wantRequired: true,
+			wantDocker:   cnab.Docker{},
+		},
+		{
+			name:         "detected from the docker-compose mixin",
+			mixins:       []manifest.MixinDeclaration{{Name: "docker-compose"}},
+			wantRequired: true,
+			wantDocker:   cnab.Docker{},
+		},
+		{
+			name:         "detected and declared",
+			mixins:       []manifest.MixinDeclaration{{Name: "docker"}},
+			required:     []manifest.RequiredExtension{{Name: "docker", Config: map[string]interface{}{"privileged": true}}},
+			wantRequired: true,
+			wantDocker:   map[string]interface{}{"privileged": true},
+		},
+		{
+			name:         "declared without a docker mixin",
+			mixins:       []manifest.MixinDeclaration{{Name: "exec"}},
+			required:     []manifest.RequiredExtension{{Name: cnab.DockerExtensionKey}},
+			wantRequired: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := config.NewTestConfig(t)
+			m := &manifest.Manifest{Mixins: tc.mixins, Required: tc.required}
+			a := NewManifestConverter(c.Config, m, nil, nil)
+
+			bun, err := a.ToBundle(context.Background())
+			require.NoError(t, err, "ToBundle failed")
+			if tc.wantRequired {
+				assert.Contains(t, bun.RequiredExtensions, cnab.DockerExtensionKey)
+			} else {
+				assert.NotContains(t, bun.RequiredExtensions, cnab.DockerExtensionKey)
+				assert.NotContains(t, bun.Custom, cnab.DockerExtensionKey)
+			}
+			if tc.wantDocker != nil {
+				assert.Equal(t, tc.wantDocker, bun.Custom[cnab.DockerExtensionKey])
+			}
+
+			_, err = bun.ProcessRequiredExtensions()
+			require.NoError(t, err, "the bundle should only require extensions supported by porter")
+		})
+	}
+}
+
+func TestManifestConverter_generateRequiredExtensions_DeclaredAndDetected(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	c.TestContext.AddTestFile("testdata/porter-with-deps.yaml", config.Name)
+
+	ctx := context.Background()
+	m, err := manifest.LoadManifestFrom(ctx, c.Config, config.Name)
+	require.NoError(t, err, "could not load manifest")
+	m.Required = append(m.Required, manifest.RequiredExtension{Name: "dependencies"})
+
+	a := NewManifestConverter(c.Config, m, nil, nil)
+
+	bun, err := a.ToBundle(ctx)
+	require.NoError(t, err, "ToBundle failed")
+
+	var deps []string
+	for _, ext := range bun.RequiredExtensions {
+		if ext == cnab.DependenciesV1ExtensionKey {
+			deps = append(deps, ext)
+		}
+	}
+	assert.Len(t, deps, 1, "an extension that is declared and detected should only be required once")
+	assert.True(t, bun.HasDependenciesV1(), "declaring the extension should not replace the generated dependencies")
+	_, err = bun.ReadDependenciesV1()
+	require.NoError(t, err)
+}
+
 func TestManifestConverter_generateCustomExtensions_withRequired(t *testing.T) {
 	t.Parallel()
 
@@ -781,6 +928,41 @@ func TestManifestConverter_generateCustomExtensions_withRequired(t *testing.T) {
 	assert.Equal(t, map[string]interface{}{"config": true}, bun.Custom["requiredExtension2"])
 }
 
+func TestManifestConverter_generateEphemeralOutputs(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	m := &manifest.Manifest{
+		Outputs: manifest.OutputDefinitions{
+			"token":      {Name: "token", Ephemeral: true},
+			"kubeconfig": {Name: "kubeconfig"},
+			"password":   {Name: "password", Ephemeral: true, Sensitive: true},
+		},
+	}
+	a := NewManifestConverter(c.Config, m, nil, nil)
+
+	outputs := a.generateEphemeralOutputs()
+	assert.Equal(t, cnab.EphemeralOutputs{"password", "token"}, outputs)
+}
+
+func TestManifestConverter_generateCustomExtensions_Environment(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	m := &manifest.Manifest{
+		Environment: "prod",
+		Environments: map[string]manifest.Environment{
+			"prod": {Registry: "example.com/prod"},
+		},
+	}
+	a := NewManifestConverter(c.Config, m, nil, nil)
+
+	b := cnab.NewBundle(bundle.Bundle{})
+	exts, err := a.generateCustomExtensions(&b)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", exts[cnab.EnvironmentExtensionKey])
+}
+
 func TestManifestConverter_GenerateCustomActionDefinitions(t *testing.T) {
 	t.Parallel()
 
@@ -924,3 +1106,18 @@ func getMaintainerByName(source []bundle.Maintainer, name string) (bundle.Mainta
 	}
 	return bundle.Maintainer{}, fmt.Errorf("Could not find maintainer with name '%s'", name)
 }
+
+func TestManifestConverter_generateCustomExtensions_Driver(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	m := &manifest.Manifest{
+		Driver: manifest.DriverRequirements{Default: "kubernetes", Supported: []string{"kubernetes"}},
+	}
+	a := NewManifestConverter(c.Config, m, nil, nil)
+
+	b := cnab.NewBundle(bundle.Bundle{})
+	exts, err := a.generateCustomExtensions(&b)
+	require.NoError(t, err)
+	assert.Equal(t, cnab.DriverRequirements{Default: "kubernetes", Supported: []string{"kubernetes"}}, exts[cnab.DriverExtensionKey])
+}
@@ -36,6 +36,12 @@ type Stamp struct {
 	// Version and commit define the version of the Porter used when a bundle was built.
 	Version string `json:"version"`
 	Commit  string `json:"commit"`
+
+	// Runtime is the porter-runtime binary embedded in the invocation image.
+	Runtime *RuntimeRecord `json:"runtime,omitempty"`
+
+	// Tools installed into the invocation image at a pinned version.
+	Tools map[string]ToolRecord `json:"tools,omitempty"`
 }
 
 // DecodeManifest base64 decodes the manifest stored in the stamp
@@ -73,6 +79,28 @@ type MixinRecord struct {
 	Version string `json:"version"`
 }
 
+// RuntimeRecord contains information about the porter-runtime binary embedded
+// in a bundle's invocation image, so that audits can attest which runtime
+// executes inside the bundle.
+type RuntimeRecord struct {
+	// Version of porter that published the runtime.
+	Version string `json:"version"`
+
+	// Digest of the runtime binary, for example sha256:abc123.
+	Digest string `json:"digest"`
+}
+
+// ToolRecord contains information about a tool that was installed into the
+// invocation image at a pinned version.
+type ToolRecord struct {
+	// Version of the tool.
+	Version string `json:"version"`
+
+	// Checksums of the tool's download, keyed by architecture, when they
+	// were pinned.
+	Checksums map[string]string `json:"checksums,omitempty"`
+}
+
 func (c *ManifestConverter) GenerateStamp(ctx context.Context) (Stamp, error) {
 	log := tracing.LoggerFromContext(ctx)
 
@@ -105,6 +133,17 @@ func (c *ManifestConverter) GenerateStamp(ctx context.Context) (Stamp, error) {
 
 	stamp.Version = pkg.Version
 	stamp.Commit = pkg.Commit
+	stamp.Runtime = c.Runtime
+
+	if len(c.Manifest.Tools) > 0 {
+		stamp.Tools = make(map[string]ToolRecord, len(c.Manifest.Tools))
+		for _, tool := range c.Manifest.Tools {
+			stamp.Tools[tool.Name] = ToolRecord{
+				Version:   tool.Version,
+				Checksums: tool.Checksums,
+			}
+		}
+	}
 
 	return stamp, nil
 }
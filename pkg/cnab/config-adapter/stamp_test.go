@@ -1,6 +1,7 @@
 package configadapter
 
 import (
+	"bytes"
 	"context"
 	"testing"
 
@@ -10,6 +11,7 @@ import (
 	"get.porter.sh/porter/pkg/manifest"
 	"get.porter.sh/porter/pkg/mixin"
 	"get.porter.sh/porter/pkg/pkgmgmt"
+	"get.porter.sh/porter/pkg/tools"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -69,6 +71,62 @@ func TestConfig_LoadStamp(t *testing.T) {
 	assert.Equal(t, "abc123", stamp.EncodedManifest)
 }
 
+func TestConfig_GenerateStamp_Runtime(t *testing.T) {
+	c := config.NewTestConfig(t)
+	c.TestContext.AddTestFileFromRoot("pkg/manifest/testdata/simple.porter.yaml", config.Name)
+
+	ctx := context.Background()
+	m, err := manifest.LoadManifestFrom(ctx, c.Config, config.Name)
+	require.NoError(t, err, "could not load manifest")
+
+	a := NewManifestConverter(c.Config, m, nil, nil)
+	stamp, err := a.GenerateStamp(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, stamp.Runtime, "expected the runtime to be omitted when it is not specified")
+
+	runtime := &RuntimeRecord{Version: "v1.2.3", Digest: "sha256:abc123"}
+	a.Runtime = runtime
+	bun, err := a.ToBundle(ctx)
+	require.NoError(t, err)
+
+	// Round trip the stamp through json to check that the runtime is persisted in the bundle
+	var buf bytes.Buffer
+	_, err = bun.WriteTo(&buf)
+	require.NoError(t, err)
+	roundTripped, err := bundle.Unmarshal(buf.Bytes())
+	require.NoError(t, err)
+
+	stamp, err = LoadStamp(cnab.NewBundle(*roundTripped))
+	require.NoError(t, err)
+	assert.Equal(t, runtime, stamp.Runtime)
+}
+
+func TestConfig_GenerateStamp_Tools(t *testing.T) {
+	c := config.NewTestConfig(t)
+	c.TestContext.AddTestFileFromRoot("pkg/manifest/testdata/simple.porter.yaml", config.Name)
+
+	ctx := context.Background()
+	m, err := manifest.LoadManifestFrom(ctx, c.Config, config.Name)
+	require.NoError(t, err, "could not load manifest")
+
+	a := NewManifestConverter(c.Config, m, nil, nil)
+	stamp, err := a.GenerateStamp(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, stamp.Tools, "expected the tools to be omitted when none are pinned")
+
+	m.Tools = []tools.Pin{
+		{Name: "helm", Version: "v3.12.0", Checksums: map[string]string{"amd64": "sha256:abc123"}},
+		{Name: "kubectl", Version: "v1.27.3"},
+	}
+	stamp, err = a.GenerateStamp(ctx)
+	require.NoError(t, err)
+	wantTools := map[string]ToolRecord{
+		"helm":    {Version: "v3.12.0", Checksums: map[string]string{"amd64": "sha256:abc123"}},
+		"kubectl": {Version: "v1.27.3"},
+	}
+	assert.Equal(t, wantTools, stamp.Tools)
+}
+
 func TestConfig_LoadStamp_Invalid(t *testing.T) {
 	t.Parallel()
 
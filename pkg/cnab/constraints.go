@@ -0,0 +1,150 @@
+package cnab
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	// ConstraintsExtensionShortHand is the short suffix of the ConstraintsExtensionKey.
+	ConstraintsExtensionShortHand = "constraints"
+
+	// ConstraintsExtensionKey represents the full key for the Constraints extension.
+	// It is stored in the custom section of a bundle so that consumers of the
+	// bundle can determine which versions of Porter and its mixins are required.
+	ConstraintsExtensionKey = PorterExtensionsPrefix + ConstraintsExtensionShortHand
+)
+
+// Constraints describes the minimum versions of Porter and mixins, and the
+// extensions, that a bundle requires.
+type Constraints struct {
+	// Porter is the minimum version of Porter required to use the bundle.
+	Porter string `json:"porter,omitempty"`
+
+	// Mixins is a map of mixin names to the minimum version of the mixin
+	// required to build the bundle.
+	Mixins map[string]string `json:"mixins,omitempty"`
+
+	// Extensions is a list of extensions that Porter must support in order to
+	// use the bundle.
+	Extensions []string `json:"extensions,omitempty"`
+}
+
+// IsEmpty indicates if no constraints were declared.
+func (c Constraints) IsEmpty() bool {
+	return c.Porter == "" && len(c.Mixins) == 0 && len(c.Extensions) == 0
+}
+
+// HasConstraints returns whether the bundle declares version constraints.
+func (b ExtendedBundle) HasConstraints() bool {
+	_, ok := b.Custom[ConstraintsExtensionKey]
+	return ok
+}
+
+// ReadConstraints reads the constraints declared in the custom section of
+// the bundle. When no constraints are declared, an empty Constraints is returned.
+func (b ExtendedBundle) ReadConstraints() (Constraints, error) {
+	var c Constraints
+
+	data, ok := b.Custom[ConstraintsExtensionKey]
+	if !ok {
+		return c, nil
+	}
+
+	dataB, err := json.Marshal(data)
+	if err != nil {
+		return c, fmt.Errorf("could not marshal the untyped %q extension data %q: %w",
+			ConstraintsExtensionKey, string(dataB), err)
+	}
+
+	err = json.Unmarshal(dataB, &c)
+	if err != nil {
+		return c, fmt.Errorf("could not unmarshal the %q extension %q: %w",
+			ConstraintsExtensionKey, string(dataB), err)
+	}
+
+	return c, nil
+}
+
+// ValidateConstraints checks that the specified version of Porter satisfies
+// the constraints declared by the bundle.
+func (b ExtendedBundle) ValidateConstraints(porterVersion string) error {
+	c, err := b.ReadConstraints()
+	if err != nil {
+		return err
+	}
+
+	if err := c.ValidatePorterVersion(porterVersion); err != nil {
+		return err
+	}
+
+	return c.ValidateExtensions()
+}
+
+// ValidatePorterVersion checks that the specified version of Porter is at
+// least the minimum version required. Development builds of Porter, which do
+// not have a valid semantic version, always satisfy the constraint.
+func (c Constraints) ValidatePorterVersion(porterVersion string) error {
+	return validateMinimumVersion("porter", c.Porter, porterVersion)
+}
+
+// ValidateMixinVersion checks that the specified version of a mixin is at least
+// the minimum version required by the bundle.
+func (c Constraints) ValidateMixinVersion(mixin string, mixinVersion string) error {
+	minimum, ok := c.Mixins[mixin]
+	if !ok {
+		return nil
+	}
+	return validateMinimumVersion(fmt.Sprintf("the %s mixin", mixin), minimum, mixinVersion)
+}
+
+// ValidateExtensions checks that Porter supports all the extensions required
+// by the bundle.
+func (c Constraints) ValidateExtensions() error {
+	for _, ext := range c.Extensions {
+		if _, err := GetSupportedExtension(ext); err != nil {
+			return fmt.Errorf("the bundle requires the %s extension which is not supported by this version of porter, upgrade porter to a version that supports it", ext)
+		}
+	}
+	return nil
+}
+
+// GetMixinNames returns the names of the mixins with a declared constraint,
+// sorted alphabetically.
+func (c Constraints) GetMixinNames() []string {
+	names := make([]string, 0, len(c.Mixins))
+	for name := range c.Mixins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func validateMinimumVersion(name string, minimum string, current string) error {
+	if minimum == "" {
+		return nil
+	}
+
+	minimumV, err := semver.NewVersion(minimum)
+	if err != nil {
+		return fmt.Errorf("invalid minimum version %q declared for %s: %w", minimum, name, err)
+	}
+
+	// Development builds do not have a proper version, so we can't enforce a minimum
+	currentV, err := semver.NewVersion(current)
+	if err != nil {
+		return nil
+	}
+
+	// Compare without the prerelease so that release candidates of the minimum version are accepted
+	currentRelease, _ := currentV.SetPrerelease("")
+	if currentRelease.LessThan(minimumV) {
+		return fmt.Errorf("the bundle requires %s >= %s but %s is installed, upgrade %s to >= %s",
+			name, minimumV.Original(), currentV.Original(), name, minimumV.Original())
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package cnab
+
+import (
+	"testing"
+
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtendedBundle_ReadConstraints(t *testing.T) {
+	t.Parallel()
+
+	t.Run("constraints present", func(t *testing.T) {
+		b := NewBundle(bundle.Bundle{
+			Custom: map[string]interface{}{
+				ConstraintsExtensionKey: map[string]interface{}{
+					"porter": "v1.0.0",
+					"mixins": map[string]interface{}{"helm3": "v0.1.0"},
+				},
+			},
+		})
+
+		require.True(t, b.HasConstraints())
+		c, err := b.ReadConstraints()
+		require.NoError(t, err)
+		assert.Equal(t, Constraints{Porter: "v1.0.0", Mixins: map[string]string{"helm3": "v0.1.0"}}, c)
+	})
+
+	t.Run("constraints missing", func(t *testing.T) {
+		b := NewBundle(bundle.Bundle{})
+
+		require.False(t, b.HasConstraints())
+		c, err := b.ReadConstraints()
+		require.NoError(t, err)
+		assert.True(t, c.IsEmpty())
+	})
+}
+
+func TestConstraints_ValidatePorterVersion(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name      string
+		minimum   string
+		current   string
+		wantError string
+	}{
+		{name: "no constraint", minimum: "", current: "v1.0.0"},
+		{name: "same version", minimum: "v1.0.0", current: "v1.0.0"},
+		{name: "newer version", minimum: "v1.0.0", current: "v1.2.3"},
+		{name: "prerelease of minimum", minimum: "v1.0.0", current: "v1.0.0-rc.1"},
+		{name: "dev build", minimum: "v1.0.0", current: ""},
+		{name: "older version", minimum: "v1.2.0", current: "v1.1.9", wantError: "the bundle requires porter >= v1.2.0 but v1.1.9 is installed, upgrade porter to >= v1.2.0"},
+		{name: "invalid constraint", minimum: "oops", current: "v1.0.0", wantError: `invalid minimum version "oops" declared for porter`},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := Constraints{Porter: tc.minimum}
+			err := c.ValidatePorterVersion(tc.current)
+			if tc.wantError == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.wantError)
+			}
+		})
+	}
+}
+
+func TestConstraints_ValidateMixinVersion(t *testing.T) {
+	t.Parallel()
+
+	c := Constraints{Mixins: map[string]string{"helm3": "v0.2.0"}}
+
+	require.NoError(t, c.ValidateMixinVersion("exec", "v0.1.0"), "mixins without a constraint should be allowed")
+	require.NoError(t, c.ValidateMixinVersion("helm3", "v0.2.1"))
+	err := c.ValidateMixinVersion("helm3", "v0.1.0")
+	require.EqualError(t, err, "the bundle requires the helm3 mixin >= v0.2.0 but v0.1.0 is installed, upgrade the helm3 mixin to >= v0.2.0")
+}
+
+func TestExtendedBundle_ValidateConstraints(t *testing.T) {
+	t.Parallel()
+
+	b := NewBundle(bundle.Bundle{
+		Custom: map[string]interface{}{
+			ConstraintsExtensionKey: Constraints{
+				Porter:     "v1.0.0",
+				Extensions: []string{DockerExtensionKey, "io.cnab.unknown"},
+			},
+		},
+	})
+
+	err := b.ValidateConstraints("v0.38.0")
+	require.ErrorContains(t, err, "upgrade porter to >= v1.0.0")
+
+	err = b.ValidateConstraints("v1.0.0")
+	require.ErrorContains(t, err, "the bundle requires the io.cnab.unknown extension")
+}
@@ -39,6 +39,9 @@ type Dependency struct {
 
 	// Version is a set of allowed versions
 	Version *DependencyVersion `json:"version,omitempty" mapstructure:"version"`
+
+	// DependsOn are the names of the dependencies that must be executed before this dependency
+	DependsOn []string `json:"dependsOn,omitempty" mapstructure:"dependsOn"`
 }
 
 // DependencyVersion is a set of allowed versions for a dependency
@@ -0,0 +1,91 @@
+package cnab
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// DriverExtensionShortHand is the short suffix of the DriverExtensionKey.
+	DriverExtensionShortHand = "driver"
+
+	// DriverExtensionKey represents the full key for the Driver extension.
+	// It is stored in the custom section of a bundle so that Porter can select
+	// the driver used to run the bundle, and check that the driver is supported.
+	DriverExtensionKey = PorterExtensionsPrefix + DriverExtensionShortHand
+
+	// debugDriver is the name of the debug driver, which prints the operation
+	// instead of running the bundle, so it is compatible with every bundle.
+	debugDriver = "debug"
+)
+
+// DriverRequirements describes the driver that a bundle prefers, and the
+// drivers that are able to run it.
+type DriverRequirements struct {
+	// Default is the driver used to run the bundle when a driver is not specified.
+	Default string `json:"default,omitempty"`
+
+	// Supported is the list of drivers that can run the bundle. When empty,
+	// the bundle can be run with any driver.
+	Supported []string `json:"supported,omitempty"`
+}
+
+// IsEmpty indicates if no driver requirements were declared.
+func (d DriverRequirements) IsEmpty() bool {
+	return d.Default == "" && len(d.Supported) == 0
+}
+
+// IsSupported indicates if the bundle can be run with the specified driver.
+func (d DriverRequirements) IsSupported(driver string) bool {
+	if len(d.Supported) == 0 || driver == debugDriver {
+		return true
+	}
+
+	for _, supported := range d.Supported {
+		if normalizeDriverName(supported) == normalizeDriverName(driver) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasDriverRequirements returns whether the bundle declares driver requirements.
+func (b ExtendedBundle) HasDriverRequirements() bool {
+	_, ok := b.Custom[DriverExtensionKey]
+	return ok
+}
+
+// ReadDriverRequirements reads the driver requirements declared in the custom
+// section of the bundle. When no requirements are declared, an empty
+// DriverRequirements is returned.
+func (b ExtendedBundle) ReadDriverRequirements() (DriverRequirements, error) {
+	var d DriverRequirements
+
+	data, ok := b.Custom[DriverExtensionKey]
+	if !ok {
+		return d, nil
+	}
+
+	dataB, err := json.Marshal(data)
+	if err != nil {
+		return d, fmt.Errorf("could not marshal the untyped %q extension data %q: %w",
+			DriverExtensionKey, string(dataB), err)
+	}
+
+	err = json.Unmarshal(dataB, &d)
+	if err != nil {
+		return d, fmt.Errorf("could not unmarshal the %q extension %q: %w",
+			DriverExtensionKey, string(dataB), err)
+	}
+
+	return d, nil
+}
+
+// normalizeDriverName resolves aliases of the driver names, so that k8s and
+// kubernetes are treated as the same driver.
+func normalizeDriverName(driver string) string {
+	if driver == "k8s" {
+		return "kubernetes"
+	}
+	return driver
+}
@@ -0,0 +1,50 @@
+package cnab
+
+import (
+	"testing"
+
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtendedBundle_ReadDriverRequirements(t *testing.T) {
+	t.Parallel()
+
+	t.Run("requirements present", func(t *testing.T) {
+		b := NewBundle(bundle.Bundle{
+			Custom: map[string]interface{}{
+				DriverExtensionKey: map[string]interface{}{
+					"default":   "kubernetes",
+					"supported": []interface{}{"kubernetes"},
+				},
+			},
+		})
+
+		require.True(t, b.HasDriverRequirements())
+		d, err := b.ReadDriverRequirements()
+		require.NoError(t, err)
+		assert.Equal(t, DriverRequirements{Default: "kubernetes", Supported: []string{"kubernetes"}}, d)
+	})
+
+	t.Run("requirements missing", func(t *testing.T) {
+		b := NewBundle(bundle.Bundle{})
+
+		require.False(t, b.HasDriverRequirements())
+		d, err := b.ReadDriverRequirements()
+		require.NoError(t, err)
+		assert.True(t, d.IsEmpty())
+	})
+}
+
+func TestDriverRequirements_IsSupported(t *testing.T) {
+	t.Parallel()
+
+	d := DriverRequirements{Supported: []string{"kubernetes"}}
+	assert.True(t, d.IsSupported("kubernetes"))
+	assert.False(t, d.IsSupported("docker"))
+	assert.True(t, d.IsSupported("k8s"), "aliases of a supported driver should be supported")
+	assert.True(t, d.IsSupported("debug"), "the debug driver does not run the bundle and should always be supported")
+
+	assert.True(t, DriverRequirements{Default: "kubernetes"}.IsSupported("docker"), "any driver is supported when the supported drivers are not declared")
+}
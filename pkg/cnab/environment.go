@@ -0,0 +1,19 @@
+package cnab
+
+const (
+	// EnvironmentExtensionShortHand is the short suffix of the EnvironmentExtensionKey.
+	EnvironmentExtensionShortHand = "environment"
+
+	// EnvironmentExtensionKey represents the full key for the Environment extension.
+	// It is stored in the custom section of a bundle and records the name of the
+	// environment from the manifest that was applied when the bundle was built.
+	EnvironmentExtensionKey = PorterExtensionsPrefix + EnvironmentExtensionShortHand
+)
+
+// GetEnvironment returns the name of the environment that was applied when the
+// bundle was built, or an empty string when the bundle was not built for a
+// specific environment.
+func (b ExtendedBundle) GetEnvironment() string {
+	env, _ := b.Custom[EnvironmentExtensionKey].(string)
+	return env
+}
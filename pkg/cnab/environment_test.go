@@ -0,0 +1,26 @@
+package cnab
+
+import (
+	"testing"
+
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtendedBundle_GetEnvironment(t *testing.T) {
+	t.Parallel()
+
+	t.Run("environment present", func(t *testing.T) {
+		b := NewBundle(bundle.Bundle{
+			Custom: map[string]interface{}{
+				EnvironmentExtensionKey: "prod",
+			},
+		})
+		assert.Equal(t, "prod", b.GetEnvironment())
+	})
+
+	t.Run("environment missing", func(t *testing.T) {
+		b := NewBundle(bundle.Bundle{})
+		assert.Empty(t, b.GetEnvironment())
+	})
+}
@@ -0,0 +1,70 @@
+package cnab
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// EphemeralOutputsExtensionShortHand is the short suffix of the EphemeralOutputsExtensionKey.
+	EphemeralOutputsExtensionShortHand = "ephemeral-outputs"
+
+	// EphemeralOutputsExtensionKey represents the full key for the Ephemeral Outputs extension.
+	// It is stored in the custom section of a bundle and lists the outputs
+	// that are printed after the bundle runs but are never persisted.
+	EphemeralOutputsExtensionKey = PorterExtensionsPrefix + EphemeralOutputsExtensionShortHand
+)
+
+// EphemeralOutputs is the list of output names that should never be persisted.
+type EphemeralOutputs []string
+
+// HasEphemeralOutputs returns whether the bundle declares any ephemeral outputs.
+func (b ExtendedBundle) HasEphemeralOutputs() bool {
+	_, ok := b.Custom[EphemeralOutputsExtensionKey]
+	return ok
+}
+
+// ReadEphemeralOutputs reads the ephemeral outputs declared in the custom
+// section of the bundle.
+func (b ExtendedBundle) ReadEphemeralOutputs() (EphemeralOutputs, error) {
+	data, ok := b.Custom[EphemeralOutputsExtensionKey]
+	if !ok {
+		return nil, nil
+	}
+
+	dataB, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal the untyped %q extension data %q: %w",
+			EphemeralOutputsExtensionKey, string(dataB), err)
+	}
+
+	var outputs EphemeralOutputs
+	err = json.Unmarshal(dataB, &outputs)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal the %q extension %q: %w",
+			EphemeralOutputsExtensionKey, string(dataB), err)
+	}
+
+	return outputs, nil
+}
+
+// IsEphemeralOutput determines if the bundle declares that the output should
+// never be persisted. Bundles with an invalid extension are treated as
+// declaring no ephemeral outputs.
+func (b ExtendedBundle) IsEphemeralOutput(output string) bool {
+	outputs, err := b.ReadEphemeralOutputs()
+	if err != nil {
+		return false
+	}
+	return outputs.Contains(output)
+}
+
+// Contains determines if the output is in the list.
+func (o EphemeralOutputs) Contains(output string) bool {
+	for _, name := range o {
+		if name == output {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package cnab
+
+import (
+	"testing"
+
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtendedBundle_ReadEphemeralOutputs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ephemeral outputs present", func(t *testing.T) {
+		b := NewBundle(bundle.Bundle{
+			Custom: map[string]interface{}{
+				EphemeralOutputsExtensionKey: []interface{}{"bootstrap-token"},
+			},
+		})
+
+		require.True(t, b.HasEphemeralOutputs())
+		outputs, err := b.ReadEphemeralOutputs()
+		require.NoError(t, err)
+		assert.Equal(t, EphemeralOutputs{"bootstrap-token"}, outputs)
+		assert.True(t, b.IsEphemeralOutput("bootstrap-token"))
+		assert.False(t, b.IsEphemeralOutput("kubeconfig"))
+	})
+
+	t.Run("ephemeral outputs missing", func(t *testing.T) {
+		b := NewBundle(bundle.Bundle{})
+
+		require.False(t, b.HasEphemeralOutputs())
+		outputs, err := b.ReadEphemeralOutputs()
+		require.NoError(t, err)
+		assert.Empty(t, outputs)
+		assert.False(t, b.IsEphemeralOutput("bootstrap-token"))
+	})
+
+	t.Run("invalid extension", func(t *testing.T) {
+		b := NewBundle(bundle.Bundle{
+			Custom: map[string]interface{}{
+				EphemeralOutputsExtensionKey: map[string]interface{}{"bootstrap-token": true},
+			},
+		})
+
+		_, err := b.ReadEphemeralOutputs()
+		require.ErrorContains(t, err, "could not unmarshal the \"sh.porter.ephemeral-outputs\" extension")
+		assert.False(t, b.IsEphemeralOutput("bootstrap-token"))
+	})
+}
@@ -21,11 +21,38 @@ var DefaultSchemaVersion = semver.MustParse(string(BundleSchemaVersion()))
 // allowing quick type-safe access to custom extensions from the CNAB spec.
 type ExtendedBundle struct {
 	bundle.Bundle
+
+	// sensitiveParameters is an index of the parameters that are sensitive,
+	// so that we don't look up the parameter's definition on every check.
+	// It is populated by NewBundle, and is nil when the ExtendedBundle is
+	// created directly, in which case the definitions are checked instead.
+	sensitiveParameters map[string]bool
 }
 
 // NewBundle creates an ExtendedBundle from a given bundle.
+// The bundle should not be modified afterwards, otherwise the precomputed
+// parameter sensitivity may be out of date.
 func NewBundle(bundle bundle.Bundle) ExtendedBundle {
-	return ExtendedBundle{bundle}
+	return ExtendedBundle{
+		Bundle:              bundle,
+		sensitiveParameters: buildSensitiveParameterIndex(bundle),
+	}
+}
+
+// buildSensitiveParameterIndex determines which parameters in the bundle are sensitive.
+func buildSensitiveParameterIndex(b bundle.Bundle) map[string]bool {
+	index := make(map[string]bool, len(b.Parameters))
+	for name, param := range b.Parameters {
+		if isSensitiveDefinition(b.Definitions[param.Definition]) {
+			index[name] = true
+		}
+	}
+	return index
+}
+
+// isSensitiveDefinition determines if the definition is for a sensitive value.
+func isSensitiveDefinition(def *definition.Schema) bool {
+	return def != nil && def.WriteOnly != nil && *def.WriteOnly
 }
 
 // LoadBundle from the specified filepath.
@@ -95,14 +122,37 @@ func (b ExtendedBundle) IsInternalOutput(name string) bool {
 
 // IsSensitiveParameter determines if the parameter contains a sensitive value.
 func (b ExtendedBundle) IsSensitiveParameter(param string) bool {
+	if b.sensitiveParameters != nil {
+		return b.sensitiveParameters[param]
+	}
+
 	if param, exists := b.Parameters[param]; exists {
-		if def, exists := b.Definitions[param.Definition]; exists {
-			return def.WriteOnly != nil && *def.WriteOnly
-		}
+		return isSensitiveDefinition(b.Definitions[param.Definition])
 	}
 	return false
 }
 
+// WithSensitiveParameters returns a copy of the bundle that also treats the
+// specified parameters as sensitive, for example when the parameter's value
+// was resolved from a sensitive output of another installation.
+func (b ExtendedBundle) WithSensitiveParameters(names ...string) ExtendedBundle {
+	index := b.sensitiveParameters
+	if index == nil {
+		index = buildSensitiveParameterIndex(b.Bundle)
+	}
+
+	sensitive := make(map[string]bool, len(index)+len(names))
+	for name, isSensitive := range index {
+		sensitive[name] = isSensitive
+	}
+	for _, name := range names {
+		sensitive[name] = true
+	}
+
+	b.sensitiveParameters = sensitive
+	return b
+}
+
 // GetParameterType determines the type of parameter accounting for
 // Porter-specific parameter types like file.
 func (b ExtendedBundle) GetParameterType(def *definition.Schema) string {
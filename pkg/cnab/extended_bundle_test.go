@@ -1,6 +1,7 @@
 package cnab
 
 import (
+	"fmt"
 	"testing"
 
 	"get.porter.sh/porter/pkg/portercontext"
@@ -164,6 +165,40 @@ func TestExtendedBundle_IsSensitiveParameter(t *testing.T) {
 	t.Run("is sensitive", func(t *testing.T) {
 		require.True(t, bun.IsSensitiveParameter("foo"))
 	})
+
+	t.Run("created without NewBundle", func(t *testing.T) {
+		b := ExtendedBundle{Bundle: bun.Bundle}
+		require.True(t, b.IsSensitiveParameter("foo"), "sensitivity should be determined from the definitions when it wasn't precomputed")
+		require.False(t, b.IsSensitiveParameter("porter-debug"))
+	})
+
+	t.Run("marked sensitive", func(t *testing.T) {
+		b := bun.WithSensitiveParameters("porter-debug")
+		require.True(t, b.IsSensitiveParameter("porter-debug"))
+		require.True(t, b.IsSensitiveParameter("foo"), "existing sensitive parameters should still be sensitive")
+		require.False(t, bun.IsSensitiveParameter("porter-debug"), "the original bundle should not be modified")
+	})
+}
+
+func BenchmarkExtendedBundle_IsSensitiveParameter(b *testing.B) {
+	sensitive := true
+	bun := bundle.Bundle{
+		Definitions: make(definition.Definitions, 500),
+		Parameters:  make(map[string]bundle.Parameter, 500),
+	}
+	for i := 0; i < 500; i++ {
+		name := fmt.Sprintf("param%d", i)
+		bun.Definitions[name] = &definition.Schema{Type: "string", WriteOnly: &sensitive}
+		bun.Parameters[name] = bundle.Parameter{Definition: name}
+	}
+	extb := NewBundle(bun)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for name := range bun.Parameters {
+			extb.IsSensitiveParameter(name)
+		}
+	}
 }
 
 func TestExtendedBundle_GetReferencedRegistries(t *testing.T) {
@@ -20,6 +20,10 @@ const (
 	// ParameterSourceTypeDependencyOutput defines a type of parameter source that is provided by a bundle's dependency
 	// output.
 	ParameterSourceTypeDependencyOutput = "dependencies.output"
+
+	// ParameterSourceTypeInstallationOutput defines a type of parameter source that is provided by the output
+	// of another installation, which may be in a different namespace.
+	ParameterSourceTypeInstallationOutput = "installation.output"
 )
 
 // ParameterSourcesExtension represents a required extension that specifies how
@@ -67,6 +71,24 @@ func (ps *ParameterSources) SetParameterFromDependencyOutput(parameter string, d
 	}
 }
 
+// SetParameterFromInstallationOutput creates an entry in the parameter sources section setting
+// the parameter's value using the specified output of another installation.
+func (ps *ParameterSources) SetParameterFromInstallationOutput(parameter string, namespace string, installation string, output string) {
+	if *ps == nil {
+		*ps = ParameterSources{}
+	}
+
+	(*ps)[parameter] = ParameterSource{
+		Priority: []string{ParameterSourceTypeInstallationOutput},
+		Sources: ParameterSourceMap{
+			ParameterSourceTypeInstallationOutput: InstallationOutputParameterSource{
+				Namespace:    namespace,
+				Installation: installation,
+				OutputName:   output},
+		},
+	}
+}
+
 type ParameterSource struct {
 	// Priority is an array of source types in the priority order that they should be used to
 	// populated the parameter.
@@ -127,6 +149,13 @@ func (m *ParameterSourceMap) UnmarshalJSON(data []byte) error {
 				return fmt.Errorf("invalid parameter source definition for key %s: %w", sourceKey, err)
 			}
 			(*m)[ParameterSourceTypeDependencyOutput] = depOutput
+		case ParameterSourceTypeInstallationOutput:
+			var instOutput InstallationOutputParameterSource
+			err := json.Unmarshal(rawDef, &instOutput)
+			if err != nil {
+				return fmt.Errorf("invalid parameter source definition for key %s: %w", sourceKey, err)
+			}
+			(*m)[ParameterSourceTypeInstallationOutput] = instOutput
 		default:
 			return fmt.Errorf("unsupported parameter source key %s", sourceKey)
 		}
@@ -151,6 +180,25 @@ type DependencyOutputParameterSource struct {
 	OutputName string `json:"name" mapstructure:"name"`
 }
 
+// InstallationOutputParameterSource represents a parameter that is set using the value
+// from the output of another installation. When the namespace is empty, the
+// installation is in the same namespace as the installation using the output.
+type InstallationOutputParameterSource struct {
+	Namespace    string `json:"namespace,omitempty" mapstructure:"namespace,omitempty"`
+	Installation string `json:"installation" mapstructure:"installation"`
+	OutputName   string `json:"name" mapstructure:"name"`
+}
+
+// GetInstallationReference returns the namespace and name of the installation
+// that generated the output. When the source does not specify a namespace, the
+// namespace of the installation using the output is returned.
+func (s InstallationOutputParameterSource) GetInstallationReference(namespace string) (string, string) {
+	if s.Namespace != "" {
+		namespace = s.Namespace
+	}
+	return namespace, s.Installation
+}
+
 // ReadParameterSources is a convenience method for returning a bonafide
 // ParameterSources reference after reading from the applicable section from
 // the provided bundle
@@ -1,6 +1,7 @@
 package cnab
 
 import (
+	"encoding/json"
 	"os"
 	"testing"
 
@@ -72,6 +73,31 @@ func TestReadParameterSourcesProperties(t *testing.T) {
 	assert.Equal(t, want, ps)
 }
 
+func TestParameterSourceMap_UnmarshalJSON_InstallationOutput(t *testing.T) {
+	t.Parallel()
+
+	data := `{"db_connstr": {"priority": ["installation.output"], "sources": {"installation.output": {"namespace": "platform", "installation": "mysql", "name": "connstr"}}}}`
+
+	var ps ParameterSources
+	require.NoError(t, json.Unmarshal([]byte(data), &ps))
+
+	want := ParameterSources{}
+	want.SetParameterFromInstallationOutput("db_connstr", "platform", "mysql", "connstr")
+	assert.Equal(t, want, ps)
+}
+
+func TestInstallationOutputParameterSource_GetInstallationReference(t *testing.T) {
+	t.Parallel()
+
+	namespace, name := InstallationOutputParameterSource{Namespace: "platform", Installation: "mysql"}.GetInstallationReference("dev")
+	assert.Equal(t, "platform", namespace)
+	assert.Equal(t, "mysql", name)
+
+	namespace, name = InstallationOutputParameterSource{Installation: "mysql"}.GetInstallationReference("dev")
+	assert.Equal(t, "dev", namespace, "the namespace should default to the namespace of the installation using the output")
+	assert.Equal(t, "mysql", name)
+}
+
 func TestParameterSource_ListSourcesByPriority(t *testing.T) {
 	t.Parallel()
 
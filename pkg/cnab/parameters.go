@@ -0,0 +1,165 @@
+package cnab
+
+import (
+	"sort"
+
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
+)
+
+// ParameterInfo describes a parameter of the bundle, along with the
+// properties that Porter derives from the parameter and its definition.
+type ParameterInfo struct {
+	// Name of the parameter.
+	Name string
+
+	// Parameter as declared by the bundle.
+	Parameter bundle.Parameter
+
+	// Definition of the parameter. It is nil when the definition is not
+	// defined in the bundle.
+	Definition *definition.Schema
+
+	// Type of the parameter, accounting for Porter-specific types like file.
+	// It is empty when the definition is not defined in the bundle.
+	Type string
+
+	// Required indicates that a value must be specified for the parameter.
+	Required bool
+
+	// Sensitive indicates that the value of the parameter is sensitive,
+	// including parameters that the bundle was told to treat as sensitive.
+	Sensitive bool
+
+	// Internal indicates that the parameter is used internally by Porter and
+	// is not set by the user.
+	Internal bool
+
+	// HasSource indicates that the value of the parameter may be set from a
+	// parameter source, such as an output.
+	HasSource bool
+}
+
+// AppliesTo determines if the parameter applies to the action.
+func (p ParameterInfo) AppliesTo(action string) bool {
+	return p.Parameter.AppliesTo(action)
+}
+
+// ParameterGroup is the set of parameters that apply to an action.
+type ParameterGroup struct {
+	// Action that the parameters apply to.
+	Action string
+
+	// Parameters that apply to the action, in display order.
+	Parameters []ParameterInfo
+}
+
+// GetParameterInfo describes a parameter of the bundle, returning false when
+// the parameter is not defined.
+func (b ExtendedBundle) GetParameterInfo(name string) (ParameterInfo, bool) {
+	param, ok := b.Parameters[name]
+	if !ok {
+		return ParameterInfo{}, false
+	}
+
+	info := ParameterInfo{
+		Name:      name,
+		Parameter: param,
+		Required:  param.Required,
+		Sensitive: b.IsSensitiveParameter(name),
+		Internal:  b.IsInternalParameter(name),
+		HasSource: b.ParameterHasSource(name),
+	}
+	if def, ok := b.Definitions[param.Definition]; ok && def != nil {
+		info.Definition = def
+		info.Type = b.GetParameterType(def)
+	}
+	return info, true
+}
+
+// ListParameters describes every parameter of the bundle, in display order,
+// which is sorted by name.
+func (b ExtendedBundle) ListParameters() []ParameterInfo {
+	names := make([]string, 0, len(b.Parameters))
+	for name := range b.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make([]ParameterInfo, 0, len(names))
+	for _, name := range names {
+		info, _ := b.GetParameterInfo(name)
+		params = append(params, info)
+	}
+	return params
+}
+
+// ListUserParameters describes the parameters of the bundle that may be set by
+// the user, excluding the parameters that are internal to Porter, in display
+// order.
+func (b ExtendedBundle) ListUserParameters() []ParameterInfo {
+	var params []ParameterInfo
+	for _, param := range b.ListParameters() {
+		if !param.Internal {
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// ListParametersForAction describes the parameters of the bundle that apply
+// to the action, in display order.
+func (b ExtendedBundle) ListParametersForAction(action string) []ParameterInfo {
+	var params []ParameterInfo
+	for _, param := range b.ListParameters() {
+		if param.AppliesTo(action) {
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// GroupParametersByAction groups the parameters of the bundle by the actions
+// that they apply to. A parameter that does not limit the actions that it
+// applies to is included in every group. The groups are ordered install,
+// upgrade, then the custom actions sorted by name, and finally uninstall.
+// Actions without parameters are omitted.
+func (b ExtendedBundle) GroupParametersByAction() []ParameterGroup {
+	params := b.ListParameters()
+
+	actionSet := map[string]struct{}{}
+	for action := range b.Actions {
+		actionSet[action] = struct{}{}
+	}
+	for _, param := range params {
+		for _, action := range param.Parameter.ApplyTo {
+			actionSet[action] = struct{}{}
+		}
+	}
+	delete(actionSet, ActionInstall)
+	delete(actionSet, ActionUpgrade)
+	delete(actionSet, ActionUninstall)
+
+	customActions := make([]string, 0, len(actionSet))
+	for action := range actionSet {
+		customActions = append(customActions, action)
+	}
+	sort.Strings(customActions)
+
+	actions := append([]string{ActionInstall, ActionUpgrade}, customActions...)
+	actions = append(actions, ActionUninstall)
+
+	var groups []ParameterGroup
+	for _, action := range actions {
+		var grouped []ParameterInfo
+		for _, param := range params {
+			if param.AppliesTo(action) {
+				grouped = append(grouped, param)
+			}
+		}
+		if len(grouped) > 0 {
+			groups = append(groups, ParameterGroup{Action: action, Parameters: grouped})
+		}
+	}
+	return groups
+}
@@ -0,0 +1,100 @@
+package cnab
+
+import (
+	"testing"
+
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newParameterTestBundle() ExtendedBundle {
+	writeOnly := true
+	return NewBundle(bundle.Bundle{
+		Actions: map[string]bundle.Action{
+			"migrate": {},
+			"backup":  {},
+		},
+		Definitions: definition.Definitions{
+			"string":   &definition.Schema{Type: "string"},
+			"password": &definition.Schema{Type: "string", WriteOnly: &writeOnly},
+			"internal": &definition.Schema{Type: "string", Comment: PorterInternal},
+		},
+		Parameters: map[string]bundle.Parameter{
+			"region":       {Definition: "string", Required: true},
+			"password":     {Definition: "password", ApplyTo: []string{"install", "migrate"}},
+			"porter-state": {Definition: "internal"},
+			"backup-path":  {Definition: "string", ApplyTo: []string{"backup"}},
+			"broken":       {Definition: "missing", ApplyTo: []string{"uninstall"}},
+		},
+	})
+}
+
+func parameterNames(params []ParameterInfo) []string {
+	names := make([]string, 0, len(params))
+	for _, p := range params {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func TestExtendedBundle_GetParameterInfo(t *testing.T) {
+	b := newParameterTestBundle()
+
+	password, ok := b.GetParameterInfo("password")
+	require.True(t, ok)
+	assert.True(t, password.Sensitive)
+	assert.False(t, password.Required)
+	assert.False(t, password.Internal)
+	assert.Equal(t, "string", password.Type)
+
+	region, ok := b.GetParameterInfo("region")
+	require.True(t, ok)
+	assert.True(t, region.Required)
+	assert.False(t, region.Sensitive)
+
+	state, ok := b.GetParameterInfo("porter-state")
+	require.True(t, ok)
+	assert.True(t, state.Internal)
+
+	broken, ok := b.GetParameterInfo("broken")
+	require.True(t, ok)
+	assert.Nil(t, broken.Definition)
+	assert.Empty(t, broken.Type)
+
+	_, ok = b.GetParameterInfo("missing")
+	assert.False(t, ok)
+
+	t.Run("with sensitive parameters", func(t *testing.T) {
+		region, _ := b.WithSensitiveParameters("region").GetParameterInfo("region")
+		assert.True(t, region.Sensitive)
+	})
+}
+
+func TestExtendedBundle_ListParameters(t *testing.T) {
+	b := newParameterTestBundle()
+
+	assert.Equal(t, []string{"backup-path", "broken", "password", "porter-state", "region"}, parameterNames(b.ListParameters()))
+	assert.Equal(t, []string{"backup-path", "broken", "password", "region"}, parameterNames(b.ListUserParameters()))
+	assert.Equal(t, []string{"password", "porter-state", "region"}, parameterNames(b.ListParametersForAction("install")))
+	assert.Equal(t, []string{"porter-state", "region"}, parameterNames(b.ListParametersForAction("upgrade")))
+}
+
+func TestExtendedBundle_GroupParametersByAction(t *testing.T) {
+	b := newParameterTestBundle()
+
+	groups := b.GroupParametersByAction()
+	got := make(map[string][]string, len(groups))
+	var actions []string
+	for _, g := range groups {
+		actions = append(actions, g.Action)
+		got[g.Action] = parameterNames(g.Parameters)
+	}
+
+	assert.Equal(t, []string{"install", "upgrade", "backup", "migrate", "uninstall"}, actions)
+	assert.Equal(t, []string{"password", "porter-state", "region"}, got["install"])
+	assert.Equal(t, []string{"backup-path", "porter-state", "region"}, got["backup"])
+	assert.Equal(t, []string{"password", "porter-state", "region"}, got["migrate"])
+	assert.Equal(t, []string{"broken", "porter-state", "region"}, got["uninstall"])
+}
@@ -5,14 +5,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"sort"
+	"strings"
+	"time"
 
 	"get.porter.sh/porter/pkg/cnab"
+	depsv1 "get.porter.sh/porter/pkg/cnab/dependencies/v1"
 	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/failure"
+	"get.porter.sh/porter/pkg/faults"
+	"get.porter.sh/porter/pkg/metrics"
 	"get.porter.sh/porter/pkg/storage"
 	"get.porter.sh/porter/pkg/tracing"
 	cnabaction "github.com/cnabio/cnab-go/action"
 	"github.com/cnabio/cnab-go/driver"
+	"github.com/cnabio/cnab-go/valuesource"
 	"github.com/hashicorp/go-multierror"
 	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap/zapcore"
@@ -39,16 +47,61 @@ type ActionArguments struct {
 	// Driver is the CNAB-compliant driver used to run bundle actions.
 	Driver string
 
+	// DriverPolicy controls what happens when the bundle does not support the
+	// driver. Allowed values are: enforce, warn. Defaults to enforce.
+	DriverPolicy string
+
 	// Give the bundle privileged access to the docker daemon.
 	AllowDockerHostAccess bool
 
 	// PersistLogs specifies if the invocation image output should be saved as an output.
 	PersistLogs bool
+
+	// Timeout is the maximum amount of time that the bundle may run before it
+	// is stopped. Zero means that the bundle is not timed out.
+	Timeout time.Duration
+
+	// EphemeralOutputs are the names of additional outputs that should be
+	// printed after the bundle runs but never persisted.
+	EphemeralOutputs []string
+
+	// ChangeTicket is the ID of a ticket in an external change-management
+	// system that is updated with the outcome of the run.
+	ChangeTicket string
+
+	// Labels to apply to the run.
+	Labels map[string]string
+
+	// RestoredSnapshot is the name of the snapshot of the installation whose
+	// outputs were used to resolve the parameters of the run.
+	RestoredSnapshot string
+
+	// OutputDependencies are the parameters of the run that were resolved from
+	// the outputs of other installations.
+	OutputDependencies []storage.RunOutputDependency
+
+	// ParameterSetRevisions are the revisions of the parameter sets that the
+	// parameters of the run were resolved from.
+	ParameterSetRevisions []storage.ParameterSetRevision
+
+	// Trigger is the event that started the run, when it was started automatically.
+	Trigger *storage.RunTrigger
+
+	// Events receives the events of the run as newline delimited json, as
+	// they happen. When set, the output of the bundle is reported as log
+	// events instead of printed.
+	Events io.Writer
+
+	// Out and Err receive the output of the bundle. When not set, the output
+	// is written to the output of the runtime.
+	Out io.Writer
+	Err io.Writer
 }
 
 func (r *Runtime) ApplyConfig(ctx context.Context, args ActionArguments) cnabaction.OperationConfigs {
 	return cnabaction.OperationConfigs{
 		r.SetOutput(),
+		r.setActionOutput(args),
 		r.AddFiles(ctx, args),
 		r.AddEnvironment(args),
 		r.AddRelocation(args),
@@ -63,6 +116,20 @@ func (r *Runtime) SetOutput() cnabaction.OperationConfigFunc {
 	}
 }
 
+// setActionOutput writes the output of the bundle to the writers of the
+// action, when they are set.
+func (r *Runtime) setActionOutput(args ActionArguments) cnabaction.OperationConfigFunc {
+	return func(op *driver.Operation) error {
+		if args.Out != nil {
+			op.Out = args.Out
+		}
+		if args.Err != nil {
+			op.Err = args.Err
+		}
+		return nil
+	}
+}
+
 func (r *Runtime) AddFiles(ctx context.Context, args ActionArguments) cnabaction.OperationConfigFunc {
 	return func(op *driver.Operation) error {
 		for k, v := range args.Files {
@@ -97,6 +164,16 @@ func (r *Runtime) AddEnvironment(args ActionArguments) cnabaction.OperationConfi
 		if debugMode, _ := args.Params["porter-debug"].(bool); debugMode {
 			op.Environment[verbosityEnv] = zapcore.DebugLevel.String()
 		}
+
+		// Request that the runtime reports the progress of each step
+		if args.Events != nil {
+			op.Environment[config.EnvRunEvents] = "true"
+		}
+
+		// Pass through the failures requested for testing, so that the runtime can inject them
+		if injectFaults := r.Getenv(config.EnvInjectFaults); injectFaults != "" {
+			op.Environment[config.EnvInjectFaults] = injectFaults
+		}
 		return nil
 	}
 }
@@ -143,41 +220,67 @@ func (r *Runtime) Execute(ctx context.Context, args ActionArguments) error {
 			return log.Error(errors.New("action is required"))
 		}
 
-		b, err := r.ProcessBundle(ctx, args.BundleReference.Definition)
+		// Fail before the parameters are saved to the secret store, because
+		// the run can't be saved in read-only mode
+		if r.Data.ReadOnly {
+			return log.Error(storage.ErrReadOnly{Collection: storage.CollectionRuns, Operation: "insert"})
+		}
+
+		b, exts, err := r.ProcessBundle(ctx, args.BundleReference.Definition)
 		if err != nil {
 			return log.Error(err)
 		}
 
+		if err = r.validateDriverExtensions(exts, args); err != nil {
+			return log.Error(err)
+		}
+
+		if err = r.validateDriverRequirements(ctx, b, args); err != nil {
+			return log.Error(err)
+		}
+
 		currentRun, err := r.CreateRun(ctx, args, b)
 		if err != nil {
 			return log.Error(err)
 		}
+		log.SetAttributes(currentRun.TraceAttributes()...)
 
 		// Validate the action
 		if _, err := b.GetAction(currentRun.Action); err != nil {
 			return log.Error(fmt.Errorf("invalid action '%s' specified for bundle %s: %w", currentRun.Action, b.Name, err))
 		}
 
-		creds, err := r.loadCredentials(ctx, b, args)
+		creds, leases, err := r.loadCredentials(ctx, b, args, currentRun.ID)
 		if err != nil {
 			return log.Error(fmt.Errorf("not load credentials: %w", err))
 		}
 
+		// Revoke the credentials issued for the run if the bundle is not executed
+		leasesRevoked := false
+		defer func() {
+			if !leasesRevoked {
+				r.revokeCredentials(ctx, leases)
+			}
+		}()
+
 		log.Debugf("Using runtime driver %s\n", args.Driver)
-		driver, err := r.newDriver(args.Driver, args)
+		driver, err := r.newDriver(exts, args.Driver, args)
 		if err != nil {
 			return log.Error(fmt.Errorf("unable to instantiate driver: %w", err))
 		}
 
-		a := cnabaction.New(driver)
-		a.SaveLogs = args.PersistLogs
+		driver = makeStoppable(driver, currentRun.ID)
 
+		stopHeartbeat := func() {}
 		if currentRun.ShouldRecord() {
+			currentRun.Heartbeat = time.Now()
 			err = r.SaveRun(ctx, args.Installation, currentRun, cnab.StatusRunning)
 			if err != nil {
 				return log.Error(fmt.Errorf("could not save the pending action's status, the bundle was not executed: %w", err))
 			}
+			stopHeartbeat = r.startRunHeartbeat(ctx, currentRun)
 		}
+		defer stopHeartbeat()
 
 		cnabClaim := currentRun.ToCNAB()
 		cnabCreds := creds.ToCNAB()
@@ -185,36 +288,114 @@ func (r *Runtime) Execute(ctx context.Context, args ActionArguments) error {
 		log.SetSensitiveAttributes(
 			tracing.ObjectAttribute("cnab-claim", cnabClaim),
 			tracing.ObjectAttribute("cnab-credentials", cnabCreds))
-		opResult, result, err := a.Run(cnabClaim, cnabCreds, r.ApplyConfig(ctx, args)...)
+		runCtx := ctx
+		if args.Timeout > 0 {
+			var cancel context.CancelFunc
+			runCtx, cancel = context.WithTimeout(ctx, args.Timeout)
+			defer cancel()
+		}
+		opCfgs := r.ApplyConfig(ctx, args)
+		var events *runEvents
+		if args.Events != nil {
+			events = r.newRunEvents(args.Events, currentRun)
+			opCfgs = append(opCfgs, events.SetOutput())
+			events.Emit(ctx, storage.RunEvent{Type: storage.RunEventStarted})
+		}
+		runStart := time.Now()
+		opResult, result, err := r.runAction(runCtx, driver, args.PersistLogs, cnabClaim, cnabCreds, opCfgs...)
+		r.redactRunOutput(ctx, currentRun, args.Params, cnabCreds, &opResult, &result)
+		stopHeartbeat()
+		runStatus := cnab.StatusSucceeded
+		if err != nil || opResult.Error != nil {
+			runStatus = cnab.StatusFailed
+		}
+		metrics.ObserveRun(currentRun.Action, runStatus, time.Since(runStart))
+		events.Complete(ctx, currentRun, opResult, result, err)
+
+		// The issued credentials are only valid for the duration of the run
+		leases = r.revokeCredentials(ctx, leases)
+		leasesRevoked = true
 
 		if currentRun.ShouldRecord() {
+			// Record the step results even when the bundle failed, they explain which step failed
+			if stepErr := r.SaveStepResults(ctx, currentRun, opResult); stepErr != nil {
+				log.Warnf("could not save the step results of the %s run: %s", currentRun.Action, stepErr)
+			}
+
 			if err != nil {
-				err = r.appendFailedResult(ctx, err, currentRun)
-				return log.Error(fmt.Errorf("failed to record that %s for installation %s failed: %w", args.Action, args.Installation.Name, err))
+				err = r.appendFailedResult(ctx, err, currentRun, leases)
+				return log.Error(failure.Execution(fmt.Errorf("failed to record that %s for installation %s failed: %w", args.Action, args.Installation.Name, err)))
 			}
-			return r.SaveOperationResult(ctx, opResult, args.Installation, currentRun, currentRun.NewResultFrom(result))
+			runResult := currentRun.NewResultFrom(result)
+			runResult.CredentialLeases = leases
+			err = r.saveOperationResult(ctx, opResult, args.Installation, currentRun, runResult, events == nil)
+			if opResult.Error != nil {
+				// The bundle failed, in addition to any errors saving the result
+				return failure.Execution(err)
+			}
+			return err
 		}
 
 		if err != nil {
-			return log.Error(fmt.Errorf("execution of %s for installation %s failed: %w", args.Action, args.Installation.Name, err))
+			return log.Error(failure.Execution(fmt.Errorf("execution of %s for installation %s failed: %w", args.Action, args.Installation.Name, err)))
 		}
 
 		return nil
 	}
 }
 
+// redactRunOutput scrubs the values of the sensitive parameters and
+// credentials of a run from the logs captured from the bundle, and from the
+// message of its result, before they are saved or sent in notifications.
+// When the sensitive parameters can't be determined, the values of every
+// parameter are redacted.
+func (r *Runtime) redactRunOutput(ctx context.Context, run storage.Run, params map[string]interface{}, creds valuesource.Set, opResult *driver.OperationResult, result *cnab.Result) {
+	sensitiveValues, err := r.sanitizer.SensitiveRunValues(run, params)
+	if err != nil {
+		log := tracing.LoggerFromContext(ctx)
+		log.Warnf("could not determine the sensitive parameters of the %s run, redacting every parameter: %s", run.Action, err)
+		sensitiveValues = make([]string, 0, len(params))
+		for _, value := range params {
+			if value != nil {
+				sensitiveValues = append(sensitiveValues, fmt.Sprintf("%v", value))
+			}
+		}
+	}
+	for _, value := range creds {
+		sensitiveValues = append(sensitiveValues, value)
+	}
+
+	if logs, ok := opResult.Outputs[cnab.OutputInvocationImageLogs]; ok {
+		opResult.Outputs[cnab.OutputInvocationImageLogs] = r.sanitizer.RedactLogs(logs, sensitiveValues)
+	}
+	result.Message = r.sanitizer.RedactLogs(result.Message, sensitiveValues)
+}
+
 func (r *Runtime) CreateRun(ctx context.Context, args ActionArguments, b cnab.ExtendedBundle) (storage.Run, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.EndSpan()
 
 	// Create a record for the run we are about to execute
-	var currentRun = args.Installation.NewRun(args.Action)
+	var currentRun = r.installations.NewRun(args.Installation, args.Action)
+	span.SetAttributes(currentRun.TraceAttributes()...)
 	currentRun.Bundle = b.Bundle
 	currentRun.BundleReference = args.BundleReference.Reference.String()
 	currentRun.BundleDigest = args.BundleReference.Digest.String()
 
-	var err error
 	extb := cnab.NewBundle(b.Bundle)
+	sensitiveSources, err := r.getSensitiveParameterSources(ctx, extb, args.Installation, args.Params)
+	if err != nil {
+		return storage.Run{}, span.Error(err)
+	}
+	extb = extb.WithSensitiveParameters(sensitiveSources...)
+
+	// Apply the sensitivity policy before the parameters are sanitized, so that
+	// the overrides are linked to the same secrets as the parameters
+	extb, err = r.sanitizer.ApplySensitivityPolicy(extb)
+	if err != nil {
+		return storage.Run{}, span.Error(err)
+	}
+
 	currentRun.Parameters.Parameters, err = r.sanitizer.CleanRawParameters(ctx, args.Params, extb, currentRun.ID)
 	if err != nil {
 		return storage.Run{}, span.Error(err)
@@ -227,12 +408,99 @@ func (r *Runtime) CreateRun(ctx context.Context, args ActionArguments, b cnab.Ex
 
 	currentRun.ParameterSets = args.Installation.ParameterSets
 	sort.Strings(currentRun.ParameterSets)
+
+	currentRun.EphemeralOutputs = getEphemeralOutputs(extb, args.EphemeralOutputs)
+	currentRun.ChangeTicket = args.ChangeTicket
+	currentRun.Labels = args.Labels
+	currentRun.RestoredSnapshot = args.RestoredSnapshot
+	currentRun.OutputDependencies = args.OutputDependencies
+	currentRun.ParameterSetRevisions = args.ParameterSetRevisions
+	currentRun.Trigger = args.Trigger
+	if args.Timeout > 0 {
+		currentRun.Timeout = args.Timeout.String()
+	}
+	currentRun.Environment = r.getRunEnvironment(ctx, args, b)
+	currentRun.Metadata = r.getRunMetadata()
 	return currentRun, nil
 }
 
+// getSensitiveParameterSources returns the names of the parameters whose value
+// was resolved from a sensitive output, such as the output of a dependency,
+// so that the value is kept in the secret store when the run is persisted.
+// The bundle's parameter definitions can't be relied upon because the
+// dependency's bundle, and its output definitions, are not available when
+// the parent bundle is built.
+func (r *Runtime) getSensitiveParameterSources(ctx context.Context, b cnab.ExtendedBundle, installation storage.Installation, params map[string]interface{}) ([]string, error) {
+	if !b.HasParameterSources() {
+		return nil, nil
+	}
+
+	sources, err := b.ReadParameterSources()
+	if err != nil {
+		return nil, err
+	}
+
+	var sensitive []string
+	for paramName, source := range sources {
+		if _, ok := params[paramName]; !ok || b.IsSensitiveParameter(paramName) {
+			continue
+		}
+
+		for _, rawSource := range source.ListSourcesByPriority() {
+			installationNamespace := installation.Namespace
+			var installationName, outputName string
+			switch s := rawSource.(type) {
+			case cnab.OutputParameterSource:
+				installationName = installation.Name
+				outputName = s.OutputName
+			case cnab.DependencyOutputParameterSource:
+				installationName = depsv1.BuildPrerequisiteInstallationName(installation.Name, s.Dependency)
+				outputName = s.OutputName
+			case cnab.InstallationOutputParameterSource:
+				installationNamespace, installationName = s.GetInstallationReference(installation.Namespace)
+				outputName = s.OutputName
+			default:
+				continue
+			}
+
+			output, err := r.installations.GetSharedOutput(ctx, installation.Namespace, installationNamespace, installationName, outputName)
+			if err != nil {
+				if errors.Is(err, storage.ErrNotFound{}) {
+					continue
+				}
+				return nil, fmt.Errorf("could not determine if parameter %s is sensitive from output %s of %s/%s: %w", paramName, outputName, installationNamespace, installationName, err)
+			}
+
+			// Sensitive outputs are stored in the secret store and only the key is saved on the output
+			if output.Key != "" {
+				sensitive = append(sensitive, paramName)
+				break
+			}
+		}
+	}
+
+	sort.Strings(sensitive)
+	return sensitive, nil
+}
+
+// getEphemeralOutputs returns the sorted names of the outputs declared
+// ephemeral by the bundle, combined with those requested by the user.
+func getEphemeralOutputs(b cnab.ExtendedBundle, requested []string) []string {
+	declared, _ := b.ReadEphemeralOutputs()
+
+	var outputs []string
+	for _, name := range append(declared, requested...) {
+		if !cnab.EphemeralOutputs(outputs).Contains(name) {
+			outputs = append(outputs, name)
+		}
+	}
+	sort.Strings(outputs)
+	return outputs
+}
+
 // SaveRun with the specified status.
 func (r *Runtime) SaveRun(ctx context.Context, installation storage.Installation, run storage.Run, status string) error {
-	ctx, span := tracing.StartSpan(ctx)
+	ctx, span := tracing.StartSpan(ctx, run.TraceAttributes()...)
 	defer span.EndSpan()
 
 	span.Debugf("saving action %s for %s installation with status %s", run.Action, installation, status)
@@ -245,7 +513,7 @@ func (r *Runtime) SaveRun(ctx context.Context, installation storage.Installation
 		return span.Error(fmt.Errorf("error saving the installation record before executing the bundle: %w", err))
 	}
 
-	result := run.NewResult(status)
+	result := r.installations.NewResult(run, status)
 	err = r.installations.InsertRun(ctx, run)
 	if err != nil {
 		return span.Error(fmt.Errorf("error saving the installation run record before executing the bundle: %w", err))
@@ -256,6 +524,7 @@ func (r *Runtime) SaveRun(ctx context.Context, installation storage.Installation
 		return span.Error(fmt.Errorf("error saving the installation status record before executing the bundle: %w", err))
 	}
 
+	r.notify(ctx, run, result)
 	return nil
 }
 
@@ -263,7 +532,14 @@ func (r *Runtime) SaveRun(ctx context.Context, installation storage.Installation
 // responsible for having already persisted the claim itself, for example using
 // SaveRun.
 func (r *Runtime) SaveOperationResult(ctx context.Context, opResult driver.OperationResult, installation storage.Installation, run storage.Run, result storage.Result) error {
-	ctx, span := tracing.StartSpan(ctx)
+	return r.saveOperationResult(ctx, opResult, installation, run, result, true)
+}
+
+// saveOperationResult saves the result and outputs of a run. The values of
+// ephemeral outputs are printed when printEphemeral is true, otherwise the
+// caller is responsible for reporting them.
+func (r *Runtime) saveOperationResult(ctx context.Context, opResult driver.OperationResult, installation storage.Installation, run storage.Run, result storage.Result, printEphemeral bool) error {
+	ctx, span := tracing.StartSpan(ctx, result.TraceAttributes()...)
 	defer span.EndSpan()
 
 	// TODO(carolynvs): optimistic locking on updates
@@ -274,38 +550,144 @@ func (r *Runtime) SaveOperationResult(ctx context.Context, opResult driver.Opera
 	var bigerr *multierror.Error
 	bigerr = multierror.Append(bigerr, opResult.Error)
 
+	r.deliverChangeTicket(ctx, run, &result)
+
+	// Save the result as pending until all of its outputs are saved, so that
+	// an interruption is detected and repaired by porter storage fsck
+	result.Pending = true
 	err := r.installations.InsertResult(ctx, result)
+	resultSaved := err == nil
 	if err != nil {
 		bigerr = multierror.Append(bigerr, fmt.Errorf("error adding %s result for %s run of installation %s\n%#v: %w", result.Status, run.Action, installation, result, err))
 	}
 
-	installation.ApplyResult(run, result)
-	err = r.installations.UpdateInstallation(ctx, installation)
-	if err != nil {
-		bigerr = multierror.Append(bigerr, fmt.Errorf("error updating installation record for %s\n%#v: %w", installation, installation, err))
-	}
-
+	bun := cnab.NewBundle(run.Bundle)
+	outputsSaved := true
+	injected := faults.FromEnv(r.Getenv)
+	ephemeralOutputs := make(map[string]string)
 	for outputName, outputValue := range opResult.Outputs {
+		// Step results are saved separately with SaveStepResults
+		if outputName == storage.StepResultsOutput {
+			continue
+		}
+
+		// Ephemeral outputs are printed instead of saved
+		if run.IsEphemeralOutput(outputName) {
+			ephemeralOutputs[outputName] = outputValue
+			continue
+		}
+
+		// Fail saving the output when a failure was requested for testing
+		if err = injected.Inject(faults.SaveOutput, outputName); err != nil {
+			outputsSaved = false
+			bigerr = multierror.Append(bigerr, fmt.Errorf("error adding %s output for %s run of installation %s: %w", outputName, run.Action, installation, err))
+			continue
+		}
+
+		// Save large outputs in chunks, so that they fit within the document size limits of the backing store
+		if len(outputValue) > storage.OutputChunkSize {
+			output := result.NewOutput(outputName, nil)
+			output, value, err := r.sanitizer.CleanOutputStream(ctx, output, strings.NewReader(outputValue), bun)
+			if err != nil {
+				bigerr = multierror.Append(bigerr, fmt.Errorf("error sanitizing sensitive %s output for %s run of installation %s: %w", output.Name, run.Action, installation, err))
+			}
+			err = r.installations.InsertOutputStream(ctx, output, value)
+			if err != nil {
+				outputsSaved = false
+				bigerr = multierror.Append(bigerr, fmt.Errorf("error adding %s output for %s run of installation %s: %w", output.Name, run.Action, installation, err))
+			}
+			continue
+		}
+
 		output := result.NewOutput(outputName, []byte(outputValue))
-		output, err = r.sanitizer.CleanOutput(ctx, output, cnab.ExtendedBundle{Bundle: run.Bundle})
+		output, err = r.sanitizer.CleanOutput(ctx, output, bun)
 		if err != nil {
 			bigerr = multierror.Append(bigerr, fmt.Errorf("error sanitizing sensitive %s output for %s run of installation %s\n%#v: %w", output.Name, run.Action, installation, output, err))
 		}
 		err = r.installations.InsertOutput(ctx, output)
 		if err != nil {
+			outputsSaved = false
 			bigerr = multierror.Append(bigerr, fmt.Errorf("error adding %s output for %s run of installation %s\n%#v: %w", output.Name, run.Action, installation, output, err))
 		}
 	}
 
+	// Leave the result pending when an output could not be saved, so that it is reported by porter storage fsck.
+	// The installation status is only updated with committed results, a pending result is applied when it is repaired.
+	if resultSaved && outputsSaved {
+		if err = r.installations.CommitResult(ctx, result); err != nil {
+			bigerr = multierror.Append(bigerr, fmt.Errorf("error committing %s result for %s run of installation %s: %w", result.Status, run.Action, installation, err))
+		} else {
+			installation.ApplyResult(run, result)
+			err = r.installations.UpdateInstallation(ctx, installation)
+			if err != nil {
+				bigerr = multierror.Append(bigerr, fmt.Errorf("error updating installation record for %s\n%#v: %w", installation, installation, err))
+			}
+		}
+	}
+
+	if resultSaved {
+		r.notify(ctx, run, result)
+	}
+
+	if printEphemeral {
+		r.printEphemeralOutputs(ephemeralOutputs)
+	}
+
 	return bigerr.ErrorOrNil()
 }
 
+// SaveStepResults saves the results of the steps executed by the run, which
+// are reported by the Porter runtime in the porter-step-results output.
+// Bundles built by an older version of Porter do not report step results.
+func (r *Runtime) SaveStepResults(ctx context.Context, run storage.Run, opResult driver.OperationResult) error {
+	data, ok := opResult.Outputs[storage.StepResultsOutput]
+	if !ok || data == "" {
+		return nil
+	}
+
+	var steps []storage.StepResult
+	if err := json.Unmarshal([]byte(data), &steps); err != nil {
+		return fmt.Errorf("could not parse the %s output: %w", storage.StepResultsOutput, err)
+	}
+
+	for i, step := range steps {
+		steps[i] = run.NewStepResult(step)
+	}
+	return r.installations.InsertStepResults(ctx, steps)
+}
+
+// printEphemeralOutputs prints the values of outputs that are not persisted,
+// since this is the only opportunity for the user to see them.
+func (r *Runtime) printEphemeralOutputs(outputs map[string]string) {
+	if len(outputs) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(r.Out, "The following outputs are ephemeral and were not saved, they will not be available after this command completes:")
+	for _, name := range names {
+		fmt.Fprintf(r.Out, "%s: %s\n", name, outputs[name])
+	}
+}
+
 // appendFailedResult creates a failed result from the operation error and accumulates
 // the error(s).
-func (r *Runtime) appendFailedResult(ctx context.Context, opErr error, run storage.Run) error {
+func (r *Runtime) appendFailedResult(ctx context.Context, opErr error, run storage.Run, leases []storage.CredentialLease) error {
 	saveResult := func() error {
-		result := run.NewResult(cnab.StatusFailed)
-		return r.installations.InsertResult(ctx, result)
+		result := r.installations.NewResult(run, cnab.StatusFailed)
+		result.CredentialLeases = leases
+		r.deliverChangeTicket(ctx, run, &result)
+		if err := r.installations.InsertResult(ctx, result); err != nil {
+			return err
+		}
+
+		r.notify(ctx, run, result)
+		return nil
 	}
 
 	resultErr := saveResult()
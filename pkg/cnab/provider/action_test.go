@@ -1,12 +1,23 @@
 package cnabprovider
 
 import (
+	"context"
 	"encoding/json"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/faults"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/storage"
 	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
 	"github.com/cnabio/cnab-go/driver"
+	"github.com/cnabio/cnab-go/secrets/host"
+	"github.com/cnabio/cnab-go/valuesource"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -41,3 +52,400 @@ func TestAddRelocation(t *testing.T) {
 	assert.Equal(t, "my.registry/microservice@sha256:cca460afa270d4c527981ef9ca4989346c56cf9b20217dcea37df1ece8120687", op.Image.Image)
 
 }
+
+func TestRuntime_SaveOperationResult_EphemeralOutputs(t *testing.T) {
+	t.Parallel()
+
+	r := NewTestRuntime(t)
+	defer r.Close()
+
+	ctx := context.Background()
+	installation := r.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybun"))
+	run := r.TestInstallations.CreateRun(installation.NewRun(cnab.ActionInstall), func(run *storage.Run) {
+		run.EphemeralOutputs = []string{"bootstrap-token"}
+	})
+
+	opResult := driver.OperationResult{
+		Outputs: map[string]string{
+			"bootstrap-token": "abc123",
+			"connection":      "mysql://localhost",
+		},
+	}
+	err := r.SaveOperationResult(ctx, opResult, installation, run, run.NewResult(cnab.StatusSucceeded))
+	require.NoError(t, err)
+
+	outputs, err := r.TestInstallations.GetLastOutputs(ctx, installation.Namespace, installation.Name)
+	require.NoError(t, err)
+	_, ok := outputs.GetByName("bootstrap-token")
+	assert.False(t, ok, "ephemeral outputs should not be persisted")
+	_, ok = outputs.GetByName("connection")
+	assert.True(t, ok, "outputs that are not ephemeral should be persisted")
+
+	output := r.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, output, "bootstrap-token: abc123", "ephemeral outputs should be printed")
+	assert.NotContains(t, output, "mysql://localhost", "persisted outputs should not be printed")
+}
+
+func TestRuntime_SaveOperationResult_LargeOutputs(t *testing.T) {
+	t.Parallel()
+
+	r := NewTestRuntime(t)
+	defer r.Close()
+
+	ctx := context.Background()
+	installation := r.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybun"))
+	run := r.TestInstallations.CreateRun(installation.NewRun(cnab.ActionInstall))
+
+	largeValue := strings.Repeat("a", storage.OutputChunkSize+1)
+	opResult := driver.OperationResult{
+		Outputs: map[string]string{
+			"archive":    largeValue,
+			"connection": "mysql://localhost",
+		},
+	}
+	err := r.SaveOperationResult(ctx, opResult, installation, run, run.NewResult(cnab.StatusSucceeded))
+	require.NoError(t, err)
+
+	outputs, err := r.TestInstallations.GetLastOutputs(ctx, installation.Namespace, installation.Name)
+	require.NoError(t, err)
+
+	archive, ok := outputs.GetByName("archive")
+	require.True(t, ok, "the large output should be persisted")
+	assert.True(t, archive.IsChunked(), "outputs larger than a chunk should be saved as a stream")
+	archive, err = storage.ReadOutputValue(ctx, r.TestInstallations, archive)
+	require.NoError(t, err)
+	assert.Equal(t, largeValue, string(archive.Value))
+
+	connection, ok := outputs.GetByName("connection")
+	require.True(t, ok, "the small output should be persisted")
+	assert.False(t, connection.IsChunked(), "small outputs should be saved on the output document")
+}
+
+func TestRuntime_SaveOperationResult_CommitsResult(t *testing.T) {
+	t.Parallel()
+
+	r := NewTestRuntime(t)
+	defer r.Close()
+
+	ctx := context.Background()
+	installation := r.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybun"))
+	run := r.TestInstallations.CreateRun(installation.NewRun(cnab.ActionInstall))
+
+	opResult := driver.OperationResult{
+		Outputs: map[string]string{
+			"connection": "mysql://localhost",
+		},
+	}
+	result := run.NewResult(cnab.StatusSucceeded)
+	err := r.SaveOperationResult(ctx, opResult, installation, run, result)
+	require.NoError(t, err)
+
+	gotResult, err := r.TestInstallations.GetResult(ctx, result.ID)
+	require.NoError(t, err)
+	assert.False(t, gotResult.Pending, "the result should be committed after its outputs are saved")
+
+	report, err := r.TestInstallations.Fsck(ctx, storage.FsckOptions{Namespace: "*"})
+	require.NoError(t, err)
+	assert.Empty(t, report.Problems, "the saved result should be consistent")
+}
+
+func TestRuntime_SaveOperationResult_InjectedFault(t *testing.T) {
+	t.Parallel()
+
+	r := NewTestRuntime(t)
+	defer r.Close()
+	r.TestConfig.TestContext.Setenv(config.EnvInjectFaults, "save-output=connection")
+
+	ctx := context.Background()
+	installation := r.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybun"))
+	run := r.TestInstallations.CreateRun(installation.NewRun(cnab.ActionInstall))
+
+	opResult := driver.OperationResult{
+		Outputs: map[string]string{
+			"connection": "mysql://localhost",
+			"username":   "admin",
+		},
+	}
+	result := run.NewResult(cnab.StatusSucceeded)
+	err := r.SaveOperationResult(ctx, opResult, installation, run, result)
+	require.ErrorIs(t, err, faults.ErrInjectedFault{Point: faults.SaveOutput, Target: "connection"})
+
+	gotResult, err := r.TestInstallations.GetResult(ctx, result.ID)
+	require.NoError(t, err)
+	assert.True(t, gotResult.Pending, "the result should not be committed when an output is not saved")
+
+	gotInst, err := r.TestInstallations.GetInstallation(ctx, "dev", "mybun")
+	require.NoError(t, err)
+	assert.NotEqual(t, result.ID, gotInst.Status.ResultID, "a pending result should not be applied to the installation")
+
+	report, err := r.TestInstallations.Fsck(ctx, storage.FsckOptions{Namespace: "*"})
+	require.NoError(t, err)
+	require.Len(t, report.Problems, 1)
+	assert.Equal(t, storage.FsckPendingResult, report.Problems[0].Type)
+}
+
+func TestRuntime_SaveStepResults(t *testing.T) {
+	t.Parallel()
+
+	r := NewTestRuntime(t)
+	defer r.Close()
+
+	ctx := context.Background()
+	installation := r.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybun"))
+	run := r.TestInstallations.CreateRun(installation.NewRun(cnab.ActionInstall))
+
+	opResult := driver.OperationResult{
+		Outputs: map[string]string{
+			storage.StepResultsOutput: `[{"index":0,"mixin":"exec","status":"succeeded"},{"index":1,"mixin":"helm3","status":"failed","exitCode":1}]`,
+			"connection":              "mysql://localhost",
+		},
+	}
+	require.NoError(t, r.SaveStepResults(ctx, run, opResult))
+	require.NoError(t, r.SaveOperationResult(ctx, opResult, installation, run, run.NewResult(cnab.StatusFailed)))
+
+	steps, err := r.TestInstallations.ListStepResults(ctx, run.ID)
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.Equal(t, run.ID, steps[0].RunID)
+	assert.Equal(t, "dev", steps[0].Namespace)
+	assert.Equal(t, "mybun", steps[0].Installation)
+	assert.Equal(t, "helm3", steps[1].Mixin)
+	assert.Equal(t, 1, steps[1].ExitCode)
+
+	outputs, err := r.TestInstallations.GetLastOutputs(ctx, installation.Namespace, installation.Name)
+	require.NoError(t, err)
+	_, ok := outputs.GetByName(storage.StepResultsOutput)
+	assert.False(t, ok, "the step results should not be saved as an output")
+
+	// Bundles built with older versions of Porter do not report step results
+	run2 := r.TestInstallations.CreateRun(installation.NewRun(cnab.ActionUpgrade))
+	require.NoError(t, r.SaveStepResults(ctx, run2, driver.OperationResult{}))
+}
+
+func TestRuntime_CreateRun_SensitiveDependencyOutputs(t *testing.T) {
+	t.Parallel()
+
+	r := NewTestRuntime(t)
+	defer r.Close()
+
+	ctx := context.Background()
+	installation := r.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybun"))
+
+	// The dependency's password output was sensitive, so only its key was persisted
+	depInstallation := r.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybun-mysql"))
+	depRun := r.TestInstallations.CreateRun(depInstallation.NewRun(cnab.ActionInstall))
+	depResult := r.TestInstallations.CreateResult(depRun.NewResult(cnab.StatusSucceeded))
+	r.TestInstallations.CreateOutput(depResult.NewOutput("password", nil), func(o *storage.Output) {
+		o.Key = depRun.ID + "-password"
+	})
+	r.TestInstallations.CreateOutput(depResult.NewOutput("host", []byte("localhost")))
+
+	ps := cnab.ParameterSources{}
+	ps.SetParameterFromDependencyOutput("porter-mysql-password-dep-output", "mysql", "password")
+	ps.SetParameterFromDependencyOutput("porter-mysql-host-dep-output", "mysql", "host")
+	b := cnab.NewBundle(bundle.Bundle{
+		Definitions: definition.Definitions{
+			"any": &definition.Schema{},
+		},
+		Parameters: map[string]bundle.Parameter{
+			"porter-mysql-password-dep-output": {Definition: "any"},
+			"porter-mysql-host-dep-output":     {Definition: "any"},
+		},
+		Custom: map[string]interface{}{
+			cnab.ParameterSourcesExtensionKey: ps,
+		},
+		RequiredExtensions: []string{cnab.ParameterSourcesExtensionKey},
+	})
+
+	args := ActionArguments{
+		Action:       cnab.ActionInstall,
+		Installation: installation,
+		Params: map[string]interface{}{
+			"porter-mysql-password-dep-output": "topsecret",
+			"porter-mysql-host-dep-output":     "localhost",
+		},
+	}
+	run, err := r.CreateRun(ctx, args, b)
+	require.NoError(t, err)
+
+	for _, param := range run.Parameters.Parameters {
+		switch param.Name {
+		case "porter-mysql-password-dep-output":
+			assert.Equal(t, secrets.SourceSecret, param.Source.Key, "parameters resolved from a sensitive dependency output should be stored in the secret store")
+		case "porter-mysql-host-dep-output":
+			assert.Equal(t, host.SourceValue, param.Source.Key, "parameters resolved from an output that was not sensitive should not be stored in the secret store")
+		default:
+			t.Fatalf("unexpected parameter %s", param.Name)
+		}
+	}
+}
+
+func TestRuntime_CreateRun_Labels(t *testing.T) {
+	t.Parallel()
+
+	r := NewTestRuntime(t)
+	defer r.Close()
+
+	installation := r.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybun"))
+	args := ActionArguments{
+		Action:       cnab.ActionUpgrade,
+		Installation: installation,
+		Labels:       map[string]string{"ticket": "OPS-1234"},
+	}
+	run, err := r.CreateRun(context.Background(), args, cnab.NewBundle(bundle.Bundle{}))
+	require.NoError(t, err)
+	assert.Equal(t, args.Labels, run.Labels)
+}
+
+func TestRuntime_redactRunOutput(t *testing.T) {
+	t.Parallel()
+
+	r := NewTestRuntime(t)
+	defer r.Close()
+
+	run := storage.NewRun("dev", "mybuns")
+	run.Parameters.Parameters = []secrets.Strategy{
+		{Name: "password", Source: secrets.Source{Key: secrets.SourceSecret, Value: run.ID + "-password"}},
+	}
+	params := map[string]interface{}{"password": "hunter2"}
+	creds := valuesource.Set{"kubeconfig": "apiVersion: v1"}
+
+	opResult := driver.OperationResult{
+		Outputs: map[string]string{
+			cnab.OutputInvocationImageLogs: "using apiVersion: v1 with password hunter2",
+			"connection-string":            "hunter2",
+		},
+	}
+	result := cnab.Result{Message: "could not connect with password hunter2"}
+	r.redactRunOutput(context.Background(), run, params, creds, &opResult, &result)
+	assert.Equal(t, "using ***** with password *****", opResult.Outputs[cnab.OutputInvocationImageLogs])
+	assert.Equal(t, "hunter2", opResult.Outputs["connection-string"], "only the logs should be redacted")
+	assert.Equal(t, "could not connect with password *****", result.Message, "the message of the result should be redacted")
+
+	opResult = driver.OperationResult{}
+	r.redactRunOutput(context.Background(), run, params, creds, &opResult, &cnab.Result{})
+	assert.Empty(t, opResult.Outputs, "the logs should not be added when they were not captured")
+
+	t.Run("invalid sensitivity policy", func(t *testing.T) {
+		r.Data.SensitivityPolicies = []config.SensitivityPolicy{{Parameters: []string{"["}}}
+		run.Bundle = bundle.Bundle{
+			Definitions: definition.Definitions{"string": &definition.Schema{Type: "string"}},
+			Parameters:  map[string]bundle.Parameter{"region": {Definition: "string"}},
+		}
+		params := map[string]interface{}{"region": "westus"}
+
+		opResult = driver.OperationResult{
+			Outputs: map[string]string{cnab.OutputInvocationImageLogs: "deploying to westus"},
+		}
+		r.redactRunOutput(context.Background(), run, params, valuesource.Set{}, &opResult, &cnab.Result{})
+		assert.Equal(t, "deploying to *****", opResult.Outputs[cnab.OutputInvocationImageLogs], "every parameter should be redacted when the sensitive parameters can't be determined")
+	})
+}
+
+func TestRuntime_CreateRun_Timeout(t *testing.T) {
+	t.Parallel()
+
+	r := NewTestRuntime(t)
+	defer r.Close()
+
+	installation := r.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybun"))
+	args := ActionArguments{
+		Action:       "backup",
+		Installation: installation,
+		Timeout:      90 * time.Minute,
+	}
+	run, err := r.CreateRun(context.Background(), args, cnab.NewBundle(bundle.Bundle{}))
+	require.NoError(t, err)
+	assert.Equal(t, "1h30m0s", run.Timeout)
+
+	args.Timeout = 0
+	run, err = r.CreateRun(context.Background(), args, cnab.NewBundle(bundle.Bundle{}))
+	require.NoError(t, err)
+	assert.Empty(t, run.Timeout, "the timeout should not be recorded when the run is not timed out")
+}
+
+func TestRuntime_CreateRun_SensitivityPolicy(t *testing.T) {
+	t.Parallel()
+
+	r := NewTestRuntime(t)
+	defer r.Close()
+	r.Data.SensitivityPolicies = []config.SensitivityPolicy{{Parameters: []string{".*_token"}}}
+
+	installation := r.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybun"), func(i *storage.Installation) {
+		i.Parameters = i.NewInternalParameterSet(
+			secrets.Strategy{Name: "api_token", Source: secrets.Source{Key: host.SourceValue, Value: "abc123"}},
+			secrets.Strategy{Name: "region", Source: secrets.Source{Key: host.SourceValue, Value: "east"}},
+		)
+	})
+	b := cnab.NewBundle(bundle.Bundle{
+		Name: "mybun",
+		Definitions: definition.Definitions{
+			"string": &definition.Schema{Type: "string"},
+		},
+		Parameters: map[string]bundle.Parameter{
+			"api_token": {Definition: "string"},
+			"region":    {Definition: "string"},
+		},
+	})
+
+	args := ActionArguments{
+		Action:       cnab.ActionInstall,
+		Installation: installation,
+		Params:       map[string]interface{}{"api_token": "abc123", "region": "east"},
+	}
+	run, err := r.CreateRun(context.Background(), args, b)
+	require.NoError(t, err)
+
+	for _, pset := range []storage.ParameterSet{run.Parameters, run.ParameterOverrides} {
+		for _, param := range pset.Parameters {
+			switch param.Name {
+			case "api_token":
+				assert.Equal(t, secrets.SourceSecret, param.Source.Key, "parameters matched by the sensitivity policy should be stored in the secret store")
+				assert.Equal(t, run.ID+"-api_token", param.Source.Value)
+			case "region":
+				assert.Equal(t, host.SourceValue, param.Source.Key, "parameters that are not sensitive should not be stored in the secret store")
+			default:
+				t.Fatalf("unexpected parameter %s", param.Name)
+			}
+		}
+	}
+}
+
+func TestRuntime_Execute_ReadOnly(t *testing.T) {
+	t.Parallel()
+
+	r := NewTestRuntime(t)
+	defer r.Close()
+	r.Data.SensitivityPolicies = []config.SensitivityPolicy{{Parameters: []string{".*_token"}}}
+
+	installation := r.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybun"))
+	r.Data.ReadOnly = true
+	b := cnab.NewBundle(bundle.Bundle{
+		SchemaVersion: "1.2.0",
+		Name:          "mybun",
+		Version:       "0.1.0",
+		InvocationImages: []bundle.InvocationImage{
+			{BaseImage: bundle.BaseImage{Image: "example.com/mybun:0.1.0", ImageType: "docker"}},
+		},
+		Definitions: definition.Definitions{
+			"string": &definition.Schema{Type: "string"},
+		},
+		Parameters: map[string]bundle.Parameter{
+			"api_token": {Definition: "string", Destination: &bundle.Location{EnvironmentVariable: "API_TOKEN"}},
+		},
+	})
+
+	args := ActionArguments{
+		Action:          cnab.ActionInstall,
+		Installation:    installation,
+		BundleReference: cnab.BundleReference{Definition: b},
+		Params:          map[string]interface{}{"api_token": "abc123"},
+	}
+	err := r.Execute(context.Background(), args)
+	assert.ErrorIs(t, err, storage.ErrReadOnly{})
+
+	assert.Empty(t, r.TestCredentials.TestSecrets.InMemory().Secrets, "sensitive parameters should not be saved to the secret store in read-only mode")
+	runs, _, err := r.TestInstallations.ListRuns(context.Background(), "dev", "mybun")
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
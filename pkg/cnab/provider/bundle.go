@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"get.porter.sh/porter/pkg"
 	"get.porter.sh/porter/pkg/cnab"
 )
 
@@ -11,21 +12,33 @@ func (r *Runtime) LoadBundle(bundleFile string) (cnab.ExtendedBundle, error) {
 	return cnab.LoadBundle(r.Context, bundleFile)
 }
 
-func (r *Runtime) ProcessBundleFromFile(ctx context.Context, bundleFile string) (cnab.ExtendedBundle, error) {
+func (r *Runtime) ProcessBundleFromFile(ctx context.Context, bundleFile string) (cnab.ExtendedBundle, cnab.ProcessedExtensions, error) {
 	b, err := r.LoadBundle(bundleFile)
 	if err != nil {
-		return cnab.ExtendedBundle{}, err
+		return cnab.ExtendedBundle{}, nil, err
 	}
 
 	return r.ProcessBundle(ctx, b)
 }
 
-func (r *Runtime) ProcessBundle(ctx context.Context, b cnab.ExtendedBundle) (cnab.ExtendedBundle, error) {
+// ProcessBundle validates the bundle and returns its processed required
+// extensions. The extensions are returned instead of being stored on the
+// runtime, because the runtime executes dependencies concurrently.
+func (r *Runtime) ProcessBundle(ctx context.Context, b cnab.ExtendedBundle) (cnab.ExtendedBundle, cnab.ProcessedExtensions, error) {
 	strategy := r.GetSchemaCheckStrategy(ctx)
 	err := b.Validate(r.Context, strategy)
 	if err != nil {
-		return b, fmt.Errorf("invalid bundle: %w", err)
+		return b, nil, fmt.Errorf("invalid bundle: %w", err)
 	}
 
-	return b, r.ProcessRequiredExtensions(b)
+	err = b.ValidateConstraints(pkg.Version)
+	if err != nil {
+		return b, nil, err
+	}
+
+	exts, err := b.ProcessRequiredExtensions()
+	if err != nil {
+		return b, nil, fmt.Errorf("unable to process required extensions: %w", err)
+	}
+	return b, exts, nil
 }
@@ -0,0 +1,171 @@
+package cnabprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	"get.porter.sh/porter/pkg/webhook"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ChangeTicketEvent is the data used to render the url and body of the
+// request that updates a change ticket.
+type ChangeTicketEvent struct {
+	Ticket       string `json:"ticket"`
+	Namespace    string `json:"namespace"`
+	Installation string `json:"installation"`
+	Action       string `json:"action"`
+	RunID        string `json:"runId"`
+	ResultID     string `json:"resultId"`
+	Status       string `json:"status"`
+	Message      string `json:"message,omitempty"`
+}
+
+// NewChangeTicketEvent describes the result of a run for its change ticket.
+func NewChangeTicketEvent(run storage.Run, result storage.Result) ChangeTicketEvent {
+	return ChangeTicketEvent{
+		Ticket:       run.ChangeTicket,
+		Namespace:    run.Namespace,
+		Installation: run.Installation,
+		Action:       run.Action,
+		RunID:        run.ID,
+		ResultID:     result.ID,
+		Status:       result.Status,
+		Message:      result.Message,
+	}
+}
+
+// deliverChangeTicket updates the run's change ticket in the configured
+// change-management system with the result of the run, retrying failed
+// requests, and records the delivery status on the result. A failed delivery
+// does not fail the run, it is logged and recorded on the result instead.
+func (r *Runtime) deliverChangeTicket(ctx context.Context, run storage.Run, result *storage.Result) {
+	cfg := r.Config.Data.ChangeManagement
+	if run.ChangeTicket == "" || !cfg.IsEnabled() {
+		return
+	}
+
+	ctx, span := tracing.StartSpan(ctx, attribute.String("ticket", run.ChangeTicket))
+	defer span.EndSpan()
+
+	delivery := &storage.ChangeTicketDelivery{
+		Ticket: run.ChangeTicket,
+		Status: storage.ChangeTicketDeliveryFailed,
+	}
+	result.ChangeTicketDelivery = delivery
+
+	err := r.sendChangeTicket(ctx, NewChangeTicketEvent(run, *result), delivery)
+	delivery.Completed = time.Now()
+	if err != nil {
+		delivery.Error = err.Error()
+		span.Warnf("Could not update change ticket %s: %s", run.ChangeTicket, err)
+		return
+	}
+
+	delivery.Status = storage.ChangeTicketDelivered
+	span.Debugf("Updated change ticket %s with the %s status of run %s", run.ChangeTicket, result.Status, run.ID)
+}
+
+// sendChangeTicket sends the event to the change-management system, retrying
+// with an exponential backoff until the request succeeds or the maximum number
+// of attempts is reached. The number of attempts and the last status code are
+// recorded on the delivery.
+func (r *Runtime) sendChangeTicket(ctx context.Context, event ChangeTicketEvent, delivery *storage.ChangeTicketDelivery) error {
+	log := tracing.LoggerFromContext(ctx)
+	cfg := r.Config.Data.ChangeManagement
+
+	timeout, err := cfg.GetTimeout()
+	if err != nil {
+		return err
+	}
+
+	delay, err := cfg.GetRetryDelay()
+	if err != nil {
+		return err
+	}
+
+	req, err := buildChangeTicketRequest(event, cfg.URL, cfg.Body)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: timeout}
+	maxAttempts := cfg.GetMaxAttempts()
+	for {
+		delivery.Attempts++
+		delivery.StatusCode, err = webhook.Send(ctx, client, cfg.GetMethod(), req.URL, cfg.Headers, req.Body)
+		if err == nil || delivery.Attempts >= maxAttempts || !isRetryableStatus(delivery.StatusCode) {
+			return err
+		}
+		log.Debugf("Attempt %d of %d to update change ticket %s failed: %s", delivery.Attempts, maxAttempts, event.Ticket, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stopped retrying after %d attempts: %w", delivery.Attempts, err)
+		case <-time.After(delay):
+			delay *= 2
+		}
+	}
+}
+
+// isRetryableStatus determines if a request that failed with the specified
+// status code may succeed when it is retried. Requests that failed without a
+// response are retried.
+func isRetryableStatus(statusCode int) bool {
+	if statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return statusCode < 400 || statusCode >= 500
+}
+
+// changeTicketRequest is a rendered request to update a change ticket.
+type changeTicketRequest struct {
+	URL  string
+	Body []byte
+}
+
+// buildChangeTicketRequest renders the url and body templates with the event.
+// When a body template is not specified, the event is sent as json.
+func buildChangeTicketRequest(event ChangeTicketEvent, urlTmpl string, bodyTmpl string) (changeTicketRequest, error) {
+	url, err := renderChangeTicketTemplate("url", urlTmpl, event)
+	if err != nil {
+		return changeTicketRequest{}, err
+	}
+
+	var body []byte
+	if bodyTmpl == "" {
+		body, err = json.Marshal(event)
+		if err != nil {
+			return changeTicketRequest{}, fmt.Errorf("error marshaling the change ticket event: %w", err)
+		}
+	} else {
+		rendered, err := renderChangeTicketTemplate("body", bodyTmpl, event)
+		if err != nil {
+			return changeTicketRequest{}, err
+		}
+		body = []byte(rendered)
+	}
+
+	return changeTicketRequest{URL: strings.TrimSpace(url), Body: body}, nil
+}
+
+func renderChangeTicketTemplate(name string, tmpl string, event ChangeTicketEvent) (string, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid change-management.%s template: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, event); err != nil {
+		return "", fmt.Errorf("error rendering the change-management.%s template: %w", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,200 @@
+package cnabprovider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/cnabio/cnab-go/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntime_deliverChangeTicket(t *testing.T) {
+	t.Parallel()
+
+	t.Run("delivered", func(t *testing.T) {
+		t.Parallel()
+
+		var gotPath string
+		var gotEvent ChangeTicketEvent
+		var gotAuth string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			gotPath = req.URL.Path
+			gotAuth = req.Header.Get("Authorization")
+			body, _ := io.ReadAll(req.Body)
+			require.NoError(t, json.Unmarshal(body, &gotEvent))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		r := NewTestRuntime(t)
+		defer r.Close()
+		r.Config.Data.ChangeManagement.URL = srv.URL + "/changes/{{.Ticket}}/{{.Status}}"
+		r.Config.Data.ChangeManagement.Headers = map[string]string{"Authorization": "Bearer abc123"}
+
+		run := storage.NewRun("dev", "mybun")
+		run.Action = cnab.ActionInstall
+		run.ChangeTicket = "CHG0001"
+		result := run.NewResult(cnab.StatusSucceeded)
+
+		r.deliverChangeTicket(context.Background(), run, &result)
+
+		require.NotNil(t, result.ChangeTicketDelivery)
+		assert.Equal(t, storage.ChangeTicketDelivered, result.ChangeTicketDelivery.Status)
+		assert.Equal(t, 1, result.ChangeTicketDelivery.Attempts)
+		assert.Equal(t, http.StatusNoContent, result.ChangeTicketDelivery.StatusCode)
+		assert.Equal(t, "/changes/CHG0001/succeeded", gotPath)
+		assert.Equal(t, "Bearer abc123", gotAuth)
+		assert.Equal(t, NewChangeTicketEvent(run, result), gotEvent)
+	})
+
+	t.Run("retried", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		r := NewTestRuntime(t)
+		defer r.Close()
+		r.Config.Data.ChangeManagement.URL = srv.URL
+		r.Config.Data.ChangeManagement.RetryDelay = "1ms"
+
+		run := storage.NewRun("dev", "mybun")
+		run.ChangeTicket = "CHG0001"
+		result := run.NewResult(cnab.StatusFailed)
+
+		r.deliverChangeTicket(context.Background(), run, &result)
+
+		require.NotNil(t, result.ChangeTicketDelivery)
+		assert.Equal(t, storage.ChangeTicketDelivered, result.ChangeTicketDelivery.Status)
+		assert.Equal(t, 3, result.ChangeTicketDelivery.Attempts)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("ticket is locked"))
+		}))
+		defer srv.Close()
+
+		r := NewTestRuntime(t)
+		defer r.Close()
+		r.Config.Data.ChangeManagement.URL = srv.URL
+		r.Config.Data.ChangeManagement.MaxAttempts = 2
+		r.Config.Data.ChangeManagement.RetryDelay = "1ms"
+
+		run := storage.NewRun("dev", "mybun")
+		run.ChangeTicket = "CHG0001"
+		result := run.NewResult(cnab.StatusSucceeded)
+
+		r.deliverChangeTicket(context.Background(), run, &result)
+
+		require.NotNil(t, result.ChangeTicketDelivery)
+		assert.Equal(t, storage.ChangeTicketDeliveryFailed, result.ChangeTicketDelivery.Status)
+		assert.Equal(t, 2, result.ChangeTicketDelivery.Attempts)
+		assert.Equal(t, http.StatusInternalServerError, result.ChangeTicketDelivery.StatusCode)
+		assert.Contains(t, result.ChangeTicketDelivery.Error, "ticket is locked")
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer srv.Close()
+
+		r := NewTestRuntime(t)
+		defer r.Close()
+		r.Config.Data.ChangeManagement.URL = srv.URL
+		r.Config.Data.ChangeManagement.RetryDelay = "1ms"
+
+		run := storage.NewRun("dev", "mybun")
+		run.ChangeTicket = "CHG0001"
+		result := run.NewResult(cnab.StatusSucceeded)
+
+		r.deliverChangeTicket(context.Background(), run, &result)
+
+		require.NotNil(t, result.ChangeTicketDelivery)
+		assert.Equal(t, storage.ChangeTicketDeliveryFailed, result.ChangeTicketDelivery.Status)
+		assert.Equal(t, 1, result.ChangeTicketDelivery.Attempts)
+	})
+
+	t.Run("no change ticket", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewTestRuntime(t)
+		defer r.Close()
+		r.Config.Data.ChangeManagement.URL = "http://example.com"
+
+		run := storage.NewRun("dev", "mybun")
+		result := run.NewResult(cnab.StatusSucceeded)
+
+		r.deliverChangeTicket(context.Background(), run, &result)
+		assert.Nil(t, result.ChangeTicketDelivery, "runs without a change ticket should not be delivered")
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewTestRuntime(t)
+		defer r.Close()
+		r.Config.Data.ChangeManagement.URL = "http://example.com/{{.Missing}}"
+
+		run := storage.NewRun("dev", "mybun")
+		run.ChangeTicket = "CHG0001"
+		result := run.NewResult(cnab.StatusSucceeded)
+
+		r.deliverChangeTicket(context.Background(), run, &result)
+
+		require.NotNil(t, result.ChangeTicketDelivery)
+		assert.Equal(t, storage.ChangeTicketDeliveryFailed, result.ChangeTicketDelivery.Status)
+		assert.Zero(t, result.ChangeTicketDelivery.Attempts)
+		assert.Contains(t, result.ChangeTicketDelivery.Error, "error rendering the change-management.url template")
+	})
+}
+
+func TestRuntime_SaveOperationResult_ChangeTicket(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	r := NewTestRuntime(t)
+	defer r.Close()
+	r.Config.Data.ChangeManagement.URL = srv.URL
+
+	ctx := context.Background()
+	installation := r.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybun"))
+	run := r.TestInstallations.CreateRun(installation.NewRun(cnab.ActionInstall), func(run *storage.Run) {
+		run.ChangeTicket = "CHG0001"
+	})
+	result := run.NewResult(cnab.StatusSucceeded)
+
+	err := r.SaveOperationResult(ctx, driver.OperationResult{}, installation, run, result)
+	require.NoError(t, err)
+
+	saved, err := r.TestInstallations.GetResult(ctx, result.ID)
+	require.NoError(t, err)
+	require.NotNil(t, saved.ChangeTicketDelivery, "the delivery status should be recorded on the result")
+	assert.Equal(t, storage.ChangeTicketDelivered, saved.ChangeTicketDelivery.Status)
+	assert.Equal(t, "CHG0001", saved.ChangeTicketDelivery.Ticket)
+}
@@ -2,6 +2,8 @@ package cnabprovider
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"get.porter.sh/porter/pkg/cnab"
 	"get.porter.sh/porter/pkg/secrets"
@@ -10,18 +12,23 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 )
 
-func (r *Runtime) loadCredentials(ctx context.Context, b cnab.ExtendedBundle, args ActionArguments) (secrets.Set, error) {
+// loadCredentials resolves the credentials of the installation for a run.
+// Credentials with the issued source are minted by the secrets plugin for
+// the run, and the returned leases must be revoked with revokeCredentials
+// when the run completes.
+func (r *Runtime) loadCredentials(ctx context.Context, b cnab.ExtendedBundle, args ActionArguments, runID string) (secrets.Set, []storage.CredentialLease, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.EndSpan()
 
 	if len(args.Installation.CredentialSets) == 0 {
-		return nil, storage.Validate(nil, b.Credentials, args.Action)
+		return nil, nil, storage.Validate(nil, b.Credentials, args.Action)
 	}
 
 	// The strategy here is "last one wins". We loop through each credential file and
 	// calculate its credentials. Then we insert them into the creds map in the order
 	// in which they were supplied on the CLI.
 	resolvedCredentials := secrets.Set{}
+	var leases []storage.CredentialLease
 	for _, name := range args.Installation.CredentialSets {
 		var cset storage.CredentialSet
 		// Try to get the creds in the local namespace first, fallback to the global creds
@@ -38,12 +45,21 @@ func (r *Runtime) loadCredentials(ctx context.Context, b cnab.ExtendedBundle, ar
 		store := r.credentials.GetDataStore()
 		err := store.FindOne(ctx, storage.CollectionCredentials, query, &cset)
 		if err != nil {
-			return nil, err
+			r.revokeCredentials(ctx, leases)
+			return nil, nil, err
 		}
 
 		rc, err := r.credentials.ResolveAll(ctx, cset)
 		if err != nil {
-			return nil, err
+			r.revokeCredentials(ctx, leases)
+			return nil, nil, err
+		}
+
+		issued, err := r.issueCredentials(ctx, cset, runID, rc)
+		leases = append(leases, issued...)
+		if err != nil {
+			r.revokeCredentials(ctx, leases)
+			return nil, nil, err
 		}
 
 		for k, v := range rc {
@@ -51,5 +67,61 @@ func (r *Runtime) loadCredentials(ctx context.Context, b cnab.ExtendedBundle, ar
 		}
 	}
 
-	return resolvedCredentials, storage.Validate(resolvedCredentials, b.Credentials, args.Action)
+	if err := storage.Validate(resolvedCredentials, b.Credentials, args.Action); err != nil {
+		r.revokeCredentials(ctx, leases)
+		return nil, nil, err
+	}
+	return resolvedCredentials, leases, nil
+}
+
+// issueCredentials mints the credentials in the set that use the issued
+// source, and adds their values to the resolved credentials. The leases of
+// the credentials that were issued are returned even when an error occurs, so
+// that they can be revoked.
+func (r *Runtime) issueCredentials(ctx context.Context, cset storage.CredentialSet, runID string, resolved secrets.Set) ([]storage.CredentialLease, error) {
+	var leases []storage.CredentialLease
+	for _, cred := range cset.Credentials {
+		if cred.Source.Key != secrets.SourceIssued {
+			continue
+		}
+
+		issued, err := secrets.IssueCredentials(ctx, r.secrets, cred.Source.Value, runID)
+		if err != nil {
+			return leases, fmt.Errorf("unable to issue credential %s.%s from %s %s: %w", cset.Name, cred.Name, cred.Source.Key, cred.Source.Value, err)
+		}
+
+		resolved[cred.Name] = issued.Value
+		leases = append(leases, storage.CredentialLease{
+			Credential: cred.Name,
+			LeaseID:    issued.LeaseID,
+			Issued:     time.Now(),
+		})
+	}
+	return leases, nil
+}
+
+// revokeCredentials revokes the credentials issued for a run, and returns the
+// leases updated with the outcome of each revocation. A credential that could
+// not be revoked does not fail the run, it is logged and recorded on the lease
+// instead.
+func (r *Runtime) revokeCredentials(ctx context.Context, leases []storage.CredentialLease) []storage.CredentialLease {
+	if len(leases) == 0 {
+		return nil
+	}
+
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	revoked := make([]storage.CredentialLease, len(leases))
+	for i, lease := range leases {
+		if err := secrets.RevokeCredentials(ctx, r.secrets, lease.LeaseID); err != nil {
+			span.Warnf("could not revoke the %s credential issued with lease %s: %s", lease.Credential, lease.LeaseID, err)
+			lease.Error = err.Error()
+		} else {
+			lease.Revoked = true
+			lease.RevokedAt = time.Now()
+		}
+		revoked[i] = lease
+	}
+	return revoked
 }
@@ -55,7 +55,7 @@ func TestRuntime_loadCredentials(t *testing.T) {
 				CredentialSets: []string{"mycreds"}},
 		},
 		Action: "install"}
-	gotValues, err := r.loadCredentials(context.Background(), b, args)
+	gotValues, _, err := r.loadCredentials(context.Background(), b, args, "")
 	require.NoError(t, err, "loadCredentials failed")
 
 	wantValues := secrets.Set{
@@ -69,7 +69,7 @@ func TestRuntime_loadCredentials(t *testing.T) {
 				CredentialSets: []string{"/db-creds.json"}},
 		},
 		Action: "install"}
-	_, err = r.loadCredentials(context.Background(), b, args)
+	_, _, err = r.loadCredentials(context.Background(), b, args, "")
 	require.Error(t, err, "loadCredentials should not load from a file")
 }
 
@@ -96,7 +96,7 @@ func TestRuntime_loadCredentials_WithApplyTo(t *testing.T) {
 
 		args := ActionArguments{Action: "status"}
 		b := getBundle(true)
-		gotValues, err := r.loadCredentials(context.Background(), b, args)
+		gotValues, _, err := r.loadCredentials(context.Background(), b, args, "")
 		require.NoError(t, err, "loadCredentials failed")
 
 		var wantValues secrets.Set
@@ -110,7 +110,7 @@ func TestRuntime_loadCredentials_WithApplyTo(t *testing.T) {
 
 		args := ActionArguments{Action: "install"}
 		b := getBundle(false)
-		gotValues, err := r.loadCredentials(context.Background(), b, args)
+		gotValues, _, err := r.loadCredentials(context.Background(), b, args, "")
 		require.NoError(t, err, "loadCredentials failed")
 
 		var wantValues secrets.Set
@@ -124,7 +124,7 @@ func TestRuntime_loadCredentials_WithApplyTo(t *testing.T) {
 
 		args := ActionArguments{Action: "install"}
 		b := getBundle(true)
-		_, err := r.loadCredentials(context.Background(), b, args)
+		_, _, err := r.loadCredentials(context.Background(), b, args, "")
 		require.Error(t, err, "expected the credential to be required")
 	})
 
@@ -153,9 +153,80 @@ func TestRuntime_loadCredentials_WithApplyTo(t *testing.T) {
 					CredentialSets: []string{"mycreds"}},
 			},
 			Action: "install"}
-		gotValues, err := r.loadCredentials(context.Background(), b, args)
+		gotValues, _, err := r.loadCredentials(context.Background(), b, args, "")
 		require.NoError(t, err, "loadCredentials failed")
 		assert.Equal(t, secrets.Set{"password": "mypassword"}, gotValues)
 	})
 
 }
+
+func TestRuntime_loadCredentials_Issued(t *testing.T) {
+	t.Parallel()
+
+	b := cnab.NewBundle(bundle.Bundle{
+		Credentials: map[string]bundle.Credential{
+			"db-user": {
+				Location: bundle.Location{EnvironmentVariable: "DB_USER"},
+				Required: true,
+			},
+			"kubeconfig": {
+				Location: bundle.Location{Path: "/home/nonroot/.kube/config"},
+				Required: true,
+			},
+		},
+	})
+
+	setup := func(t *testing.T) *TestRuntime {
+		r := NewTestRuntime(t)
+		cs := storage.NewCredentialSet("", "mycreds", secrets.Strategy{
+			Name:   "db-user",
+			Source: secrets.Source{Key: secrets.SourceIssued, Value: "postgres/readonly"},
+		})
+		require.NoError(t, r.credentials.InsertCredentialSet(context.Background(), cs))
+		return r
+	}
+
+	t.Run("issued and revoked", func(t *testing.T) {
+		t.Parallel()
+		r := setup(t)
+		defer r.Close()
+		r.TestCredentials.AddSecret("kubeconfig", "mykubeconfig")
+		cs := storage.NewCredentialSet("", "kube", secrets.Strategy{
+			Name:   "kubeconfig",
+			Source: secrets.Source{Key: secrets.SourceSecret, Value: "kubeconfig"},
+		})
+		require.NoError(t, r.credentials.InsertCredentialSet(context.Background(), cs))
+
+		args := ActionArguments{
+			Installation: storage.Installation{InstallationSpec: storage.InstallationSpec{CredentialSets: []string{"mycreds", "kube"}}},
+			Action:       "install",
+		}
+		gotValues, leases, err := r.loadCredentials(context.Background(), b, args, "01RUN")
+		require.NoError(t, err)
+		assert.Equal(t, secrets.Set{"db-user": "postgres/readonly-01RUN", "kubeconfig": "mykubeconfig"}, gotValues)
+		require.Len(t, leases, 1)
+		assert.Equal(t, "db-user", leases[0].Credential)
+		assert.False(t, leases[0].Revoked)
+
+		leases = r.revokeCredentials(context.Background(), leases)
+		require.Len(t, leases, 1)
+		assert.True(t, leases[0].Revoked, "the credential should be revoked")
+		assert.Empty(t, leases[0].Error)
+		assert.Equal(t, []string{leases[0].LeaseID}, r.TestCredentials.TestSecrets.InMemory().Revoked)
+	})
+
+	t.Run("revoked when validation fails", func(t *testing.T) {
+		t.Parallel()
+		r := setup(t)
+		defer r.Close()
+
+		args := ActionArguments{
+			Installation: storage.Installation{InstallationSpec: storage.InstallationSpec{CredentialSets: []string{"mycreds"}}},
+			Action:       "install",
+		}
+		_, _, err := r.loadCredentials(context.Background(), b, args, "01RUN")
+		require.ErrorContains(t, err, "bundle requires credential for kubeconfig")
+		assert.Len(t, r.TestCredentials.TestSecrets.InMemory().Revoked, 1, "the issued credential should be revoked when the run is not executed")
+		assert.Empty(t, r.TestCredentials.TestSecrets.InMemory().Leases)
+	})
+}
@@ -1,10 +1,15 @@
 package cnabprovider
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"get.porter.sh/porter/pkg/cnab"
 	"get.porter.sh/porter/pkg/cnab/drivers"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/failure"
+	"get.porter.sh/porter/pkg/tracing"
 	"github.com/cnabio/cnab-go/driver"
 	"github.com/cnabio/cnab-go/driver/docker"
 	"github.com/docker/docker/api/types/container"
@@ -18,12 +23,57 @@ const (
 	DriverNameDebug = "debug"
 )
 
-func (r *Runtime) newDriver(driverName string, args ActionArguments) (driver.Driver, error) {
+// validateDriverExtensions checks that the driver supports the extensions
+// required by the bundle, so that execution fails before credentials are
+// resolved or the run is recorded.
+func (r *Runtime) validateDriverExtensions(exts cnab.ProcessedExtensions, args ActionArguments) error {
+	_, dockerRequired, err := exts.GetDocker()
+	if err != nil {
+		return err
+	}
+
+	if dockerRequired {
+		if args.Driver != DriverNameDocker {
+			return fmt.Errorf("the bundle requires the %s extension for access to the Docker host, which is not supported by the %s driver, use the %s driver instead",
+				cnab.DockerExtensionKey, args.Driver, DriverNameDocker)
+		}
+		if !args.AllowDockerHostAccess {
+			return fmt.Errorf("the bundle requires the %s extension for access to the Docker host, run the bundle again with --allow-docker-host-access to grant it access",
+				cnab.DockerExtensionKey)
+		}
+	}
+
+	return nil
+}
+
+// validateDriverRequirements checks that the bundle supports the driver. When
+// the driver policy is warn, a warning is logged instead of returning an error.
+func (r *Runtime) validateDriverRequirements(ctx context.Context, b cnab.ExtendedBundle, args ActionArguments) error {
+	log := tracing.LoggerFromContext(ctx)
+
+	requirements, err := b.ReadDriverRequirements()
+	if err != nil {
+		return err
+	}
+	if requirements.IsSupported(args.Driver) {
+		return nil
+	}
+
+	msg := fmt.Sprintf("the bundle does not support the %s driver, the supported drivers are: %s",
+		args.Driver, strings.Join(requirements.Supported, ", "))
+	if args.DriverPolicy == config.DriverPolicyWarn {
+		log.Warn(msg)
+		return nil
+	}
+	return failure.PolicyDenied(fmt.Errorf("%s. Use --driver to select a supported driver, or --driver-policy %s to run the bundle anyway", msg, config.DriverPolicyWarn))
+}
+
+func (r *Runtime) newDriver(exts cnab.ProcessedExtensions, driverName string, args ActionArguments) (driver.Driver, error) {
 	var driverImpl driver.Driver
 	var err error
 
 	// Pull applicable extension from list of processed extensions
-	dockerExt, dockerRequired, err := r.Extensions.GetDocker()
+	dockerExt, dockerRequired, err := exts.GetDocker()
 	if err != nil {
 		return nil, err
 	}
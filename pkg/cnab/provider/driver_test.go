@@ -1,9 +1,13 @@
 package cnabprovider
 
 import (
+	"context"
 	"testing"
 
 	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/failure"
+	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-go/driver/docker"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,7 +22,7 @@ func TestNewDriver_Docker(t *testing.T) {
 		r := NewTestRuntime(t)
 		defer r.Close()
 
-		driver, err := r.newDriver(DriverNameDocker, ActionArguments{})
+		driver, err := r.newDriver(cnab.ProcessedExtensions{}, DriverNameDocker, ActionArguments{})
 
 		require.NoError(t, err)
 		assert.IsType(t, driver, &docker.Driver{})
@@ -37,7 +41,7 @@ func TestNewDriver_Docker(t *testing.T) {
 			AllowDockerHostAccess: true,
 		}
 
-		driver, err := r.newDriver(DriverNameDocker, args)
+		driver, err := r.newDriver(cnab.ProcessedExtensions{}, DriverNameDocker, args)
 
 		require.NoError(t, err)
 		assert.IsType(t, driver, &docker.Driver{})
@@ -54,7 +58,7 @@ func TestNewDriver_Docker(t *testing.T) {
 			AllowDockerHostAccess: true,
 		}
 
-		_, err := r.newDriver("custom-driver", args)
+		_, err := r.newDriver(cnab.ProcessedExtensions{}, "custom-driver", args)
 
 		assert.EqualError(t, err, "allow-docker-host-access was enabled, but the driver is custom-driver")
 	})
@@ -68,13 +72,13 @@ func TestNewDriver_Docker(t *testing.T) {
 
 		// Currently, toggling Privileged is the only config exposed to users
 		// Here we supply no override, so expect Privileged to be false
-		r.Extensions[cnab.DockerExtensionKey] = cnab.Docker{}
+		exts := cnab.ProcessedExtensions{cnab.DockerExtensionKey: cnab.Docker{}}
 		r.FileSystem.Create("/var/run/docker.sock")
 		args := ActionArguments{
 			AllowDockerHostAccess: true,
 		}
 
-		driver, err := r.newDriver(DriverNameDocker, args)
+		driver, err := r.newDriver(exts, DriverNameDocker, args)
 		require.NoError(t, err)
 		assert.IsType(t, driver, &docker.Driver{})
 
@@ -98,15 +102,15 @@ func TestNewDriver_Docker(t *testing.T) {
 
 		// Currently, toggling Privileged is the only config exposed to users
 		// Here we supply an override, so expect Privileged to be set to the override
-		r.Extensions[cnab.DockerExtensionKey] = cnab.Docker{
+		exts := cnab.ProcessedExtensions{cnab.DockerExtensionKey: cnab.Docker{
 			Privileged: true,
-		}
+		}}
 		r.FileSystem.Create("/var/run/docker.sock")
 		args := ActionArguments{
 			AllowDockerHostAccess: true,
 		}
 
-		driver, err := r.newDriver(DriverNameDocker, args)
+		driver, err := r.newDriver(exts, DriverNameDocker, args)
 		require.NoError(t, err)
 		assert.IsType(t, driver, &docker.Driver{})
 
@@ -121,3 +125,86 @@ func TestNewDriver_Docker(t *testing.T) {
 		require.Equal(t, true, containerHostCfg.Privileged)
 	})
 }
+
+func TestRuntime_validateDriverExtensions(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		required bool
+		args     ActionArguments
+		wantErr  string
+	}{
+		{name: "not required", args: ActionArguments{Driver: "kubernetes"}},
+		{name: "docker with host access", required: true, args: ActionArguments{Driver: DriverNameDocker, AllowDockerHostAccess: true}},
+		{name: "docker without host access", required: true, args: ActionArguments{Driver: DriverNameDocker},
+			wantErr: "run the bundle again with --allow-docker-host-access"},
+		{name: "unsupported driver", required: true, args: ActionArguments{Driver: "kubernetes", AllowDockerHostAccess: true},
+			wantErr: "which is not supported by the kubernetes driver, use the docker driver instead"},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := NewTestRuntime(t)
+			defer r.Close()
+
+			exts := cnab.ProcessedExtensions{}
+			if tc.required {
+				exts[cnab.DockerExtensionKey] = cnab.Docker{}
+			}
+
+			err := r.validateDriverExtensions(exts, tc.args)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), cnab.DockerExtensionKey)
+				assert.Contains(t, err.Error(), tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuntime_validateDriverRequirements(t *testing.T) {
+	t.Parallel()
+
+	b := cnab.NewBundle(bundle.Bundle{
+		Custom: map[string]interface{}{
+			cnab.DriverExtensionKey: map[string]interface{}{"supported": []interface{}{"kubernetes"}},
+		},
+	})
+
+	testcases := []struct {
+		name    string
+		bundle  cnab.ExtendedBundle
+		args    ActionArguments
+		wantErr string
+	}{
+		{name: "no requirements", bundle: cnab.NewBundle(bundle.Bundle{}), args: ActionArguments{Driver: DriverNameDocker}},
+		{name: "supported driver", bundle: b, args: ActionArguments{Driver: "kubernetes"}},
+		{name: "unsupported driver", bundle: b, args: ActionArguments{Driver: DriverNameDocker},
+			wantErr: "the bundle does not support the docker driver, the supported drivers are: kubernetes. Use --driver to select a supported driver, or --driver-policy warn to run the bundle anyway"},
+		{name: "unsupported driver with enforce policy", bundle: b, args: ActionArguments{Driver: DriverNameDocker, DriverPolicy: config.DriverPolicyEnforce},
+			wantErr: "the bundle does not support the docker driver"},
+		{name: "unsupported driver with warn policy", bundle: b, args: ActionArguments{Driver: DriverNameDocker, DriverPolicy: config.DriverPolicyWarn}},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := NewTestRuntime(t)
+			defer r.Close()
+
+			err := r.validateDriverRequirements(context.Background(), tc.bundle, tc.args)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.wantErr)
+				assert.True(t, failure.Is(err, failure.ClassPolicyDenied), "expected a policy denial")
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package cnabprovider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/cnab"
+	configadapter "get.porter.sh/porter/pkg/cnab/config-adapter"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/docker/docker/client"
+)
+
+// driverVersionTimeout limits how long to wait for the driver to report its
+// version, so that an unresponsive driver does not delay the run.
+const driverVersionTimeout = 5 * time.Second
+
+// getRunEnvironment records the executor environment of a run, so that the
+// environments of two runs can be compared with porter installation runs env-diff.
+func (r *Runtime) getRunEnvironment(ctx context.Context, args ActionArguments, b cnab.ExtendedBundle) *storage.RunEnvironment {
+	log := tracing.LoggerFromContext(ctx)
+
+	env := &storage.RunEnvironment{
+		PorterVersion: pkg.Version,
+		PorterCommit:  pkg.Commit,
+		Driver:        args.Driver,
+		DriverVersion: r.getDriverVersion(ctx, args.Driver),
+	}
+
+	if b.IsPorterBundle() {
+		stamp, err := configadapter.LoadStamp(b)
+		if err != nil {
+			log.Debugf("could not record the mixins used by the bundle: %s", err)
+		} else if len(stamp.Mixins) > 0 {
+			env.Mixins = make(map[string]string, len(stamp.Mixins))
+			for name, mixin := range stamp.Mixins {
+				env.Mixins[name] = mixin.Version
+			}
+		}
+	}
+
+	images := make(map[string]string, len(b.InvocationImages)+len(b.Images))
+	for i, img := range b.InvocationImages {
+		name := "invocationImage"
+		if i > 0 {
+			name = fmt.Sprintf("invocationImage[%d]", i)
+		}
+		images[name] = imageDigestOrReference(img.Digest, img.Image)
+	}
+	for name, img := range b.Images {
+		images[name] = imageDigestOrReference(img.Digest, img.Image)
+	}
+	if len(images) > 0 {
+		env.Images = images
+	}
+
+	return env
+}
+
+// getDriverVersion returns the version reported by the driver, or an empty
+// string when the driver does not report a version or it is unavailable.
+func (r *Runtime) getDriverVersion(ctx context.Context, driverName string) string {
+	if driverName != DriverNameDocker {
+		return ""
+	}
+
+	log := tracing.LoggerFromContext(ctx)
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		log.Debugf("could not record the version of the docker driver: %s", err)
+		return ""
+	}
+	defer cli.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, driverVersionTimeout)
+	defer cancel()
+	v, err := cli.ServerVersion(ctx)
+	if err != nil {
+		log.Debugf("could not record the version of the docker driver: %s", err)
+		return ""
+	}
+	return v.Version
+}
+
+func imageDigestOrReference(digest string, reference string) string {
+	if digest != "" {
+		return digest
+	}
+	return reference
+}
@@ -0,0 +1,47 @@
+package cnabprovider
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/cnab"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntime_getRunEnvironment(t *testing.T) {
+	t.Parallel()
+
+	r := NewTestRuntime(t)
+	defer r.Close()
+
+	b := cnab.NewBundle(bundle.Bundle{
+		Custom: map[string]interface{}{
+			cnab.PorterExtension: map[string]interface{}{
+				"mixins": map[string]interface{}{
+					"exec":  map[string]interface{}{"version": "v1.0.0"},
+					"helm3": map[string]interface{}{"version": "v0.1.16"},
+				},
+			},
+		},
+		InvocationImages: []bundle.InvocationImage{
+			{BaseImage: bundle.BaseImage{Image: "example.com/mybuns:v1.0.0", Digest: "sha256:aaa"}},
+		},
+		Images: map[string]bundle.Image{
+			"api": {BaseImage: bundle.BaseImage{Image: "example.com/api:v1.0.0"}},
+		},
+	})
+
+	env := r.getRunEnvironment(context.Background(), ActionArguments{Driver: DriverNameDebug}, b)
+
+	assert.Equal(t, pkg.Version, env.PorterVersion)
+	assert.Equal(t, pkg.Commit, env.PorterCommit)
+	assert.Equal(t, DriverNameDebug, env.Driver)
+	assert.Empty(t, env.DriverVersion, "the debug driver does not report a version")
+	assert.Equal(t, map[string]string{"exec": "v1.0.0", "helm3": "v0.1.16"}, env.Mixins)
+	assert.Equal(t, map[string]string{
+		"invocationImage": "sha256:aaa",
+		"api":             "example.com/api:v1.0.0",
+	}, env.Images, "the reference should be used when the digest of an image is unknown")
+}
@@ -0,0 +1,102 @@
+package cnabprovider
+
+import (
+	"context"
+	"io"
+	"sort"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	cnabaction "github.com/cnabio/cnab-go/action"
+	"github.com/cnabio/cnab-go/driver"
+)
+
+// runEvents reports the events of a run as they happen. The methods may be
+// called on a nil runEvents, which does nothing, so that the caller does not
+// need to check if events were requested.
+type runEvents struct {
+	writer    *storage.RunEventWriter
+	sanitizer *storage.Sanitizer
+	stdout    io.WriteCloser
+	stderr    io.WriteCloser
+}
+
+func (r *Runtime) newRunEvents(out io.Writer, run storage.Run) *runEvents {
+	writer := storage.NewRunEventWriter(out, run)
+	return &runEvents{
+		writer:    writer,
+		sanitizer: r.sanitizer,
+		stdout:    writer.LogWriter("stdout"),
+		stderr:    writer.LogWriter("stderr"),
+	}
+}
+
+// SetOutput reports the output of the bundle as log events. It must be
+// applied after the other operation configs, so that it is not overwritten.
+func (e *runEvents) SetOutput() cnabaction.OperationConfigFunc {
+	return func(op *driver.Operation) error {
+		op.Out = e.stdout
+		op.Err = e.stderr
+		return nil
+	}
+}
+
+// Emit an event, logging a warning when the event cannot be written.
+func (e *runEvents) Emit(ctx context.Context, event storage.RunEvent) {
+	if e == nil {
+		return
+	}
+
+	if err := e.writer.Emit(event); err != nil {
+		log := tracing.LoggerFromContext(ctx)
+		log.Warnf("could not write the %s event: %s", event.Type, err)
+	}
+}
+
+// Complete reports the outputs and the result of the run, after the bundle
+// has stopped running. The values of sensitive outputs are not reported,
+// unless they are ephemeral, because ephemeral outputs are not saved and this
+// is the only opportunity to see them.
+func (e *runEvents) Complete(ctx context.Context, run storage.Run, opResult driver.OperationResult, result cnab.Result, runErr error) {
+	if e == nil {
+		return
+	}
+
+	// Report any remaining partial line of the logs
+	e.stdout.Close()
+	e.stderr.Close()
+
+	bun := cnab.NewBundle(run.Bundle)
+	names := make([]string, 0, len(opResult.Outputs))
+	for name := range opResult.Outputs {
+		// Only report the outputs defined by the bundle, the logs of the bundle and the step results were already reported
+		if _, ok := run.Bundle.Outputs[name]; ok && name != storage.StepResultsOutput {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		output := storage.RunEventOutput{Name: name}
+		sensitive, err := e.sanitizer.IsOutputSensitive(bun, name)
+		output.Sensitive = sensitive || err != nil
+		if !output.Sensitive || run.IsEphemeralOutput(name) {
+			output.Value = opResult.Outputs[name]
+		}
+		e.Emit(ctx, storage.RunEvent{Type: storage.RunEventOutputCaptured, Output: &output})
+	}
+
+	status := result.Status
+	if status == "" {
+		status = cnab.StatusSucceeded
+		if runErr != nil {
+			status = cnab.StatusFailed
+		}
+	}
+	event := storage.RunEvent{Type: storage.RunEventResult, Status: status}
+	if runErr != nil {
+		event.Error = runErr.Error()
+	}
+	e.Emit(ctx, event)
+}
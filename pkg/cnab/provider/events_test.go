@@ -0,0 +1,157 @@
+package cnabprovider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/cnabio/cnab-go/driver"
+	"github.com/cnabio/cnab-go/valuesource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventsDriver writes logs, and an event from the Porter runtime, to the output of the bundle.
+type eventsDriver struct {
+	err error
+}
+
+func (d eventsDriver) Handles(string) bool {
+	return true
+}
+
+func (d eventsDriver) Run(op *driver.Operation) (driver.OperationResult, error) {
+	step, _ := storage.FormatRunEvent(storage.RunEvent{Type: storage.RunEventStepStarted, Step: &storage.StepResult{Index: 0, Description: "Install the app"}})
+	fmt.Fprintln(op.Err, step)
+	fmt.Fprintln(op.Out, "installing...")
+	fmt.Fprint(op.Err, "almost done")
+
+	return driver.OperationResult{Outputs: map[string]string{
+		"connection":                   "mysql://localhost",
+		"password":                     "topsecret",
+		"token":                        "abc123",
+		storage.StepResultsOutput:      "[]",
+		cnab.OutputInvocationImageLogs: "installing...",
+	}}, d.err
+}
+
+func TestRuntime_runEvents(t *testing.T) {
+	t.Parallel()
+
+	newRun := func() storage.Run {
+		sensitive := true
+		run := storage.NewRun("dev", "mybun")
+		run.Action = cnab.ActionInstall
+		run.Bundle = bundle.Bundle{
+			SchemaVersion: cnab.BundleSchemaVersion(),
+			Name:          "mybun",
+			Version:       "1.0.0",
+			InvocationImages: []bundle.InvocationImage{
+				{BaseImage: bundle.BaseImage{Image: "example.com/mybun:v1.0.0", ImageType: "docker"}},
+			},
+			Definitions: definition.Definitions{
+				"string":           {Type: "string"},
+				"sensitive-string": {Type: "string", WriteOnly: &sensitive},
+			},
+			Outputs: map[string]bundle.Output{
+				"connection":              {Definition: "string"},
+				"password":                {Definition: "sensitive-string"},
+				"token":                   {Definition: "sensitive-string"},
+				storage.StepResultsOutput: {Definition: "string"},
+			},
+		}
+		run.EphemeralOutputs = []string{"token"}
+		return run
+	}
+
+	execute := func(t *testing.T, r *TestRuntime, run storage.Run, d driver.Driver) []storage.RunEvent {
+		ctx := context.Background()
+		var out bytes.Buffer
+		events := r.newRunEvents(&out, run)
+		events.Emit(ctx, storage.RunEvent{Type: storage.RunEventStarted})
+		opResult, result, err := r.runAction(ctx, d, true, run.ToCNAB(), valuesource.Set{}, r.SetOutput(), events.SetOutput())
+		require.NoError(t, err)
+		events.Complete(ctx, run, opResult, result, opResult.Error)
+
+		var got []storage.RunEvent
+		scanner := bufio.NewScanner(&out)
+		for scanner.Scan() {
+			var event storage.RunEvent
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &event), "each line should be a json document: %s", scanner.Text())
+			assert.Equal(t, run.ID, event.RunID)
+			assert.Equal(t, "mybun", event.Installation)
+			assert.Equal(t, cnab.ActionInstall, event.Action)
+			assert.False(t, event.Time.IsZero(), "the time of the event should be set")
+			got = append(got, event)
+		}
+		return got
+	}
+
+	t.Run("succeeded", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewTestRuntime(t)
+		defer r.Close()
+
+		run := newRun()
+		events := execute(t, r, run, eventsDriver{})
+		require.Len(t, events, 8)
+
+		assert.Equal(t, storage.RunEventStarted, events[0].Type)
+
+		assert.Equal(t, storage.RunEventStepStarted, events[1].Type)
+		require.NotNil(t, events[1].Step)
+		assert.Equal(t, "Install the app", events[1].Step.Description)
+
+		assert.Equal(t, storage.RunEvent{Type: storage.RunEventLog, Stream: "stdout", Message: "installing..."}, clearRunDetails(events[2]))
+		assert.Equal(t, storage.RunEvent{Type: storage.RunEventLog, Stream: "stderr", Message: "almost done"}, clearRunDetails(events[3]), "the last partial line should be reported")
+
+		wantOutputs := []storage.RunEventOutput{
+			{Name: "connection", Value: "mysql://localhost"},
+			{Name: "password", Sensitive: true},
+			{Name: "token", Sensitive: true, Value: "abc123"},
+		}
+		for i, want := range wantOutputs {
+			event := events[4+i]
+			assert.Equal(t, storage.RunEventOutputCaptured, event.Type)
+			require.NotNil(t, event.Output)
+			assert.Equal(t, want, *event.Output)
+		}
+
+		assert.Equal(t, storage.RunEvent{Type: storage.RunEventResult, Status: cnab.StatusSucceeded}, clearRunDetails(events[7]))
+		assert.Empty(t, r.TestConfig.TestContext.GetOutput(), "the output of the bundle should only be reported as events")
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewTestRuntime(t)
+		defer r.Close()
+
+		events := execute(t, r, newRun(), eventsDriver{err: errors.New("container exit code: 1")})
+		last := events[len(events)-1]
+		assert.Equal(t, storage.RunEventResult, last.Type)
+		assert.Equal(t, cnab.StatusFailed, last.Status)
+		assert.Contains(t, last.Error, "container exit code: 1")
+	})
+}
+
+func clearRunDetails(event storage.RunEvent) storage.RunEvent {
+	return storage.RunEvent{
+		Type:    event.Type,
+		Stream:  event.Stream,
+		Message: event.Message,
+		Step:    event.Step,
+		Output:  event.Output,
+		Status:  event.Status,
+		Error:   event.Error,
+	}
+}
@@ -0,0 +1,49 @@
+package cnabprovider
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// runHeartbeatInterval is how often the heartbeat of a run is updated while
+// the bundle is running.
+var runHeartbeatInterval = storage.RunHeartbeatInterval
+
+// startRunHeartbeat updates the heartbeat of the run in the background, so
+// that the run can be identified as interrupted when Porter stops without
+// recording its result. The returned function stops the heartbeat.
+func (r *Runtime) startRunHeartbeat(ctx context.Context, run storage.Run) func() {
+	log := tracing.LoggerFromContext(ctx)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(runHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := r.installations.UpdateRunHeartbeat(ctx, run.ID, time.Now()); err != nil {
+					log.Warnf("could not update the heartbeat of the %s run: %s", run.Action, err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package cnabprovider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntime_StartRunHeartbeat(t *testing.T) {
+	// Do not run in parallel, it changes the heartbeat interval
+	defer func(interval time.Duration) { runHeartbeatInterval = interval }(runHeartbeatInterval)
+	runHeartbeatInterval = 10 * time.Millisecond
+
+	r := NewTestRuntime(t)
+	defer r.Close()
+
+	ctx := context.Background()
+	installation := r.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybun"))
+	run := r.TestInstallations.CreateRun(installation.NewRun(cnab.ActionInstall))
+
+	stop := r.startRunHeartbeat(ctx, run)
+	require.Eventually(t, func() bool {
+		got, err := r.TestInstallations.GetRun(ctx, run.ID)
+		return err == nil && !got.Heartbeat.IsZero()
+	}, 5*time.Second, runHeartbeatInterval, "expected the heartbeat of the run to be updated")
+	stop()
+	stop() // Stopping the heartbeat again should not panic
+
+	got, err := r.TestInstallations.GetRun(ctx, run.ID)
+	require.NoError(t, err)
+	stopped := got.Heartbeat
+	time.Sleep(5 * runHeartbeatInterval)
+	got, err = r.TestInstallations.GetRun(ctx, run.ID)
+	require.NoError(t, err)
+	assert.True(t, stopped.Equal(got.Heartbeat), "the heartbeat should not be updated after it is stopped")
+}
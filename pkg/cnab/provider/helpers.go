@@ -32,6 +32,8 @@ func NewTestRuntime(t *testing.T) *TestRuntime {
 	testStorage := storage.NewTestStore(tc)
 	testSecrets := secrets.NewTestSecretsProvider()
 	testInstallations := storage.NewTestInstallationProviderFor(tc.TestContext.T, testStorage)
+	testInstallations.SetOutputAccessControl(storage.NewNamespacePolicyAccessControl(tc.Config))
+	testInstallations.SetOutputOffload(storage.NewConfigOutputOffload(tc.Config))
 	testCredentials := storage.NewTestCredentialProviderFor(tc.TestContext.T, testStorage, testSecrets)
 	testParameters := storage.NewTestParameterProviderFor(tc.TestContext.T, testStorage, testSecrets)
 
@@ -39,8 +41,10 @@ func NewTestRuntime(t *testing.T) *TestRuntime {
 }
 
 func NewTestRuntimeFor(tc *config.TestConfig, testInstallations *storage.TestInstallationProvider, testCredentials *storage.TestCredentialSetProvider, testParameters *storage.TestParameterSetProvider, testSecrets secrets.Store) *TestRuntime {
+	sanitizer := storage.NewSanitizer(testParameters, testSecrets)
+	sanitizer.SetSensitivityPolicy(storage.NewConfigSensitivityPolicy(tc.Config))
 	return &TestRuntime{
-		Runtime:           NewRuntime(tc.Config, testInstallations, testCredentials, testSecrets, storage.NewSanitizer(testParameters, testSecrets)),
+		Runtime:           NewRuntime(tc.Config, testInstallations, testCredentials, testSecrets, sanitizer),
 		TestStorage:       storage.TestStore{},
 		TestInstallations: testInstallations,
 		TestCredentials:   testCredentials,
@@ -0,0 +1,122 @@
+package cnabprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	"get.porter.sh/porter/pkg/webhook"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// NotificationEvent is the data used to render the body of a notification
+// about a result of a run.
+type NotificationEvent struct {
+	Namespace    string    `json:"namespace"`
+	Installation string    `json:"installation"`
+	Action       string    `json:"action"`
+	Bundle       string    `json:"bundle,omitempty"`
+	RunID        string    `json:"runId"`
+	ResultID     string    `json:"resultId"`
+	Status       string    `json:"status"`
+	Message      string    `json:"message,omitempty"`
+	Created      time.Time `json:"created"`
+}
+
+// NewNotificationEvent describes a result of a run for a notification.
+func NewNotificationEvent(run storage.Run, result storage.Result) NotificationEvent {
+	return NotificationEvent{
+		Namespace:    run.Namespace,
+		Installation: run.Installation,
+		Action:       run.Action,
+		Bundle:       run.BundleReference,
+		RunID:        run.ID,
+		ResultID:     result.ID,
+		Status:       result.Status,
+		Message:      result.Message,
+		Created:      result.Created,
+	}
+}
+
+// notify sends the result of a run to the configured notifications that
+// match the run. A failed notification does not fail the run, it is logged
+// instead.
+func (r *Runtime) notify(ctx context.Context, run storage.Run, result storage.Result) {
+	if len(r.Config.Data.Notifications) == 0 {
+		return
+	}
+
+	ctx, span := tracing.StartSpan(ctx, attribute.String("status", result.Status))
+	defer span.EndSpan()
+
+	event := NewNotificationEvent(run, result)
+	for _, n := range r.Config.Data.Notifications {
+		if !n.Matches(event.Namespace, event.Action, event.Status) {
+			continue
+		}
+
+		if err := sendNotification(ctx, n, event); err != nil {
+			span.Warnf("Could not send notification %s: %s", n.GetName(), err)
+			continue
+		}
+		span.Debugf("Sent notification %s for the %s status of run %s", n.GetName(), event.Status, event.RunID)
+	}
+}
+
+// sendNotification posts the event to the notification's webhook.
+func sendNotification(ctx context.Context, n config.NotificationConfig, event NotificationEvent) error {
+	if n.URL == "" {
+		return fmt.Errorf("the url of notification %s is required", n.GetName())
+	}
+
+	timeout, err := n.GetTimeout()
+	if err != nil {
+		return err
+	}
+
+	body, err := buildNotificationBody(n.Body, event)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: timeout}
+	_, err = webhook.Send(ctx, client, http.MethodPost, n.URL, n.Headers, body)
+	return err
+}
+
+// buildNotificationBody renders the body template with the event. When a
+// body template is not specified, the event is sent as json.
+func buildNotificationBody(bodyTmpl string, event NotificationEvent) ([]byte, error) {
+	if bodyTmpl == "" {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling the notification event: %w", err)
+		}
+		return body, nil
+	}
+
+	funcs := template.FuncMap{
+		// json quotes a value so that it can be embedded in a json body
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}
+	t, err := template.New("body").Funcs(funcs).Option("missingkey=error").Parse(bodyTmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notification body template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, event); err != nil {
+		return nil, fmt.Errorf("error rendering the notification body template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,141 @@
+package cnabprovider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// notificationServer records the requests sent to a webhook.
+type notificationServer struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	bodies map[string][]string
+}
+
+func newNotificationServer(t *testing.T) *notificationServer {
+	s := &notificationServer{bodies: make(map[string][]string)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.bodies[req.URL.Path] = append(s.bodies[req.URL.Path], string(body))
+
+		if req.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *notificationServer) Bodies(path string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bodies[path]
+}
+
+func TestRuntime_notify(t *testing.T) {
+	t.Parallel()
+
+	srv := newNotificationServer(t)
+	r := NewTestRuntime(t)
+	defer r.Close()
+	r.Config.Data.Notifications = []config.NotificationConfig{
+		{Name: "all", URL: srv.URL + "/all"},
+		{Name: "broken", URL: srv.URL + "/broken"},
+		{Name: "prod-failures", URL: srv.URL + "/prod", Namespaces: []string{"prod"}, Statuses: []string{cnab.StatusFailed}},
+		{Name: "slack", URL: srv.URL + "/slack", Actions: []string{cnab.ActionUpgrade},
+			Body: `{"text": {{ printf "porter %s %s/%s: %s" .Action .Namespace .Installation .Message | json }}}`},
+	}
+
+	run := storage.NewRun("prod", "mysql")
+	run.Action = cnab.ActionUpgrade
+	started := run.NewResult(cnab.StatusRunning)
+	failed := run.NewResult(cnab.StatusFailed)
+	failed.Message = `the "migrate" step failed`
+
+	r.notify(context.Background(), run, started)
+	r.notify(context.Background(), run, failed)
+
+	all := srv.Bodies("/all")
+	require.Len(t, all, 2, "the notification without filters should be sent for every result")
+	var gotEvent NotificationEvent
+	require.NoError(t, json.Unmarshal([]byte(all[1]), &gotEvent))
+	assert.Equal(t, NewNotificationEvent(run, failed).ResultID, gotEvent.ResultID)
+	assert.Equal(t, cnab.StatusFailed, gotEvent.Status)
+	assert.Equal(t, "mysql", gotEvent.Installation)
+
+	assert.Len(t, srv.Bodies("/broken"), 2, "a failed notification should not stop the other notifications")
+
+	prod := srv.Bodies("/prod")
+	require.Len(t, prod, 1, "only the failed result should match the status filter")
+	assert.Contains(t, prod[0], `"status":"failed"`)
+
+	slack := srv.Bodies("/slack")
+	require.Len(t, slack, 2)
+	assert.Equal(t, `{"text": "porter upgrade prod/mysql: the \"migrate\" step failed"}`, slack[1])
+}
+
+func TestRuntime_notify_Filters(t *testing.T) {
+	t.Parallel()
+
+	srv := newNotificationServer(t)
+	r := NewTestRuntime(t)
+	defer r.Close()
+	r.Config.Data.Notifications = []config.NotificationConfig{
+		{URL: srv.URL + "/dev", Namespaces: []string{"dev"}},
+		{URL: srv.URL + "/install", Actions: []string{cnab.ActionInstall}},
+	}
+
+	run := storage.NewRun("prod", "mysql")
+	run.Action = cnab.ActionUpgrade
+	r.notify(context.Background(), run, run.NewResult(cnab.StatusSucceeded))
+
+	assert.Empty(t, srv.Bodies("/dev"))
+	assert.Empty(t, srv.Bodies("/install"))
+}
+
+func TestRuntime_SaveRun_Notifies(t *testing.T) {
+	t.Parallel()
+
+	srv := newNotificationServer(t)
+	r := NewTestRuntime(t)
+	defer r.Close()
+	r.Config.Data.Notifications = []config.NotificationConfig{
+		{URL: srv.URL + "/started", Statuses: []string{cnab.StatusRunning}},
+	}
+
+	i := storage.NewInstallation("dev", "mybuns")
+	run := i.NewRun(cnab.ActionInstall)
+	require.NoError(t, r.SaveRun(context.Background(), i, run, cnab.StatusRunning))
+
+	bodies := srv.Bodies("/started")
+	require.Len(t, bodies, 1, "starting a run should send a notification")
+	assert.Contains(t, bodies[0], `"runId":"`+run.ID+`"`)
+}
+
+func TestBuildNotificationBody_InvalidTemplate(t *testing.T) {
+	t.Parallel()
+
+	_, err := buildNotificationBody("{{ .Missing }}", NotificationEvent{})
+	require.ErrorContains(t, err, "error rendering the notification body template")
+
+	_, err = buildNotificationBody("{{ .Status", NotificationEvent{})
+	require.ErrorContains(t, err, "invalid notification body template")
+}
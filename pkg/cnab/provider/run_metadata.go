@@ -0,0 +1,204 @@
+package cnabprovider
+
+import (
+	"net/url"
+	"strings"
+
+	"get.porter.sh/porter/pkg/storage"
+)
+
+// RunMetadataDetector captures metadata about where a run was started from,
+// such as a CI pipeline, from the environment variables that it sets.
+type RunMetadataDetector interface {
+	// Name of the detector, which is recorded as the source of the metadata.
+	Name() string
+
+	// Detect returns the metadata found in the environment, and false when
+	// the run was not started from the environment that it detects.
+	Detect(getenv func(string) string) (storage.RunMetadata, bool)
+}
+
+// envDetector detects metadata from a fixed set of environment variables.
+type envDetector struct {
+	name string
+
+	// marker is an environment variable that is set only in the detected
+	// environment.
+	marker string
+
+	commit     string
+	branch     string
+	repository string
+	user       string
+
+	// pipelineURL builds the link to the pipeline from the environment.
+	pipelineURL func(getenv func(string) string) string
+}
+
+func (d envDetector) Name() string {
+	return d.name
+}
+
+func (d envDetector) Detect(getenv func(string) string) (storage.RunMetadata, bool) {
+	if getenv(d.marker) == "" {
+		return storage.RunMetadata{}, false
+	}
+
+	lookup := func(key string) string {
+		if key == "" {
+			return ""
+		}
+		return getenv(key)
+	}
+	md := storage.RunMetadata{
+		Source:     d.name,
+		Commit:     lookup(d.commit),
+		Branch:     lookup(d.branch),
+		Repository: withoutUserinfo(lookup(d.repository)),
+		User:       lookup(d.user),
+	}
+	if d.pipelineURL != nil {
+		md.PipelineURL = withoutUserinfo(d.pipelineURL(getenv))
+	}
+	return md, true
+}
+
+// withoutUserinfo removes the username and password from a url, because CI
+// systems such as Jenkins include the credentials used to clone the
+// repository in its url. Values that are not urls are returned unchanged.
+func withoutUserinfo(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	u.User = nil
+	return u.String()
+}
+
+// envValue returns a function that reads the pipeline url from a single
+// environment variable.
+func envValue(key string) func(func(string) string) string {
+	return func(getenv func(string) string) string {
+		return getenv(key)
+	}
+}
+
+// DefaultRunMetadataDetectors returns the detectors for the CI systems that
+// Porter recognizes. The first detector that matches the environment is used,
+// so the generic detector is last.
+func DefaultRunMetadataDetectors() []RunMetadataDetector {
+	return []RunMetadataDetector{
+		envDetector{
+			name:       "github-actions",
+			marker:     "GITHUB_ACTIONS",
+			commit:     "GITHUB_SHA",
+			branch:     "GITHUB_REF_NAME",
+			repository: "GITHUB_REPOSITORY",
+			user:       "GITHUB_ACTOR",
+			pipelineURL: func(getenv func(string) string) string {
+				server, repo, id := getenv("GITHUB_SERVER_URL"), getenv("GITHUB_REPOSITORY"), getenv("GITHUB_RUN_ID")
+				if server == "" || repo == "" || id == "" {
+					return ""
+				}
+				return strings.TrimSuffix(server, "/") + "/" + repo + "/actions/runs/" + id
+			},
+		},
+		envDetector{
+			name:        "gitlab-ci",
+			marker:      "GITLAB_CI",
+			commit:      "CI_COMMIT_SHA",
+			branch:      "CI_COMMIT_REF_NAME",
+			repository:  "CI_PROJECT_PATH",
+			user:        "GITLAB_USER_LOGIN",
+			pipelineURL: envValue("CI_JOB_URL"),
+		},
+		envDetector{
+			name:       "azure-pipelines",
+			marker:     "TF_BUILD",
+			commit:     "BUILD_SOURCEVERSION",
+			branch:     "BUILD_SOURCEBRANCHNAME",
+			repository: "BUILD_REPOSITORY_NAME",
+			user:       "BUILD_REQUESTEDFOR",
+			pipelineURL: func(getenv func(string) string) string {
+				server, project, id := getenv("SYSTEM_COLLECTIONURI"), getenv("SYSTEM_TEAMPROJECT"), getenv("BUILD_BUILDID")
+				if server == "" || project == "" || id == "" {
+					return ""
+				}
+				return strings.TrimSuffix(server, "/") + "/" + project + "/_build/results?buildId=" + id
+			},
+		},
+		envDetector{
+			name:        "circleci",
+			marker:      "CIRCLECI",
+			commit:      "CIRCLE_SHA1",
+			branch:      "CIRCLE_BRANCH",
+			repository:  "CIRCLE_REPOSITORY_URL",
+			user:        "CIRCLE_USERNAME",
+			pipelineURL: envValue("CIRCLE_BUILD_URL"),
+		},
+		envDetector{
+			name:        "jenkins",
+			marker:      "JENKINS_URL",
+			commit:      "GIT_COMMIT",
+			branch:      "GIT_BRANCH",
+			repository:  "GIT_URL",
+			user:        "BUILD_USER_ID",
+			pipelineURL: envValue("BUILD_URL"),
+		},
+		envDetector{
+			name:        "generic",
+			marker:      "GIT_COMMIT",
+			commit:      "GIT_COMMIT",
+			branch:      "GIT_BRANCH",
+			repository:  "GIT_URL",
+			pipelineURL: envValue("BUILD_URL"),
+		},
+	}
+}
+
+// getRunMetadata captures where the run was started from with the first
+// detector that matches the environment. Returns nil when metadata is
+// disabled or nothing was captured.
+func (r *Runtime) getRunMetadata() *storage.RunMetadata {
+	cfg := r.Config.Data.RunMetadata
+	if cfg.Disabled {
+		return nil
+	}
+
+	var md storage.RunMetadata
+	for _, d := range r.MetadataDetectors {
+		if !isDetectorEnabled(cfg.Detectors, d.Name()) {
+			continue
+		}
+		if found, ok := d.Detect(r.Getenv); ok {
+			md = found
+			break
+		}
+	}
+
+	if cfg.ExcludeUser {
+		md.User = ""
+	} else if md.User == "" {
+		md.User = r.Getenv("USER")
+		if md.User == "" {
+			md.User = r.Getenv("USERNAME")
+		}
+	}
+
+	if md.IsEmpty() {
+		return nil
+	}
+	return &md
+}
+
+func isDetectorEnabled(enabled []string, name string) bool {
+	if len(enabled) == 0 {
+		return true
+	}
+	for _, e := range enabled {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
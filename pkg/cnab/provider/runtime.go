@@ -1,9 +1,6 @@
 package cnabprovider
 
 import (
-	"fmt"
-
-	"get.porter.sh/porter/pkg/cnab"
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/secrets"
 	"get.porter.sh/porter/pkg/storage"
@@ -19,7 +16,10 @@ type Runtime struct {
 	secrets       secrets.Store
 	installations storage.InstallationProvider
 	sanitizer     *storage.Sanitizer
-	Extensions    cnab.ProcessedExtensions
+
+	// MetadataDetectors capture where a run was started from, such as a CI
+	// pipeline. The first detector that matches the environment is used.
+	MetadataDetectors []RunMetadataDetector
 }
 
 func NewRuntime(c *config.Config, installations storage.InstallationProvider, credentials storage.CredentialSetProvider, secrets secrets.Store, sanitizer *storage.Sanitizer) *Runtime {
@@ -29,15 +29,7 @@ func NewRuntime(c *config.Config, installations storage.InstallationProvider, cr
 		credentials:   credentials,
 		secrets:       secrets,
 		sanitizer:     sanitizer,
-		Extensions:    cnab.ProcessedExtensions{},
-	}
-}
 
-func (r *Runtime) ProcessRequiredExtensions(b cnab.ExtendedBundle) error {
-	exts, err := b.ProcessRequiredExtensions()
-	if err != nil {
-		return fmt.Errorf("unable to process required extensions: %w", err)
+		MetadataDetectors: DefaultRunMetadataDetectors(),
 	}
-	r.Extensions = exts
-	return nil
 }
@@ -0,0 +1,221 @@
+package cnabprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/failure"
+	"get.porter.sh/porter/pkg/faults"
+	"get.porter.sh/porter/pkg/tracing"
+	cnabaction "github.com/cnabio/cnab-go/action"
+	"github.com/cnabio/cnab-go/driver"
+	"github.com/cnabio/cnab-go/driver/docker"
+	"github.com/cnabio/cnab-go/driver/kubernetes"
+	"github.com/cnabio/cnab-go/valuesource"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/hashicorp/go-multierror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// DefaultStopGracePeriod is the amount of time that a bundle is given to
+	// exit after it is asked to stop, before it is forcefully terminated.
+	DefaultStopGracePeriod = 30 * time.Second
+
+	// stopDriverTimeout is how long to wait, after the grace period, for the
+	// driver to return once the bundle has been asked to stop.
+	stopDriverTimeout = 30 * time.Second
+
+	// dockerRunLabel is applied to the container executing a run, so that it
+	// can be found later if the run must be stopped.
+	dockerRunLabel = "sh.porter.run"
+
+	// kubernetesRunLabel is applied to the job and pod executing a run, so
+	// that they can be found later if the run must be stopped.
+	kubernetesRunLabel = "porter.sh/run"
+)
+
+// StoppableDriver is a driver that can gracefully stop a running bundle.
+type StoppableDriver interface {
+	driver.Driver
+
+	// Stop asks the bundle to exit, allowing it the specified grace period to
+	// clean up before it is forcefully terminated.
+	Stop(ctx context.Context, gracePeriod time.Duration) error
+}
+
+// makeStoppable wraps the drivers that support stopping a running bundle so
+// that the resources created for the run can be found and stopped.
+// Drivers that can't be stopped are returned unchanged.
+func makeStoppable(d driver.Driver, runID string) driver.Driver {
+	switch typedDriver := d.(type) {
+	case *docker.Driver:
+		return newStoppableDockerDriver(typedDriver, runID)
+	case *kubernetes.Driver:
+		return newStoppableKubernetesDriver(typedDriver, runID)
+	default:
+		return d
+	}
+}
+
+var _ StoppableDriver = &stoppableDockerDriver{}
+
+// stoppableDockerDriver stops a bundle running on docker by stopping its
+// container, which sends SIGTERM and then SIGKILL after the grace period.
+type stoppableDockerDriver struct {
+	*docker.Driver
+	runID string
+}
+
+func newStoppableDockerDriver(d *docker.Driver, runID string) *stoppableDockerDriver {
+	d.AddConfigurationOptions(func(cfg *container.Config, hostCfg *container.HostConfig) error {
+		if cfg.Labels == nil {
+			cfg.Labels = make(map[string]string, 1)
+		}
+		cfg.Labels[dockerRunLabel] = runID
+		return nil
+	})
+	return &stoppableDockerDriver{Driver: d, runID: runID}
+}
+
+func (d *stoppableDockerDriver) Stop(ctx context.Context, gracePeriod time.Duration) error {
+	cli, err := docker.GetDockerClient()
+	if err != nil {
+		return fmt.Errorf("error connecting to docker: %w", err)
+	}
+
+	containers, err := cli.Client().ContainerList(ctx, types.ContainerListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", dockerRunLabel+"="+d.runID)),
+	})
+	if err != nil {
+		return fmt.Errorf("error listing the containers for run %s: %w", d.runID, err)
+	}
+
+	for _, c := range containers {
+		if err = cli.Client().ContainerStop(ctx, c.ID, &gracePeriod); err != nil {
+			return fmt.Errorf("error stopping container %s for run %s: %w", c.ID, d.runID, err)
+		}
+	}
+	return nil
+}
+
+var _ StoppableDriver = &stoppableKubernetesDriver{}
+
+// stoppableKubernetesDriver stops a bundle running on kubernetes by deleting
+// its pod with a grace period, which sends SIGTERM to the bundle.
+type stoppableKubernetesDriver struct {
+	*kubernetes.Driver
+	runID string
+}
+
+func newStoppableKubernetesDriver(d *kubernetes.Driver, runID string) *stoppableKubernetesDriver {
+	d.Labels = append(d.Labels, kubernetesRunLabel+"="+runID)
+	return &stoppableKubernetesDriver{Driver: d, runID: runID}
+}
+
+func (d *stoppableKubernetesDriver) Stop(ctx context.Context, gracePeriod time.Duration) error {
+	var conf *rest.Config
+	var err error
+	if d.InCluster {
+		conf, err = rest.InClusterConfig()
+	} else {
+		conf, err = clientcmd.BuildConfigFromFlags(d.MasterURL, d.Kubeconfig)
+	}
+	if err != nil {
+		return fmt.Errorf("error retrieving the kubernetes configuration: %w", err)
+	}
+
+	coreClient, err := coreclientv1.NewForConfig(conf)
+	if err != nil {
+		return fmt.Errorf("error creating a kubernetes client: %w", err)
+	}
+
+	gracePeriodSeconds := int64(gracePeriod.Seconds())
+	err = coreClient.Pods(d.Namespace).DeleteCollection(ctx,
+		metav1.DeleteOptions{GracePeriodSeconds: &gracePeriodSeconds},
+		metav1.ListOptions{LabelSelector: kubernetesRunLabel + "=" + d.runID})
+	if err != nil {
+		return fmt.Errorf("error deleting the pods for run %s: %w", d.runID, err)
+	}
+	return nil
+}
+
+// runAction executes the bundle with the driver. When the context is
+// cancelled or its deadline is exceeded before the bundle completes, the
+// driver is asked to stop the bundle gracefully and the result is recorded as
+// timed out or canceled. We wait for the driver to return so that any logs
+// captured by the driver are preserved.
+func (r *Runtime) runAction(ctx context.Context, d driver.Driver, saveLogs bool, c cnab.Claim, creds valuesource.Set, opCfgs ...cnabaction.OperationConfigFunc) (driver.OperationResult, cnab.Result, error) {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	type actionResult struct {
+		opResult driver.OperationResult
+		result   cnab.Result
+		err      error
+	}
+
+	// Fail before the bundle runs when a failure was requested for testing
+	if err := faults.FromEnv(r.Getenv).Inject(faults.DriverRun, c.Action); err != nil {
+		return driver.OperationResult{}, cnab.Result{}, log.Error(err)
+	}
+
+	a := cnabaction.New(d)
+	a.SaveLogs = saveLogs
+
+	done := make(chan actionResult, 1)
+	go func() {
+		opResult, result, err := a.Run(c, creds, opCfgs...)
+		done <- actionResult{opResult: opResult, result: result, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.opResult, res.result, res.err
+	case <-ctx.Done():
+	}
+
+	status := cnab.StatusCanceled
+	stopErr := fmt.Errorf("the %s action was canceled", c.Action)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		status = cnab.StatusTimedOut
+		stopErr = failure.Timeout(fmt.Errorf("the %s action timed out", c.Action))
+	}
+
+	// Use a new context because the current one is already done
+	stopCtx, cancel := context.WithTimeout(context.Background(), DefaultStopGracePeriod+stopDriverTimeout)
+	defer cancel()
+
+	if stoppable, ok := d.(StoppableDriver); ok {
+		log.Warnf("%s, stopping the bundle...", stopErr)
+		if err := stoppable.Stop(stopCtx, DefaultStopGracePeriod); err != nil {
+			log.Warnf("could not stop the bundle: %s", err)
+		}
+	} else {
+		log.Warnf("%s, but the driver does not support stopping a running bundle", stopErr)
+	}
+
+	var res actionResult
+	select {
+	case res = <-done:
+		if res.err != nil {
+			return res.opResult, res.result, res.err
+		}
+	case <-stopCtx.Done():
+		// The driver didn't return so there are no outputs or logs to save
+		res.result, _ = c.NewResult(status)
+	}
+
+	res.result.Status = status
+	res.result.Message = stopErr.Error()
+	res.opResult.Error = multierror.Append(stopErr, res.opResult.Error).ErrorOrNil()
+	return res.opResult, res.result, nil
+}
@@ -0,0 +1,175 @@
+package cnabprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/faults"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/driver"
+	"github.com/cnabio/cnab-go/driver/docker"
+	"github.com/cnabio/cnab-go/driver/kubernetes"
+	"github.com/cnabio/cnab-go/valuesource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ StoppableDriver = &blockingDriver{}
+
+// blockingDriver runs until it is asked to stop.
+type blockingDriver struct {
+	stopped chan struct{}
+}
+
+func newBlockingDriver() *blockingDriver {
+	return &blockingDriver{stopped: make(chan struct{})}
+}
+
+func (d *blockingDriver) Handles(string) bool {
+	return true
+}
+
+func (d *blockingDriver) Run(op *driver.Operation) (driver.OperationResult, error) {
+	fmt.Fprintln(op.Out, "installing...")
+	<-d.stopped
+	fmt.Fprintln(op.Out, "received SIGTERM, cleaning up")
+	return driver.OperationResult{Outputs: map[string]string{}}, errors.New("container exit code: 143")
+}
+
+func (d *blockingDriver) Stop(ctx context.Context, gracePeriod time.Duration) error {
+	close(d.stopped)
+	return nil
+}
+
+// completedDriver finishes immediately.
+type completedDriver struct{}
+
+func (d completedDriver) Handles(string) bool {
+	return true
+}
+
+func (d completedDriver) Run(op *driver.Operation) (driver.OperationResult, error) {
+	fmt.Fprintln(op.Out, "installed")
+	return driver.OperationResult{Outputs: map[string]string{}}, nil
+}
+
+func newTestClaim() cnab.Claim {
+	run := storage.NewRun("dev", "mybun")
+	run.Action = cnab.ActionInstall
+	run.Bundle = bundle.Bundle{
+		SchemaVersion: cnab.BundleSchemaVersion(),
+		Name:          "mybun",
+		Version:       "1.0.0",
+		InvocationImages: []bundle.InvocationImage{
+			{BaseImage: bundle.BaseImage{Image: "example.com/mybun:v1.0.0", ImageType: "docker"}},
+		},
+	}
+	return run.ToCNAB()
+}
+
+func TestRuntime_runAction(t *testing.T) {
+	t.Parallel()
+
+	t.Run("completed", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewTestRuntime(t)
+		defer r.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		opResult, result, err := r.runAction(ctx, completedDriver{}, true, newTestClaim(), valuesource.Set{}, r.SetOutput())
+		require.NoError(t, err)
+		require.NoError(t, opResult.Error)
+		assert.Equal(t, cnab.StatusSucceeded, result.Status)
+	})
+
+	t.Run("timed out", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewTestRuntime(t)
+		defer r.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		opResult, result, err := r.runAction(ctx, newBlockingDriver(), true, newTestClaim(), valuesource.Set{}, r.SetOutput())
+		require.NoError(t, err)
+		require.ErrorContains(t, opResult.Error, "the install action timed out")
+		assert.Equal(t, cnab.StatusTimedOut, result.Status)
+		assert.Equal(t, "the install action timed out", result.Message)
+
+		logs := opResult.Outputs[cnab.OutputInvocationImageLogs]
+		assert.Contains(t, logs, "received SIGTERM, cleaning up", "the logs captured while stopping the bundle should be preserved")
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewTestRuntime(t)
+		defer r.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+
+		opResult, result, err := r.runAction(ctx, newBlockingDriver(), true, newTestClaim(), valuesource.Set{}, r.SetOutput())
+		require.NoError(t, err)
+		require.ErrorContains(t, opResult.Error, "the install action was canceled")
+		assert.Equal(t, cnab.StatusCanceled, result.Status)
+	})
+
+	t.Run("injected fault", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewTestRuntime(t)
+		defer r.Close()
+		r.TestConfig.TestContext.Setenv(config.EnvInjectFaults, "driver-run=install")
+
+		_, _, err := r.runAction(context.Background(), completedDriver{}, true, newTestClaim(), valuesource.Set{}, r.SetOutput())
+		require.ErrorIs(t, err, faults.ErrInjectedFault{Point: faults.DriverRun, Target: cnab.ActionInstall})
+	})
+}
+
+func TestMakeStoppable(t *testing.T) {
+	t.Parallel()
+
+	t.Run("docker", func(t *testing.T) {
+		t.Parallel()
+
+		d := makeStoppable(&docker.Driver{}, "myrun")
+		stoppable, ok := d.(*stoppableDockerDriver)
+		require.True(t, ok, "expected the docker driver to be stoppable")
+
+		require.NoError(t, stoppable.ApplyConfigurationOptions())
+		cfg, err := stoppable.GetContainerConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "myrun", cfg.Labels[dockerRunLabel], "expected the container to be labeled with the run id")
+	})
+
+	t.Run("kubernetes", func(t *testing.T) {
+		t.Parallel()
+
+		d := makeStoppable(&kubernetes.Driver{}, "myrun")
+		stoppable, ok := d.(*stoppableKubernetesDriver)
+		require.True(t, ok, "expected the kubernetes driver to be stoppable")
+		assert.Contains(t, stoppable.Labels, kubernetesRunLabel+"=myrun", "expected the job to be labeled with the run id")
+	})
+
+	t.Run("unsupported driver", func(t *testing.T) {
+		t.Parallel()
+
+		d := makeStoppable(completedDriver{}, "myrun")
+		_, ok := d.(StoppableDriver)
+		assert.False(t, ok)
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"sort"
 
 	depsv1 "get.porter.sh/porter/pkg/cnab/dependencies/v1"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/failure"
 	"github.com/Masterminds/semver/v3"
 	"github.com/google/go-containerregistry/pkg/crane"
 )
@@ -12,10 +14,15 @@ import (
 type DependencyLock struct {
 	Alias     string
 	Reference string
+
+	// DependsOn are the aliases of the dependencies that must be executed first.
+	DependsOn []string
 }
 
 // TODO: move this logic onto the new ExtendedBundle struct
 type DependencySolver struct {
+	// Policy restricts the sources and versions of the resolved dependencies.
+	Policy config.DependencyPolicy
 }
 
 func (s *DependencySolver) ResolveDependencies(bun ExtendedBundle) ([]DependencyLock, error) {
@@ -38,9 +45,14 @@ func (s *DependencySolver) ResolveDependencies(bun ExtendedBundle) ([]Dependency
 			return nil, err
 		}
 
+		if err = s.checkPolicy(dep.Name, ref); err != nil {
+			return nil, err
+		}
+
 		lock := DependencyLock{
 			Alias:     dep.Name,
 			Reference: ref.String(),
+			DependsOn: dep.DependsOn,
 		}
 		q = append(q, lock)
 	}
@@ -73,6 +85,33 @@ func (s *DependencySolver) ResolveVersion(name string, dep depsv1.Dependency) (O
 	return OCIReference{}, fmt.Errorf("not implemented: dependency version range specified for %s: %w", name, err)
 }
 
+// checkPolicy returns an error when the dependency policy does not allow the
+// resolved dependency.
+func (s *DependencySolver) checkPolicy(name string, ref OCIReference) error {
+	repository := ref.Named.Name()
+	if !s.Policy.AllowsSource(repository) {
+		return failure.PolicyDenied(fmt.Errorf("dependency %s (%s) is denied by the dependency policy: %s is not an allowed source", name, ref, repository))
+	}
+
+	for _, floor := range s.Policy.GetMinimumVersions(repository) {
+		minVersion, err := semver.NewVersion(floor.Version)
+		if err != nil {
+			return fmt.Errorf("invalid dependency policy: the minimum version %q for %s is not a valid semantic version: %w", floor.Version, floor.Source, err)
+		}
+
+		if !ref.HasVersion() {
+			return failure.PolicyDenied(fmt.Errorf("dependency %s (%s) is denied by the dependency policy: the version could not be determined from the tag and %s requires at least version %s", name, ref, floor.Source, floor.Version))
+		}
+
+		version, _ := semver.NewVersion(ref.Tag())
+		if version.LessThan(minVersion) {
+			return failure.PolicyDenied(fmt.Errorf("dependency %s (%s) is denied by the dependency policy: version %s is lower than the minimum version %s required for %s", name, ref, ref.Tag(), floor.Version, floor.Source))
+		}
+	}
+
+	return nil
+}
+
 func (s *DependencySolver) determineDefaultTag(dep depsv1.Dependency) (string, error) {
 	tags, err := crane.ListTags(dep.Bundle)
 	if err != nil {
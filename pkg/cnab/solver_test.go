@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	depsv1 "get.porter.sh/porter/pkg/cnab/dependencies/v1"
+	"get.porter.sh/porter/pkg/config"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,7 +21,8 @@ func TestDependencySolver_ResolveDependencies(t *testing.T) {
 						Bundle: "getporter/mysql:5.7",
 					},
 					"nginx": {
-						Bundle: "localhost:5000/nginx:1.19",
+						Bundle:    "localhost:5000/nginx:1.19",
+						DependsOn: []string{"mysql"},
 					},
 				},
 			},
@@ -45,6 +47,77 @@ func TestDependencySolver_ResolveDependencies(t *testing.T) {
 
 	assert.Equal(t, "getporter/mysql:5.7", mysql.Reference)
 	assert.Equal(t, "localhost:5000/nginx:1.19", nginx.Reference)
+	assert.Empty(t, mysql.DependsOn)
+	assert.Equal(t, []string{"mysql"}, nginx.DependsOn)
+}
+
+func TestDependencySolver_ResolveDependencies_Policy(t *testing.T) {
+	t.Parallel()
+
+	bun := NewBundle(bundle.Bundle{
+		Custom: map[string]interface{}{
+			DependenciesV1ExtensionKey: depsv1.Dependencies{
+				Requires: map[string]depsv1.Dependency{
+					"mysql": {
+						Bundle: "ghcr.io/getporter/mysql:v1.2.0",
+					},
+				},
+			},
+		},
+	})
+
+	testcases := []struct {
+		name      string
+		policy    config.DependencyPolicy
+		wantError string
+	}{
+		{name: "no policy"},
+		{name: "allowed registry",
+			policy: config.DependencyPolicy{AllowedSources: []string{"ghcr.io"}}},
+		{name: "allowed repository prefix",
+			policy: config.DependencyPolicy{AllowedSources: []string{"docker.io", "ghcr.io/getporter"}}},
+		{name: "source not allowed",
+			policy:    config.DependencyPolicy{AllowedSources: []string{"ghcr.io/getporter/mysql-community", "docker.io"}},
+			wantError: "dependency mysql (ghcr.io/getporter/mysql:v1.2.0) is denied by the dependency policy: ghcr.io/getporter/mysql is not an allowed source"},
+		{name: "meets minimum version",
+			policy: config.DependencyPolicy{MinimumVersions: []config.DependencyVersionFloor{{Source: "ghcr.io/getporter", Version: "1.2.0"}}}},
+		{name: "below minimum version",
+			policy:    config.DependencyPolicy{MinimumVersions: []config.DependencyVersionFloor{{Source: "ghcr.io/getporter/mysql", Version: "v1.3.0"}}},
+			wantError: "dependency mysql (ghcr.io/getporter/mysql:v1.2.0) is denied by the dependency policy: version v1.2.0 is lower than the minimum version v1.3.0 required for ghcr.io/getporter/mysql"},
+		{name: "minimum version for another source",
+			policy: config.DependencyPolicy{MinimumVersions: []config.DependencyVersionFloor{{Source: "docker.io", Version: "v2.0.0"}}}},
+		{name: "invalid minimum version",
+			policy:    config.DependencyPolicy{MinimumVersions: []config.DependencyVersionFloor{{Source: "*", Version: "oops"}}},
+			wantError: "invalid dependency policy"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := DependencySolver{Policy: tc.policy}
+			locks, err := s.ResolveDependencies(bun)
+			if tc.wantError != "" {
+				require.ErrorContains(t, err, tc.wantError)
+			} else {
+				require.NoError(t, err)
+				require.Len(t, locks, 1)
+				assert.Equal(t, "ghcr.io/getporter/mysql:v1.2.0", locks[0].Reference)
+			}
+		})
+	}
+}
+
+func TestDependencySolver_checkPolicy_Unversioned(t *testing.T) {
+	t.Parallel()
+
+	ref := MustParseOCIReference("getporter/mysql:latest")
+	s := DependencySolver{Policy: config.DependencyPolicy{
+		MinimumVersions: []config.DependencyVersionFloor{{Source: "docker.io/getporter", Version: "v1.0.0"}},
+	}}
+	err := s.checkPolicy("mysql", ref)
+	require.ErrorContains(t, err, "dependency mysql (getporter/mysql:latest) is denied by the dependency policy: the version could not be determined from the tag")
 }
 
 func TestDependencySolver_ResolveVersion(t *testing.T) {
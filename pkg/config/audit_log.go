@@ -0,0 +1,65 @@
+package config
+
+const (
+	// AuditSinkFile appends audit events to a file.
+	AuditSinkFile = "file"
+
+	// AuditSinkSyslog sends audit events to syslog.
+	AuditSinkSyslog = "syslog"
+
+	// AuditSinkWebhook posts audit events to a webhook.
+	AuditSinkWebhook = "webhook"
+)
+
+// AuditLogConfig selects where Porter records an audit event every time a
+// sensitive parameter or output is resolved from the secret store.
+type AuditLogConfig struct {
+	// Sink of the audit events. Allowed values are: file, syslog, webhook.
+	// Audit events are not recorded when it is not set.
+	Sink string `mapstructure:"sink"`
+
+	// Required prevents a sensitive value from being used when its audit
+	// event could not be recorded. By default, a warning is logged instead.
+	Required bool `mapstructure:"required"`
+
+	File    FileAuditSinkConfig    `mapstructure:"file"`
+	Syslog  SyslogAuditSinkConfig  `mapstructure:"syslog"`
+	Webhook WebhookAuditSinkConfig `mapstructure:"webhook"`
+}
+
+// IsEnabled determines if audit events are recorded.
+func (c AuditLogConfig) IsEnabled() bool {
+	return c.Sink != ""
+}
+
+// FileAuditSinkConfig are the settings for appending audit events to a file.
+type FileAuditSinkConfig struct {
+	// Path to the file. Defaults to PORTER_HOME/audit.log.
+	Path string `mapstructure:"path"`
+}
+
+// SyslogAuditSinkConfig are the settings for sending audit events to syslog.
+type SyslogAuditSinkConfig struct {
+	// Network used to connect to a remote syslog server, for example udp or tcp.
+	// The local syslog server is used when it is not set.
+	Network string `mapstructure:"network"`
+
+	// Address of a remote syslog server, for example syslog.example.com:514.
+	Address string `mapstructure:"address"`
+
+	// Tag of the messages. Defaults to porter.
+	Tag string `mapstructure:"tag"`
+}
+
+// WebhookAuditSinkConfig are the settings for posting audit events to a webhook.
+type WebhookAuditSinkConfig struct {
+	// URL of the webhook.
+	URL string `mapstructure:"url"`
+
+	// Headers to include in the request, such as an authorization token.
+	Headers map[string]string `mapstructure:"headers"`
+
+	// Timeout is the amount of time to wait for each request, for example
+	// 10s. Defaults to 10s.
+	Timeout string `mapstructure:"timeout"`
+}
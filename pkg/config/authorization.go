@@ -0,0 +1,82 @@
+package config
+
+// DefaultAPISubject is the identity that is authorized for the clients of
+// porter api serve when api-subject is not set.
+const DefaultAPISubject = "porter-api"
+
+// AuthorizationConfig restricts the namespaces in which a subject may read,
+// write and delete installations, runs, outputs, credential sets and
+// parameter sets. Every request is allowed when no rules are defined.
+type AuthorizationConfig struct {
+	// Subject is the identity that is authorized when one is not set on the
+	// request, for example the name of a CI service account. Defaults to the
+	// USER environment variable.
+	Subject string `mapstructure:"subject"`
+
+	// APISubject is the identity that is authorized for the requests of the
+	// clients of porter api serve, such as remote runs and registry
+	// webhooks, which share the token of the server. Defaults to porter-api.
+	APISubject string `mapstructure:"api-subject"`
+
+	// Rules grant subjects access to namespaces. A request is denied unless
+	// a rule allows it.
+	Rules []AuthorizationRule `mapstructure:"rules"`
+
+	// Plugin is the key of an authorization plugin, for example
+	// mycompany.opa, that must also allow each request. No plugin is used
+	// when it is empty.
+	Plugin string `mapstructure:"plugin"`
+
+	// Config is passed to the authorization plugin when it is started.
+	Config map[string]interface{} `mapstructure:"config"`
+}
+
+// GetAPISubject returns the identity that is authorized for the clients of
+// porter api serve.
+func (c AuthorizationConfig) GetAPISubject() string {
+	if c.APISubject != "" {
+		return c.APISubject
+	}
+	return DefaultAPISubject
+}
+
+// GetName returns the name of the authorization plugin configuration.
+func (c AuthorizationConfig) GetName() string {
+	return "authorization"
+}
+
+// GetPluginSubKey returns the key of the authorization plugin.
+func (c AuthorizationConfig) GetPluginSubKey() string {
+	return c.Plugin
+}
+
+// GetConfig returns the configuration passed to the authorization plugin.
+func (c AuthorizationConfig) GetConfig() interface{} {
+	return c.Config
+}
+
+// AuthorizationRule allows subjects to perform actions on resources in
+// namespaces. An empty list, or *, matches every value.
+type AuthorizationRule struct {
+	// Subjects that are allowed by the rule.
+	Subjects []string `mapstructure:"subjects"`
+
+	// Namespaces in which the rule applies. Use "" for the global namespace.
+	Namespaces []string `mapstructure:"namespaces"`
+
+	// Verbs that are allowed: read, write, delete.
+	Verbs []string `mapstructure:"verbs"`
+
+	// Resources that are allowed: installations, runs, results, outputs,
+	// credentials, parameters.
+	Resources []string `mapstructure:"resources"`
+}
+
+// Allows determines if the rule grants the subject permission to perform the
+// verb on the resource in the namespace.
+func (r AuthorizationRule) Allows(subject string, namespace string, verb string, resource string) bool {
+	return matchesPolicyValue(r.Subjects, subject, true) &&
+		matchesPolicyValue(r.Namespaces, namespace, true) &&
+		matchesPolicyValue(r.Verbs, verb, true) &&
+		matchesPolicyValue(r.Resources, resource, true)
+}
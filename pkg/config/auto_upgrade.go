@@ -0,0 +1,20 @@
+package config
+
+// AutoUpgradeRule upgrades installations when a new version of their bundle
+// is pushed to a registry that notifies the registry webhook of porter api serve.
+type AutoUpgradeRule struct {
+	// Name of the rule, which is recorded on the runs that it triggers.
+	Name string `mapstructure:"name"`
+
+	// Namespace of the installations that the rule upgrades. Use * to upgrade
+	// installations in any namespace. Defaults to the global namespace.
+	Namespace string `mapstructure:"namespace"`
+
+	// Selector is a list of label requirements that the installations must
+	// meet, for example env!=prod or team=red,tier=web.
+	Selector []string `mapstructure:"selector"`
+
+	// Constraint is a semantic version constraint that the pushed version must
+	// satisfy, for example ~1.2 or >=1.0.0 <2.0.0. Defaults to any version.
+	Constraint string `mapstructure:"constraint"`
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultChangeManagementMaxAttempts is the number of times Porter tries to
+	// update a change ticket when a limit is not configured.
+	DefaultChangeManagementMaxAttempts = 3
+
+	// DefaultChangeManagementTimeout is the amount of time to wait for each
+	// request to the change-management system when a timeout is not configured.
+	DefaultChangeManagementTimeout = 10 * time.Second
+
+	// DefaultChangeManagementRetryDelay is the amount of time to wait before
+	// the first retry when a delay is not configured. The delay doubles after
+	// each failed attempt.
+	DefaultChangeManagementRetryDelay = time.Second
+)
+
+// ChangeManagementConfig specifies how to update a ticket in an external
+// change-management system when a run with a change ticket completes.
+type ChangeManagementConfig struct {
+	// URL of the endpoint that transitions the ticket. It is a Go template
+	// that is rendered with the run's result, for example
+	// https://example.com/api/changes/{{.Ticket}}/transition.
+	URL string `mapstructure:"url"`
+
+	// Method is the HTTP method used to call the endpoint. Defaults to POST.
+	Method string `mapstructure:"method"`
+
+	// Body is a Go template for the request body that is rendered with the
+	// run's result. Defaults to a json document describing the result.
+	Body string `mapstructure:"body"`
+
+	// Headers to include in the request, such as an authorization token.
+	Headers map[string]string `mapstructure:"headers"`
+
+	// MaxAttempts is the maximum number of requests made before the delivery
+	// is recorded as failed. Defaults to 3.
+	MaxAttempts int `mapstructure:"max-attempts"`
+
+	// Timeout is the amount of time to wait for each request, for example 10s.
+	Timeout string `mapstructure:"timeout"`
+
+	// RetryDelay is the amount of time to wait before retrying a failed
+	// request, for example 1s. The delay doubles after each failed attempt.
+	RetryDelay string `mapstructure:"retry-delay"`
+}
+
+// IsEnabled determines if an endpoint is configured for change tickets.
+func (c ChangeManagementConfig) IsEnabled() bool {
+	return c.URL != ""
+}
+
+// GetMethod returns the HTTP method used to update change tickets.
+func (c ChangeManagementConfig) GetMethod() string {
+	if c.Method == "" {
+		return http.MethodPost
+	}
+	return c.Method
+}
+
+// GetMaxAttempts returns the maximum number of requests made to update a change ticket.
+func (c ChangeManagementConfig) GetMaxAttempts() int {
+	if c.MaxAttempts <= 0 {
+		return DefaultChangeManagementMaxAttempts
+	}
+	return c.MaxAttempts
+}
+
+// GetTimeout returns the amount of time to wait for each request.
+func (c ChangeManagementConfig) GetTimeout() (time.Duration, error) {
+	return parseChangeManagementDuration("timeout", c.Timeout, DefaultChangeManagementTimeout)
+}
+
+// GetRetryDelay returns the amount of time to wait before the first retry.
+func (c ChangeManagementConfig) GetRetryDelay() (time.Duration, error) {
+	return parseChangeManagementDuration("retry-delay", c.RetryDelay, DefaultChangeManagementRetryDelay)
+}
+
+func parseChangeManagementDuration(key string, value string, defaultValue time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid change-management.%s %q: %w", key, value, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid change-management.%s %q: the duration cannot be negative", key, value)
+	}
+	return d, nil
+}
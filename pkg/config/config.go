@@ -8,13 +8,16 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"time"
 
 	"get.porter.sh/porter/pkg/experimental"
 	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/printer"
 	"get.porter.sh/porter/pkg/schema"
 	"get.porter.sh/porter/pkg/tracing"
 	"github.com/spf13/viper"
 	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap/zapcore"
 )
 
 const (
@@ -55,8 +58,22 @@ const (
 	// invocation image, containing the name of the installation.
 	EnvPorterInstallationName = "PORTER_INSTALLATION_NAME"
 
+	// EnvRunEvents is the name of the environment variable which is injected into the
+	// invocation image when Porter reports the events of the run, requesting that the
+	// runtime report when each step starts and completes.
+	EnvRunEvents = "PORTER_RUN_EVENTS"
+
+	// EnvInjectFaults is the name of the environment variable that requests
+	// failures at specific points of a run, for testing. It is passed through
+	// to the invocation image so that the runtime can inject failures too.
+	EnvInjectFaults = "PORTER_INJECT_FAULTS"
+
 	// DefaultVerbosity is the default value for the --verbosity flag.
 	DefaultVerbosity = "info"
+
+	// DefaultDependencyParallelism is the default maximum number of
+	// dependencies that are executed at the same time.
+	DefaultDependencyParallelism = 4
 )
 
 // These are functions that afero doesn't support, so this lets us stub them out for tests to set the
@@ -108,8 +125,16 @@ func NewFor(pCtx *portercontext.Context) *Config {
 }
 
 func (c *Config) NewLogConfiguration() portercontext.LogConfiguration {
+	profile := c.GetOutputProfile()
+	verbosity := c.GetVerbosity().Level()
+	if profile.Quiet() && verbosity < zapcore.ErrorLevel {
+		// The quiet profile only prints errors to the console
+		verbosity = zapcore.ErrorLevel
+	}
+
 	return portercontext.LogConfiguration{
-		Verbosity:               c.GetVerbosity().Level(),
+		Verbosity:               verbosity,
+		DisableColor:            !profile.Color(),
 		StructuredLogs:          c.Data.Logs.Structured,
 		LogToFile:               c.Data.Logs.LogToFile,
 		LogDirectory:            filepath.Join(c.porterHome, "logs"),
@@ -140,6 +165,18 @@ func (c *Config) loadData(ctx context.Context, templateData map[string]interface
 		return ctx, err
 	}
 
+	profile, err := printer.ParseProfile(c.Data.OutputProfile)
+	if err != nil {
+		return ctx, err
+	}
+	c.Out = printer.NewProfileWriter(c.Out, profile)
+
+	redactions, err := printer.ParseRedactions(c.Data.OutputRedactions)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid output-redactions in the porter configuration: %w", err)
+	}
+	c.Out = printer.NewRedactingWriter(c.Out, redactions)
+
 	// Now that we have completely loaded our config, configure our final logging/tracing
 	ctx = c.Context.ConfigureLogging(ctx, c.NewLogConfiguration())
 	return ctx, nil
@@ -162,6 +199,15 @@ func (c *Config) GetSchemaCheckStrategy(ctx context.Context) schema.CheckStrateg
 	}
 }
 
+// GetDependencyParallelism returns the maximum number of dependencies that
+// are executed at the same time, which is at least 1.
+func (c *Config) GetDependencyParallelism() int {
+	if c.Data.DependencyParallelism < 1 {
+		return 1
+	}
+	return c.Data.DependencyParallelism
+}
+
 func (c *Config) GetStorage(name string) (StoragePlugin, error) {
 	if c != nil {
 		for _, is := range c.Data.StoragePlugins {
@@ -315,11 +361,58 @@ func (c *Config) GetBuildDriver() string {
 	return BuildDriverBuildkit
 }
 
+// GetRuntimeTimeout returns the maximum amount of time that a bundle may run
+// before it is stopped. Zero indicates that bundles are not timed out.
+func (c *Config) GetRuntimeTimeout() (time.Duration, error) {
+	if c.Data.RuntimeTimeout == "" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(c.Data.RuntimeTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid runtime-timeout %q: %w", c.Data.RuntimeTimeout, err)
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("invalid runtime-timeout %q: the timeout cannot be negative", c.Data.RuntimeTimeout)
+	}
+	return timeout, nil
+}
+
+// GetActionTimeout returns the maximum amount of time that a bundle may run
+// the specified action before it is stopped, and if a timeout is configured
+// for the action in action-timeouts. Zero indicates that the bundle is not
+// timed out.
+func (c *Config) GetActionTimeout(action string) (time.Duration, bool, error) {
+	value, ok := c.Data.ActionTimeouts[action]
+	if !ok {
+		return 0, false, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid action-timeouts.%s %q: %w", action, value, err)
+	}
+	if timeout < 0 {
+		return 0, false, fmt.Errorf("invalid action-timeouts.%s %q: the timeout cannot be negative", action, value)
+	}
+	return timeout, true, nil
+}
+
 // GetVerbosity converts the user-specified verbosity flag into a LogLevel enum.
 func (c *Config) GetVerbosity() LogLevel {
 	return ParseLogLevel(c.Data.Verbosity)
 }
 
+// GetOutputProfile returns the output profile selected with --output-profile,
+// using the default profile when the value is invalid.
+func (c *Config) GetOutputProfile() printer.Profile {
+	profile, err := printer.ParseProfile(c.Data.OutputProfile)
+	if err != nil {
+		return printer.ProfileDefault
+	}
+	return profile
+}
+
 // Load loads the configuration file, rendering any templating used in the config file
 // such as ${secret.NAME} or ${env.NAME}.
 // Pass nil for resolveSecret to skip resolving secrets.
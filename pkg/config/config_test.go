@@ -6,10 +6,13 @@ import (
 	"path/filepath"
 	"sort"
 	"testing"
+	"time"
 
 	"get.porter.sh/porter/pkg/experimental"
+	"get.porter.sh/porter/pkg/printer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
 )
 
 func TestConfig_GetHomeDir(t *testing.T) {
@@ -110,3 +113,130 @@ func TestConfig_ExportRemoteConfigAsEnvironmentVariables(t *testing.T) {
 	}
 	assert.Equal(t, wantEnvVars, gotEnvVars)
 }
+
+func TestConfig_GetActionTimeout(t *testing.T) {
+	c := NewTestConfig(t)
+	c.Data.RuntimeTimeout = "30m"
+	c.Data.ActionTimeouts = map[string]string{
+		"backup":  "4h",
+		"restore": "later",
+		"status":  "-1m",
+	}
+
+	got, ok, err := c.GetActionTimeout("backup")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 4*time.Hour, got)
+
+	_, ok, err = c.GetActionTimeout("install")
+	require.NoError(t, err)
+	assert.False(t, ok, "actions that are not configured should not have a timeout")
+
+	_, _, err = c.GetActionTimeout("restore")
+	require.ErrorContains(t, err, `invalid action-timeouts.restore "later"`)
+
+	_, _, err = c.GetActionTimeout("status")
+	require.ErrorContains(t, err, "the timeout cannot be negative")
+}
+
+func TestConfig_GetRuntimeTimeout(t *testing.T) {
+	testcases := []struct {
+		name      string
+		value     string
+		want      time.Duration
+		wantError string
+	}{
+		{name: "unset", value: "", want: 0},
+		{name: "valid", value: "1h30m", want: 90 * time.Minute},
+		{name: "invalid", value: "soon", wantError: `invalid runtime-timeout "soon"`},
+		{name: "negative", value: "-5m", wantError: "the timeout cannot be negative"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewTestConfig(t)
+			c.Data.RuntimeTimeout = tc.value
+
+			got, err := c.GetRuntimeTimeout()
+			if tc.wantError != "" {
+				require.ErrorContains(t, err, tc.wantError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestConfig_NewLogConfiguration_OutputProfile(t *testing.T) {
+	testcases := []struct {
+		profile       string
+		verbosity     string
+		wantVerbosity zapcore.Level
+		wantNoColor   bool
+	}{
+		{profile: "", verbosity: "info", wantVerbosity: zapcore.InfoLevel},
+		{profile: "ci", verbosity: "debug", wantVerbosity: zapcore.DebugLevel, wantNoColor: true},
+		{profile: "quiet", verbosity: "info", wantVerbosity: zapcore.ErrorLevel, wantNoColor: true},
+		{profile: "porcelain", verbosity: "warn", wantVerbosity: zapcore.WarnLevel, wantNoColor: true},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.profile, func(t *testing.T) {
+			c := NewTestConfig(t)
+			c.Data.OutputProfile = tc.profile
+			c.Data.Verbosity = tc.verbosity
+
+			logCfg := c.NewLogConfiguration()
+			assert.Equal(t, tc.wantVerbosity, logCfg.Verbosity)
+			assert.Equal(t, tc.wantNoColor, logCfg.DisableColor)
+		})
+	}
+}
+
+func TestConfig_Load_OutputProfile(t *testing.T) {
+	ctx := context.Background()
+	c := NewTestConfig(t)
+	c.DataLoader = func(ctx context.Context, cfg *Config, templateData map[string]interface{}) error {
+		cfg.Data.OutputProfile = "porcelain"
+		return nil
+	}
+
+	_, err := c.Load(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, printer.ProfilePorcelain, c.GetOutputProfile())
+	assert.Equal(t, printer.ProfilePorcelain, printer.GetProfile(c.Out), "the profile should be applied to the output")
+
+	c.DataLoader = func(ctx context.Context, cfg *Config, templateData map[string]interface{}) error {
+		cfg.Data.OutputProfile = "loud"
+		return nil
+	}
+	_, err = c.Load(ctx, nil)
+	require.ErrorContains(t, err, `invalid output profile "loud"`)
+}
+
+func TestConfig_Load_OutputRedactions(t *testing.T) {
+	ctx := context.Background()
+	c := NewTestConfig(t)
+	c.DataLoader = func(ctx context.Context, cfg *Config, templateData map[string]interface{}) error {
+		cfg.Data.OutputProfile = "ci"
+		cfg.Data.OutputRedactions = []string{"$..parameterOverrides"}
+		return nil
+	}
+
+	_, err := c.Load(ctx, nil)
+	require.NoError(t, err)
+	redactions := printer.GetRedactions(c.Out)
+	require.Len(t, redactions, 1, "the redactions should be applied to the output")
+	assert.Equal(t, "$..parameterOverrides", redactions[0].Path)
+	assert.Equal(t, printer.ProfileCI, printer.GetProfile(c.Out), "the profile should be preserved")
+
+	c.DataLoader = func(ctx context.Context, cfg *Config, templateData map[string]interface{}) error {
+		cfg.Data.OutputRedactions = []string{"parameterOverrides"}
+		return nil
+	}
+	_, err = c.Load(ctx, nil)
+	require.ErrorContains(t, err, `invalid redaction "parameterOverrides"`)
+}
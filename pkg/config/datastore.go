@@ -13,6 +13,12 @@ const (
 
 	// RuntimeDriverKubernetes specifies that the invocation image should be executed on kubernetes.
 	RuntimeDriverKubernetes = "kubernetes"
+
+	// DriverPolicyEnforce fails when a bundle is run with a driver that the bundle does not support.
+	DriverPolicyEnforce = "enforce"
+
+	// DriverPolicyWarn warns when a bundle is run with a driver that the bundle does not support.
+	DriverPolicyWarn = "warn"
 )
 
 // Data is the data stored in PORTER_HOME/porter.toml|yaml|json.
@@ -33,6 +39,19 @@ type Data struct {
 	// to ensure that the global config value works even for those commands.
 	RuntimeDriver string `mapstructure:"runtime-driver"`
 
+	// DriverPolicy controls what happens when a bundle is run with a driver
+	// that the bundle does not support. Available values are: enforce, warn.
+	// Defaults to enforce.
+	// It is both a global variable and a command flag so that commands that do not expose all the
+	// bundle execution flags, like porter installation apply, also apply the policy.
+	DriverPolicy string `mapstructure:"driver-policy"`
+
+	// ChangeTicket is the ID of a ticket in an external change-management
+	// system that is updated with the outcome of a run.
+	// It is both a global variable and a command flag so that it can be set
+	// once for a pipeline, for example with PORTER_CHANGE_TICKET.
+	ChangeTicket string `mapstructure:"change-ticket"`
+
 	// ForceOverwrite specifies OCI artifacts can be overwritten when pushed.
 	// By default, Porter requires the --force flag to be specified to overwrite a bundle or image.
 	ForceOverwrite bool `mapstructure:"force-overwrite"`
@@ -45,6 +64,19 @@ type Data struct {
 	// to ensure that the global config value works even for those commands.
 	AllowDockerHostAccess bool `mapstructure:"allow-docker-host-access"`
 
+	// RuntimeTimeout is the maximum amount of time that a bundle may run before it is stopped, for example 30m.
+	// By default, bundles are not stopped no matter how long they run.
+	// It is both a global variable and a command flag (--timeout) so that commands that do not expose all the
+	// bundle execution flags, like porter installation apply, can also be timed out.
+	// Do not use directly, use Config.GetRuntimeTimeout.
+	RuntimeTimeout string `mapstructure:"runtime-timeout"`
+
+	// ActionTimeouts is the maximum amount of time that a bundle may run for
+	// a specific action, keyed by the action name, for example backup: 4h.
+	// It takes precedence over RuntimeTimeout, but not over the --timeout flag.
+	// Do not use directly, use Config.GetActionTimeout.
+	ActionTimeouts map[string]string `mapstructure:"action-timeouts"`
+
 	// DefaultStoragePlugin is the storage plugin to use when no named storage is specified.
 	DefaultStoragePlugin string `mapstructure:"default-storage-plugin"`
 
@@ -67,6 +99,11 @@ type Data struct {
 	// Namespace is the default namespace for commands that do not override it with a flag.
 	Namespace string `mapstructure:"namespace"`
 
+	// IDStrategy is the strategy used by the installation store to generate
+	// the ids of runs and results, and the revisions of installations.
+	// Available values are: ulid, uuidv7, numeric. Defaults to ulid.
+	IDStrategy string `mapstructure:"id-strategy"`
+
 	// SecretsPlugin defined in the configuration file.
 	SecretsPlugin []SecretsPlugin `mapstructure:"secrets"`
 
@@ -76,6 +113,68 @@ type Data struct {
 	// Telemetry are settings related to Porter's tracing with open telemetry.
 	Telemetry TelemetryConfig `mapstructure:"telemetry"`
 
+	// ChangeManagement are settings for updating tickets in an external
+	// change-management system when a run with a change ticket completes.
+	ChangeManagement ChangeManagementConfig `mapstructure:"change-management"`
+
+	// Notifications are webhooks that are notified when a run starts,
+	// succeeds, or fails.
+	Notifications []NotificationConfig `mapstructure:"notifications"`
+
+	// Metrics are settings for exposing Prometheus metrics.
+	Metrics MetricsConfig `mapstructure:"metrics"`
+
+	// NamespacePolicies grant installations access to the outputs of
+	// installations in other namespaces.
+	NamespacePolicies []NamespacePolicy `mapstructure:"namespace-policies"`
+
+	// SensitiveOutputPolicy controls when the values of sensitive outputs
+	// may be revealed.
+	SensitiveOutputPolicy SensitiveOutputPolicy `mapstructure:"sensitive-output-policy"`
+
+	// AuditLog records an audit event every time a sensitive parameter or
+	// output is resolved from the secret store.
+	AuditLog AuditLogConfig `mapstructure:"audit-log"`
+
+	// SensitivityPolicies mark parameters and outputs as sensitive, in
+	// addition to those marked sensitive by the bundle.
+	SensitivityPolicies []SensitivityPolicy `mapstructure:"sensitivity-policies"`
+
+	// DependencyPolicy restricts the sources and versions of the bundles
+	// that may be used as dependencies.
+	DependencyPolicy DependencyPolicy `mapstructure:"dependency-policy"`
+
+	// DependencyParallelism is the maximum number of dependencies that are
+	// executed at the same time, when the bundle declares the order of its
+	// dependencies with dependsOn. Set to 1 to execute dependencies one at a time.
+	DependencyParallelism int `mapstructure:"dependency-parallelism"`
+
+	// StrictParameters validates every parameter against its definition in the
+	// bundle before a bundle is run, and reports all invalid parameters together.
+	StrictParameters bool `mapstructure:"strict-parameters"`
+
+	// MixinTrustPolicy controls which mixin binaries may be run.
+	MixinTrustPolicy MixinTrustPolicy `mapstructure:"mixin-trust-policy"`
+
+	// MixinExecutionPolicy restricts the environment that mixins are run with.
+	MixinExecutionPolicy MixinExecutionPolicy `mapstructure:"mixin-execution-policy"`
+
+	// MixinContainer runs mixins inside a container instead of running the
+	// mixin binaries installed in PORTER_HOME.
+	MixinContainer MixinContainerConfig `mapstructure:"mixin-container"`
+
+	// StorageEncryption configures the encryption of runs, parameter sets and
+	// credential sets at rest.
+	StorageEncryption StorageEncryption `mapstructure:"storage-encryption"`
+
+	// OutputStorage limits the size of output values that are saved in the
+	// database, saving larger values to a blob store.
+	OutputStorage OutputStorageConfig `mapstructure:"output-storage"`
+
+	// ReadOnly rejects any change to Porter's storage, such as saving an
+	// installation or a run, with storage.ErrReadOnly.
+	ReadOnly bool `mapstructure:"read-only"`
+
 	// SchemaCheck specifies how strict Porter should be when comparing the
 	// schemaVersion field on a resource with the supported schemaVersion.
 	// Supported values are: exact, minor, major, none.
@@ -85,17 +184,59 @@ type Data struct {
 	// Use Logs.LogLevel if you want to change what is output to the logfile.
 	// Traces sent to an OpenTelemetry collector always include all levels of messages.
 	Verbosity string `mapstructure:"verbosity"`
+
+	// OutputProfile controls color, progress, prompts and the format of tables
+	// printed to the console. Supported values are: default, ci, quiet, porcelain.
+	OutputProfile string `mapstructure:"output-profile"`
+
+	// OutputRedactions is a list of JSONPath expressions that select fields
+	// to remove from json and yaml printed by commands.
+	OutputRedactions []string `mapstructure:"output-redactions"`
+
+	// Authorization restricts which namespaces a subject may read and change.
+	Authorization AuthorizationConfig `mapstructure:"authorization"`
+
+	// RunMetadata controls the CI metadata, such as the commit and pipeline
+	// URL, that is captured when a run is created.
+	RunMetadata RunMetadataConfig `mapstructure:"run-metadata"`
+
+	// StorageFailover configures a secondary storage account that is used
+	// while the default storage account is unreachable.
+	StorageFailover StorageFailoverConfig `mapstructure:"storage-failover"`
+
+	// AutoUpgradeRules upgrade installations when porter api serve is notified
+	// that a new version of their bundle was pushed to a registry.
+	AutoUpgradeRules []AutoUpgradeRule `mapstructure:"auto-upgrade-rules"`
+
+	// APIToken is the token that clients of porter api serve must present.
+	// It is both a global variable and a command flag so that the token does
+	// not need to be passed on the command line, where it is visible in the
+	// process list.
+	APIToken string `mapstructure:"api-token"`
+
+	// RunLedger records runs and results in a hash chained, optionally
+	// signed, ledger so that changes to the run history can be detected.
+	RunLedger RunLedgerConfig `mapstructure:"run-ledger"`
+
+	// SecretsCache caches the secrets resolved from the secret store in
+	// memory for the duration of a command.
+	SecretsCache SecretsCacheConfig `mapstructure:"secrets-cache"`
+
+	// DebugStorageStats prints a summary of the queries made to the storage
+	// plugin and the bundle cache hit rate when a command completes.
+	DebugStorageStats bool `mapstructure:"debug-storage-stats"`
 }
 
 // DefaultDataStore used when no config file is found.
 func DefaultDataStore() Data {
 	return Data{
-		BuildDriver:          BuildDriverBuildkit,
-		RuntimeDriver:        RuntimeDriverDocker,
-		DefaultStoragePlugin: "mongodb-docker",
-		DefaultSecretsPlugin: "host",
-		Logs:                 LogConfig{Level: "info"},
-		Verbosity:            DefaultVerbosity,
+		BuildDriver:           BuildDriverBuildkit,
+		RuntimeDriver:         RuntimeDriverDocker,
+		DefaultStoragePlugin:  "mongodb-docker",
+		DefaultSecretsPlugin:  "host",
+		Logs:                  LogConfig{Level: "info"},
+		Verbosity:             DefaultVerbosity,
+		DependencyParallelism: DefaultDependencyParallelism,
 	}
 }
 
@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -64,3 +65,45 @@ func TestData_SecretAccessors(t *testing.T) {
 	assert.Equal(t, "prod", source.Name, "SecretsPlugin.Name returned the wrong value")
 	assert.Equal(t, "azure.keyvault", source.PluginSubKey, "SecretsPlugin.PluginSubKey returned the wrong value")
 }
+
+func TestSecretsCacheConfig(t *testing.T) {
+	var cfg SecretsCacheConfig
+	ttl, err := cfg.GetTTL()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSecretsCacheTTL, ttl)
+	assert.Equal(t, DefaultSecretsCacheMaxEntries, cfg.GetMaxEntries())
+
+	cfg = SecretsCacheConfig{TTL: "30s", MaxEntries: 10}
+	ttl, err = cfg.GetTTL()
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, ttl)
+	assert.Equal(t, 10, cfg.GetMaxEntries())
+
+	cfg.TTL = "0s"
+	_, err = cfg.GetTTL()
+	require.ErrorContains(t, err, "must be greater than zero")
+}
+
+func TestMixinContainerConfig(t *testing.T) {
+	var cfg MixinContainerConfig
+	assert.False(t, cfg.Enabled())
+	_, ok := cfg.GetImage("exec")
+	assert.False(t, ok)
+
+	cfg = MixinContainerConfig{Image: "mixins:v1", Images: map[string]string{"helm3": "helm3:v2"}}
+	assert.True(t, cfg.Enabled())
+	img, ok := cfg.GetImage("helm3")
+	assert.True(t, ok)
+	assert.Equal(t, "helm3:v2", img)
+	img, ok = cfg.GetImage("exec")
+	assert.True(t, ok)
+	assert.Equal(t, "mixins:v1", img, "the default image should be used")
+
+	pull, err := cfg.GetPull()
+	require.NoError(t, err)
+	assert.Equal(t, MixinContainerPullMissing, pull)
+
+	cfg.Pull = "sometimes"
+	_, err = cfg.GetPull()
+	require.ErrorContains(t, err, "invalid mixin-container.pull value")
+}
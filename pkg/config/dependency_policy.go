@@ -0,0 +1,62 @@
+package config
+
+import "strings"
+
+// DependencyPolicy restricts which bundles may be used as dependencies. It is
+// evaluated when the dependencies of a bundle are resolved.
+type DependencyPolicy struct {
+	// AllowedSources is the list of sources that dependencies may be resolved
+	// from. A source is a registry, such as ghcr.io, a repository prefix, such as
+	// ghcr.io/getporter, or a repository, such as ghcr.io/getporter/mysql.
+	// Use * to allow every source. When empty, dependencies may be resolved from
+	// any source.
+	AllowedSources []string `mapstructure:"allowed-sources"`
+
+	// MinimumVersions are the lowest versions of dependencies that may be used.
+	MinimumVersions []DependencyVersionFloor `mapstructure:"minimum-versions"`
+}
+
+// DependencyVersionFloor is the minimum version that may be used for the
+// dependencies resolved from a source.
+type DependencyVersionFloor struct {
+	// Source of the dependencies that the floor applies to. It is matched in
+	// the same way as DependencyPolicy.AllowedSources.
+	Source string `mapstructure:"source"`
+
+	// Version is the lowest semantic version that may be used.
+	Version string `mapstructure:"version"`
+}
+
+// AllowsSource determines if the policy allows resolving dependencies from the
+// fully-qualified repository, for example docker.io/getporter/mysql.
+func (p DependencyPolicy) AllowsSource(repository string) bool {
+	if len(p.AllowedSources) == 0 {
+		return true
+	}
+
+	for _, source := range p.AllowedSources {
+		if matchesDependencySource(source, repository) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMinimumVersions returns the version floors that apply to dependencies
+// resolved from the fully-qualified repository.
+func (p DependencyPolicy) GetMinimumVersions(repository string) []DependencyVersionFloor {
+	var floors []DependencyVersionFloor
+	for _, floor := range p.MinimumVersions {
+		if matchesDependencySource(floor.Source, repository) {
+			floors = append(floors, floor)
+		}
+	}
+	return floors
+}
+
+// matchesDependencySource determines if the repository is the source, or is
+// located under it.
+func matchesDependencySource(source string, repository string) bool {
+	source = strings.TrimSuffix(source, "/")
+	return source == NamespacePolicyWildcard || repository == source || strings.HasPrefix(repository, source+"/")
+}
@@ -0,0 +1,336 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix is the prefix of the environment variables that set Porter's configuration.
+const EnvPrefix = "PORTER_"
+
+// errUnknownEnvKey indicates that an environment variable does not match a
+// configuration key.
+var errUnknownEnvKey = errors.New("unknown configuration key")
+
+// integerPattern matches integers that are converted to a number when they
+// are set on a map, such as the config of a plugin. Values with leading
+// zeroes remain strings, because they are usually identifiers.
+var integerPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+
+// applyEnvironment sets nested configuration values from environment
+// variables, for example PORTER_LOGS_LEVEL sets logs.level and
+// PORTER_STORAGE_DEVDB_URL sets the url in the config of the storage named
+// devdb. The path of the key is the uppercase key names, separated by
+// underscores, with any dashes in the key names replaced by underscores.
+//
+// Top-level keys are not set because they are bound to environment variables,
+// and to command flags which take precedence, when the config is loaded.
+// Environment variables that do not match a configuration key are ignored,
+// and their names are returned so that they can be logged.
+func applyEnvironment(data *Data, environ []string) ([]string, error) {
+	vars := make([]string, 0, len(environ))
+	for _, env := range environ {
+		if strings.HasPrefix(env, EnvPrefix) {
+			vars = append(vars, env)
+		}
+	}
+
+	// Apply the variables in a stable order, so that a variable that defines
+	// a new storage or secrets entry always results in the same configuration
+	sort.Strings(vars)
+
+	var ignored []string
+	for _, env := range vars {
+		name, value, _ := strings.Cut(env, "=")
+		path := strings.Split(strings.TrimPrefix(name, EnvPrefix), "_")
+
+		field, rest, ok := findField(reflect.ValueOf(data).Elem(), path)
+		if !ok || len(rest) == 0 {
+			// Either not a config key, or a top-level key which is set by viper
+			continue
+		}
+
+		if err := setEnvValue(field, rest, value); err != nil {
+			if errors.Is(err, errUnknownEnvKey) {
+				ignored = append(ignored, name)
+				continue
+			}
+			return nil, fmt.Errorf("invalid value for environment variable %s: %w", name, err)
+		}
+	}
+
+	return ignored, nil
+}
+
+// envSegments converts a configuration key to the segments of its
+// environment variable, for example max-attempts is MAX and ATTEMPTS.
+func envSegments(key string) []string {
+	return strings.Split(strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key)), "_")
+}
+
+// hasSegmentsPrefix returns the remainder of the path after the key, and
+// if the path starts with the key.
+func hasSegmentsPrefix(path []string, key string) ([]string, bool) {
+	segments := envSegments(key)
+	if len(segments) > len(path) {
+		return nil, false
+	}
+	for i, segment := range segments {
+		if path[i] != segment {
+			return nil, false
+		}
+	}
+	return path[len(segments):], true
+}
+
+// findField finds the field of a struct that matches the start of the path,
+// preferring the longest key so that default-storage-plugin is matched
+// instead of default-storage.
+func findField(v reflect.Value, path []string) (reflect.Value, []string, bool) {
+	var match reflect.Value
+	var matchRest []string
+	found := false
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		key, opts, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+		if key == "-" {
+			continue
+		}
+		if opts == "squash" {
+			if field, rest, ok := findField(v.Field(i), path); ok && (!found || len(rest) < len(matchRest)) {
+				match, matchRest, found = field, rest, true
+			}
+			continue
+		}
+		if key == "" {
+			key = f.Name
+		}
+
+		if rest, ok := hasSegmentsPrefix(path, key); ok && (!found || len(rest) < len(matchRest)) {
+			match, matchRest, found = v.Field(i), rest, true
+		}
+	}
+
+	return match, matchRest, found
+}
+
+// setEnvValue sets the value of the environment variable at the remainder of
+// the path, relative to v.
+func setEnvValue(v reflect.Value, path []string, value string) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setEnvValue(v.Elem(), path, value)
+	case reflect.Struct:
+		if len(path) == 0 {
+			return fmt.Errorf("%s is a section of the configuration, set the keys within it instead", v.Type())
+		}
+		field, rest, ok := findField(v, path)
+		if !ok {
+			return fmt.Errorf("%w %s", errUnknownEnvKey, strings.ToLower(strings.Join(path, "_")))
+		}
+		return setEnvValue(field, rest, value)
+	case reflect.Map:
+		if len(path) == 0 {
+			return fmt.Errorf("%s is a section of the configuration, set the keys within it instead", v.Type())
+		}
+		return setEnvMapValue(v, path, value)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Struct {
+			return setEnvEntryValue(v, path, value)
+		}
+	}
+
+	if len(path) > 0 {
+		return fmt.Errorf("%w %s", errUnknownEnvKey, strings.ToLower(strings.Join(path, "_")))
+	}
+	return coerceEnvValue(v, value)
+}
+
+// setEnvMapValue sets a key in a map. The key is matched to an existing key
+// regardless of case, or whether it uses dashes or underscores, otherwise it
+// is the lowercase remainder of the path.
+func setEnvMapValue(m reflect.Value, path []string, value string) error {
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+
+	key := strings.ToLower(strings.Join(path, "_"))
+	for _, existing := range m.MapKeys() {
+		if existing.Kind() == reflect.String && strings.EqualFold(strings.ReplaceAll(existing.String(), "-", "_"), key) {
+			key = existing.String()
+			break
+		}
+	}
+
+	mapKey := reflect.ValueOf(key).Convert(m.Type().Key())
+	elem := reflect.New(m.Type().Elem()).Elem()
+	if existing := m.MapIndex(mapKey); existing.IsValid() {
+		elem.Set(existing)
+	}
+	if err := coerceEnvValue(elem, value); err != nil {
+		return err
+	}
+	m.SetMapIndex(mapKey, elem)
+	return nil
+}
+
+// setEnvEntryValue sets a value on the entry of a list, such as the storage or
+// secrets entries, which is identified by its name. When an entry with the
+// name is not defined, a new entry is added with the first segment of the path
+// as its name.
+func setEnvEntryValue(list reflect.Value, path []string, value string) error {
+	for i := 0; i < list.Len(); i++ {
+		entry := list.Index(i)
+		name, ok := getEntryName(entry)
+		if !ok {
+			return fmt.Errorf("the entries of %s cannot be set with environment variables", list.Type())
+		}
+		if rest, ok := hasSegmentsPrefix(path, name); ok && len(rest) > 0 {
+			return setEnvEntryField(entry, rest, value)
+		}
+	}
+
+	if len(path) < 2 {
+		return fmt.Errorf("%w %s", errUnknownEnvKey, strings.ToLower(strings.Join(path, "_")))
+	}
+
+	entry := reflect.New(list.Type().Elem()).Elem()
+	if !setEntryName(entry, strings.ToLower(path[0])) {
+		return fmt.Errorf("the entries of %s cannot be set with environment variables", list.Type())
+	}
+	if err := setEnvEntryField(entry, path[1:], value); err != nil {
+		return err
+	}
+	list.Set(reflect.Append(list, entry))
+	return nil
+}
+
+// setEnvEntryField sets a field of an entry, such as the plugin of a storage
+// entry. Keys that are not fields of the entry are set in its config.
+func setEnvEntryField(entry reflect.Value, path []string, value string) error {
+	if field, rest, ok := findField(entry, path); ok {
+		return setEnvValue(field, rest, value)
+	}
+	if config, _, ok := findField(entry, []string{"CONFIG"}); ok && config.Kind() == reflect.Map {
+		return setEnvMapValue(config, path, value)
+	}
+	return fmt.Errorf("%w %s", errUnknownEnvKey, strings.ToLower(strings.Join(path, "_")))
+}
+
+func getEntryName(entry reflect.Value) (string, bool) {
+	field, rest, ok := findField(entry, []string{"NAME"})
+	if !ok || len(rest) > 0 || field.Kind() != reflect.String {
+		return "", false
+	}
+	return field.String(), true
+}
+
+func setEntryName(entry reflect.Value, name string) bool {
+	field, rest, ok := findField(entry, []string{"NAME"})
+	if !ok || len(rest) > 0 || field.Kind() != reflect.String {
+		return false
+	}
+	field.SetString(name)
+	return true
+}
+
+// coerceEnvValue converts the value of an environment variable to the type
+// of the destination. Lists are comma separated. When the destination may
+// hold any type, such as the values in the config of a plugin, the type of
+// its current value is kept, otherwise true and false are converted to a
+// boolean and integers to a number.
+func coerceEnvValue(v reflect.Value, value string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a positive integer", value)
+		}
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		if value != "" {
+			items = strings.Split(value, ",")
+		}
+		list := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := coerceEnvValue(list.Index(i), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		v.Set(list)
+	case reflect.Interface:
+		if !v.IsNil() {
+			current := reflect.New(v.Elem().Type()).Elem()
+			if err := coerceEnvValue(current, value); err == nil {
+				v.Set(current)
+				return nil
+			}
+		}
+		v.Set(reflect.ValueOf(inferEnvValue(value)))
+	default:
+		return fmt.Errorf("values of type %s cannot be set with environment variables", v.Type())
+	}
+	return nil
+}
+
+// inferEnvValue converts a value that may be any type to a boolean or integer
+// when it looks like one.
+func inferEnvValue(value string) interface{} {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if integerPattern.MatchString(value) {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return value
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyEnvironment(t *testing.T) {
+	t.Parallel()
+
+	data := DefaultDataStore()
+	data.StoragePlugins = []StoragePlugin{
+		{PluginConfig: PluginConfig{Name: "dev", PluginSubKey: "mongodb", Config: map[string]interface{}{"url": "mongodb://localhost", "timeout": 10}}},
+	}
+
+	ignored, err := applyEnvironment(&data, []string{
+		"PORTER_LOGS_LEVEL=debug",
+		"PORTER_LOGS_STRUCTURED=true",
+		"PORTER_TELEMETRY_HEADERS_API_KEY=abc123",
+		"PORTER_CHANGE_MANAGEMENT_MAX_ATTEMPTS=5",
+		"PORTER_DEPENDENCY_POLICY_ALLOWED_SOURCES=ghcr.io/getporter, docker.io/example",
+		"PORTER_STORAGE_DEV_URL=mongodb://example.com",
+		"PORTER_STORAGE_DEV_TIMEOUT=30",
+		"PORTER_STORAGE_DEV_PLUGIN=mongodb-docker",
+		"PORTER_SECRETS_VAULT_PLUGIN=hashicorp.vault",
+		"PORTER_SECRETS_VAULT_VAULT_ADDR=https://vault.example.com",
+		"PORTER_SECRETS_VAULT_INSECURE=false",
+		"PORTER_LOGS_COLOR=blue",
+		"PORTER_HOME=/home/me/.porter",
+		"PORTER_VERBOSITY=warn",
+		"HOME=/home/me",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"PORTER_LOGS_COLOR"}, ignored, "unknown nested keys should be ignored")
+	assert.Equal(t, LogLevel("debug"), data.Logs.Level)
+	assert.True(t, data.Logs.Structured)
+	assert.Equal(t, map[string]string{"api_key": "abc123"}, data.Telemetry.Headers)
+	assert.Equal(t, 5, data.ChangeManagement.MaxAttempts)
+	assert.Equal(t, []string{"ghcr.io/getporter", "docker.io/example"}, data.DependencyPolicy.AllowedSources)
+	assert.Equal(t, DefaultDataStore().Verbosity, data.Verbosity, "top-level keys are set by viper")
+
+	require.Len(t, data.StoragePlugins, 1)
+	dev := data.StoragePlugins[0]
+	assert.Equal(t, "mongodb-docker", dev.PluginSubKey)
+	assert.Equal(t, map[string]interface{}{"url": "mongodb://example.com", "timeout": 30}, dev.Config)
+
+	require.Len(t, data.SecretsPlugin, 1, "a secrets entry should be added")
+	vault := data.SecretsPlugin[0]
+	assert.Equal(t, "vault", vault.Name)
+	assert.Equal(t, "hashicorp.vault", vault.PluginSubKey)
+	assert.Equal(t, map[string]interface{}{"vault_addr": "https://vault.example.com", "insecure": false}, vault.Config)
+}
+
+func TestApplyEnvironment_InvalidValue(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name    string
+		env     string
+		wantErr string
+	}{
+		{name: "bool", env: "PORTER_LOGS_STRUCTURED=yes", wantErr: `invalid value for environment variable PORTER_LOGS_STRUCTURED: "yes" is not a boolean`},
+		{name: "int", env: "PORTER_CHANGE_MANAGEMENT_MAX_ATTEMPTS=lots", wantErr: `invalid value for environment variable PORTER_CHANGE_MANAGEMENT_MAX_ATTEMPTS: "lots" is not an integer`},
+		{name: "section", env: "PORTER_STORAGE_ENCRYPTION=abc", wantErr: ""},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			data := DefaultDataStore()
+			_, err := applyEnvironment(&data, []string{tc.env})
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestConfig_NestedEnvironmentVariables(t *testing.T) {
+	t.Parallel()
+
+	c := NewTestConfig(t)
+	c.SetHomeDir("/home/myuser/.porter")
+	c.TestContext.AddTestFile("testdata/config.toml", "/home/myuser/.porter/config.toml")
+	c.Setenv("PORTER_STORAGE_DEV_URL", "mongodb://example.com")
+	c.Setenv("PORTER_LOGS_LEVEL", "error")
+
+	c.DataLoader = WithEnvironmentOverrides(LoadFromFilesystem())
+	resolveTestSecrets := func(ctx context.Context, secretKey string) (string, error) {
+		return "topsecret-connectionstring", nil
+	}
+	_, err := c.Load(context.Background(), resolveTestSecrets)
+	require.NoError(t, err, "Load failed")
+
+	require.Len(t, c.Data.StoragePlugins, 1)
+	assert.Equal(t, map[string]interface{}{"url": "mongodb://example.com"}, c.Data.StoragePlugins[0].Config,
+		"the environment variable should take precedence over the config file")
+	assert.Equal(t, LogLevel("error"), c.Data.Logs.Level)
+	assert.True(t, c.Data.Logs.Structured, "values from the config file that are not overridden should be kept")
+}
@@ -22,11 +22,12 @@ func NoopDataLoader(_ context.Context, _ *Config, _ map[string]interface{}) erro
 }
 
 // LoadFromEnvironment loads data with the following precedence:
-// * Environment variables where --flag is assumed to be PORTER_FLAG
+// * Environment variables where --flag is assumed to be PORTER_FLAG,
+// and nested keys such as storage.devdb.url are PORTER_STORAGE_DEVDB_URL
 // * Config file
 // * Flag default (lowest)
 func LoadFromEnvironment() DataStoreLoaderFunc {
-	return LoadFromViper(func(v *viper.Viper) {
+	return WithEnvironmentOverrides(LoadFromViper(func(v *viper.Viper) {
 		v.SetEnvPrefix("PORTER")
 		v.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
 		v.AutomaticEnv()
@@ -40,7 +41,38 @@ func LoadFromEnvironment() DataStoreLoaderFunc {
 		v.BindEnv("telemetry.headers", "OTEL_EXPORTER_OTLP_HEADERS", "OTEL_EXPORTER_OTLP_TRACES_HEADERS")
 		v.BindEnv("telemetry.compression", "OTEL_EXPORTER_OTLP_COMPRESSION", "OTEL_EXPORTER_OTLP_TRACES_COMPRESSION")
 		v.BindEnv("telemetry.timeout", "OTEL_EXPORTER_OTLP_TIMEOUT", "OTEL_EXPORTER_OTLP_TRACES_TIMEOUT")
-	})
+	}))
+}
+
+// WithEnvironmentOverrides applies environment variables for nested
+// configuration keys, such as PORTER_STORAGE_DEVDB_URL, after the data is
+// loaded. Viper only binds environment variables to keys that it already
+// knows about, so nested keys that are not in the config file, and the
+// entries in the storage and secrets lists, would otherwise be ignored.
+func WithEnvironmentOverrides(loader DataStoreLoaderFunc) DataStoreLoaderFunc {
+	return func(ctx context.Context, cfg *Config, templateData map[string]interface{}) error {
+		if err := loader(ctx, cfg, templateData); err != nil {
+			return err
+		}
+
+		// Internal plugins are passed the resolved config by porter
+		if cfg.IsInternalPlugin {
+			return nil
+		}
+
+		//lint:ignore SA4006 ignore unused context for now
+		ctx, log := tracing.StartSpan(ctx)
+		defer log.EndSpan()
+
+		ignored, err := applyEnvironment(&cfg.Data, cfg.Environ())
+		if err != nil {
+			return log.Error(fmt.Errorf("error loading configuration from environment variables: %w", err))
+		}
+		for _, name := range ignored {
+			log.Debugf("Ignoring environment variable %s because it does not match a configuration key", name)
+		}
+		return nil
+	}
 }
 
 // LoadFromFilesystem loads data with the following precedence:
@@ -0,0 +1,24 @@
+package config
+
+// DefaultMetricsListenAddress is the address that the metrics listener binds
+// to when an address is not configured.
+const DefaultMetricsListenAddress = "127.0.0.1:9464"
+
+// MetricsConfig are settings for exposing Prometheus metrics from a
+// long-running Porter process.
+type MetricsConfig struct {
+	// Enabled starts an http listener that serves the metrics at /metrics.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Listen is the address of the metrics listener, for example :9464.
+	Listen string `mapstructure:"listen"`
+}
+
+// GetListen returns the address of the metrics listener, using the default
+// address when one is not configured.
+func (c MetricsConfig) GetListen() string {
+	if c.Listen == "" {
+		return DefaultMetricsListenAddress
+	}
+	return c.Listen
+}
@@ -0,0 +1,56 @@
+package config
+
+import "fmt"
+
+const (
+	// MixinContainerPullMissing pulls the image of a mixin when it is not
+	// already in the local docker engine.
+	MixinContainerPullMissing = "missing"
+
+	// MixinContainerPullAlways pulls the image of a mixin each time the mixin
+	// is run.
+	MixinContainerPullAlways = "always"
+)
+
+// MixinContainerConfig runs the schema, build, lint, version and capabilities
+// commands of mixins inside a container, instead of running the mixin binaries
+// installed in PORTER_HOME, for hermetic builds.
+type MixinContainerConfig struct {
+	// Image that mixins are run in, when an image is not configured for the
+	// mixin in Images. The mixin binary, named after the mixin, must be on the
+	// PATH of the image.
+	Image string `mapstructure:"image"`
+
+	// Images that mixins are run in, keyed by the name of the mixin.
+	Images map[string]string `mapstructure:"images"`
+
+	// Pull determines when the image is pulled: missing or always.
+	// Defaults to missing.
+	Pull string `mapstructure:"pull"`
+}
+
+// Enabled determines if any mixin is run in a container.
+func (c MixinContainerConfig) Enabled() bool {
+	return c.Image != "" || len(c.Images) > 0
+}
+
+// GetImage returns the image that the mixin is run in, and false when the
+// mixin is not run in a container.
+func (c MixinContainerConfig) GetImage(mixin string) (string, bool) {
+	if img, ok := c.Images[mixin]; ok && img != "" {
+		return img, true
+	}
+	return c.Image, c.Image != ""
+}
+
+// GetPull returns when the image of a mixin is pulled.
+func (c MixinContainerConfig) GetPull() (string, error) {
+	switch c.Pull {
+	case "":
+		return MixinContainerPullMissing, nil
+	case MixinContainerPullMissing, MixinContainerPullAlways:
+		return c.Pull, nil
+	default:
+		return "", fmt.Errorf("invalid mixin-container.pull value %q, allowed values are: %s, %s", c.Pull, MixinContainerPullMissing, MixinContainerPullAlways)
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+// DefaultMixinAllowedEnv is the list of environment variables that are passed
+// to every mixin when the mixin execution policy restricts the environment.
+var DefaultMixinAllowedEnv = []string{
+	"PATH", "HOME", "USER", "TMPDIR", "TMP", "TEMP", "LANG", "LC_*", "TZ",
+	"SYSTEMROOT", "PORTER_HOME",
+}
+
+// MixinExecutionPolicy controls the environment that mixin binaries are run
+// with on the machine running Porter. Mixins run inside the invocation image
+// are not restricted.
+type MixinExecutionPolicy struct {
+	// RestrictEnvironment only passes the allowed environment variables to
+	// mixins, instead of Porter's full environment.
+	RestrictEnvironment bool `mapstructure:"restrict-environment"`
+
+	// AllowedEnv is the list of environment variables, in addition to
+	// DefaultMixinAllowedEnv, that are passed to every mixin. A name ending
+	// with * matches every variable with that prefix.
+	AllowedEnv []string `mapstructure:"allowed-env"`
+
+	// Mixins is the list of additional environment variables that are passed
+	// to a mixin, keyed by the name of the mixin.
+	Mixins map[string][]string `mapstructure:"mixins"`
+
+	// IsolateWorkingDir runs mixins in an empty temporary directory instead
+	// of the current working directory.
+	IsolateWorkingDir bool `mapstructure:"isolate-working-dir"`
+}
+
+// IsRestricted determines if the policy restricts how mixins are run.
+func (p MixinExecutionPolicy) IsRestricted() bool {
+	return p.RestrictEnvironment || p.IsolateWorkingDir
+}
+
+// GetAllowedEnv returns the environment variables that are passed to the
+// mixin, or nil when every environment variable is passed to the mixin.
+func (p MixinExecutionPolicy) GetAllowedEnv(mixin string) []string {
+	if !p.RestrictEnvironment {
+		return nil
+	}
+
+	allowed := make([]string, 0, len(DefaultMixinAllowedEnv)+len(p.AllowedEnv)+len(p.Mixins[mixin]))
+	allowed = append(allowed, DefaultMixinAllowedEnv...)
+	allowed = append(allowed, p.AllowedEnv...)
+	allowed = append(allowed, p.Mixins[mixin]...)
+	return allowed
+}
@@ -0,0 +1,20 @@
+package config
+
+// MixinTrustPolicy controls which mixin binaries Porter trusts. When public
+// keys are configured, a mixin must have a detached signature, created by one
+// of the corresponding private keys, before Porter runs it.
+type MixinTrustPolicy struct {
+	// PublicKeys is the list of paths to PEM encoded public keys that are
+	// trusted to sign mixins. Relative paths are relative to PORTER_HOME.
+	PublicKeys []string `mapstructure:"public-keys"`
+
+	// AllowUnsigned is the list of mixins that may be run without a
+	// signature. Use * to allow every mixin to be run without a signature.
+	AllowUnsigned []string `mapstructure:"allow-unsigned"`
+}
+
+// RequiresSignature determines if the mixin must be signed by a trusted key
+// before it is run.
+func (p MixinTrustPolicy) RequiresSignature(mixin string) bool {
+	return len(p.PublicKeys) > 0 && !matchesPolicyValue(p.AllowUnsigned, mixin, false)
+}
@@ -0,0 +1,54 @@
+package config
+
+// NamespacePolicyWildcard matches every namespace in a NamespacePolicy.
+const NamespacePolicyWildcard = "*"
+
+// NamespacePolicy grants installations in other namespaces access to the
+// outputs of the installations in a namespace, for example so that bundles
+// deployed to application namespaces can use the connection strings of
+// shared platform services.
+type NamespacePolicy struct {
+	// Namespace whose installation outputs are shared.
+	Namespace string `mapstructure:"namespace"`
+
+	// ShareOutputsWith is the list of namespaces that may read the outputs.
+	// Use * to share the outputs with every namespace.
+	ShareOutputsWith []string `mapstructure:"share-outputs-with"`
+
+	// Installations limits the shared outputs to those generated by the
+	// specified installations. When empty, the outputs of every installation
+	// in the namespace are shared.
+	Installations []string `mapstructure:"installations"`
+
+	// Outputs limits the shared outputs to those with the specified names.
+	// When empty, every output is shared.
+	Outputs []string `mapstructure:"outputs"`
+}
+
+// SharesOutput determines if the policy allows the requesting namespace to
+// read the named output of an installation.
+func (p NamespacePolicy) SharesOutput(requestingNamespace string, namespace string, installation string, output string) bool {
+	if p.Namespace != namespace {
+		return false
+	}
+
+	return matchesPolicyValue(p.ShareOutputsWith, requestingNamespace, false) &&
+		matchesPolicyValue(p.Installations, installation, true) &&
+		matchesPolicyValue(p.Outputs, output, true)
+}
+
+// matchesPolicyValue determines if the value is in the list of allowed
+// values, which may include a wildcard. An empty list matches when
+// matchEmpty is true.
+func matchesPolicyValue(allowed []string, value string, matchEmpty bool) bool {
+	if len(allowed) == 0 {
+		return matchEmpty
+	}
+
+	for _, a := range allowed {
+		if a == NamespacePolicyWildcard || a == value {
+			return true
+		}
+	}
+	return false
+}
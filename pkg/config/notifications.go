@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// DefaultNotificationTimeout is the amount of time to wait for a webhook to
+// accept a notification when a timeout is not configured.
+const DefaultNotificationTimeout = 10 * time.Second
+
+// NotificationConfig is a webhook that is notified when a run of an
+// installation starts, succeeds, or fails.
+type NotificationConfig struct {
+	// Name identifies the notification in messages.
+	Name string `mapstructure:"name"`
+
+	// URL of the webhook, for example a Slack or Microsoft Teams incoming
+	// webhook.
+	URL string `mapstructure:"url"`
+
+	// Body is a Go template for the request body that is rendered with the
+	// run's result. Defaults to a json document describing the result.
+	Body string `mapstructure:"body"`
+
+	// Headers to include in the request, such as an authorization token.
+	Headers map[string]string `mapstructure:"headers"`
+
+	// Timeout is the amount of time to wait for the request, for example 10s.
+	Timeout string `mapstructure:"timeout"`
+
+	// Namespaces limits the notifications to runs of installations in the
+	// specified namespaces. When empty, runs in every namespace are notified.
+	Namespaces []string `mapstructure:"namespaces"`
+
+	// Actions limits the notifications to runs of the specified actions,
+	// for example install. When empty, runs of every action are notified.
+	Actions []string `mapstructure:"actions"`
+
+	// Statuses limits the notifications to results with the specified
+	// statuses, for example failed. When empty, every result is notified.
+	Statuses []string `mapstructure:"statuses"`
+}
+
+// GetName returns the name of the notification, or the host of its url when a
+// name is not specified. The url is not used because webhook urls often
+// include a token, and the name is logged.
+func (n NotificationConfig) GetName() string {
+	if n.Name != "" {
+		return n.Name
+	}
+	if u, err := url.Parse(n.URL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "unnamed"
+}
+
+// Matches determines if the notification applies to a result of a run.
+func (n NotificationConfig) Matches(namespace string, action string, status string) bool {
+	return matchesPolicyValue(n.Namespaces, namespace, true) &&
+		matchesPolicyValue(n.Actions, action, true) &&
+		matchesPolicyValue(n.Statuses, status, true)
+}
+
+// GetTimeout returns the amount of time to wait for the request.
+func (n NotificationConfig) GetTimeout() (time.Duration, error) {
+	if n.Timeout == "" {
+		return DefaultNotificationTimeout, nil
+	}
+
+	d, err := time.ParseDuration(n.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q for notification %s: %w", n.Timeout, n.GetName(), err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid timeout %q for notification %s: the duration cannot be negative", n.Timeout, n.GetName())
+	}
+	return d, nil
+}
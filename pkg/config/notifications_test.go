@@ -0,0 +1,25 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationConfig_GetName(t *testing.T) {
+	testcases := []struct {
+		name string
+		n    NotificationConfig
+		want string
+	}{
+		{name: "named", n: NotificationConfig{Name: "prod-failures", URL: "https://hooks.slack.com/services/T000/B000/topsecret"}, want: "prod-failures"},
+		{name: "unnamed", n: NotificationConfig{URL: "https://hooks.slack.com/services/T000/B000/topsecret"}, want: "hooks.slack.com"},
+		{name: "invalid url", n: NotificationConfig{URL: "://topsecret"}, want: "unnamed"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.n.GetName(), "the url of the webhook should not be used as its name")
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+)
+
+const (
+	// BlobStoreFilesystem saves the values of large outputs to a directory.
+	BlobStoreFilesystem = "filesystem"
+
+	// BlobStoreS3 saves the values of large outputs to an Amazon S3, or S3
+	// compatible, bucket.
+	BlobStoreS3 = "s3"
+
+	// BlobStoreAzure saves the values of large outputs to an Azure Blob Storage container.
+	BlobStoreAzure = "azure"
+)
+
+// OutputStorageConfig limits the size of the output values that are saved in
+// Porter's database. Values that are larger than the limit are saved to a blob
+// store instead, and the output only references the blob.
+type OutputStorageConfig struct {
+	// MaxSize is the maximum size of an output value that is saved in the
+	// database, for example 512KB. Outputs are not limited when it is not set.
+	MaxSize string `mapstructure:"max-size"`
+
+	// Limits override the maximum size of specific outputs.
+	Limits []OutputSizeLimit `mapstructure:"limits"`
+
+	// BlobStore is where the values of outputs that exceed their limit are saved.
+	BlobStore BlobStoreConfig `mapstructure:"blob-store"`
+}
+
+// OutputSizeLimit is the maximum size of the value of an output that is saved
+// in the database.
+type OutputSizeLimit struct {
+	// Output is the name of the output. Use * to match every output.
+	Output string `mapstructure:"output"`
+
+	// MaxSize is the maximum size of the output value, for example 10MB.
+	MaxSize string `mapstructure:"max-size"`
+}
+
+// BlobStoreConfig selects the blob store that holds the values of outputs
+// that exceed their size limit, and the settings of each blob store.
+type BlobStoreConfig struct {
+	// Type of the blob store. Allowed values are: filesystem, s3, azure.
+	// Defaults to filesystem.
+	Type string `mapstructure:"type"`
+
+	Filesystem FilesystemBlobStoreConfig `mapstructure:"filesystem"`
+	S3         S3BlobStoreConfig         `mapstructure:"s3"`
+	Azure      AzureBlobStoreConfig      `mapstructure:"azure"`
+}
+
+// GetType returns the type of the blob store, defaulting to filesystem.
+func (c BlobStoreConfig) GetType() string {
+	if c.Type == "" {
+		return BlobStoreFilesystem
+	}
+	return c.Type
+}
+
+// FilesystemBlobStoreConfig are the settings for saving output values to a directory.
+type FilesystemBlobStoreConfig struct {
+	// Path to the directory where output values are saved. Defaults to PORTER_HOME/outputs.
+	Path string `mapstructure:"path"`
+}
+
+// S3BlobStoreConfig are the settings for saving output values to an S3 bucket.
+type S3BlobStoreConfig struct {
+	// Bucket where output values are saved.
+	Bucket string `mapstructure:"bucket"`
+
+	// Region of the bucket. Defaults to the AWS_REGION environment variable, or
+	// the region in the AWS shared configuration.
+	Region string `mapstructure:"region"`
+
+	// Endpoint of an S3 compatible service, such as MinIO, for example
+	// https://minio.example.com. Buckets are addressed by path when an endpoint
+	// is set.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Prefix is prepended to the name of each object.
+	Prefix string `mapstructure:"prefix"`
+
+	// AccessKeyID used to authenticate. When it is not set, credentials are
+	// resolved with the default credential chain of the AWS SDK.
+	AccessKeyID string `mapstructure:"access-key-id"`
+
+	// SecretAccessKey used to authenticate with AccessKeyID.
+	SecretAccessKey string `mapstructure:"secret-access-key"`
+
+	// SessionToken used with temporary credentials set with AccessKeyID.
+	SessionToken string `mapstructure:"session-token"`
+}
+
+// AzureBlobStoreConfig are the settings for saving output values to an Azure
+// Blob Storage container.
+type AzureBlobStoreConfig struct {
+	// Account is the name of the storage account.
+	Account string `mapstructure:"account"`
+
+	// Container where output values are saved.
+	Container string `mapstructure:"container"`
+
+	// Endpoint of the blob service, such as an Azurite emulator. Defaults to
+	// https://ACCOUNT.blob.core.windows.net.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Prefix is prepended to the name of each blob.
+	Prefix string `mapstructure:"prefix"`
+
+	// AccountKey is the base64 encoded key of the storage account. Defaults
+	// to the AZURE_STORAGE_KEY environment variable.
+	AccountKey string `mapstructure:"account-key"`
+
+	// SASToken is a shared access signature that is used instead of the
+	// account key. Defaults to the AZURE_STORAGE_SAS_TOKEN environment variable.
+	// When neither is set, the default credential chain of the Azure SDK is used.
+	SASToken string `mapstructure:"sas-token"`
+}
+
+// IsEnabled determines if the size of any output is limited.
+func (c OutputStorageConfig) IsEnabled() bool {
+	return c.MaxSize != "" || len(c.Limits) > 0
+}
+
+// GetMaxSize returns the maximum number of bytes of the output's value that
+// are saved in the database, and false when the output is not limited.
+// A limit for the output by name takes precedence over a wildcard limit,
+// which takes precedence over max-size.
+func (c OutputStorageConfig) GetMaxSize(output string) (int64, bool, error) {
+	wildcard := -1
+	for i, limit := range c.Limits {
+		if limit.Output == output {
+			return parseOutputSize(fmt.Sprintf("limits[%d].max-size", i), limit.MaxSize)
+		}
+		if limit.Output == NamespacePolicyWildcard && wildcard < 0 {
+			wildcard = i
+		}
+	}
+
+	if wildcard >= 0 {
+		return parseOutputSize(fmt.Sprintf("limits[%d].max-size", wildcard), c.Limits[wildcard].MaxSize)
+	}
+	if c.MaxSize != "" {
+		return parseOutputSize("max-size", c.MaxSize)
+	}
+	return 0, false, nil
+}
+
+func parseOutputSize(key string, value string) (int64, bool, error) {
+	size, err := humanize.ParseBytes(value)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid output-storage.%s %q: %w", key, value, err)
+	}
+	return int64(size), true, nil
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputStorageConfig_GetMaxSize(t *testing.T) {
+	t.Parallel()
+
+	cfg := OutputStorageConfig{
+		MaxSize: "1MB",
+		Limits: []OutputSizeLimit{
+			{Output: "kubeconfig", MaxSize: "10KiB"},
+			{Output: "*", MaxSize: "512KB"},
+		},
+	}
+	assert.True(t, cfg.IsEnabled())
+
+	size, limited, err := cfg.GetMaxSize("kubeconfig")
+	require.NoError(t, err)
+	assert.True(t, limited)
+	assert.Equal(t, int64(10*1024), size, "the limit for the output should be used")
+
+	size, _, err = cfg.GetMaxSize("tfstate")
+	require.NoError(t, err)
+	assert.Equal(t, int64(512*1000), size, "the wildcard limit should take precedence over max-size")
+
+	cfg.Limits = cfg.Limits[:1]
+	size, _, err = cfg.GetMaxSize("tfstate")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000*1000), size, "max-size should be used when the output does not have a limit")
+
+	_, limited, err = OutputStorageConfig{}.GetMaxSize("tfstate")
+	require.NoError(t, err)
+	assert.False(t, limited, "outputs should not be limited by default")
+
+	_, _, err = OutputStorageConfig{MaxSize: "lots"}.GetMaxSize("tfstate")
+	require.ErrorContains(t, err, `invalid output-storage.max-size "lots"`)
+}
@@ -0,0 +1,28 @@
+package config
+
+// RunLedgerConfig controls the append-only ledger of runs and results, which
+// chains each run and result of an installation to the previous one with a
+// hash so that changes to the run history can be detected.
+type RunLedgerConfig struct {
+	// Enabled records each run and result in the ledger.
+	Enabled bool `mapstructure:"enabled"`
+
+	// SigningKey is the path to a PEM encoded ECDSA, Ed25519 or RSA private
+	// key that signs each entry in the ledger.
+	SigningKey string `mapstructure:"signing-key"`
+
+	// SignCommand is a command that signs each entry in the ledger, for
+	// example with a key in a KMS. The entry is written to the standard input
+	// of the command, which must print the signature, optionally base64
+	// encoded. Used when SigningKey is not set.
+	SignCommand []string `mapstructure:"sign-command"`
+
+	// PublicKey is the path to the PEM encoded public key that verifies the
+	// signatures of the entries in the ledger.
+	PublicKey string `mapstructure:"public-key"`
+}
+
+// IsSigned determines if the entries in the ledger are signed.
+func (c RunLedgerConfig) IsSigned() bool {
+	return c.SigningKey != "" || len(c.SignCommand) > 0
+}
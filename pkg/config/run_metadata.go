@@ -0,0 +1,15 @@
+package config
+
+// RunMetadataConfig controls the metadata, such as the CI pipeline and commit,
+// that is captured from the environment when a run is created.
+type RunMetadataConfig struct {
+	// Disabled stops Porter from capturing metadata on runs.
+	Disabled bool `mapstructure:"disabled"`
+
+	// Detectors limits the metadata to the named detectors, for example
+	// github-actions or gitlab-ci. All detectors are used when it is not set.
+	Detectors []string `mapstructure:"detectors"`
+
+	// ExcludeUser stops Porter from recording the user that started the run.
+	ExcludeUser bool `mapstructure:"exclude-user"`
+}
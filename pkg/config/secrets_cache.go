@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultSecretsCacheTTL is how long a resolved secret is cached when
+	// the ttl is not configured.
+	DefaultSecretsCacheTTL = time.Minute
+
+	// DefaultSecretsCacheMaxEntries is the number of resolved secrets that
+	// are cached when max-entries is not configured.
+	DefaultSecretsCacheMaxEntries = 256
+)
+
+// SecretsCacheConfig controls the in-memory cache of secrets resolved from the
+// secret store, which avoids resolving the same secret more than once during a
+// command. Cached values are never persisted.
+type SecretsCacheConfig struct {
+	// Disabled resolves every secret from the secret store.
+	Disabled bool `mapstructure:"disabled"`
+
+	// TTL is how long a resolved secret is cached, for example 30s.
+	// Defaults to DefaultSecretsCacheTTL.
+	TTL string `mapstructure:"ttl"`
+
+	// MaxEntries is the maximum number of resolved secrets that are cached.
+	// Defaults to DefaultSecretsCacheMaxEntries.
+	MaxEntries int `mapstructure:"max-entries"`
+}
+
+// GetTTL returns how long a resolved secret is cached.
+func (c SecretsCacheConfig) GetTTL() (time.Duration, error) {
+	if c.TTL == "" {
+		return DefaultSecretsCacheTTL, nil
+	}
+
+	ttl, err := time.ParseDuration(c.TTL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid secrets-cache.ttl %q: %w", c.TTL, err)
+	}
+	if ttl <= 0 {
+		return 0, fmt.Errorf("invalid secrets-cache.ttl %q, the ttl must be greater than zero", c.TTL)
+	}
+	return ttl, nil
+}
+
+// GetMaxEntries returns the maximum number of resolved secrets that are cached.
+func (c SecretsCacheConfig) GetMaxEntries() int {
+	if c.MaxEntries <= 0 {
+		return DefaultSecretsCacheMaxEntries
+	}
+	return c.MaxEntries
+}
@@ -0,0 +1,16 @@
+package config
+
+// SensitiveOutputPolicy controls when Porter may reveal the values of
+// sensitive outputs, which are otherwise masked when displayed.
+type SensitiveOutputPolicy struct {
+	// AllowShowSensitive is the list of namespaces in which the values of
+	// sensitive outputs may be revealed with --show-sensitive.
+	// Use * to allow revealing sensitive outputs in every namespace.
+	AllowShowSensitive []string `mapstructure:"allow-show-sensitive"`
+}
+
+// AllowsShowSensitive determines if the policy allows revealing the values of
+// sensitive outputs generated by installations in the namespace.
+func (p SensitiveOutputPolicy) AllowsShowSensitive(namespace string) bool {
+	return matchesPolicyValue(p.AllowShowSensitive, namespace, false)
+}
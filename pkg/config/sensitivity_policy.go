@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// SensitivityPolicy marks parameters and outputs as sensitive, in addition to
+// those marked sensitive by the bundle, so that their values are saved to the
+// secret store instead of Porter's database.
+type SensitivityPolicy struct {
+	// Bundles limits the policy to the bundles with the specified names.
+	// Use * to apply the policy to every bundle. When empty, the policy
+	// applies to every bundle.
+	Bundles []string `mapstructure:"bundles"`
+
+	// Parameters is a list of regular expressions that must match the whole
+	// name of a parameter, for example .*_token.
+	Parameters []string `mapstructure:"parameters"`
+
+	// Outputs is a list of regular expressions that must match the whole name
+	// of an output. Use .* to treat every output of the bundles as sensitive.
+	Outputs []string `mapstructure:"outputs"`
+}
+
+// MatchesParameter determines if the policy marks the parameter of a bundle as sensitive.
+func (p SensitivityPolicy) MatchesParameter(bundle string, parameter string) (bool, error) {
+	if !matchesPolicyValue(p.Bundles, bundle, true) {
+		return false, nil
+	}
+	return matchesSensitivityPattern(p.Parameters, parameter)
+}
+
+// MatchesOutput determines if the policy marks the output of a bundle as sensitive.
+func (p SensitivityPolicy) MatchesOutput(bundle string, output string) (bool, error) {
+	if !matchesPolicyValue(p.Bundles, bundle, true) {
+		return false, nil
+	}
+	return matchesSensitivityPattern(p.Outputs, output)
+}
+
+func matchesSensitivityPattern(patterns []string, name string) (bool, error) {
+	for _, pattern := range patterns {
+		r, err := compileSensitivityPattern(pattern)
+		if err != nil {
+			return false, err
+		}
+		if r.MatchString(name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// compileSensitivityPattern compiles a pattern so that it only matches whole
+// names, otherwise a pattern like token would match every name containing it.
+func compileSensitivityPattern(pattern string) (*regexp.Regexp, error) {
+	r, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid sensitivity policy pattern %q: %w", pattern, err)
+	}
+	return r, nil
+}
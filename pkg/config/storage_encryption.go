@@ -0,0 +1,19 @@
+package config
+
+// StorageEncryption configures the encryption of documents before they are
+// persisted by the storage plugin.
+type StorageEncryption struct {
+	// Key is the name of the secret, in the secret store, that holds the
+	// base64 encoded 256-bit key used to encrypt documents.
+	// Documents are not encrypted when the key is not set.
+	Key string `mapstructure:"key"`
+
+	// PreviousKeys are the names of the secrets that hold keys used before the
+	// key was rotated. They are only used to decrypt existing documents.
+	PreviousKeys []string `mapstructure:"previous-keys"`
+}
+
+// Enabled determines if documents should be encrypted before they are persisted.
+func (e StorageEncryption) Enabled() bool {
+	return e.Key != ""
+}
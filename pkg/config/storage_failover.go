@@ -0,0 +1,30 @@
+package config
+
+import "path/filepath"
+
+// StorageFailoverConfig configures a secondary storage account that Porter
+// uses when the default storage account is unreachable.
+type StorageFailoverConfig struct {
+	// Secondary is the name of the storage account that is read from, and
+	// written to, while the default storage account is unreachable.
+	Secondary string `mapstructure:"secondary"`
+
+	// Queue is the path to the file where writes are queued while the
+	// default storage account is unreachable, so that they are replayed when
+	// it is reachable again. Defaults to PORTER_HOME/storage-failover-queue.jsonl.
+	Queue string `mapstructure:"queue"`
+}
+
+// GetStorageFailoverQueue returns the path to the file where writes are
+// queued while the default storage account is unreachable.
+func (c *Config) GetStorageFailoverQueue() (string, error) {
+	if c.Data.StorageFailover.Queue != "" {
+		return c.Data.StorageFailover.Queue, nil
+	}
+
+	home, err := c.GetHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "storage-failover-queue.jsonl"), nil
+}
@@ -0,0 +1,216 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// macros are the shorthand expressions that may be used instead of the five fields.
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// field is the definition of one of the fields of an expression.
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: monthNames},
+	{name: "day of week", min: 0, max: 7, names: dayNames},
+}
+
+// Expression is a parsed cron expression with the standard five fields:
+// minute, hour, day of month, month and day of week.
+type Expression struct {
+	raw     string
+	minutes uint64
+	hours   uint64
+	days    uint64
+	months  uint64
+	weekday uint64
+
+	// anyDay and anyWeekday record if the day of month and day of week
+	// fields are *, because when both are restricted a time matches when
+	// either matches.
+	anyDay     bool
+	anyWeekday bool
+}
+
+// Parse a cron expression, such as "0 2 * * *" or "@daily". Each field may be
+// *, a value, a range such as 1-5, a step such as */15 or 1-30/5, or a
+// comma separated list of them. Months and days of the week may be specified
+// by their three letter names, such as jan or mon.
+func Parse(expr string) (Expression, error) {
+	spec := strings.TrimSpace(expr)
+	if macro, ok := macros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return Expression{}, fmt.Errorf("invalid cron expression %q: expected 5 fields, minute hour day-of-month month day-of-week, but got %d", expr, len(parts))
+	}
+
+	values := make([]uint64, len(fields))
+	for i, f := range fields {
+		bits, err := f.parse(parts[i])
+		if err != nil {
+			return Expression{}, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+		}
+		values[i] = bits
+	}
+
+	// Sunday may be specified as 0 or 7
+	weekday := values[4]
+	if weekday&(1<<7) != 0 {
+		weekday |= 1
+		weekday &^= 1 << 7
+	}
+
+	return Expression{
+		raw:        expr,
+		minutes:    values[0],
+		hours:      values[1],
+		days:       values[2],
+		months:     values[3],
+		weekday:    weekday,
+		anyDay:     parts[2] == "*" || parts[2] == "?",
+		anyWeekday: parts[4] == "*" || parts[4] == "?",
+	}, nil
+}
+
+// parse a field into a bit set of the values that it matches.
+func (f field) parse(value string) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(value, ",") {
+		rangePart, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			rangePart = item[:i]
+			var err error
+			step, err = strconv.Atoi(item[i+1:])
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q in the %s field", item[i+1:], f.name)
+			}
+		}
+
+		start, end := f.min, f.max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if start, err = f.parseValue(bounds[0]); err != nil {
+				return 0, err
+			}
+			if end, err = f.parseValue(bounds[1]); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q in the %s field, the start is after the end", rangePart, f.name)
+			}
+		default:
+			var err error
+			if start, err = f.parseValue(rangePart); err != nil {
+				return 0, err
+			}
+			end = start
+			if strings.Contains(item, "/") {
+				end = f.max
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (f field) parseValue(value string) (int, error) {
+	if n, ok := f.names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in the %s field", value, f.name)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("invalid value %d in the %s field, it must be between %d and %d", n, f.name, f.min, f.max)
+	}
+	return n, nil
+}
+
+// String returns the expression as it was specified.
+func (e Expression) String() string {
+	return e.raw
+}
+
+// Next returns the first time after the specified time that matches the
+// expression, in the location of the specified time. The zero time is
+// returned when no time matches within five years, for example for 30 February.
+func (e Expression) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if e.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !e.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if e.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if e.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (e Expression) matchesDay(t time.Time) bool {
+	dayMatches := e.days&(1<<uint(t.Day())) != 0
+	weekdayMatches := e.weekday&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case e.anyDay && e.anyWeekday:
+		return true
+	case e.anyDay:
+		return weekdayMatches
+	case e.anyWeekday:
+		return dayMatches
+	default:
+		return dayMatches || weekdayMatches
+	}
+}
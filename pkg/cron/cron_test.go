@@ -0,0 +1,68 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		expr    string
+		wantErr string
+	}{
+		{expr: "* * * *", wantErr: "expected 5 fields"},
+		{expr: "60 * * * *", wantErr: "invalid value 60 in the minute field"},
+		{expr: "* * * foo *", wantErr: `invalid value "foo" in the month field`},
+		{expr: "*/0 * * * *", wantErr: `invalid step "0" in the minute field`},
+		{expr: "* 5-2 * * *", wantErr: "the start is after the end"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.expr, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Parse(tc.expr)
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestExpression_Next(t *testing.T) {
+	t.Parallel()
+
+	// Wednesday
+	now := time.Date(2026, time.March, 4, 10, 17, 30, 0, time.UTC)
+
+	testcases := []struct {
+		expr string
+		want time.Time
+	}{
+		{expr: "* * * * *", want: time.Date(2026, time.March, 4, 10, 18, 0, 0, time.UTC)},
+		{expr: "*/15 * * * *", want: time.Date(2026, time.March, 4, 10, 30, 0, 0, time.UTC)},
+		{expr: "0 2 * * *", want: time.Date(2026, time.March, 5, 2, 0, 0, 0, time.UTC)},
+		{expr: "@daily", want: time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC)},
+		{expr: "@hourly", want: time.Date(2026, time.March, 4, 11, 0, 0, 0, time.UTC)},
+		{expr: "30 9 * * mon-fri", want: time.Date(2026, time.March, 5, 9, 30, 0, 0, time.UTC)},
+		{expr: "0 0 * * 7", want: time.Date(2026, time.March, 8, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 1 jan,jul *", want: time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 15 * fri", want: time.Date(2026, time.March, 6, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 30 2 *", want: time.Time{}},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.expr, func(t *testing.T) {
+			t.Parallel()
+
+			expr, err := Parse(tc.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, expr.Next(now))
+		})
+	}
+}
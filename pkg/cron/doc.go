@@ -0,0 +1,3 @@
+// Package cron parses the cron expressions used to schedule runs of an
+// installation, and calculates when a schedule is next due.
+package cron
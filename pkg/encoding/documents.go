@@ -0,0 +1,92 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/carolynvs/aferox"
+	"gopkg.in/yaml.v3"
+)
+
+// Document is one of the documents defined in a file.
+type Document struct {
+	// Index is the position of the document in the file, starting at 1.
+	Index int
+
+	format string
+	data   []byte
+	node   *yaml.Node
+}
+
+// Unmarshal the document into a struct.
+func (d Document) Unmarshal(out interface{}) error {
+	if d.node != nil {
+		// Decode the parsed node so that errors report lines relative to the file
+		return d.node.Decode(out)
+	}
+	return Unmarshal(d.format, d.data, out)
+}
+
+// UnmarshalDocumentsFile reads the documents defined in a file.
+// YAML files may define multiple documents separated by ---, and empty YAML
+// documents are skipped. Files in other formats define a single document.
+// Supported file extensions are: yaml, yml, json, and toml.
+func UnmarshalDocumentsFile(fs aferox.Aferox, path string) ([]Document, error) {
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %w", path, err)
+	}
+	format := strings.TrimPrefix(filepath.Ext(path), ".")
+	return UnmarshalDocuments(format, data)
+}
+
+// UnmarshalDocuments splits the data into the documents that it defines.
+// Supported formats are: yaml, json, and toml.
+func UnmarshalDocuments(format string, data []byte) ([]Document, error) {
+	switch format {
+	case "yaml", "yml":
+		return splitYamlDocuments(data)
+	case "json", "toml":
+		return []Document{{Index: 1, format: format, data: data}}, nil
+	default:
+		return nil, newUnsupportedFormatError(format)
+	}
+}
+
+func splitYamlDocuments(data []byte) ([]Document, error) {
+	var docs []Document
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for index := 1; ; index++ {
+		node := &yaml.Node{}
+		err := decoder.Decode(node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// The decoder cannot continue after a syntax error
+			return nil, fmt.Errorf("error parsing document %d: %w", index, err)
+		}
+
+		if isEmptyYamlDocument(node) {
+			continue
+		}
+		docs = append(docs, Document{Index: index, format: Yaml, node: node})
+	}
+	return docs, nil
+}
+
+// isEmptyYamlDocument determines if the document only contains comments or whitespace.
+func isEmptyYamlDocument(node *yaml.Node) bool {
+	if len(node.Content) == 0 {
+		return true
+	}
+	if len(node.Content) == 1 {
+		value := node.Content[0]
+		return value.Kind == yaml.ScalarNode && value.Tag == "!!null" && value.Value == ""
+	}
+	return false
+}
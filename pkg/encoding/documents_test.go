@@ -0,0 +1,79 @@
+package encoding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalDocuments(t *testing.T) {
+	t.Parallel()
+
+	type resource struct {
+		Name string `yaml:"name" json:"name"`
+	}
+
+	t.Run("multiple yaml documents", func(t *testing.T) {
+		t.Parallel()
+
+		data := []byte(`---
+name: first
+---
+# empty documents are skipped
+---
+name: second
+`)
+		docs, err := UnmarshalDocuments(Yaml, data)
+		require.NoError(t, err)
+		require.Len(t, docs, 2)
+
+		var first, second resource
+		require.NoError(t, docs[0].Unmarshal(&first))
+		require.NoError(t, docs[1].Unmarshal(&second))
+		assert.Equal(t, 1, docs[0].Index)
+		assert.Equal(t, "first", first.Name)
+		assert.Equal(t, 3, docs[1].Index, "expected the index to count empty documents")
+		assert.Equal(t, "second", second.Name)
+	})
+
+	t.Run("invalid yaml document", func(t *testing.T) {
+		t.Parallel()
+
+		data := []byte("name: first\n---\nname: [\n")
+		_, err := UnmarshalDocuments(Yaml, data)
+		require.ErrorContains(t, err, "error parsing document 2")
+	})
+
+	t.Run("decode errors report the line in the file", func(t *testing.T) {
+		t.Parallel()
+
+		data := []byte("name: first\n---\nname:\n  - second\n")
+		docs, err := UnmarshalDocuments(Yaml, data)
+		require.NoError(t, err)
+		require.Len(t, docs, 2)
+
+		var r resource
+		err = docs[1].Unmarshal(&r)
+		require.ErrorContains(t, err, "line 4")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		docs, err := UnmarshalDocuments(Json, []byte(`{"name": "first"}`))
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+
+		var r resource
+		require.NoError(t, docs[0].Unmarshal(&r))
+		assert.Equal(t, "first", r.Name)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := UnmarshalDocuments("xml", nil)
+		require.ErrorContains(t, err, "unsupported format xml")
+	})
+}
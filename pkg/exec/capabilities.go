@@ -0,0 +1,42 @@
+package exec
+
+import (
+	"fmt"
+
+	"get.porter.sh/porter/pkg/mixin"
+	"get.porter.sh/porter/pkg/printer"
+)
+
+// CapabilitiesOptions represent options for the exec mixin's capabilities command.
+type CapabilitiesOptions struct {
+	printer.PrintOptions
+}
+
+// Validate the options provided to the exec mixin's capabilities command.
+func (o *CapabilitiesOptions) Validate() error {
+	return o.PrintOptions.Validate(printer.FormatJson, []printer.Format{printer.FormatJson, printer.FormatYaml})
+}
+
+// GetCapabilities returns the capabilities of the exec mixin.
+func (m *Mixin) GetCapabilities() mixin.Capabilities {
+	return mixin.Capabilities{
+		ProtocolVersions: []string{mixin.ProtocolVersion},
+		Commands:         []string{"build", "lint", "schema", "version", "capabilities", "install", "upgrade", "invoke", "uninstall"},
+		// The build input is parsed with a yaml parser, which also accepts json
+		BuildInputFormats: []string{mixin.BuildInputFormatYaml, mixin.BuildInputFormatJson},
+	}
+}
+
+// PrintCapabilities prints the capabilities of the exec mixin, which Porter
+// uses to negotiate the mixin protocol version.
+func (m *Mixin) PrintCapabilities(opts CapabilitiesOptions) error {
+	caps := m.GetCapabilities()
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(m.Config.Out, caps)
+	case printer.FormatYaml:
+		return printer.PrintYaml(m.Config.Out, caps)
+	default:
+		return fmt.Errorf("invalid format: %s", opts.Format)
+	}
+}
@@ -0,0 +1,26 @@
+package exec
+
+import (
+	"encoding/json"
+	"testing"
+
+	"get.porter.sh/porter/pkg/mixin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMixin_PrintCapabilities(t *testing.T) {
+	m := NewTestMixin(t)
+
+	opts := CapabilitiesOptions{}
+	require.NoError(t, opts.Validate())
+	require.NoError(t, m.PrintCapabilities(opts))
+
+	var caps mixin.Capabilities
+	require.NoError(t, json.Unmarshal([]byte(m.TestConfig.TestContext.GetOutput()), &caps), "the capabilities should be printed as json by default")
+	protocol, err := caps.NegotiateProtocol()
+	require.NoError(t, err)
+	assert.Equal(t, mixin.ProtocolVersion, protocol)
+	assert.True(t, caps.Supports("lint"))
+	assert.True(t, caps.Supports("status"), "custom actions should be supported")
+}
@@ -0,0 +1,116 @@
+// Package failure classifies errors by the type of failure, so that Porter can
+// exit with a distinct exit code for each class of failure.
+package failure
+
+import (
+	"context"
+	"errors"
+)
+
+// Class of failure.
+type Class string
+
+const (
+	// ClassValidation is a failure caused by invalid input, such as an invalid
+	// flag, argument or parameter value, that was detected before any changes
+	// were made.
+	ClassValidation Class = "validation"
+
+	// ClassExecution is a failure of the bundle while it was run.
+	ClassExecution Class = "execution"
+
+	// ClassPartialSuccess is a failure of an operation on multiple resources,
+	// where some of the resources were changed successfully.
+	ClassPartialSuccess Class = "partial-success"
+
+	// ClassPolicyDenied is a failure caused by a policy in Porter's
+	// configuration that does not allow the operation.
+	ClassPolicyDenied Class = "policy-denied"
+
+	// ClassTimeout is a failure caused by an operation that did not complete
+	// before its timeout.
+	ClassTimeout Class = "timeout"
+
+	// ClassStorageUnavailable is a failure to connect to Porter's storage.
+	ClassStorageUnavailable Class = "storage-unavailable"
+)
+
+// Error classifies the error that it wraps. Its message is the message of the
+// wrapped error.
+type Error struct {
+	Class Class
+	Err   error
+}
+
+// New classifies an error. A nil error is returned as nil, so that the result
+// of a function may be classified without checking it first.
+func New(class Class, err error) error {
+	if err == nil {
+		return nil
+	}
+	return Error{Class: class, Err: err}
+}
+
+// Validation classifies the error as a validation failure.
+func Validation(err error) error {
+	return New(ClassValidation, err)
+}
+
+// Execution classifies the error as a failure of the bundle.
+func Execution(err error) error {
+	return New(ClassExecution, err)
+}
+
+// PartialSuccess classifies the error as a failure of an operation that
+// changed some of its resources successfully.
+func PartialSuccess(err error) error {
+	return New(ClassPartialSuccess, err)
+}
+
+// PolicyDenied classifies the error as a denial by a policy.
+func PolicyDenied(err error) error {
+	return New(ClassPolicyDenied, err)
+}
+
+// Timeout classifies the error as a timeout.
+func Timeout(err error) error {
+	return New(ClassTimeout, err)
+}
+
+// StorageUnavailable classifies the error as a failure to connect to storage.
+func StorageUnavailable(err error) error {
+	return New(ClassStorageUnavailable, err)
+}
+
+func (e Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
+// Is allows errors.Is to find an error of the class anywhere in the chain.
+func (e Error) Is(target error) bool {
+	class, ok := target.(classTarget)
+	return ok && Class(class) == e.Class
+}
+
+// classTarget is the error that Is compares to the class of an Error.
+type classTarget Class
+
+func (c classTarget) Error() string {
+	return string(c)
+}
+
+// Is determines if the error, or any error that it wraps, is classified as the
+// class of failure. An exceeded context deadline is always a timeout.
+func Is(err error, class Class) bool {
+	if err == nil {
+		return false
+	}
+	if class == ClassTimeout && errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	return errors.Is(err, classTarget(class))
+}
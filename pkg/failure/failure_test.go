@@ -0,0 +1,45 @@
+package failure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	assert.Nil(t, Validation(nil), "a nil error should not be classified")
+
+	err := PolicyDenied(errors.New("denied by the dependency policy"))
+	require.EqualError(t, err, "denied by the dependency policy", "the message should not change")
+	assert.True(t, Is(err, ClassPolicyDenied))
+	assert.False(t, Is(err, ClassValidation))
+}
+
+func TestIs(t *testing.T) {
+	timeout := Timeout(errors.New("the install action timed out"))
+
+	t.Run("wrapped", func(t *testing.T) {
+		err := Execution(fmt.Errorf("execution of install for installation mysql failed: %w", timeout))
+		assert.True(t, Is(err, ClassExecution))
+		assert.True(t, Is(err, ClassTimeout), "the classes of wrapped errors should be found")
+	})
+
+	t.Run("multierror", func(t *testing.T) {
+		err := multierror.Append(errors.New("could not save the outputs"), timeout)
+		assert.True(t, Is(err, ClassTimeout), "the classes of every error in a multierror should be found")
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		err := fmt.Errorf("could not pull the bundle: %w", context.DeadlineExceeded)
+		assert.True(t, Is(err, ClassTimeout))
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		assert.False(t, Is(nil, ClassTimeout))
+	})
+}
@@ -0,0 +1,5 @@
+// Package faults injects failures at specific points of a run, so that
+// integration tests, and users validating their rollback and retry
+// configuration, can reproduce a failure on demand. Failures are only injected
+// when they are requested with the PORTER_INJECT_FAULTS environment variable.
+package faults
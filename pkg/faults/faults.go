@@ -0,0 +1,115 @@
+package faults
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"get.porter.sh/porter/pkg/config"
+)
+
+const (
+	// AfterStep fails the action after the step with the specified number,
+	// starting at 1, completes. The failure is injected by the Porter runtime
+	// in the invocation image.
+	AfterStep = "after-step"
+
+	// DriverRun fails the run of the specified action, or every action when an
+	// action is not specified, before the driver runs the bundle.
+	DriverRun = "driver-run"
+
+	// SaveOutput fails saving the output with the specified name, or every
+	// output when a name is not specified.
+	SaveOutput = "save-output"
+
+	// SecretWrite fails writing the secret for the specified parameter or
+	// output name, or every secret when a name is not specified.
+	SecretWrite = "secret-write"
+)
+
+// Points where failures may be injected.
+var Points = []string{AfterStep, DriverRun, SaveOutput, SecretWrite}
+
+// ErrInjectedFault is returned at a point where a failure was injected.
+type ErrInjectedFault struct {
+	// Point where the failure was injected, for example AfterStep.
+	Point string
+
+	// Target of the failure at the point, for example the step number.
+	Target string
+}
+
+func (e ErrInjectedFault) Error() string {
+	if e.Target == "" {
+		return fmt.Sprintf("injected fault at %s, requested with %s", e.Point, config.EnvInjectFaults)
+	}
+	return fmt.Sprintf("injected fault at %s %s, requested with %s", e.Point, e.Target, config.EnvInjectFaults)
+}
+
+// Faults are the failures requested for a process.
+type Faults struct {
+	// targets of each point where a failure is injected. An empty target
+	// matches every target at the point.
+	targets map[string][]string
+
+	// err is returned at every point when the requested failures are invalid.
+	err error
+}
+
+// FromEnv returns the failures requested with the PORTER_INJECT_FAULTS environment variable.
+func FromEnv(getenv func(string) string) Faults {
+	return Parse(getenv(config.EnvInjectFaults))
+}
+
+// Parse a comma separated list of POINT or POINT=TARGET, for example
+// after-step=2,save-output=connstr. When the list is invalid, the error is
+// returned at every point, so that the run fails instead of silently running
+// without the requested failures.
+func Parse(value string) Faults {
+	f := Faults{targets: make(map[string][]string)}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		point, target, _ := strings.Cut(entry, "=")
+		switch point {
+		case AfterStep:
+			if step, err := strconv.Atoi(target); err != nil || step < 1 {
+				f.err = fmt.Errorf("invalid %s value %q: %s requires a step number starting at 1, for example %s=2", config.EnvInjectFaults, value, AfterStep, AfterStep)
+				return f
+			}
+		case DriverRun, SaveOutput, SecretWrite:
+		default:
+			f.err = fmt.Errorf("invalid %s value %q: unsupported point %s, allowed values are %s", config.EnvInjectFaults, value, point, strings.Join(Points, ", "))
+			return f
+		}
+		f.targets[point] = append(f.targets[point], target)
+	}
+	return f
+}
+
+// Enabled determines if any failures were requested.
+func (f Faults) Enabled() bool {
+	return f.err != nil || len(f.targets) > 0
+}
+
+// Inject returns an ErrInjectedFault when a failure was requested at the
+// point for the target.
+func (f Faults) Inject(point string, target string) error {
+	return f.inject(point, target, func(want string) bool { return want == target })
+}
+
+func (f Faults) inject(point string, target string, matches func(want string) bool) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	for _, want := range f.targets[point] {
+		if want == "" || matches(want) {
+			return ErrInjectedFault{Point: point, Target: target}
+		}
+	}
+	return nil
+}
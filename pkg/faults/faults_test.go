@@ -0,0 +1,91 @@
+package faults
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	f := Parse("after-step=2, save-output=connstr,driver-run")
+	assert.True(t, f.Enabled())
+
+	assert.NoError(t, f.Inject(AfterStep, "1"))
+	assert.ErrorIs(t, f.Inject(AfterStep, "2"), ErrInjectedFault{Point: AfterStep, Target: "2"})
+	assert.NoError(t, f.Inject(SaveOutput, "password"))
+	assert.ErrorIs(t, f.Inject(SaveOutput, "connstr"), ErrInjectedFault{Point: SaveOutput, Target: "connstr"})
+	assert.Error(t, f.Inject(DriverRun, "install"), "a point without a target should match every target")
+	assert.NoError(t, f.Inject(SecretWrite, "password"))
+}
+
+func TestParse_Disabled(t *testing.T) {
+	f := Parse("")
+	assert.False(t, f.Enabled())
+	for _, point := range Points {
+		assert.NoError(t, f.Inject(point, ""))
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	testcases := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{name: "unsupported point", value: "save-output,oops", wantErr: "unsupported point oops"},
+		{name: "missing step", value: "after-step", wantErr: "after-step requires a step number"},
+		{name: "invalid step", value: "after-step=0", wantErr: "after-step requires a step number"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := Parse(tc.value)
+			assert.True(t, f.Enabled())
+
+			// The error is returned at every point so that the run does not silently continue
+			for _, point := range Points {
+				err := f.Inject(point, "")
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	getenv := func(key string) string {
+		if key == config.EnvInjectFaults {
+			return "driver-run=upgrade"
+		}
+		return ""
+	}
+	f := FromEnv(getenv)
+	assert.NoError(t, f.Inject(DriverRun, "install"))
+	assert.Error(t, f.Inject(DriverRun, "upgrade"))
+}
+
+func TestWrapSecretStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled", func(t *testing.T) {
+		store := secrets.NewTestSecretsProvider()
+		assert.Equal(t, store, WrapSecretStore(store, Parse("")), "the store should not be wrapped when no failures were requested")
+	})
+
+	t.Run("secret-write", func(t *testing.T) {
+		store := WrapSecretStore(secrets.NewTestSecretsProvider(), Parse("secret-write=password"))
+
+		err := store.Create(ctx, secrets.SourceSecret, "01GD5Z8XRQ-8BY2-password", "topsecret")
+		assert.ErrorIs(t, err, ErrInjectedFault{Point: SecretWrite, Target: "01GD5Z8XRQ-8BY2-password"})
+
+		err = store.Create(ctx, secrets.SourceSecret, "01GD5Z8XRQ-8BY2-connstr", "mysql://localhost")
+		require.NoError(t, err, "secrets for other names should be written")
+		value, err := store.Resolve(ctx, secrets.SourceSecret, "01GD5Z8XRQ-8BY2-connstr")
+		require.NoError(t, err)
+		assert.Equal(t, "mysql://localhost", value)
+	})
+}
@@ -0,0 +1,37 @@
+package faults
+
+import (
+	"context"
+	"strings"
+
+	"get.porter.sh/porter/pkg/secrets"
+)
+
+var _ secrets.Store = secretStore{}
+
+// secretStore injects failures when secrets are written.
+type secretStore struct {
+	secrets.Store
+	faults Faults
+}
+
+// WrapSecretStore returns a secret store that fails writing secrets as
+// requested by the SecretWrite point. The store is returned unchanged when no
+// failures were requested.
+func WrapSecretStore(store secrets.Store, f Faults) secrets.Store {
+	if !f.Enabled() {
+		return store
+	}
+	return secretStore{Store: store, faults: f}
+}
+
+func (s secretStore) Create(ctx context.Context, keyName string, keyValue string, value string) error {
+	// The secrets saved by Porter are named ID-NAME, match the name of the parameter or output
+	matches := func(want string) bool {
+		return keyValue == want || strings.HasSuffix(keyValue, "-"+want)
+	}
+	if err := s.faults.inject(SecretWrite, keyValue, matches); err != nil {
+		return err
+	}
+	return s.Store.Create(ctx, keyName, keyValue, value)
+}
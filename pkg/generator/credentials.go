@@ -24,10 +24,7 @@ func GenerateCredentials(opts GenerateCredentialsOptions) (storage.CredentialSet
 	if opts.Name == "" {
 		return storage.CredentialSet{}, errors.New("credentialset name is required")
 	}
-	generator := genSurvey
-	if opts.Silent {
-		generator = genEmptySet
-	}
+	generator := opts.getGenerator()
 	credSet, err := genCredentialSet(opts.Namespace, opts.Name, opts.Credentials, generator)
 	if err != nil {
 		return storage.CredentialSet{}, err
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"get.porter.sh/porter/pkg/printer"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/stretchr/testify/require"
 )
@@ -91,3 +92,24 @@ func TestNoCredentialsName(t *testing.T) {
 	require.Error(t, err, "expected an error because name is required")
 	require.Empty(t, cs, "credential set should have been empty")
 }
+
+func TestGenerateCredentials_PromptsDisabled(t *testing.T) {
+	opts := GenerateCredentialsOptions{
+		GenerateOptions: GenerateOptions{
+			Name:    "mycreds",
+			Profile: printer.ProfileCI,
+		},
+		Credentials: map[string]bundle.Credential{
+			"token": {},
+		},
+	}
+
+	_, err := GenerateCredentials(opts)
+	require.ErrorAs(t, err, &printer.ErrPromptRequired{})
+	require.ErrorContains(t, err, `setting credential "token" requires prompting for input, which is disabled by the ci output profile. Use --silent`)
+
+	opts.Silent = true
+	cs, err := GenerateCredentials(opts)
+	require.NoError(t, err, "silent generation should not prompt")
+	require.Len(t, cs.Credentials, 1)
+}
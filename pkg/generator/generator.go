@@ -3,6 +3,7 @@ package generator
 import (
 	"fmt"
 
+	"get.porter.sh/porter/pkg/printer"
 	"get.porter.sh/porter/pkg/secrets"
 	"github.com/cnabio/cnab-go/secrets/host"
 	survey "gopkg.in/AlecAivazis/survey.v1"
@@ -17,6 +18,24 @@ type GenerateOptions struct {
 
 	// Should we survey?
 	Silent bool
+
+	// Profile is the output profile, which controls if we may survey.
+	Profile printer.Profile
+}
+
+// getGenerator returns the generator for the options, either an empty set
+// when Silent is set, or a survey that fails when the profile disables prompts.
+func (o GenerateOptions) getGenerator() generator {
+	if o.Silent {
+		return genEmptySet
+	}
+
+	return func(name string, surveyType SurveyType) (secrets.Strategy, error) {
+		if err := o.Profile.CheckPrompt(fmt.Sprintf("setting %s %q", surveyType, name)); err != nil {
+			return secrets.Strategy{}, fmt.Errorf("%w. Use --silent to generate placeholder values instead", err)
+		}
+		return genSurvey(name, surveyType)
+	}
 }
 
 // SurveyType indicates whether the survey is for a parameter or credential
@@ -3,7 +3,6 @@ package generator
 import (
 	"errors"
 	"fmt"
-	"sort"
 	"strings"
 
 	"get.porter.sh/porter/pkg/cnab"
@@ -23,10 +22,7 @@ func (opts *GenerateParametersOptions) GenerateParameters() (storage.ParameterSe
 	if opts.Name == "" {
 		return storage.ParameterSet{}, errors.New("parameter set name is required")
 	}
-	generator := genSurvey
-	if opts.Silent {
-		generator = genEmptySet
-	}
+	generator := opts.getGenerator()
 	pset, err := opts.genParameterSet(generator)
 	if err != nil {
 		return storage.ParameterSet{}, err
@@ -43,18 +39,8 @@ func (opts *GenerateParametersOptions) genParameterSet(fn generator) (storage.Pa
 		return pset, fmt.Errorf("parameter set name '%s' cannot contain the following characters: './\\'", opts.Name)
 	}
 
-	var parameterNames []string
-	for name := range opts.Bundle.Parameters {
-		parameterNames = append(parameterNames, name)
-	}
-
-	sort.Strings(parameterNames)
-
-	for _, name := range parameterNames {
-		if opts.Bundle.IsInternalParameter(name) {
-			continue
-		}
-		c, err := fn(name, surveyParameters)
+	for _, param := range opts.Bundle.ListUserParameters() {
+		c, err := fn(param.Name, surveyParameters)
 		if err != nil {
 			return pset, err
 		}
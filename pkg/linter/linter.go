@@ -3,10 +3,12 @@ package linter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
 	"get.porter.sh/porter/pkg/manifest"
+	"get.porter.sh/porter/pkg/mixin"
 	"get.porter.sh/porter/pkg/mixin/query"
 	"get.porter.sh/porter/pkg/pkgmgmt"
 	"get.porter.sh/porter/pkg/portercontext"
@@ -170,7 +172,7 @@ func (l *Linter) Lint(ctx context.Context, m *manifest.Manifest) (Results, error
 	for _, response := range responses {
 		if response.Error != nil {
 			// Ignore mixins that do not support the lint command
-			if strings.Contains(response.Error.Error(), "unknown command") {
+			if errors.Is(response.Error, mixin.ErrCommandNotSupported) || strings.Contains(response.Error.Error(), "unknown command") {
 				continue
 			}
 			return nil, span.Error(fmt.Errorf("lint command failed for mixin %s: %s", response.Name, response.Stdout))
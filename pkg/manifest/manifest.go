@@ -16,6 +16,7 @@ import (
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/portercontext"
 	"get.porter.sh/porter/pkg/schema"
+	"get.porter.sh/porter/pkg/tools"
 	"get.porter.sh/porter/pkg/tracing"
 	"get.porter.sh/porter/pkg/yaml"
 	"github.com/Masterminds/semver/v3"
@@ -101,6 +102,26 @@ type Manifest struct {
 	ImageMap map[string]MappedImage `yaml:"images,omitempty"`
 
 	Required []RequiredExtension `yaml:"required,omitempty"`
+
+	// Constraints declares the minimum versions of Porter and mixins, and the
+	// extensions, that are required by the bundle.
+	Constraints Constraints `yaml:"constraints,omitempty"`
+
+	// Driver declares the driver used to run the bundle by default, and the
+	// drivers that are able to run the bundle.
+	Driver DriverRequirements `yaml:"driver,omitempty"`
+
+	// Environment is the name of the environment, defined in Environments,
+	// that is applied when the bundle is built. It may be overridden with porter build --env.
+	Environment string `yaml:"environment,omitempty"`
+
+	// Environments are named sets of overrides, such as the registry, image
+	// references and default parameter values, that are applied when the bundle is built.
+	Environments map[string]Environment `yaml:"environments,omitempty"`
+
+	// Tools are installed into the invocation image at the pinned version
+	// when the bundle is built.
+	Tools []tools.Pin `yaml:"tools,omitempty"`
 }
 
 func (m *Manifest) Validate(cxt *portercontext.Context, strategy schema.CheckStrategy) error {
@@ -154,6 +175,16 @@ func (m *Manifest) Validate(cxt *portercontext.Context, strategy schema.CheckStr
 		}
 	}
 
+	err = m.validateDependencyOrder()
+	if err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	err = m.validateDependencyOutputReferences()
+	if err != nil {
+		result = multierror.Append(result, err)
+	}
+
 	for _, output := range m.Outputs {
 		err = output.Validate()
 		if err != nil {
@@ -166,6 +197,11 @@ func (m *Manifest) Validate(cxt *portercontext.Context, strategy schema.CheckStr
 		if err != nil {
 			result = multierror.Append(result, err)
 		}
+
+		err = m.validateParameterSource(parameter)
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
 	}
 
 	for _, image := range m.ImageMap {
@@ -175,9 +211,120 @@ func (m *Manifest) Validate(cxt *portercontext.Context, strategy schema.CheckStr
 		}
 	}
 
+	err = m.Constraints.Validate()
+	if err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	err = m.Driver.Validate()
+	if err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	err = m.validateEnvironments()
+	if err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	err = tools.ValidatePins(m.Tools)
+	if err != nil {
+		result = multierror.Append(result, err)
+	}
+
 	return result
 }
 
+// validateEnvironments checks that the selected environment is defined and
+// that the environments only override images and parameters that exist.
+func (m *Manifest) validateEnvironments() error {
+	var result error
+
+	if m.Environment != "" {
+		if _, ok := m.Environments[m.Environment]; !ok {
+			result = multierror.Append(result, fmt.Errorf("environment %s is not defined in the environments section of the manifest", m.Environment))
+		}
+	}
+
+	for name, env := range m.Environments {
+		if err := env.Validate(name, m); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	return result
+}
+
+// validateDependencyOutputReferences checks that templated dependency outputs,
+// bundle.dependencies.DEPENDENCY.outputs.OUTPUT, refer to a declared dependency.
+func (m *Manifest) validateDependencyOutputReferences() error {
+	var result error
+
+	deps := make(map[string]bool, len(m.Dependencies.Requires))
+	for _, dep := range m.Dependencies.Requires {
+		deps[dep.Name] = true
+	}
+
+	refs := m.GetTemplatedDependencyOutputs()
+	keys := make([]string, 0, len(refs))
+	for key := range refs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		ref := refs[key]
+		if !deps[ref.Dependency] {
+			result = multierror.Append(result, fmt.Errorf("bundle.dependencies.%s.outputs.%s references dependency %s which is not defined in the dependencies section of the manifest", ref.Dependency, ref.Output, ref.Dependency))
+		}
+	}
+
+	return result
+}
+
+// validateDependencyOrder checks that dependsOn only refers to dependencies
+// that are listed before the dependency, so that the listed order is always
+// a valid order in which to execute the dependencies.
+func (m *Manifest) validateDependencyOrder() error {
+	var result error
+
+	listed := make(map[string]bool, len(m.Dependencies.Requires))
+	for _, dep := range m.Dependencies.Requires {
+		for _, prereq := range dep.DependsOn {
+			if !listed[prereq] {
+				result = multierror.Append(result, fmt.Errorf("dependency %s depends on %s, which must be defined before it in the dependencies section of the manifest", dep.Name, prereq))
+			}
+		}
+		listed[dep.Name] = true
+	}
+
+	return result
+}
+
+// validateParameterSource checks that a parameter is not sourced from an
+// ephemeral output, which is never persisted and so can't be used by a later run.
+func (m *Manifest) validateParameterSource(pd ParameterDefinition) error {
+	if pd.Source.Installation != "" {
+		if pd.Source.Dependency != "" {
+			return fmt.Errorf("parameter %s source cannot specify both a dependency and an installation", pd.Name)
+		}
+		namespace, name := pd.Source.GetInstallationReference()
+		if name == "" || strings.Contains(name, "/") || (strings.Contains(pd.Source.Installation, "/") && namespace == "") {
+			return fmt.Errorf("parameter %s source has an invalid installation %q, the installation must be in the format [NAMESPACE/]NAME", pd.Name, pd.Source.Installation)
+		}
+		return nil
+	}
+
+	if pd.Source.Output == "" || pd.Source.Dependency != "" {
+		return nil
+	}
+
+	output, ok := m.Outputs[pd.Source.Output]
+	if ok && output.Ephemeral {
+		return fmt.Errorf("parameter %s cannot use output %s as its source because the output is ephemeral and is never persisted", pd.Name, output.Name)
+	}
+	return nil
+}
+
 func (m *Manifest) validateMetadata(cxt *portercontext.Context, strategy schema.CheckStrategy) error {
 	if warnOnly, err := schema.ValidateSchemaVersion(strategy, SupportedSchemaVersions, m.SchemaVersion, DefaultSchemaVersion); err != nil {
 		if warnOnly {
@@ -402,11 +549,12 @@ func (pd *ParameterDefinition) AppliesTo(action string) bool {
 }
 
 // exemptFromInstall returns true if a parameter definition:
-//   - has an output source (which will not exist prior to install)
+//   - has an output source (which will not exist prior to install), that is not
+//     the output of another installation
 //   - doesn't already have applyTo specified
 //   - doesn't have a default value
 func (pd *ParameterDefinition) exemptFromInstall() bool {
-	return pd.Source.Output != "" && pd.ApplyTo == nil && pd.Default == nil
+	return pd.Source.Output != "" && pd.Source.Installation == "" && pd.ApplyTo == nil && pd.Default == nil
 }
 
 // UpdateApplyTo updates a parameter definition's applyTo section
@@ -430,7 +578,22 @@ func (pd *ParameterDefinition) UpdateApplyTo(m *Manifest) {
 
 type ParameterSource struct {
 	Dependency string `yaml:"dependency,omitempty"`
-	Output     string `yaml:"output"`
+
+	// Installation that generated the output, in the format [NAMESPACE/]NAME.
+	// When the namespace is omitted, the installation is in the same namespace
+	// as the installation of the current bundle.
+	Installation string `yaml:"installation,omitempty"`
+
+	Output string `yaml:"output"`
+}
+
+// GetInstallationReference splits the installation of the parameter source
+// into its namespace and name. The namespace is empty when it isn't specified.
+func (s ParameterSource) GetInstallationReference() (namespace string, name string) {
+	if i := strings.Index(s.Installation, "/"); i >= 0 {
+		return s.Installation[:i], s.Installation[i+1:]
+	}
+	return "", s.Installation
 }
 
 // CredentialDefinitions allows us to represent credentials as a list in the YAML
@@ -633,6 +796,11 @@ type Dependency struct {
 	Bundle BundleCriteria `yaml:"bundle"`
 
 	Parameters map[string]string `yaml:"parameters,omitempty"`
+
+	// DependsOn are the names of the dependencies that must be executed before
+	// this dependency. When any dependency declares dependsOn, dependencies
+	// that do not depend on each other are executed at the same time.
+	DependsOn []string `yaml:"dependsOn,omitempty"`
 }
 
 type BundleCriteria struct {
@@ -708,6 +876,10 @@ type OutputDefinition struct {
 	ApplyTo   []string `yaml:"applyTo,omitempty"`
 	Sensitive bool     `yaml:"sensitive"`
 
+	// Ephemeral outputs are printed after the bundle runs but are never persisted,
+	// for example a one-time bootstrap token.
+	Ephemeral bool `yaml:"ephemeral,omitempty"`
+
 	// This is not in the CNAB spec, but it allows a mixin to create a file
 	// and porter will take care of making it a proper output.
 	Path string `yaml:"path,omitempty"`
@@ -1167,6 +1339,183 @@ func (r *RequiredExtension) UnmarshalYAML(unmarshal func(interface{}) error) err
 	return nil
 }
 
+// Constraints declares the minimum versions of Porter and mixins, and the
+// extensions, that a bundle requires.
+//
+//	constraints:
+//	  porter: v1.0.0
+//	  mixins:
+//	    helm3: v0.1.16
+//	  extensions:
+//	    - docker
+type Constraints struct {
+	// Porter is the minimum version of Porter that can build and run the bundle.
+	Porter string `yaml:"porter,omitempty"`
+
+	// Mixins is a map of mixin names to the minimum version of the mixin required to build the bundle.
+	Mixins map[string]string `yaml:"mixins,omitempty"`
+
+	// Extensions that Porter must support in order to run the bundle.
+	Extensions []string `yaml:"extensions,omitempty"`
+}
+
+// Validate that the constraints are valid semantic versions and reference known extensions.
+func (c Constraints) Validate() error {
+	var result error
+
+	if c.Porter != "" {
+		if _, err := semver.NewVersion(c.Porter); err != nil {
+			result = multierror.Append(result, fmt.Errorf("invalid porter version constraint %q, it must be a valid semver value: %w", c.Porter, err))
+		}
+	}
+
+	for mixin, version := range c.Mixins {
+		if _, err := semver.NewVersion(version); err != nil {
+			result = multierror.Append(result, fmt.Errorf("invalid version constraint %q for the %s mixin, it must be a valid semver value: %w", version, mixin, err))
+		}
+	}
+
+	for _, ext := range c.Extensions {
+		if _, err := cnab.GetSupportedExtension(ext); err != nil {
+			result = multierror.Append(result, fmt.Errorf("invalid extension constraint: %w", err))
+		}
+	}
+
+	return result
+}
+
+// ToBundleConstraints converts the constraints to the representation stored in the bundle.
+func (c Constraints) ToBundleConstraints() cnab.Constraints {
+	bc := cnab.Constraints{
+		Porter: c.Porter,
+	}
+
+	if len(c.Mixins) > 0 {
+		bc.Mixins = make(map[string]string, len(c.Mixins))
+		for mixin, version := range c.Mixins {
+			bc.Mixins[mixin] = version
+		}
+	}
+
+	// Always store the full extension key so that consumers don't need to know the shorthand
+	for _, ext := range c.Extensions {
+		if supported, err := cnab.GetSupportedExtension(ext); err == nil {
+			ext = supported.Key
+		}
+		bc.Extensions = append(bc.Extensions, ext)
+	}
+
+	return bc
+}
+
+// DriverRequirements declares the driver used to run a bundle when a driver
+// is not specified, and the drivers that are able to run the bundle.
+//
+//	driver:
+//	  default: kubernetes
+//	  supported:
+//	    - kubernetes
+type DriverRequirements struct {
+	// Default is the driver used to run the bundle when a driver is not specified.
+	Default string `yaml:"default,omitempty"`
+
+	// Supported is the list of drivers that can run the bundle. Any driver
+	// may be used when it is empty.
+	Supported []string `yaml:"supported,omitempty"`
+}
+
+// Validate that the default driver is one of the supported drivers.
+func (d DriverRequirements) Validate() error {
+	var result error
+
+	for _, driver := range d.Supported {
+		if driver == "" {
+			result = multierror.Append(result, errors.New("invalid driver requirements: the supported drivers cannot include an empty driver name"))
+		}
+	}
+
+	bd := d.ToBundleDriverRequirements()
+	if d.Default != "" && !bd.IsSupported(d.Default) {
+		result = multierror.Append(result, fmt.Errorf("invalid driver requirements: the default driver %s must be one of the supported drivers: %s", d.Default, strings.Join(d.Supported, ", ")))
+	}
+
+	return result
+}
+
+// ToBundleDriverRequirements converts the driver requirements to the representation stored in the bundle.
+func (d DriverRequirements) ToBundleDriverRequirements() cnab.DriverRequirements {
+	bd := cnab.DriverRequirements{Default: d.Default}
+	if len(d.Supported) > 0 {
+		bd.Supported = make([]string, len(d.Supported))
+		copy(bd.Supported, d.Supported)
+	}
+	return bd
+}
+
+// Environment is a named set of overrides that is applied to the manifest
+// when the bundle is built for that environment.
+//
+//	environments:
+//	  prod:
+//	    registry: example.com/prod
+//	    images:
+//	      whalesayd:
+//	        repository: example.com/prod/whalesayd
+//	        tag: v1.2.3
+//	    parameters:
+//	      replicas: 3
+type Environment struct {
+	// Registry overrides the registry to which the bundle is published.
+	Registry string `yaml:"registry,omitempty"`
+
+	// Reference overrides the full bundle reference.
+	Reference string `yaml:"reference,omitempty"`
+
+	// Images is a map of the names of images from the images section to their overrides.
+	Images map[string]EnvironmentImage `yaml:"images,omitempty"`
+
+	// Parameters is a map of parameter names to their default value in the environment.
+	Parameters map[string]interface{} `yaml:"parameters,omitempty"`
+}
+
+// EnvironmentImage overrides the location of an image from the images section.
+// When the repository or tag is overridden without a digest, the digest is
+// resolved again when the bundle is built.
+type EnvironmentImage struct {
+	Repository string `yaml:"repository,omitempty"`
+	Tag        string `yaml:"tag,omitempty"`
+	Digest     string `yaml:"digest,omitempty"`
+}
+
+// Validate that the environment only overrides images and parameters defined
+// in the manifest.
+func (e Environment) Validate(name string, m *Manifest) error {
+	var result error
+
+	if e.Registry != "" && e.Reference == "" && m.Reference != "" {
+		result = multierror.Append(result, fmt.Errorf("environment %s overrides the registry but the manifest defines a reference, which takes precedence, override the reference instead", name))
+	}
+
+	for imageName, img := range e.Images {
+		if _, ok := m.ImageMap[imageName]; !ok {
+			result = multierror.Append(result, fmt.Errorf("environment %s overrides image %s which is not defined in the images section of the manifest", name, imageName))
+		}
+		if img.Digest != "" {
+			if _, err := digest.Parse(img.Digest); err != nil {
+				result = multierror.Append(result, fmt.Errorf("environment %s has an invalid digest for image %s: %w", name, imageName, err))
+			}
+		}
+	}
+
+	for paramName := range e.Parameters {
+		if _, ok := m.Parameters[paramName]; !ok {
+			result = multierror.Append(result, fmt.Errorf("environment %s overrides parameter %s which is not defined in the parameters section of the manifest", name, paramName))
+		}
+	}
+
+	return result
+}
+
 // Convert a parameter name to an environment variable.
 // Anything more complicated should define the variable explicitly.
 func ParamToEnvVar(name string) string {
@@ -5,9 +5,11 @@ import (
 	"os"
 	"testing"
 
+	"get.porter.sh/porter/pkg/cnab"
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/portercontext"
 	"get.porter.sh/porter/pkg/schema"
+	"get.porter.sh/porter/pkg/tools"
 	"get.porter.sh/porter/pkg/yaml"
 	"github.com/cnabio/cnab-go/bundle/definition"
 	"github.com/stretchr/testify/assert"
@@ -258,6 +260,22 @@ func TestManifest_Validate_Dockerfile(t *testing.T) {
 	assert.EqualError(t, err, "Dockerfile template cannot be named 'Dockerfile' because that is the filename generated during porter build")
 }
 
+func TestManifest_Validate_Tools(t *testing.T) {
+	c := config.NewTestConfig(t)
+
+	c.TestContext.AddTestFile("testdata/simple.porter.yaml", config.Name)
+
+	m, err := LoadManifestFrom(context.Background(), c.Config, config.Name)
+	require.NoError(t, err, "could not load manifest")
+
+	m.Tools = []tools.Pin{{Name: "kubectl", Version: "v1.27.3"}}
+	require.NoError(t, m.Validate(c.Context, schema.CheckStrategyNone))
+
+	m.Tools = append(m.Tools, tools.Pin{Name: "kubectl", Version: "v1.26.0"})
+	err = m.Validate(c.Context, schema.CheckStrategyNone)
+	require.ErrorContains(t, err, "tool kubectl is pinned more than once")
+}
+
 func TestManifest_Validate_WrongSchema(t *testing.T) {
 	c := config.NewTestConfig(t)
 
@@ -585,6 +603,110 @@ func TestValidateOutputDefinition_defaultFailsValidation(t *testing.T) {
 `)
 }
 
+func TestManifest_validateParameterSource(t *testing.T) {
+	m := &Manifest{
+		Outputs: OutputDefinitions{
+			"token":      {Name: "token", Ephemeral: true},
+			"kubeconfig": {Name: "kubeconfig"},
+		},
+	}
+
+	t.Run("persisted output", func(t *testing.T) {
+		pd := ParameterDefinition{Name: "kubeconfig", Source: ParameterSource{Output: "kubeconfig"}}
+		require.NoError(t, m.validateParameterSource(pd))
+	})
+
+	t.Run("ephemeral output", func(t *testing.T) {
+		pd := ParameterDefinition{Name: "token", Source: ParameterSource{Output: "token"}}
+		err := m.validateParameterSource(pd)
+		require.EqualError(t, err, "parameter token cannot use output token as its source because the output is ephemeral and is never persisted")
+	})
+
+	t.Run("dependency output", func(t *testing.T) {
+		pd := ParameterDefinition{Name: "token", Source: ParameterSource{Dependency: "mysql", Output: "token"}}
+		require.NoError(t, m.validateParameterSource(pd), "outputs of a dependency should not be checked against the bundle's outputs")
+	})
+
+	t.Run("installation output", func(t *testing.T) {
+		pd := ParameterDefinition{Name: "token", Source: ParameterSource{Installation: "platform/mysql", Output: "token"}}
+		require.NoError(t, m.validateParameterSource(pd), "outputs of another installation should not be checked against the bundle's outputs")
+	})
+
+	t.Run("installation and dependency", func(t *testing.T) {
+		pd := ParameterDefinition{Name: "connstr", Source: ParameterSource{Dependency: "mysql", Installation: "platform/mysql", Output: "connstr"}}
+		err := m.validateParameterSource(pd)
+		require.EqualError(t, err, "parameter connstr source cannot specify both a dependency and an installation")
+	})
+
+	t.Run("invalid installation", func(t *testing.T) {
+		for _, installation := range []string{"/mysql", "platform/", "a/b/c"} {
+			pd := ParameterDefinition{Name: "connstr", Source: ParameterSource{Installation: installation, Output: "connstr"}}
+			err := m.validateParameterSource(pd)
+			require.ErrorContains(t, err, "the installation must be in the format [NAMESPACE/]NAME", installation)
+		}
+	})
+}
+
+func TestParameterSource_GetInstallationReference(t *testing.T) {
+	namespace, name := ParameterSource{Installation: "platform/mysql"}.GetInstallationReference()
+	assert.Equal(t, "platform", namespace)
+	assert.Equal(t, "mysql", name)
+
+	namespace, name = ParameterSource{Installation: "mysql"}.GetInstallationReference()
+	assert.Empty(t, namespace)
+	assert.Equal(t, "mysql", name)
+}
+
+func TestManifest_validateDependencyOutputReferences(t *testing.T) {
+	m := &Manifest{
+		Dependencies: Dependencies{
+			Requires: []*Dependency{{Name: "mysql"}},
+		},
+	}
+
+	t.Run("declared dependency", func(t *testing.T) {
+		m.TemplateVariables = []string{"bundle.dependencies.mysql.outputs.connstr"}
+		require.NoError(t, m.validateDependencyOutputReferences())
+	})
+
+	t.Run("undeclared dependency", func(t *testing.T) {
+		m.TemplateVariables = []string{"bundle.dependencies.redis.outputs.connstr"}
+		err := m.validateDependencyOutputReferences()
+		require.ErrorContains(t, err, "bundle.dependencies.redis.outputs.connstr references dependency redis which is not defined in the dependencies section of the manifest")
+	})
+}
+
+func TestManifest_validateDependencyOrder(t *testing.T) {
+	t.Run("depends on earlier dependency", func(t *testing.T) {
+		m := &Manifest{
+			Dependencies: Dependencies{
+				Requires: []*Dependency{{Name: "mysql"}, {Name: "redis"}, {Name: "app", DependsOn: []string{"mysql", "redis"}}},
+			},
+		}
+		require.NoError(t, m.validateDependencyOrder())
+	})
+
+	t.Run("depends on later dependency", func(t *testing.T) {
+		m := &Manifest{
+			Dependencies: Dependencies{
+				Requires: []*Dependency{{Name: "app", DependsOn: []string{"mysql"}}, {Name: "mysql"}},
+			},
+		}
+		err := m.validateDependencyOrder()
+		require.ErrorContains(t, err, "dependency app depends on mysql, which must be defined before it in the dependencies section of the manifest")
+	})
+
+	t.Run("depends on undeclared dependency", func(t *testing.T) {
+		m := &Manifest{
+			Dependencies: Dependencies{
+				Requires: []*Dependency{{Name: "app", DependsOn: []string{"redis"}}},
+			},
+		}
+		err := m.validateDependencyOrder()
+		require.ErrorContains(t, err, "dependency app depends on redis")
+	})
+}
+
 func TestValidateImageMap(t *testing.T) {
 	t.Run("with valid image digest, valid repository format and valid tag", func(t *testing.T) {
 		mi := MappedImage{
@@ -731,6 +853,70 @@ func TestLoadManifestWithRequiredExtensions(t *testing.T) {
 	assert.Equal(t, expected, m.Required)
 }
 
+func TestLoadManifestWithConstraints(t *testing.T) {
+	c := config.NewTestConfig(t)
+
+	c.TestContext.AddTestFile("testdata/porter-with-constraints.yaml", config.Name)
+
+	m, err := LoadManifestFrom(context.Background(), c.Config, config.Name)
+	require.NoError(t, err, "could not load manifest")
+
+	wantConstraints := Constraints{
+		Porter:     "v1.0.0",
+		Mixins:     map[string]string{"exec": "v1.0.0"},
+		Extensions: []string{"docker"},
+	}
+	assert.Equal(t, wantConstraints, m.Constraints)
+
+	bunConstraints := m.Constraints.ToBundleConstraints()
+	assert.Equal(t, []string{cnab.DockerExtensionKey}, bunConstraints.Extensions, "the full extension key should be stored in the bundle")
+}
+
+func TestConstraints_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		c := Constraints{Porter: "v1.0.0", Mixins: map[string]string{"exec": "1.0.0"}, Extensions: []string{"docker"}}
+		require.NoError(t, c.Validate())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		c := Constraints{Porter: "latest", Mixins: map[string]string{"exec": "oops"}, Extensions: []string{"vpn"}}
+		err := c.Validate()
+		require.ErrorContains(t, err, `invalid porter version constraint "latest"`)
+		require.ErrorContains(t, err, `invalid version constraint "oops" for the exec mixin`)
+		require.ErrorContains(t, err, "unsupported required extension: vpn")
+	})
+}
+
+func TestLoadManifestWithDriver(t *testing.T) {
+	c := config.NewTestConfig(t)
+
+	c.TestContext.AddTestFile("testdata/porter-with-driver.yaml", config.Name)
+
+	m, err := LoadManifestFrom(context.Background(), c.Config, config.Name)
+	require.NoError(t, err, "could not load manifest")
+
+	assert.Equal(t, DriverRequirements{Default: "kubernetes", Supported: []string{"kubernetes"}}, m.Driver)
+}
+
+func TestDriverRequirements_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		d := DriverRequirements{Default: "kubernetes", Supported: []string{"docker", "kubernetes"}}
+		require.NoError(t, d.Validate())
+	})
+
+	t.Run("default only", func(t *testing.T) {
+		d := DriverRequirements{Default: "kubernetes"}
+		require.NoError(t, d.Validate())
+	})
+
+	t.Run("unsupported default", func(t *testing.T) {
+		d := DriverRequirements{Default: "docker", Supported: []string{"kubernetes", ""}}
+		err := d.Validate()
+		require.ErrorContains(t, err, "the default driver docker must be one of the supported drivers: kubernetes")
+		require.ErrorContains(t, err, "the supported drivers cannot include an empty driver name")
+	})
+}
+
 func TestReadManifest_WithTemplateVariables(t *testing.T) {
 	cxt := portercontext.NewTestContext(t)
 	cxt.AddTestFile("testdata/porter-with-templating.yaml", config.Name)
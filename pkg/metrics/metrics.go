@@ -0,0 +1,184 @@
+// Package metrics defines the Prometheus metrics that Porter records while it
+// runs bundles, so that long-running Porter processes, such as porter api serve
+// or the Porter Operator agent, can be monitored.
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// Namespace prefixes the name of every metric recorded by Porter.
+	Namespace = "porter"
+
+	// ResultSuccess is the result label of an operation that succeeded.
+	ResultSuccess = "success"
+
+	// ResultError is the result label of an operation that failed.
+	ResultError = "error"
+)
+
+// Registry contains the metrics recorded by Porter. A dedicated registry is
+// used, instead of the global Prometheus registry, so that only Porter's
+// metrics and the go runtime metrics are exposed.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	// RunDuration is the time taken to execute a bundle, by action and status.
+	RunDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "run_duration_seconds",
+		Help:      "Time taken to execute a bundle action.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600},
+	}, []string{"action", "status"})
+
+	// SecretStoreDuration is the latency of calls to the secret store, by
+	// operation and result.
+	SecretStoreDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "secret_store_duration_seconds",
+		Help:      "Latency of calls to the secret store.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "result"})
+
+	// MixinInvocations counts the commands run by mixins, by mixin, command and result.
+	MixinInvocations = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "mixin_invocations_total",
+		Help:      "Number of mixin commands that were run.",
+	}, []string{"mixin", "command", "result"})
+
+	// StorageErrors counts the errors returned by the storage plugin, by
+	// operation and collection.
+	StorageErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "storage_errors_total",
+		Help:      "Number of errors returned by the storage plugin.",
+	}, []string{"operation", "collection"})
+
+	// StorageQueries counts the calls to the storage plugin, by operation and
+	// collection.
+	StorageQueries = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "storage_queries_total",
+		Help:      "Number of queries made to the storage plugin.",
+	}, []string{"operation", "collection"})
+
+	// StorageDocumentsScanned counts the documents returned by queries to the
+	// storage plugin, by collection.
+	StorageDocumentsScanned = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "storage_documents_scanned_total",
+		Help:      "Number of documents returned by queries to the storage plugin.",
+	}, []string{"collection"})
+
+	// CacheLookups counts the lookups of bundles in the local bundle cache, by
+	// result, either hit or miss.
+	CacheLookups = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "cache_lookups_total",
+		Help:      "Number of lookups of bundles in the local bundle cache.",
+	}, []string{"result"})
+)
+
+const (
+	// CacheHit is the result label of a cache lookup that found the bundle.
+	CacheHit = "hit"
+
+	// CacheMiss is the result label of a cache lookup that did not find the bundle.
+	CacheMiss = "miss"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+}
+
+// ObserveRun records the duration of a bundle run.
+func ObserveRun(action string, status string, duration time.Duration) {
+	RunDuration.WithLabelValues(action, status).Observe(duration.Seconds())
+}
+
+// ObserveSecretStore records the latency of a call to the secret store that
+// started at the specified time.
+func ObserveSecretStore(operation string, start time.Time, err error) {
+	SecretStoreDuration.WithLabelValues(operation, result(err)).Observe(time.Since(start).Seconds())
+}
+
+// CountMixinInvocation records that a mixin command was run.
+func CountMixinInvocation(mixin string, command string, err error) {
+	MixinInvocations.WithLabelValues(mixin, command, result(err)).Inc()
+}
+
+// CountStorageError records an error returned by the storage plugin. Nil
+// errors are ignored so that it can be called with the result of any call.
+func CountStorageError(operation string, collection string, err error) {
+	if err == nil {
+		return
+	}
+	StorageErrors.WithLabelValues(operation, collection).Inc()
+}
+
+// CountStorageQuery records a call to the storage plugin, the number of
+// documents that it returned, and if it failed.
+func CountStorageQuery(operation string, collection string, documents int, err error) {
+	StorageQueries.WithLabelValues(operation, collection).Inc()
+	if documents > 0 {
+		StorageDocumentsScanned.WithLabelValues(collection).Add(float64(documents))
+	}
+	CountStorageError(operation, collection, err)
+}
+
+// CountCacheLookup records a lookup in the bundle cache, and if the bundle was found.
+func CountCacheLookup(found bool) {
+	if found {
+		CacheLookups.WithLabelValues(CacheHit).Inc()
+		return
+	}
+	CacheLookups.WithLabelValues(CacheMiss).Inc()
+}
+
+// MetricsPath is the path that the metrics are served at.
+const MetricsPath = "/metrics"
+
+// Listen serves the metrics at MetricsPath on the address in the background,
+// until the returned server is shut down.
+func Listen(address string) (*http.Server, net.Addr, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not listen on %s: %w", address, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, Handler())
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go server.Serve(listener)
+
+	return server, listener.Addr(), nil
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+func result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultSuccess
+}
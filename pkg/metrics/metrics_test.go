@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountStorageError(t *testing.T) {
+	counter := StorageErrors.WithLabelValues("insert", "test-collection")
+	before := testutil.ToFloat64(counter)
+
+	CountStorageError("insert", "test-collection", nil)
+	assert.Equal(t, before, testutil.ToFloat64(counter), "nil errors should not be counted")
+
+	CountStorageError("insert", "test-collection", errors.New("oops"))
+	assert.Equal(t, before+1, testutil.ToFloat64(counter))
+}
+
+func TestCountMixinInvocation(t *testing.T) {
+	success := MixinInvocations.WithLabelValues("test-mixin", "build", ResultSuccess)
+	failed := MixinInvocations.WithLabelValues("test-mixin", "build", ResultError)
+	beforeSuccess := testutil.ToFloat64(success)
+	beforeFailed := testutil.ToFloat64(failed)
+
+	CountMixinInvocation("test-mixin", "build", nil)
+	CountMixinInvocation("test-mixin", "build", errors.New("oops"))
+
+	assert.Equal(t, beforeSuccess+1, testutil.ToFloat64(success))
+	assert.Equal(t, beforeFailed+1, testutil.ToFloat64(failed))
+}
+
+func TestListen(t *testing.T) {
+	ObserveRun("test-action", "succeeded", 3*time.Second)
+	ObserveSecretStore("resolve", time.Now(), nil)
+
+	server, addr, err := Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	resp, err := http.Get("http://" + addr.String() + MetricsPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `porter_run_duration_seconds_count{action="test-action",status="succeeded"} 1`)
+	assert.Contains(t, string(body), `porter_secret_store_duration_seconds_count{operation="resolve",result="success"}`)
+	assert.Contains(t, string(body), "go_goroutines")
+}
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// StorageStats summarizes the queries made to the storage plugin and the
+// lookups in the bundle cache by the current process.
+type StorageStats struct {
+	// Collections are the query counts for each collection, sorted by name.
+	Collections []CollectionStats `json:"collections" yaml:"collections"`
+
+	// CacheHits is the number of bundles that were found in the bundle cache.
+	CacheHits int64 `json:"cacheHits" yaml:"cacheHits"`
+
+	// CacheMisses is the number of bundles that were not found in the bundle cache.
+	CacheMisses int64 `json:"cacheMisses" yaml:"cacheMisses"`
+}
+
+// CollectionStats are the query counts for a single collection.
+type CollectionStats struct {
+	// Collection that was queried.
+	Collection string `json:"collection" yaml:"collection"`
+
+	// Queries is the number of calls to the storage plugin for the collection.
+	Queries int64 `json:"queries" yaml:"queries"`
+
+	// DocumentsScanned is the number of documents returned by the queries.
+	DocumentsScanned int64 `json:"documentsScanned" yaml:"documentsScanned"`
+
+	// Errors is the number of queries that failed.
+	Errors int64 `json:"errors" yaml:"errors"`
+}
+
+// CacheHitRate is the percentage of cache lookups that found the bundle.
+func (s StorageStats) CacheHitRate() float64 {
+	total := s.CacheHits + s.CacheMisses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.CacheHits) / float64(total) * 100
+}
+
+// GetStorageStats summarizes the storage and cache metrics recorded so far.
+func GetStorageStats() (StorageStats, error) {
+	families, err := Registry.Gather()
+	if err != nil {
+		return StorageStats{}, fmt.Errorf("could not gather the storage metrics: %w", err)
+	}
+
+	var stats StorageStats
+	collections := make(map[string]*CollectionStats)
+	getCollection := func(m *dto.Metric) *CollectionStats {
+		name := getLabel(m, "collection")
+		if _, ok := collections[name]; !ok {
+			collections[name] = &CollectionStats{Collection: name}
+		}
+		return collections[name]
+	}
+
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			value := int64(m.GetCounter().GetValue())
+			switch family.GetName() {
+			case Namespace + "_storage_queries_total":
+				getCollection(m).Queries += value
+			case Namespace + "_storage_documents_scanned_total":
+				getCollection(m).DocumentsScanned += value
+			case Namespace + "_storage_errors_total":
+				// Errors from ensuring indices are not for a single collection
+				if getLabel(m, "collection") != "" {
+					getCollection(m).Errors += value
+				}
+			case Namespace + "_cache_lookups_total":
+				if getLabel(m, "result") == CacheHit {
+					stats.CacheHits += value
+				} else {
+					stats.CacheMisses += value
+				}
+			}
+		}
+	}
+
+	for _, c := range collections {
+		stats.Collections = append(stats.Collections, *c)
+	}
+	sort.Slice(stats.Collections, func(i, j int) bool {
+		return stats.Collections[i].Collection < stats.Collections[j].Collection
+	})
+	return stats, nil
+}
+
+func getLabel(m *dto.Metric, name string) string {
+	for _, label := range m.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
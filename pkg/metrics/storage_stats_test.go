@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStorageStats(t *testing.T) {
+	before, err := GetStorageStats()
+	require.NoError(t, err)
+
+	CountStorageQuery("find", "stats-test", 3, nil)
+	CountStorageQuery("aggregate", "stats-test", 2, nil)
+	CountStorageQuery("insert", "stats-test", 0, errors.New("oops"))
+	CountCacheLookup(true)
+	CountCacheLookup(true)
+	CountCacheLookup(false)
+
+	stats, err := GetStorageStats()
+	require.NoError(t, err)
+
+	var got *CollectionStats
+	for i, c := range stats.Collections {
+		if c.Collection == "stats-test" {
+			got = &stats.Collections[i]
+		}
+	}
+	require.NotNil(t, got, "expected stats for the stats-test collection")
+	assert.Equal(t, CollectionStats{Collection: "stats-test", Queries: 3, DocumentsScanned: 5, Errors: 1}, *got)
+	assert.Equal(t, before.CacheHits+2, stats.CacheHits)
+	assert.Equal(t, before.CacheMisses+1, stats.CacheMisses)
+}
+
+func TestStorageStats_CacheHitRate(t *testing.T) {
+	assert.Equal(t, float64(0), StorageStats{}.CacheHitRate())
+	assert.Equal(t, float64(75), StorageStats{CacheHits: 3, CacheMisses: 1}.CacheHitRate())
+}
@@ -0,0 +1,235 @@
+package mixin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/singleflight"
+)
+
+// ProtocolVersion is the version of the protocol that Porter uses to
+// communicate with mixins: the commands that Porter runs, and the input that
+// it sends to them.
+const ProtocolVersion = "1"
+
+// SupportedProtocolVersions are the versions of the mixin protocol that this
+// version of Porter supports.
+var SupportedProtocolVersions = []string{ProtocolVersion}
+
+const (
+	// BuildInputFormatYaml is a build input formatted as yaml, which every
+	// mixin supports.
+	BuildInputFormatYaml = "yaml"
+
+	// BuildInputFormatJson is a build input formatted as json.
+	BuildInputFormatJson = "json"
+)
+
+// legacyCommands are the commands that Porter assumes a mixin supports when
+// it does not report its capabilities.
+var legacyCommands = []string{"build", "install", "upgrade", "uninstall", "invoke", "lint", "schema", "version"}
+
+// ErrCommandNotSupported is returned when a mixin reports in its capabilities
+// that it does not support a command.
+var ErrCommandNotSupported = errors.New("the mixin does not support the command")
+
+// Capabilities of a mixin, reported by the mixin's capabilities command, that
+// let Porter negotiate the protocol version and only run the commands that
+// the mixin supports.
+type Capabilities struct {
+	// ProtocolVersions are the versions of the mixin protocol that the mixin
+	// supports. Defaults to version 1.
+	ProtocolVersions []string `json:"protocolVersions,omitempty" yaml:"protocolVersions,omitempty"`
+
+	// Commands that the mixin supports, for example build, lint and schema.
+	// Custom actions are supported by the invoke command.
+	Commands []string `json:"commands,omitempty" yaml:"commands,omitempty"`
+
+	// BuildInputFormats are the formats of the input that the mixin accepts
+	// on stdin for the build and lint commands. Defaults to yaml.
+	BuildInputFormats []string `json:"buildInputFormats,omitempty" yaml:"buildInputFormats,omitempty"`
+
+	// Legacy indicates that the mixin did not report its capabilities, and
+	// they were assumed by Porter.
+	Legacy bool `json:"-" yaml:"-"`
+}
+
+// LegacyCapabilities are the capabilities that Porter assumes a mixin has when
+// it does not support the capabilities command.
+func LegacyCapabilities() Capabilities {
+	return Capabilities{
+		ProtocolVersions:  []string{ProtocolVersion},
+		Commands:          append([]string(nil), legacyCommands...),
+		BuildInputFormats: []string{BuildInputFormatYaml},
+		Legacy:            true,
+	}
+}
+
+// applyDefaults sets the capabilities that the mixin did not report.
+func (c *Capabilities) applyDefaults() {
+	if len(c.ProtocolVersions) == 0 {
+		c.ProtocolVersions = []string{ProtocolVersion}
+	}
+	if len(c.Commands) == 0 {
+		c.Commands = append([]string(nil), legacyCommands...)
+	}
+	if len(c.BuildInputFormats) == 0 {
+		c.BuildInputFormats = []string{BuildInputFormatYaml}
+	}
+}
+
+// Supports determines if the mixin supports the command. Commands that are
+// not core mixin commands are custom actions, which are run with invoke.
+func (c Capabilities) Supports(command string) bool {
+	name := strings.Fields(command)
+	if len(name) == 0 {
+		return false
+	}
+
+	cmd := name[0]
+	if !IsCoreMixinCommand(cmd) {
+		cmd = "invoke"
+	}
+	for _, supported := range c.Commands {
+		if supported == cmd {
+			return true
+		}
+	}
+	return false
+}
+
+// NegotiateProtocol returns the most recent protocol version that both Porter
+// and the mixin support.
+func (c Capabilities) NegotiateProtocol() (string, error) {
+	for i := len(SupportedProtocolVersions) - 1; i >= 0; i-- {
+		for _, v := range c.ProtocolVersions {
+			if v == SupportedProtocolVersions[i] {
+				return v, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("the mixin supports the protocol versions %s, but this version of Porter only supports %s: upgrade Porter or install a compatible version of the mixin",
+		strings.Join(c.ProtocolVersions, ", "), strings.Join(SupportedProtocolVersions, ", "))
+}
+
+// GetBuildInputFormat returns the format of the input sent to the mixin,
+// preferring yaml.
+func (c Capabilities) GetBuildInputFormat() (string, error) {
+	for _, format := range []string{BuildInputFormatYaml, BuildInputFormatJson} {
+		for _, supported := range c.BuildInputFormats {
+			if supported == format {
+				return format, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("the mixin does not accept any of the build input formats supported by Porter: %s", strings.Join(c.BuildInputFormats, ", "))
+}
+
+// CapabilitiesProvider is implemented by a package manager that can report the
+// capabilities of its mixins.
+type CapabilitiesProvider interface {
+	// GetCapabilities returns the capabilities reported by the mixin.
+	GetCapabilities(ctx context.Context, name string) (Capabilities, error)
+}
+
+// runCommand runs a command against a mixin, see pkgmgmt.PackageManager.Run.
+type runCommand func(ctx context.Context, pkgContext *portercontext.Context, name string, commandOpts pkgmgmt.CommandOptions) error
+
+// capabilitiesCache holds the capabilities reported by each mixin, so that
+// each mixin is only queried once.
+type capabilitiesCache struct {
+	mu           sync.Mutex
+	capabilities map[string]Capabilities
+
+	// queries deduplicates concurrent queries of the same mixin, without
+	// blocking the queries of other mixins.
+	queries singleflight.Group
+}
+
+// get returns the cached capabilities of the mixin, querying the mixin with
+// the run function when they are not cached.
+func (cc *capabilitiesCache) get(ctx context.Context, cxt *portercontext.Context, name string, run runCommand) (Capabilities, error) {
+	if caps, ok := cc.lookup(name); ok {
+		return caps, nil
+	}
+
+	caps, err, _ := cc.queries.Do(name, func() (interface{}, error) {
+		// The capabilities may have been cached after the lookup above
+		if caps, ok := cc.lookup(name); ok {
+			return caps, nil
+		}
+
+		caps, err := queryCapabilities(ctx, cxt, name, run)
+		if err != nil {
+			return nil, err
+		}
+
+		cc.mu.Lock()
+		defer cc.mu.Unlock()
+		if cc.capabilities == nil {
+			cc.capabilities = make(map[string]Capabilities)
+		}
+		cc.capabilities[name] = caps
+		return caps, nil
+	})
+	if err != nil {
+		return Capabilities{}, err
+	}
+	return caps.(Capabilities), nil
+}
+
+// lookup returns the cached capabilities of the mixin.
+func (cc *capabilitiesCache) lookup(name string) (Capabilities, bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	caps, ok := cc.capabilities[name]
+	return caps, ok
+}
+
+// GetCapabilities queries the mixin for its capabilities. Mixins that do not
+// support the capabilities command are assumed to have the LegacyCapabilities.
+// The capabilities are cached for the lifetime of the package manager.
+func (c *PackageManager) GetCapabilities(ctx context.Context, name string) (Capabilities, error) {
+	return c.capabilities.get(ctx, c.Context, name, c.Run)
+}
+
+// queryCapabilities runs the mixin's capabilities command.
+func queryCapabilities(ctx context.Context, cxt *portercontext.Context, name string, run runCommand) (Capabilities, error) {
+	ctx, span := tracing.StartSpan(ctx, attribute.String("mixin", name))
+	defer span.EndSpan()
+
+	// Copy the existing context and tweak to pipe the output differently
+	output := &bytes.Buffer{}
+	mixinContext := *cxt
+	mixinContext.Out = output
+	mixinContext.Err = io.Discard
+
+	cmd := pkgmgmt.CommandOptions{Command: "capabilities --output json"}
+	if err := run(ctx, &mixinContext, name, cmd); err != nil {
+		// Mixins built before the capabilities command was added do not
+		// support it. Do not assume anything about mixins that failed for
+		// another reason, for example because they are not trusted.
+		if !strings.Contains(err.Error(), "unknown command") {
+			return Capabilities{}, span.Error(fmt.Errorf("could not query the capabilities of the %s mixin: %w", name, err))
+		}
+		span.Debugf("the %s mixin did not report its capabilities, assuming it supports mixin protocol version %s: %s", name, ProtocolVersion, err)
+		return LegacyCapabilities(), nil
+	}
+
+	var caps Capabilities
+	if err := json.Unmarshal(output.Bytes(), &caps); err != nil {
+		return Capabilities{}, span.Error(fmt.Errorf("could not parse the capabilities reported by the %s mixin: %w", name, err))
+	}
+	caps.applyDefaults()
+	return caps, nil
+}
@@ -0,0 +1,159 @@
+package mixin
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities_Supports(t *testing.T) {
+	caps := Capabilities{Commands: []string{"build", "install", "invoke"}}
+
+	assert.True(t, caps.Supports("build"))
+	assert.False(t, caps.Supports("version --output json"), "version is not listed")
+	assert.False(t, caps.Supports("lint"))
+	assert.True(t, caps.Supports("status"), "custom actions are supported by invoke")
+	assert.False(t, caps.Supports(""))
+}
+
+func TestCapabilities_NegotiateProtocol(t *testing.T) {
+	caps := Capabilities{ProtocolVersions: []string{"1", "2"}}
+	v, err := caps.NegotiateProtocol()
+	require.NoError(t, err)
+	assert.Equal(t, ProtocolVersion, v)
+
+	caps = Capabilities{ProtocolVersions: []string{"2"}}
+	_, err = caps.NegotiateProtocol()
+	require.ErrorContains(t, err, "upgrade Porter or install a compatible version of the mixin")
+}
+
+func TestCapabilities_GetBuildInputFormat(t *testing.T) {
+	format, err := Capabilities{BuildInputFormats: []string{"json", "yaml"}}.GetBuildInputFormat()
+	require.NoError(t, err)
+	assert.Equal(t, BuildInputFormatYaml, format, "yaml should be preferred")
+
+	format, err = Capabilities{BuildInputFormats: []string{"json"}}.GetBuildInputFormat()
+	require.NoError(t, err)
+	assert.Equal(t, BuildInputFormatJson, format)
+
+	_, err = Capabilities{BuildInputFormats: []string{"toml"}}.GetBuildInputFormat()
+	require.ErrorContains(t, err, "does not accept any of the build input formats")
+}
+
+func TestPackageManager_GetCapabilities(t *testing.T) {
+	const mixinPath = "/home/myuser/.porter/mixins/exec/exec"
+
+	t.Run("reported", func(t *testing.T) {
+		c := config.NewTestConfig(t)
+		require.NoError(t, c.FileSystem.WriteFile(mixinPath, []byte(mixinPath), 0700))
+		c.Setenv(test.ExpectedCommandEnv, mixinPath+" capabilities --output json")
+		c.Setenv(test.ExpectedCommandOutputEnv, `{"protocolVersions": ["1"], "commands": ["build", "install"]}`)
+		p := NewPackageManager(c.Config)
+
+		caps, err := p.GetCapabilities(context.Background(), "exec")
+		require.NoError(t, err)
+		assert.False(t, caps.Legacy)
+		assert.Equal(t, []string{"1"}, caps.ProtocolVersions)
+		assert.Equal(t, []string{"build", "install"}, caps.Commands)
+		assert.Equal(t, []string{BuildInputFormatYaml}, caps.BuildInputFormats, "the default build input format should be used")
+
+		// The capabilities are cached, so the mixin is not run again
+		c.Setenv(test.ExpectedCommandExitCodeEnv, "1")
+		cached, err := p.GetCapabilities(context.Background(), "exec")
+		require.NoError(t, err)
+		assert.Equal(t, caps, cached)
+	})
+
+	t.Run("older mixin", func(t *testing.T) {
+		c := config.NewTestConfig(t)
+		require.NoError(t, c.FileSystem.WriteFile(mixinPath, []byte(mixinPath), 0700))
+		c.Setenv(test.ExpectedCommandErrorEnv, `Error: unknown command "capabilities" for "exec"`)
+		c.Setenv(test.ExpectedCommandExitCodeEnv, "1")
+		p := NewPackageManager(c.Config)
+
+		caps, err := p.GetCapabilities(context.Background(), "exec")
+		require.NoError(t, err)
+		assert.Equal(t, LegacyCapabilities(), caps)
+	})
+
+	t.Run("broken mixin", func(t *testing.T) {
+		c := config.NewTestConfig(t)
+		require.NoError(t, c.FileSystem.WriteFile(mixinPath, []byte(mixinPath), 0700))
+		c.Setenv(test.ExpectedCommandErrorEnv, "panic: oops")
+		c.Setenv(test.ExpectedCommandExitCodeEnv, "2")
+		p := NewPackageManager(c.Config)
+
+		_, err := p.GetCapabilities(context.Background(), "exec")
+		require.ErrorContains(t, err, "could not query the capabilities of the exec mixin")
+		assert.ErrorContains(t, err, "panic: oops")
+	})
+
+	t.Run("invalid capabilities", func(t *testing.T) {
+		c := config.NewTestConfig(t)
+		require.NoError(t, c.FileSystem.WriteFile(mixinPath, []byte(mixinPath), 0700))
+		c.Setenv(test.ExpectedCommandOutputEnv, "oops")
+		p := NewPackageManager(c.Config)
+
+		_, err := p.GetCapabilities(context.Background(), "exec")
+		require.ErrorContains(t, err, "could not parse the capabilities reported by the exec mixin")
+	})
+}
+
+func TestCapabilitiesCache_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	cxt := portercontext.NewTestContext(t)
+
+	var queries int32
+	slowStarted := make(chan struct{})
+	releaseSlow := make(chan struct{})
+	run := func(ctx context.Context, pkgContext *portercontext.Context, name string, commandOpts pkgmgmt.CommandOptions) error {
+		atomic.AddInt32(&queries, 1)
+		if name == "slow" {
+			close(slowStarted)
+			<-releaseSlow
+		}
+		fmt.Fprintf(pkgContext.Out, `{"commands": [%q]}`, name)
+		return nil
+	}
+
+	var cc capabilitiesCache
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			caps, err := cc.get(ctx, cxt.Context, "slow", run)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"slow"}, caps.Commands)
+		}()
+	}
+	<-slowStarted
+
+	// Querying another mixin is not blocked by the slow mixin
+	fast := make(chan Capabilities)
+	go func() {
+		caps, err := cc.get(ctx, cxt.Context, "fast", run)
+		assert.NoError(t, err)
+		fast <- caps
+	}()
+	select {
+	case caps := <-fast:
+		assert.Equal(t, []string{"fast"}, caps.Commands)
+	case <-time.After(10 * time.Second):
+		t.Fatal("querying the fast mixin was blocked by the slow mixin")
+	}
+
+	close(releaseSlow)
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&queries), "expected each mixin to be queried once")
+}
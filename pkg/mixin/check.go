@@ -0,0 +1,216 @@
+package mixin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"get.porter.sh/porter/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// CheckExecutable checks that the mixin client binary is installed and executable.
+	CheckExecutable = "executable"
+
+	// CheckRuntime checks that the mixin runtime binary, which is copied into
+	// the invocation image, is installed and executable.
+	CheckRuntime = "runtime"
+
+	// CheckVersion checks that the mixin reports its version.
+	CheckVersion = "version"
+
+	// CheckSchema checks that the mixin reports a valid manifest schema.
+	CheckSchema = "schema"
+
+	// CheckCapabilities checks that the mixin supports a version of the mixin
+	// protocol that Porter supports.
+	CheckCapabilities = "capabilities"
+)
+
+const (
+	// CheckStatusOK indicates that the check passed.
+	CheckStatusOK = "ok"
+
+	// CheckStatusWarning indicates a problem that does not stop the mixin from
+	// being used, for example a mixin that does not report a schema.
+	CheckStatusWarning = "warning"
+
+	// CheckStatusFailed indicates a problem that stops the mixin from being used.
+	CheckStatusFailed = "failed"
+)
+
+// schemaStepDefinitions are the definitions that the schema of a mixin must
+// include, because the Porter manifest schema refers to them.
+var schemaStepDefinitions = []string{"installStep", "upgradeStep", "uninstallStep"}
+
+// CheckResult is the outcome of a single check of a mixin.
+type CheckResult struct {
+	// Name of the check, for example CheckExecutable.
+	Name string `json:"name" yaml:"name"`
+
+	// Status of the check, for example CheckStatusOK.
+	Status string `json:"status" yaml:"status"`
+
+	// Message describing the outcome of the check.
+	Message string `json:"message" yaml:"message"`
+}
+
+// MixinHealth is the outcome of checking an installed mixin.
+type MixinHealth struct {
+	// Name of the mixin.
+	Name string `json:"name" yaml:"name"`
+
+	// Version reported by the mixin.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+
+	// Checks that were run against the mixin.
+	Checks []CheckResult `json:"checks" yaml:"checks"`
+}
+
+// Healthy determines if none of the checks failed. Warnings do not make the
+// mixin unhealthy.
+func (h MixinHealth) Healthy() bool {
+	for _, check := range h.Checks {
+		if check.Status == CheckStatusFailed {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *MixinHealth) pass(name string, format string, args ...interface{}) {
+	h.Checks = append(h.Checks, CheckResult{Name: name, Status: CheckStatusOK, Message: fmt.Sprintf(format, args...)})
+}
+
+func (h *MixinHealth) warn(name string, format string, args ...interface{}) {
+	h.Checks = append(h.Checks, CheckResult{Name: name, Status: CheckStatusWarning, Message: fmt.Sprintf(format, args...)})
+}
+
+func (h *MixinHealth) fail(name string, format string, args ...interface{}) {
+	h.Checks = append(h.Checks, CheckResult{Name: name, Status: CheckStatusFailed, Message: fmt.Sprintf(format, args...)})
+}
+
+// CheckMixin diagnoses an installed mixin: the client and runtime binaries
+// must be executable, the mixin must report its version and manifest schema,
+// and support a version of the mixin protocol that Porter supports. The mixin is only run when its client binary is executable, and
+// the schema cache is not used. An error is returned when the mixin is not
+// installed, problems with the mixin are reported in the checks.
+func (c *PackageManager) CheckMixin(ctx context.Context, name string) (MixinHealth, error) {
+	ctx, span := tracing.StartSpan(ctx, attribute.String("mixin", name))
+	defer span.EndSpan()
+
+	mixinDir, err := c.GetPackageDir(name)
+	if err != nil {
+		return MixinHealth{}, span.Error(err)
+	}
+
+	health := MixinHealth{Name: name}
+
+	clientPath := c.BuildClientPath(mixinDir, name)
+	clientOK := c.checkExecutable(&health, CheckExecutable, clientPath)
+
+	runtimePath := filepath.Join(mixinDir, "runtimes", name+"-runtime")
+	c.checkExecutable(&health, CheckRuntime, runtimePath)
+
+	if !clientOK {
+		health.fail(CheckVersion, "skipped because the mixin binary is not executable")
+		health.fail(CheckSchema, "skipped because the mixin binary is not executable")
+		health.fail(CheckCapabilities, "skipped because the mixin binary is not executable")
+		return health, nil
+	}
+
+	meta, err := c.GetMetadata(ctx, name)
+	if err != nil {
+		health.fail(CheckVersion, "the mixin did not report its version: %s", err)
+	} else if m, ok := meta.(*Metadata); !ok || m.VersionInfo.Version == "" {
+		health.fail(CheckVersion, "the mixin reported its version without a version number")
+	} else {
+		health.Version = m.VersionInfo.Version
+		health.pass(CheckVersion, "%s", m.VersionInfo.Version)
+	}
+
+	c.checkCapabilities(ctx, &health, name)
+
+	schema, err := c.querySchema(ctx, mixinDir, name)
+	if err != nil {
+		health.warn(CheckSchema, "the mixin did not report a schema, so its steps are not validated: %s", strings.TrimSpace(err.Error()))
+	} else {
+		checkSchema(&health, schema)
+	}
+
+	return health, nil
+}
+
+// checkCapabilities checks that the mixin supports a version of the mixin
+// protocol that Porter supports. Mixins that do not report their capabilities
+// are assumed to support the first version of the protocol.
+func (c *PackageManager) checkCapabilities(ctx context.Context, health *MixinHealth, name string) {
+	caps, err := c.GetCapabilities(ctx, name)
+	if err != nil {
+		health.fail(CheckCapabilities, "the mixin did not report valid capabilities: %s", err)
+		return
+	}
+
+	if caps.Legacy {
+		health.warn(CheckCapabilities, "the mixin does not report its capabilities, so Porter assumes it supports mixin protocol version %s", ProtocolVersion)
+		return
+	}
+
+	protocol, err := caps.NegotiateProtocol()
+	if err != nil {
+		health.fail(CheckCapabilities, "%s", err)
+		return
+	}
+	health.pass(CheckCapabilities, "mixin protocol version %s", protocol)
+}
+
+// checkExecutable checks that the binary exists, and on Linux and macOS that
+// it has the executable permission. Returns true when the check passed.
+func (c *PackageManager) checkExecutable(health *MixinHealth, check string, path string) bool {
+	info, err := c.Config.FileSystem.Stat(path)
+	if err != nil {
+		health.fail(check, "%s is not installed: %s", path, err)
+		return false
+	}
+
+	if info.IsDir() {
+		health.fail(check, "%s is a directory", path)
+		return false
+	}
+
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0111 == 0 {
+		health.fail(check, "%s is not executable, run porter storage fix-permissions or reinstall the mixin", path)
+		return false
+	}
+
+	health.pass(check, "%s", path)
+	return true
+}
+
+// checkSchema checks that the schema is a json document that defines the
+// steps referenced by the Porter manifest schema.
+func checkSchema(health *MixinHealth, schema string) {
+	var schemaMap map[string]interface{}
+	if err := json.Unmarshal([]byte(schema), &schemaMap); err != nil {
+		health.fail(CheckSchema, "the mixin reported a schema that is not a json document: %s", err)
+		return
+	}
+
+	definitions, _ := schemaMap["definitions"].(map[string]interface{})
+	var missing []string
+	for _, step := range schemaStepDefinitions {
+		if _, ok := definitions[step]; !ok {
+			missing = append(missing, step)
+		}
+	}
+	if len(missing) > 0 {
+		health.fail(CheckSchema, "the schema reported by the mixin is missing the definitions: %s", strings.Join(missing, ", "))
+		return
+	}
+
+	health.pass(CheckSchema, "the mixin reported a valid schema")
+}
@@ -0,0 +1,120 @@
+package mixin
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageManager_CheckMixin(t *testing.T) {
+	const (
+		mixinPath   = "/home/myuser/.porter/mixins/exec/exec"
+		runtimePath = "/home/myuser/.porter/mixins/exec/runtimes/exec-runtime"
+
+		// The mixin is mocked to print the same output for every command, so it is its version, capabilities and schema
+		validOutput = `{"name": "exec", "version": "v1.0.0", "definitions": {"installStep": {}, "upgradeStep": {}, "uninstallStep": {}}}`
+	)
+
+	testcases := []struct {
+		name           string
+		clientMode     os.FileMode
+		missingRuntime bool
+		output         string
+		exitCode       string
+		wantVersion    string
+		wantHealthy    bool
+		wantStatuses   map[string]string
+		wantMessage    string
+	}{
+		{
+			name: "healthy", output: validOutput, wantVersion: "v1.0.0", wantHealthy: true,
+			wantStatuses: map[string]string{CheckExecutable: CheckStatusOK, CheckRuntime: CheckStatusOK, CheckVersion: CheckStatusOK, CheckCapabilities: CheckStatusOK, CheckSchema: CheckStatusOK},
+		},
+		{
+			name: "missing runtime", missingRuntime: true, output: validOutput, wantVersion: "v1.0.0",
+			wantStatuses: map[string]string{CheckExecutable: CheckStatusOK, CheckRuntime: CheckStatusFailed, CheckVersion: CheckStatusOK, CheckCapabilities: CheckStatusOK, CheckSchema: CheckStatusOK},
+			wantMessage:  "exec-runtime is not installed",
+		},
+		{
+			name: "not executable", clientMode: pkg.FileModeWritable, output: validOutput,
+			wantStatuses: map[string]string{CheckExecutable: CheckStatusFailed, CheckRuntime: CheckStatusOK, CheckVersion: CheckStatusFailed, CheckCapabilities: CheckStatusFailed, CheckSchema: CheckStatusFailed},
+			wantMessage:  "exec is not executable",
+		},
+		{
+			name: "invalid schema", output: `{"name": "exec", "version": "v1.0.0", "definitions": {"installStep": {}}}`, wantVersion: "v1.0.0",
+			wantStatuses: map[string]string{CheckExecutable: CheckStatusOK, CheckRuntime: CheckStatusOK, CheckVersion: CheckStatusOK, CheckCapabilities: CheckStatusOK, CheckSchema: CheckStatusFailed},
+			wantMessage:  "missing the definitions: upgradeStep, uninstallStep",
+		},
+		{
+			name: "broken mixin", output: "panic: oops", exitCode: "2",
+			wantStatuses: map[string]string{CheckExecutable: CheckStatusOK, CheckRuntime: CheckStatusOK, CheckVersion: CheckStatusFailed, CheckCapabilities: CheckStatusFailed, CheckSchema: CheckStatusWarning},
+			wantMessage:  "the mixin did not report its version",
+		},
+		{
+			name: "unsupported protocol", output: `{"name": "exec", "version": "v1.0.0", "protocolVersions": ["99"], "definitions": {"installStep": {}, "upgradeStep": {}, "uninstallStep": {}}}`, wantVersion: "v1.0.0",
+			wantStatuses: map[string]string{CheckExecutable: CheckStatusOK, CheckRuntime: CheckStatusOK, CheckVersion: CheckStatusOK, CheckCapabilities: CheckStatusFailed, CheckSchema: CheckStatusOK},
+			wantMessage:  "the mixin supports the protocol versions 99",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.clientMode != 0 && runtime.GOOS == "windows" {
+				t.Skip("the executable permission is not checked on Windows")
+			}
+
+			ctx := context.Background()
+			c := config.NewTestConfig(t)
+			clientMode := pkg.FileModeExecutable
+			if tc.clientMode != 0 {
+				clientMode = tc.clientMode
+			}
+			// The in-memory filesystem does not apply the permissions when a file is created
+			writeFile := func(path string, mode os.FileMode) {
+				require.NoError(t, c.FileSystem.WriteFile(path, []byte(path), mode))
+				require.NoError(t, c.FileSystem.Chmod(path, mode))
+			}
+			writeFile(mixinPath, clientMode)
+			if tc.missingRuntime {
+				require.NoError(t, c.FileSystem.RemoveAll(runtimePath))
+			} else {
+				writeFile(runtimePath, pkg.FileModeExecutable)
+			}
+			c.Setenv(test.ExpectedCommandOutputEnv, tc.output)
+			if tc.exitCode != "" {
+				c.Setenv(test.ExpectedCommandExitCodeEnv, tc.exitCode)
+			}
+			p := NewPackageManager(c.Config)
+
+			health, err := p.CheckMixin(ctx, "exec")
+			require.NoError(t, err)
+			assert.Equal(t, "exec", health.Name)
+			assert.Equal(t, tc.wantVersion, health.Version)
+
+			gotStatuses := make(map[string]string, len(health.Checks))
+			var messages []string
+			for _, check := range health.Checks {
+				gotStatuses[check.Name] = check.Status
+				messages = append(messages, check.Message)
+			}
+			assert.Equal(t, tc.wantStatuses, gotStatuses)
+			assert.Contains(t, strings.Join(messages, "\n"), tc.wantMessage, "the checks should explain the problem")
+			assert.Equal(t, tc.wantHealthy, health.Healthy())
+		})
+	}
+
+	t.Run("not installed", func(t *testing.T) {
+		c := config.NewTestConfig(t)
+		p := NewPackageManager(c.Config)
+		_, err := p.CheckMixin(context.Background(), "helm3")
+		require.ErrorContains(t, err, "mixins helm3 not installed")
+	})
+}
@@ -0,0 +1,98 @@
+package mixin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/sync/errgroup"
+)
+
+// MaxConcurrentQueries is the maximum number of mixins that are queried at the
+// same time when querying multiple mixins.
+const MaxConcurrentQueries = 8
+
+// GetAllMetadata queries the mixins for their metadata concurrently. The
+// metadata is returned in the same order as the mixin names. When a mixin
+// cannot be queried, its metadata is left empty and its error is included in
+// the returned error, which combines the errors from every mixin.
+func GetAllMetadata(ctx context.Context, mixins MixinProvider, names []string) ([]Metadata, error) {
+	results := make([]Metadata, len(names))
+	err := queryConcurrently(names, func(i int, name string) error {
+		m, err := mixins.GetMetadata(ctx, name)
+		if err != nil {
+			return fmt.Errorf("could not get the metadata for the %s mixin: %w", name, err)
+		}
+
+		meta, ok := m.(*Metadata)
+		if !ok {
+			return fmt.Errorf("unexpected metadata type %T returned by the %s mixin", m, name)
+		}
+		results[i] = *meta
+		return nil
+	})
+	return results, err
+}
+
+// GetAllSchemas queries the mixins for their schema concurrently. The schemas
+// are returned in the same order as the mixin names. When a mixin cannot be
+// queried, its schema is left empty and its error is included in the returned
+// error, which combines the errors from every mixin.
+func GetAllSchemas(ctx context.Context, mixins MixinProvider, names []string, opts SchemaOptions) ([]string, error) {
+	results := make([]string, len(names))
+	err := queryConcurrently(names, func(i int, name string) error {
+		schema, err := mixins.GetSchema(ctx, name, opts)
+		if err != nil {
+			return fmt.Errorf("could not get the schema for the %s mixin: %w", name, err)
+		}
+		results[i] = schema
+		return nil
+	})
+	return results, err
+}
+
+// CheckAllMixins checks the mixins concurrently. The results are returned in
+// the same order as the mixin names. When a mixin cannot be checked, for
+// example because it is not installed, its result is left empty and its
+// error is included in the returned error.
+func CheckAllMixins(ctx context.Context, mixins MixinProvider, names []string) ([]MixinHealth, error) {
+	results := make([]MixinHealth, len(names))
+	err := queryConcurrently(names, func(i int, name string) error {
+		health, err := mixins.CheckMixin(ctx, name)
+		if err != nil {
+			return fmt.Errorf("could not check the %s mixin: %w", name, err)
+		}
+		results[i] = health
+		return nil
+	})
+	return results, err
+}
+
+// queryConcurrently calls query for each mixin, limiting the number of mixins
+// that are queried at the same time. A failed query does not stop the other
+// mixins from being queried, and the errors from every query are combined.
+func queryConcurrently(names []string, query func(i int, name string) error) error {
+	errs := make([]error, len(names))
+
+	g := errgroup.Group{}
+	g.SetLimit(MaxConcurrentQueries)
+	for i, name := range names {
+		// Force variables to be in the go routine's closure below
+		i := i
+		name := name
+		g.Go(func() error {
+			errs[i] = query(i, name)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	// Report the errors in the same order as the mixins
+	var result *multierror.Error
+	for _, err := range errs {
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	return result.ErrorOrNil()
+}
@@ -0,0 +1,71 @@
+package mixin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAllMetadata(t *testing.T) {
+	ctx := context.Background()
+	mixins := NewTestMixinProvider()
+
+	results, err := GetAllMetadata(ctx, mixins, []string{"testmixin", "missing1", "exec", "missing2"})
+	require.Len(t, results, 4, "a result should be returned for every mixin")
+	assert.Equal(t, "testmixin", results[0].Name)
+	assert.Empty(t, results[1].Name, "the metadata of a mixin that could not be queried should be empty")
+	assert.Equal(t, "exec", results[2].Name)
+	assert.Empty(t, results[3].Name, "the metadata of a mixin that could not be queried should be empty")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "could not get the metadata for the missing1 mixin")
+	assert.Contains(t, err.Error(), "could not get the metadata for the missing2 mixin")
+}
+
+func TestGetAllSchemas(t *testing.T) {
+	ctx := context.Background()
+	mixins := NewTestMixinProvider()
+
+	results, err := GetAllSchemas(ctx, mixins, []string{"testmixin", "exec"}, SchemaOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Contains(t, results[0], "testmixin", "the schemas should be returned in the same order as the mixins")
+	assert.Contains(t, results[1], "exec", "the schemas should be returned in the same order as the mixins")
+}
+
+func TestQueryConcurrently(t *testing.T) {
+	names := make([]string, 3*MaxConcurrentQueries)
+	for i := range names {
+		names[i] = fmt.Sprintf("mixin%d", i)
+	}
+
+	var running, maxRunning int32
+	err := queryConcurrently(names, func(i int, name string) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			highest := atomic.LoadInt32(&maxRunning)
+			if n <= highest || atomic.CompareAndSwapInt32(&maxRunning, highest, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		if i%MaxConcurrentQueries == 0 {
+			return errors.New(name + " failed")
+		}
+		return nil
+	})
+
+	assert.LessOrEqual(t, maxRunning, int32(MaxConcurrentQueries), "too many mixins were queried at the same time")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "3 errors occurred", "every failed query should be reported")
+	assert.Contains(t, err.Error(), "mixin0 failed")
+	assert.Contains(t, err.Error(), fmt.Sprintf("mixin%d failed", 2*MaxConcurrentQueries))
+}
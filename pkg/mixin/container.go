@@ -0,0 +1,210 @@
+package mixin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/metrics"
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap/zapcore"
+)
+
+var _ MixinProvider = &ContainerProvider{}
+
+// ContainerOptions are the options for running a mixin command in a container.
+type ContainerOptions struct {
+	// Image that the command is run in.
+	Image string
+
+	// Pull the image even when it is already in the local image store.
+	Pull bool
+
+	// Command and arguments that are run in the container.
+	Command []string
+
+	// Stdin is piped to the command, when set.
+	Stdin io.Reader
+
+	// Stdout receives the standard output of the command.
+	Stdout io.Writer
+
+	// Stderr receives the standard error of the command.
+	Stderr io.Writer
+}
+
+// ContainerRunner runs a command in a container and waits for it to exit. An
+// error is returned when the command exits with a non-zero exit code.
+type ContainerRunner interface {
+	RunContainer(ctx context.Context, opts ContainerOptions) error
+}
+
+// ContainerProvider runs the client commands of mixins, such as schema, build
+// and lint, inside a container for the mixins that have an image configured
+// in the mixin-container config file setting. The remaining mixins, and the
+// mixin runtime binaries that are copied into the bundle, are managed by the
+// PackageManager, so mixins must still be installed in PORTER_HOME.
+type ContainerProvider struct {
+	*PackageManager
+
+	// runner runs the mixin commands in a container.
+	runner ContainerRunner
+
+	// capabilities reported by each mixin run in a container.
+	capabilities capabilitiesCache
+}
+
+// NewContainerProvider creates a mixin provider that runs mixins in the
+// containers configured in the mixin-container config file setting.
+func NewContainerProvider(c *config.Config) *ContainerProvider {
+	return &ContainerProvider{
+		PackageManager: NewPackageManager(c),
+		runner:         dockerRunner{},
+	}
+}
+
+// getImage returns the image that the mixin is run in, and false when the
+// mixin is run from PORTER_HOME.
+func (p *ContainerProvider) getImage(name string) (string, bool) {
+	return p.Data.MixinContainer.GetImage(name)
+}
+
+// Run the command in the mixin's container. Commands against the mixin
+// runtime, and mixins without an image, are run from PORTER_HOME.
+func (p *ContainerProvider) Run(ctx context.Context, pkgContext *portercontext.Context, name string, commandOpts pkgmgmt.CommandOptions) error {
+	image, ok := p.getImage(name)
+	if !ok || commandOpts.Runtime {
+		return p.PackageManager.Run(ctx, pkgContext, name, commandOpts)
+	}
+
+	err := p.runInContainer(ctx, pkgContext, name, image, commandOpts)
+	metrics.CountMixinInvocation(name, commandOpts.Command, err)
+	return err
+}
+
+func (p *ContainerProvider) runInContainer(ctx context.Context, pkgContext *portercontext.Context, name string, image string, commandOpts pkgmgmt.CommandOptions) error {
+	ctx, span := tracing.StartSpan(ctx,
+		attribute.String("mixin", name),
+		attribute.String("image", image),
+		attribute.String("stdin", commandOpts.Input),
+	)
+	defer span.EndSpan()
+
+	if commandOpts.File != "" {
+		return span.Error(fmt.Errorf("cannot run the %s command of the %s mixin in a container: files on the host are not available in the container", commandOpts.Command, name))
+	}
+
+	pull, err := p.Data.MixinContainer.GetPull()
+	if err != nil {
+		return span.Error(err)
+	}
+
+	// Apply the same tweaks to the command, such as calling custom commands with invoke, as when the mixin is run from PORTER_HOME
+	cmdArgs := strings.Split(commandOpts.Command, " ")
+	cmd := &exec.Cmd{Args: append([]string{name}, cmdArgs...)}
+	if p.PreRun != nil {
+		p.PreRun(cmdArgs[0], cmd)
+	}
+
+	cmdStderr := &bytes.Buffer{}
+	opts := ContainerOptions{
+		Image:   image,
+		Pull:    pull == config.MixinContainerPullAlways,
+		Command: cmd.Args,
+		Stdout:  pkgContext.Out,
+		Stderr:  io.MultiWriter(cmdStderr, pkgContext.Err),
+	}
+	if commandOpts.Input != "" {
+		opts.Stdin = strings.NewReader(commandOpts.Input)
+	}
+
+	prettyCmd := strings.Join(cmd.Args, " ")
+	span.SetAttributes(attribute.String("command", prettyCmd))
+	if err = p.runner.RunContainer(ctx, opts); err != nil {
+		// Include stderr in the error, otherwise it just includes the exit code
+		err = fmt.Errorf("mixin command failed %s in %s\n%s: %w", prettyCmd, image, cmdStderr, err)
+		// Do not flag this as an error in the logs because we often call mixins to see if they support a command
+		span.Debugf(err.Error())
+		return err
+	}
+	return nil
+}
+
+// runForOutput runs the command in the mixin's container and returns its output.
+func (p *ContainerProvider) runForOutput(ctx context.Context, name string, command string) ([]byte, error) {
+	log := tracing.LoggerFromContext(ctx)
+
+	// Copy the existing context and tweak to pipe the output differently
+	output := &bytes.Buffer{}
+	mixinContext := *p.Context
+	mixinContext.Out = output
+	if !log.ShouldLog(zapcore.DebugLevel) {
+		mixinContext.Err = io.Discard
+	}
+
+	if err := p.Run(ctx, &mixinContext, name, pkgmgmt.CommandOptions{Command: command}); err != nil {
+		return nil, err
+	}
+	return output.Bytes(), nil
+}
+
+// GetMetadata queries the mixin for its metadata, in its container when an
+// image is configured for the mixin.
+func (p *ContainerProvider) GetMetadata(ctx context.Context, name string) (pkgmgmt.PackageMetadata, error) {
+	if _, ok := p.getImage(name); !ok {
+		return p.PackageManager.GetMetadata(ctx, name)
+	}
+
+	output, err := p.runForOutput(ctx, name, "version --output json")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Metadata{}
+	if err = json.Unmarshal(output, result); err != nil {
+		return nil, fmt.Errorf("could not parse the version reported by the %s mixin: %w", name, err)
+	}
+	return result, nil
+}
+
+// GetSchema returns the manifest schema for the mixin, queried in its
+// container when an image is configured for the mixin. Schemas queried in a
+// container are not cached, because the image may change.
+func (p *ContainerProvider) GetSchema(ctx context.Context, name string, opts SchemaOptions) (string, error) {
+	if _, ok := p.getImage(name); !ok {
+		return p.PackageManager.GetSchema(ctx, name, opts)
+	}
+
+	output, err := p.runForOutput(ctx, name, "schema")
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// GetCapabilities queries the mixin for its capabilities, in its container
+// when an image is configured for the mixin.
+func (p *ContainerProvider) GetCapabilities(ctx context.Context, name string) (Capabilities, error) {
+	if _, ok := p.getImage(name); !ok {
+		return p.PackageManager.GetCapabilities(ctx, name)
+	}
+	return p.capabilities.get(ctx, p.Context, name, p.Run)
+}
+
+// errContainerExit is returned when a command run in a container exits with
+// a non-zero exit code.
+type errContainerExit struct {
+	code int64
+}
+
+func (e errContainerExit) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
@@ -0,0 +1,154 @@
+package mixin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"get.porter.sh/porter/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testContainerRunner records the containers that were run, and prints the
+// configured output.
+type testContainerRunner struct {
+	runs   []ContainerOptions
+	stdin  []string
+	output string
+	err    error
+}
+
+func (r *testContainerRunner) RunContainer(ctx context.Context, opts ContainerOptions) error {
+	r.runs = append(r.runs, opts)
+	if opts.Stdin != nil {
+		b, _ := io.ReadAll(opts.Stdin)
+		r.stdin = append(r.stdin, string(b))
+	}
+	fmt.Fprint(opts.Stdout, r.output)
+	return r.err
+}
+
+func newTestContainerProvider(t *testing.T) (*config.TestConfig, *ContainerProvider, *testContainerRunner) {
+	c := config.NewTestConfig(t)
+	c.Data.MixinContainer = config.MixinContainerConfig{
+		Image:  "example.com/mixins:v1",
+		Images: map[string]string{"helm3": "example.com/helm3-mixin:v2"},
+	}
+	runner := &testContainerRunner{}
+	p := NewContainerProvider(c.Config)
+	p.runner = runner
+	return c, p, runner
+}
+
+func TestContainerProvider_Run(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("build", func(t *testing.T) {
+		c, p, runner := newTestContainerProvider(t)
+		runner.output = "RUN apt-get install helm"
+
+		err := p.Run(ctx, c.Context, "helm3", pkgmgmt.CommandOptions{Command: "build", Input: "actions: {}"})
+		require.NoError(t, err)
+
+		require.Len(t, runner.runs, 1)
+		assert.Equal(t, "example.com/helm3-mixin:v2", runner.runs[0].Image, "the image configured for the mixin should be used")
+		assert.Equal(t, []string{"helm3", "build"}, runner.runs[0].Command)
+		assert.False(t, runner.runs[0].Pull, "the image should only be pulled when it is missing by default")
+		assert.Equal(t, []string{"actions: {}"}, runner.stdin, "the input should be piped to the container")
+		assert.Contains(t, c.TestContext.GetOutput(), "RUN apt-get install helm")
+	})
+
+	t.Run("custom action", func(t *testing.T) {
+		c, p, runner := newTestContainerProvider(t)
+		c.Data.MixinContainer.Pull = config.MixinContainerPullAlways
+
+		err := p.Run(ctx, c.Context, "exec", pkgmgmt.CommandOptions{Command: "status"})
+		require.NoError(t, err)
+
+		require.Len(t, runner.runs, 1)
+		assert.Equal(t, "example.com/mixins:v1", runner.runs[0].Image, "the default image should be used")
+		assert.Equal(t, []string{"exec", "invoke", "--action", "status"}, runner.runs[0].Command)
+		assert.True(t, runner.runs[0].Pull)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		c, p, runner := newTestContainerProvider(t)
+		runner.err = errContainerExit{code: 1}
+
+		err := p.Run(ctx, c.Context, "exec", pkgmgmt.CommandOptions{Command: "lint"})
+		require.ErrorContains(t, err, "mixin command failed exec lint in example.com/mixins:v1")
+		assert.True(t, errors.As(err, &errContainerExit{}))
+	})
+
+	t.Run("file", func(t *testing.T) {
+		c, p, _ := newTestContainerProvider(t)
+
+		err := p.Run(ctx, c.Context, "exec", pkgmgmt.CommandOptions{Command: "install", File: "install.yaml"})
+		require.ErrorContains(t, err, "files on the host are not available in the container")
+	})
+
+	t.Run("runtime", func(t *testing.T) {
+		c, p, runner := newTestContainerProvider(t)
+		mixinPath := "/home/myuser/.porter/mixins/exec/runtimes/exec-runtime"
+		require.NoError(t, c.FileSystem.WriteFile(mixinPath, []byte(mixinPath), 0700))
+		c.Setenv(test.ExpectedCommandEnv, mixinPath+" install")
+
+		err := p.Run(ctx, c.Context, "exec", pkgmgmt.CommandOptions{Command: "install", Runtime: true})
+		require.NoError(t, err)
+		assert.Empty(t, runner.runs, "the mixin runtime should not be run in a container")
+	})
+
+	t.Run("no image", func(t *testing.T) {
+		c, p, runner := newTestContainerProvider(t)
+		c.Data.MixinContainer.Image = ""
+		mixinPath := "/home/myuser/.porter/mixins/exec/exec"
+		require.NoError(t, c.FileSystem.WriteFile(mixinPath, []byte(mixinPath), 0700))
+		c.Setenv(test.ExpectedCommandEnv, mixinPath+" build")
+
+		err := p.Run(ctx, c.Context, "exec", pkgmgmt.CommandOptions{Command: "build"})
+		require.NoError(t, err)
+		assert.Empty(t, runner.runs, "mixins without an image should be run from PORTER_HOME")
+	})
+}
+
+func TestContainerProvider_Queries(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("metadata", func(t *testing.T) {
+		_, p, runner := newTestContainerProvider(t)
+		runner.output = `{"name": "helm3", "version": "v2.0.0"}`
+
+		meta, err := p.GetMetadata(ctx, "helm3")
+		require.NoError(t, err)
+		assert.Equal(t, "v2.0.0", meta.(*Metadata).VersionInfo.Version)
+		assert.Equal(t, []string{"helm3", "version", "--output", "json"}, runner.runs[0].Command)
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		_, p, runner := newTestContainerProvider(t)
+		runner.output = `{"definitions": {}}`
+
+		schema, err := p.GetSchema(ctx, "helm3", SchemaOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, `{"definitions": {}}`, schema)
+		assert.Equal(t, []string{"helm3", "schema"}, runner.runs[0].Command)
+	})
+
+	t.Run("capabilities", func(t *testing.T) {
+		_, p, runner := newTestContainerProvider(t)
+		runner.output = `{"protocolVersions": ["1"], "commands": ["build"]}`
+
+		caps, err := p.GetCapabilities(ctx, "helm3")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"build"}, caps.Commands)
+
+		_, err = p.GetCapabilities(ctx, "helm3")
+		require.NoError(t, err)
+		assert.Len(t, runner.runs, 1, "the capabilities should be cached")
+	})
+}
@@ -0,0 +1,134 @@
+package mixin
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+var _ ContainerRunner = dockerRunner{}
+
+// dockerRunner runs mixin commands in a container with the docker engine
+// configured by the DOCKER_HOST environment variable. The container does not
+// have network access, and is removed when the command exits.
+type dockerRunner struct{}
+
+func (dockerRunner) RunContainer(ctx context.Context, opts ContainerOptions) error {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return fmt.Errorf("could not connect to the docker engine: %w", err)
+	}
+	defer cli.Close()
+
+	if err = pullImage(ctx, cli, opts.Image, opts.Pull); err != nil {
+		return err
+	}
+
+	cfg := &container.Config{
+		Image:        opts.Image,
+		Cmd:          opts.Command,
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+	if opts.Stdin != nil {
+		cfg.AttachStdin = true
+		cfg.OpenStdin = true
+		cfg.StdinOnce = true
+	}
+	hostCfg := &container.HostConfig{NetworkMode: "none"}
+
+	created, err := cli.ContainerCreate(ctx, cfg, hostCfg, nil, nil, "")
+	if err != nil {
+		return fmt.Errorf("could not create a container from %s: %w", opts.Image, err)
+	}
+	defer func() {
+		// Remove the container even when the command was cancelled
+		_ = cli.ContainerRemove(context.Background(), created.ID, types.ContainerRemoveOptions{Force: true})
+	}()
+
+	attach, err := cli.ContainerAttach(ctx, created.ID, types.ContainerAttachOptions{
+		Stream: true,
+		Stdin:  opts.Stdin != nil,
+		Stdout: true,
+		Stderr: true,
+	})
+	if err != nil {
+		return fmt.Errorf("could not attach to the container: %w", err)
+	}
+	defer attach.Close()
+
+	// Wait for the container before it is started, so that the exit is not missed
+	statusC, errC := cli.ContainerWait(ctx, created.ID, container.WaitConditionNextExit)
+
+	if err = cli.ContainerStart(ctx, created.ID, types.ContainerStartOptions{}); err != nil {
+		return fmt.Errorf("could not start the container: %w", err)
+	}
+
+	if opts.Stdin != nil {
+		go func() {
+			_, _ = io.Copy(attach.Conn, opts.Stdin)
+			_ = attach.CloseWrite()
+		}()
+	}
+
+	stdout := opts.Stdout
+	if stdout == nil {
+		stdout = io.Discard
+	}
+	stderr := opts.Stderr
+	if stderr == nil {
+		stderr = io.Discard
+	}
+	outputDone := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(stdout, stderr, attach.Reader)
+		outputDone <- err
+	}()
+
+	select {
+	case err = <-errC:
+		return fmt.Errorf("error waiting for the container: %w", err)
+	case status := <-statusC:
+		// Wait for the remaining output to be copied
+		<-outputDone
+		if status.Error != nil {
+			return fmt.Errorf("error waiting for the container: %s", status.Error.Message)
+		}
+		if status.StatusCode != 0 {
+			return errContainerExit{code: status.StatusCode}
+		}
+		return nil
+	}
+}
+
+// pullImage pulls the image when it is not in the local image store, or when
+// always is set.
+func pullImage(ctx context.Context, cli *client.Client, image string, always bool) error {
+	if !always {
+		_, _, err := cli.ImageInspectWithRaw(ctx, image)
+		if err == nil {
+			return nil
+		}
+		if !client.IsErrNotFound(err) {
+			return fmt.Errorf("could not inspect image %s: %w", image, err)
+		}
+	}
+
+	r, err := cli.ImagePull(ctx, image, types.ImagePullOptions{})
+	if err != nil {
+		return fmt.Errorf("could not pull image %s: %w", image, err)
+	}
+	defer r.Close()
+
+	// Errors that happen during the pull are reported in the progress messages
+	if err = jsonmessage.DisplayJSONMessagesStream(r, io.Discard, 0, false, nil); err != nil {
+		return fmt.Errorf("could not pull image %s: %w", image, err)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package mixin
+
+import "get.porter.sh/porter/pkg/pkgmgmt"
+
+// getExecutionPolicy returns the policy from the mixin-execution-policy
+// config file setting that restricts how the mixin is run, or nil when it is
+// not restricted.
+func (c *PackageManager) getExecutionPolicy(name string) *pkgmgmt.ExecutionPolicy {
+	policy := c.Data.MixinExecutionPolicy
+	if !policy.IsRestricted() {
+		return nil
+	}
+
+	return &pkgmgmt.ExecutionPolicy{
+		RestrictEnv:       policy.RestrictEnvironment,
+		AllowedEnv:        policy.GetAllowedEnv(name),
+		IsolateWorkingDir: policy.IsolateWorkingDir,
+	}
+}
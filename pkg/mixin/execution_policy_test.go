@@ -0,0 +1,48 @@
+package mixin
+
+import (
+	"testing"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageManager_GetExecutionPolicy(t *testing.T) {
+	c := config.NewTestConfig(t)
+	mgr := NewPackageManager(c.Config)
+
+	t.Run("unrestricted by default", func(t *testing.T) {
+		assert.Nil(t, mgr.GetExecutionPolicy("exec"))
+	})
+
+	t.Run("restricted environment", func(t *testing.T) {
+		c.Data.MixinExecutionPolicy = config.MixinExecutionPolicy{
+			RestrictEnvironment: true,
+			AllowedEnv:          []string{"HTTPS_PROXY"},
+			Mixins:              map[string][]string{"az": {"AZURE_*"}},
+		}
+
+		policy := mgr.GetExecutionPolicy("az")
+		require.NotNil(t, policy)
+		assert.True(t, policy.RestrictEnv)
+		assert.False(t, policy.IsolateWorkingDir)
+		assert.True(t, policy.AllowsEnv("PATH"), "the default environment variables should be allowed")
+		assert.True(t, policy.AllowsEnv("HTTPS_PROXY"), "the environment variables allowed for every mixin should be allowed")
+		assert.True(t, policy.AllowsEnv("AZURE_CLIENT_ID"), "the environment variables allowed for the mixin should be allowed")
+		assert.False(t, policy.AllowsEnv("AWS_SECRET_ACCESS_KEY"))
+
+		policy = mgr.GetExecutionPolicy("exec")
+		require.NotNil(t, policy)
+		assert.False(t, policy.AllowsEnv("AZURE_CLIENT_ID"), "the environment variables allowed for another mixin should not be allowed")
+	})
+
+	t.Run("isolated working directory", func(t *testing.T) {
+		c.Data.MixinExecutionPolicy = config.MixinExecutionPolicy{IsolateWorkingDir: true}
+
+		policy := mgr.GetExecutionPolicy("exec")
+		require.NotNil(t, policy)
+		assert.Equal(t, &pkgmgmt.ExecutionPolicy{IsolateWorkingDir: true}, policy)
+	})
+}
@@ -22,6 +22,14 @@ type TestMixinProvider struct {
 	// ReturnBuildError will force the TestMixinProvider to return a build error
 	// if set to true
 	ReturnBuildError bool
+
+	// MixinHealth allows you to provide the result of CheckMixin for a mixin.
+	// By default, every check passes for the installed mixins.
+	MixinHealth map[string]MixinHealth
+
+	// MixinCapabilities allows you to provide the result of GetCapabilities
+	// for a mixin. By default, mixins report the default capabilities.
+	MixinCapabilities map[string]Capabilities
 }
 
 // NewTestMixinProvider helps us test Porter.Mixins in our unit tests without actually hitting any real plugins on the file system.
@@ -73,7 +81,7 @@ func (p *TestMixinProvider) PrintMixinOutput(pkgContext *portercontext.Context,
 	return nil
 }
 
-func (p *TestMixinProvider) GetSchema(ctx context.Context, name string) (string, error) {
+func (p *TestMixinProvider) GetSchema(ctx context.Context, name string, opts SchemaOptions) (string, error) {
 	var schemaFile string
 	switch name {
 	case "exec":
@@ -86,3 +94,36 @@ func (p *TestMixinProvider) GetSchema(ctx context.Context, name string) (string,
 	b, err := os.ReadFile(schemaFile)
 	return string(b), err
 }
+
+func (p *TestMixinProvider) CheckMixin(ctx context.Context, name string) (MixinHealth, error) {
+	if health, ok := p.MixinHealth[name]; ok {
+		return health, nil
+	}
+
+	meta, err := p.GetMetadata(ctx, name)
+	if err != nil {
+		return MixinHealth{}, err
+	}
+	version := meta.(*Metadata).VersionInfo.Version
+	return MixinHealth{
+		Name:    name,
+		Version: version,
+		Checks: []CheckResult{
+			{Name: CheckExecutable, Status: CheckStatusOK, Message: fmt.Sprintf("/home/myuser/.porter/mixins/%s/%s", name, name)},
+			{Name: CheckRuntime, Status: CheckStatusOK, Message: fmt.Sprintf("/home/myuser/.porter/mixins/%s/runtimes/%s-runtime", name, name)},
+			{Name: CheckVersion, Status: CheckStatusOK, Message: version},
+			{Name: CheckCapabilities, Status: CheckStatusOK, Message: "mixin protocol version " + ProtocolVersion},
+			{Name: CheckSchema, Status: CheckStatusOK, Message: "the mixin reported a valid schema"},
+		},
+	}, nil
+}
+
+func (p *TestMixinProvider) GetCapabilities(ctx context.Context, name string) (Capabilities, error) {
+	if caps, ok := p.MixinCapabilities[name]; ok {
+		return caps, nil
+	}
+
+	var caps Capabilities
+	caps.applyDefaults()
+	return caps, nil
+}
@@ -8,7 +8,7 @@ import (
 
 func IsCoreMixinCommand(value string) bool {
 	switch value {
-	case "install", "upgrade", "uninstall", "build", "lint", "schema", "version":
+	case "install", "upgrade", "uninstall", "build", "lint", "schema", "version", "capabilities":
 		return true
 	default:
 		return false
@@ -21,5 +21,11 @@ type MixinProvider interface {
 	pkgmgmt.PackageManager
 
 	// GetSchema requests the manifest schema from the mixin.
-	GetSchema(ctx context.Context, name string) (string, error)
+	GetSchema(ctx context.Context, name string, opts SchemaOptions) (string, error)
+
+	// CheckMixin diagnoses problems with an installed mixin.
+	CheckMixin(ctx context.Context, name string) (MixinHealth, error)
+
+	// GetCapabilities returns the capabilities reported by the mixin.
+	GetCapabilities(ctx context.Context, name string) (Capabilities, error)
 }
@@ -7,8 +7,10 @@ import (
 	"os/exec"
 
 	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/metrics"
 	"get.porter.sh/porter/pkg/pkgmgmt"
 	"get.porter.sh/porter/pkg/pkgmgmt/client"
+	"get.porter.sh/porter/pkg/portercontext"
 	"get.porter.sh/porter/pkg/tracing"
 	"go.uber.org/zap/zapcore"
 )
@@ -22,6 +24,9 @@ var _ MixinProvider = &PackageManager{}
 // PackageManager handles package management for mixins.
 type PackageManager struct {
 	*client.FileSystem
+
+	// capabilities reported by each mixin.
+	capabilities capabilitiesCache
 }
 
 func NewPackageManager(c *config.Config) *PackageManager {
@@ -29,6 +34,7 @@ func NewPackageManager(c *config.Config) *PackageManager {
 		FileSystem: client.NewFileSystem(c, Directory),
 	}
 	client.PreRun = client.PreRunMixinCommandHandler
+	client.GetExecutionPolicy = client.getExecutionPolicy
 	client.BuildMetadata = func() pkgmgmt.PackageMetadata {
 		return &Metadata{}
 	}
@@ -48,7 +54,33 @@ func (c *PackageManager) PreRunMixinCommandHandler(command string, cmd *exec.Cmd
 	}
 }
 
-func (c *PackageManager) GetSchema(ctx context.Context, name string) (string, error) {
+// GetMetadata verifies the mixin against the mixin trust policy and then
+// queries it for its metadata.
+func (c *PackageManager) GetMetadata(ctx context.Context, name string) (pkgmgmt.PackageMetadata, error) {
+	if err := c.VerifyMixin(ctx, name); err != nil {
+		return nil, err
+	}
+	return c.FileSystem.GetMetadata(ctx, name)
+}
+
+// Run verifies the mixin against the mixin trust policy and then runs the
+// command. The mixin runtime binary is not verified because it is only run
+// inside the invocation image.
+func (c *PackageManager) Run(ctx context.Context, pkgContext *portercontext.Context, name string, commandOpts pkgmgmt.CommandOptions) error {
+	if !commandOpts.Runtime {
+		if err := c.VerifyMixin(ctx, name); err != nil {
+			return err
+		}
+	}
+	err := c.FileSystem.Run(ctx, pkgContext, name, commandOpts)
+	metrics.CountMixinInvocation(name, commandOpts.Command, err)
+	return err
+}
+
+// GetSchema returns the manifest schema for the mixin. Querying a mixin for
+// its schema is slow, so schemas are cached in PORTER_HOME, and the cache is
+// used until the mixin binary changes.
+func (c *PackageManager) GetSchema(ctx context.Context, name string, opts SchemaOptions) (string, error) {
 	log := tracing.LoggerFromContext(ctx)
 
 	mixinDir, err := c.GetPackageDir(name)
@@ -56,6 +88,39 @@ func (c *PackageManager) GetSchema(ctx context.Context, name string) (string, er
 		return "", err
 	}
 
+	if err = c.VerifyMixin(ctx, name); err != nil {
+		return "", err
+	}
+
+	digest, err := c.getMixinDigest(name, mixinDir)
+	if err != nil {
+		return "", err
+	}
+
+	if !opts.NoCache {
+		if schema, ok := c.readCachedSchema(ctx, name, digest); ok {
+			log.Debugf("using the cached schema for the %s mixin", name)
+			return schema, nil
+		}
+	}
+
+	schema, err := c.querySchema(ctx, mixinDir, name)
+	if err != nil {
+		return "", err
+	}
+
+	// Failing to cache the schema only makes the next call slower
+	if err = c.writeCachedSchema(name, digest, schema); err != nil {
+		log.Debugf("could not cache the schema for the %s mixin: %w", name, err)
+	}
+
+	return schema, nil
+}
+
+// querySchema runs the mixin's schema command.
+func (c *PackageManager) querySchema(ctx context.Context, mixinDir string, name string) (string, error) {
+	log := tracing.LoggerFromContext(ctx)
+
 	r := client.NewRunner(name, mixinDir, false)
 
 	// Copy the existing context and tweak to pipe the output differently
@@ -67,9 +132,8 @@ func (c *PackageManager) GetSchema(ctx context.Context, name string) (string, er
 	}
 	r.Context = &mixinContext
 
-	cmd := pkgmgmt.CommandOptions{Command: "schema", PreRun: c.PreRun}
-	err = r.Run(ctx, cmd)
-	if err != nil {
+	cmd := pkgmgmt.CommandOptions{Command: "schema", PreRun: c.PreRun, Policy: c.GetExecutionPolicy(name)}
+	if err := r.Run(ctx, cmd); err != nil {
 		return "", err
 	}
 
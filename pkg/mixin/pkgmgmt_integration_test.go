@@ -24,7 +24,7 @@ func TestPackageManager_GetSchema(t *testing.T) {
 	c.SetHomeDir(binDir)
 
 	p := NewPackageManager(c.Config)
-	gotSchema, err := p.GetSchema(ctx, "exec")
+	gotSchema, err := p.GetSchema(ctx, "exec", SchemaOptions{})
 	require.NoError(t, err)
 
 	wantSchema, err := os.ReadFile("../exec/schema/exec.json")
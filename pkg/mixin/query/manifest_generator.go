@@ -1,10 +1,12 @@
 package query
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"get.porter.sh/porter/pkg/cnab"
 	"get.porter.sh/porter/pkg/manifest"
+	"get.porter.sh/porter/pkg/mixin"
 	"get.porter.sh/porter/pkg/yaml"
 )
 
@@ -20,6 +22,7 @@ func NewManifestGenerator(m *manifest.Manifest) *ManifestGenerator {
 }
 
 var _ MixinInputGenerator = &ManifestGenerator{}
+var _ FormattedInputGenerator = &ManifestGenerator{}
 
 func (g ManifestGenerator) ListMixins() []string {
 	mixinNames := make([]string, len(g.Manifest.Mixins))
@@ -39,6 +42,26 @@ func (g ManifestGenerator) BuildInput(mixinName string) ([]byte, error) {
 	return inputB, nil
 }
 
+// BuildFormattedInput generates the input to send to the specified mixin
+// in the specified format.
+func (g ManifestGenerator) BuildFormattedInput(mixinName string, format string) ([]byte, error) {
+	inputB, err := g.BuildInput(mixinName)
+	if err != nil || format != mixin.BuildInputFormatJson {
+		return inputB, err
+	}
+
+	// Convert from yaml so that the steps are formatted the same way in either format
+	var input interface{}
+	if err = yaml.Unmarshal(inputB, &input); err != nil {
+		return nil, fmt.Errorf("could not convert mixin build input for %s to json: %w", mixinName, err)
+	}
+	inputB, err = json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal mixin build input for %s: %w", mixinName, err)
+	}
+	return inputB, nil
+}
+
 func (g ManifestGenerator) buildInputForMixin(mixinName string) BuildInput {
 	input := BuildInput{
 		Actions: make(map[string]interface{}, 3),
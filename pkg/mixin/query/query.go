@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 
+	"get.porter.sh/porter/pkg/mixin"
 	"get.porter.sh/porter/pkg/pkgmgmt"
 	"get.porter.sh/porter/pkg/portercontext"
 	"get.porter.sh/porter/pkg/tracing"
@@ -46,6 +47,14 @@ type MixinInputGenerator interface {
 	BuildInput(mixinName string) ([]byte, error)
 }
 
+// FormattedInputGenerator is implemented by a MixinInputGenerator that can
+// generate the input in the formats that a mixin reports in its capabilities.
+type FormattedInputGenerator interface {
+	// BuildFormattedInput generates the input to send to the specified mixin
+	// in the specified format, for example mixin.BuildInputFormatJson.
+	BuildFormattedInput(mixinName string, format string) ([]byte, error)
+}
+
 type MixinBuildOutput struct {
 	// Name of the mixin.
 	Name string
@@ -87,7 +96,18 @@ func (q *MixinQuery) Execute(ctx context.Context, cmd string, inputGenerator Mix
 				mixinContext.Err = io.Discard
 			}
 
-			inputB, err := inputGenerator.BuildInput(mn)
+			format, err := q.negotiate(ctx, mn, cmd)
+			if err != nil {
+				results[i].Error = err
+				return nil
+			}
+
+			var inputB []byte
+			if fg, ok := inputGenerator.(FormattedInputGenerator); ok {
+				inputB, err = fg.BuildFormattedInput(mn, format)
+			} else {
+				inputB, err = inputGenerator.BuildInput(mn)
+			}
 			if err != nil {
 				return err
 			}
@@ -131,3 +151,29 @@ func (q *MixinQuery) Execute(ctx context.Context, cmd string, inputGenerator Mix
 
 	return results, nil
 }
+
+// negotiate checks the capabilities of the mixin, when the package manager
+// reports them, and returns the format of the input to send to the mixin.
+// An error wrapping mixin.ErrCommandNotSupported is returned when the mixin
+// does not support the command.
+func (q *MixinQuery) negotiate(ctx context.Context, mixinName string, cmd string) (string, error) {
+	provider, ok := q.Mixins.(mixin.CapabilitiesProvider)
+	if !ok {
+		return mixin.BuildInputFormatYaml, nil
+	}
+
+	caps, err := provider.GetCapabilities(ctx, mixinName)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = caps.NegotiateProtocol(); err != nil {
+		return "", err
+	}
+
+	if !caps.Supports(cmd) {
+		return "", fmt.Errorf("%s: %w", cmd, mixin.ErrCommandNotSupported)
+	}
+
+	return caps.GetBuildInputFormat()
+}
@@ -0,0 +1,73 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/manifest"
+	"get.porter.sh/porter/pkg/mixin"
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"get.porter.sh/porter/pkg/portercontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMixinQuery_Execute_Capabilities(t *testing.T) {
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	c.TestContext.AddTestFile("testdata/porter.yaml", config.Name)
+	m, err := manifest.LoadManifestFrom(ctx, c.Config, config.Name)
+	require.NoError(t, err, "could not load manifest")
+
+	mixins := mixin.NewTestMixinProvider()
+	mixins.MixinCapabilities = map[string]mixin.Capabilities{
+		"exec": {ProtocolVersions: []string{"1"}, Commands: []string{"build"}, BuildInputFormats: []string{"json"}},
+		"az":   mixin.LegacyCapabilities(),
+	}
+	// The mixins are run in parallel
+	var inputsMu sync.Mutex
+	inputs := make(map[string]string)
+	mixins.RunAssertions = []func(pkgContext *portercontext.Context, name string, commandOpts pkgmgmt.CommandOptions) error{
+		func(pkgContext *portercontext.Context, name string, commandOpts pkgmgmt.CommandOptions) error {
+			inputsMu.Lock()
+			defer inputsMu.Unlock()
+			inputs[name+" "+commandOpts.Command] = commandOpts.Input
+			return nil
+		},
+	}
+	q := New(c.Context, mixins)
+
+	t.Run("input format", func(t *testing.T) {
+		results, err := q.Execute(ctx, "build", NewManifestGenerator(m))
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+
+		var input map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(inputs["exec build"]), &input), "the exec mixin should receive json input")
+		assert.Contains(t, input, "actions")
+		assert.Contains(t, inputs["az build"], "extensions:", "the az mixin should receive yaml input")
+	})
+
+	t.Run("unsupported command", func(t *testing.T) {
+		results, err := q.Execute(ctx, "lint", NewManifestGenerator(m))
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+
+		assert.True(t, errors.Is(results[0].Error, mixin.ErrCommandNotSupported), "the exec mixin does not support lint")
+		assert.NotContains(t, inputs, "exec lint", "the exec mixin should not be run")
+		assert.NoError(t, results[1].Error)
+		assert.Contains(t, inputs, "az lint")
+	})
+
+	t.Run("unsupported protocol", func(t *testing.T) {
+		mixins.MixinCapabilities["exec"] = mixin.Capabilities{ProtocolVersions: []string{"2"}, Commands: []string{"build"}, BuildInputFormats: []string{"yaml"}}
+		q.RequireAllMixinResponses = true
+
+		_, err := q.Execute(ctx, "build", NewManifestGenerator(m))
+		require.ErrorContains(t, err, "this version of Porter only supports 1")
+	})
+}
@@ -0,0 +1,125 @@
+package mixin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// SchemaOptions control how the manifest schema is retrieved from a mixin.
+type SchemaOptions struct {
+	// NoCache skips the schema cache and always queries the mixin for its
+	// schema. The cache is updated with the schema that was returned.
+	NoCache bool
+}
+
+// cachedSchema is the schema of a mixin saved in the schema cache.
+type cachedSchema struct {
+	// Digest of the mixin binary that generated the schema. When the mixin
+	// binary changes, the cached schema is no longer used.
+	Digest string `json:"digest"`
+
+	// Schema reported by the mixin.
+	Schema string `json:"schema"`
+}
+
+// getSchemaCacheDir returns the directory where mixin schemas are cached,
+// PORTER_HOME/cache/mixins.
+func (c *PackageManager) getSchemaCacheDir() (string, error) {
+	home, err := c.GetHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "cache", Directory), nil
+}
+
+func (c *PackageManager) getSchemaCachePath(name string) (string, error) {
+	cacheDir, err := c.getSchemaCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, name+"-schema.json"), nil
+}
+
+// getMixinDigest calculates the digest of the mixin binary, which is used to
+// detect when the cached schema is out-of-date.
+func (c *PackageManager) getMixinDigest(name string, mixinDir string) (string, error) {
+	mixinPath := c.BuildClientPath(mixinDir, name)
+	f, err := c.Config.FileSystem.Open(mixinPath)
+	if err != nil {
+		return "", fmt.Errorf("could not open the %s mixin binary %s: %w", name, mixinPath, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("could not calculate the digest of the %s mixin binary %s: %w", name, mixinPath, err)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// readCachedSchema returns the cached schema for a mixin, when it was
+// generated by a mixin binary with the specified digest.
+func (c *PackageManager) readCachedSchema(ctx context.Context, name string, digest string) (string, bool) {
+	log := tracing.LoggerFromContext(ctx)
+
+	cachePath, err := c.getSchemaCachePath(name)
+	if err != nil {
+		log.Debugf("could not determine the schema cache path for the %s mixin: %w", name, err)
+		return "", false
+	}
+
+	exists, _ := c.Config.FileSystem.Exists(cachePath)
+	if !exists {
+		return "", false
+	}
+
+	data, err := c.Config.FileSystem.ReadFile(cachePath)
+	if err != nil {
+		log.Debugf("could not read the cached schema for the %s mixin from %s: %w", name, cachePath, err)
+		return "", false
+	}
+
+	var cached cachedSchema
+	if err = json.Unmarshal(data, &cached); err != nil {
+		log.Debugf("ignoring invalid cached schema for the %s mixin at %s: %w", name, cachePath, err)
+		return "", false
+	}
+
+	if cached.Digest != digest {
+		log.Debugf("the cached schema for the %s mixin is out-of-date because the mixin binary has changed", name)
+		return "", false
+	}
+
+	return cached.Schema, true
+}
+
+// writeCachedSchema saves the schema reported by a mixin binary with the
+// specified digest to the schema cache.
+func (c *PackageManager) writeCachedSchema(name string, digest string, schema string) error {
+	cachePath, err := c.getSchemaCachePath(name)
+	if err != nil {
+		return err
+	}
+
+	if err = c.Config.FileSystem.MkdirAll(filepath.Dir(cachePath), pkg.FileModeDirectory); err != nil {
+		return fmt.Errorf("could not create the mixin schema cache directory: %w", err)
+	}
+
+	data, err := json.Marshal(cachedSchema{Digest: digest, Schema: schema})
+	if err != nil {
+		return fmt.Errorf("could not marshal the schema for the %s mixin: %w", name, err)
+	}
+
+	if err = c.Config.FileSystem.WriteFile(cachePath, data, pkg.FileModeWritable); err != nil {
+		return fmt.Errorf("could not write the cached schema for the %s mixin to %s: %w", name, cachePath, err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package mixin
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageManager_GetSchema_Cache(t *testing.T) {
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	mixinPath := "/home/myuser/.porter/mixins/exec/exec"
+	require.NoError(t, c.FileSystem.WriteFile(mixinPath, []byte("exec v1"), pkg.FileModeExecutable))
+	p := NewPackageManager(c.Config)
+
+	c.Setenv(test.ExpectedCommandOutputEnv, `{"version": 1}`)
+	schema, err := p.GetSchema(ctx, "exec", SchemaOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "{\"version\": 1}\n", schema, "the schema should be queried from the mixin")
+
+	cacheExists, _ := c.FileSystem.Exists("/home/myuser/.porter/cache/mixins/exec-schema.json")
+	assert.True(t, cacheExists, "the schema should be cached")
+
+	c.Setenv(test.ExpectedCommandOutputEnv, `{"version": 2}`)
+	schema, err = p.GetSchema(ctx, "exec", SchemaOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "{\"version\": 1}\n", schema, "the cached schema should be used")
+
+	schema, err = p.GetSchema(ctx, "exec", SchemaOptions{NoCache: true})
+	require.NoError(t, err)
+	assert.Equal(t, "{\"version\": 2}\n", schema, "the schema should be queried from the mixin when --no-cache is specified")
+
+	c.Setenv(test.ExpectedCommandOutputEnv, `{"version": 3}`)
+	require.NoError(t, c.FileSystem.WriteFile(mixinPath, []byte("exec v2"), pkg.FileModeExecutable))
+	schema, err = p.GetSchema(ctx, "exec", SchemaOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "{\"version\": 3}\n", schema, "the cached schema should not be used when the mixin binary changes")
+}
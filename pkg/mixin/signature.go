@@ -0,0 +1,158 @@
+package mixin
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"get.porter.sh/porter/pkg/failure"
+	"get.porter.sh/porter/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SignatureExt is the file extension of the detached signature for a mixin
+// binary, which is saved next to the binary, for example
+// PORTER_HOME/mixins/exec/exec.sig. The signature is compatible with
+// signatures created by cosign sign-blob using a key pair.
+const SignatureExt = ".sig"
+
+// VerifyMixin checks that the mixin client binary is signed by one of the keys
+// trusted by the mixin trust policy. Mixins that the policy does not require
+// to be signed are not verified.
+func (c *PackageManager) VerifyMixin(ctx context.Context, name string) error {
+	policy := c.Data.MixinTrustPolicy
+	if !policy.RequiresSignature(name) {
+		return nil
+	}
+
+	ctx, span := tracing.StartSpan(ctx, attribute.String("mixin", name))
+	defer span.EndSpan()
+
+	mixinDir, err := c.GetPackageDir(name)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	mixinPath := c.BuildClientPath(mixinDir, name)
+	binary, err := c.Config.FileSystem.ReadFile(mixinPath)
+	if err != nil {
+		return span.Error(fmt.Errorf("could not read the %s mixin binary %s: %w", name, mixinPath, err))
+	}
+
+	sigPath := mixinPath + SignatureExt
+	sigExists, _ := c.Config.FileSystem.Exists(sigPath)
+	if !sigExists {
+		return span.Error(failure.PolicyDenied(fmt.Errorf("the %s mixin is not signed: the mixin trust policy requires a signature at %s", name, sigPath)))
+	}
+
+	sig, err := c.readSignature(sigPath)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	keys, err := c.loadTrustedKeys(policy.PublicKeys)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	for keyPath, key := range keys {
+		if err = verifySignature(key, binary, sig); err == nil {
+			span.Debugf("Verified the signature of the %s mixin with the public key %s", name, keyPath)
+			return nil
+		}
+	}
+
+	return span.Error(failure.PolicyDenied(fmt.Errorf("the %s mixin failed signature verification: the signature %s was not created by a key trusted by the mixin trust policy", name, sigPath)))
+}
+
+// readSignature reads a detached signature, which may be base64 encoded.
+func (c *PackageManager) readSignature(sigPath string) ([]byte, error) {
+	data, err := c.Config.FileSystem.ReadFile(sigPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the mixin signature %s: %w", sigPath, err)
+	}
+
+	if sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data))); err == nil {
+		return sig, nil
+	}
+	return data, nil
+}
+
+// loadTrustedKeys reads the trusted public keys, returning them keyed by their path.
+func (c *PackageManager) loadTrustedKeys(keyPaths []string) (map[string]crypto.PublicKey, error) {
+	home, err := c.GetHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(keyPaths))
+	for _, keyPath := range keyPaths {
+		if !filepath.IsAbs(keyPath) {
+			keyPath = filepath.Join(home, keyPath)
+		}
+
+		data, err := c.Config.FileSystem.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the public key %s from the mixin trust policy: %w", keyPath, err)
+		}
+
+		key, err := parsePublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %s in the mixin trust policy: %w", keyPath, err)
+		}
+		keys[keyPath] = key
+	}
+	return keys, nil
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("the key is not PEM encoded")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key.(type) {
+	case *ecdsa.PublicKey, ed25519.PublicKey, *rsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T, only ECDSA, Ed25519 and RSA keys are supported", key)
+	}
+}
+
+// verifySignature checks the signature of the contents. ECDSA and RSA
+// signatures are expected to be over the SHA-256 digest of the contents.
+func verifySignature(key crypto.PublicKey, contents []byte, sig []byte) error {
+	digest := sha256.Sum256(contents)
+
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, digest[:], sig) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, contents, sig) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig)
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+}
@@ -0,0 +1,139 @@
+package mixin
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"github.com/stretchr/testify/require"
+)
+
+const testMixinPath = "/home/myuser/.porter/mixins/exec/exec"
+
+// writePublicKey saves the public key to the test filesystem in PEM format.
+func writePublicKey(t *testing.T, c *config.TestConfig, path string, key crypto.PublicKey) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	require.NoError(t, c.FileSystem.WriteFile(path, data, pkg.FileModeWritable))
+}
+
+// signECDSA signs the contents like cosign sign-blob.
+func signECDSA(t *testing.T, key *ecdsa.PrivateKey, contents []byte) []byte {
+	digest := sha256.Sum256(contents)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	return []byte(base64.StdEncoding.EncodeToString(sig))
+}
+
+func TestPackageManager_VerifyMixin(t *testing.T) {
+	ctx := context.Background()
+	binary := []byte("exec mixin binary")
+
+	trustedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	untrustedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name      string
+		policy    config.MixinTrustPolicy
+		signature []byte
+		tamper    bool
+		wantError string
+	}{
+		{name: "no policy"},
+		{name: "signed by trusted key",
+			policy:    config.MixinTrustPolicy{PublicKeys: []string{"keys/mixins.pub"}},
+			signature: signECDSA(t, trustedKey, binary)},
+		{name: "not signed",
+			policy:    config.MixinTrustPolicy{PublicKeys: []string{"keys/mixins.pub"}},
+			wantError: "the exec mixin is not signed: the mixin trust policy requires a signature at /home/myuser/.porter/mixins/exec/exec.sig"},
+		{name: "allowed unsigned",
+			policy: config.MixinTrustPolicy{PublicKeys: []string{"keys/mixins.pub"}, AllowUnsigned: []string{"exec"}}},
+		{name: "signed by untrusted key",
+			policy:    config.MixinTrustPolicy{PublicKeys: []string{"keys/mixins.pub"}},
+			signature: signECDSA(t, untrustedKey, binary),
+			wantError: "the exec mixin failed signature verification"},
+		{name: "binary modified after signing",
+			policy:    config.MixinTrustPolicy{PublicKeys: []string{"keys/mixins.pub"}},
+			signature: signECDSA(t, trustedKey, binary),
+			tamper:    true,
+			wantError: "the exec mixin failed signature verification"},
+		{name: "missing public key",
+			policy:    config.MixinTrustPolicy{PublicKeys: []string{"/keys/missing.pub"}},
+			signature: signECDSA(t, trustedKey, binary),
+			wantError: "could not read the public key /keys/missing.pub"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := config.NewTestConfig(t)
+			c.Data.MixinTrustPolicy = tc.policy
+			writePublicKey(t, c, "/home/myuser/.porter/keys/mixins.pub", &trustedKey.PublicKey)
+
+			contents := binary
+			if tc.tamper {
+				contents = []byte("malicious exec mixin binary")
+			}
+			require.NoError(t, c.FileSystem.WriteFile(testMixinPath, contents, pkg.FileModeExecutable))
+			if tc.signature != nil {
+				require.NoError(t, c.FileSystem.WriteFile(testMixinPath+SignatureExt, tc.signature, pkg.FileModeWritable))
+			}
+
+			p := NewPackageManager(c.Config)
+			err := p.VerifyMixin(ctx, "exec")
+			if tc.wantError != "" {
+				require.ErrorContains(t, err, tc.wantError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPackageManager_VerifyMixin_Ed25519(t *testing.T) {
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	binary := []byte("exec mixin binary")
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	writePublicKey(t, c, "/keys/mixins.pub", pub)
+	c.Data.MixinTrustPolicy = config.MixinTrustPolicy{PublicKeys: []string{"/keys/mixins.pub"}}
+
+	require.NoError(t, c.FileSystem.WriteFile(testMixinPath, binary, pkg.FileModeExecutable))
+	require.NoError(t, c.FileSystem.WriteFile(testMixinPath+SignatureExt, ed25519.Sign(priv, binary), pkg.FileModeWritable))
+
+	p := NewPackageManager(c.Config)
+	require.NoError(t, p.VerifyMixin(ctx, "exec"))
+}
+
+func TestPackageManager_Run_UnsignedMixin(t *testing.T) {
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	c.Data.MixinTrustPolicy = config.MixinTrustPolicy{PublicKeys: []string{"keys/mixins.pub"}}
+	require.NoError(t, c.FileSystem.WriteFile(testMixinPath, []byte("exec mixin binary"), pkg.FileModeExecutable))
+
+	p := NewPackageManager(c.Config)
+	err := p.Run(ctx, c.Context, "exec", pkgmgmt.CommandOptions{Command: "build"})
+	require.ErrorContains(t, err, "the exec mixin is not signed")
+
+	_, err = p.GetSchema(ctx, "exec", SchemaOptions{})
+	require.ErrorContains(t, err, "the exec mixin is not signed")
+
+	_, err = p.GetMetadata(ctx, "exec")
+	require.ErrorContains(t, err, "the exec mixin is not signed")
+}
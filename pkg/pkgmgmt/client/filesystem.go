@@ -43,6 +43,21 @@ type FileSystem struct {
 	// BuildMetadata allows mixins/plugins to supply the proper struct that
 	// represents its package metadata.
 	BuildMetadata PackageMetadataBuilder
+
+	// GetExecutionPolicy returns the policy that restricts the environment
+	// of the commands run against a package client. It is optional, and a nil
+	// policy does not restrict the command.
+	GetExecutionPolicy func(name string) *pkgmgmt.ExecutionPolicy
+}
+
+// getExecutionPolicy returns the policy for running the package client.
+// Runtime packages are run inside the invocation image, which already
+// isolates them, and are not restricted.
+func (fs *FileSystem) getExecutionPolicy(name string, runtime bool) *pkgmgmt.ExecutionPolicy {
+	if runtime || fs.GetExecutionPolicy == nil {
+		return nil
+	}
+	return fs.GetExecutionPolicy(name)
 }
 
 func (fs *FileSystem) List() ([]string, error) {
@@ -87,7 +102,7 @@ func (fs *FileSystem) GetMetadata(ctx context.Context, name string) (pkgmgmt.Pac
 	}
 	r.Context = &pkgContext
 
-	cmd := pkgmgmt.CommandOptions{Command: "version --output json", PreRun: fs.PreRun}
+	cmd := pkgmgmt.CommandOptions{Command: "version --output json", PreRun: fs.PreRun, Policy: fs.getExecutionPolicy(name, false)}
 	err = r.Run(ctx, cmd)
 	if err != nil {
 		return nil, span.Error(err)
@@ -117,6 +132,7 @@ func (fs *FileSystem) Run(ctx context.Context, pkgContext *portercontext.Context
 	}
 
 	commandOpts.PreRun = fs.PreRun
+	commandOpts.Policy = fs.getExecutionPolicy(name, commandOpts.Runtime)
 	return r.Run(ctx, commandOpts)
 }
 
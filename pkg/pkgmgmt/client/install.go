@@ -2,6 +2,8 @@ package client
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,6 +12,7 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"get.porter.sh/porter/pkg"
 	"get.porter.sh/porter/pkg/pkgmgmt"
@@ -20,19 +23,24 @@ import (
 const PackageCacheJSON string = "cache.json"
 
 func (fs *FileSystem) Install(ctx context.Context, opts pkgmgmt.InstallOptions) error {
+	info := PackageInfo{Name: opts.Name, FeedURL: opts.FeedURL, URL: opts.URL, Reference: opts.Reference}
+
 	var err error
-	if opts.FeedURL != "" {
+	switch {
+	case opts.Reference != "":
+		info.Digest, err = fs.InstallFromReference(ctx, opts)
+	case opts.FeedURL != "":
 		err = fs.InstallFromFeedURL(ctx, opts)
-	} else {
+	default:
 		err = fs.InstallFromURL(ctx, opts)
 	}
 	if err != nil {
 		return err
 	}
-	return fs.savePackageInfo(ctx, opts)
+	return fs.savePackageInfo(ctx, info)
 }
 
-func (fs *FileSystem) savePackageInfo(ctx context.Context, opts pkgmgmt.InstallOptions) error {
+func (fs *FileSystem) savePackageInfo(ctx context.Context, info PackageInfo) error {
 	log := tracing.LoggerFromContext(ctx)
 
 	parentDir, _ := fs.GetPackagesDir()
@@ -59,11 +67,11 @@ func (fs *FileSystem) savePackageInfo(ctx context.Context, opts pkgmgmt.InstallO
 	}
 	//if a package exists, skip.
 	for _, pkg := range pkgDataJSON.Packages {
-		if pkg.Name == opts.Name {
+		if pkg.Name == info.Name {
 			return nil
 		}
 	}
-	updatedPkgList := append(pkgDataJSON.Packages, PackageInfo{Name: opts.Name, FeedURL: opts.FeedURL, URL: opts.URL})
+	updatedPkgList := append(pkgDataJSON.Packages, info)
 	pkgDataJSON.Packages = updatedPkgList
 	updatedPkgInfo, err := json.MarshalIndent(&pkgDataJSON, "", "  ")
 	if err != nil {
@@ -78,9 +86,11 @@ func (fs *FileSystem) savePackageInfo(ctx context.Context, opts pkgmgmt.InstallO
 }
 
 type PackageInfo struct {
-	Name    string `json:"name"`
-	FeedURL string `json:"URL,omitempty"`
-	URL     string `json:"url,omitempty"`
+	Name      string `json:"name"`
+	FeedURL   string `json:"URL,omitempty"`
+	URL       string `json:"url,omitempty"`
+	Reference string `json:"reference,omitempty"`
+	Digest    string `json:"digest,omitempty"`
 }
 
 type packages struct {
@@ -100,7 +110,7 @@ func (fs *FileSystem) installFromURLFor(ctx context.Context, opts pkgmgmt.Instal
 	runtimeUrl := opts.GetParsedURL()
 	runtimeUrl.Path = path.Join(runtimeUrl.Path, opts.Version, fmt.Sprintf("%s-linux-amd64", opts.Name))
 
-	err := fs.downloadPackage(ctx, opts.Name, clientUrl, runtimeUrl)
+	err := fs.downloadPackage(ctx, opts.Name, download{URL: clientUrl}, download{URL: runtimeUrl})
 	if err != nil && os == "darwin" && arch == "arm64" {
 		// Until we have full support for M1 chipsets, rely on rossetta functionality in macos and use the amd64 binary
 		log.Debugf("%s @ %s did not publish a download for darwin/amd64, falling back to darwin/amd64", opts.Name, opts.Version)
@@ -121,7 +131,7 @@ func (fs *FileSystem) InstallFromFeedURL(ctx context.Context, opts pkgmgmt.Insta
 	defer fs.FileSystem.RemoveAll(tmpDir)
 	feedPath := filepath.Join(tmpDir, "atom.xml")
 
-	err = fs.downloadFile(ctx, feedUrl, feedPath, false)
+	err = fs.downloadFile(ctx, download{URL: feedUrl}, feedPath, false)
 	if err != nil {
 		return err
 	}
@@ -132,49 +142,94 @@ func (fs *FileSystem) InstallFromFeedURL(ctx context.Context, opts pkgmgmt.Insta
 		return err
 	}
 
-	result := searchFeed.Search(opts.Name, opts.Version)
+	result, err := searchFeed.SearchCompatible(opts.Name, opts.Version, pkg.Version)
+	if err != nil {
+		return log.Error(err)
+	}
 	if result == nil {
 		return log.Error(fmt.Errorf("the feed at %s does not contain an entry for %s @ %s", opts.FeedURL, opts.Name, opts.Version))
 	}
 
-	clientUrl := result.FindDownloadURL(ctx, runtime.GOOS, runtime.GOARCH)
-	if clientUrl == nil {
-		return log.Error(fmt.Errorf("%s @ %s did not publish a download for %s/%s", opts.Name, opts.Version, runtime.GOOS, runtime.GOARCH))
+	clientFile := result.FindFile(ctx, runtime.GOOS, runtime.GOARCH)
+	if clientFile == nil {
+		return log.Error(fmt.Errorf("%s @ %s did not publish a download for %s/%s", opts.Name, result.Version, runtime.GOOS, runtime.GOARCH))
 	}
 
-	runtimeUrl := result.FindDownloadURL(ctx, "linux", "amd64")
-	if runtimeUrl == nil {
-		return log.Error(fmt.Errorf("%s @ %s did not publish a download for linux/amd64", opts.Name, opts.Version))
+	runtimeFile := result.FindFile(ctx, "linux", "amd64")
+	if runtimeFile == nil {
+		return log.Error(fmt.Errorf("%s @ %s did not publish a download for linux/amd64", opts.Name, result.Version))
 	}
 
-	return fs.downloadPackage(ctx, opts.Name, *clientUrl, *runtimeUrl)
+	return fs.downloadPackage(ctx, opts.Name,
+		download{URL: *clientFile.URL, SHA256: clientFile.SHA256},
+		download{URL: *runtimeFile.URL, SHA256: runtimeFile.SHA256})
 }
 
-func (fs *FileSystem) downloadPackage(ctx context.Context, name string, clientUrl url.URL, runtimeUrl url.URL) error {
+// download is a file to download.
+type download struct {
+	URL url.URL
+
+	// SHA256 is the expected checksum of the file, hex encoded. The checksum
+	// is not verified when it is empty.
+	SHA256 string
+}
+
+// downloadPackage downloads the client and runtime binaries of a package.
+// Both files are downloaded and verified before either is moved into place, so
+// that a package that is already installed is not replaced by a partial
+// download or a file that does not match its checksum.
+func (fs *FileSystem) downloadPackage(ctx context.Context, name string, clientFile download, runtimeFile download) error {
+	log := tracing.LoggerFromContext(ctx)
+
 	parentDir, err := fs.GetPackagesDir()
 	if err != nil {
 		return err
 	}
 	pkgDir := filepath.Join(parentDir, name)
-
-	clientPath := fs.BuildClientPath(pkgDir, name)
-	err = fs.downloadFile(ctx, clientUrl, clientPath, true)
+	pkgDirExists, err := fs.FileSystem.DirExists(pkgDir)
 	if err != nil {
-		return err
+		return log.Error(fmt.Errorf("unable to check if directory exists %s: %w", pkgDir, err))
 	}
 
+	clientPath := fs.BuildClientPath(pkgDir, name)
 	runtimePath := filepath.Join(pkgDir, "runtimes", name+"-runtime")
-	err = fs.downloadFile(ctx, runtimeUrl, runtimePath, true)
-	if err != nil {
-		fs.FileSystem.RemoveAll(pkgDir) // If the runtime download fails, cleanup the package so it's not half installed
-		return err
+	files := []struct {
+		download
+		destPath string
+	}{
+		{clientFile, clientPath},
+		{runtimeFile, runtimePath},
+	}
+
+	cleanup := func() {
+		if !pkgDirExists {
+			fs.FileSystem.RemoveAll(pkgDir) // If a download fails, cleanup the package so it's not half installed
+			return
+		}
+		for _, f := range files {
+			fs.FileSystem.Remove(f.destPath + ".download")
+		}
 	}
 
+	for _, f := range files {
+		if err = fs.downloadFile(ctx, f.download, f.destPath+".download", true); err != nil {
+			cleanup()
+			return err
+		}
+	}
+
+	for _, f := range files {
+		if err = fs.FileSystem.Rename(f.destPath+".download", f.destPath); err != nil {
+			cleanup()
+			return log.Error(fmt.Errorf("could not move the download to %s: %w", f.destPath, err))
+		}
+	}
 	return nil
 }
 
-func (fs *FileSystem) downloadFile(ctx context.Context, url url.URL, destPath string, executable bool) error {
+func (fs *FileSystem) downloadFile(ctx context.Context, file download, destPath string, executable bool) error {
 	log := tracing.LoggerFromContext(ctx)
+	url := file.URL
 	log.Debugf("Downloading %s to %s\n", url.String(), destPath)
 
 	req, err := http.NewRequest(http.MethodGet, url.String(), nil)
@@ -193,6 +248,26 @@ func (fs *FileSystem) downloadFile(ctx context.Context, url url.URL, destPath st
 	}
 	defer resp.Body.Close()
 
+	hash := sha256.New()
+	if err = fs.writeFile(ctx, io.TeeReader(resp.Body, hash), destPath, executable); err != nil {
+		return err
+	}
+
+	if file.SHA256 != "" {
+		checksum := hex.EncodeToString(hash.Sum(nil))
+		if !strings.EqualFold(checksum, file.SHA256) {
+			fs.FileSystem.Remove(destPath) // Do not leave a file that may have been tampered with
+			return log.Error(fmt.Errorf("the sha256 checksum of %s, %s, does not match the checksum from the feed, %s", url.String(), checksum, file.SHA256))
+		}
+	}
+	return nil
+}
+
+// writeFile saves the contents of src to destPath, creating the parent
+// directories as needed.
+func (fs *FileSystem) writeFile(ctx context.Context, src io.Reader, destPath string, executable bool) error {
+	log := tracing.LoggerFromContext(ctx)
+
 	// Ensure the parent directories exist
 	parentDir := filepath.Dir(destPath)
 	parentDirExists, err := fs.FileSystem.DirExists(parentDir)
@@ -226,7 +301,7 @@ func (fs *FileSystem) downloadFile(ctx context.Context, url url.URL, destPath st
 		}
 	}
 
-	_, err = io.Copy(destFile, resp.Body)
+	_, err = io.Copy(destFile, src)
 	if err != nil {
 		cleanup()
 		return log.Error(fmt.Errorf("error writing the file to %s: %w", destPath, err))
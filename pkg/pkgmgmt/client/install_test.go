@@ -121,6 +121,84 @@ func TestFileSystem_InstallFromFeedUrl(t *testing.T) {
 	assert.True(t, runtimeExists)
 }
 
+func TestFileSystem_InstallFromFeedUrl_V2(t *testing.T) {
+	var testURL = ""
+	feed, err := os.ReadFile("../feed/testdata/feed-v2.json")
+	require.NoError(t, err)
+
+	// serve out a fake v2 feed and package
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.RequestURI, "feed.json") {
+			fmt.Fprintln(w, strings.Replace(string(feed), "https://cdn.porter.sh", testURL, -1))
+		} else {
+			fmt.Fprintf(w, "#!/usr/bin/env bash\necho i am helm\n")
+		}
+	}))
+	defer ts.Close()
+	testURL = ts.URL
+
+	defer func(version string) { pkg.Version = version }(pkg.Version)
+	pkg.Version = "v1.1.0"
+
+	install := func(name string, version string) (*FileSystem, error) {
+		c := config.NewTestConfig(t)
+		p := NewFileSystem(c.Config, "packages")
+
+		opts := pkgmgmt.InstallOptions{
+			PackageType: "mixin",
+			Version:     version,
+			FeedURL:     ts.URL + "/feed.json",
+		}
+		require.NoError(t, opts.Validate([]string{name}), "Validate failed")
+		return p, p.Install(context.Background(), opts)
+	}
+
+	t.Run("verified checksum", func(t *testing.T) {
+		p, err := install("helm", "latest")
+		require.NoError(t, err)
+
+		clientExists, _ := p.FileSystem.Exists("/home/myuser/.porter/packages/helm/helm")
+		assert.True(t, clientExists)
+		runtimeExists, _ := p.FileSystem.Exists("/home/myuser/.porter/packages/helm/runtimes/helm-runtime")
+		assert.True(t, runtimeExists)
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		p, err := install("exec", "canary")
+		require.ErrorContains(t, err, "does not match the checksum from the feed")
+
+		clientExists, _ := p.FileSystem.Exists("/home/myuser/.porter/packages/exec/exec")
+		assert.False(t, clientExists, "the package should be removed when the checksum does not match")
+	})
+
+	t.Run("checksum mismatch keeps the installed package", func(t *testing.T) {
+		c := config.NewTestConfig(t)
+		p := NewFileSystem(c.Config, "packages")
+		clientPath := "/home/myuser/.porter/packages/exec/exec"
+		require.NoError(t, p.FileSystem.WriteFile(clientPath, []byte("installed"), pkg.FileModeExecutable))
+
+		opts := pkgmgmt.InstallOptions{
+			PackageType: "mixin",
+			Version:     "canary",
+			FeedURL:     ts.URL + "/feed.json",
+		}
+		require.NoError(t, opts.Validate([]string{"exec"}), "Validate failed")
+		err := p.Install(context.Background(), opts)
+		require.ErrorContains(t, err, "does not match the checksum from the feed")
+
+		contents, err := p.FileSystem.ReadFile(clientPath)
+		require.NoError(t, err)
+		assert.Equal(t, "installed", string(contents), "the installed package should not be replaced by a download that does not match its checksum")
+		downloadExists, _ := p.FileSystem.Exists(clientPath + ".download")
+		assert.False(t, downloadExists, "the download should be removed")
+	})
+
+	t.Run("incompatible porter version", func(t *testing.T) {
+		_, err := install("helm", "v1.3.0")
+		require.ErrorContains(t, err, "helm @ v1.3.0 requires Porter >=2.0.0 but the current version of Porter is v1.1.0")
+	})
+}
+
 func TestFileSystem_Install_RollbackMissingRuntime(t *testing.T) {
 	// serve out a fake package
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -173,7 +251,7 @@ func TestFileSystem_Install_PackageInfoSavedWhenNoFileExists(t *testing.T) {
 	cacheExists, _ := p.FileSystem.Exists("/home/myuser/.porter/packages/cache.json")
 	assert.False(t, cacheExists)
 
-	err = p.savePackageInfo(context.Background(), opts)
+	err = p.savePackageInfo(context.Background(), PackageInfo{Name: opts.Name, FeedURL: opts.FeedURL, URL: opts.URL})
 	require.NoError(t, err)
 
 	// cache.json should have been created
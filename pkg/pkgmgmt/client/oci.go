@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"path/filepath"
+	"runtime"
+
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// AnnotationTitle is the layer annotation that holds the file name of each
+// binary in a package published as an OCI artifact. This is the same
+// annotation used by tools such as oras when pushing files.
+const AnnotationTitle = "org.opencontainers.image.title"
+
+// InstallFromReference installs a package that was published as an OCI
+// artifact. The artifact has a layer for each binary, named with the
+// AnnotationTitle annotation using the same convention as packages downloaded
+// from a URL: NAME-OS-ARCH[FILE_EXT]. The layers are verified against their
+// digest, and the digest of the artifact is returned.
+func (fs *FileSystem) InstallFromReference(ctx context.Context, opts pkgmgmt.InstallOptions) (string, error) {
+	ctx, span := tracing.StartSpan(ctx, attribute.String("reference", opts.Reference), attribute.Bool("insecure", opts.InsecureRegistry))
+	defer span.EndSpan()
+
+	ref, err := name.ParseReference(opts.Reference, remoteNameOptions(opts)...)
+	if err != nil {
+		return "", span.Error(fmt.Errorf("invalid reference %s: %w", opts.Reference, err))
+	}
+
+	// When the reference includes a digest, the registry client verifies that
+	// the manifest matches it.
+	img, err := remote.Image(ref, remoteOptions(ctx, opts)...)
+	if err != nil {
+		return "", span.Error(fmt.Errorf("error pulling %s %s: %w", fs.PackageType, ref, err))
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return "", span.Error(fmt.Errorf("error calculating the digest of %s: %w", ref, err))
+	}
+	span.Debugf("Resolved %s to %s@%s", ref, ref.Context().Name(), digest)
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return "", span.Error(fmt.Errorf("error reading the manifest of %s: %w", ref, err))
+	}
+
+	clientLayer, ok := findPackageLayer(manifest, opts.Name, runtime.GOOS, runtime.GOARCH)
+	if !ok && runtime.GOOS == "darwin" && runtime.GOARCH == "arm64" {
+		// Until we have full support for M1 chipsets, rely on rossetta functionality in macos and use the amd64 binary
+		span.Debugf("%s did not publish a binary for darwin/arm64, falling back to darwin/amd64", ref)
+		clientLayer, ok = findPackageLayer(manifest, opts.Name, "darwin", "amd64")
+	}
+	if !ok {
+		return "", span.Error(fmt.Errorf("%s did not publish a binary for %s/%s", ref, runtime.GOOS, runtime.GOARCH))
+	}
+
+	runtimeLayer, ok := findPackageLayer(manifest, opts.Name, "linux", "amd64")
+	if !ok {
+		return "", span.Error(fmt.Errorf("%s did not publish a binary for linux/amd64", ref))
+	}
+
+	parentDir, err := fs.GetPackagesDir()
+	if err != nil {
+		return "", span.Error(err)
+	}
+	pkgDir := filepath.Join(parentDir, opts.Name)
+
+	clientPath := fs.BuildClientPath(pkgDir, opts.Name)
+	if err = fs.pullLayer(ctx, img, clientLayer, clientPath); err != nil {
+		return "", span.Error(err)
+	}
+
+	runtimePath := filepath.Join(pkgDir, "runtimes", opts.Name+"-runtime")
+	if err = fs.pullLayer(ctx, img, runtimeLayer, runtimePath); err != nil {
+		fs.FileSystem.RemoveAll(pkgDir) // If the runtime download fails, cleanup the package so it's not half installed
+		return "", span.Error(err)
+	}
+
+	return digest.String(), nil
+}
+
+// findPackageLayer returns the layer that holds the binary of a package for
+// the specified platform.
+func findPackageLayer(manifest *v1.Manifest, pkgName string, os string, arch string) (v1.Descriptor, bool) {
+	fileExt := ""
+	if os == "windows" {
+		fileExt = ".exe"
+	}
+	title := fmt.Sprintf("%s-%s-%s%s", pkgName, os, arch, fileExt)
+
+	for _, layer := range manifest.Layers {
+		if layer.Annotations[AnnotationTitle] == title {
+			return layer, true
+		}
+	}
+	return v1.Descriptor{}, false
+}
+
+// pullLayer saves the contents of a layer to destPath, verifying that the
+// contents match the layer's digest.
+func (fs *FileSystem) pullLayer(ctx context.Context, img v1.Image, desc v1.Descriptor, destPath string) error {
+	log := tracing.LoggerFromContext(ctx)
+	log.Debugf("Pulling %s to %s\n", desc.Digest, destPath)
+
+	if desc.Digest.Algorithm != "sha256" {
+		return log.Error(fmt.Errorf("unsupported digest algorithm for layer %s, only sha256 is supported", desc.Digest))
+	}
+
+	layer, err := img.LayerByDigest(desc.Digest)
+	if err != nil {
+		return log.Error(fmt.Errorf("error retrieving layer %s: %w", desc.Digest, err))
+	}
+
+	blob, err := layer.Compressed()
+	if err != nil {
+		return log.Error(fmt.Errorf("error pulling layer %s: %w", desc.Digest, err))
+	}
+	defer blob.Close()
+
+	h := sha256.New()
+	if err = fs.writeFile(ctx, io.TeeReader(blob, h), destPath, true); err != nil {
+		return err
+	}
+
+	if err = verifyDigest(h, desc.Digest); err != nil {
+		fs.FileSystem.Remove(destPath)
+		return log.Error(fmt.Errorf("error verifying %s: %w", destPath, err))
+	}
+	return nil
+}
+
+func verifyDigest(h hash.Hash, want v1.Hash) error {
+	got := hex.EncodeToString(h.Sum(nil))
+	if got != want.Hex {
+		return fmt.Errorf("digest mismatch, expected sha256:%s but the contents have digest sha256:%s", want.Hex, got)
+	}
+	return nil
+}
+
+func remoteNameOptions(opts pkgmgmt.InstallOptions) []name.Option {
+	if opts.InsecureRegistry {
+		return []name.Option{name.Insecure}
+	}
+	return nil
+}
+
+func remoteOptions(ctx context.Context, opts pkgmgmt.InstallOptions) []remote.Option {
+	result := []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+	}
+	if opts.InsecureRegistry {
+		skipTLS := http.DefaultTransport.(*http.Transport).Clone()
+		if skipTLS.TLSClientConfig == nil {
+			skipTLS.TLSClientConfig = &tls.Config{}
+		}
+		skipTLS.TLSClientConfig.InsecureSkipVerify = true
+		result = append(result, remote.WithTransport(skipTLS))
+	}
+	return result
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"runtime"
+	"testing"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"get.porter.sh/porter/tests"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pushTestPackage publishes a fake package with a binary for each platform to
+// the registry and returns the reference to it, and its digest.
+func pushTestPackage(t *testing.T, registryHost string, pkgName string, platforms ...string) (string, string) {
+	img := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	for _, platform := range platforms {
+		title := fmt.Sprintf("%s-%s", pkgName, platform)
+		layer := static.NewLayer([]byte("#!/usr/bin/env bash\necho i am "+title+"\n"), "application/octet-stream")
+
+		var err error
+		img, err = mutate.Append(img, mutate.Addendum{
+			Layer:       layer,
+			Annotations: map[string]string{AnnotationTitle: title},
+		})
+		require.NoError(t, err)
+	}
+
+	ref := fmt.Sprintf("%s/mixins/%s:v1.0.0", registryHost, pkgName)
+	parsedRef, err := name.ParseReference(ref, name.Insecure)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(parsedRef, img), "failed to push the test package")
+
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	return ref, digest.String()
+}
+
+func newTestRegistry(t *testing.T) string {
+	ts := httptest.NewServer(registry.New())
+	t.Cleanup(ts.Close)
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	return u.Host
+}
+
+func TestFileSystem_InstallFromReference(t *testing.T) {
+	registryHost := newTestRegistry(t)
+	clientPlatform := fmt.Sprintf("%s-%s%s", runtime.GOOS, runtime.GOARCH, pkgmgmt.FileExt)
+	ref, digest := pushTestPackage(t, registryHost, "mypkg", clientPlatform, "linux-amd64")
+
+	testcases := []struct {
+		name      string
+		reference string
+	}{
+		{name: "tag", reference: ref},
+		{name: "digest", reference: fmt.Sprintf("%s/mixins/mypkg@%s", registryHost, digest)},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := config.NewTestConfig(t)
+			p := NewFileSystem(c.Config, "packages")
+
+			opts := pkgmgmt.InstallOptions{
+				PackageType:      "mixin",
+				Reference:        tc.reference,
+				InsecureRegistry: true,
+			}
+			err := opts.Validate([]string{"mypkg"})
+			require.NoError(t, err, "Validate failed")
+
+			err = p.Install(context.Background(), opts)
+			require.NoError(t, err)
+
+			wantMode := pkg.FileModeExecutable
+			clientPath := "/home/myuser/.porter/packages/mypkg/mypkg" + pkgmgmt.FileExt
+			clientStats, err := p.FileSystem.Stat(clientPath)
+			require.NoError(t, err)
+			tests.AssertFilePermissionsEqual(t, clientPath, wantMode, clientStats.Mode())
+			clientContents, err := p.FileSystem.ReadFile(clientPath)
+			require.NoError(t, err)
+			assert.Contains(t, string(clientContents), "i am mypkg-"+clientPlatform)
+
+			runtimePath := "/home/myuser/.porter/packages/mypkg/runtimes/mypkg-runtime"
+			runtimeContents, err := p.FileSystem.ReadFile(runtimePath)
+			require.NoError(t, err)
+			assert.Contains(t, string(runtimeContents), "i am mypkg-linux-amd64")
+
+			cacheContents, err := p.FileSystem.ReadFile("/home/myuser/.porter/packages/cache.json")
+			require.NoError(t, err)
+			var cache packages
+			require.NoError(t, json.Unmarshal(cacheContents, &cache))
+			require.Len(t, cache.Packages, 1)
+			assert.Equal(t, PackageInfo{Name: "mypkg", Reference: tc.reference, Digest: digest}, cache.Packages[0])
+		})
+	}
+}
+
+func TestFileSystem_InstallFromReference_MissingBinary(t *testing.T) {
+	registryHost := newTestRegistry(t)
+	ref, _ := pushTestPackage(t, registryHost, "mypkg", "myos-myarch")
+
+	c := config.NewTestConfig(t)
+	p := NewFileSystem(c.Config, "packages")
+
+	opts := pkgmgmt.InstallOptions{
+		PackageType:      "mixin",
+		Reference:        ref,
+		InsecureRegistry: true,
+	}
+	err := opts.Validate([]string{"mypkg"})
+	require.NoError(t, err, "Validate failed")
+
+	err = p.Install(context.Background(), opts)
+	require.ErrorContains(t, err, "did not publish a binary")
+
+	pkgExists, _ := p.FileSystem.DirExists("/home/myuser/.porter/packages/mypkg")
+	assert.False(t, pkgExists, "the package should not be installed")
+}
+
+func TestVerifyDigest(t *testing.T) {
+	layer := static.NewLayer([]byte("i am a mixin"), "application/octet-stream")
+	digest, err := layer.Digest()
+	require.NoError(t, err)
+
+	hasher := sha256.New()
+	hasher.Write([]byte("i am a mixin"))
+	require.NoError(t, verifyDigest(hasher, digest))
+
+	hasher = sha256.New()
+	hasher.Write([]byte("i am a tampered mixin"))
+	require.ErrorContains(t, verifyDigest(hasher, digest), "digest mismatch")
+}
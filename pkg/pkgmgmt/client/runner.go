@@ -77,6 +77,18 @@ func (r *Runner) Run(ctx context.Context, commandOpts pkgmgmt.CommandOptions) er
 		cmd.Args = append(cmd.Args, "-f", commandOpts.File)
 	}
 
+	if policy := commandOpts.Policy; policy != nil {
+		cmd.Env = policy.FilterEnv(cmd.Env)
+		if policy.IsolateWorkingDir {
+			workDir, err := r.FileSystem.TempDir("", "porter-"+r.pkgName)
+			if err != nil {
+				return span.Error(fmt.Errorf("could not create a working directory for package command %s: %w", r.pkgName, err))
+			}
+			defer r.FileSystem.RemoveAll(workDir)
+			cmd.Dir = workDir
+		}
+	}
+
 	if commandOpts.Input != "" {
 		stdin, err := cmd.StdinPipe()
 		if err != nil {
@@ -99,7 +111,10 @@ func (r *Runner) Run(ctx context.Context, commandOpts pkgmgmt.CommandOptions) er
 	err = cmd.Wait()
 	if err != nil {
 		// Include stderr in the error, otherwise it just includes the exit code
-		err = fmt.Errorf("package command failed %s\n%s", prettyCmd, cmdStderr)
+		err = commandError{
+			msg: fmt.Sprintf("package command failed %s\n%s", prettyCmd, cmdStderr),
+			err: err,
+		}
 		// Do not flag this as an error in the logs because we often call mixins to see if they support a command
 		// and if they don't it's not an error, e.g. not all mixins support lint or schema
 		span.Debugf(err.Error())
@@ -109,6 +124,21 @@ func (r *Runner) Run(ctx context.Context, commandOpts pkgmgmt.CommandOptions) er
 	return nil
 }
 
+// commandError is returned when a package command fails. It wraps the error
+// from the command, so that callers can inspect the exit code.
+type commandError struct {
+	msg string
+	err error
+}
+
+func (e commandError) Error() string {
+	return e.msg
+}
+
+func (e commandError) Unwrap() error {
+	return e.err
+}
+
 func (r *Runner) getExecutablePath() string {
 	path := filepath.Join(r.pkgDir, r.pkgName)
 	if r.runtime {
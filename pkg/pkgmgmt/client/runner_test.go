@@ -1,8 +1,12 @@
 package client
 
 import (
+	"context"
 	"testing"
 
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"get.porter.sh/porter/pkg/test"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -34,3 +38,23 @@ func TestRunner_Validate_MissingExecutable(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "package not found")
 }
+
+func TestRunner_Run_ExecutionPolicy(t *testing.T) {
+	ctx := context.Background()
+	r := NewTestRunner(t, "mypackage", "packages", false)
+	r.Setenv(test.ExpectedCommandOutputEnv, "allowed")
+	r.Setenv(test.ExpectedCommandErrorEnv, "not allowed")
+
+	cmd := pkgmgmt.CommandOptions{
+		Command: "install",
+		Policy: &pkgmgmt.ExecutionPolicy{
+			RestrictEnv: true,
+			AllowedEnv:  []string{test.MockedCommandEnv, test.ExpectedCommandOutputEnv},
+		},
+	}
+	err := r.Run(ctx, cmd)
+	require.NoError(t, err)
+
+	assert.Contains(t, r.TestContext.GetOutput(), "allowed")
+	assert.NotContains(t, r.TestContext.GetError(), "not allowed", "environment variables that are not allowed should not be passed to the package")
+}
@@ -0,0 +1,64 @@
+package pkgmgmt
+
+import (
+	"runtime"
+	"strings"
+)
+
+// ExecutionPolicy restricts the environment that a package command is run
+// with, so that the package does not inherit Porter's full environment, such
+// as cloud credentials.
+type ExecutionPolicy struct {
+	// RestrictEnv only passes the allowed environment variables to the
+	// command, instead of Porter's full environment.
+	RestrictEnv bool
+
+	// AllowedEnv is the names of the environment variables that are passed to
+	// the command when RestrictEnv is set. A name ending with * matches every variable with that
+	// prefix, for example AWS_*.
+	AllowedEnv []string
+
+	// IsolateWorkingDir runs the command in an empty temporary directory,
+	// that is removed when the command completes, instead of Porter's
+	// current working directory.
+	IsolateWorkingDir bool
+}
+
+// FilterEnv returns the environment variables, formatted as NAME=VALUE, that
+// are allowed by the policy.
+func (p ExecutionPolicy) FilterEnv(env []string) []string {
+	if !p.RestrictEnv {
+		return env
+	}
+
+	filtered := make([]string, 0, len(p.AllowedEnv))
+	for _, e := range env {
+		name, _, _ := strings.Cut(e, "=")
+		if p.AllowsEnv(name) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// AllowsEnv determines if the environment variable is passed to the command.
+func (p ExecutionPolicy) AllowsEnv(name string) bool {
+	// Environment variable names are case-insensitive on Windows
+	if runtime.GOOS == "windows" {
+		name = strings.ToUpper(name)
+	}
+
+	for _, allowed := range p.AllowedEnv {
+		if runtime.GOOS == "windows" {
+			allowed = strings.ToUpper(allowed)
+		}
+		if strings.HasSuffix(allowed, "*") {
+			if strings.HasPrefix(name, strings.TrimSuffix(allowed, "*")) {
+				return true
+			}
+		} else if name == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package pkgmgmt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutionPolicy_FilterEnv(t *testing.T) {
+	env := []string{"PATH=/usr/bin", "AWS_ACCESS_KEY_ID=abc", "AWS_REGION=us-east-1", "AZURE_CLIENT_SECRET=xyz", "EMPTY="}
+
+	t.Run("unrestricted", func(t *testing.T) {
+		p := ExecutionPolicy{}
+		assert.Equal(t, env, p.FilterEnv(env))
+	})
+
+	t.Run("allowed names", func(t *testing.T) {
+		p := ExecutionPolicy{RestrictEnv: true, AllowedEnv: []string{"PATH", "AWS_REGION", "EMPTY"}}
+		assert.Equal(t, []string{"PATH=/usr/bin", "AWS_REGION=us-east-1", "EMPTY="}, p.FilterEnv(env))
+	})
+
+	t.Run("allowed prefix", func(t *testing.T) {
+		p := ExecutionPolicy{RestrictEnv: true, AllowedEnv: []string{"AWS_*"}}
+		assert.Equal(t, []string{"AWS_ACCESS_KEY_ID=abc", "AWS_REGION=us-east-1"}, p.FilterEnv(env))
+	})
+
+	t.Run("nothing allowed", func(t *testing.T) {
+		p := ExecutionPolicy{RestrictEnv: true}
+		assert.Empty(t, p.FilterEnv(env))
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -66,26 +67,116 @@ func (feed *MixinFeed) Search(mixin string, version string) *MixinFileset {
 	return nil
 }
 
+// SearchCompatible finds the requested version of a mixin, like Search, and
+// checks that it supports the specified version of Porter. When the latest
+// version is requested, the highest version that supports Porter is returned.
+// Returns nil when the feed does not include the requested version.
+func (feed *MixinFeed) SearchCompatible(mixin string, version string, porterVersion string) (*MixinFileset, error) {
+	if version != "latest" {
+		fileset := feed.Search(mixin, version)
+		if fileset == nil {
+			return nil, nil
+		}
+		return fileset, fileset.CheckPorterVersion(porterVersion)
+	}
+
+	// Try each release from the highest version, ignoring pre-releases
+	var releases []*semver.Version
+	for version := range feed.Index[mixin] {
+		v, err := semver.NewVersion(version)
+		if err != nil || v.Prerelease() != "" {
+			continue
+		}
+		releases = append(releases, v)
+	}
+	sort.Sort(sort.Reverse(semver.Collection(releases)))
+
+	var firstErr error
+	for _, v := range releases {
+		fileset := feed.Index[mixin][v.Original()]
+		err := fileset.CheckPorterVersion(porterVersion)
+		if err == nil {
+			return fileset, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	// Fallback to the latest version when it is not a semver release
+	if firstErr == nil {
+		if fileset := feed.Search(mixin, version); fileset != nil {
+			return fileset, fileset.CheckPorterVersion(porterVersion)
+		}
+	}
+	return nil, firstErr
+}
+
 type MixinFileset struct {
 	Mixin   string
 	Version string
 	Files   []*MixinFile
+
+	// PorterVersion is a semver constraint on the versions of Porter that
+	// support the mixin, for example >=1.0.0. Only set by v2 feeds.
+	PorterVersion string
+}
+
+// CheckPorterVersion returns an error when the mixin does not support the
+// specified version of Porter. Development builds of Porter, which do not
+// have a semver version, are assumed to support every mixin.
+func (f *MixinFileset) CheckPorterVersion(porterVersion string) error {
+	if f.PorterVersion == "" {
+		return nil
+	}
+
+	v, err := semver.NewVersion(porterVersion)
+	if err != nil {
+		return nil
+	}
+
+	constraint, err := semver.NewConstraint(f.PorterVersion)
+	if err != nil {
+		return fmt.Errorf("%s @ %s has an invalid Porter version constraint %q: %w", f.Mixin, f.Version, f.PorterVersion, err)
+	}
+
+	// Compare against the release, so that pre-releases of Porter satisfy the constraint
+	release, _ := v.SetPrerelease("")
+	if !constraint.Check(&release) {
+		return fmt.Errorf("%s @ %s requires Porter %s but the current version of Porter is %s", f.Mixin, f.Version, f.PorterVersion, porterVersion)
+	}
+	return nil
 }
 
 func (f *MixinFileset) FindDownloadURL(ctx context.Context, os string, arch string) *url.URL {
+	file := f.FindFile(ctx, os, arch)
+	if file == nil {
+		return nil
+	}
+	return file.URL
+}
+
+// FindFile returns the file published for the specified platform. Files from
+// a v2 feed are matched by their platform, otherwise the platform is
+// determined from the file name.
+func (f *MixinFileset) FindFile(ctx context.Context, os string, arch string) *MixinFile {
 	log := tracing.LoggerFromContext(ctx)
 
 	match := fmt.Sprintf("%s-%s-%s", f.Mixin, os, arch)
 	for _, file := range f.Files {
-		if strings.Contains(file.URL.Path, match) {
-			return file.URL
+		if file.OS != "" {
+			if file.OS == os && file.Arch == arch {
+				return file
+			}
+		} else if strings.Contains(file.URL.Path, match) {
+			return file
 		}
 	}
 
 	// Until we have full support for M1 chipsets, rely on rossetta functionality in macos and use the amd64 binary
 	if os == "darwin" && arch == "arm64" {
 		log.Debugf("%s @ %s did not publish a download for darwin/arm64, falling back to darwin/amd64", f.Mixin, f.Version)
-		return f.FindDownloadURL(ctx, "darwin", "amd64")
+		return f.FindFile(ctx, "darwin", "amd64")
 	}
 
 	return nil
@@ -109,6 +200,15 @@ type MixinFile struct {
 	File    string
 	URL     *url.URL
 	Updated time.Time
+
+	// OS of the platform that the file is built for. Only set by v2 feeds.
+	OS string
+
+	// Arch of the platform that the file is built for. Only set by v2 feeds.
+	Arch string
+
+	// SHA256 checksum of the file, hex encoded. Only set by v2 feeds.
+	SHA256 string
 }
 
 // MixinEntries is used to sort the entries in a mixin feed by when they were last updated
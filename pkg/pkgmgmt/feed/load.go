@@ -22,6 +22,11 @@ func (feed *MixinFeed) Load(ctx context.Context, file string) error {
 		return span.Error(fmt.Errorf("error reading mixin feed at %s: %w", file, err))
 	}
 
+	// v2 feeds are json documents, the original feed format is an atom xml file
+	if bytes.HasPrefix(bytes.TrimSpace(contents), []byte("{")) {
+		return span.Error(feed.loadV2(ctx, contents))
+	}
+
 	p := atom.Parser{}
 	atomFeed, err := p.Parse(bytes.NewReader(contents))
 	if err != nil {
@@ -76,17 +81,23 @@ func (feed *MixinFeed) Load(ctx context.Context, file string) error {
 				fileset.Files = append(fileset.Files, file)
 			}
 		}
-		versions, ok := feed.Index[fileset.Mixin]
-		if !ok {
-			versions = map[string]*MixinFileset{}
-			feed.Index[fileset.Mixin] = versions
-		}
-
-		indexedFileset, ok := versions[fileset.Version]
-		if !ok || fileset.GetLastUpdated().After(indexedFileset.GetLastUpdated()) {
-			versions[fileset.Version] = fileset
-		}
+		feed.index(fileset)
 	}
 
 	return nil
 }
+
+// index adds the fileset to the feed, unless a more recently updated fileset
+// for the same version is already indexed.
+func (feed *MixinFeed) index(fileset *MixinFileset) {
+	versions, ok := feed.Index[fileset.Mixin]
+	if !ok {
+		versions = map[string]*MixinFileset{}
+		feed.Index[fileset.Mixin] = versions
+	}
+
+	indexedFileset, ok := versions[fileset.Version]
+	if !ok || fileset.GetLastUpdated().After(indexedFileset.GetLastUpdated()) {
+		versions[fileset.Version] = fileset
+	}
+}
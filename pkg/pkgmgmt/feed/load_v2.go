@@ -0,0 +1,135 @@
+package feed
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/Masterminds/semver/v3"
+)
+
+// FeedV2SchemaVersion is the schemaVersion of a v2 mixin feed.
+const FeedV2SchemaVersion = "2.0.0"
+
+// FeedV2 is a json mixin feed that includes the platform and checksum of each
+// file, and the versions of Porter supported by each mixin version.
+type FeedV2 struct {
+	// SchemaVersion of the feed, always FeedV2SchemaVersion.
+	SchemaVersion string `json:"schemaVersion"`
+
+	// Updated is when the feed was last updated.
+	Updated *time.Time `json:"updated,omitempty"`
+
+	// Mixins published in the feed, one entry per mixin version.
+	Mixins []FeedV2Entry `json:"mixins"`
+}
+
+// FeedV2Entry is a version of a mixin in a v2 feed.
+type FeedV2Entry struct {
+	// Name of the mixin.
+	Name string `json:"name"`
+
+	// Version of the mixin.
+	Version string `json:"version"`
+
+	// PorterVersion is a semver constraint on the versions of Porter that
+	// support this version of the mixin, for example >=1.0.0.
+	PorterVersion string `json:"porterVersion,omitempty"`
+
+	// Updated is when this version of the mixin was published.
+	Updated time.Time `json:"updated"`
+
+	// Artifacts are the binaries of the mixin for each platform.
+	Artifacts []FeedV2Artifact `json:"artifacts"`
+}
+
+// FeedV2Artifact is the mixin binary for a platform.
+type FeedV2Artifact struct {
+	// OS of the platform, for example linux.
+	OS string `json:"os"`
+
+	// Arch of the platform, for example amd64.
+	Arch string `json:"arch"`
+
+	// URL where the binary is downloaded.
+	URL string `json:"url"`
+
+	// SHA256 checksum of the binary, hex encoded.
+	SHA256 string `json:"sha256"`
+}
+
+// loadV2 indexes the mixins in a v2 feed. Entries and artifacts that are
+// invalid are skipped, like in the atom feed.
+func (feed *MixinFeed) loadV2(ctx context.Context, contents []byte) error {
+	log := tracing.LoggerFromContext(ctx)
+
+	var v2 FeedV2
+	if err := json.Unmarshal(contents, &v2); err != nil {
+		return fmt.Errorf("error parsing the mixin feed as a json document: %w", err)
+	}
+	if v2.SchemaVersion != FeedV2SchemaVersion {
+		return fmt.Errorf("unsupported mixin feed schemaVersion %q, this version of Porter supports %s", v2.SchemaVersion, FeedV2SchemaVersion)
+	}
+
+	feed.Updated = v2.Updated
+
+	mixins := map[string]bool{}
+	for _, entry := range v2.Mixins {
+		id := fmt.Sprintf("%s @ %s", entry.Name, entry.Version)
+		if entry.Name == "" || entry.Version == "" {
+			log.Debugf("skipping invalid entry %s, missing name or version", id)
+			continue
+		}
+		if entry.PorterVersion != "" {
+			if _, err := semver.NewConstraint(entry.PorterVersion); err != nil {
+				log.Debugf("skipping invalid entry %s, invalid porterVersion constraint %q", id, entry.PorterVersion)
+				continue
+			}
+		}
+
+		fileset := &MixinFileset{
+			Mixin:         entry.Name,
+			Version:       entry.Version,
+			PorterVersion: entry.PorterVersion,
+			Files:         make([]*MixinFile, 0, len(entry.Artifacts)),
+		}
+		for _, artifact := range entry.Artifacts {
+			parsedUrl, err := url.Parse(artifact.URL)
+			if err != nil || artifact.URL == "" {
+				log.Debugf("skipping invalid artifact of %s, invalid url %q", id, artifact.URL)
+				continue
+			}
+			if artifact.OS == "" || artifact.Arch == "" {
+				log.Debugf("skipping invalid artifact %s of %s, missing os or arch", artifact.URL, id)
+				continue
+			}
+			if checksum, err := hex.DecodeString(artifact.SHA256); err != nil || len(checksum) != 32 {
+				log.Debugf("skipping invalid artifact %s of %s, invalid sha256 %q", artifact.URL, id, artifact.SHA256)
+				continue
+			}
+
+			fileset.Files = append(fileset.Files, &MixinFile{
+				File:    path.Base(parsedUrl.Path),
+				URL:     parsedUrl,
+				Updated: entry.Updated,
+				OS:      artifact.OS,
+				Arch:    artifact.Arch,
+				SHA256:  strings.ToLower(artifact.SHA256),
+			})
+		}
+
+		if !mixins[entry.Name] {
+			mixins[entry.Name] = true
+			feed.Mixins = append(feed.Mixins, entry.Name)
+		}
+		feed.index(fileset)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package feed
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/portercontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMixinFeed_LoadV2(t *testing.T) {
+	ctx := context.Background()
+	tc := portercontext.NewTestContext(t)
+	tc.AddTestFile("testdata/feed-v2.json", "/feed.json")
+
+	f := NewMixinFeed(tc.Context)
+	err := f.Load(ctx, "/feed.json")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"helm", "exec"}, f.Mixins)
+	require.NotNil(t, f.Updated)
+	assert.Len(t, f.Index["helm"], 3)
+	assert.Len(t, f.Index["exec"], 1, "entries without a version should be skipped")
+
+	helm := f.Search("helm", "v1.2.4")
+	require.NotNil(t, helm)
+	assert.Equal(t, ">=1.0.0", helm.PorterVersion)
+	assert.Len(t, helm.Files, 5, "artifacts with an invalid checksum should be skipped")
+
+	file := helm.FindFile(ctx, "windows", "amd64")
+	require.NotNil(t, file)
+	assert.Equal(t, "helm-windows-amd64.exe", file.File)
+	assert.Equal(t, "windows", file.OS)
+	assert.Equal(t, "amd64", file.Arch)
+	assert.Equal(t, "a78666207d4d1601f4b046a7e9e6ba0f0d747e67becb40962291d6bbe27ddadf", file.SHA256)
+
+	assert.Nil(t, helm.FindFile(ctx, "plan9", "amd64"))
+
+	t.Run("unsupported schema version", func(t *testing.T) {
+		tc.FileSystem.WriteFile("/feed-v3.json", []byte(`{"schemaVersion": "3.0.0"}`), 0600)
+		err := NewMixinFeed(tc.Context).Load(ctx, "/feed-v3.json")
+		require.EqualError(t, err, `unsupported mixin feed schemaVersion "3.0.0", this version of Porter supports 2.0.0`)
+	})
+}
+
+func TestMixinFeed_SearchCompatible(t *testing.T) {
+	tc := portercontext.NewTestContext(t)
+	tc.AddTestFile("testdata/feed-v2.json", "/feed.json")
+	f := NewMixinFeed(tc.Context)
+	require.NoError(t, f.Load(context.Background(), "/feed.json"))
+
+	testcases := []struct {
+		name          string
+		mixin         string
+		version       string
+		porterVersion string
+		wantVersion   string
+		wantErr       string
+	}{
+		{name: "latest", mixin: "helm", version: "latest", porterVersion: "v2.1.0", wantVersion: "v1.3.0"},
+		{name: "latest compatible", mixin: "helm", version: "latest", porterVersion: "v1.1.0", wantVersion: "v1.2.4"},
+		{name: "porter pre-release", mixin: "helm", version: "latest", porterVersion: "v2.0.0-beta.1", wantVersion: "v1.3.0"},
+		{name: "development build", mixin: "helm", version: "latest", porterVersion: "", wantVersion: "v1.3.0"},
+		{name: "no compatible version", mixin: "helm", version: "latest", porterVersion: "v0.38.0", wantErr: "helm @ v1.3.0 requires Porter >=2.0.0 but the current version of Porter is v0.38.0"},
+		{name: "incompatible version", mixin: "helm", version: "v1.3.0", porterVersion: "v1.1.0", wantErr: "helm @ v1.3.0 requires Porter >=2.0.0"},
+		{name: "pre-release", mixin: "helm", version: "v1.4.0-beta.1", porterVersion: "v1.1.0", wantVersion: "v1.4.0-beta.1"},
+		{name: "not found", mixin: "helm", version: "v9.0.0", porterVersion: "v1.1.0"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := f.SearchCompatible(tc.mixin, tc.version, tc.porterVersion)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tc.wantVersion == "" {
+				assert.Nil(t, result)
+			} else {
+				require.NotNil(t, result)
+				assert.Equal(t, tc.wantVersion, result.Version)
+			}
+		})
+	}
+}
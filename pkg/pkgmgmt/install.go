@@ -6,6 +6,8 @@ import (
 	"net/url"
 	"path"
 	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
 )
 
 type InstallOptions struct {
@@ -18,6 +20,15 @@ type InstallOptions struct {
 	parsedURL     *url.URL
 	parsedFeedURL *url.URL
 
+	// Reference to the package published as an OCI artifact, for example
+	// example.com/mixins/helm3:v1.0.0. Pin the package to a specific version
+	// with a digested reference, such as example.com/mixins/helm3@sha256:...
+	Reference string
+
+	// InsecureRegistry allows pulling the package from an unsecured registry
+	// or one without verifiable certificates.
+	InsecureRegistry bool
+
 	PackageType string
 }
 
@@ -59,6 +70,11 @@ func (o *InstallOptions) Validate(args []string) error {
 		return err
 	}
 
+	err = o.validateReference()
+	if err != nil {
+		return err
+	}
+
 	err = o.validateFeedURL()
 	if err != nil {
 		return err
@@ -88,8 +104,24 @@ func (o *InstallOptions) validateURL() error {
 	return nil
 }
 
+func (o *InstallOptions) validateReference() error {
+	if o.Reference == "" {
+		return nil
+	}
+
+	if o.URL != "" || o.FeedURL != "" {
+		return errors.New("--reference cannot be used with --url or --feed-url")
+	}
+
+	if _, err := name.ParseReference(o.Reference); err != nil {
+		return fmt.Errorf("invalid --reference %s: %w", o.Reference, err)
+	}
+
+	return nil
+}
+
 func (o *InstallOptions) validateFeedURL() error {
-	if o.URL == "" && o.FeedURL == "" {
+	if o.URL == "" && o.FeedURL == "" && o.Reference == "" {
 		feedURL := o.defaultFeedURL()
 		o.FeedURL = feedURL.String()
 	}
@@ -129,6 +129,35 @@ func TestInstallOptions_ValidateURL(t *testing.T) {
 	})
 }
 
+func TestInstallOptions_ValidateReference(t *testing.T) {
+	t.Run("reference specified", func(t *testing.T) {
+		opts := InstallOptions{
+			PackageType: "mixin",
+			Reference:   "example.com/mixins/mymixin:v1.0.0",
+		}
+		err := opts.Validate([]string{"mymixin"})
+		require.NoError(t, err)
+		assert.Empty(t, opts.FeedURL, "the default feed should not be used when a reference is specified")
+	})
+	t.Run("reference and url specified", func(t *testing.T) {
+		opts := InstallOptions{
+			PackageType: "mixin",
+			Reference:   "example.com/mixins/mymixin:v1.0.0",
+			FeedURL:     "https://example.com/atom.xml",
+		}
+		err := opts.Validate([]string{"mymixin"})
+		require.EqualError(t, err, "--reference cannot be used with --url or --feed-url")
+	})
+	t.Run("invalid reference specified", func(t *testing.T) {
+		opts := InstallOptions{
+			PackageType: "mixin",
+			Reference:   "example.com/MIXINS/mymixin:v1.0.0",
+		}
+		err := opts.Validate([]string{"mymixin"})
+		require.ErrorContains(t, err, "invalid --reference example.com/MIXINS/mymixin:v1.0.0")
+	})
+}
+
 func TestInstallOptions_Validate(t *testing.T) {
 	t.Run("mixin", func(t *testing.T) {
 		opts := InstallOptions{
@@ -41,6 +41,11 @@ type CommandOptions struct {
 	// This is only necessary if being called directly from a runner, if
 	// using a PackageManager, this is set for you.
 	PreRun PreRunHandler
+
+	// Policy restricts the environment variables and working directory of
+	// the command. When nil, the command inherits Porter's environment and
+	// working directory. If using a PackageManager, this is set for you.
+	Policy *ExecutionPolicy
 }
 
 // GetPackageListURL returns the URL for package listings of the provided type.
@@ -35,4 +35,9 @@ type PluginTypeConfig struct {
 
 	// ProtocolVersion is the version of the protocol used by this plugin.
 	ProtocolVersion uint
+
+	// CompatibleVersions are older protocol versions that porter can negotiate
+	// when the plugin does not support ProtocolVersion, and the Plugin used to
+	// communicate with the plugin over that version.
+	CompatibleVersions map[uint]plugin.Plugin
 }
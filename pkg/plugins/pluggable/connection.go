@@ -141,10 +141,12 @@ func (c *PluginConnection) Start(ctx context.Context, pluginCfg io.Reader) error
 		Plugins: map[string]plugin.Plugin{
 			c.pluginType.Interface: c.pluginType.Plugin,
 		},
-		Cmd:          c.pluginCmd,
-		Logger:       logger,
-		Stderr:       &errbuf,
-		StartTimeout: getPluginStartTimeout(),
+		// Fall back to an older protocol version when the plugin does not support the current version
+		VersionedPlugins: c.getCompatiblePlugins(),
+		Cmd:              c.pluginCmd,
+		Logger:           logger,
+		Stderr:           &errbuf,
+		StartTimeout:     getPluginStartTimeout(),
 		// Configure gRPC to propagate the span context so the plugin's traces
 		// show up under the current span
 		GRPCDialOptions: []grpc.DialOption{
@@ -186,6 +188,20 @@ func (c *PluginConnection) Start(ctx context.Context, pluginCfg io.Reader) error
 	return nil
 }
 
+// getCompatiblePlugins returns the plugins to use for each of the older protocol
+// versions that are compatible with the plugin type.
+func (c *PluginConnection) getCompatiblePlugins() map[int]plugin.PluginSet {
+	if len(c.pluginType.CompatibleVersions) == 0 {
+		return nil
+	}
+
+	versions := make(map[int]plugin.PluginSet, len(c.pluginType.CompatibleVersions))
+	for version, p := range c.pluginType.CompatibleVersions {
+		versions[int(version)] = plugin.PluginSet{c.pluginType.Interface: p}
+	}
+	return versions
+}
+
 // GetClient returns the raw connection to the pluginProtocol.
 // This value should be cast to the plugin protocol interface,
 // such as plugins.StorageProtocol or plugins.SecretsProtocol.
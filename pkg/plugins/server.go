@@ -6,6 +6,7 @@ import (
 
 	"get.porter.sh/porter/pkg/portercontext"
 	"get.porter.sh/porter/pkg/tracing"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
 	"github.com/hashicorp/go-plugin"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
@@ -14,11 +15,15 @@ import (
 	"google.golang.org/grpc"
 )
 
-// Serve a single named plugin.
-func Serve(c *portercontext.Context, interfaceName string, pluginImplementation plugin.Plugin, version int) {
+// Serve a single named plugin. The plugin is also served over any compatible
+// older protocol versions, so that it can be used by older versions of porter.
+func Serve(c *portercontext.Context, interfaceName string, pluginImplementation plugin.Plugin, version int, compatibleVersions ...int) {
 	pluginMap := map[int]plugin.PluginSet{
 		version: {interfaceName: pluginImplementation},
 	}
+	for _, v := range compatibleVersions {
+		pluginMap[v] = plugin.PluginSet{interfaceName: pluginImplementation}
+	}
 	ServeMany(c, pluginMap)
 }
 
@@ -36,6 +41,10 @@ func ServeMany(c *portercontext.Context, pluginMap map[int]plugin.PluginSet) {
 					otelgrpc.UnaryServerInterceptor(),
 					makeLogUnaryHandler(c),
 					makePanicHandler()),
+				grpc.ChainStreamInterceptor(
+					otelgrpc.StreamServerInterceptor(),
+					makeLogStreamHandler(c),
+					makeStreamPanicHandler()),
 			)
 			return grpc.NewServer(opts...)
 		},
@@ -56,6 +65,21 @@ func makeLogUnaryHandler(c *portercontext.Context) func(ctx context.Context, req
 	}
 }
 
+// makeLogStreamHandler creates a span for each streaming RPC method called
+func makeLogStreamHandler(c *portercontext.Context) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		bags := baggage.FromContext(ctx)
+		ctx, rootLog := c.StartRootSpan(ctx, info.FullMethod, attribute.String("baggage", bags.String()))
+		defer func() {
+			rootLog.EndSpan()
+		}()
+
+		err := handler(srv, &grpc_middleware.WrappedServerStream{ServerStream: ss, WrappedContext: ctx})
+		return rootLog.Error(err)
+	}
+}
+
 // makePanicHandler recovers from a panic, logs the error and returns it
 func makePanicHandler() grpc.UnaryServerInterceptor {
 	recoveryOpts := grpc_recovery.WithRecoveryHandlerContext(func(ctx context.Context, p interface{}) (err error) {
@@ -64,3 +88,12 @@ func makePanicHandler() grpc.UnaryServerInterceptor {
 	})
 	return grpc_recovery.UnaryServerInterceptor(recoveryOpts)
 }
+
+// makeStreamPanicHandler recovers from a panic in a streaming RPC, logs the error and returns it
+func makeStreamPanicHandler() grpc.StreamServerInterceptor {
+	recoveryOpts := grpc_recovery.WithRecoveryHandlerContext(func(ctx context.Context, p interface{}) (err error) {
+		rootLog := tracing.LoggerFromContext(ctx)
+		return rootLog.Error(fmt.Errorf("%s", p))
+	})
+	return grpc_recovery.StreamServerInterceptor(recoveryOpts)
+}
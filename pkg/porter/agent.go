@@ -0,0 +1,241 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/hashicorp/go-multierror"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultAgentInterval is how often the agent checks for schedules that are due.
+const DefaultAgentInterval = time.Minute
+
+// scheduleLockTTL is how long the lock on a schedule is held without being
+// renewed. The lock is renewed while the schedule runs, so the ttl only
+// determines how long a schedule stays locked when an agent exits without
+// releasing it.
+var scheduleLockTTL = 2 * time.Minute
+
+// AgentOptions represent options for Porter's agent command.
+type AgentOptions struct {
+	// Interval between checks for schedules that are due.
+	Interval time.Duration
+
+	// Once runs the schedules that are due and then exits.
+	Once bool
+
+	// Namespace limits the agent to the schedules in a single namespace.
+	// Defaults to all namespaces.
+	Namespace string
+}
+
+// Validate the options provided to Porter's agent command.
+func (o *AgentOptions) Validate() error {
+	if o.Interval <= 0 {
+		return fmt.Errorf("invalid --interval %s, the interval must be greater than zero", o.Interval)
+	}
+	if o.Namespace == "" {
+		o.Namespace = "*"
+	}
+	return nil
+}
+
+// RunAgent runs the actions of the schedules that are due, checking again each
+// interval until the context is cancelled.
+func (p *Porter) RunAgent(ctx context.Context, opts AgentOptions) error {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	if opts.Once {
+		return p.RunDueSchedules(ctx, opts.Namespace, time.Now())
+	}
+
+	log.Infof("Checking for schedules that are due every %s", opts.Interval)
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	for {
+		if err := p.RunDueSchedules(ctx, opts.Namespace, time.Now()); err != nil {
+			// Keep the agent running when the datastore is temporarily unreachable
+			log.Warnf("could not run the schedules that are due: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDueSchedules runs the action of each schedule in the namespace that is
+// due at the specified time. A schedule that was due more than once since the
+// last check is only run once. Failed actions are recorded on the schedule
+// and do not stop the remaining schedules from running. Each schedule is locked
+// while it runs, so that a schedule is only run by one agent at a time.
+func (p *Porter) RunDueSchedules(ctx context.Context, namespace string, now time.Time) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	schedules, err := p.Schedules.ListSchedules(ctx, storage.ListOptions{Namespace: namespace})
+	if err != nil {
+		return span.Error(fmt.Errorf("could not list schedules: %w", err))
+	}
+
+	var errs *multierror.Error
+	for _, schedule := range schedules {
+		if !schedule.IsDue(now) {
+			continue
+		}
+
+		if err := p.runDueSchedule(ctx, schedule, now); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return span.Error(errs.ErrorOrNil())
+}
+
+// runDueSchedule locks the schedule, then runs its action and records the
+// outcome. A schedule that is locked by another agent is skipped.
+func (p *Porter) runDueSchedule(ctx context.Context, schedule storage.Schedule, now time.Time) error {
+	log := tracing.LoggerFromContext(ctx)
+
+	unlock, err := p.lockSchedule(ctx, schedule)
+	if errors.Is(err, storage.ErrScheduleLocked) {
+		log.Debugf("Skipping schedule %s: %s", schedule, err)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Another agent may have run the schedule after it was listed and before
+	// the lock was acquired.
+	current, err := p.Schedules.GetSchedule(ctx, schedule.Namespace, schedule.Name)
+	if err != nil {
+		return fmt.Errorf("could not retrieve schedule %s: %w", schedule, err)
+	}
+	if !current.IsDue(now) {
+		return nil
+	}
+
+	runErr := p.runSchedule(ctx, current)
+	return p.recordScheduleRun(ctx, current, now, runErr)
+}
+
+// runSchedule runs the scheduled action on the installation.
+func (p *Porter) runSchedule(ctx context.Context, schedule storage.Schedule) error {
+	ctx, log := tracing.StartSpan(ctx,
+		attribute.String("namespace", schedule.Namespace),
+		attribute.String("schedule", schedule.Name),
+		attribute.String("installation", schedule.Installation),
+		attribute.String("action", schedule.Action))
+	defer log.EndSpan()
+
+	log.Infof("Running the %s action on installation %s/%s for schedule %s", schedule.Action, schedule.Namespace, schedule.Installation, schedule.Name)
+
+	installation, err := p.Installations.GetInstallation(ctx, schedule.Namespace, schedule.Installation)
+	if err != nil {
+		return log.Error(fmt.Errorf("could not retrieve installation %s/%s: %w", schedule.Namespace, schedule.Installation, err))
+	}
+
+	ref, ok, err := installation.Bundle.GetBundleReference()
+	if err != nil {
+		return log.Error(err)
+	}
+	if !ok {
+		return log.Error(fmt.Errorf("installation %s does not define a valid bundle reference", installation))
+	}
+
+	opts := NewInvokeOptions()
+	opts.Action = schedule.Action
+	opts.Name = schedule.Installation
+	opts.Namespace = schedule.Namespace
+	opts.Reference = ref.String()
+	opts.Params = schedule.Parameters
+	opts.ParameterSets = schedule.ParameterSets
+	opts.CredentialIdentifiers = schedule.CredentialSets
+	if err = opts.Validate(ctx, nil, p); err != nil {
+		return log.Error(err)
+	}
+
+	return log.Error(p.InvokeBundle(ctx, opts))
+}
+
+// recordScheduleRun saves the outcome of running a schedule, and when it runs next.
+func (p *Porter) recordScheduleRun(ctx context.Context, schedule storage.Schedule, now time.Time, runErr error) error {
+	schedule.Status.LastRun = &now
+	schedule.Status.LastError = ""
+	if runErr != nil {
+		schedule.Status.LastError = runErr.Error()
+		fmt.Fprintf(p.Err, "warning: schedule %s failed: %s\n", schedule, runErr)
+	}
+
+	lastRun, err := p.Installations.GetLastRun(ctx, schedule.Namespace, schedule.Installation)
+	if err != nil && !errors.Is(err, storage.ErrNotFound{}) {
+		return fmt.Errorf("could not retrieve the last run of installation %s/%s: %w", schedule.Namespace, schedule.Installation, err)
+	}
+	if err == nil && lastRun.Action == schedule.Action && !lastRun.Created.Before(now) {
+		schedule.Status.LastRunID = lastRun.ID
+	}
+
+	if err = schedule.ScheduleNext(now); err != nil {
+		return err
+	}
+	schedule.Status.Modified = now
+	if err = p.Schedules.UpdateSchedule(ctx, schedule); err != nil {
+		return fmt.Errorf("unable to save schedule %s: %w", schedule, err)
+	}
+	return nil
+}
+
+// lockSchedule acquires the lock on a schedule, which is renewed in the
+// background while the schedule runs. The returned function releases the lock.
+func (p *Porter) lockSchedule(ctx context.Context, schedule storage.Schedule) (func(), error) {
+	log := tracing.LoggerFromContext(ctx)
+
+	lock, err := p.Schedules.AcquireScheduleLock(ctx, schedule.Namespace, schedule.Name, p.installationLockOwner(), scheduleLockTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(scheduleLockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				renewed, err := p.Schedules.RenewScheduleLock(ctx, lock, scheduleLockTTL)
+				if errors.Is(err, storage.ErrScheduleLockLost) {
+					log.Warnf("The lock on schedule %s was lost, another agent may run the schedule at the same time", lock)
+					return
+				} else if err != nil {
+					log.Warnf("Could not renew the lock on schedule %s: %s", lock, err)
+					continue
+				}
+				lock = renewed
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+
+		if err := p.Schedules.ReleaseScheduleLock(ctx, lock); err != nil {
+			log.Warnf("Could not release the lock on schedule %s, it expires at %s: %s", lock, lock.Expires.Format(time.RFC3339), err)
+		}
+	}, nil
+}
@@ -0,0 +1,116 @@
+package porter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentOptions_Validate(t *testing.T) {
+	opts := AgentOptions{Interval: DefaultAgentInterval}
+	require.NoError(t, opts.Validate())
+	assert.Equal(t, "*", opts.Namespace, "the agent should default to all namespaces")
+
+	opts = AgentOptions{}
+	require.ErrorContains(t, opts.Validate(), "invalid --interval 0s")
+}
+
+func TestPorter_RunDueSchedules(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	now := time.Date(2026, time.March, 4, 10, 0, 0, 0, time.UTC)
+	createSchedule := func(name string, installation string, nextRun time.Time, disabled bool) {
+		schedule := storage.NewSchedule("dev", name)
+		schedule.Installation = installation
+		schedule.Action = "backup"
+		schedule.Cron = "@hourly"
+		schedule.Disabled = disabled
+		schedule.Status.NextRun = nextRun
+		require.NoError(t, p.Schedules.InsertSchedule(ctx, schedule))
+	}
+	// The installation does not reference a bundle, so the action fails
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+	createSchedule("due", "mysql", now.Add(-time.Minute), false)
+	createSchedule("missed", "missing", now.Add(-3*time.Hour), false)
+	createSchedule("not-due", "mysql", now.Add(time.Minute), false)
+	createSchedule("disabled", "mysql", now.Add(-time.Minute), true)
+	createSchedule("locked", "mysql", now.Add(-time.Minute), false)
+
+	// Another agent is running the locked schedule
+	lock, err := p.Schedules.AcquireScheduleLock(ctx, "dev", "locked", "alice", time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, p.RunDueSchedules(ctx, "*", now))
+
+	due, err := p.Schedules.GetSchedule(ctx, "dev", "due")
+	require.NoError(t, err)
+	require.NotNil(t, due.Status.LastRun)
+	assert.Equal(t, now, due.Status.LastRun.UTC())
+	assert.Contains(t, due.Status.LastError, "does not define a valid bundle reference")
+	assert.Equal(t, now.Add(time.Hour), due.Status.NextRun.UTC())
+
+	missed, err := p.Schedules.GetSchedule(ctx, "dev", "missed")
+	require.NoError(t, err)
+	require.NotNil(t, missed.Status.LastRun, "a failed schedule should not stop the remaining schedules")
+	assert.Contains(t, missed.Status.LastError, "could not retrieve installation dev/missing")
+	assert.Equal(t, now.Add(time.Hour), missed.Status.NextRun.UTC(), "a schedule that was missed more than once should only run once")
+
+	for _, name := range []string{"not-due", "disabled", "locked"} {
+		schedule, err := p.Schedules.GetSchedule(ctx, "dev", name)
+		require.NoError(t, err)
+		assert.Nil(t, schedule.Status.LastRun, "schedule %s should not have been run", name)
+	}
+
+	assert.Contains(t, p.TestConfig.TestContext.GetError(), "warning: schedule dev/due failed")
+
+	t.Run("locks are released", func(t *testing.T) {
+		_, err := p.Schedules.AcquireScheduleLock(ctx, "dev", "due", "bob", time.Minute)
+		require.NoError(t, err, "the lock on a schedule should be released after it runs")
+	})
+
+	t.Run("run after the lock is released", func(t *testing.T) {
+		require.NoError(t, p.Schedules.ReleaseScheduleLock(ctx, lock))
+		require.NoError(t, p.RunDueSchedules(ctx, "dev", now))
+
+		locked, err := p.Schedules.GetSchedule(ctx, "dev", "locked")
+		require.NoError(t, err)
+		require.NotNil(t, locked.Status.LastRun)
+
+		due, err := p.Schedules.GetSchedule(ctx, "dev", "due")
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(time.Hour), due.Status.NextRun.UTC(), "a schedule that already ran should not run again")
+	})
+}
+
+func TestPorter_RunDueSchedules_RecordError(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	now := time.Date(2026, time.March, 4, 10, 0, 0, 0, time.UTC)
+	for _, name := range []string{"invalid", "valid"} {
+		schedule := storage.NewSchedule("dev", name)
+		schedule.Installation = "mysql"
+		schedule.Action = "backup"
+		schedule.Cron = "@hourly"
+		schedule.Status.NextRun = now.Add(-time.Minute)
+		if name == "invalid" {
+			// The next run cannot be scheduled, so the run is not recorded
+			schedule.Cron = "not a cron expression"
+		}
+		require.NoError(t, p.Schedules.InsertSchedule(ctx, schedule))
+	}
+
+	err := p.RunDueSchedules(ctx, "dev", now)
+	require.Error(t, err, "the error recording the invalid schedule should be returned")
+
+	valid, err := p.Schedules.GetSchedule(ctx, "dev", "valid")
+	require.NoError(t, err)
+	require.NotNil(t, valid.Status.LastRun, "an error recording a schedule should not stop the remaining schedules")
+}
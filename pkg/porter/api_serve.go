@@ -0,0 +1,334 @@
+package porter
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultAPIListenAddress is the address that porter api serve listens on
+// when an address is not specified.
+const DefaultAPIListenAddress = "127.0.0.1:8080"
+
+// maxAPIRequestSize is the largest request body accepted by the Porter API, so
+// that a client cannot exhaust the memory of the server.
+const maxAPIRequestSize = 1 << 20
+
+// APIServeOptions are the options of porter api serve.
+type APIServeOptions struct {
+	// Listen is the address that the server listens on, for example :8080.
+	Listen string
+
+	// Token that clients must present as a bearer token.
+	Token string
+
+	// TLSCert is the path to the certificate used to serve https.
+	TLSCert string
+
+	// TLSKey is the path to the private key of the certificate.
+	TLSKey string
+}
+
+func (o *APIServeOptions) Validate(p *Porter) error {
+	if o.Token == "" {
+		o.Token = p.Data.APIToken
+	}
+	if o.Token == "" {
+		return errors.New("--token is required, clients authenticate with it using --remote-token. Set it with the PORTER_API_TOKEN environment variable or the api-token config file setting to keep it out of the process list")
+	}
+	if (o.TLSCert == "") != (o.TLSKey == "") {
+		return errors.New("--tls-cert and --tls-key must be specified together")
+	}
+	if o.Listen == "" {
+		o.Listen = DefaultAPIListenAddress
+	}
+	return nil
+}
+
+// ServeAPI runs bundles that are submitted by porter commands with --remote,
+// until the context is canceled.
+func (p *Porter) ServeAPI(ctx context.Context, opts APIServeOptions) error {
+	log := tracing.LoggerFromContext(ctx)
+
+	listener, err := net.Listen("tcp", opts.Listen)
+	if err != nil {
+		return log.Error(fmt.Errorf("could not listen on %s: %w", opts.Listen, err))
+	}
+
+	p.startMetricsListener(ctx)
+
+	server := &http.Server{
+		Handler:           p.NewAPIHandler(ctx, opts.Token),
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	scheme := "http"
+	if opts.TLSCert != "" {
+		scheme = "https"
+	}
+	log.Infof("Serving the Porter API at %s://%s", scheme, listener.Addr())
+
+	if opts.TLSCert != "" {
+		err = server.ServeTLS(listener, opts.TLSCert, opts.TLSKey)
+	} else {
+		err = server.Serve(listener)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return log.Error(err)
+}
+
+// apiHandler serves the Porter API.
+type apiHandler struct {
+	porter *Porter
+	ctx    context.Context
+	token  string
+
+	// mu ensures that one bundle is run at a time, because the output of the
+	// run is written to the Porter's output.
+	mu sync.Mutex
+}
+
+// NewAPIHandler creates the handler of the Porter API. Requests must
+// authenticate with the token. The context is used to run the bundles, so
+// that a run is not stopped when the client disconnects. Storage requests
+// made for clients are authorized as the api-subject, rather than as the
+// user running the server, because every client presents the same token.
+func (p *Porter) NewAPIHandler(ctx context.Context, token string) http.Handler {
+	ctx = storage.ContextWithSubject(ctx, p.Data.Authorization.GetAPISubject())
+	h := &apiHandler{porter: p, ctx: ctx, token: token}
+	mux := http.NewServeMux()
+	mux.HandleFunc(RemoteRunsPath, h.authorize(h.handleRun))
+	mux.HandleFunc(RegistryWebhookPath, h.handleRegistryWebhook)
+	return mux
+}
+
+// authorize rejects requests that do not present the token.
+func (h *apiHandler) authorize(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !h.hasToken(r) {
+			http.Error(w, "invalid or missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// hasToken determines if the request presents the token as a bearer token.
+func (h *apiHandler) hasToken(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
+
+// hasSignature determines if the body of the request is signed with the
+// token, using the X-Hub-Signature-256 header sent by GitHub webhooks.
+func (h *apiHandler) hasSignature(r *http.Request, body []byte) bool {
+	signature := r.Header.Get("X-Hub-Signature-256")
+	if signature == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(h.token))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(signature), []byte(expected))
+}
+
+// handleRun runs a bundle and streams the events of the run to the client as
+// newline delimited json.
+func (h *apiHandler) handleRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req RemoteRunRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxAPIRequestSize)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid run request: %s", err), requestErrorStatus(err))
+		return
+	}
+
+	ctx, log := tracing.StartSpan(h.ctx,
+		attribute.String("command", req.Command),
+		attribute.String("installation", req.Namespace+"/"+req.Installation))
+	defer log.EndSpan()
+
+	action, err := req.NewAction()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err = action.Validate(ctx, nil, h.porter); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Infof("Running the %s of %s/%s for a remote client", req.Command, req.Namespace, req.Installation)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	out := newFlushWriter(w)
+
+	// The events of the run are written to the Porter's output
+	origOut := h.porter.Out
+	h.porter.Out = out
+	defer func() { h.porter.Out = origOut }()
+
+	if err = h.porter.runRemoteAction(ctx, action); err != nil {
+		log.Errorf("The remote %s of %s/%s failed: %w", req.Command, req.Namespace, req.Installation, err)
+		event := storage.RunEvent{
+			Type:         storage.RunEventError,
+			Time:         time.Now(),
+			Namespace:    req.Namespace,
+			Installation: req.Installation,
+			Action:       action.GetAction(),
+			Error:        err.Error(),
+		}
+		if data, err := json.Marshal(event); err == nil {
+			out.Write(append(data, '\n'))
+		}
+	}
+}
+
+// requestErrorStatus returns the status code for an error reading the body of
+// a request.
+func requestErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// RegistryWebhookResponse lists the upgrades started by a registry webhook.
+type RegistryWebhookResponse struct {
+	// Upgrades are the installations that are upgraded by auto-upgrade rules.
+	Upgrades []AutoUpgrade `json:"upgrades"`
+}
+
+// handleRegistryWebhook triggers the auto-upgrade rules that match the tags
+// pushed to a registry. The registry must present the token as a bearer
+// token, or sign the payload with it like a GitHub webhook. The upgrades are
+// run after the response is sent, so that the registry is not left waiting.
+func (h *apiHandler) handleRegistryWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAPIRequestSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not read the webhook payload: %s", err), requestErrorStatus(err))
+		return
+	}
+	if !h.hasToken(r) && !h.hasSignature(r, body) {
+		http.Error(w, "invalid or missing bearer token or signature", http.StatusUnauthorized)
+		return
+	}
+
+	ctx, log := tracing.StartSpan(h.ctx)
+	defer log.EndSpan()
+
+	events, err := ParseRegistryPushEvents(r.Header, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	received := time.Now()
+	resp := RegistryWebhookResponse{Upgrades: []AutoUpgrade{}}
+	for _, event := range events {
+		upgrades, err := h.porter.FindAutoUpgrades(ctx, event, received)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		resp.Upgrades = append(resp.Upgrades, upgrades...)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(resp)
+
+	if len(resp.Upgrades) > 0 {
+		go h.runAutoUpgrades(resp.Upgrades)
+	}
+}
+
+// runAutoUpgrades runs the upgrades one at a time, continuing when an
+// upgrade fails.
+func (h *apiHandler) runAutoUpgrades(upgrades []AutoUpgrade) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, upgrade := range upgrades {
+		if err := h.porter.RunAutoUpgrade(h.ctx, upgrade); err != nil {
+			fmt.Fprintf(h.porter.Err, "warning: auto-upgrade of %s/%s to %s failed: %s\n", upgrade.Namespace, upgrade.Installation, upgrade.Reference, err)
+		}
+	}
+}
+
+// runRemoteAction runs the validated options of a remote run request.
+func (p *Porter) runRemoteAction(ctx context.Context, action BundleAction) error {
+	switch opts := action.(type) {
+	case InstallOptions:
+		return p.InstallBundle(ctx, opts)
+	case *UpgradeOptions:
+		return p.UpgradeBundle(ctx, opts)
+	case InvokeOptions:
+		return p.InvokeBundle(ctx, opts)
+	case UninstallOptions:
+		return p.UninstallBundle(ctx, opts)
+	default:
+		return fmt.Errorf("unsupported bundle action %T", action)
+	}
+}
+
+// flushWriter sends each write to the client immediately, so that the events
+// of a run are streamed as they happen.
+type flushWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newFlushWriter(w io.Writer) *flushWriter {
+	return &flushWriter{w: w}
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n, err := f.w.Write(p)
+	if flusher, ok := f.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return n, err
+}
@@ -4,17 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"get.porter.sh/porter/pkg/encoding"
 	"get.porter.sh/porter/pkg/portercontext"
 	"get.porter.sh/porter/pkg/printer"
 	"get.porter.sh/porter/pkg/storage"
 	"get.porter.sh/porter/pkg/tracing"
+	"github.com/hashicorp/go-multierror"
 	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap/zapcore"
 )
 
 type ApplyOptions struct {
+	printer.PrintOptions
+
 	Namespace string
 	File      string
 
@@ -27,7 +31,7 @@ type ApplyOptions struct {
 
 const ApplyDefaultFormat = printer.FormatPlaintext
 
-var ApplyAllowedFormats = printer.Formats{printer.FormatPlaintext, printer.FormatYaml, printer.FormatJson}
+var ApplyAllowedFormats = printer.Formats{printer.FormatPlaintext, printer.FormatNdjson}
 
 func (o *ApplyOptions) Validate(cxt *portercontext.Context, args []string) error {
 	switch len(args) {
@@ -47,7 +51,7 @@ func (o *ApplyOptions) Validate(cxt *portercontext.Context, args []string) error
 		return fmt.Errorf("invalid file argument %s, must be a file not a directory", o.File)
 	}
 
-	return nil
+	return o.PrintOptions.Validate(ApplyDefaultFormat, ApplyAllowedFormats)
 }
 
 func (p *Porter) InstallationApply(ctx context.Context, opts ApplyOptions) error {
@@ -56,27 +60,44 @@ func (p *Porter) InstallationApply(ctx context.Context, opts ApplyOptions) error
 
 	log.Debugf("Reading input file %s", opts.File)
 
-	namespace, err := p.getNamespaceFromFile(opts)
-	if err != nil {
-		return err
-	}
-
 	if log.ShouldLog(zapcore.DebugLevel) {
 		// ignoring any error here, printing debug info isn't critical
 		contents, _ := p.FileSystem.ReadFile(opts.File)
 		log.Debug("read input file", attribute.String("contents", string(contents)))
 	}
 
-	var input DisplayInstallation
-	if err := encoding.UnmarshalFile(p.FileSystem, opts.File, &input); err != nil {
-		return fmt.Errorf("unable to parse %s as an installation document: %w", opts.File, err)
-	}
-	input.Namespace = namespace
-	inputInstallation, err := input.ConvertToInstallation()
+	// Validate every installation before any are applied
+	var inputs []storage.Installation
+	docs, err := p.readApplyDocuments(ctx, opts, "Installation", func(doc applyDocument) error {
+		var input DisplayInstallation
+		if err := doc.Unmarshal(&input); err != nil {
+			return fmt.Errorf("unable to parse %s as an installation document: %w", opts.File, err)
+		}
+		input.Namespace = doc.Namespace
+		inputInstallation, err := input.ConvertToInstallation()
+		if err != nil {
+			return err
+		}
+		inputs = append(inputs, inputInstallation)
+		return nil
+	})
 	if err != nil {
-		return err
+		return log.Error(err)
 	}
 
+	for i, input := range inputs {
+		if err := p.applyInstallation(ctx, opts, input); err != nil {
+			return log.Error(docs[i].wrapError(err))
+		}
+	}
+	return nil
+}
+
+// applyInstallation creates or updates an installation to match the input,
+// and then reconciles the installation.
+func (p *Porter) applyInstallation(ctx context.Context, opts ApplyOptions, inputInstallation storage.Installation) error {
+	log := tracing.LoggerFromContext(ctx)
+
 	installation, err := p.Installations.GetInstallation(ctx, inputInstallation.Namespace, inputInstallation.Name)
 	if err != nil {
 		if !errors.Is(err, storage.ErrNotFound{}) {
@@ -84,8 +105,11 @@ func (p *Porter) InstallationApply(ctx context.Context, opts ApplyOptions) error
 		}
 
 		// Create a new installation
-		installation = storage.NewInstallation(input.Namespace, input.Name)
+		installation = storage.NewInstallation(inputInstallation.Namespace, inputInstallation.Name)
 		installation.Apply(inputInstallation.InstallationSpec)
+		if err = p.applyNamespaceDefaults(ctx, &installation); err != nil {
+			return err
+		}
 
 		log.Info("Creating a new installation", attribute.String("installation", installation.String()))
 	} else {
@@ -99,11 +123,104 @@ func (p *Porter) InstallationApply(ctx context.Context, opts ApplyOptions) error
 	}
 
 	reconcileOpts := ReconcileOptions{
-		Namespace:    input.Namespace,
-		Name:         input.Name,
+		Namespace:    inputInstallation.Namespace,
+		Name:         inputInstallation.Name,
 		Installation: installation,
 		Force:        opts.Force,
 		DryRun:       opts.DryRun,
+		Format:       opts.Format,
 	}
 	return p.ReconcileInstallation(ctx, reconcileOpts)
 }
+
+// applyDocument is a resource defined in the file passed to an apply command.
+type applyDocument struct {
+	encoding.Document
+
+	// Namespace of the resource. Defaults to the namespace of the command
+	// when it is not set in the document.
+	Namespace string
+
+	// multiple indicates that the file defines multiple resources.
+	multiple bool
+}
+
+// wrapError identifies the document that caused the error when the file
+// defines multiple resources.
+func (d applyDocument) wrapError(err error) error {
+	if !d.multiple {
+		return err
+	}
+	return fmt.Errorf("document %d: %w", d.Index, err)
+}
+
+// readApplyDocuments reads the resources defined in the file passed to an
+// apply command, calling load for each resource. A yaml file may define
+// multiple resources as separate documents. Documents with a schemaType for a
+// different type of resource are skipped, so that a single file can define
+// all the resources for an environment.
+//
+// The errors from every document are returned together, and the documents
+// are only returned when all of them were loaded successfully.
+func (p *Porter) readApplyDocuments(ctx context.Context, o ApplyOptions, schemaType string, load func(doc applyDocument) error) ([]applyDocument, error) {
+	log := tracing.LoggerFromContext(ctx)
+
+	docs, err := encoding.UnmarshalDocumentsFile(p.FileSystem, o.File)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file '%s': %w", o.File, err)
+	}
+
+	result := make([]applyDocument, 0, len(docs))
+	var errs *multierror.Error
+	for _, doc := range docs {
+		applyDoc := applyDocument{Document: doc, Namespace: o.Namespace, multiple: len(docs) > 1}
+
+		var raw map[string]interface{}
+		if err := doc.Unmarshal(&raw); err != nil {
+			errs = multierror.Append(errs, applyDoc.wrapError(fmt.Errorf("invalid file '%s': %w", o.File, err)))
+			continue
+		}
+
+		if docType, ok := raw["schemaType"].(string); ok && docType != "" && !strings.EqualFold(docType, schemaType) {
+			log.Debugf("Skipping document %d in %s because it defines a %s", doc.Index, o.File, docType)
+			continue
+		}
+
+		// Check if the namespace was set in the document, if not, use the namespace set on the command
+		if rawNamespace, ok := raw["namespace"]; ok {
+			ns, ok := rawNamespace.(string)
+			if !ok {
+				errs = multierror.Append(errs, applyDoc.wrapError(errors.New("invalid namespace specified in file, must be a string")))
+				continue
+			}
+			applyDoc.Namespace = ns
+		}
+
+		if err := load(applyDoc); err != nil {
+			errs = multierror.Append(errs, applyDoc.wrapError(err))
+			continue
+		}
+
+		result = append(result, applyDoc)
+	}
+	if err := flattenApplyErrors(errs); err != nil {
+		return nil, err
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no %s documents were found in %s", schemaType, o.File)
+	}
+	return result, nil
+}
+
+// flattenApplyErrors returns a single error as-is, so that the error from a
+// file that defines a single resource is not reported as a list.
+func flattenApplyErrors(errs *multierror.Error) error {
+	if errs == nil {
+		return nil
+	}
+	if len(errs.Errors) == 1 {
+		return errs.Errors[0]
+	}
+	return errs
+}
@@ -0,0 +1,154 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// environmentFile defines all the resources for an environment in a single file.
+const environmentFile = `schemaType: CredentialSet
+schemaVersion: 1.0.1
+namespace: dev
+name: kubeconfig
+credentials:
+  - name: kubeconfig
+    source:
+      path: /home/myuser/.kube/config
+---
+schemaType: ParameterSet
+schemaVersion: 1.0.1
+name: mysql
+parameters:
+  - name: database
+    source:
+      value: wordpress
+---
+schemaType: CredentialSet
+schemaVersion: 1.0.1
+name: github
+credentials:
+  - name: token
+    source:
+      env: GITHUB_TOKEN
+---
+schemaType: Installation
+schemaVersion: 1.0.2
+name: wordpress
+bundle:
+  repository: ghcr.io/getporter/examples/wordpress
+  version: 0.1.0
+`
+
+func TestPorter_CredentialsApply_MultipleDocuments(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.TestConfig.TestContext.AddTestFileContents([]byte(environmentFile), "env.yaml")
+
+	ctx := context.Background()
+	err := p.CredentialsApply(ctx, ApplyOptions{Namespace: "staging", File: "env.yaml"})
+	require.NoError(t, err)
+
+	creds, err := p.Credentials.GetCredentialSet(ctx, "dev", "kubeconfig")
+	require.NoError(t, err, "expected the namespace in the document to be used")
+	assert.Equal(t, "kubeconfig", creds.Credentials[0].Name)
+
+	_, err = p.Credentials.GetCredentialSet(ctx, "staging", "github")
+	require.NoError(t, err, "expected the namespace of the command to be used when the document does not set it")
+
+	_, err = p.Parameters.GetParameterSet(ctx, "staging", "mysql")
+	require.ErrorIs(t, err, storage.ErrNotFound{}, "expected documents of other types to be skipped")
+}
+
+func TestPorter_ParametersApply_MultipleDocuments(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.TestConfig.TestContext.AddTestFileContents([]byte(environmentFile), "env.yaml")
+
+	ctx := context.Background()
+	err := p.ParametersApply(ctx, ApplyOptions{Namespace: "staging", File: "env.yaml"})
+	require.NoError(t, err)
+
+	params, err := p.Parameters.GetParameterSet(ctx, "staging", "mysql")
+	require.NoError(t, err)
+	assert.Equal(t, "database", params.Parameters[0].Name)
+
+	_, err = p.Credentials.GetCredentialSet(ctx, "dev", "kubeconfig")
+	require.ErrorIs(t, err, storage.ErrNotFound{}, "expected documents of other types to be skipped")
+}
+
+func TestPorter_CredentialsApply_DocumentErrors(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	contents := `schemaType: CredentialSet
+schemaVersion: 1.0.1
+name: valid
+credentials:
+  - name: token
+    source:
+      env: GITHUB_TOKEN
+---
+schemaType: CredentialSet
+schemaVersion: 0.1.0
+name: oldschema
+credentials:
+  - name: token
+    source:
+      env: GITHUB_TOKEN
+---
+schemaType: CredentialSet
+schemaVersion: 1.0.1
+namespace: [dev]
+name: badnamespace
+`
+	p.TestConfig.TestContext.AddTestFileContents([]byte(contents), "creds.yaml")
+
+	ctx := context.Background()
+	err := p.CredentialsApply(ctx, ApplyOptions{File: "creds.yaml"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "document 2: invalid credential set")
+	assert.Contains(t, err.Error(), "document 3: invalid namespace specified in file, must be a string")
+
+	_, err = p.Credentials.GetCredentialSet(ctx, "", "valid")
+	require.ErrorIs(t, err, storage.ErrNotFound{}, "expected no credential sets to be applied when a document is invalid")
+}
+
+func TestPorter_ParametersApply_SingleDocumentError(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	contents := `schemaType: ParameterSet
+schemaVersion: 0.1.0
+name: mysql
+parameters:
+  - name: database
+    source:
+      value: wordpress
+`
+	p.TestConfig.TestContext.AddTestFileContents([]byte(contents), "params.yaml")
+
+	err := p.ParametersApply(context.Background(), ApplyOptions{File: "params.yaml"})
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "document 1", "expected the document index to only be reported when the file defines multiple documents")
+	assert.Contains(t, err.Error(), "invalid parameter set")
+}
+
+func TestPorter_ParametersApply_NoDocuments(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	contents := `schemaType: CredentialSet
+schemaVersion: 1.0.1
+name: github
+`
+	p.TestConfig.TestContext.AddTestFileContents([]byte(contents), "creds.yaml")
+
+	err := p.ParametersApply(context.Background(), ApplyOptions{File: "creds.yaml"})
+	require.EqualError(t, err, "no ParameterSet documents were found in creds.yaml")
+}
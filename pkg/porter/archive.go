@@ -2,6 +2,7 @@ package porter
 
 import (
 	"archive/tar"
+	"bufio"
 	"compress/gzip"
 	"context"
 	"encoding/json"
@@ -10,14 +11,17 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
 	"time"
 
 	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/archive"
 	"get.porter.sh/porter/pkg/cnab"
 	cnabtooci "get.porter.sh/porter/pkg/cnab/cnab-to-oci"
+	"get.porter.sh/porter/pkg/secrets"
 	"get.porter.sh/porter/pkg/tracing"
 	"github.com/carolynvs/aferox"
 	"github.com/cnabio/cnab-go/bundle"
@@ -32,6 +36,11 @@ import (
 type ArchiveOptions struct {
 	BundleReferenceOptions
 	ArchiveFile string
+
+	// EncryptKeyRef is a reference to the key in the secret store, of the form
+	// secret://NAME, used to encrypt the archive. The archive is not
+	// encrypted when it is not set.
+	EncryptKeyRef string
 }
 
 // Validate performs validation on the publish options
@@ -47,6 +56,12 @@ func (o *ArchiveOptions) Validate(ctx context.Context, args []string, p *Porter)
 	if o.Reference == "" {
 		return errors.New("must provide a value for --reference of the form REGISTRY/bundle:tag")
 	}
+
+	if o.EncryptKeyRef != "" {
+		if _, err := archive.ParseKeyRef(o.EncryptKeyRef); err != nil {
+			return fmt.Errorf("invalid --encrypt-key-ref: %w", err)
+		}
+	}
 	return o.BundleReferenceOptions.Validate(ctx, args, p)
 }
 
@@ -73,10 +88,32 @@ func (p *Porter) Archive(ctx context.Context, opts ArchiveOptions) error {
 		return log.Error(err)
 	}
 
-	dest, err := p.Config.FileSystem.OpenFile(opts.ArchiveFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, pkg.FileModeWritable)
+	// Resolve the key before the images are exported, so that a missing key is reported right away
+	var encryptionKey []byte
+	encryptionCipher, err := archive.GetCipher(archive.DefaultAlgorithm)
 	if err != nil {
 		return log.Error(err)
 	}
+	if opts.EncryptKeyRef != "" {
+		encryptionKey, err = p.resolveArchiveKey(ctx, encryptionCipher, opts.EncryptKeyRef)
+		if err != nil {
+			return log.Error(err)
+		}
+	}
+
+	f, err := p.Config.FileSystem.OpenFile(opts.ArchiveFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, pkg.FileModeWritable)
+	if err != nil {
+		return log.Error(err)
+	}
+	defer f.Close()
+
+	var dest io.WriteCloser = f
+	if opts.EncryptKeyRef != "" {
+		dest, err = archive.NewEncryptedWriter(f, encryptionCipher, opts.EncryptKeyRef, encryptionKey)
+		if err != nil {
+			return log.Errorf("error encrypting the archive: %w", err)
+		}
+	}
 
 	exp := &exporter{
 		fs:                    p.Config.FileSystem,
@@ -92,7 +129,164 @@ func (p *Porter) Archive(ctx context.Context, opts ArchiveOptions) error {
 		return log.Error(err)
 	}
 
-	return nil
+	return log.Error(dest.Close())
+}
+
+// resolveArchiveKey resolves the key used to encrypt an archive from the
+// secret store. The reference is of the form secret://NAME.
+func (p *Porter) resolveArchiveKey(ctx context.Context, c archive.Cipher, keyRef string) ([]byte, error) {
+	name, err := archive.ParseKeyRef(keyRef)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := p.Secrets.Resolve(ctx, secrets.SourceSecret, name)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve the archive encryption key %s from the secret store: %w", keyRef, err)
+	}
+	return archive.DecodeKey(c, keyRef, value)
+}
+
+// openArchive opens an archive for reading, decrypting it when it is
+// encrypted. The key reference defaults to the one recorded in the encrypted
+// archive when it is not specified.
+func (p *Porter) openArchive(ctx context.Context, source string, keyRef string) (io.ReadCloser, error) {
+	f, err := p.FileSystem.Open(source)
+	if err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(f)
+	header, err := archive.ReadHeader(r)
+	if errors.Is(err, archive.ErrNotEncrypted) {
+		if keyRef != "" {
+			f.Close()
+			return nil, fmt.Errorf("the archive %s is not encrypted, remove --decrypt-key-ref", source)
+		}
+		return readCloser{Reader: r, Closer: f}, nil
+	} else if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if keyRef == "" {
+		keyRef = header.KeyRef
+	}
+	c, err := archive.GetCipher(header.Algorithm)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	key, err := p.resolveArchiveKey(ctx, c, keyRef)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("the archive %s is encrypted, specify the key with --decrypt-key-ref: %w", source, err)
+	}
+
+	plaintext, err := archive.NewDecryptedReader(r, header, key)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return readCloser{Reader: plaintext, Closer: f}, nil
+}
+
+// decryptArchive writes the decrypted archive to the directory, using the
+// same file name, and returns its path. When the archive is not encrypted,
+// the source path is returned.
+func (p *Porter) decryptArchive(ctx context.Context, source string, dir string, keyRef string) (string, error) {
+	f, err := p.FileSystem.Open(source)
+	if err != nil {
+		return "", err
+	}
+	_, err = archive.ReadHeader(bufio.NewReader(f))
+	f.Close()
+	if errors.Is(err, archive.ErrNotEncrypted) && keyRef == "" {
+		return source, nil
+	}
+
+	src, err := p.openArchive(ctx, source, keyRef)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	decrypted := filepath.Join(dir, filepath.Base(source))
+	dest, err := p.FileSystem.OpenFile(decrypted, os.O_RDWR|os.O_CREATE|os.O_TRUNC, pkg.FileModeWritable)
+	if err != nil {
+		return "", err
+	}
+	defer dest.Close()
+
+	if _, err = io.Copy(dest, src); err != nil {
+		return "", fmt.Errorf("error decrypting the archive %s: %w", source, err)
+	}
+	return decrypted, nil
+}
+
+// readArchiveBundle reads the bundle, and its relocation mapping, from an
+// archive without extracting the images.
+func (p *Porter) readArchiveBundle(ctx context.Context, source string, keyRef string) (cnab.BundleReference, error) {
+	//lint:ignore SA4006 ignore unused ctx for now
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	src, err := p.openArchive(ctx, source, keyRef)
+	if err != nil {
+		return cnab.BundleReference{}, span.Error(err)
+	}
+	defer src.Close()
+
+	gz, err := gzip.NewReader(src)
+	if err != nil {
+		return cnab.BundleReference{}, span.Errorf("the archive %s is not a gzipped tar archive: %w", source, err)
+	}
+	defer gz.Close()
+
+	var bundleRef cnab.BundleReference
+	var foundBundle bool
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return cnab.BundleReference{}, span.Errorf("error reading the archive %s: %w", source, err)
+		}
+
+		// The bundle files are at the root of the archive
+		switch path.Clean(header.Name) {
+		case "bundle.json":
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return cnab.BundleReference{}, span.Errorf("error reading bundle.json from archive %s: %w", source, err)
+			}
+			bun, err := bundle.Unmarshal(data)
+			if err != nil {
+				return cnab.BundleReference{}, span.Errorf("failed to load bundle from archive %s: %w", source, err)
+			}
+			bundleRef.Definition = cnab.NewBundle(*bun)
+			foundBundle = true
+		case "relocation-mapping.json":
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return cnab.BundleReference{}, span.Errorf("error reading relocation-mapping.json from archive %s: %w", source, err)
+			}
+			if err = json.Unmarshal(data, &bundleRef.RelocationMap); err != nil {
+				return cnab.BundleReference{}, span.Errorf("failed to parse relocation-mapping.json from archive %s: %w", source, err)
+			}
+		}
+	}
+
+	if !foundBundle {
+		return cnab.BundleReference{}, span.Errorf("the archive %s does not contain a bundle.json", source)
+	}
+	return bundleRef, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
 }
 
 type exporter struct {
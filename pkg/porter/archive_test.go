@@ -1,15 +1,23 @@
 package porter
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
+	"path/filepath"
 	"testing"
 
 	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/archive"
 	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/secrets"
 	"get.porter.sh/porter/tests"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-to-oci/relocation"
 	"github.com/cnabio/image-relocation/pkg/image"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -56,6 +64,107 @@ func TestArchive_Validate(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("invalid encryption key", func(t *testing.T) {
+		opts := ArchiveOptions{EncryptKeyRef: "archive-key"}
+		opts.Reference = "myreg/mybuns:v0.1.0"
+
+		err := opts.Validate(context.Background(), []string{"/path/to/file"}, p.Porter)
+		require.ErrorContains(t, err, "invalid --encrypt-key-ref")
+	})
+}
+
+// writeTestArchive writes a bundle archive, encrypting it when a key reference is specified.
+func writeTestArchive(t *testing.T, p *TestPorter, path string, keyRef string) []byte {
+	var tgz bytes.Buffer
+	gz := gzip.NewWriter(&tgz)
+	tw := tar.NewWriter(gz)
+	files := map[string]string{
+		"./bundle.json":             `{"name": "mybuns", "version": "0.1.0", "schemaVersion": "v1.0.0", "invocationImages": [{"imageType": "docker", "image": "example.com/mybuns-installer:v0.1.0"}]}`,
+		"./relocation-mapping.json": `{"example.com/mybuns-installer:v0.1.0": "myreg/mybuns@sha256:abc"}`,
+	}
+	for _, name := range []string{"./bundle.json", "./relocation-mapping.json"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(files[name]))}))
+		_, err := tw.Write([]byte(files[name]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	data := tgz.Bytes()
+	if keyRef != "" {
+		c, err := archive.GetCipher(archive.DefaultAlgorithm)
+		require.NoError(t, err)
+		key, err := p.resolveArchiveKey(context.Background(), c, keyRef)
+		require.NoError(t, err)
+
+		var encrypted bytes.Buffer
+		w, err := archive.NewEncryptedWriter(&encrypted, c, keyRef, key)
+		require.NoError(t, err)
+		_, err = w.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		data = encrypted.Bytes()
+	}
+
+	require.NoError(t, p.FileSystem.WriteFile(path, data, pkg.FileModeWritable))
+	return tgz.Bytes()
+}
+
+func TestArchive_Encrypted(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("k"), 32))
+	require.NoError(t, p.TestSecrets.Create(ctx, secrets.SourceSecret, "archive-key", key))
+	require.NoError(t, p.TestSecrets.Create(ctx, secrets.SourceSecret, "other-key", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("o"), 32))))
+	require.NoError(t, p.TestSecrets.Create(ctx, secrets.SourceSecret, "short-key", "c2hvcnQ="))
+	plaintext := writeTestArchive(t, p, "/mybuns.tgz", "secret://archive-key")
+	writeTestArchive(t, p, "/plain.tgz", "")
+
+	t.Run("read bundle", func(t *testing.T) {
+		for _, keyRef := range []string{"", "secret://archive-key"} {
+			bundleRef, err := p.readArchiveBundle(ctx, "/mybuns.tgz", keyRef)
+			require.NoError(t, err, "the archive should be decrypted with the key %q", keyRef)
+			assert.Equal(t, "mybuns", bundleRef.Definition.Name)
+			assert.Equal(t, "myreg/mybuns@sha256:abc", bundleRef.RelocationMap["example.com/mybuns-installer:v0.1.0"])
+		}
+
+		bundleRef, err := p.readArchiveBundle(ctx, "/plain.tgz", "")
+		require.NoError(t, err)
+		assert.Equal(t, "mybuns", bundleRef.Definition.Name)
+	})
+
+	t.Run("decrypt", func(t *testing.T) {
+		dir, err := p.FileSystem.TempDir("", "porter")
+		require.NoError(t, err)
+
+		decrypted, err := p.decryptArchive(ctx, "/mybuns.tgz", dir, "")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "mybuns.tgz"), decrypted)
+		got, err := p.FileSystem.ReadFile(decrypted)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, got)
+
+		decrypted, err = p.decryptArchive(ctx, "/plain.tgz", dir, "")
+		require.NoError(t, err)
+		assert.Equal(t, "/plain.tgz", decrypted, "archives that are not encrypted should be used as is")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := p.readArchiveBundle(ctx, "/mybuns.tgz", "secret://other-key")
+		require.ErrorContains(t, err, "either the key is incorrect or the archive was modified")
+
+		_, err = p.readArchiveBundle(ctx, "/mybuns.tgz", "secret://missing-key")
+		require.ErrorContains(t, err, "the archive /mybuns.tgz is encrypted, specify the key with --decrypt-key-ref")
+
+		_, err = p.readArchiveBundle(ctx, "/mybuns.tgz", "secret://short-key")
+		require.ErrorContains(t, err, "must be a base64 encoded 256-bit key")
+
+		_, err = p.decryptArchive(ctx, "/plain.tgz", "/", "secret://archive-key")
+		require.EqualError(t, err, "the archive /plain.tgz is not encrypted, remove --decrypt-key-ref")
+	})
 }
 
 func TestArchive_ArchiveDirectory(t *testing.T) {
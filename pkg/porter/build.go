@@ -13,22 +13,27 @@ import (
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/manifest"
 	"get.porter.sh/porter/pkg/mixin"
+	"get.porter.sh/porter/pkg/pkgmgmt"
 	"get.porter.sh/porter/pkg/printer"
 	"get.porter.sh/porter/pkg/storage"
 	"get.porter.sh/porter/pkg/tracing"
 	"github.com/Masterminds/semver/v3"
 	"github.com/opencontainers/go-digest"
-	"golang.org/x/sync/errgroup"
 )
 
 type BuildOptions struct {
 	bundleFileOptions
 	metadataOpts
 	build.BuildImageOptions
+	pkgmgmt.PackageDownloadOptions
 
 	// NoLint indicates if lint should be run before build.
 	NoLint bool
 
+	// VerifyRuntime indicates if the porter-runtime should be verified against
+	// the checksums published on the mirror.
+	VerifyRuntime bool
+
 	// Driver to use when building the invocation image.
 	Driver string
 
@@ -69,6 +74,10 @@ func (o *BuildOptions) Validate(p *Porter) error {
 		return err
 	}
 
+	if err := o.PackageDownloadOptions.Validate(); err != nil {
+		return err
+	}
+
 	return o.bundleFileOptions.Validate(p.Context)
 }
 
@@ -125,13 +134,19 @@ func (p *Porter) Build(ctx context.Context, opts BuildOptions) error {
 		}
 	}
 
+	// Record the porter-runtime that is copied into the invocation image
+	runtime, err := p.getRuntimeRecord(ctx, opts)
+	if err != nil {
+		return err
+	}
+
 	// Build bundle so that resulting bundle.json is available for inclusion
 	// into the invocation image.
 	// Note: the content digest field on the invocation image section of the
 	// bundle.json will *not* be correct until the image is actually pushed
 	// to a registry.  The bundle.json will need to be updated after publishing
 	// and provided just-in-time during bundle execution.
-	if err := p.buildBundle(ctx, m, ""); err != nil {
+	if err := p.buildBundle(ctx, m, "", runtime); err != nil {
 		return span.Error(fmt.Errorf("unable to build bundle: %w", err))
 	}
 
@@ -186,36 +201,46 @@ func (p *Porter) getUsedMixins(ctx context.Context, m *manifest.Manifest) ([]mix
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.EndSpan()
 
-	g := new(errgroup.Group)
-	results := make(chan mixin.Metadata, len(m.Mixins))
-	for _, m := range m.Mixins {
-		m := m
-		g.Go(func() error {
-			result, err := p.Mixins.GetMetadata(ctx, m.Name)
-			if err != nil {
-				return err
-			}
-
-			mixinMetadata := result.(*mixin.Metadata)
-			results <- *mixinMetadata
-			return nil
-		})
+	names := make([]string, len(m.Mixins))
+	for i, m := range m.Mixins {
+		names[i] = m.Name
 	}
 
-	if err := g.Wait(); err != nil {
-		return nil, err
+	usedMixins, err := mixin.GetAllMetadata(ctx, p.Mixins, names)
+	if err != nil {
+		return nil, span.Error(err)
 	}
 
-	usedMixins := make([]mixin.Metadata, len(m.Mixins))
-	for i := 0; i < len(usedMixins); i++ {
-		result := <-results
-		usedMixins[i] = result
+	return usedMixins, nil
+}
+
+// getRuntimeRecord returns the porter-runtime that is copied into the
+// invocation image. When requested, the runtime is first verified against the
+// checksums published for the current version of porter.
+func (p *Porter) getRuntimeRecord(ctx context.Context, opts BuildOptions) (*configadapter.RuntimeRecord, error) {
+	var info build.RuntimeInfo
+	var err error
+	if opts.VerifyRuntime {
+		info, err = build.VerifyRuntime(ctx, p.Config, opts.GetMirror())
+		if err != nil {
+			return nil, fmt.Errorf("unable to verify the porter-runtime: %w", err)
+		}
+	} else {
+		info, err = build.GetRuntimeInfo(p.Config)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return usedMixins, nil
+	return &configadapter.RuntimeRecord{
+		Version: info.Version,
+		Digest:  info.Digest,
+	}, nil
 }
 
-func (p *Porter) buildBundle(ctx context.Context, m *manifest.Manifest, digest digest.Digest) error {
+// buildBundle generates the bundle.json, recording the porter-runtime in the
+// porter stamp when it is specified.
+func (p *Porter) buildBundle(ctx context.Context, m *manifest.Manifest, digest digest.Digest, runtime *configadapter.RuntimeRecord) error {
 	imageDigests := map[string]string{m.Image: digest.String()}
 
 	mixins, err := p.getUsedMixins(ctx, m)
@@ -223,7 +248,12 @@ func (p *Porter) buildBundle(ctx context.Context, m *manifest.Manifest, digest d
 		return err
 	}
 
+	if err := validateConstraints(m, mixins); err != nil {
+		return err
+	}
+
 	converter := configadapter.NewManifestConverter(p.Config, m, imageDigests, mixins)
+	converter.Runtime = runtime
 	bun, err := converter.ToBundle(ctx)
 	if err != nil {
 		return err
@@ -232,6 +262,23 @@ func (p *Porter) buildBundle(ctx context.Context, m *manifest.Manifest, digest d
 	return p.writeBundle(bun)
 }
 
+// validateConstraints checks that the installed versions of porter and the
+// mixins used by the bundle satisfy the constraints declared in the manifest.
+func validateConstraints(m *manifest.Manifest, mixins []mixin.Metadata) error {
+	constraints := m.Constraints.ToBundleConstraints()
+	if err := constraints.ValidatePorterVersion(pkg.Version); err != nil {
+		return err
+	}
+
+	for _, mixin := range mixins {
+		if err := constraints.ValidateMixinVersion(mixin.Name, mixin.VersionInfo.Version); err != nil {
+			return err
+		}
+	}
+
+	return constraints.ValidateExtensions()
+}
+
 func (p Porter) writeBundle(b cnab.ExtendedBundle) error {
 	f, err := p.Config.FileSystem.OpenFile(build.LOCAL_BUNDLE, os.O_RDWR|os.O_CREATE|os.O_TRUNC, pkg.FileModeWritable)
 	if err != nil {
@@ -187,7 +187,7 @@ func TestPorter_paramRequired(t *testing.T) {
 	m, err := manifest.LoadManifestFrom(ctx, p.Config, config.Name)
 	require.NoError(t, err)
 
-	err = p.buildBundle(ctx, m, "digest")
+	err = p.buildBundle(ctx, m, "digest", nil)
 	require.NoError(t, err)
 
 	bundleBytes, err := p.FileSystem.ReadFile(build.LOCAL_BUNDLE)
@@ -279,7 +279,7 @@ func TestPorter_BuildWithCustomValues(t *testing.T) {
 	m, err := manifest.LoadManifestFrom(ctx, p.Config, config.Name)
 	require.NoError(t, err)
 
-	err = p.buildBundle(ctx, m, "digest")
+	err = p.buildBundle(ctx, m, "digest", nil)
 	require.NoError(t, err)
 
 	opts := BuildOptions{Customs: []string{"customKey1=editedCustomValue1"}}
@@ -0,0 +1,170 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"get.porter.sh/porter/pkg/cnab"
+	cnabtooci "get.porter.sh/porter/pkg/cnab/cnab-to-oci"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// resolveBundleDigest looks up the digest of the bundle in the registry, when
+// the bundle was referenced by a tag and its digest was not recorded when it
+// was pulled, so that the digest of the bundle used by a run is recorded.
+func (p *Porter) resolveBundleDigest(ctx context.Context, bundleRef *cnab.BundleReference, regOpts cnabtooci.RegistryOptions) {
+	if bundleRef.Reference.Named == nil || bundleRef.Digest != "" {
+		return
+	}
+
+	if bundleRef.Reference.HasDigest() {
+		bundleRef.Digest = bundleRef.Reference.Digest()
+		return
+	}
+
+	log := tracing.LoggerFromContext(ctx)
+	meta, err := p.Registry.GetBundleMetadata(ctx, bundleRef.Reference, regOpts)
+	if err != nil {
+		log.Debugf("could not resolve the digest of bundle %s, the digest will not be recorded on the run: %s", bundleRef.Reference, err)
+		return
+	}
+	bundleRef.Digest = meta.Digest
+}
+
+// warnOnBundleDigestDrift warns when the bundle reference used by the last
+// run of the installation now resolves to a different digest, which happens
+// when the tag was pushed again with a different bundle.
+func (p *Porter) warnOnBundleDigestDrift(installation storage.Installation, bundleRef cnab.BundleReference) {
+	lastRef := installation.Status.BundleReference
+	lastDigest := installation.Status.BundleDigest
+	if lastRef == "" || lastDigest == "" || bundleRef.Digest == "" || lastRef != bundleRef.Reference.String() {
+		return
+	}
+	if lastDigest == bundleRef.Digest.String() {
+		return
+	}
+
+	fmt.Fprintf(p.Err, "warning: bundle %s has changed since the last run of installation %s, its digest was %s and is now %s\n",
+		lastRef, installation.Name, lastDigest, bundleRef.Digest)
+}
+
+// RunVerifyOptions are the options for verifying the digest of the bundle
+// used by a run.
+type RunVerifyOptions struct {
+	printer.PrintOptions
+
+	// RunID is the identifier of the run to verify.
+	RunID string
+
+	// InsecureRegistry allows connecting to an unsecured registry or one without verifiable certificates.
+	InsecureRegistry bool
+}
+
+// Validate the args and options for verifying a run.
+func (o *RunVerifyOptions) Validate(args []string) error {
+	if len(args) < 1 || args[0] == "" {
+		return errors.New("run id is required")
+	}
+
+	if len(args) > 1 {
+		return fmt.Errorf("only one positional argument may be specified, the run id, but multiple were received: %s", args)
+	}
+
+	o.RunID = args[0]
+
+	return o.PrintOptions.Validate(printer.FormatPlaintext, []printer.Format{printer.FormatPlaintext, printer.FormatJson, printer.FormatYaml})
+}
+
+// RunVerification is the result of comparing the digest of the bundle used
+// by a run with the digest that its reference currently resolves to.
+type RunVerification struct {
+	// RunID is the identifier of the verified run.
+	RunID string `json:"runId" yaml:"runId"`
+
+	// BundleReference used by the run.
+	BundleReference string `json:"bundleReference" yaml:"bundleReference"`
+
+	// RecordedDigest is the digest of the bundle recorded on the run.
+	RecordedDigest string `json:"recordedDigest" yaml:"recordedDigest"`
+
+	// CurrentDigest is the digest that the bundle reference resolves to now.
+	CurrentDigest string `json:"currentDigest" yaml:"currentDigest"`
+
+	// Drifted indicates that the bundle reference resolves to a different
+	// bundle than the one used by the run.
+	Drifted bool `json:"drifted" yaml:"drifted"`
+}
+
+// VerifyInstallationRun resolves the bundle reference used by a run again,
+// and compares its digest with the digest recorded on the run.
+func (p *Porter) VerifyInstallationRun(ctx context.Context, opts RunVerifyOptions) (RunVerification, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	run, err := p.Installations.GetRun(ctx, opts.RunID)
+	if err != nil {
+		return RunVerification{}, span.Error(fmt.Errorf("could not retrieve run %s: %w", opts.RunID, err))
+	}
+
+	if run.BundleReference == "" {
+		return RunVerification{}, span.Error(fmt.Errorf("run %s was not made with a bundle from a registry, so its digest cannot be verified", opts.RunID))
+	}
+
+	ref, err := cnab.ParseOCIReference(run.BundleReference)
+	if err != nil {
+		return RunVerification{}, span.Error(fmt.Errorf("invalid bundle reference %s on run %s: %w", run.BundleReference, opts.RunID, err))
+	}
+
+	meta, err := p.Registry.GetBundleMetadata(ctx, ref, cnabtooci.RegistryOptions{InsecureRegistry: opts.InsecureRegistry})
+	if err != nil {
+		return RunVerification{}, span.Error(fmt.Errorf("could not resolve bundle %s: %w", run.BundleReference, err))
+	}
+
+	result := RunVerification{
+		RunID:           run.ID,
+		BundleReference: run.BundleReference,
+		RecordedDigest:  run.BundleDigest,
+		CurrentDigest:   meta.Digest.String(),
+	}
+	result.Drifted = result.RecordedDigest != "" && result.RecordedDigest != result.CurrentDigest
+	return result, nil
+}
+
+// PrintInstallationRunVerification verifies the digest of the bundle used by
+// a run, and warns when the bundle reference resolves to a different bundle.
+func (p *Porter) PrintInstallationRunVerification(ctx context.Context, opts RunVerifyOptions) error {
+	result, err := p.VerifyInstallationRun(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		err = printer.PrintJson(p.Out, result)
+	case printer.FormatYaml:
+		err = printer.PrintYaml(p.Out, result)
+	case printer.FormatPlaintext:
+		fmt.Fprintf(p.Out, "Run: %s\n", result.RunID)
+		fmt.Fprintf(p.Out, "Bundle: %s\n", result.BundleReference)
+		recorded := result.RecordedDigest
+		if recorded == "" {
+			recorded = "not recorded"
+		}
+		fmt.Fprintf(p.Out, "Recorded Digest: %s\n", recorded)
+		fmt.Fprintf(p.Out, "Current Digest: %s\n", result.CurrentDigest)
+	}
+	if err != nil {
+		return err
+	}
+
+	if result.Drifted {
+		fmt.Fprintf(p.Err, "warning: bundle %s has changed since run %s, its digest was %s and is now %s\n",
+			result.BundleReference, result.RunID, result.RecordedDigest, result.CurrentDigest)
+	} else if result.RecordedDigest == "" {
+		fmt.Fprintf(p.Err, "warning: the digest of the bundle was not recorded on run %s, so it cannot be compared\n", result.RunID)
+	}
+	return nil
+}
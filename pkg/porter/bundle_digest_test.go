@@ -0,0 +1,116 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	cnabtooci "get.porter.sh/porter/pkg/cnab/cnab-to-oci"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testDigestV1 = "sha256:276b44be3f478b4c8d1f99c1925386d45a878a853f22436ece5589f32e9df384"
+	testDigestV2 = "sha256:7bd3bb4c8b4a7fcbcb8d4c8e0a2b4d1c8a7d58ad3e1c5c5b8a7a3f0d2e1f4a6b"
+)
+
+func TestPorter_VerifyInstallationRun(t *testing.T) {
+	testcases := []struct {
+		name          string
+		currentDigest string
+		wantDrifted   bool
+	}{
+		{name: "unchanged", currentDigest: testDigestV1, wantDrifted: false},
+		{name: "drifted", currentDigest: testDigestV2, wantDrifted: true},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			p := NewTestPorter(t)
+			defer p.Close()
+
+			inst := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybuns"))
+			run := p.TestInstallations.CreateRun(inst.NewRun(cnab.ActionInstall), func(r *storage.Run) {
+				r.BundleReference = "example.com/mybuns:v1"
+				r.BundleDigest = testDigestV1
+			})
+
+			p.TestRegistry.MockGetBundleMetadata = func(ctx context.Context, ref cnab.OCIReference, opts cnabtooci.RegistryOptions) (cnabtooci.BundleMetadata, error) {
+				assert.Equal(t, "example.com/mybuns:v1", ref.String())
+				return cnabtooci.BundleMetadata{BundleReference: cnab.BundleReference{Reference: ref, Digest: digest.Digest(tc.currentDigest)}}, nil
+			}
+
+			opts := RunVerifyOptions{PrintOptions: printer.PrintOptions{RawFormat: "json"}}
+			require.NoError(t, opts.Validate([]string{run.ID}))
+			err := p.PrintInstallationRunVerification(ctx, opts)
+			require.NoError(t, err, "a drifted digest should only be a warning")
+
+			assert.Contains(t, p.TestConfig.TestContext.GetOutput(), `"currentDigest": "`+tc.currentDigest+`"`)
+			if tc.wantDrifted {
+				assert.Contains(t, p.TestConfig.TestContext.GetError(), "bundle example.com/mybuns:v1 has changed since run")
+			} else {
+				assert.NotContains(t, p.TestConfig.TestContext.GetError(), "has changed")
+			}
+		})
+	}
+}
+
+func TestPorter_VerifyInstallationRun_NoReference(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	inst := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybuns"))
+	run := p.TestInstallations.CreateRun(inst.NewRun(cnab.ActionInstall))
+
+	_, err := p.VerifyInstallationRun(context.Background(), RunVerifyOptions{RunID: run.ID})
+	require.ErrorContains(t, err, "was not made with a bundle from a registry")
+}
+
+func TestPorter_resolveBundleDigest(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	t.Run("digested reference", func(t *testing.T) {
+		ref := cnab.MustParseOCIReference("example.com/mybuns@" + testDigestV1)
+		bundleRef := cnab.BundleReference{Reference: ref}
+		p.resolveBundleDigest(context.Background(), &bundleRef, cnabtooci.RegistryOptions{})
+		assert.Equal(t, testDigestV1, bundleRef.Digest.String())
+	})
+
+	t.Run("tagged reference", func(t *testing.T) {
+		p.TestRegistry.MockGetBundleMetadata = func(ctx context.Context, ref cnab.OCIReference, opts cnabtooci.RegistryOptions) (cnabtooci.BundleMetadata, error) {
+			return cnabtooci.BundleMetadata{BundleReference: cnab.BundleReference{Reference: ref, Digest: testDigestV2}}, nil
+		}
+		bundleRef := cnab.BundleReference{Reference: cnab.MustParseOCIReference("example.com/mybuns:v1")}
+		p.resolveBundleDigest(context.Background(), &bundleRef, cnabtooci.RegistryOptions{})
+		assert.Equal(t, testDigestV2, bundleRef.Digest.String())
+	})
+}
+
+func TestPorter_warnOnBundleDigestDrift(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	inst := storage.NewInstallation("dev", "mybuns")
+	inst.Status.BundleReference = "example.com/mybuns:v1"
+	inst.Status.BundleDigest = testDigestV1
+
+	bundleRef := cnab.BundleReference{Reference: cnab.MustParseOCIReference("example.com/mybuns:v1"), Digest: testDigestV1}
+	p.warnOnBundleDigestDrift(inst, bundleRef)
+	assert.Empty(t, p.TestConfig.TestContext.GetError())
+
+	bundleRef.Digest = testDigestV2
+	p.warnOnBundleDigestDrift(inst, bundleRef)
+	assert.Contains(t, p.TestConfig.TestContext.GetError(), "bundle example.com/mybuns:v1 has changed since the last run of installation mybuns")
+
+	p.TestConfig.TestContext.ClearOutputs()
+	bundleRef.Reference = cnab.MustParseOCIReference("example.com/mybuns:v2")
+	p.warnOnBundleDigestDrift(inst, bundleRef)
+	assert.Empty(t, p.TestConfig.TestContext.GetError(), "a different reference is an upgrade and should not warn")
+}
@@ -0,0 +1,320 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"get.porter.sh/porter/pkg/cache"
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/dustin/go-humanize"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// CacheItemBundle is the kind of cache item for cached bundle metadata.
+	CacheItemBundle = "bundle"
+
+	// CacheItemImage is the kind of cache item for an invocation image in the
+	// local container engine.
+	CacheItemImage = "image"
+)
+
+// CacheGCOptions are the options of porter cache gc.
+type CacheGCOptions struct {
+	printer.PrintOptions
+
+	// DryRun lists what would be removed without removing it.
+	DryRun bool
+
+	// Images also removes the invocation images of the unreferenced bundles
+	// from the local container engine.
+	Images bool
+}
+
+func (o *CacheGCOptions) Validate() error {
+	return o.ParseFormat()
+}
+
+// CacheItem is a cached bundle or invocation image that was reclaimed, or
+// would be reclaimed during a dry-run, by porter cache gc.
+type CacheItem struct {
+	// Kind of item, either bundle or image.
+	Kind string `json:"kind" yaml:"kind"`
+
+	// Reference of the bundle or image.
+	Reference string `json:"reference" yaml:"reference"`
+
+	// Path to the cached bundle on the filesystem.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Size in bytes that is reclaimed.
+	Size int64 `json:"size" yaml:"size"`
+}
+
+// CacheGCResult summarizes the space reclaimed by porter cache gc.
+type CacheGCResult struct {
+	// DryRun indicates that the items were not removed.
+	DryRun bool `json:"dryRun" yaml:"dryRun"`
+
+	// Items that were reclaimed.
+	Items []CacheItem `json:"items" yaml:"items"`
+
+	// ReclaimedSize is the total size in bytes of the items.
+	ReclaimedSize int64 `json:"reclaimedSize" yaml:"reclaimedSize"`
+}
+
+// CacheGC removes cached bundles, and optionally their invocation images, that
+// are not referenced by any installation.
+func (p *Porter) CacheGC(ctx context.Context, opts CacheGCOptions) (CacheGCResult, error) {
+	ctx, span := tracing.StartSpan(ctx,
+		attribute.Bool("dryRun", opts.DryRun),
+		attribute.Bool("images", opts.Images))
+	defer span.EndSpan()
+
+	bundleRefs, imageRefs, err := p.getReferencedBundles(ctx)
+	if err != nil {
+		return CacheGCResult{}, err
+	}
+
+	cachedBundles, err := p.Cache.ListBundles()
+	if err != nil {
+		return CacheGCResult{}, span.Error(fmt.Errorf("could not list the cached bundles: %w", err))
+	}
+
+	// Keep the invocation images of the bundles that are still in use
+	var unreferenced []cache.CachedBundle
+	for _, cb := range cachedBundles {
+		if isBundleReferenced(cb, bundleRefs) {
+			for _, img := range getInvocationImageRefs(cb.Definition) {
+				imageRefs[img] = true
+			}
+			continue
+		}
+		unreferenced = append(unreferenced, cb)
+	}
+
+	result := CacheGCResult{DryRun: opts.DryRun}
+	for _, cb := range unreferenced {
+		size, err := cb.GetSize(p.Context)
+		if err != nil {
+			return result, span.Error(err)
+		}
+
+		ref := cb.Reference.String()
+		if ref == "" {
+			ref = "(unknown)"
+		}
+		span.Debugf("Bundle %s at %s is not referenced by an installation", ref, cb.GetCacheDir())
+		if !opts.DryRun {
+			if err = p.Cache.RemoveBundle(cb); err != nil {
+				return result, span.Error(err)
+			}
+		}
+		result.add(CacheItem{Kind: CacheItemBundle, Reference: ref, Path: cb.GetCacheDir(), Size: size})
+	}
+
+	if !opts.Images {
+		return result, nil
+	}
+
+	images := p.getLocalImageStore()
+	removed := make(map[string]bool)
+	for _, cb := range unreferenced {
+		for _, img := range getInvocationImageRefs(cb.Definition) {
+			if imageRefs[img] || removed[img] {
+				continue
+			}
+			removed[img] = true
+
+			size, found, err := images.GetImageSize(ctx, img)
+			if err != nil {
+				return result, span.Error(fmt.Errorf("could not inspect invocation image %s: %w", img, err))
+			}
+			if !found {
+				continue
+			}
+
+			if !opts.DryRun {
+				if err = images.RemoveImage(ctx, img); err != nil {
+					return result, span.Error(fmt.Errorf("could not remove invocation image %s: %w", img, err))
+				}
+			}
+			result.add(CacheItem{Kind: CacheItemImage, Reference: img, Size: size})
+		}
+	}
+
+	return result, nil
+}
+
+func (r *CacheGCResult) add(item CacheItem) {
+	r.Items = append(r.Items, item)
+	r.ReclaimedSize += item.Size
+}
+
+// getReferencedBundles returns the references and digests of the bundles used by
+// installations in any namespace, and the invocation images of their last run.
+func (p *Porter) getReferencedBundles(ctx context.Context) (map[string]bool, map[string]bool, error) {
+	log := tracing.LoggerFromContext(ctx)
+
+	installations, err := p.Installations.ListInstallations(ctx, storage.ListOptions{Namespace: "*"})
+	if err != nil {
+		return nil, nil, log.Error(fmt.Errorf("could not list installations: %w", err))
+	}
+
+	bundleRefs := make(map[string]bool)
+	imageRefs := make(map[string]bool)
+	for _, inst := range installations {
+		if ref, ok, err := inst.Bundle.GetBundleReference(); err == nil && ok {
+			bundleRefs[ref.String()] = true
+		}
+		if inst.Status.BundleReference != "" {
+			if ref, err := cnab.ParseOCIReference(inst.Status.BundleReference); err == nil {
+				bundleRefs[ref.String()] = true
+			}
+		}
+		if inst.Status.BundleDigest != "" {
+			bundleRefs[inst.Status.BundleDigest] = true
+		}
+
+		run, err := p.Installations.GetLastRun(ctx, inst.Namespace, inst.Name)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound{}) {
+				continue
+			}
+			return nil, nil, log.Error(fmt.Errorf("could not retrieve the last run of installation %s: %w", inst, err))
+		}
+		for _, img := range getInvocationImageRefs(cnab.NewBundle(run.Bundle)) {
+			imageRefs[img] = true
+		}
+	}
+
+	return bundleRefs, imageRefs, nil
+}
+
+// isBundleReferenced determines if a cached bundle matches a reference or
+// digest that is used by an installation.
+func isBundleReferenced(cb cache.CachedBundle, refs map[string]bool) bool {
+	if cb.Reference.Named == nil {
+		return false
+	}
+	if refs[cb.Reference.String()] {
+		return true
+	}
+	return cb.Digest != "" && refs[cb.Digest.String()]
+}
+
+// getInvocationImageRefs returns the normalized references of the invocation
+// images of a bundle.
+func getInvocationImageRefs(bun cnab.ExtendedBundle) []string {
+	var refs []string
+	for _, ii := range bun.InvocationImages {
+		if ii.Image == "" {
+			continue
+		}
+		ref, err := cnab.ParseOCIReference(ii.Image)
+		if err != nil {
+			continue
+		}
+		refs = append(refs, ref.String())
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// PrintCacheGC removes unreferenced cached bundles and prints the reclaimed space.
+func (p *Porter) PrintCacheGC(ctx context.Context, opts CacheGCOptions) error {
+	result, err := p.CacheGC(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, result)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, result)
+	case printer.FormatPlaintext:
+		if len(result.Items) == 0 {
+			fmt.Fprintln(p.Out, "Nothing to reclaim, every cached bundle is referenced by an installation")
+			return nil
+		}
+
+		row := func(v interface{}) []string {
+			item, ok := v.(CacheItem)
+			if !ok {
+				return nil
+			}
+			return []string{item.Kind, item.Reference, humanize.Bytes(uint64(item.Size))}
+		}
+		if err = printer.PrintTable(p.Out, result.Items, row, "KIND", "REFERENCE", "SIZE"); err != nil {
+			return err
+		}
+
+		verb := "Reclaimed"
+		if opts.DryRun {
+			verb = "Would reclaim"
+		}
+		fmt.Fprintf(p.Out, "\n%s %s from %d items\n", verb, humanize.Bytes(uint64(result.ReclaimedSize)), len(result.Items))
+		return nil
+	default:
+		return fmt.Errorf("invalid format: %s", opts.Format)
+	}
+}
+
+// localImageStore manages the images in the local container engine.
+type localImageStore interface {
+	// GetImageSize returns the size of an image, and if it was found.
+	GetImageSize(ctx context.Context, ref string) (int64, bool, error)
+
+	// RemoveImage removes an image reference from the local container engine.
+	RemoveImage(ctx context.Context, ref string) error
+}
+
+// getLocalImageStore returns the images in the local docker engine, unless
+// another store was set for tests.
+func (p *Porter) getLocalImageStore() localImageStore {
+	if p.localImages == nil {
+		p.localImages = dockerImageStore{}
+	}
+	return p.localImages
+}
+
+var _ localImageStore = dockerImageStore{}
+
+// dockerImageStore manages images with the docker engine.
+type dockerImageStore struct{}
+
+func (dockerImageStore) GetImageSize(ctx context.Context, ref string) (int64, bool, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return 0, false, err
+	}
+	defer cli.Close()
+
+	img, _, err := cli.ImageInspectWithRaw(ctx, ref)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return img.Size, true, nil
+}
+
+func (dockerImageStore) RemoveImage(ctx context.Context, ref string) error {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	_, err = cli.ImageRemove(ctx, ref, types.ImageRemoveOptions{PruneChildren: true})
+	return err
+}
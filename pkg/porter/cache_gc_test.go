@@ -0,0 +1,101 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testImageStore struct {
+	sizes   map[string]int64
+	removed []string
+}
+
+func (s *testImageStore) GetImageSize(_ context.Context, ref string) (int64, bool, error) {
+	size, ok := s.sizes[ref]
+	return size, ok, nil
+}
+
+func (s *testImageStore) RemoveImage(_ context.Context, ref string) error {
+	s.removed = append(s.removed, ref)
+	delete(s.sizes, ref)
+	return nil
+}
+
+func TestPorter_CacheGC(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	storeBundle := func(ref string, images ...string) {
+		bun := bundle.Bundle{Name: "mybuns", Version: "0.1.0"}
+		for _, img := range images {
+			bun.InvocationImages = append(bun.InvocationImages, bundle.InvocationImage{BaseImage: bundle.BaseImage{Image: img}})
+		}
+		_, err := p.Cache.StoreBundle(cnab.BundleReference{Reference: cnab.MustParseOCIReference(ref), Definition: cnab.NewBundle(bun)})
+		require.NoError(t, err)
+	}
+	storeBundle("example.com/mybuns:v0.1.0", "example.com/mybuns-installer:v0.1.0", "example.com/shared:v1")
+	storeBundle("example.com/mybuns:v0.2.0", "example.com/mybuns-installer:v0.2.0", "example.com/shared:v1")
+	storeBundle("example.com/oldbuns:v1.0.0", "example.com/oldbuns-installer:v1.0.0")
+
+	i := storage.NewInstallation("dev", "mybuns")
+	i.Status.BundleReference = "example.com/mybuns:v0.2.0"
+	p.TestInstallations.CreateInstallation(i)
+
+	images := &testImageStore{sizes: map[string]int64{
+		"example.com/mybuns-installer:v0.1.0": 100,
+		"example.com/mybuns-installer:v0.2.0": 200,
+		"example.com/shared:v1":               300,
+	}}
+	p.localImages = images
+
+	result, err := p.CacheGC(ctx, CacheGCOptions{DryRun: true, Images: true})
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	require.Len(t, result.Items, 3)
+	assert.Equal(t, CacheItemBundle, result.Items[0].Kind)
+	assert.Equal(t, CacheItemBundle, result.Items[1].Kind)
+	assert.Equal(t, CacheItem{Kind: CacheItemImage, Reference: "example.com/mybuns-installer:v0.1.0", Size: 100}, result.Items[2],
+		"only the invocation image that is not used by a referenced bundle, and exists locally, should be reclaimed")
+	assert.Empty(t, images.removed, "a dry-run should not remove images")
+
+	cachedBundles, err := p.Cache.ListBundles()
+	require.NoError(t, err)
+	assert.Len(t, cachedBundles, 3, "a dry-run should not remove cached bundles")
+
+	result, err = p.CacheGC(ctx, CacheGCOptions{Images: true})
+	require.NoError(t, err)
+	require.Len(t, result.Items, 3)
+	assert.Equal(t, []string{"example.com/mybuns-installer:v0.1.0"}, images.removed)
+
+	var total int64
+	for _, item := range result.Items {
+		total += item.Size
+	}
+	assert.Equal(t, total, result.ReclaimedSize)
+
+	cachedBundles, err = p.Cache.ListBundles()
+	require.NoError(t, err)
+	require.Len(t, cachedBundles, 1)
+	assert.Equal(t, "example.com/mybuns:v0.2.0", cachedBundles[0].Reference.String())
+
+	_, found, err := p.Cache.FindBundle(cnab.MustParseOCIReference("example.com/mybuns:v0.2.0"))
+	require.NoError(t, err)
+	assert.True(t, found, "the referenced bundle should still be cached")
+}
+
+func TestPorter_PrintCacheGC_NothingToReclaim(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	opts := CacheGCOptions{}
+	require.NoError(t, opts.Validate())
+	require.NoError(t, p.PrintCacheGC(context.Background(), opts))
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "Nothing to reclaim")
+}
@@ -33,12 +33,17 @@ type CredentialEditOptions struct {
 
 // ListCredentials lists saved credential sets.
 func (p *Porter) ListCredentials(ctx context.Context, opts ListOptions) ([]storage.CredentialSet, error) {
+	selector, err := opts.ParseLabelSelector()
+	if err != nil {
+		return nil, err
+	}
+
 	return p.Credentials.ListCredentialSets(ctx, storage.ListOptions{
-		Namespace: opts.GetNamespace(),
-		Name:      opts.Name,
-		Labels:    opts.ParseLabels(),
-		Skip:      opts.Skip,
-		Limit:     opts.Limit,
+		Namespace:     opts.GetNamespace(),
+		Name:          opts.Name,
+		LabelSelector: selector,
+		Skip:          opts.Skip,
+		Limit:         opts.Limit,
 	})
 }
 
@@ -134,6 +139,7 @@ func (p *Porter) GenerateCredentials(ctx context.Context, opts CredentialOptions
 			Namespace: opts.Namespace,
 			Labels:    opts.ParseLabels(),
 			Silent:    opts.Silent,
+			Profile:   p.GetOutputProfile(),
 		},
 		Credentials: bundleRef.Definition.Credentials,
 	}
@@ -354,55 +360,51 @@ func (p *Porter) CredentialsApply(ctx context.Context, o ApplyOptions) error {
 	defer span.EndSpan()
 
 	span.Debugf("Reading input file %s...\n", o.File)
-	namespace, err := p.getNamespaceFromFile(o)
-	if err != nil {
-		return span.Error(err)
-	}
 
-	var creds storage.CredentialSet
-	err = encoding.UnmarshalFile(p.FileSystem, o.File, &creds)
+	// Validate every credential set before any are saved
+	var credsets []storage.CredentialSet
+	docs, err := p.readApplyDocuments(ctx, o, "CredentialSet", func(doc applyDocument) error {
+		creds, err := p.loadCredentialSetDocument(ctx, o, doc)
+		if err != nil {
+			return err
+		}
+		credsets = append(credsets, creds)
+		return nil
+	})
 	if err != nil {
-		return span.Error(fmt.Errorf("could not load %s as a credential set: %w", o.File, err))
+		return span.Error(err)
 	}
 
-	if err = creds.Validate(); err != nil {
-		return span.Error(fmt.Errorf("invalid credential set: %w", err))
+	for i, creds := range credsets {
+		if err := p.Credentials.UpsertCredentialSet(ctx, creds); err != nil {
+			return span.Error(docs[i].wrapError(err))
+		}
+		span.Infof("Applied %s credential set", creds)
 	}
 
-	creds.Namespace = namespace
-	creds.Status.Modified = time.Now()
+	return nil
+}
 
-	err = p.Credentials.Validate(ctx, creds)
-	if err != nil {
-		return span.Error(fmt.Errorf("credential set is invalid: %w", err))
+// loadCredentialSetDocument reads and validates a credential set from a file
+// passed to porter credentials apply.
+func (p *Porter) loadCredentialSetDocument(ctx context.Context, o ApplyOptions, doc applyDocument) (storage.CredentialSet, error) {
+	var creds storage.CredentialSet
+	if err := doc.Unmarshal(&creds); err != nil {
+		return creds, fmt.Errorf("could not load %s as a credential set: %w", o.File, err)
 	}
 
-	err = p.Credentials.UpsertCredentialSet(ctx, creds)
-	if err != nil {
-		return err
+	if err := creds.Validate(); err != nil {
+		return creds, fmt.Errorf("invalid credential set: %w", err)
 	}
 
-	span.Infof("Applied %s credential set", creds)
-	return nil
-}
-
-func (p *Porter) getNamespaceFromFile(o ApplyOptions) (string, error) {
-	// Check if the namespace was set in the file, if not, use the namespace set on the command
-	var raw map[string]interface{}
-	err := encoding.UnmarshalFile(p.FileSystem, o.File, &raw)
-	if err != nil {
-		return "", fmt.Errorf("invalid file '%s': %w", o.File, err)
-	}
+	creds.Namespace = doc.Namespace
+	creds.Status.Modified = time.Now()
 
-	if rawNamespace, ok := raw["namespace"]; ok {
-		if ns, ok := rawNamespace.(string); ok {
-			return ns, nil
-		} else {
-			return "", errors.New("invalid namespace specified in file, must be a string")
-		}
+	if err := p.Credentials.Validate(ctx, creds); err != nil {
+		return creds, fmt.Errorf("credential set is invalid: %w", err)
 	}
 
-	return o.Namespace, nil
+	return creds, nil
 }
 
 // CredentialCreateOptions represent options for Porter's credential create command
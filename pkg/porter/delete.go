@@ -7,6 +7,9 @@ import (
 
 	"get.porter.sh/porter/pkg/cnab"
 	"get.porter.sh/porter/pkg/portercontext"
+	secretsplugins "get.porter.sh/porter/pkg/secrets/plugins"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
 )
 
 const installationDeleteTmpl = "deleting installation records for %s...\n"
@@ -52,6 +55,66 @@ func (p *Porter) DeleteInstallation(ctx context.Context, opts DeleteOptions) err
 		return ErrUnsafeInstallationDeleteRetryForce
 	}
 
+	if !opts.Force {
+		if err = p.checkInstallationProtection(ctx, opts.Namespace, opts.Name); err != nil {
+			return fmt.Errorf("%w; if you are sure it should be deleted, retry the last command with the --force flag", err)
+		}
+	}
+
 	fmt.Fprintf(p.Out, installationDeleteTmpl, opts.Name)
-	return p.Installations.RemoveInstallation(ctx, opts.Namespace, opts.Name)
+	return p.removeInstallation(ctx, opts.Namespace, opts.Name)
+}
+
+// removeInstallation deletes the records of an installation, along with the
+// sensitive values of the installation and its runs that were saved to the
+// secret store.
+func (p *Porter) removeInstallation(ctx context.Context, namespace string, name string) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	installation, err := p.Installations.GetInstallation(ctx, namespace, name)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound{}) {
+			return span.Error(p.Installations.RemoveInstallation(ctx, namespace, name))
+		}
+		return span.Error(fmt.Errorf("could not retrieve installation %s/%s: %w", namespace, name, err))
+	}
+
+	runs, _, err := p.Installations.ListRuns(ctx, namespace, name)
+	if err != nil {
+		return span.Error(fmt.Errorf("could not list the runs of installation %s/%s: %w", namespace, name, err))
+	}
+
+	// Remove the sensitive values first, so that a failure leaves the records
+	// in place and the delete can be retried
+	err = p.checkCleanSecretsError(ctx, p.Sanitizer.CleanInstallationSecrets(ctx, installation))
+	if err == nil {
+		err = p.cleanRunSecrets(ctx, runs)
+	}
+	if err != nil {
+		return span.Error(fmt.Errorf("could not remove the sensitive data of installation %s/%s: %w", namespace, name, err))
+	}
+
+	return span.Error(p.Installations.RemoveInstallation(ctx, namespace, name))
+}
+
+// cleanRunSecrets removes the sensitive values of runs that were deleted.
+func (p *Porter) cleanRunSecrets(ctx context.Context, runs []storage.Run) error {
+	for _, run := range runs {
+		if err := p.Sanitizer.CleanRunSecrets(ctx, run); err != nil {
+			return p.checkCleanSecretsError(ctx, err)
+		}
+	}
+	return nil
+}
+
+// checkCleanSecretsError ignores the error returned when the secrets plugin
+// does not support removing secrets, which is logged as a warning instead.
+func (p *Porter) checkCleanSecretsError(ctx context.Context, err error) error {
+	if errors.Is(err, secretsplugins.ErrNotImplemented) {
+		log := tracing.LoggerFromContext(ctx)
+		log.Warnf("The sensitive data could not be removed from the secret store and must be removed manually: %s", err)
+		return nil
+	}
+	return err
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/secrets"
 	"get.porter.sh/porter/pkg/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -84,3 +85,38 @@ func TestDeleteInstallation(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteInstallation_RemovesSensitiveData(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.TestConfig.TestContext.AddTestFile("testdata/bundle.json", "/bundle.json")
+	bun, err := cnab.LoadBundle(p.Context, "/bundle.json")
+	require.NoError(t, err)
+
+	i := storage.NewInstallation("", "test")
+	i.Parameters.Parameters = p.SanitizeParameters([]secrets.Strategy{storage.ValueStrategy("my-second-param", "2")}, i.ID, bun)
+	i = p.TestInstallations.CreateInstallation(i)
+
+	run := i.NewRun(cnab.ActionUninstall)
+	run.Bundle = bun.Bundle
+	run.Parameters.Parameters = p.SanitizeParameters([]secrets.Strategy{storage.ValueStrategy("my-second-param", "2")}, run.ID, bun)
+	run = p.TestInstallations.CreateRun(run)
+	result := p.TestInstallations.CreateResult(run.NewResult(cnab.StatusSucceeded))
+	output := p.CreateOutput(result.NewOutput("my-first-output", []byte("secret output")), bun)
+
+	sensitiveKeys := []string{i.ID + "-my-second-param", run.ID + "-my-second-param", output.Key}
+	for _, key := range sensitiveKeys {
+		_, err = p.TestSecrets.Resolve(ctx, secrets.SourceSecret, key)
+		require.NoErrorf(t, err, "expected %s to be saved to the secret store", key)
+	}
+
+	err = p.DeleteInstallation(ctx, DeleteOptions{installationOptions: installationOptions{Name: "test"}, Force: true})
+	require.NoError(t, err)
+
+	for _, key := range sensitiveKeys {
+		_, err = p.TestSecrets.Resolve(ctx, secrets.SourceSecret, key)
+		require.Errorf(t, err, "expected %s to be removed from the secret store", key)
+	}
+}
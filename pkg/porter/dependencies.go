@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"get.porter.sh/porter/pkg/cnab"
 	depsv1 "get.porter.sh/porter/pkg/cnab/dependencies/v1"
 	cnabprovider "get.porter.sh/porter/pkg/cnab/provider"
 	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/failure"
 	"get.porter.sh/porter/pkg/manifest"
 	"get.porter.sh/porter/pkg/runtime"
 	"get.porter.sh/porter/pkg/storage"
@@ -93,17 +95,167 @@ func (e *dependencyExecutioner) Execute(ctx context.Context) error {
 		return span.Error(errors.New("Prepare must be called before Execute"))
 	}
 
-	// executeDependency the requested action against all the dependencies
+	parallelism := e.GetDependencyParallelism()
+	if !e.hasDependencyGraph() || parallelism == 1 {
+		// executeDependency the requested action against all the dependencies
+		for _, dep := range e.deps {
+			err := e.executeDependency(ctx, dep, nil)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return e.executeDependencyGraph(ctx, parallelism)
+}
+
+// hasDependencyGraph determines if the bundle declared the order of its
+// dependencies with dependsOn. Otherwise, the dependencies are executed one
+// at a time in the order they are listed.
+func (e *dependencyExecutioner) hasDependencyGraph() bool {
 	for _, dep := range e.deps {
-		err := e.executeDependency(ctx, dep)
-		if err != nil {
-			return err
+		if len(dep.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencyResult is the outcome of executing a dependency in the graph.
+type dependencyResult struct {
+	// Err is the error executing the dependency.
+	Err error
+
+	// Skipped indicates that the dependency was not executed because a
+	// dependency that it waited for failed.
+	Skipped bool
+}
+
+// executeDependencyGraph executes the dependencies as soon as the dependencies
+// that they wait for have completed, running up to parallelism dependencies at
+// the same time. Each dependency is recorded in its own run, and the errors of
+// every dependency that failed are returned together. When a dependency fails,
+// the dependencies that wait for it are skipped.
+func (e *dependencyExecutioner) executeDependencyGraph(ctx context.Context, parallelism int) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	waitFor, err := e.buildDependencyGraph()
+	if err != nil {
+		return span.Error(err)
+	}
+
+	done := make(map[string]chan struct{}, len(e.deps))
+	for _, dep := range e.deps {
+		done[dep.Alias] = make(chan struct{})
+	}
+
+	var mu sync.Mutex
+	results := make(map[string]dependencyResult, len(e.deps))
+	limit := make(chan struct{}, parallelism)
+
+	// The output of the dependencies that run at the same time is written
+	// line by line, and prefixed with the alias of the dependency, so that the
+	// output of each dependency can be told apart.
+	var outputMu sync.Mutex
+
+	var wg sync.WaitGroup
+	for _, dep := range e.deps {
+		wg.Add(1)
+		go func(dep *queuedDependency) {
+			defer wg.Done()
+			defer close(done[dep.Alias])
+
+			var failed []string
+			for _, prereq := range waitFor[dep.Alias] {
+				<-done[prereq]
+
+				mu.Lock()
+				if results[prereq].Err != nil {
+					failed = append(failed, prereq)
+				}
+				mu.Unlock()
+			}
+
+			result := dependencyResult{}
+			if len(failed) > 0 {
+				result.Skipped = true
+				result.Err = fmt.Errorf("dependency %s was not executed because %s failed", dep.Alias, strings.Join(failed, ", "))
+			} else {
+				output := &dependencyOutput{
+					Out: newDependencyOutputWriter(&outputMu, e.Out, dep.Alias),
+					Err: newDependencyOutputWriter(&outputMu, e.Err, dep.Alias),
+				}
+				limit <- struct{}{}
+				result.Err = e.executeDependency(ctx, dep, output)
+				output.Flush()
+				<-limit
+			}
+
+			mu.Lock()
+			results[dep.Alias] = result
+			mu.Unlock()
+		}(dep)
+	}
+	wg.Wait()
+
+	var executeErrs error
+	var succeeded, skipped int
+	for _, dep := range e.deps {
+		result := results[dep.Alias]
+		switch {
+		case result.Skipped:
+			skipped++
+		case result.Err == nil:
+			succeeded++
+		}
+		if result.Err != nil {
+			executeErrs = multierror.Append(executeErrs, result.Err)
 		}
 	}
+	span.Infof("Executed dependencies: %d succeeded, %d failed, %d skipped", succeeded, len(e.deps)-succeeded-skipped, skipped)
 
+	if executeErrs != nil {
+		if succeeded > 0 {
+			// Some of the dependencies were changed, so the installation is only partially updated
+			executeErrs = failure.PartialSuccess(executeErrs)
+		}
+		return span.Error(executeErrs)
+	}
 	return nil
 }
 
+// buildDependencyGraph returns the dependencies that each dependency waits for
+// before it is executed. Dependencies wait for the dependencies that they
+// depend on, except during uninstall, when dependencies are uninstalled after
+// the dependencies that depend on them.
+func (e *dependencyExecutioner) buildDependencyGraph() (map[string][]string, error) {
+	_, uninstall := e.parentAction.(UninstallOptions)
+
+	// The listed order is a valid order in which to execute the dependencies
+	// when every dependency only depends on the dependencies listed before it,
+	// which also guarantees that there isn't a cycle.
+	listed := make(map[string]bool, len(e.deps))
+	waitFor := make(map[string][]string, len(e.deps))
+	for _, dep := range e.deps {
+		for _, prereq := range dep.DependsOn {
+			if !listed[prereq] {
+				return nil, fmt.Errorf("invalid dependencies: dependency %s depends on %s, which must be listed before it in the sequence of dependencies", dep.Alias, prereq)
+			}
+
+			if uninstall {
+				waitFor[prereq] = append(waitFor[prereq], dep.Alias)
+			} else {
+				waitFor[dep.Alias] = append(waitFor[dep.Alias], prereq)
+			}
+		}
+		listed[dep.Alias] = true
+	}
+
+	return waitFor, nil
+}
+
 // PrepareRootActionArguments uses information about the dependencies of a bundle to prepare
 // the execution of the root operation.
 func (e *dependencyExecutioner) PrepareRootActionArguments(ctx context.Context) (cnabprovider.ActionArguments, error) {
@@ -158,7 +310,7 @@ func (e *dependencyExecutioner) identifyDependencies(ctx context.Context) error
 		return span.Error(errors.New("identifyDependencies failed to load the bundle because no bundle was specified. Please report this bug to https://github.com/getporter/porter/issues/new/choose"))
 	}
 
-	solver := &cnab.DependencySolver{}
+	solver := &cnab.DependencySolver{Policy: e.Data.DependencyPolicy}
 	locks, err := solver.ResolveDependencies(bun)
 	if err != nil {
 		return span.Error(err)
@@ -269,7 +421,10 @@ func (e *dependencyExecutioner) prepareDependency(ctx context.Context, dep *queu
 	return nil
 }
 
-func (e *dependencyExecutioner) executeDependency(ctx context.Context, dep *queuedDependency) error {
+// executeDependency runs the action on a dependency. The output of the
+// dependency is written to the output when it is set, otherwise to the output
+// of Porter.
+func (e *dependencyExecutioner) executeDependency(ctx context.Context, dep *queuedDependency, output *dependencyOutput) error {
 	// TODO(carolynvs): We should really switch up how the deperator works so that
 	// even the root bundle uses the execution engine here. This would set up how
 	// we want dependencies and mixins as bundles to work in the future.
@@ -293,7 +448,7 @@ func (e *dependencyExecutioner) executeDependency(ctx context.Context, dep *queu
 		}
 	}
 
-	finalParams, err := e.porter.finalizeParameters(ctx, depInstallation, dep.BundleReference.Definition, e.parentArgs.Action, dep.Parameters)
+	finalParams, outputDeps, err := e.porter.finalizeParameters(ctx, depInstallation, dep.BundleReference.Definition, e.parentArgs.Action, dep.Parameters, nil)
 	if err != nil {
 		return span.Error(fmt.Errorf("error resolving parameters for dependency %s: %w", dep.Alias, err))
 	}
@@ -303,9 +458,18 @@ func (e *dependencyExecutioner) executeDependency(ctx context.Context, dep *queu
 		Action:                e.parentArgs.Action,
 		Installation:          depInstallation,
 		Driver:                e.parentArgs.Driver,
+		DriverPolicy:          e.parentArgs.DriverPolicy,
 		AllowDockerHostAccess: e.parentOpts.AllowDockerHostAccess,
 		Params:                finalParams,
+		OutputDependencies:    outputDeps,
 		PersistLogs:           e.parentArgs.PersistLogs,
+		Timeout:               e.parentArgs.Timeout,
+		Labels:                e.parentArgs.Labels,
+		Events:                e.parentArgs.Events,
+	}
+	if output != nil {
+		depArgs.Out = output.Out
+		depArgs.Err = output.Err
 	}
 
 	// Determine if we're working with UninstallOptions, to inform deletion and
@@ -333,7 +497,7 @@ func (e *dependencyExecutioner) executeDependency(ctx context.Context, dep *queu
 	// will resolve to false and thus be a no-op
 	if uninstallOpts.shouldDelete() {
 		span.Infof(installationDeleteTmpl, depArgs.Installation)
-		return e.Installations.RemoveInstallation(ctx, depArgs.Installation.Namespace, depArgs.Installation.Name)
+		return e.porter.removeInstallation(ctx, depArgs.Installation.Namespace, depArgs.Installation.Name)
 	}
 	return nil
 }
@@ -0,0 +1,299 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	depsv1 "get.porter.sh/porter/pkg/cnab/dependencies/v1"
+	cnabprovider "get.porter.sh/porter/pkg/cnab/provider"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ cnabprovider.CNABProvider = &recordingCNABProvider{}
+
+// recordingCNABProvider records the order in which dependencies are executed.
+type recordingCNABProvider struct {
+	mu       sync.Mutex
+	started  []string
+	finished []string
+
+	// changeTickets are the change tickets that each dependency was executed with.
+	changeTickets map[string]string
+
+	// fail are the dependencies that fail when executed.
+	fail map[string]bool
+
+	// waitFor blocks the execution of a dependency until another dependency starts.
+	waitFor map[string]string
+	running map[string]chan struct{}
+}
+
+func newRecordingCNABProvider(aliases ...string) *recordingCNABProvider {
+	r := &recordingCNABProvider{
+		changeTickets: map[string]string{},
+		fail:          map[string]bool{},
+		waitFor:       map[string]string{},
+		running:       map[string]chan struct{}{},
+	}
+	for _, alias := range aliases {
+		r.running[alias] = make(chan struct{})
+	}
+	return r
+}
+
+func (r *recordingCNABProvider) LoadBundle(bundleFile string) (cnab.ExtendedBundle, error) {
+	return cnab.ExtendedBundle{}, errors.New("not implemented")
+}
+
+func (r *recordingCNABProvider) Execute(ctx context.Context, args cnabprovider.ActionArguments) error {
+	alias := args.Installation.Labels["alias"]
+
+	r.mu.Lock()
+	r.started = append(r.started, alias)
+	r.changeTickets[alias] = args.ChangeTicket
+	r.mu.Unlock()
+	close(r.running[alias])
+
+	// Write a line that is only completed after the other dependencies
+	// that run at the same time have started
+	if args.Out != nil {
+		fmt.Fprint(args.Out, "executing ")
+		defer fmt.Fprintln(args.Out, alias)
+	}
+
+	if other, ok := r.waitFor[alias]; ok {
+		select {
+		case <-r.running[other]:
+		case <-time.After(5 * time.Second):
+			return fmt.Errorf("%s was not executed at the same time as %s", other, alias)
+		}
+	}
+
+	r.mu.Lock()
+	r.finished = append(r.finished, alias)
+	r.mu.Unlock()
+
+	if r.fail[alias] {
+		return errors.New("oops")
+	}
+	return nil
+}
+
+// newTestDependencyExecutioner creates an executioner for the dependencies of
+// the mybuns installation, labeling the installation of each dependency with
+// its alias so that the provider can identify it.
+func newTestDependencyExecutioner(ctx context.Context, t *testing.T, p *TestPorter, action BundleAction, provider *recordingCNABProvider, deps ...*queuedDependency) *dependencyExecutioner {
+	opts := action.GetOptions()
+	opts.Name = "mybuns"
+
+	e := newDependencyExecutioner(p.Porter, p.TestInstallations.CreateInstallation(storage.NewInstallation("", "mybuns")), action)
+	e.CNAB = provider
+	e.parentArgs = cnabprovider.ActionArguments{Action: action.GetAction(), Installation: e.parentInstallation}
+	e.deps = deps
+
+	for _, dep := range deps {
+		inst := storage.NewInstallation("", depsv1.BuildPrerequisiteInstallationName("mybuns", dep.Alias))
+		inst.SetLabel("alias", dep.Alias)
+		require.NoError(t, p.Installations.InsertInstallation(ctx, inst))
+	}
+	return e
+}
+
+func newTestQueuedDependency(alias string, dependsOn ...string) *queuedDependency {
+	return &queuedDependency{
+		DependencyLock: cnab.DependencyLock{Alias: alias, DependsOn: dependsOn},
+	}
+}
+
+func TestDependencyExecutioner_Execute_Graph(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	provider := newRecordingCNABProvider("mysql", "redis", "app")
+	// mysql only completes when redis is executed at the same time
+	provider.waitFor["mysql"] = "redis"
+	e := newTestDependencyExecutioner(ctx, t, p, NewInstallOptions(), provider,
+		newTestQueuedDependency("mysql"),
+		newTestQueuedDependency("redis"),
+		newTestQueuedDependency("app", "mysql", "redis"))
+
+	err := e.Execute(ctx)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"mysql", "redis"}, provider.started[:2], "independent dependencies should be executed first")
+	assert.Equal(t, "app", provider.started[2], "app should be executed after the dependencies that it depends on")
+	assert.ElementsMatch(t, []string{"mysql", "redis"}, provider.finished[:2])
+
+	gotOutput := p.TestConfig.TestContext.GetOutput()
+	for _, alias := range []string{"mysql", "redis", "app"} {
+		assert.Contains(t, gotOutput, alias+" | executing "+alias+"\n", "the output of each dependency should be prefixed with its alias and not interleaved")
+	}
+}
+
+func TestDependencyExecutioner_Execute_ChangeTicket(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	provider := newRecordingCNABProvider("mysql")
+	e := newTestDependencyExecutioner(ctx, t, p, NewInstallOptions(), provider, newTestQueuedDependency("mysql"))
+	e.parentArgs.ChangeTicket = "CHG0001"
+
+	require.NoError(t, e.Execute(ctx))
+	assert.Empty(t, provider.changeTickets["mysql"], "only the run of the root bundle should transition the change ticket")
+}
+
+func TestDependencyExecutioner_Execute_GraphFailure(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	provider := newRecordingCNABProvider("mysql", "redis", "app", "cache")
+	provider.fail["mysql"] = true
+	e := newTestDependencyExecutioner(ctx, t, p, NewInstallOptions(), provider,
+		newTestQueuedDependency("mysql"),
+		newTestQueuedDependency("redis"),
+		newTestQueuedDependency("app", "mysql"),
+		newTestQueuedDependency("cache", "redis"))
+
+	err := e.Execute(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error executing dependency mysql: oops")
+	assert.Contains(t, err.Error(), "dependency app was not executed because mysql failed")
+	assert.ElementsMatch(t, []string{"mysql", "redis", "cache"}, provider.started, "the dependencies that do not depend on the failed dependency should still be executed")
+}
+
+func TestDependencyExecutioner_Execute_GraphUninstall(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	provider := newRecordingCNABProvider("mysql", "redis", "app")
+	provider.waitFor["mysql"] = "redis"
+	e := newTestDependencyExecutioner(ctx, t, p, NewUninstallOptions(), provider,
+		newTestQueuedDependency("mysql"),
+		newTestQueuedDependency("redis"),
+		newTestQueuedDependency("app", "mysql", "redis"))
+
+	err := e.Execute(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, "app", provider.started[0], "app should be uninstalled before the dependencies that it depends on")
+	assert.ElementsMatch(t, []string{"mysql", "redis"}, provider.started[1:])
+}
+
+func TestDependencyExecutioner_Execute_Serial(t *testing.T) {
+	testcases := []struct {
+		name        string
+		parallelism int
+		deps        []*queuedDependency
+	}{
+		{name: "no graph", parallelism: 4, deps: []*queuedDependency{
+			newTestQueuedDependency("mysql"),
+			newTestQueuedDependency("redis"),
+			newTestQueuedDependency("app"),
+		}},
+		{name: "parallelism disabled", parallelism: 1, deps: []*queuedDependency{
+			newTestQueuedDependency("mysql"),
+			newTestQueuedDependency("redis"),
+			newTestQueuedDependency("app", "mysql", "redis"),
+		}},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			p := NewTestPorter(t)
+			defer p.Close()
+			p.Data.DependencyParallelism = tc.parallelism
+
+			provider := newRecordingCNABProvider("mysql", "redis", "app")
+			provider.fail["mysql"] = true
+			e := newTestDependencyExecutioner(ctx, t, p, NewInstallOptions(), provider, tc.deps...)
+
+			err := e.Execute(ctx)
+			require.EqualError(t, err, "1 error occurred:\n\t* error executing dependency mysql: oops\n\n")
+			assert.Equal(t, []string{"mysql"}, provider.started, "dependencies should be executed one at a time, stopping at the first failure")
+		})
+	}
+}
+
+func TestDependencyExecutioner_Execute_InvalidGraph(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	provider := newRecordingCNABProvider("mysql", "app")
+	e := newTestDependencyExecutioner(ctx, t, p, NewInstallOptions(), provider,
+		newTestQueuedDependency("app", "mysql"),
+		newTestQueuedDependency("mysql"))
+
+	err := e.Execute(ctx)
+	require.ErrorContains(t, err, "dependency app depends on mysql, which must be listed before it")
+	assert.Empty(t, provider.started)
+}
+
+// TestDependencyExecutioner_Execute_GraphRuntime executes dependencies with
+// different docker requirements concurrently with the CNAB runtime, so that
+// running the tests with -race detects state shared between the executions.
+func TestDependencyExecutioner_Execute_GraphRuntime(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	newDependency := func(alias string, requireDocker bool, dependsOn ...string) *queuedDependency {
+		b := bundle.Bundle{
+			SchemaVersion: "v1.0.0",
+			Name:          alias,
+			Version:       "0.1.0",
+			InvocationImages: []bundle.InvocationImage{
+				{BaseImage: bundle.BaseImage{Image: "example.com/" + alias + ":v0.1.0", ImageType: "docker"}},
+			},
+		}
+		if requireDocker {
+			b.RequiredExtensions = []string{cnab.DockerExtensionKey}
+			b.Custom = map[string]interface{}{cnab.DockerExtensionKey: map[string]interface{}{"privileged": true}}
+		}
+		dep := newTestQueuedDependency(alias, dependsOn...)
+		dep.BundleReference = cnab.BundleReference{Definition: cnab.NewBundle(b)}
+		return dep
+	}
+
+	// Execute the dependencies many times, so that the executions are likely
+	// to overlap
+	p.Data.DependencyParallelism = 8
+	for i := 0; i < 50; i++ {
+		var deps []*queuedDependency
+		var aliases []string
+		for j := 0; j < 4; j++ {
+			docker := fmt.Sprintf("docker%d-%d", i, j)
+			plain := fmt.Sprintf("plain%d-%d", i, j)
+			deps = append(deps, newDependency(docker, true), newDependency(plain, false))
+			aliases = append(aliases, docker, plain)
+		}
+		deps = append(deps, newDependency(fmt.Sprintf("app%d", i), false, aliases...))
+
+		e := newTestDependencyExecutioner(ctx, t, p, NewInstallOptions(), nil, deps...)
+		e.CNAB = p.CNAB
+		e.parentArgs.Driver = cnabprovider.DriverNameDebug
+
+		err := e.Execute(ctx)
+		require.Error(t, err)
+		for j := 0; j < 4; j++ {
+			assert.Contains(t, err.Error(), fmt.Sprintf("error executing dependency docker%d-%d", i, j), "the dependency that requires docker should not be supported by the debug driver")
+			assert.NotContains(t, err.Error(), fmt.Sprintf("error executing dependency plain%d-%d", i, j), "the dependency that does not require docker should not be validated with the extensions of another dependency")
+		}
+
+		require.NoError(t, p.Installations.RemoveInstallation(ctx, "", "mybuns"))
+	}
+}
@@ -0,0 +1,74 @@
+package porter
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// dependencyOutput is where the output of a dependency is written.
+type dependencyOutput struct {
+	Out *dependencyOutputWriter
+	Err *dependencyOutputWriter
+}
+
+// Flush writes the last line of output, when it did not end with a newline.
+func (o *dependencyOutput) Flush() {
+	o.Out.Flush()
+	o.Err.Flush()
+}
+
+// dependencyOutputWriter prefixes each line of the output of a dependency with
+// its alias. Complete lines are written while holding a lock that is shared by
+// the dependencies that run at the same time, so that their output is not
+// interleaved within a line.
+type dependencyOutputWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix []byte
+
+	// line is the incomplete line that was written last.
+	line []byte
+}
+
+func newDependencyOutputWriter(mu *sync.Mutex, out io.Writer, alias string) *dependencyOutputWriter {
+	return &dependencyOutputWriter{
+		mu:     mu,
+		out:    out,
+		prefix: []byte(alias + " | "),
+	}
+}
+
+func (w *dependencyOutputWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.line = append(w.line, p...)
+	var buf bytes.Buffer
+	for {
+		i := bytes.IndexByte(w.line, '\n')
+		if i < 0 {
+			break
+		}
+		buf.Write(w.prefix)
+		buf.Write(w.line[:i+1])
+		w.line = w.line[i+1:]
+	}
+	// Do not hold onto the lines that were written
+	w.line = append([]byte(nil), w.line...)
+
+	if buf.Len() > 0 {
+		if _, err := w.out.Write(buf.Bytes()); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes the incomplete line, followed by a newline.
+func (w *dependencyOutputWriter) Flush() {
+	if len(w.line) == 0 {
+		return
+	}
+	w.Write([]byte{'\n'})
+}
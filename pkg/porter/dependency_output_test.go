@@ -0,0 +1,27 @@
+package porter
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDependencyOutputWriter(t *testing.T) {
+	var mu sync.Mutex
+	var out bytes.Buffer
+	mysql := newDependencyOutputWriter(&mu, &out, "mysql")
+	redis := newDependencyOutputWriter(&mu, &out, "redis")
+
+	fmt.Fprint(mysql, "installing ")
+	fmt.Fprint(redis, "installing redis\nstarting ")
+	fmt.Fprint(mysql, "mysql\n")
+	fmt.Fprint(redis, "redis")
+	assert.Equal(t, "redis | installing redis\nmysql | installing mysql\n", out.String(), "only complete lines should be written")
+
+	redis.Flush()
+	mysql.Flush()
+	assert.Equal(t, "redis | installing redis\nmysql | installing mysql\nredis | starting redis\n", out.String(), "the incomplete line should be written when the output is flushed")
+}
@@ -18,7 +18,7 @@ func ExamplePorter_captureOutput() {
 	p.Out = &output
 
 	// porter schema
-	err := p.PrintManifestSchema(context.Background())
+	err := p.PrintManifestSchema(context.Background(), porter.SchemaOptions{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -2,11 +2,13 @@ package porter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strconv"
 	"strings"
 
+	"get.porter.sh/porter/pkg/archive"
 	"get.porter.sh/porter/pkg/cnab"
 	configadapter "get.porter.sh/porter/pkg/cnab/config-adapter"
 	"get.porter.sh/porter/pkg/portercontext"
@@ -19,6 +21,14 @@ type ExplainOpts struct {
 	printer.PrintOptions
 
 	Action string
+
+	// ArchiveFile is the path to a bundle archive to explain.
+	ArchiveFile string
+
+	// DecryptKeyRef is a reference to the key in the secret store, of the form
+	// secret://NAME, used to decrypt an encrypted archive. Defaults to the key
+	// recorded in the archive when it was encrypted.
+	DecryptKeyRef string
 }
 
 // PrintableBundle holds a subset of pertinent values to be explained from a bundle
@@ -147,6 +157,28 @@ func (o *ExplainOpts) Validate(args []string, pctx *portercontext.Context) error
 	if err != nil {
 		return err
 	}
+
+	if o.DecryptKeyRef != "" {
+		if o.ArchiveFile == "" {
+			return errors.New("--decrypt-key-ref can only be used with --archive")
+		}
+		if _, err := archive.ParseKeyRef(o.DecryptKeyRef); err != nil {
+			return fmt.Errorf("invalid --decrypt-key-ref: %w", err)
+		}
+	}
+
+	if o.ArchiveFile != "" {
+		if o.Reference != "" {
+			return errors.New("cannot specify both a bundle reference and --archive")
+		}
+		if _, err := pctx.FileSystem.Stat(o.ArchiveFile); err != nil {
+			return fmt.Errorf("unable to access --archive %s: %w", o.ArchiveFile, err)
+		}
+		o.File = ""
+		o.CNABFile = ""
+		return nil
+	}
+
 	if o.Reference != "" {
 		o.File = ""
 		o.CNABFile = ""
@@ -157,7 +189,13 @@ func (o *ExplainOpts) Validate(args []string, pctx *portercontext.Context) error
 }
 
 func (p *Porter) Explain(ctx context.Context, o ExplainOpts) error {
-	bundleRef, err := o.GetBundleReference(ctx, p)
+	var bundleRef cnab.BundleReference
+	var err error
+	if o.ArchiveFile != "" {
+		bundleRef, err = p.readArchiveBundle(ctx, p.FileSystem.Abs(o.ArchiveFile), o.DecryptKeyRef)
+	} else {
+		bundleRef, err = o.GetBundleReference(ctx, p)
+	}
 	if err != nil {
 		return err
 	}
@@ -232,33 +270,28 @@ func generatePrintable(bun cnab.ExtendedBundle, action string) (*PrintableBundle
 	}
 	sort.Sort(SortPrintableCredential(pb.Credentials))
 
-	for p, v := range bun.Parameters {
-		v := v // Go closures are funny like that
-		if bun.IsInternalParameter(p) || bun.ParameterHasSource(p) {
+	for _, param := range bun.ListParameters() {
+		if param.Internal || param.HasSource {
 			continue
 		}
 
-		def, ok := bun.Definitions[v.Definition]
-		if !ok {
+		v := param.Parameter
+		if param.Definition == nil {
 			return nil, fmt.Errorf("unable to find definition %s", v.Definition)
 		}
-		if def == nil {
-			return nil, fmt.Errorf("empty definition for %s", v.Definition)
-		}
 		pp := PrintableParameter{param: &v}
-		pp.Name = p
-		pp.Type = bun.GetParameterType(def)
-		pp.Default = def.Default
+		pp.Name = param.Name
+		pp.Type = param.Type
+		pp.Default = param.Definition.Default
 		pp.ApplyTo = generateApplyToString(v.ApplyTo)
-		pp.Required = v.Required
+		pp.Required = param.Required
 		pp.Description = v.Description
-		pp.Sensitive = bun.IsSensitiveParameter((p))
+		pp.Sensitive = param.Sensitive
 
 		if shouldIncludeInExplainOutput(&v, action) {
 			pb.Parameters = append(pb.Parameters, pp)
 		}
 	}
-	sort.Sort(SortPrintableParameter(pb.Parameters))
 
 	for o, v := range bun.Outputs {
 		if bun.IsInternalOutput(o) {
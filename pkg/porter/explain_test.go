@@ -1,12 +1,16 @@
 package porter
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
 	"fmt"
 	"testing"
 
 	"get.porter.sh/porter/pkg/cnab"
 	depsv1 "get.porter.sh/porter/pkg/cnab/dependencies/v1"
 	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/secrets"
 	"get.porter.sh/porter/pkg/test"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-go/bundle/definition"
@@ -35,6 +39,33 @@ func TestExplain_ValidateReference(t *testing.T) {
 	})
 }
 
+func TestExplain_Archive(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("k"), 32))
+	require.NoError(t, p.TestSecrets.Create(ctx, secrets.SourceSecret, "archive-key", key))
+	writeTestArchive(t, p, "/mybuns.tgz", "secret://archive-key")
+
+	opts := ExplainOpts{ArchiveFile: "/mybuns.tgz", DecryptKeyRef: "secret://archive-key"}
+	opts.RawFormat = "json"
+	err := opts.Validate(nil, p.Context)
+	require.NoError(t, err)
+
+	err = p.Explain(ctx, opts)
+	require.NoError(t, err)
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), `"name": "mybuns"`)
+
+	opts = ExplainOpts{ArchiveFile: "/mybuns.tgz"}
+	err = opts.Validate([]string{"myreg/mybuns:v0.1.0"}, p.Context)
+	require.EqualError(t, err, "cannot specify both a bundle reference and --archive")
+
+	opts = ExplainOpts{DecryptKeyRef: "secret://archive-key"}
+	err = opts.Validate(nil, p.Context)
+	require.EqualError(t, err, "--decrypt-key-ref can only be used with --archive")
+}
+
 func TestExplain_validateBadFormat(t *testing.T) {
 	p := NewTestPorter(t)
 	defer p.Close()
@@ -0,0 +1,110 @@
+package porter
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"get.porter.sh/porter/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// ExternalCommandPrefix is the prefix of executables that extend the porter
+	// CLI. For example, "porter foo" runs an executable named porter-foo.
+	ExternalCommandPrefix = "porter-"
+
+	// EnvPorterExecutable is the name of the environment variable containing
+	// the path to the porter binary that called an external command.
+	EnvPorterExecutable = "PORTER_EXECUTABLE"
+)
+
+// FindExternalCommand looks for an executable named porter-NAME that
+// implements the porter NAME command. PORTER_HOME is searched first, followed
+// by the directories in PATH.
+func (p *Porter) FindExternalCommand(name string) (string, bool) {
+	// Don't allow the name to escape the search directories, e.g. porter ../foo
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+
+	executable := ExternalCommandPrefix + name + pkgmgmt.FileExt
+
+	if home, err := p.GetHomeDir(); err == nil {
+		cmdPath := filepath.Join(home, executable)
+		if exists, _ := p.FileSystem.Exists(cmdPath); exists {
+			return cmdPath, true
+		}
+	}
+
+	return p.LookPath(executable)
+}
+
+// RunExternalCommand executes an external command, passing it the remaining
+// arguments. The command's environment includes the porter home directory, the
+// porter binary, and the namespace, storage and secrets configuration so that
+// it can call back into porter using the same context.
+func (p *Porter) RunExternalCommand(ctx context.Context, cmdPath string, args []string) error {
+	ctx, span := tracing.StartSpan(ctx,
+		attribute.String("command", cmdPath),
+		attribute.String("args", strings.Join(args, " ")))
+	defer span.EndSpan()
+
+	env, err := p.getExternalCommandEnv(ctx)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	cmd := p.NewCommand(ctx, cmdPath, args...)
+	cmd.Env = append(cmd.Env, env...)
+	cmd.Stdin = p.In
+	cmd.Stdout = p.Out
+	cmd.Stderr = p.Err
+
+	if err = cmd.Run(); err != nil {
+		return span.Error(fmt.Errorf("external command %s failed: %w", filepath.Base(cmdPath), err))
+	}
+	return nil
+}
+
+// getExternalCommandEnv returns the environment variables that describe the
+// current porter configuration to an external command.
+func (p *Porter) getExternalCommandEnv(ctx context.Context) ([]string, error) {
+	home, err := p.GetHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	porterPath, err := p.GetPorterPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	env := []string{
+		fmt.Sprintf("%s=%s", config.EnvHOME, home),
+		fmt.Sprintf("%s=%s", EnvPorterExecutable, porterPath),
+		fmt.Sprintf("PORTER_NAMESPACE=%s", p.Data.Namespace),
+	}
+
+	// Only pass the plugin configuration when set, otherwise the external
+	// command would override the defaults with empty values when calling porter
+	optional := []struct {
+		key   string
+		value string
+	}{
+		{"PORTER_DEFAULT_STORAGE", p.Data.DefaultStorage},
+		{"PORTER_DEFAULT_STORAGE_PLUGIN", p.Data.DefaultStoragePlugin},
+		{"PORTER_DEFAULT_SECRETS", p.Data.DefaultSecrets},
+		{"PORTER_DEFAULT_SECRETS_PLUGIN", p.Data.DefaultSecretsPlugin},
+	}
+	for _, e := range optional {
+		if e.value != "" {
+			env = append(env, fmt.Sprintf("%s=%s", e.key, e.value))
+		}
+	}
+
+	return env, nil
+}
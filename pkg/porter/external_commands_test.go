@@ -0,0 +1,66 @@
+package porter
+
+import (
+	"testing"
+
+	"get.porter.sh/porter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPorter_FindExternalCommand(t *testing.T) {
+	t.Parallel()
+
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.Setenv("PATH", "/usr/local/bin")
+	p.FileSystem.Create("/home/myuser/.porter/porter-audit")
+	p.FileSystem.Create("/usr/local/bin/porter-audit")
+	p.FileSystem.Create("/usr/local/bin/porter-report")
+
+	t.Run("in PORTER_HOME", func(t *testing.T) {
+		cmdPath, ok := p.FindExternalCommand("audit")
+		require.True(t, ok)
+		assert.Equal(t, "/home/myuser/.porter/porter-audit", cmdPath, "PORTER_HOME should be searched before PATH")
+	})
+
+	t.Run("on PATH", func(t *testing.T) {
+		cmdPath, ok := p.FindExternalCommand("report")
+		require.True(t, ok)
+		assert.Equal(t, "/usr/local/bin/porter-report", cmdPath)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, ok := p.FindExternalCommand("missing")
+		assert.False(t, ok)
+	})
+
+	t.Run("path traversal", func(t *testing.T) {
+		_, ok := p.FindExternalCommand("../bin/porter-report")
+		assert.False(t, ok)
+	})
+}
+
+func TestPorter_getExternalCommandEnv(t *testing.T) {
+	t.Parallel()
+
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.SetPorterPath("/usr/local/bin/porter")
+	p.Data.Namespace = "dev"
+	p.Data.DefaultStorage = "mydb"
+	p.Data.DefaultSecrets = ""
+
+	env, err := p.getExternalCommandEnv(p.RootContext)
+	require.NoError(t, err)
+
+	assert.Contains(t, env, config.EnvHOME+"=/home/myuser/.porter")
+	assert.Contains(t, env, EnvPorterExecutable+"=/usr/local/bin/porter")
+	assert.Contains(t, env, "PORTER_NAMESPACE=dev")
+	assert.Contains(t, env, "PORTER_DEFAULT_STORAGE=mydb")
+	for _, e := range env {
+		assert.NotContains(t, e, "PORTER_DEFAULT_SECRETS=", "unset configuration should not be passed to the external command")
+	}
+}
@@ -24,6 +24,9 @@ import (
 type metadataOpts struct {
 	Name    string
 	Version string
+
+	// Environment is the name of the environment from the manifest to apply.
+	Environment string
 }
 
 // generateInternalManifest decodes the manifest designated by filepath and applies
@@ -64,6 +67,10 @@ func (p *Porter) generateInternalManifest(ctx context.Context, opts BuildOptions
 		}
 	}
 
+	if err = applyManifestEnvironment(e, opts.Environment); err != nil {
+		return span.Error(err)
+	}
+
 	// find all referenced images that does not have digest specified
 	// get the image digest for all of them and update the manifest with the digest
 	err = e.WalkNodes(ctx, "images.*", func(ctx context.Context, nc *yqlib.NodeContext) error {
@@ -115,6 +122,78 @@ func (p *Porter) generateInternalManifest(ctx context.Context, opts BuildOptions
 	return e.WriteFile(build.LOCAL_MANIFEST)
 }
 
+// applyManifestEnvironment applies the overrides from the named environment
+// in the manifest's environments section. When no environment is specified,
+// the default environment declared by the manifest, if any, is used.
+// Images that are relocated by the environment without a digest have their
+// digest removed so that it is resolved again.
+func applyManifestEnvironment(e *yaml.Editor, name string) error {
+	if name == "" {
+		node, err := e.GetNode("environment")
+		if err != nil || node.Value == "" {
+			return nil
+		}
+		name = node.Value
+	}
+
+	envNode, err := e.GetNode("environments." + name)
+	if err != nil || envNode.IsZero() {
+		return fmt.Errorf("environment %s is not defined in the environments section of the manifest", name)
+	}
+
+	var env manifest.Environment
+	if err = envNode.Decode(&env); err != nil {
+		return fmt.Errorf("invalid environment %s: %w", name, err)
+	}
+
+	// Record the environment so that it's included in the bundle
+	if err = e.SetValue("environment", name); err != nil {
+		return err
+	}
+
+	if env.Registry != "" {
+		if err = e.SetValue("registry", env.Registry); err != nil {
+			return err
+		}
+	}
+
+	if env.Reference != "" {
+		if err = e.SetValue("reference", env.Reference); err != nil {
+			return err
+		}
+	}
+
+	for imageName, img := range env.Images {
+		imagePath := "images." + imageName
+		if img.Repository != "" {
+			if err = e.SetValue(imagePath+".repository", img.Repository); err != nil {
+				return err
+			}
+		}
+		if img.Tag != "" {
+			if err = e.SetValue(imagePath+".tag", img.Tag); err != nil {
+				return err
+			}
+		}
+		if img.Digest != "" {
+			err = e.SetValue(imagePath+".digest", img.Digest)
+		} else if img.Repository != "" || img.Tag != "" {
+			err = e.DeleteNode(imagePath + ".digest")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for paramName, value := range env.Parameters {
+		if err = e.SetNodeValue(fmt.Sprintf("parameters.(name==%s).default", paramName), value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (p *Porter) getImageLatestDigest(ctx context.Context, img cnab.OCIReference) (digest.Digest, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.EndSpan()
@@ -94,6 +94,60 @@ func Test_generateInternalManifest(t *testing.T) {
 	}
 }
 
+func Test_generateInternalManifest_Environment(t *testing.T) {
+	testcases := []struct {
+		name         string
+		env          string
+		wantErr      string
+		wantManifest string
+	}{{
+		name:         "no environment",
+		wantManifest: "environments-none.yaml",
+	}, {
+		name:         "environment set",
+		env:          "prod",
+		wantManifest: "environments-prod.yaml",
+	}, {
+		name:    "undefined environment",
+		env:     "staging",
+		wantErr: "environment staging is not defined in the environments section of the manifest",
+	}}
+
+	p := NewTestPorter(t)
+	defer p.Close()
+	p.TestRegistry.MockGetCachedImage = func(ctx context.Context, ref cnab.OCIReference) (cnabtooci.ImageSummary, error) {
+		sum := types.ImageInspect{
+			ID:          "test-id",
+			RepoDigests: []string{ref.Repository() + "@sha256:8b92b7269f59e3ed824e811a1ff1ee64f0d44c0218efefada57a4bebc2d7ef6f"},
+		}
+		return cnabtooci.NewImageSummary(ref.String(), sum)
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			p.TestConfig.TestContext.AddTestFile("testdata/generateManifest/environments.yaml", config.Name)
+
+			opts := BuildOptions{metadataOpts: metadataOpts{Environment: tc.env}}
+			err := opts.Validate(p.Porter)
+			require.NoError(t, err)
+
+			err = p.generateInternalManifest(context.Background(), opts)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			goldenFile := filepath.Join("testdata/generateManifest", tc.wantManifest)
+			p.TestConfig.TestContext.AddTestFile(goldenFile, tc.wantManifest)
+			got, err := p.FileSystem.ReadFile(build.LOCAL_MANIFEST)
+			require.NoError(t, err)
+			test.CompareGoldenFile(t, goldenFile, string(got))
+		})
+	}
+}
+
 func mockPullImageFailure(ctx context.Context, ref cnab.OCIReference, opts cnabtooci.RegistryOptions) error {
 	return fmt.Errorf("failed to pull image %s", ref)
 }
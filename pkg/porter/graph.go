@@ -0,0 +1,137 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// GraphAllowedFormats are the formats that the installation graph may be printed in.
+var GraphAllowedFormats = printer.Formats{printer.FormatPlaintext, printer.FormatJson, printer.FormatYaml, printer.FormatDot}
+
+// InstallationGraphOptions are the options for printing the dependency graph
+// of installations.
+type InstallationGraphOptions struct {
+	printer.PrintOptions
+
+	// Namespace of the installations in the graph, and of the installation
+	// when a name is specified.
+	Namespace string
+
+	// AllNamespaces includes the installations from all namespaces in the graph.
+	AllNamespaces bool
+
+	// Name of an installation. When set, the graph only includes the
+	// dependencies of the installation and what consumes its outputs.
+	Name string
+
+	// Consumers limits the graph to what consumes the outputs of the
+	// installation.
+	Consumers bool
+}
+
+// Validate the graph options and the optional installation name argument.
+func (o *InstallationGraphOptions) Validate(args []string) error {
+	switch len(args) {
+	case 0:
+	case 1:
+		o.Name = args[0]
+	default:
+		return fmt.Errorf("only one positional argument may be specified, the installation name, but multiple were received: %s", args)
+	}
+
+	if o.Consumers && o.Name == "" {
+		return errors.New("--consumers requires an installation name")
+	}
+
+	return o.PrintOptions.Validate(printer.FormatPlaintext, GraphAllowedFormats)
+}
+
+// GetInstallationGraph builds the graph of the dependencies between
+// installations, formed by the outputs that they use to set their parameters.
+func (p *Porter) GetInstallationGraph(ctx context.Context, opts InstallationGraphOptions) (storage.InstallationGraph, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	// Outputs may be shared with other namespaces, so look for what consumes
+	// an installation's outputs in every namespace
+	namespace := opts.Namespace
+	if opts.AllNamespaces || opts.Name != "" {
+		namespace = "*"
+	}
+
+	graph, err := storage.BuildInstallationGraph(ctx, p.Installations, storage.ListOptions{Namespace: namespace})
+	if err != nil {
+		return storage.InstallationGraph{}, span.Error(err)
+	}
+
+	if opts.Name == "" {
+		return graph, nil
+	}
+
+	node := storage.InstallationNode{Namespace: opts.Namespace, Name: opts.Name}
+	if _, err = p.Installations.GetInstallation(ctx, node.Namespace, node.Name); err != nil {
+		return storage.InstallationGraph{}, span.Error(fmt.Errorf("could not retrieve installation %s: %w", node, err))
+	}
+
+	var focused storage.InstallationGraph
+	focused.AddNode(node)
+	edges := graph.Consumers(node)
+	if !opts.Consumers {
+		edges = append(edges, graph.Dependencies(node)...)
+	}
+	for _, edge := range edges {
+		focused.AddEdge(edge)
+	}
+	focused.Sort()
+	return focused, nil
+}
+
+// PrintInstallationGraph prints the graph of the dependencies between installations.
+func (p *Porter) PrintInstallationGraph(ctx context.Context, opts InstallationGraphOptions) error {
+	graph, err := p.GetInstallationGraph(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, graph)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, graph)
+	case printer.FormatDot:
+		return printInstallationGraphDot(p.Out, graph)
+	case printer.FormatPlaintext:
+		row := func(v interface{}) []string {
+			edge, ok := v.(storage.InstallationEdge)
+			if !ok {
+				return nil
+			}
+			return []string{edge.Consumer.String(), edge.Parameter, edge.Producer.String(), edge.Output}
+		}
+		return printer.PrintTable(p.Out, graph.Edges, row, "Consumer", "Parameter", "Producer", "Output")
+	default:
+		return fmt.Errorf("invalid format: %s", opts.Format)
+	}
+}
+
+// printInstallationGraphDot renders the graph in the graphviz dot language.
+// The edges point from the installation that generated an output to the
+// installation that consumes it.
+func printInstallationGraphDot(out io.Writer, graph storage.InstallationGraph) error {
+	fmt.Fprintln(out, "digraph installations {")
+	for _, node := range graph.Nodes {
+		fmt.Fprintf(out, "  %q;\n", node.String())
+	}
+	for _, edge := range graph.Edges {
+		label := edge.Output + " -> " + edge.Parameter
+		fmt.Fprintf(out, "  %q -> %q [label=%q];\n", edge.Producer.String(), edge.Consumer.String(), label)
+	}
+	_, err := fmt.Fprintln(out, "}")
+	return err
+}
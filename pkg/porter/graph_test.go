@@ -0,0 +1,104 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationGraphOptions_Validate(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		opts := InstallationGraphOptions{}
+		require.NoError(t, opts.Validate(nil))
+		assert.Equal(t, printer.FormatPlaintext, opts.Format)
+	})
+
+	t.Run("dot", func(t *testing.T) {
+		opts := InstallationGraphOptions{}
+		opts.RawFormat = "dot"
+		require.NoError(t, opts.Validate([]string{"network"}))
+		assert.Equal(t, printer.FormatDot, opts.Format)
+		assert.Equal(t, "network", opts.Name)
+	})
+
+	t.Run("consumers requires a name", func(t *testing.T) {
+		opts := InstallationGraphOptions{Consumers: true}
+		require.EqualError(t, opts.Validate(nil), "--consumers requires an installation name")
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		opts := InstallationGraphOptions{}
+		opts.RawFormat = "svg"
+		require.EqualError(t, opts.Validate(nil), "invalid format: svg")
+	})
+}
+
+func TestPorter_PrintInstallationGraph(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) *TestPorter {
+		p := NewTestPorter(t)
+
+		network := p.TestInstallations.CreateInstallation(storage.NewInstallation("platform", "network"))
+		run := p.TestInstallations.CreateRun(network.NewRun(cnab.ActionInstall))
+		p.TestInstallations.CreateResult(run.NewResult(cnab.StatusSucceeded))
+
+		for _, namespace := range []string{"dev", "platform"} {
+			i := storage.NewInstallation(namespace, "myapp")
+			run := i.NewRun(cnab.ActionInstall)
+			run.OutputDependencies = []storage.RunOutputDependency{
+				{Parameter: "vpcId", Namespace: "platform", Installation: "network", Output: "vpc-id"},
+			}
+			i.Status.RunID = run.ID
+			p.TestInstallations.CreateInstallation(i)
+			p.TestInstallations.CreateRun(run)
+		}
+		return p
+	}
+
+	t.Run("dot", func(t *testing.T) {
+		p := setup(t)
+		defer p.Close()
+
+		opts := InstallationGraphOptions{Namespace: "platform"}
+		opts.Format = printer.FormatDot
+		require.NoError(t, p.PrintInstallationGraph(ctx, opts))
+
+		want := `digraph installations {
+  "platform/myapp";
+  "platform/network";
+  "platform/network" -> "platform/myapp" [label="vpc-id -> vpcId"];
+}
+`
+		assert.Equal(t, want, p.TestConfig.TestContext.GetOutput())
+	})
+
+	t.Run("consumers", func(t *testing.T) {
+		p := setup(t)
+		defer p.Close()
+
+		opts := InstallationGraphOptions{Namespace: "platform", Name: "network", Consumers: true}
+		opts.Format = printer.FormatPlaintext
+		require.NoError(t, p.PrintInstallationGraph(ctx, opts))
+
+		output := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, output, "dev/myapp")
+		assert.Contains(t, output, "platform/myapp")
+		assert.Contains(t, output, "vpcId")
+	})
+
+	t.Run("installation not found", func(t *testing.T) {
+		p := setup(t)
+		defer p.Close()
+
+		opts := InstallationGraphOptions{Namespace: "dev", Name: "network"}
+		opts.Format = printer.FormatPlaintext
+		err := p.PrintInstallationGraph(ctx, opts)
+		require.ErrorIs(t, err, storage.ErrNotFound{})
+	})
+}
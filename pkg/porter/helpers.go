@@ -21,7 +21,9 @@ import (
 	"get.porter.sh/porter/pkg/mixin"
 	"get.porter.sh/porter/pkg/plugins"
 	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/secrets/audit"
 	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/storage/migrations"
 	"get.porter.sh/porter/pkg/tracing"
 	"get.porter.sh/porter/pkg/yaml"
 	"github.com/cnabio/cnab-go/bundle"
@@ -66,7 +68,22 @@ func NewTestPorter(t *testing.T) *TestPorter {
 	testParameters := storage.NewTestParameterProviderFor(t, testStore, testSecrets)
 	testCache := cache.NewTestCache(cache.New(tc.Config))
 	testInstallations := storage.NewTestInstallationProviderFor(t, testStore)
+	testInstallations.SetOutputAccessControl(storage.NewNamespacePolicyAccessControl(tc.Config))
+	testInstallations.SetOutputOffload(storage.NewConfigOutputOffload(tc.Config))
+	testInstallations.SetRunLedger(storage.NewConfigRunLedger(tc.Config))
+	testInstallations.SetRunMigrations(migrations.RunMigrations)
+	testInstallations.SetIDStrategy(storage.NewConfigIDStrategy(tc.Config))
+	testAuthorizer := storage.NewConfigAuthorizer(tc.Config)
+	testInstallations.SetAuthorizer(testAuthorizer)
+	testCredentials.SetAuthorizer(testAuthorizer)
+	testParameters.SetAuthorizer(testAuthorizer)
 	testRegistry := cnabtooci.NewTestRegistry()
+	testSanitizer := storage.NewSanitizer(testParameters, testSecrets)
+	testSanitizer.SetSensitivityPolicy(storage.NewConfigSensitivityPolicy(tc.Config))
+	testAuditHook := audit.NewConfigHook(tc.Config)
+	testSanitizer.SetAuditHook(testAuditHook)
+	testCredentials.SetAuditHook(testAuditHook)
+	testParameters.SetAuditHook(testAuditHook)
 
 	p := NewFor(tc.Config, testStore, testSecrets)
 	p.Config = tc.Config
@@ -77,6 +94,9 @@ func NewTestPorter(t *testing.T) *TestPorter {
 	p.Installations = testInstallations
 	p.Credentials = testCredentials
 	p.Parameters = testParameters
+	testSchedules := storage.NewScheduleStore(testStore)
+	testSchedules.SetAuthorizer(testAuthorizer)
+	p.Schedules = testSchedules
 	p.Secrets = testSecrets
 	p.CNAB = cnabprovider.NewTestRuntimeFor(tc, testInstallations, testCredentials, testParameters, testSecrets)
 	p.Registry = testRegistry
@@ -91,7 +111,7 @@ func NewTestPorter(t *testing.T) *TestPorter {
 		TestParameters:    testParameters,
 		TestCache:         testCache,
 		TestRegistry:      testRegistry,
-		TestSanitizer:     storage.NewSanitizer(testParameters, testSecrets),
+		TestSanitizer:     testSanitizer,
 		RepoRoot:          tc.TestContext.FindRepoRoot(),
 	}
 
@@ -17,7 +17,7 @@ var _ BundleAction = NewInstallOptions()
 type InstallOptions struct {
 	*BundleExecutionOptions
 
-	// Labels to apply to the installation.
+	// Labels to apply to the installation and its run.
 	Labels []string
 }
 
@@ -60,6 +60,16 @@ func (p *Porter) InstallBundle(ctx context.Context, opts InstallOptions) error {
 	ctx, log := tracing.StartSpan(ctx)
 	defer log.EndSpan()
 
+	if opts.Remote != "" {
+		return p.executeRemote(ctx, cnab.ActionInstall, opts)
+	}
+
+	unlock, err := p.lockInstallation(ctx, opts.Namespace, opts.Name, opts.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	i, err := p.Installations.GetInstallation(ctx, opts.Namespace, opts.Name)
 	if err == nil {
 		// Validate that we are not overwriting an existing installation
@@ -70,6 +80,9 @@ func (p *Porter) InstallBundle(ctx context.Context, opts InstallOptions) error {
 	} else if errors.Is(err, storage.ErrNotFound{}) {
 		// Create the installation record
 		i = storage.NewInstallation(opts.Namespace, opts.Name)
+		if err = p.applyNamespaceDefaults(ctx, &i); err != nil {
+			return log.Error(err)
+		}
 	} else {
 		err = fmt.Errorf("could not retrieve the installation record: %w", err)
 		return log.Error(err)
@@ -0,0 +1,160 @@
+package porter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// ImportSourceArchive imports an installation, and its history, from an
+// archive created by porter installations export.
+const ImportSourceArchive = "archive"
+
+// InstallationExportOptions are the options for exporting an installation,
+// and its history, to an archive.
+type InstallationExportOptions struct {
+	// Namespace of the installation.
+	Namespace string
+
+	// Name of the installation.
+	Name string
+
+	// File where the archive is written. Defaults to NAME.json.
+	File string
+
+	// Anonymize replaces the namespaces, installation names, registries and
+	// parameter names in the archive with pseudonyms.
+	Anonymize bool
+
+	// MappingFile is where the pseudonyms used to anonymize the archive are
+	// written. When the file exists, its pseudonyms are reused so that
+	// they are stable across exports. Defaults to FILE-mapping.json.
+	MappingFile string
+}
+
+func (o *InstallationExportOptions) Validate(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a single argument, the name of the installation, but got %d", len(args))
+	}
+	o.Name = args[0]
+
+	if o.File == "" {
+		o.File = o.Name + ".json"
+	}
+
+	if o.MappingFile != "" && !o.Anonymize {
+		return errors.New("--mapping-file can only be used with --anonymize")
+	}
+	if o.Anonymize && o.MappingFile == "" {
+		o.MappingFile = strings.TrimSuffix(o.File, filepath.Ext(o.File)) + "-mapping.json"
+	}
+	if o.Anonymize && filepath.Clean(o.MappingFile) == filepath.Clean(o.File) {
+		return errors.New("--mapping-file must be different from --file, the mapping must not be shared with the archive")
+	}
+	return nil
+}
+
+// ExportInstallation writes an installation, and its runs, results, outputs
+// and step results, to an archive that can be imported into another storage
+// backend with porter installations import archive.
+func (p *Porter) ExportInstallation(ctx context.Context, opts InstallationExportOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	archive, err := p.Installations.ExportInstallation(ctx, opts.Namespace, opts.Name)
+	if err != nil {
+		return span.Error(fmt.Errorf("could not export installation %s/%s: %w", opts.Namespace, opts.Name, err))
+	}
+
+	var pseudonyms storage.Pseudonyms
+	if opts.Anonymize {
+		if pseudonyms, err = p.readPseudonyms(opts.MappingFile); err != nil {
+			return span.Error(err)
+		}
+		archive = archive.Anonymize(&pseudonyms)
+	}
+
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return span.Error(fmt.Errorf("could not marshal the archive of installation %s: %w", archive.Installation, err))
+	}
+
+	if err = p.FileSystem.WriteFile(opts.File, data, pkg.FileModeWritable); err != nil {
+		return span.Error(fmt.Errorf("could not write %s: %w", opts.File, err))
+	}
+
+	fmt.Fprintf(p.Out, "Exported installation %s with %d runs to %s\n", archive.Installation, len(archive.Runs), opts.File)
+	if opts.Anonymize {
+		data, err = json.MarshalIndent(pseudonyms, "", "  ")
+		if err != nil {
+			return span.Error(fmt.Errorf("could not marshal the pseudonyms: %w", err))
+		}
+		if err = p.FileSystem.WriteFile(opts.MappingFile, data, pkg.FileModeWritable); err != nil {
+			return span.Error(fmt.Errorf("could not write %s: %w", opts.MappingFile, err))
+		}
+		fmt.Fprintf(p.Out, "The names in the archive were replaced with pseudonyms. The mapping to the original names was written to %s, do not share it with the archive.\n", opts.MappingFile)
+	}
+	if len(archive.SecretReferences) > 0 {
+		fmt.Fprintln(p.Out, "The archive references the following secrets, which must be available in the secret store used by the Porter that imports it:")
+		for _, key := range archive.SecretReferences {
+			fmt.Fprintf(p.Out, "  - %s\n", key)
+		}
+	}
+	return nil
+}
+
+// readPseudonyms reads the pseudonyms from a previous export, so that names
+// are replaced with the same pseudonyms. No pseudonyms are returned when the
+// file does not exist.
+func (p *Porter) readPseudonyms(file string) (storage.Pseudonyms, error) {
+	var pseudonyms storage.Pseudonyms
+	exists, err := p.FileSystem.Exists(file)
+	if err != nil || !exists {
+		return pseudonyms, err
+	}
+
+	data, err := p.FileSystem.ReadFile(file)
+	if err != nil {
+		return pseudonyms, fmt.Errorf("could not read %s: %w", file, err)
+	}
+	if err = json.Unmarshal(data, &pseudonyms); err != nil {
+		return pseudonyms, fmt.Errorf("could not parse the pseudonyms in %s: %w", file, err)
+	}
+	return pseudonyms, nil
+}
+
+// importInstallationArchive saves the installation, and its history, from an
+// archive created by ExportInstallation.
+func (p *Porter) importInstallationArchive(ctx context.Context, data []byte, opts InstallationImportOptions) error {
+	var archive storage.InstallationArchive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return fmt.Errorf("could not parse the installation archive %s: %w", opts.File, err)
+	}
+
+	// The installation is restored as-is, the namespace is only used to double-check the destination
+	if opts.Namespace != "" && opts.Namespace != archive.Installation.Namespace {
+		return fmt.Errorf("the archive contains installation %s which is not in the %s namespace", archive.Installation, opts.Namespace)
+	}
+
+	if err := p.Installations.ImportInstallation(ctx, archive); err != nil {
+		return fmt.Errorf("could not import installation from %s: %w", opts.File, err)
+	}
+
+	fmt.Fprintf(p.Out, "Imported installation %s with %d runs\n", archive.Installation, len(archive.Runs))
+	return nil
+}
+
+// validateArchiveImport checks the options that apply when importing an archive.
+func (o *InstallationImportOptions) validateArchiveImport() error {
+	if o.Name != "" || o.Dir != "" {
+		return errors.New("--name and --dir are not supported when importing an archive, the installation is restored with the name from the archive")
+	}
+	return nil
+}
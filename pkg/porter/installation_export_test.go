@@ -0,0 +1,114 @@
+package porter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationExportOptions_Validate(t *testing.T) {
+	opts := InstallationExportOptions{}
+	require.NoError(t, opts.Validate([]string{"mysql"}))
+	assert.Equal(t, "mysql", opts.Name)
+	assert.Equal(t, "mysql.json", opts.File, "the archive should be named after the installation by default")
+
+	opts = InstallationExportOptions{}
+	require.ErrorContains(t, opts.Validate(nil), "expected a single argument")
+
+	opts = InstallationExportOptions{File: "/tmp/mysql.json", Anonymize: true}
+	require.NoError(t, opts.Validate([]string{"mysql"}))
+	assert.Equal(t, "/tmp/mysql-mapping.json", opts.MappingFile, "the mapping should be written next to the archive by default")
+
+	opts = InstallationExportOptions{MappingFile: "mapping.json"}
+	require.EqualError(t, opts.Validate([]string{"mysql"}), "--mapping-file can only be used with --anonymize")
+
+	opts = InstallationExportOptions{Anonymize: true, MappingFile: "mysql.json"}
+	require.ErrorContains(t, opts.Validate([]string{"mysql"}), "--mapping-file must be different from --file")
+}
+
+func TestPorter_ExportInstallation_Anonymize(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "redis"))
+
+	export := func(name string) storage.InstallationArchive {
+		opts := InstallationExportOptions{Namespace: "dev", File: "/" + name + ".json", Anonymize: true, MappingFile: "/mapping.json"}
+		require.NoError(t, opts.Validate([]string{name}))
+		require.NoError(t, p.ExportInstallation(ctx, opts))
+
+		data, err := p.FileSystem.ReadFile(opts.File)
+		require.NoError(t, err)
+		var archive storage.InstallationArchive
+		require.NoError(t, json.Unmarshal(data, &archive))
+		return archive
+	}
+
+	archive := export("mysql")
+	assert.Equal(t, "namespace-1", archive.Installation.Namespace)
+	assert.Equal(t, "installation-1", archive.Installation.Name)
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "The mapping to the original names was written to /mapping.json")
+
+	archive = export("redis")
+	assert.Equal(t, "namespace-1", archive.Installation.Namespace, "the pseudonyms from the mapping file should be reused")
+	assert.Equal(t, "installation-2", archive.Installation.Name)
+
+	data, err := p.FileSystem.ReadFile("/mapping.json")
+	require.NoError(t, err)
+	var pseudonyms storage.Pseudonyms
+	require.NoError(t, json.Unmarshal(data, &pseudonyms))
+	assert.Equal(t, map[string]string{"mysql": "installation-1", "redis": "installation-2"}, pseudonyms.Installations)
+
+	opts := InstallationImportOptions{}
+	require.NoError(t, opts.Validate([]string{"archive", "/redis.json"}))
+	err = p.ImportInstallation(ctx, opts)
+	require.ErrorContains(t, err, "the archive was anonymized and cannot be imported")
+}
+
+func TestPorter_ExportImportInstallation(t *testing.T) {
+	ctx := context.Background()
+	src := NewTestPorter(t)
+	defer src.Close()
+
+	i := src.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+	run := src.TestInstallations.CreateRun(i.NewRun(cnab.ActionInstall))
+	result := src.TestInstallations.CreateResult(run.NewResult(cnab.StatusSucceeded))
+	src.TestInstallations.CreateOutput(result.NewOutput("password", nil), func(o *storage.Output) {
+		o.Key = "mysql-password"
+	})
+
+	err := src.ExportInstallation(ctx, InstallationExportOptions{Namespace: "dev", Name: "mysql", File: "/mysql.json"})
+	require.NoError(t, err)
+	assert.Contains(t, src.TestConfig.TestContext.GetOutput(), "Exported installation dev/mysql with 1 runs to /mysql.json")
+	assert.Contains(t, src.TestConfig.TestContext.GetOutput(), "  - mysql-password", "the referenced secrets should be listed")
+	data, err := src.FileSystem.ReadFile("/mysql.json")
+	require.NoError(t, err)
+
+	dest := NewTestPorter(t)
+	defer dest.Close()
+	require.NoError(t, dest.FileSystem.WriteFile("/mysql.json", data, 0600))
+
+	opts := InstallationImportOptions{Namespace: "test"}
+	require.NoError(t, opts.Validate([]string{"archive", "/mysql.json"}))
+	err = dest.ImportInstallation(ctx, opts)
+	require.ErrorContains(t, err, "not in the test namespace")
+
+	opts.Namespace = ""
+	require.NoError(t, dest.ImportInstallation(ctx, opts))
+
+	gotInst, err := dest.Installations.GetInstallation(ctx, "dev", "mysql")
+	require.NoError(t, err)
+	assert.Equal(t, i.ID, gotInst.ID)
+	outputs, err := dest.Installations.GetLastOutputs(ctx, "dev", "mysql")
+	require.NoError(t, err)
+	password, ok := outputs.GetByName("password")
+	require.True(t, ok)
+	assert.Equal(t, "mysql-password", password.Key)
+}
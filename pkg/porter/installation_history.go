@@ -0,0 +1,92 @@
+package porter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// HistoryVerifyOptions are the options for verifying the run ledger of an installation.
+type HistoryVerifyOptions struct {
+	printer.PrintOptions
+
+	// Namespace of the installation.
+	Namespace string
+
+	// Name of the installation.
+	Name string
+}
+
+// Validate the args and options for verifying the run ledger of an installation.
+func (o *HistoryVerifyOptions) Validate(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a single argument, the name of the installation, but got %d", len(args))
+	}
+	o.Name = args[0]
+
+	return o.PrintOptions.Validate(printer.FormatPlaintext, []printer.Format{printer.FormatPlaintext, printer.FormatJson, printer.FormatYaml})
+}
+
+// VerifyInstallationHistory checks that the run ledger of an installation
+// was not tampered with, and returns an error when problems are found.
+func (p *Porter) VerifyInstallationHistory(ctx context.Context, opts HistoryVerifyOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if _, err := p.Installations.GetInstallation(ctx, opts.Namespace, opts.Name); err != nil {
+		return span.Error(fmt.Errorf("could not retrieve installation %s/%s: %w", opts.Namespace, opts.Name, err))
+	}
+
+	report, err := p.Installations.VerifyLedger(ctx, opts.Namespace, opts.Name)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		err = printer.PrintJson(p.Out, report)
+	case printer.FormatYaml:
+		err = printer.PrintYaml(p.Out, report)
+	case printer.FormatPlaintext:
+		err = p.printLedgerReport(report)
+	}
+	if err != nil {
+		return span.Error(err)
+	}
+
+	if report.Entries == 0 {
+		return span.Error(fmt.Errorf("installation %s/%s has no entries in the run ledger, enable it with run-ledger.enabled in the Porter config file", opts.Namespace, opts.Name))
+	}
+	if !report.Valid() {
+		return span.Error(fmt.Errorf("found %d problems with the run ledger of installation %s/%s", len(report.Problems), opts.Namespace, opts.Name))
+	}
+	return nil
+}
+
+func (p *Porter) printLedgerReport(report storage.LedgerReport) error {
+	fmt.Fprintf(p.Out, "Entries: %d\n", report.Entries)
+	fmt.Fprintf(p.Out, "Signed: %d\n", report.Signed)
+
+	if report.Valid() {
+		fmt.Fprintln(p.Out, "The run ledger is valid")
+		return nil
+	}
+
+	fmt.Fprintln(p.Out)
+	row := func(v interface{}) []string {
+		problem, ok := v.(storage.LedgerProblem)
+		if !ok {
+			return nil
+		}
+		sequence := ""
+		if problem.Sequence > 0 {
+			sequence = strconv.FormatInt(problem.Sequence, 10)
+		}
+		return []string{sequence, problem.Kind, problem.RecordID, problem.Description}
+	}
+	return printer.PrintTable(p.Out, report.Problems, row, "Sequence", "Kind", "ID", "Problem")
+}
@@ -0,0 +1,51 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPorter_VerifyInstallationHistory(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	opts := HistoryVerifyOptions{Namespace: "dev"}
+	require.NoError(t, opts.Validate([]string{"mysql"}))
+
+	inst := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+	p.TestInstallations.CreateRun(inst.NewRun(cnab.ActionInstall))
+	err := p.VerifyInstallationHistory(ctx, opts)
+	require.ErrorContains(t, err, "has no entries in the run ledger")
+
+	p.Config.Data.RunLedger.Enabled = true
+	run := p.TestInstallations.CreateRun(inst.NewRun(cnab.ActionUpgrade))
+	p.TestInstallations.CreateResult(run.NewResult(cnab.StatusSucceeded))
+	err = p.VerifyInstallationHistory(ctx, opts)
+	require.ErrorContains(t, err, "found 1 problems with the run ledger of installation dev/mysql", "runs created before the ledger was enabled should be reported")
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "the run is not recorded in the")
+
+	opts = HistoryVerifyOptions{Namespace: "dev"}
+	require.NoError(t, opts.Validate([]string{"redis"}))
+	inst = p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "redis"))
+	run = p.TestInstallations.CreateRun(inst.NewRun(cnab.ActionInstall))
+	p.TestInstallations.CreateResult(run.NewResult(cnab.StatusSucceeded))
+	p.TestConfig.TestContext.ClearOutputs()
+	err = p.VerifyInstallationHistory(ctx, opts)
+	require.NoError(t, err)
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "The run ledger is valid")
+
+	run.Action = cnab.ActionUninstall
+	require.NoError(t, p.TestStore.Update(ctx, storage.CollectionRuns, storage.UpdateOptions{Document: run}))
+	opts.PrintOptions = printer.PrintOptions{RawFormat: "json"}
+	require.NoError(t, opts.Validate([]string{"redis"}))
+	err = p.VerifyInstallationHistory(ctx, opts)
+	require.ErrorContains(t, err, "found 1 problems with the run ledger of installation dev/redis")
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), `"description": "the run was modified"`)
+}
@@ -0,0 +1,429 @@
+package porter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	"get.porter.sh/porter/pkg/yaml"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
+)
+
+const (
+	// ImportSourceHelm imports a helm release from the output of helm status RELEASE --output json.
+	ImportSourceHelm = "helm"
+
+	// ImportSourceTerraform imports the infrastructure defined in a terraform state file.
+	ImportSourceTerraform = "terraform"
+
+	// LabelImportedFrom is the label applied to imported installations, set to the tool that managed the deployment.
+	LabelImportedFrom = "porter.sh/imported-from"
+)
+
+// ImportSources are the tools that deployments can be imported from.
+var ImportSources = []string{ImportSourceArchive, ImportSourceHelm, ImportSourceTerraform}
+
+// InstallationImportOptions are the options for importing a deployment
+// managed by another tool as an installation.
+type InstallationImportOptions struct {
+	// Source is the tool that manages the deployment.
+	Source string
+
+	// File containing the state of the deployment.
+	File string
+
+	// Name of the installation. Defaults to the name of the helm release.
+	Name string
+
+	// Namespace of the installation.
+	Namespace string
+
+	// Dir is the directory where the bundle that wraps the tool is scaffolded.
+	// Defaults to a directory named after the installation.
+	Dir string
+}
+
+func (o *InstallationImportOptions) Validate(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected two arguments, the source (%v) and the file containing the state of the deployment, but got %d", ImportSources, len(args))
+	}
+	o.Source = args[0]
+	o.File = args[1]
+
+	switch o.Source {
+	case ImportSourceArchive:
+		return o.validateArchiveImport()
+	case ImportSourceHelm:
+	case ImportSourceTerraform:
+		if o.Name == "" {
+			return errors.New("--name is required when importing from terraform")
+		}
+	default:
+		return fmt.Errorf("unsupported import source %q, allowed values are: %v", o.Source, ImportSources)
+	}
+	return nil
+}
+
+// importedDeployment is a deployment managed by another tool, parsed from its
+// state, that is used to create the installation and scaffold its bundle.
+type importedDeployment struct {
+	// Name of the installation and bundle.
+	Name string
+
+	// Parameters of the installation.
+	Parameters map[string]string
+
+	// SensitiveParameters are the names of the parameters whose values are
+	// saved to the secret store instead of the installation.
+	SensitiveParameters []string
+
+	// Outputs generated by the deployment.
+	Outputs []importedOutput
+
+	// TemplateData is used to render the porter.yaml template.
+	TemplateData interface{}
+}
+
+type importedOutput struct {
+	Name      string
+	Type      string
+	Sensitive bool
+	Value     []byte
+}
+
+// ImportInstallation creates an installation for a deployment that is managed
+// by another tool, such as a helm release, and scaffolds a bundle that wraps the
+// tool so that the deployment can be managed by Porter going forward.
+// Installations exported by Porter are restored from the archive instead.
+func (p *Porter) ImportInstallation(ctx context.Context, opts InstallationImportOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	// Fail before the bundle is scaffolded and sensitive values are saved to
+	// the secret store, because the installation can't be saved
+	if p.Data.ReadOnly {
+		return span.Error(storage.ErrReadOnly{Collection: storage.CollectionInstallations, Operation: "insert"})
+	}
+
+	data, err := p.FileSystem.ReadFile(opts.File)
+	if err != nil {
+		return span.Error(fmt.Errorf("could not read %s: %w", opts.File, err))
+	}
+
+	if opts.Source == ImportSourceArchive {
+		return span.Error(p.importInstallationArchive(ctx, data, opts))
+	}
+
+	var deployment importedDeployment
+	switch opts.Source {
+	case ImportSourceHelm:
+		deployment, err = parseHelmRelease(data, opts.Name)
+	case ImportSourceTerraform:
+		deployment, err = parseTerraformState(data, opts.Name)
+	}
+	if err != nil {
+		return span.Error(fmt.Errorf("could not import %s from %s: %w", opts.Source, opts.File, err))
+	}
+
+	_, err = p.Installations.GetInstallation(ctx, opts.Namespace, deployment.Name)
+	if err == nil {
+		return span.Error(fmt.Errorf("installation %s/%s already exists", opts.Namespace, deployment.Name))
+	} else if !errors.Is(err, storage.ErrNotFound{}) {
+		return span.Error(err)
+	}
+
+	dir := opts.Dir
+	if dir == "" {
+		dir = deployment.Name
+	}
+	manifestPath := filepath.Join(dir, config.Name)
+	if exists, _ := p.FileSystem.Exists(manifestPath); exists {
+		return span.Error(fmt.Errorf("could not scaffold the bundle because %s already exists", manifestPath))
+	}
+
+	if err = p.scaffoldImportedBundle(opts.Source, dir, deployment); err != nil {
+		return span.Error(err)
+	}
+
+	inst, err := p.recordImportedInstallation(ctx, opts, deployment)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	fmt.Fprintf(p.Out, "Imported %s as installation %s\n", opts.Source, inst)
+	if len(deployment.SensitiveParameters) > 0 {
+		fmt.Fprintf(p.Out, "Saved the parameters that may contain secrets (%s) to the secret store instead of the bundle.\n", strings.Join(deployment.SensitiveParameters, ", "))
+	}
+	fmt.Fprintf(p.Out, "Scaffolded a bundle to manage the installation in %s. Review it, then build and publish it, and set the installation's bundle to the published reference with porter installation apply.\n", dir)
+	return nil
+}
+
+// scaffoldImportedBundle writes a porter.yaml that wraps the tool to the directory.
+func (p *Porter) scaffoldImportedBundle(source string, dir string, deployment importedDeployment) error {
+	tmplData, err := p.Templates.GetImportManifest(source)
+	if err != nil {
+		return err
+	}
+
+	// porter.yaml uses {{ }} for its own templates, so use different delimiters
+	tmpl, err := template.New(config.Name).
+		Delims("[[", "]]").
+		Funcs(template.FuncMap{"quote": strconv.Quote}).
+		Parse(string(tmplData))
+	if err != nil {
+		return fmt.Errorf("error parsing the %s import template: %w", source, err)
+	}
+
+	manifest := &bytes.Buffer{}
+	if err = tmpl.Execute(manifest, deployment.TemplateData); err != nil {
+		return fmt.Errorf("error rendering the %s import template: %w", source, err)
+	}
+
+	if err = p.FileSystem.MkdirAll(dir, pkg.FileModeDirectory); err != nil {
+		return fmt.Errorf("could not create directory %s: %w", dir, err)
+	}
+
+	dest := filepath.Join(dir, config.Name)
+	if err = p.FileSystem.WriteFile(dest, manifest.Bytes(), pkg.FileModeWritable); err != nil {
+		return fmt.Errorf("could not write %s: %w", dest, err)
+	}
+
+	return nil
+}
+
+// recordImportedInstallation saves the installation along with a successful
+// install run that records the outputs of the deployment.
+func (p *Porter) recordImportedInstallation(ctx context.Context, opts InstallationImportOptions, deployment importedDeployment) (storage.Installation, error) {
+	inst := storage.NewInstallation(opts.Namespace, deployment.Name)
+	inst.Labels = map[string]string{LabelImportedFrom: opts.Source}
+
+	// Describe the parameters and outputs so that sensitive values are saved to the secret store
+	bun := bundle.Bundle{
+		Name:        deployment.Name,
+		Version:     "0.0.0",
+		Definitions: definition.Definitions{},
+		Parameters:  map[string]bundle.Parameter{},
+		Outputs:     map[string]bundle.Output{},
+	}
+
+	names := make([]string, 0, len(deployment.Parameters))
+	for name := range deployment.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	params := make([]secrets.Strategy, 0, len(names))
+	for _, name := range names {
+		sensitive := false
+		for _, sensitiveName := range deployment.SensitiveParameters {
+			if name == sensitiveName {
+				sensitive = true
+			}
+		}
+		bun.Definitions[name] = &definition.Schema{Type: "string", WriteOnly: &sensitive}
+		bun.Parameters[name] = bundle.Parameter{Definition: name}
+		params = append(params, storage.ValueStrategy(name, deployment.Parameters[name]))
+	}
+	params, err := p.Sanitizer.CleanParameters(ctx, params, cnab.NewBundle(bun), inst.ID)
+	if err != nil {
+		return storage.Installation{}, err
+	}
+	inst.Parameters = storage.NewParameterSet(inst.Namespace, inst.Name, params...)
+	for _, output := range deployment.Outputs {
+		sensitive := output.Sensitive
+		bun.Definitions[output.Name] = &definition.Schema{Type: output.Type, WriteOnly: &sensitive}
+		bun.Outputs[output.Name] = bundle.Output{Definition: output.Name}
+	}
+
+	run := p.Installations.NewRun(inst, cnab.ActionInstall)
+	run.Bundle = bun
+	run.AddNote(fmt.Sprintf("Imported from %s %s", opts.Source, opts.File))
+	result := p.Installations.NewResult(run, cnab.StatusSucceeded)
+	result.Message = fmt.Sprintf("Imported from %s", opts.Source)
+	inst.ApplyResult(run, result)
+
+	if err := p.Installations.InsertInstallation(ctx, inst); err != nil {
+		return storage.Installation{}, err
+	}
+	if err := p.Installations.InsertRun(ctx, run); err != nil {
+		return storage.Installation{}, err
+	}
+	if err := p.Installations.InsertResult(ctx, result); err != nil {
+		return storage.Installation{}, err
+	}
+
+	for _, o := range deployment.Outputs {
+		output, err := p.Sanitizer.CleanOutput(ctx, result.NewOutput(o.Name, o.Value), cnab.NewBundle(bun))
+		if err != nil {
+			return storage.Installation{}, err
+		}
+		if err = p.Installations.InsertOutput(ctx, output); err != nil {
+			return storage.Installation{}, err
+		}
+	}
+
+	return inst, nil
+}
+
+// helmRelease is the release printed by helm status RELEASE --output json.
+type helmRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Info      struct {
+		Status string `json:"status"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"metadata"`
+	} `json:"chart"`
+
+	// Config is the values that were set when the release was deployed.
+	Config map[string]interface{} `json:"config"`
+}
+
+// helmTemplateData is used to render the helm porter.yaml template.
+type helmTemplateData struct {
+	Name             string
+	Release          string
+	ReleaseNamespace string
+	Chart            string
+	ChartVersion     string
+	HasValues        bool
+}
+
+func parseHelmRelease(data []byte, name string) (importedDeployment, error) {
+	var release helmRelease
+	if err := json.Unmarshal(data, &release); err != nil {
+		return importedDeployment{}, fmt.Errorf("expected the output of helm status RELEASE --output json: %w", err)
+	}
+
+	if release.Name == "" || release.Chart.Metadata.Name == "" {
+		return importedDeployment{}, errors.New("expected the output of helm status RELEASE --output json: the release name or chart is missing")
+	}
+	if release.Info.Status != "deployed" {
+		return importedDeployment{}, fmt.Errorf("the helm release %s is %s, only deployed releases can be imported", release.Name, release.Info.Status)
+	}
+
+	if name == "" {
+		name = release.Name
+	}
+
+	deployment := importedDeployment{
+		Name: name,
+		Parameters: map[string]string{
+			"release":   release.Name,
+			"namespace": release.Namespace,
+			"chart":     release.Chart.Metadata.Name,
+			"version":   release.Chart.Metadata.Version,
+		},
+	}
+
+	// The values often include passwords and tokens, so they are saved to the
+	// secret store, and the bundle writes them to values.yaml when it runs
+	if len(release.Config) > 0 {
+		values, err := yaml.Marshal(release.Config)
+		if err != nil {
+			return importedDeployment{}, fmt.Errorf("could not marshal the values of the helm release: %w", err)
+		}
+		deployment.Parameters["values"] = string(values)
+		deployment.SensitiveParameters = []string{"values"}
+	}
+
+	deployment.TemplateData = helmTemplateData{
+		Name:             name,
+		Release:          release.Name,
+		ReleaseNamespace: release.Namespace,
+		Chart:            release.Chart.Metadata.Name,
+		ChartVersion:     release.Chart.Metadata.Version,
+		HasValues:        len(release.Config) > 0,
+	}
+	return deployment, nil
+}
+
+// terraformState is a terraform state file, only version 4 is supported.
+type terraformState struct {
+	Version          int    `json:"version"`
+	TerraformVersion string `json:"terraform_version"`
+	Outputs          map[string]struct {
+		Value     json.RawMessage `json:"value"`
+		Sensitive bool            `json:"sensitive"`
+	} `json:"outputs"`
+}
+
+// terraformTemplateData is used to render the terraform porter.yaml template.
+type terraformTemplateData struct {
+	Name             string
+	TerraformVersion string
+	Outputs          []importedOutput
+}
+
+func parseTerraformState(data []byte, name string) (importedDeployment, error) {
+	var state terraformState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return importedDeployment{}, fmt.Errorf("expected a terraform state file: %w", err)
+	}
+	if state.Version != 4 {
+		return importedDeployment{}, fmt.Errorf("unsupported terraform state version %d, only version 4 is supported", state.Version)
+	}
+
+	names := make([]string, 0, len(state.Outputs))
+	for outputName := range state.Outputs {
+		names = append(names, outputName)
+	}
+	sort.Strings(names)
+
+	outputs := make([]importedOutput, 0, len(names))
+	for _, outputName := range names {
+		stateOutput := state.Outputs[outputName]
+		output := importedOutput{
+			Name:      outputName,
+			Sensitive: stateOutput.Sensitive,
+			Value:     stateOutput.Value,
+		}
+
+		var value interface{}
+		if err := json.Unmarshal(stateOutput.Value, &value); err != nil {
+			return importedDeployment{}, fmt.Errorf("invalid value for output %s: %w", outputName, err)
+		}
+		switch v := value.(type) {
+		case string:
+			output.Type = "string"
+			output.Value = []byte(v)
+		case float64:
+			output.Type = "number"
+		case bool:
+			output.Type = "boolean"
+		case []interface{}:
+			output.Type = "array"
+		default:
+			output.Type = "object"
+		}
+		outputs = append(outputs, output)
+	}
+
+	deployment := importedDeployment{
+		Name:    name,
+		Outputs: outputs,
+		TemplateData: terraformTemplateData{
+			Name:             name,
+			TerraformVersion: state.TerraformVersion,
+			Outputs:          outputs,
+		},
+	}
+	return deployment, nil
+}
@@ -0,0 +1,135 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationImportOptions_Validate(t *testing.T) {
+	testcases := []struct {
+		name      string
+		args      []string
+		opts      InstallationImportOptions
+		wantError string
+	}{
+		{name: "helm", args: []string{"helm", "mysql.json"}},
+		{name: "terraform", args: []string{"terraform", "terraform.tfstate"}, opts: InstallationImportOptions{Name: "infra"}},
+		{name: "terraform without name", args: []string{"terraform", "terraform.tfstate"}, wantError: "--name is required"},
+		{name: "unsupported source", args: []string{"pulumi", "stack.json"}, wantError: `unsupported import source "pulumi"`},
+		{name: "missing file", args: []string{"helm"}, wantError: "expected two arguments"},
+		{name: "archive", args: []string{"archive", "mysql.json"}},
+		{name: "archive with name", args: []string{"archive", "mysql.json"}, opts: InstallationImportOptions{Name: "mydb"}, wantError: "--name and --dir are not supported"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.opts.Validate(tc.args)
+			if tc.wantError != "" {
+				require.ErrorContains(t, err, tc.wantError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPorter_ImportInstallation_Helm(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.TestConfig.TestContext.AddTestFile("testdata/import/helm-release.json", "/mysql.json")
+	opts := InstallationImportOptions{Namespace: "dev"}
+	require.NoError(t, opts.Validate([]string{"helm", "/mysql.json"}))
+	require.NoError(t, p.ImportInstallation(ctx, opts))
+
+	inst, err := p.Installations.GetInstallation(ctx, "dev", "mysql")
+	require.NoError(t, err, "the installation should be named after the release")
+	assert.Equal(t, "helm", inst.Labels[LabelImportedFrom])
+	assert.True(t, inst.IsInstalled(), "the installation should be recorded as installed")
+
+	params := map[string]interface{}{}
+	var values secrets.Strategy
+	for _, param := range inst.Parameters.Parameters {
+		if param.Name == "values" {
+			values = param
+			continue
+		}
+		params[param.Name] = param.Source.Value
+	}
+	assert.Equal(t, map[string]interface{}{"release": "mysql", "namespace": "db", "chart": "mysql", "version": "9.4.1"}, params)
+
+	require.Equal(t, "values", values.Name, "the values of the release should be imported as a parameter")
+	assert.Equal(t, secrets.SourceSecret, values.Source.Key, "the values of the release should be saved to the secret store")
+	storedValues, err := p.Secrets.Resolve(ctx, values.Source.Key, values.Source.Value)
+	require.NoError(t, err)
+	assert.Contains(t, storedValues, "rootPassword: topsecret")
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "Saved the parameters that may contain secrets (values)")
+
+	runs, _, err := p.Installations.ListRuns(ctx, "dev", "mysql")
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, cnab.ActionInstall, runs[0].Action)
+
+	manifest, err := p.FileSystem.ReadFile("mysql/porter.yaml")
+	require.NoError(t, err, "the porter.yaml should be scaffolded in a directory named after the installation")
+	var m map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(manifest, &m), "the scaffolded porter.yaml should be valid yaml")
+	assert.Equal(t, "mysql", m["name"])
+	assert.Contains(t, string(manifest), "- values.yaml")
+	assert.NotContains(t, string(manifest), "topsecret")
+
+	exists, _ := p.FileSystem.Exists("mysql/values.yaml")
+	assert.False(t, exists, "the values of the release should not be written to disk")
+
+	err = p.ImportInstallation(ctx, opts)
+	require.ErrorContains(t, err, "installation dev/mysql already exists")
+}
+
+func TestPorter_ImportInstallation_Terraform(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.TestConfig.TestContext.AddTestFile("testdata/import/terraform.tfstate", "/terraform.tfstate")
+	opts := InstallationImportOptions{Name: "infra", Dir: "/bundles/infra"}
+	require.NoError(t, opts.Validate([]string{"terraform", "/terraform.tfstate"}))
+	require.NoError(t, p.ImportInstallation(ctx, opts))
+
+	inst, err := p.Installations.GetInstallation(ctx, "", "infra")
+	require.NoError(t, err)
+	assert.Equal(t, "terraform", inst.Labels[LabelImportedFrom])
+
+	outputs, err := p.Installations.GetLastOutputs(ctx, "", "infra")
+	require.NoError(t, err)
+	require.Equal(t, 3, outputs.Len())
+
+	endpoint, ok := outputs.GetByName("endpoint")
+	require.True(t, ok)
+	assert.Equal(t, "db.example.com", string(endpoint.Value), "string outputs should be saved without quotes")
+
+	port, ok := outputs.GetByName("port")
+	require.True(t, ok)
+	assert.Equal(t, "5432", string(port.Value))
+
+	password, ok := outputs.GetByName("password")
+	require.True(t, ok)
+	assert.NotEmpty(t, password.Key, "sensitive outputs should be saved to the secret store")
+	assert.Empty(t, password.Value, "sensitive outputs should not be saved in the database")
+
+	manifest, err := p.FileSystem.ReadFile("/bundles/infra/porter.yaml")
+	require.NoError(t, err)
+	var m map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(manifest, &m), "the scaffolded porter.yaml should be valid yaml")
+	assert.Contains(t, string(manifest), `clientVersion: "1.5.7"`)
+
+	err = p.ImportInstallation(ctx, InstallationImportOptions{Source: "terraform", File: "/terraform.tfstate", Name: "infra2", Dir: "/bundles/infra"})
+	require.ErrorContains(t, err, "/bundles/infra/porter.yaml already exists")
+}
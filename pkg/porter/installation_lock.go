@@ -0,0 +1,119 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+var (
+	// installationLockTTL is how long the lock on an installation is held
+	// without being renewed. The lock is renewed while the bundle runs, so the
+	// ttl only determines how long an installation stays locked when Porter
+	// exits without releasing it.
+	installationLockTTL = 2 * time.Minute
+
+	// installationLockPollInterval is how often Porter tries to acquire the
+	// lock on an installation while waiting for it.
+	installationLockPollInterval = 2 * time.Second
+)
+
+// lockInstallation acquires the lock on an installation so that it is not
+// modified by another operation at the same time. When the installation is
+// locked, Porter waits up to the timeout for the lock to be released before
+// returning an error. The returned function releases the lock.
+func (p *Porter) lockInstallation(ctx context.Context, namespace string, name string, timeout time.Duration) (func(), error) {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	owner := p.installationLockOwner()
+	deadline := time.Now().Add(timeout)
+	for {
+		lock, err := p.Installations.AcquireInstallationLock(ctx, namespace, name, owner, installationLockTTL)
+		if err == nil {
+			return p.renewInstallationLock(ctx, lock), nil
+		}
+		if !errors.Is(err, storage.ErrInstallationLocked) {
+			return nil, log.Error(err)
+		}
+
+		if time.Now().Add(installationLockPollInterval).After(deadline) {
+			if timeout == 0 {
+				return nil, log.Error(fmt.Errorf("%w. Use --lock-timeout to wait for the lock", err))
+			}
+			return nil, log.Error(fmt.Errorf("timed out after %s waiting for the lock: %w", timeout, err))
+		}
+
+		var lockedErr storage.InstallationLockedError
+		if errors.As(err, &lockedErr) {
+			log.Infof("Waiting for %s to release the lock on installation %s", lockedErr.Lock.Owner, lockedErr.Lock)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, log.Error(ctx.Err())
+		case <-time.After(installationLockPollInterval):
+		}
+	}
+}
+
+// renewInstallationLock renews the lock in the background until the returned
+// function is called to release it.
+func (p *Porter) renewInstallationLock(ctx context.Context, lock storage.InstallationLock) func() {
+	log := tracing.LoggerFromContext(ctx)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(installationLockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				renewed, err := p.Installations.RenewInstallationLock(ctx, lock, installationLockTTL)
+				if errors.Is(err, storage.ErrInstallationLockLost) {
+					log.Warnf("The lock on installation %s was lost, another operation may modify the installation at the same time", lock)
+					return
+				} else if err != nil {
+					log.Warnf("Could not renew the lock on installation %s: %s", lock, err)
+					continue
+				}
+				lock = renewed
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+
+			if err := p.Installations.ReleaseInstallationLock(ctx, lock); err != nil {
+				log.Warnf("Could not release the lock on installation %s, it expires at %s: %s", lock, lock.Expires.Format(time.RFC3339), err)
+			}
+		})
+	}
+}
+
+// installationLockOwner describes the current process, so that users can
+// tell who is modifying an installation when it is locked.
+func (p *Porter) installationLockOwner() string {
+	user := p.Getenv("USER")
+	if user == "" {
+		user = p.Getenv("USERNAME")
+	}
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s@%s (pid %d)", user, host, os.Getpid())
+}
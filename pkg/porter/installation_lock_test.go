@@ -0,0 +1,95 @@
+package porter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPorter_lockInstallation(t *testing.T) {
+	defer func(ttl, interval time.Duration) {
+		installationLockTTL, installationLockPollInterval = ttl, interval
+	}(installationLockTTL, installationLockPollInterval)
+	installationLockPollInterval = 10 * time.Millisecond
+
+	ctx := context.Background()
+
+	t.Run("locked", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		_, err := p.Installations.AcquireInstallationLock(ctx, "dev", "mybuns", "alice", time.Minute)
+		require.NoError(t, err)
+
+		_, err = p.lockInstallation(ctx, "dev", "mybuns", 0)
+		require.ErrorIs(t, err, storage.ErrInstallationLocked)
+		require.ErrorContains(t, err, "installation dev/mybuns is locked by alice")
+		require.ErrorContains(t, err, "Use --lock-timeout to wait for the lock")
+	})
+
+	t.Run("wait for the lock", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		lock, err := p.Installations.AcquireInstallationLock(ctx, "dev", "mybuns", "alice", time.Minute)
+		require.NoError(t, err)
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			p.Installations.ReleaseInstallationLock(ctx, lock)
+		}()
+
+		unlock, err := p.lockInstallation(ctx, "dev", "mybuns", 5*time.Second)
+		require.NoError(t, err, "the lock should be acquired once it is released")
+		unlock()
+	})
+
+	t.Run("lock timeout", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		_, err := p.Installations.AcquireInstallationLock(ctx, "dev", "mybuns", "alice", time.Minute)
+		require.NoError(t, err)
+
+		_, err = p.lockInstallation(ctx, "dev", "mybuns", 50*time.Millisecond)
+		require.ErrorIs(t, err, storage.ErrInstallationLocked)
+		require.ErrorContains(t, err, "timed out after 50ms waiting for the lock")
+	})
+
+	t.Run("renew and release", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		installationLockTTL = 30 * time.Millisecond
+
+		unlock, err := p.lockInstallation(ctx, "dev", "mybuns", 0)
+		require.NoError(t, err)
+
+		// Wait past the ttl, the lock should still be held because it was renewed
+		time.Sleep(100 * time.Millisecond)
+		_, err = p.Installations.AcquireInstallationLock(ctx, "dev", "mybuns", "alice", time.Minute)
+		require.ErrorIs(t, err, storage.ErrInstallationLocked, "the lock should be renewed while it is held")
+
+		unlock()
+		lock, err := p.Installations.AcquireInstallationLock(ctx, "dev", "mybuns", "alice", time.Minute)
+		require.NoError(t, err, "the lock should be released")
+		assert.Equal(t, "alice", lock.Owner)
+	})
+}
+
+func TestPorter_UpgradeBundle_Locked(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("", "mybuns"))
+	_, err := p.Installations.AcquireInstallationLock(ctx, "", "mybuns", "alice", time.Minute)
+	require.NoError(t, err)
+
+	opts := NewUpgradeOptions()
+	opts.Name = "mybuns"
+	err = p.UpgradeBundle(ctx, opts)
+	require.ErrorIs(t, err, storage.ErrInstallationLocked, "a concurrent upgrade should fail before the installation is modified")
+}
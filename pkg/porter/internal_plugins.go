@@ -13,7 +13,9 @@ import (
 	secretsplugins "get.porter.sh/porter/pkg/secrets/plugins"
 	"get.porter.sh/porter/pkg/secrets/plugins/filesystem"
 	"get.porter.sh/porter/pkg/secrets/plugins/host"
+	"get.porter.sh/porter/pkg/secrets/plugins/vault"
 	storageplugins "get.porter.sh/porter/pkg/storage/plugins"
+	"get.porter.sh/porter/pkg/storage/plugins/embedded"
 	"get.porter.sh/porter/pkg/storage/plugins/mongodb"
 	"get.porter.sh/porter/pkg/storage/plugins/mongodb_docker"
 	"get.porter.sh/porter/pkg/tracing"
@@ -92,7 +94,7 @@ func (p *Porter) RunInternalPlugins(ctx context.Context, opts RunInternalPluginO
 		}
 	}()
 
-	plugins.Serve(p.Context, selectedPlugin.Interface, impl, selectedPlugin.ProtocolVersion)
+	plugins.Serve(p.Context, selectedPlugin.Interface, impl, selectedPlugin.ProtocolVersion, selectedPlugin.CompatibleProtocolVersions...)
 	return err // Return the error that may have been set during recover above
 }
 
@@ -104,7 +106,12 @@ var internalPlugins map[string]InternalPlugin = getInternalPlugins()
 type InternalPlugin struct {
 	Interface       string
 	ProtocolVersion int
-	Create          func(c *config.Config, pluginCfg interface{}) (plugin.Plugin, error)
+
+	// CompatibleProtocolVersions are older protocol versions that the plugin
+	// also serves, so that it can be used by older versions of porter.
+	CompatibleProtocolVersions []int
+
+	Create func(c *config.Config, pluginCfg interface{}) (plugin.Plugin, error)
 }
 
 // A long running plugin needs to setup a connection or other resources first,
@@ -129,16 +136,33 @@ func getInternalPlugins() map[string]InternalPlugin {
 				return filesystem.NewPlugin(c, pluginCfg), nil
 			},
 		},
+		vault.PluginKey: {
+			Interface:       secretsplugins.PluginInterface,
+			ProtocolVersion: secretsplugins.PluginProtocolVersion,
+			Create: func(c *config.Config, pluginCfg interface{}) (plugin.Plugin, error) {
+				return vault.NewPlugin(c, pluginCfg)
+			},
+		},
 		mongodb.PluginKey: {
-			Interface:       storageplugins.PluginInterface,
-			ProtocolVersion: storageplugins.PluginProtocolVersion,
+			Interface:                  storageplugins.PluginInterface,
+			ProtocolVersion:            storageplugins.PluginProtocolVersion,
+			CompatibleProtocolVersions: []int{storageplugins.MinPluginProtocolVersion},
 			Create: func(c *config.Config, pluginCfg interface{}) (plugin.Plugin, error) {
 				return mongodb.NewPlugin(c.Context, pluginCfg)
 			},
 		},
+		embedded.PluginKey: {
+			Interface:                  storageplugins.PluginInterface,
+			ProtocolVersion:            storageplugins.PluginProtocolVersion,
+			CompatibleProtocolVersions: []int{storageplugins.MinPluginProtocolVersion},
+			Create: func(c *config.Config, pluginCfg interface{}) (plugin.Plugin, error) {
+				return embedded.NewPlugin(c, pluginCfg)
+			},
+		},
 		mongodb_docker.PluginKey: {
-			Interface:       storageplugins.PluginInterface,
-			ProtocolVersion: storageplugins.PluginProtocolVersion,
+			Interface:                  storageplugins.PluginInterface,
+			ProtocolVersion:            storageplugins.PluginProtocolVersion,
+			CompatibleProtocolVersions: []int{storageplugins.MinPluginProtocolVersion},
 			Create: func(c *config.Config, pluginCfg interface{}) (plugin.Plugin, error) {
 				return mongodb_docker.NewPlugin(c.Context, pluginCfg)
 			},
@@ -44,12 +44,22 @@ func (o InvokeOptions) Validate(ctx context.Context, args []string, p *Porter) e
 // InvokeBundle accepts a set of pre-validated InvokeOptions and uses
 // them to upgrade a bundle.
 func (p *Porter) InvokeBundle(ctx context.Context, opts InvokeOptions) error {
+	if opts.Remote != "" {
+		return p.executeRemote(ctx, "invoke", opts)
+	}
+
 	// Figure out which bundle/installation we are working with
 	bundleRef, err := opts.GetBundleReference(ctx, p)
 	if err != nil {
 		return err
 	}
 
+	unlock, err := p.lockInstallation(ctx, opts.Namespace, opts.Name, opts.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	installation, err := p.Installations.GetInstallation(ctx, opts.Namespace, opts.Name)
 	if errors.Is(err, storage.ErrNotFound{}) {
 		action, actionErr := bundleRef.Definition.GetAction(opts.Action)
@@ -4,13 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"get.porter.sh/porter/pkg/cache"
 	"get.porter.sh/porter/pkg/cnab"
+	cnabtooci "get.porter.sh/porter/pkg/cnab/cnab-to-oci"
 	"get.porter.sh/porter/pkg/cnab/drivers"
 	cnabprovider "get.porter.sh/porter/pkg/cnab/provider"
+	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/encoding"
 	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/printer"
 	"get.porter.sh/porter/pkg/storage"
 	"get.porter.sh/porter/pkg/tracing"
 	"github.com/opencontainers/go-digest"
@@ -34,10 +38,22 @@ type BundleAction interface {
 	Validate(ctx context.Context, args []string, p *Porter) error
 }
 
+// labeledAction is implemented by bundle actions that apply labels to the run,
+// such as install and upgrade.
+type labeledAction interface {
+	// ParseLabels returns the labels to apply to the run.
+	ParseLabels() map[string]string
+}
+
 // BundleExecutionOptions are common options for commands that run a bundle (install/upgrade/invoke/uninstall)
 type BundleExecutionOptions struct {
 	*BundleReferenceOptions
 
+	// PrintOptions selects how the bundle execution is reported. When the format
+	// is ndjson, the events of the run are printed as they happen instead of
+	// the output of the bundle.
+	printer.PrintOptions
+
 	// AllowDockerHostAccess grants the bundle access to the Docker socket.
 	AllowDockerHostAccess bool
 
@@ -59,17 +75,74 @@ type BundleExecutionOptions struct {
 	// Driver is the CNAB-compliant driver used to run bundle actions.
 	Driver string
 
+	// DriverPolicy controls what happens when the bundle does not support the driver.
+	// Allowed values are: enforce, warn.
+	DriverPolicy string
+
+	// LockTimeout is the maximum amount of time to wait for another operation
+	// on the installation to complete. Zero means that Porter does not wait.
+	LockTimeout time.Duration
+
+	// Timeout is the maximum amount of time that the bundle may run before it is stopped.
+	// Zero means that the bundle is not timed out.
+	Timeout time.Duration
+
+	// TimeoutSpecified indicates that Timeout was set with the --timeout flag,
+	// so it is used instead of the timeout configured for the action.
+	TimeoutSpecified bool
+
+	// EphemeralOutputs is a list of output names that should be printed but never persisted.
+	EphemeralOutputs []string
+
+	// ChangeTicket is the ID of a ticket in an external change-management system
+	// that is updated with the outcome of the run.
+	ChangeTicket string
+
+	// RestoreSnapshot is the name of a snapshot of the installation whose
+	// outputs, including the state of the bundle, are used instead of the
+	// most recent outputs of the installation. Only supported by upgrade and invoke.
+	RestoreSnapshot string
+
+	// Remote is the url of a porter api serve instance that runs the bundle,
+	// instead of running it locally.
+	Remote string
+
+	// RemoteToken authenticates with the porter api serve instance.
+	RemoteToken string
+
 	// parameters that are intended for dependencies
 	// This is legacy support for v1 of dependencies where you could pass a parameter to a dependency directly using special formatting
 	// Example: --param mysql#username=admin
 	// This is not used anymore in dependencies v2
 	depParams map[string]string
 
+	// outputDependencies are the parameters that are resolved from the outputs
+	// of other installations. They are recorded on the run.
+	outputDependencies []storage.RunOutputDependency
+
+	// parameterSetRevisions are the revisions of the parameter sets that the
+	// parameters are resolved from. They are recorded on the run.
+	parameterSetRevisions []storage.ParameterSetRevision
+
+	// trigger is the event that started the run, when the bundle is run
+	// automatically. It is recorded on the run.
+	trigger *storage.RunTrigger
+
+	// useBundleDriver indicates that a driver was not specified, so the default
+	// driver declared by the bundle is used instead of Porter's default.
+	useBundleDriver bool
+
 	// A cache of the final resolved set of parameters that are passed to the bundle
 	// Do not use directly, use GetParameters instead.
 	finalParams map[string]interface{}
 }
 
+// ExecutionDefaultFormat is the default output format of commands that run a bundle.
+const ExecutionDefaultFormat = printer.FormatPlaintext
+
+// ExecutionAllowedFormats are the output formats of commands that run a bundle.
+var ExecutionAllowedFormats = printer.Formats{printer.FormatPlaintext, printer.FormatNdjson}
+
 func NewBundleExecutionOptions() *BundleExecutionOptions {
 	return &BundleExecutionOptions{
 		BundleReferenceOptions: &BundleReferenceOptions{},
@@ -91,6 +164,10 @@ func (o *BundleExecutionOptions) GetParameters() map[string]interface{} {
 }
 
 func (o *BundleExecutionOptions) Validate(ctx context.Context, args []string, p *Porter) error {
+	if o.Remote != "" {
+		return o.validateRemote(args)
+	}
+
 	if err := o.BundleReferenceOptions.Validate(ctx, args, p); err != nil {
 		return err
 	}
@@ -100,7 +177,24 @@ func (o *BundleExecutionOptions) Validate(ctx context.Context, args []string, p
 		return err
 	}
 
-	return nil
+	if err := o.validateChangeTicket(p); err != nil {
+		return err
+	}
+
+	if err := o.PrintOptions.Validate(ExecutionDefaultFormat, ExecutionAllowedFormats); err != nil {
+		return err
+	}
+
+	return o.defaultTimeout(p)
+}
+
+// validateChangeTicket checks that an endpoint is configured to update the
+// change ticket when one is specified.
+func (o *BundleExecutionOptions) validateChangeTicket(p *Porter) error {
+	if o.ChangeTicket == "" || p.Config.Data.ChangeManagement.IsEnabled() {
+		return nil
+	}
+	return fmt.Errorf("invalid --change-ticket %s: change-management.url must be set in the porter configuration file to update change tickets", o.ChangeTicket)
 }
 
 // defaultDriver supplies the default driver if none is specified
@@ -118,6 +212,19 @@ func (o *BundleExecutionOptions) defaultDriver(p *Porter) {
 		// This is why we check first before applying the value. Only apply the config
 		// file setting if they didn't specify a flag.
 		o.Driver = p.Data.RuntimeDriver
+
+		// Let the bundle pick its default driver, unless runtime-driver was changed in the config file
+		o.useBundleDriver = o.Driver == DefaultDriver
+	}
+
+	// Apply global config to the --driver-policy flag
+	if o.DriverPolicy == "" {
+		o.DriverPolicy = p.Data.DriverPolicy
+	}
+
+	// Apply global config to the --change-ticket flag
+	if o.ChangeTicket == "" {
+		o.ChangeTicket = p.Data.ChangeTicket
 	}
 
 	// Apply global config to the --allow-docker-host-access flag
@@ -127,12 +234,75 @@ func (o *BundleExecutionOptions) defaultDriver(p *Porter) {
 	}
 }
 
+// defaultTimeout supplies the default timeout from the config file if none is specified
+func (o *BundleExecutionOptions) defaultTimeout(p *Porter) error {
+	if o.Timeout < 0 {
+		return fmt.Errorf("invalid --timeout %s: the timeout cannot be negative", o.Timeout)
+	}
+	if o.LockTimeout < 0 {
+		return fmt.Errorf("invalid --lock-timeout %s: the timeout cannot be negative", o.LockTimeout)
+	}
+
+	if o.Timeout == 0 {
+		// Only apply the config setting if they didn't specify the flag (i.e. it's porter installation apply which doesn't have that flag)
+		timeout, err := p.Config.GetRuntimeTimeout()
+		if err != nil {
+			return err
+		}
+		o.Timeout = timeout
+	}
+
+	return nil
+}
+
+// getTimeout returns the maximum amount of time that the bundle may run the
+// action. The timeout configured for the action in the config file is used
+// unless --timeout was specified.
+func (o *BundleExecutionOptions) getTimeout(p *Porter, action string) (time.Duration, error) {
+	if o.TimeoutSpecified {
+		return o.Timeout, nil
+	}
+
+	timeout, ok, err := p.Config.GetActionTimeout(action)
+	if err != nil || !ok {
+		return o.Timeout, err
+	}
+	return timeout, nil
+}
+
 // validateDriver validates that the provided driver is supported by Porter
 func (o *BundleExecutionOptions) validateDriver(cxt *portercontext.Context) error {
+	switch o.DriverPolicy {
+	case "", config.DriverPolicyEnforce, config.DriverPolicyWarn:
+	default:
+		return fmt.Errorf("invalid --driver-policy %s, allowed values are: %s, %s", o.DriverPolicy, config.DriverPolicyEnforce, config.DriverPolicyWarn)
+	}
+
 	_, err := drivers.LookupDriver(cxt, o.Driver)
 	return err
 }
 
+// selectDriver returns the driver used to run the bundle, which is the
+// bundle's default driver when a driver was not specified.
+func (o *BundleExecutionOptions) selectDriver(cxt *portercontext.Context, b cnab.ExtendedBundle) (string, error) {
+	if !o.useBundleDriver {
+		return o.Driver, nil
+	}
+
+	requirements, err := b.ReadDriverRequirements()
+	if err != nil {
+		return "", err
+	}
+	if requirements.Default == "" {
+		return o.Driver, nil
+	}
+
+	if _, err = drivers.LookupDriver(cxt, requirements.Default); err != nil {
+		return "", fmt.Errorf("could not use the default driver of the bundle, %s: %w", requirements.Default, err)
+	}
+	return requirements.Default, nil
+}
+
 // BundleReferenceOptions are the set of options available for commands that accept a bundle reference
 type BundleReferenceOptions struct {
 	installationOptions
@@ -281,14 +451,43 @@ func (p *Porter) BuildActionArgs(ctx context.Context, installation storage.Insta
 		}
 	}
 
+	p.resolveBundleDigest(ctx, &bundleRef, cnabtooci.RegistryOptions{InsecureRegistry: opts.InsecureRegistry})
+	p.warnOnBundleDigestDrift(installation, bundleRef)
+
+	driver, err := opts.selectDriver(p.Context, bundleRef.Definition)
+	if err != nil {
+		return cnabprovider.ActionArguments{}, log.Error(err)
+	}
+
+	timeout, err := opts.getTimeout(p, action.GetAction())
+	if err != nil {
+		return cnabprovider.ActionArguments{}, log.Error(err)
+	}
+
 	args := cnabprovider.ActionArguments{
 		Action:                action.GetAction(),
 		Installation:          installation,
 		BundleReference:       bundleRef,
 		Params:                opts.GetParameters(),
-		Driver:                opts.Driver,
+		Driver:                driver,
+		DriverPolicy:          opts.DriverPolicy,
 		AllowDockerHostAccess: opts.AllowDockerHostAccess,
 		PersistLogs:           !opts.NoLogs,
+		Timeout:               timeout,
+		EphemeralOutputs:      opts.EphemeralOutputs,
+		ChangeTicket:          opts.ChangeTicket,
+		RestoredSnapshot:      opts.RestoreSnapshot,
+		OutputDependencies:    opts.outputDependencies,
+		ParameterSetRevisions: opts.parameterSetRevisions,
+		Trigger:               opts.trigger,
+	}
+
+	if opts.Format == printer.FormatNdjson {
+		args.Events = p.Out
+	}
+
+	if labeled, ok := action.(labeledAction); ok {
+		args.Labels = labeled.ParseLabels()
 	}
 
 	return args, nil
@@ -3,14 +3,17 @@ package porter
 import (
 	"context"
 	"testing"
+	"time"
 
 	"get.porter.sh/porter/pkg"
 	"get.porter.sh/porter/pkg/cnab"
 	configadapter "get.porter.sh/porter/pkg/cnab/config-adapter"
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/manifest"
+	"get.porter.sh/porter/pkg/printer"
 	"get.porter.sh/porter/pkg/storage"
 	"get.porter.sh/porter/tests"
+	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-to-oci/relocation"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -88,6 +91,37 @@ func TestPorter_BuildActionArgs(t *testing.T) {
 		assert.NotEmpty(t, args.BundleReference.Definition, "BundlePath was not populated correctly")
 	})
 
+	t.Run("ndjson output", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := NewInstallOptions()
+		opts.CNABFile = "/bundle.json"
+		opts.RawFormat = string(printer.FormatNdjson)
+		p.TestConfig.TestContext.AddTestFile("testdata/bundle.json", "/bundle.json")
+		opts.finalParams = map[string]interface{}{}
+
+		err := opts.Validate(ctx, nil, p.Porter)
+		require.NoError(t, err, "Validate failed")
+		args, err := p.BuildActionArgs(ctx, storage.Installation{}, opts)
+		require.NoError(t, err, "BuildActionArgs failed")
+
+		assert.Equal(t, p.Out, args.Events, "the events of the run should be written to stdout")
+	})
+
+	t.Run("invalid output format", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := NewInstallOptions()
+		opts.CNABFile = "/bundle.json"
+		opts.RawFormat = "json"
+		p.TestConfig.TestContext.AddTestFile("testdata/bundle.json", "/bundle.json")
+
+		err := opts.Validate(ctx, nil, p.Porter)
+		require.ErrorContains(t, err, "invalid format: json")
+	})
+
 	t.Run("remaining fields", func(t *testing.T) {
 		p := NewTestPorter(t)
 		p.TestConfig.TestContext.AddTestFile("testdata/porter.yaml", "porter.yaml")
@@ -105,7 +139,8 @@ func TestPorter_BuildActionArgs(t *testing.T) {
 				CredentialIdentifiers: []string{
 					"mycreds",
 				},
-				Driver: "docker",
+				Driver:           "docker",
+				EphemeralOutputs: []string{"my-first-output"},
 				BundleReferenceOptions: &BundleReferenceOptions{
 					installationOptions: installationOptions{
 						bundleFileOptions: bundleFileOptions{
@@ -116,6 +151,7 @@ func TestPorter_BuildActionArgs(t *testing.T) {
 					},
 				},
 			},
+			Labels: []string{"ticket=OPS-1234"},
 		}
 		p.TestParameters.AddSecret("PARAM2_SECRET", "VALUE2")
 		p.TestParameters.AddTestParameters("testdata/paramset2.json")
@@ -140,6 +176,8 @@ func TestPorter_BuildActionArgs(t *testing.T) {
 
 		assert.Equal(t, opts.AllowDockerHostAccess, args.AllowDockerHostAccess, "AllowDockerHostAccess not populated correctly")
 		assert.Equal(t, opts.Driver, args.Driver, "Driver not populated correctly")
+		assert.Equal(t, opts.EphemeralOutputs, args.EphemeralOutputs, "EphemeralOutputs not populated correctly")
+		assert.Equal(t, map[string]string{"ticket": "OPS-1234"}, args.Labels, "Labels not populated correctly")
 		assert.EqualValues(t, expectedParams, args.Params, "Params not populated correctly")
 		assert.NotEmpty(t, args.Installation, "Installation not populated")
 		wantReloMap := relocation.ImageRelocationMap{"gabrtv/microservice@sha256:cca460afa270d4c527981ef9ca4989346c56cf9b20217dcea37df1ece8120687": "my.registry/microservice@sha256:cca460afa270d4c527981ef9ca4989346c56cf9b20217dcea37df1ece8120687"}
@@ -244,6 +282,235 @@ func TestBundleExecutionOptions_defaultDriver(t *testing.T) {
 		assert.True(t, opts.AllowDockerHostAccess, "expected allow-docker-host-access to use the flag value when specified")
 	})
 
+	t.Run("driver policy defaults to config", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		p.Config.Data.DriverPolicy = config.DriverPolicyWarn
+
+		opts := NewBundleExecutionOptions()
+
+		opts.defaultDriver(p.Porter)
+
+		assert.Equal(t, config.DriverPolicyWarn, opts.DriverPolicy, "expected driver-policy to inherit the value from the config file when the flag isn't specified")
+	})
+
+	t.Run("change ticket defaults to config", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		p.Config.Data.ChangeTicket = "CHG0001"
+
+		opts := NewBundleExecutionOptions()
+
+		opts.defaultDriver(p.Porter)
+
+		assert.Equal(t, "CHG0001", opts.ChangeTicket, "expected change-ticket to inherit the value from the config file when the flag isn't specified")
+	})
+
+	t.Run("change ticket flag set", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		p.Config.Data.ChangeTicket = "CHG0001"
+
+		opts := NewBundleExecutionOptions()
+		opts.ChangeTicket = "CHG0002"
+
+		opts.defaultDriver(p.Porter)
+
+		assert.Equal(t, "CHG0002", opts.ChangeTicket, "expected change-ticket to use the flag value when specified")
+	})
+}
+
+func TestBundleExecutionOptions_selectDriver(t *testing.T) {
+	b := cnab.NewBundle(bundle.Bundle{
+		Custom: map[string]interface{}{
+			cnab.DriverExtensionKey: map[string]interface{}{"default": "debug"},
+		},
+	})
+
+	t.Run("bundle default driver", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := NewBundleExecutionOptions()
+		opts.defaultDriver(p.Porter)
+
+		driver, err := opts.selectDriver(p.Context, b)
+		require.NoError(t, err)
+		assert.Equal(t, "debug", driver, "expected the default driver of the bundle to be used when a driver isn't specified")
+	})
+
+	t.Run("no bundle default driver", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := NewBundleExecutionOptions()
+		opts.defaultDriver(p.Porter)
+
+		driver, err := opts.selectDriver(p.Context, cnab.NewBundle(bundle.Bundle{}))
+		require.NoError(t, err)
+		assert.Equal(t, "docker", driver)
+	})
+
+	t.Run("driver flag set", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := NewBundleExecutionOptions()
+		opts.Driver = "docker"
+		opts.defaultDriver(p.Porter)
+
+		driver, err := opts.selectDriver(p.Context, b)
+		require.NoError(t, err)
+		assert.Equal(t, "docker", driver, "expected the --driver flag value to override the default driver of the bundle")
+	})
+
+	t.Run("runtime driver configured", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		p.Config.Data.RuntimeDriver = "kubernetes"
+
+		opts := NewBundleExecutionOptions()
+		opts.defaultDriver(p.Porter)
+
+		driver, err := opts.selectDriver(p.Context, b)
+		require.NoError(t, err)
+		assert.Equal(t, "kubernetes", driver, "expected runtime-driver in the config file to override the default driver of the bundle")
+	})
+}
+
+func TestBundleExecutionOptions_validateDriver(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	opts := NewBundleExecutionOptions()
+	opts.Driver = "docker"
+	opts.DriverPolicy = "ignore"
+
+	err := opts.validateDriver(p.Context)
+	require.EqualError(t, err, "invalid --driver-policy ignore, allowed values are: enforce, warn")
+}
+
+func TestBundleExecutionOptions_defaultTimeout(t *testing.T) {
+	t.Run("no timeout specified", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := NewBundleExecutionOptions()
+
+		require.NoError(t, opts.defaultTimeout(p.Porter))
+		assert.Zero(t, opts.Timeout, "expected bundles to not time out by default")
+	})
+
+	t.Run("timeout defaults to config", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		p.Config.Data.RuntimeTimeout = "30m"
+
+		opts := NewBundleExecutionOptions()
+
+		require.NoError(t, opts.defaultTimeout(p.Porter))
+		assert.Equal(t, 30*time.Minute, opts.Timeout, "expected the timeout to inherit the value from the config file when the flag isn't specified")
+	})
+
+	t.Run("timeout flag set", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		p.Config.Data.RuntimeTimeout = "30m"
+
+		opts := NewBundleExecutionOptions()
+		opts.Timeout = 5 * time.Minute
+
+		require.NoError(t, opts.defaultTimeout(p.Porter))
+		assert.Equal(t, 5*time.Minute, opts.Timeout, "expected the --timeout flag value to be used")
+	})
+
+	t.Run("negative timeout", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := NewBundleExecutionOptions()
+		opts.Timeout = -1 * time.Minute
+
+		err := opts.defaultTimeout(p.Porter)
+		require.ErrorContains(t, err, "the timeout cannot be negative")
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		p.Config.Data.RuntimeTimeout = "soon"
+
+		opts := NewBundleExecutionOptions()
+
+		err := opts.defaultTimeout(p.Porter)
+		require.ErrorContains(t, err, `invalid runtime-timeout "soon"`)
+	})
+
+	t.Run("action timeout from config", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		p.Config.Data.RuntimeTimeout = "30m"
+		p.Config.Data.ActionTimeouts = map[string]string{"backup": "4h"}
+
+		opts := NewBundleExecutionOptions()
+		require.NoError(t, opts.defaultTimeout(p.Porter))
+
+		timeout, err := opts.getTimeout(p.Porter, "backup")
+		require.NoError(t, err)
+		assert.Equal(t, 4*time.Hour, timeout, "expected the timeout configured for the action to be used")
+
+		timeout, err = opts.getTimeout(p.Porter, "upgrade")
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Minute, timeout, "expected actions without a configured timeout to use runtime-timeout")
+	})
+
+	t.Run("timeout flag overrides action timeout", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		p.Config.Data.ActionTimeouts = map[string]string{"backup": "4h"}
+
+		opts := NewBundleExecutionOptions()
+		opts.Timeout = 5 * time.Minute
+		opts.TimeoutSpecified = true
+		require.NoError(t, opts.defaultTimeout(p.Porter))
+
+		timeout, err := opts.getTimeout(p.Porter, "backup")
+		require.NoError(t, err)
+		assert.Equal(t, 5*time.Minute, timeout, "expected the --timeout flag value to be used")
+	})
+}
+
+func TestBundleExecutionOptions_validateChangeTicket(t *testing.T) {
+	t.Run("no change ticket", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := NewBundleExecutionOptions()
+
+		require.NoError(t, opts.validateChangeTicket(p.Porter))
+	})
+
+	t.Run("change management configured", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		p.Config.Data.ChangeManagement.URL = "https://example.com/changes/{{.Ticket}}"
+
+		opts := NewBundleExecutionOptions()
+		opts.ChangeTicket = "CHG0001"
+
+		require.NoError(t, opts.validateChangeTicket(p.Porter))
+	})
+
+	t.Run("change management not configured", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := NewBundleExecutionOptions()
+		opts.ChangeTicket = "CHG0001"
+
+		err := opts.validateChangeTicket(p.Porter)
+		require.ErrorContains(t, err, "change-management.url must be set")
+	})
 }
 
 func TestBundleExecutionOptions_ParseParamSets(t *testing.T) {
@@ -35,9 +35,19 @@ type ListOptions struct {
 	Labels        []string
 	Skip          int64
 	Limit         int64
+
+	// GroupBy aggregates the results by a field instead of listing each result.
+	// Only installations may be grouped, by bundle.
+	GroupBy string
 }
 
 func (o *ListOptions) Validate() error {
+	if _, err := o.ParseLabelSelector(); err != nil {
+		return err
+	}
+	if o.GroupBy != "" && o.GroupBy != GroupByBundle {
+		return fmt.Errorf("invalid --group-by value %s, allowed values are: %s", o.GroupBy, GroupByBundle)
+	}
 	return o.ParseFormat()
 }
 
@@ -48,8 +58,10 @@ func (o ListOptions) GetNamespace() string {
 	return o.Namespace
 }
 
-func (o ListOptions) ParseLabels() map[string]string {
-	return parseLabels(o.Labels)
+// ParseLabelSelector parses the labels used to filter the list, formatted as
+// KEY=VALUE, KEY!=VALUE, KEY or !KEY.
+func (o ListOptions) ParseLabelSelector() (storage.LabelSelector, error) {
+	return storage.ParseLabelSelector(o.Labels)
 }
 
 func parseLabels(raw []string) map[string]string {
@@ -221,6 +233,10 @@ type DisplayRun struct {
 	Started    time.Time              `json:"started" yaml:"started"`
 	Stopped    *time.Time             `json:"stopped" yaml:"stopped"`
 	Status     string                 `json:"status" yaml:"status"`
+	Timeout    string                 `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Stale      bool                   `json:"stale,omitempty" yaml:"stale,omitempty"`
+	Notes      []storage.RunNote      `json:"notes,omitempty" yaml:"notes,omitempty"`
+	Labels     map[string]string      `json:"labels,omitempty" yaml:"labels,omitempty"`
 }
 
 func NewDisplayRun(run storage.Run) DisplayRun {
@@ -231,6 +247,9 @@ func NewDisplayRun(run storage.Run) DisplayRun {
 		Started:    run.Created,
 		Bundle:     run.BundleReference,
 		Version:    run.Bundle.Version,
+		Notes:      run.Notes,
+		Labels:     run.Labels,
+		Timeout:    run.Timeout,
 	}
 }
 
@@ -239,12 +258,17 @@ func (p *Porter) ListInstallations(ctx context.Context, opts ListOptions) (Displ
 	ctx, log := tracing.StartSpan(ctx)
 	defer log.EndSpan()
 
+	selector, err := opts.ParseLabelSelector()
+	if err != nil {
+		return nil, log.Error(err)
+	}
+
 	installations, err := p.Installations.ListInstallations(ctx, storage.ListOptions{
-		Namespace: opts.GetNamespace(),
-		Name:      opts.Name,
-		Labels:    opts.ParseLabels(),
-		Skip:      opts.Skip,
-		Limit:     opts.Limit,
+		Namespace:     opts.GetNamespace(),
+		Name:          opts.Name,
+		LabelSelector: selector,
+		Skip:          opts.Skip,
+		Limit:         opts.Limit,
 	})
 	if err != nil {
 		return nil, log.Error(fmt.Errorf("could not list installations: %w", err))
@@ -262,6 +286,10 @@ func (p *Porter) ListInstallations(ctx context.Context, opts ListOptions) (Displ
 
 // PrintInstallations prints installed bundles.
 func (p *Porter) PrintInstallations(ctx context.Context, opts ListOptions) error {
+	if opts.GroupBy == GroupByBundle {
+		return p.PrintInstallationsByBundle(ctx, opts)
+	}
+
 	displayInstallations, err := p.ListInstallations(ctx, opts)
 	if err != nil {
 		return err
@@ -312,6 +340,8 @@ func getDisplayInstallationStatus(installation storage.Installation) string {
 		status = cnab.StatusSucceeded
 	case cnab.StatusFailed:
 		status = cnab.StatusFailed
+	case cnab.StatusTimedOut:
+		status = cnab.StatusTimedOut
 	case cnab.StatusRunning:
 		switch installation.Status.Action {
 		case cnab.ActionInstall:
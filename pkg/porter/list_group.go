@@ -0,0 +1,196 @@
+package porter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/Masterminds/semver/v3"
+	dtprinter "github.com/carolynvs/datetime-printer"
+)
+
+const (
+	// GroupByBundle groups the installations by the bundle that they install.
+	GroupByBundle = "bundle"
+
+	// histogramWidth is the maximum width of the bar printed for each version.
+	histogramWidth = 20
+)
+
+// DisplayBundleInstallations is the number of installations of a bundle, and
+// how many installations are at each version of the bundle.
+type DisplayBundleInstallations struct {
+	// Bundle repository.
+	Bundle string `json:"bundle" yaml:"bundle"`
+
+	// Installations of the bundle.
+	Installations int `json:"installations" yaml:"installations"`
+
+	// OldestModified is when the least recently modified installation was last modified.
+	OldestModified time.Time `json:"oldestModified" yaml:"oldestModified"`
+
+	// NewestModified is when the most recently modified installation was last modified.
+	NewestModified time.Time `json:"newestModified" yaml:"newestModified"`
+
+	// Versions of the bundle that are installed, from the newest version to the oldest.
+	Versions []DisplayBundleVersion `json:"versions" yaml:"versions"`
+}
+
+// DisplayBundleVersion is the number of installations at a version of a bundle.
+type DisplayBundleVersion struct {
+	// Version of the bundle that was last run by the installations. Empty when
+	// the installations have not been run.
+	Version string `json:"version" yaml:"version"`
+
+	// Installations at the version.
+	Installations int `json:"installations" yaml:"installations"`
+
+	// OldestModified is when the least recently modified installation was last modified.
+	OldestModified time.Time `json:"oldestModified" yaml:"oldestModified"`
+
+	// NewestModified is when the most recently modified installation was last modified.
+	NewestModified time.Time `json:"newestModified" yaml:"newestModified"`
+}
+
+// NewDisplayBundleInstallations converts a summary of the installations of a
+// bundle into its display representation, sorting the versions from newest to oldest.
+func NewDisplayBundleInstallations(summary storage.BundleInstallationSummary) DisplayBundleInstallations {
+	db := DisplayBundleInstallations{
+		Bundle:         summary.Repository,
+		Installations:  summary.Count,
+		OldestModified: summary.OldestModified,
+		NewestModified: summary.NewestModified,
+		Versions:       make([]DisplayBundleVersion, len(summary.Versions)),
+	}
+	for i, v := range summary.Versions {
+		db.Versions[i] = DisplayBundleVersion{
+			Version:        v.Version,
+			Installations:  v.Count,
+			OldestModified: v.OldestModified,
+			NewestModified: v.NewestModified,
+		}
+	}
+	sort.SliceStable(db.Versions, func(i, j int) bool {
+		return compareBundleVersions(db.Versions[i].Version, db.Versions[j].Version) > 0
+	})
+	return db
+}
+
+// compareBundleVersions orders semantic versions by precedence, followed by
+// any versions that are not semantic versions, and lastly no version.
+func compareBundleVersions(a string, b string) int {
+	if a == "" || b == "" {
+		return len(a) - len(b)
+	}
+
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	switch {
+	case errA == nil && errB == nil:
+		return va.Compare(vb)
+	case errA == nil:
+		return 1
+	case errB == nil:
+		return -1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// ListInstallationsByBundle counts the installations of each bundle, and of
+// each version of the bundle.
+func (p *Porter) ListInstallationsByBundle(ctx context.Context, opts ListOptions) ([]DisplayBundleInstallations, error) {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	selector, err := opts.ParseLabelSelector()
+	if err != nil {
+		return nil, log.Error(err)
+	}
+
+	summaries, err := p.Installations.SummarizeInstallationsByBundle(ctx, storage.ListOptions{
+		Namespace:     opts.GetNamespace(),
+		Name:          opts.Name,
+		LabelSelector: selector,
+		Skip:          opts.Skip,
+		Limit:         opts.Limit,
+	})
+	if err != nil {
+		return nil, log.Error(fmt.Errorf("could not group installations by bundle: %w", err))
+	}
+
+	results := make([]DisplayBundleInstallations, len(summaries))
+	for i, summary := range summaries {
+		results[i] = NewDisplayBundleInstallations(summary)
+	}
+	return results, nil
+}
+
+// PrintInstallationsByBundle prints the number of installations of each
+// bundle, with a histogram of the installed versions.
+func (p *Porter) PrintInstallationsByBundle(ctx context.Context, opts ListOptions) error {
+	bundles, err := p.ListInstallationsByBundle(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, bundles)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, bundles)
+	case printer.FormatPlaintext:
+		now := time.Now()
+		tp := dtprinter.DateTimePrinter{
+			Now: func() time.Time { return now },
+		}
+
+		type versionRow struct {
+			bundle  DisplayBundleInstallations
+			version DisplayBundleVersion
+		}
+		var rows []versionRow
+		for _, b := range bundles {
+			for _, v := range b.Versions {
+				rows = append(rows, versionRow{bundle: b, version: v})
+			}
+		}
+
+		row := func(v interface{}) []string {
+			r, ok := v.(versionRow)
+			if !ok {
+				return nil
+			}
+			version := r.version.Version
+			if version == "" {
+				version = "(not run)"
+			}
+			return []string{r.bundle.Bundle, version, strconv.Itoa(r.version.Installations),
+				histogramBar(r.version.Installations, r.bundle.Installations),
+				tp.Format(r.version.OldestModified), tp.Format(r.version.NewestModified)}
+		}
+		return printer.PrintTable(p.Out, rows, row,
+			"BUNDLE", "VERSION", "INSTALLATIONS", "DISTRIBUTION", "OLDEST MODIFIED", "NEWEST MODIFIED")
+	default:
+		return fmt.Errorf("invalid format: %s", opts.Format)
+	}
+}
+
+// histogramBar represents the proportion of the total installations of a
+// bundle that are at a version of the bundle.
+func histogramBar(count int, total int) string {
+	if count <= 0 || total <= 0 {
+		return ""
+	}
+	width := count * histogramWidth / total
+	if width == 0 {
+		width = 1
+	}
+	return strings.Repeat("#", width)
+}
@@ -0,0 +1,85 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListOptions_Validate_GroupBy(t *testing.T) {
+	opts := ListOptions{GroupBy: GroupByBundle}
+	require.NoError(t, opts.Validate())
+
+	opts = ListOptions{GroupBy: "namespace"}
+	require.EqualError(t, opts.Validate(), "invalid --group-by value namespace, allowed values are: bundle")
+}
+
+func TestNewDisplayBundleInstallations(t *testing.T) {
+	summary := storage.BundleInstallationSummary{
+		Repository: "example.com/mysql",
+		Count:      6,
+		Versions: []storage.BundleVersionSummary{
+			{Version: "", Count: 1},
+			{Version: "0.10.0", Count: 1},
+			{Version: "0.2.0", Count: 1},
+			{Version: "0.2.0-beta.1", Count: 1},
+			{Version: "latest", Count: 1},
+			{Version: "v1.0.0", Count: 1},
+		},
+	}
+
+	db := NewDisplayBundleInstallations(summary)
+	assert.Equal(t, "example.com/mysql", db.Bundle)
+	assert.Equal(t, 6, db.Installations)
+
+	gotVersions := make([]string, len(db.Versions))
+	for i, v := range db.Versions {
+		gotVersions[i] = v.Version
+	}
+	assert.Equal(t, []string{"v1.0.0", "0.10.0", "0.2.0", "0.2.0-beta.1", "latest", ""}, gotVersions,
+		"versions should be sorted from newest to oldest, followed by versions that are not semantic versions")
+}
+
+func TestHistogramBar(t *testing.T) {
+	assert.Equal(t, "", histogramBar(0, 10))
+	assert.Equal(t, "#", histogramBar(1, 100), "a version with any installations should always have a bar")
+	assert.Equal(t, "##########", histogramBar(5, 10))
+	assert.Equal(t, "####################", histogramBar(10, 10))
+}
+
+func TestPorter_PrintInstallationsByBundle(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	createInstallation := func(namespace string, name string, version string) {
+		p.TestInstallations.CreateInstallation(storage.NewInstallation(namespace, name), func(i *storage.Installation) {
+			i.Bundle.Repository = "example.com/mysql"
+			i.Status.BundleVersion = version
+		})
+	}
+	createInstallation("dev", "mysql1", "0.1.0")
+	createInstallation("dev", "mysql2", "0.2.0")
+	createInstallation("test", "mysql3", "0.2.0")
+
+	opts := ListOptions{AllNamespaces: true, GroupBy: GroupByBundle}
+	opts.Format = printer.FormatJson
+	bundles, err := p.ListInstallationsByBundle(ctx, opts)
+	require.NoError(t, err)
+	require.Len(t, bundles, 1)
+	assert.Equal(t, 3, bundles[0].Installations)
+	require.Len(t, bundles[0].Versions, 2)
+	assert.Equal(t, "0.2.0", bundles[0].Versions[0].Version)
+	assert.Equal(t, 2, bundles[0].Versions[0].Installations)
+
+	opts.Format = printer.FormatPlaintext
+	require.NoError(t, p.PrintInstallations(ctx, opts))
+	output := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, output, "DISTRIBUTION")
+	assert.Contains(t, output, "example.com/mysql  0.2.0    2              #############")
+	assert.Contains(t, output, "example.com/mysql  0.1.0    1              ######")
+}
@@ -66,8 +66,12 @@ func TestPorter_ListInstallations(t *testing.T) {
 	i1.Status.RunID = "10" // Add a run but don't populate the data for it, list should not retrieve it
 
 	p.TestInstallations.CreateInstallation(i1)
-	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "carolyn-wordpress"))
-	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "vaughn-wordpress"))
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "carolyn-wordpress"), func(i *storage.Installation) {
+		i.Labels = map[string]string{"team": "red"}
+	})
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "vaughn-wordpress"), func(i *storage.Installation) {
+		i.Labels = map[string]string{"team": "blue", "env": "prod"}
+	})
 	p.TestInstallations.CreateInstallation(storage.NewInstallation("test", "staging-wordpress"))
 	p.TestInstallations.CreateInstallation(storage.NewInstallation("test", "iat-wordpress"))
 	p.TestInstallations.CreateInstallation(storage.NewInstallation("test", "shared-mysql"))
@@ -102,6 +106,19 @@ func TestPorter_ListInstallations(t *testing.T) {
 		require.NoError(t, err)
 		assert.Len(t, results, 1)
 	})
+
+	t.Run("label selector", func(t *testing.T) {
+		opts := ListOptions{AllNamespaces: true, Labels: []string{"team", "env!=prod"}}
+		results, err := p.ListInstallations(ctx, opts)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "carolyn-wordpress", results[0].Name)
+	})
+
+	t.Run("invalid label selector", func(t *testing.T) {
+		opts := ListOptions{Labels: []string{"app.name=mysql"}}
+		require.ErrorContains(t, opts.Validate(), "invalid label selector")
+	})
 }
 
 func TestDisplayInstallation_ConvertToInstallation(t *testing.T) {
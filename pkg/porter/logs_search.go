@@ -0,0 +1,270 @@
+package porter
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	dtprinter "github.com/carolynvs/datetime-printer"
+)
+
+// maxLogLineLength is the longest line of logs that can be searched.
+const maxLogLineLength = 1024 * 1024
+
+// LogsSearchOptions represent options for the logs search command.
+type LogsSearchOptions struct {
+	printer.PrintOptions
+
+	// Namespace of the installations to search.
+	Namespace string
+
+	// Installation to search. When empty, every installation in the namespace is searched.
+	Installation string
+
+	// RawSince is the unparsed --since flag.
+	RawSince string
+
+	// Since limits the search to runs created within the duration.
+	Since time.Duration
+
+	// Grep is the regular expression that log lines must match.
+	Grep string
+
+	pattern *regexp.Regexp
+}
+
+// Validate the logs search options.
+func (o *LogsSearchOptions) Validate() error {
+	if o.Grep == "" {
+		return errors.New("--grep is required")
+	}
+
+	var err error
+	if o.pattern, err = regexp.Compile(o.Grep); err != nil {
+		return fmt.Errorf("invalid --grep expression %q: %w", o.Grep, err)
+	}
+
+	if o.RawSince != "" {
+		if o.Since, err = parseSince(o.RawSince); err != nil {
+			return err
+		}
+	}
+
+	return o.PrintOptions.Validate(printer.FormatPlaintext, []printer.Format{printer.FormatPlaintext, printer.FormatJson, printer.FormatYaml})
+}
+
+// parseSince parses a duration, which in addition to the units supported by
+// time.ParseDuration may be specified in days, for example 7d.
+func parseSince(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid --since value %q, expected a number of days such as 7d or a duration such as 12h", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid --since value %q, expected a number of days such as 7d or a duration such as 12h", value)
+	}
+	return d, nil
+}
+
+// LogMatch is a line of logs from a run that matched a search.
+type LogMatch struct {
+	Namespace    string    `json:"namespace" yaml:"namespace"`
+	Installation string    `json:"installation" yaml:"installation"`
+	RunID        string    `json:"runId" yaml:"runId"`
+	Action       string    `json:"action" yaml:"action"`
+	Started      time.Time `json:"started" yaml:"started"`
+
+	// StepIndex is the index of the step that wrote the line, when it is known.
+	StepIndex *int `json:"stepIndex,omitempty" yaml:"stepIndex,omitempty"`
+
+	// StepDescription is the description of the step that wrote the line.
+	StepDescription string `json:"stepDescription,omitempty" yaml:"stepDescription,omitempty"`
+
+	// Line number of the match in the logs of the run, or of the step when the
+	// logs of the run were not persisted.
+	Line int `json:"line" yaml:"line"`
+
+	// Text of the line that matched.
+	Text string `json:"text" yaml:"text"`
+}
+
+// SearchLogs scans the logs of the runs of installations for lines that match
+// a regular expression. The logs of a run are read from the logs persisted
+// with --persist-logs, falling back to the logs recorded for each step.
+func (p *Porter) SearchLogs(ctx context.Context, opts LogsSearchOptions) ([]LogMatch, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if opts.Installation != "" {
+		// Report an installation that does not exist, instead of finding no logs
+		if _, err := p.Installations.GetInstallation(ctx, opts.Namespace, opts.Installation); err != nil {
+			return nil, span.Error(err)
+		}
+	}
+
+	listOpts := storage.ListLogsOptions{
+		Namespace:    opts.Namespace,
+		Installation: opts.Installation,
+	}
+	if opts.Since > 0 {
+		listOpts.Since = time.Now().Add(-opts.Since)
+	}
+	runs, err := p.Installations.ListRunLogs(ctx, listOpts)
+	if err != nil {
+		return nil, span.Error(fmt.Errorf("could not list the runs to search: %w", err))
+	}
+
+	var matches []LogMatch
+	for _, runLogs := range runs {
+		runMatches, err := p.searchRunLogs(ctx, runLogs, opts.pattern)
+		if err != nil {
+			return nil, span.Error(err)
+		}
+		matches = append(matches, runMatches...)
+	}
+
+	return matches, nil
+}
+
+// searchRunLogs returns the lines of the logs of a run that match the pattern.
+func (p *Porter) searchRunLogs(ctx context.Context, runLogs storage.RunLogs, pattern *regexp.Regexp) ([]LogMatch, error) {
+	run := runLogs.Run
+	steps := runLogs.Steps
+
+	newMatch := func(line int, text string, step *storage.StepResult) LogMatch {
+		m := LogMatch{
+			Namespace:    run.Namespace,
+			Installation: run.Installation,
+			RunID:        run.ID,
+			Action:       run.Action,
+			Started:      run.Created,
+			Line:         line,
+			Text:         text,
+		}
+		if step != nil {
+			index := step.Index
+			m.StepIndex = &index
+			m.StepDescription = step.Description
+		}
+		return m
+	}
+
+	var matches []LogMatch
+	if runLogs.Logs != nil {
+		logs, err := p.Installations.OpenOutputStream(ctx, *runLogs.Logs)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the logs of run %s: %w", run.ID, err)
+		}
+		defer logs.Close()
+
+		err = scanLogs(logs, pattern, func(line int, text string) {
+			matches = append(matches, newMatch(line, text, findStepForLine(steps, text)))
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not search the logs of run %s: %w", run.ID, err)
+		}
+		return matches, nil
+	}
+
+	// The logs of the run were not persisted, search the logs recorded for each step instead
+	for i := range steps {
+		step := &steps[i]
+		err := scanLogs(strings.NewReader(step.Logs), pattern, func(line int, text string) {
+			matches = append(matches, newMatch(line, text, step))
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not search the logs of step %d of run %s: %w", step.Index, run.ID, err)
+		}
+	}
+	return matches, nil
+}
+
+// scanLogs reads the logs one line at a time, calling match with the line
+// number and text of each line that matches the pattern.
+func scanLogs(logs io.Reader, pattern *regexp.Regexp, match func(line int, text string)) error {
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineLength)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if pattern.Match(scanner.Bytes()) {
+			match(line, scanner.Text())
+		}
+	}
+	return scanner.Err()
+}
+
+// findStepForLine returns the step whose recorded logs contain the line, or
+// nil when the step is not known, for example when the line was written
+// before the first step ran or the step's logs were truncated.
+func findStepForLine(steps []storage.StepResult, text string) *storage.StepResult {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	for i := range steps {
+		if strings.Contains(steps[i].Logs, text) {
+			return &steps[i]
+		}
+	}
+	return nil
+}
+
+// PrintLogsSearch prints the log lines of runs that match the search.
+func (p *Porter) PrintLogsSearch(ctx context.Context, opts LogsSearchOptions) error {
+	matches, err := p.SearchLogs(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, matches)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, matches)
+	case printer.FormatPlaintext:
+		if len(matches) == 0 {
+			fmt.Fprintln(p.Out, "No log lines matched the search")
+			return nil
+		}
+
+		now := time.Now()
+		tp := dtprinter.DateTimePrinter{
+			Now: func() time.Time { return now },
+		}
+
+		row := func(v interface{}) []string {
+			m, ok := v.(LogMatch)
+			if !ok {
+				return nil
+			}
+
+			step := ""
+			if m.StepIndex != nil {
+				step = strconv.Itoa(*m.StepIndex)
+				if m.StepDescription != "" {
+					step += ": " + m.StepDescription
+				}
+			}
+			return []string{m.Installation, m.RunID, m.Action, tp.Format(m.Started), step, strconv.Itoa(m.Line), m.Text}
+		}
+		return printer.PrintTable(p.Out, matches, row, "Installation", "Run ID", "Action", "Started", "Step", "Line", "Text")
+	}
+
+	return nil
+}
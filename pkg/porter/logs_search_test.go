@@ -0,0 +1,130 @@
+package porter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogsSearchOptions_Validate(t *testing.T) {
+	testcases := []struct {
+		name      string
+		opts      LogsSearchOptions
+		wantSince time.Duration
+		wantError string
+	}{
+		{name: "days", opts: LogsSearchOptions{Grep: "refused", RawSince: "7d"}, wantSince: 7 * 24 * time.Hour},
+		{name: "duration", opts: LogsSearchOptions{Grep: "refused", RawSince: "12h"}, wantSince: 12 * time.Hour},
+		{name: "all runs", opts: LogsSearchOptions{Grep: "refused"}},
+		{name: "missing grep", opts: LogsSearchOptions{}, wantError: "--grep is required"},
+		{name: "invalid grep", opts: LogsSearchOptions{Grep: "conn("}, wantError: "invalid --grep expression"},
+		{name: "invalid since", opts: LogsSearchOptions{Grep: "refused", RawSince: "1w"}, wantError: `invalid --since value "1w"`},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.opts.Validate()
+			if tc.wantError != "" {
+				require.ErrorContains(t, err, tc.wantError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantSince, tc.opts.Since)
+		})
+	}
+}
+
+func TestPorter_SearchLogs(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	// A run with persisted logs
+	i := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "wordpress"))
+	run := p.TestInstallations.CreateRun(i.NewRun(cnab.ActionInstall))
+	result := p.TestInstallations.CreateResult(run.NewResult(cnab.StatusFailed))
+	logs := "Installing mysql\nconnecting to db.example.com\ndial tcp: connection refused\nfailed\n"
+	p.TestInstallations.CreateOutput(result.NewOutput(cnab.OutputInvocationImageLogs, []byte(logs)))
+	require.NoError(t, p.TestInstallations.InsertStepResults(ctx, []storage.StepResult{
+		run.NewStepResult(storage.StepResult{Index: 0, Description: "Install mysql", Mixin: "helm3", Logs: "connecting to db.example.com\ndial tcp: connection refused\n"}),
+	}))
+
+	// A run without persisted logs, only the step logs are available
+	other := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+	otherRun := p.TestInstallations.CreateRun(other.NewRun(cnab.ActionUpgrade))
+	require.NoError(t, p.TestInstallations.InsertStepResults(ctx, []storage.StepResult{
+		otherRun.NewStepResult(storage.StepResult{Index: 0, Description: "Backup", Mixin: "exec", Logs: "ok\n"}),
+		otherRun.NewStepResult(storage.StepResult{Index: 1, Description: "Upgrade", Mixin: "exec", Logs: "waiting\nconnection refused\n"}),
+	}))
+
+	// An old run that is excluded by --since
+	oldRun := p.TestInstallations.CreateRun(i.NewRun(cnab.ActionInstall), func(r *storage.Run) {
+		r.Created = time.Now().Add(-30 * 24 * time.Hour)
+	})
+	oldResult := p.TestInstallations.CreateResult(oldRun.NewResult(cnab.StatusFailed))
+	p.TestInstallations.CreateOutput(oldResult.NewOutput(cnab.OutputInvocationImageLogs, []byte("connection refused\n")))
+
+	search := func(t *testing.T, opts LogsSearchOptions) []LogMatch {
+		require.NoError(t, opts.Validate())
+		matches, err := p.SearchLogs(ctx, opts)
+		require.NoError(t, err)
+		return matches
+	}
+
+	t.Run("installation", func(t *testing.T) {
+		matches := search(t, LogsSearchOptions{Namespace: "dev", Installation: "wordpress", RawSince: "7d", Grep: "connection refused"})
+		require.Len(t, matches, 1, "the old run should be excluded")
+		m := matches[0]
+		assert.Equal(t, run.ID, m.RunID)
+		assert.Equal(t, 3, m.Line)
+		assert.Equal(t, "dial tcp: connection refused", m.Text)
+		require.NotNil(t, m.StepIndex, "the step that wrote the line should be identified")
+		assert.Equal(t, 0, *m.StepIndex)
+		assert.Equal(t, "Install mysql", m.StepDescription)
+	})
+
+	t.Run("namespace", func(t *testing.T) {
+		matches := search(t, LogsSearchOptions{Namespace: "dev", Grep: "connection (refused|reset)"})
+		require.Len(t, matches, 3)
+
+		var stepMatch LogMatch
+		for _, m := range matches {
+			if m.RunID == otherRun.ID {
+				stepMatch = m
+			}
+		}
+		assert.Equal(t, "mysql", stepMatch.Installation)
+		require.NotNil(t, stepMatch.StepIndex, "runs without persisted logs should be searched using the step logs")
+		assert.Equal(t, 1, *stepMatch.StepIndex)
+		assert.Equal(t, 2, stepMatch.Line)
+	})
+
+	t.Run("installation not found", func(t *testing.T) {
+		_, err := p.SearchLogs(ctx, LogsSearchOptions{Namespace: "dev", Installation: "missing", Grep: "refused"})
+		require.ErrorIs(t, err, storage.ErrNotFound{})
+	})
+
+	t.Run("print", func(t *testing.T) {
+		p.TestConfig.TestContext.ClearOutputs()
+		opts := LogsSearchOptions{Namespace: "dev", Installation: "wordpress", RawSince: "7d", Grep: "refused"}
+		opts.Format = printer.FormatPlaintext
+		require.NoError(t, opts.Validate())
+		require.NoError(t, p.PrintLogsSearch(ctx, opts))
+		out := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, out, "0: Install mysql")
+		assert.Contains(t, out, "dial tcp: connection refused")
+
+		p.TestConfig.TestContext.ClearOutputs()
+		opts.Grep = "no such text"
+		require.NoError(t, opts.Validate())
+		require.NoError(t, p.PrintLogsSearch(ctx, opts))
+		assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "No log lines matched the search")
+	})
+}
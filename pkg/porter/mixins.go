@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"get.porter.sh/porter/pkg/mixin"
 	"get.porter.sh/porter/pkg/pkgmgmt"
@@ -59,16 +60,10 @@ func (p *Porter) ListMixins(ctx context.Context) ([]mixin.Metadata, error) {
 	}
 
 	// Query each mixin and fill out their metadata
-	mixins := make([]mixin.Metadata, len(names))
-	for i, name := range names {
-		m, err := p.Mixins.GetMetadata(ctx, name)
-		if err != nil {
-			fmt.Fprintf(p.Err, "could not get version from mixin %s: %s\n ", name, err.Error())
-			continue
-		}
-
-		meta, _ := m.(*mixin.Metadata)
-		mixins[i] = *meta
+	mixins, err := mixin.GetAllMetadata(ctx, p.Mixins, names)
+	if err != nil {
+		// Still list the mixins that responded
+		fmt.Fprintln(p.Err, err.Error())
 	}
 
 	return mixins, nil
@@ -102,6 +97,100 @@ func (p *Porter) UninstallMixin(ctx context.Context, opts pkgmgmt.UninstallOptio
 	return nil
 }
 
+// MixinsDoctorOptions are the options for the mixins doctor command.
+type MixinsDoctorOptions struct {
+	printer.PrintOptions
+
+	// Name of the mixin to check. Defaults to every installed mixin.
+	Name string
+}
+
+// Validate the mixins doctor options.
+func (o *MixinsDoctorOptions) Validate(args []string) error {
+	switch len(args) {
+	case 0:
+	case 1:
+		o.Name = args[0]
+	default:
+		return fmt.Errorf("only one positional argument may be specified, the mixin name, but multiple were received: %s", args)
+	}
+
+	return o.PrintOptions.Validate(printer.FormatPlaintext, []printer.Format{printer.FormatPlaintext, printer.FormatJson, printer.FormatYaml})
+}
+
+// MixinsDoctor checks the installed mixins for problems, such as missing or
+// non-executable binaries, and mixins that do not report their version or
+// schema. An error is returned when any mixin is unhealthy.
+func (p *Porter) MixinsDoctor(ctx context.Context, opts MixinsDoctorOptions) error {
+	names := []string{opts.Name}
+	if opts.Name == "" {
+		var err error
+		if names, err = p.Mixins.List(); err != nil {
+			return err
+		}
+	}
+
+	results, checkErr := mixin.CheckAllMixins(ctx, p.Mixins, names)
+
+	// Only report the mixins that could be checked
+	checked := make([]mixin.MixinHealth, 0, len(results))
+	for _, health := range results {
+		if health.Name != "" {
+			checked = append(checked, health)
+		}
+	}
+
+	var err error
+	switch opts.Format {
+	case printer.FormatPlaintext:
+		err = p.printMixinsHealth(checked)
+	case printer.FormatJson:
+		err = printer.PrintJson(p.Out, checked)
+	case printer.FormatYaml:
+		err = printer.PrintYaml(p.Out, checked)
+	}
+	if err != nil {
+		return err
+	}
+
+	if checkErr != nil {
+		return checkErr
+	}
+
+	var unhealthy []string
+	for _, health := range checked {
+		if !health.Healthy() {
+			unhealthy = append(unhealthy, health.Name)
+		}
+	}
+	if len(unhealthy) > 0 {
+		return fmt.Errorf("found problems with the mixins: %s", strings.Join(unhealthy, ", "))
+	}
+	return nil
+}
+
+func (p *Porter) printMixinsHealth(results []mixin.MixinHealth) error {
+	type checkRow struct {
+		mixinName string
+		mixin.CheckResult
+	}
+	var rows []checkRow
+	for _, health := range results {
+		for _, check := range health.Checks {
+			rows = append(rows, checkRow{mixinName: health.Name, CheckResult: check})
+		}
+	}
+
+	printCheckRow := func(v interface{}) []string {
+		row, ok := v.(checkRow)
+		if !ok {
+			return nil
+		}
+		return []string{row.mixinName, row.Name, row.Status, row.Message}
+	}
+	return printer.PrintTable(p.Out, rows, printCheckRow, "Mixin", "Check", "Status", "Message")
+}
+
 func (p *Porter) GenerateMixinFeed(ctx context.Context, opts feed.GenerateOptions) error {
 	f := feed.NewMixinFeed(p.Context)
 
@@ -30,6 +30,56 @@ func TestPorter_PrintMixins(t *testing.T) {
 	test.CompareGoldenFile(t, "mixins/list-output.txt", gotOutput)
 }
 
+func TestPorter_MixinsDoctor(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("healthy", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := MixinsDoctorOptions{}
+		require.NoError(t, opts.Validate(nil))
+		err := p.MixinsDoctor(ctx, opts)
+		require.NoError(t, err)
+
+		gotOutput := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, gotOutput, "exec")
+		assert.Contains(t, gotOutput, "testmixin")
+		assert.NotContains(t, gotOutput, mixin.CheckStatusFailed)
+	})
+
+	t.Run("unhealthy", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		testMixins := p.Mixins.(*mixin.TestMixinProvider)
+		testMixins.MixinHealth = map[string]mixin.MixinHealth{
+			"testmixin": {Name: "testmixin", Checks: []mixin.CheckResult{
+				{Name: mixin.CheckRuntime, Status: mixin.CheckStatusFailed, Message: "runtime missing"},
+			}},
+		}
+
+		opts := MixinsDoctorOptions{}
+		require.NoError(t, opts.Validate([]string{"testmixin"}))
+		err := p.MixinsDoctor(ctx, opts)
+		require.EqualError(t, err, "found problems with the mixins: testmixin")
+
+		gotOutput := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, gotOutput, "runtime missing")
+		assert.NotContains(t, gotOutput, "exec", "only the requested mixin should be checked")
+	})
+
+	t.Run("not installed", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := MixinsDoctorOptions{PrintOptions: printer.PrintOptions{RawFormat: "json"}}
+		require.NoError(t, opts.Validate([]string{"helm3"}))
+		err := p.MixinsDoctor(ctx, opts)
+		require.ErrorContains(t, err, "could not check the helm3 mixin")
+		assert.Equal(t, "[]\n", p.TestConfig.TestContext.GetOutput())
+	})
+}
+
 func TestPorter_InstallMixin(t *testing.T) {
 	p := NewTestPorter(t)
 	defer p.Close()
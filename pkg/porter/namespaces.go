@@ -0,0 +1,282 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	dtprinter "github.com/carolynvs/datetime-printer"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// NamespaceCreateOptions represent options for Porter's namespace create command.
+type NamespaceCreateOptions struct {
+	// Name of the namespace.
+	Name string
+
+	// Description of the namespace.
+	Description string
+
+	// Owners of the namespace.
+	Owners []string
+
+	// Labels to apply to the namespace.
+	Labels []string
+
+	// DefaultCredentialSets used by new installations in the namespace.
+	DefaultCredentialSets []string
+
+	// DefaultParameterSets used by new installations in the namespace.
+	DefaultParameterSets []string
+
+	// ProtectDeletion prevents the namespace from being deleted without --force.
+	ProtectDeletion bool
+
+	// ProtectInstallations prevents installations in the namespace from being
+	// deleted without --force.
+	ProtectInstallations bool
+}
+
+// Validate the args provided to Porter's namespace create command.
+func (o *NamespaceCreateOptions) Validate(args []string) error {
+	name, err := validateNamespaceName(args)
+	if err != nil {
+		return err
+	}
+	o.Name = name
+	return nil
+}
+
+// ToNamespace creates the namespace document defined by the options.
+func (o NamespaceCreateOptions) ToNamespace() storage.Namespace {
+	ns := storage.NewNamespace(o.Name)
+	ns.Description = o.Description
+	ns.Owners = o.Owners
+	ns.Labels = parseLabels(o.Labels)
+	ns.DefaultCredentialSets = o.DefaultCredentialSets
+	ns.DefaultParameterSets = o.DefaultParameterSets
+	ns.Protection = storage.NamespaceProtection{
+		Deletion:      o.ProtectDeletion,
+		Installations: o.ProtectInstallations,
+	}
+	return ns
+}
+
+// CreateNamespace saves a new namespace document.
+func (p *Porter) CreateNamespace(ctx context.Context, opts NamespaceCreateOptions) error {
+	ctx, span := tracing.StartSpan(ctx, attribute.String("namespace", opts.Name))
+	defer span.EndSpan()
+
+	ns := opts.ToNamespace()
+	if err := ns.Validate(); err != nil {
+		return span.Error(err)
+	}
+
+	_, err := p.Namespaces.GetNamespace(ctx, ns.Name)
+	if err == nil {
+		return span.Error(fmt.Errorf("namespace %s already exists", ns.Name))
+	}
+	if !errors.Is(err, storage.ErrNotFound{}) {
+		return span.Error(fmt.Errorf("could not query for an existing namespace document for %s: %w", ns.Name, err))
+	}
+
+	if err = p.Namespaces.InsertNamespace(ctx, ns); err != nil {
+		return span.Error(fmt.Errorf("unable to save namespace %s: %w", ns.Name, err))
+	}
+
+	fmt.Fprintf(p.Out, "Created namespace %s\n", ns.Name)
+	return nil
+}
+
+// NamespaceListOptions represent options for Porter's namespace list command.
+type NamespaceListOptions struct {
+	printer.PrintOptions
+	Name   string
+	Labels []string
+	Skip   int64
+	Limit  int64
+}
+
+// Validate the options provided to Porter's namespace list command.
+func (o *NamespaceListOptions) Validate() error {
+	if _, err := storage.ParseLabelSelector(o.Labels); err != nil {
+		return err
+	}
+	return o.ParseFormat()
+}
+
+// ListNamespaces lists saved namespace documents.
+func (p *Porter) ListNamespaces(ctx context.Context, opts NamespaceListOptions) ([]storage.Namespace, error) {
+	selector, err := storage.ParseLabelSelector(opts.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Namespaces.ListNamespaces(ctx, storage.ListOptions{
+		Name:          opts.Name,
+		LabelSelector: selector,
+		Skip:          opts.Skip,
+		Limit:         opts.Limit,
+	})
+}
+
+// PrintNamespaces prints saved namespace documents.
+func (p *Porter) PrintNamespaces(ctx context.Context, opts NamespaceListOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	namespaces, err := p.ListNamespaces(ctx, opts)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, namespaces)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, namespaces)
+	case printer.FormatPlaintext:
+		// have every row use the same "now" starting ... NOW!
+		now := time.Now()
+		tp := dtprinter.DateTimePrinter{
+			Now: func() time.Time { return now },
+		}
+
+		row :=
+			func(v interface{}) []string {
+				ns, ok := v.(storage.Namespace)
+				if !ok {
+					return nil
+				}
+				return []string{ns.Name, ns.Description, strings.Join(ns.Owners, ", "), getDisplayNamespaceProtection(ns.Protection), tp.Format(ns.Status.Modified)}
+			}
+		return printer.PrintTable(p.Out, namespaces, row,
+			"NAME", "DESCRIPTION", "OWNERS", "PROTECTED", "MODIFIED")
+	default:
+		return span.Error(fmt.Errorf("invalid format: %s", opts.Format))
+	}
+}
+
+func getDisplayNamespaceProtection(protection storage.NamespaceProtection) string {
+	var protected []string
+	if protection.Deletion {
+		protected = append(protected, "deletion")
+	}
+	if protection.Installations {
+		protected = append(protected, "installations")
+	}
+	return strings.Join(protected, ", ")
+}
+
+// NamespaceDeleteOptions represent options for Porter's namespace delete command.
+type NamespaceDeleteOptions struct {
+	Name  string
+	Force bool
+}
+
+// Validate the args provided to Porter's namespace delete command.
+func (o *NamespaceDeleteOptions) Validate(args []string) error {
+	name, err := validateNamespaceName(args)
+	if err != nil {
+		return err
+	}
+	o.Name = name
+	return nil
+}
+
+// DeleteNamespace removes a namespace document. The resources defined in the
+// namespace are not removed, so a namespace that is protected or still
+// contains installations is only deleted when forced.
+func (p *Porter) DeleteNamespace(ctx context.Context, opts NamespaceDeleteOptions) error {
+	ctx, span := tracing.StartSpan(ctx, attribute.String("namespace", opts.Name))
+	defer span.EndSpan()
+
+	ns, err := p.Namespaces.GetNamespace(ctx, opts.Name)
+	if errors.Is(err, storage.ErrNotFound{}) {
+		span.Debug("nothing to remove, namespace already does not exist")
+		return nil
+	}
+	if err != nil {
+		return span.Error(fmt.Errorf("could not retrieve namespace %s: %w", opts.Name, err))
+	}
+
+	if !opts.Force {
+		if ns.Protection.Deletion {
+			return span.Error(fmt.Errorf("namespace %s is protected from deletion; if you are sure it should be deleted, retry the last command with the --force flag", ns.Name))
+		}
+
+		installations, err := p.Installations.ListInstallations(ctx, storage.ListOptions{Namespace: ns.Name, Limit: 1})
+		if err != nil {
+			return span.Error(fmt.Errorf("could not list the installations in namespace %s: %w", ns.Name, err))
+		}
+		if len(installations) > 0 {
+			return span.Error(fmt.Errorf("namespace %s still has installations; if you are sure it should be deleted, retry the last command with the --force flag", ns.Name))
+		}
+	}
+
+	if err = p.Namespaces.RemoveNamespace(ctx, ns.Name); err != nil {
+		return span.Error(fmt.Errorf("unable to delete namespace %s: %w", ns.Name, err))
+	}
+	return nil
+}
+
+func validateNamespaceName(args []string) (string, error) {
+	switch len(args) {
+	case 0:
+		return "", errors.New("no namespace name was specified")
+	case 1:
+		return args[0], nil
+	default:
+		return "", fmt.Errorf("only one positional argument may be specified, the namespace name, but multiple were received: %s", args)
+	}
+}
+
+// getNamespace returns the document for a namespace. Namespaces do not need
+// to be created before they are used, so a namespace without a document is
+// returned with only its name set.
+func (p *Porter) getNamespace(ctx context.Context, name string) (storage.Namespace, error) {
+	ns, err := p.Namespaces.GetNamespace(ctx, name)
+	if errors.Is(err, storage.ErrNotFound{}) {
+		return storage.NewNamespace(name), nil
+	}
+	if err != nil {
+		return storage.Namespace{}, fmt.Errorf("could not retrieve namespace %s: %w", name, err)
+	}
+	return ns, nil
+}
+
+// applyNamespaceDefaults uses the default credential and parameter sets of
+// the installation's namespace when the installation does not specify any.
+func (p *Porter) applyNamespaceDefaults(ctx context.Context, inst *storage.Installation) error {
+	ns, err := p.getNamespace(ctx, inst.Namespace)
+	if err != nil {
+		return err
+	}
+
+	if len(inst.CredentialSets) == 0 {
+		inst.CredentialSets = ns.DefaultCredentialSets
+	}
+	if len(inst.ParameterSets) == 0 {
+		inst.ParameterSets = ns.DefaultParameterSets
+	}
+	return nil
+}
+
+// checkInstallationProtection returns an error when the installation's
+// namespace protects its installations from deletion.
+func (p *Porter) checkInstallationProtection(ctx context.Context, namespace string, name string) error {
+	ns, err := p.getNamespace(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	if ns.Protection.Installations {
+		return fmt.Errorf("installation %s/%s is protected from deletion by its namespace", namespace, name)
+	}
+	return nil
+}
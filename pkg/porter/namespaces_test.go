@@ -0,0 +1,199 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespaceCreateOptions_Validate(t *testing.T) {
+	opts := NamespaceCreateOptions{}
+	require.EqualError(t, opts.Validate(nil), "no namespace name was specified")
+	require.ErrorContains(t, opts.Validate([]string{"dev", "test"}), "only one positional argument may be specified")
+
+	require.NoError(t, opts.Validate([]string{"dev"}))
+	assert.Equal(t, "dev", opts.Name)
+}
+
+func TestPorter_CreateNamespace(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	opts := NamespaceCreateOptions{
+		Name:                  "prod",
+		Description:           "Production environments",
+		Owners:                []string{"platform-team"},
+		Labels:                []string{"env=prod"},
+		DefaultCredentialSets: []string{"kubeconfig"},
+		DefaultParameterSets:  []string{"prod-defaults"},
+		ProtectDeletion:       true,
+	}
+	require.NoError(t, p.CreateNamespace(ctx, opts))
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "Created namespace prod")
+
+	ns, err := p.Namespaces.GetNamespace(ctx, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "Production environments", ns.Description)
+	assert.Equal(t, []string{"platform-team"}, ns.Owners)
+	assert.Equal(t, map[string]string{"env": "prod"}, ns.Labels)
+	assert.Equal(t, []string{"kubeconfig"}, ns.DefaultCredentialSets)
+	assert.Equal(t, []string{"prod-defaults"}, ns.DefaultParameterSets)
+	assert.True(t, ns.Protection.Deletion)
+	assert.False(t, ns.Protection.Installations)
+
+	err = p.CreateNamespace(ctx, opts)
+	require.EqualError(t, err, "namespace prod already exists")
+
+	err = p.CreateNamespace(ctx, NamespaceCreateOptions{Name: "*"})
+	require.ErrorContains(t, err, "not a valid namespace name")
+}
+
+func TestPorter_PrintNamespaces(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	require.NoError(t, p.CreateNamespace(ctx, NamespaceCreateOptions{Name: "dev", Owners: []string{"alice", "bob"}}))
+	require.NoError(t, p.CreateNamespace(ctx, NamespaceCreateOptions{Name: "prod", Description: "Production", ProtectDeletion: true, ProtectInstallations: true}))
+	p.TestConfig.TestContext.ClearOutputs()
+
+	opts := NamespaceListOptions{}
+	opts.Format = printer.FormatPlaintext
+	require.NoError(t, p.PrintNamespaces(ctx, opts))
+
+	output := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, output, "alice, bob")
+	assert.Contains(t, output, "Production")
+	assert.Contains(t, output, "deletion, installations")
+
+	namespaces, err := p.ListNamespaces(ctx, NamespaceListOptions{Name: "pro"})
+	require.NoError(t, err)
+	require.Len(t, namespaces, 1)
+	assert.Equal(t, "prod", namespaces[0].Name)
+}
+
+func TestPorter_DeleteNamespace(t *testing.T) {
+	ctx := context.Background()
+
+	testcases := []struct {
+		name            string
+		protected       bool
+		hasInstallation bool
+		force           bool
+		wantError       string
+	}{
+		{name: "unprotected"},
+		{name: "protected", protected: true, wantError: "namespace dev is protected from deletion"},
+		{name: "protected; --force", protected: true, force: true},
+		{name: "has installations", hasInstallation: true, wantError: "namespace dev still has installations"},
+		{name: "has installations; --force", hasInstallation: true, force: true},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewTestPorter(t)
+			defer p.Close()
+
+			require.NoError(t, p.CreateNamespace(ctx, NamespaceCreateOptions{Name: "dev", ProtectDeletion: tc.protected}))
+			if tc.hasInstallation {
+				p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybuns"))
+			}
+
+			err := p.DeleteNamespace(ctx, NamespaceDeleteOptions{Name: "dev", Force: tc.force})
+			_, getErr := p.Namespaces.GetNamespace(ctx, "dev")
+			if tc.wantError != "" {
+				require.ErrorContains(t, err, tc.wantError)
+				require.NoError(t, getErr, "the namespace should not be deleted")
+			} else {
+				require.NoError(t, err)
+				require.ErrorIs(t, getErr, storage.ErrNotFound{})
+			}
+
+			if tc.hasInstallation {
+				_, err = p.Installations.GetInstallation(ctx, "dev", "mybuns")
+				require.NoError(t, err, "the installations in the namespace should not be deleted")
+			}
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		err := p.DeleteNamespace(ctx, NamespaceDeleteOptions{Name: "missing"})
+		require.NoError(t, err)
+	})
+}
+
+func TestPorter_applyNamespaceDefaults(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	require.NoError(t, p.CreateNamespace(ctx, NamespaceCreateOptions{
+		Name:                  "dev",
+		DefaultCredentialSets: []string{"kubeconfig"},
+		DefaultParameterSets:  []string{"dev-defaults"},
+	}))
+
+	t.Run("defaults applied", func(t *testing.T) {
+		inst := storage.NewInstallation("dev", "mybuns")
+		require.NoError(t, p.applyNamespaceDefaults(ctx, &inst))
+		assert.Equal(t, []string{"kubeconfig"}, inst.CredentialSets)
+		assert.Equal(t, []string{"dev-defaults"}, inst.ParameterSets)
+	})
+
+	t.Run("sets specified", func(t *testing.T) {
+		inst := storage.NewInstallation("dev", "mybuns")
+		inst.CredentialSets = []string{"mycreds"}
+		require.NoError(t, p.applyNamespaceDefaults(ctx, &inst))
+		assert.Equal(t, []string{"mycreds"}, inst.CredentialSets)
+		assert.Equal(t, []string{"dev-defaults"}, inst.ParameterSets)
+	})
+
+	t.Run("namespace not created", func(t *testing.T) {
+		inst := storage.NewInstallation("test", "mybuns")
+		require.NoError(t, p.applyNamespaceDefaults(ctx, &inst))
+		assert.Empty(t, inst.CredentialSets)
+		assert.Empty(t, inst.ParameterSets)
+	})
+}
+
+func TestDeleteInstallation_ProtectedNamespace(t *testing.T) {
+	ctx := context.Background()
+
+	for _, force := range []bool{false, true} {
+		force := force
+		t.Run("force="+map[bool]string{false: "false", true: "true"}[force], func(t *testing.T) {
+			p := NewTestPorter(t)
+			defer p.Close()
+
+			require.NoError(t, p.CreateNamespace(ctx, NamespaceCreateOptions{Name: "prod", ProtectInstallations: true}))
+			i := p.TestInstallations.CreateInstallation(storage.NewInstallation("prod", "mybuns"), func(i *storage.Installation) {
+				i.Status.Action = cnab.ActionUninstall
+				i.Status.ResultStatus = cnab.StatusSucceeded
+			})
+
+			opts := DeleteOptions{Force: force}
+			opts.Namespace = i.Namespace
+			opts.Name = i.Name
+			err := p.DeleteInstallation(ctx, opts)
+
+			_, getErr := p.Installations.GetInstallation(ctx, "prod", "mybuns")
+			if force {
+				require.NoError(t, err)
+				require.ErrorIs(t, getErr, storage.ErrNotFound{})
+			} else {
+				require.ErrorContains(t, err, "installation prod/mybuns is protected from deletion by its namespace")
+				require.NoError(t, getErr, "the installation should not be deleted")
+			}
+		})
+	}
+}
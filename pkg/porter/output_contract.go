@@ -0,0 +1,341 @@
+package porter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/cnabio/cnab-go/bundle/definition"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// ContractViolationMissing indicates that a required output was not found.
+	ContractViolationMissing = "missing"
+
+	// ContractViolationType indicates that the type of an output does not match the contract.
+	ContractViolationType = "type"
+
+	// ContractViolationSchema indicates that the value of an output does not satisfy the schema in the contract.
+	ContractViolationSchema = "schema"
+)
+
+// OutputContract is provided by consumers of an installation's outputs, such
+// as automation owned by another team, and describes the outputs that they
+// depend upon.
+type OutputContract struct {
+	// Outputs that are expected, keyed by the output name.
+	Outputs map[string]OutputContractDefinition `json:"outputs"`
+}
+
+// OutputContractDefinition describes an output expected by a contract.
+type OutputContractDefinition struct {
+	// Type of the output as defined by the bundle, for example string,
+	// integer, number, boolean, object, array or file. When empty, any type
+	// is accepted.
+	Type string `json:"type,omitempty"`
+
+	// Optional outputs are not required to be present.
+	Optional bool `json:"optional,omitempty"`
+
+	// Schema is a json schema that the output value must satisfy.
+	Schema *definition.Schema `json:"schema,omitempty"`
+}
+
+// OutputVerifyOptions are the options for the installation outputs verify command.
+type OutputVerifyOptions struct {
+	installationOptions
+	printer.PrintOptions
+
+	// Contract is the path to the contract file.
+	Contract string
+}
+
+// Validate the options provided to the installation outputs verify command.
+func (o *OutputVerifyOptions) Validate(args []string, cxt *portercontext.Context) error {
+	err := o.installationOptions.validateInstallationName(args)
+	if err != nil {
+		return err
+	}
+
+	err = o.installationOptions.defaultBundleFiles(cxt)
+	if err != nil {
+		return fmt.Errorf("installation name must be provided: %w", err)
+	}
+
+	if o.Contract == "" {
+		return errors.New("the path to a contract file must be specified with --contract")
+	}
+
+	return o.ParseFormat()
+}
+
+// OutputContractResult is the result of verifying the outputs of an
+// installation against a contract.
+type OutputContractResult struct {
+	// Namespace of the installation.
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// Installation name.
+	Installation string `json:"installation" yaml:"installation"`
+
+	// RunID of the last run of the installation.
+	RunID string `json:"runId" yaml:"runId"`
+
+	// Contract is the path to the contract file.
+	Contract string `json:"contract" yaml:"contract"`
+
+	// Violations of the contract, sorted by output name.
+	Violations []OutputContractViolation `json:"violations" yaml:"violations"`
+}
+
+// Satisfied returns true when the outputs did not violate the contract.
+func (r OutputContractResult) Satisfied() bool {
+	return len(r.Violations) == 0
+}
+
+// OutputContractViolation is a difference between the contract and an output.
+type OutputContractViolation struct {
+	// Output name.
+	Output string `json:"output" yaml:"output"`
+
+	// Kind of violation: missing, type or schema.
+	Kind string `json:"kind" yaml:"kind"`
+
+	// Expected is what the contract defined.
+	Expected string `json:"expected" yaml:"expected"`
+
+	// Actual is what the installation has. Sensitive values are redacted.
+	Actual string `json:"actual" yaml:"actual"`
+
+	// Message explains why a value does not satisfy the schema.
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// ReadOutputContract reads and validates a contract file.
+func (p *Porter) ReadOutputContract(path string) (OutputContract, error) {
+	var contract OutputContract
+
+	data, err := p.FileSystem.ReadFile(path)
+	if err != nil {
+		return contract, fmt.Errorf("could not read contract file %s: %w", path, err)
+	}
+
+	if err = json.Unmarshal(data, &contract); err != nil {
+		return contract, fmt.Errorf("could not parse contract file %s: %w", path, err)
+	}
+
+	for name, def := range contract.Outputs {
+		if def.Schema == nil {
+			continue
+		}
+		if _, err = def.Schema.ValidateSchema(); err != nil {
+			return contract, fmt.Errorf("invalid schema for output %s in contract file %s: %w", name, path, err)
+		}
+	}
+
+	return contract, nil
+}
+
+// VerifyOutputContract checks the outputs of the last run of an installation
+// against a contract, returning any violations.
+func (p *Porter) VerifyOutputContract(ctx context.Context, opts *OutputVerifyOptions) (OutputContractResult, error) {
+	ctx, span := tracing.StartSpan(ctx, attribute.String("contract", opts.Contract))
+	defer span.EndSpan()
+
+	err := p.applyDefaultOptions(ctx, &opts.installationOptions)
+	if err != nil {
+		return OutputContractResult{}, span.Error(err)
+	}
+
+	contract, err := p.ReadOutputContract(opts.Contract)
+	if err != nil {
+		return OutputContractResult{}, span.Error(err)
+	}
+
+	run, err := p.Installations.GetLastRun(ctx, opts.Namespace, opts.Name)
+	if err != nil {
+		return OutputContractResult{}, span.Error(err)
+	}
+
+	outputs, err := p.Installations.GetLastOutputs(ctx, opts.Namespace, opts.Name)
+	if err != nil {
+		return OutputContractResult{}, span.Error(err)
+	}
+
+	outputs, err = p.readChunkedOutputs(ctx, outputs)
+	if err != nil {
+		return OutputContractResult{}, span.Error(err)
+	}
+
+	outputs, err = p.Sanitizer.RestoreOutputs(ctx, outputs)
+	if err != nil {
+		return OutputContractResult{}, span.Error(err)
+	}
+
+	violations, err := p.checkOutputContract(contract, cnab.NewBundle(run.Bundle), outputs)
+	if err != nil {
+		return OutputContractResult{}, span.Error(err)
+	}
+
+	result := OutputContractResult{
+		Namespace:    opts.Namespace,
+		Installation: opts.Name,
+		RunID:        run.ID,
+		Contract:     opts.Contract,
+		Violations:   violations,
+	}
+	return result, nil
+}
+
+// checkOutputContract compares the outputs to the contract. The values of
+// sensitive outputs are redacted from the violations.
+func (p *Porter) checkOutputContract(contract OutputContract, bun cnab.ExtendedBundle, outputs storage.Outputs) ([]OutputContractViolation, error) {
+	names := make([]string, 0, len(contract.Outputs))
+	for name := range contract.Outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	violations := make([]OutputContractViolation, 0)
+	for _, name := range names {
+		def := contract.Outputs[name]
+
+		output, ok := outputs.GetByName(name)
+		if !ok {
+			if !def.Optional {
+				violations = append(violations, OutputContractViolation{
+					Output:   name,
+					Kind:     ContractViolationMissing,
+					Expected: "present",
+					Actual:   "missing",
+				})
+			}
+			continue
+		}
+
+		schema, hasSchema := output.GetSchema(bun)
+		outputType := "unknown"
+		if hasSchema {
+			outputType = bun.GetParameterType(&schema)
+		}
+		if def.Type != "" && def.Type != outputType {
+			violations = append(violations, OutputContractViolation{
+				Output:   name,
+				Kind:     ContractViolationType,
+				Expected: def.Type,
+				Actual:   outputType,
+			})
+		}
+
+		if def.Schema == nil {
+			continue
+		}
+
+		// Convert the value to the type defined by the bundle, so that it is
+		// validated as a number, object, etc. instead of as a string
+		var value interface{} = string(output.Value)
+		if hasSchema {
+			if converted, err := schema.ConvertValue(string(output.Value)); err == nil {
+				value = converted
+			}
+		}
+
+		// Outputs saved to the secret store are sensitive, even when the
+		// bundle was changed after they were saved
+		sensitive, err := p.Sanitizer.IsOutputSensitive(bun, name)
+		if err != nil {
+			return nil, fmt.Errorf("could not determine if output %s is sensitive: %w", name, err)
+		}
+		sensitive = sensitive || output.Key != ""
+		actual := string(output.Value)
+		if sensitive {
+			actual = maskedValue
+		}
+
+		valErrs, err := def.Schema.Validate(value)
+		if err != nil {
+			valErrs = []definition.ValidationError{{Path: "/", Error: err.Error()}}
+		}
+		if len(valErrs) == 0 {
+			continue
+		}
+
+		messages := make([]string, len(valErrs))
+		for i, valErr := range valErrs {
+			messages[i] = fmt.Sprintf("%s: %s", valErr.Path, valErr.Error)
+			if sensitive && len(output.Value) > 0 {
+				messages[i] = strings.ReplaceAll(messages[i], string(output.Value), maskedValue)
+			}
+		}
+		expected, _ := json.Marshal(def.Schema)
+		violations = append(violations, OutputContractViolation{
+			Output:   name,
+			Kind:     ContractViolationSchema,
+			Expected: string(expected),
+			Actual:   actual,
+			Message:  strings.Join(messages, ", "),
+		})
+	}
+	return violations, nil
+}
+
+// PrintOutputContractResult verifies the outputs of an installation against a
+// contract, printing the differences and returning an error when the contract
+// is not satisfied.
+func (p *Porter) PrintOutputContractResult(ctx context.Context, opts OutputVerifyOptions) error {
+	result, err := p.VerifyOutputContract(ctx, &opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		err = printer.PrintJson(p.Out, result)
+	case printer.FormatYaml:
+		err = printer.PrintYaml(p.Out, result)
+	case printer.FormatPlaintext:
+		if result.Satisfied() {
+			fmt.Fprintf(p.Out, "The outputs of installation %s/%s from run %s satisfy the contract %s\n", result.Namespace, result.Installation, result.RunID, result.Contract)
+			return nil
+		}
+		p.printOutputContractDiff(result)
+	default:
+		return fmt.Errorf("invalid format: %s", opts.Format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !result.Satisfied() {
+		return fmt.Errorf("the outputs of installation %s/%s do not satisfy the contract %s: %d violations found", result.Namespace, result.Installation, result.Contract, len(result.Violations))
+	}
+	return nil
+}
+
+// printOutputContractDiff prints the violations as a diff from the contract
+// to the outputs of the installation.
+func (p *Porter) printOutputContractDiff(result OutputContractResult) {
+	fmt.Fprintf(p.Out, "--- contract %s\n", result.Contract)
+	fmt.Fprintf(p.Out, "+++ installation %s/%s (run %s)\n", result.Namespace, result.Installation, result.RunID)
+	for _, v := range result.Violations {
+		fmt.Fprintf(p.Out, "@@ %s @@\n", v.Output)
+		switch v.Kind {
+		case ContractViolationMissing:
+			fmt.Fprintf(p.Out, "-%s\n+%s\n", v.Expected, v.Actual)
+		case ContractViolationType:
+			fmt.Fprintf(p.Out, "-type: %s\n+type: %s\n", v.Expected, v.Actual)
+		case ContractViolationSchema:
+			fmt.Fprintf(p.Out, "-schema: %s\n+value: %s\n", v.Expected, truncateString(v.Actual, 60))
+			fmt.Fprintf(p.Out, "# %s\n", v.Message)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupOutputContractTest creates an installation with outputs to verify against a contract.
+func setupOutputContractTest(t *testing.T, contract string) *TestPorter {
+	p := NewTestPorter(t)
+
+	writeOnly := true
+	b := bundle.Bundle{
+		Definitions: definition.Definitions{
+			"port":     &definition.Schema{Type: "integer"},
+			"region":   &definition.Schema{Type: "string"},
+			"password": &definition.Schema{Type: "string", WriteOnly: &writeOnly},
+		},
+		Outputs: map[string]bundle.Output{
+			"port":     {Definition: "port"},
+			"region":   {Definition: "region"},
+			"password": {Definition: "password"},
+		},
+	}
+	extB := cnab.NewBundle(b)
+	i := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+	c := p.TestInstallations.CreateRun(i.NewRun(cnab.ActionInstall), func(r *storage.Run) {
+		r.Bundle = b
+	})
+	r := p.TestInstallations.CreateResult(c.NewResult(cnab.StatusSucceeded))
+	p.CreateOutput(r.NewOutput("port", []byte("3306")), extB)
+	p.CreateOutput(r.NewOutput("region", []byte("eastus")), extB)
+	p.CreateOutput(r.NewOutput("password", []byte("topsecret")), extB)
+
+	require.NoError(t, p.FileSystem.WriteFile("contract.json", []byte(contract), 0600))
+	return p
+}
+
+func TestPorter_VerifyOutputContract(t *testing.T) {
+	t.Parallel()
+
+	t.Run("satisfied", func(t *testing.T) {
+		p := setupOutputContractTest(t, `{"outputs": {
+			"port": {"type": "integer", "schema": {"type": "integer", "minimum": 1024}},
+			"password": {"type": "string"},
+			"zone": {"type": "string", "optional": true}
+		}}`)
+		defer p.Close()
+
+		opts := OutputVerifyOptions{Contract: "contract.json"}
+		opts.Namespace = "dev"
+		opts.Name = "mysql"
+		result, err := p.VerifyOutputContract(context.Background(), &opts)
+		require.NoError(t, err)
+		assert.True(t, result.Satisfied(), "expected no violations, got %v", result.Violations)
+	})
+
+	t.Run("violated", func(t *testing.T) {
+		p := setupOutputContractTest(t, `{"outputs": {
+			"port": {"type": "string"},
+			"region": {"schema": {"type": "string", "enum": ["westus"]}},
+			"password": {"schema": {"type": "string", "maxLength": 3}},
+			"zone": {"type": "string"}
+		}}`)
+		defer p.Close()
+
+		opts := OutputVerifyOptions{Contract: "contract.json"}
+		opts.Namespace = "dev"
+		opts.Name = "mysql"
+		result, err := p.VerifyOutputContract(context.Background(), &opts)
+		require.NoError(t, err)
+		require.Len(t, result.Violations, 4)
+
+		assert.Equal(t, "password", result.Violations[0].Output)
+		assert.Equal(t, ContractViolationSchema, result.Violations[0].Kind)
+		assert.Equal(t, maskedValue, result.Violations[0].Actual, "sensitive values should be redacted")
+
+		assert.Equal(t, OutputContractViolation{Output: "port", Kind: ContractViolationType, Expected: "string", Actual: "integer"}, result.Violations[1])
+
+		assert.Equal(t, "region", result.Violations[2].Output)
+		assert.Equal(t, ContractViolationSchema, result.Violations[2].Kind)
+		assert.Equal(t, "eastus", result.Violations[2].Actual)
+		assert.NotEmpty(t, result.Violations[2].Message)
+
+		assert.Equal(t, OutputContractViolation{Output: "zone", Kind: ContractViolationMissing, Expected: "present", Actual: "missing"}, result.Violations[3])
+	})
+
+	t.Run("sensitivity policy", func(t *testing.T) {
+		p := setupOutputContractTest(t, `{"outputs": {
+			"region": {"schema": {"type": "string", "enum": ["westus"]}}
+		}}`)
+		defer p.Close()
+		p.Config.Data.SensitivityPolicies = []config.SensitivityPolicy{{Outputs: []string{"region"}}}
+
+		opts := OutputVerifyOptions{Contract: "contract.json"}
+		opts.Namespace = "dev"
+		opts.Name = "mysql"
+		result, err := p.VerifyOutputContract(context.Background(), &opts)
+		require.NoError(t, err)
+		require.Len(t, result.Violations, 1)
+		assert.Equal(t, maskedValue, result.Violations[0].Actual, "outputs marked sensitive by a policy should be redacted")
+		assert.NotContains(t, result.Violations[0].Message, "eastus")
+	})
+
+	t.Run("invalid contract", func(t *testing.T) {
+		p := setupOutputContractTest(t, `{"outputs": `)
+		defer p.Close()
+
+		opts := OutputVerifyOptions{Contract: "contract.json"}
+		opts.Namespace = "dev"
+		opts.Name = "mysql"
+		_, err := p.VerifyOutputContract(context.Background(), &opts)
+		require.ErrorContains(t, err, "could not parse contract file contract.json")
+	})
+}
+
+func TestPorter_PrintOutputContractResult(t *testing.T) {
+	t.Parallel()
+
+	p := setupOutputContractTest(t, `{"outputs": {"port": {"type": "string"}, "zone": {}}}`)
+	defer p.Close()
+
+	opts := OutputVerifyOptions{Contract: "contract.json"}
+	opts.Namespace = "dev"
+	opts.Name = "mysql"
+	opts.Format = printer.FormatPlaintext
+	err := p.PrintOutputContractResult(context.Background(), opts)
+	require.EqualError(t, err, "the outputs of installation dev/mysql do not satisfy the contract contract.json: 2 violations found")
+
+	gotOutput := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, gotOutput, "--- contract contract.json\n")
+	assert.Contains(t, gotOutput, "@@ port @@\n-type: string\n+type: integer\n")
+	assert.Contains(t, gotOutput, "@@ zone @@\n-present\n+missing\n")
+}
@@ -0,0 +1,175 @@
+package porter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"get.porter.sh/porter/pkg/failure"
+	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	dtprinter "github.com/carolynvs/datetime-printer"
+)
+
+// OutputHistoryOptions are the options for the installation outputs history command.
+type OutputHistoryOptions struct {
+	installationOptions
+	printer.PrintOptions
+
+	// Output is the name of the output.
+	Output string
+
+	// ShowSensitive reveals the values of sensitive outputs, when allowed by
+	// the sensitive output policy.
+	ShowSensitive bool
+}
+
+// Validate the options provided to the installation outputs history command.
+func (o *OutputHistoryOptions) Validate(args []string, cxt *portercontext.Context) error {
+	switch len(args) {
+	case 0:
+		return errors.New("an output name must be provided")
+	case 1:
+		o.Output = args[0]
+	case 2:
+		if o.installationOptions.Name != "" {
+			return errors.New("the installation must be specified either as an argument or with --installation, not both")
+		}
+		o.installationOptions.Name = args[0]
+		o.Output = args[1]
+	default:
+		return fmt.Errorf("at most two positional arguments may be specified, the installation and the output name, but more were received: %s", args)
+	}
+
+	// If not provided, attempt to derive installation name from context
+	if o.installationOptions.Name == "" {
+		err := o.installationOptions.defaultBundleFiles(cxt)
+		if err != nil {
+			return errors.New("installation name must be provided via [--installation|-i INSTALLATION]")
+		}
+	}
+
+	return o.ParseFormat()
+}
+
+// DisplayOutputHistoryEntry is a value of an output, generated by a run of the installation.
+type DisplayOutputHistoryEntry struct {
+	RunID     string      `json:"runId" yaml:"runId"`
+	ResultID  string      `json:"resultId" yaml:"resultId"`
+	Created   time.Time   `json:"created" yaml:"created"`
+	Value     interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+	Sensitive bool        `json:"sensitive" yaml:"sensitive"`
+
+	// Changed indicates that the value is different from the previous value
+	// of the output. It is not set when the value, or the previous value, is
+	// sensitive and was not revealed.
+	Changed *bool `json:"changed,omitempty" yaml:"changed,omitempty"`
+}
+
+// GetOutputHistory returns every value of an output of the installation,
+// sorted from oldest to newest. The values of sensitive outputs are only
+// resolved from the secret store when ShowSensitive is set.
+func (p *Porter) GetOutputHistory(ctx context.Context, opts *OutputHistoryOptions) ([]DisplayOutputHistoryEntry, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	err := p.applyDefaultOptions(ctx, &opts.installationOptions)
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
+	if opts.ShowSensitive && !p.Config.Data.SensitiveOutputPolicy.AllowsShowSensitive(opts.Namespace) {
+		return nil, span.Error(failure.PolicyDenied(fmt.Errorf("revealing sensitive outputs in namespace %q is not allowed by the allow-show-sensitive setting of the sensitive-output-policy configuration", opts.Namespace)))
+	}
+
+	history, err := p.Installations.GetOutputHistory(ctx, opts.Namespace, opts.Name, opts.Output)
+	if err != nil {
+		return nil, span.Error(fmt.Errorf("could not retrieve the history of output %s of installation %s/%s: %w", opts.Output, opts.Namespace, opts.Name, err))
+	}
+
+	entries := make([]DisplayOutputHistoryEntry, 0, len(history))
+	var previous []byte
+	previousKnown := false
+	for i, h := range history {
+		entry := DisplayOutputHistoryEntry{
+			RunID:     h.RunID,
+			ResultID:  h.ResultID,
+			Created:   h.Created,
+			Sensitive: h.Key != "",
+		}
+
+		if entry.Sensitive && !opts.ShowSensitive {
+			previousKnown = false
+		} else {
+			output, err := storage.ReadOutputValue(ctx, p.Installations, h.Output)
+			if err != nil {
+				return nil, span.Error(fmt.Errorf("could not read the value of output %s: %w", h.Name, err))
+			}
+
+			output, err = p.Sanitizer.RestoreOutput(ctx, output)
+			if err != nil {
+				return nil, span.Error(fmt.Errorf("could not resolve the value of output %s: %w", h.Name, err))
+			}
+
+			entry.Value = string(output.Value)
+			if i == 0 || previousKnown {
+				changed := i == 0 || !bytes.Equal(previous, output.Value)
+				entry.Changed = &changed
+			}
+			previous = output.Value
+			previousKnown = true
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// PrintOutputHistory prints every value of an output of the installation.
+func (p *Porter) PrintOutputHistory(ctx context.Context, opts *OutputHistoryOptions) error {
+	entries, err := p.GetOutputHistory(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, entries)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, entries)
+	case printer.FormatPlaintext:
+		now := time.Now()
+		tp := dtprinter.DateTimePrinter{
+			Now: func() time.Time { return now },
+		}
+
+		row := func(v interface{}) []string {
+			entry, ok := v.(DisplayOutputHistoryEntry)
+			if !ok {
+				return nil
+			}
+
+			value := maskedValue
+			if entry.Value != nil {
+				value = truncateString(entry.Value.(string), 60)
+			}
+
+			changed := ""
+			if entry.Changed != nil {
+				changed = "no"
+				if *entry.Changed {
+					changed = "yes"
+				}
+			}
+			return []string{entry.RunID, tp.Format(entry.Created), value, changed}
+		}
+		return printer.PrintTable(p.Out, entries, row, "Run ID", "Created", "Value", "Changed")
+	default:
+		return fmt.Errorf("invalid format: %s", opts.Format)
+	}
+}
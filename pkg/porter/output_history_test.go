@@ -0,0 +1,131 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/failure"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputHistoryOptions_Validate(t *testing.T) {
+	t.Run("installation and output", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := OutputHistoryOptions{}
+		require.NoError(t, opts.Validate([]string{"mysql", "connstr"}, p.Context))
+		assert.Equal(t, "mysql", opts.Name)
+		assert.Equal(t, "connstr", opts.Output)
+		assert.Equal(t, printer.FormatPlaintext, opts.Format)
+	})
+
+	t.Run("installation specified twice", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := OutputHistoryOptions{}
+		opts.Name = "mysql"
+		err := opts.Validate([]string{"mysql", "connstr"}, p.Context)
+		require.EqualError(t, err, "the installation must be specified either as an argument or with --installation, not both")
+	})
+
+	t.Run("no output", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := OutputHistoryOptions{}
+		require.EqualError(t, opts.Validate(nil, p.Context), "an output name must be provided")
+	})
+}
+
+func TestPorter_GetOutputHistory(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) *TestPorter {
+		p := NewTestPorter(t)
+
+		p.TestConfig.TestContext.AddTestFile("testdata/bundle.json", "/bundle.json")
+		bun, err := cnab.LoadBundle(p.Context, "/bundle.json")
+		require.NoError(t, err)
+
+		i := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybuns"))
+		for _, value := range []string{"one", "one", "two"} {
+			run := i.NewRun(cnab.ActionUpgrade)
+			run.Bundle = bun.Bundle
+			run = p.TestInstallations.CreateRun(run)
+			result := p.TestInstallations.CreateResult(run.NewResult(cnab.StatusSucceeded))
+			p.CreateOutput(result.NewOutput("my-first-output", []byte("secret-"+value)), bun)
+			p.CreateOutput(result.NewOutput("my-second-output", []byte(value)), bun)
+		}
+		return p
+	}
+
+	changed := func(v bool) *bool { return &v }
+
+	t.Run("not sensitive", func(t *testing.T) {
+		p := setup(t)
+		defer p.Close()
+
+		opts := &OutputHistoryOptions{Output: "my-second-output"}
+		opts.Namespace = "dev"
+		opts.Name = "mybuns"
+		entries, err := p.GetOutputHistory(ctx, opts)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+
+		assert.Equal(t, "one", entries[0].Value)
+		assert.Equal(t, changed(true), entries[0].Changed)
+		assert.Equal(t, changed(false), entries[1].Changed)
+		assert.Equal(t, "two", entries[2].Value)
+		assert.Equal(t, changed(true), entries[2].Changed)
+		assert.False(t, entries[2].Created.IsZero())
+	})
+
+	t.Run("sensitive values are masked", func(t *testing.T) {
+		p := setup(t)
+		defer p.Close()
+
+		opts := &OutputHistoryOptions{Output: "my-first-output"}
+		opts.Namespace = "dev"
+		opts.Name = "mybuns"
+		opts.Format = printer.FormatPlaintext
+		require.NoError(t, p.PrintOutputHistory(ctx, opts))
+
+		output := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, output, maskedValue)
+		assert.NotContains(t, output, "secret-one")
+	})
+
+	t.Run("show sensitive", func(t *testing.T) {
+		p := setup(t)
+		defer p.Close()
+		p.Config.Data.SensitiveOutputPolicy.AllowShowSensitive = []string{"dev"}
+
+		opts := &OutputHistoryOptions{Output: "my-first-output", ShowSensitive: true}
+		opts.Namespace = "dev"
+		opts.Name = "mybuns"
+		entries, err := p.GetOutputHistory(ctx, opts)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		assert.True(t, entries[0].Sensitive)
+		assert.Equal(t, "secret-one", entries[0].Value)
+		assert.Equal(t, changed(false), entries[1].Changed)
+		assert.Equal(t, "secret-two", entries[2].Value)
+	})
+
+	t.Run("show sensitive denied", func(t *testing.T) {
+		p := setup(t)
+		defer p.Close()
+
+		opts := &OutputHistoryOptions{Output: "my-first-output", ShowSensitive: true}
+		opts.Namespace = "dev"
+		opts.Name = "mybuns"
+		_, err := p.GetOutputHistory(ctx, opts)
+		require.True(t, failure.Is(err, failure.ClassPolicyDenied), "expected the policy to deny revealing the sensitive values")
+	})
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"regexp"
 	"sort"
+	"strings"
 
 	"get.porter.sh/porter/pkg/cnab"
 	"get.porter.sh/porter/pkg/portercontext"
@@ -16,6 +19,11 @@ import (
 type OutputShowOptions struct {
 	installationOptions
 	Output string
+
+	// InstallationNamespace is the namespace of the installation that generated
+	// the output, when it is in a different namespace than Namespace. The
+	// output must be shared with Namespace by a namespace policy.
+	InstallationNamespace string
 }
 
 // OutputListOptions represent options for a bundle output list command
@@ -24,6 +32,69 @@ type OutputListOptions struct {
 	printer.PrintOptions
 }
 
+// OutputFindOptions represent options for the installation outputs find command.
+type OutputFindOptions struct {
+	printer.PrintOptions
+
+	// Name of the output.
+	Name string
+
+	// Namespace of the installations. Defaults to the global namespace.
+	Namespace string
+
+	// AllNamespaces includes installations in every namespace.
+	AllNamespaces bool
+
+	// Value limits the results to installations whose most recent value of the
+	// output matches.
+	Value string
+
+	// ValueHash limits the results to installations whose most recent value of
+	// the output has the digest, formatted as sha256:HEX.
+	ValueHash string
+}
+
+// Validate the options provided to the installation outputs find command.
+func (o *OutputFindOptions) Validate(args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("an output name must be provided")
+	case 1:
+		o.Name = args[0]
+	default:
+		return fmt.Errorf("only one positional argument may be specified, the output name, but multiple were received: %s", args)
+	}
+
+	if o.Value != "" && o.ValueHash != "" {
+		return errors.New("either --value or --value-hash may be specified, not both")
+	}
+	if o.ValueHash != "" && !valueHashPattern.MatchString(o.ValueHash) {
+		return fmt.Errorf("invalid --value-hash %q, the hash must be formatted as sha256:HEX", o.ValueHash)
+	}
+
+	return o.ParseFormat()
+}
+
+// valueHashPattern matches the digest of an output value, formatted as sha256:HEX.
+var valueHashPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// GetNamespace returns the namespace of the installations to search.
+func (o OutputFindOptions) GetNamespace() string {
+	if o.AllNamespaces {
+		return "*"
+	}
+	return o.Namespace
+}
+
+// GetValueHash returns the digest of the value to find, or an empty string
+// when the value is not used to filter the installations.
+func (o OutputFindOptions) GetValueHash() string {
+	if o.Value != "" {
+		return storage.HashOutputValue([]byte(o.Value))
+	}
+	return o.ValueHash
+}
+
 // Validate validates the provided args, using the provided context,
 // setting attributes of OutputShowOptions as applicable
 func (o *OutputShowOptions) Validate(args []string, cxt *portercontext.Context) error {
@@ -32,8 +103,24 @@ func (o *OutputShowOptions) Validate(args []string, cxt *portercontext.Context)
 		return errors.New("an output name must be provided")
 	case 1:
 		o.Output = args[0]
+	case 2:
+		if o.installationOptions.Name != "" {
+			return errors.New("the installation must be specified either as an argument or with --installation, not both")
+		}
+		o.installationOptions.Name = args[0]
+		o.Output = args[1]
 	default:
-		return fmt.Errorf("only one positional argument may be specified, the output name, but multiple were received: %s", args)
+		return fmt.Errorf("at most two positional arguments may be specified, the installation and the output name, but more were received: %s", args)
+	}
+
+	// The installation may be in another namespace, specified as NAMESPACE/NAME
+	if ref := o.installationOptions.Name; strings.Contains(ref, "/") {
+		i := strings.Index(ref, "/")
+		o.InstallationNamespace = ref[:i]
+		o.installationOptions.Name = ref[i+1:]
+		if o.InstallationNamespace == "" || o.installationOptions.Name == "" || strings.Contains(o.installationOptions.Name, "/") {
+			return fmt.Errorf("invalid installation %q, the installation must be in the format [NAMESPACE/]NAME", ref)
+		}
 	}
 
 	// If not provided, attempt to derive installation name from context
@@ -72,12 +159,17 @@ func (p *Porter) ShowBundleOutput(ctx context.Context, opts *OutputShowOptions)
 		return err
 	}
 
-	output, err := p.ReadBundleOutput(ctx, opts.Output, opts.Name, opts.Namespace)
+	namespace := opts.Namespace
+	if opts.InstallationNamespace != "" {
+		namespace = opts.InstallationNamespace
+	}
+
+	err = p.writeBundleOutput(ctx, p.Out, opts.Namespace, namespace, opts.Name, opts.Output)
 	if err != nil {
-		return fmt.Errorf("unable to read output '%s' for installation '%s/%s': %w", opts.Output, opts.Namespace, opts.Name, err)
+		return fmt.Errorf("unable to read output '%s' for installation '%s/%s': %w", opts.Output, namespace, opts.Name, err)
 	}
 
-	fmt.Fprintln(p.Out, output)
+	fmt.Fprintln(p.Out)
 	return nil
 }
 
@@ -125,6 +217,11 @@ func (p *Porter) ListBundleOutputs(ctx context.Context, opts *OutputListOptions)
 		return nil, err
 	}
 
+	outputs, err = p.readChunkedOutputs(ctx, outputs)
+	if err != nil {
+		return nil, err
+	}
+
 	resolved, err := p.Sanitizer.RestoreOutputs(ctx, outputs)
 	if err != nil {
 		return nil, err
@@ -145,6 +242,20 @@ func (p *Porter) ListBundleOutputs(ctx context.Context, opts *OutputListOptions)
 	return displayOutputs, nil
 }
 
+// readChunkedOutputs retrieves the values of outputs that were saved as a
+// stream, or to a blob store.
+func (p *Porter) readChunkedOutputs(ctx context.Context, outputs storage.Outputs) (storage.Outputs, error) {
+	values := make([]storage.Output, 0, outputs.Len())
+	for _, o := range outputs.Value() {
+		o, err := storage.ReadOutputValue(ctx, p.Installations, o)
+		if err != nil {
+			return outputs, fmt.Errorf("could not read the value of output %s: %w", o.Name, err)
+		}
+		values = append(values, o)
+	}
+	return storage.NewOutputs(values), nil
+}
+
 func (p *Porter) PrintBundleOutputs(ctx context.Context, opts OutputListOptions) error {
 	outputs, err := p.ListBundleOutputs(ctx, &opts)
 	if err != nil {
@@ -163,19 +274,113 @@ func (p *Porter) PrintBundleOutputs(ctx context.Context, opts OutputListOptions)
 	}
 }
 
+// DisplayFoundOutput is an installation that exposes an output found by the
+// installation outputs find command.
+type DisplayFoundOutput struct {
+	Namespace    string `json:"namespace" yaml:"namespace"`
+	Installation string `json:"installation" yaml:"installation"`
+	Name         string `json:"name" yaml:"name"`
+	RunID        string `json:"runId" yaml:"runId"`
+	Sensitive    bool   `json:"sensitive" yaml:"sensitive"`
+
+	// ValueHash is the digest of the output value. It is empty for sensitive
+	// outputs, and for outputs saved by an earlier version of Porter.
+	ValueHash string `json:"valueHash,omitempty" yaml:"valueHash,omitempty"`
+}
+
+// FindOutputs lists the installations that expose an output, using the most
+// recent value of the output for each installation.
+func (p *Porter) FindOutputs(ctx context.Context, opts OutputFindOptions) ([]DisplayFoundOutput, error) {
+	outputs, err := p.Installations.FindOutputs(ctx, storage.FindOutputsOptions{
+		Name:      opts.Name,
+		Namespace: opts.GetNamespace(),
+		ValueHash: opts.GetValueHash(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]DisplayFoundOutput, len(outputs))
+	for i, output := range outputs {
+		found[i] = DisplayFoundOutput{
+			Namespace:    output.Namespace,
+			Installation: output.Installation,
+			Name:         output.Name,
+			RunID:        output.RunID,
+			Sensitive:    output.Key != "",
+			ValueHash:    output.ValueHash,
+		}
+	}
+	return found, nil
+}
+
+func (p *Porter) PrintFoundOutputs(ctx context.Context, opts OutputFindOptions) error {
+	found, err := p.FindOutputs(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, found)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, found)
+	case printer.FormatPlaintext:
+		row := func(v interface{}) []string {
+			o, ok := v.(DisplayFoundOutput)
+			if !ok {
+				return nil
+			}
+			valueHash := o.ValueHash
+			if o.Sensitive {
+				valueHash = "(sensitive)"
+			}
+			return []string{o.Namespace, o.Installation, o.RunID, valueHash}
+		}
+		return printer.PrintTable(p.Out, found, row, "NAMESPACE", "INSTALLATION", "RUN", "VALUE HASH")
+	default:
+		return fmt.Errorf("invalid format: %s", opts.Format)
+	}
+}
+
 // ReadBundleOutput reads a bundle output from an installation
 func (p *Porter) ReadBundleOutput(ctx context.Context, outputName, installation, namespace string) (string, error) {
-	o, err := p.Installations.GetLastOutput(ctx, namespace, installation, outputName)
-	if err != nil {
+	var value strings.Builder
+	if err := p.WriteBundleOutput(ctx, &value, outputName, installation, namespace); err != nil {
 		return "", err
 	}
+	return value.String(), nil
+}
+
+// WriteBundleOutput writes the value of a bundle output from an installation
+// to the writer, streaming the value from the backing store so that large
+// outputs are not held in memory.
+func (p *Porter) WriteBundleOutput(ctx context.Context, w io.Writer, outputName, installation, namespace string) error {
+	return p.writeBundleOutput(ctx, w, namespace, namespace, installation, outputName)
+}
 
-	o, err = p.Sanitizer.RestoreOutput(ctx, o)
+// writeBundleOutput writes the value of a bundle output from an installation
+// on behalf of the requesting namespace. When the installation is in another
+// namespace, the output must be shared with the requesting namespace.
+func (p *Porter) writeBundleOutput(ctx context.Context, w io.Writer, requestingNamespace, namespace, installation, outputName string) error {
+	o, err := p.Installations.GetSharedOutput(ctx, requestingNamespace, namespace, installation, outputName)
 	if err != nil {
-		return "", err
+		return err
+	}
+
+	value, err := p.Installations.OpenOutputStream(ctx, o)
+	if err != nil {
+		return err
+	}
+
+	value, err = p.Sanitizer.RestoreOutputStream(ctx, o, value)
+	if err != nil {
+		return err
 	}
+	defer value.Close()
 
-	return fmt.Sprintf("%v", string(o.Value)), nil
+	_, err = io.Copy(w, value)
+	return err
 }
 
 func truncateString(str string, num int) string {
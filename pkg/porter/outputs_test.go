@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"os"
+	"strings"
 	"testing"
 
 	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/printer"
 	"get.porter.sh/porter/pkg/storage"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -132,3 +135,159 @@ func TestPorter_PrintBundleOutputs(t *testing.T) {
 		})
 	}
 }
+
+func TestPorter_ShowBundleOutput_Chunked(t *testing.T) {
+	t.Parallel()
+
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	i := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "test"))
+	c := p.TestInstallations.CreateRun(i.NewRun(cnab.ActionInstall))
+	r := p.TestInstallations.CreateResult(c.NewResult(cnab.StatusSucceeded))
+
+	value := strings.Repeat("a", storage.OutputChunkSize+10)
+	err := p.TestInstallations.InsertOutputStream(ctx, r.NewOutput("archive", nil), strings.NewReader(value))
+	require.NoError(t, err)
+
+	opts := OutputShowOptions{Output: "archive"}
+	opts.Namespace = "dev"
+	opts.Name = "test"
+	err = p.ShowBundleOutput(ctx, &opts)
+	require.NoError(t, err)
+	require.Equal(t, value+"\n", p.TestConfig.TestContext.GetOutput())
+}
+
+func TestOutputShowOptions_Validate(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name          string
+		args          []string
+		installation  string
+		wantNamespace string
+		wantName      string
+		wantOutput    string
+		wantErr       string
+	}{
+		{name: "output", args: []string{"connstr"}, installation: "mysql", wantName: "mysql", wantOutput: "connstr"},
+		{name: "installation argument", args: []string{"mysql", "connstr"}, wantName: "mysql", wantOutput: "connstr"},
+		{name: "installation in another namespace", args: []string{"platform/mysql", "connstr"}, wantNamespace: "platform", wantName: "mysql", wantOutput: "connstr"},
+		{name: "installation flag in another namespace", args: []string{"connstr"}, installation: "platform/mysql", wantNamespace: "platform", wantName: "mysql", wantOutput: "connstr"},
+		{name: "installation argument and flag", args: []string{"mysql", "connstr"}, installation: "mysql", wantErr: "not both"},
+		{name: "invalid installation", args: []string{"platform/", "connstr"}, wantErr: `invalid installation "platform/"`},
+		{name: "too many arguments", args: []string{"platform", "mysql", "connstr"}, wantErr: "at most two positional arguments"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewTestPorter(t)
+			defer p.Close()
+
+			opts := OutputShowOptions{}
+			opts.Name = tc.installation
+			err := opts.Validate(tc.args, p.Context)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantNamespace, opts.InstallationNamespace)
+			assert.Equal(t, tc.wantName, opts.Name)
+			assert.Equal(t, tc.wantOutput, opts.Output)
+		})
+	}
+}
+
+func TestPorter_ShowBundleOutput_SharedNamespace(t *testing.T) {
+	t.Parallel()
+
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	i := p.TestInstallations.CreateInstallation(storage.NewInstallation("platform", "mysql"))
+	c := p.TestInstallations.CreateRun(i.NewRun(cnab.ActionInstall))
+	r := p.TestInstallations.CreateResult(c.NewResult(cnab.StatusSucceeded))
+	p.TestInstallations.CreateOutput(r.NewOutput("connstr", []byte("mysql://platform")))
+
+	opts := OutputShowOptions{Output: "connstr", InstallationNamespace: "platform"}
+	opts.Namespace = "dev"
+	opts.Name = "mysql"
+
+	err := p.ShowBundleOutput(ctx, &opts)
+	require.ErrorIs(t, err, storage.ErrAccessDenied{}, "outputs should not be shared with other namespaces by default")
+
+	p.Config.Data.NamespacePolicies = []config.NamespacePolicy{
+		{Namespace: "platform", ShareOutputsWith: []string{"*"}, Installations: []string{"mysql"}},
+	}
+	err = p.ShowBundleOutput(ctx, &opts)
+	require.NoError(t, err)
+	assert.Equal(t, "mysql://platform\n", p.TestConfig.TestContext.GetOutput())
+}
+
+func TestOutputFindOptions_Validate(t *testing.T) {
+	testcases := []struct {
+		name    string
+		args    []string
+		opts    OutputFindOptions
+		wantErr string
+	}{
+		{name: "name", args: []string{"cluster"}},
+		{name: "value hash", args: []string{"cluster"}, opts: OutputFindOptions{ValueHash: "sha256:5fb8fce78382d28d746e0af6610c85c3bbf7ef8934a36c5bc2124fee3cab8f58"}},
+		{name: "no name", wantErr: "an output name must be provided"},
+		{name: "value and hash", args: []string{"cluster"}, opts: OutputFindOptions{Value: "prod-east", ValueHash: "sha256:abc"}, wantErr: "either --value or --value-hash may be specified, not both"},
+		{name: "invalid hash", args: []string{"cluster"}, opts: OutputFindOptions{ValueHash: "5fb8fce7"}, wantErr: `invalid --value-hash "5fb8fce7", the hash must be formatted as sha256:HEX`},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			opts := tc.opts
+			opts.RawFormat = "plaintext"
+			err := opts.Validate(tc.args)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, "cluster", opts.Name)
+			} else {
+				require.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestPorter_FindOutputs(t *testing.T) {
+	t.Parallel()
+
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	createOutput := func(namespace string, installation string, value string) {
+		i := p.TestInstallations.CreateInstallation(storage.NewInstallation(namespace, installation))
+		c := p.TestInstallations.CreateRun(i.NewRun(cnab.ActionInstall), func(r *storage.Run) { r.ID = namespace + "-" + installation })
+		r := p.TestInstallations.CreateResult(c.NewResult(cnab.StatusSucceeded))
+		p.TestInstallations.CreateOutput(r.NewOutput("cluster", []byte(value)))
+	}
+	createOutput("dev", "mysql", "prod-east")
+	createOutput("dev", "redis", "prod-west")
+	createOutput("prod", "mysql", "prod-east")
+
+	opts := OutputFindOptions{Name: "cluster", AllNamespaces: true, Value: "prod-east"}
+	opts.Format = printer.FormatPlaintext
+	require.NoError(t, p.PrintFoundOutputs(ctx, opts))
+
+	hash := storage.HashOutputValue([]byte("prod-east"))
+	wantLines := []string{
+		"dev", "mysql", "dev-mysql", hash,
+		"prod", "mysql", "prod-mysql", hash,
+	}
+	gotOutput := p.TestConfig.TestContext.GetOutput()
+	for _, want := range wantLines {
+		assert.Contains(t, gotOutput, want)
+	}
+	assert.NotContains(t, gotOutput, "redis")
+}
@@ -15,6 +15,7 @@ import (
 	depsv1 "get.porter.sh/porter/pkg/cnab/dependencies/v1"
 	"get.porter.sh/porter/pkg/editor"
 	"get.porter.sh/porter/pkg/encoding"
+	"get.porter.sh/porter/pkg/failure"
 	"get.porter.sh/porter/pkg/generator"
 	"get.porter.sh/porter/pkg/printer"
 	"get.porter.sh/porter/pkg/secrets"
@@ -42,12 +43,17 @@ type ParameterEditOptions struct {
 
 // ListParameters lists saved parameter sets.
 func (p *Porter) ListParameters(ctx context.Context, opts ListOptions) ([]storage.ParameterSet, error) {
+	selector, err := opts.ParseLabelSelector()
+	if err != nil {
+		return nil, err
+	}
+
 	return p.Parameters.ListParameterSets(ctx, storage.ListOptions{
-		Namespace: opts.GetNamespace(),
-		Name:      opts.Name,
-		Labels:    opts.ParseLabels(),
-		Skip:      opts.Skip,
-		Limit:     opts.Limit,
+		Namespace:     opts.GetNamespace(),
+		Name:          opts.Name,
+		LabelSelector: selector,
+		Skip:          opts.Skip,
+		Limit:         opts.Limit,
 	})
 }
 
@@ -136,18 +142,12 @@ func (p *Porter) GenerateParameters(ctx context.Context, opts ParameterOptions)
 			Namespace: opts.Namespace,
 			Labels:    opts.ParseLabels(),
 			Silent:    opts.Silent,
+			Profile:   p.GetOutputProfile(),
 		},
 		Bundle: bundleRef.Definition,
 	}
 	fmt.Fprintf(p.Out, "Generating new parameter set %s from bundle %s\n", genOpts.Name, bundleRef.Definition.Name)
-	numExternalParams := 0
-
-	for name := range bundleRef.Definition.Parameters {
-		if !bundleRef.Definition.IsInternalParameter(name) {
-			numExternalParams += 1
-		}
-	}
-
+	numExternalParams := len(bundleRef.Definition.ListUserParameters())
 	fmt.Fprintf(p.Out, "==> %d parameter(s) declared for bundle %s\n", numExternalParams, bundleRef.Definition.Name)
 
 	pset, err := genOpts.GenerateParameters()
@@ -275,6 +275,11 @@ func (p *Porter) ShowParameter(ctx context.Context, opts ParameterShowOptions) e
 		fmt.Fprintf(p.Out, "Created: %s\n", tp.Format(paramSet.Status.Created))
 		fmt.Fprintf(p.Out, "Modified: %s\n\n", tp.Format(paramSet.Status.Modified))
 
+		// Print the inherited parameter sets, if any
+		if len(paramSet.Inherits) > 0 {
+			fmt.Fprintf(p.Out, "Inherits: %s\n\n", strings.Join(paramSet.Inherits, ", "))
+		}
+
 		// Print labels, if any
 		if len(paramSet.Labels) > 0 {
 			fmt.Fprintln(p.Out, "Labels:")
@@ -341,9 +346,13 @@ func validateParameterName(args []string) error {
 	}
 }
 
-// loadParameterSets loads parameter values per their parameter set strategies
-func (p *Porter) loadParameterSets(ctx context.Context, bun cnab.ExtendedBundle, namespace string, params []string) (secrets.Set, error) {
+// loadParameterSets loads parameter values per their parameter set strategies,
+// and returns the revisions of the parameter sets that were used.
+// When deps is not nil, the parameters that are resolved from the outputs of
+// other installations are recorded in it.
+func (p *Porter) loadParameterSets(ctx context.Context, bun cnab.ExtendedBundle, namespace string, params []string) (secrets.Set, []storage.ParameterSetRevision, error) {
 	resolvedParameters := secrets.Set{}
+	revisions := make([]storage.ParameterSetRevision, 0, len(params))
 
 	for _, name := range params {
 		// Try to get the params in the local namespace first, fallback to the global creds
@@ -362,7 +371,15 @@ func (p *Porter) loadParameterSets(ctx context.Context, bun cnab.ExtendedBundle,
 		var pset storage.ParameterSet
 		err := store.FindOne(ctx, storage.CollectionParameters, query, &pset)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+		revisions = append(revisions, pset.GetRevision())
+
+		// Include the parameters inherited from other parameter sets, so that
+		// inherited file parameters are handled below
+		pset, err = p.Parameters.FlattenParameterSet(ctx, pset)
+		if err != nil {
+			return nil, nil, err
 		}
 
 		// A parameter may correspond to a Porter-specific parameter type of 'file'
@@ -370,7 +387,7 @@ func (p *Porter) loadParameterSets(ctx context.Context, bun cnab.ExtendedBundle,
 		for paramName, paramDef := range bun.Parameters {
 			paramSchema, ok := bun.Definitions[paramDef.Definition]
 			if !ok {
-				return nil, fmt.Errorf("definition %s not defined in bundle", paramDef.Definition)
+				return nil, nil, fmt.Errorf("definition %s not defined in bundle", paramDef.Definition)
 			}
 
 			if bun.IsFileType(paramSchema) {
@@ -389,7 +406,7 @@ func (p *Porter) loadParameterSets(ctx context.Context, bun cnab.ExtendedBundle,
 
 		rc, err := p.Parameters.ResolveAll(ctx, pset)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		for k, v := range rc {
@@ -397,7 +414,7 @@ func (p *Porter) loadParameterSets(ctx context.Context, bun cnab.ExtendedBundle,
 		}
 	}
 
-	return resolvedParameters, nil
+	return resolvedParameters, revisions, nil
 }
 
 type DisplayValue struct {
@@ -454,20 +471,17 @@ func NewDisplayValuesFromParameters(bun cnab.ExtendedBundle, params map[string]i
 	// via their corresponding Definitions and add to rows
 	displayParams := make(DisplayValues, 0, len(params))
 	for name, value := range params {
-		def, ok := bun.Parameters[name]
-		if !ok || bun.IsInternalParameter(name) {
+		param, ok := bun.GetParameterInfo(name)
+		if !ok || param.Internal {
 			continue
 		}
 
 		dp := &DisplayValue{Name: name}
 		dp.SetValue(value)
 
-		schema, ok := bun.Definitions[def.Definition]
-		if ok {
-			dp.Type = bun.GetParameterType(schema)
-			if schema.WriteOnly != nil && *schema.WriteOnly {
-				dp.Sensitive = true
-			}
+		if param.Definition != nil {
+			dp.Type = param.Type
+			dp.Sensitive = param.Sensitive
 		} else {
 			dp.Type = "unknown"
 		}
@@ -503,46 +517,65 @@ func (p *Porter) ParametersApply(ctx context.Context, o ApplyOptions) error {
 	defer span.EndSpan()
 
 	span.Debugf("Reading input file %s...", o.File)
-	namespace, err := p.getNamespaceFromFile(o)
+
+	// Validate every parameter set before any are saved
+	var paramsets []storage.ParameterSet
+	docs, err := p.readApplyDocuments(ctx, o, "ParameterSet", func(doc applyDocument) error {
+		params, err := p.loadParameterSetDocument(ctx, o, doc)
+		if err != nil {
+			return err
+		}
+		paramsets = append(paramsets, params)
+		return nil
+	})
 	if err != nil {
 		return span.Error(err)
 	}
 
+	for i, params := range paramsets {
+		if err := p.Parameters.UpsertParameterSet(ctx, params); err != nil {
+			return span.Error(docs[i].wrapError(err))
+		}
+		span.Infof("Applied %s parameter set", params)
+	}
+
+	return nil
+}
+
+// loadParameterSetDocument reads and validates a parameter set from a file
+// passed to porter parameters apply.
+func (p *Porter) loadParameterSetDocument(ctx context.Context, o ApplyOptions, doc applyDocument) (storage.ParameterSet, error) {
 	var params storage.ParameterSet
-	err = encoding.UnmarshalFile(p.FileSystem, o.File, &params)
-	if err != nil {
-		return span.Error(fmt.Errorf("could not load %s as a parameter set: %w", o.File, err))
+	if err := doc.Unmarshal(&params); err != nil {
+		return params, fmt.Errorf("could not load %s as a parameter set: %w", o.File, err)
 	}
 
-	if err = params.Validate(); err != nil {
-		return span.Error(fmt.Errorf("invalid parameter set: %w", err))
+	if err := params.Validate(); err != nil {
+		return params, fmt.Errorf("invalid parameter set: %w", err)
 	}
 
-	params.Namespace = namespace
+	params.Namespace = doc.Namespace
 	params.Status.Modified = time.Now()
 
-	err = p.Parameters.Validate(ctx, params)
-	if err != nil {
-		return span.Error(fmt.Errorf("parameter set is invalid: %w", err))
+	if err := p.Parameters.Validate(ctx, params); err != nil {
+		return params, fmt.Errorf("parameter set is invalid: %w", err)
 	}
 
-	err = p.Parameters.UpsertParameterSet(ctx, params)
-	if err != nil {
-		return err
-	}
-
-	span.Infof("Applied %s parameter set", params)
-	return nil
+	return params, nil
 }
 
 // finalizeParameters accepts a set of resolved parameters and combines them
 // with parameter sources and default parameter values to create a full set
 // of parameters that are defined in proper Go types, and not strings.
-func (p *Porter) finalizeParameters(ctx context.Context, installation storage.Installation, bun cnab.ExtendedBundle, action string, params map[string]string) (map[string]interface{}, error) {
+// When a snapshot is specified, parameters sourced from the installation's
+// outputs are resolved from the snapshot.
+// The parameters that were resolved from the outputs of other installations
+// are returned so that they are recorded on the run.
+func (p *Porter) finalizeParameters(ctx context.Context, installation storage.Installation, bun cnab.ExtendedBundle, action string, params map[string]string, snapshot *storage.InstallationSnapshot) (map[string]interface{}, []storage.RunOutputDependency, error) {
 	mergedParams := make(secrets.Set, len(params))
-	paramSources, err := p.resolveParameterSources(ctx, bun, installation)
+	paramSources, outputDeps, err := p.resolveParameterSources(ctx, bun, installation, snapshot)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	for key, val := range paramSources {
@@ -553,21 +586,31 @@ func (p *Porter) finalizeParameters(ctx context.Context, installation storage.In
 	for key, rawValue := range params {
 		param, ok := bun.Parameters[key]
 		if !ok {
-			return nil, fmt.Errorf("parameter %s not defined in bundle", key)
+			return nil, nil, fmt.Errorf("parameter %s not defined in bundle", key)
 		}
 
 		def, ok := bun.Definitions[param.Definition]
 		if !ok {
-			return nil, fmt.Errorf("definition %s not defined in bundle", param.Definition)
+			return nil, nil, fmt.Errorf("definition %s not defined in bundle", param.Definition)
 		}
 
 		// Apply porter specific conversions, like retrieving file contents
 		value, err := p.getUnconvertedValueFromRaw(bun, def, key, rawValue)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		mergedParams[key] = value
+
+		// The parameter no longer depends on the output that it was resolved from
+		delete(outputDeps, key)
+	}
+
+	// In strict mode, report every invalid parameter together before any are used
+	if p.Data.StrictParameters {
+		if err := validateParameterValues(bun, action, mergedParams); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	// Now convert all parameters which are currently strings into the
@@ -576,18 +619,18 @@ func (p *Porter) finalizeParameters(ctx context.Context, installation storage.In
 	for key, unconverted := range mergedParams {
 		param, ok := bun.Parameters[key]
 		if !ok {
-			return nil, fmt.Errorf("parameter %s not defined in bundle", key)
+			return nil, nil, fmt.Errorf("parameter %s not defined in bundle", key)
 		}
 
 		def, ok := bun.Definitions[param.Definition]
 		if !ok {
-			return nil, fmt.Errorf("definition %s not defined in bundle", param.Definition)
+			return nil, nil, fmt.Errorf("definition %s not defined in bundle", param.Definition)
 		}
 
 		if def.Type != nil {
 			value, err := def.ConvertValue(unconverted)
 			if err != nil {
-				return nil, fmt.Errorf("unable to convert parameter's %s value %s to the destination parameter type %s: %w", key, unconverted, def.Type, err)
+				return nil, nil, fmt.Errorf("unable to convert parameter's %s value %s to the destination parameter type %s: %w", key, unconverted, def.Type, err)
 			}
 			typedParams[key] = value
 		} else {
@@ -597,7 +640,80 @@ func (p *Porter) finalizeParameters(ctx context.Context, installation storage.In
 
 	}
 
-	return bundle.ValuesOrDefaults(typedParams, &bun.Bundle, action)
+	finalParams, err := bundle.ValuesOrDefaults(typedParams, &bun.Bundle, action)
+	if err != nil {
+		return nil, nil, err
+	}
+	return finalParams, storage.SortOutputDependencies(outputDeps), nil
+}
+
+// validateParameterValues converts and validates the value of every parameter
+// that applies to the action against its definition in the bundle, such as its
+// type, enum, minimum, maximum and length. Unlike bundle.ValuesOrDefaults, which
+// stops at the first problem, all invalid parameters are returned in a single error.
+func validateParameterValues(bun cnab.ExtendedBundle, action string, params secrets.Set) error {
+	var problems []string
+	for _, param := range bun.ListParametersForAction(action) {
+		name := param.Name
+		def := param.Definition
+		if def == nil {
+			problems = append(problems, fmt.Sprintf("%s: definition %s not defined in bundle", name, param.Parameter.Definition))
+			continue
+		}
+
+		// Do not include sensitive values in the error message
+		sensitive := param.Sensitive
+		describeValue := func(value interface{}) string {
+			if sensitive {
+				return "value"
+			}
+			return fmt.Sprintf("value %v", value)
+		}
+
+		var value interface{}
+		if unconverted, ok := params[name]; ok {
+			if def.Type == nil {
+				continue
+			}
+			converted, err := def.ConvertValue(unconverted)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: invalid %s: not a valid %v", name, describeValue(unconverted), def.Type))
+				continue
+			}
+			value = converted
+		} else if param.Required {
+			problems = append(problems, fmt.Sprintf("%s: a value is required", name))
+			continue
+		} else if def.Default != nil {
+			value = def.Default
+		} else {
+			continue
+		}
+
+		valErrs, err := def.Validate(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: error validating the value: %s", name, err))
+			continue
+		}
+		for _, valErr := range valErrs {
+			reason := valErr.Error
+			if sensitive {
+				if raw := fmt.Sprint(value); raw != "" {
+					reason = strings.ReplaceAll(reason, raw, "******")
+				}
+			}
+			msg := fmt.Sprintf("%s: invalid %s: %s", name, describeValue(value), reason)
+			if valErr.Path != "" && valErr.Path != "/" {
+				msg = fmt.Sprintf("%s at %s", msg, valErr.Path)
+			}
+			problems = append(problems, msg)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return failure.Validation(fmt.Errorf("invalid parameters for the %s action:\n  * %s", action, strings.Join(problems, "\n  * ")))
 }
 
 func (p *Porter) getUnconvertedValueFromRaw(b cnab.ExtendedBundle, def *definition.Schema, key, rawValue string) (string, error) {
@@ -614,25 +730,32 @@ func (p *Porter) getUnconvertedValueFromRaw(b cnab.ExtendedBundle, def *definiti
 	return rawValue, nil
 }
 
-func (p *Porter) resolveParameterSources(ctx context.Context, bun cnab.ExtendedBundle, installation storage.Installation) (secrets.Set, error) {
+// resolveParameterSources resolves the parameters whose value comes from an
+// output. When a snapshot is specified, the outputs of the installation are
+// read from the snapshot instead of using their most recent values. The
+// parameters that were resolved from the outputs of other installations are
+// returned, keyed by the parameter name.
+func (p *Porter) resolveParameterSources(ctx context.Context, bun cnab.ExtendedBundle, installation storage.Installation, snapshot *storage.InstallationSnapshot) (secrets.Set, map[string]storage.RunOutputDependency, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.EndSpan()
 
 	if !bun.HasParameterSources() {
 		span.Debug("No parameter sources defined, skipping")
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	span.Debug("Resolving parameter sources...")
 	parameterSources, err := bun.ReadParameterSources()
 	if err != nil {
-		return nil, span.Error(err)
+		return nil, nil, span.Error(err)
 	}
 
 	values := secrets.Set{}
+	outputDeps := make(map[string]storage.RunOutputDependency)
 	for parameterName, parameterSource := range parameterSources {
 		span.Debugf("Resolving parameter source %s", parameterName)
 		for _, rawSource := range parameterSource.ListSourcesByPriority() {
+			installationNamespace := installation.Namespace
 			var installationName string
 			var outputName string
 			switch source := rawSource.(type) {
@@ -643,35 +766,55 @@ func (p *Porter) resolveParameterSources(ctx context.Context, bun cnab.ExtendedB
 				// TODO(carolynvs): does this need to take namespace into account
 				installationName = depsv1.BuildPrerequisiteInstallationName(installation.Name, source.Dependency)
 				outputName = source.OutputName
+			case cnab.InstallationOutputParameterSource:
+				installationNamespace, installationName = source.GetInstallationReference(installation.Namespace)
+				outputName = source.OutputName
 			}
 
-			output, err := p.Installations.GetLastOutput(ctx, installation.Namespace, installationName, outputName)
+			var output storage.Output
+			var err error
+			if _, ok := rawSource.(cnab.OutputParameterSource); ok && snapshot != nil {
+				output, err = p.Installations.GetSnapshotOutput(ctx, *snapshot, outputName)
+				if errors.Is(err, storage.ErrNotFound{}) {
+					// The output did not exist when the snapshot was taken, so it is not restored
+					span.Debugf("Output %s is not captured by snapshot %s of %s", outputName, snapshot.Name, installation)
+					continue
+				}
+			} else {
+				// Outputs from another namespace must be shared with the installation's namespace
+				output, err = p.Installations.GetSharedOutput(ctx, installation.Namespace, installationNamespace, installationName, outputName)
+			}
 			if err != nil {
 				// When we can't find the output, skip it and let the parameter be set another way
 				if errors.Is(err, storage.ErrNotFound{}) {
-					span.Debugf("No previous output found for %s from %s/%s", outputName, installation.Namespace, installationName)
+					span.Debugf("No previous output found for %s from %s/%s", outputName, installationNamespace, installationName)
 					continue
 				}
 				// Otherwise, something else has happened, perhaps bad data or connectivity problems, we can't ignore it
-				return nil, span.Error(fmt.Errorf("could not set parameter %s from output %s of %s: %w", parameterName, outputName, installation, err))
+				return nil, nil, span.Error(fmt.Errorf("could not set parameter %s from output %s of %s: %w", parameterName, outputName, installation, err))
+			}
+
+			output, err = storage.ReadOutputValue(ctx, p.Installations, output)
+			if err != nil {
+				return nil, nil, span.Error(fmt.Errorf("could not read output %s of %s: %w", outputName, installation, err))
 			}
 
 			if output.Key != "" {
 				resolved, err := p.Sanitizer.RestoreOutput(ctx, output)
 				if err != nil {
-					return nil, span.Error(fmt.Errorf("could not resolve %s's output %s: %w", installation, outputName, err))
+					return nil, nil, span.Error(fmt.Errorf("could not resolve %s's output %s: %w", installation, outputName, err))
 				}
 				output = resolved
 			}
 
 			param, ok := bun.Parameters[parameterName]
 			if !ok {
-				return nil, span.Error(fmt.Errorf("resolveParameterSources:  %s not defined in bundle", parameterName))
+				return nil, nil, span.Error(fmt.Errorf("resolveParameterSources:  %s not defined in bundle", parameterName))
 			}
 
 			def, ok := bun.Definitions[param.Definition]
 			if !ok {
-				return nil, span.Error(fmt.Errorf("definition %s not defined in bundle", param.Definition))
+				return nil, nil, span.Error(fmt.Errorf("definition %s not defined in bundle", param.Definition))
 			}
 
 			if bun.IsFileType(def) {
@@ -680,11 +823,23 @@ func (p *Porter) resolveParameterSources(ctx context.Context, bun cnab.ExtendedB
 				values[parameterName] = string(output.Value)
 			}
 
+			// Record when the parameter depends upon the output of another installation
+			if _, ok := rawSource.(cnab.InstallationOutputParameterSource); ok {
+				outputDeps[parameterName] = storage.RunOutputDependency{
+					Parameter:    parameterName,
+					Namespace:    installationNamespace,
+					Installation: installationName,
+					Output:       outputName,
+				}
+			} else {
+				delete(outputDeps, parameterName)
+			}
+
 			span.Debugf("Injected installation %s output %s as parameter %s", installation, outputName, parameterName)
 		}
 	}
 
-	return values, nil
+	return values, outputDeps, nil
 }
 
 // ParameterCreateOptions represent options for Porter's parameter create command
@@ -820,10 +975,11 @@ func (p *Porter) applyActionOptionsToInstallation(ctx context.Context, ba Bundle
 	//
 	// 3. Resolve named parameter sets
 	//
-	resolvedParams, err := p.loadParameterSets(ctx, bun, o.Namespace, inst.ParameterSets)
+	resolvedParams, revisions, err := p.loadParameterSets(ctx, bun, o.Namespace, inst.ParameterSets)
 	if err != nil {
 		return fmt.Errorf("unable to process provided parameter sets: %w", err)
 	}
+	o.parameterSetRevisions = revisions
 
 	// This contains resolved sensitive values, so only trace it in special dev builds (nothing is traced for release builds)
 	span.SetSensitiveAttributes(tracing.ObjectAttribute("resolved-parameter-sets-keys", resolvedParams))
@@ -863,11 +1019,25 @@ func (p *Porter) applyActionOptionsToInstallation(ctx context.Context, ba Bundle
 	//
 	// 7. When a parameter is not specified, fallback to a parameter source or default
 	//
-	finalParams, err := p.finalizeParameters(ctx, *inst, bun, ba.GetAction(), resolvedParams)
+	var snapshot *storage.InstallationSnapshot
+	if o.RestoreSnapshot != "" {
+		s, err := p.Installations.GetSnapshot(ctx, inst.Namespace, inst.Name, o.RestoreSnapshot)
+		if err != nil {
+			return span.Error(fmt.Errorf("could not restore snapshot %s: %w", o.RestoreSnapshot, err))
+		}
+		span.Infof("Restoring the outputs of installation %s from snapshot %s, taken at %s", inst, s.Name, s.Created.Format(time.RFC3339))
+		snapshot = &s
+	}
+
+	finalParams, outputDeps, err := p.finalizeParameters(ctx, *inst, bun, ba.GetAction(), resolvedParams, snapshot)
 	if err != nil {
 		return err
 	}
 
+	// Record which parameters were resolved from the outputs of other
+	// installations, so that the dependency is recorded on the run
+	o.outputDependencies = outputDeps
+
 	// This contains resolved sensitive values, so only trace it in special dev builds (nothing is traced for release builds)
 	span.SetSensitiveAttributes(tracing.ObjectAttribute("final-parameters", finalParams))
 
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/printer"
 	"get.porter.sh/porter/pkg/secrets"
 	"get.porter.sh/porter/pkg/storage"
@@ -109,7 +110,7 @@ func Test_loadParameters_paramNotDefined(t *testing.T) {
 	}
 
 	i := storage.Installation{}
-	_, err := r.finalizeParameters(context.Background(), i, b, "action", overrides)
+	_, _, err := r.finalizeParameters(context.Background(), i, b, "action", overrides, nil)
 	require.EqualError(t, err, "parameter foo not defined in bundle")
 }
 
@@ -132,10 +133,118 @@ func Test_loadParameters_definitionNotDefined(t *testing.T) {
 	}
 
 	i := storage.Installation{}
-	_, err := r.finalizeParameters(context.Background(), i, b, "action", overrides)
+	_, _, err := r.finalizeParameters(context.Background(), i, b, "action", overrides, nil)
 	require.EqualError(t, err, "definition foo not defined in bundle")
 }
 
+func Test_loadParameters_strict(t *testing.T) {
+	t.Parallel()
+
+	writeOnly := true
+	maxLength := 5
+	minLength := 8
+	minimum := float64(1)
+	maximum := float64(10)
+	b := cnab.NewBundle(bundle.Bundle{
+		Definitions: definition.Definitions{
+			"color": &definition.Schema{
+				Type: "string",
+				Enum: []interface{}{"red", "green"},
+			},
+			"replicas": &definition.Schema{
+				Type:    "integer",
+				Minimum: &minimum,
+				Maximum: &maximum,
+			},
+			"port": &definition.Schema{
+				Type: "integer",
+			},
+			"name": &definition.Schema{
+				Type:      "string",
+				MaxLength: &maxLength,
+			},
+			"password": &definition.Schema{
+				Type:      "string",
+				MinLength: &minLength,
+				WriteOnly: &writeOnly,
+			},
+			"region": &definition.Schema{
+				Type: "string",
+			},
+		},
+		Parameters: map[string]bundle.Parameter{
+			"color":    {Definition: "color"},
+			"replicas": {Definition: "replicas"},
+			"port":     {Definition: "port"},
+			"name":     {Definition: "name"},
+			"password": {Definition: "password"},
+			"region":   {Definition: "region", Required: true},
+		},
+	})
+
+	overrides := map[string]string{
+		"color":    "blue",
+		"replicas": "20",
+		"port":     "http",
+		"name":     "mylongname",
+		"password": "short",
+	}
+
+	t.Run("strict", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewTestPorter(t)
+		defer r.Close()
+		r.Data.StrictParameters = true
+
+		i := storage.Installation{}
+		_, _, err := r.finalizeParameters(context.Background(), i, b, "install", overrides, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid parameters for the install action:")
+		assert.Contains(t, err.Error(), "* color: invalid value blue: should be one of")
+		assert.Contains(t, err.Error(), "* name: invalid value mylongname: max length of 5 characters exceeded")
+		assert.Contains(t, err.Error(), "* password: invalid value: min length of 8 characters required")
+		assert.Contains(t, err.Error(), "* port: invalid value http: not a valid integer")
+		assert.Contains(t, err.Error(), "* region: a value is required")
+		assert.Contains(t, err.Error(), "* replicas: invalid value 20: must be less than or equal to 10")
+		assert.NotContains(t, err.Error(), "short", "the sensitive value should not be included in the error")
+	})
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewTestPorter(t)
+		defer r.Close()
+
+		i := storage.Installation{}
+		_, _, err := r.finalizeParameters(context.Background(), i, b, "install", overrides, nil)
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "invalid parameters for the install action")
+	})
+
+	t.Run("strict with valid parameters", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewTestPorter(t)
+		defer r.Close()
+		r.Data.StrictParameters = true
+
+		valid := map[string]string{
+			"color":    "red",
+			"replicas": "3",
+			"port":     "8080",
+			"name":     "me",
+			"password": "supersecret",
+			"region":   "eastus",
+		}
+		i := storage.Installation{}
+		params, _, err := r.finalizeParameters(context.Background(), i, b, "install", valid, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 3, params["replicas"])
+		assert.Equal(t, 8080, params["port"])
+	})
+}
+
 func Test_loadParameters_applyTo(t *testing.T) {
 	t.Parallel()
 
@@ -185,7 +294,7 @@ func Test_loadParameters_applyTo(t *testing.T) {
 	}
 
 	i := storage.Installation{}
-	params, err := r.finalizeParameters(context.Background(), i, b, "action", overrides)
+	params, _, err := r.finalizeParameters(context.Background(), i, b, "action", overrides, nil)
 	require.NoError(t, err)
 
 	require.Equal(t, "FOO", params["foo"], "expected param 'foo' to be updated")
@@ -217,7 +326,7 @@ func Test_loadParameters_applyToBundleDefaults(t *testing.T) {
 	})
 
 	i := storage.Installation{}
-	params, err := r.finalizeParameters(context.Background(), i, b, "action", nil)
+	params, _, err := r.finalizeParameters(context.Background(), i, b, "action", nil, nil)
 	require.NoError(t, err)
 
 	require.Equal(t, nil, params["foo"], "expected param 'foo' to be nil, regardless of the bundle default, as it does not apply")
@@ -247,7 +356,7 @@ func Test_loadParameters_requiredButDoesNotApply(t *testing.T) {
 	})
 
 	i := storage.Installation{}
-	params, err := r.finalizeParameters(context.Background(), i, b, "action", nil)
+	params, _, err := r.finalizeParameters(context.Background(), i, b, "action", nil, nil)
 	require.NoError(t, err)
 
 	require.Equal(t, nil, params["foo"], "expected param 'foo' to be nil, regardless of claim value, as it does not apply")
@@ -287,12 +396,83 @@ func Test_loadParameters_fileParameter(t *testing.T) {
 	}
 
 	i := storage.Installation{}
-	params, err := r.finalizeParameters(context.Background(), i, b, "action", overrides)
+	params, _, err := r.finalizeParameters(context.Background(), i, b, "action", overrides, nil)
 	require.NoError(t, err)
 
 	require.Equal(t, "SGVsbG8gV29ybGQh", params["foo"], "expected param 'foo' to be the base64-encoded file contents")
 }
 
+func Test_loadParameterSets_Inherits(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	base := storage.NewParameterSet("", "base",
+		storage.ValueStrategy("config", "/path/to/config"),
+		storage.ValueStrategy("logLevel", "1"))
+	require.NoError(t, p.TestParameters.InsertParameterSet(ctx, base))
+
+	dev := storage.NewParameterSet("dev", "dev-overrides", storage.ValueStrategy("logLevel", "2"))
+	dev.Inherits = []string{"base"}
+	require.NoError(t, p.TestParameters.InsertParameterSet(ctx, dev))
+
+	b := cnab.NewBundle(bundle.Bundle{
+		RequiredExtensions: []string{cnab.FileParameterExtensionKey},
+		Definitions: definition.Definitions{
+			"file":   &definition.Schema{Type: "string", ContentEncoding: "base64"},
+			"string": &definition.Schema{Type: "string"},
+		},
+		Parameters: map[string]bundle.Parameter{
+			"config":   {Definition: "file"},
+			"logLevel": {Definition: "string"},
+		},
+	})
+
+	params, _, err := p.loadParameterSets(ctx, b, "dev", []string{"dev-overrides"})
+	require.NoError(t, err)
+	wantParams := secrets.Set{
+		"config":   "/path/to/config",
+		"logLevel": "2",
+	}
+	assert.Equal(t, wantParams, params, "expected the inherited parameters to be resolved, with the file parameter passed through as a path")
+}
+
+func Test_loadParameterSets_Revisions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	network := storage.NewParameterSet("dev", "network", storage.ValueStrategy("vpcId", "vpc-1234"))
+	require.NoError(t, p.TestParameters.InsertParameterSet(ctx, network))
+
+	overrides := storage.NewParameterSet("dev", "overrides", storage.ValueStrategy("subnetId", "subnet-5678"))
+	require.NoError(t, p.TestParameters.InsertParameterSet(ctx, overrides))
+
+	b := cnab.NewBundle(bundle.Bundle{
+		Definitions: definition.Definitions{
+			"string": &definition.Schema{Type: "string"},
+		},
+		Parameters: map[string]bundle.Parameter{
+			"vpcId":    {Definition: "string"},
+			"subnetId": {Definition: "string"},
+		},
+	})
+
+	params, revisions, err := p.loadParameterSets(ctx, b, "dev", []string{"network", "overrides"})
+	require.NoError(t, err)
+	assert.Equal(t, secrets.Set{"vpcId": "vpc-1234", "subnetId": "subnet-5678"}, params)
+	require.Len(t, revisions, 2, "expected the revision of each parameter set to be returned")
+	for i, pset := range []storage.ParameterSet{network, overrides} {
+		assert.Equal(t, pset.Name, revisions[i].Name)
+		assert.Equal(t, pset.Namespace, revisions[i].Namespace)
+		assert.True(t, pset.Status.Modified.Equal(revisions[i].Modified), "expected the revision to be identified by when the parameter set was modified")
+	}
+}
+
 func Test_loadParameters_ParameterSourcePrecedence(t *testing.T) {
 	t.Parallel()
 
@@ -310,7 +490,7 @@ func Test_loadParameters_ParameterSourcePrecedence(t *testing.T) {
 		require.NoError(t, err, "ProcessBundle failed")
 
 		i := storage.Installation{InstallationSpec: storage.InstallationSpec{Name: "mybun"}}
-		params, err := r.finalizeParameters(context.Background(), i, b, cnab.ActionUpgrade, nil)
+		params, _, err := r.finalizeParameters(context.Background(), i, b, cnab.ActionUpgrade, nil, nil)
 		require.NoError(t, err)
 		assert.Equal(t, "foo_default", params["foo"],
 			"expected param 'foo' to have default value")
@@ -334,7 +514,7 @@ func Test_loadParameters_ParameterSourcePrecedence(t *testing.T) {
 		}
 
 		i := storage.Installation{InstallationSpec: storage.InstallationSpec{Name: "mybun"}}
-		params, err := r.finalizeParameters(context.Background(), i, b, cnab.ActionUpgrade, overrides)
+		params, _, err := r.finalizeParameters(context.Background(), i, b, cnab.ActionUpgrade, overrides, nil)
 		require.NoError(t, err)
 		assert.Equal(t, "foo_override", params["foo"],
 			"expected param 'foo' to have override value")
@@ -358,7 +538,7 @@ func Test_loadParameters_ParameterSourcePrecedence(t *testing.T) {
 		cr := r.TestInstallations.CreateResult(c.NewResult(cnab.StatusSucceeded))
 		r.TestInstallations.CreateOutput(cr.NewOutput("foo", []byte("foo_source")))
 
-		params, err := r.finalizeParameters(context.Background(), i, b, cnab.ActionUpgrade, nil)
+		params, _, err := r.finalizeParameters(context.Background(), i, b, cnab.ActionUpgrade, nil, nil)
 		require.NoError(t, err)
 		assert.Equal(t, "foo_source", params["foo"],
 			"expected param 'foo' to have parameter source value")
@@ -386,7 +566,7 @@ func Test_loadParameters_ParameterSourcePrecedence(t *testing.T) {
 		cr := r.TestInstallations.CreateResult(c.NewResult(cnab.StatusSucceeded))
 		r.TestInstallations.CreateOutput(cr.NewOutput("foo", []byte("foo_source")))
 
-		params, err := r.finalizeParameters(context.Background(), i, b, cnab.ActionUpgrade, overrides)
+		params, _, err := r.finalizeParameters(context.Background(), i, b, cnab.ActionUpgrade, overrides, nil)
 		require.NoError(t, err)
 		assert.Equal(t, "foo_override", params["foo"],
 			"expected param 'foo' to have parameter override value")
@@ -410,7 +590,7 @@ func Test_loadParameters_ParameterSourcePrecedence(t *testing.T) {
 		cr := r.TestInstallations.CreateResult(c.NewResult(cnab.StatusSucceeded))
 		r.TestInstallations.CreateOutput(cr.NewOutput("connstr", []byte("connstr value")))
 
-		params, err := r.finalizeParameters(context.Background(), i, b, cnab.ActionUpgrade, nil)
+		params, _, err := r.finalizeParameters(context.Background(), i, b, cnab.ActionUpgrade, nil, nil)
 		require.NoError(t, err)
 		assert.Equal(t, "connstr value", params["connstr"],
 			"expected param 'connstr' to have parameter value from the untyped dependency output")
@@ -440,7 +620,7 @@ func Test_loadParameters_ParameterSourcePrecedence(t *testing.T) {
 		r.TestInstallations.CreateOutput(cr.NewOutput("baz", []byte("baz_source")))
 
 		overrides := map[string]string{"foo": "foo_override"}
-		params, err := r.finalizeParameters(context.Background(), i, b, cnab.ActionUpgrade, overrides)
+		params, _, err := r.finalizeParameters(context.Background(), i, b, cnab.ActionUpgrade, overrides, nil)
 		require.NoError(t, err)
 		assert.Equal(t, "foo_override", params["foo"],
 			"expected param 'foo' to have parameter override value")
@@ -579,7 +759,7 @@ func Test_Paramapalooza(t *testing.T) {
 						overrides["my-param"] = "my-param-value"
 					}
 
-					resolvedParams, err := r.finalizeParameters(context.Background(), i, bun, action, overrides)
+					resolvedParams, _, err := r.finalizeParameters(context.Background(), i, bun, action, overrides, nil)
 					if tc.ExpectedErr != "" {
 						require.EqualError(t, err, tc.ExpectedErr)
 					} else {
@@ -612,7 +792,7 @@ func TestRuntime_ResolveParameterSources(t *testing.T) {
 	cr = r.TestInstallations.CreateResult(c.NewResult(cnab.StatusSucceeded))
 	r.TestInstallations.CreateOutput(cr.NewOutput("bar", []byte("bar value")))
 
-	got, err := r.resolveParameterSources(context.Background(), bun, i)
+	got, _, err := r.resolveParameterSources(context.Background(), bun, i, nil)
 	require.NoError(t, err, "resolveParameterSources failed")
 
 	want := secrets.Set{
@@ -622,6 +802,65 @@ func TestRuntime_ResolveParameterSources(t *testing.T) {
 	assert.Equal(t, want, got, "resolved incorrect parameter values")
 }
 
+func TestRuntime_ResolveParameterSources_InstallationOutput(t *testing.T) {
+	t.Parallel()
+
+	r := NewTestPorter(t)
+	defer r.Close()
+	ctx := context.Background()
+
+	var ps cnab.ParameterSources
+	ps.SetParameterFromInstallationOutput("db-connstr", "platform", "mysql", "connstr")
+	bun := cnab.NewBundle(bundle.Bundle{
+		Parameters: map[string]bundle.Parameter{
+			"db-connstr": {Definition: "db-connstr"},
+		},
+		Definitions: definition.Definitions{
+			"db-connstr": &definition.Schema{Type: "string"},
+		},
+		Custom: map[string]interface{}{
+			cnab.ParameterSourcesExtensionKey: ps,
+		},
+	})
+
+	mysql := r.TestInstallations.CreateInstallation(storage.NewInstallation("platform", "mysql"))
+	c := r.TestInstallations.CreateRun(mysql.NewRun(cnab.ActionInstall))
+	cr := r.TestInstallations.CreateResult(c.NewResult(cnab.StatusSucceeded))
+	r.TestInstallations.CreateOutput(cr.NewOutput("connstr", []byte("mysql://platform")))
+
+	i := r.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "myapp"))
+
+	t.Run("not shared", func(t *testing.T) {
+		_, _, err := r.resolveParameterSources(ctx, bun, i, nil)
+		require.ErrorIs(t, err, storage.ErrAccessDenied{})
+	})
+
+	t.Run("shared by a namespace policy", func(t *testing.T) {
+		r.Config.Data.NamespacePolicies = []config.NamespacePolicy{
+			{Namespace: "platform", ShareOutputsWith: []string{"dev"}, Outputs: []string{"connstr"}},
+		}
+		defer func() { r.Config.Data.NamespacePolicies = nil }()
+
+		got, deps, err := r.resolveParameterSources(ctx, bun, i, nil)
+		require.NoError(t, err, "resolveParameterSources failed")
+		assert.Equal(t, secrets.Set{"db-connstr": "mysql://platform"}, got)
+		wantDeps := map[string]storage.RunOutputDependency{
+			"db-connstr": {Parameter: "db-connstr", Namespace: "platform", Installation: "mysql", Output: "connstr"},
+		}
+		assert.Equal(t, wantDeps, deps, "expected the parameter to depend on the output of the other installation")
+
+		// A parameter that is overridden no longer depends on the output
+		params, finalDeps, err := r.finalizeParameters(ctx, i, bun, cnab.ActionInstall, map[string]string{"db-connstr": "mysql://override"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "mysql://override", params["db-connstr"])
+		assert.Empty(t, finalDeps)
+
+		_, finalDeps, err = r.finalizeParameters(ctx, i, bun, cnab.ActionInstall, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []storage.RunOutputDependency{wantDeps["db-connstr"]}, finalDeps)
+	})
+}
+
 func TestShowParameters_NotFound(t *testing.T) {
 	p := NewTestPorter(t)
 	defer p.Close()
@@ -4,21 +4,27 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"sync"
 
+	authzstore "get.porter.sh/porter/pkg/authorization/pluginstore"
 	"get.porter.sh/porter/pkg/build"
 	"get.porter.sh/porter/pkg/build/buildkit"
 	"get.porter.sh/porter/pkg/cache"
 	cnabtooci "get.porter.sh/porter/pkg/cnab/cnab-to-oci"
 	cnabprovider "get.porter.sh/porter/pkg/cnab/provider"
 	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/faults"
+	"get.porter.sh/porter/pkg/metrics"
 	"get.porter.sh/porter/pkg/mixin"
 	"get.porter.sh/porter/pkg/plugins"
 	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/secrets/audit"
 	secretsplugin "get.porter.sh/porter/pkg/secrets/pluginstore"
 	"get.porter.sh/porter/pkg/storage"
 	"get.porter.sh/porter/pkg/storage/migrations"
+	storageplugins "get.porter.sh/porter/pkg/storage/plugins"
 	storageplugin "get.porter.sh/porter/pkg/storage/pluginstore"
 	"get.porter.sh/porter/pkg/templates"
 	"get.porter.sh/porter/pkg/tracing"
@@ -34,9 +40,22 @@ type Porter struct {
 	// or to switch it out for tests.
 	builder build.Builder
 
+	// localImages is loaded dynamically when unset, so that tests can
+	// switch out the local container engine.
+	localImages localImageStore
+
+	// metricsServer serves the Prometheus metrics when they are enabled.
+	metricsServer *http.Server
+
+	// authorizationPlugin is the connection to the authorization plugin,
+	// which is released when Porter is closed.
+	authorizationPlugin *authzstore.Authorizer
+
 	Cache         cache.BundleCache
 	Credentials   storage.CredentialSetProvider
+	Namespaces    storage.NamespaceProvider
 	Parameters    storage.ParameterSetProvider
+	Schedules     storage.ScheduleProvider
 	Sanitizer     *storage.Sanitizer
 	Installations storage.InstallationProvider
 	Registry      cnabtooci.RegistryProvider
@@ -46,33 +65,64 @@ type Porter struct {
 	CNAB          cnabprovider.CNABProvider
 	Secrets       secrets.Store
 	Storage       storage.Provider
+
+	// StorageFailover fails over to the secondary storage account when the
+	// default storage account is unreachable. It is only set when Porter is
+	// created with New.
+	StorageFailover *storage.FailoverStorage
 }
 
 // New porter client, initialized with useful defaults.
 func New() *Porter {
 	c := config.New()
-	storage := storage.NewPluginAdapter(storageplugin.NewStore(c))
 	secretStorage := secrets.NewPluginAdapter(secretsplugin.NewStore(c))
-	return NewFor(c, storage, secretStorage)
+	failover := storage.NewFailoverStorage(c, storageplugin.NewStore(c), func(name string) storageplugins.StorageProtocol {
+		return storageplugin.NewStoreFor(c, name)
+	})
+	store := storage.NewPluginAdapter(storage.NewEncryptedStorage(failover, c, secretStorage))
+	p := NewFor(c, store, secretStorage)
+	p.StorageFailover = failover
+	return p
 }
 
 func NewFor(c *config.Config, store storage.Store, secretStorage secrets.Store) *Porter {
 	cache := cache.New(c)
+	secretStorage = faults.WrapSecretStore(secretStorage, faults.FromEnv(c.Getenv))
+	secretStorage = secrets.NewCachingStore(c, secretStorage)
 
 	storageManager := migrations.NewManager(c, store)
 	installationStorage := storage.NewInstallationStore(storageManager)
+	installationStorage.SetOutputAccessControl(storage.NewNamespacePolicyAccessControl(c))
+	installationStorage.SetOutputOffload(storage.NewConfigOutputOffload(c))
+	installationStorage.SetRunLedger(storage.NewConfigRunLedger(c))
+	installationStorage.SetRunMigrations(migrations.RunMigrations)
+	installationStorage.SetIDStrategy(storage.NewConfigIDStrategy(c))
 	credStorage := storage.NewCredentialStore(storageManager, secretStorage)
 	paramStorage := storage.NewParameterStore(storageManager, secretStorage)
+	authzPlugin := authzstore.NewAuthorizer(c)
+	authorizer := storage.ChainAuthorizer{storage.NewConfigAuthorizer(c), authzPlugin}
+	installationStorage.SetAuthorizer(authorizer)
+	credStorage.SetAuthorizer(authorizer)
+	paramStorage.SetAuthorizer(authorizer)
+	scheduleStorage := storage.NewScheduleStore(storageManager)
+	scheduleStorage.SetAuthorizer(authorizer)
 	sanitizerService := storage.NewSanitizer(paramStorage, secretStorage)
+	sanitizerService.SetSensitivityPolicy(storage.NewConfigSensitivityPolicy(c))
+	auditHook := audit.NewConfigHook(c)
+	sanitizerService.SetAuditHook(auditHook)
+	credStorage.SetAuditHook(auditHook)
+	paramStorage.SetAuditHook(auditHook)
 	storageManager.Initialize(sanitizerService) // we have a bit of a dependency problem here that it would be great to figure out eventually
 
-	return &Porter{
+	p := &Porter{
 		Config:        c,
 		Cache:         cache,
 		Storage:       storageManager,
 		Installations: installationStorage,
 		Credentials:   credStorage,
+		Namespaces:    storage.NewNamespaceStore(storageManager),
 		Parameters:    paramStorage,
+		Schedules:     scheduleStorage,
 		Secrets:       secretStorage,
 		Registry:      cnabtooci.NewRegistry(c.Context),
 		Templates:     templates.NewTemplates(c),
@@ -81,6 +131,8 @@ func NewFor(c *config.Config, store storage.Store, secretStorage secrets.Store)
 		CNAB:          cnabprovider.NewRuntime(c, installationStorage, credStorage, secretStorage, sanitizerService),
 		Sanitizer:     sanitizerService,
 	}
+	p.authorizationPlugin = authzPlugin
+	return p
 }
 
 // Used to warn just a single time when Porter starts up.
@@ -101,16 +153,52 @@ func (p *Porter) Connect(ctx context.Context) (context.Context, error) {
 	})
 
 	// Load the config file and replace any referenced secrets
-	return p.Config.Load(ctx, func(innerCtx context.Context, secret string) (string, error) {
+	ctx, err := p.Config.Load(ctx, func(innerCtx context.Context, secret string) (string, error) {
 		value, err := p.Secrets.Resolve(innerCtx, "secret", secret)
 		if err != nil {
-			if strings.Contains(err.Error(), "invalid value source: secret") {
+			// Older secrets plugins report an unsupported source only in the message
+			if errors.Is(err, secrets.ErrNotImplemented) || strings.Contains(err.Error(), "invalid value source: secret") {
 				return "", errors.New("No secret store account is configured")
 			}
 			return "", err
 		}
 		return value, nil
 	})
+	if err != nil {
+		return ctx, err
+	}
+
+	// Validate the strategy used to generate the ids of new runs and results
+	if _, err = storage.NewIDStrategy(p.Config.Data.IDStrategy); err != nil {
+		return ctx, err
+	}
+
+	// Run mixins in containers when images are configured for them
+	if _, ok := p.Mixins.(*mixin.PackageManager); ok && p.Config.Data.MixinContainer.Enabled() {
+		p.Mixins = mixin.NewContainerProvider(p.Config)
+	}
+
+	return ctx, nil
+}
+
+// startMetricsListener serves the Prometheus metrics when they are enabled in
+// the configuration. It is only called by long-running commands, such as
+// porter api serve, and they keep running when the listener can't be started,
+// for example because another process is already using the address.
+func (p *Porter) startMetricsListener(ctx context.Context) {
+	cfg := p.Config.Data.Metrics
+	if !cfg.Enabled || p.metricsServer != nil {
+		return
+	}
+
+	log := tracing.LoggerFromContext(ctx)
+	server, addr, err := metrics.Listen(cfg.GetListen())
+	if err != nil {
+		log.Warnf("could not start the metrics listener: %s", err)
+		return
+	}
+	p.metricsServer = server
+	log.Debugf("Serving metrics at http://%s%s", addr, metrics.MetricsPath)
 }
 
 // Close releases resources used by Porter before terminating the application.
@@ -128,6 +216,19 @@ func (p *Porter) Close() error {
 		bigErr = multierror.Append(bigErr, err)
 	}
 
+	if p.authorizationPlugin != nil {
+		if err = p.authorizationPlugin.Close(); err != nil {
+			bigErr = multierror.Append(bigErr, err)
+		}
+	}
+
+	if p.metricsServer != nil {
+		if err = p.metricsServer.Close(); err != nil {
+			bigErr = multierror.Append(bigErr, err)
+		}
+		p.metricsServer = nil
+	}
+
 	err = p.Config.Close()
 	if err != nil {
 		bigErr = multierror.Append(bigErr, err)
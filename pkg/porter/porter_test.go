@@ -8,6 +8,7 @@ import (
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/tests"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPorter_GetBuilder(t *testing.T) {
@@ -50,3 +51,16 @@ func TestPorter_LoadWithSecretResolveError(t *testing.T) {
 	// Validate the porter is handling the error
 	tests.RequireErrorContains(t, err, "secret not found")
 }
+
+func TestPorter_Connect_DoesNotServeMetrics(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.Config.Data.Metrics = config.MetricsConfig{Enabled: true, Listen: "127.0.0.1:0"}
+	_, err := p.Connect(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, p.metricsServer, "metrics should only be served by porter api serve")
+
+	p.startMetricsListener(context.Background())
+	assert.NotNil(t, p.metricsServer, "the metrics listener should start when it is enabled")
+}
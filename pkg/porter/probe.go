@@ -0,0 +1,78 @@
+package porter
+
+import (
+	"context"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/manifest"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/runtime"
+	"get.porter.sh/porter/pkg/schema"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// ProbeOptions are the options for probing a bundle from inside its invocation image.
+type ProbeOptions struct {
+	RunOptions
+}
+
+func NewProbeOptions(c *config.Config) ProbeOptions {
+	return ProbeOptions{
+		RunOptions: NewRunOptions(c),
+	}
+}
+
+func (o *ProbeOptions) Validate() error {
+	err := o.RunOptions.Validate()
+	if err != nil {
+		return err
+	}
+
+	if o.Action == "" {
+		o.Action = runtime.DefaultProbeAction
+	}
+
+	return nil
+}
+
+// Probe executes a read-only action of the bundle, such as status, and prints
+// the result as json so that it can be consumed by other tools, such as the
+// Porter Operator. An error is returned when the probe fails, after the result
+// is printed.
+func (p *Porter) Probe(ctx context.Context, opts ProbeOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	// The bundle was already validated when it was built, see Run
+	p.Config.Data.SchemaCheck = string(schema.CheckStrategyNone)
+
+	var result runtime.ProbeResult
+	m, err := manifest.LoadManifestFrom(ctx, p.Config, opts.File)
+	if err != nil {
+		now := time.Now()
+		result = runtime.ProbeResult{
+			Action:  opts.Action,
+			Status:  storage.StepStatusFailed,
+			Started: now,
+			Stopped: now,
+			Steps:   []storage.StepResult{},
+			Error:   err.Error(),
+		}
+	} else {
+		runtimeCfg := runtime.NewConfigFor(p.Context)
+		runtimeCfg.DebugMode = opts.DebugMode
+		r := runtime.NewPorterRuntime(runtimeCfg, p.Mixins)
+		runtimeManifest := r.NewRuntimeManifest(opts.Action, m)
+		result, err = r.Probe(ctx, runtimeManifest)
+	}
+
+	if printErr := printer.PrintJson(p.Out, result); printErr != nil {
+		return span.Error(printErr)
+	}
+	if err != nil {
+		return span.Error(err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package porter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/runtime"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeOptions_Validate(t *testing.T) {
+	t.Run("defaults to status", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := NewProbeOptions(p.Config)
+		require.NoError(t, opts.Validate())
+		assert.Equal(t, runtime.DefaultProbeAction, opts.Action)
+	})
+
+	t.Run("uses CNAB_ACTION", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		p.Setenv(config.EnvACTION, "health")
+		opts := NewProbeOptions(p.Config)
+		require.NoError(t, opts.Validate())
+		assert.Equal(t, "health", opts.Action)
+	})
+}
+
+func TestPorter_Probe(t *testing.T) {
+	testcases := []struct {
+		name       string
+		action     string
+		wantStatus string
+		wantError  string
+	}{
+		{name: "read-only action", action: "zombies", wantStatus: storage.StepStatusSucceeded},
+		{name: "modifying action", action: cnab.ActionInstall, wantStatus: storage.StepStatusFailed, wantError: "the install action cannot be probed"},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewTestPorter(t)
+			defer p.Close()
+
+			p.TestConfig.TestContext.AddTestFile("testdata/porter.yaml", "porter.yaml")
+			p.TestConfig.TestContext.AddTestFile("testdata/bundle.json", "/cnab/bundle.json")
+
+			// Declare the custom action as read-only so that it can be probed
+			bun, err := cnab.LoadBundle(p.Context, "/cnab/bundle.json")
+			require.NoError(t, err)
+			action := bun.Actions["zombies"]
+			action.Modifies = false
+			bun.Actions["zombies"] = action
+			data, err := json.Marshal(bun.Bundle)
+			require.NoError(t, err)
+			require.NoError(t, p.FileSystem.WriteFile("/cnab/bundle.json", data, pkg.FileModeWritable))
+
+			opts := NewProbeOptions(p.Config)
+			opts.Action = tc.action
+			opts.File = "porter.yaml"
+			require.NoError(t, opts.Validate())
+
+			err = p.Probe(context.Background(), opts)
+			if tc.wantError != "" {
+				require.ErrorContains(t, err, tc.wantError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			var result runtime.ProbeResult
+			require.NoError(t, json.Unmarshal([]byte(p.TestConfig.TestContext.GetOutput()), &result), "the result should be printed as json")
+			assert.Equal(t, tc.action, result.Action)
+			assert.Equal(t, tc.wantStatus, result.Status)
+			assert.Contains(t, result.Error, tc.wantError)
+		})
+	}
+}
@@ -5,12 +5,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
 
+	"get.porter.sh/porter/pkg/archive"
 	"get.porter.sh/porter/pkg/build"
 	"get.porter.sh/porter/pkg/cnab"
 	cnabtooci "get.porter.sh/porter/pkg/cnab/cnab-to-oci"
+	configadapter "get.porter.sh/porter/pkg/cnab/config-adapter"
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/manifest"
 	"get.porter.sh/porter/pkg/tracing"
@@ -31,6 +34,11 @@ type PublishOptions struct {
 	Tag         string
 	Registry    string
 	ArchiveFile string
+
+	// DecryptKeyRef is a reference to the key in the secret store, of the form
+	// secret://NAME, used to decrypt an encrypted archive. Defaults to the key
+	// recorded in the archive when it was encrypted.
+	DecryptKeyRef string
 }
 
 // Validate performs validation on the publish options
@@ -44,7 +52,17 @@ func (o *PublishOptions) Validate(cfg *config.Config) error {
 		if o.Reference == "" {
 			return errors.New("must provide a value for --reference of the form REGISTRY/bundle:tag")
 		}
+
+		if o.DecryptKeyRef != "" {
+			if _, err := archive.ParseKeyRef(o.DecryptKeyRef); err != nil {
+				return fmt.Errorf("invalid --decrypt-key-ref: %w", err)
+			}
+		}
 	} else {
+		if o.DecryptKeyRef != "" {
+			return errors.New("--decrypt-key-ref can only be used with --archive")
+		}
+
 		// Proceed with publishing from the resolved build context directory
 		err := o.bundleFileOptions.Validate(cfg.Context)
 		if err != nil {
@@ -182,21 +200,13 @@ func (p *Porter) publishFromFile(ctx context.Context, opts PublishOptions) error
 		InsecureRegistry: opts.InsecureRegistry,
 	}
 
-	// Before we attempt to push, check if any of the bundle exists already.
-	// If force was not specified, we shouldn't push any of the bundle since
-	// the bundle and images must be pushed as a unit.
-	if !opts.Force {
-		_, err := p.Registry.GetBundleMetadata(ctx, bundleRef.Reference, regOpts)
-		if err != nil {
-			if !errors.Is(err, cnabtooci.ErrNotFound{}) {
-				return log.Errorf("Publish stopped because detection of %s in the destination registry failed. To overwrite it, repeat the command with --force specified: %w", bundleRef, err)
-			}
-		} else {
-			return log.Errorf("Publish stopped because %s already exists in the destination registry. To overwrite it, repeat the command with --force specified.", bundleRef)
-		}
+	existingDigest, err := p.checkPublishDestination(ctx, bundleRef.Reference, opts.Force, regOpts)
+	if err != nil {
+		return log.Error(err)
 	}
 
-	bundleRef.Digest, err = p.Registry.PushImage(ctx, imgRef, regOpts)
+	var summary publishSummary
+	bundleRef.Digest, err = p.pushInvocationImage(ctx, imgRef, regOpts, &summary)
 	if err != nil {
 		return log.Errorf("unable to push CNAB invocation image %q: %w", m.Image, err)
 	}
@@ -210,6 +220,8 @@ func (p *Porter) publishFromFile(ctx context.Context, opts PublishOptions) error
 	if err != nil {
 		return err
 	}
+	summary.BundleUnchanged = existingDigest != "" && existingDigest == bundleRef.Digest
+	summary.print(p.Out, bundleRef.Reference)
 
 	// Perhaps we have a cached version of a bundle with the same reference, previously pulled
 	// If so, replace it, as it is most likely out-of-date per this publish
@@ -233,19 +245,10 @@ func (p *Porter) publishFromArchive(ctx context.Context, opts PublishOptions) er
 
 	regOpts := cnabtooci.RegistryOptions{InsecureRegistry: opts.InsecureRegistry}
 
-	// Before we attempt to push, check if any of the bundle exists already.
-	// If force was not specified, we shouldn't push any of the bundle since
-	// the bundle and images must be pushed as a unit.
 	ref := opts.GetReference()
-	if !opts.Force {
-		_, err := p.Registry.GetBundleMetadata(ctx, ref, regOpts)
-		if err != nil {
-			if !errors.Is(err, cnabtooci.ErrNotFound{}) {
-				return log.Errorf("Publish stopped because detection of %s in the destination registry failed. To overwrite it, repeat the command with --force specified: %w", ref, err)
-			}
-		} else {
-			return log.Errorf("Publish stopped because %s already exists in the destination registry. To overwrite it, repeat the command with --force specified.", ref)
-		}
+	existingDigest, err := p.checkPublishDestination(ctx, ref, opts.Force, regOpts)
+	if err != nil {
+		return log.Error(err)
 	}
 
 	source := p.FileSystem.Abs(opts.ArchiveFile)
@@ -255,6 +258,17 @@ func (p *Porter) publishFromArchive(ctx context.Context, opts PublishOptions) er
 	}
 	defer p.FileSystem.RemoveAll(tmpDir)
 
+	// Decrypt the archive into a separate directory, so that it does not conflict with the extracted archive
+	decryptDir, err := p.FileSystem.TempDir("", "porter")
+	if err != nil {
+		return log.Errorf("error creating temp directory for archive decryption: %w", err)
+	}
+	defer p.FileSystem.RemoveAll(decryptDir)
+	source, err = p.decryptArchive(ctx, source, decryptDir, opts.DecryptKeyRef)
+	if err != nil {
+		return log.Error(err)
+	}
+
 	bundleRef, err := p.extractBundle(ctx, tmpDir, source)
 	if err != nil {
 		return err
@@ -279,8 +293,9 @@ func (p *Porter) publishFromArchive(ctx context.Context, opts PublishOptions) er
 
 	// Push updated images (renamed based on provided bundle tag) with same digests
 	// then update the bundle with new values (image name, digest)
+	var summary publishSummary
 	for _, invImg := range bundleRef.Definition.InvocationImages {
-		relocMap, err := p.relocateImage(bundleRef.RelocationMap, layout, invImg.Image, opts.Reference)
+		relocMap, err := p.relocateImage(ctx, bundleRef.RelocationMap, layout, invImg.Image, opts.Reference, regOpts, &summary)
 		if err != nil {
 			return log.Error(err)
 		}
@@ -288,7 +303,7 @@ func (p *Porter) publishFromArchive(ctx context.Context, opts PublishOptions) er
 		bundleRef.RelocationMap = relocMap
 	}
 	for _, img := range bundleRef.Definition.Images {
-		relocMap, err := p.relocateImage(bundleRef.RelocationMap, layout, img.Image, opts.Reference)
+		relocMap, err := p.relocateImage(ctx, bundleRef.RelocationMap, layout, img.Image, opts.Reference, regOpts, &summary)
 		if err != nil {
 			return log.Error(err)
 		}
@@ -300,6 +315,8 @@ func (p *Porter) publishFromArchive(ctx context.Context, opts PublishOptions) er
 	if err != nil {
 		return err
 	}
+	summary.BundleUnchanged = existingDigest != "" && existingDigest == bundleRef.Digest
+	summary.print(p.Out, bundleRef.Reference)
 
 	// Perhaps we have a cached version of a bundle with the same tag, previously pulled
 	// If so, replace it, as it is most likely out-of-date per this publish
@@ -335,28 +352,83 @@ func (p *Porter) extractBundle(ctx context.Context, tmpDir, source string) (cnab
 		return cnab.BundleReference{}, span.Error(fmt.Errorf("failed to parse relocation-mapping.json from archive %s: %w", source, err))
 	}
 
-	return cnab.BundleReference{Definition: cnab.ExtendedBundle{Bundle: *bun}, RelocationMap: reloMap}, nil
+	return cnab.BundleReference{Definition: cnab.NewBundle(*bun), RelocationMap: reloMap}, nil
+}
+
+// checkPublishDestination checks if the bundle already exists in the
+// destination registry, returning the digest of the existing bundle.
+// If force was not specified, we shouldn't push any of the bundle since
+// the bundle and images must be pushed as a unit.
+func (p *Porter) checkPublishDestination(ctx context.Context, ref cnab.OCIReference, force bool, regOpts cnabtooci.RegistryOptions) (digest.Digest, error) {
+	existing, err := p.Registry.GetBundleMetadata(ctx, ref, regOpts)
+	if err != nil {
+		if errors.Is(err, cnabtooci.ErrNotFound{}) || force {
+			return "", nil
+		}
+		return "", fmt.Errorf("Publish stopped because detection of %s in the destination registry failed. To overwrite it, repeat the command with --force specified: %w", ref, err)
+	}
+
+	if !force {
+		return "", fmt.Errorf("Publish stopped because %s already exists in the destination registry. To overwrite it, repeat the command with --force specified.", ref)
+	}
+	return existing.Digest, nil
+}
+
+// pushInvocationImage pushes the invocation image from the local Docker
+// cache, returning its digest in the registry. The push is skipped when the
+// destination already has the same image, determined by comparing the image id
+// with the image configuration digest in the registry.
+func (p *Porter) pushInvocationImage(ctx context.Context, imgRef cnab.OCIReference, regOpts cnabtooci.RegistryOptions, summary *publishSummary) (digest.Digest, error) {
+	log := tracing.LoggerFromContext(ctx)
+
+	if localImg, err := p.Registry.GetCachedImage(ctx, imgRef); err == nil {
+		existing, err := p.Registry.GetImageMetadata(ctx, imgRef, regOpts)
+		if err == nil && existing.ConfigDigest != "" && existing.ConfigDigest.String() == localImg.ID {
+			log.Debugf("Skipping push of %s because the image already exists in the destination registry", imgRef)
+			summary.Skipped = append(summary.Skipped, imgRef.String())
+			return existing.Digest, nil
+		}
+	}
+
+	imgDigest, err := p.Registry.PushImage(ctx, imgRef, regOpts)
+	if err != nil {
+		return "", err
+	}
+	summary.Uploaded = append(summary.Uploaded, imgRef.String())
+	return imgDigest, nil
 }
 
 // pushUpdatedImage uses the provided layout to find the provided origImg,
-// gathers the pre-existing digest and then pushes this digest using the newImgName
-func pushUpdatedImage(layout registry.Layout, origImg string, newImgName image.Name) (image.Digest, error) {
+// gathers the pre-existing digest and then pushes this digest using the newImgName.
+// The push is skipped when the destination repository already has an image with the digest.
+func (p *Porter) pushUpdatedImage(ctx context.Context, layout registry.Layout, origImg string, newImgName image.Name, regOpts cnabtooci.RegistryOptions, summary *publishSummary) (image.Digest, error) {
+	log := tracing.LoggerFromContext(ctx)
+
 	origImgName, err := image.NewName(origImg)
 	if err != nil {
 		return image.EmptyDigest, fmt.Errorf("unable to parse image %q into domain/path components: %w", origImg, err)
 	}
 
-	digest, err := layout.Find(origImgName)
+	imgDigest, err := layout.Find(origImgName)
 	if err != nil {
 		return image.EmptyDigest, fmt.Errorf("unable to find image %s in archived OCI Layout: %w", origImgName.String(), err)
 	}
 
-	err = layout.Push(digest, newImgName)
+	if existingRef, err := cnab.ParseOCIReference(newImgName.Name() + "@" + imgDigest.String()); err == nil {
+		if _, err := p.Registry.GetImageMetadata(ctx, existingRef, regOpts); err == nil {
+			log.Debugf("Skipping push of %s because the image already exists in the destination registry", existingRef)
+			summary.Skipped = append(summary.Skipped, existingRef.String())
+			return imgDigest, nil
+		}
+	}
+
+	err = layout.Push(imgDigest, newImgName)
 	if err != nil {
 		return image.EmptyDigest, fmt.Errorf("unable to push image %s: %w", newImgName.String(), err)
 	}
+	summary.Uploaded = append(summary.Uploaded, newImgName.String())
 
-	return digest, nil
+	return imgDigest, nil
 }
 
 // getNewImageNameFromBundleReference derives a new image.Name object from the provided original
@@ -401,8 +473,17 @@ func (p *Porter) rewriteBundleWithInvocationImageDigest(ctx context.Context, m *
 	}
 	m.Image = taggedImage
 
+	// Keep the porter-runtime recorded when the bundle was built, it is the
+	// runtime embedded in the invocation image that was pushed
+	var runtime *configadapter.RuntimeRecord
+	if origBun, err := cnab.LoadBundle(p.Context, build.LOCAL_BUNDLE); err == nil {
+		if stamp, err := configadapter.LoadStamp(origBun); err == nil {
+			runtime = stamp.Runtime
+		}
+	}
+
 	fmt.Fprintln(p.Out, "\nRewriting CNAB bundle.json...")
-	err = p.buildBundle(ctx, m, digest)
+	err = p.buildBundle(ctx, m, digest, runtime)
 	if err != nil {
 		return cnab.ExtendedBundle{}, fmt.Errorf("unable to rewrite CNAB bundle.json with updated invocation image digest: %w", err)
 	}
@@ -415,7 +496,7 @@ func (p *Porter) rewriteBundleWithInvocationImageDigest(ctx context.Context, m *
 	return bun, nil
 }
 
-func (p *Porter) relocateImage(relocationMap relocation.ImageRelocationMap, layout registry.Layout, originImg string, newReference string) (relocation.ImageRelocationMap, error) {
+func (p *Porter) relocateImage(ctx context.Context, relocationMap relocation.ImageRelocationMap, layout registry.Layout, originImg string, newReference string, regOpts cnabtooci.RegistryOptions, summary *publishSummary) (relocation.ImageRelocationMap, error) {
 	newImgName, err := getNewImageNameFromBundleReference(originImg, newReference)
 	if err != nil {
 		return nil, err
@@ -425,7 +506,7 @@ func (p *Porter) relocateImage(relocationMap relocation.ImageRelocationMap, layo
 	if relocatedImage, ok := relocationMap[originImg]; ok {
 		originImgRef = relocatedImage
 	}
-	digest, err := pushUpdatedImage(layout, originImgRef, newImgName)
+	digest, err := p.pushUpdatedImage(ctx, layout, originImgRef, newImgName, regOpts, summary)
 	if err != nil {
 		return nil, fmt.Errorf("unable to push updated image: %w", err)
 	}
@@ -467,3 +548,33 @@ func (p *Porter) refreshCachedBundle(bundleRef cnab.BundleReference) error {
 	}
 	return nil
 }
+
+// publishSummary records what was uploaded to the destination registry when
+// publishing, and what was skipped because it already exists there.
+type publishSummary struct {
+	// Uploaded images.
+	Uploaded []string
+
+	// Skipped images that already exist in the destination registry.
+	Skipped []string
+
+	// BundleUnchanged indicates that the destination already had the same bundle.
+	BundleUnchanged bool
+}
+
+func (s publishSummary) print(out io.Writer, ref cnab.OCIReference) {
+	fmt.Fprintf(out, "\nPublished %s\n", ref)
+	fmt.Fprintf(out, "  %d images uploaded\n", len(s.Uploaded))
+	for _, img := range s.Uploaded {
+		fmt.Fprintf(out, "    %s\n", img)
+	}
+	fmt.Fprintf(out, "  %d images skipped because they already exist in the destination registry\n", len(s.Skipped))
+	for _, img := range s.Skipped {
+		fmt.Fprintf(out, "    %s\n", img)
+	}
+	if s.BundleUnchanged {
+		fmt.Fprintln(out, "  bundle unchanged")
+	} else {
+		fmt.Fprintln(out, "  bundle uploaded")
+	}
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/cnabio/cnab-to-oci/relocation"
 	"github.com/cnabio/image-relocation/pkg/image"
 	"github.com/cnabio/image-relocation/pkg/registry"
+	"github.com/docker/docker/api/types"
+	"github.com/opencontainers/go-digest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -60,6 +62,14 @@ func TestPublish_Validate_ArchivePath(t *testing.T) {
 	opts.Reference = "myreg/mybuns:v0.1.0"
 	err = opts.Validate(p.Config)
 	require.NoError(t, err, "validating should not have failed")
+
+	opts.DecryptKeyRef = "archive-key"
+	err = opts.Validate(p.Config)
+	assert.ErrorContains(t, err, "invalid --decrypt-key-ref")
+
+	opts.DecryptKeyRef = "secret://archive-key"
+	err = opts.Validate(p.Config)
+	require.NoError(t, err, "validating should not have failed")
 }
 
 func TestPublish_validateTag(t *testing.T) {
@@ -173,12 +183,79 @@ func TestPublish_RelocateImage(t *testing.T) {
 	for _, tc := range testcases {
 		tc := tc
 		t.Run(tc.description, func(t *testing.T) {
-			newMap, err := p.relocateImage(tc.relocationMap, tc.layout, originImg, tag)
+			var summary publishSummary
+			newMap, err := p.relocateImage(context.Background(), tc.relocationMap, tc.layout, originImg, tag, cnabtooci.RegistryOptions{}, &summary)
 			if tc.wantErr != nil {
 				require.ErrorContains(t, err, tc.wantErr.Error())
 				return
 			}
 			require.Equal(t, tag+"@sha256:6b5a28ccbb76f12ce771a23757880c6083234255c5ba191fca1c5db1f71c1687", newMap[originImg])
+			require.Len(t, summary.Uploaded, 1, "the image should be pushed")
+		})
+	}
+
+	t.Run("image exists at the destination", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		var checkedRef string
+		p.TestRegistry.MockGetImageMetadata = func(ctx context.Context, ref cnab.OCIReference, opts cnabtooci.RegistryOptions) (cnabtooci.ImageMetadata, error) {
+			checkedRef = ref.String()
+			return cnabtooci.ImageMetadata{Reference: ref, Digest: ref.Digest()}, nil
+		}
+
+		var summary publishSummary
+		layout := mockRegistryLayout{expectedDigest: digest, hasError: true}
+		newMap, err := p.relocateImage(context.Background(), relocation.ImageRelocationMap{}, layout, originImg, tag, cnabtooci.RegistryOptions{}, &summary)
+		require.NoError(t, err, "the image should not be pushed")
+		require.Equal(t, tag+"@sha256:6b5a28ccbb76f12ce771a23757880c6083234255c5ba191fca1c5db1f71c1687", newMap[originImg])
+		assert.Equal(t, tag+"@"+digest.String(), checkedRef)
+		assert.Empty(t, summary.Uploaded)
+		assert.Equal(t, []string{tag + "@" + digest.String()}, summary.Skipped)
+	})
+}
+
+func TestPublish_pushInvocationImage(t *testing.T) {
+	ctx := context.Background()
+	imgRef := cnab.MustParseOCIReference("example.com/mybuns:v0.1.0")
+	localID := "sha256:2d6e6c8b3a2ca4c1a51e6ac5c6a23a4d1a0a8a3f8c91cbd1a4c1a91e8fbb24a7"
+	remoteDigest := digest.Digest("sha256:ea9d7c1d6bd0e8ba4c5ab1c8dfe3e0f06d6c5c5a7f09e5d7b4d5f8ad5c7e2d31")
+
+	testcases := []struct {
+		name         string
+		configDigest digest.Digest
+		wantSkipped  bool
+	}{
+		{name: "image exists at the destination", configDigest: digest.Digest(localID), wantSkipped: true},
+		{name: "image changed", configDigest: "sha256:7a4b5c36e1b0a0fd1f84e0b1b5d5dd0d3ce4f6f5a84d28b1c7d9ef9c4a1d3b52", wantSkipped: false},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewTestPorter(t)
+			defer p.Close()
+
+			p.TestRegistry.MockGetCachedImage = func(ctx context.Context, ref cnab.OCIReference) (cnabtooci.ImageSummary, error) {
+				return cnabtooci.NewImageSummary(ref.String(), types.ImageInspect{ID: localID})
+			}
+			p.TestRegistry.MockGetImageMetadata = func(ctx context.Context, ref cnab.OCIReference, opts cnabtooci.RegistryOptions) (cnabtooci.ImageMetadata, error) {
+				return cnabtooci.ImageMetadata{Reference: ref, Digest: remoteDigest, ConfigDigest: tc.configDigest}, nil
+			}
+
+			var summary publishSummary
+			gotDigest, err := p.pushInvocationImage(ctx, imgRef, cnabtooci.RegistryOptions{}, &summary)
+			require.NoError(t, err)
+
+			if tc.wantSkipped {
+				assert.Equal(t, remoteDigest, gotDigest, "the digest of the existing image should be used")
+				assert.Equal(t, []string{imgRef.String()}, summary.Skipped)
+				assert.Empty(t, summary.Uploaded)
+			} else {
+				assert.NotEqual(t, remoteDigest, gotDigest, "the digest of the pushed image should be used")
+				assert.Equal(t, []string{imgRef.String()}, summary.Uploaded)
+				assert.Empty(t, summary.Skipped)
+			}
 		})
 	}
 }
@@ -324,3 +401,32 @@ func TestPublish_ForceOverwrite(t *testing.T) {
 		})
 	}
 }
+
+func TestPublish_BundleUnchanged(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	existingDigest := digest.Digest("sha256:ea9d7c1d6bd0e8ba4c5ab1c8dfe3e0f06d6c5c5a7f09e5d7b4d5f8ad5c7e2d31")
+	p.TestRegistry.MockGetBundleMetadata = func(ctx context.Context, ref cnab.OCIReference, opts cnabtooci.RegistryOptions) (cnabtooci.BundleMetadata, error) {
+		return cnabtooci.BundleMetadata{BundleReference: cnab.BundleReference{Reference: ref, Digest: existingDigest}}, nil
+	}
+	p.TestRegistry.MockPushBundle = func(ctx context.Context, ref cnab.BundleReference, opts cnabtooci.RegistryOptions) (cnab.BundleReference, error) {
+		ref.Digest = existingDigest
+		return ref, nil
+	}
+
+	p.TestConfig.TestContext.AddTestDirectoryFromRoot("tests/testdata/mybuns", p.BundleDir)
+
+	opts := PublishOptions{}
+	opts.Force = true
+	require.NoError(t, opts.Validate(p.Config))
+
+	err := p.Publish(ctx, opts)
+	require.NoError(t, err, "Publish failed")
+
+	gotOutput := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, gotOutput, "1 images uploaded")
+	assert.Contains(t, gotOutput, "0 images skipped because they already exist in the destination registry")
+	assert.Contains(t, gotOutput, "bundle unchanged")
+}
@@ -7,6 +7,7 @@ import (
 	"sort"
 
 	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/printer"
 	"get.porter.sh/porter/pkg/storage"
 	"get.porter.sh/porter/pkg/tracing"
 	"get.porter.sh/porter/pkg/yaml"
@@ -24,6 +25,9 @@ type ReconcileOptions struct {
 
 	// DryRun only checks if the changes would trigger a bundle run
 	DryRun bool
+
+	// Format of the output when the bundle is executed, see ExecutionAllowedFormats.
+	Format printer.Format
 }
 
 // ReconcileInstallation compares the desired state of an installation
@@ -36,6 +40,15 @@ func (p *Porter) ReconcileInstallation(ctx context.Context, opts ReconcileOption
 	ctx, log := tracing.StartSpan(ctx)
 	log.Debugf("Reconciling %s/%s installation", opts.Namespace, opts.Name)
 
+	// Lock the installation before comparing it, so that it is not modified by another operation in the meantime
+	if !opts.DryRun {
+		unlock, err := p.lockInstallation(ctx, opts.Namespace, opts.Name, 0)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
 	// Get the last run of the installation, if available
 	var lastRun *storage.Run
 	r, err := p.Installations.GetLastRun(ctx, opts.Namespace, opts.Name)
@@ -74,6 +87,7 @@ func (p *Porter) ReconcileInstallation(ctx context.Context, opts ReconcileOption
 	lifecycleOpts.Namespace = opts.Namespace
 	lifecycleOpts.CredentialIdentifiers = opts.Installation.CredentialSets
 	lifecycleOpts.ParameterSets = opts.Installation.ParameterSets
+	lifecycleOpts.RawFormat = string(opts.Format)
 
 	if err = p.applyActionOptionsToInstallation(ctx, actionOpts, &opts.Installation); err != nil {
 		return err
@@ -195,7 +209,7 @@ func (p *Porter) IsInstallationInSync(ctx context.Context, i storage.Installatio
 		return compParams, nil
 	}
 
-	lastRunParams, err := p.Sanitizer.RestoreParameterSet(ctx, lastRun.Parameters, cnab.NewBundle(lastRun.Bundle))
+	lastRunParams, err := p.Sanitizer.RestoreParameterSet(ctx, lastRun.GetParameters(), cnab.NewBundle(lastRun.Bundle))
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,353 @@
+package porter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/Masterminds/semver/v3"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// RegistryWebhookPath is the path of the endpoint that receives the push
+// events of a registry and triggers the configured auto-upgrade rules.
+const RegistryWebhookPath = "/v1/webhooks/registry"
+
+const (
+	// RegistryWebhookHarbor is the source of events sent by a Harbor webhook.
+	RegistryWebhookHarbor = "harbor"
+
+	// RegistryWebhookACR is the source of events sent by an Azure Container Registry webhook.
+	RegistryWebhookACR = "acr"
+
+	// RegistryWebhookGHCR is the source of events sent by a GitHub package webhook.
+	RegistryWebhookGHCR = "ghcr"
+)
+
+// RegistryPushEvent is a tag that was pushed to a registry, parsed from the
+// payload of a registry webhook.
+type RegistryPushEvent struct {
+	// Source of the event: harbor, acr or ghcr.
+	Source string
+
+	// ID that the registry assigned to the event, when it provides one.
+	ID string
+
+	// Reference is the repository and tag that was pushed.
+	Reference cnab.OCIReference
+
+	// Digest of the pushed artifact.
+	Digest string
+}
+
+// harborPayload is the payload of a Harbor webhook.
+type harborPayload struct {
+	Type      string `json:"type"`
+	EventData struct {
+		Resources []struct {
+			Digest      string `json:"digest"`
+			Tag         string `json:"tag"`
+			ResourceURL string `json:"resource_url"`
+		} `json:"resources"`
+	} `json:"event_data"`
+}
+
+// acrPayload is the payload of an Azure Container Registry webhook.
+type acrPayload struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+	Target struct {
+		Digest     string `json:"digest"`
+		Repository string `json:"repository"`
+		Tag        string `json:"tag"`
+	} `json:"target"`
+	Request struct {
+		Host string `json:"host"`
+	} `json:"request"`
+}
+
+// githubPackage is the package of a GitHub package or registry_package webhook.
+type githubPackage struct {
+	PackageVersion struct {
+		PackageURL        string `json:"package_url"`
+		ContainerMetadata struct {
+			Tag struct {
+				Name   string `json:"name"`
+				Digest string `json:"digest"`
+			} `json:"tag"`
+		} `json:"container_metadata"`
+	} `json:"package_version"`
+}
+
+// githubPayload is the payload of a GitHub package or registry_package webhook.
+type githubPayload struct {
+	Action          string         `json:"action"`
+	Package         *githubPackage `json:"package"`
+	RegistryPackage *githubPackage `json:"registry_package"`
+}
+
+// ParseRegistryPushEvents parses the tags that were pushed from the payload of
+// a Harbor, Azure Container Registry or GitHub Container Registry webhook.
+// Events that are not a push of a tag, such as a deleted artifact, are ignored.
+func ParseRegistryPushEvents(header http.Header, body []byte) ([]RegistryPushEvent, error) {
+	if event := header.Get("X-GitHub-Event"); event != "" {
+		if event != "package" && event != "registry_package" {
+			return nil, nil
+		}
+		return parseGitHubPushEvents(header.Get("X-GitHub-Delivery"), body)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("invalid registry webhook payload: %w", err)
+	}
+	if _, ok := fields["event_data"]; ok {
+		return parseHarborPushEvents(body)
+	}
+	if _, ok := fields["target"]; ok {
+		return parseACRPushEvents(body)
+	}
+	return nil, errors.New("unsupported registry webhook payload, only Harbor, Azure Container Registry and GitHub Container Registry webhooks are supported")
+}
+
+func parseHarborPushEvents(body []byte) ([]RegistryPushEvent, error) {
+	var payload harborPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid Harbor webhook payload: %w", err)
+	}
+	if payload.Type != "PUSH_ARTIFACT" {
+		return nil, nil
+	}
+
+	events := make([]RegistryPushEvent, 0, len(payload.EventData.Resources))
+	for _, resource := range payload.EventData.Resources {
+		if resource.Tag == "" {
+			continue
+		}
+		ref, err := cnab.ParseOCIReference(resource.ResourceURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource_url in the Harbor webhook payload: %w", err)
+		}
+		if !ref.HasTag() {
+			if ref, err = ref.WithTag(resource.Tag); err != nil {
+				return nil, fmt.Errorf("invalid tag in the Harbor webhook payload: %w", err)
+			}
+		}
+		events = append(events, RegistryPushEvent{
+			Source:    RegistryWebhookHarbor,
+			Reference: ref,
+			Digest:    resource.Digest,
+		})
+	}
+	return events, nil
+}
+
+func parseACRPushEvents(body []byte) ([]RegistryPushEvent, error) {
+	var payload acrPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid Azure Container Registry webhook payload: %w", err)
+	}
+	if payload.Action != "push" || payload.Target.Tag == "" {
+		return nil, nil
+	}
+
+	ref, err := cnab.ParseOCIReference(fmt.Sprintf("%s/%s:%s", payload.Request.Host, payload.Target.Repository, payload.Target.Tag))
+	if err != nil {
+		return nil, fmt.Errorf("invalid target in the Azure Container Registry webhook payload: %w", err)
+	}
+	return []RegistryPushEvent{{
+		Source:    RegistryWebhookACR,
+		ID:        payload.ID,
+		Reference: ref,
+		Digest:    payload.Target.Digest,
+	}}, nil
+}
+
+func parseGitHubPushEvents(delivery string, body []byte) ([]RegistryPushEvent, error) {
+	var payload githubPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid GitHub webhook payload: %w", err)
+	}
+
+	pkg := payload.Package
+	if pkg == nil {
+		pkg = payload.RegistryPackage
+	}
+	if payload.Action != "published" || pkg == nil || pkg.PackageVersion.ContainerMetadata.Tag.Name == "" {
+		return nil, nil
+	}
+
+	ref, err := cnab.ParseOCIReference(pkg.PackageVersion.PackageURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid package_url in the GitHub webhook payload: %w", err)
+	}
+	tag := pkg.PackageVersion.ContainerMetadata.Tag
+	if ref, err = ref.WithTag(tag.Name); err != nil {
+		return nil, fmt.Errorf("invalid tag in the GitHub webhook payload: %w", err)
+	}
+	return []RegistryPushEvent{{
+		Source:    RegistryWebhookGHCR,
+		ID:        delivery,
+		Reference: ref,
+		Digest:    tag.Digest,
+	}}, nil
+}
+
+// AutoUpgrade is an installation that an auto-upgrade rule upgrades to a
+// pushed version of its bundle.
+type AutoUpgrade struct {
+	// Rule is the name of the auto-upgrade rule.
+	Rule string `json:"rule"`
+
+	// Namespace of the installation.
+	Namespace string `json:"namespace"`
+
+	// Installation name.
+	Installation string `json:"installation"`
+
+	// Reference of the bundle that the installation is upgraded to.
+	Reference string `json:"reference"`
+
+	// Trigger is recorded on the run of the upgrade.
+	Trigger storage.RunTrigger `json:"-"`
+}
+
+// FindAutoUpgrades returns the installations that the auto-upgrade rules
+// upgrade to the pushed tag. An installation is upgraded when it uses the
+// pushed repository, matches the namespace and selector of a rule, and the tag
+// is a version that satisfies the constraint of the rule and is newer than the
+// installed version. Each installation is upgraded at most once, by the first
+// rule that matches it.
+func (p *Porter) FindAutoUpgrades(ctx context.Context, event RegistryPushEvent, received time.Time) ([]AutoUpgrade, error) {
+	ctx, log := tracing.StartSpan(ctx, attribute.String("reference", event.Reference.String()))
+	defer log.EndSpan()
+
+	version, err := semver.NewVersion(event.Reference.Tag())
+	if err != nil {
+		log.Debugf("Ignoring the push of %s because the tag is not a version", event.Reference)
+		return nil, nil
+	}
+
+	var upgrades []AutoUpgrade
+	upgraded := make(map[string]bool)
+	for _, rule := range p.Config.Data.AutoUpgradeRules {
+		if rule.Constraint != "" {
+			constraint, err := semver.NewConstraint(rule.Constraint)
+			if err != nil {
+				return nil, log.Error(fmt.Errorf("invalid constraint %q in auto-upgrade rule %s: %w", rule.Constraint, rule.Name, err))
+			}
+			if !constraint.Check(version) {
+				continue
+			}
+		}
+
+		installations, err := p.listAutoUpgradeInstallations(ctx, rule)
+		if err != nil {
+			return nil, log.Error(err)
+		}
+
+		for _, inst := range installations {
+			if upgraded[inst.String()] || !inst.IsInstalled() || !usesRepository(inst, event.Reference) {
+				continue
+			}
+			if current, ok := getInstalledVersion(inst); ok && !version.GreaterThan(current) {
+				continue
+			}
+
+			upgraded[inst.String()] = true
+			upgrades = append(upgrades, AutoUpgrade{
+				Rule:         rule.Name,
+				Namespace:    inst.Namespace,
+				Installation: inst.Name,
+				Reference:    event.Reference.String(),
+				Trigger: storage.RunTrigger{
+					Type:      storage.RunTriggerRegistryPush,
+					Rule:      rule.Name,
+					Source:    event.Source,
+					EventID:   event.ID,
+					Reference: event.Reference.String(),
+					Digest:    event.Digest,
+					Received:  received,
+				},
+			})
+		}
+	}
+	return upgrades, nil
+}
+
+// listAutoUpgradeInstallations lists the installations in the namespace of the
+// rule that match its selector.
+func (p *Porter) listAutoUpgradeInstallations(ctx context.Context, rule config.AutoUpgradeRule) ([]storage.Installation, error) {
+	selector, err := storage.ParseLabelSelector(rule.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector in auto-upgrade rule %s: %w", rule.Name, err)
+	}
+
+	installations, err := p.Installations.ListInstallations(ctx, storage.ListOptions{
+		Namespace:     rule.Namespace,
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list the installations of auto-upgrade rule %s: %w", rule.Name, err)
+	}
+	return installations, nil
+}
+
+// usesRepository determines if the bundle of the installation is from the
+// repository of the reference.
+func usesRepository(inst storage.Installation, ref cnab.OCIReference) bool {
+	if inst.Bundle.Repository == "" {
+		return false
+	}
+	repo, err := cnab.ParseOCIReference(inst.Bundle.Repository)
+	if err != nil {
+		return false
+	}
+	return repo.Named.Name() == ref.Named.Name()
+}
+
+// getInstalledVersion returns the version of the bundle that last altered the
+// installation, falling back to the version or tag that the installation
+// references when it has not been recorded.
+func getInstalledVersion(inst storage.Installation) (*semver.Version, bool) {
+	for _, value := range []string{inst.Status.BundleVersion, inst.Bundle.Version, inst.Bundle.Tag} {
+		if value == "" {
+			continue
+		}
+		if v, err := semver.NewVersion(value); err == nil {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// RunAutoUpgrade upgrades the installation to the pushed version of its
+// bundle, recording the push event as the trigger of the run.
+func (p *Porter) RunAutoUpgrade(ctx context.Context, upgrade AutoUpgrade) error {
+	ctx, log := tracing.StartSpan(ctx,
+		attribute.String("rule", upgrade.Rule),
+		attribute.String("installation", upgrade.Namespace+"/"+upgrade.Installation),
+		attribute.String("reference", upgrade.Reference))
+	defer log.EndSpan()
+
+	log.Infof("Upgrading installation %s/%s to %s for auto-upgrade rule %s", upgrade.Namespace, upgrade.Installation, upgrade.Reference, upgrade.Rule)
+
+	opts := NewUpgradeOptions()
+	opts.Namespace = upgrade.Namespace
+	opts.Name = upgrade.Installation
+	opts.Reference = upgrade.Reference
+	trigger := upgrade.Trigger
+	opts.trigger = &trigger
+	if err := opts.Validate(ctx, nil, p); err != nil {
+		return log.Error(err)
+	}
+
+	return log.Error(p.UpgradeBundle(ctx, opts))
+}
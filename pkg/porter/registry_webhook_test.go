@@ -0,0 +1,212 @@
+package porter
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	harborPushPayload = `{"type":"PUSH_ARTIFACT","occur_at":1680000000,"operator":"admin","event_data":{"resources":[{"digest":"sha256:aaa","tag":"v1.2.0","resource_url":"harbor.example.com/library/mybuns:v1.2.0"}],"repository":{"name":"mybuns","namespace":"library","repo_full_name":"library/mybuns"}}}`
+	acrPushPayload    = `{"id":"cb8c3971-9adc-488b-xxxx-43cbb4974ff5","timestamp":"2023-03-28T20:00:00Z","action":"push","target":{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:bbb","repository":"mybuns","tag":"v1.2.0"},"request":{"id":"3cbb6949-7549-4fa1-xxxx-a6d5451dffc7","host":"myregistry.azurecr.io","method":"PUT"}}`
+	ghcrPushPayload   = `{"action":"published","package":{"name":"mybuns","package_type":"CONTAINER","package_version":{"version":"sha256:ccc","package_url":"ghcr.io/getporter/mybuns:v1.2.0","container_metadata":{"tag":{"name":"v1.2.0","digest":"sha256:ccc"}}}}}`
+)
+
+func TestParseRegistryPushEvents(t *testing.T) {
+	testcases := []struct {
+		name       string
+		header     http.Header
+		body       string
+		wantSource string
+		wantID     string
+		wantRef    string
+		wantDigest string
+		wantNone   bool
+		wantErr    string
+	}{
+		{name: "harbor", body: harborPushPayload, wantSource: RegistryWebhookHarbor, wantRef: "harbor.example.com/library/mybuns:v1.2.0", wantDigest: "sha256:aaa"},
+		{name: "acr", body: acrPushPayload, wantSource: RegistryWebhookACR, wantID: "cb8c3971-9adc-488b-xxxx-43cbb4974ff5", wantRef: "myregistry.azurecr.io/mybuns:v1.2.0", wantDigest: "sha256:bbb"},
+		{name: "ghcr", header: http.Header{"X-Github-Event": {"package"}, "X-Github-Delivery": {"72d3162e"}}, body: ghcrPushPayload,
+			wantSource: RegistryWebhookGHCR, wantID: "72d3162e", wantRef: "ghcr.io/getporter/mybuns:v1.2.0", wantDigest: "sha256:ccc"},
+		{name: "harbor delete", body: `{"type":"DELETE_ARTIFACT","event_data":{"resources":[{"tag":"v1.2.0","resource_url":"harbor.example.com/library/mybuns:v1.2.0"}]}}`, wantNone: true},
+		{name: "acr untagged push", body: `{"action":"push","target":{"repository":"mybuns","digest":"sha256:bbb"},"request":{"host":"myregistry.azurecr.io"}}`, wantNone: true},
+		{name: "github ping", header: http.Header{"X-Github-Event": {"ping"}}, body: `{"zen":"Keep it logically awesome."}`, wantNone: true},
+		{name: "unsupported", body: `{"repository":"mybuns"}`, wantErr: "unsupported registry webhook payload"},
+		{name: "invalid json", body: `{`, wantErr: "invalid registry webhook payload"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			events, err := ParseRegistryPushEvents(tc.header, []byte(tc.body))
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tc.wantNone {
+				assert.Empty(t, events)
+				return
+			}
+
+			require.Len(t, events, 1)
+			assert.Equal(t, tc.wantSource, events[0].Source)
+			assert.Equal(t, tc.wantID, events[0].ID)
+			assert.Equal(t, tc.wantRef, events[0].Reference.String())
+			assert.Equal(t, tc.wantDigest, events[0].Digest)
+		})
+	}
+}
+
+func TestPorter_FindAutoUpgrades(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	p.Config.Data.AutoUpgradeRules = []config.AutoUpgradeRule{
+		{Name: "dev-minor", Namespace: "dev", Constraint: "~1.2"},
+		{Name: "any-web", Namespace: "*", Selector: []string{"tier=web"}},
+	}
+
+	installed := time.Now()
+	createInstallation := func(namespace string, name string, repository string, version string, labels map[string]string) {
+		p.TestInstallations.CreateInstallation(storage.NewInstallation(namespace, name), func(i *storage.Installation) {
+			i.Bundle.Repository = repository
+			i.Status.BundleVersion = version
+			i.Status.Installed = &installed
+			i.Labels = labels
+		})
+	}
+	createInstallation("dev", "mybuns", "ghcr.io/getporter/mybuns", "1.1.0", nil)
+	createInstallation("dev", "current", "ghcr.io/getporter/mybuns", "1.2.0", nil)
+	createInstallation("dev", "other-repo", "ghcr.io/getporter/other", "1.1.0", nil)
+	createInstallation("prod", "web", "ghcr.io/getporter/mybuns", "1.0.0", map[string]string{"tier": "web"})
+	createInstallation("prod", "db", "ghcr.io/getporter/mybuns", "1.0.0", map[string]string{"tier": "db"})
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "not-installed"), func(i *storage.Installation) {
+		i.Bundle.Repository = "ghcr.io/getporter/mybuns"
+	})
+
+	events, err := ParseRegistryPushEvents(http.Header{"X-Github-Event": {"package"}, "X-Github-Delivery": {"72d3162e"}}, []byte(ghcrPushPayload))
+	require.NoError(t, err)
+	received := time.Date(2026, time.March, 4, 10, 0, 0, 0, time.UTC)
+
+	upgrades, err := p.FindAutoUpgrades(ctx, events[0], received)
+	require.NoError(t, err)
+	require.Len(t, upgrades, 2)
+	assert.Equal(t, AutoUpgrade{
+		Rule:         "dev-minor",
+		Namespace:    "dev",
+		Installation: "mybuns",
+		Reference:    "ghcr.io/getporter/mybuns:v1.2.0",
+		Trigger: storage.RunTrigger{
+			Type:      storage.RunTriggerRegistryPush,
+			Rule:      "dev-minor",
+			Source:    RegistryWebhookGHCR,
+			EventID:   "72d3162e",
+			Reference: "ghcr.io/getporter/mybuns:v1.2.0",
+			Digest:    "sha256:ccc",
+			Received:  received,
+		},
+	}, upgrades[0])
+	assert.Equal(t, "any-web", upgrades[1].Rule)
+	assert.Equal(t, "prod/web", upgrades[1].Namespace+"/"+upgrades[1].Installation)
+
+	t.Run("tag is not a version", func(t *testing.T) {
+		event := events[0]
+		event.Reference, err = event.Reference.WithTag("latest")
+		require.NoError(t, err)
+
+		upgrades, err := p.FindAutoUpgrades(ctx, event, received)
+		require.NoError(t, err)
+		assert.Empty(t, upgrades)
+	})
+
+	t.Run("invalid constraint", func(t *testing.T) {
+		p.Config.Data.AutoUpgradeRules = []config.AutoUpgradeRule{{Name: "broken", Constraint: "not a constraint"}}
+		_, err := p.FindAutoUpgrades(ctx, events[0], received)
+		require.ErrorContains(t, err, `invalid constraint "not a constraint" in auto-upgrade rule broken`)
+	})
+}
+
+func TestPorter_RunAutoUpgrade(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybuns"))
+	_, err := p.Installations.AcquireInstallationLock(ctx, "dev", "mybuns", "alice", time.Minute)
+	require.NoError(t, err)
+
+	err = p.RunAutoUpgrade(ctx, AutoUpgrade{Rule: "dev-minor", Namespace: "dev", Installation: "mybuns", Reference: "ghcr.io/getporter/mybuns:v1.2.0"})
+	require.ErrorIs(t, err, storage.ErrInstallationLocked, "the installation should be upgraded")
+}
+
+func TestAPIHandler_RegistryWebhook(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	srv := httptest.NewServer(p.NewAPIHandler(context.Background(), "abc123"))
+	defer srv.Close()
+
+	post := func(header http.Header, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, srv.URL+RegistryWebhookPath, strings.NewReader(body))
+		require.NoError(t, err)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	sign := func(body string) string {
+		mac := hmac.New(sha256.New, []byte("abc123"))
+		mac.Write([]byte(body))
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	t.Run("unauthorized", func(t *testing.T) {
+		resp := post(http.Header{"Authorization": {"Bearer wrong"}}, harborPushPayload)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		resp := post(http.Header{"X-Github-Event": {"package"}, "X-Hub-Signature-256": {sign("tampered")}}, ghcrPushPayload)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("signed payload", func(t *testing.T) {
+		resp := post(http.Header{"X-Github-Event": {"package"}, "X-Hub-Signature-256": {sign(ghcrPushPayload)}}, ghcrPushPayload)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		var got RegistryWebhookResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Empty(t, got.Upgrades, "no auto-upgrade rules are configured")
+	})
+
+	t.Run("payload too large", func(t *testing.T) {
+		resp := post(http.Header{"Authorization": {"Bearer abc123"}}, strings.Repeat(" ", maxAPIRequestSize+1))
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+
+	t.Run("unsupported payload", func(t *testing.T) {
+		resp := post(http.Header{"Authorization": {"Bearer abc123"}}, `{"repository":"mybuns"}`)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,294 @@
+package porter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/failure"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// RemoteRunsPath is the path of the porter api serve endpoint that runs a bundle.
+const RemoteRunsPath = "/v1/runs"
+
+// RemoteRunRequest asks porter api serve to run a bundle, with the options of
+// the porter install, upgrade, invoke or uninstall command. Parameter and
+// credential sets are resolved by the server.
+type RemoteRunRequest struct {
+	// Command that runs the bundle: install, upgrade, invoke or uninstall.
+	Command string `json:"command"`
+
+	// Action to invoke, only used by the invoke command.
+	Action string `json:"action,omitempty"`
+
+	// Namespace of the installation.
+	Namespace string `json:"namespace"`
+
+	// Installation name.
+	Installation string `json:"installation"`
+
+	// Reference to the bundle in an OCI registry.
+	Reference string `json:"reference,omitempty"`
+
+	// Version of the bundle to upgrade to, only used by the upgrade command.
+	Version string `json:"version,omitempty"`
+
+	// Params are the parameters in the form NAME=VALUE.
+	Params []string `json:"params,omitempty"`
+
+	// ParameterSets are the names of the parameter sets to use.
+	ParameterSets []string `json:"parameterSets,omitempty"`
+
+	// CredentialSets are the names of the credential sets to use.
+	CredentialSets []string `json:"credentialSets,omitempty"`
+
+	// Labels to apply to the installation and its run.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Timeout is the maximum amount of time that the bundle may run, for example 30m.
+	Timeout string `json:"timeout,omitempty"`
+
+	// ChangeTicket is the ID of a ticket that is updated with the outcome of the run.
+	ChangeTicket string `json:"changeTicket,omitempty"`
+
+	// EphemeralOutputs are the names of outputs that are reported but never saved.
+	EphemeralOutputs []string `json:"ephemeralOutputs,omitempty"`
+
+	// RestoreSnapshot is the name of a snapshot of the installation whose
+	// outputs are used by the run, only used by upgrade and invoke.
+	RestoreSnapshot string `json:"restoreSnapshot,omitempty"`
+
+	// Delete the installation after it is uninstalled, only used by the uninstall command.
+	Delete bool `json:"delete,omitempty"`
+
+	// ForceDelete deletes the installation even when uninstall fails, only
+	// used by the uninstall command.
+	ForceDelete bool `json:"forceDelete,omitempty"`
+}
+
+// validateRemote validates the options when the bundle is run by a remote
+// porter api serve instance. The bundle, parameter and credential sets are
+// resolved by the server, so they are not validated locally.
+func (o *BundleExecutionOptions) validateRemote(args []string) error {
+	u, err := url.Parse(o.Remote)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid --remote %s: must be the http or https url of a porter api serve instance", o.Remote)
+	}
+
+	if err := o.validateInstallationName(args); err != nil {
+		return err
+	}
+	if o.File != "" || o.CNABFile != "" {
+		return errors.New("--file and --cnab-file are not supported with --remote, publish the bundle and use --reference instead")
+	}
+	if o.Name == "" {
+		return errors.New("an installation name is required with --remote")
+	}
+
+	return o.PrintOptions.Validate(ExecutionDefaultFormat, ExecutionAllowedFormats)
+}
+
+// newRemoteRunRequest describes the bundle action for porter api serve.
+func newRemoteRunRequest(command string, action BundleAction) RemoteRunRequest {
+	opts := action.GetOptions()
+	req := RemoteRunRequest{
+		Command:          command,
+		Namespace:        opts.Namespace,
+		Installation:     opts.Name,
+		Reference:        opts.Reference,
+		Params:           opts.Params,
+		ParameterSets:    opts.ParameterSets,
+		CredentialSets:   opts.CredentialIdentifiers,
+		ChangeTicket:     opts.ChangeTicket,
+		EphemeralOutputs: opts.EphemeralOutputs,
+		RestoreSnapshot:  opts.RestoreSnapshot,
+	}
+	if opts.Timeout > 0 {
+		req.Timeout = opts.Timeout.String()
+	}
+	if labeled, ok := action.(labeledAction); ok {
+		req.Labels = labeled.ParseLabels()
+	}
+
+	switch a := action.(type) {
+	case InvokeOptions:
+		req.Action = a.Action
+	case *UpgradeOptions:
+		req.Version = a.Version
+	case UninstallOptions:
+		req.Delete = a.Delete
+		req.ForceDelete = a.ForceDelete
+	}
+	return req
+}
+
+// NewAction converts the request into the options of the command that runs
+// the bundle. The options must be validated before they are used.
+func (r RemoteRunRequest) NewAction() (BundleAction, error) {
+	var action BundleAction
+	switch r.Command {
+	case cnab.ActionInstall:
+		opts := NewInstallOptions()
+		opts.Labels = formatLabels(r.Labels)
+		action = opts
+	case cnab.ActionUpgrade:
+		opts := NewUpgradeOptions()
+		opts.Version = r.Version
+		opts.Labels = formatLabels(r.Labels)
+		action = opts
+	case "invoke":
+		opts := NewInvokeOptions()
+		opts.Action = r.Action
+		action = opts
+	case cnab.ActionUninstall:
+		opts := NewUninstallOptions()
+		opts.Delete = r.Delete
+		opts.ForceDelete = r.ForceDelete
+		action = opts
+	default:
+		return nil, fmt.Errorf("invalid command %q, allowed values are: install, upgrade, invoke, uninstall", r.Command)
+	}
+
+	opts := action.GetOptions()
+	opts.Namespace = r.Namespace
+	opts.Name = r.Installation
+	opts.Reference = r.Reference
+	opts.Params = r.Params
+	opts.ParameterSets = r.ParameterSets
+	opts.CredentialIdentifiers = r.CredentialSets
+	opts.ChangeTicket = r.ChangeTicket
+	opts.EphemeralOutputs = r.EphemeralOutputs
+	opts.RestoreSnapshot = r.RestoreSnapshot
+	opts.Format = printer.FormatNdjson
+	if r.Timeout != "" {
+		timeout, err := time.ParseDuration(r.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout %q: %w", r.Timeout, err)
+		}
+		opts.Timeout = timeout
+		opts.TimeoutSpecified = true
+	}
+	return action, nil
+}
+
+// formatLabels converts labels into the KEY=VALUE form used by the --label flag.
+func formatLabels(labels map[string]string) []string {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	raw := make([]string, 0, len(labels))
+	for k, v := range labels {
+		raw = append(raw, k+"="+v)
+	}
+	sort.Strings(raw)
+	return raw
+}
+
+// executeRemote submits the bundle action to the porter api serve instance
+// specified with --remote, and reports the events of the run as they are
+// streamed back. When the format is ndjson the events are printed as is,
+// otherwise the logs of the bundle are printed.
+func (p *Porter) executeRemote(ctx context.Context, command string, action BundleAction) error {
+	opts := action.GetOptions()
+	ctx, log := tracing.StartSpan(ctx, attribute.String("remote", opts.Remote))
+	defer log.EndSpan()
+
+	body, err := json.Marshal(newRemoteRunRequest(command, action))
+	if err != nil {
+		return log.Error(fmt.Errorf("error marshaling the remote run request: %w", err))
+	}
+
+	endpoint := strings.TrimSuffix(opts.Remote, "/") + RemoteRunsPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return log.Error(fmt.Errorf("error creating the remote run request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if opts.RemoteToken != "" {
+		req.Header.Set("Authorization", "Bearer "+opts.RemoteToken)
+	}
+
+	log.Debugf("Submitting the %s of %s/%s to %s", command, opts.Namespace, opts.Name, opts.Remote)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return log.Error(fmt.Errorf("could not connect to the remote porter server at %s: %w", opts.Remote, err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		err = fmt.Errorf("the remote porter server at %s returned %s: %s", opts.Remote, resp.Status, strings.TrimSpace(string(msg)))
+		if resp.StatusCode == http.StatusBadRequest {
+			err = failure.Validation(err)
+		}
+		return log.Error(err)
+	}
+
+	return log.Error(p.printRemoteEvents(resp.Body, opts.Format == printer.FormatNdjson))
+}
+
+// printRemoteEvents reports the events streamed by porter api serve, and
+// returns an error when the command failed on the server.
+func (p *Porter) printRemoteEvents(events io.Reader, printEvents bool) error {
+	var completed bool
+	var runErr error
+	scanner := bufio.NewScanner(events)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if printEvents {
+			fmt.Fprintln(p.Out, line)
+		}
+
+		var event storage.RunEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			if !printEvents {
+				fmt.Fprintln(p.Out, line)
+			}
+			continue
+		}
+
+		switch event.Type {
+		case storage.RunEventLog:
+			if printEvents {
+				continue
+			}
+			if event.Stream == "stderr" {
+				fmt.Fprintln(p.Err, event.Message)
+			} else {
+				fmt.Fprintln(p.Out, event.Message)
+			}
+		case storage.RunEventResult:
+			completed = true
+		case storage.RunEventError:
+			completed = true
+			runErr = failure.Execution(fmt.Errorf("the remote run failed: %s", event.Error))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading the events of the remote run: %w", err)
+	}
+
+	if runErr != nil {
+		return runErr
+	}
+	if !completed {
+		return errors.New("the connection to the remote porter server closed before the run completed, use porter installation runs on the server to check the status of the run")
+	}
+	return nil
+}
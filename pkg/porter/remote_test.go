@@ -0,0 +1,297 @@
+package porter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/failure"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleExecutionOptions_validateRemote(t *testing.T) {
+	testcases := []struct {
+		name    string
+		remote  string
+		args    []string
+		file    string
+		wantErr string
+	}{
+		{name: "valid", remote: "https://porter.example.com", args: []string{"mybuns"}},
+		{name: "invalid url", remote: "porter.example.com", args: []string{"mybuns"}, wantErr: "invalid --remote porter.example.com"},
+		{name: "missing name", remote: "https://porter.example.com", wantErr: "an installation name is required with --remote"},
+		{name: "local bundle", remote: "https://porter.example.com", args: []string{"mybuns"}, file: "porter.yaml", wantErr: "--file and --cnab-file are not supported with --remote"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewTestPorter(t)
+			defer p.Close()
+
+			opts := NewUpgradeOptions()
+			opts.Remote = tc.remote
+			opts.File = tc.file
+			err := opts.Validate(context.Background(), tc.args, p.Porter)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, "mybuns", opts.Name)
+			} else {
+				require.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRemoteRunRequest_NewAction(t *testing.T) {
+	opts := NewUpgradeOptions()
+	opts.Namespace = "prod"
+	opts.Name = "mysql"
+	opts.Version = "1.2.3"
+	opts.Params = []string{"replicas=3"}
+	opts.ParameterSets = []string{"mysql"}
+	opts.CredentialIdentifiers = []string{"prod-kubeconfig"}
+	opts.Labels = []string{"team=data", "env=prod"}
+	opts.Timeout = 30 * time.Minute
+	opts.ChangeTicket = "CHG0001"
+
+	req := newRemoteRunRequest(cnab.ActionUpgrade, opts)
+	data, err := json.Marshal(req)
+	require.NoError(t, err)
+	var got RemoteRunRequest
+	require.NoError(t, json.Unmarshal(data, &got))
+
+	action, err := got.NewAction()
+	require.NoError(t, err)
+	upgrade, ok := action.(*UpgradeOptions)
+	require.True(t, ok, "expected upgrade options, got %T", action)
+	assert.Equal(t, "prod", upgrade.Namespace)
+	assert.Equal(t, "mysql", upgrade.Name)
+	assert.Equal(t, "1.2.3", upgrade.Version)
+	assert.Equal(t, opts.Params, upgrade.Params)
+	assert.Equal(t, opts.ParameterSets, upgrade.ParameterSets)
+	assert.Equal(t, opts.CredentialIdentifiers, upgrade.CredentialIdentifiers)
+	assert.Equal(t, []string{"env=prod", "team=data"}, upgrade.Labels)
+	assert.Equal(t, 30*time.Minute, upgrade.Timeout)
+	assert.Equal(t, "CHG0001", upgrade.ChangeTicket)
+	assert.Equal(t, printer.FormatNdjson, upgrade.Format, "the server should report the events of the run")
+
+	_, err = RemoteRunRequest{Command: "explain"}.NewAction()
+	require.ErrorContains(t, err, `invalid command "explain"`)
+}
+
+func TestPorter_executeRemote(t *testing.T) {
+	var gotAuth string
+	var gotReq RemoteRunRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&gotReq))
+
+		events := []storage.RunEvent{
+			{Type: storage.RunEventStarted},
+			{Type: storage.RunEventLog, Stream: "stdout", Message: "Installing mysql"},
+			{Type: storage.RunEventLog, Stream: "stderr", Message: "warning: deprecated chart"},
+			{Type: storage.RunEventResult, Status: cnab.StatusSucceeded},
+		}
+		for _, event := range events {
+			data, _ := json.Marshal(event)
+			fmt.Fprintln(w, string(data))
+		}
+	}))
+	defer srv.Close()
+
+	t.Run("plaintext", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := NewInstallOptions()
+		opts.Remote = srv.URL + "/"
+		opts.RemoteToken = "abc123"
+		opts.Reference = "example.com/mysql:v1.0.0"
+		require.NoError(t, opts.Validate(context.Background(), []string{"mysql"}, p.Porter))
+
+		err := p.InstallBundle(context.Background(), opts)
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer abc123", gotAuth)
+		assert.Equal(t, cnab.ActionInstall, gotReq.Command)
+		assert.Equal(t, "mysql", gotReq.Installation)
+		assert.Equal(t, "example.com/mysql:v1.0.0", gotReq.Reference)
+		assert.Equal(t, "Installing mysql\n", p.TestConfig.TestContext.GetOutput())
+		assert.Contains(t, p.TestConfig.TestContext.GetError(), "warning: deprecated chart")
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := NewInstallOptions()
+		opts.Remote = srv.URL
+		opts.Reference = "example.com/mysql:v1.0.0"
+		opts.RawFormat = string(printer.FormatNdjson)
+		require.NoError(t, opts.Validate(context.Background(), []string{"mysql"}, p.Porter))
+
+		err := p.InstallBundle(context.Background(), opts)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(p.TestConfig.TestContext.GetOutput()), "\n")
+		assert.Len(t, lines, 4, "the events should be printed as is")
+	})
+}
+
+func TestPorter_printRemoteEvents(t *testing.T) {
+	t.Run("error event", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		events := `{"type":"result","status":"failed"}
+{"type":"error","error":"the migrate step failed"}
+`
+		err := p.printRemoteEvents(strings.NewReader(events), false)
+		require.ErrorContains(t, err, "the remote run failed: the migrate step failed")
+		assert.True(t, failure.Is(err, failure.ClassExecution))
+	})
+
+	t.Run("disconnected", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		events := `{"type":"started"}
+`
+		err := p.printRemoteEvents(strings.NewReader(events), false)
+		require.ErrorContains(t, err, "closed before the run completed")
+	})
+}
+
+func TestAPIServeOptions_Validate(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	opts := APIServeOptions{}
+	require.ErrorContains(t, opts.Validate(p.Porter), "--token is required")
+
+	p.Data.APIToken = "abc123"
+	require.NoError(t, opts.Validate(p.Porter))
+	assert.Equal(t, "abc123", opts.Token, "the token should default to the api-token config setting")
+	assert.Equal(t, DefaultAPIListenAddress, opts.Listen)
+
+	opts = APIServeOptions{Token: "xyz789"}
+	require.NoError(t, opts.Validate(p.Porter))
+	assert.Equal(t, "xyz789", opts.Token, "--token should take precedence over the config setting")
+}
+
+func TestAPIHandler(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	srv := httptest.NewServer(p.NewAPIHandler(context.Background(), "abc123"))
+	defer srv.Close()
+
+	post := func(token string, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, srv.URL+RemoteRunsPath, strings.NewReader(body))
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("unauthorized", func(t *testing.T) {
+		resp := post("wrong", `{"command":"install"}`)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		resp := post("abc123", `{"command":"invoke","installation":"mysql"}`)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		msg, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(msg), "--action is required")
+	})
+
+	t.Run("request too large", func(t *testing.T) {
+		body := `{"command":"install","labels":{"padding":"` + strings.Repeat("a", maxAPIRequestSize) + `"}}`
+		resp := post("abc123", body)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+
+	t.Run("failed run", func(t *testing.T) {
+		client := NewTestPorter(t)
+		defer client.Close()
+
+		opts := NewUninstallOptions()
+		opts.Remote = srv.URL
+		opts.RemoteToken = "abc123"
+		require.NoError(t, opts.Validate(context.Background(), []string{"missing"}, client.Porter))
+
+		err := client.UninstallBundle(context.Background(), opts)
+		require.ErrorContains(t, err, "could not find installation /missing")
+	})
+
+	t.Run("validation error is reported to the client", func(t *testing.T) {
+		client := NewTestPorter(t)
+		defer client.Close()
+
+		opts := NewInvokeOptions()
+		opts.Action = "status"
+		opts.Remote = srv.URL
+		opts.RemoteToken = "abc123"
+		opts.ChangeTicket = "CHG0001"
+		require.NoError(t, opts.Validate(context.Background(), []string{"mysql"}, client.Porter))
+
+		err := client.InvokeBundle(context.Background(), opts)
+		require.ErrorContains(t, err, "400 Bad Request")
+		assert.True(t, failure.Is(err, failure.ClassValidation))
+	})
+}
+
+func TestAPIHandler_AuthorizesAsAPISubject(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	// The user running the server may use every namespace, the clients of
+	// the api may only use dev
+	p.Data.Authorization.Subject = "admin"
+	p.Data.Authorization.APISubject = "remote-ci"
+	p.Data.Authorization.Rules = []config.AuthorizationRule{
+		{Subjects: []string{"admin"}},
+		{Subjects: []string{"remote-ci"}, Namespaces: []string{"dev"}},
+	}
+
+	srv := httptest.NewServer(p.NewAPIHandler(context.Background(), "abc123"))
+	defer srv.Close()
+
+	uninstall := func(namespace string) error {
+		client := NewTestPorter(t)
+		defer client.Close()
+
+		opts := NewUninstallOptions()
+		opts.Namespace = namespace
+		opts.Remote = srv.URL
+		opts.RemoteToken = "abc123"
+		require.NoError(t, opts.Validate(context.Background(), []string{"missing"}, client.Porter))
+		return client.UninstallBundle(context.Background(), opts)
+	}
+
+	t.Run("allowed namespace", func(t *testing.T) {
+		err := uninstall("dev")
+		require.ErrorContains(t, err, "could not find installation dev/missing")
+	})
+
+	t.Run("denied namespace", func(t *testing.T) {
+		err := uninstall("prod")
+		require.ErrorContains(t, err, "access denied: remote-ci is not allowed", "the client should be authorized as the api-subject, not as the user running the server")
+	})
+}
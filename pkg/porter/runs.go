@@ -2,11 +2,19 @@ package porter
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/failure"
 	"get.porter.sh/porter/pkg/portercontext"
 	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
 	dtprinter "github.com/carolynvs/datetime-printer"
 )
 
@@ -14,8 +22,39 @@ import (
 type RunListOptions struct {
 	installationOptions
 	printer.PrintOptions
+
+	// Labels used to filter the runs, formatted as KEY=VALUE, KEY!=VALUE, KEY or !KEY.
+	Labels []string
+
+	// Action limits the runs to those that executed the action.
+	Action string
+
+	// Status limits the runs to those whose most recent result has the status.
+	Status string
+
+	// RawSince is the unparsed --since flag.
+	RawSince string
+
+	// Since limits the runs to those created at or after the time.
+	Since time.Time
+
+	// RawUntil is the unparsed --until flag.
+	RawUntil string
+
+	// Until limits the runs to those created before the time.
+	Until time.Time
+
+	// Skip is the number of runs to skip, counting back from the most recent run.
+	Skip int64
+
+	// Limit is the maximum number of runs to list.
+	Limit int64
 }
 
+// runStatuses are the statuses that may be used to filter runs.
+var runStatuses = []string{cnab.StatusSucceeded, cnab.StatusFailed, cnab.StatusCanceled,
+	cnab.StatusRunning, cnab.StatusPending, cnab.StatusUnknown, cnab.StatusTimedOut}
+
 // Validate prepares for the list installation runs action and validates the args/options.
 func (so *RunListOptions) Validate(args []string, cxt *portercontext.Context) error {
 	// Ensure only one argument exists (installation name) if args length non-zero
@@ -29,9 +68,54 @@ func (so *RunListOptions) Validate(args []string, cxt *portercontext.Context) er
 		return err
 	}
 
+	if _, err = storage.ParseLabelSelector(so.Labels); err != nil {
+		return err
+	}
+
+	if so.Status != "" && !stringSliceContains(runStatuses, so.Status) {
+		return fmt.Errorf("invalid --status value %q, allowed values are: %s", so.Status, strings.Join(runStatuses, ", "))
+	}
+
+	now := time.Now()
+	if so.RawSince != "" {
+		if so.Since, err = parseRunTime("--since", so.RawSince, now); err != nil {
+			return err
+		}
+	}
+	if so.RawUntil != "" {
+		if so.Until, err = parseRunTime("--until", so.RawUntil, now); err != nil {
+			return err
+		}
+	}
+	if !so.Since.IsZero() && !so.Until.IsZero() && !so.Since.Before(so.Until) {
+		return errors.New("--since must be before --until")
+	}
+
+	if so.Skip < 0 {
+		return errors.New("--skip must not be negative")
+	}
+	if so.Limit < 0 {
+		return errors.New("--limit must not be negative")
+	}
+
 	return so.PrintOptions.Validate(ShowDefaultFormat, ShowAllowedFormats)
 }
 
+// parseRunTime parses a point in time formatted as an RFC3339 timestamp, a
+// date (YYYY-MM-DD) in local time, or a duration before now such as 12h or 7d.
+func parseRunTime(flag string, value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if d, err := parseSince(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid %s value %q, expected a timestamp such as 2022-01-31T15:04:05Z, a date such as 2022-01-31, or a duration such as 12h or 7d", flag, value)
+}
+
 type DisplayRuns []DisplayRun
 
 func (l DisplayRuns) Len() int {
@@ -54,36 +138,56 @@ func (p *Porter) ListInstallationRuns(ctx context.Context, opts RunListOptions)
 
 	var displayRuns DisplayRuns
 
-	runs, runResults, err := p.Installations.ListRuns(ctx, opts.Namespace, opts.Name)
+	selector, err := storage.ParseLabelSelector(opts.Labels)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, run := range runs {
-		results := runResults[run.ID]
+	runs, runResults, err := p.Installations.ListRunsWithOptions(ctx, storage.ListRunsOptions{
+		Namespace:     opts.Namespace,
+		Installation:  opts.Name,
+		Action:        opts.Action,
+		Status:        opts.Status,
+		Since:         opts.Since,
+		Until:         opts.Until,
+		LabelSelector: selector,
+		Skip:          opts.Skip,
+		Limit:         opts.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
 
+	now := time.Now()
+	for _, run := range runs {
 		displayRun := NewDisplayRun(run)
-
-		if len(results) > 0 {
-			displayRun.Status = results[len(results)-1].Status
-
-			switch len(results) {
-			case 2:
-				displayRun.Started = results[0].Created
-				displayRun.Stopped = &results[1].Created
-			case 1:
-				displayRun.Started = results[0].Created
-			default:
-				displayRun.Stopped = &results[len(results)-1].Created
-			}
-		}
-
+		displayRun.setResults(runResults[run.ID])
+		displayRun.Stale = run.IsStale(displayRun.Status, now, storage.RunStaleTimeout)
 		displayRuns = append(displayRuns, displayRun)
 	}
 
 	return displayRuns, nil
 }
 
+// setResults updates the status and timing of the run from its results.
+func (r *DisplayRun) setResults(results []storage.Result) {
+	if len(results) == 0 {
+		return
+	}
+
+	r.Status = results[len(results)-1].Status
+
+	switch len(results) {
+	case 2:
+		r.Started = results[0].Created
+		r.Stopped = &results[1].Created
+	case 1:
+		r.Started = results[0].Created
+	default:
+		r.Stopped = &results[len(results)-1].Created
+	}
+}
+
 func (p *Porter) PrintInstallationRuns(ctx context.Context, opts RunListOptions) error {
 	displayRuns, err := p.ListInstallationRuns(ctx, opts)
 	if err != nil {
@@ -115,10 +219,864 @@ func (p *Porter) PrintInstallationRuns(ctx context.Context, opts RunListOptions)
 					stopped = tp.Format(*a.Stopped)
 				}
 
-				return []string{a.ID, a.Action, tp.Format(a.Started), stopped, a.Status}
+				status := a.Status
+				if a.Stale {
+					status += " (stale)"
+				}
+
+				return []string{a.ID, a.Action, tp.Format(a.Started), stopped, status}
 			}
 		return printer.PrintTable(p.Out, displayRuns, row, "Run ID", "Action", "Started", "Stopped", "Status")
 	}
 
 	return nil
 }
+
+// RunAnnotateOptions represent options for attaching a note to a run of an installation
+type RunAnnotateOptions struct {
+	// RunID is the identifier of the run to annotate.
+	RunID string
+
+	// Note is the text to attach to the run.
+	Note string
+}
+
+// Validate the args and options for annotating a run.
+func (o *RunAnnotateOptions) Validate(args []string) error {
+	if len(args) < 1 || args[0] == "" {
+		return errors.New("run id is required")
+	}
+
+	if len(args) > 1 {
+		return fmt.Errorf("only one positional argument may be specified, the run id, but multiple were received: %s", args)
+	}
+
+	o.RunID = args[0]
+
+	if strings.TrimSpace(o.Note) == "" {
+		return errors.New("must provide a value for flag --note")
+	}
+
+	return nil
+}
+
+// AnnotateRun attaches a timestamped note to a previously executed run.
+func (p *Porter) AnnotateRun(ctx context.Context, opts RunAnnotateOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	run, err := p.Installations.GetRun(ctx, opts.RunID)
+	if err != nil {
+		return span.Error(fmt.Errorf("could not retrieve run %s: %w", opts.RunID, err))
+	}
+
+	run.AddNote(opts.Note)
+	if err = p.Installations.UpsertRun(ctx, run); err != nil {
+		return span.Error(fmt.Errorf("could not save the note to run %s: %w", opts.RunID, err))
+	}
+
+	return nil
+}
+
+// RunMarkFailedOptions represent options for marking an interrupted run of an installation as failed
+type RunMarkFailedOptions struct {
+	// RunID is the identifier of the run to mark as failed.
+	RunID string
+
+	// StaleTimeout is how long after its last heartbeat the run is considered interrupted.
+	StaleTimeout time.Duration
+
+	// Force marks the run as failed even when it may still be running.
+	Force bool
+}
+
+// Validate the args and options for marking a run as failed.
+func (o *RunMarkFailedOptions) Validate(args []string) error {
+	if len(args) < 1 || args[0] == "" {
+		return errors.New("run id is required")
+	}
+
+	if len(args) > 1 {
+		return fmt.Errorf("only one positional argument may be specified, the run id, but multiple were received: %s", args)
+	}
+
+	o.RunID = args[0]
+
+	if o.StaleTimeout < 0 {
+		return fmt.Errorf("invalid --stale-timeout %s, it must be a positive duration", o.StaleTimeout)
+	}
+
+	return nil
+}
+
+// MarkRunFailed records that a run which did not complete, because the
+// process running the bundle was interrupted, failed. Otherwise the run, and
+// the status of the installation, remain running forever. Runs whose
+// heartbeat was updated within the stale timeout may still be running, and
+// are only marked failed when forced.
+func (p *Porter) MarkRunFailed(ctx context.Context, opts RunMarkFailedOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	run, err := p.Installations.GetRun(ctx, opts.RunID)
+	if err != nil {
+		return span.Error(fmt.Errorf("could not retrieve run %s: %w", opts.RunID, err))
+	}
+
+	results, err := p.Installations.ListResults(ctx, run.ID)
+	if err != nil {
+		return span.Error(fmt.Errorf("could not retrieve the results of run %s: %w", run.ID, err))
+	}
+
+	status := cnab.StatusUnknown
+	if len(results) > 0 {
+		status = results[len(results)-1].Status
+	}
+	if status != cnab.StatusRunning && status != cnab.StatusPending {
+		return span.Error(fmt.Errorf("run %s is not running, its status is %s", run.ID, status))
+	}
+
+	now := time.Now()
+	if !opts.Force && !run.IsStale(status, now, opts.StaleTimeout) {
+		return span.Error(fmt.Errorf("run %s may still be running, its last heartbeat was %s ago. Wait until it has not reported a heartbeat for %s, or use --force to mark it failed anyway",
+			run.ID, now.Sub(run.LastHeartbeat()).Round(time.Second), opts.StaleTimeout))
+	}
+
+	result := p.Installations.NewResult(run, cnab.StatusFailed)
+	result.Message = fmt.Sprintf("The run was interrupted and marked failed, its last heartbeat was at %s", run.LastHeartbeat().Format(time.RFC3339))
+	if err = p.Installations.InsertResult(ctx, result); err != nil {
+		return span.Error(fmt.Errorf("could not save the failed result of run %s: %w", run.ID, err))
+	}
+
+	// Only update the status of the installation when it is for this run, so
+	// that the status of a more recent run is not replaced
+	installation, err := p.Installations.GetInstallation(ctx, run.Namespace, run.Installation)
+	if err != nil {
+		return span.Error(fmt.Errorf("could not retrieve installation %s/%s: %w", run.Namespace, run.Installation, err))
+	}
+	if installation.Status.RunID == run.ID {
+		installation.ApplyResult(run, result)
+		if err = p.Installations.UpdateInstallation(ctx, installation); err != nil {
+			return span.Error(fmt.Errorf("could not update the status of installation %s: %w", installation, err))
+		}
+	}
+
+	fmt.Fprintf(p.Out, "Marked the %s run %s of installation %s as failed\n", run.Action, run.ID, installation)
+	return nil
+}
+
+// RunPruneOptions represent options for removing old runs of an installation
+type RunPruneOptions struct {
+	installationOptions
+
+	// KeepLast is the number of most recent runs to keep.
+	KeepLast int
+
+	// KeepNewerThan keeps runs that were created within the specified duration.
+	KeepNewerThan time.Duration
+
+	// KeepLastSuccessful keeps the most recent successful run of each action.
+	KeepLastSuccessful bool
+
+	// DryRun lists the runs that would be removed without removing them.
+	DryRun bool
+}
+
+// Validate prepares for the prune installation runs action and validates the args/options.
+func (o *RunPruneOptions) Validate(args []string, cxt *portercontext.Context) error {
+	err := o.installationOptions.validateInstallationName(args)
+	if err != nil {
+		return err
+	}
+
+	err = o.installationOptions.defaultBundleFiles(cxt)
+	if err != nil {
+		return err
+	}
+
+	return o.GetRetentionPolicy().Validate()
+}
+
+// GetRetentionPolicy returns the policy that selects which runs are kept.
+func (o *RunPruneOptions) GetRetentionPolicy() storage.RetentionPolicy {
+	return storage.RetentionPolicy{
+		KeepLast:                    o.KeepLast,
+		KeepNewerThan:               o.KeepNewerThan,
+		KeepLastSuccessfulPerAction: o.KeepLastSuccessful,
+	}
+}
+
+// PruneInstallationRuns removes the runs of an installation, along with their
+// results and outputs, that are not kept by the retention policy.
+func (p *Porter) PruneInstallationRuns(ctx context.Context, opts RunPruneOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	err := p.applyDefaultOptions(ctx, &opts.installationOptions)
+	if err != nil {
+		return err
+	}
+
+	// Check that the installation exists so that a typo isn't reported as nothing to prune
+	if _, err = p.Installations.GetInstallation(ctx, opts.Namespace, opts.Name); err != nil {
+		return span.Error(fmt.Errorf("could not retrieve installation %s/%s: %w", opts.Namespace, opts.Name, err))
+	}
+
+	pruned, err := p.Installations.PruneRuns(ctx, storage.PruneRunsOptions{
+		Namespace:    opts.Namespace,
+		Installation: opts.Name,
+		Policy:       opts.GetRetentionPolicy(),
+		DryRun:       opts.DryRun,
+	})
+	if err != nil {
+		return span.Error(fmt.Errorf("could not prune the runs of installation %s/%s: %w", opts.Namespace, opts.Name, err))
+	}
+
+	if !opts.DryRun {
+		if err = p.cleanRunSecrets(ctx, pruned); err != nil {
+			return span.Error(fmt.Errorf("could not remove the sensitive data of the pruned runs of installation %s/%s: %w", opts.Namespace, opts.Name, err))
+		}
+	}
+
+	if len(pruned) == 0 {
+		fmt.Fprintf(p.Out, "No runs of installation %s/%s need to be pruned\n", opts.Namespace, opts.Name)
+		return nil
+	}
+
+	if opts.DryRun {
+		fmt.Fprintf(p.Out, "The following %d runs of installation %s/%s would be pruned:\n", len(pruned), opts.Namespace, opts.Name)
+	} else {
+		fmt.Fprintf(p.Out, "Pruned %d runs of installation %s/%s:\n", len(pruned), opts.Namespace, opts.Name)
+	}
+
+	now := time.Now()
+	tp := dtprinter.DateTimePrinter{
+		Now: func() time.Time { return now },
+	}
+	row := func(v interface{}) []string {
+		run, ok := v.(storage.Run)
+		if !ok {
+			return nil
+		}
+		return []string{run.ID, run.Action, tp.Format(run.Created)}
+	}
+	return printer.PrintTable(p.Out, pruned, row, "Run ID", "Action", "Started")
+}
+
+// RunDiffOptions represent options for comparing two runs of an installation
+type RunDiffOptions struct {
+	printer.PrintOptions
+
+	// BeforeID is the identifier of the run that the changes are relative to.
+	BeforeID string
+
+	// AfterID is the identifier of the run that is compared against the before run.
+	AfterID string
+}
+
+// Validate the args and options for comparing two runs.
+func (o *RunDiffOptions) Validate(args []string) error {
+	if len(args) != 2 || args[0] == "" || args[1] == "" {
+		return fmt.Errorf("two run ids are required, the run to compare against followed by the run to compare, but %d were received: %s", len(args), args)
+	}
+
+	o.BeforeID = args[0]
+	o.AfterID = args[1]
+
+	return o.PrintOptions.Validate(ShowDefaultFormat, ShowAllowedFormats)
+}
+
+// DiffInstallationRuns compares two runs of an installation and returns what
+// changed from the before run to the after run.
+func (p *Porter) DiffInstallationRuns(ctx context.Context, opts RunDiffOptions) (storage.RunDiff, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	before, beforeOutputs, err := p.getRunWithOutputs(ctx, opts.BeforeID)
+	if err != nil {
+		return storage.RunDiff{}, span.Error(err)
+	}
+
+	after, afterOutputs, err := p.getRunWithOutputs(ctx, opts.AfterID)
+	if err != nil {
+		return storage.RunDiff{}, span.Error(err)
+	}
+
+	if before.Namespace != after.Namespace || before.Installation != after.Installation {
+		return storage.RunDiff{}, span.Error(fmt.Errorf("cannot compare runs of different installations: run %s is for installation %s/%s and run %s is for installation %s/%s",
+			before.ID, before.Namespace, before.Installation, after.ID, after.Namespace, after.Installation))
+	}
+
+	secretValues, err := p.Sanitizer.RestoreRunSecrets(ctx, before, beforeOutputs)
+	if err != nil {
+		return storage.RunDiff{}, span.Error(fmt.Errorf("could not resolve the sensitive values of run %s: %w", before.ID, err))
+	}
+	afterSecrets, err := p.Sanitizer.RestoreRunSecrets(ctx, after, afterOutputs)
+	if err != nil {
+		return storage.RunDiff{}, span.Error(fmt.Errorf("could not resolve the sensitive values of run %s: %w", after.ID, err))
+	}
+	for key, value := range afterSecrets {
+		secretValues[key] = value
+	}
+
+	diff := storage.DiffRuns(before, beforeOutputs, after, afterOutputs, secretValues)
+	maskNamedValueDiffs(diff.ParameterOverrides)
+	maskNamedValueDiffs(diff.Outputs)
+	return diff, nil
+}
+
+// maskNamedValueDiffs replaces the values of the sensitive parameters or
+// outputs in a run diff with maskedValue.
+func maskNamedValueDiffs(diffs []storage.NamedValueDiff) {
+	masked := maskedValue
+	for i, d := range diffs {
+		if !d.Sensitive {
+			continue
+		}
+		if d.Before != nil {
+			diffs[i].Before = &masked
+		}
+		if d.After != nil {
+			diffs[i].After = &masked
+		}
+	}
+}
+
+// getRunWithOutputs retrieves a run and the outputs generated by its results.
+func (p *Porter) getRunWithOutputs(ctx context.Context, runID string) (storage.Run, storage.Outputs, error) {
+	run, err := p.Installations.GetRun(ctx, runID)
+	if err != nil {
+		return storage.Run{}, storage.Outputs{}, fmt.Errorf("could not retrieve run %s: %w", runID, err)
+	}
+
+	results, err := p.Installations.ListResults(ctx, runID)
+	if err != nil {
+		return storage.Run{}, storage.Outputs{}, fmt.Errorf("could not retrieve the results of run %s: %w", runID, err)
+	}
+
+	outputs, err := p.listResultOutputs(ctx, runID, results)
+	if err != nil {
+		return storage.Run{}, storage.Outputs{}, err
+	}
+
+	return run, outputs, nil
+}
+
+// listResultOutputs retrieves the outputs generated by the results of a run.
+func (p *Porter) listResultOutputs(ctx context.Context, runID string, results []storage.Result) (storage.Outputs, error) {
+	var outputs []storage.Output
+	for _, result := range results {
+		resultOutputs, err := p.Installations.ListOutputs(ctx, result.ID)
+		if err != nil {
+			return storage.Outputs{}, fmt.Errorf("could not retrieve the outputs of run %s: %w", runID, err)
+		}
+		outputs = append(outputs, resultOutputs...)
+	}
+	return storage.NewOutputs(outputs), nil
+}
+
+// PrintInstallationRunsDiff prints the changes between two runs of an installation.
+func (p *Porter) PrintInstallationRunsDiff(ctx context.Context, opts RunDiffOptions) error {
+	diff, err := p.DiffInstallationRuns(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, diff)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, diff)
+	case printer.FormatPlaintext:
+		if !diff.HasChanges() {
+			fmt.Fprintf(p.Out, "No changes between runs %s and %s of installation %s/%s\n", diff.Before, diff.After, diff.Namespace, diff.Installation)
+			return nil
+		}
+
+		fmt.Fprintf(p.Out, "Changes from run %s to run %s of installation %s/%s:\n", diff.Before, diff.After, diff.Namespace, diff.Installation)
+		rows := getDisplayRunDiffRows(diff)
+		row := func(v interface{}) []string {
+			r, ok := v.([]string)
+			if !ok {
+				return nil
+			}
+			return r
+		}
+		return printer.PrintTable(p.Out, rows, row, "Field", "Name", "Before", "After")
+	}
+
+	return nil
+}
+
+// getDisplayRunDiffRows flattens a run diff into table rows of: field, name, before, after.
+func getDisplayRunDiffRows(diff storage.RunDiff) [][]string {
+	var rows [][]string
+
+	addValue := func(field string, d *storage.ValueDiff) {
+		if d != nil {
+			rows = append(rows, []string{field, "", d.Before, d.After})
+		}
+	}
+	addList := func(field string, d *storage.ListDiff) {
+		if d == nil {
+			return
+		}
+		for _, item := range d.Removed {
+			rows = append(rows, []string{field, item, "present", ""})
+		}
+		for _, item := range d.Added {
+			rows = append(rows, []string{field, item, "", "present"})
+		}
+	}
+	addNamedValues := func(field string, diffs []storage.NamedValueDiff) {
+		for _, d := range diffs {
+			var before, after string
+			if d.Before != nil {
+				before = *d.Before
+			}
+			if d.After != nil {
+				after = *d.After
+			}
+			rows = append(rows, []string{field, d.Name, before, after})
+		}
+	}
+
+	addValue("Action", diff.Action)
+	addValue("Bundle Reference", diff.BundleReference)
+	addValue("Bundle Digest", diff.BundleDigest)
+	addList("Credential Set", diff.CredentialSets)
+	addList("Parameter Set", diff.ParameterSets)
+	addNamedValues("Parameter", diff.ParameterOverrides)
+	addNamedValues("Output", diff.Outputs)
+
+	return rows
+}
+
+// DiffInstallationRunEnvironments compares the recorded executor environments
+// of two runs of an installation, such as the version of Porter, the driver,
+// the mixins and the image digests, and returns what changed from the before
+// run to the after run.
+func (p *Porter) DiffInstallationRunEnvironments(ctx context.Context, opts RunDiffOptions) (storage.RunEnvironmentDiff, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	before, err := p.Installations.GetRun(ctx, opts.BeforeID)
+	if err != nil {
+		return storage.RunEnvironmentDiff{}, span.Error(fmt.Errorf("could not retrieve run %s: %w", opts.BeforeID, err))
+	}
+
+	after, err := p.Installations.GetRun(ctx, opts.AfterID)
+	if err != nil {
+		return storage.RunEnvironmentDiff{}, span.Error(fmt.Errorf("could not retrieve run %s: %w", opts.AfterID, err))
+	}
+
+	if before.Namespace != after.Namespace || before.Installation != after.Installation {
+		return storage.RunEnvironmentDiff{}, span.Error(fmt.Errorf("cannot compare runs of different installations: run %s is for installation %s/%s and run %s is for installation %s/%s",
+			before.ID, before.Namespace, before.Installation, after.ID, after.Namespace, after.Installation))
+	}
+
+	for _, run := range []storage.Run{before, after} {
+		if run.Environment == nil {
+			return storage.RunEnvironmentDiff{}, span.Error(fmt.Errorf("cannot compare the environment of run %s because it was executed by a version of Porter that did not record the environment of its runs", run.ID))
+		}
+	}
+
+	return storage.DiffRunEnvironments(before, after), nil
+}
+
+// PrintInstallationRunsEnvDiff prints the changes between the executor
+// environments of two runs of an installation.
+func (p *Porter) PrintInstallationRunsEnvDiff(ctx context.Context, opts RunDiffOptions) error {
+	diff, err := p.DiffInstallationRunEnvironments(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, diff)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, diff)
+	case printer.FormatPlaintext:
+		if !diff.HasChanges() {
+			fmt.Fprintf(p.Out, "No changes to the environment between runs %s and %s of installation %s/%s\n", diff.Before, diff.After, diff.Namespace, diff.Installation)
+			return nil
+		}
+
+		fmt.Fprintf(p.Out, "Changes to the environment from run %s to run %s of installation %s/%s:\n", diff.Before, diff.After, diff.Namespace, diff.Installation)
+		rows := getDisplayRunEnvironmentDiffRows(diff)
+		row := func(v interface{}) []string {
+			r, ok := v.([]string)
+			if !ok {
+				return nil
+			}
+			return r
+		}
+		return printer.PrintTable(p.Out, rows, row, "Field", "Name", "Before", "After")
+	}
+
+	return nil
+}
+
+// getDisplayRunEnvironmentDiffRows flattens a run environment diff into table
+// rows of: field, name, before, after.
+func getDisplayRunEnvironmentDiffRows(diff storage.RunEnvironmentDiff) [][]string {
+	var rows [][]string
+
+	addValue := func(field string, d *storage.ValueDiff) {
+		if d != nil {
+			rows = append(rows, []string{field, "", d.Before, d.After})
+		}
+	}
+	addNamedValues := func(field string, diffs []storage.NamedValueDiff) {
+		for _, d := range diffs {
+			var before, after string
+			if d.Before != nil {
+				before = *d.Before
+			}
+			if d.After != nil {
+				after = *d.After
+			}
+			rows = append(rows, []string{field, d.Name, before, after})
+		}
+	}
+
+	addValue("Porter Version", diff.PorterVersion)
+	addValue("Porter Commit", diff.PorterCommit)
+	addValue("Driver", diff.Driver)
+	addValue("Driver Version", diff.DriverVersion)
+	addNamedValues("Mixin", diff.Mixins)
+	addNamedValues("Image", diff.Images)
+
+	return rows
+}
+
+// RunShowOptions represent options for showing a run of an installation
+type RunShowOptions struct {
+	printer.PrintOptions
+
+	// RunID is the identifier of the run to show.
+	RunID string
+
+	// Outputs includes the outputs generated by the run.
+	Outputs bool
+
+	// ShowSensitive reveals the values of sensitive outputs, when allowed by
+	// the sensitive output policy.
+	ShowSensitive bool
+}
+
+// Validate the args and options for showing a run.
+func (o *RunShowOptions) Validate(args []string) error {
+	if len(args) < 1 || args[0] == "" {
+		return errors.New("run id is required")
+	}
+
+	if len(args) > 1 {
+		return fmt.Errorf("only one positional argument may be specified, the run id, but multiple were received: %s", args)
+	}
+
+	o.RunID = args[0]
+
+	if o.ShowSensitive && !o.Outputs {
+		return errors.New("--show-sensitive can only be used with --outputs")
+	}
+
+	return o.PrintOptions.Validate(ShowDefaultFormat, ShowAllowedFormats)
+}
+
+// DisplayRunDetails is the representation of a single run of an installation
+// with the details of how it was executed.
+type DisplayRunDetails struct {
+	DisplayRun `yaml:",inline"`
+
+	Namespace      string               `json:"namespace" yaml:"namespace"`
+	Installation   string               `json:"installation" yaml:"installation"`
+	BundleDigest   string               `json:"bundleDigest,omitempty" yaml:"bundleDigest,omitempty"`
+	CredentialSets []string             `json:"credentialSets,omitempty" yaml:"credentialSets,omitempty"`
+	ParameterSets  []string             `json:"parameterSets,omitempty" yaml:"parameterSets,omitempty"`
+	Metadata       *storage.RunMetadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Steps          []DisplayStepResult  `json:"steps,omitempty" yaml:"steps,omitempty"`
+	Outputs        DisplayValues        `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+
+	// OutputDependencies are the parameters that were resolved from the
+	// outputs of other installations.
+	OutputDependencies []storage.RunOutputDependency `json:"outputDependencies,omitempty" yaml:"outputDependencies,omitempty"`
+}
+
+// DisplayStepResult is the representation of the outcome of a single step of a run.
+type DisplayStepResult struct {
+	Index         int       `json:"index" yaml:"index"`
+	Description   string    `json:"description,omitempty" yaml:"description,omitempty"`
+	Mixin         string    `json:"mixin" yaml:"mixin"`
+	Started       time.Time `json:"started" yaml:"started"`
+	Stopped       time.Time `json:"stopped" yaml:"stopped"`
+	Duration      string    `json:"duration" yaml:"duration"`
+	Status        string    `json:"status" yaml:"status"`
+	ExitCode      int       `json:"exitCode,omitempty" yaml:"exitCode,omitempty"`
+	Error         string    `json:"error,omitempty" yaml:"error,omitempty"`
+	Logs          string    `json:"logs,omitempty" yaml:"logs,omitempty"`
+	LogsTruncated bool      `json:"logsTruncated,omitempty" yaml:"logsTruncated,omitempty"`
+}
+
+// NewDisplayStepResult converts a step result into its display representation.
+func NewDisplayStepResult(step storage.StepResult) DisplayStepResult {
+	return DisplayStepResult{
+		Index:         step.Index,
+		Description:   step.Description,
+		Mixin:         step.Mixin,
+		Started:       step.Started,
+		Stopped:       step.Stopped,
+		Duration:      step.Duration().Round(time.Millisecond).String(),
+		Status:        step.Status,
+		ExitCode:      step.ExitCode,
+		Error:         step.Error,
+		Logs:          step.Logs,
+		LogsTruncated: step.LogsTruncated,
+	}
+}
+
+// GetInstallationRun retrieves a run of an installation, and optionally the
+// outputs that it generated. Sensitive outputs are masked, and are only
+// resolved from the secret store when ShowSensitive is set.
+func (p *Porter) GetInstallationRun(ctx context.Context, opts RunShowOptions) (DisplayRunDetails, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	run, err := p.Installations.GetRun(ctx, opts.RunID)
+	if err != nil {
+		return DisplayRunDetails{}, span.Error(fmt.Errorf("could not retrieve run %s: %w", opts.RunID, err))
+	}
+
+	if opts.ShowSensitive && !p.Config.Data.SensitiveOutputPolicy.AllowsShowSensitive(run.Namespace) {
+		return DisplayRunDetails{}, span.Error(failure.PolicyDenied(fmt.Errorf("revealing sensitive outputs in namespace %q is not allowed by the allow-show-sensitive setting of the sensitive-output-policy configuration", run.Namespace)))
+	}
+
+	results, err := p.Installations.ListResults(ctx, run.ID)
+	if err != nil {
+		return DisplayRunDetails{}, span.Error(fmt.Errorf("could not retrieve the results of run %s: %w", run.ID, err))
+	}
+
+	displayRun := DisplayRunDetails{
+		DisplayRun:     NewDisplayRun(run),
+		Namespace:      run.Namespace,
+		Installation:   run.Installation,
+		BundleDigest:   run.BundleDigest,
+		CredentialSets: run.CredentialSets,
+		ParameterSets:  run.ParameterSets,
+		Metadata:       run.Metadata,
+
+		OutputDependencies: run.OutputDependencies,
+	}
+	displayRun.setResults(results)
+
+	steps, err := p.Installations.ListStepResults(ctx, run.ID)
+	if err != nil {
+		return DisplayRunDetails{}, span.Error(fmt.Errorf("could not retrieve the step results of run %s: %w", run.ID, err))
+	}
+	for _, step := range steps {
+		displayRun.Steps = append(displayRun.Steps, NewDisplayStepResult(step))
+	}
+
+	if !opts.Outputs {
+		return displayRun, nil
+	}
+
+	outputs, err := p.listResultOutputs(ctx, run.ID, results)
+	if err != nil {
+		return DisplayRunDetails{}, span.Error(err)
+	}
+
+	bun := cnab.NewBundle(run.Bundle)
+	if opts.ShowSensitive {
+		outputs, err = p.readChunkedOutputs(ctx, outputs)
+		if err != nil {
+			return DisplayRunDetails{}, span.Error(err)
+		}
+
+		outputs, err = p.Sanitizer.RestoreOutputs(ctx, outputs)
+		if err != nil {
+			return DisplayRunDetails{}, span.Error(err)
+		}
+		displayRun.Outputs = NewDisplayValuesFromOutputs(bun, outputs)
+	} else {
+		outputs, err = p.readUnmaskedOutputs(ctx, bun, outputs)
+		if err != nil {
+			return DisplayRunDetails{}, span.Error(err)
+		}
+		displayRun.Outputs = NewDisplayValuesFromOutputs(bun, outputs)
+		for i, output := range displayRun.Outputs {
+			if output.Sensitive {
+				displayRun.Outputs[i].Value = maskedValue
+			}
+		}
+	}
+
+	return displayRun, nil
+}
+
+// maskedValue replaces the value of a sensitive output that was not revealed.
+const maskedValue = "******"
+
+// readUnmaskedOutputs retrieves the values of outputs that are not sensitive.
+// Sensitive outputs are left unresolved, so that their values are not
+// retrieved from the secret store or their chunks when they are masked anyway.
+func (p *Porter) readUnmaskedOutputs(ctx context.Context, bun cnab.ExtendedBundle, outputs storage.Outputs) (storage.Outputs, error) {
+	values := make([]storage.Output, 0, outputs.Len())
+	for _, o := range outputs.Value() {
+		if sensitive, _ := bun.IsOutputSensitive(o.Name); sensitive || o.Key != "" {
+			o.Value = nil
+			values = append(values, o)
+			continue
+		}
+
+		o, err := storage.ReadOutputValue(ctx, p.Installations, o)
+		if err != nil {
+			return outputs, fmt.Errorf("could not read the value of output %s: %w", o.Name, err)
+		}
+		values = append(values, o)
+	}
+	return storage.NewOutputs(values), nil
+}
+
+// PrintInstallationRun prints a run of an installation.
+func (p *Porter) PrintInstallationRun(ctx context.Context, opts RunShowOptions) error {
+	displayRun, err := p.GetInstallationRun(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, displayRun)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, displayRun)
+	case printer.FormatPlaintext:
+		now := time.Now()
+		tp := dtprinter.DateTimePrinter{
+			Now: func() time.Time { return now },
+		}
+
+		fmt.Fprintf(p.Out, "Run ID: %s\n", displayRun.ID)
+		fmt.Fprintf(p.Out, "Installation: %s/%s\n", displayRun.Namespace, displayRun.Installation)
+		fmt.Fprintf(p.Out, "Action: %s\n", displayRun.Action)
+		fmt.Fprintf(p.Out, "Status: %s\n", displayRun.Status)
+		fmt.Fprintf(p.Out, "Started: %s\n", tp.Format(displayRun.Started))
+		if displayRun.Stopped != nil {
+			fmt.Fprintf(p.Out, "Stopped: %s\n", tp.Format(*displayRun.Stopped))
+		}
+		if displayRun.Timeout != "" {
+			fmt.Fprintf(p.Out, "Timeout: %s\n", displayRun.Timeout)
+		}
+
+		fmt.Fprintln(p.Out)
+		fmt.Fprintln(p.Out, "Bundle:")
+		fmt.Fprintf(p.Out, "  Reference: %s\n", displayRun.Bundle)
+		if displayRun.Version != "" {
+			fmt.Fprintf(p.Out, "  Version: %s\n", displayRun.Version)
+		}
+		if displayRun.BundleDigest != "" {
+			fmt.Fprintf(p.Out, "  Digest: %s\n", displayRun.BundleDigest)
+		}
+
+		if md := displayRun.Metadata; md != nil {
+			fmt.Fprintln(p.Out)
+			fmt.Fprintln(p.Out, "Metadata:")
+			printField := func(name string, value string) {
+				if value != "" {
+					fmt.Fprintf(p.Out, "  %s: %s\n", name, value)
+				}
+			}
+			printField("Source", md.Source)
+			printField("Commit", md.Commit)
+			printField("Branch", md.Branch)
+			printField("Repository", md.Repository)
+			printField("Pipeline", md.PipelineURL)
+			printField("User", md.User)
+		}
+
+		if len(displayRun.ParameterSets) > 0 {
+			fmt.Fprintln(p.Out)
+			fmt.Fprintln(p.Out, "Parameter Sets:")
+			for _, ps := range displayRun.ParameterSets {
+				fmt.Fprintf(p.Out, "  - %s\n", ps)
+			}
+		}
+
+		if len(displayRun.CredentialSets) > 0 {
+			fmt.Fprintln(p.Out)
+			fmt.Fprintln(p.Out, "Credential Sets:")
+			for _, cs := range displayRun.CredentialSets {
+				fmt.Fprintf(p.Out, "  - %s\n", cs)
+			}
+		}
+
+		if len(displayRun.OutputDependencies) > 0 {
+			fmt.Fprintln(p.Out)
+			fmt.Fprintln(p.Out, "Output Dependencies:")
+			for _, dep := range displayRun.OutputDependencies {
+				fmt.Fprintf(p.Out, "  %s: %s\n", dep.Parameter, dep)
+			}
+		}
+
+		if len(displayRun.Labels) > 0 {
+			fmt.Fprintln(p.Out)
+			fmt.Fprintln(p.Out, "Labels:")
+
+			// Print labels in alphabetical order
+			labels := make([]string, 0, len(displayRun.Labels))
+			for k, v := range displayRun.Labels {
+				labels = append(labels, fmt.Sprintf("%s: %s", k, v))
+			}
+			sort.Strings(labels)
+
+			for _, label := range labels {
+				fmt.Fprintf(p.Out, "  %s\n", label)
+			}
+		}
+
+		if len(displayRun.Notes) > 0 {
+			fmt.Fprintln(p.Out)
+			fmt.Fprintln(p.Out, "Notes:")
+			for _, note := range displayRun.Notes {
+				fmt.Fprintf(p.Out, "  %s: %s\n", tp.Format(note.Created), note.Note)
+			}
+		}
+
+		if len(displayRun.Steps) > 0 {
+			fmt.Fprintln(p.Out)
+			fmt.Fprintln(p.Out, "Steps:")
+			row := func(v interface{}) []string {
+				step, ok := v.(DisplayStepResult)
+				if !ok {
+					return nil
+				}
+				exitCode := ""
+				if step.ExitCode != 0 {
+					exitCode = strconv.Itoa(step.ExitCode)
+				}
+				return []string{strconv.Itoa(step.Index + 1), step.Description, step.Mixin, step.Duration, step.Status, exitCode}
+			}
+			if err = printer.PrintTable(p.Out, displayRun.Steps, row, "#", "Description", "Mixin", "Duration", "Status", "Exit Code"); err != nil {
+				return err
+			}
+		}
+
+		if opts.Outputs {
+			fmt.Fprintln(p.Out)
+			fmt.Fprintln(p.Out, "Outputs:")
+			outputs := displayRun.Outputs
+			if opts.ShowSensitive {
+				// The values were revealed, so print them instead of the mask
+				outputs = make(DisplayValues, len(displayRun.Outputs))
+				for i, output := range displayRun.Outputs {
+					output.Sensitive = false
+					outputs[i] = output
+				}
+			}
+			return p.printDisplayValuesTable(outputs)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// RunInspectOptions are the options for inspecting how a run is stored.
+type RunInspectOptions struct {
+	printer.PrintOptions
+
+	// RunID is the identifier of the run to inspect.
+	RunID string
+
+	// ShowSensitiveMap lists the sensitive parameters and outputs of the run,
+	// and the keys of the secrets that they are saved to.
+	ShowSensitiveMap bool
+}
+
+// Validate the args and options for inspecting a run.
+func (o *RunInspectOptions) Validate(args []string) error {
+	if len(args) < 1 || args[0] == "" {
+		return errors.New("run id is required")
+	}
+
+	if len(args) > 1 {
+		return fmt.Errorf("only one positional argument may be specified, the run id, but multiple were received: %s", args)
+	}
+
+	o.RunID = args[0]
+
+	if !o.ShowSensitiveMap {
+		return errors.New("specify what to inspect, for example --show-sensitive-map")
+	}
+
+	return o.PrintOptions.Validate(printer.FormatPlaintext, []printer.Format{printer.FormatPlaintext, printer.FormatJson, printer.FormatYaml})
+}
+
+// GetSensitiveMap lists the sensitive parameters and outputs of a run, and the
+// keys of the secrets that the sanitizer saves them to, without reading or
+// writing any values in the secret store.
+func (p *Porter) GetSensitiveMap(ctx context.Context, runID string) ([]storage.SanitizedValue, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	run, err := p.Installations.GetRun(ctx, runID)
+	if err != nil {
+		return nil, span.Error(fmt.Errorf("could not retrieve run %s: %w", runID, err))
+	}
+
+	results, err := p.Installations.ListResults(ctx, run.ID)
+	if err != nil {
+		return nil, span.Error(fmt.Errorf("could not retrieve the results of run %s: %w", run.ID, err))
+	}
+
+	outputs, err := p.listResultOutputs(ctx, run.ID, results)
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
+	report, err := p.Sanitizer.ReportSensitiveValues(run, outputs)
+	if err != nil {
+		return nil, span.Error(fmt.Errorf("could not determine the sensitive values of run %s: %w", run.ID, err))
+	}
+	return report, nil
+}
+
+// InspectInstallationRun prints how the values of a run are stored.
+func (p *Porter) InspectInstallationRun(ctx context.Context, opts RunInspectOptions) error {
+	report, err := p.GetSensitiveMap(ctx, opts.RunID)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, report)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, report)
+	case printer.FormatPlaintext:
+		if len(report) == 0 {
+			fmt.Fprintln(p.Out, "No sensitive parameters or outputs")
+			return nil
+		}
+
+		row := func(v interface{}) []string {
+			sv, ok := v.(storage.SanitizedValue)
+			if !ok {
+				return nil
+			}
+			return []string{sv.Kind, sv.Name, sv.SensitiveBy, sv.Key, strconv.FormatBool(sv.Saved)}
+		}
+		return printer.PrintTable(p.Out, report, row, "Kind", "Name", "Sensitive By", "Secret Key", "Saved")
+	default:
+		return fmt.Errorf("invalid format: %s", opts.Format)
+	}
+}
@@ -0,0 +1,95 @@
+package porter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunInspectOptions_Validate(t *testing.T) {
+	t.Run("run id required", func(t *testing.T) {
+		opts := RunInspectOptions{ShowSensitiveMap: true}
+		err := opts.Validate(nil)
+		require.EqualError(t, err, "run id is required")
+	})
+
+	t.Run("view required", func(t *testing.T) {
+		opts := RunInspectOptions{}
+		err := opts.Validate([]string{"abc"})
+		require.EqualError(t, err, "specify what to inspect, for example --show-sensitive-map")
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		opts := RunInspectOptions{ShowSensitiveMap: true}
+		err := opts.Validate([]string{"abc"})
+		require.NoError(t, err)
+		assert.Equal(t, "abc", opts.RunID)
+		assert.Equal(t, printer.FormatPlaintext, opts.Format)
+	})
+}
+
+func TestPorter_InspectInstallationRun_SensitiveMap(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.TestConfig.TestContext.AddTestFile("testdata/bundle.json", "/bundle.json")
+	bun, err := cnab.LoadBundle(p.Context, "/bundle.json")
+	require.NoError(t, err)
+
+	i := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybuns"))
+	run := i.NewRun(cnab.ActionInstall)
+	run.Bundle = bun.Bundle
+	run.Parameters.Parameters, err = p.TestSanitizer.CleanRawParameters(ctx, map[string]interface{}{
+		"my-first-param":  1,
+		"my-second-param": "spring-music-demo",
+	}, bun, run.ID)
+	require.NoError(t, err)
+	run = p.TestInstallations.CreateRun(run)
+	result := p.TestInstallations.CreateResult(run.NewResult(cnab.StatusSucceeded))
+	p.CreateOutput(result.NewOutput("my-first-output", []byte("topsecret")), bun)
+	p.CreateOutput(result.NewOutput("my-second-output", []byte("true")), bun)
+
+	// The policy applies after the run was stored, so its values are not saved in the secret store yet
+	p.Config.Data.SensitivityPolicies = []config.SensitivityPolicy{{Parameters: []string{"my-first-param"}, Outputs: []string{"my-second-output"}}}
+
+	// Remove the secrets to check that the secret store is not read
+	require.NoError(t, p.TestSecrets.Delete(ctx, secrets.SourceSecret, run.ID+"-my-first-output"))
+	require.NoError(t, p.TestSecrets.Delete(ctx, secrets.SourceSecret, run.ID+"-my-second-param"))
+
+	opts := RunInspectOptions{RunID: run.ID, ShowSensitiveMap: true}
+	opts.Format = printer.FormatJson
+	require.NoError(t, p.InspectInstallationRun(ctx, opts))
+
+	var report []storage.SanitizedValue
+	require.NoError(t, json.Unmarshal([]byte(p.TestConfig.TestContext.GetOutput()), &report))
+	wantReport := []storage.SanitizedValue{
+		{Kind: "parameter", Name: "my-first-param", SensitiveBy: "policy", Key: run.ID + "-my-first-param", Saved: false},
+		{Kind: "parameter", Name: "my-second-param", SensitiveBy: "bundle", Key: run.ID + "-my-second-param", Saved: true},
+		{Kind: "output", Name: "my-first-output", SensitiveBy: "bundle", Key: run.ID + "-my-first-output", Saved: true},
+		{Kind: "output", Name: "my-second-output", SensitiveBy: "policy", Key: run.ID + "-my-second-output", Saved: false},
+	}
+	assert.Equal(t, wantReport, report)
+}
+
+func TestPorter_InspectInstallationRun_Plaintext(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	i := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybuns"))
+	run := p.TestInstallations.CreateRun(i.NewRun(cnab.ActionInstall))
+
+	opts := RunInspectOptions{RunID: run.ID, ShowSensitiveMap: true}
+	opts.Format = printer.FormatPlaintext
+	require.NoError(t, p.InspectInstallationRun(ctx, opts))
+	assert.Equal(t, "No sensitive parameters or outputs\n", p.TestConfig.TestContext.GetOutput())
+}
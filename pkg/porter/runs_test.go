@@ -2,11 +2,13 @@ package porter
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
 	"get.porter.sh/porter/pkg/cnab"
 	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/secrets"
 	"get.porter.sh/porter/pkg/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -59,6 +61,151 @@ func TestPorter_ListInstallationRuns(t *testing.T) {
 	})
 }
 
+func TestPorter_ListInstallationRuns_Labels(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"), p.TestInstallations.SetMutableInstallationValues)
+	run1 := storage.NewRun("dev", "mysql")
+	run1.Labels = map[string]string{"ticket": "OPS-1", "team": "red"}
+	p.TestInstallations.CreateRun(run1)
+	run2 := storage.NewRun("dev", "mysql")
+	run2.Labels = map[string]string{"ticket": "OPS-2"}
+	p.TestInstallations.CreateRun(run2)
+	run3 := storage.NewRun("dev", "mysql")
+	p.TestInstallations.CreateRun(run3)
+
+	testcases := []struct {
+		name    string
+		labels  []string
+		wantIDs []string
+	}{
+		{name: "no selector", labels: nil, wantIDs: []string{run1.ID, run2.ID, run3.ID}},
+		{name: "equals", labels: []string{"ticket=OPS-2"}, wantIDs: []string{run2.ID}},
+		{name: "not equals", labels: []string{"ticket!=OPS-2"}, wantIDs: []string{run1.ID, run3.ID}},
+		{name: "exists", labels: []string{"ticket"}, wantIDs: []string{run1.ID, run2.ID}},
+		{name: "does not exist", labels: []string{"!ticket"}, wantIDs: []string{run3.ID}},
+		{name: "multiple requirements", labels: []string{"ticket", "team!=red"}, wantIDs: []string{run2.ID}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := RunListOptions{
+				installationOptions: installationOptions{Namespace: "dev", Name: "mysql"},
+				Labels:              tc.labels,
+			}
+			results, err := p.ListInstallationRuns(context.Background(), opts)
+			require.NoError(t, err)
+
+			gotIDs := make([]string, len(results))
+			for i, r := range results {
+				gotIDs[i] = r.ID
+			}
+			assert.ElementsMatch(t, tc.wantIDs, gotIDs)
+		})
+	}
+}
+
+func TestPorter_ListInstallationRuns_Filters(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"), p.TestInstallations.SetMutableInstallationValues)
+	install := storage.NewRun("dev", "mysql")
+	install.Action = cnab.ActionInstall
+	install.Created = time.Now().Add(-48 * time.Hour)
+	p.TestInstallations.CreateRun(install)
+	p.TestInstallations.CreateResult(install.NewResult(cnab.StatusSucceeded))
+	upgrade := storage.NewRun("dev", "mysql")
+	upgrade.Action = cnab.ActionUpgrade
+	p.TestInstallations.CreateRun(upgrade)
+	p.TestInstallations.CreateResult(upgrade.NewResult(cnab.StatusFailed))
+
+	testcases := []struct {
+		name    string
+		opts    RunListOptions
+		wantIDs []string
+	}{
+		{name: "action", opts: RunListOptions{Action: cnab.ActionInstall}, wantIDs: []string{install.ID}},
+		{name: "status", opts: RunListOptions{Status: cnab.StatusFailed}, wantIDs: []string{upgrade.ID}},
+		{name: "since", opts: RunListOptions{RawSince: "1d"}, wantIDs: []string{upgrade.ID}},
+		{name: "until", opts: RunListOptions{RawUntil: "1d"}, wantIDs: []string{install.ID}},
+		{name: "limit", opts: RunListOptions{Limit: 1}, wantIDs: []string{upgrade.ID}},
+		{name: "skip", opts: RunListOptions{Skip: 1}, wantIDs: []string{install.ID}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := tc.opts
+			opts.Namespace = "dev"
+			opts.Name = "mysql"
+			require.NoError(t, opts.Validate(nil, p.Context))
+
+			results, err := p.ListInstallationRuns(context.Background(), opts)
+			require.NoError(t, err)
+
+			gotIDs := make([]string, len(results))
+			for i, r := range results {
+				gotIDs[i] = r.ID
+			}
+			assert.Equal(t, tc.wantIDs, gotIDs)
+		})
+	}
+}
+
+func TestRunListOptions_Validate(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	testcases := []struct {
+		name    string
+		opts    RunListOptions
+		wantErr string
+	}{
+		{name: "defaults", opts: RunListOptions{}},
+		{name: "valid status", opts: RunListOptions{Status: cnab.StatusTimedOut}},
+		{name: "invalid status", opts: RunListOptions{Status: "done"}, wantErr: `invalid --status value "done"`},
+		{name: "timestamp", opts: RunListOptions{RawSince: "2022-01-31T15:04:05Z"}},
+		{name: "date", opts: RunListOptions{RawUntil: "2022-01-31"}},
+		{name: "invalid since", opts: RunListOptions{RawSince: "yesterday"}, wantErr: `invalid --since value "yesterday"`},
+		{name: "invalid until", opts: RunListOptions{RawUntil: "-1d"}, wantErr: `invalid --until value "-1d"`},
+		{name: "since after until", opts: RunListOptions{RawSince: "1d", RawUntil: "2d"}, wantErr: "--since must be before --until"},
+		{name: "negative skip", opts: RunListOptions{Skip: -1}, wantErr: "--skip must not be negative"},
+		{name: "negative limit", opts: RunListOptions{Limit: -1}, wantErr: "--limit must not be negative"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := tc.opts
+			opts.Name = "mysql"
+			err := opts.Validate(nil, p.Context)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseRunTime(t *testing.T) {
+	now := time.Date(2022, 2, 1, 12, 0, 0, 0, time.UTC)
+
+	got, err := parseRunTime("--since", "2022-01-31T15:04:05Z", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 1, 31, 15, 4, 5, 0, time.UTC), got)
+
+	got, err = parseRunTime("--since", "2022-01-31", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 1, 31, 0, 0, 0, 0, time.Local), got)
+
+	got, err = parseRunTime("--since", "12h", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-12*time.Hour), got)
+
+	got, err = parseRunTime("--since", "7d", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-7*24*time.Hour), got)
+}
+
 func TestPorter_PrintInstallationRunsOutput(t *testing.T) {
 	outputTestcases := []struct {
 		name       string
@@ -104,3 +251,512 @@ func TestPorter_PrintInstallationRunsOutput(t *testing.T) {
 
 	}
 }
+
+func TestRunAnnotateOptions_Validate(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name      string
+		args      []string
+		note      string
+		wantError string
+	}{
+		{name: "valid", args: []string{"abc123"}, note: "rolled back manually"},
+		{name: "missing run id", args: []string{}, note: "rolled back manually", wantError: "run id is required"},
+		{name: "multiple args", args: []string{"abc123", "def456"}, note: "rolled back manually", wantError: "only one positional argument may be specified"},
+		{name: "missing note", args: []string{"abc123"}, note: "  ", wantError: "must provide a value for flag --note"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := RunAnnotateOptions{Note: tc.note}
+			err := opts.Validate(tc.args)
+			if tc.wantError != "" {
+				require.ErrorContains(t, err, tc.wantError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.args[0], opts.RunID)
+			}
+		})
+	}
+}
+
+func TestPorter_AnnotateRun(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	installation := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+	run := p.TestInstallations.CreateRun(installation.NewRun(cnab.ActionUpgrade))
+
+	t.Run("annotate existing run", func(t *testing.T) {
+		err := p.AnnotateRun(ctx, RunAnnotateOptions{RunID: run.ID, Note: "rolled back manually, see INC-123"})
+		require.NoError(t, err)
+		err = p.AnnotateRun(ctx, RunAnnotateOptions{RunID: run.ID, Note: "root cause identified"})
+		require.NoError(t, err)
+
+		runs, err := p.ListInstallationRuns(ctx, RunListOptions{installationOptions: installationOptions{Namespace: "dev", Name: "mysql"}})
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		require.Len(t, runs[0].Notes, 2, "expected the notes to be included in the run listing")
+		assert.Equal(t, "rolled back manually, see INC-123", runs[0].Notes[0].Note)
+		assert.Equal(t, "root cause identified", runs[0].Notes[1].Note)
+	})
+
+	t.Run("run not found", func(t *testing.T) {
+		err := p.AnnotateRun(ctx, RunAnnotateOptions{RunID: "missing", Note: "oops"})
+		require.ErrorContains(t, err, "could not retrieve run missing")
+	})
+}
+
+func TestRunMarkFailedOptions_Validate(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name         string
+		args         []string
+		staleTimeout time.Duration
+		wantError    string
+	}{
+		{name: "valid", args: []string{"abc123"}, staleTimeout: time.Minute},
+		{name: "missing run id", args: []string{}, wantError: "run id is required"},
+		{name: "multiple args", args: []string{"abc123", "def456"}, wantError: "only one positional argument may be specified"},
+		{name: "negative stale timeout", args: []string{"abc123"}, staleTimeout: -time.Minute, wantError: "invalid --stale-timeout -1m0s"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := RunMarkFailedOptions{StaleTimeout: tc.staleTimeout}
+			err := opts.Validate(tc.args)
+			if tc.wantError != "" {
+				require.ErrorContains(t, err, tc.wantError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.args[0], opts.RunID)
+			}
+		})
+	}
+}
+
+func TestPorter_MarkRunFailed(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	installation := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+	createRunningRun := func(heartbeat time.Time) storage.Run {
+		run := p.TestInstallations.CreateRun(installation.NewRun(cnab.ActionInstall), func(r *storage.Run) {
+			r.Created = heartbeat
+			r.Heartbeat = heartbeat
+		})
+		result := p.TestInstallations.CreateResult(run.NewResult(cnab.StatusRunning))
+		installation.ApplyResult(run, result)
+		require.NoError(t, p.Installations.UpdateInstallation(ctx, installation))
+		return run
+	}
+
+	t.Run("stale run", func(t *testing.T) {
+		run := createRunningRun(time.Now().Add(-time.Hour))
+
+		runs, err := p.ListInstallationRuns(ctx, RunListOptions{installationOptions: installationOptions{Namespace: "dev", Name: "mysql"}})
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		assert.True(t, runs[0].Stale, "expected the run to be listed as stale")
+
+		err = p.MarkRunFailed(ctx, RunMarkFailedOptions{RunID: run.ID, StaleTimeout: storage.RunStaleTimeout})
+		require.NoError(t, err)
+		assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "Marked the install run "+run.ID+" of installation dev/mysql as failed")
+
+		results, err := p.Installations.ListResults(ctx, run.ID)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, cnab.StatusFailed, results[1].Status)
+		assert.Contains(t, results[1].Message, "The run was interrupted")
+
+		inst, err := p.Installations.GetInstallation(ctx, "dev", "mysql")
+		require.NoError(t, err)
+		assert.Equal(t, cnab.StatusFailed, inst.Status.ResultStatus, "expected the status of the installation to be updated")
+
+		err = p.MarkRunFailed(ctx, RunMarkFailedOptions{RunID: run.ID, StaleTimeout: storage.RunStaleTimeout})
+		require.EqualError(t, err, "run "+run.ID+" is not running, its status is failed")
+	})
+
+	t.Run("recent heartbeat", func(t *testing.T) {
+		run := createRunningRun(time.Now())
+
+		err := p.MarkRunFailed(ctx, RunMarkFailedOptions{RunID: run.ID, StaleTimeout: storage.RunStaleTimeout})
+		require.ErrorContains(t, err, "may still be running")
+		require.ErrorContains(t, err, "use --force to mark it failed anyway")
+
+		err = p.MarkRunFailed(ctx, RunMarkFailedOptions{RunID: run.ID, StaleTimeout: storage.RunStaleTimeout, Force: true})
+		require.NoError(t, err)
+	})
+
+	t.Run("run not found", func(t *testing.T) {
+		err := p.MarkRunFailed(ctx, RunMarkFailedOptions{RunID: "missing"})
+		require.ErrorContains(t, err, "could not retrieve run missing")
+	})
+}
+
+func TestRunPruneOptions_Validate(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	t.Run("valid", func(t *testing.T) {
+		opts := RunPruneOptions{KeepLast: 5}
+		require.NoError(t, opts.Validate([]string{"mybuns"}, p.Context))
+		assert.Equal(t, "mybuns", opts.Name)
+	})
+
+	t.Run("no rules", func(t *testing.T) {
+		opts := RunPruneOptions{}
+		err := opts.Validate([]string{"mybuns"}, p.Context)
+		require.ErrorContains(t, err, "at least one rule must be specified")
+	})
+}
+
+func TestPorter_PruneInstallationRuns(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	p.TestConfig.TestContext.AddTestFile("testdata/bundle.json", "/bundle.json")
+	bun, err := cnab.LoadBundle(p.Context, "/bundle.json")
+	require.NoError(t, err)
+
+	i := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybuns"))
+	var runs []storage.Run
+	for _, action := range []string{cnab.ActionInstall, cnab.ActionUpgrade, cnab.ActionUpgrade} {
+		run := i.NewRun(action)
+		run.Bundle = bun.Bundle
+		run.Parameters.Parameters = p.SanitizeParameters([]secrets.Strategy{storage.ValueStrategy("my-second-param", "2")}, run.ID, bun)
+		run = p.TestInstallations.CreateRun(run)
+		p.TestInstallations.CreateResult(run.NewResult(cnab.StatusSucceeded))
+		runs = append(runs, run)
+	}
+
+	opts := RunPruneOptions{KeepLast: 1, DryRun: true}
+	opts.Namespace = "dev"
+	opts.Name = "mybuns"
+	err = p.PruneInstallationRuns(ctx, opts)
+	require.NoError(t, err)
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "The following 2 runs of installation dev/mybuns would be pruned")
+
+	remaining, _, err := p.Installations.ListRuns(ctx, "dev", "mybuns")
+	require.NoError(t, err)
+	assert.Len(t, remaining, 3, "a dry run should not remove any runs")
+
+	opts.DryRun = false
+	err = p.PruneInstallationRuns(ctx, opts)
+	require.NoError(t, err)
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "Pruned 2 runs of installation dev/mybuns")
+
+	remaining, _, err = p.Installations.ListRuns(ctx, "dev", "mybuns")
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, runs[2].ID, remaining[0].ID)
+
+	_, err = p.TestSecrets.Resolve(ctx, secrets.SourceSecret, runs[0].ID+"-my-second-param")
+	require.Error(t, err, "the sensitive data of a pruned run should be removed from the secret store")
+	_, err = p.TestSecrets.Resolve(ctx, secrets.SourceSecret, runs[2].ID+"-my-second-param")
+	require.NoError(t, err, "the sensitive data of a remaining run should be kept")
+}
+
+func TestRunDiffOptions_Validate(t *testing.T) {
+	opts := RunDiffOptions{}
+	opts.RawFormat = "plaintext"
+
+	err := opts.Validate([]string{"abc"})
+	require.ErrorContains(t, err, "two run ids are required")
+
+	err = opts.Validate([]string{"abc", "def"})
+	require.NoError(t, err)
+	assert.Equal(t, "abc", opts.BeforeID)
+	assert.Equal(t, "def", opts.AfterID)
+}
+
+func TestPorter_PrintInstallationRunsDiff(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybuns"))
+	before := p.TestInstallations.CreateRun(storage.NewRun("dev", "mybuns"), func(r *storage.Run) {
+		r.Action = cnab.ActionInstall
+		r.BundleReference = "example.com/mybuns:v1.0.0"
+		r.CredentialSets = []string{"kubeconfig"}
+	})
+	beforeResult := p.TestInstallations.CreateResult(before.NewResult(cnab.StatusSucceeded))
+	p.TestInstallations.CreateOutput(beforeResult.NewOutput("host", []byte("10.0.0.1")))
+	p.TestConfig.TestContext.AddTestFile("testdata/bundle.json", "/bundle.json")
+	bun, err := cnab.LoadBundle(p.Context, "/bundle.json")
+	require.NoError(t, err)
+	p.CreateOutput(beforeResult.NewOutput("my-first-output", []byte("topsecret")), bun)
+
+	after := p.TestInstallations.CreateRun(storage.NewRun("dev", "mybuns"), func(r *storage.Run) {
+		r.Action = cnab.ActionUpgrade
+		r.BundleReference = "example.com/mybuns:v1.1.0"
+		r.CredentialSets = []string{"kubeconfig"}
+	})
+	afterResult := p.TestInstallations.CreateResult(after.NewResult(cnab.StatusSucceeded))
+	p.TestInstallations.CreateOutput(afterResult.NewOutput("host", []byte("10.0.0.2")))
+	p.CreateOutput(afterResult.NewOutput("my-first-output", []byte("rotated")), bun)
+
+	opts := RunDiffOptions{}
+	opts.Format = printer.FormatPlaintext
+	opts.BeforeID = before.ID
+	opts.AfterID = after.ID
+	require.NoError(t, p.PrintInstallationRunsDiff(ctx, opts))
+
+	output := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, output, "Changes from run "+before.ID+" to run "+after.ID+" of installation dev/mybuns")
+	assert.Regexp(t, `Action\s+install\s+upgrade`, output)
+	assert.Regexp(t, `Bundle Reference\s+example.com/mybuns:v1.0.0\s+example.com/mybuns:v1.1.0`, output)
+	assert.Regexp(t, `Output\s+host\s+10.0.0.1\s+10.0.0.2`, output)
+	assert.Regexp(t, `Output\s+my-first-output\s+\*{6}\s+\*{6}`, output, "the sensitive output changed and should be masked")
+	assert.NotContains(t, output, "topsecret")
+	assert.NotContains(t, output, "Credential Set", "the credential sets did not change")
+
+	t.Run("different installations", func(t *testing.T) {
+		other := p.TestInstallations.CreateRun(storage.NewRun("dev", "otherbuns"))
+		opts.AfterID = other.ID
+		err := p.PrintInstallationRunsDiff(ctx, opts)
+		require.ErrorContains(t, err, "cannot compare runs of different installations")
+	})
+}
+
+func TestPorter_PrintInstallationRunsEnvDiff(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybuns"))
+	before := p.TestInstallations.CreateRun(storage.NewRun("dev", "mybuns"), func(r *storage.Run) {
+		r.Action = cnab.ActionInstall
+		r.Environment = &storage.RunEnvironment{
+			PorterVersion: "v1.0.0",
+			Driver:        "docker",
+			DriverVersion: "20.10.7",
+			Mixins:        map[string]string{"helm3": "v0.1.15"},
+			Images:        map[string]string{"invocationImage": "sha256:aaa"},
+		}
+	})
+	after := p.TestInstallations.CreateRun(storage.NewRun("dev", "mybuns"), func(r *storage.Run) {
+		r.Action = cnab.ActionUpgrade
+		r.Environment = &storage.RunEnvironment{
+			PorterVersion: "v1.0.0",
+			Driver:        "docker",
+			DriverVersion: "23.0.1",
+			Mixins:        map[string]string{"helm3": "v0.1.16"},
+			Images:        map[string]string{"invocationImage": "sha256:bbb"},
+		}
+	})
+
+	opts := RunDiffOptions{}
+	opts.Format = printer.FormatPlaintext
+	opts.BeforeID = before.ID
+	opts.AfterID = after.ID
+	require.NoError(t, p.PrintInstallationRunsEnvDiff(ctx, opts))
+
+	output := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, output, "Changes to the environment from run "+before.ID+" to run "+after.ID+" of installation dev/mybuns")
+	assert.Regexp(t, `Driver Version\s+20.10.7\s+23.0.1`, output)
+	assert.Regexp(t, `Mixin\s+helm3\s+v0.1.15\s+v0.1.16`, output)
+	assert.Regexp(t, `Image\s+invocationImage\s+sha256:aaa\s+sha256:bbb`, output)
+	assert.NotContains(t, output, "Porter Version", "the version of Porter did not change")
+
+	t.Run("no recorded environment", func(t *testing.T) {
+		legacy := p.TestInstallations.CreateRun(storage.NewRun("dev", "mybuns"))
+		opts.AfterID = legacy.ID
+		err := p.PrintInstallationRunsEnvDiff(ctx, opts)
+		require.ErrorContains(t, err, "cannot compare the environment of run "+legacy.ID)
+	})
+
+	t.Run("different installations", func(t *testing.T) {
+		other := p.TestInstallations.CreateRun(storage.NewRun("dev", "otherbuns"))
+		opts.AfterID = other.ID
+		err := p.PrintInstallationRunsEnvDiff(ctx, opts)
+		require.ErrorContains(t, err, "cannot compare runs of different installations")
+	})
+}
+
+func TestRunShowOptions_Validate(t *testing.T) {
+	t.Run("run id required", func(t *testing.T) {
+		opts := RunShowOptions{}
+		err := opts.Validate(nil)
+		require.EqualError(t, err, "run id is required")
+	})
+
+	t.Run("show sensitive without outputs", func(t *testing.T) {
+		opts := RunShowOptions{ShowSensitive: true}
+		err := opts.Validate([]string{"abc"})
+		require.EqualError(t, err, "--show-sensitive can only be used with --outputs")
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		opts := RunShowOptions{Outputs: true, ShowSensitive: true}
+		err := opts.Validate([]string{"abc"})
+		require.NoError(t, err)
+		assert.Equal(t, "abc", opts.RunID)
+		assert.Equal(t, printer.FormatPlaintext, opts.Format)
+	})
+}
+
+func TestPorter_PrintInstallationRun(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*TestPorter, storage.Run) {
+		p := NewTestPorter(t)
+
+		p.TestConfig.TestContext.AddTestFile("testdata/bundle.json", "/bundle.json")
+		bun, err := cnab.LoadBundle(p.Context, "/bundle.json")
+		require.NoError(t, err)
+
+		i := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybuns"))
+		run := i.NewRun(cnab.ActionInstall)
+		run.Bundle = bun.Bundle
+		run.BundleReference = "example.com/mybuns:v0.1.0"
+		run.CredentialSets = []string{"kubeconfig"}
+		run.Metadata = &storage.RunMetadata{
+			Source:      "github-actions",
+			Commit:      "abc123",
+			PipelineURL: "https://github.com/getporter/porter/actions/runs/42",
+		}
+		run.OutputDependencies = []storage.RunOutputDependency{
+			{Parameter: "vpc-id", Namespace: "platform", Installation: "network", Output: "vpc-id"},
+		}
+		run = p.TestInstallations.CreateRun(run)
+		p.TestInstallations.CreateResult(run.NewResult(cnab.StatusRunning))
+		result := p.TestInstallations.CreateResult(run.NewResult(cnab.StatusSucceeded))
+		p.CreateOutput(result.NewOutput("my-first-output", []byte("topsecret")), bun)
+		p.CreateOutput(result.NewOutput("my-second-output", []byte("true")), bun)
+
+		return p, run
+	}
+
+	t.Run("without outputs", func(t *testing.T) {
+		p, run := setup(t)
+		defer p.Close()
+
+		opts := RunShowOptions{RunID: run.ID}
+		opts.Format = printer.FormatPlaintext
+		require.NoError(t, p.PrintInstallationRun(ctx, opts))
+
+		output := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, output, "Run ID: "+run.ID)
+		assert.Contains(t, output, "Installation: dev/mybuns")
+		assert.Contains(t, output, "Status: succeeded")
+		assert.Contains(t, output, "Reference: example.com/mybuns:v0.1.0")
+		assert.Contains(t, output, "- kubeconfig")
+		assert.NotContains(t, output, "Outputs:")
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		p, run := setup(t)
+		defer p.Close()
+
+		opts := RunShowOptions{RunID: run.ID}
+		opts.Format = printer.FormatPlaintext
+		require.NoError(t, p.PrintInstallationRun(ctx, opts))
+
+		output := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, output, "Metadata:")
+		assert.Contains(t, output, "Source: github-actions")
+		assert.Contains(t, output, "Commit: abc123")
+		assert.Contains(t, output, "Pipeline: https://github.com/getporter/porter/actions/runs/42")
+		assert.NotContains(t, output, "Branch:", "fields that were not captured should not be printed")
+	})
+
+	t.Run("output dependencies", func(t *testing.T) {
+		p, run := setup(t)
+		defer p.Close()
+
+		opts := RunShowOptions{RunID: run.ID}
+		opts.Format = printer.FormatPlaintext
+		require.NoError(t, p.PrintInstallationRun(ctx, opts))
+
+		output := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, output, "Output Dependencies:")
+		assert.Contains(t, output, "vpc-id: platform/network/vpc-id")
+	})
+
+	t.Run("sensitive outputs masked", func(t *testing.T) {
+		p, run := setup(t)
+		defer p.Close()
+
+		// Remove the sensitive value from the secret store, so that the test
+		// fails if it is resolved when it should be masked
+		require.NoError(t, p.TestSecrets.Delete(ctx, secrets.SourceSecret, run.ID+"-my-first-output"))
+
+		opts := RunShowOptions{RunID: run.ID, Outputs: true}
+		opts.Format = printer.FormatJson
+		require.NoError(t, p.PrintInstallationRun(ctx, opts))
+
+		output := p.TestConfig.TestContext.GetOutput()
+		assert.NotContains(t, output, "topsecret")
+		assert.Contains(t, output, `"value": "******"`)
+		assert.Contains(t, output, `"value": "true"`)
+	})
+
+	t.Run("show sensitive not allowed", func(t *testing.T) {
+		p, run := setup(t)
+		defer p.Close()
+
+		opts := RunShowOptions{RunID: run.ID, Outputs: true, ShowSensitive: true}
+		opts.Format = printer.FormatPlaintext
+		err := p.PrintInstallationRun(ctx, opts)
+		require.ErrorContains(t, err, `revealing sensitive outputs in namespace "dev" is not allowed`)
+		assert.NotContains(t, p.TestConfig.TestContext.GetOutput(), "topsecret")
+	})
+
+	t.Run("show sensitive allowed", func(t *testing.T) {
+		p, run := setup(t)
+		defer p.Close()
+		p.Config.Data.SensitiveOutputPolicy.AllowShowSensitive = []string{"dev"}
+
+		opts := RunShowOptions{RunID: run.ID, Outputs: true, ShowSensitive: true}
+		opts.Format = printer.FormatPlaintext
+		require.NoError(t, p.PrintInstallationRun(ctx, opts))
+
+		output := p.TestConfig.TestContext.GetOutput()
+		assert.Regexp(t, `my-first-output\s+string\s+topsecret`, output)
+		assert.Regexp(t, `my-second-output\s+boolean\s+true`, output)
+	})
+
+	t.Run("step results", func(t *testing.T) {
+		p, run := setup(t)
+		defer p.Close()
+
+		started := now
+		require.NoError(t, p.Installations.InsertStepResults(ctx, []storage.StepResult{
+			run.NewStepResult(storage.StepResult{Index: 0, Description: "Install mysql", Mixin: "helm3", Started: started, Stopped: started.Add(90 * time.Second), Status: storage.StepStatusSucceeded}),
+			run.NewStepResult(storage.StepResult{Index: 1, Description: "Create the database", Mixin: "exec", Started: started.Add(90 * time.Second), Stopped: started.Add(92 * time.Second), Status: storage.StepStatusFailed, ExitCode: 2, Error: "mixin execution failed", Logs: "database already exists"}),
+		}))
+
+		opts := RunShowOptions{RunID: run.ID}
+		opts.Format = printer.FormatJson
+		require.NoError(t, p.PrintInstallationRun(ctx, opts))
+
+		var displayRun DisplayRunDetails
+		require.NoError(t, json.Unmarshal([]byte(p.TestConfig.TestContext.GetOutput()), &displayRun))
+		require.Len(t, displayRun.Steps, 2)
+		assert.Equal(t, "helm3", displayRun.Steps[0].Mixin)
+		assert.Equal(t, "1m30s", displayRun.Steps[0].Duration)
+		assert.Equal(t, storage.StepStatusFailed, displayRun.Steps[1].Status)
+		assert.Equal(t, 2, displayRun.Steps[1].ExitCode)
+		assert.Equal(t, "database already exists", displayRun.Steps[1].Logs)
+
+		p.TestConfig.TestContext.ClearOutputs()
+		opts.Format = printer.FormatPlaintext
+		require.NoError(t, p.PrintInstallationRun(ctx, opts))
+		output := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, output, "Steps:")
+		assert.Regexp(t, `2\s+Create the database\s+exec\s+2s\s+failed\s+2`, output)
+	})
+}
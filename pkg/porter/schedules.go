@@ -0,0 +1,268 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	dtprinter "github.com/carolynvs/datetime-printer"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ScheduleCreateOptions represent options for Porter's installation runs schedule create command.
+type ScheduleCreateOptions struct {
+	// Installation on which the action is run.
+	Installation string
+
+	// Namespace of the installation.
+	Namespace string
+
+	// Name of the schedule. Defaults to INSTALLATION-ACTION.
+	Name string
+
+	// Action to run.
+	Action string
+
+	// Cron expression that determines when the action is run.
+	Cron string
+
+	// Params is the unparsed list of NAME=VALUE parameters passed to the action.
+	Params []string
+
+	// ParameterSets used by the action.
+	ParameterSets []string
+
+	// CredentialSets used by the action.
+	CredentialSets []string
+
+	// Disabled creates the schedule without running it.
+	Disabled bool
+}
+
+// Validate the args provided to Porter's installation runs schedule create command.
+func (o *ScheduleCreateOptions) Validate(args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("no installation name was specified")
+	case 1:
+		o.Installation = args[0]
+	default:
+		return fmt.Errorf("only one positional argument may be specified, the installation name, but multiple were received: %s", args)
+	}
+
+	if o.Action == "" {
+		return errors.New("--action is required")
+	}
+	if o.Cron == "" {
+		return errors.New("--cron is required")
+	}
+	if o.Name == "" {
+		o.Name = fmt.Sprintf("%s-%s", o.Installation, o.Action)
+	}
+	if _, err := storage.ParseVariableAssignments(o.Params); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ToSchedule creates the schedule document defined by the options.
+func (o ScheduleCreateOptions) ToSchedule() storage.Schedule {
+	schedule := storage.NewSchedule(o.Namespace, o.Name)
+	schedule.Installation = o.Installation
+	schedule.Action = o.Action
+	schedule.Cron = o.Cron
+	schedule.Parameters = o.Params
+	schedule.ParameterSets = o.ParameterSets
+	schedule.CredentialSets = o.CredentialSets
+	schedule.Disabled = o.Disabled
+	return schedule
+}
+
+// CreateSchedule saves a new schedule for an action on an installation.
+func (p *Porter) CreateSchedule(ctx context.Context, opts ScheduleCreateOptions) error {
+	ctx, span := tracing.StartSpan(ctx,
+		attribute.String("namespace", opts.Namespace),
+		attribute.String("installation", opts.Installation),
+		attribute.String("schedule", opts.Name))
+	defer span.EndSpan()
+
+	schedule := opts.ToSchedule()
+	if err := schedule.Validate(); err != nil {
+		return span.Error(err)
+	}
+	if err := schedule.ScheduleNext(time.Now()); err != nil {
+		return span.Error(err)
+	}
+	if schedule.Status.NextRun.IsZero() {
+		return span.Error(fmt.Errorf("the cron expression %q never matches a time", schedule.Cron))
+	}
+
+	if _, err := p.Installations.GetInstallation(ctx, schedule.Namespace, schedule.Installation); err != nil {
+		return span.Error(fmt.Errorf("could not retrieve installation %s/%s: %w", schedule.Namespace, schedule.Installation, err))
+	}
+
+	_, err := p.Schedules.GetSchedule(ctx, schedule.Namespace, schedule.Name)
+	if err == nil {
+		return span.Error(fmt.Errorf("schedule %s already exists", schedule))
+	}
+	if !errors.Is(err, storage.ErrNotFound{}) {
+		return span.Error(fmt.Errorf("could not query for an existing schedule %s: %w", schedule, err))
+	}
+
+	if err = p.Schedules.InsertSchedule(ctx, schedule); err != nil {
+		return span.Error(fmt.Errorf("unable to save schedule %s: %w", schedule, err))
+	}
+
+	fmt.Fprintf(p.Out, "Created schedule %s, the %s action will next run on %s at %s\n",
+		schedule.Name, schedule.Action, schedule.Installation, schedule.Status.NextRun.Format(time.RFC3339))
+	return nil
+}
+
+// ScheduleListOptions represent options for Porter's installation runs schedule list command.
+type ScheduleListOptions struct {
+	printer.PrintOptions
+
+	// Installation filters the schedules to those of a single installation.
+	Installation  string
+	Namespace     string
+	AllNamespaces bool
+}
+
+// Validate the args provided to Porter's installation runs schedule list command.
+func (o *ScheduleListOptions) Validate(args []string) error {
+	switch len(args) {
+	case 0:
+	case 1:
+		o.Installation = args[0]
+	default:
+		return fmt.Errorf("only one positional argument may be specified, the installation name, but multiple were received: %s", args)
+	}
+	return o.ParseFormat()
+}
+
+func (o ScheduleListOptions) GetNamespace() string {
+	if o.AllNamespaces {
+		return "*"
+	}
+	return o.Namespace
+}
+
+// ListSchedules lists the saved schedules.
+func (p *Porter) ListSchedules(ctx context.Context, opts ScheduleListOptions) ([]storage.Schedule, error) {
+	schedules, err := p.Schedules.ListSchedules(ctx, storage.ListOptions{Namespace: opts.GetNamespace()})
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Installation == "" {
+		return schedules, nil
+	}
+
+	results := make([]storage.Schedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		if schedule.Installation == opts.Installation {
+			results = append(results, schedule)
+		}
+	}
+	return results, nil
+}
+
+// PrintSchedules prints the saved schedules.
+func (p *Porter) PrintSchedules(ctx context.Context, opts ScheduleListOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	schedules, err := p.ListSchedules(ctx, opts)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, schedules)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, schedules)
+	case printer.FormatPlaintext:
+		// have every row use the same "now" starting ... NOW!
+		now := time.Now()
+		tp := dtprinter.DateTimePrinter{
+			Now: func() time.Time { return now },
+		}
+
+		row :=
+			func(v interface{}) []string {
+				schedule, ok := v.(storage.Schedule)
+				if !ok {
+					return nil
+				}
+				return []string{schedule.Namespace, schedule.Name, schedule.Installation, schedule.Action, schedule.Cron,
+					getDisplayScheduleNextRun(tp, schedule), getDisplayScheduleLastRun(tp, schedule)}
+			}
+		return printer.PrintTable(p.Out, schedules, row,
+			"NAMESPACE", "NAME", "INSTALLATION", "ACTION", "CRON", "NEXT RUN", "LAST RUN")
+	default:
+		return span.Error(fmt.Errorf("invalid format: %s", opts.Format))
+	}
+}
+
+func getDisplayScheduleNextRun(tp dtprinter.DateTimePrinter, schedule storage.Schedule) string {
+	if schedule.Disabled {
+		return "disabled"
+	}
+	return tp.Format(schedule.Status.NextRun)
+}
+
+func getDisplayScheduleLastRun(tp dtprinter.DateTimePrinter, schedule storage.Schedule) string {
+	if schedule.Status.LastRun == nil {
+		return ""
+	}
+	if schedule.Status.LastError != "" {
+		return tp.Format(*schedule.Status.LastRun) + " (failed)"
+	}
+	return tp.Format(*schedule.Status.LastRun)
+}
+
+// ScheduleDeleteOptions represent options for Porter's installation runs schedule delete command.
+type ScheduleDeleteOptions struct {
+	Name      string
+	Namespace string
+}
+
+// Validate the args provided to Porter's installation runs schedule delete command.
+func (o *ScheduleDeleteOptions) Validate(args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("no schedule name was specified")
+	case 1:
+		o.Name = args[0]
+		return nil
+	default:
+		return fmt.Errorf("only one positional argument may be specified, the schedule name, but multiple were received: %s", args)
+	}
+}
+
+// DeleteSchedule removes a schedule. The runs created by the schedule are not removed.
+func (p *Porter) DeleteSchedule(ctx context.Context, opts ScheduleDeleteOptions) error {
+	ctx, span := tracing.StartSpan(ctx,
+		attribute.String("namespace", opts.Namespace),
+		attribute.String("schedule", opts.Name))
+	defer span.EndSpan()
+
+	_, err := p.Schedules.GetSchedule(ctx, opts.Namespace, opts.Name)
+	if errors.Is(err, storage.ErrNotFound{}) {
+		span.Debug("nothing to remove, schedule already does not exist")
+		return nil
+	}
+	if err != nil {
+		return span.Error(fmt.Errorf("could not retrieve schedule %s/%s: %w", opts.Namespace, opts.Name, err))
+	}
+
+	if err = p.Schedules.RemoveSchedule(ctx, opts.Namespace, opts.Name); err != nil {
+		return span.Error(fmt.Errorf("unable to delete schedule %s/%s: %w", opts.Namespace, opts.Name, err))
+	}
+	return nil
+}
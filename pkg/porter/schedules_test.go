@@ -0,0 +1,113 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleCreateOptions_Validate(t *testing.T) {
+	opts := ScheduleCreateOptions{Action: "backup", Cron: "@daily"}
+	require.EqualError(t, opts.Validate(nil), "no installation name was specified")
+	require.ErrorContains(t, opts.Validate([]string{"mysql", "redis"}), "only one positional argument may be specified")
+
+	require.NoError(t, opts.Validate([]string{"mysql"}))
+	assert.Equal(t, "mysql", opts.Installation)
+	assert.Equal(t, "mysql-backup", opts.Name, "the name should default to INSTALLATION-ACTION")
+
+	opts = ScheduleCreateOptions{Cron: "@daily"}
+	require.EqualError(t, opts.Validate([]string{"mysql"}), "--action is required")
+
+	opts = ScheduleCreateOptions{Action: "backup"}
+	require.EqualError(t, opts.Validate([]string{"mysql"}), "--cron is required")
+}
+
+func TestPorter_CreateSchedule(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	opts := ScheduleCreateOptions{
+		Installation:  "mysql",
+		Namespace:     "dev",
+		Name:          "nightly-backup",
+		Action:        "backup",
+		Cron:          "0 2 * * *",
+		Params:        []string{"retention=30d"},
+		ParameterSets: []string{"backup-storage"},
+	}
+	err := p.CreateSchedule(ctx, opts)
+	require.ErrorContains(t, err, "could not retrieve installation dev/mysql")
+
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+	require.NoError(t, p.CreateSchedule(ctx, opts))
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "Created schedule nightly-backup, the backup action will next run on mysql at")
+
+	schedule, err := p.Schedules.GetSchedule(ctx, "dev", "nightly-backup")
+	require.NoError(t, err)
+	assert.Equal(t, "mysql", schedule.Installation)
+	assert.Equal(t, "backup", schedule.Action)
+	assert.Equal(t, []string{"retention=30d"}, schedule.Parameters)
+	assert.Equal(t, []string{"backup-storage"}, schedule.ParameterSets)
+	assert.Equal(t, 2, schedule.Status.NextRun.Hour())
+	assert.Equal(t, 0, schedule.Status.NextRun.Minute())
+
+	err = p.CreateSchedule(ctx, opts)
+	require.EqualError(t, err, "schedule dev/nightly-backup already exists")
+
+	opts.Name = "invalid"
+	opts.Cron = "0 2 * *"
+	err = p.CreateSchedule(ctx, opts)
+	require.ErrorContains(t, err, "invalid cron expression for schedule invalid")
+
+	opts.Cron = "0 0 31 2 *"
+	err = p.CreateSchedule(ctx, opts)
+	require.ErrorContains(t, err, "never matches a time")
+}
+
+func TestPorter_PrintSchedules(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "redis"))
+	require.NoError(t, p.CreateSchedule(ctx, ScheduleCreateOptions{Installation: "mysql", Namespace: "dev", Name: "mysql-backup", Action: "backup", Cron: "@daily"}))
+	require.NoError(t, p.CreateSchedule(ctx, ScheduleCreateOptions{Installation: "redis", Namespace: "dev", Name: "redis-flush", Action: "flush", Cron: "@hourly", Disabled: true}))
+	p.TestConfig.TestContext.ClearOutputs()
+
+	opts := ScheduleListOptions{Namespace: "dev"}
+	require.NoError(t, opts.Validate([]string{"redis"}))
+	require.NoError(t, p.PrintSchedules(ctx, opts))
+	output := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, output, "redis-flush")
+	assert.Contains(t, output, "disabled")
+	assert.NotContains(t, output, "mysql-backup")
+
+	p.TestConfig.TestContext.ClearOutputs()
+	opts = ScheduleListOptions{AllNamespaces: true, PrintOptions: printer.PrintOptions{RawFormat: "json"}}
+	require.NoError(t, opts.Validate(nil))
+	require.NoError(t, p.PrintSchedules(ctx, opts))
+	output = p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, output, `"name": "mysql-backup"`)
+	assert.Contains(t, output, `"name": "redis-flush"`)
+}
+
+func TestPorter_DeleteSchedule(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+	require.NoError(t, p.CreateSchedule(ctx, ScheduleCreateOptions{Installation: "mysql", Namespace: "dev", Name: "mysql-backup", Action: "backup", Cron: "@daily"}))
+
+	require.NoError(t, p.DeleteSchedule(ctx, ScheduleDeleteOptions{Namespace: "dev", Name: "mysql-backup"}))
+	_, err := p.Schedules.GetSchedule(ctx, "dev", "mysql-backup")
+	require.ErrorIs(t, err, storage.ErrNotFound{})
+
+	require.NoError(t, p.DeleteSchedule(ctx, ScheduleDeleteOptions{Namespace: "dev", Name: "mysql-backup"}), "deleting a missing schedule should not fail")
+}
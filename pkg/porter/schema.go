@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"get.porter.sh/porter/pkg/mixin"
 	"get.porter.sh/porter/pkg/tracing"
 	"github.com/PaesslerAG/jsonpath"
 )
@@ -14,8 +15,14 @@ import (
 type jsonSchema = map[string]interface{}
 type jsonObject = map[string]interface{}
 
-func (p *Porter) PrintManifestSchema(ctx context.Context) error {
-	schemaMap, err := p.GetManifestSchema(ctx)
+// SchemaOptions are the options for generating the porter manifest schema.
+type SchemaOptions struct {
+	// NoCache queries each mixin for its schema instead of using the cached schemas.
+	NoCache bool
+}
+
+func (p *Porter) PrintManifestSchema(ctx context.Context, opts SchemaOptions) error {
+	schemaMap, err := p.GetManifestSchema(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -29,7 +36,7 @@ func (p *Porter) PrintManifestSchema(ctx context.Context) error {
 	return nil
 }
 
-func (p *Porter) GetManifestSchema(ctx context.Context) (jsonSchema, error) {
+func (p *Porter) GetManifestSchema(ctx context.Context, opts SchemaOptions) (jsonSchema, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.EndSpan()
 
@@ -52,7 +59,7 @@ func (p *Porter) GetManifestSchema(ctx context.Context) (jsonSchema, error) {
 		return nil, span.Error(fmt.Errorf("could not unmarshal the root porter manifest schema: %w", err))
 	}
 
-	combinedSchema, err := p.injectMixinSchemas(ctx, manifestSchema)
+	combinedSchema, err := p.injectMixinSchemas(ctx, manifestSchema, opts)
 	if err != nil {
 		span.Warn(err.Error())
 		// Fallback to the porter schema, without any mixins
@@ -62,7 +69,7 @@ func (p *Porter) GetManifestSchema(ctx context.Context) (jsonSchema, error) {
 	return combinedSchema, nil
 }
 
-func (p *Porter) injectMixinSchemas(ctx context.Context, manifestSchema jsonSchema) (jsonSchema, error) {
+func (p *Porter) injectMixinSchemas(ctx context.Context, manifestSchema jsonSchema, opts SchemaOptions) (jsonSchema, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.EndSpan()
 
@@ -126,11 +133,15 @@ func (p *Porter) injectMixinSchemas(ctx context.Context, manifestSchema jsonSche
 	}
 
 	// If there is an error with any mixin, print a warning and skip the mixin, do not return an error
-	for _, mixin := range mixins {
-		mixinSchema, err := p.Mixins.GetSchema(ctx, mixin)
-		if err != nil {
+	mixinSchemas, err := mixin.GetAllSchemas(ctx, p.Mixins, mixins, mixin.SchemaOptions{NoCache: opts.NoCache})
+	if err != nil {
+		span.Debugf("not all mixins reported their schema: %w", err)
+	}
+
+	for i, mixin := range mixins {
+		mixinSchema := mixinSchemas[i]
+		if mixinSchema == "" {
 			// if a mixin can't report its schema, don't include it and keep going
-			span.Debugf("could not query mixin %s for its schema: %w", mixin, err)
 			continue
 		}
 
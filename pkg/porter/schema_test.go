@@ -12,7 +12,7 @@ func TestPorter_PrintManifestSchema(t *testing.T) {
 	p := NewTestPorter(t)
 	defer p.Close()
 
-	err := p.PrintManifestSchema(ctx)
+	err := p.PrintManifestSchema(ctx, SchemaOptions{})
 	require.NoError(t, err)
 
 	p.CompareGoldenFile("testdata/schema.json", p.TestConfig.TestContext.GetOutput())
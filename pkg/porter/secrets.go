@@ -0,0 +1,128 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SecretGenerateOptions represent options for Porter's secrets generate command.
+type SecretGenerateOptions struct {
+	printer.PrintOptions
+
+	// Name of the secret where the generated value is saved. The public key of
+	// a generated key pair is saved to NAME-public.
+	Name string
+
+	// Type of the secret to generate. Allowed values are: password, rsa, ssh.
+	Type string
+
+	// Length is the number of characters in a generated password.
+	Length int
+
+	// Bits is the size of a generated RSA key.
+	Bits int
+
+	// Force overwrites existing secrets with the same name.
+	Force bool
+}
+
+// Validate the options provided to Porter's secrets generate command.
+func (o *SecretGenerateOptions) Validate() error {
+	if o.Name == "" {
+		return errors.New("--name is required")
+	}
+	if strings.ContainsAny(o.Name, " \t\r\n") {
+		return fmt.Errorf("invalid --name %q, the name of a secret cannot contain whitespace", o.Name)
+	}
+
+	switch o.Type {
+	case secrets.GeneratePassword, secrets.GenerateRSA, secrets.GenerateSSH:
+	case "":
+		return fmt.Errorf("--type is required, allowed values are: %s, %s, %s", secrets.GeneratePassword, secrets.GenerateRSA, secrets.GenerateSSH)
+	default:
+		return fmt.Errorf("invalid --type %q, allowed values are: %s, %s, %s", o.Type, secrets.GeneratePassword, secrets.GenerateRSA, secrets.GenerateSSH)
+	}
+
+	if o.Length != 0 && o.Type != secrets.GeneratePassword {
+		return fmt.Errorf("--length can only be used with --type %s", secrets.GeneratePassword)
+	}
+	if o.Bits != 0 && o.Type != secrets.GenerateRSA {
+		return fmt.Errorf("--bits can only be used with --type %s", secrets.GenerateRSA)
+	}
+
+	return o.ParseFormat()
+}
+
+// publicKeyName is the name of the secret where the public key of a
+// generated key pair is saved.
+func (o SecretGenerateOptions) publicKeyName() string {
+	return o.Name + "-public"
+}
+
+// GenerateSecret generates a secret value, saves it with the configured secrets
+// plugin, and prints how to reference the secret from a parameter or
+// credential set.
+func (p *Porter) GenerateSecret(ctx context.Context, opts SecretGenerateOptions) error {
+	ctx, span := tracing.StartSpan(ctx, attribute.String("name", opts.Name), attribute.String("type", opts.Type))
+	defer span.EndSpan()
+
+	generated, err := secrets.Generate(secrets.GenerateOptions{
+		Type:   opts.Type,
+		Length: opts.Length,
+		Bits:   opts.Bits,
+	})
+	if err != nil {
+		return span.Error(err)
+	}
+
+	toSave := []secrets.Secret{{KeyName: secrets.SourceSecret, KeyValue: opts.Name, Value: generated.Value}}
+	if generated.PublicKey != "" {
+		toSave = append(toSave, secrets.Secret{KeyName: secrets.SourceSecret, KeyValue: opts.publicKeyName(), Value: generated.PublicKey})
+	}
+
+	if !opts.Force {
+		for _, secret := range toSave {
+			// Only check that a value exists, so that it isn't accidentally replaced
+			if _, err := p.Secrets.Resolve(ctx, secrets.SourceSecret, secret.KeyValue); err == nil {
+				return span.Error(fmt.Errorf("secret %s already exists, use --force to overwrite it", secret.KeyValue))
+			}
+		}
+	}
+
+	if err = secrets.CreateMultiple(ctx, p.Secrets, toSave); err != nil {
+		return span.Error(fmt.Errorf("error saving the generated %s secret %s: %w", opts.Type, opts.Name, err))
+	}
+
+	strategies := make([]secrets.Strategy, 0, len(toSave))
+	for _, secret := range toSave {
+		strategies = append(strategies, secrets.Strategy{
+			Name:   secret.KeyValue,
+			Source: secrets.Source{Key: secrets.SourceSecret, Value: secret.KeyValue},
+		})
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, strategies)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, strategies)
+	case printer.FormatPlaintext:
+		if generated.PublicKey != "" {
+			fmt.Fprintf(p.Out, "Generated a %s key pair and saved the private key to the secret %s, and the public key to the secret %s.\n", opts.Type, opts.Name, opts.publicKeyName())
+		} else {
+			fmt.Fprintf(p.Out, "Generated a %s and saved it to the secret %s.\n", opts.Type, opts.Name)
+		}
+		fmt.Fprintln(p.Out, "Reference the secret in a parameter or credential set with:")
+		fmt.Fprintln(p.Out)
+		return printer.PrintYaml(p.Out, strategies)
+	default:
+		return fmt.Errorf("invalid format: %s", opts.Format)
+	}
+}
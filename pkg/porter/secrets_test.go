@@ -0,0 +1,118 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretGenerateOptions_Validate(t *testing.T) {
+	testcases := []struct {
+		name      string
+		opts      SecretGenerateOptions
+		wantError string
+	}{
+		{name: "password", opts: SecretGenerateOptions{Name: "db-password", Type: "password", Length: 64}},
+		{name: "rsa", opts: SecretGenerateOptions{Name: "signing-key", Type: "rsa", Bits: 2048}},
+		{name: "ssh", opts: SecretGenerateOptions{Name: "deploy-key", Type: "ssh"}},
+		{name: "missing name", opts: SecretGenerateOptions{Type: "password"}, wantError: "--name is required"},
+		{name: "whitespace in name", opts: SecretGenerateOptions{Name: "db password", Type: "password"}, wantError: `invalid --name "db password", the name of a secret cannot contain whitespace`},
+		{name: "missing type", opts: SecretGenerateOptions{Name: "db-password"}, wantError: "--type is required, allowed values are: password, rsa, ssh"},
+		{name: "invalid type", opts: SecretGenerateOptions{Name: "db-password", Type: "oops"}, wantError: `invalid --type "oops", allowed values are: password, rsa, ssh`},
+		{name: "length without password", opts: SecretGenerateOptions{Name: "deploy-key", Type: "ssh", Length: 10}, wantError: "--length can only be used with --type password"},
+		{name: "bits without rsa", opts: SecretGenerateOptions{Name: "db-password", Type: "password", Bits: 2048}, wantError: "--bits can only be used with --type rsa"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.opts.Validate()
+			if tc.wantError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.wantError)
+			}
+		})
+	}
+}
+
+func TestPorter_GenerateSecret(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("password", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := SecretGenerateOptions{Name: "db-password", Type: secrets.GeneratePassword}
+		require.NoError(t, opts.Validate())
+		require.NoError(t, p.GenerateSecret(ctx, opts))
+
+		value, err := p.Secrets.Resolve(ctx, secrets.SourceSecret, "db-password")
+		require.NoError(t, err, "the generated password should be saved to the secret store")
+		assert.Len(t, value, secrets.DefaultPasswordLength)
+
+		output := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, output, "Generated a password and saved it to the secret db-password.")
+		assert.Contains(t, output, "- name: db-password\n  source:\n    secret: db-password\n")
+		assert.NotContains(t, output, value, "the generated value should not be printed")
+	})
+
+	t.Run("key pair", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := SecretGenerateOptions{Name: "deploy-key", Type: secrets.GenerateSSH, PrintOptions: printer.PrintOptions{RawFormat: "json"}}
+		require.NoError(t, opts.Validate())
+		require.NoError(t, p.GenerateSecret(ctx, opts))
+
+		privateKey, err := p.Secrets.Resolve(ctx, secrets.SourceSecret, "deploy-key")
+		require.NoError(t, err)
+		assert.Contains(t, privateKey, "OPENSSH PRIVATE KEY")
+		publicKey, err := p.Secrets.Resolve(ctx, secrets.SourceSecret, "deploy-key-public")
+		require.NoError(t, err)
+		assert.Contains(t, publicKey, "ssh-ed25519 ")
+
+		wantOutput := `[
+  {
+    "name": "deploy-key",
+    "source": {
+      "secret": "deploy-key"
+    }
+  },
+  {
+    "name": "deploy-key-public",
+    "source": {
+      "secret": "deploy-key-public"
+    }
+  }
+]
+`
+		assert.Equal(t, wantOutput, p.TestConfig.TestContext.GetOutput())
+	})
+
+	t.Run("existing secret", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		require.NoError(t, p.Secrets.Create(ctx, secrets.SourceSecret, "db-password", "original"))
+
+		opts := SecretGenerateOptions{Name: "db-password", Type: secrets.GeneratePassword}
+		require.NoError(t, opts.Validate())
+		err := p.GenerateSecret(ctx, opts)
+		require.EqualError(t, err, "secret db-password already exists, use --force to overwrite it")
+
+		value, err := p.Secrets.Resolve(ctx, secrets.SourceSecret, "db-password")
+		require.NoError(t, err)
+		assert.Equal(t, "original", value, "the existing secret should not be replaced")
+
+		opts.Force = true
+		require.NoError(t, p.GenerateSecret(ctx, opts))
+		value, err = p.Secrets.Resolve(ctx, secrets.SourceSecret, "db-password")
+		require.NoError(t, err)
+		assert.NotEqual(t, "original", value, "the existing secret should be replaced with --force")
+	})
+}
@@ -165,6 +165,15 @@ func (p *Porter) ShowInstallation(ctx context.Context, opts ShowOptions) error {
 			fmt.Fprintf(p.Out, "  Digest: %s\n", displayInstallation.Status.BundleDigest)
 		}
 
+		// Print notes attached to the last run, if any
+		if run != nil && len(run.Notes) > 0 {
+			fmt.Fprintln(p.Out)
+			fmt.Fprintln(p.Out, "Notes:")
+			for _, note := range run.Notes {
+				fmt.Fprintf(p.Out, "  %s: %s\n", tp.Format(note.Created), note.Note)
+			}
+		}
+
 		return nil
 	default:
 		return fmt.Errorf("invalid format: %s", opts.Format)
@@ -182,7 +191,7 @@ func (p *Porter) NewDisplayInstallationWithSecrets(ctx context.Context, installa
 		}
 		displayInstallation.Parameters = installParams
 
-		runParams, err := p.Sanitizer.RestoreParameterSet(ctx, run.Parameters, bun)
+		runParams, err := p.Sanitizer.RestoreParameterSet(ctx, run.GetParameters(), bun)
 		if err != nil {
 			return DisplayInstallation{}, err
 		}
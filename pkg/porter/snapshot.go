@@ -0,0 +1,81 @@
+package porter
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// snapshotNamePattern restricts snapshot names to characters that are safe to
+// use in scripts and on the command line.
+var snapshotNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// SnapshotOptions represent options for taking a snapshot of the outputs of an installation.
+type SnapshotOptions struct {
+	installationOptions
+
+	// SnapshotName is the name of the snapshot. Defaults to the current time.
+	SnapshotName string
+}
+
+// Validate prepares for the snapshot installation action and validates the args/options.
+func (o *SnapshotOptions) Validate(args []string) error {
+	if err := o.installationOptions.validateInstallationName(args); err != nil {
+		return err
+	}
+
+	if o.SnapshotName != "" && !snapshotNamePattern.MatchString(o.SnapshotName) {
+		return fmt.Errorf("invalid --snapshot %q, it may only contain letters, numbers, dashes, underscores and periods, and must start with a letter or number", o.SnapshotName)
+	}
+
+	return nil
+}
+
+// SnapshotInstallation captures the current outputs of an installation,
+// including the state of the bundle, as a named snapshot that can be restored
+// with the --restore-snapshot flag when the bundle is upgraded or invoked.
+func (p *Porter) SnapshotInstallation(ctx context.Context, opts SnapshotOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := p.applyDefaultOptions(ctx, &opts.installationOptions); err != nil {
+		return err
+	}
+
+	installation, err := p.Installations.GetInstallation(ctx, opts.Namespace, opts.Name)
+	if err != nil {
+		return span.Error(fmt.Errorf("could not retrieve installation %s/%s: %w", opts.Namespace, opts.Name, err))
+	}
+
+	lastOutputs, err := p.Installations.GetLastOutputs(ctx, installation.Namespace, installation.Name)
+	if err != nil {
+		return span.Error(fmt.Errorf("could not retrieve the outputs of installation %s: %w", installation, err))
+	}
+
+	// The logs and step results describe a run, they are not state of the installation
+	var outputs []storage.Output
+	for _, output := range lastOutputs.Value() {
+		if output.Name == cnab.OutputInvocationImageLogs || output.Name == storage.StepResultsOutput {
+			continue
+		}
+		outputs = append(outputs, output)
+	}
+
+	name := opts.SnapshotName
+	if name == "" {
+		name = time.Now().UTC().Format("20060102-150405")
+	}
+
+	snapshot := storage.NewInstallationSnapshot(installation, name, storage.NewOutputs(outputs))
+	if err = p.Installations.InsertSnapshot(ctx, snapshot); err != nil {
+		return span.Error(fmt.Errorf("could not save snapshot %s: %w", name, err))
+	}
+
+	fmt.Fprintf(p.Out, "Created snapshot %s of installation %s with %d outputs\n", snapshot.Name, installation, len(snapshot.Outputs))
+	return nil
+}
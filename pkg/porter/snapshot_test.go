@@ -0,0 +1,114 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotOptions_Validate(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name         string
+		args         []string
+		snapshotName string
+		wantError    string
+	}{
+		{name: "default name", args: []string{"mybuns"}},
+		{name: "valid name", args: []string{"mybuns"}, snapshotName: "before-v1.2.0_migration"},
+		{name: "invalid name", args: []string{"mybuns"}, snapshotName: "../oops", wantError: `invalid --snapshot "../oops"`},
+		{name: "multiple args", args: []string{"mybuns", "yourbuns"}, wantError: "only one positional argument may be specified"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := SnapshotOptions{SnapshotName: tc.snapshotName}
+			err := opts.Validate(tc.args)
+			if tc.wantError != "" {
+				require.ErrorContains(t, err, tc.wantError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.args[0], opts.Name)
+			}
+		})
+	}
+}
+
+func TestPorter_SnapshotInstallation(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+	ctx := context.Background()
+
+	i := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybuns"))
+	c := p.TestInstallations.CreateRun(i.NewRun(cnab.ActionInstall))
+	cr := p.TestInstallations.CreateResult(c.NewResult(cnab.StatusSucceeded))
+	p.TestInstallations.CreateOutput(cr.NewOutput("connstr", []byte("v1")))
+	p.TestInstallations.CreateOutput(cr.NewOutput(cnab.OutputInvocationImageLogs, []byte("installing...")))
+
+	opts := SnapshotOptions{SnapshotName: "before-upgrade"}
+	opts.Namespace = "dev"
+	opts.Name = "mybuns"
+	require.NoError(t, p.SnapshotInstallation(ctx, opts))
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "Created snapshot before-upgrade of installation dev/mybuns with 1 outputs")
+
+	snapshot, err := p.Installations.GetSnapshot(ctx, "dev", "mybuns", "before-upgrade")
+	require.NoError(t, err, "GetSnapshot failed")
+	require.Len(t, snapshot.Outputs, 1, "the logs should not be captured by the snapshot")
+	assert.Equal(t, "connstr", snapshot.Outputs[0].Name)
+	assert.Equal(t, c.ID, snapshot.Outputs[0].RunID)
+
+	err = p.SnapshotInstallation(ctx, opts)
+	require.ErrorContains(t, err, "installation dev/mybuns already has a snapshot named before-upgrade")
+}
+
+func TestRuntime_ResolveParameterSources_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	r := NewTestPorter(t)
+	defer r.Close()
+	ctx := context.Background()
+
+	r.TestConfig.TestContext.AddTestFile("testdata/bundle-with-param-sources.json", "bundle.json")
+	bun, err := cnab.LoadBundle(r.Context, "bundle.json")
+	require.NoError(t, err, "ProcessBundle failed")
+
+	mysql := r.TestInstallations.CreateInstallation(storage.NewInstallation("", "mybun-mysql"))
+	c := r.TestInstallations.CreateRun(mysql.NewRun(cnab.ActionInstall), func(r *storage.Run) { r.Bundle = bun.Bundle })
+	cr := r.TestInstallations.CreateResult(c.NewResult(cnab.StatusSucceeded))
+	r.TestInstallations.CreateOutput(cr.NewOutput("connstr", []byte("connstr value")))
+
+	i := r.TestInstallations.CreateInstallation(storage.NewInstallation("", "mybun"))
+	c = r.TestInstallations.CreateRun(i.NewRun(cnab.ActionInstall), func(r *storage.Run) { r.Bundle = bun.Bundle })
+	cr = r.TestInstallations.CreateResult(c.NewResult(cnab.StatusSucceeded))
+	r.TestInstallations.CreateOutput(cr.NewOutput("bar", []byte("bar v1")))
+
+	lastOutputs, err := r.Installations.GetLastOutputs(ctx, i.Namespace, i.Name)
+	require.NoError(t, err)
+	snapshot := storage.NewInstallationSnapshot(i, "v1", lastOutputs)
+	require.NoError(t, r.Installations.InsertSnapshot(ctx, snapshot))
+
+	c = r.TestInstallations.CreateRun(i.NewRun(cnab.ActionUpgrade), func(r *storage.Run) { r.Bundle = bun.Bundle })
+	cr = r.TestInstallations.CreateResult(c.NewResult(cnab.StatusSucceeded))
+	r.TestInstallations.CreateOutput(cr.NewOutput("bar", []byte("bar v2")))
+
+	got, _, err := r.resolveParameterSources(ctx, bun, i, nil)
+	require.NoError(t, err, "resolveParameterSources failed")
+	assert.Equal(t, "bar v2", got["bar"], "the most recent output should be used without a snapshot")
+
+	got, _, err = r.resolveParameterSources(ctx, bun, i, &snapshot)
+	require.NoError(t, err, "resolveParameterSources failed")
+	want := secrets.Set{
+		"bar":     "bar v1",
+		"connstr": "connstr value",
+	}
+	assert.Equal(t, want, got, "the output from the snapshot should be used, and outputs of other installations are not restored")
+}
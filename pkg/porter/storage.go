@@ -9,30 +9,50 @@ import (
 	"path/filepath"
 
 	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/printer"
 	"get.porter.sh/porter/pkg/storage"
 	"get.porter.sh/porter/pkg/tracing"
 	"github.com/hashicorp/go-multierror"
 )
 
 type MigrateStorageOptions struct {
+	printer.PrintOptions
+
 	OldHome           string
 	OldStorageAccount string
 	Namespace         string
+
+	// DryRun reports the runs that would be migrated to the current schema
+	// without saving them.
+	DryRun bool
 }
 
-func (o MigrateStorageOptions) Validate() error {
+func (o *MigrateStorageOptions) Validate() error {
 	if o.OldHome == "" {
-		return errors.New("--old-home is required")
+		return o.PrintOptions.Validate(printer.FormatPlaintext, []printer.Format{printer.FormatPlaintext, printer.FormatJson, printer.FormatYaml})
 	}
 
+	if o.DryRun {
+		return errors.New("--dry-run cannot be used with --old-home")
+	}
+	if o.RawFormat != "" && o.RawFormat != string(printer.FormatPlaintext) {
+		return errors.New("--output cannot be used with --old-home")
+	}
 	return nil
 }
 
+// MigrateStorage migrates the data from a Porter v0.38 home directory when
+// OldHome is set. Otherwise, the runs saved with an older schema are migrated
+// to the current schema.
 func (p *Porter) MigrateStorage(ctx context.Context, opts MigrateStorageOptions) error {
 	if err := opts.Validate(); err != nil {
 		return err
 	}
 
+	if opts.OldHome == "" {
+		return p.migrateRuns(ctx, opts)
+	}
+
 	migrateOpts := storage.MigrateOptions{
 		OldHome:           opts.OldHome,
 		OldStorageAccount: opts.OldStorageAccount,
@@ -41,6 +61,55 @@ func (p *Porter) MigrateStorage(ctx context.Context, opts MigrateStorageOptions)
 	return p.Storage.Migrate(ctx, migrateOpts)
 }
 
+func (p *Porter) migrateRuns(ctx context.Context, opts MigrateStorageOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = "*"
+	}
+	report, migrateErr := p.Installations.MigrateRuns(ctx, storage.MigrateRunsOptions{Namespace: namespace, DryRun: opts.DryRun})
+
+	var err error
+	switch opts.Format {
+	case printer.FormatJson:
+		err = printer.PrintJson(p.Out, report)
+	case printer.FormatYaml:
+		err = printer.PrintYaml(p.Out, report)
+	case printer.FormatPlaintext:
+		err = p.printRunMigrationReport(report)
+	}
+	if err != nil {
+		return span.Error(err)
+	}
+
+	if migrateErr != nil {
+		return span.Error(fmt.Errorf("%d runs could not be migrated: %w", report.Failed(), migrateErr))
+	}
+	return nil
+}
+
+func (p *Porter) printRunMigrationReport(report storage.RunMigrationReport) error {
+	if len(report.Runs) == 0 {
+		fmt.Fprintf(p.Out, "All runs use the current schema version %s\n", storage.RunSchemaVersion)
+		return nil
+	}
+
+	row := func(v interface{}) []string {
+		run, ok := v.(storage.RunMigrationStatus)
+		if !ok {
+			return nil
+		}
+		from := string(run.From)
+		if from == "" {
+			from = "none"
+		}
+		return []string{run.Namespace, run.Installation, run.RunID, from, string(run.To), run.Status, run.Error}
+	}
+	return printer.PrintTable(p.Out, report.Runs, row, "Namespace", "Installation", "Run", "From", "To", "Status", "Error")
+}
+
 func (p *Porter) FixPermissions(ctx context.Context) error {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.EndSpan()
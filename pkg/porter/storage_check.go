@@ -0,0 +1,96 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// StorageCheckOptions are the options for checking the storage accounts used by Porter.
+type StorageCheckOptions struct {
+	printer.PrintOptions
+}
+
+// Validate the storage check options.
+func (o *StorageCheckOptions) Validate() error {
+	return o.PrintOptions.Validate(printer.FormatPlaintext, []printer.Format{printer.FormatPlaintext, printer.FormatJson, printer.FormatYaml})
+}
+
+// StorageCheck reports if the default storage account, and the secondary
+// storage account used when it is unreachable, can be reached, and how many
+// writes are queued for the default storage account. The queued writes are
+// replayed when the default storage account is reachable. An error is
+// returned when the default storage account is unreachable.
+func (p *Porter) StorageCheck(ctx context.Context, opts StorageCheckOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if p.StorageFailover == nil {
+		return span.Error(errors.New("the storage used by Porter does not support checking its status"))
+	}
+
+	status, checkErr := p.StorageFailover.Check(ctx)
+
+	var err error
+	switch opts.Format {
+	case printer.FormatJson:
+		err = printer.PrintJson(p.Out, status)
+	case printer.FormatYaml:
+		err = printer.PrintYaml(p.Out, status)
+	case printer.FormatPlaintext:
+		err = p.printStorageStatus(status)
+	}
+	if err != nil {
+		return span.Error(err)
+	}
+
+	if checkErr != nil {
+		return span.Error(checkErr)
+	}
+	if !status.Primary.Available {
+		if status.Secondary != nil && status.Secondary.Available {
+			return span.Error(fmt.Errorf("the %s storage account is unreachable, reads and writes use the %s storage account", status.Primary.Name, status.Secondary.Name))
+		}
+		return span.Error(fmt.Errorf("the %s storage account is unreachable", status.Primary.Name))
+	}
+	return nil
+}
+
+// storageAccountRow is a storage account and its role, printed by storage check.
+type storageAccountRow struct {
+	Role string
+	storage.StorageAccountStatus
+}
+
+func (p *Porter) printStorageStatus(status storage.FailoverStatus) error {
+	accounts := []storageAccountRow{{Role: "primary", StorageAccountStatus: status.Primary}}
+	if status.Secondary != nil {
+		accounts = append(accounts, storageAccountRow{Role: "secondary", StorageAccountStatus: *status.Secondary})
+	}
+
+	row := func(v interface{}) []string {
+		account, ok := v.(storageAccountRow)
+		if !ok {
+			return nil
+		}
+		return []string{account.Role, account.Name, strconv.FormatBool(account.Available), account.Error}
+	}
+	if err := printer.PrintTable(p.Out, accounts, row, "Role", "Name", "Available", "Error"); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(p.Out)
+	if status.FailedOver {
+		fmt.Fprintf(p.Out, "Failed over to the %s storage account\n", status.Secondary.Name)
+	}
+	if status.Replayed > 0 {
+		fmt.Fprintf(p.Out, "Replayed %d queued writes\n", status.Replayed)
+	}
+	fmt.Fprintf(p.Out, "Queued writes: %d\n", status.QueuedWrites)
+	return nil
+}
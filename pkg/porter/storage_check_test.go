@@ -0,0 +1,48 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"get.porter.sh/porter/pkg/storage/plugins/inmemory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPorter_StorageCheck(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.Config.Data.DefaultStorage = "primary"
+	p.Config.Data.StorageFailover.Secondary = "standby"
+	p.StorageFailover = storage.NewFailoverStorage(p.Config, inmemory.NewStore(), func(name string) plugins.StorageProtocol {
+		return inmemory.NewStore()
+	})
+
+	opts := StorageCheckOptions{}
+	require.NoError(t, opts.Validate())
+	err := p.StorageCheck(ctx, opts)
+	require.NoError(t, err)
+	gotOutput := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, gotOutput, "primary")
+	assert.Contains(t, gotOutput, "standby")
+	assert.Contains(t, gotOutput, "Queued writes: 0")
+
+	opts = StorageCheckOptions{PrintOptions: printer.PrintOptions{RawFormat: "json"}}
+	require.NoError(t, opts.Validate())
+	err = p.StorageCheck(ctx, opts)
+	require.NoError(t, err)
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), `"failedOver": false`)
+}
+
+func TestPorter_StorageCheck_NotSupported(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	err := p.StorageCheck(context.Background(), StorageCheckOptions{})
+	require.ErrorContains(t, err, "does not support checking its status")
+}
@@ -0,0 +1,93 @@
+package porter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// StorageFsckOptions are the options for checking the consistency of the
+// installation data saved by Porter.
+type StorageFsckOptions struct {
+	printer.PrintOptions
+
+	// Namespace of the installation data to check. Defaults to all namespaces.
+	Namespace string
+
+	// Repair the problems that are found.
+	Repair bool
+
+	// PendingGracePeriod is how long after it was created that a pending
+	// result may still be saving its outputs, and is not checked.
+	PendingGracePeriod time.Duration
+}
+
+// Validate the storage fsck options.
+func (o *StorageFsckOptions) Validate() error {
+	return o.PrintOptions.Validate(printer.FormatPlaintext, []printer.Format{printer.FormatPlaintext, printer.FormatJson, printer.FormatYaml})
+}
+
+func (o StorageFsckOptions) toFsckOptions() storage.FsckOptions {
+	namespace := o.Namespace
+	if namespace == "" {
+		namespace = "*"
+	}
+	return storage.FsckOptions{
+		Namespace:          namespace,
+		Repair:             o.Repair,
+		PendingGracePeriod: o.PendingGracePeriod,
+	}
+}
+
+// StorageFsck checks that the results and outputs of runs were saved
+// completely, for example when Porter stopped while saving a result, and
+// optionally repairs the problems that are found. An error is returned when
+// problems remain.
+func (p *Porter) StorageFsck(ctx context.Context, opts StorageFsckOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	report, repairErr := p.Installations.Fsck(ctx, opts.toFsckOptions())
+
+	var err error
+	switch opts.Format {
+	case printer.FormatJson:
+		err = printer.PrintJson(p.Out, report)
+	case printer.FormatYaml:
+		err = printer.PrintYaml(p.Out, report)
+	case printer.FormatPlaintext:
+		err = p.printFsckReport(report)
+	}
+	if err != nil {
+		return span.Error(err)
+	}
+
+	if repairErr != nil {
+		return span.Error(fmt.Errorf("some problems could not be repaired: %w", repairErr))
+	}
+	if unrepaired := report.Unrepaired(); unrepaired > 0 {
+		return span.Error(fmt.Errorf("found %d problems with the installation data, repeat the command with --repair to repair them", unrepaired))
+	}
+	return nil
+}
+
+func (p *Porter) printFsckReport(report storage.FsckReport) error {
+	if len(report.Problems) == 0 {
+		fmt.Fprintln(p.Out, "No problems found")
+		return nil
+	}
+
+	row := func(v interface{}) []string {
+		problem, ok := v.(storage.FsckProblem)
+		if !ok {
+			return nil
+		}
+		return []string{problem.Namespace, problem.Installation, problem.Type, problem.Description, strconv.FormatBool(problem.Repaired)}
+	}
+	return printer.PrintTable(p.Out, report.Problems, row, "Namespace", "Installation", "Problem", "Description", "Repaired")
+}
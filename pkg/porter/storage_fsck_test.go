@@ -0,0 +1,49 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPorter_StorageFsck(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	inst := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybuns"))
+	run := p.TestInstallations.CreateRun(inst.NewRun(cnab.ActionInstall))
+	p.TestInstallations.CreateResult(run.NewResult(cnab.StatusSucceeded), func(r *storage.Result) { r.Pending = true })
+
+	opts := StorageFsckOptions{}
+	require.NoError(t, opts.Validate())
+
+	err := p.StorageFsck(ctx, opts)
+	require.ErrorContains(t, err, "found 1 problems with the installation data, repeat the command with --repair")
+	gotOutput := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, gotOutput, storage.FsckPendingResult)
+	assert.Contains(t, gotOutput, "false")
+
+	opts.Repair = true
+	err = p.StorageFsck(ctx, opts)
+	require.NoError(t, err)
+	gotOutput = p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, gotOutput, "true", "the problem should be repaired")
+
+	opts = StorageFsckOptions{PrintOptions: printer.PrintOptions{RawFormat: "json"}}
+	require.NoError(t, opts.Validate())
+	err = p.StorageFsck(ctx, opts)
+	require.NoError(t, err)
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), `"problems": null`)
+
+	opts = StorageFsckOptions{}
+	require.NoError(t, opts.Validate())
+	err = p.StorageFsck(ctx, opts)
+	require.NoError(t, err)
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "No problems found")
+}
@@ -0,0 +1,80 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"get.porter.sh/porter/pkg/secrets"
+	secretsplugin "get.porter.sh/porter/pkg/secrets/pluginstore"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// RotateSecretsOptions are the options for copying the sensitive data of
+// installations and runs to a new secret store.
+type RotateSecretsOptions struct {
+	// From is the name of the secrets account that currently stores the
+	// sensitive data. Defaults to the default secrets account.
+	From string
+
+	// To is the name of the secrets account that the sensitive data is copied to.
+	To string
+
+	// Namespace of the installations to rotate. Defaults to all namespaces.
+	Namespace string
+
+	// Installation name to rotate. Defaults to all installations.
+	// When the namespace is not set, installations with the name are rotated in all namespaces.
+	Installation string
+}
+
+func (o *RotateSecretsOptions) Validate() error {
+	if o.To == "" {
+		return errors.New("--to is required")
+	}
+
+	if o.From == o.To {
+		return fmt.Errorf("--from and --to must be different secrets accounts, both are %s", o.To)
+	}
+
+	return nil
+}
+
+func (o RotateSecretsOptions) filter() storage.RotateSecretsFilter {
+	namespace := o.Namespace
+	if namespace == "" {
+		namespace = "*"
+	}
+	return storage.RotateSecretsFilter{
+		Namespace:    namespace,
+		Installation: o.Installation,
+	}
+}
+
+// RotateSecrets copies the sensitive parameters and outputs that Porter saved
+// to the secret store, from one secrets account to another. After the secrets
+// are rotated, set default-secrets in the config file to the new account.
+func (p *Porter) RotateSecrets(ctx context.Context, opts RotateSecretsOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if opts.From == "" && opts.To == p.Config.Data.DefaultSecrets {
+		return span.Error(fmt.Errorf("--to must be different from the default secrets account %s, use --from to select the secrets account to rotate", opts.To))
+	}
+
+	oldStore := p.Secrets
+	if opts.From != "" {
+		oldStore = secrets.NewPluginAdapter(secretsplugin.NewStoreFor(p.Config, opts.From))
+		defer oldStore.Close()
+	}
+	newStore := secrets.NewPluginAdapter(secretsplugin.NewStoreFor(p.Config, opts.To))
+	defer newStore.Close()
+
+	result, err := p.Sanitizer.Rotate(ctx, p.Installations, oldStore, newStore, opts.filter())
+	fmt.Fprintf(p.Out, "Rotated %d secrets to the %s secrets account\n", len(result.Rotated), opts.To)
+	if err != nil {
+		return span.Error(fmt.Errorf("%d secrets could not be rotated: %w", len(result.Failed), err))
+	}
+	return nil
+}
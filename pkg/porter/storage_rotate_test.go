@@ -0,0 +1,53 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateSecretsOptions_Validate(t *testing.T) {
+	testcases := []struct {
+		name      string
+		opts      RotateSecretsOptions
+		wantError string
+	}{
+		{name: "to required", opts: RotateSecretsOptions{From: "old-vault"}, wantError: "--to is required"},
+		{name: "same account", opts: RotateSecretsOptions{From: "vault", To: "vault"}, wantError: "--from and --to must be different secrets accounts, both are vault"},
+		{name: "valid", opts: RotateSecretsOptions{To: "new-vault"}},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.opts.Validate()
+			if tc.wantError == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.wantError)
+			}
+		})
+	}
+}
+
+func TestRotateSecretsOptions_filter(t *testing.T) {
+	opts := RotateSecretsOptions{To: "new-vault", Installation: "mysql"}
+	assert.Equal(t, storage.RotateSecretsFilter{Namespace: "*", Installation: "mysql"}, opts.filter(), "all namespaces should be rotated by default")
+
+	opts.Namespace = "dev"
+	assert.Equal(t, storage.RotateSecretsFilter{Namespace: "dev", Installation: "mysql"}, opts.filter())
+}
+
+func TestPorter_RotateSecrets_DefaultAccount(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.Config.Data.DefaultSecrets = "vault"
+	opts := RotateSecretsOptions{To: "vault"}
+	require.NoError(t, opts.Validate())
+
+	err := p.RotateSecrets(context.Background(), opts)
+	require.EqualError(t, err, "--to must be different from the default secrets account vault, use --from to select the secrets account to rotate")
+}
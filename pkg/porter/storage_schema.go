@@ -0,0 +1,112 @@
+package porter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/dustin/go-humanize"
+)
+
+// StorageSchemaShowOptions are the options for the storage schema show command.
+type StorageSchemaShowOptions struct {
+	printer.PrintOptions
+}
+
+// Validate the storage schema show options.
+func (o *StorageSchemaShowOptions) Validate() error {
+	return o.PrintOptions.Validate(printer.FormatPlaintext, []printer.Format{printer.FormatPlaintext, printer.FormatJson, printer.FormatYaml})
+}
+
+// ShowStorageSchema prints the schema of the documents stored by Porter, and the
+// size, count and indexes of each collection in the storage backend.
+func (p *Porter) ShowStorageSchema(ctx context.Context, opts StorageSchemaShowOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	desc, err := p.Storage.DescribeStorage(ctx)
+	if err != nil {
+		return span.Error(fmt.Errorf("could not describe the storage backend: %w", err))
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, desc)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, desc)
+	case printer.FormatPlaintext:
+		return p.printStorageDescription(desc)
+	}
+	return nil
+}
+
+func (p *Porter) printStorageDescription(desc storage.StorageDescription) error {
+	fmt.Fprintln(p.Out, "Schemas:")
+	schemaRow := func(v interface{}) []string {
+		s, ok := v.(storage.SchemaDescription)
+		if !ok {
+			return nil
+		}
+		version := string(s.Version)
+		if version == "" {
+			version = "none"
+		}
+		status := "up-to-date"
+		if s.OutOfDate() {
+			status = "migration required"
+		}
+		return []string{s.Name, version, string(s.Supported), status}
+	}
+	err := printer.PrintTable(p.Out, desc.Schemas, schemaRow, "Storage", "Version", "Supported", "Status")
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(p.Out)
+	fmt.Fprintln(p.Out, "Collections:")
+	collectionRow := func(v interface{}) []string {
+		c, ok := v.(storage.CollectionDescription)
+		if !ok {
+			return nil
+		}
+		if !c.Exists {
+			return []string{c.Name, string(c.SchemaVersion), "0", "-", "-", "-", "0"}
+		}
+		return []string{c.Name, string(c.SchemaVersion), strconv.FormatInt(c.Count, 10),
+			humanize.Bytes(uint64(c.Size)), humanize.Bytes(uint64(c.AverageDocumentSize)),
+			humanize.Bytes(uint64(c.StorageSize + c.IndexSize)), strconv.Itoa(len(c.Indexes))}
+	}
+	err = printer.PrintTable(p.Out, desc.Collections, collectionRow, "Collection", "Schema", "Documents", "Size", "Avg Document", "Storage", "Indexes")
+	if err != nil {
+		return err
+	}
+
+	type indexRow struct {
+		Collection string
+		storage.IndexDescription
+	}
+	var indexes []indexRow
+	for _, c := range desc.Collections {
+		for _, idx := range c.Indexes {
+			indexes = append(indexes, indexRow{Collection: c.Name, IndexDescription: idx})
+		}
+	}
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(p.Out)
+	fmt.Fprintln(p.Out, "Indexes:")
+	row := func(v interface{}) []string {
+		idx, ok := v.(indexRow)
+		if !ok {
+			return nil
+		}
+		return []string{idx.Collection, idx.Name, strings.Join(idx.Keys, ", "), strconv.FormatBool(idx.Unique)}
+	}
+	return printer.PrintTable(p.Out, indexes, row, "Collection", "Index", "Keys", "Unique")
+}
@@ -0,0 +1,58 @@
+package porter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageSchemaShowOptions_Validate(t *testing.T) {
+	opts := StorageSchemaShowOptions{}
+	require.NoError(t, opts.Validate())
+	assert.Equal(t, printer.FormatPlaintext, opts.Format)
+
+	opts.RawFormat = "human"
+	require.Error(t, opts.Validate())
+}
+
+func TestPorter_ShowStorageSchema(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+
+	err := p.ShowStorageSchema(ctx, StorageSchemaShowOptions{PrintOptions: printer.PrintOptions{Format: printer.FormatPlaintext}})
+	require.NoError(t, err)
+	output := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, output, "Schemas:")
+	assert.Contains(t, output, "Collections:")
+	assert.Contains(t, output, "Indexes:")
+	assert.Contains(t, output, "runId, name, -resultId", "the index keys should be listed in order")
+}
+
+func TestPorter_ShowStorageSchema_Json(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	require.NoError(t, p.Storage.WriteSchema(ctx))
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+
+	err := p.ShowStorageSchema(ctx, StorageSchemaShowOptions{PrintOptions: printer.PrintOptions{Format: printer.FormatJson}})
+	require.NoError(t, err)
+
+	var desc storage.StorageDescription
+	require.NoError(t, json.Unmarshal([]byte(p.TestConfig.TestContext.GetOutput()), &desc))
+	require.NotEmpty(t, desc.Collections)
+	assert.Equal(t, storage.CollectionInstallations, desc.Collections[0].Name)
+	assert.Equal(t, int64(1), desc.Collections[0].Count)
+	for _, s := range desc.Schemas {
+		assert.False(t, s.OutOfDate(), "the %s schema should be up-to-date", s.Name)
+	}
+}
@@ -0,0 +1,39 @@
+package porter
+
+import (
+	"fmt"
+	"strconv"
+
+	"get.porter.sh/porter/pkg/metrics"
+	"get.porter.sh/porter/pkg/printer"
+)
+
+// PrintStorageStats prints a summary of the queries made to the storage plugin
+// and the lookups in the bundle cache by the command, to help decide which
+// indices to add or when to compact the database. The summary is printed to
+// stderr so that it does not interfere with the output of the command.
+func (p *Porter) PrintStorageStats() error {
+	stats, err := metrics.GetStorageStats()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(p.Err, "\nStorage statistics:")
+	if len(stats.Collections) == 0 {
+		fmt.Fprintln(p.Err, "No queries were made to the storage plugin")
+	} else {
+		row := func(v interface{}) []string {
+			c, ok := v.(metrics.CollectionStats)
+			if !ok {
+				return nil
+			}
+			return []string{c.Collection, strconv.FormatInt(c.Queries, 10), strconv.FormatInt(c.DocumentsScanned, 10), strconv.FormatInt(c.Errors, 10)}
+		}
+		if err = printer.PrintTable(p.Err, stats.Collections, row, "COLLECTION", "QUERIES", "DOCUMENTS SCANNED", "ERRORS"); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(p.Err, "Bundle cache: %d hits, %d misses (%.0f%% hit rate)\n", stats.CacheHits, stats.CacheMisses, stats.CacheHitRate())
+	return nil
+}
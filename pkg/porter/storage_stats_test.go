@@ -0,0 +1,23 @@
+package porter
+
+import (
+	"testing"
+
+	"get.porter.sh/porter/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPorter_PrintStorageStats(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	metrics.CountStorageQuery("find", "print-stats-test", 4, nil)
+
+	require.NoError(t, p.PrintStorageStats())
+
+	gotErr := p.TestConfig.TestContext.GetError()
+	assert.Contains(t, gotErr, "Storage statistics:")
+	assert.Regexp(t, `print-stats-test\s+1\s+4\s+0`, gotErr)
+	assert.Contains(t, gotErr, "Bundle cache:")
+}
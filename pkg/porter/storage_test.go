@@ -0,0 +1,73 @@
+package porter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateStorageOptions_Validate(t *testing.T) {
+	testcases := []struct {
+		name    string
+		opts    MigrateStorageOptions
+		wantErr string
+	}{
+		{name: "runs", opts: MigrateStorageOptions{DryRun: true, PrintOptions: printer.PrintOptions{RawFormat: "json"}}},
+		{name: "old home", opts: MigrateStorageOptions{OldHome: "/home/me/.porterv0", PrintOptions: printer.PrintOptions{RawFormat: "plaintext"}}},
+		{name: "old home dry run", opts: MigrateStorageOptions{OldHome: "/home/me/.porterv0", DryRun: true}, wantErr: "--dry-run cannot be used with --old-home"},
+		{name: "old home output", opts: MigrateStorageOptions{OldHome: "/home/me/.porterv0", PrintOptions: printer.PrintOptions{RawFormat: "json"}}, wantErr: "--output cannot be used with --old-home"},
+		{name: "invalid output", opts: MigrateStorageOptions{PrintOptions: printer.PrintOptions{RawFormat: "table"}}, wantErr: "invalid format"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.opts.Validate()
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestPorter_MigrateStorage_Runs(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	inst := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mybuns"))
+
+	// Save a run without a schema version
+	run := inst.NewRun(cnab.ActionInstall)
+	data, err := json.Marshal(run)
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	delete(doc, "schemaVersion")
+	err = p.TestInstallations.Insert(ctx, storage.CollectionRuns, storage.InsertOptions{Documents: []interface{}{doc}})
+	require.NoError(t, err)
+
+	opts := MigrateStorageOptions{DryRun: true}
+	err = p.MigrateStorage(ctx, opts)
+	require.NoError(t, err)
+	gotOutput := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, gotOutput, run.ID)
+	assert.Contains(t, gotOutput, storage.RunMigrationPending)
+
+	opts = MigrateStorageOptions{}
+	err = p.MigrateStorage(ctx, opts)
+	require.NoError(t, err)
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), storage.RunMigrationMigrated)
+
+	opts = MigrateStorageOptions{}
+	err = p.MigrateStorage(ctx, opts)
+	require.NoError(t, err)
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "All runs use the current schema version")
+}
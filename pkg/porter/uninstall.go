@@ -74,11 +74,28 @@ func (p *Porter) UninstallBundle(ctx context.Context, opts UninstallOptions) err
 	ctx, log := tracing.StartSpan(ctx)
 	defer log.EndSpan()
 
+	if opts.Remote != "" {
+		return p.executeRemote(ctx, cnab.ActionUninstall, opts)
+	}
+
+	unlock, err := p.lockInstallation(ctx, opts.Namespace, opts.Name, opts.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	installation, err := p.Installations.GetInstallation(ctx, opts.Namespace, opts.Name)
 	if err != nil {
 		return fmt.Errorf("could not find installation %s/%s: %w", opts.Namespace, opts.Name, err)
 	}
 
+	// Check that the installation may be deleted before uninstalling it
+	if opts.unsafeDelete() {
+		if err = p.checkInstallationProtection(ctx, opts.Namespace, opts.Name); err != nil {
+			return fmt.Errorf("%w; if you are sure it should be deleted, retry the last command with the --force-delete flag", err)
+		}
+	}
+
 	err = p.applyActionOptionsToInstallation(ctx, opts, &installation)
 	if err != nil {
 		return err
@@ -127,7 +144,7 @@ func (p *Porter) UninstallBundle(ctx context.Context, opts UninstallOptions) err
 
 	if opts.shouldDelete() {
 		log.Info("deleting installation records")
-		return p.Installations.RemoveInstallation(ctx, opts.Namespace, opts.Name)
+		return p.removeInstallation(ctx, opts.Namespace, opts.Name)
 	}
 	return nil
 }
@@ -18,6 +18,9 @@ type UpgradeOptions struct {
 
 	// Version of the bundle to upgrade to
 	Version string
+
+	// Labels to apply to the run.
+	Labels []string
 }
 
 func NewUpgradeOptions() *UpgradeOptions {
@@ -43,6 +46,10 @@ func (o *UpgradeOptions) Validate(ctx context.Context, args []string, p *Porter)
 	return o.BundleExecutionOptions.Validate(ctx, args, p)
 }
 
+func (o *UpgradeOptions) ParseLabels() map[string]string {
+	return parseLabels(o.Labels)
+}
+
 func (o *UpgradeOptions) GetAction() string {
 	return cnab.ActionUpgrade
 }
@@ -54,6 +61,16 @@ func (o *UpgradeOptions) GetActionVerb() string {
 // UpgradeBundle accepts a set of pre-validated UpgradeOptions and uses
 // them to upgrade a bundle.
 func (p *Porter) UpgradeBundle(ctx context.Context, opts *UpgradeOptions) error {
+	if opts.Remote != "" {
+		return p.executeRemote(ctx, cnab.ActionUpgrade, opts)
+	}
+
+	unlock, err := p.lockInstallation(ctx, opts.Namespace, opts.Name, opts.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	// Sync any changes specified by the user to the installation before running upgrade
 	i, err := p.Installations.GetInstallation(ctx, opts.Namespace, opts.Name)
 	if err != nil {
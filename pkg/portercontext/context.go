@@ -139,6 +139,9 @@ type LogConfiguration struct {
 	// Verbosity is the threshold for printing messages to the console.
 	Verbosity zapcore.Level
 
+	// DisableColor prevents messages printed to the console from being colored.
+	DisableColor bool
+
 	LogToFile    bool
 	LogDirectory string
 
@@ -214,7 +217,7 @@ func (c *Context) makeConsoleLogger() zapcore.Core {
 	encoding := c.makeLogEncoding()
 
 	stderr := c.Err
-	if f, ok := stderr.(*os.File); ok {
+	if f, ok := stderr.(*os.File); ok && !c.logCfg.DisableColor {
 		if isatty.IsTerminal(f.Fd()) {
 			stderr = colorable.NewColorable(f)
 			encoding.EncodeLevel = zapcore.LowercaseColorLevelEncoder
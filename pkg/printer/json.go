@@ -7,11 +7,18 @@ import (
 	"get.porter.sh/porter/pkg/encoding"
 )
 
+// PrintJson is a printer that prints the provided value in json, removing
+// any fields redacted by the writer.
 func PrintJson(out io.Writer, v interface{}) error {
 	b, err := encoding.MarshalJson(v)
 	if err != nil {
 		return fmt.Errorf("could not marshal value to json: %w", err)
 	}
+	if redactions := GetRedactions(out); len(redactions) > 0 {
+		if b, err = redactDocument(FormatJson, b, redactions); err != nil {
+			return err
+		}
+	}
 	fmt.Fprintln(out, string(b))
 	return nil
 }
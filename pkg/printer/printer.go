@@ -11,6 +11,13 @@ const (
 	FormatJson      Format = "json"
 	FormatYaml      Format = "yaml"
 	FormatPlaintext Format = "plaintext"
+
+	// FormatNdjson is newline delimited json, where each line is a json
+	// document. It is used to report events as they happen.
+	FormatNdjson Format = "ndjson"
+
+	// FormatDot is the graphviz dot language, used to render graphs.
+	FormatDot Format = "dot"
 )
 
 type Formats []Format
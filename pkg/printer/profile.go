@@ -0,0 +1,150 @@
+package printer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Profile controls how Porter interacts with the console, such as whether
+// color and progress are displayed and if Porter may prompt for input.
+type Profile string
+
+const (
+	// ProfileDefault is intended for people using Porter from a terminal.
+	// Color and progress are displayed when the console is a terminal, and
+	// Porter may prompt for input.
+	ProfileDefault Profile = "default"
+
+	// ProfileCI is intended for automated pipelines. Output is not colored,
+	// progress is printed as plain text, and Porter never prompts for input.
+	ProfileCI Profile = "ci"
+
+	// ProfileQuiet only prints the results of a command and errors. Output is
+	// not colored, progress is not displayed, and Porter never prompts for input.
+	ProfileQuiet Profile = "quiet"
+
+	// ProfilePorcelain prints output that is stable and easily parsed by scripts.
+	// Tables are printed without headers as tab separated values, output is not
+	// colored, progress is not displayed, and Porter never prompts for input.
+	ProfilePorcelain Profile = "porcelain"
+)
+
+// Profiles is the list of supported output profiles.
+var Profiles = []Profile{ProfileDefault, ProfileCI, ProfileQuiet, ProfilePorcelain}
+
+// ParseProfile converts the name of an output profile into a Profile. An empty
+// value is the default profile.
+func ParseProfile(value string) (Profile, error) {
+	if value == "" {
+		return ProfileDefault, nil
+	}
+
+	for _, p := range Profiles {
+		if string(p) == value {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("invalid output profile %q, allowed values are: %s", value, profileNames())
+}
+
+func profileNames() string {
+	names := make([]string, len(Profiles))
+	for i, p := range Profiles {
+		names[i] = string(p)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Color indicates if console output may be colored.
+func (p Profile) Color() bool {
+	return p.isDefault()
+}
+
+// Progress indicates if interactive progress, such as the progress of an
+// image build, may be displayed.
+func (p Profile) Progress() bool {
+	return p.isDefault()
+}
+
+// PlainProgress indicates if progress should be printed as plain text,
+// instead of interactively or not at all.
+func (p Profile) PlainProgress() bool {
+	return p == ProfileCI
+}
+
+// Prompts indicates if Porter may prompt for input.
+func (p Profile) Prompts() bool {
+	return p.isDefault()
+}
+
+// Quiet indicates if informational messages should be suppressed, leaving
+// only the results of the command and errors.
+func (p Profile) Quiet() bool {
+	return p == ProfileQuiet
+}
+
+// Porcelain indicates if output should be printed in a stable format that
+// is easily parsed by scripts.
+func (p Profile) Porcelain() bool {
+	return p == ProfilePorcelain
+}
+
+func (p Profile) isDefault() bool {
+	return p == "" || p == ProfileDefault
+}
+
+// CheckPrompt returns ErrPromptRequired when the profile does not allow Porter
+// to prompt for input. The action describes what requires the prompt.
+func (p Profile) CheckPrompt(action string) error {
+	if p.Prompts() {
+		return nil
+	}
+	return ErrPromptRequired{Profile: p, Action: action}
+}
+
+// ErrPromptRequired is returned when a command must prompt for input but
+// prompts are disabled by the output profile.
+type ErrPromptRequired struct {
+	// Profile that disabled prompts.
+	Profile Profile
+
+	// Action that required the prompt.
+	Action string
+}
+
+func (e ErrPromptRequired) Error() string {
+	return fmt.Sprintf("%s requires prompting for input, which is disabled by the %s output profile", e.Action, e.Profile)
+}
+
+// ProfileWriter is an io.Writer that is printed to using an output profile.
+type ProfileWriter struct {
+	io.Writer
+
+	// Profile used when printing to the writer.
+	Profile Profile
+
+	// Redactions applied to json and yaml printed to the writer.
+	Redactions []Redaction
+}
+
+// NewProfileWriter wraps a writer so that the output profile is applied when
+// printing to it. The writer is returned unwrapped for the default profile.
+func NewProfileWriter(out io.Writer, profile Profile) io.Writer {
+	redactions := GetRedactions(out)
+	if pw, ok := out.(ProfileWriter); ok {
+		out = pw.Writer
+	}
+	if profile.isDefault() && len(redactions) == 0 {
+		return out
+	}
+	return ProfileWriter{Writer: out, Profile: profile, Redactions: redactions}
+}
+
+// GetProfile returns the output profile of a writer.
+func GetProfile(out io.Writer) Profile {
+	if pw, ok := out.(ProfileWriter); ok {
+		return pw.Profile
+	}
+	return ProfileDefault
+}
@@ -0,0 +1,87 @@
+package printer
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProfile(t *testing.T) {
+	testcases := []struct {
+		value     string
+		want      Profile
+		wantError string
+	}{
+		{value: "", want: ProfileDefault},
+		{value: "default", want: ProfileDefault},
+		{value: "ci", want: ProfileCI},
+		{value: "quiet", want: ProfileQuiet},
+		{value: "porcelain", want: ProfilePorcelain},
+		{value: "loud", wantError: `invalid output profile "loud", allowed values are: default, ci, quiet, porcelain`},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := ParseProfile(tc.value)
+			if tc.wantError != "" {
+				require.EqualError(t, err, tc.wantError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestProfile_Settings(t *testing.T) {
+	testcases := []struct {
+		profile                                                   Profile
+		color, progress, plainProgress, prompts, quiet, porcelain bool
+	}{
+		{profile: ProfileDefault, color: true, progress: true, prompts: true},
+		{profile: ProfileCI, plainProgress: true},
+		{profile: ProfileQuiet, quiet: true},
+		{profile: ProfilePorcelain, porcelain: true},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(string(tc.profile), func(t *testing.T) {
+			assert.Equal(t, tc.color, tc.profile.Color(), "Color")
+			assert.Equal(t, tc.progress, tc.profile.Progress(), "Progress")
+			assert.Equal(t, tc.plainProgress, tc.profile.PlainProgress(), "PlainProgress")
+			assert.Equal(t, tc.prompts, tc.profile.Prompts(), "Prompts")
+			assert.Equal(t, tc.quiet, tc.profile.Quiet(), "Quiet")
+			assert.Equal(t, tc.porcelain, tc.profile.Porcelain(), "Porcelain")
+		})
+	}
+}
+
+func TestProfile_CheckPrompt(t *testing.T) {
+	require.NoError(t, ProfileDefault.CheckPrompt("setting credential \"token\""))
+
+	err := ProfileCI.CheckPrompt("setting credential \"token\"")
+	require.EqualError(t, err, `setting credential "token" requires prompting for input, which is disabled by the ci output profile`)
+	assert.True(t, errors.As(err, &ErrPromptRequired{}))
+}
+
+func TestNewProfileWriter(t *testing.T) {
+	b := &bytes.Buffer{}
+
+	out := NewProfileWriter(b, ProfileDefault)
+	assert.Same(t, b, out, "the writer should not be wrapped for the default profile")
+	assert.Equal(t, ProfileDefault, GetProfile(out))
+
+	out = NewProfileWriter(b, ProfileCI)
+	assert.Equal(t, ProfileCI, GetProfile(out))
+
+	out = NewProfileWriter(out, ProfilePorcelain)
+	assert.Equal(t, ProfileWriter{Writer: b, Profile: ProfilePorcelain}, out, "the writer should not be wrapped twice")
+
+	out = NewProfileWriter(out, ProfileDefault)
+	assert.Same(t, b, out, "the writer should be unwrapped for the default profile")
+}
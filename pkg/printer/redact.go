@@ -0,0 +1,287 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Redaction removes the fields that match a JSONPath expression from json and
+// yaml printed to a writer.
+//
+// A subset of JSONPath is supported: the root ($), child names (.name or
+// ['name']), array indices ([0]), wildcards (.* or [*]) and recursive descent
+// (..name). For example, $..parameterOverrides removes the parameterOverrides
+// field wherever it appears in the document.
+type Redaction struct {
+	// Path is the JSONPath expression.
+	Path string
+
+	segments []pathSegment
+}
+
+// pathSegment is a single step of a JSONPath expression.
+type pathSegment struct {
+	// descendant matches the selector against the node and all of its descendants.
+	descendant bool
+
+	// wildcard matches all children.
+	wildcard bool
+
+	// name of the field to match.
+	name string
+
+	// index of the array item to match, or -1 when a name or wildcard is used.
+	index int
+}
+
+// ParseRedactions parses a list of JSONPath expressions.
+func ParseRedactions(paths []string) ([]Redaction, error) {
+	redactions := make([]Redaction, 0, len(paths))
+	for _, path := range paths {
+		r, err := ParseRedaction(path)
+		if err != nil {
+			return nil, err
+		}
+		redactions = append(redactions, r)
+	}
+	return redactions, nil
+}
+
+// ParseRedaction parses a JSONPath expression that selects the fields to redact.
+func ParseRedaction(path string) (Redaction, error) {
+	r := Redaction{Path: path}
+	invalid := func(reason string) (Redaction, error) {
+		return Redaction{}, fmt.Errorf("invalid redaction %q: %s", path, reason)
+	}
+
+	if !strings.HasPrefix(path, "$") {
+		return invalid("the expression must start with $")
+	}
+
+	remaining := path[1:]
+	for remaining != "" {
+		var seg pathSegment
+		seg.index = -1
+
+		switch {
+		case strings.HasPrefix(remaining, ".."):
+			seg.descendant = true
+			remaining = remaining[2:]
+			if strings.HasPrefix(remaining, "[") {
+				break
+			}
+			remaining = "." + remaining
+			fallthrough
+		case strings.HasPrefix(remaining, "."):
+			remaining = remaining[1:]
+			end := strings.IndexAny(remaining, ".[")
+			if end == -1 {
+				end = len(remaining)
+			}
+			name := remaining[:end]
+			remaining = remaining[end:]
+			if name == "" {
+				return invalid("a field name is required after .")
+			}
+			if name == "*" {
+				seg.wildcard = true
+			} else {
+				seg.name = name
+			}
+			r.segments = append(r.segments, seg)
+			continue
+		case strings.HasPrefix(remaining, "["):
+		default:
+			return invalid(fmt.Sprintf("unexpected %q", remaining))
+		}
+
+		// Parse a bracketed selector: ['name'], ["name"], [*] or [0]
+		end := strings.Index(remaining, "]")
+		if end == -1 {
+			return invalid("missing ]")
+		}
+		selector := remaining[1:end]
+		remaining = remaining[end+1:]
+		switch {
+		case selector == "*":
+			seg.wildcard = true
+		case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+			seg.name = selector[1 : len(selector)-1]
+		default:
+			i, err := strconv.Atoi(selector)
+			if err != nil || i < 0 {
+				return invalid(fmt.Sprintf("unsupported selector [%s]", selector))
+			}
+			seg.index = i
+		}
+		r.segments = append(r.segments, seg)
+	}
+
+	if len(r.segments) == 0 {
+		return invalid("the entire document cannot be redacted")
+	}
+	return r, nil
+}
+
+// NewRedactingWriter wraps a writer so that the fields matching the
+// redactions are removed from json and yaml printed to it.
+func NewRedactingWriter(out io.Writer, redactions []Redaction) io.Writer {
+	profile := GetProfile(out)
+	if pw, ok := out.(ProfileWriter); ok {
+		out = pw.Writer
+	}
+	if profile.isDefault() && len(redactions) == 0 {
+		return out
+	}
+	return ProfileWriter{Writer: out, Profile: profile, Redactions: redactions}
+}
+
+// GetRedactions returns the redactions applied to json and yaml printed to a writer.
+func GetRedactions(out io.Writer) []Redaction {
+	if pw, ok := out.(ProfileWriter); ok {
+		return pw.Redactions
+	}
+	return nil
+}
+
+// redactDocument removes the fields matching the redactions from a json or
+// yaml document, preserving the order of the remaining fields.
+func redactDocument(format Format, data []byte, redactions []Redaction) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("could not parse the %s document to redact it: %w", format, err)
+	}
+	if len(doc.Content) == 0 {
+		return data, nil
+	}
+
+	for _, r := range redactions {
+		redactNode(doc.Content[0], r.segments)
+	}
+
+	if format == FormatJson {
+		var buf bytes.Buffer
+		if err := writeJsonNode(&buf, doc.Content[0]); err != nil {
+			return nil, err
+		}
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, buf.Bytes(), "", "  "); err != nil {
+			return nil, fmt.Errorf("could not format the redacted json document: %w", err)
+		}
+		return indented.Bytes(), nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("could not format the redacted yaml document: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// redactNode removes the children of the node that match the path.
+func redactNode(node *yaml.Node, segments []pathSegment) {
+	seg := segments[0]
+	if seg.descendant {
+		// Apply the selector to the node and each of its descendants. The
+		// descendants are collected first, because nodes are removed as the
+		// selector is applied.
+		seg.descendant = false
+		next := append([]pathSegment{seg}, segments[1:]...)
+		for _, n := range collectNodes(node) {
+			redactNode(n, next)
+		}
+		return
+	}
+
+	last := len(segments) == 1
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := len(node.Content) - 2; i >= 0; i -= 2 {
+			if !seg.wildcard && (seg.index != -1 || node.Content[i].Value != seg.name) {
+				continue
+			}
+			if last {
+				node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			} else {
+				redactNode(node.Content[i+1], segments[1:])
+			}
+		}
+	case yaml.SequenceNode:
+		for i := len(node.Content) - 1; i >= 0; i-- {
+			if !seg.wildcard && seg.index != i {
+				continue
+			}
+			if last {
+				node.Content = append(node.Content[:i], node.Content[i+1:]...)
+			} else {
+				redactNode(node.Content[i], segments[1:])
+			}
+		}
+	}
+}
+
+// collectNodes returns the node and all of its descendant values.
+func collectNodes(node *yaml.Node) []*yaml.Node {
+	nodes := []*yaml.Node{node}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			nodes = append(nodes, collectNodes(node.Content[i])...)
+		}
+	case yaml.SequenceNode:
+		for _, child := range node.Content {
+			nodes = append(nodes, collectNodes(child)...)
+		}
+	}
+	return nodes
+}
+
+// writeJsonNode writes a node that was parsed from json back to compact json.
+func writeJsonNode(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(node.Content[i].Value)
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeJsonNode(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, child := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJsonNode(buf, child); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!int", "!!float", "!!bool", "!!null":
+			buf.WriteString(node.Value)
+		default:
+			value, _ := json.Marshal(node.Value)
+			buf.Write(value)
+		}
+	default:
+		return fmt.Errorf("could not format the redacted json document: unexpected node kind %v", node.Kind)
+	}
+	return nil
+}
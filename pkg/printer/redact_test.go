@@ -0,0 +1,179 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRedaction(t *testing.T) {
+	testcases := []struct {
+		path      string
+		wantError string
+	}{
+		{path: "$.parameterOverrides"},
+		{path: "$..parameterOverrides"},
+		{path: "$.status.outputs[*].value"},
+		{path: "$['credentialSets'][0]"},
+		{path: `$..["my.field"]`},
+		{path: "$.items.*.value"},
+		{path: "parameterOverrides", wantError: `invalid redaction "parameterOverrides": the expression must start with $`},
+		{path: "$", wantError: `invalid redaction "$": the entire document cannot be redacted`},
+		{path: "$.a.", wantError: `invalid redaction "$.a.": a field name is required after .`},
+		{path: "$.a[0", wantError: `invalid redaction "$.a[0": missing ]`},
+		{path: "$.a[?(@.b)]", wantError: `invalid redaction "$.a[?(@.b)]": unsupported selector [?(@.b)]`},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.path, func(t *testing.T) {
+			_, err := ParseRedaction(tc.path)
+			if tc.wantError != "" {
+				require.EqualError(t, err, tc.wantError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+type redactTestRun struct {
+	ID                 string            `json:"id" yaml:"id"`
+	ParameterOverrides map[string]string `json:"parameterOverrides" yaml:"parameterOverrides"`
+	Outputs            []redactTestValue `json:"outputs" yaml:"outputs"`
+}
+
+type redactTestValue struct {
+	Name  string `json:"name" yaml:"name"`
+	Value string `json:"value" yaml:"value"`
+	Count int    `json:"count" yaml:"count"`
+}
+
+func TestPrintJson_Redactions(t *testing.T) {
+	runs := []redactTestRun{
+		{
+			ID:                 "1",
+			ParameterOverrides: map[string]string{"password": "topsecret"},
+			Outputs:            []redactTestValue{{Name: "token", Value: "abc<123>", Count: 1}},
+		},
+	}
+
+	testcases := []struct {
+		name  string
+		paths []string
+		want  string
+	}{
+		{
+			name:  "recursive descent",
+			paths: []string{"$..parameterOverrides"},
+			want: `[
+  {
+    "id": "1",
+    "outputs": [
+      {
+        "name": "token",
+        "value": "abc\u003c123\u003e",
+        "count": 1
+      }
+    ]
+  }
+]
+`,
+		},
+		{
+			name:  "wildcards",
+			paths: []string{"$[*].parameterOverrides", "$.*.outputs[*].value"},
+			want: `[
+  {
+    "id": "1",
+    "outputs": [
+      {
+        "name": "token",
+        "count": 1
+      }
+    ]
+  }
+]
+`,
+		},
+		{
+			name:  "array index",
+			paths: []string{"$[0].outputs[0]", "$[0]['parameterOverrides'].password"},
+			want: `[
+  {
+    "id": "1",
+    "parameterOverrides": {},
+    "outputs": []
+  }
+]
+`,
+		},
+		{
+			name:  "no match",
+			paths: []string{"$..missing"},
+			want: `[
+  {
+    "id": "1",
+    "parameterOverrides": {
+      "password": "topsecret"
+    },
+    "outputs": [
+      {
+        "name": "token",
+        "value": "abc\u003c123\u003e",
+        "count": 1
+      }
+    ]
+  }
+]
+`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			redactions, err := ParseRedactions(tc.paths)
+			require.NoError(t, err)
+
+			b := &bytes.Buffer{}
+			err = PrintJson(NewRedactingWriter(b, redactions), runs)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, b.String())
+		})
+	}
+}
+
+func TestPrintYaml_Redactions(t *testing.T) {
+	run := redactTestRun{
+		ID:                 "1",
+		ParameterOverrides: map[string]string{"password": "topsecret"},
+		Outputs:            []redactTestValue{{Name: "token", Value: "abc", Count: 1}},
+	}
+
+	redactions, err := ParseRedactions([]string{"$.parameterOverrides", "$..count"})
+	require.NoError(t, err)
+
+	b := &bytes.Buffer{}
+	err = PrintYaml(NewRedactingWriter(b, redactions), run)
+	require.NoError(t, err)
+	assert.Equal(t, "id: \"1\"\noutputs:\n  - name: token\n    value: abc\n", b.String())
+}
+
+func TestNewRedactingWriter(t *testing.T) {
+	redactions, err := ParseRedactions([]string{"$.a"})
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+	assert.Equal(t, &b, NewRedactingWriter(&b, nil), "the writer should not be wrapped without redactions")
+
+	out := NewRedactingWriter(NewProfileWriter(&b, ProfileCI), redactions)
+	assert.Equal(t, ProfileCI, GetProfile(out), "the profile should be preserved")
+	assert.Equal(t, redactions, GetRedactions(out))
+
+	out = NewProfileWriter(out, ProfileDefault)
+	assert.Equal(t, ProfileDefault, GetProfile(out))
+	assert.Equal(t, redactions, GetRedactions(out), "the redactions should be preserved when the profile changes")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"strings"
 
 	"github.com/olekukonko/tablewriter"
 )
@@ -29,6 +30,16 @@ func PrintTable(out io.Writer, v interface{}, getRow func(row interface{}) []str
 
 	rows := reflect.ValueOf(v)
 
+	// Porcelain tables are tab separated values without headers, so that they are easily parsed
+	if GetProfile(out).Porcelain() {
+		for i := 0; i < rows.Len(); i++ {
+			if _, err := fmt.Fprintln(out, strings.Join(getRow(rows.Index(i).Interface()), "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	table := NewTableSection(out)
 
 	// Print the outputs table
@@ -49,3 +49,18 @@ func TestPrintTable_WithoutHeaders(t *testing.T) {
 	require.NoError(t, err)
 	test.CompareGoldenFile(t, "testdata/table-without-headers.txt", b.String())
 }
+
+func TestPrintTable_Porcelain(t *testing.T) {
+	v := []testType{
+		{A: "foo", B: "a really long bit of text that should not be wrapped"},
+		{A: 123, B: true},
+	}
+
+	b := &bytes.Buffer{}
+
+	err := PrintTable(NewProfileWriter(b, ProfilePorcelain), v, printTestType,
+		"A", "B")
+
+	require.NoError(t, err)
+	require.Equal(t, "foo\ta really long bit of text that should not be wrapped\n123\ttrue\n", b.String())
+}
@@ -7,12 +7,18 @@ import (
 	"get.porter.sh/porter/pkg/encoding"
 )
 
-// PrintYaml is a printer that prints the provided value in yaml
+// PrintYaml is a printer that prints the provided value in yaml, removing
+// any fields redacted by the writer.
 func PrintYaml(out io.Writer, v interface{}) error {
 	b, err := encoding.MarshalYaml(v)
 	if err != nil {
 		return fmt.Errorf("could not marshal value to yaml: %w", err)
 	}
+	if redactions := GetRedactions(out); len(redactions) > 0 {
+		if b, err = redactDocument(FormatYaml, b, redactions); err != nil {
+			return err
+		}
+	}
 	fmt.Fprint(out, string(b)) // yaml already includes a trailing newline, so don't print another
 	return nil
 }
@@ -0,0 +1,123 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/storage"
+)
+
+// DefaultProbeAction is the action that is probed when an action is not specified.
+const DefaultProbeAction = "status"
+
+// ProbeResult is the structured status reported by a probe of the bundle.
+type ProbeResult struct {
+	// Action that was probed.
+	Action string `json:"action"`
+
+	// Status of the probe, either StepStatusSucceeded or StepStatusFailed.
+	Status string `json:"status"`
+
+	// Started timestamp of the probe.
+	Started time.Time `json:"started"`
+
+	// Stopped timestamp of the probe.
+	Stopped time.Time `json:"stopped"`
+
+	// Steps are the results of the steps executed by the probe.
+	Steps []storage.StepResult `json:"steps"`
+
+	// Outputs are the bundle outputs generated by the probe.
+	// Sensitive outputs are not included.
+	Outputs map[string]string `json:"outputs,omitempty"`
+
+	// Error message when the probe failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Probe executes a read-only action of the bundle, such as status, and reports
+// its result. Unlike Execute, the images of the bundle are not resolved, the
+// bundle outputs and step results are not written, and the state of the bundle
+// is not saved, so that the probe is cheap to run and does not change the
+// bundle. The logs of the mixins are captured in the step results instead of
+// printed, so that the result is the only output of the probe.
+func (r *PorterRuntime) Probe(ctx context.Context, rm *RuntimeManifest) (ProbeResult, error) {
+	r.RuntimeManifest = rm
+	r.probeOutputs = map[string]string{}
+	defer func() { r.probeOutputs = nil }()
+
+	result := ProbeResult{
+		Action:  rm.Action,
+		Started: time.Now(),
+	}
+
+	out := r.config.Out
+	r.config.Out = io.Discard
+	err := r.probe(ctx)
+	r.config.Out = out
+
+	result.Stopped = time.Now()
+	result.Steps = r.stepResults
+	if result.Steps == nil {
+		result.Steps = []storage.StepResult{}
+	}
+	if err != nil {
+		result.Status = storage.StepStatusFailed
+		result.Error = err.Error()
+		return result, err
+	}
+
+	result.Status = storage.StepStatusSucceeded
+	if len(r.probeOutputs) > 0 {
+		result.Outputs = r.probeOutputs
+	}
+	return result, nil
+}
+
+func (r *PorterRuntime) probe(ctx context.Context) error {
+	err := r.RuntimeManifest.Validate()
+	if err != nil {
+		return err
+	}
+
+	action := r.RuntimeManifest.Action
+	def, ok := r.RuntimeManifest.bundle.Actions[action]
+	if !ok || def.Modifies {
+		return fmt.Errorf("the %s action cannot be probed because it may modify the resources of the bundle, only custom actions that set modifies: false can be probed", action)
+	}
+
+	err = r.RuntimeManifest.Initialize(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = r.config.FileSystem.MkdirAll(portercontext.MixinOutputsDir, pkg.FileModeDirectory)
+	if err != nil {
+		return fmt.Errorf("could not create outputs directory %s: %w", portercontext.MixinOutputsDir, err)
+	}
+
+	for stepIndex, step := range r.RuntimeManifest.GetSteps() {
+		err = r.executeStep(ctx, stepIndex, step)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordProbeOutput saves a bundle output generated during a probe, so that
+// it is reported with the result instead of written to the outputs directory.
+func (r *PorterRuntime) recordProbeOutput(name string, value string) error {
+	sensitive, err := r.RuntimeManifest.bundle.IsOutputSensitive(name)
+	if err != nil {
+		return err
+	}
+	if !sensitive {
+		r.probeOutputs[name] = value
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/mixin"
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const probeManifest = `schemaVersion: 1.0.0
+mixins:
+- exec
+
+outputs:
+- name: health
+- name: token
+  sensitive: true
+
+install:
+- exec:
+    description: "Install the database"
+    command: ./helpers.sh
+
+status:
+- exec:
+    description: "Check the database"
+    command: ./helpers.sh
+    outputs:
+    - name: health
+    - name: token
+
+upgrade:
+- exec:
+    description: "Upgrade the database"
+    command: ./helpers.sh
+`
+
+func setupProbe(t *testing.T, statusModifies bool) (*TestPorterRuntime, *RuntimeManifest) {
+	r := NewTestPorterRuntime(t)
+
+	bun := bundle.Bundle{
+		SchemaVersion: "v1.0.0",
+		Name:          "mydb",
+		Version:       "0.1.0",
+		Actions: map[string]bundle.Action{
+			"status": {Modifies: statusModifies},
+		},
+		Definitions: definition.Definitions{
+			"health-output": {Type: "string"},
+			"token-output":  {Type: "string", WriteOnly: boolPtr(true)},
+		},
+		Outputs: map[string]bundle.Output{
+			"health": {Definition: "health-output", Path: "/cnab/app/outputs/health"},
+			"token":  {Definition: "token-output", Path: "/cnab/app/outputs/token"},
+		},
+	}
+	data, err := json.Marshal(bun)
+	require.NoError(t, err)
+	require.NoError(t, r.config.FileSystem.WriteFile("/cnab/bundle.json", data, pkg.FileModeWritable))
+
+	rm := runtimeManifestFromStepYaml(t, r.TestContext, probeManifest)
+	rm.Action = "status"
+	return r, rm
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+func TestPorterRuntime_Probe(t *testing.T) {
+	ctx := context.Background()
+	r, rm := setupProbe(t, false)
+
+	mixins := r.mixins.(*mixin.TestMixinProvider)
+	mixins.RunAssertions = append(mixins.RunAssertions, func(pkgContext *portercontext.Context, name string, commandOpts pkgmgmt.CommandOptions) error {
+		assert.Equal(t, "status", commandOpts.Command, "the status action should be executed")
+		fmt.Fprintln(pkgContext.Out, "database is healthy")
+		require.NoError(t, pkgContext.FileSystem.WriteFile(filepath.Join(portercontext.MixinOutputsDir, "health"), []byte("ok"), pkg.FileModeWritable))
+		require.NoError(t, pkgContext.FileSystem.WriteFile(filepath.Join(portercontext.MixinOutputsDir, "token"), []byte("topsecret"), pkg.FileModeWritable))
+		return nil
+	})
+
+	result, err := r.Probe(ctx, rm)
+	require.NoError(t, err)
+
+	assert.Equal(t, "status", result.Action)
+	assert.Equal(t, storage.StepStatusSucceeded, result.Status)
+	assert.Empty(t, result.Error)
+	assert.False(t, result.Stopped.Before(result.Started))
+	assert.Equal(t, map[string]string{"health": "ok"}, result.Outputs, "sensitive outputs should not be reported")
+
+	require.Len(t, result.Steps, 1)
+	assert.Equal(t, "Check the database", result.Steps[0].Description)
+	assert.Equal(t, storage.StepStatusSucceeded, result.Steps[0].Status)
+	assert.Equal(t, "database is healthy\n", result.Steps[0].Logs)
+
+	assert.Empty(t, r.TestContext.GetOutput(), "the logs of the probe should only be captured in the step results")
+	for _, output := range []string{"health", "token", storage.StepResultsOutput} {
+		exists, _ := r.config.FileSystem.Exists(filepath.Join(config.BundleOutputsDir, output))
+		assert.False(t, exists, "the probe should not write the %s output", output)
+	}
+}
+
+func TestPorterRuntime_Probe_Failed(t *testing.T) {
+	ctx := context.Background()
+	r, rm := setupProbe(t, false)
+
+	mixins := r.mixins.(*mixin.TestMixinProvider)
+	mixins.RunAssertions = append(mixins.RunAssertions, func(pkgContext *portercontext.Context, name string, commandOpts pkgmgmt.CommandOptions) error {
+		fmt.Fprintln(pkgContext.Err, "could not connect to the database")
+		return fmt.Errorf("exit status 1")
+	})
+
+	result, err := r.Probe(ctx, rm)
+	require.Error(t, err)
+
+	assert.Equal(t, storage.StepStatusFailed, result.Status)
+	assert.Contains(t, result.Error, "mixin execution failed")
+	require.Len(t, result.Steps, 1)
+	assert.Equal(t, storage.StepStatusFailed, result.Steps[0].Status)
+	assert.Contains(t, result.Steps[0].Logs, "could not connect to the database")
+}
+
+func TestPorterRuntime_Probe_ModifyingAction(t *testing.T) {
+	testcases := []struct {
+		name           string
+		action         string
+		statusModifies bool
+	}{
+		{name: "main action", action: "upgrade"},
+		{name: "custom action that modifies resources", action: "status", statusModifies: true},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			r, rm := setupProbe(t, tc.statusModifies)
+			rm.Action = tc.action
+
+			mixins := r.mixins.(*mixin.TestMixinProvider)
+			mixins.RunAssertions = append(mixins.RunAssertions, func(pkgContext *portercontext.Context, name string, commandOpts pkgmgmt.CommandOptions) error {
+				return fmt.Errorf("the %s action should not be executed", commandOpts.Command)
+			})
+
+			result, err := r.Probe(ctx, rm)
+			require.Error(t, err)
+			assert.True(t, strings.HasPrefix(err.Error(), fmt.Sprintf("the %s action cannot be probed", tc.action)), "unexpected error: %s", err)
+			assert.Equal(t, storage.StepStatusFailed, result.Status)
+			assert.Empty(t, result.Steps)
+		})
+	}
+}
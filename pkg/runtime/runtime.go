@@ -3,15 +3,24 @@ package runtime
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"get.porter.sh/porter/pkg"
 	"get.porter.sh/porter/pkg/cnab"
 	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/faults"
 	"get.porter.sh/porter/pkg/manifest"
 	"get.porter.sh/porter/pkg/pkgmgmt"
 	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tools"
 	"get.porter.sh/porter/pkg/yaml"
 	"github.com/cnabio/cnab-to-oci/relocation"
 	"github.com/hashicorp/go-multierror"
@@ -22,6 +31,13 @@ type PorterRuntime struct {
 	config          RuntimeConfig
 	mixins          pkgmgmt.PackageManager
 	RuntimeManifest *RuntimeManifest
+
+	// stepResults are the results of the steps executed so far.
+	stepResults []storage.StepResult
+
+	// probeOutputs are the bundle outputs generated while probing the bundle.
+	// When set, bundle outputs are saved here instead of written to the outputs directory.
+	probeOutputs map[string]string
 }
 
 func NewPorterRuntime(runtimeCfg RuntimeConfig, mixins pkgmgmt.PackageManager) *PorterRuntime {
@@ -43,6 +59,12 @@ func (r *PorterRuntime) Execute(ctx context.Context, rm *RuntimeManifest) error
 		return err
 	}
 
+	// Check that the invocation image has the tool versions that the bundle pinned
+	err = tools.Verify(ctx, r.config.Context, r.RuntimeManifest.Tools)
+	if err != nil {
+		return fmt.Errorf("the tools in the invocation image do not match the versions pinned in the bundle: %w", err)
+	}
+
 	// Prepare prepares the runtime environment prior to step execution.
 	// As an example, for parameters of type "file", we may need to decode file contents
 	// on the filesystem before execution of the step/action
@@ -72,12 +94,19 @@ func (r *PorterRuntime) Execute(ctx context.Context, rm *RuntimeManifest) error
 	}
 
 	var bigErr *multierror.Error
+	injected := faults.FromEnv(r.config.Getenv)
 	for stepIndex, step := range r.RuntimeManifest.GetSteps() {
 		err = r.executeStep(ctx, stepIndex, step)
 		if err != nil {
 			bigErr = multierror.Append(bigErr, err)
 			break
 		}
+
+		// Fail the action after the step when a failure was requested for testing
+		if err = injected.Inject(faults.AfterStep, strconv.Itoa(stepIndex+1)); err != nil {
+			bigErr = multierror.Append(bigErr, err)
+			break
+		}
 	}
 
 	err = r.RuntimeManifest.Finalize(ctx)
@@ -85,25 +114,57 @@ func (r *PorterRuntime) Execute(ctx context.Context, rm *RuntimeManifest) error
 		bigErr = multierror.Append(bigErr, err)
 	}
 
+	err = r.writeStepResults()
+	if err != nil {
+		bigErr = multierror.Append(bigErr, err)
+	}
+
 	return bigErr.ErrorOrNil()
 }
 
-func (r *PorterRuntime) executeStep(ctx context.Context, stepIndex int, step *manifest.Step) error {
+func (r *PorterRuntime) executeStep(ctx context.Context, stepIndex int, step *manifest.Step) (err error) {
 	if step == nil {
 		return nil
 	}
-	err := r.RuntimeManifest.ResolveStep(ctx, stepIndex, step)
+
+	description, _ := step.GetDescription()
+	stepResult := storage.StepResult{
+		Index:       stepIndex,
+		Description: description,
+		Mixin:       step.GetMixinName(),
+		Started:     time.Now(),
+	}
+	logs := newStepLogs(storage.StepLogsLimit)
+	r.emitStepEvent(storage.RunEventStepStarted, stepResult)
+	defer func() {
+		r.recordStepResult(stepResult, logs, err)
+	}()
+
+	err = r.RuntimeManifest.ResolveStep(ctx, stepIndex, step)
 	if err != nil {
 		return fmt.Errorf("unable to resolve step: %w", err)
 	}
 
-	description, _ := step.GetDescription()
+	// The description may contain templates, so read it again after the step is resolved
+	description, _ = step.GetDescription()
+	stepResult.Description = description
 	if len(description) > 0 {
 		fmt.Fprintln(r.config.Out, description)
 	}
 
 	// Hand over values needing masking in config output streams
-	r.config.Context.SetSensitiveValues(r.RuntimeManifest.GetSensitiveValues())
+	sensitiveValues := r.RuntimeManifest.GetSensitiveValues()
+	r.config.Context.SetSensitiveValues(sensitiveValues)
+
+	// Capture the end of the logs from the mixin, masking sensitive values
+	censoredLogs := portercontext.NewCensoredWriter(logs)
+	censoredLogs.SetSensitiveValues(sensitiveValues)
+	out, errOut := r.config.Out, r.config.Err
+	r.config.Out = io.MultiWriter(out, censoredLogs)
+	r.config.Err = io.MultiWriter(errOut, censoredLogs)
+	defer func() {
+		r.config.Out, r.config.Err = out, errOut
+	}()
 
 	input := &ActionInput{
 		action: r.RuntimeManifest.Action,
@@ -144,6 +205,13 @@ func (r *PorterRuntime) applyStepOutputsToBundle(outputs map[string]string) erro
 		}
 
 		if r.shouldApplyOutput(bundleOutput) {
+			if r.probeOutputs != nil {
+				if err := r.recordProbeOutput(bundleOutput.Name, outputValue); err != nil {
+					return err
+				}
+				continue
+			}
+
 			outpath := filepath.Join(config.BundleOutputsDir, bundleOutput.Name)
 
 			err := r.config.FileSystem.WriteFile(outpath, []byte(outputValue), pkg.FileModeWritable)
@@ -197,6 +265,69 @@ func (r *PorterRuntime) readMixinOutputs() (map[string]string, error) {
 	return outputs, nil
 }
 
+// recordStepResult records the outcome of a step, so that it is reported to
+// Porter when the bundle completes.
+func (r *PorterRuntime) recordStepResult(stepResult storage.StepResult, logs *stepLogs, err error) {
+	stepResult.Stopped = time.Now()
+	stepResult.Logs, stepResult.LogsTruncated = logs.String(), logs.Truncated()
+	if err != nil {
+		stepResult.Status = storage.StepStatusFailed
+		stepResult.Error = r.censor(err.Error())
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			stepResult.ExitCode = exitErr.ExitCode()
+		}
+	} else {
+		stepResult.Status = storage.StepStatusSucceeded
+	}
+	r.stepResults = append(r.stepResults, stepResult)
+	r.emitStepEvent(storage.RunEventStepCompleted, stepResult)
+}
+
+// censor masks the sensitive values of the bundle in a message, such as the
+// error returned by a mixin which includes its stderr, the same way that the
+// logs of the step are masked.
+func (r *PorterRuntime) censor(msg string) string {
+	var censored strings.Builder
+	w := portercontext.NewCensoredWriter(&censored)
+	w.SetSensitiveValues(r.RuntimeManifest.GetSensitiveValues())
+	_, _ = w.Write([]byte(msg))
+	return censored.String()
+}
+
+// emitStepEvent reports the progress of a step to Porter as it happens, when
+// Porter requested the events of the run. The logs of the step are not
+// included, because Porter already receives them as the step runs.
+func (r *PorterRuntime) emitStepEvent(eventType string, stepResult storage.StepResult) {
+	if enabled, _ := strconv.ParseBool(r.config.Getenv(config.EnvRunEvents)); !enabled {
+		return
+	}
+
+	stepResult.Logs, stepResult.LogsTruncated = "", false
+	event, err := storage.FormatRunEvent(storage.RunEvent{Type: eventType, Time: time.Now(), Step: &stepResult})
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(r.config.Err, event)
+}
+
+// writeStepResults saves the results of the executed steps to the
+// porter-step-results output, so that Porter can record them with the run.
+func (r *PorterRuntime) writeStepResults() error {
+	data, err := json.Marshal(r.stepResults)
+	if err != nil {
+		return fmt.Errorf("could not marshal the step results: %w", err)
+	}
+
+	outpath := filepath.Join(config.BundleOutputsDir, storage.StepResultsOutput)
+	err = r.config.FileSystem.WriteFile(outpath, data, pkg.FileModeWritable)
+	if err != nil {
+		return fmt.Errorf("unable to write output file %s: %w", outpath, err)
+	}
+	return nil
+}
+
 func (r *PorterRuntime) getImageMappingFiles() (cnab.ExtendedBundle, relocation.ImageRelocationMap, error) {
 	// TODO(carolynvs): switch to returning a BundleReference
 	b, err := cnab.LoadBundle(r.config.Context, "/cnab/bundle.json")
@@ -21,6 +21,7 @@ import (
 	"get.porter.sh/porter/pkg/yaml"
 	"github.com/cbroglie/mustache"
 	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
 	"github.com/cnabio/cnab-to-oci/relocation"
 	"github.com/hashicorp/go-multierror"
 	"github.com/opencontainers/go-digest"
@@ -612,6 +613,10 @@ func (m *RuntimeManifest) Finalize(ctx context.Context) error {
 		bigErr = multierror.Append(bigErr, err)
 	}
 
+	if err := m.validateBundleOutputs(ctx); err != nil {
+		bigErr = multierror.Append(bigErr, err)
+	}
+
 	// Always try to persist state, even when errors occur
 	if err := m.packStateBag(ctx); err != nil {
 		bigErr = multierror.Append(bigErr, err)
@@ -732,6 +737,73 @@ func (m *RuntimeManifest) applyUnboundBundleOutputs(ctx context.Context) error {
 	return log.Error(bigErr.ErrorOrNil())
 }
 
+// validateBundleOutputs checks the bundle outputs generated by the action
+// against the schema defined for each output. Malformed outputs are removed so
+// that they are not persisted, and the action fails.
+func (m *RuntimeManifest) validateBundleOutputs(ctx context.Context) error {
+	if len(m.bundle.Outputs) == 0 {
+		return nil
+	}
+
+	log := tracing.LoggerFromContext(ctx)
+
+	var bigErr *multierror.Error
+	for name, output := range m.bundle.Outputs {
+		if !output.AppliesTo(m.Action) || m.bundle.IsInternalOutput(name) {
+			continue
+		}
+
+		def, ok := m.bundle.Definitions[output.Definition]
+		if !ok || def.Type == nil || def.ContentEncoding != "" {
+			// Files and outputs without a type can't be validated
+			continue
+		}
+
+		outputPath := filepath.Join(config.BundleOutputsDir, name)
+		contents, err := m.config.FileSystem.ReadFile(outputPath)
+		if err != nil {
+			// Missing outputs are reported by porter when the run is saved
+			continue
+		}
+
+		value := string(contents)
+		if def.Type != "string" {
+			value = strings.TrimSpace(value)
+		}
+
+		if err = validateOutputValue(def, value); err != nil {
+			bigErr = multierror.Append(bigErr, fmt.Errorf("invalid value for output %s: %w", name, err))
+
+			// Do not persist the malformed output
+			if err = m.config.FileSystem.Remove(outputPath); err != nil {
+				bigErr = multierror.Append(bigErr, fmt.Errorf("could not remove invalid output %s: %w", name, err))
+			}
+		}
+	}
+
+	return log.Error(bigErr.ErrorOrNil())
+}
+
+// validateOutputValue converts the output value to the type of the output, and
+// then validates it against the output's schema.
+func validateOutputValue(def *definition.Schema, value string) error {
+	typedValue, err := def.ConvertValue(value)
+	if err != nil {
+		return err
+	}
+
+	valErrs, err := def.Validate(typedValue)
+	if err != nil {
+		return err
+	}
+
+	var bigErr *multierror.Error
+	for _, valErr := range valErrs {
+		bigErr = multierror.Append(bigErr, fmt.Errorf("%s: %s", valErr.Path, valErr.Error))
+	}
+	return bigErr.ErrorOrNil()
+}
+
 // ResolveInvocationImage updates the RuntimeManifest to properly reflect the invocation image passed to the bundle via the
 // mounted bundle.json and relocation mapping
 func (m *RuntimeManifest) ResolveInvocationImage(bun cnab.ExtendedBundle, reloMap relocation.ImageRelocationMap) error {
@@ -1118,3 +1118,51 @@ func TestResolveInvocationImage(t *testing.T) {
 	}
 
 }
+
+func TestRuntimeManifest_validateBundleOutputs(t *testing.T) {
+	ctx := context.Background()
+
+	testcases := []struct {
+		name      string
+		schema    definition.Schema
+		value     string
+		wantError string
+	}{
+		{name: "valid string", schema: definition.Schema{Type: "string", Enum: []interface{}{"v1", "v2"}}, value: "v1"},
+		{name: "invalid string", schema: definition.Schema{Type: "string", Enum: []interface{}{"v1", "v2"}}, value: "latest", wantError: "invalid value for output out"},
+		{name: "valid integer with trailing newline", schema: definition.Schema{Type: "integer"}, value: "8080\n"},
+		{name: "invalid integer", schema: definition.Schema{Type: "integer"}, value: "http", wantError: "invalid value for output out"},
+		{name: "valid object", schema: definition.Schema{Type: "object", Required: []string{"host"}}, value: `{"host": "localhost"}`},
+		{name: "object missing required property", schema: definition.Schema{Type: "object", Required: []string{"host"}}, value: `{"port": 8080}`, wantError: `"host" value is required`},
+		{name: "malformed object", schema: definition.Schema{Type: "object"}, value: `{"host":`, wantError: "could not unmarshal value"},
+		{name: "no type", schema: definition.Schema{}, value: "anything"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			pCtx := portercontext.NewTestContext(t)
+			rm := runtimeManifestFromStepYaml(t, pCtx, "schemaVersion: 1.0.0\ninstall:\n- mymixin: {}\n")
+			rm.bundle = cnab.NewBundle(bundle.Bundle{
+				Definitions: definition.Definitions{"out": &tc.schema},
+				Outputs: map[string]bundle.Output{
+					"out": {Definition: "out"},
+				},
+			})
+
+			outputPath := "/cnab/app/outputs/out"
+			require.NoError(t, pCtx.FileSystem.WriteFile(outputPath, []byte(tc.value), pkg.FileModeWritable))
+
+			err := rm.validateBundleOutputs(ctx)
+			if tc.wantError != "" {
+				require.ErrorContains(t, err, tc.wantError)
+				exists, _ := pCtx.FileSystem.Exists(outputPath)
+				assert.False(t, exists, "the malformed output should not be persisted")
+			} else {
+				require.NoError(t, err)
+				exists, _ := pCtx.FileSystem.Exists(outputPath)
+				assert.True(t, exists, "the valid output should be persisted")
+			}
+		})
+	}
+}
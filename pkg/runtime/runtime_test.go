@@ -2,14 +2,22 @@ package runtime
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"get.porter.sh/porter/pkg"
 	"get.porter.sh/porter/pkg/cnab"
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/manifest"
+	"get.porter.sh/porter/pkg/mixin"
+	"get.porter.sh/porter/pkg/pkgmgmt"
 	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/storage"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-go/bundle/definition"
 	"github.com/stretchr/testify/assert"
@@ -321,3 +329,85 @@ func TestLoadImageMappingFilesGoodFiles(t *testing.T) {
 	assert.NotEmpty(t, reloMap)
 	assert.Equal(t, "mysql", bun.Name)
 }
+
+func TestPorterRuntime_executeStep_RecordsStepResults(t *testing.T) {
+	ctx := context.Background()
+	r := NewTestPorterRuntime(t)
+	require.NoError(t, r.config.FileSystem.MkdirAll(portercontext.MixinOutputsDir, pkg.FileModeDirectory))
+
+	mContent := `schemaVersion: 1.0.0
+parameters:
+- name: password
+  sensitive: true
+
+install:
+- exec:
+    description: "Create the database"
+    command: ./helpers.sh
+- exec:
+    description: "Load the data"
+    command: ./helpers.sh
+`
+	r.TestContext.Setenv("PASSWORD", "topsecret")
+	r.RuntimeManifest = runtimeManifestFromStepYaml(t, r.TestContext, mContent)
+	require.NoError(t, r.RuntimeManifest.Initialize(ctx))
+
+	mixins := r.mixins.(*mixin.TestMixinProvider)
+	mixins.RunAssertions = append(mixins.RunAssertions, func(pkgContext *portercontext.Context, name string, commandOpts pkgmgmt.CommandOptions) error {
+		if strings.Contains(commandOpts.Input, "Load the data") {
+			fmt.Fprintln(pkgContext.Err, "could not connect with password topsecret")
+			return fmt.Errorf("mixin failed: %w", exec.Command("sh", "-c", "exit 3").Run())
+		}
+		fmt.Fprintln(pkgContext.Out, "database created")
+		return nil
+	})
+
+	for i, step := range r.RuntimeManifest.Install {
+		_ = r.executeStep(ctx, i, step)
+	}
+	require.NoError(t, r.writeStepResults())
+
+	data, err := r.config.FileSystem.ReadFile(filepath.Join(config.BundleOutputsDir, storage.StepResultsOutput))
+	require.NoError(t, err, "the step results should be written to the porter-step-results output")
+	var steps []storage.StepResult
+	require.NoError(t, json.Unmarshal(data, &steps))
+	require.Len(t, steps, 2)
+
+	assert.Equal(t, 0, steps[0].Index)
+	assert.Equal(t, "Create the database", steps[0].Description)
+	assert.Equal(t, "exec", steps[0].Mixin)
+	assert.Equal(t, storage.StepStatusSucceeded, steps[0].Status)
+	assert.Equal(t, "database created\n", steps[0].Logs)
+	assert.False(t, steps[0].Stopped.Before(steps[0].Started))
+
+	assert.Equal(t, 1, steps[1].Index)
+	assert.Equal(t, storage.StepStatusFailed, steps[1].Status)
+	assert.Equal(t, 3, steps[1].ExitCode)
+	assert.Contains(t, steps[1].Error, "mixin execution failed")
+	assert.Equal(t, "could not connect with password *******\n", steps[1].Logs, "sensitive values should be masked in the logs")
+}
+
+func TestStepLogs(t *testing.T) {
+	logs := newStepLogs(10)
+
+	fmt.Fprint(logs, "abc")
+	assert.Equal(t, "abc", logs.String())
+	assert.False(t, logs.Truncated())
+
+	fmt.Fprint(logs, "defghijklmn")
+	assert.Equal(t, "efghijklmn", logs.String(), "only the end of the logs should be kept")
+	assert.True(t, logs.Truncated())
+}
+
+func TestPorterRuntime_RecordStepResult_CensorsError(t *testing.T) {
+	r := NewTestPorterRuntime(t)
+	r.RuntimeManifest = r.NewRuntimeManifest(cnab.ActionInstall, &manifest.Manifest{Name: "mybun"})
+	r.RuntimeManifest.setSensitiveValue("topsecret")
+
+	err := errors.New("mixin failed: could not log in with password topsecret")
+	r.recordStepResult(storage.StepResult{Index: 0}, newStepLogs(storage.StepLogsLimit), err)
+
+	require.Len(t, r.stepResults, 1)
+	assert.Equal(t, storage.StepStatusFailed, r.stepResults[0].Status)
+	assert.Equal(t, "mixin failed: could not log in with password *******", r.stepResults[0].Error)
+}
@@ -0,0 +1,32 @@
+package runtime
+
+// stepLogs keeps the end of the logs written by a step, discarding the
+// beginning of the logs once the limit is reached.
+type stepLogs struct {
+	limit     int
+	data      []byte
+	truncated bool
+}
+
+func newStepLogs(limit int) *stepLogs {
+	return &stepLogs{limit: limit}
+}
+
+func (l *stepLogs) Write(p []byte) (int, error) {
+	l.data = append(l.data, p...)
+	if extra := len(l.data) - l.limit; extra > 0 {
+		l.data = append(l.data[:0], l.data[extra:]...)
+		l.truncated = true
+	}
+	return len(p), nil
+}
+
+// String returns the logs that were kept.
+func (l *stepLogs) String() string {
+	return string(l.data)
+}
+
+// Truncated indicates that the beginning of the logs was discarded.
+func (l *stepLogs) Truncated() bool {
+	return l.truncated
+}
@@ -0,0 +1,71 @@
+package secrets
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// AuditActionResolve is the action of an audit event that records a
+	// sensitive value was resolved from the secret store.
+	AuditActionResolve = "resolve"
+
+	// AuditKindParameter is the kind of an audit event for a sensitive parameter.
+	AuditKindParameter = "parameter"
+
+	// AuditKindCredential is the kind of an audit event for a credential.
+	AuditKindCredential = "credential"
+
+	// AuditKindOutput is the kind of an audit event for a sensitive output.
+	AuditKindOutput = "output"
+)
+
+// AuditEvent records that a sensitive value was resolved from the secret
+// store. It never includes the value.
+type AuditEvent struct {
+	// Time that the value was resolved.
+	Time time.Time `json:"time"`
+
+	// Action performed on the value, for example resolve.
+	Action string `json:"action"`
+
+	// Kind of the value: parameter, credential or output.
+	Kind string `json:"kind"`
+
+	// Name of the parameter or output.
+	Name string `json:"name"`
+
+	// SecretKey is the key of the value in the secret store.
+	SecretKey string `json:"secretKey"`
+
+	// Namespace of the installation.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Installation that the value belongs to.
+	Installation string `json:"installation,omitempty"`
+
+	// RunID of the run that generated the output, when known.
+	RunID string `json:"runId,omitempty"`
+
+	// User that ran Porter.
+	User string `json:"user,omitempty"`
+
+	// Error that prevented the value from being resolved.
+	Error string `json:"error,omitempty"`
+}
+
+// AuditHook is notified every time a sensitive parameter, credential or
+// output is resolved from the secret store or decrypted with a key management
+// service.
+type AuditHook interface {
+	// Audit records the event. An error is returned when the event could not
+	// be recorded and the value must not be used.
+	Audit(ctx context.Context, event AuditEvent) error
+}
+
+// NoopAuditHook discards audit events.
+type NoopAuditHook struct{}
+
+func (NoopAuditHook) Audit(ctx context.Context, event AuditEvent) error {
+	return nil
+}
@@ -0,0 +1,114 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// Sink records audit events.
+type Sink interface {
+	// Type of the sink, for example file.
+	Type() string
+
+	// Write records the event.
+	Write(ctx context.Context, event secrets.AuditEvent) error
+}
+
+// NewSink creates the sink selected by the audit-log configuration.
+func NewSink(c *config.Config) (Sink, error) {
+	cfg := c.Data.AuditLog
+	switch cfg.Sink {
+	case config.AuditSinkFile:
+		return NewFileSink(c, cfg.File)
+	case config.AuditSinkSyslog:
+		return NewSyslogSink(cfg.Syslog)
+	case config.AuditSinkWebhook:
+		return NewWebhookSink(cfg.Webhook)
+	default:
+		return nil, fmt.Errorf("invalid audit-log.sink %q, allowed values are: %s, %s, %s",
+			cfg.Sink, config.AuditSinkFile, config.AuditSinkSyslog, config.AuditSinkWebhook)
+	}
+}
+
+var _ secrets.AuditHook = &ConfigHook{}
+
+// ConfigHook records audit events with the audit-log settings in Porter's
+// configuration.
+type ConfigHook struct {
+	config *config.Config
+
+	mu   sync.Mutex
+	sink Sink
+}
+
+// NewConfigHook creates an audit hook that uses the audit-log configuration.
+// The configuration is read when an event is recorded, so that it reflects
+// the loaded configuration.
+func NewConfigHook(c *config.Config) *ConfigHook {
+	return &ConfigHook{config: c}
+}
+
+// Audit records the event to the configured sink. When the event cannot be
+// recorded, a warning is logged, unless audit-log.required is set and the
+// error is returned instead.
+func (h *ConfigHook) Audit(ctx context.Context, event secrets.AuditEvent) error {
+	cfg := h.config.Data.AuditLog
+	if !cfg.IsEnabled() {
+		return nil
+	}
+
+	if event.User == "" {
+		event.User = h.getUser()
+	}
+
+	err := h.write(ctx, event)
+	if err == nil {
+		return nil
+	}
+
+	if cfg.Required {
+		return err
+	}
+	log := tracing.LoggerFromContext(ctx)
+	log.Warnf("Could not record the audit event for the sensitive %s %s: %s", event.Kind, event.Name, err)
+	return nil
+}
+
+func (h *ConfigHook) write(ctx context.Context, event secrets.AuditEvent) error {
+	sink, err := h.getSink()
+	if err != nil {
+		return err
+	}
+	if err = sink.Write(ctx, event); err != nil {
+		return fmt.Errorf("error writing the audit event to the %s sink: %w", sink.Type(), err)
+	}
+	return nil
+}
+
+// getSink creates the configured sink the first time it is used.
+func (h *ConfigHook) getSink() (Sink, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.sink == nil {
+		sink, err := NewSink(h.config)
+		if err != nil {
+			return nil, err
+		}
+		h.sink = sink
+	}
+	return h.sink, nil
+}
+
+// getUser returns the name of the user running Porter.
+func (h *ConfigHook) getUser() string {
+	if user := h.config.Getenv("USER"); user != "" {
+		return user
+	}
+	return h.config.Getenv("USERNAME")
+}
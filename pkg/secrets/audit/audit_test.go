@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent() secrets.AuditEvent {
+	return secrets.AuditEvent{
+		Time:         time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC),
+		Action:       secrets.AuditActionResolve,
+		Kind:         secrets.AuditKindOutput,
+		Name:         "password",
+		SecretKey:    "01FZVC5AVP8Z7A78CSCP1EJ604-password",
+		Namespace:    "dev",
+		Installation: "mysql",
+		RunID:        "01FZVC5AVP8Z7A78CSCP1EJ604",
+	}
+}
+
+func TestConfigHook_Audit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled", func(t *testing.T) {
+		c := config.NewTestConfig(t)
+		hook := NewConfigHook(c.Config)
+
+		require.NoError(t, hook.Audit(ctx, testEvent()))
+		home, _ := c.GetHomeDir()
+		exists, _ := c.FileSystem.Exists(home + "/audit.log")
+		assert.False(t, exists, "no audit log should be written when audit-log.sink is not set")
+	})
+
+	t.Run("file sink", func(t *testing.T) {
+		c := config.NewTestConfig(t)
+		c.Setenv("USER", "sally")
+		c.Data.AuditLog.Sink = config.AuditSinkFile
+		c.Data.AuditLog.File.Path = "/var/log/porter/audit.log"
+		hook := NewConfigHook(c.Config)
+
+		require.NoError(t, hook.Audit(ctx, testEvent()))
+
+		data, err := c.FileSystem.ReadFile("/var/log/porter/audit.log")
+		require.NoError(t, err)
+		var event secrets.AuditEvent
+		require.NoError(t, json.Unmarshal(data, &event))
+		want := testEvent()
+		want.User = "sally"
+		assert.Equal(t, want, event)
+	})
+
+	t.Run("invalid sink", func(t *testing.T) {
+		c := config.NewTestConfig(t)
+		c.Data.AuditLog.Sink = "printer"
+		hook := NewConfigHook(c.Config)
+
+		require.NoError(t, hook.Audit(ctx, testEvent()), "a failed audit event should only be logged by default")
+
+		c.Data.AuditLog.Required = true
+		err := hook.Audit(ctx, testEvent())
+		require.Error(t, err)
+		assert.True(t, strings.HasPrefix(err.Error(), `invalid audit-log.sink "printer"`), err.Error())
+	})
+}
@@ -0,0 +1,3 @@
+// Package audit records an audit event every time a sensitive parameter or
+// output is resolved from the secret store, to a file, syslog or a webhook.
+package audit
@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/secrets"
+)
+
+var _ Sink = &FileSink{}
+
+// FileSink appends audit events to a file, one JSON document per line.
+type FileSink struct {
+	config *config.Config
+	path   string
+
+	mu sync.Mutex
+}
+
+// NewFileSink creates a sink that appends to the configured file, which
+// defaults to PORTER_HOME/audit.log.
+func NewFileSink(c *config.Config, cfg config.FileAuditSinkConfig) (*FileSink, error) {
+	path := cfg.Path
+	if path == "" {
+		home, err := c.GetHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, "audit.log")
+	}
+	return &FileSink{config: c, path: path}, nil
+}
+
+func (s *FileSink) Type() string {
+	return config.AuditSinkFile
+}
+
+func (s *FileSink) Write(ctx context.Context, event secrets.AuditEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error marshaling the audit event: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = s.config.FileSystem.MkdirAll(filepath.Dir(s.path), pkg.FileModeDirectory); err != nil {
+		return fmt.Errorf("error creating the directory for the audit log %s: %w", s.path, err)
+	}
+
+	f, err := s.config.FileSystem.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, pkg.FileModeWritable)
+	if err != nil {
+		return fmt.Errorf("error opening the audit log %s: %w", s.path, err)
+	}
+
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing to the audit log %s: %w", s.path, err)
+	}
+	return nil
+}
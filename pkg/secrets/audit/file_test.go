@@ -0,0 +1,29 @@
+package audit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"get.porter.sh/porter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink_Write(t *testing.T) {
+	c := config.NewTestConfig(t)
+	sink, err := NewFileSink(c.Config, config.FileAuditSinkConfig{})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, sink.Write(ctx, testEvent()))
+	require.NoError(t, sink.Write(ctx, testEvent()))
+
+	home, _ := c.GetHomeDir()
+	data, err := c.FileSystem.ReadFile(home + "/audit.log")
+	require.NoError(t, err, "the audit log should default to PORTER_HOME/audit.log")
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2, "each event should be appended on its own line")
+	assert.Contains(t, lines[1], `"kind":"output","name":"password"`)
+}
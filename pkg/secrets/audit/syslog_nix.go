@@ -0,0 +1,48 @@
+//go:build !windows
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/secrets"
+)
+
+var _ Sink = &SyslogSink{}
+
+// SyslogSink sends audit events to syslog as JSON documents, with the
+// authpriv facility so that they are kept with other security events.
+type SyslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink connects to the configured syslog server, or the local syslog
+// server when an address is not set.
+func NewSyslogSink(cfg config.SyslogAuditSinkConfig) (*SyslogSink, error) {
+	tag := cfg.Tag
+	if tag == "" {
+		tag = "porter"
+	}
+
+	writer, err := syslog.Dial(cfg.Network, cfg.Address, syslog.LOG_INFO|syslog.LOG_AUTHPRIV, tag)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to syslog: %w", err)
+	}
+	return &SyslogSink{writer: writer}, nil
+}
+
+func (s *SyslogSink) Type() string {
+	return config.AuditSinkSyslog
+}
+
+func (s *SyslogSink) Write(ctx context.Context, event secrets.AuditEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error marshaling the audit event: %w", err)
+	}
+	return s.writer.Info(string(data))
+}
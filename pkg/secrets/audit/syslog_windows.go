@@ -0,0 +1,12 @@
+package audit
+
+import (
+	"errors"
+
+	"get.porter.sh/porter/pkg/config"
+)
+
+// NewSyslogSink returns an error because syslog is not available on Windows.
+func NewSyslogSink(cfg config.SyslogAuditSinkConfig) (Sink, error) {
+	return nil, errors.New("the syslog audit-log sink is not supported on Windows")
+}
@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/webhook"
+)
+
+// DefaultWebhookTimeout is the default amount of time to wait for the webhook
+// to accept an audit event.
+const DefaultWebhookTimeout = 10 * time.Second
+
+var _ Sink = &WebhookSink{}
+
+// WebhookSink posts each audit event to a webhook as a JSON document.
+type WebhookSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhookSink creates a sink that posts to the configured webhook.
+func NewWebhookSink(cfg config.WebhookAuditSinkConfig) (*WebhookSink, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("audit-log.webhook.url is required")
+	}
+
+	timeout := DefaultWebhookTimeout
+	if cfg.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid audit-log.webhook.timeout %q: %w", cfg.Timeout, err)
+		}
+	}
+
+	return &WebhookSink{
+		url:     cfg.URL,
+		headers: cfg.Headers,
+		client:  &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (s *WebhookSink) Type() string {
+	return config.AuditSinkWebhook
+}
+
+func (s *WebhookSink) Write(ctx context.Context, event secrets.AuditEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error marshaling the audit event: %w", err)
+	}
+
+	_, err = webhook.Send(ctx, s.client, http.MethodPost, s.url, s.headers, data)
+	return err
+}
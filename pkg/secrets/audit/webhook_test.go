@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSink_Write(t *testing.T) {
+	var got secrets.AuditEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		if r.Header.Get("Authorization") != "Bearer abc123" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	t.Run("accepted", func(t *testing.T) {
+		sink, err := NewWebhookSink(config.WebhookAuditSinkConfig{
+			URL:     server.URL,
+			Headers: map[string]string{"Authorization": "Bearer abc123"},
+			Timeout: "5s",
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, sink.Write(ctx, testEvent()))
+		assert.Equal(t, testEvent(), got)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		sink, err := NewWebhookSink(config.WebhookAuditSinkConfig{URL: server.URL})
+		require.NoError(t, err)
+
+		err = sink.Write(ctx, testEvent())
+		require.EqualError(t, err, "the webhook returned 401 Unauthorized: unauthorized")
+	})
+}
+
+func TestNewWebhookSink_Validate(t *testing.T) {
+	_, err := NewWebhookSink(config.WebhookAuditSinkConfig{})
+	require.EqualError(t, err, "audit-log.webhook.url is required")
+
+	_, err = NewWebhookSink(config.WebhookAuditSinkConfig{URL: "https://example.com", Timeout: "soon"})
+	require.ErrorContains(t, err, `invalid audit-log.webhook.timeout "soon"`)
+}
@@ -0,0 +1,144 @@
+package secrets
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+)
+
+var _ BulkStore = &CachingStore{}
+var _ CredentialIssuer = &CachingStore{}
+
+// CachingStore caches the secrets resolved from a secret store in memory, so
+// that a secret referenced by many parameters or credentials is only resolved
+// once. Secrets are cached for the configured ttl, and the entry closest to
+// expiring is evicted when the cache is full. Cached values are never
+// persisted.
+//
+// Only secrets from the secret source are cached. Other sources, such as
+// env, path and command, are cheap to resolve or may change between calls.
+type CachingStore struct {
+	Store
+	config *config.Config
+
+	// now returns the current time, and is replaced in tests.
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[cacheKey]cacheEntry
+}
+
+type cacheKey struct {
+	keyName  string
+	keyValue string
+}
+
+type cacheEntry struct {
+	value   string
+	expires time.Time
+}
+
+// NewCachingStore caches the secrets resolved from the store, as configured by
+// the secrets-cache section of the config file.
+func NewCachingStore(c *config.Config, store Store) *CachingStore {
+	return &CachingStore{
+		Store:   store,
+		config:  c,
+		now:     time.Now,
+		entries: make(map[cacheKey]cacheEntry),
+	}
+}
+
+func (s *CachingStore) Resolve(ctx context.Context, keyName string, keyValue string) (string, error) {
+	cfg := s.config.Data.SecretsCache
+	if cfg.Disabled || keyName != SourceSecret {
+		return s.Store.Resolve(ctx, keyName, keyValue)
+	}
+
+	ttl, err := cfg.GetTTL()
+	if err != nil {
+		return "", err
+	}
+
+	key := cacheKey{keyName: keyName, keyValue: keyValue}
+	now := s.now()
+	s.mu.Lock()
+	entry, ok := s.entries[key]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.value, nil
+	}
+
+	value, err := s.Store.Resolve(ctx, keyName, keyValue)
+	if err != nil {
+		// Do not cache failures, the secret may be created or become accessible
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evict(now, cfg.GetMaxEntries()-1)
+	s.entries[key] = cacheEntry{value: value, expires: now.Add(ttl)}
+	return value, nil
+}
+
+// evict removes expired entries, and then the entries closest to expiring,
+// until at most max entries remain. The caller must hold the lock.
+func (s *CachingStore) evict(now time.Time, max int) {
+	for key, entry := range s.entries {
+		if !now.Before(entry.expires) {
+			delete(s.entries, key)
+		}
+	}
+
+	for len(s.entries) > max && len(s.entries) > 0 {
+		var oldest cacheKey
+		var oldestExpires time.Time
+		for key, entry := range s.entries {
+			if oldestExpires.IsZero() || entry.expires.Before(oldestExpires) {
+				oldest = key
+				oldestExpires = entry.expires
+			}
+		}
+		delete(s.entries, oldest)
+	}
+}
+
+// forget removes a secret from the cache after it is changed.
+func (s *CachingStore) forget(keyName string, keyValue string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, cacheKey{keyName: keyName, keyValue: keyValue})
+}
+
+func (s *CachingStore) Create(ctx context.Context, keyName string, keyValue string, value string) error {
+	s.forget(keyName, keyValue)
+	return s.Store.Create(ctx, keyName, keyValue, value)
+}
+
+func (s *CachingStore) Delete(ctx context.Context, keyName string, keyValue string) error {
+	s.forget(keyName, keyValue)
+	return s.Store.Delete(ctx, keyName, keyValue)
+}
+
+// CreateMultiple stores the secrets with the wrapped store, in a single call
+// when it supports it.
+func (s *CachingStore) CreateMultiple(ctx context.Context, secrets []Secret) error {
+	for _, secret := range secrets {
+		s.forget(secret.KeyName, secret.KeyValue)
+	}
+	return CreateMultiple(ctx, s.Store, secrets)
+}
+
+// IssueCredentials mints a credential with the wrapped store. Issued
+// credentials are scoped to a single run and are never cached.
+func (s *CachingStore) IssueCredentials(ctx context.Context, keyValue string, runID string) (IssuedCredential, error) {
+	return IssueCredentials(ctx, s.Store, keyValue, runID)
+}
+
+// RevokeCredentials revokes a credential with the wrapped store.
+func (s *CachingStore) RevokeCredentials(ctx context.Context, leaseID string) error {
+	return RevokeCredentials(ctx, s.Store, leaseID)
+}
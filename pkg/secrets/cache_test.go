@@ -0,0 +1,173 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStore is a Store that counts the number of times each secret is resolved.
+type countingStore struct {
+	values   map[string]string
+	resolves map[string]int
+}
+
+func newCountingStore() *countingStore {
+	return &countingStore{values: map[string]string{}, resolves: map[string]int{}}
+}
+
+func (s *countingStore) Close() error {
+	return nil
+}
+
+func (s *countingStore) Resolve(ctx context.Context, keyName string, keyValue string) (string, error) {
+	s.resolves[keyName+":"+keyValue]++
+	value, ok := s.values[keyValue]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *countingStore) Create(ctx context.Context, keyName string, keyValue string, value string) error {
+	s.values[keyValue] = value
+	return nil
+}
+
+func (s *countingStore) Delete(ctx context.Context, keyName string, keyValue string) error {
+	delete(s.values, keyValue)
+	return nil
+}
+
+func TestCachingStore_Resolve(t *testing.T) {
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	inner := newCountingStore()
+	inner.values["password"] = "topsecret"
+	store := NewCachingStore(c.Config, inner)
+
+	for i := 0; i < 3; i++ {
+		value, err := store.Resolve(ctx, SourceSecret, "password")
+		require.NoError(t, err)
+		assert.Equal(t, "topsecret", value)
+	}
+	assert.Equal(t, 1, inner.resolves["secret:password"], "the secret should only be resolved once")
+
+	t.Run("other sources are not cached", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, err := store.Resolve(ctx, "env", "password")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, inner.resolves["env:password"])
+	})
+
+	t.Run("failures are not cached", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, err := store.Resolve(ctx, SourceSecret, "missing")
+			require.True(t, errors.Is(err, ErrNotFound))
+		}
+		assert.Equal(t, 2, inner.resolves["secret:missing"])
+	})
+}
+
+func TestCachingStore_Expires(t *testing.T) {
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	c.Data.SecretsCache.TTL = "30s"
+	inner := newCountingStore()
+	inner.values["password"] = "topsecret"
+	store := NewCachingStore(c.Config, inner)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	_, err := store.Resolve(ctx, SourceSecret, "password")
+	require.NoError(t, err)
+
+	now = now.Add(29 * time.Second)
+	_, err = store.Resolve(ctx, SourceSecret, "password")
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.resolves["secret:password"], "the cached value should be used before the ttl expires")
+
+	now = now.Add(time.Second)
+	_, err = store.Resolve(ctx, SourceSecret, "password")
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.resolves["secret:password"], "the secret should be resolved again after the ttl expires")
+}
+
+func TestCachingStore_MaxEntries(t *testing.T) {
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	c.Data.SecretsCache.MaxEntries = 2
+	inner := newCountingStore()
+	inner.values["a"] = "1"
+	inner.values["b"] = "2"
+	inner.values["c"] = "3"
+	store := NewCachingStore(c.Config, inner)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	for _, key := range []string{"a", "b", "c"} {
+		now = now.Add(time.Second)
+		_, err := store.Resolve(ctx, SourceSecret, key)
+		require.NoError(t, err)
+	}
+	assert.Len(t, store.entries, 2)
+
+	for _, key := range []string{"b", "c", "a"} {
+		_, err := store.Resolve(ctx, SourceSecret, key)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, inner.resolves["secret:a"], "the oldest entry should have been evicted")
+	assert.Equal(t, 1, inner.resolves["secret:c"], "the newest entry should still be cached")
+}
+
+func TestCachingStore_Invalidate(t *testing.T) {
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	inner := newCountingStore()
+	store := NewCachingStore(c.Config, inner)
+
+	require.NoError(t, store.Create(ctx, SourceSecret, "password", "v1"))
+	value, err := store.Resolve(ctx, SourceSecret, "password")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+
+	require.NoError(t, store.Create(ctx, SourceSecret, "password", "v2"))
+	value, err = store.Resolve(ctx, SourceSecret, "password")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", value, "creating a secret should remove it from the cache")
+
+	require.NoError(t, store.Delete(ctx, SourceSecret, "password"))
+	_, err = store.Resolve(ctx, SourceSecret, "password")
+	assert.True(t, errors.Is(err, ErrNotFound), "deleting a secret should remove it from the cache")
+}
+
+func TestCachingStore_Disabled(t *testing.T) {
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	c.Data.SecretsCache.Disabled = true
+	inner := newCountingStore()
+	inner.values["password"] = "topsecret"
+	store := NewCachingStore(c.Config, inner)
+
+	for i := 0; i < 2; i++ {
+		_, err := store.Resolve(ctx, SourceSecret, "password")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, inner.resolves["secret:password"])
+}
+
+func TestCachingStore_InvalidTTL(t *testing.T) {
+	c := config.NewTestConfig(t)
+	c.Data.SecretsCache.TTL = "soon"
+	store := NewCachingStore(c.Config, newCountingStore())
+
+	_, err := store.Resolve(context.Background(), SourceSecret, "password")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid secrets-cache.ttl")
+}
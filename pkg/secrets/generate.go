@@ -0,0 +1,197 @@
+package secrets
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// GeneratePassword generates a random password.
+	GeneratePassword = "password"
+
+	// GenerateRSA generates an RSA key pair, encoded as PEM.
+	GenerateRSA = "rsa"
+
+	// GenerateSSH generates an ed25519 SSH key pair, with the private key in
+	// the OpenSSH format and the public key in the authorized_keys format.
+	GenerateSSH = "ssh"
+
+	// DefaultPasswordLength is the default number of characters in a generated password.
+	DefaultPasswordLength = 32
+
+	// DefaultRSABits is the default size of a generated RSA key.
+	DefaultRSABits = 4096
+)
+
+// passwordCharacters are the characters used in a generated password. Symbols
+// that are often interpreted by a shell or in a URL are excluded.
+const passwordCharacters = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_.~"
+
+// GeneratedSecret is a secret value generated by Porter.
+type GeneratedSecret struct {
+	// Value is the secret value, such as a password or private key.
+	Value string
+
+	// PublicKey is the public key that corresponds to a generated private key,
+	// and is empty for a password.
+	PublicKey string
+}
+
+// GenerateOptions control how a secret value is generated.
+type GenerateOptions struct {
+	// Type of the secret to generate. Allowed values are: password, rsa, ssh.
+	Type string
+
+	// Length is the number of characters in a generated password.
+	Length int
+
+	// Bits is the size of a generated RSA key.
+	Bits int
+}
+
+// Generate creates a new random secret value.
+func Generate(opts GenerateOptions) (GeneratedSecret, error) {
+	switch opts.Type {
+	case GeneratePassword:
+		length := opts.Length
+		if length == 0 {
+			length = DefaultPasswordLength
+		}
+		return generatePassword(length)
+	case GenerateRSA:
+		bits := opts.Bits
+		if bits == 0 {
+			bits = DefaultRSABits
+		}
+		return generateRSA(bits)
+	case GenerateSSH:
+		return generateSSH()
+	default:
+		return GeneratedSecret{}, fmt.Errorf("invalid secret type %q, allowed values are: %s, %s, %s", opts.Type, GeneratePassword, GenerateRSA, GenerateSSH)
+	}
+}
+
+func generatePassword(length int) (GeneratedSecret, error) {
+	if length < 8 {
+		return GeneratedSecret{}, fmt.Errorf("invalid password length %d, it must be at least 8", length)
+	}
+
+	max := big.NewInt(int64(len(passwordCharacters)))
+	password := make([]byte, length)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return GeneratedSecret{}, fmt.Errorf("error generating a password: %w", err)
+		}
+		password[i] = passwordCharacters[n.Int64()]
+	}
+	return GeneratedSecret{Value: string(password)}, nil
+}
+
+func generateRSA(bits int) (GeneratedSecret, error) {
+	if bits < 2048 {
+		return GeneratedSecret{}, fmt.Errorf("invalid RSA key size %d, it must be at least 2048 bits", bits)
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return GeneratedSecret{}, fmt.Errorf("error generating an RSA key: %w", err)
+	}
+
+	privateKey, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return GeneratedSecret{}, fmt.Errorf("error encoding the RSA private key: %w", err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return GeneratedSecret{}, fmt.Errorf("error encoding the RSA public key: %w", err)
+	}
+
+	return GeneratedSecret{
+		Value:     string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKey})),
+		PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})),
+	}, nil
+}
+
+func generateSSH() (GeneratedSecret, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return GeneratedSecret{}, fmt.Errorf("error generating an SSH key: %w", err)
+	}
+
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return GeneratedSecret{}, fmt.Errorf("error encoding the SSH public key: %w", err)
+	}
+
+	encodedKey, err := marshalOpenSSHPrivateKey(sshPublicKey, privateKey)
+	if err != nil {
+		return GeneratedSecret{}, err
+	}
+
+	return GeneratedSecret{
+		Value:     string(encodedKey),
+		PublicKey: string(ssh.MarshalAuthorizedKey(sshPublicKey)),
+	}, nil
+}
+
+// marshalOpenSSHPrivateKey encodes an unencrypted ed25519 private key in the
+// OpenSSH format, which is what ssh-keygen generates and ssh requires.
+// See https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.key
+func marshalOpenSSHPrivateKey(publicKey ssh.PublicKey, privateKey ed25519.PrivateKey) ([]byte, error) {
+	// The check value is repeated to detect when a key is decrypted incorrectly
+	var check [4]byte
+	if _, err := rand.Read(check[:]); err != nil {
+		return nil, fmt.Errorf("error encoding the SSH private key: %w", err)
+	}
+	checkValue := binary.BigEndian.Uint32(check[:])
+
+	keyBlock := struct {
+		Check1  uint32
+		Check2  uint32
+		Keytype string
+		Pub     []byte
+		Priv    []byte
+		Comment string
+		Pad     []byte `ssh:"rest"`
+	}{
+		Check1:  checkValue,
+		Check2:  checkValue,
+		Keytype: ssh.KeyAlgoED25519,
+		Pub:     publicKey.(ssh.CryptoPublicKey).CryptoPublicKey().(ed25519.PublicKey),
+		Priv:    privateKey,
+	}
+
+	// Pad the key block to the block size of the cipher, 8 when unencrypted
+	unpadded := len(ssh.Marshal(keyBlock))
+	for i := 0; unpadded%8 != 0; i++ {
+		keyBlock.Pad = append(keyBlock.Pad, byte(i+1))
+		unpadded++
+	}
+
+	key := struct {
+		CipherName   string
+		KdfName      string
+		KdfOpts      string
+		NumKeys      uint32
+		PubKey       []byte
+		PrivKeyBlock []byte
+	}{
+		CipherName:   "none",
+		KdfName:      "none",
+		NumKeys:      1,
+		PubKey:       publicKey.Marshal(),
+		PrivKeyBlock: ssh.Marshal(keyBlock),
+	}
+
+	data := append([]byte("openssh-key-v1\x00"), ssh.Marshal(key)...)
+	return pem.EncodeToMemory(&pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: data}), nil
+}
@@ -21,3 +21,8 @@ func NewTestSecretsProvider() TestSecretsProvider {
 func (s TestSecretsProvider) Close() error {
 	return nil
 }
+
+// InMemory returns the in-memory plugin that stores the test secrets.
+func (s TestSecretsProvider) InMemory() *inmemory.Store {
+	return s.secrets
+}
@@ -0,0 +1,141 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/cloud/sigv4"
+	"get.porter.sh/porter/pkg/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+var _ Decryptor = &AWSDecryptor{}
+
+// AWSDecryptor decrypts values with AWS KMS. The key is optional because the
+// ciphertext identifies the key that encrypted it. When the key is an ARN,
+// the request is sent to the region of the key.
+type AWSDecryptor struct {
+	// Region of AWS KMS used when the key is not an ARN.
+	Region string
+
+	// Credentials used to sign requests.
+	Credentials aws.CredentialsProvider
+
+	// Endpoint overrides the URL of AWS KMS, for example to use a local emulator.
+	Endpoint string
+
+	client *http.Client
+
+	// now returns the time used to sign requests.
+	now func() time.Time
+}
+
+// NewAWSDecryptor creates a decryptor for AWS KMS that resolves its
+// credentials with the default credential chain of the AWS SDK: environment
+// variables, the shared configuration and credentials files, web identity, and
+// the role of the container or instance. The region defaults to AWS_REGION, or
+// the region in the shared configuration.
+func NewAWSDecryptor(c *config.Config) (Decryptor, error) {
+	region := c.Getenv("AWS_REGION")
+	if region == "" {
+		region = c.Getenv("AWS_DEFAULT_REGION")
+	}
+
+	var opts []func(*awsconfig.LoadOptions) error
+	if region != "" {
+		opts = append(opts, awsconfig.WithRegion(region))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not load the AWS configuration: %w", err)
+	}
+	if awsCfg.Credentials == nil {
+		return nil, errors.New("no AWS credentials were found, configure them with the AWS SDK credential chain")
+	}
+
+	return &AWSDecryptor{
+		Region:      awsCfg.Region,
+		Credentials: awsCfg.Credentials,
+		Endpoint:    c.Getenv("AWS_ENDPOINT_URL_KMS"),
+		client:      &http.Client{Timeout: requestTimeout},
+		now:         time.Now,
+	}, nil
+}
+
+type awsDecryptRequest struct {
+	CiphertextBlob []byte `json:"CiphertextBlob"`
+	KeyId          string `json:"KeyId,omitempty"`
+}
+
+type awsDecryptResponse struct {
+	Plaintext []byte `json:"Plaintext"`
+}
+
+// Decrypt the ciphertext with the AWS KMS Decrypt API.
+// See https://docs.aws.amazon.com/kms/latest/APIReference/API_Decrypt.html
+func (d *AWSDecryptor) Decrypt(ctx context.Context, key string, ciphertext []byte) ([]byte, error) {
+	region := d.Region
+	if arnRegion := awsRegionFromARN(key); arnRegion != "" {
+		region = arnRegion
+	}
+	if region == "" {
+		return nil, errors.New("the region of the key is unknown, use the ARN of the key or set AWS_REGION")
+	}
+
+	endpoint := d.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com/", region)
+	}
+
+	awsCreds, err := d.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve the AWS credentials: %w", err)
+	}
+	creds := sigv4.Credentials{
+		AccessKeyID:     awsCreds.AccessKeyID,
+		SecretAccessKey: awsCreds.SecretAccessKey,
+		SessionToken:    awsCreds.SessionToken,
+	}
+
+	body, err := json.Marshal(awsDecryptRequest{CiphertextBlob: ciphertext, KeyId: key})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService.Decrypt")
+	sigv4.SignRequest(req, body, creds, region, "kms", d.now())
+
+	respBody, err := doJSON(d.client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp awsDecryptResponse
+	if err = json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("error parsing the response from AWS KMS: %w", err)
+	}
+	return resp.Plaintext, nil
+}
+
+// awsRegionFromARN returns the region of a key ARN, formatted as
+// arn:PARTITION:kms:REGION:ACCOUNT:key/ID, or an empty string when the key is
+// not an ARN.
+func awsRegionFromARN(key string) string {
+	parts := strings.Split(key, ":")
+	if len(parts) < 6 || parts[0] != "arn" || parts[2] != "kms" {
+		return ""
+	}
+	return parts[3]
+}
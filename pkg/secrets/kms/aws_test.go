@@ -0,0 +1,91 @@
+package kms
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSDecryptor_Decrypt(t *testing.T) {
+	t.Parallel()
+
+	var gotRequest awsDecryptRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=mykey/") ||
+			!strings.Contains(r.Header.Get("Authorization"), "/us-west-2/kms/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("X-Amz-Target") != "TrentService.Decrypt" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&gotRequest); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(awsDecryptResponse{Plaintext: []byte("secret")})
+	}))
+	defer server.Close()
+
+	d := &AWSDecryptor{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("mykey", "mysecret", ""),
+		Endpoint:    server.URL,
+		client:      server.Client(),
+		now:         time.Now,
+	}
+
+	key := "arn:aws:kms:us-west-2:111122223333:key/1234"
+	plaintext, err := d.Decrypt(context.Background(), key, []byte("encrypted"))
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+	assert.Equal(t, []byte("encrypted"), gotRequest.CiphertextBlob)
+	assert.Equal(t, key, gotRequest.KeyId)
+
+	t.Run("request failed", func(t *testing.T) {
+		_, err := d.Decrypt(context.Background(), "", []byte("encrypted"))
+		require.ErrorContains(t, err, "403 Forbidden", "the request should be signed for the region of the decryptor when the key is not an ARN")
+	})
+}
+
+func TestNewAWSDecryptor(t *testing.T) {
+	// Do not run in parallel, the AWS SDK reads the environment of the process
+	awsDir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(awsDir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(awsDir, "credentials"))
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "mykey")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "mysecret")
+
+	c := config.NewTestConfig(t)
+	c.Setenv("AWS_REGION", "")
+	c.Setenv("AWS_DEFAULT_REGION", "eu-west-1")
+	d, err := NewAWSDecryptor(c.Config)
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", d.(*AWSDecryptor).Region)
+
+	creds, err := d.(*AWSDecryptor).Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "mykey", creds.AccessKeyID, "the credentials should be resolved with the AWS SDK credential chain")
+}
+
+func TestAWSDecryptor_MissingRegion(t *testing.T) {
+	t.Parallel()
+
+	d := &AWSDecryptor{}
+	_, err := d.Decrypt(context.Background(), "alias/mykey", []byte("encrypted"))
+	require.EqualError(t, err, "the region of the key is unknown, use the ARN of the key or set AWS_REGION")
+}
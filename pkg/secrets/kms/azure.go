@@ -0,0 +1,118 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"get.porter.sh/porter/pkg/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+var _ Decryptor = &AzureDecryptor{}
+
+const (
+	// azureKeyVaultAPIVersion is the version of the Key Vault REST API.
+	azureKeyVaultAPIVersion = "7.4"
+
+	// azureKeyVaultScope is the OAuth scope required to use Key Vault.
+	azureKeyVaultScope = "https://vault.azure.net/.default"
+
+	// DefaultAzureAlgorithm is the encryption algorithm used to decrypt a
+	// value with an Azure Key Vault key, when the key does not specify one.
+	DefaultAzureAlgorithm = "RSA-OAEP-256"
+)
+
+// AzureDecryptor decrypts values with a key in Azure Key Vault. The key is the
+// key identifier, https://VAULT.vault.azure.net/keys/NAME/VERSION, with an
+// optional alg query parameter that specifies the encryption algorithm, such as
+// https://VAULT.vault.azure.net/keys/NAME/VERSION?alg=RSA-OAEP.
+type AzureDecryptor struct {
+	// credential provides the access token added to requests.
+	credential azcore.TokenCredential
+
+	client *http.Client
+}
+
+// NewAzureDecryptor creates a decryptor for Azure Key Vault that authenticates
+// with the default credential chain of the Azure SDK: the service principal
+// or workload identity in the AZURE_* environment variables, a managed
+// identity, or the Azure CLI.
+func NewAzureDecryptor(c *config.Config) (Decryptor, error) {
+	credential, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not configure the Azure SDK credential chain: %w", err)
+	}
+	return &AzureDecryptor{
+		credential: credential,
+		client:     &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+type azureDecryptRequest struct {
+	Algorithm string `json:"alg"`
+	Value     string `json:"value"`
+}
+
+type azureDecryptResponse struct {
+	Value string `json:"value"`
+}
+
+// Decrypt the ciphertext with the Azure Key Vault decrypt operation.
+// See https://learn.microsoft.com/rest/api/keyvault/keys/decrypt/decrypt
+func (d *AzureDecryptor) Decrypt(ctx context.Context, key string, ciphertext []byte) ([]byte, error) {
+	keyURL, err := url.Parse(key)
+	if err != nil || keyURL.Host == "" || !strings.HasPrefix(keyURL.Path, "/keys/") {
+		return nil, errors.New("the key must be the key identifier, formatted as https://VAULT.vault.azure.net/keys/NAME/VERSION")
+	}
+
+	algorithm := keyURL.Query().Get("alg")
+	if algorithm == "" {
+		algorithm = DefaultAzureAlgorithm
+	}
+
+	body, err := json.Marshal(azureDecryptRequest{
+		Algorithm: algorithm,
+		Value:     base64.RawURLEncoding.EncodeToString(ciphertext),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keyURL.Path = strings.TrimSuffix(keyURL.Path, "/") + "/decrypt"
+	keyURL.RawQuery = url.Values{"api-version": []string{azureKeyVaultAPIVersion}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, keyURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	token, err := d.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{azureKeyVaultScope}})
+	if err != nil {
+		return nil, fmt.Errorf("could not get an access token for Azure Key Vault: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+
+	respBody, err := doJSON(d.client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp azureDecryptResponse
+	if err = json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("error parsing the response from Azure Key Vault: %w", err)
+	}
+	plaintext, err := decodeBase64(resp.Value)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding the decrypted value from Azure Key Vault: %w", err)
+	}
+	return plaintext, nil
+}
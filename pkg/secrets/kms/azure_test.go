@@ -0,0 +1,70 @@
+package kms
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticTokenCredential returns the same access token for every request.
+type staticTokenCredential string
+
+func (c staticTokenCredential) GetToken(ctx context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	if len(opts.Scopes) != 1 || opts.Scopes[0] != azureKeyVaultScope {
+		return azcore.AccessToken{}, fmt.Errorf("unexpected scopes %v", opts.Scopes)
+	}
+	return azcore.AccessToken{Token: string(c), ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestAzureDecryptor_Decrypt(t *testing.T) {
+	t.Parallel()
+
+	var gotRequest azureDecryptRequest
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/keys/mykey/abc/decrypt" || r.URL.Query().Get("api-version") != azureKeyVaultAPIVersion {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer mytoken" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&gotRequest); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(azureDecryptResponse{Value: base64.RawURLEncoding.EncodeToString([]byte("secret"))})
+	}))
+	defer server.Close()
+
+	d := &AzureDecryptor{credential: staticTokenCredential("mytoken"), client: server.Client()}
+
+	t.Run("default algorithm", func(t *testing.T) {
+		plaintext, err := d.Decrypt(context.Background(), server.URL+"/keys/mykey/abc", []byte("encrypted"))
+		require.NoError(t, err)
+		assert.Equal(t, "secret", string(plaintext))
+		assert.Equal(t, DefaultAzureAlgorithm, gotRequest.Algorithm)
+		assert.Equal(t, base64.RawURLEncoding.EncodeToString([]byte("encrypted")), gotRequest.Value)
+	})
+
+	t.Run("algorithm specified", func(t *testing.T) {
+		_, err := d.Decrypt(context.Background(), server.URL+"/keys/mykey/abc?alg=RSA-OAEP", []byte("encrypted"))
+		require.NoError(t, err)
+		assert.Equal(t, "RSA-OAEP", gotRequest.Algorithm)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		_, err := d.Decrypt(context.Background(), "mykey", []byte("encrypted"))
+		require.ErrorContains(t, err, "the key must be the key identifier")
+	})
+}
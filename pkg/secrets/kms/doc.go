@@ -0,0 +1,5 @@
+// Package kms decrypts the values of the kms secret source, which are
+// encrypted with a key managed by AWS KMS, Google Cloud KMS or Azure Key Vault.
+// Decryptors for other key management services may be registered with
+// RegisterDecryptor.
+package kms
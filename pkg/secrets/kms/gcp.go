@@ -0,0 +1,88 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"get.porter.sh/porter/pkg/config"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+var _ Decryptor = &GCPDecryptor{}
+
+// gcpKMSScope is the OAuth scope required to decrypt with Google Cloud KMS.
+const gcpKMSScope = "https://www.googleapis.com/auth/cloudkms"
+
+// GCPDecryptor decrypts values with Google Cloud KMS. The key is the resource
+// name of the key, formatted as
+// projects/PROJECT/locations/LOCATION/keyRings/KEYRING/cryptoKeys/KEY.
+type GCPDecryptor struct {
+	// Endpoint overrides the URL of Google Cloud KMS.
+	Endpoint string
+
+	// client adds the access token of the credentials to requests.
+	client *http.Client
+}
+
+// NewGCPDecryptor creates a decryptor for Google Cloud KMS that authenticates
+// with the Application Default Credentials, such as the service account key
+// file in GOOGLE_APPLICATION_CREDENTIALS.
+func NewGCPDecryptor(c *config.Config) (Decryptor, error) {
+	ctx := context.Background()
+	tokens, err := google.DefaultTokenSource(ctx, gcpKMSScope)
+	if err != nil {
+		return nil, fmt.Errorf("could not find the Google Cloud application default credentials: %w", err)
+	}
+
+	client := oauth2.NewClient(ctx, tokens)
+	client.Timeout = requestTimeout
+	return &GCPDecryptor{
+		Endpoint: "https://cloudkms.googleapis.com",
+		client:   client,
+	}, nil
+}
+
+type gcpDecryptRequest struct {
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type gcpDecryptResponse struct {
+	Plaintext []byte `json:"plaintext"`
+}
+
+// Decrypt the ciphertext with the Google Cloud KMS decrypt API.
+// See https://cloud.google.com/kms/docs/reference/rest/v1/projects.locations.keyRings.cryptoKeys/decrypt
+func (d *GCPDecryptor) Decrypt(ctx context.Context, key string, ciphertext []byte) ([]byte, error) {
+	if !strings.HasPrefix(key, "projects/") || !strings.Contains(key, "/cryptoKeys/") {
+		return nil, errors.New("the key must be the resource name of the key, formatted as projects/PROJECT/locations/LOCATION/keyRings/KEYRING/cryptoKeys/KEY")
+	}
+
+	body, err := json.Marshal(gcpDecryptRequest{Ciphertext: ciphertext})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/%s:decrypt", strings.TrimSuffix(d.Endpoint, "/"), key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	respBody, err := doJSON(d.client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp gcpDecryptResponse
+	if err = json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("error parsing the response from Google Cloud KMS: %w", err)
+	}
+	return resp.Plaintext, nil
+}
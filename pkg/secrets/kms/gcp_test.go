@@ -0,0 +1,42 @@
+package kms
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCPDecryptor_Decrypt(t *testing.T) {
+	t.Parallel()
+
+	key := "projects/myproject/locations/global/keyRings/myring/cryptoKeys/mykey"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/"+key+":decrypt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req gcpDecryptRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || string(req.Ciphertext) != "encrypted" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(gcpDecryptResponse{Plaintext: []byte("secret")})
+	}))
+	defer server.Close()
+
+	d := &GCPDecryptor{Endpoint: server.URL, client: server.Client()}
+
+	plaintext, err := d.Decrypt(context.Background(), key, []byte("encrypted"))
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+
+	t.Run("invalid key", func(t *testing.T) {
+		_, err := d.Decrypt(context.Background(), "mykey", []byte("encrypted"))
+		require.ErrorContains(t, err, "the key must be the resource name of the key")
+	})
+}
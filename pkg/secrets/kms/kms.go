@@ -0,0 +1,202 @@
+package kms
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+)
+
+const (
+	// ProviderAWS decrypts values with AWS KMS.
+	ProviderAWS = "aws"
+
+	// ProviderGCP decrypts values with Google Cloud KMS.
+	ProviderGCP = "gcp"
+
+	// ProviderAzure decrypts values with Azure Key Vault.
+	ProviderAzure = "azure"
+)
+
+// requestTimeout limits how long to wait for a key management service to
+// decrypt a value.
+const requestTimeout = 30 * time.Second
+
+// ErrUnknownProvider is returned when no decryptor is registered for the
+// provider of an encrypted value.
+var ErrUnknownProvider = errors.New("no decryptor is registered for the kms provider")
+
+// Decryptor decrypts values with a key managed by a key management service.
+type Decryptor interface {
+	// Decrypt the ciphertext with the key. The format of the key depends on
+	// the provider, for example the resource name of a Google Cloud KMS key.
+	Decrypt(ctx context.Context, key string, ciphertext []byte) ([]byte, error)
+}
+
+// DecryptorFactory creates a Decryptor, reading its settings, such as
+// credentials, from Porter's environment.
+type DecryptorFactory func(c *config.Config) (Decryptor, error)
+
+var (
+	decryptorsMu sync.RWMutex
+	decryptors   = map[string]DecryptorFactory{
+		ProviderAWS:   NewAWSDecryptor,
+		ProviderGCP:   NewGCPDecryptor,
+		ProviderAzure: NewAzureDecryptor,
+	}
+)
+
+// RegisterDecryptor makes a decryptor available for the provider, replacing
+// any decryptor already registered for it.
+func RegisterDecryptor(provider string, factory DecryptorFactory) {
+	decryptorsMu.Lock()
+	defer decryptorsMu.Unlock()
+	decryptors[provider] = factory
+}
+
+func lookupDecryptor(provider string) (DecryptorFactory, bool) {
+	decryptorsMu.RLock()
+	defer decryptorsMu.RUnlock()
+	factory, ok := decryptors[provider]
+	return factory, ok
+}
+
+// Ciphertext is a value that was encrypted with a key managed by a key
+// management service, formatted as PROVIDER:KEY:CIPHERTEXT.
+type Ciphertext struct {
+	// Provider of the key management service, for example aws.
+	Provider string
+
+	// Key that encrypted the value. It may be empty when the provider
+	// identifies the key from the ciphertext, such as with AWS KMS.
+	Key string
+
+	// Data is the encrypted value.
+	Data []byte
+}
+
+// ParseCiphertext parses a value of the kms secret source, formatted as
+// PROVIDER:KEY:CIPHERTEXT where CIPHERTEXT is base64 encoded. The key may
+// contain colons, such as an AWS ARN or an Azure Key Vault URL.
+func ParseCiphertext(value string) (Ciphertext, error) {
+	first := strings.Index(value, ":")
+	last := strings.LastIndex(value, ":")
+	if first <= 0 || first == last {
+		return Ciphertext{}, errors.New("invalid kms value, expected PROVIDER:KEY:CIPHERTEXT")
+	}
+
+	c := Ciphertext{
+		Provider: value[:first],
+		Key:      value[first+1 : last],
+	}
+
+	// Encrypted values are often wrapped when pasted into a file
+	encoded := strings.Join(strings.Fields(value[last+1:]), "")
+	if encoded == "" {
+		return Ciphertext{}, errors.New("invalid kms value, the ciphertext is empty")
+	}
+	data, err := decodeBase64(encoded)
+	if err != nil {
+		return Ciphertext{}, fmt.Errorf("invalid kms value, the ciphertext is not base64 encoded: %w", err)
+	}
+	c.Data = data
+	return c, nil
+}
+
+// decodeBase64 decodes standard or URL safe base64, with or without padding.
+func decodeBase64(value string) ([]byte, error) {
+	value = strings.TrimRight(value, "=")
+	if strings.ContainsAny(value, "-_") {
+		return base64.RawURLEncoding.DecodeString(value)
+	}
+	return base64.RawStdEncoding.DecodeString(value)
+}
+
+// Decryptors decrypt the values of the kms secret source, creating the
+// decryptor for each provider once and reusing it, along with its credentials
+// and connections, for the following values.
+type Decryptors struct {
+	config *config.Config
+
+	mu         sync.Mutex
+	decryptors map[string]Decryptor
+}
+
+// NewDecryptors creates decryptors that read their settings from Porter's
+// environment.
+func NewDecryptors(c *config.Config) *Decryptors {
+	return &Decryptors{
+		config:     c,
+		decryptors: make(map[string]Decryptor),
+	}
+}
+
+// Decrypt the value of a kms secret source with the decryptor registered for
+// its provider. ErrUnknownProvider is returned when there is no decryptor for
+// the provider.
+func (d *Decryptors) Decrypt(ctx context.Context, value string) (string, error) {
+	ciphertext, err := ParseCiphertext(value)
+	if err != nil {
+		return "", err
+	}
+
+	decryptor, err := d.get(ciphertext.Provider)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := decryptor.Decrypt(ctx, ciphertext.Key, ciphertext.Data)
+	if err != nil {
+		return "", fmt.Errorf("error decrypting the value with %s kms: %w", ciphertext.Provider, err)
+	}
+	return string(plaintext), nil
+}
+
+// get returns the decryptor for the provider, creating it the first time the
+// provider is used.
+func (d *Decryptors) get(provider string) (Decryptor, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if decryptor, ok := d.decryptors[provider]; ok {
+		return decryptor, nil
+	}
+
+	factory, ok := lookupDecryptor(provider)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, provider)
+	}
+
+	decryptor, err := factory(d.config)
+	if err != nil {
+		return nil, fmt.Errorf("error configuring the %s kms decryptor: %w", provider, err)
+	}
+	d.decryptors[provider] = decryptor
+	return decryptor, nil
+}
+
+// doJSON sends the request to a key management service, returning the body
+// of the response, or an error when the response is not successful.
+func doJSON(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("error reading the response from %s: %w", req.URL.Host, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s: %s %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
@@ -0,0 +1,102 @@
+package kms
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"get.porter.sh/porter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCiphertext(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte("encrypted"))
+
+	testcases := []struct {
+		name    string
+		value   string
+		want    Ciphertext
+		wantErr string
+	}{
+		{name: "aws without a key", value: "aws::" + encoded,
+			want: Ciphertext{Provider: ProviderAWS, Data: []byte("encrypted")}},
+		{name: "aws arn", value: "aws:arn:aws:kms:us-west-2:111122223333:key/1234:" + encoded,
+			want: Ciphertext{Provider: ProviderAWS, Key: "arn:aws:kms:us-west-2:111122223333:key/1234", Data: []byte("encrypted")}},
+		{name: "azure url", value: "azure:https://myvault.vault.azure.net/keys/mykey/abc:" + base64.RawURLEncoding.EncodeToString([]byte("encrypted")),
+			want: Ciphertext{Provider: ProviderAzure, Key: "https://myvault.vault.azure.net/keys/mykey/abc", Data: []byte("encrypted")}},
+		{name: "wrapped ciphertext", value: "gcp:projects/p/locations/global/keyRings/r/cryptoKeys/k:" + encoded[:4] + "\n  " + encoded[4:],
+			want: Ciphertext{Provider: ProviderGCP, Key: "projects/p/locations/global/keyRings/r/cryptoKeys/k", Data: []byte("encrypted")}},
+		{name: "missing key", value: "aws" + encoded, wantErr: "invalid kms value, expected PROVIDER:KEY:CIPHERTEXT"},
+		{name: "missing provider", value: ":key:" + encoded, wantErr: "invalid kms value, expected PROVIDER:KEY:CIPHERTEXT"},
+		{name: "empty ciphertext", value: "aws:key:", wantErr: "invalid kms value, the ciphertext is empty"},
+		{name: "invalid ciphertext", value: "aws:key:not base64!", wantErr: "invalid kms value, the ciphertext is not base64 encoded"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseCiphertext(tc.value)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+type reverseDecryptor struct {
+	key string
+}
+
+func (d *reverseDecryptor) Decrypt(ctx context.Context, key string, ciphertext []byte) ([]byte, error) {
+	if key != d.key {
+		return nil, errors.New("wrong key")
+	}
+	plaintext := make([]byte, len(ciphertext))
+	for i, b := range ciphertext {
+		plaintext[len(ciphertext)-1-i] = b
+	}
+	return plaintext, nil
+}
+
+func TestDecryptors_Decrypt(t *testing.T) {
+	c := config.NewTestConfig(t)
+	var created int
+	RegisterDecryptor("test-reverse", func(c *config.Config) (Decryptor, error) {
+		created++
+		return &reverseDecryptor{key: "mykey"}, nil
+	})
+	RegisterDecryptor("test-broken", func(c *config.Config) (Decryptor, error) {
+		return nil, errors.New("missing credentials")
+	})
+	d := NewDecryptors(c.Config)
+
+	ciphertext := base64.StdEncoding.EncodeToString([]byte("terces"))
+
+	t.Run("registered provider", func(t *testing.T) {
+		value, err := d.Decrypt(context.Background(), "test-reverse:mykey:"+ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", value)
+	})
+
+	t.Run("decrypt failed", func(t *testing.T) {
+		_, err := d.Decrypt(context.Background(), "test-reverse:otherkey:"+ciphertext)
+		require.EqualError(t, err, "error decrypting the value with test-reverse kms: wrong key")
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		_, err := d.Decrypt(context.Background(), "vault-transit:mykey:"+ciphertext)
+		require.ErrorIs(t, err, ErrUnknownProvider)
+	})
+
+	t.Run("missing credentials", func(t *testing.T) {
+		_, err := d.Decrypt(context.Background(), "test-broken:mykey:"+ciphertext)
+		require.EqualError(t, err, "error configuring the test-broken kms decryptor: missing credentials")
+	})
+
+	assert.Equal(t, 1, created, "the decryptor should be created once and reused")
+}
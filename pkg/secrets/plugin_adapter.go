@@ -2,12 +2,14 @@ package secrets
 
 import (
 	"context"
+	"fmt"
 	"io"
 
 	"get.porter.sh/porter/pkg/secrets/plugins"
 )
 
-var _ Store = PluginAdapter{}
+var _ BulkStore = PluginAdapter{}
+var _ CredentialIssuer = PluginAdapter{}
 
 // PluginAdapter converts between the low-level plugins.SecretsProtocol and
 // the secrets.Store interface.
@@ -34,3 +36,31 @@ func (a PluginAdapter) Resolve(ctx context.Context, keyName string, keyValue str
 func (a PluginAdapter) Create(ctx context.Context, keyName string, keyValue string, value string) error {
 	return a.plugin.Create(ctx, keyName, keyValue, value)
 }
+
+func (a PluginAdapter) Delete(ctx context.Context, keyName string, keyValue string) error {
+	return a.plugin.Delete(ctx, keyName, keyValue)
+}
+
+// CreateMultiple stores the secrets in a single call when the plugin supports
+// it, otherwise each secret is created individually.
+func (a PluginAdapter) CreateMultiple(ctx context.Context, secrets []Secret) error {
+	return plugins.CreateMultiple(ctx, a.plugin, secrets)
+}
+
+// IssueCredentials mints a credential for the run when the plugin supports it.
+func (a PluginAdapter) IssueCredentials(ctx context.Context, keyValue string, runID string) (IssuedCredential, error) {
+	issuer, ok := a.plugin.(plugins.CredentialIssuerProtocol)
+	if !ok {
+		return IssuedCredential{}, fmt.Errorf("the secrets plugin does not support issuing credentials: %w", plugins.ErrNotImplemented)
+	}
+	return issuer.IssueCredentials(ctx, keyValue, runID)
+}
+
+// RevokeCredentials revokes a credential issued for a run when the plugin supports it.
+func (a PluginAdapter) RevokeCredentials(ctx context.Context, leaseID string) error {
+	issuer, ok := a.plugin.(plugins.CredentialIssuerProtocol)
+	if !ok {
+		return fmt.Errorf("the secrets plugin does not support revoking credentials: %w", plugins.ErrNotImplemented)
+	}
+	return issuer.RevokeCredentials(ctx, leaseID)
+}
@@ -97,7 +97,9 @@ func (s *Store) Resolve(ctx context.Context, keyName string, keyValue string) (s
 
 	path := filepath.Join(s.secretDir, keyValue)
 	data, err := s.config.FileSystem.ReadFile(path)
-	if err != nil {
+	if errors.Is(err, os.ErrNotExist) {
+		return "", log.Error(fmt.Errorf("error reading secret from filesystem: %s: %w", err, plugins.ErrNotFound))
+	} else if err != nil {
 		return "", log.Error(fmt.Errorf("error reading secret from filesystem: %w", err))
 	}
 
@@ -131,3 +133,25 @@ func (s *Store) Create(ctx context.Context, keyName string, keyValue string, val
 	}
 	return nil
 }
+
+// Delete implements the Delete method on the secret plugins' interface.
+func (s *Store) Delete(ctx context.Context, keyName string, keyValue string) error {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	if err := s.Connect(ctx); err != nil {
+		return err
+	}
+
+	// check if the keyName is secret
+	if keyName != secrets.SourceSecret {
+		return log.Error(errors.New("invalid key name: " + keyName))
+	}
+
+	path := filepath.Join(s.secretDir, keyValue)
+	err := s.config.FileSystem.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return log.Error(fmt.Errorf("error removing secret from filesystem: %w", err))
+	}
+	return nil
+}
@@ -65,3 +65,26 @@ func TestFileSystem_DataOperation(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, secretValue, data)
 }
+
+func TestFileSystem_Delete(t *testing.T) {
+	c := config.NewTestConfig(t)
+	defer c.Close()
+
+	testStore := filesystem.NewStore(c.Config)
+	defer testStore.Close()
+
+	ctx := context.Background()
+	secretKey := "porter-filesystem-plugin-test"
+	err := testStore.Create(ctx, secrets.SourceSecret, secretKey, "supersecret")
+	require.NoError(t, err)
+
+	err = testStore.Delete(ctx, secrets.SourceSecret, secretKey)
+	require.NoError(t, err)
+
+	_, err = testStore.Resolve(ctx, secrets.SourceSecret, secretKey)
+	require.ErrorContains(t, err, "error reading secret from filesystem")
+	require.ErrorIs(t, err, secrets.ErrNotFound)
+
+	err = testStore.Delete(ctx, secrets.SourceSecret, secretKey)
+	require.NoError(t, err, "deleting a missing secret should not be an error")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"get.porter.sh/porter/pkg/secrets"
 	secretsplugins "get.porter.sh/porter/pkg/secrets/plugins"
 	"github.com/cnabio/cnab-go/secrets/host"
 )
@@ -19,9 +20,16 @@ func NewStore() Store {
 }
 
 func (s Store) Resolve(ctx context.Context, keyName string, keyValue string) (string, error) {
+	if keyName == secrets.SourceSecret {
+		return "", fmt.Errorf("the default secrets plugin, %s, does not support resolving secrets from a secret store: %w", PluginKey, secretsplugins.ErrNotImplemented)
+	}
 	return s.store.Resolve(keyName, keyValue)
 }
 
 func (s Store) Create(ctx context.Context, keyName string, keyValue string, value string) error {
 	return fmt.Errorf("the default secrets plugin, %s, does not support persisting secrets: %w", PluginKey, secretsplugins.ErrNotImplemented)
 }
+
+func (s Store) Delete(ctx context.Context, keyName string, keyValue string) error {
+	return fmt.Errorf("the default secrets plugin, %s, does not support deleting secrets: %w", PluginKey, secretsplugins.ErrNotImplemented)
+}
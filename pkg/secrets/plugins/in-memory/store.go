@@ -2,22 +2,32 @@ package inmemory
 
 import (
 	"context"
-	"errors"
+	"fmt"
 
 	"get.porter.sh/porter/pkg/secrets/plugins"
 	"github.com/cnabio/cnab-go/secrets/host"
 )
 
 var _ plugins.SecretsProtocol = &Store{}
+var _ plugins.BulkSecretsProtocol = &Store{}
+var _ plugins.CredentialIssuerProtocol = &Store{}
 
 // Store implements an in-memory secrets store for testing.
 type Store struct {
 	Secrets map[string]map[string]string
+
+	// Leases are the credentials that have been issued and not yet revoked,
+	// keyed by the lease id.
+	Leases map[string]plugins.IssuedCredential
+
+	// Revoked are the lease ids of the credentials that have been revoked.
+	Revoked []string
 }
 
 func NewStore() *Store {
 	s := &Store{
 		Secrets: make(map[string]map[string]string),
+		Leases:  make(map[string]plugins.IssuedCredential),
 	}
 
 	return s
@@ -32,7 +42,7 @@ func (s *Store) Resolve(ctx context.Context, keyName string, keyValue string) (s
 	if keyName == "secret" {
 		value, ok := s.Secrets[keyName][keyValue]
 		if !ok {
-			return "", errors.New("secret not found")
+			return "", fmt.Errorf("secret %w", plugins.ErrNotFound)
 		}
 
 		return value, nil
@@ -51,3 +61,35 @@ func (s *Store) Create(ctx context.Context, keyName string, keyValue string, val
 	s.Secrets[keyName][keyValue] = value
 	return nil
 }
+
+func (s *Store) Delete(ctx context.Context, keyName string, keyValue string) error {
+	delete(s.Secrets[keyName], keyValue)
+	return nil
+}
+
+func (s *Store) CreateMultiple(ctx context.Context, secrets []plugins.Secret) error {
+	for _, secret := range secrets {
+		if err := s.Create(ctx, secret.KeyName, secret.KeyValue, secret.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) IssueCredentials(ctx context.Context, keyValue string, runID string) (plugins.IssuedCredential, error) {
+	cred := plugins.IssuedCredential{
+		Value:   fmt.Sprintf("%s-%s", keyValue, runID),
+		LeaseID: fmt.Sprintf("lease-%d", len(s.Leases)+len(s.Revoked)+1),
+	}
+	s.Leases[cred.LeaseID] = cred
+	return cred, nil
+}
+
+func (s *Store) RevokeCredentials(ctx context.Context, leaseID string) error {
+	if _, ok := s.Leases[leaseID]; !ok {
+		return nil
+	}
+	delete(s.Leases, leaseID)
+	s.Revoked = append(s.Revoked, leaseID)
+	return nil
+}
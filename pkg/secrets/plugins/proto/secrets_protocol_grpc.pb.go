@@ -25,6 +25,10 @@ const _ = grpc.SupportPackageIsVersion7
 type SecretsProtocolClient interface {
 	Resolve(ctx context.Context, in *ResolveRequest, opts ...grpc.CallOption) (*ResolveResponse, error)
 	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	IssueCredentials(ctx context.Context, in *IssueCredentialsRequest, opts ...grpc.CallOption) (*IssueCredentialsResponse, error)
+	RevokeCredentials(ctx context.Context, in *RevokeCredentialsRequest, opts ...grpc.CallOption) (*RevokeCredentialsResponse, error)
+	CreateMultiple(ctx context.Context, in *CreateMultipleRequest, opts ...grpc.CallOption) (*CreateMultipleResponse, error)
 }
 
 type secretsProtocolClient struct {
@@ -53,12 +57,52 @@ func (c *secretsProtocolClient) Create(ctx context.Context, in *CreateRequest, o
 	return out, nil
 }
 
+func (c *secretsProtocolClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, "/plugins.SecretsProtocol/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsProtocolClient) IssueCredentials(ctx context.Context, in *IssueCredentialsRequest, opts ...grpc.CallOption) (*IssueCredentialsResponse, error) {
+	out := new(IssueCredentialsResponse)
+	err := c.cc.Invoke(ctx, "/plugins.SecretsProtocol/IssueCredentials", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsProtocolClient) RevokeCredentials(ctx context.Context, in *RevokeCredentialsRequest, opts ...grpc.CallOption) (*RevokeCredentialsResponse, error) {
+	out := new(RevokeCredentialsResponse)
+	err := c.cc.Invoke(ctx, "/plugins.SecretsProtocol/RevokeCredentials", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsProtocolClient) CreateMultiple(ctx context.Context, in *CreateMultipleRequest, opts ...grpc.CallOption) (*CreateMultipleResponse, error) {
+	out := new(CreateMultipleResponse)
+	err := c.cc.Invoke(ctx, "/plugins.SecretsProtocol/CreateMultiple", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SecretsProtocolServer is the server API for SecretsProtocol service.
 // All implementations must embed UnimplementedSecretsProtocolServer
 // for forward compatibility
 type SecretsProtocolServer interface {
 	Resolve(context.Context, *ResolveRequest) (*ResolveResponse, error)
 	Create(context.Context, *CreateRequest) (*CreateResponse, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	IssueCredentials(context.Context, *IssueCredentialsRequest) (*IssueCredentialsResponse, error)
+	RevokeCredentials(context.Context, *RevokeCredentialsRequest) (*RevokeCredentialsResponse, error)
+	CreateMultiple(context.Context, *CreateMultipleRequest) (*CreateMultipleResponse, error)
 	mustEmbedUnimplementedSecretsProtocolServer()
 }
 
@@ -72,6 +116,18 @@ func (UnimplementedSecretsProtocolServer) Resolve(context.Context, *ResolveReque
 func (UnimplementedSecretsProtocolServer) Create(context.Context, *CreateRequest) (*CreateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
 }
+func (UnimplementedSecretsProtocolServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedSecretsProtocolServer) IssueCredentials(context.Context, *IssueCredentialsRequest) (*IssueCredentialsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IssueCredentials not implemented")
+}
+func (UnimplementedSecretsProtocolServer) RevokeCredentials(context.Context, *RevokeCredentialsRequest) (*RevokeCredentialsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeCredentials not implemented")
+}
+func (UnimplementedSecretsProtocolServer) CreateMultiple(context.Context, *CreateMultipleRequest) (*CreateMultipleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateMultiple not implemented")
+}
 func (UnimplementedSecretsProtocolServer) mustEmbedUnimplementedSecretsProtocolServer() {}
 
 // UnsafeSecretsProtocolServer may be embedded to opt out of forward compatibility for this service.
@@ -121,6 +177,78 @@ func _SecretsProtocol_Create_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SecretsProtocol_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsProtocolServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/plugins.SecretsProtocol/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsProtocolServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SecretsProtocol_IssueCredentials_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IssueCredentialsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsProtocolServer).IssueCredentials(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/plugins.SecretsProtocol/IssueCredentials",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsProtocolServer).IssueCredentials(ctx, req.(*IssueCredentialsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SecretsProtocol_RevokeCredentials_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeCredentialsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsProtocolServer).RevokeCredentials(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/plugins.SecretsProtocol/RevokeCredentials",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsProtocolServer).RevokeCredentials(ctx, req.(*RevokeCredentialsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SecretsProtocol_CreateMultiple_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateMultipleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsProtocolServer).CreateMultiple(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/plugins.SecretsProtocol/CreateMultiple",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsProtocolServer).CreateMultiple(ctx, req.(*CreateMultipleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SecretsProtocol_ServiceDesc is the grpc.ServiceDesc for SecretsProtocol service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -136,6 +264,22 @@ var SecretsProtocol_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Create",
 			Handler:    _SecretsProtocol_Create_Handler,
 		},
+		{
+			MethodName: "Delete",
+			Handler:    _SecretsProtocol_Delete_Handler,
+		},
+		{
+			MethodName: "IssueCredentials",
+			Handler:    _SecretsProtocol_IssueCredentials_Handler,
+		},
+		{
+			MethodName: "RevokeCredentials",
+			Handler:    _SecretsProtocol_RevokeCredentials_Handler,
+		},
+		{
+			MethodName: "CreateMultiple",
+			Handler:    _SecretsProtocol_CreateMultiple_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/secrets/plugins/proto/secrets_protocol.proto",
@@ -16,4 +16,13 @@ var (
 	// ErrNotImplemented is the error to be returned if a method is not implemented
 	// in a secret plugin
 	ErrNotImplemented = errors.New("not implemented")
+
+	// ErrNotFound is the error to be returned when a secret does not exist
+	// in the secret store.
+	ErrNotFound = errors.New("not found")
+
+	// ErrUnauthorized is the error to be returned when the secret store
+	// rejected the plugin's credentials, or they do not grant access to the
+	// secret.
+	ErrUnauthorized = errors.New("unauthorized")
 )
@@ -1,6 +1,9 @@
 package plugins
 
-import "context"
+import (
+	"context"
+	"errors"
+)
 
 // SecretsProtocol is the interface that secrets plugins must implement.
 // This defines the protocol used to communicate with secrets plugins.
@@ -24,4 +27,86 @@ type SecretsProtocol interface {
 	// - keyName=key, keyValue=conn-string, value=redis://foo
 	// - keyName=path, keyValue=/tmp/connstring.txt, value=redis://foo
 	Create(ctx context.Context, keyName string, keyValue string, value string) error
+
+	// Delete removes a secret value from a secret store.
+	// - keyName is name of the key where the secret can be found.
+	// - keyValue is the value of the key.
+	// Deleting a secret that does not exist is not an error. Plugins that do
+	// not support removing secrets return ErrNotImplemented.
+	// Examples:
+	// - keyName=secret, keyValue=conn-string
+	Delete(ctx context.Context, keyName string, keyValue string) error
+}
+
+// Secret is a secret value to store in a secret store.
+type Secret struct {
+	// KeyName is name of the key where the secret can be found.
+	KeyName string
+
+	// KeyValue is the value of the key.
+	KeyValue string
+
+	// Value of the secret.
+	Value string
+}
+
+// BulkSecretsProtocol is an optional interface that secrets plugins may
+// implement to store multiple secrets in a single call, reducing the number
+// of round trips to a remote secret store. Use CreateMultiple to save secrets
+// with a plugin that may not support it.
+type BulkSecretsProtocol interface {
+	// CreateMultiple stores multiple secret values in a secret store.
+	CreateMultiple(ctx context.Context, secrets []Secret) error
+}
+
+// CreateMultiple stores the secrets in a single call when the store implements
+// BulkSecretsProtocol, otherwise each secret is created individually. Secrets
+// are also created individually when the store returns ErrNotImplemented, for
+// example because the plugin was built before the protocol supported batches.
+func CreateMultiple(ctx context.Context, store SecretsProtocol, secrets []Secret) error {
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	if bulk, ok := store.(BulkSecretsProtocol); ok {
+		err := bulk.CreateMultiple(ctx, secrets)
+		if !errors.Is(err, ErrNotImplemented) {
+			return err
+		}
+	}
+
+	for _, secret := range secrets {
+		if err := store.Create(ctx, secret.KeyName, secret.KeyValue, secret.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CredentialIssuerProtocol is an optional interface that secrets plugins may
+// implement to mint short-lived credentials, such as a database user or a
+// cloud role, for the duration of a single run. Porter issues the credentials
+// when the run starts and revokes them when the run completes.
+type CredentialIssuerProtocol interface {
+	// IssueCredentials mints a credential scoped to a run.
+	// - keyValue identifies the kind of credential to issue, for example the
+	//   name of a database role.
+	// - runID is the id of the run that will use the credential.
+	// Examples:
+	// - keyValue=postgres/readonly, runID=01G1VJGY43HT3KZN82DS6DDPWK
+	IssueCredentials(ctx context.Context, keyValue string, runID string) (IssuedCredential, error)
+
+	// RevokeCredentials revokes a credential previously issued by
+	// IssueCredentials. Revoking a lease that has already expired is not an
+	// error.
+	RevokeCredentials(ctx context.Context, leaseID string) error
+}
+
+// IssuedCredential is a credential minted by a plugin for a single run.
+type IssuedCredential struct {
+	// Value of the credential.
+	Value string
+
+	// LeaseID identifies the credential when it is revoked.
+	LeaseID string
 }
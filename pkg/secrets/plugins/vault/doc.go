@@ -0,0 +1,4 @@
+// Package vault provides a plugin implementing the secret plugin protocol
+// for creating/resolving secrets in a HashiCorp Vault KV version 2 secrets
+// engine. The plugin authenticates with a token or with AppRole.
+package vault
@@ -0,0 +1,98 @@
+package vault
+
+import (
+	"fmt"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/secrets/plugins"
+	"get.porter.sh/porter/pkg/secrets/pluginstore"
+	"github.com/hashicorp/go-plugin"
+	"github.com/mitchellh/mapstructure"
+)
+
+const PluginKey = plugins.PluginInterface + ".porter.vault"
+
+const (
+	// AuthMethodToken authenticates to Vault with a token.
+	AuthMethodToken = "token"
+
+	// AuthMethodAppRole authenticates to Vault with an AppRole role id and secret id.
+	AuthMethodAppRole = "approle"
+)
+
+// PluginConfig are the configuration settings that can be defined for the
+// vault plugin in porter.yaml
+type PluginConfig struct {
+	// Address of the Vault server, defaults to the VAULT_ADDR environment variable.
+	Address string `mapstructure:"address"`
+
+	// Namespace is the Vault Enterprise namespace that contains the secrets engine.
+	Namespace string `mapstructure:"namespace,omitempty"`
+
+	// MountPath is where the KV version 2 secrets engine is mounted.
+	MountPath string `mapstructure:"mount-path,omitempty"`
+
+	// PathPrefix is prepended to the path of each secret in the secrets engine.
+	PathPrefix string `mapstructure:"path-prefix,omitempty"`
+
+	// AuthMethod used to authenticate to Vault: token or approle.
+	AuthMethod string `mapstructure:"auth-method,omitempty"`
+
+	// Token used with the token auth method, defaults to the VAULT_TOKEN environment variable.
+	Token string `mapstructure:"token,omitempty"`
+
+	// AppRoleMountPath is where the AppRole auth method is mounted.
+	AppRoleMountPath string `mapstructure:"approle-mount-path,omitempty"`
+
+	// RoleID used with the approle auth method.
+	RoleID string `mapstructure:"role-id,omitempty"`
+
+	// SecretID used with the approle auth method.
+	SecretID string `mapstructure:"secret-id,omitempty"`
+
+	// Timeout in seconds of each request made to Vault.
+	Timeout int `mapstructure:"timeout,omitempty"`
+}
+
+// NewPluginConfig returns the plugin configuration with the default values set.
+func NewPluginConfig() PluginConfig {
+	return PluginConfig{
+		MountPath:        "secret",
+		PathPrefix:       "porter",
+		AuthMethod:       AuthMethodToken,
+		AppRoleMountPath: "approle",
+		Timeout:          10,
+	}
+}
+
+// Validate the plugin configuration.
+func (cfg PluginConfig) Validate() error {
+	if cfg.Address == "" {
+		return fmt.Errorf("the vault address must be specified in the plugin configuration or with the VAULT_ADDR environment variable")
+	}
+
+	switch cfg.AuthMethod {
+	case AuthMethodToken:
+		if cfg.Token == "" {
+			return fmt.Errorf("a token must be specified in the plugin configuration or with the VAULT_TOKEN environment variable when the auth-method is %s", AuthMethodToken)
+		}
+	case AuthMethodAppRole:
+		if cfg.RoleID == "" || cfg.SecretID == "" {
+			return fmt.Errorf("role-id and secret-id must be specified in the plugin configuration when the auth-method is %s", AuthMethodAppRole)
+		}
+	default:
+		return fmt.Errorf("invalid auth-method %q, allowed values are: %s, %s", cfg.AuthMethod, AuthMethodToken, AuthMethodAppRole)
+	}
+
+	return nil
+}
+
+func NewPlugin(c *config.Config, rawCfg interface{}) (plugin.Plugin, error) {
+	cfg := NewPluginConfig()
+	if err := mapstructure.Decode(rawCfg, &cfg); err != nil {
+		return nil, fmt.Errorf("error reading plugin configuration: %w", err)
+	}
+
+	impl := NewStore(c, cfg)
+	return pluginstore.NewPlugin(c.Context, impl), nil
+}
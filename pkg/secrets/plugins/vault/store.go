@@ -0,0 +1,263 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/secrets/plugins"
+	"get.porter.sh/porter/pkg/secrets/plugins/host"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+var _ plugins.SecretsProtocol = &Store{}
+
+// SecretValueKey is the key in the secret's data that holds its value.
+const SecretValueKey = "value"
+
+// errSecretNotFound is returned when Vault does not have the requested secret.
+var errSecretNotFound = fmt.Errorf("secret %w", plugins.ErrNotFound)
+
+// Store implements a secrets store backed by a HashiCorp Vault KV version 2
+// secrets engine. Secrets that are not stored in Vault, such as environment
+// variables and files, are resolved from the host.
+type Store struct {
+	config    *config.Config
+	cfg       PluginConfig
+	client    *http.Client
+	token     string
+	hostStore plugins.SecretsProtocol
+}
+
+// NewStore returns a new instance of the vault secret store.
+func NewStore(c *config.Config, cfg PluginConfig) *Store {
+	return &Store{
+		config:    c,
+		cfg:       cfg,
+		hostStore: host.NewStore(),
+	}
+}
+
+// Connect authenticates to Vault.
+// The plugin itself is responsible for ensuring it was called.
+// Close is called automatically when the plugin is used by Porter.
+func (s *Store) Connect(ctx context.Context) error {
+	if s.client != nil {
+		return nil
+	}
+
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	if s.cfg.Address == "" {
+		s.cfg.Address = s.config.Getenv("VAULT_ADDR")
+	}
+	if s.cfg.AuthMethod == AuthMethodToken && s.cfg.Token == "" {
+		s.cfg.Token = s.config.Getenv("VAULT_TOKEN")
+	}
+	if err := s.cfg.Validate(); err != nil {
+		return log.Error(fmt.Errorf("invalid vault plugin configuration: %w", err))
+	}
+
+	client := &http.Client{Timeout: time.Duration(s.cfg.Timeout) * time.Second}
+
+	token := s.cfg.Token
+	if s.cfg.AuthMethod == AuthMethodAppRole {
+		var err error
+		if token, err = s.loginWithAppRole(ctx, client); err != nil {
+			return log.Error(err)
+		}
+	}
+
+	s.client = client
+	s.token = token
+	log.Debugf("storing secrets in vault at %s", s.cfg.Address)
+	return nil
+}
+
+// loginWithAppRole exchanges the configured role id and secret id for a token.
+func (s *Store) loginWithAppRole(ctx context.Context, client *http.Client) (string, error) {
+	body := map[string]string{
+		"role_id":   s.cfg.RoleID,
+		"secret_id": s.cfg.SecretID,
+	}
+	loginPath := path.Join("auth", s.cfg.AppRoleMountPath, "login")
+
+	var resp struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if err := s.do(ctx, client, http.MethodPost, loginPath, "", body, &resp); err != nil {
+		return "", fmt.Errorf("error logging in to vault with approle: %w", err)
+	}
+	if resp.Auth.ClientToken == "" {
+		return "", errors.New("error logging in to vault with approle: the response did not include a token")
+	}
+	return resp.Auth.ClientToken, nil
+}
+
+// Close implements the Close method on the secret plugins' interface.
+func (s *Store) Close() error {
+	if s.client != nil {
+		s.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// Resolve implements the Resolve method on the secret plugins' interface.
+func (s *Store) Resolve(ctx context.Context, keyName string, keyValue string) (string, error) {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	// check if the keyName is secret
+	if keyName != secrets.SourceSecret {
+		value, err := s.hostStore.Resolve(ctx, keyName, keyValue)
+		return value, log.Error(err)
+	}
+
+	if err := s.Connect(ctx); err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := s.do(ctx, s.client, http.MethodGet, s.secretPath(keyValue), s.token, nil, &resp); err != nil {
+		return "", log.Error(fmt.Errorf("error reading secret %s from vault: %w", keyValue, err))
+	}
+
+	value, ok := resp.Data.Data[SecretValueKey].(string)
+	if !ok {
+		return "", log.Error(fmt.Errorf("error reading secret %s from vault: the secret does not have a %s key", keyValue, SecretValueKey))
+	}
+	return value, nil
+}
+
+// Create implements the Create method on the secret plugins' interface.
+func (s *Store) Create(ctx context.Context, keyName string, keyValue string, value string) error {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	// check if the keyName is secret
+	if keyName != secrets.SourceSecret {
+		return log.Error(errors.New("invalid key name: " + keyName))
+	}
+
+	if err := s.Connect(ctx); err != nil {
+		return err
+	}
+
+	body := map[string]interface{}{
+		"data": map[string]string{SecretValueKey: value},
+	}
+	if err := s.do(ctx, s.client, http.MethodPost, s.secretPath(keyValue), s.token, body, nil); err != nil {
+		return log.Error(fmt.Errorf("error writing secret %s to vault: %w", keyValue, err))
+	}
+	return nil
+}
+
+// Delete implements the Delete method on the secret plugins' interface. All
+// versions of the secret are removed from Vault.
+func (s *Store) Delete(ctx context.Context, keyName string, keyValue string) error {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	// check if the keyName is secret
+	if keyName != secrets.SourceSecret {
+		return log.Error(errors.New("invalid key name: " + keyName))
+	}
+
+	if err := s.Connect(ctx); err != nil {
+		return err
+	}
+
+	err := s.do(ctx, s.client, http.MethodDelete, s.metadataPath(keyValue), s.token, nil, nil)
+	if err != nil && !errors.Is(err, errSecretNotFound) {
+		return log.Error(fmt.Errorf("error deleting secret %s from vault: %w", keyValue, err))
+	}
+	return nil
+}
+
+// secretPath returns the path of the KV version 2 data endpoint for a secret.
+func (s *Store) secretPath(keyValue string) string {
+	return path.Join(s.cfg.MountPath, "data", s.cfg.PathPrefix, url.PathEscape(keyValue))
+}
+
+// metadataPath returns the path of the KV version 2 metadata endpoint for a
+// secret, which manages every version of the secret.
+func (s *Store) metadataPath(keyValue string) string {
+	return path.Join(s.cfg.MountPath, "metadata", s.cfg.PathPrefix, url.PathEscape(keyValue))
+}
+
+// do makes a request to the Vault HTTP API, decoding the response into result
+// when it is not nil.
+func (s *Store) do(ctx context.Context, client *http.Client, method string, apiPath string, token string, body interface{}, result interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error encoding the request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	reqURL := strings.TrimSuffix(s.cfg.Address, "/") + "/v1/" + apiPath
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("error creating the request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if s.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.cfg.Namespace)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errSecretNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&vaultErr)
+		msg := fmt.Sprintf("vault returned %s", resp.Status)
+		if len(vaultErr.Errors) > 0 {
+			msg = fmt.Sprintf("%s: %s", msg, strings.Join(vaultErr.Errors, "; "))
+		}
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return fmt.Errorf("%s: %w", msg, plugins.ErrUnauthorized)
+		}
+		return errors.New(msg)
+	}
+
+	if result == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("error decoding the response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package vault_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/secrets/plugins/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault emulates the parts of the Vault HTTP API used by the plugin.
+type fakeVault struct {
+	mu        sync.Mutex
+	token     string
+	namespace string
+	secrets   map[string]string
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, *httptest.Server) {
+	v := &fakeVault{token: "root-token", secrets: map[string]string{}}
+	srv := httptest.NewServer(v)
+	t.Cleanup(srv.Close)
+	return v, srv
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if r.URL.Path == "/v1/auth/approle/login" {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["role_id"] != "myrole" || body["secret_id"] != "mysecret" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["invalid role or secret ID"]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"auth":{"client_token":"` + v.token + `"}}`))
+		return
+	}
+
+	if r.Header.Get("X-Vault-Token") != v.token || r.Header.Get("X-Vault-Namespace") != v.namespace {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		key := strings.TrimPrefix(r.URL.Path, "/v1/secret/metadata/")
+		delete(v.secrets, key)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")
+	switch r.Method {
+	case http.MethodGet:
+		value, ok := v.secrets[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"data": map[string]string{"value": value}},
+		})
+	case http.MethodPost:
+		var body struct {
+			Data map[string]string `json:"data"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		v.secrets[key] = body.Data["value"]
+		_, _ = w.Write([]byte(`{"data":{"version":1}}`))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestStore_CreateAndResolve(t *testing.T) {
+	fake, srv := newFakeVault(t)
+
+	c := config.NewTestConfig(t)
+	cfg := vault.NewPluginConfig()
+	cfg.Address = srv.URL
+	cfg.Token = fake.token
+	store := vault.NewStore(c.Config, cfg)
+	defer store.Close()
+
+	ctx := context.Background()
+	require.NoError(t, store.Create(ctx, secrets.SourceSecret, "password", "topsecret"))
+	assert.Equal(t, "topsecret", fake.secrets["porter/password"], "expected the secret to be stored under the path prefix")
+
+	value, err := store.Resolve(ctx, secrets.SourceSecret, "password")
+	require.NoError(t, err)
+	assert.Equal(t, "topsecret", value)
+
+	_, err = store.Resolve(ctx, secrets.SourceSecret, "missing")
+	require.ErrorContains(t, err, "secret not found")
+}
+
+func TestStore_Delete(t *testing.T) {
+	fake, srv := newFakeVault(t)
+	fake.secrets["porter/password"] = "topsecret"
+
+	c := config.NewTestConfig(t)
+	cfg := vault.NewPluginConfig()
+	cfg.Address = srv.URL
+	cfg.Token = fake.token
+	store := vault.NewStore(c.Config, cfg)
+	defer store.Close()
+
+	ctx := context.Background()
+	require.NoError(t, store.Delete(ctx, secrets.SourceSecret, "password"))
+	assert.NotContains(t, fake.secrets, "porter/password", "expected the secret to be removed from vault")
+
+	require.NoError(t, store.Delete(ctx, secrets.SourceSecret, "password"), "deleting a missing secret should not be an error")
+
+	err := store.Delete(ctx, "env", "PORTER_VAULT_TEST")
+	require.ErrorContains(t, err, "invalid key name")
+}
+
+func TestStore_AppRole(t *testing.T) {
+	fake, srv := newFakeVault(t)
+	fake.namespace = "team1"
+
+	c := config.NewTestConfig(t)
+	cfg := vault.NewPluginConfig()
+	cfg.Address = srv.URL
+	cfg.Namespace = "team1"
+	cfg.AuthMethod = vault.AuthMethodAppRole
+	cfg.RoleID = "myrole"
+	cfg.SecretID = "mysecret"
+	store := vault.NewStore(c.Config, cfg)
+	defer store.Close()
+
+	ctx := context.Background()
+	require.NoError(t, store.Create(ctx, secrets.SourceSecret, "password", "topsecret"))
+	value, err := store.Resolve(ctx, secrets.SourceSecret, "password")
+	require.NoError(t, err)
+	assert.Equal(t, "topsecret", value)
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		cfg.SecretID = "oops"
+		store := vault.NewStore(c.Config, cfg)
+		err := store.Connect(ctx)
+		require.ErrorContains(t, err, "invalid role or secret ID")
+	})
+}
+
+func TestStore_DefaultsFromEnvironment(t *testing.T) {
+	fake, srv := newFakeVault(t)
+
+	c := config.NewTestConfig(t)
+	c.Setenv("VAULT_ADDR", srv.URL)
+	c.Setenv("VAULT_TOKEN", fake.token)
+	store := vault.NewStore(c.Config, vault.NewPluginConfig())
+	defer store.Close()
+
+	require.NoError(t, store.Create(context.Background(), secrets.SourceSecret, "password", "topsecret"))
+}
+
+func TestStore_ResolveFromHost(t *testing.T) {
+	c := config.NewTestConfig(t)
+	t.Setenv("PORTER_VAULT_TEST", "hostvalue")
+
+	// Values that are not stored in vault do not require a connection
+	store := vault.NewStore(c.Config, vault.NewPluginConfig())
+	value, err := store.Resolve(context.Background(), "env", "PORTER_VAULT_TEST")
+	require.NoError(t, err)
+	assert.Equal(t, "hostvalue", value)
+
+	err = store.Create(context.Background(), "env", "PORTER_VAULT_TEST", "newvalue")
+	require.ErrorContains(t, err, "invalid key name")
+}
+
+func TestPluginConfig_Validate(t *testing.T) {
+	testcases := []struct {
+		name    string
+		modify  func(cfg *vault.PluginConfig)
+		wantErr string
+	}{
+		{name: "token", modify: func(cfg *vault.PluginConfig) { cfg.Token = "abc" }},
+		{name: "approle", modify: func(cfg *vault.PluginConfig) {
+			cfg.AuthMethod = vault.AuthMethodAppRole
+			cfg.RoleID = "role"
+			cfg.SecretID = "secret"
+		}},
+		{name: "missing address", modify: func(cfg *vault.PluginConfig) { cfg.Address = "" }, wantErr: "the vault address must be specified"},
+		{name: "missing token", modify: func(cfg *vault.PluginConfig) {}, wantErr: "a token must be specified"},
+		{name: "missing secret id", modify: func(cfg *vault.PluginConfig) {
+			cfg.AuthMethod = vault.AuthMethodAppRole
+			cfg.RoleID = "role"
+		}, wantErr: "role-id and secret-id must be specified"},
+		{name: "invalid auth method", modify: func(cfg *vault.PluginConfig) { cfg.AuthMethod = "ldap" }, wantErr: "invalid auth-method"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := vault.NewPluginConfig()
+			cfg.Address = "https://vault.example.com"
+			tc.modify(&cfg)
+			err := cfg.Validate()
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
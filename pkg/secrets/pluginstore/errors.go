@@ -0,0 +1,62 @@
+package pluginstore
+
+import (
+	"errors"
+
+	"get.porter.sh/porter/pkg/secrets/plugins"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatusError converts an error returned by the plugin to a gRPC status
+// with a code for the class of error, so that the class survives the round
+// trip to porter.
+func toStatusError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, plugins.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, plugins.ErrUnauthorized):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, plugins.ErrNotImplemented):
+		return status.Error(codes.Unimplemented, err.Error())
+	}
+	return err
+}
+
+// fromStatusError converts the gRPC status returned by the plugin back to the
+// class of error.
+func fromStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var class error
+	switch status.Code(err) {
+	case codes.NotFound:
+		class = plugins.ErrNotFound
+	case codes.PermissionDenied, codes.Unauthenticated:
+		class = plugins.ErrUnauthorized
+	case codes.Unimplemented:
+		class = plugins.ErrNotImplemented
+	default:
+		return err
+	}
+	return statusError{message: status.Convert(err).Message(), class: class}
+}
+
+// statusError is an error returned by the plugin, with the message reported
+// by the plugin, that can be tested for its class with errors.Is.
+type statusError struct {
+	message string
+	class   error
+}
+
+func (e statusError) Error() string {
+	return e.message
+}
+
+func (e statusError) Unwrap() error {
+	return e.class
+}
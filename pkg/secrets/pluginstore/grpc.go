@@ -6,9 +6,13 @@ import (
 	"get.porter.sh/porter/pkg/portercontext"
 	"get.porter.sh/porter/pkg/secrets/plugins"
 	"get.porter.sh/porter/pkg/secrets/plugins/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var _ plugins.SecretsProtocol = &GClient{}
+var _ plugins.BulkSecretsProtocol = &GClient{}
+var _ plugins.CredentialIssuerProtocol = &GClient{}
 
 // GClient is a gRPC implementation of the storage client.
 type GClient struct {
@@ -27,7 +31,7 @@ func (m *GClient) Resolve(ctx context.Context, keyName string, keyValue string)
 
 	resp, err := m.client.Resolve(ctx, req)
 	if err != nil {
-		return "", err
+		return "", fromStatusError(err)
 	}
 	return resp.Value, nil
 }
@@ -39,7 +43,53 @@ func (m *GClient) Create(ctx context.Context, keyName string, keyValue string, v
 		Value:    value,
 	}
 	_, err := m.client.Create(ctx, req)
-	return err
+	return fromStatusError(err)
+}
+
+func (m *GClient) Delete(ctx context.Context, keyName string, keyValue string) error {
+	req := &proto.DeleteRequest{
+		KeyName:  keyName,
+		KeyValue: keyValue,
+	}
+	_, err := m.client.Delete(ctx, req)
+	// Plugins built against an older version of the protocol do not support
+	// deleting secrets, and return ErrNotImplemented
+	return fromStatusError(err)
+}
+
+// CreateMultiple stores the secrets in a single call. Plugins built before the
+// protocol supported batches return ErrNotImplemented.
+func (m *GClient) CreateMultiple(ctx context.Context, secrets []plugins.Secret) error {
+	req := &proto.CreateMultipleRequest{Secrets: make([]*proto.Secret, len(secrets))}
+	for i, secret := range secrets {
+		req.Secrets[i] = &proto.Secret{
+			KeyName:  secret.KeyName,
+			KeyValue: secret.KeyValue,
+			Value:    secret.Value,
+		}
+	}
+	_, err := m.client.CreateMultiple(ctx, req)
+	return fromStatusError(err)
+}
+
+func (m *GClient) IssueCredentials(ctx context.Context, keyValue string, runID string) (plugins.IssuedCredential, error) {
+	req := &proto.IssueCredentialsRequest{
+		KeyValue: keyValue,
+		RunID:    runID,
+	}
+	resp, err := m.client.IssueCredentials(ctx, req)
+	if err != nil {
+		return plugins.IssuedCredential{}, fromStatusError(err)
+	}
+	return plugins.IssuedCredential{Value: resp.Value, LeaseID: resp.LeaseID}, nil
+}
+
+func (m *GClient) RevokeCredentials(ctx context.Context, leaseID string) error {
+	req := &proto.RevokeCredentialsRequest{
+		LeaseID: leaseID,
+	}
+	_, err := m.client.RevokeCredentials(ctx, req)
+	return fromStatusError(err)
 }
 
 // GServer is a gRPC wrapper around a SecretsProtocol plugin
@@ -56,7 +106,7 @@ func NewServer(c *portercontext.Context, impl plugins.SecretsProtocol) *GServer
 func (m *GServer) Resolve(ctx context.Context, request *proto.ResolveRequest) (*proto.ResolveResponse, error) {
 	value, err := m.impl.Resolve(ctx, request.KeyName, request.KeyValue)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
 	return &proto.ResolveResponse{Value: value}, nil
 }
@@ -64,7 +114,53 @@ func (m *GServer) Resolve(ctx context.Context, request *proto.ResolveRequest) (*
 func (m *GServer) Create(ctx context.Context, request *proto.CreateRequest) (*proto.CreateResponse, error) {
 	err := m.impl.Create(ctx, request.KeyName, request.KeyValue, request.Value)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
 	return &proto.CreateResponse{}, nil
 }
+
+func (m *GServer) Delete(ctx context.Context, request *proto.DeleteRequest) (*proto.DeleteResponse, error) {
+	err := m.impl.Delete(ctx, request.KeyName, request.KeyValue)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &proto.DeleteResponse{}, nil
+}
+
+func (m *GServer) CreateMultiple(ctx context.Context, request *proto.CreateMultipleRequest) (*proto.CreateMultipleResponse, error) {
+	secrets := make([]plugins.Secret, len(request.Secrets))
+	for i, secret := range request.Secrets {
+		secrets[i] = plugins.Secret{
+			KeyName:  secret.KeyName,
+			KeyValue: secret.KeyValue,
+			Value:    secret.Value,
+		}
+	}
+	if err := plugins.CreateMultiple(ctx, m.impl, secrets); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &proto.CreateMultipleResponse{}, nil
+}
+
+func (m *GServer) IssueCredentials(ctx context.Context, request *proto.IssueCredentialsRequest) (*proto.IssueCredentialsResponse, error) {
+	issuer, ok := m.impl.(plugins.CredentialIssuerProtocol)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "the secrets plugin does not support issuing credentials")
+	}
+	cred, err := issuer.IssueCredentials(ctx, request.KeyValue, request.RunID)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &proto.IssueCredentialsResponse{Value: cred.Value, LeaseID: cred.LeaseID}, nil
+}
+
+func (m *GServer) RevokeCredentials(ctx context.Context, request *proto.RevokeCredentialsRequest) (*proto.RevokeCredentialsResponse, error) {
+	issuer, ok := m.impl.(plugins.CredentialIssuerProtocol)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "the secrets plugin does not support issuing credentials")
+	}
+	if err := issuer.RevokeCredentials(ctx, request.LeaseID); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &proto.RevokeCredentialsResponse{}, nil
+}
@@ -4,14 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/metrics"
 	"get.porter.sh/porter/pkg/plugins/pluggable"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/secrets/kms"
 	"get.porter.sh/porter/pkg/secrets/plugins"
 	"get.porter.sh/porter/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var _ plugins.SecretsProtocol = &Store{}
+var _ plugins.BulkSecretsProtocol = &Store{}
+var _ plugins.CredentialIssuerProtocol = &Store{}
 
 // Store is a plugin-backed source of secrets. It resolves the appropriate
 // plugin based on Porter's config and implements the plugins.SecretsProtocol interface
@@ -22,11 +29,30 @@ type Store struct {
 	*config.Config
 	plugin plugins.SecretsProtocol
 	conn   *pluggable.PluginConnection
+
+	// decryptors decrypt the values of the kms source, reusing the client of
+	// each key management service.
+	decryptors *kms.Decryptors
+
+	// name of the secrets account defined in the config file to connect to.
+	// When empty, the default secrets account is used.
+	name string
 }
 
 func NewStore(c *config.Config) *Store {
 	return &Store{
-		Config: c,
+		Config:     c,
+		decryptors: kms.NewDecryptors(c),
+	}
+}
+
+// NewStoreFor creates a Store that connects to the named secrets account
+// defined in the config file, instead of the default secrets account.
+func NewStoreFor(c *config.Config, name string) *Store {
+	return &Store{
+		Config:     c,
+		name:       name,
+		decryptors: kms.NewDecryptors(c),
 	}
 }
 
@@ -49,14 +75,24 @@ func NewSecretsPluginConfig() pluggable.PluginTypeConfig {
 }
 
 func (s *Store) Resolve(ctx context.Context, keyName string, keyValue string) (string, error) {
-	ctx, span := tracing.StartSpan(ctx)
+	ctx, span := tracing.StartSpan(ctx, attribute.String("sourceKey", keyName))
 	defer span.EndSpan()
 
+	if keyName == secrets.SourceKMS {
+		value, err := s.decryptors.Decrypt(ctx, keyValue)
+		if !errors.Is(err, kms.ErrUnknownProvider) {
+			return value, span.Error(err)
+		}
+		// Let the secrets plugin decrypt values from providers that Porter does not support
+	}
+
 	if err := s.Connect(ctx); err != nil {
 		return "", err
 	}
 
+	start := time.Now()
 	value, err := s.plugin.Resolve(ctx, keyName, keyValue)
+	metrics.ObserveSecretStore("resolve", start, err)
 	if err != nil {
 		return "", span.Error(err)
 	}
@@ -64,22 +100,98 @@ func (s *Store) Resolve(ctx context.Context, keyName string, keyValue string) (s
 }
 
 func (s *Store) Create(ctx context.Context, keyName string, keyValue string, value string) error {
-	ctx, span := tracing.StartSpan(ctx)
+	ctx, span := tracing.StartSpan(ctx, attribute.String("sourceKey", keyName))
 	defer span.EndSpan()
 
 	if err := s.Connect(ctx); err != nil {
 		return err
 	}
 
+	start := time.Now()
 	err := s.plugin.Create(ctx, keyName, keyValue, value)
+	metrics.ObserveSecretStore("create", start, err)
+	return span.Error(checkCreateError(err))
+}
+
+func (s *Store) Delete(ctx context.Context, keyName string, keyValue string) error {
+	ctx, span := tracing.StartSpan(ctx, attribute.String("sourceKey", keyName))
+	defer span.EndSpan()
+
+	if err := s.Connect(ctx); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err := s.plugin.Delete(ctx, keyName, keyValue)
+	metrics.ObserveSecretStore("delete", start, err)
+	return span.Error(err)
+}
+
+// CreateMultiple stores the secrets in a single call when the plugin supports
+// it, otherwise each secret is created individually.
+func (s *Store) CreateMultiple(ctx context.Context, secrets []plugins.Secret) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := s.Connect(ctx); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err := plugins.CreateMultiple(ctx, s.plugin, secrets)
+	metrics.ObserveSecretStore("create", start, err)
+	return span.Error(checkCreateError(err))
+}
+
+// IssueCredentials mints a credential for the run when the plugin supports it.
+func (s *Store) IssueCredentials(ctx context.Context, keyValue string, runID string) (plugins.IssuedCredential, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := s.Connect(ctx); err != nil {
+		return plugins.IssuedCredential{}, err
+	}
+
+	issuer, ok := s.plugin.(plugins.CredentialIssuerProtocol)
+	if !ok {
+		return plugins.IssuedCredential{}, span.Error(fmt.Errorf("the current secrets plugin does not support issuing credentials: %w", plugins.ErrNotImplemented))
+	}
+
+	cred, err := issuer.IssueCredentials(ctx, keyValue, runID)
+	if err != nil {
+		return plugins.IssuedCredential{}, span.Error(err)
+	}
+	return cred, nil
+}
+
+// RevokeCredentials revokes a credential issued for a run when the plugin supports it.
+func (s *Store) RevokeCredentials(ctx context.Context, leaseID string) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := s.Connect(ctx); err != nil {
+		return err
+	}
+
+	issuer, ok := s.plugin.(plugins.CredentialIssuerProtocol)
+	if !ok {
+		return span.Error(fmt.Errorf("the current secrets plugin does not support revoking credentials: %w", plugins.ErrNotImplemented))
+	}
+
+	err := issuer.RevokeCredentials(ctx, leaseID)
+	return span.Error(err)
+}
+
+// checkCreateError explains how to resolve the error returned when the plugin
+// does not support persisting secrets.
+func checkCreateError(err error) error {
 	if errors.Is(err, plugins.ErrNotImplemented) {
 		//TODO: add the doc page link once it exists
-		return span.Error(fmt.Errorf(`the current secrets plugin does not support persisting secrets. You need to edit your porter configuration file and configure a different secrets plugin.
+		return fmt.Errorf(`the current secrets plugin does not support persisting secrets. You need to edit your porter configuration file and configure a different secrets plugin.
 		
-If you are just testing out Porter, and are not working with production secrets, you can edit your config file and set default-storage-plugin to "filesystem" to use the insecure filesystem plugin. Do not use the filesystem plugin for production data.: %w`, err))
+If you are just testing out Porter, and are not working with production secrets, you can edit your config file and set default-storage-plugin to "filesystem" to use the insecure filesystem plugin. Do not use the filesystem plugin for production data.: %w`, err)
 	}
-
-	return span.Error(err)
+	return err
 }
 
 // Connect initializes the plugin for use.
@@ -94,6 +206,11 @@ func (s *Store) Connect(ctx context.Context) error {
 	defer span.EndSpan()
 
 	pluginType := NewSecretsPluginConfig()
+	if s.name != "" {
+		pluginType.GetDefaultPluggable = func(c *config.Config) string {
+			return s.name
+		}
+	}
 
 	l := pluggable.NewPluginLoader(s.Config)
 	conn, err := l.Load(ctx, pluginType)
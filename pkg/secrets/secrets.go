@@ -2,10 +2,38 @@ package secrets
 
 import (
 	"context"
+	"fmt"
+
+	"get.porter.sh/porter/pkg/secrets/plugins"
 )
 
 const SourceSecret = "secret"
 
+// SourceIssued is the source of a credential that is minted by the secrets
+// plugin when a run starts and revoked when the run completes. The value of
+// the source identifies the kind of credential to issue.
+const SourceIssued = "issued"
+
+// SourceKMS is the source of a value that is encrypted with a key in a key
+// management service, such as AWS KMS, and decrypted when it is resolved.
+// The value of the source is formatted as PROVIDER:KEY:CIPHERTEXT.
+const SourceKMS = "kms"
+
+// Errors returned by a Store, which can be tested with errors.Is.
+var (
+	// ErrNotFound is returned when a secret does not exist in the secret store.
+	ErrNotFound = plugins.ErrNotFound
+
+	// ErrUnauthorized is returned when the secret store rejected the
+	// credentials of the secrets plugin, or they do not grant access to the
+	// secret.
+	ErrUnauthorized = plugins.ErrUnauthorized
+
+	// ErrNotImplemented is returned when the secrets plugin does not support
+	// the operation.
+	ErrNotImplemented = plugins.ErrNotImplemented
+)
+
 // Store is the interface that Porter uses to interact with secrets.
 type Store interface {
 	Close() error
@@ -27,4 +55,69 @@ type Store interface {
 	// - keyName=key, keyValue=conn-string, value=redis://foo
 	// - keyName=path, keyValue=/tmp/connstring.txt, value=redis://foo
 	Create(ctx context.Context, keyName string, keyValue string, value string) error
+
+	// Delete removes a secret value from a secret store.
+	// - keyName is name of the key where the secret can be found.
+	// - keyValue is the value of the key.
+	// Deleting a secret that does not exist is not an error.
+	// Examples:
+	// - keyName=secret, keyValue=conn-string
+	Delete(ctx context.Context, keyName string, keyValue string) error
+}
+
+// Secret is a secret value to store in a secret store.
+type Secret = plugins.Secret
+
+// BulkStore is an optional interface implemented by a Store that can save
+// multiple secrets in a single call. Use CreateMultiple to save secrets with a
+// Store that may not support it.
+type BulkStore interface {
+	Store
+
+	// CreateMultiple stores multiple secret values in a secret store.
+	CreateMultiple(ctx context.Context, secrets []Secret) error
+}
+
+// CreateMultiple stores the secrets in a single call when the store implements
+// BulkStore, otherwise each secret is created individually.
+func CreateMultiple(ctx context.Context, store Store, secrets []Secret) error {
+	return plugins.CreateMultiple(ctx, store, secrets)
+}
+
+// IssuedCredential is a credential minted by a secrets plugin for a single run.
+type IssuedCredential = plugins.IssuedCredential
+
+// CredentialIssuer is an optional interface implemented by a Store that can
+// mint credentials scoped to a single run. Use IssueCredentials to issue
+// credentials with a Store that may not support it.
+type CredentialIssuer interface {
+	Store
+
+	// IssueCredentials mints a credential for the run.
+	// - keyValue identifies the kind of credential to issue.
+	// - runID is the id of the run that will use the credential.
+	IssueCredentials(ctx context.Context, keyValue string, runID string) (IssuedCredential, error)
+
+	// RevokeCredentials revokes a credential previously issued for a run.
+	RevokeCredentials(ctx context.Context, leaseID string) error
+}
+
+// IssueCredentials mints a credential for the run when the store implements
+// CredentialIssuer, otherwise an error wrapping plugins.ErrNotImplemented is
+// returned.
+func IssueCredentials(ctx context.Context, store Store, keyValue string, runID string) (IssuedCredential, error) {
+	issuer, ok := store.(CredentialIssuer)
+	if !ok {
+		return IssuedCredential{}, fmt.Errorf("the secrets store does not support issuing credentials: %w", plugins.ErrNotImplemented)
+	}
+	return issuer.IssueCredentials(ctx, keyValue, runID)
+}
+
+// RevokeCredentials revokes a credential issued with IssueCredentials.
+func RevokeCredentials(ctx context.Context, store Store, leaseID string) error {
+	issuer, ok := store.(CredentialIssuer)
+	if !ok {
+		return fmt.Errorf("the secrets store does not support revoking credentials: %w", plugins.ErrNotImplemented)
+	}
+	return issuer.RevokeCredentials(ctx, leaseID)
 }
@@ -0,0 +1,139 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"get.porter.sh/porter/pkg/secrets/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingStore is a Store that does not support BulkStore, and records
+// the secrets that were created.
+type recordingStore struct {
+	created []Secret
+	err     error
+}
+
+func (s *recordingStore) Close() error {
+	return nil
+}
+
+func (s *recordingStore) Resolve(ctx context.Context, keyName string, keyValue string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (s *recordingStore) Create(ctx context.Context, keyName string, keyValue string, value string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.created = append(s.created, Secret{KeyName: keyName, KeyValue: keyValue, Value: value})
+	return nil
+}
+
+func (s *recordingStore) Delete(ctx context.Context, keyName string, keyValue string) error {
+	return errors.New("not implemented")
+}
+
+// recordingBulkStore is a BulkStore that records each batch of secrets.
+type recordingBulkStore struct {
+	recordingStore
+	batches [][]Secret
+	bulkErr error
+}
+
+func (s *recordingBulkStore) CreateMultiple(ctx context.Context, secrets []Secret) error {
+	if s.bulkErr != nil {
+		return s.bulkErr
+	}
+	s.batches = append(s.batches, secrets)
+	return nil
+}
+
+func TestCreateMultiple(t *testing.T) {
+	ctx := context.Background()
+	values := []Secret{
+		{KeyName: SourceSecret, KeyValue: "password", Value: "topsecret"},
+		{KeyName: SourceSecret, KeyValue: "token", Value: "abc123"},
+	}
+
+	t.Run("bulk store", func(t *testing.T) {
+		store := &recordingBulkStore{}
+		require.NoError(t, CreateMultiple(ctx, store, values))
+		assert.Equal(t, [][]Secret{values}, store.batches, "expected the secrets to be saved in a single call")
+		assert.Empty(t, store.created, "expected Create not to be called")
+	})
+
+	t.Run("fallback to create", func(t *testing.T) {
+		store := &recordingStore{}
+		require.NoError(t, CreateMultiple(ctx, store, values))
+		assert.Equal(t, values, store.created)
+	})
+
+	t.Run("bulk not implemented", func(t *testing.T) {
+		store := &recordingBulkStore{bulkErr: plugins.ErrNotImplemented}
+		require.NoError(t, CreateMultiple(ctx, store, values))
+		assert.Equal(t, values, store.created, "expected each secret to be created when the plugin does not support batches")
+	})
+
+	t.Run("bulk error", func(t *testing.T) {
+		store := &recordingBulkStore{bulkErr: errors.New("boom")}
+		require.EqualError(t, CreateMultiple(ctx, store, values), "boom")
+		assert.Empty(t, store.created, "expected Create not to be called")
+	})
+
+	t.Run("create error", func(t *testing.T) {
+		store := &recordingStore{err: errors.New("boom")}
+		require.EqualError(t, CreateMultiple(ctx, store, values), "boom")
+	})
+
+	t.Run("no secrets", func(t *testing.T) {
+		store := &recordingBulkStore{}
+		require.NoError(t, CreateMultiple(ctx, store, nil))
+		assert.Empty(t, store.batches)
+	})
+}
+
+func TestPluginAdapter_CreateMultiple(t *testing.T) {
+	ctx := context.Background()
+	store := NewTestSecretsProvider()
+
+	values := []Secret{
+		{KeyName: SourceSecret, KeyValue: "password", Value: "topsecret"},
+		{KeyName: SourceSecret, KeyValue: "token", Value: "abc123"},
+	}
+	require.NoError(t, store.CreateMultiple(ctx, values))
+
+	for _, secret := range values {
+		value, err := store.Resolve(ctx, secret.KeyName, secret.KeyValue)
+		require.NoError(t, err)
+		assert.Equal(t, secret.Value, value)
+	}
+}
+
+func TestIssueCredentials(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("issuer", func(t *testing.T) {
+		store := NewTestSecretsProvider()
+		cred, err := IssueCredentials(ctx, store, "postgres/readonly", "01RUN")
+		require.NoError(t, err)
+		assert.Equal(t, "postgres/readonly-01RUN", cred.Value)
+		require.NotEmpty(t, cred.LeaseID)
+
+		require.NoError(t, RevokeCredentials(ctx, store, cred.LeaseID))
+		assert.Equal(t, []string{cred.LeaseID}, store.InMemory().Revoked)
+		assert.Empty(t, store.InMemory().Leases)
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		store := &recordingStore{}
+		_, err := IssueCredentials(ctx, store, "postgres/readonly", "01RUN")
+		require.ErrorIs(t, err, plugins.ErrNotImplemented)
+
+		err = RevokeCredentials(ctx, store, "lease-1")
+		require.ErrorIs(t, err, plugins.ErrNotImplemented)
+	})
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/failure"
+)
+
+// OutputAccessControl is the storage access-control hook that is called before
+// an installation's output is read on behalf of another namespace.
+type OutputAccessControl interface {
+	// AuthorizeOutputAccess returns ErrAccessDenied when the requesting
+	// namespace is not allowed to read the output.
+	AuthorizeOutputAccess(ctx context.Context, requestingNamespace string, namespace string, installation string, output string) error
+}
+
+// ErrAccessDenied is returned when a namespace is not allowed to read the
+// output of an installation in another namespace.
+type ErrAccessDenied struct {
+	RequestingNamespace string
+	Namespace           string
+	Installation        string
+	Output              string
+}
+
+func (e ErrAccessDenied) Error() string {
+	return fmt.Sprintf("access denied: no namespace policy shares output %s of installation %s/%s with namespace %s",
+		e.Output, e.Namespace, e.Installation, e.RequestingNamespace)
+}
+
+func (e ErrAccessDenied) Is(err error) bool {
+	_, ok := err.(ErrAccessDenied)
+	return ok
+}
+
+var _ OutputAccessControl = NamespacePolicyAccessControl{}
+
+// NamespacePolicyAccessControl authorizes access to outputs in other
+// namespaces using the namespace-policies defined in Porter's configuration.
+// Access is denied unless a policy shares the output with the requesting
+// namespace.
+type NamespacePolicyAccessControl struct {
+	config *config.Config
+}
+
+// NewNamespacePolicyAccessControl creates an access-control hook that
+// enforces the namespace policies in the configuration. The policies are read
+// each time access is requested, so that they reflect the loaded configuration.
+func NewNamespacePolicyAccessControl(c *config.Config) NamespacePolicyAccessControl {
+	return NamespacePolicyAccessControl{config: c}
+}
+
+func (a NamespacePolicyAccessControl) AuthorizeOutputAccess(ctx context.Context, requestingNamespace string, namespace string, installation string, output string) error {
+	// Installations can always read outputs from their own namespace
+	if requestingNamespace == namespace {
+		return nil
+	}
+
+	if a.config != nil {
+		for _, policy := range a.config.Data.NamespacePolicies {
+			if policy.SharesOutput(requestingNamespace, namespace, installation, output) {
+				return nil
+			}
+		}
+	}
+
+	return failure.PolicyDenied(ErrAccessDenied{
+		RequestingNamespace: requestingNamespace,
+		Namespace:           namespace,
+		Installation:        installation,
+		Output:              output,
+	})
+}
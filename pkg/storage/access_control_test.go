@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespacePolicyAccessControl_AuthorizeOutputAccess(t *testing.T) {
+	c := config.NewTestConfig(t)
+	c.Data.NamespacePolicies = []config.NamespacePolicy{
+		{Namespace: "platform", ShareOutputsWith: []string{"dev", "test"}, Installations: []string{"mysql"}, Outputs: []string{"connstr"}},
+		{Namespace: "shared", ShareOutputsWith: []string{"*"}},
+	}
+	ac := NewNamespacePolicyAccessControl(c.Config)
+
+	testcases := []struct {
+		name         string
+		requesting   string
+		namespace    string
+		installation string
+		output       string
+		allowed      bool
+	}{
+		{name: "same namespace", requesting: "dev", namespace: "dev", installation: "myapp", output: "password", allowed: true},
+		{name: "shared output", requesting: "dev", namespace: "platform", installation: "mysql", output: "connstr", allowed: true},
+		{name: "output not shared", requesting: "dev", namespace: "platform", installation: "mysql", output: "root-password"},
+		{name: "installation not shared", requesting: "dev", namespace: "platform", installation: "redis", output: "connstr"},
+		{name: "namespace not shared with", requesting: "prod", namespace: "platform", installation: "mysql", output: "connstr"},
+		{name: "shared with every namespace", requesting: "prod", namespace: "shared", installation: "dns", output: "zone", allowed: true},
+		{name: "no policy", requesting: "dev", namespace: "prod", installation: "mysql", output: "connstr"},
+		{name: "global namespace", requesting: "", namespace: "platform", installation: "mysql", output: "connstr"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := ac.AuthorizeOutputAccess(context.Background(), tc.requesting, tc.namespace, tc.installation, tc.output)
+			if tc.allowed {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrAccessDenied{})
+			}
+		})
+	}
+}
+
+func TestInstallationStore_GetSharedOutput(t *testing.T) {
+	ctx := context.Background()
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	i := cp.CreateInstallation(NewInstallation("platform", "mysql"))
+	run := cp.CreateRun(i.NewRun(cnab.ActionInstall))
+	result := cp.CreateResult(run.NewResult(cnab.StatusSucceeded))
+	cp.CreateOutput(result.NewOutput("connstr", []byte("mysql://platform")))
+
+	t.Run("same namespace", func(t *testing.T) {
+		o, err := cp.GetSharedOutput(ctx, "platform", "platform", "mysql", "connstr")
+		require.NoError(t, err)
+		assert.Equal(t, "mysql://platform", string(o.Value))
+	})
+
+	t.Run("denied by default", func(t *testing.T) {
+		_, err := cp.GetSharedOutput(ctx, "dev", "platform", "mysql", "connstr")
+		require.ErrorIs(t, err, ErrAccessDenied{})
+		assert.EqualError(t, err, "access denied: no namespace policy shares output connstr of installation platform/mysql with namespace dev")
+	})
+
+	t.Run("shared", func(t *testing.T) {
+		c := config.NewTestConfig(t)
+		c.Data.NamespacePolicies = []config.NamespacePolicy{{Namespace: "platform", ShareOutputsWith: []string{"dev"}}}
+		cp.SetOutputAccessControl(NewNamespacePolicyAccessControl(c.Config))
+		defer cp.SetOutputAccessControl(NamespacePolicyAccessControl{})
+
+		o, err := cp.GetSharedOutput(ctx, "dev", "platform", "mysql", "connstr")
+		require.NoError(t, err)
+		assert.Equal(t, "mysql://platform", string(o.Value))
+
+		_, err = cp.GetSharedOutput(ctx, "dev", "platform", "mysql", "missing")
+		require.ErrorIs(t, err, ErrNotFound{})
+	})
+}
@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/failure"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// AuthorizationVerb is the action that a subject requests to perform on a resource.
+type AuthorizationVerb string
+
+const (
+	// VerbRead is requested to get or list documents.
+	VerbRead AuthorizationVerb = "read"
+
+	// VerbWrite is requested to insert or update documents.
+	VerbWrite AuthorizationVerb = "write"
+
+	// VerbDelete is requested to remove documents.
+	VerbDelete AuthorizationVerb = "delete"
+)
+
+// AuthorizationRequest describes an operation on the documents in a namespace.
+type AuthorizationRequest struct {
+	// Subject is the identity making the request. It is set on the context
+	// with ContextWithSubject, and is empty when it was not set.
+	Subject string
+
+	// Namespace of the documents.
+	Namespace string
+
+	// Verb is the action requested: read, write or delete.
+	Verb AuthorizationVerb
+
+	// Resource is the collection of the documents, such as installations,
+	// runs, results, outputs, credentials or parameters.
+	Resource string
+
+	// Name of the installation, credential set or parameter set, when the
+	// request is for a single document.
+	Name string
+}
+
+// Authorizer is the storage hook that is called by the installation,
+// credential set, parameter set and schedule stores before documents are
+// read or changed, so that requests for another team's namespace can be
+// denied. Every method of the stores is authorized, including maintenance
+// operations such as fsck.
+type Authorizer interface {
+	// Authorize returns ErrForbidden when the request is denied.
+	Authorize(ctx context.Context, req AuthorizationRequest) error
+}
+
+// ErrForbidden is returned when the authorizer does not allow a subject to
+// perform an operation in a namespace.
+// You can test for this error using errors.Is(err, storage.ErrForbidden{})
+type ErrForbidden struct {
+	Request AuthorizationRequest
+}
+
+func (e ErrForbidden) Error() string {
+	return fmt.Sprintf("access denied: %s is not allowed to %s %s in namespace %q",
+		e.Request.Subject, e.Request.Verb, e.Request.Resource, e.Request.Namespace)
+}
+
+func (e ErrForbidden) Is(err error) bool {
+	_, ok := err.(ErrForbidden)
+	return ok
+}
+
+type subjectContextKey struct{}
+
+// ContextWithSubject returns a context that authorizes storage requests as the subject.
+func ContextWithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, subjectContextKey{}, subject)
+}
+
+// SubjectFromContext returns the subject set with ContextWithSubject.
+func SubjectFromContext(ctx context.Context) string {
+	subject, _ := ctx.Value(subjectContextKey{}).(string)
+	return subject
+}
+
+// authorize checks the request with the authorizer. Every request is allowed
+// when an authorizer is not set.
+func authorize(ctx context.Context, authz Authorizer, verb AuthorizationVerb, resource string, namespace string, name string) error {
+	if authz == nil {
+		return nil
+	}
+	return authz.Authorize(ctx, AuthorizationRequest{
+		Subject:   SubjectFromContext(ctx),
+		Namespace: namespace,
+		Verb:      verb,
+		Resource:  resource,
+		Name:      name,
+	})
+}
+
+// authorizeFetched checks that the subject may read a document that was
+// looked up by its id. A denied request is reported as not found, so that a
+// subject can't discover the documents in a namespace that it may not read.
+func authorizeFetched(ctx context.Context, authz Authorizer, resource string, namespace string, name string) error {
+	err := authorize(ctx, authz, VerbRead, resource, namespace, name)
+	if errors.Is(err, ErrForbidden{}) {
+		return ErrNotFound{Collection: resource}
+	}
+	return err
+}
+
+// namespaceAuthorizer determines if the subject may perform an action on a
+// set of resources in a namespace, caching the decision for each namespace
+// so that documents from several collections are filtered consistently.
+type namespaceAuthorizer struct {
+	authz     Authorizer
+	verb      AuthorizationVerb
+	resources []string
+	allowed   map[string]bool
+}
+
+func newNamespaceAuthorizer(authz Authorizer, verb AuthorizationVerb, resources ...string) *namespaceAuthorizer {
+	return &namespaceAuthorizer{
+		authz:     authz,
+		verb:      verb,
+		resources: resources,
+		allowed:   make(map[string]bool),
+	}
+}
+
+// Allows returns true when every resource may be accessed in the namespace.
+// Errors other than ErrForbidden are returned.
+func (a *namespaceAuthorizer) Allows(ctx context.Context, namespace string) (bool, error) {
+	if a.authz == nil {
+		return true, nil
+	}
+	if ok, checked := a.allowed[namespace]; checked {
+		return ok, nil
+	}
+
+	ok := true
+	for _, resource := range a.resources {
+		err := authorize(ctx, a.authz, a.verb, resource, namespace, "")
+		if err != nil && !errors.Is(err, ErrForbidden{}) {
+			return false, err
+		}
+		if err != nil {
+			ok = false
+			break
+		}
+	}
+	a.allowed[namespace] = ok
+	return ok, nil
+}
+
+// filterNamespaces removes the documents from the namespaces that are not allowed.
+func filterNamespaces[T any](ctx context.Context, allows func(context.Context, string) (bool, error), docs []T, getNamespace func(T) string) ([]T, error) {
+	result := make([]T, 0, len(docs))
+	for _, doc := range docs {
+		ok, err := allows(ctx, getNamespace(doc))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, doc)
+		}
+	}
+	return result, nil
+}
+
+// filterAuthorized removes the documents that the subject is not allowed to
+// read from a list, so that listing every namespace only returns the
+// documents from the namespaces that the subject may read.
+func filterAuthorized[T any](ctx context.Context, authz Authorizer, resource string, docs []T, getNamespace func(T) string) ([]T, error) {
+	if authz == nil {
+		return docs, nil
+	}
+	return filterNamespaces(ctx, newNamespaceAuthorizer(authz, VerbRead, resource).Allows, docs, getNamespace)
+}
+
+// authorizedNamespaces returns the namespaces of the documents in the
+// collection, that match the filter, which the subject may read. It is used to
+// restrict an aggregation, whose results combine documents from several
+// namespaces and can't be filtered afterwards. A nil slice is returned when
+// an authorizer is not set, and every namespace may be read.
+func authorizedNamespaces(ctx context.Context, store Store, authz Authorizer, collection string, filter bson.M) ([]string, error) {
+	if authz == nil {
+		return nil, nil
+	}
+
+	var docs []struct {
+		Namespace string `json:"namespace"`
+	}
+	opts := FindOptions{Filter: filter, Select: bson.D{{Key: "namespace", Value: 1}}}
+	if err := store.Find(ctx, collection, opts, &docs); err != nil {
+		return nil, err
+	}
+
+	a := newNamespaceAuthorizer(authz, VerbRead, collection)
+	namespaces := []string{}
+	seen := make(map[string]bool)
+	for _, doc := range docs {
+		if seen[doc.Namespace] {
+			continue
+		}
+		seen[doc.Namespace] = true
+
+		ok, err := a.Allows(ctx, doc.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			namespaces = append(namespaces, doc.Namespace)
+		}
+	}
+	return namespaces, nil
+}
+
+var _ Authorizer = ChainAuthorizer{}
+
+// ChainAuthorizer allows a request only when every authorizer in the chain
+// allows it, for example the rules in the configuration and an
+// authorization plugin. The authorizers are called in order, and the first
+// denial is returned.
+type ChainAuthorizer []Authorizer
+
+func (c ChainAuthorizer) Authorize(ctx context.Context, req AuthorizationRequest) error {
+	for _, authz := range c {
+		if err := authz.Authorize(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ Authorizer = ConfigAuthorizer{}
+
+// ConfigAuthorizer authorizes requests with the rules in the authorization
+// section of Porter's configuration. Every request is allowed when no rules
+// are defined.
+type ConfigAuthorizer struct {
+	config *config.Config
+}
+
+// NewConfigAuthorizer creates an authorizer that enforces the authorization
+// rules in the configuration. The rules are read each time a request is
+// authorized, so that they reflect the loaded configuration.
+func NewConfigAuthorizer(c *config.Config) ConfigAuthorizer {
+	return ConfigAuthorizer{config: c}
+}
+
+func (a ConfigAuthorizer) Authorize(ctx context.Context, req AuthorizationRequest) error {
+	if a.config == nil || len(a.config.Data.Authorization.Rules) == 0 {
+		return nil
+	}
+
+	if req.Subject == "" {
+		req.Subject = a.GetSubject()
+	}
+
+	for _, rule := range a.config.Data.Authorization.Rules {
+		if rule.Allows(req.Subject, req.Namespace, string(req.Verb), req.Resource) {
+			return nil
+		}
+	}
+	return failure.PolicyDenied(ErrForbidden{Request: req})
+}
+
+// GetSubject returns the subject that is authorized when a request does not
+// specify one: the configured subject, or the current user.
+func (a ConfigAuthorizer) GetSubject() string {
+	if subject := a.config.Data.Authorization.Subject; subject != "" {
+		return subject
+	}
+	if user := a.config.Getenv("USER"); user != "" {
+		return user
+	}
+	return a.config.Getenv("USERNAME")
+}
@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigAuthorizer_Authorize(t *testing.T) {
+	c := config.NewTestConfig(t)
+	c.Setenv("USER", "sally")
+	c.Data.Authorization.Rules = []config.AuthorizationRule{
+		{Subjects: []string{"sally"}, Namespaces: []string{"dev"}},
+		{Subjects: []string{"*"}, Namespaces: []string{"prod"}, Verbs: []string{"read"}, Resources: []string{"installations", "runs"}},
+		{Subjects: []string{"ci"}, Namespaces: []string{""}},
+	}
+	authz := NewConfigAuthorizer(c.Config)
+
+	testcases := []struct {
+		name    string
+		req     AuthorizationRequest
+		allowed bool
+	}{
+		{name: "default subject", req: AuthorizationRequest{Namespace: "dev", Verb: VerbDelete, Resource: CollectionInstallations}, allowed: true},
+		{name: "other subject", req: AuthorizationRequest{Subject: "bob", Namespace: "dev", Verb: VerbRead, Resource: CollectionInstallations}},
+		{name: "read allowed for everyone", req: AuthorizationRequest{Subject: "bob", Namespace: "prod", Verb: VerbRead, Resource: CollectionRuns}, allowed: true},
+		{name: "write not allowed", req: AuthorizationRequest{Subject: "bob", Namespace: "prod", Verb: VerbWrite, Resource: CollectionRuns}},
+		{name: "resource not allowed", req: AuthorizationRequest{Subject: "bob", Namespace: "prod", Verb: VerbRead, Resource: CollectionCredentials}},
+		{name: "global namespace", req: AuthorizationRequest{Subject: "ci", Namespace: "", Verb: VerbWrite, Resource: CollectionParameters}, allowed: true},
+		{name: "global namespace not allowed", req: AuthorizationRequest{Subject: "sally", Namespace: "", Verb: VerbRead, Resource: CollectionParameters}},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := authz.Authorize(context.Background(), tc.req)
+			if tc.allowed {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.True(t, errors.Is(err, ErrForbidden{}), "expected ErrForbidden, got %T", err)
+			}
+		})
+	}
+
+	t.Run("no rules", func(t *testing.T) {
+		c := config.NewTestConfig(t)
+		err := NewConfigAuthorizer(c.Config).Authorize(context.Background(), AuthorizationRequest{Subject: "bob", Namespace: "prod", Verb: VerbDelete, Resource: CollectionInstallations})
+		require.NoError(t, err, "every request should be allowed when no rules are defined")
+	})
+}
+
+func TestConfigAuthorizer_GetSubject(t *testing.T) {
+	c := config.NewTestConfig(t)
+	c.Setenv("USER", "sally")
+	authz := NewConfigAuthorizer(c.Config)
+	assert.Equal(t, "sally", authz.GetSubject())
+
+	c.Data.Authorization.Subject = "ci"
+	assert.Equal(t, "ci", authz.GetSubject(), "the configured subject should take precedence")
+}
+
+func TestInstallationStore_Authorizer(t *testing.T) {
+	ctx := ContextWithSubject(context.Background(), "sally")
+	c := config.NewTestConfig(t)
+	c.Data.Authorization.Rules = []config.AuthorizationRule{
+		{Subjects: []string{"sally"}, Namespaces: []string{"dev"}},
+		{Subjects: []string{"admin"}},
+	}
+
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	// Create the test data as an admin
+	adminCtx := ContextWithSubject(context.Background(), "admin")
+	cp.SetAuthorizer(NewConfigAuthorizer(c.Config))
+	dev := NewInstallation("dev", "mysql")
+	prod := NewInstallation("prod", "mysql")
+	require.NoError(t, cp.InsertInstallation(adminCtx, dev))
+	require.NoError(t, cp.InsertInstallation(adminCtx, prod))
+	prodRun := prod.NewRun(cnab.ActionInstall)
+	require.NoError(t, cp.InsertRun(adminCtx, prodRun))
+
+	t.Run("list filters namespaces", func(t *testing.T) {
+		installations, err := cp.ListInstallations(ctx, ListOptions{Namespace: "*"})
+		require.NoError(t, err)
+		require.Len(t, installations, 1)
+		assert.Equal(t, "dev", installations[0].Namespace)
+	})
+
+	t.Run("get denied", func(t *testing.T) {
+		_, err := cp.GetInstallation(ctx, "prod", "mysql")
+		require.ErrorIs(t, err, ErrForbidden{})
+		assert.Contains(t, err.Error(), `access denied: sally is not allowed to read installations in namespace "prod"`)
+
+		_, err = cp.GetRun(ctx, prodRun.ID)
+		require.ErrorIs(t, err, ErrNotFound{}, "runs retrieved by id should be reported as not found, so that their existence is not revealed")
+		assert.NotErrorIs(t, err, ErrForbidden{})
+
+		err = cp.UpdateRunHeartbeat(ctx, prodRun.ID, prodRun.Created)
+		require.ErrorIs(t, err, ErrNotFound{})
+
+		_, err = cp.ListStepResults(ctx, prodRun.ID)
+		require.NoError(t, err)
+
+		_, err = cp.ListSnapshots(ctx, "prod", "mysql")
+		require.ErrorIs(t, err, ErrForbidden{})
+
+		_, err = cp.FindOutputs(ctx, FindOutputsOptions{Name: "connstr", Namespace: "prod"})
+		require.ErrorIs(t, err, ErrForbidden{})
+
+		_, err = cp.VerifyLedger(ctx, "prod", "mysql")
+		require.ErrorIs(t, err, ErrForbidden{})
+	})
+
+	t.Run("maintenance denied", func(t *testing.T) {
+		_, err := cp.Fsck(ctx, FsckOptions{Namespace: "prod"})
+		require.ErrorIs(t, err, ErrForbidden{})
+
+		_, err = cp.MigrateRuns(ctx, MigrateRunsOptions{Namespace: "prod"})
+		require.ErrorIs(t, err, ErrForbidden{})
+
+		_, err = cp.AcquireInstallationLock(ctx, "prod", "mysql", "sally", time.Minute)
+		require.ErrorIs(t, err, ErrForbidden{})
+	})
+
+	t.Run("all namespaces filtered", func(t *testing.T) {
+		summary, err := cp.SummarizeInstallationsByBundle(ctx, ListOptions{Namespace: "*"})
+		require.NoError(t, err)
+		require.Len(t, summary, 1)
+		assert.Equal(t, 1, summary[0].Count, "only the installations in the allowed namespace should be counted")
+
+		report, err := cp.Fsck(ctx, FsckOptions{Namespace: "*"})
+		require.NoError(t, err)
+		assert.Empty(t, report.Problems)
+	})
+
+	t.Run("write denied", func(t *testing.T) {
+		err := cp.InsertInstallation(ctx, NewInstallation("prod", "redis"))
+		require.ErrorIs(t, err, ErrForbidden{})
+
+		err = cp.RemoveInstallation(ctx, "prod", "mysql")
+		require.ErrorIs(t, err, ErrForbidden{})
+	})
+
+	t.Run("allowed namespace", func(t *testing.T) {
+		_, err := cp.GetInstallation(ctx, "dev", "mysql")
+		require.NoError(t, err)
+		require.NoError(t, cp.InsertRun(ctx, dev.NewRun(cnab.ActionUpgrade)))
+	})
+}
+
+func TestCredentialStore_Authorizer(t *testing.T) {
+	ctx := ContextWithSubject(context.Background(), "sally")
+	c := config.NewTestConfig(t)
+	c.Data.Authorization.Rules = []config.AuthorizationRule{
+		{Subjects: []string{"sally"}, Namespaces: []string{"dev"}},
+	}
+
+	cp := NewTestCredentialProvider(t)
+	defer cp.Close()
+	cp.SetAuthorizer(NewConfigAuthorizer(c.Config))
+
+	require.NoError(t, cp.InsertCredentialSet(ctx, NewCredentialSet("dev", "mycreds", secrets.Strategy{Name: "password", Source: secrets.Source{Key: "env", Value: "PASSWORD"}})))
+	err := cp.InsertCredentialSet(ctx, NewCredentialSet("prod", "mycreds"))
+	require.ErrorIs(t, err, ErrForbidden{})
+
+	_, err = cp.GetCredentialSet(ctx, "prod", "mycreds")
+	require.ErrorIs(t, err, ErrForbidden{})
+
+	sets, err := cp.ListCredentialSets(ctx, ListOptions{Namespace: "*"})
+	require.NoError(t, err)
+	require.Len(t, sets, 1)
+	assert.Equal(t, "dev", sets[0].Namespace)
+}
+
+func TestChainAuthorizer_Authorize(t *testing.T) {
+	ctx := context.Background()
+	req := AuthorizationRequest{Subject: "sally", Namespace: "prod", Verb: VerbRead, Resource: CollectionInstallations}
+
+	allow := testAuthorizer(func(req AuthorizationRequest) error { return nil })
+	deny := testAuthorizer(func(req AuthorizationRequest) error { return ErrForbidden{Request: req} })
+
+	require.NoError(t, ChainAuthorizer{allow, allow}.Authorize(ctx, req))
+
+	err := ChainAuthorizer{allow, deny}.Authorize(ctx, req)
+	require.ErrorIs(t, err, ErrForbidden{}, "a denial from any authorizer should deny the request")
+}
+
+type testAuthorizer func(req AuthorizationRequest) error
+
+func (a testAuthorizer) Authorize(ctx context.Context, req AuthorizationRequest) error {
+	return a(req)
+}
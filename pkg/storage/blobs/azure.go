@@ -0,0 +1,269 @@
+package blobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+var _ Store = &AzureStore{}
+
+// azureAPIVersion is the version of the Blob service REST API used by Porter.
+const azureAPIVersion = "2020-10-02"
+
+// azureStorageScope is the scope of the tokens used to access Azure Storage.
+const azureStorageScope = "https://storage.azure.com/.default"
+
+// AzureStore saves blobs in an Azure Blob Storage container. Requests are
+// authorized with the account key, a shared access signature, or a token
+// from the default credential chain of the Azure SDK.
+type AzureStore struct {
+	cfg        config.AzureBlobStoreConfig
+	accountKey []byte
+	credential azcore.TokenCredential
+	client     *http.Client
+
+	// now returns the time used to sign requests.
+	now func() time.Time
+}
+
+// NewAzureStore creates a blob store for the configured container. The
+// account key and shared access signature that are not configured are read
+// from the AZURE_STORAGE_KEY and AZURE_STORAGE_SAS_TOKEN environment variables.
+// When neither is set, requests are authorized with the default credential
+// chain of the Azure SDK: a service principal or workload identity from the
+// environment, a managed identity, or the Azure CLI.
+func NewAzureStore(c *config.Config, cfg config.AzureBlobStoreConfig) (*AzureStore, error) {
+	if cfg.AccountKey == "" && cfg.SASToken == "" {
+		cfg.AccountKey = c.Getenv("AZURE_STORAGE_KEY")
+		cfg.SASToken = c.Getenv("AZURE_STORAGE_SAS_TOKEN")
+	}
+
+	if cfg.Account == "" {
+		return nil, errors.New("invalid output-storage.blob-store.azure configuration: account is required")
+	}
+	if cfg.Container == "" {
+		return nil, errors.New("invalid output-storage.blob-store.azure configuration: container is required")
+	}
+
+	s := &AzureStore{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 5 * time.Minute},
+		now:    time.Now,
+	}
+
+	switch {
+	case cfg.SASToken != "":
+		s.cfg.SASToken = strings.TrimPrefix(cfg.SASToken, "?")
+	case cfg.AccountKey != "":
+		key, err := base64.StdEncoding.DecodeString(cfg.AccountKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output-storage.blob-store.azure configuration: the account-key is not base64 encoded: %w", err)
+		}
+		s.accountKey = key
+	default:
+		credential, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output-storage.blob-store.azure configuration: set account-key or sas-token, or configure the Azure SDK credential chain: %w", err)
+		}
+		s.credential = credential
+	}
+
+	return s, nil
+}
+
+func (s *AzureStore) Type() string {
+	return config.BlobStoreAzure
+}
+
+// blobURL returns the URL of the blob for the key.
+func (s *AzureStore) blobURL(key string) (*url.URL, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	endpoint := s.cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", s.cfg.Account)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output-storage.blob-store.azure.endpoint %q: %w", s.cfg.Endpoint, err)
+	}
+	u.Path = path.Join("/", u.Path, s.cfg.Container, s.cfg.Prefix, key)
+	if s.cfg.SASToken != "" {
+		u.RawQuery = s.cfg.SASToken
+	}
+	return u, nil
+}
+
+// Put uploads the value as a block blob in a single request. The value is
+// streamed when its size is known, otherwise it is read into memory first.
+func (s *AzureStore) Put(ctx context.Context, key string, value io.Reader, size int64) error {
+	if size < 0 {
+		data, err := readValue(value, size)
+		if err != nil {
+			return err
+		}
+		value = bytes.NewReader(data)
+		size = int64(len(data))
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, key, value, size)
+	if err != nil {
+		return fmt.Errorf("error uploading blob %s to azure container %s: %w", key, s.cfg.Container, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *AzureStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading blob %s from azure container %s: %w", key, s.cfg.Container, err)
+	}
+	return resp.Body, nil
+}
+
+func (s *AzureStore) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting blob %s from azure container %s: %w", key, s.cfg.Container, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends an authorized request for the blob, returning an error when the
+// response is not successful.
+func (s *AzureStore) do(ctx context.Context, method string, key string, body io.Reader, size int64) (*http.Response, error) {
+	u, err := s.blobURL(key)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	if body == nil || size == 0 {
+		req.Body = http.NoBody
+	}
+	req.Header.Set("x-ms-date", s.now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	if method == http.MethodPut {
+		req.Header.Set("x-ms-blob-type", "BlockBlob")
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	switch {
+	case s.accountKey != nil:
+		s.sign(req)
+	case s.credential != nil:
+		token, err := s.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{azureStorageScope}})
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve an Azure access token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, u.Path)
+	}
+	return nil, fmt.Errorf("%s %s: %s %s", method, u.Path, resp.Status, strings.TrimSpace(string(msg)))
+}
+
+// sign adds the Shared Key authorization header to the request.
+// See https://learn.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key
+func (s *AzureStore) sign(req *http.Request) {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, x-ms-date is used instead
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		azureCanonicalHeaders(req.Header) + azureCanonicalResource(s.cfg.Account, req.URL),
+	}, "\n")
+
+	h := hmac.New(sha256.New, s.accountKey)
+	h.Write([]byte(stringToSign))
+	signature := base64.StdEncoding.EncodeToString(h.Sum(nil))
+	req.Header.Set("Authorization", fmt.Sprintf("SharedKey %s:%s", s.cfg.Account, signature))
+}
+
+func azureCanonicalHeaders(header http.Header) string {
+	var names []string
+	for name := range header {
+		if strings.HasPrefix(strings.ToLower(name), "x-ms-") {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return strings.ToLower(names[i]) < strings.ToLower(names[j])
+	})
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(strings.ToLower(name) + ":" + strings.TrimSpace(strings.Join(header.Values(name), ",")) + "\n")
+	}
+	return b.String()
+}
+
+func azureCanonicalResource(account string, u *url.URL) string {
+	resource := "/" + account + u.EscapedPath()
+
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		resource += "\n" + strings.ToLower(key) + ":" + strings.Join(values, ",")
+	}
+	return resource
+}
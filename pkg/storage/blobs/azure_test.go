@@ -0,0 +1,153 @@
+package blobs
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzureStore(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	blobs := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey devstoreaccount1:") || r.Header.Get("x-ms-version") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPut:
+			if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(r.Body)
+			blobs[r.URL.Path] = string(data)
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			value, ok := blobs[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			io.WriteString(w, value)
+		case http.MethodDelete:
+			if _, ok := blobs[r.URL.Path]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			delete(blobs, r.URL.Path)
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	s, err := NewAzureStore(c.Config, config.AzureBlobStoreConfig{
+		Account:    "devstoreaccount1",
+		Container:  "porter",
+		Endpoint:   server.URL + "/devstoreaccount1",
+		AccountKey: base64.StdEncoding.EncodeToString([]byte("mykey")),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Put(ctx, "dev/mysql/abc123/kubeconfig", strings.NewReader("big value"), -1))
+	assert.Equal(t, map[string]string{"/devstoreaccount1/porter/dev/mysql/abc123/kubeconfig": "big value"}, blobs)
+
+	r, err := s.Open(ctx, "dev/mysql/abc123/kubeconfig")
+	require.NoError(t, err)
+	value, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "big value", string(value))
+
+	require.NoError(t, s.Delete(ctx, "dev/mysql/abc123/kubeconfig"))
+	require.NoError(t, s.Delete(ctx, "dev/mysql/abc123/kubeconfig"), "deleting a missing blob should not be an error")
+	_, err = s.Open(ctx, "dev/mysql/abc123/kubeconfig")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+// staticTokenCredential returns the same access token for every request.
+type staticTokenCredential string
+
+func (c staticTokenCredential) GetToken(ctx context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	if len(opts.Scopes) != 1 || opts.Scopes[0] != azureStorageScope {
+		return azcore.AccessToken{}, fmt.Errorf("unexpected scopes %v", opts.Scopes)
+	}
+	return azcore.AccessToken{Token: string(c), ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestAzureStore_TokenCredential(t *testing.T) {
+	t.Parallel()
+
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	s := &AzureStore{
+		cfg:        config.AzureBlobStoreConfig{Account: "myaccount", Container: "porter", Endpoint: server.URL},
+		credential: staticTokenCredential("mytoken"),
+		client:     server.Client(),
+		now:        time.Now,
+	}
+	require.NoError(t, s.Put(context.Background(), "dev/mysql/abc123/kubeconfig", strings.NewReader("big value"), 9))
+	assert.Equal(t, "Bearer mytoken", gotAuth)
+}
+
+func TestAzureStore_sign(t *testing.T) {
+	t.Parallel()
+
+	req, err := http.NewRequest(http.MethodGet, "https://myaccount.blob.core.windows.net/porter/dev/mysql?comp=metadata", nil)
+	require.NoError(t, err)
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	req.Header.Set("X-Ms-Date", "Fri, 26 Jun 2015 23:39:12 GMT")
+
+	assert.Equal(t, "x-ms-date:Fri, 26 Jun 2015 23:39:12 GMT\nx-ms-version:2020-10-02\n", azureCanonicalHeaders(req.Header))
+	assert.Equal(t, "/myaccount/porter/dev/mysql\ncomp:metadata", azureCanonicalResource("myaccount", req.URL))
+}
+
+func TestNewAzureStore_Validate(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	c.Setenv("AZURE_STORAGE_KEY", "")
+	c.Setenv("AZURE_STORAGE_SAS_TOKEN", "")
+
+	_, err := NewAzureStore(c.Config, config.AzureBlobStoreConfig{Container: "porter"})
+	require.ErrorContains(t, err, "account is required")
+
+	s, err := NewAzureStore(c.Config, config.AzureBlobStoreConfig{Account: "myaccount", Container: "porter"})
+	require.NoError(t, err)
+	assert.NotNil(t, s.credential, "the Azure SDK credential chain should be used when a key is not set")
+
+	_, err = NewAzureStore(c.Config, config.AzureBlobStoreConfig{Account: "myaccount", Container: "porter", AccountKey: "not base64!"})
+	require.ErrorContains(t, err, "the account-key is not base64 encoded")
+
+	s, err = NewAzureStore(c.Config, config.AzureBlobStoreConfig{Account: "myaccount", Container: "porter", SASToken: "?sv=2020-10-02&sig=abc"})
+	require.NoError(t, err)
+	u, err := s.blobURL("dev/mysql/abc123/kubeconfig")
+	require.NoError(t, err)
+	assert.Equal(t, "https://myaccount.blob.core.windows.net/porter/dev/mysql/abc123/kubeconfig?sv=2020-10-02&sig=abc", u.String())
+}
@@ -0,0 +1,4 @@
+// Package blobs saves the values of outputs that are too large for Porter's
+// database to a blob store: a directory, an Amazon S3 bucket or an Azure Blob
+// Storage container.
+package blobs
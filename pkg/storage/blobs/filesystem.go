@@ -0,0 +1,105 @@
+package blobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/config"
+)
+
+var _ Store = &FilesystemStore{}
+
+// FilesystemStore saves blobs as files in a directory.
+type FilesystemStore struct {
+	config *config.Config
+	root   string
+}
+
+// NewFilesystemStore creates a blob store in the configured directory, which
+// defaults to PORTER_HOME/outputs.
+func NewFilesystemStore(c *config.Config, cfg config.FilesystemBlobStoreConfig) (*FilesystemStore, error) {
+	root := cfg.Path
+	if root == "" {
+		home, err := c.GetHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		root = filepath.Join(home, "outputs")
+	}
+	return &FilesystemStore{config: c, root: root}, nil
+}
+
+func (s *FilesystemStore) Type() string {
+	return config.BlobStoreFilesystem
+}
+
+func (s *FilesystemStore) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes the value to a temporary file that is renamed once it is
+// complete, so that an interrupted write does not leave a partial blob.
+func (s *FilesystemStore) Put(ctx context.Context, key string, value io.Reader, size int64) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = s.config.FileSystem.MkdirAll(filepath.Dir(path), pkg.FileModeDirectory); err != nil {
+		return fmt.Errorf("error creating the directory for blob %s: %w", key, err)
+	}
+
+	tmpPath := path + ".tmp"
+	f, err := s.config.FileSystem.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, pkg.FileModeWritable)
+	if err != nil {
+		return fmt.Errorf("error creating blob %s: %w", key, err)
+	}
+
+	_, err = io.Copy(f, value)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = s.config.FileSystem.Rename(tmpPath, path)
+	}
+	if err != nil {
+		s.config.FileSystem.Remove(tmpPath)
+		return fmt.Errorf("error writing blob %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *FilesystemStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := s.config.FileSystem.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+	} else if err != nil {
+		return nil, fmt.Errorf("error opening blob %s: %w", key, err)
+	}
+	return f, nil
+}
+
+func (s *FilesystemStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = s.config.FileSystem.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error deleting blob %s: %w", key, err)
+	}
+	return nil
+}
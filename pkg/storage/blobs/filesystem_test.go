@@ -0,0 +1,40 @@
+package blobs
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"get.porter.sh/porter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesystemStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	c.SetHomeDir("/home/myuser/.porter")
+
+	s, err := NewFilesystemStore(c.Config, config.FilesystemBlobStoreConfig{})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Put(ctx, "dev/mysql/abc123/kubeconfig", strings.NewReader("big value"), -1))
+	exists, _ := c.FileSystem.Exists("/home/myuser/.porter/outputs/dev/mysql/abc123/kubeconfig")
+	assert.True(t, exists, "the blob should be saved in PORTER_HOME/outputs")
+
+	r, err := s.Open(ctx, "dev/mysql/abc123/kubeconfig")
+	require.NoError(t, err)
+	value, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "big value", string(value))
+
+	require.NoError(t, s.Delete(ctx, "dev/mysql/abc123/kubeconfig"))
+	require.NoError(t, s.Delete(ctx, "dev/mysql/abc123/kubeconfig"), "deleting a missing blob should not be an error")
+
+	_, err = s.Open(ctx, "dev/mysql/abc123/kubeconfig")
+	require.ErrorIs(t, err, ErrNotFound)
+}
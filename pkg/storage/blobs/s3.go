@@ -0,0 +1,187 @@
+package blobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/cloud/sigv4"
+	"get.porter.sh/porter/pkg/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+var _ Store = &S3Store{}
+
+// S3Store saves blobs as objects in an Amazon S3, or S3 compatible, bucket.
+// Requests are signed with AWS Signature Version 4.
+type S3Store struct {
+	cfg    config.S3BlobStoreConfig
+	creds  aws.CredentialsProvider
+	client *http.Client
+
+	// now returns the time used to sign requests.
+	now func() time.Time
+}
+
+// NewS3Store creates a blob store for the configured bucket. When credentials
+// are not configured, they are resolved with the default credential chain of
+// the AWS SDK: environment variables, the shared configuration and credentials
+// files, web identity, and the role of the container or instance. The region
+// defaults to AWS_REGION, or the region in the shared configuration.
+func NewS3Store(c *config.Config, cfg config.S3BlobStoreConfig) (*S3Store, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("invalid output-storage.blob-store.s3 configuration: bucket is required")
+	}
+
+	if cfg.Region == "" {
+		cfg.Region = c.Getenv("AWS_REGION")
+	}
+	if cfg.Region == "" {
+		cfg.Region = c.Getenv("AWS_DEFAULT_REGION")
+	}
+
+	var opts []func(*awsconfig.LoadOptions) error
+	if cfg.Region != "" {
+		opts = append(opts, awsconfig.WithRegion(cfg.Region))
+	}
+	if cfg.AccessKeyID != "" {
+		creds := credentials.NewStaticCredentialsProvider(cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken)
+		opts = append(opts, awsconfig.WithCredentialsProvider(creds))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output-storage.blob-store.s3 configuration: could not load the AWS configuration: %w", err)
+	}
+
+	cfg.Region = awsCfg.Region
+	if cfg.Region == "" {
+		return nil, errors.New("invalid output-storage.blob-store.s3 configuration: region is required, set it in the configuration, with AWS_REGION, or in the AWS shared configuration")
+	}
+
+	return &S3Store{
+		cfg:    cfg,
+		creds:  awsCfg.Credentials,
+		client: &http.Client{Timeout: 5 * time.Minute},
+		now:    time.Now,
+	}, nil
+}
+
+func (s *S3Store) Type() string {
+	return config.BlobStoreS3
+}
+
+// objectURL returns the URL of the object for the key. Buckets are addressed
+// by path when a custom endpoint is configured, and by virtual host otherwise.
+func (s *S3Store) objectURL(key string) (*url.URL, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	key = path.Join(s.cfg.Prefix, key)
+
+	if s.cfg.Endpoint == "" {
+		return url.Parse(fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.cfg.Bucket, s.cfg.Region, key))
+	}
+
+	u, err := url.Parse(s.cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output-storage.blob-store.s3.endpoint %q: %w", s.cfg.Endpoint, err)
+	}
+	u.Path = path.Join("/", u.Path, s.cfg.Bucket, key)
+	return u, nil
+}
+
+// Put uploads the value in a single request. S3 requires the size of the
+// value, and the value is hashed for the signature, so it is read into memory.
+func (s *S3Store) Put(ctx context.Context, key string, value io.Reader, size int64) error {
+	data, err := readValue(value, size)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return fmt.Errorf("error uploading blob %s to s3 bucket %s: %w", key, s.cfg.Bucket, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Store) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading blob %s from s3 bucket %s: %w", key, s.cfg.Bucket, err)
+	}
+	return resp.Body, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting blob %s from s3 bucket %s: %w", key, s.cfg.Bucket, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends a signed request for the object, returning an error when the
+// response is not successful.
+func (s *S3Store) do(ctx context.Context, method string, key string, body []byte) (*http.Response, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if err = s.sign(req, body); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, u.Path)
+	}
+	return nil, fmt.Errorf("%s %s: %s %s", method, u.Path, resp.Status, strings.TrimSpace(string(msg)))
+}
+
+// sign adds the AWS Signature Version 4 authorization header to the request.
+func (s *S3Store) sign(req *http.Request, body []byte) error {
+	if s.creds == nil {
+		return errors.New("no AWS credentials were found, configure them with the AWS SDK credential chain or in the output-storage.blob-store.s3 configuration")
+	}
+	awsCreds, err := s.creds.Retrieve(req.Context())
+	if err != nil {
+		return fmt.Errorf("could not retrieve the AWS credentials: %w", err)
+	}
+
+	creds := sigv4.Credentials{
+		AccessKeyID:     awsCreds.AccessKeyID,
+		SecretAccessKey: awsCreds.SecretAccessKey,
+		SessionToken:    awsCreds.SessionToken,
+	}
+	sigv4.SignRequest(req, body, creds, s.cfg.Region, "s3", s.now())
+	return nil
+}
@@ -0,0 +1,78 @@
+package blobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"get.porter.sh/porter/pkg/config"
+)
+
+// ErrNotFound is returned when a blob does not exist in the blob store.
+var ErrNotFound = errors.New("blob not found")
+
+// Store saves blobs, identified by a key, to a blob store.
+type Store interface {
+	// Type of the blob store, for example s3.
+	Type() string
+
+	// Put saves the value of the blob, replacing the blob when it exists.
+	// The size is the number of bytes in the value, or -1 when it is not known.
+	Put(ctx context.Context, key string, value io.Reader, size int64) error
+
+	// Open returns a reader for the value of a blob, or ErrNotFound.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes a blob. Deleting a blob that does not exist is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// New creates the blob store selected by the output-storage configuration.
+func New(c *config.Config) (Store, error) {
+	cfg := c.Data.OutputStorage.BlobStore
+	switch cfg.GetType() {
+	case config.BlobStoreFilesystem:
+		return NewFilesystemStore(c, cfg.Filesystem)
+	case config.BlobStoreS3:
+		return NewS3Store(c, cfg.S3)
+	case config.BlobStoreAzure:
+		return NewAzureStore(c, cfg.Azure)
+	default:
+		return nil, fmt.Errorf("invalid output-storage.blob-store.type %q, allowed values are: %s, %s, %s",
+			cfg.Type, config.BlobStoreFilesystem, config.BlobStoreS3, config.BlobStoreAzure)
+	}
+}
+
+// validateKey ensures that a key cannot escape the location of the blob
+// store, for example with a .. segment.
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
+
+// readValue reads the entire value of a blob, for blob stores that must know
+// the size, or the hash, of the value before it is sent.
+func readValue(value io.Reader, size int64) ([]byte, error) {
+	if size >= 0 {
+		data := make([]byte, size)
+		if _, err := io.ReadFull(value, data); err != nil {
+			return nil, fmt.Errorf("error reading the value of the blob: %w", err)
+		}
+		return data, nil
+	}
+
+	data, err := io.ReadAll(value)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the value of the blob: %w", err)
+	}
+	return data, nil
+}
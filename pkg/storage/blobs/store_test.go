@@ -0,0 +1,33 @@
+package blobs
+
+import (
+	"testing"
+
+	"get.porter.sh/porter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	c.SetHomeDir("/home/myuser/.porter")
+
+	store, err := New(c.Config)
+	require.NoError(t, err)
+	assert.Equal(t, config.BlobStoreFilesystem, store.Type(), "the filesystem blob store should be the default")
+
+	c.Data.OutputStorage.BlobStore.Type = "ftp"
+	_, err = New(c.Config)
+	require.EqualError(t, err, `invalid output-storage.blob-store.type "ftp", allowed values are: filesystem, s3, azure`)
+}
+
+func TestValidateKey(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, validateKey("dev/mysql/01G4AF3X9HBAW9YM1XW7AQ8M8K/kubeconfig"))
+	for _, key := range []string{"", "/etc/passwd", "dev/../../etc/passwd", "dev//mysql", "./mysql"} {
+		assert.Error(t, validateKey(key), "expected %q to be invalid", key)
+	}
+}
@@ -0,0 +1,66 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+// StoreFactory creates an empty store for a test. The suite closes the store
+// when the test completes.
+type StoreFactory func(t *testing.T) storage.Store
+
+// Run executes the conformance suite against the stores created by newStore.
+// Each test uses a new store, so that the tests do not affect each other.
+func Run(t *testing.T, newStore StoreFactory) {
+	t.Run("documents", func(t *testing.T) {
+		t.Run("get missing document", func(t *testing.T) { testGetMissingDocument(t, newStore) })
+		t.Run("find", func(t *testing.T) { testFind(t, newStore) })
+		t.Run("count", func(t *testing.T) { testCount(t, newStore) })
+		t.Run("unique index", func(t *testing.T) { testUniqueIndex(t, newStore) })
+		t.Run("update", func(t *testing.T) { testUpdate(t, newStore) })
+		t.Run("patch", func(t *testing.T) { testPatch(t, newStore) })
+		t.Run("remove", func(t *testing.T) { testRemove(t, newStore) })
+	})
+	t.Run("installations", func(t *testing.T) {
+		t.Run("crud", func(t *testing.T) { testInstallations(t, newStore) })
+		t.Run("list", func(t *testing.T) { testListInstallations(t, newStore) })
+	})
+	t.Run("claims", func(t *testing.T) {
+		t.Run("runs", func(t *testing.T) { testRuns(t, newStore) })
+		t.Run("results", func(t *testing.T) { testResults(t, newStore) })
+	})
+	t.Run("outputs", func(t *testing.T) {
+		t.Run("list", func(t *testing.T) { testListOutputs(t, newStore) })
+		t.Run("last outputs", func(t *testing.T) { testLastOutputs(t, newStore) })
+	})
+	t.Run("parameter sets", func(t *testing.T) { testParameterSets(t, newStore) })
+	t.Run("credential sets", func(t *testing.T) { testCredentialSets(t, newStore) })
+}
+
+// setupStore creates a store for a test with the indices that Porter creates
+// when it connects to the storage backend.
+func setupStore(t *testing.T, newStore StoreFactory) storage.Store {
+	store := newStore(t)
+	t.Cleanup(func() {
+		require.NoError(t, store.Close(), "Close failed")
+	})
+
+	ctx := context.Background()
+	require.NoError(t, storage.EnsureInstallationIndices(ctx, store), "could not create the installation indices")
+	require.NoError(t, storage.EnsureCredentialIndices(ctx, store), "could not create the credential set indices")
+	require.NoError(t, storage.EnsureParameterIndices(ctx, store), "could not create the parameter set indices")
+	return store
+}
+
+// setupSecrets creates a secret store for the parameter and credential set tests.
+func setupSecrets(t *testing.T) secrets.Store {
+	s := secrets.NewTestSecretsProvider()
+	t.Cleanup(func() {
+		require.NoError(t, s.Close())
+	})
+	return s
+}
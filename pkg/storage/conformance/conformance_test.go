@@ -0,0 +1,23 @@
+package conformance
+
+import (
+	"path/filepath"
+	"testing"
+
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/storage/plugins/embedded"
+	"get.porter.sh/porter/pkg/storage/plugins/inmemory"
+)
+
+func TestInMemoryPlugin(t *testing.T) {
+	Run(t, func(t *testing.T) storage.Store {
+		return storage.NewPluginAdapter(inmemory.NewStore())
+	})
+}
+
+func TestEmbeddedPlugin(t *testing.T) {
+	Run(t, func(t *testing.T) storage.Store {
+		path := filepath.Join(t.TempDir(), embedded.DefaultDatabaseFile)
+		return storage.NewPluginAdapter(embedded.NewStore(embedded.PluginConfig{Path: path}))
+	})
+}
@@ -0,0 +1,15 @@
+// Package conformance is a test suite that verifies a storage backend behaves
+// the way that Porter expects, for example that documents are filtered and
+// sorted like mongodb, that unique indices are enforced, and that missing
+// documents are reported with storage.ErrNotFound.
+//
+// Authors of storage plugins can run the suite from a test in their plugin,
+// wrapping their implementation of plugins.StorageProtocol with
+// storage.NewPluginAdapter:
+//
+//	func TestConformance(t *testing.T) {
+//		conformance.Run(t, func(t *testing.T) storage.Store {
+//			return storage.NewPluginAdapter(myplugin.NewStore(t.TempDir()))
+//		})
+//	}
+package conformance
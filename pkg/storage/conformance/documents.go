@@ -0,0 +1,228 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// testCollection is the collection used by the tests of basic document operations.
+const testCollection = "conformance"
+
+// testDocument is saved to the test collection.
+type testDocument struct {
+	ID        string            `json:"_id"`
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Count     int               `json:"count"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+func (d testDocument) DefaultDocumentFilter() map[string]interface{} {
+	return map[string]interface{}{"_id": d.ID}
+}
+
+// insertTestDocuments saves documents to the test collection, with a unique
+// index on namespace and name.
+func insertTestDocuments(t *testing.T, store storage.Store) {
+	ctx := context.Background()
+	err := store.EnsureIndex(ctx, storage.EnsureIndexOptions{Indices: []storage.Index{
+		{Collection: testCollection, Keys: []string{"namespace", "name"}, Unique: true},
+	}})
+	require.NoError(t, err, "EnsureIndex failed")
+
+	docs := []interface{}{
+		testDocument{ID: "1", Namespace: "dev", Name: "mysql", Count: 3, Labels: map[string]string{"team": "red"}},
+		testDocument{ID: "2", Namespace: "dev", Name: "redis", Count: 1},
+		testDocument{ID: "3", Namespace: "test", Name: "mysql", Count: 2, Labels: map[string]string{"team": "blue"}},
+		testDocument{ID: "4", Namespace: "", Name: "nginx", Count: 5},
+	}
+	err = store.Insert(ctx, testCollection, storage.InsertOptions{Documents: docs})
+	require.NoError(t, err, "Insert failed")
+}
+
+func findTestDocumentIDs(t *testing.T, store storage.Store, opts storage.FindOptions) []string {
+	var docs []testDocument
+	err := store.Find(context.Background(), testCollection, opts, &docs)
+	require.NoError(t, err, "Find failed")
+
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+	}
+	return ids
+}
+
+func testGetMissingDocument(t *testing.T, newStore StoreFactory) {
+	store := setupStore(t, newStore)
+	insertTestDocuments(t, store)
+	ctx := context.Background()
+
+	var doc testDocument
+	err := store.Get(ctx, testCollection, storage.GetOptions{ID: "missing"}, &doc)
+	assert.True(t, errors.Is(err, storage.ErrNotFound{}), "Get should return storage.ErrNotFound for a missing document, got %v", err)
+
+	err = store.FindOne(ctx, testCollection, storage.FindOptions{Filter: bson.M{"name": "missing"}}, &doc)
+	assert.True(t, errors.Is(err, storage.ErrNotFound{}), "FindOne should return storage.ErrNotFound when no documents match, got %v", err)
+
+	err = store.Get(ctx, "emptycollection", storage.GetOptions{ID: "1"}, &doc)
+	assert.True(t, errors.Is(err, storage.ErrNotFound{}), "Get should return storage.ErrNotFound for a collection that does not exist, got %v", err)
+
+	err = store.Get(ctx, testCollection, storage.GetOptions{Namespace: "dev", Name: "redis"}, &doc)
+	require.NoError(t, err, "Get should find a document by namespace and name")
+	assert.Equal(t, "2", doc.ID)
+}
+
+func testFind(t *testing.T, newStore StoreFactory) {
+	store := setupStore(t, newStore)
+	insertTestDocuments(t, store)
+
+	testcases := []struct {
+		name    string
+		opts    storage.FindOptions
+		wantIDs []string
+	}{
+		{name: "sort ascending", opts: storage.FindOptions{Sort: []string{"count"}}, wantIDs: []string{"2", "3", "1", "4"}},
+		{name: "sort descending", opts: storage.FindOptions{Sort: []string{"-count"}}, wantIDs: []string{"4", "1", "3", "2"}},
+		{name: "sort multiple fields", opts: storage.FindOptions{Sort: []string{"namespace", "-name"}}, wantIDs: []string{"4", "2", "1", "3"}},
+		{name: "filter", opts: storage.FindOptions{Sort: []string{"_id"}, Filter: bson.M{"name": "mysql"}}, wantIDs: []string{"1", "3"}},
+		{name: "filter nested field", opts: storage.FindOptions{Filter: bson.M{"labels.team": "blue"}}, wantIDs: []string{"3"}},
+		{name: "filter operator", opts: storage.FindOptions{Sort: []string{"_id"}, Filter: bson.M{"count": bson.M{"$gte": 3}}}, wantIDs: []string{"1", "4"}},
+		{name: "filter in", opts: storage.FindOptions{Sort: []string{"_id"}, Filter: bson.M{"namespace": bson.M{"$in": []string{"", "test"}}}}, wantIDs: []string{"3", "4"}},
+		{name: "skip and limit", opts: storage.FindOptions{Sort: []string{"_id"}, Skip: 1, Limit: 2}, wantIDs: []string{"2", "3"}},
+		{name: "no match", opts: storage.FindOptions{Filter: bson.M{"name": "missing"}}, wantIDs: []string{}},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantIDs, findTestDocumentIDs(t, store, tc.opts))
+		})
+	}
+
+	t.Run("select", func(t *testing.T) {
+		var docs []testDocument
+		opts := storage.FindOptions{Filter: bson.M{"_id": "1"}, Select: bson.D{{Key: "name", Value: 1}}}
+		err := store.Find(context.Background(), testCollection, opts, &docs)
+		require.NoError(t, err, "Find failed")
+		require.Len(t, docs, 1)
+		assert.Equal(t, testDocument{ID: "1", Name: "mysql"}, docs[0], "only the selected fields and the id should be returned")
+	})
+}
+
+func testCount(t *testing.T, newStore StoreFactory) {
+	store := setupStore(t, newStore)
+	ctx := context.Background()
+
+	count, err := store.Count(ctx, testCollection, storage.CountOptions{})
+	require.NoError(t, err, "Count should not fail for a collection that does not exist")
+	assert.Equal(t, int64(0), count)
+
+	insertTestDocuments(t, store)
+
+	count, err = store.Count(ctx, testCollection, storage.CountOptions{})
+	require.NoError(t, err, "Count failed")
+	assert.Equal(t, int64(4), count)
+
+	count, err = store.Count(ctx, testCollection, storage.CountOptions{Filter: bson.M{"namespace": "dev"}})
+	require.NoError(t, err, "Count failed")
+	assert.Equal(t, int64(2), count)
+}
+
+func testUniqueIndex(t *testing.T, newStore StoreFactory) {
+	store := setupStore(t, newStore)
+	insertTestDocuments(t, store)
+	ctx := context.Background()
+
+	// EnsureIndex must be idempotent, since Porter calls it every time it connects
+	err := store.EnsureIndex(ctx, storage.EnsureIndexOptions{Indices: []storage.Index{
+		{Collection: testCollection, Keys: []string{"namespace", "name"}, Unique: true},
+	}})
+	require.NoError(t, err, "EnsureIndex should succeed when the index already exists")
+
+	dupe := testDocument{ID: "5", Namespace: "dev", Name: "mysql"}
+	err = store.Insert(ctx, testCollection, storage.InsertOptions{Documents: []interface{}{dupe}})
+	assert.True(t, errors.Is(err, storage.ErrConflict{}), "Insert should return storage.ErrConflict when a unique index is violated, got %v", err)
+
+	dupe.ID = "1"
+	dupe.Namespace = "prod"
+	err = store.Insert(ctx, testCollection, storage.InsertOptions{Documents: []interface{}{dupe}})
+	assert.True(t, errors.Is(err, storage.ErrConflict{}), "Insert should return storage.ErrConflict when the id is already used, got %v", err)
+
+	count, err := store.Count(ctx, testCollection, storage.CountOptions{})
+	require.NoError(t, err, "Count failed")
+	assert.Equal(t, int64(4), count, "the conflicting documents should not be saved")
+
+	other := testDocument{ID: "5", Namespace: "prod", Name: "mysql"}
+	err = store.Insert(ctx, testCollection, storage.InsertOptions{Documents: []interface{}{other}})
+	require.NoError(t, err, "Insert should allow the same name in another namespace")
+}
+
+func testUpdate(t *testing.T, newStore StoreFactory) {
+	store := setupStore(t, newStore)
+	insertTestDocuments(t, store)
+	ctx := context.Background()
+
+	updated := testDocument{ID: "2", Namespace: "dev", Name: "redis", Count: 10}
+	err := store.Update(ctx, testCollection, storage.UpdateOptions{Document: updated})
+	require.NoError(t, err, "Update failed")
+
+	var doc testDocument
+	err = store.Get(ctx, testCollection, storage.GetOptions{ID: "2"}, &doc)
+	require.NoError(t, err, "Get failed")
+	assert.Equal(t, updated, doc, "the document should be replaced")
+
+	missing := testDocument{ID: "10", Namespace: "dev", Name: "postgres"}
+	err = store.Update(ctx, testCollection, storage.UpdateOptions{Document: missing})
+	require.NoError(t, err, "Update should not fail when the document does not exist, like mongodb")
+	err = store.Get(ctx, testCollection, storage.GetOptions{ID: "10"}, &doc)
+	assert.True(t, errors.Is(err, storage.ErrNotFound{}), "Update should not insert a missing document unless Upsert is set, got %v", err)
+
+	err = store.Update(ctx, testCollection, storage.UpdateOptions{Document: missing, Upsert: true})
+	require.NoError(t, err, "Update should insert a missing document when Upsert is set")
+	err = store.Get(ctx, testCollection, storage.GetOptions{ID: "10"}, &doc)
+	require.NoError(t, err, "Get failed")
+	assert.Equal(t, missing, doc)
+}
+
+func testPatch(t *testing.T, newStore StoreFactory) {
+	store := setupStore(t, newStore)
+	insertTestDocuments(t, store)
+	ctx := context.Background()
+
+	err := store.Patch(ctx, testCollection, storage.PatchOptions{
+		QueryDocument:  bson.M{"_id": "3"},
+		Transformation: bson.D{{Key: "$set", Value: bson.M{"count": 20, "labels.env": "test"}}},
+	})
+	require.NoError(t, err, "Patch failed")
+
+	var doc testDocument
+	err = store.Get(ctx, testCollection, storage.GetOptions{ID: "3"}, &doc)
+	require.NoError(t, err, "Get failed")
+	assert.Equal(t, 20, doc.Count)
+	assert.Equal(t, map[string]string{"team": "blue", "env": "test"}, doc.Labels, "only the patched fields should change")
+	assert.Equal(t, "mysql", doc.Name)
+}
+
+func testRemove(t *testing.T, newStore StoreFactory) {
+	store := setupStore(t, newStore)
+	insertTestDocuments(t, store)
+	ctx := context.Background()
+
+	err := store.Remove(ctx, testCollection, storage.RemoveOptions{ID: "4"})
+	require.NoError(t, err, "Remove by id failed")
+	assert.Equal(t, []string{"1", "2", "3"}, findTestDocumentIDs(t, store, storage.FindOptions{Sort: []string{"_id"}}))
+
+	err = store.Remove(ctx, testCollection, storage.RemoveOptions{Namespace: "dev", Name: "redis"})
+	require.NoError(t, err, "Remove by namespace and name failed")
+	assert.Equal(t, []string{"1", "3"}, findTestDocumentIDs(t, store, storage.FindOptions{Sort: []string{"_id"}}))
+
+	err = store.Remove(ctx, testCollection, storage.RemoveOptions{Filter: bson.M{"name": "mysql"}, All: true})
+	require.NoError(t, err, "Remove all matching documents failed")
+	assert.Empty(t, findTestDocumentIDs(t, store, storage.FindOptions{}))
+}
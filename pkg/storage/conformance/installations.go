@@ -0,0 +1,146 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInstallations(t *testing.T, newStore StoreFactory) {
+	installations := storage.NewInstallationStore(setupStore(t, newStore))
+	ctx := context.Background()
+
+	inst := storage.NewInstallation("dev", "mysql")
+	inst.Labels = map[string]string{"team": "red"}
+	inst.Status.Created = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	inst.Status.Modified = inst.Status.Created
+	inst.Parameters.Status.Created = inst.Status.Created
+	inst.Parameters.Status.Modified = inst.Status.Created
+	require.NoError(t, installations.InsertInstallation(ctx, inst), "InsertInstallation failed")
+
+	got, err := installations.GetInstallation(ctx, "dev", "mysql")
+	require.NoError(t, err, "GetInstallation failed")
+	assert.Equal(t, inst, got, "the installation should be returned as it was saved")
+
+	err = installations.InsertInstallation(ctx, storage.NewInstallation("dev", "mysql"))
+	assert.True(t, errors.Is(err, storage.ErrConflict{}), "InsertInstallation should return storage.ErrConflict for a duplicate installation, got %v", err)
+
+	inst.Status.RunID = "01FZVC5AVP8Z7A78CSCP1EJ604"
+	inst.Status.Modified = inst.Status.Created.Add(time.Hour)
+	require.NoError(t, installations.UpdateInstallation(ctx, inst), "UpdateInstallation failed")
+	got, err = installations.GetInstallation(ctx, "dev", "mysql")
+	require.NoError(t, err, "GetInstallation failed")
+	assert.Equal(t, inst, got, "the installation should be updated")
+
+	require.NoError(t, installations.RemoveInstallation(ctx, "dev", "mysql"), "RemoveInstallation failed")
+	_, err = installations.GetInstallation(ctx, "dev", "mysql")
+	assert.True(t, errors.Is(err, storage.ErrNotFound{}), "GetInstallation should return storage.ErrNotFound after the installation is removed, got %v", err)
+}
+
+func testListInstallations(t *testing.T, newStore StoreFactory) {
+	installations := storage.NewInstallationStore(setupStore(t, newStore))
+	ctx := context.Background()
+
+	create := func(namespace, name string, labels map[string]string) {
+		inst := storage.NewInstallation(namespace, name)
+		inst.Labels = labels
+		require.NoError(t, installations.InsertInstallation(ctx, inst), "InsertInstallation failed")
+	}
+	create("dev", "redis", map[string]string{"team": "red"})
+	create("dev", "mysql", map[string]string{"team": "blue"})
+	create("test", "mysql", map[string]string{"team": "red"})
+	create("", "nginx", nil)
+
+	names := func(opts storage.ListOptions) []string {
+		list, err := installations.ListInstallations(ctx, opts)
+		require.NoError(t, err, "ListInstallations failed")
+		result := make([]string, len(list))
+		for i, inst := range list {
+			result[i] = inst.String()
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"dev/mysql", "dev/redis"}, names(storage.ListOptions{Namespace: "dev"}), "installations should be filtered by namespace and sorted by name")
+	assert.Equal(t, []string{"/nginx"}, names(storage.ListOptions{}), "the global namespace should only include global installations")
+	assert.Equal(t, []string{"/nginx", "dev/mysql", "dev/redis", "test/mysql"}, names(storage.ListOptions{Namespace: "*"}), "all namespaces should be sorted by namespace and name")
+	assert.Equal(t, []string{"dev/redis", "test/mysql"}, names(storage.ListOptions{Namespace: "*", Labels: map[string]string{"team": "red"}}), "installations should be filtered by label")
+	assert.Equal(t, []string{"dev/mysql", "test/mysql"}, names(storage.ListOptions{Namespace: "*", Name: "sql"}), "installations should be filtered by a substring of the name")
+	assert.Equal(t, []string{"dev/mysql", "dev/redis"}, names(storage.ListOptions{Namespace: "*", Skip: 1, Limit: 2}))
+}
+
+func testRuns(t *testing.T, newStore StoreFactory) {
+	installations := storage.NewInstallationStore(setupStore(t, newStore))
+	ctx := context.Background()
+
+	inst := storage.NewInstallation("dev", "mysql")
+	require.NoError(t, installations.InsertInstallation(ctx, inst), "InsertInstallation failed")
+
+	_, err := installations.GetLastRun(ctx, "dev", "mysql")
+	assert.True(t, errors.Is(err, storage.ErrNotFound{}), "GetLastRun should return storage.ErrNotFound before the installation is run, got %v", err)
+
+	install := inst.NewRun(cnab.ActionInstall)
+	install.Bundle.Name = "mysql"
+	install.Bundle.Version = "1.0.0"
+	require.NoError(t, installations.InsertRun(ctx, install), "InsertRun failed")
+
+	upgrade := inst.NewRun(cnab.ActionUpgrade)
+	require.NoError(t, installations.InsertRun(ctx, upgrade), "InsertRun failed")
+
+	got, err := installations.GetRun(ctx, install.ID)
+	require.NoError(t, err, "GetRun failed")
+	assert.Equal(t, install.ID, got.ID)
+	assert.Equal(t, cnab.ActionInstall, got.Action)
+	assert.Equal(t, "1.0.0", got.Bundle.Version, "the bundle of the run should be saved")
+
+	last, err := installations.GetLastRun(ctx, "dev", "mysql")
+	require.NoError(t, err, "GetLastRun failed")
+	assert.Equal(t, upgrade.ID, last.ID, "the most recent run should be returned")
+
+	runs, _, err := installations.ListRuns(ctx, "dev", "mysql")
+	require.NoError(t, err, "ListRuns failed")
+	require.Len(t, runs, 2)
+	assert.Equal(t, install.ID, runs[0].ID, "runs should be sorted by id, oldest first")
+	assert.Equal(t, upgrade.ID, runs[1].ID)
+
+	_, err = installations.GetRun(ctx, "missing")
+	assert.True(t, errors.Is(err, storage.ErrNotFound{}), "GetRun should return storage.ErrNotFound for a missing run, got %v", err)
+}
+
+func testResults(t *testing.T, newStore StoreFactory) {
+	installations := storage.NewInstallationStore(setupStore(t, newStore))
+	ctx := context.Background()
+
+	inst := storage.NewInstallation("dev", "mysql")
+	require.NoError(t, installations.InsertInstallation(ctx, inst), "InsertInstallation failed")
+	run := inst.NewRun(cnab.ActionInstall)
+	require.NoError(t, installations.InsertRun(ctx, run), "InsertRun failed")
+
+	running := run.NewResult(cnab.StatusRunning)
+	require.NoError(t, installations.InsertResult(ctx, running), "InsertResult failed")
+	succeeded := run.NewResult(cnab.StatusSucceeded)
+	require.NoError(t, installations.InsertResult(ctx, succeeded), "InsertResult failed")
+
+	got, err := installations.GetResult(ctx, succeeded.ID)
+	require.NoError(t, err, "GetResult failed")
+	assert.Equal(t, succeeded.ID, got.ID)
+	assert.Equal(t, run.ID, got.RunID)
+	assert.Equal(t, cnab.StatusSucceeded, got.Status)
+
+	results, err := installations.ListResults(ctx, run.ID)
+	require.NoError(t, err, "ListResults failed")
+	require.Len(t, results, 2)
+	assert.Equal(t, running.ID, results[0].ID, "results should be sorted by id, oldest first")
+	assert.Equal(t, succeeded.ID, results[1].ID)
+
+	runs, resultsByRun, err := installations.ListRuns(ctx, "dev", "mysql")
+	require.NoError(t, err, "ListRuns failed")
+	require.Len(t, runs, 1)
+	assert.Len(t, resultsByRun[run.ID], 2, "ListRuns should return the results of each run")
+}
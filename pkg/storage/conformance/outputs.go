@@ -0,0 +1,84 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testListOutputs(t *testing.T, newStore StoreFactory) {
+	installations := storage.NewInstallationStore(setupStore(t, newStore))
+	ctx := context.Background()
+
+	inst := storage.NewInstallation("dev", "mysql")
+	require.NoError(t, installations.InsertInstallation(ctx, inst), "InsertInstallation failed")
+	run := inst.NewRun(cnab.ActionInstall)
+	require.NoError(t, installations.InsertRun(ctx, run), "InsertRun failed")
+	result := run.NewResult(cnab.StatusSucceeded)
+	require.NoError(t, installations.InsertResult(ctx, result), "InsertResult failed")
+
+	require.NoError(t, installations.InsertOutput(ctx, result.NewOutput("port", []byte("3306"))), "InsertOutput failed")
+	require.NoError(t, installations.InsertOutput(ctx, result.NewOutput("host", []byte("mysql.local"))), "InsertOutput failed")
+
+	err := installations.InsertOutput(ctx, result.NewOutput("port", []byte("3307")))
+	assert.True(t, errors.Is(err, storage.ErrConflict{}), "InsertOutput should return storage.ErrConflict when the result already has the output, got %v", err)
+
+	outputs, err := installations.ListOutputs(ctx, result.ID)
+	require.NoError(t, err, "ListOutputs failed")
+	require.Len(t, outputs, 2)
+	assert.Equal(t, "host", outputs[0].Name, "outputs should be sorted by name")
+	assert.Equal(t, "mysql.local", string(outputs[0].Value))
+	assert.Equal(t, "port", outputs[1].Name)
+	assert.Equal(t, "3306", string(outputs[1].Value))
+}
+
+func testLastOutputs(t *testing.T, newStore StoreFactory) {
+	installations := storage.NewInstallationStore(setupStore(t, newStore))
+	ctx := context.Background()
+
+	inst := storage.NewInstallation("dev", "mysql")
+	require.NoError(t, installations.InsertInstallation(ctx, inst), "InsertInstallation failed")
+
+	_, err := installations.GetLastOutput(ctx, "dev", "mysql", "port")
+	assert.True(t, errors.Is(err, storage.ErrNotFound{}), "GetLastOutput should return storage.ErrNotFound before the output is saved, got %v", err)
+
+	// The install run generates both outputs, and the upgrade only changes the port
+	install := inst.NewRun(cnab.ActionInstall)
+	require.NoError(t, installations.InsertRun(ctx, install), "InsertRun failed")
+	installResult := install.NewResult(cnab.StatusSucceeded)
+	require.NoError(t, installations.InsertResult(ctx, installResult), "InsertResult failed")
+	require.NoError(t, installations.InsertOutput(ctx, installResult.NewOutput("port", []byte("3306"))), "InsertOutput failed")
+	require.NoError(t, installations.InsertOutput(ctx, installResult.NewOutput("host", []byte("mysql.local"))), "InsertOutput failed")
+
+	upgrade := inst.NewRun(cnab.ActionUpgrade)
+	require.NoError(t, installations.InsertRun(ctx, upgrade), "InsertRun failed")
+	upgradeResult := upgrade.NewResult(cnab.StatusSucceeded)
+	require.NoError(t, installations.InsertResult(ctx, upgradeResult), "InsertResult failed")
+	require.NoError(t, installations.InsertOutput(ctx, upgradeResult.NewOutput("port", []byte("3307"))), "InsertOutput failed")
+
+	// Outputs of another installation should not be included
+	other := storage.NewInstallation("test", "mysql")
+	otherRun := other.NewRun(cnab.ActionInstall)
+	otherResult := otherRun.NewResult(cnab.StatusSucceeded)
+	require.NoError(t, installations.InsertOutput(ctx, otherResult.NewOutput("port", []byte("1234"))), "InsertOutput failed")
+
+	port, err := installations.GetLastOutput(ctx, "dev", "mysql", "port")
+	require.NoError(t, err, "GetLastOutput failed")
+	assert.Equal(t, "3307", string(port.Value), "the most recent value of the output should be returned")
+	assert.Equal(t, upgradeResult.ID, port.ResultID)
+
+	outputs, err := installations.GetLastOutputs(ctx, "dev", "mysql")
+	require.NoError(t, err, "GetLastOutputs failed")
+	require.Equal(t, 2, outputs.Len())
+	host, ok := outputs.GetByName("host")
+	require.True(t, ok, "the host output should be returned even though the last run did not generate it")
+	assert.Equal(t, "mysql.local", string(host.Value))
+	port, ok = outputs.GetByName("port")
+	require.True(t, ok, "the port output should be returned")
+	assert.Equal(t, "3307", string(port.Value), "the most recent value of each output should be returned")
+}
@@ -0,0 +1,100 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testParameterSets(t *testing.T, newStore StoreFactory) {
+	params := storage.NewParameterStore(setupStore(t, newStore), setupSecrets(t))
+	ctx := context.Background()
+
+	newSet := func(namespace, name string) storage.ParameterSet {
+		ps := storage.NewParameterSet(namespace, name, secrets.Strategy{
+			Name:   "color",
+			Source: secrets.Source{Key: "value", Value: "blue"},
+		})
+		ps.Status.Created = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+		ps.Status.Modified = ps.Status.Created
+		return ps
+	}
+
+	mine := newSet("dev", "mine")
+	require.NoError(t, params.InsertParameterSet(ctx, mine), "InsertParameterSet failed")
+	require.NoError(t, params.InsertParameterSet(ctx, newSet("dev", "another")), "InsertParameterSet failed")
+	require.NoError(t, params.InsertParameterSet(ctx, newSet("", "global")), "InsertParameterSet failed")
+
+	err := params.InsertParameterSet(ctx, newSet("dev", "mine"))
+	assert.True(t, errors.Is(err, storage.ErrConflict{}), "InsertParameterSet should return storage.ErrConflict for a duplicate parameter set, got %v", err)
+
+	got, err := params.GetParameterSet(ctx, "dev", "mine")
+	require.NoError(t, err, "GetParameterSet failed")
+	assert.Equal(t, mine, got, "the parameter set should be returned as it was saved")
+
+	list, err := params.ListParameterSets(ctx, storage.ListOptions{Namespace: "dev"})
+	require.NoError(t, err, "ListParameterSets failed")
+	require.Len(t, list, 2)
+	assert.Equal(t, "another", list[0].Name, "parameter sets should be sorted by name")
+	assert.Equal(t, "mine", list[1].Name)
+
+	mine.Parameters[0].Source.Value = "green"
+	require.NoError(t, params.UpdateParameterSet(ctx, mine), "UpdateParameterSet failed")
+	got, err = params.GetParameterSet(ctx, "dev", "mine")
+	require.NoError(t, err, "GetParameterSet failed")
+	assert.Equal(t, "green", got.Parameters[0].Source.Value, "the parameter set should be updated")
+
+	require.NoError(t, params.RemoveParameterSet(ctx, "dev", "mine"), "RemoveParameterSet failed")
+	_, err = params.GetParameterSet(ctx, "dev", "mine")
+	assert.True(t, errors.Is(err, storage.ErrNotFound{}), "GetParameterSet should return storage.ErrNotFound after the parameter set is removed, got %v", err)
+}
+
+func testCredentialSets(t *testing.T, newStore StoreFactory) {
+	creds := storage.NewCredentialStore(setupStore(t, newStore), setupSecrets(t))
+	ctx := context.Background()
+
+	newSet := func(namespace, name string) storage.CredentialSet {
+		cs := storage.NewCredentialSet(namespace, name, secrets.Strategy{
+			Name:   "kubeconfig",
+			Source: secrets.Source{Key: "path", Value: "~/.kube/config"},
+		})
+		cs.Status.Created = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+		cs.Status.Modified = cs.Status.Created
+		return cs
+	}
+
+	mine := newSet("dev", "mine")
+	require.NoError(t, creds.InsertCredentialSet(ctx, mine), "InsertCredentialSet failed")
+	require.NoError(t, creds.InsertCredentialSet(ctx, newSet("dev", "another")), "InsertCredentialSet failed")
+	require.NoError(t, creds.InsertCredentialSet(ctx, newSet("", "global")), "InsertCredentialSet failed")
+
+	err := creds.InsertCredentialSet(ctx, newSet("dev", "mine"))
+	assert.True(t, errors.Is(err, storage.ErrConflict{}), "InsertCredentialSet should return storage.ErrConflict for a duplicate credential set, got %v", err)
+
+	got, err := creds.GetCredentialSet(ctx, "dev", "mine")
+	require.NoError(t, err, "GetCredentialSet failed")
+	assert.Equal(t, mine, got, "the credential set should be returned as it was saved")
+
+	list, err := creds.ListCredentialSets(ctx, storage.ListOptions{Namespace: "*"})
+	require.NoError(t, err, "ListCredentialSets failed")
+	require.Len(t, list, 3)
+	assert.Equal(t, "global", list[0].Name, "credential sets should be sorted by namespace and name")
+	assert.Equal(t, "another", list[1].Name)
+	assert.Equal(t, "mine", list[2].Name)
+
+	mine.Credentials[0].Source.Value = "/etc/kubeconfig"
+	require.NoError(t, creds.UpdateCredentialSet(ctx, mine), "UpdateCredentialSet failed")
+	got, err = creds.GetCredentialSet(ctx, "dev", "mine")
+	require.NoError(t, err, "GetCredentialSet failed")
+	assert.Equal(t, "/etc/kubeconfig", got.Credentials[0].Source.Value, "the credential set should be updated")
+
+	require.NoError(t, creds.RemoveCredentialSet(ctx, "dev", "mine"), "RemoveCredentialSet failed")
+	_, err = creds.GetCredentialSet(ctx, "dev", "mine")
+	assert.True(t, errors.Is(err, storage.ErrNotFound{}), "GetCredentialSet should return storage.ErrNotFound after the credential set is removed, got %v", err)
+}
@@ -9,6 +9,7 @@ import (
 	"get.porter.sh/porter/pkg/tracing"
 	"github.com/cnabio/cnab-go/secrets/host"
 	"github.com/hashicorp/go-multierror"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var _ CredentialSetProvider = &CredentialStore{}
@@ -23,6 +24,12 @@ const (
 type CredentialStore struct {
 	Documents Store
 	Secrets   secrets.Store
+
+	// authz authorizes reading and changing the credential sets in a namespace.
+	authz Authorizer
+
+	// audit is notified every time a sensitive credential is resolved.
+	audit secrets.AuditHook
 }
 
 func NewCredentialStore(storage Store, secrets secrets.Store) *CredentialStore {
@@ -32,6 +39,18 @@ func NewCredentialStore(storage Store, secrets secrets.Store) *CredentialStore {
 	}
 }
 
+// SetAuthorizer sets the hook that authorizes reading and changing the
+// credential sets in a namespace.
+func (s *CredentialStore) SetAuthorizer(authz Authorizer) {
+	s.authz = authz
+}
+
+// SetAuditHook sets the hook that is notified every time a credential is
+// resolved from the secret store or decrypted with a key management service.
+func (s *CredentialStore) SetAuditHook(hook secrets.AuditHook) {
+	s.audit = hook
+}
+
 // EnsureCredentialIndices creates indices on the credentials collection.
 func EnsureCredentialIndices(ctx context.Context, store Store) error {
 	ctx, span := tracing.StartSpan(ctx)
@@ -58,11 +77,22 @@ func (s CredentialStore) GetDataStore() Store {
 */
 
 func (s CredentialStore) ResolveAll(ctx context.Context, creds CredentialSet) (secrets.Set, error) {
+	ctx, span := tracing.StartSpan(ctx, attribute.String("credentialSet", creds.String()))
+	defer span.EndSpan()
+
 	resolvedCreds := make(secrets.Set)
 	var resolveErrors error
 
 	for _, cred := range creds.Credentials {
+		// Issued credentials are minted for each run when the bundle is executed
+		if cred.Source.Key == secrets.SourceIssued {
+			continue
+		}
+
 		value, err := s.Secrets.Resolve(ctx, cred.Source.Key, cred.Source.Value)
+		if auditErr := auditStrategy(ctx, s.audit, secrets.AuditKindCredential, creds.Namespace, "", cred, err); auditErr != nil {
+			return nil, span.Error(auditErr)
+		}
 		if err != nil {
 			resolveErrors = multierror.Append(resolveErrors, fmt.Errorf("unable to resolve credential %s.%s from %s %s: %w", creds.Name, cred.Name, cred.Source.Key, cred.Source.Value, err))
 		}
@@ -74,7 +104,7 @@ func (s CredentialStore) ResolveAll(ctx context.Context, creds CredentialSet) (s
 }
 
 func (s CredentialStore) Validate(ctx context.Context, creds CredentialSet) error {
-	validSources := []string{secrets.SourceSecret, host.SourceValue, host.SourceEnv, host.SourcePath, host.SourceCommand}
+	validSources := []string{secrets.SourceSecret, secrets.SourceKMS, secrets.SourceIssued, host.SourceValue, host.SourceEnv, host.SourcePath, host.SourceCommand}
 	var errors error
 
 	for _, cs := range creds.Credentials {
@@ -102,6 +132,10 @@ func (s CredentialStore) Validate(ctx context.Context, creds CredentialSet) erro
 */
 
 func (s CredentialStore) InsertCredentialSet(ctx context.Context, creds CredentialSet) error {
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionCredentials, creds.Namespace, creds.Name); err != nil {
+		return err
+	}
+
 	creds.SchemaVersion = CredentialSetSchemaVersion
 	opts := InsertOptions{
 		Documents: []interface{}{creds},
@@ -112,10 +146,17 @@ func (s CredentialStore) InsertCredentialSet(ctx context.Context, creds Credenti
 func (s CredentialStore) ListCredentialSets(ctx context.Context, listOptions ListOptions) ([]CredentialSet, error) {
 	var out []CredentialSet
 	err := s.Documents.Find(ctx, CollectionCredentials, listOptions.ToFindOptions(), &out)
-	return out, err
+	if err != nil {
+		return nil, err
+	}
+	return filterAuthorized(ctx, s.authz, CollectionCredentials, out, func(set CredentialSet) string { return set.Namespace })
 }
 
 func (s CredentialStore) GetCredentialSet(ctx context.Context, namespace string, name string) (CredentialSet, error) {
+	if err := authorize(ctx, s.authz, VerbRead, CollectionCredentials, namespace, name); err != nil {
+		return CredentialSet{}, err
+	}
+
 	var out CredentialSet
 	opts := FindOptions{
 		Filter: map[string]interface{}{
@@ -128,6 +169,10 @@ func (s CredentialStore) GetCredentialSet(ctx context.Context, namespace string,
 }
 
 func (s CredentialStore) UpdateCredentialSet(ctx context.Context, creds CredentialSet) error {
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionCredentials, creds.Namespace, creds.Name); err != nil {
+		return err
+	}
+
 	creds.SchemaVersion = CredentialSetSchemaVersion
 	opts := UpdateOptions{
 		Document: creds,
@@ -136,6 +181,10 @@ func (s CredentialStore) UpdateCredentialSet(ctx context.Context, creds Credenti
 }
 
 func (s CredentialStore) UpsertCredentialSet(ctx context.Context, creds CredentialSet) error {
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionCredentials, creds.Namespace, creds.Name); err != nil {
+		return err
+	}
+
 	creds.SchemaVersion = CredentialSetSchemaVersion
 	opts := UpdateOptions{
 		Document: creds,
@@ -145,6 +194,10 @@ func (s CredentialStore) UpsertCredentialSet(ctx context.Context, creds Credenti
 }
 
 func (s CredentialStore) RemoveCredentialSet(ctx context.Context, namespace string, name string) error {
+	if err := authorize(ctx, s.authz, VerbDelete, CollectionCredentials, namespace, name); err != nil {
+		return err
+	}
+
 	opts := RemoveOptions{
 		Namespace: namespace,
 		Name:      name,
@@ -111,3 +111,40 @@ func TestCredentialStorage_Validate_BadSources(t *testing.T) {
 	err := s.Validate(context.Background(), testCreds)
 	require.Error(t, err, "Validate returned errors")
 }
+
+type recordingAuditHook struct {
+	events []secrets.AuditEvent
+}
+
+func (h *recordingAuditHook) Audit(ctx context.Context, event secrets.AuditEvent) error {
+	h.events = append(h.events, event)
+	return nil
+}
+
+func TestCredentialStorage_ResolveAll_Audit(t *testing.T) {
+	ctx := context.Background()
+	cp := NewTestCredentialProvider(t)
+	defer cp.Close()
+
+	hook := &recordingAuditHook{}
+	cp.SetAuditHook(hook)
+
+	require.NoError(t, cp.TestSecrets.Create(ctx, secrets.SourceSecret, "dbPassword", "hunter2"))
+
+	cs := NewCredentialSet("dev", "sekrets",
+		secrets.Strategy{Name: "password", Source: secrets.Source{Key: secrets.SourceSecret, Value: "dbPassword"}},
+		secrets.Strategy{Name: "token", Source: secrets.Source{Key: secrets.SourceSecret, Value: "missing"}},
+		secrets.Strategy{Name: "kubeconfig", Source: secrets.Source{Key: "value", Value: "/tmp/kubeconfig"}},
+	)
+	_, err := cp.ResolveAll(ctx, cs)
+	require.Error(t, err, "the missing secret should fail to resolve")
+
+	require.Len(t, hook.events, 2, "only the credentials read from the secret store should be audited")
+	assert.Equal(t, secrets.AuditKindCredential, hook.events[0].Kind)
+	assert.Equal(t, "password", hook.events[0].Name)
+	assert.Equal(t, "dbPassword", hook.events[0].SecretKey)
+	assert.Equal(t, "dev", hook.events[0].Namespace)
+	assert.Empty(t, hook.events[0].Error)
+	assert.Equal(t, "token", hook.events[1].Name)
+	assert.NotEmpty(t, hook.events[1].Error)
+}
@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cnabio/cnab-go/schema"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// collectionSchemas are the collections of documents stored by Porter, and
+// the version of the schema of the documents in each collection.
+var collectionSchemas = []struct {
+	name    string
+	version schema.Version
+}{
+	{CollectionInstallations, InstallationSchemaVersion},
+	{CollectionRuns, RunSchemaVersion},
+	{CollectionResults, InstallationSchemaVersion},
+	{CollectionOutputs, InstallationSchemaVersion},
+	{CollectionOutputChunks, InstallationSchemaVersion},
+	{CollectionStepResults, InstallationSchemaVersion},
+	{CollectionCredentials, CredentialSetSchemaVersion},
+	{CollectionParameters, ParameterSetSchemaVersion},
+	{CollectionNamespaces, NamespaceSchemaVersion},
+	{CollectionSchedules, ScheduleSchemaVersion},
+}
+
+// StorageDescription describes the documents stored by Porter in the storage backend.
+type StorageDescription struct {
+	// Schemas compares the schema of each storage system recorded in the
+	// database with the schema supported by this version of Porter.
+	Schemas []SchemaDescription `json:"schemas" yaml:"schemas"`
+
+	// Collections of documents stored by Porter.
+	Collections []CollectionDescription `json:"collections" yaml:"collections"`
+}
+
+// SchemaDescription is the version of the schema of a storage system.
+type SchemaDescription struct {
+	// Name of the storage system.
+	Name string `json:"name" yaml:"name"`
+
+	// Version of the schema recorded in the database.
+	Version schema.Version `json:"version" yaml:"version"`
+
+	// Supported version of the schema for this version of Porter.
+	Supported schema.Version `json:"supported" yaml:"supported"`
+}
+
+// OutOfDate indicates if the database must be migrated to the supported schema.
+func (s SchemaDescription) OutOfDate() bool {
+	return s.Version != s.Supported
+}
+
+// CollectionDescription describes a collection of documents in the storage backend.
+type CollectionDescription struct {
+	// Name of the collection.
+	Name string `json:"name" yaml:"name"`
+
+	// SchemaVersion of the documents in the collection.
+	SchemaVersion schema.Version `json:"schemaVersion" yaml:"schemaVersion"`
+
+	// Exists indicates if the collection has been created in the storage backend.
+	Exists bool `json:"exists" yaml:"exists"`
+
+	// Count of the documents in the collection.
+	Count int64 `json:"count" yaml:"count"`
+
+	// Size of the documents in the collection in bytes, before compression.
+	Size int64 `json:"size" yaml:"size"`
+
+	// AverageDocumentSize is the average size of a document in bytes.
+	AverageDocumentSize int64 `json:"averageDocumentSize" yaml:"averageDocumentSize"`
+
+	// StorageSize is the number of bytes allocated to store the documents.
+	StorageSize int64 `json:"storageSize" yaml:"storageSize"`
+
+	// IndexSize is the number of bytes allocated to store the indexes.
+	IndexSize int64 `json:"indexSize" yaml:"indexSize"`
+
+	// Indexes defined on the collection.
+	Indexes []IndexDescription `json:"indexes,omitempty" yaml:"indexes,omitempty"`
+}
+
+// IndexDescription describes an index on a collection.
+type IndexDescription struct {
+	// Name of the index.
+	Name string `json:"name" yaml:"name"`
+
+	// Keys of the index in order. Keys sorted in descending order are prefixed with a -.
+	Keys []string `json:"keys" yaml:"keys"`
+
+	// Unique indicates if the index enforces that the indexed fields are unique.
+	Unique bool `json:"unique,omitempty" yaml:"unique,omitempty"`
+}
+
+// collectionStats is the result of the $collStats aggregation stage.
+type collectionStats struct {
+	StorageStats struct {
+		Count          float64 `json:"count"`
+		Size           float64 `json:"size"`
+		AvgObjSize     float64 `json:"avgObjSize"`
+		StorageSize    float64 `json:"storageSize"`
+		TotalIndexSize float64 `json:"totalIndexSize"`
+	} `json:"storageStats"`
+}
+
+// indexStats is the result of the $indexStats aggregation stage.
+type indexStats struct {
+	Name string                 `json:"name"`
+	Key  map[string]interface{} `json:"key"`
+	Spec struct {
+		Unique bool `json:"unique"`
+	} `json:"spec"`
+}
+
+// DescribeStorage queries the storage backend for the collections of documents
+// stored by Porter, their size and indexes. The schema is the version of each
+// storage system recorded in the database.
+func DescribeStorage(ctx context.Context, store Store, current Schema) (StorageDescription, error) {
+	supported := NewSchema()
+	desc := StorageDescription{
+		Schemas: []SchemaDescription{
+			{Name: "installations", Version: current.Installations, Supported: supported.Installations},
+			{Name: "credentials", Version: current.Credentials, Supported: supported.Credentials},
+			{Name: "parameters", Version: current.Parameters, Supported: supported.Parameters},
+		},
+		Collections: make([]CollectionDescription, 0, len(collectionSchemas)),
+	}
+
+	for _, c := range collectionSchemas {
+		coll, err := DescribeCollection(ctx, store, c.name)
+		if err != nil {
+			return StorageDescription{}, err
+		}
+		coll.SchemaVersion = c.version
+		desc.Collections = append(desc.Collections, coll)
+	}
+
+	return desc, nil
+}
+
+// DescribeCollection queries the storage backend for the size and indexes of a collection.
+func DescribeCollection(ctx context.Context, store Store, collection string) (CollectionDescription, error) {
+	desc := CollectionDescription{Name: collection}
+
+	var stats []collectionStats
+	statsOpts := AggregateOptions{
+		Pipeline: []bson.D{{{Key: "$collStats", Value: bson.D{{Key: "storageStats", Value: bson.D{}}}}}},
+	}
+	if err := store.Aggregate(ctx, collection, statsOpts, &stats); err != nil {
+		// The collection is created when the first document or index is saved
+		if errors.Is(err, ErrNotFound{}) {
+			return desc, nil
+		}
+		return CollectionDescription{}, fmt.Errorf("error querying the size of the %s collection: %w", collection, err)
+	}
+	desc.Exists = true
+	if len(stats) > 0 {
+		s := stats[0].StorageStats
+		desc.Count = int64(s.Count)
+		desc.Size = int64(s.Size)
+		desc.AverageDocumentSize = int64(s.AvgObjSize)
+		desc.StorageSize = int64(s.StorageSize)
+		desc.IndexSize = int64(s.TotalIndexSize)
+	}
+
+	var indexes []indexStats
+	indexOpts := AggregateOptions{
+		Pipeline: []bson.D{{{Key: "$indexStats", Value: bson.D{}}}},
+	}
+	if err := store.Aggregate(ctx, collection, indexOpts, &indexes); err != nil {
+		return CollectionDescription{}, fmt.Errorf("error querying the indexes of the %s collection: %w", collection, err)
+	}
+	for _, idx := range indexes {
+		desc.Indexes = append(desc.Indexes, IndexDescription{
+			Name:   idx.Name,
+			Keys:   getIndexKeys(idx.Name, idx.Key),
+			Unique: idx.Spec.Unique || idx.Name == "_id_",
+		})
+	}
+	sort.Slice(desc.Indexes, func(i, j int) bool {
+		return desc.Indexes[i].Name < desc.Indexes[j].Name
+	})
+
+	return desc, nil
+}
+
+// getIndexKeys returns the keys of an index, in the same format used to
+// define indexes with EnsureIndex. The order of the keys is recovered from
+// the name of the index, which is generated from the keys in order, since the
+// key document has lost its order by the time that it is unmarshaled.
+func getIndexKeys(name string, key map[string]interface{}) []string {
+	formatKey := func(field string, direction interface{}) string {
+		if d, ok := direction.(float64); ok && d < 0 {
+			return "-" + field
+		}
+		return field
+	}
+
+	var keys []string
+	var field []string
+	for _, token := range strings.Split(name, "_") {
+		if len(field) > 0 && (token == "1" || token == "-1") {
+			f := strings.Join(field, "_")
+			direction, ok := key[f]
+			if !ok {
+				break
+			}
+			keys = append(keys, formatKey(f, direction))
+			field = nil
+			continue
+		}
+		field = append(field, token)
+	}
+	if len(keys) == len(key) {
+		return keys
+	}
+
+	// The index has a custom name, fall back to sorting the keys by name
+	keys = make([]string, 0, len(key))
+	for f, direction := range key {
+		keys = append(keys, formatKey(f, direction))
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeStorage(t *testing.T) {
+	ctx := context.Background()
+	store := NewTestStore(config.NewTestConfig(t))
+	defer store.Close()
+
+	require.NoError(t, EnsureInstallationIndices(ctx, store))
+	installations := NewInstallationStore(store)
+	require.NoError(t, installations.InsertInstallation(ctx, NewInstallation("dev", "mysql")))
+	require.NoError(t, installations.InsertInstallation(ctx, NewInstallation("dev", "redis")))
+
+	current := NewSchema()
+	current.Credentials = "cnab-credentialsets-1.0.0"
+	desc, err := DescribeStorage(ctx, store, current)
+	require.NoError(t, err)
+
+	require.Len(t, desc.Schemas, 3)
+	assert.Equal(t, "installations", desc.Schemas[0].Name)
+	assert.False(t, desc.Schemas[0].OutOfDate())
+	assert.Equal(t, "credentials", desc.Schemas[1].Name)
+	assert.True(t, desc.Schemas[1].OutOfDate(), "the credentials schema should be out-of-date")
+
+	require.Len(t, desc.Collections, len(collectionSchemas))
+	inst := desc.Collections[0]
+	assert.Equal(t, CollectionInstallations, inst.Name)
+	assert.Equal(t, InstallationSchemaVersion, inst.SchemaVersion)
+	assert.True(t, inst.Exists)
+	assert.Equal(t, int64(2), inst.Count)
+	assert.Greater(t, inst.Size, int64(0))
+	assert.Equal(t, inst.Size/2, inst.AverageDocumentSize)
+
+	var params CollectionDescription
+	for _, c := range desc.Collections {
+		if c.Name == CollectionParameters {
+			params = c
+		}
+	}
+	assert.False(t, params.Exists, "collections that have not been created should be reported as missing")
+	assert.Empty(t, params.Indexes)
+}
+
+func TestDescribeCollection_Indexes(t *testing.T) {
+	ctx := context.Background()
+	store := NewTestStore(config.NewTestConfig(t))
+	defer store.Close()
+
+	require.NoError(t, EnsureInstallationIndices(ctx, store))
+
+	desc, err := DescribeCollection(ctx, store, CollectionOutputs)
+	require.NoError(t, err)
+	assert.True(t, desc.Exists, "the collection should exist once it has an index")
+	assert.Equal(t, int64(0), desc.Count)
+
+	wantKeys := map[string][]string{
+		"namespace_1_installation_1_createdAt_-1_resultId_-1": {"namespace", "installation", "-createdAt", "-resultId"},
+		"resultId_1_name_1": {"resultId", "name"},
+		"namespace_1_installation_1_name_1_createdAt_-1_resultId_-1": {"namespace", "installation", "name", "-createdAt", "-resultId"},
+		"runId_1_name_1_resultId_-1":                                 {"runId", "name", "-resultId"},
+	}
+	gotKeys := map[string][]string{}
+	for _, idx := range desc.Indexes {
+		gotKeys[idx.Name] = idx.Keys
+		if idx.Name == "resultId_1_name_1" {
+			assert.True(t, idx.Unique, "expected %s to be unique", idx.Name)
+		}
+	}
+	for name, keys := range wantKeys {
+		assert.Equal(t, keys, gotKeys[name], "unexpected keys for index %s", name)
+	}
+}
+
+func TestGetIndexKeys(t *testing.T) {
+	testcases := []struct {
+		name     string
+		index    string
+		key      map[string]interface{}
+		wantKeys []string
+	}{
+		{name: "generated name", index: "runId_1_index_1", key: map[string]interface{}{"runId": 1.0, "index": 1.0}, wantKeys: []string{"runId", "index"}},
+		{name: "descending", index: "namespace_1_resultId_-1", key: map[string]interface{}{"namespace": 1.0, "resultId": -1.0}, wantKeys: []string{"namespace", "-resultId"}},
+		{name: "field with underscore", index: "_id_1", key: map[string]interface{}{"_id": 1.0}, wantKeys: []string{"_id"}},
+		{name: "custom name", index: "by-installation", key: map[string]interface{}{"namespace": 1.0, "installation": -1.0}, wantKeys: []string{"-installation", "namespace"}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantKeys, getIndexKeys(tc.index, tc.key))
+		})
+	}
+}
@@ -0,0 +1,363 @@
+package storage
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// EncryptedField is the name of the field that holds the encrypted fields
+	// of a document.
+	EncryptedField = "encrypted"
+
+	// EncryptionAlgorithm is the algorithm used to encrypt documents, and
+	// the keys used to encrypt each document.
+	EncryptionAlgorithm = "AES-256-GCM"
+
+	// encryptionKeySize is the size in bytes of the keys used to encrypt documents.
+	encryptionKeySize = 32
+)
+
+// encryptedFields are the fields of the documents in each collection that are
+// encrypted at rest. The remaining fields are not encrypted so that the
+// documents can still be queried and indexed.
+var encryptedFields = map[string][]string{
+	CollectionRuns:        {"bundle", "parameters", "parameterOverrides", "custom"},
+	CollectionParameters:  {"parameters"},
+	CollectionCredentials: {"credentials"},
+}
+
+// encryptionEnvelope is the representation of the encrypted fields of a
+// document. Each document is encrypted with its own data key, which is in turn
+// encrypted with the key from the secret store, so that the key can be
+// rotated without re-encrypting every document.
+type encryptionEnvelope struct {
+	// Algorithm used to encrypt the data key and the document.
+	Algorithm string `bson:"algorithm"`
+
+	// KeyID is the name of the secret that holds the key used to encrypt the data key.
+	KeyID string `bson:"keyId"`
+
+	// DataKey is the encrypted key used to encrypt the document, prefixed with its nonce.
+	DataKey []byte `bson:"dataKey"`
+
+	// Ciphertext is the encrypted fields of the document, prefixed with its nonce.
+	Ciphertext []byte `bson:"ciphertext"`
+}
+
+// EncryptionError indicates that a document could not be encrypted or decrypted.
+type EncryptionError struct {
+	Err error
+}
+
+func (e EncryptionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e EncryptionError) Unwrap() error {
+	return e.Err
+}
+
+var _ plugins.StorageProtocol = &EncryptedStorage{}
+
+// EncryptedStorage is a storage plugin that encrypts the runs, parameter sets
+// and credential sets before they are persisted by the wrapped plugin, and
+// decrypts them when they are read. Documents are only encrypted when
+// storage-encryption is configured.
+type EncryptedStorage struct {
+	plugin  plugins.StorageProtocol
+	config  *config.Config
+	secrets secrets.Store
+
+	// keys that have been resolved from the secret store, by name.
+	keys     map[string][]byte
+	keysLock sync.Mutex
+}
+
+// NewEncryptedStorage wraps the specified storage plugin, encrypting documents
+// with a key resolved from the secret store.
+func NewEncryptedStorage(plugin plugins.StorageProtocol, c *config.Config, secretStore secrets.Store) *EncryptedStorage {
+	return &EncryptedStorage{
+		plugin:  plugin,
+		config:  c,
+		secrets: secretStore,
+		keys:    map[string][]byte{},
+	}
+}
+
+func (s *EncryptedStorage) Close() error {
+	if closer, ok := s.plugin.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (s *EncryptedStorage) EnsureIndex(ctx context.Context, opts plugins.EnsureIndexOptions) error {
+	return s.plugin.EnsureIndex(ctx, opts)
+}
+
+func (s *EncryptedStorage) Aggregate(ctx context.Context, opts plugins.AggregateOptions) ([]bson.Raw, error) {
+	results, err := s.plugin.Aggregate(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.decryptDocuments(ctx, opts.Collection, results)
+}
+
+func (s *EncryptedStorage) Count(ctx context.Context, opts plugins.CountOptions) (int64, error) {
+	return s.plugin.Count(ctx, opts)
+}
+
+func (s *EncryptedStorage) Find(ctx context.Context, opts plugins.FindOptions) ([]bson.Raw, error) {
+	opts.Select = selectEncryptedField(opts.Collection, opts.Select)
+	results, err := s.plugin.Find(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.decryptDocuments(ctx, opts.Collection, results)
+}
+
+func (s *EncryptedStorage) Insert(ctx context.Context, opts plugins.InsertOptions) error {
+	docs := make([]bson.M, len(opts.Documents))
+	for i, doc := range opts.Documents {
+		var err error
+		if docs[i], err = s.encryptDocument(ctx, opts.Collection, doc); err != nil {
+			return EncryptionError{Err: err}
+		}
+	}
+	opts.Documents = docs
+	return s.plugin.Insert(ctx, opts)
+}
+
+func (s *EncryptedStorage) Patch(ctx context.Context, opts plugins.PatchOptions) error {
+	return s.plugin.Patch(ctx, opts)
+}
+
+func (s *EncryptedStorage) Remove(ctx context.Context, opts plugins.RemoveOptions) error {
+	return s.plugin.Remove(ctx, opts)
+}
+
+func (s *EncryptedStorage) Update(ctx context.Context, opts plugins.UpdateOptions) error {
+	doc, err := s.encryptDocument(ctx, opts.Collection, opts.Document)
+	if err != nil {
+		return EncryptionError{Err: err}
+	}
+	opts.Document = doc
+	return s.plugin.Update(ctx, opts)
+}
+
+// selectEncryptedField includes the encrypted field in a projection that
+// selects any of the fields that are encrypted.
+func selectEncryptedField(collection string, projection bson.D) bson.D {
+	for _, e := range projection {
+		if e.Value == 0 || e.Value == false {
+			continue
+		}
+		for _, field := range encryptedFields[collection] {
+			if e.Key == field {
+				return append(projection, bson.E{Key: EncryptedField, Value: 1})
+			}
+		}
+	}
+	return projection
+}
+
+// encryptDocument returns a copy of the document where the fields that are
+// encrypted at rest are replaced with an encryption envelope.
+func (s *EncryptedStorage) encryptDocument(ctx context.Context, collection string, doc bson.M) (bson.M, error) {
+	fields := encryptedFields[collection]
+	cfg := s.config.Data.StorageEncryption
+	if len(fields) == 0 || !cfg.Enabled() {
+		return doc, nil
+	}
+
+	result := make(bson.M, len(doc))
+	for k, v := range doc {
+		result[k] = v
+	}
+
+	plaintext := bson.M{}
+	for _, field := range fields {
+		if v, ok := result[field]; ok {
+			plaintext[field] = v
+			delete(result, field)
+		}
+	}
+	if len(plaintext) == 0 {
+		return result, nil
+	}
+
+	key, err := s.getKey(ctx, cfg.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := bson.Marshal(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling the fields of the %s document to encrypt: %w", collection, err)
+	}
+
+	dataKey := make([]byte, encryptionKeySize)
+	if _, err = io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, fmt.Errorf("error generating a data key: %w", err)
+	}
+
+	env := encryptionEnvelope{Algorithm: EncryptionAlgorithm, KeyID: cfg.Key}
+	if env.DataKey, err = seal(key, dataKey, []byte(cfg.Key)); err != nil {
+		return nil, fmt.Errorf("error encrypting the data key: %w", err)
+	}
+	if env.Ciphertext, err = seal(dataKey, data, []byte(collection)); err != nil {
+		return nil, fmt.Errorf("error encrypting the %s document: %w", collection, err)
+	}
+
+	result[EncryptedField] = bson.M{
+		"algorithm":  env.Algorithm,
+		"keyId":      env.KeyID,
+		"dataKey":    env.DataKey,
+		"ciphertext": env.Ciphertext,
+	}
+	return result, nil
+}
+
+func (s *EncryptedStorage) decryptDocuments(ctx context.Context, collection string, docs []bson.Raw) ([]bson.Raw, error) {
+	for i, doc := range docs {
+		var err error
+		if docs[i], err = s.decryptDocument(ctx, collection, doc); err != nil {
+			return nil, EncryptionError{Err: err}
+		}
+	}
+	return docs, nil
+}
+
+// decryptDocument replaces the encryption envelope of a document with the
+// fields that it encrypted. Documents that are not encrypted are returned unchanged.
+func (s *EncryptedStorage) decryptDocument(ctx context.Context, collection string, doc bson.Raw) (bson.Raw, error) {
+	value, err := doc.LookupErr(EncryptedField)
+	if err != nil {
+		return doc, nil
+	}
+
+	var env encryptionEnvelope
+	if err = value.Unmarshal(&env); err != nil {
+		return nil, fmt.Errorf("invalid encrypted %s document: %w", collection, err)
+	}
+	if env.Algorithm != EncryptionAlgorithm {
+		return nil, fmt.Errorf("the %s document is encrypted with unsupported algorithm %q", collection, env.Algorithm)
+	}
+
+	cfg := s.config.Data.StorageEncryption
+	if env.KeyID != cfg.Key && !contains(cfg.PreviousKeys, env.KeyID) {
+		return nil, fmt.Errorf("the %s document is encrypted with key %s, which is not configured in storage-encryption. Set storage-encryption.key, or add the key to storage-encryption.previous-keys", collection, env.KeyID)
+	}
+
+	key, err := s.getKey(ctx, env.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := open(key, env.DataKey, []byte(env.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting the data key of the %s document with key %s: %w", collection, env.KeyID, err)
+	}
+	data, err := open(dataKey, env.Ciphertext, []byte(collection))
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting the %s document: %w", collection, err)
+	}
+
+	var result bson.M
+	if err = bson.Unmarshal(doc, &result); err != nil {
+		return nil, err
+	}
+	delete(result, EncryptedField)
+
+	var fields bson.M
+	if err = bson.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("invalid encrypted fields of the %s document: %w", collection, err)
+	}
+	for k, v := range fields {
+		result[k] = v
+	}
+
+	return bson.Marshal(result)
+}
+
+// getKey resolves a key by name from the secret store.
+func (s *EncryptedStorage) getKey(ctx context.Context, name string) ([]byte, error) {
+	s.keysLock.Lock()
+	defer s.keysLock.Unlock()
+
+	if key, ok := s.keys[name]; ok {
+		return key, nil
+	}
+
+	value, err := s.secrets.Resolve(ctx, secrets.SourceSecret, name)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve the storage encryption key %s from the secret store: %w", name, err)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil || len(key) != encryptionKeySize {
+		return nil, fmt.Errorf("the storage encryption key %s must be a base64 encoded 256-bit key, for example generated with: openssl rand -base64 32", name)
+	}
+
+	s.keys[name] = key
+	return key, nil
+}
+
+// seal encrypts the plaintext, returning the ciphertext prefixed with its nonce.
+func seal(key []byte, plaintext []byte, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts ciphertext created by seal.
+func open(key []byte, ciphertext []byte, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("the ciphertext is too short")
+	}
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
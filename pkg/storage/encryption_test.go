@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"get.porter.sh/porter/pkg/storage/plugins/testplugin"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/secrets/host"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type testEncryptedStorage struct {
+	config  *config.TestConfig
+	plugin  *testplugin.TestStoragePlugin
+	secrets secrets.TestSecretsProvider
+	store   PluginAdapter
+}
+
+func newTestEncryptedStorage(t *testing.T) testEncryptedStorage {
+	tc := config.NewTestConfig(t)
+	plugin := testplugin.NewTestStoragePlugin(tc.TestContext)
+	t.Cleanup(func() { plugin.Close() })
+	testSecrets := secrets.NewTestSecretsProvider()
+
+	return testEncryptedStorage{
+		config:  tc,
+		plugin:  plugin,
+		secrets: testSecrets,
+		store:   NewPluginAdapter(NewEncryptedStorage(plugin, tc.Config, testSecrets)),
+	}
+}
+
+// createKey saves a new encryption key to the secret store.
+func (s testEncryptedStorage) createKey(t *testing.T, name string, fill byte) {
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, encryptionKeySize))
+	require.NoError(t, s.secrets.Create(context.Background(), secrets.SourceSecret, name, key))
+}
+
+// findRaw returns the documents in a collection as they were persisted.
+func (s testEncryptedStorage) findRaw(t *testing.T, collection string) []bson.Raw {
+	docs, err := s.plugin.Find(context.Background(), plugins.FindOptions{Collection: collection})
+	require.NoError(t, err)
+	return docs
+}
+
+func TestEncryptedStorage_CredentialSet(t *testing.T) {
+	ctx := context.Background()
+	s := newTestEncryptedStorage(t)
+	s.createKey(t, "storage-key", 1)
+	s.config.Data.StorageEncryption.Key = "storage-key"
+
+	credStore := NewCredentialStore(s.store, s.secrets)
+	cs := NewCredentialSet("dev", "azure", secrets.Strategy{
+		Name:   "token",
+		Source: secrets.Source{Key: host.SourceEnv, Value: "AZURE_TOKEN"},
+	})
+	require.NoError(t, credStore.InsertCredentialSet(ctx, cs))
+
+	docs := s.findRaw(t, CollectionCredentials)
+	require.Len(t, docs, 1)
+	_, err := docs[0].LookupErr("credentials")
+	assert.Error(t, err, "the credentials should not be persisted in plain text")
+	assert.NotContains(t, docs[0].String(), "AZURE_TOKEN")
+	assert.Equal(t, "azure", docs[0].Lookup("name").StringValue(), "the fields used to query the document should not be encrypted")
+
+	var env encryptionEnvelope
+	require.NoError(t, docs[0].Lookup(EncryptedField).Unmarshal(&env))
+	assert.Equal(t, EncryptionAlgorithm, env.Algorithm)
+	assert.Equal(t, "storage-key", env.KeyID)
+
+	got, err := credStore.GetCredentialSet(ctx, "dev", "azure")
+	require.NoError(t, err)
+	assert.Equal(t, cs.Credentials, got.Credentials)
+
+	cs.Credentials[0].Source.Value = "ARM_TOKEN"
+	require.NoError(t, credStore.UpdateCredentialSet(ctx, cs))
+	got, err = credStore.GetCredentialSet(ctx, "dev", "azure")
+	require.NoError(t, err)
+	assert.Equal(t, "ARM_TOKEN", got.Credentials[0].Source.Value, "updated documents should be encrypted")
+	assert.NotContains(t, s.findRaw(t, CollectionCredentials)[0].String(), "ARM_TOKEN")
+}
+
+func TestEncryptedStorage_Run(t *testing.T) {
+	ctx := context.Background()
+	s := newTestEncryptedStorage(t)
+	s.createKey(t, "storage-key", 1)
+	s.config.Data.StorageEncryption.Key = "storage-key"
+
+	installations := NewInstallationStore(s.store)
+	run := NewRun("dev", "mysql")
+	run.Action = cnab.ActionInstall
+	run.Bundle = bundle.Bundle{Name: "mysql", Version: "0.1.0"}
+	run.Parameters.Parameters = []secrets.Strategy{ValueStrategy("database", "wordpress")}
+	require.NoError(t, installations.InsertRun(ctx, run))
+
+	docs := s.findRaw(t, CollectionRuns)
+	require.Len(t, docs, 1)
+	assert.NotContains(t, docs[0].String(), "wordpress")
+	assert.Equal(t, cnab.ActionInstall, docs[0].Lookup("action").StringValue())
+
+	got, err := installations.GetRun(ctx, run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, run.Bundle.Name, got.Bundle.Name)
+	require.Len(t, got.Parameters.Parameters, 1)
+	assert.Equal(t, "wordpress", got.Parameters.Parameters[0].Source.Value)
+
+	runs, _, err := installations.ListRuns(ctx, "dev", "mysql")
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "0.1.0", runs[0].Bundle.Version)
+}
+
+func TestEncryptedStorage_RotateKey(t *testing.T) {
+	ctx := context.Background()
+	s := newTestEncryptedStorage(t)
+	s.createKey(t, "key-v1", 1)
+	s.createKey(t, "key-v2", 2)
+	s.config.Data.StorageEncryption.Key = "key-v1"
+
+	paramStore := NewParameterStore(s.store, s.secrets)
+	ps := NewParameterSet("dev", "mysql", ValueStrategy("database", "wordpress"))
+	require.NoError(t, paramStore.InsertParameterSet(ctx, ps))
+
+	s.config.Data.StorageEncryption.Key = "key-v2"
+	_, err := paramStore.GetParameterSet(ctx, "dev", "mysql")
+	require.ErrorContains(t, err, "encrypted with key key-v1, which is not configured in storage-encryption")
+
+	s.config.Data.StorageEncryption.PreviousKeys = []string{"key-v1"}
+	got, err := paramStore.GetParameterSet(ctx, "dev", "mysql")
+	require.NoError(t, err, "documents encrypted with a previous key should be readable")
+	require.Len(t, got.Parameters, 1)
+	assert.Equal(t, "wordpress", got.Parameters[0].Source.Value)
+
+	require.NoError(t, paramStore.UpdateParameterSet(ctx, got))
+	var env encryptionEnvelope
+	require.NoError(t, s.findRaw(t, CollectionParameters)[0].Lookup(EncryptedField).Unmarshal(&env))
+	assert.Equal(t, "key-v2", env.KeyID, "documents should be encrypted with the current key when they are saved")
+}
+
+func TestEncryptedStorage_Disabled(t *testing.T) {
+	ctx := context.Background()
+	s := newTestEncryptedStorage(t)
+
+	paramStore := NewParameterStore(s.store, s.secrets)
+	ps := NewParameterSet("dev", "mysql", ValueStrategy("database", "wordpress"))
+	require.NoError(t, paramStore.InsertParameterSet(ctx, ps))
+
+	docs := s.findRaw(t, CollectionParameters)
+	require.Len(t, docs, 1)
+	_, err := docs[0].LookupErr(EncryptedField)
+	assert.Error(t, err, "documents should not be encrypted when storage-encryption is not configured")
+
+	// Documents that were saved before encryption was enabled are still readable
+	s.createKey(t, "storage-key", 1)
+	s.config.Data.StorageEncryption.Key = "storage-key"
+	got, err := paramStore.GetParameterSet(ctx, "dev", "mysql")
+	require.NoError(t, err)
+	require.Len(t, got.Parameters, 1)
+	assert.Equal(t, "wordpress", got.Parameters[0].Source.Value)
+}
+
+func TestEncryptedStorage_InvalidKey(t *testing.T) {
+	ctx := context.Background()
+	s := newTestEncryptedStorage(t)
+	require.NoError(t, s.secrets.Create(ctx, secrets.SourceSecret, "storage-key", "not-a-key"))
+	s.config.Data.StorageEncryption.Key = "storage-key"
+
+	paramStore := NewParameterStore(s.store, s.secrets)
+	err := paramStore.InsertParameterSet(ctx, NewParameterSet("dev", "mysql"))
+	require.ErrorContains(t, err, "the storage encryption key storage-key must be a base64 encoded 256-bit key")
+
+	s.config.Data.StorageEncryption.Key = "missing-key"
+	err = paramStore.InsertParameterSet(ctx, NewParameterSet("dev", "mysql"))
+	require.ErrorContains(t, err, "could not resolve the storage encryption key missing-key")
+}
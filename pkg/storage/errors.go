@@ -0,0 +1,128 @@
+package storage
+
+import "fmt"
+
+// ErrNotFound indicates that the requested document was not found.
+// You can test for this error using errors.Is(err, storage.ErrNotFound{})
+type ErrNotFound struct {
+	Collection string
+	Item       string
+}
+
+func (e ErrNotFound) Error() string {
+	return fmt.Sprintf("%s not found", documentType(e.Collection, e.Item))
+}
+
+func (e ErrNotFound) Is(err error) bool {
+	_, ok := err.(ErrNotFound)
+	return ok
+}
+
+// ErrConflict indicates that a document could not be saved because it
+// conflicts with an existing document, for example an installation with the
+// same namespace and name.
+// You can test for this error using errors.Is(err, storage.ErrConflict{})
+type ErrConflict struct {
+	Collection string
+
+	// Err is the error returned by the storage plugin.
+	Err error
+}
+
+func (e ErrConflict) Error() string {
+	docType := documentType(e.Collection, "")
+	if docType == "" {
+		docType = "Document"
+	}
+	return fmt.Sprintf("%s already exists: %s", docType, e.Err)
+}
+
+func (e ErrConflict) Is(err error) bool {
+	_, ok := err.(ErrConflict)
+	return ok
+}
+
+func (e ErrConflict) Unwrap() error {
+	return e.Err
+}
+
+// ErrUnauthorized indicates that the storage backend rejected Porter's
+// credentials, or they do not grant access to the requested documents.
+// You can test for this error using errors.Is(err, storage.ErrUnauthorized{})
+type ErrUnauthorized struct {
+	Collection string
+
+	// Err is the error returned by the storage plugin.
+	Err error
+}
+
+func (e ErrUnauthorized) Error() string {
+	return fmt.Sprintf("not authorized to access the %s collection: %s", e.Collection, e.Err)
+}
+
+func (e ErrUnauthorized) Is(err error) bool {
+	_, ok := err.(ErrUnauthorized)
+	return ok
+}
+
+func (e ErrUnauthorized) Unwrap() error {
+	return e.Err
+}
+
+// ErrReadOnly indicates that a document could not be saved because storage is
+// in read-only mode.
+// You can test for this error using errors.Is(err, storage.ErrReadOnly{})
+type ErrReadOnly struct {
+	// Collection that the write was for, empty when the operation is not for
+	// a single collection.
+	Collection string
+
+	// Operation that was rejected, for example insert or migrate.
+	Operation string
+}
+
+func (e ErrReadOnly) Error() string {
+	if e.Collection == "" {
+		return fmt.Sprintf("cannot %s because storage is in read-only mode", e.Operation)
+	}
+	return fmt.Sprintf("cannot %s documents in the %s collection because storage is in read-only mode", e.Operation, e.Collection)
+}
+
+func (e ErrReadOnly) Is(err error) bool {
+	_, ok := err.(ErrReadOnly)
+	return ok
+}
+
+// ReadOnlyStore is implemented by a Store that rejects changes in read-only
+// mode, such as the storage manager.
+type ReadOnlyStore interface {
+	// IsReadOnly determines if changes to storage are rejected.
+	IsReadOnly() bool
+}
+
+// checkWritable returns ErrReadOnly when the store is in read-only mode, so
+// that a change is rejected before it has side effects outside of the store,
+// such as signing a run ledger entry or saving a value to a blob store.
+func checkWritable(store Store, collection string, operation string) error {
+	if s, ok := store.(ReadOnlyStore); ok && s.IsReadOnly() {
+		return ErrReadOnly{Collection: collection, Operation: operation}
+	}
+	return nil
+}
+
+// documentType returns the name of the type of document stored in a collection.
+func documentType(collection string, item string) string {
+	switch collection {
+	case "installations":
+		return "Installation"
+	case "runs":
+		return "Run"
+	case "results":
+		return "Result"
+	case "output":
+		return "Output"
+	case "credentials", "parameters":
+		return item
+	}
+	return ""
+}
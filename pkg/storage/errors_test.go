@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestPluginAdapter_TypedErrors(t *testing.T) {
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+	ctx := context.Background()
+
+	doc := bson.M{"_id": "mylock", "owner": "me"}
+	require.NoError(t, cp.TestStore.Insert(ctx, CollectionInstallationLocks, InsertOptions{Documents: []interface{}{doc}}))
+
+	err := cp.TestStore.Insert(ctx, CollectionInstallationLocks, InsertOptions{Documents: []interface{}{doc}})
+	require.ErrorIs(t, err, ErrConflict{}, "inserting a document with the same id should return a conflict")
+	assert.NotErrorIs(t, err, ErrNotFound{})
+	assert.Contains(t, err.Error(), "already exists")
+
+	_, err = cp.GetInstallation(ctx, "dev", "missing")
+	require.ErrorIs(t, err, ErrNotFound{})
+	assert.NotErrorIs(t, err, ErrConflict{})
+}
+
+func TestPluginAdapter_handleError(t *testing.T) {
+	a := PluginAdapter{}
+
+	err := a.handleError(errors.New("rpc error: code = Unknown desc = document Not Found"), CollectionRuns)
+	require.ErrorIs(t, err, ErrNotFound{}, "errors from plugins that don't classify their errors should fall back to the message")
+
+	err = a.handleError(fmt.Errorf("duplicate: %w", plugins.ErrConflict), CollectionRuns)
+	require.ErrorIs(t, err, ErrConflict{})
+
+	err = a.handleError(errors.New("connection refused"), CollectionRuns)
+	assert.NotErrorIs(t, err, ErrNotFound{})
+	assert.NotErrorIs(t, err, ErrConflict{})
+}
+
+// readOnlyTestStore reports that it is in read-only mode, but does not reject
+// changes itself, so that a change that the installation store does not check
+// is saved.
+type readOnlyTestStore struct {
+	TestStore
+}
+
+func (s readOnlyTestStore) IsReadOnly() bool {
+	return true
+}
+
+func TestInstallationStore_ReadOnly(t *testing.T) {
+	ctx := context.Background()
+	tc := config.NewTestConfig(t)
+	// Signing reads a key that does not exist, so the changes must be
+	// rejected before they are recorded in the run ledger
+	tc.Data.RunLedger.Enabled = true
+	tc.Data.RunLedger.SigningKey = "/keys/missing.key"
+	testStore := NewTestStore(tc)
+	defer testStore.Close()
+	s := NewInstallationStore(readOnlyTestStore{testStore})
+	s.SetRunLedger(NewConfigRunLedger(tc.Config))
+
+	inst := NewInstallation("dev", "mybuns")
+	run := s.NewRun(inst, cnab.ActionInstall)
+	result := s.NewResult(run, cnab.StatusSucceeded)
+	output := result.NewOutput("connstr", []byte("localhost"))
+
+	changes := map[string]func() error{
+		"InsertInstallation": func() error { return s.InsertInstallation(ctx, inst) },
+		"UpdateInstallation": func() error { return s.UpdateInstallation(ctx, inst) },
+		"UpsertInstallation": func() error { return s.UpsertInstallation(ctx, inst) },
+		"RemoveInstallation": func() error { return s.RemoveInstallation(ctx, inst.Namespace, inst.Name) },
+		"ImportInstallation": func() error {
+			return s.ImportInstallation(ctx, InstallationArchive{SchemaType: InstallationArchiveSchemaType, SchemaVersion: InstallationSchemaVersion, Installation: inst})
+		},
+		"InsertRun":    func() error { return s.InsertRun(ctx, run) },
+		"UpsertRun":    func() error { return s.UpsertRun(ctx, run) },
+		"InsertResult": func() error { return s.InsertResult(ctx, result) },
+		"CommitResult": func() error { return s.CommitResult(ctx, result) },
+		"InsertOutput": func() error { return s.InsertOutput(ctx, output) },
+		"InsertOutputStream": func() error {
+			return s.InsertOutputStream(ctx, output, bytes.NewReader(output.Value))
+		},
+		"InsertStepResults": func() error {
+			return s.InsertStepResults(ctx, []StepResult{run.NewStepResult(StepResult{Mixin: "exec"})})
+		},
+		"InsertSnapshot": func() error {
+			return s.InsertSnapshot(ctx, InstallationSnapshot{Namespace: inst.Namespace, Installation: inst.Name, Name: "before-upgrade"})
+		},
+		"UpdateRunHeartbeat": func() error { return s.UpdateRunHeartbeat(ctx, run.ID, time.Now()) },
+		"AcquireInstallationLock": func() error {
+			_, err := s.AcquireInstallationLock(ctx, inst.Namespace, inst.Name, "me", time.Minute)
+			return err
+		},
+		"PruneRuns": func() error {
+			_, err := s.PruneRuns(ctx, PruneRunsOptions{Namespace: inst.Namespace, Installation: inst.Name, Policy: RetentionPolicy{KeepLast: 1}})
+			return err
+		},
+		"MigrateRuns": func() error {
+			_, err := s.MigrateRuns(ctx, MigrateRunsOptions{Namespace: "*"})
+			return err
+		},
+		"Fsck": func() error {
+			_, err := s.Fsck(ctx, FsckOptions{Namespace: "*", Repair: true})
+			return err
+		},
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			err := change()
+			require.ErrorIs(t, err, ErrReadOnly{})
+		})
+	}
+
+	for _, collection := range []string{CollectionInstallations, CollectionRuns, CollectionResults, CollectionOutputs,
+		CollectionOutputChunks, CollectionStepResults, CollectionSnapshots, CollectionLedger, CollectionInstallationLocks} {
+		count, err := testStore.Count(ctx, collection, CountOptions{})
+		require.NoError(t, err)
+		assert.Zero(t, count, "no documents should be saved to the %s collection", collection)
+	}
+}
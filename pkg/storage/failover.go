@@ -0,0 +1,700 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/failure"
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/hashicorp/go-multierror"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var _ plugins.StorageProtocol = &FailoverStorage{}
+
+// Operations that are queued while the primary storage is unreachable.
+const (
+	queuedInsert = "insert"
+	queuedPatch  = "patch"
+	queuedRemove = "remove"
+	queuedUpdate = "update"
+)
+
+const (
+	// failoverLockTimeout is the amount of time to wait for another porter
+	// process to release the lock on the storage failover queue.
+	failoverLockTimeout = 2 * time.Minute
+
+	// failoverLockStale is the amount of time after which a lock on the
+	// storage failover queue is considered abandoned, for example because the
+	// process that held it was killed. The lock is refreshed while the queue
+	// is replayed.
+	failoverLockStale = 2 * time.Minute
+)
+
+// FailoverStorage sends requests to the primary storage, and when it is
+// unreachable, fails over to the secondary storage account configured with
+// storage-failover.secondary. While failed over, reads are made against the
+// secondary storage, and writes are applied to the secondary storage and
+// queued so that they are replayed to the primary storage when it is
+// reachable again.
+//
+// The queue is shared by every porter process that uses the same queue file.
+// While it has writes that were not replayed, requests are sent to the
+// secondary storage, so that the writes are applied to the primary storage in
+// the order that they were made.
+//
+// When no secondary storage account is configured, requests are passed
+// through to the primary storage.
+type FailoverStorage struct {
+	config  *config.Config
+	primary plugins.StorageProtocol
+
+	// newSecondary creates the storage for the named secondary storage account.
+	newSecondary func(name string) plugins.StorageProtocol
+
+	// mu protects the fields below, which are used by concurrent requests.
+	mu        sync.Mutex
+	secondary plugins.StorageProtocol
+
+	// failedOver is set once the primary storage was found to be unreachable,
+	// or the queued writes could not be replayed to it.
+	failedOver bool
+
+	// queued is set when a write was queued after failing over.
+	queued bool
+}
+
+// NewFailoverStorage wraps the primary storage so that requests fail over to
+// the secondary storage, created with newSecondary, when it is unreachable.
+func NewFailoverStorage(c *config.Config, primary plugins.StorageProtocol, newSecondary func(name string) plugins.StorageProtocol) *FailoverStorage {
+	return &FailoverStorage{
+		config:       c,
+		primary:      primary,
+		newSecondary: newSecondary,
+	}
+}
+
+// FailoverStatus is the status of the storage used by Porter.
+type FailoverStatus struct {
+	// Primary is the status of the default storage account.
+	Primary StorageAccountStatus `json:"primary" yaml:"primary"`
+
+	// Secondary is the status of the storage account used when the
+	// primary storage is unreachable. It is not set when failover is not configured.
+	Secondary *StorageAccountStatus `json:"secondary,omitempty" yaml:"secondary,omitempty"`
+
+	// FailedOver indicates that requests are sent to the secondary storage.
+	FailedOver bool `json:"failedOver" yaml:"failedOver"`
+
+	// Replayed is the number of queued writes that were replayed to the
+	// primary storage by the check.
+	Replayed int `json:"replayed" yaml:"replayed"`
+
+	// QueuedWrites is the number of writes waiting to be replayed to the
+	// primary storage.
+	QueuedWrites int `json:"queuedWrites" yaml:"queuedWrites"`
+}
+
+// StorageAccountStatus is the connectivity of a storage account.
+type StorageAccountStatus struct {
+	// Name of the storage account.
+	Name string `json:"name" yaml:"name"`
+
+	// Available indicates that the storage account could be reached.
+	Available bool `json:"available" yaml:"available"`
+
+	// Error is the reason that the storage account could not be reached.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// queuedWrite is a write made while the primary storage was unreachable.
+type queuedWrite struct {
+	Operation string    `bson:"operation"`
+	Created   time.Time `bson:"created"`
+
+	// Version is the version of the documents changed by the write, before
+	// the write was made, which must match the documents in the primary
+	// storage when the write is replayed. It is not set for inserts.
+	Version string `bson:"version,omitempty"`
+
+	Insert *plugins.InsertOptions `bson:"insert,omitempty"`
+	Patch  *plugins.PatchOptions  `bson:"patch,omitempty"`
+	Remove *plugins.RemoveOptions `bson:"remove,omitempty"`
+	Update *plugins.UpdateOptions `bson:"update,omitempty"`
+}
+
+// IsStorageUnavailable determines if the error was caused by storage that
+// could not be reached.
+func IsStorageUnavailable(err error) bool {
+	return errors.Is(err, plugins.ErrUnavailable) || failure.Is(err, failure.ClassStorageUnavailable)
+}
+
+func (s *FailoverStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var bigErr *multierror.Error
+	if closer, ok := s.primary.(io.Closer); ok {
+		bigErr = multierror.Append(bigErr, closer.Close())
+	}
+	if closer, ok := s.secondary.(io.Closer); ok {
+		bigErr = multierror.Append(bigErr, closer.Close())
+	}
+	return bigErr.ErrorOrNil()
+}
+
+// getSecondary returns the secondary storage, or nil when failover is not configured.
+func (s *FailoverStorage) getSecondary() plugins.StorageProtocol {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.secondaryLocked()
+}
+
+// secondaryLocked is getSecondary for callers that hold the lock.
+func (s *FailoverStorage) secondaryLocked() plugins.StorageProtocol {
+	name := s.config.Data.StorageFailover.Secondary
+	if name == "" || s.newSecondary == nil {
+		return nil
+	}
+	if s.secondary == nil {
+		s.secondary = s.newSecondary(name)
+	}
+	return s.secondary
+}
+
+// usePrimary determines if requests should be sent to the primary storage.
+// Writes queued by this or another porter process are replayed to the primary
+// storage first, and requests are sent to the secondary storage until they
+// are all replayed.
+func (s *FailoverStorage) usePrimary(ctx context.Context) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.secondaryLocked() == nil {
+		return true
+	}
+
+	pending := s.hasQueuedWrites()
+	if s.failedOver {
+		// The writes queued by this process were replayed by another process,
+		// so the primary storage is reachable again
+		if s.queued && !pending {
+			s.failedOver = false
+			s.queued = false
+			return true
+		}
+		return false
+	}
+
+	if pending {
+		if _, err := s.Replay(ctx); err != nil {
+			if !IsStorageUnavailable(err) {
+				err = fmt.Errorf("could not replay the writes queued while the primary storage was unreachable: %w", err)
+			}
+			s.failoverLocked(ctx, err)
+			return false
+		}
+	}
+	return true
+}
+
+// shouldFailover determines if the request should be retried against the
+// secondary storage, after it failed against the primary storage.
+func (s *FailoverStorage) shouldFailover(ctx context.Context, err error) bool {
+	if err == nil || !IsStorageUnavailable(err) {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.secondaryLocked() == nil {
+		return false
+	}
+	s.failoverLocked(ctx, err)
+	return true
+}
+
+// failoverLocked sends requests to the secondary storage. The caller must hold the lock.
+func (s *FailoverStorage) failoverLocked(ctx context.Context, err error) {
+	s.failedOver = true
+
+	log := tracing.LoggerFromContext(ctx)
+	log.Warnf("the primary storage cannot be used, using the %s storage account until the queued writes are replayed to it: %s",
+		s.config.Data.StorageFailover.Secondary, err)
+}
+
+// failoverRead makes a read against the primary storage, and against the
+// secondary storage when the primary storage is unreachable.
+func failoverRead[T any](ctx context.Context, s *FailoverStorage, read func(store plugins.StorageProtocol) (T, error)) (T, error) {
+	if s.usePrimary(ctx) {
+		result, err := read(s.primary)
+		if !s.shouldFailover(ctx, err) {
+			return result, err
+		}
+	}
+	return read(s.getSecondary())
+}
+
+// failoverWrite makes a write against the primary storage. When the primary
+// storage is unreachable, the write is made against the secondary storage
+// and queued so that it is replayed to the primary storage later, along with
+// the version of the documents that it changes.
+func (s *FailoverStorage) failoverWrite(ctx context.Context, write queuedWrite, apply func(store plugins.StorageProtocol) error) error {
+	if s.usePrimary(ctx) {
+		err := apply(s.primary)
+		if !s.shouldFailover(ctx, err) {
+			return err
+		}
+	}
+
+	if err := s.applyAndQueue(ctx, s.getSecondary(), write, apply); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.queued = true
+	s.mu.Unlock()
+	return nil
+}
+
+// applyAndQueue makes the write against the secondary storage and queues it.
+// The lock on the queue is held until the write is queued, so that the writes
+// are queued in the order that they were made to the secondary storage.
+func (s *FailoverStorage) applyAndQueue(ctx context.Context, secondary plugins.StorageProtocol, write queuedWrite, apply func(store plugins.StorageProtocol) error) error {
+	unlock, err := s.lockQueue(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if collection, filter, ok := write.target(); ok {
+		if write.Version, err = documentsVersion(ctx, secondary, collection, filter); err != nil {
+			return err
+		}
+	}
+
+	if err = apply(secondary); err != nil {
+		return err
+	}
+	write.Created = time.Now()
+	if err = s.enqueue(write); err != nil {
+		return fmt.Errorf("the write was saved to the %s storage account but could not be queued for the primary storage: %w",
+			s.config.Data.StorageFailover.Secondary, err)
+	}
+	return nil
+}
+
+func (s *FailoverStorage) EnsureIndex(ctx context.Context, opts plugins.EnsureIndexOptions) error {
+	_, err := failoverRead(ctx, s, func(store plugins.StorageProtocol) (struct{}, error) {
+		return struct{}{}, store.EnsureIndex(ctx, opts)
+	})
+	return err
+}
+
+func (s *FailoverStorage) Aggregate(ctx context.Context, opts plugins.AggregateOptions) ([]bson.Raw, error) {
+	return failoverRead(ctx, s, func(store plugins.StorageProtocol) ([]bson.Raw, error) {
+		return store.Aggregate(ctx, opts)
+	})
+}
+
+func (s *FailoverStorage) Count(ctx context.Context, opts plugins.CountOptions) (int64, error) {
+	return failoverRead(ctx, s, func(store plugins.StorageProtocol) (int64, error) {
+		return store.Count(ctx, opts)
+	})
+}
+
+func (s *FailoverStorage) Find(ctx context.Context, opts plugins.FindOptions) ([]bson.Raw, error) {
+	return failoverRead(ctx, s, func(store plugins.StorageProtocol) ([]bson.Raw, error) {
+		return store.Find(ctx, opts)
+	})
+}
+
+func (s *FailoverStorage) Insert(ctx context.Context, opts plugins.InsertOptions) error {
+	return s.failoverWrite(ctx, queuedWrite{Operation: queuedInsert, Insert: &opts}, func(store plugins.StorageProtocol) error {
+		return store.Insert(ctx, opts)
+	})
+}
+
+func (s *FailoverStorage) Patch(ctx context.Context, opts plugins.PatchOptions) error {
+	return s.failoverWrite(ctx, queuedWrite{Operation: queuedPatch, Patch: &opts}, func(store plugins.StorageProtocol) error {
+		return store.Patch(ctx, opts)
+	})
+}
+
+func (s *FailoverStorage) Remove(ctx context.Context, opts plugins.RemoveOptions) error {
+	return s.failoverWrite(ctx, queuedWrite{Operation: queuedRemove, Remove: &opts}, func(store plugins.StorageProtocol) error {
+		return store.Remove(ctx, opts)
+	})
+}
+
+func (s *FailoverStorage) Update(ctx context.Context, opts plugins.UpdateOptions) error {
+	return s.failoverWrite(ctx, queuedWrite{Operation: queuedUpdate, Update: &opts}, func(store plugins.StorageProtocol) error {
+		return store.Update(ctx, opts)
+	})
+}
+
+// lockQueue takes the lock on the queue file that is shared by every porter
+// process that uses it, and returns a function that releases the lock.
+func (s *FailoverStorage) lockQueue(ctx context.Context) (func(), error) {
+	queuePath, err := s.config.GetStorageFailoverQueue()
+	if err != nil {
+		return nil, err
+	}
+	lockPath := queuePath + ".lock"
+
+	deadline := time.Now().Add(failoverLockTimeout)
+	for {
+		f, err := s.config.FileSystem.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, pkg.FileModeWritable)
+		if err == nil {
+			fmt.Fprintf(f, "%d", os.Getpid())
+			f.Close()
+			return func() { s.config.FileSystem.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("error locking the storage failover queue %s: %w", queuePath, err)
+		}
+
+		// Remove a lock that was abandoned by a process that did not release it
+		if info, statErr := s.config.FileSystem.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > failoverLockStale {
+			s.config.FileSystem.Remove(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for another porter process to release the lock on the storage failover queue %s", lockPath)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// refreshLock updates the modified time of the lock on the queue file, so
+// that it is not considered abandoned while a long replay is running.
+func (s *FailoverStorage) refreshLock(queuePath string) {
+	now := time.Now()
+	s.config.FileSystem.Chtimes(queuePath+".lock", now, now)
+}
+
+// hasQueuedWrites determines if the queue has writes that were not replayed.
+// The queue file is removed once every write in it is replayed.
+func (s *FailoverStorage) hasQueuedWrites() bool {
+	queuePath, err := s.config.GetStorageFailoverQueue()
+	if err != nil {
+		return false
+	}
+	exists, _ := s.config.FileSystem.Exists(queuePath)
+	return exists
+}
+
+// enqueue appends a write to the queue file. The caller must hold the lock on
+// the queue. The queue file is only appended to, the writes that were replayed
+// are tracked in a separate cursor file.
+func (s *FailoverStorage) enqueue(write queuedWrite) error {
+	queuePath, err := s.config.GetStorageFailoverQueue()
+	if err != nil {
+		return err
+	}
+
+	line, err := bson.MarshalExtJSON(write, true, false)
+	if err != nil {
+		return fmt.Errorf("error marshaling the queued %s to the %s collection: %w", write.Operation, write.collection(), err)
+	}
+
+	// Remove the cursor of a previous queue, left behind when replaying it was interrupted
+	if exists, _ := s.config.FileSystem.Exists(queuePath); !exists {
+		if err = s.removeQueueFile(queuePath + ".replayed"); err != nil {
+			return err
+		}
+	}
+
+	f, err := s.config.FileSystem.OpenFile(queuePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, pkg.FileModeWritable)
+	if err != nil {
+		return fmt.Errorf("error opening the storage failover queue %s: %w", queuePath, err)
+	}
+	defer f.Close()
+
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error writing to the storage failover queue %s: %w", queuePath, err)
+	}
+	return nil
+}
+
+// readQueue returns the writes queued while the primary storage was
+// unreachable, and the number of them that were already replayed.
+func (s *FailoverStorage) readQueue() ([]queuedWrite, int, error) {
+	queuePath, err := s.config.GetStorageFailoverQueue()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data, err := s.config.FileSystem.ReadFile(queuePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, 0, nil
+		}
+		return nil, 0, fmt.Errorf("error reading the storage failover queue %s: %w", queuePath, err)
+	}
+
+	var writes []queuedWrite
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var write queuedWrite
+		if err := bson.UnmarshalExtJSON(line, true, &write); err != nil {
+			return nil, 0, fmt.Errorf("error parsing the storage failover queue %s: %w", queuePath, err)
+		}
+		writes = append(writes, write)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error reading the storage failover queue %s: %w", queuePath, err)
+	}
+
+	cursorPath := queuePath + ".replayed"
+	cursorData, err := s.config.FileSystem.ReadFile(cursorPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return writes, 0, nil
+		}
+		return nil, 0, fmt.Errorf("error reading the storage failover queue cursor %s: %w", cursorPath, err)
+	}
+	replayed, err := strconv.Atoi(strings.TrimSpace(string(cursorData)))
+	if err != nil || replayed < 0 || replayed > len(writes) {
+		return nil, 0, fmt.Errorf("invalid storage failover queue cursor %s: %q", cursorPath, cursorData)
+	}
+	return writes, replayed, nil
+}
+
+// writeCursor records the number of queued writes that were replayed.
+func (s *FailoverStorage) writeCursor(queuePath string, replayed int) error {
+	cursorPath := queuePath + ".replayed"
+	if err := s.config.FileSystem.WriteFile(cursorPath, []byte(strconv.Itoa(replayed)), pkg.FileModeWritable); err != nil {
+		return fmt.Errorf("error writing the storage failover queue cursor %s: %w", cursorPath, err)
+	}
+	return nil
+}
+
+func (s *FailoverStorage) removeQueueFile(path string) error {
+	err := s.config.FileSystem.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing %s: %w", path, err)
+	}
+	return nil
+}
+
+// Replay the writes queued while the primary storage was unreachable to the
+// primary storage, in the order that they were made, and returns the number
+// of writes replayed. Writes that were not replayed remain queued. A write is
+// not replayed when the documents that it changes were modified in the
+// primary storage after it was queued.
+func (s *FailoverStorage) Replay(ctx context.Context) (int, error) {
+	if !s.hasQueuedWrites() {
+		return 0, nil
+	}
+
+	unlock, err := s.lockQueue(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	queuePath, err := s.config.GetStorageFailoverQueue()
+	if err != nil {
+		return 0, err
+	}
+	writes, replayed, err := s.readQueue()
+	if err != nil || len(writes) == 0 {
+		return 0, err
+	}
+
+	for i := replayed; i < len(writes); i++ {
+		write := writes[i]
+		if err = write.replay(ctx, s.primary); err != nil {
+			return i - replayed, fmt.Errorf("error replaying the queued %s to the %s collection: %w", write.Operation, write.collection(), err)
+		}
+		if err = s.writeCursor(queuePath, i+1); err != nil {
+			return i + 1 - replayed, err
+		}
+		s.refreshLock(queuePath)
+	}
+
+	// Remove the queue before the cursor, a cursor without a queue is ignored
+	if err = s.removeQueueFile(queuePath); err != nil {
+		return len(writes) - replayed, err
+	}
+	return len(writes) - replayed, s.removeQueueFile(queuePath + ".replayed")
+}
+
+// Check the connectivity of the primary and secondary storage. When the
+// primary storage is reachable, the queued writes are replayed to it.
+func (s *FailoverStorage) Check(ctx context.Context) (FailoverStatus, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	primaryName := s.config.Data.DefaultStorage
+	if primaryName == "" {
+		primaryName = s.config.Data.DefaultStoragePlugin
+	}
+	status := FailoverStatus{
+		Primary: checkStorageAccount(ctx, primaryName, s.primary),
+	}
+
+	if secondary := s.getSecondary(); secondary != nil {
+		secondaryStatus := checkStorageAccount(ctx, s.config.Data.StorageFailover.Secondary, secondary)
+		status.Secondary = &secondaryStatus
+		status.FailedOver = !status.Primary.Available
+	}
+
+	var replayErr error
+	if status.Primary.Available {
+		status.Replayed, replayErr = s.Replay(ctx)
+		if replayErr == nil {
+			s.mu.Lock()
+			s.failedOver = false
+			s.queued = false
+			s.mu.Unlock()
+		}
+	}
+
+	writes, replayed, err := s.readQueue()
+	if err != nil {
+		return status, span.Error(err)
+	}
+	status.QueuedWrites = len(writes) - replayed
+	if status.Secondary != nil && status.QueuedWrites > 0 {
+		// Requests are sent to the secondary storage until the queued writes are replayed
+		status.FailedOver = true
+	}
+
+	return status, span.Error(replayErr)
+}
+
+// checkStorageAccount makes a request to a storage account to check that it is reachable.
+func checkStorageAccount(ctx context.Context, name string, store plugins.StorageProtocol) StorageAccountStatus {
+	status := StorageAccountStatus{Name: name}
+	_, err := store.Count(ctx, plugins.CountOptions{Collection: CollectionInstallations})
+	if err != nil {
+		status.Error = err.Error()
+	} else {
+		status.Available = true
+	}
+	return status
+}
+
+func (w queuedWrite) collection() string {
+	switch {
+	case w.Insert != nil:
+		return w.Insert.Collection
+	case w.Patch != nil:
+		return w.Patch.Collection
+	case w.Remove != nil:
+		return w.Remove.Collection
+	case w.Update != nil:
+		return w.Update.Collection
+	}
+	return ""
+}
+
+// target returns the collection and filter of the documents changed by the
+// write, and false for inserts.
+func (w queuedWrite) target() (string, bson.M, bool) {
+	switch {
+	case w.Patch != nil:
+		return w.Patch.Collection, w.Patch.QueryDocument, true
+	case w.Remove != nil:
+		return w.Remove.Collection, w.Remove.Filter, true
+	case w.Update != nil:
+		return w.Update.Collection, w.Update.Filter, true
+	}
+	return "", nil, false
+}
+
+// replay the queued write to the storage, after checking that the documents
+// that it changes were not modified since the write was queued.
+func (w queuedWrite) replay(ctx context.Context, store plugins.StorageProtocol) error {
+	if collection, filter, ok := w.target(); ok && w.Version != "" {
+		version, err := documentsVersion(ctx, store, collection, filter)
+		if err != nil {
+			return err
+		}
+		if version != w.Version {
+			return fmt.Errorf("the documents matching %v were modified in the primary storage after the write was queued, reconcile them with the secondary storage and then remove the storage failover queue to discard the remaining queued writes", filter)
+		}
+	}
+	return w.apply(ctx, store)
+}
+
+// apply the queued write to the storage.
+func (w queuedWrite) apply(ctx context.Context, store plugins.StorageProtocol) error {
+	switch {
+	case w.Operation == queuedInsert && w.Insert != nil:
+		err := store.Insert(ctx, *w.Insert)
+		if errors.Is(err, plugins.ErrConflict) {
+			// The documents were already saved, for example by a replay that was interrupted
+			return nil
+		}
+		return err
+	case w.Operation == queuedPatch && w.Patch != nil:
+		return store.Patch(ctx, *w.Patch)
+	case w.Operation == queuedRemove && w.Remove != nil:
+		return store.Remove(ctx, *w.Remove)
+	case w.Operation == queuedUpdate && w.Update != nil:
+		return store.Update(ctx, *w.Update)
+	}
+	return fmt.Errorf("unsupported queued operation %q", w.Operation)
+}
+
+// documentsVersion returns a hash of the documents that match the filter,
+// which identifies the version of the documents that a queued write changes.
+// Documents are compared as json, so that the version does not depend on the
+// order of their fields in the storage.
+func documentsVersion(ctx context.Context, store plugins.StorageProtocol, collection string, filter bson.M) (string, error) {
+	docs, err := store.Find(ctx, plugins.FindOptions{Collection: collection, Filter: filter})
+	if err != nil {
+		return "", fmt.Errorf("error reading the documents in the %s collection changed by the write: %w", collection, err)
+	}
+
+	canonical := make([]string, len(docs))
+	for i, doc := range docs {
+		data, err := bson.MarshalExtJSON(doc, true, false)
+		if err != nil {
+			return "", fmt.Errorf("error marshaling a document in the %s collection: %w", collection, err)
+		}
+		var value interface{}
+		if err = json.Unmarshal(data, &value); err != nil {
+			return "", fmt.Errorf("error parsing a document in the %s collection: %w", collection, err)
+		}
+		if data, err = json.Marshal(value); err != nil {
+			return "", fmt.Errorf("error marshaling a document in the %s collection: %w", collection, err)
+		}
+		canonical[i] = string(data)
+	}
+	sort.Strings(canonical)
+
+	sum := sha256.Sum256([]byte(strings.Join(canonical, "\n")))
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,256 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"get.porter.sh/porter/pkg/storage/plugins/inmemory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// unreachableStore is a storage plugin that returns ErrUnavailable while it is down.
+type unreachableStore struct {
+	*inmemory.Store
+	down bool
+}
+
+func (s *unreachableStore) Count(ctx context.Context, opts plugins.CountOptions) (int64, error) {
+	if s.down {
+		return 0, fmt.Errorf("server selection timeout: %w", plugins.ErrUnavailable)
+	}
+	return s.Store.Count(ctx, opts)
+}
+
+func (s *unreachableStore) Find(ctx context.Context, opts plugins.FindOptions) ([]bson.Raw, error) {
+	if s.down {
+		return nil, fmt.Errorf("server selection timeout: %w", plugins.ErrUnavailable)
+	}
+	return s.Store.Find(ctx, opts)
+}
+
+func (s *unreachableStore) Insert(ctx context.Context, opts plugins.InsertOptions) error {
+	if s.down {
+		return fmt.Errorf("server selection timeout: %w", plugins.ErrUnavailable)
+	}
+	return s.Store.Insert(ctx, opts)
+}
+
+func (s *unreachableStore) Update(ctx context.Context, opts plugins.UpdateOptions) error {
+	if s.down {
+		return fmt.Errorf("server selection timeout: %w", plugins.ErrUnavailable)
+	}
+	return s.Store.Update(ctx, opts)
+}
+
+func TestFailoverStorage(t *testing.T) {
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	c.Data.DefaultStorage = "primary"
+	c.Data.StorageFailover.Secondary = "standby"
+
+	primary := &unreachableStore{Store: inmemory.NewStore(), down: true}
+	secondary := inmemory.NewStore()
+	newSecondary := func(name string) plugins.StorageProtocol {
+		assert.Equal(t, "standby", name)
+		return secondary
+	}
+
+	s := NewFailoverStorage(c.Config, primary, newSecondary)
+	err := s.Insert(ctx, plugins.InsertOptions{Collection: CollectionInstallations, Documents: []bson.M{{"_id": "1", "name": "mysql"}}})
+	require.NoError(t, err, "the write should be made against the secondary storage")
+	err = s.Update(ctx, plugins.UpdateOptions{Collection: CollectionInstallations, Filter: bson.M{"_id": "1"}, Document: bson.M{"_id": "1", "name": "mysql", "bundle": "v2"}})
+	require.NoError(t, err)
+
+	results, err := s.Find(ctx, plugins.FindOptions{Collection: CollectionInstallations})
+	require.NoError(t, err, "the read should be made against the secondary storage")
+	require.Len(t, results, 1)
+
+	status, err := s.Check(ctx)
+	require.NoError(t, err)
+	assert.False(t, status.Primary.Available)
+	assert.Contains(t, status.Primary.Error, "server selection timeout")
+	require.NotNil(t, status.Secondary)
+	assert.True(t, status.Secondary.Available)
+	assert.True(t, status.FailedOver)
+	assert.Equal(t, 2, status.QueuedWrites)
+
+	// The next command replays the queued writes once the primary storage is reachable again
+	primary.down = false
+	s = NewFailoverStorage(c.Config, primary, newSecondary)
+	results, err = s.Find(ctx, plugins.FindOptions{Collection: CollectionInstallations})
+	require.NoError(t, err)
+	require.Len(t, results, 1, "the queued writes should be replayed to the primary storage")
+	assert.Equal(t, "v2", results[0].Lookup("bundle").StringValue())
+
+	status, err = s.Check(ctx)
+	require.NoError(t, err)
+	assert.True(t, status.Primary.Available)
+	assert.False(t, status.FailedOver)
+	assert.Equal(t, 0, status.QueuedWrites)
+}
+
+func TestFailoverStorage_Check_Replay(t *testing.T) {
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	c.Data.StorageFailover.Secondary = "standby"
+
+	primary := &unreachableStore{Store: inmemory.NewStore(), down: true}
+	s := NewFailoverStorage(c.Config, primary, func(name string) plugins.StorageProtocol {
+		return inmemory.NewStore()
+	})
+	err := s.Insert(ctx, plugins.InsertOptions{Collection: CollectionInstallations, Documents: []bson.M{{"_id": "1"}}})
+	require.NoError(t, err)
+
+	primary.down = false
+	status, err := s.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, status.Replayed)
+	assert.Equal(t, 0, status.QueuedWrites)
+
+	count, err := s.Count(ctx, plugins.CountOptions{Collection: CollectionInstallations})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count, "requests should be sent to the primary storage after it is reachable again")
+}
+
+func TestFailoverStorage_NotConfigured(t *testing.T) {
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+
+	primary := &unreachableStore{Store: inmemory.NewStore(), down: true}
+	s := NewFailoverStorage(c.Config, primary, func(name string) plugins.StorageProtocol {
+		require.Fail(t, "the secondary storage should not be used when it is not configured")
+		return nil
+	})
+
+	err := s.Insert(ctx, plugins.InsertOptions{Collection: CollectionInstallations, Documents: []bson.M{{"_id": "1"}}})
+	require.ErrorIs(t, err, plugins.ErrUnavailable)
+
+	status, err := s.Check(ctx)
+	require.NoError(t, err)
+	assert.False(t, status.Primary.Available)
+	assert.Nil(t, status.Secondary)
+	assert.Equal(t, 0, status.QueuedWrites)
+}
+
+func TestFailoverStorage_Replay_Conflict(t *testing.T) {
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	c.Data.StorageFailover.Secondary = "standby"
+
+	primary := &unreachableStore{Store: inmemory.NewStore()}
+	secondary := inmemory.NewStore()
+	doc := bson.M{"_id": "1", "name": "mysql"}
+	require.NoError(t, primary.Store.Insert(ctx, plugins.InsertOptions{Collection: CollectionInstallations, Documents: []bson.M{doc}}))
+	require.NoError(t, secondary.Insert(ctx, plugins.InsertOptions{Collection: CollectionInstallations, Documents: []bson.M{doc}}))
+
+	primary.down = true
+	s := NewFailoverStorage(c.Config, primary, func(name string) plugins.StorageProtocol { return secondary })
+	err := s.Insert(ctx, plugins.InsertOptions{Collection: CollectionInstallations, Documents: []bson.M{{"_id": "2", "name": "redis"}}})
+	require.NoError(t, err)
+	err = s.Update(ctx, plugins.UpdateOptions{Collection: CollectionInstallations, Filter: bson.M{"_id": "1"}, Document: bson.M{"_id": "1", "name": "mysql", "bundle": "v2"}})
+	require.NoError(t, err)
+
+	// Another client changes the document in the primary storage while the update is queued
+	primary.down = false
+	require.NoError(t, primary.Store.Update(ctx, plugins.UpdateOptions{Collection: CollectionInstallations, Filter: bson.M{"_id": "1"}, Document: bson.M{"_id": "1", "name": "mysql", "bundle": "v3"}}))
+
+	status, err := s.Check(ctx)
+	require.ErrorContains(t, err, "were modified in the primary storage after the write was queued")
+	assert.Equal(t, 1, status.Replayed, "the writes before the conflict should be replayed")
+	assert.Equal(t, 1, status.QueuedWrites)
+	assert.True(t, status.FailedOver, "requests should be sent to the secondary storage until the conflict is resolved")
+
+	queuePath, err := c.GetStorageFailoverQueue()
+	require.NoError(t, err)
+	queue, err := c.FileSystem.ReadFile(queuePath)
+	require.NoError(t, err)
+	assert.Len(t, bytes.Split(bytes.TrimSpace(queue), []byte("\n")), 2, "the queue should only be appended to")
+	cursor, err := c.FileSystem.ReadFile(queuePath + ".replayed")
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(cursor))
+
+	results, err := primary.Store.Find(ctx, plugins.FindOptions{Collection: CollectionInstallations, Filter: bson.M{"_id": "1"}})
+	require.NoError(t, err)
+	assert.Equal(t, "v3", results[0].Lookup("bundle").StringValue(), "the conflicting write should not overwrite the primary storage")
+}
+
+func TestFailoverStorage_SharedQueue(t *testing.T) {
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	c.Data.StorageFailover.Secondary = "standby"
+
+	primary := &unreachableStore{Store: inmemory.NewStore(), down: true}
+	secondary := inmemory.NewStore()
+	newSecondary := func(name string) plugins.StorageProtocol { return secondary }
+
+	// Two porter processes that share the queue file
+	first := NewFailoverStorage(c.Config, primary, newSecondary)
+	second := NewFailoverStorage(c.Config, primary, newSecondary)
+
+	err := first.Insert(ctx, plugins.InsertOptions{Collection: CollectionInstallations, Documents: []bson.M{{"_id": "1"}}})
+	require.NoError(t, err)
+
+	// The primary is reachable again, the second process replays the writes queued by the first process before using it
+	primary.down = false
+	count, err := second.Count(ctx, plugins.CountOptions{Collection: CollectionInstallations})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.False(t, second.hasQueuedWrites())
+
+	// The first process uses the primary storage again, now that its queued writes were replayed
+	require.True(t, first.usePrimary(ctx))
+}
+
+func TestFailoverStorage_ConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	c.Data.StorageFailover.Secondary = "standby"
+
+	primary := &unreachableStore{Store: inmemory.NewStore(), down: true}
+	s := NewFailoverStorage(c.Config, primary, func(name string) plugins.StorageProtocol { return inmemory.NewStore() })
+
+	const writes = 10
+	var wg sync.WaitGroup
+	for i := 0; i < writes; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := s.Insert(ctx, plugins.InsertOptions{Collection: CollectionInstallations, Documents: []bson.M{{"_id": fmt.Sprint(i)}}})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	queued, replayed, err := s.readQueue()
+	require.NoError(t, err)
+	assert.Len(t, queued, writes)
+	assert.Equal(t, 0, replayed)
+}
+
+func TestFailoverStorage_lockQueue_Abandoned(t *testing.T) {
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	s := NewFailoverStorage(c.Config, inmemory.NewStore(), nil)
+
+	queuePath, err := c.GetStorageFailoverQueue()
+	require.NoError(t, err)
+	lockPath := queuePath + ".lock"
+	require.NoError(t, c.FileSystem.WriteFile(lockPath, []byte("123"), 0600))
+	abandoned := time.Now().Add(-2 * failoverLockStale)
+	require.NoError(t, c.FileSystem.Chtimes(lockPath, abandoned, abandoned))
+
+	unlock, err := s.lockQueue(ctx)
+	require.NoError(t, err, "an abandoned lock should be removed")
+	unlock()
+
+	exists, _ := c.FileSystem.Exists(lockPath)
+	assert.False(t, exists, "the lock should be released")
+}
@@ -0,0 +1,374 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/hashicorp/go-multierror"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// FsckPendingResult is a result whose outputs were not all saved, because
+	// Porter stopped before the result was committed.
+	FsckPendingResult = "pending-result"
+
+	// FsckOrphanedResult is a result whose run does not exist.
+	FsckOrphanedResult = "orphaned-result"
+
+	// FsckOrphanedOutput is an output whose result does not exist.
+	FsckOrphanedOutput = "orphaned-output"
+
+	// FsckIncompleteOutput is an output that was saved as a stream but is
+	// missing some of its chunks.
+	FsckIncompleteOutput = "incomplete-output"
+
+	// FsckOrphanedOutputChunk is a chunk of an output value whose output does not exist.
+	FsckOrphanedOutputChunk = "orphaned-output-chunk"
+)
+
+// PendingResultRepairedMessage is added to the message of a pending result
+// when it is repaired.
+const PendingResultRepairedMessage = "Porter stopped before all of the outputs of the run were saved, some outputs may be missing"
+
+// FsckOptions are the options for checking the consistency of the installation data.
+type FsckOptions struct {
+	// Namespace of the installation data to check. Use * to check all namespaces.
+	Namespace string
+
+	// Repair the problems that are found.
+	Repair bool
+
+	// PendingGracePeriod is how long after it was created that a pending
+	// result may still be saving its outputs. Newer pending results, and their
+	// outputs, are not checked.
+	PendingGracePeriod time.Duration
+}
+
+// FsckPendingGracePeriod is how long after it was created that a pending
+// result is considered interrupted by default.
+const FsckPendingGracePeriod = 15 * time.Minute
+
+// FsckProblem is an inconsistency found in the installation data.
+type FsckProblem struct {
+	// Type of problem, for example FsckOrphanedOutput.
+	Type string `json:"type" yaml:"type"`
+
+	// Namespace of the installation.
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// Installation name.
+	Installation string `json:"installation" yaml:"installation"`
+
+	// RunID of the affected run.
+	RunID string `json:"runId,omitempty" yaml:"runId,omitempty"`
+
+	// ResultID of the affected result.
+	ResultID string `json:"resultId,omitempty" yaml:"resultId,omitempty"`
+
+	// Output name of the affected output.
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+
+	// Description of the problem.
+	Description string `json:"description" yaml:"description"`
+
+	// Repaired indicates that the problem was repaired.
+	Repaired bool `json:"repaired" yaml:"repaired"`
+}
+
+// FsckReport lists the problems found when checking the installation data.
+type FsckReport struct {
+	Problems []FsckProblem `json:"problems" yaml:"problems"`
+}
+
+// Unrepaired returns the number of problems that were not repaired.
+func (r FsckReport) Unrepaired() int {
+	var count int
+	for _, problem := range r.Problems {
+		if !problem.Repaired {
+			count++
+		}
+	}
+	return count
+}
+
+// Fsck checks that the results and outputs of runs were saved completely,
+// and that every result, output and output chunk belongs to a document that
+// exists. When repair is requested, pending results are committed and applied
+// to their installation, and documents that are orphaned or incomplete are
+// removed. Every problem is attempted, and the errors are returned together.
+func (s InstallationStore) Fsck(ctx context.Context, opts FsckOptions) (FsckReport, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if opts.Repair {
+		if err := checkWritable(s.store, "", "repair"); err != nil {
+			return FsckReport{}, span.Error(err)
+		}
+	}
+
+	// Fsck reads every document in a namespace, and removes the documents
+	// that it repairs, so the subject must be allowed to do both
+	readAuthz := newNamespaceAuthorizer(s.authz, VerbRead, CollectionRuns, CollectionResults, CollectionOutputs)
+	repairAuthz := newNamespaceAuthorizer(s.authz, VerbDelete, CollectionResults, CollectionOutputs)
+	allows := func(ctx context.Context, namespace string) (bool, error) {
+		if ok, err := readAuthz.Allows(ctx, namespace); !ok || err != nil {
+			return ok, err
+		}
+		if !opts.Repair {
+			return true, nil
+		}
+		return repairAuthz.Allows(ctx, namespace)
+	}
+
+	filter := bson.M{}
+	if opts.Namespace != "*" {
+		for _, resource := range readAuthz.resources {
+			if err := authorize(ctx, s.authz, VerbRead, resource, opts.Namespace, ""); err != nil {
+				return FsckReport{}, span.Error(err)
+			}
+		}
+		if opts.Repair {
+			for _, resource := range repairAuthz.resources {
+				if err := authorize(ctx, s.authz, VerbDelete, resource, opts.Namespace, ""); err != nil {
+					return FsckReport{}, span.Error(err)
+				}
+			}
+		}
+		filter["namespace"] = opts.Namespace
+	}
+
+	var runs []Run
+	findRuns := FindOptions{Filter: filter, Select: bson.D{{Key: "_id", Value: 1}, {Key: "namespace", Value: 1}}}
+	if err := s.store.Find(ctx, CollectionRuns, findRuns, &runs); err != nil {
+		return FsckReport{}, span.Error(fmt.Errorf("error listing runs: %w", err))
+	}
+	runs, err := filterNamespaces(ctx, allows, runs, func(r Run) string { return r.Namespace })
+	if err != nil {
+		return FsckReport{}, span.Error(err)
+	}
+	runIDs := make(map[string]bool, len(runs))
+	for _, run := range runs {
+		runIDs[run.ID] = true
+	}
+
+	var results []Result
+	findResults := FindOptions{Filter: filter, Sort: []string{"_id"}}
+	if err := s.store.Find(ctx, CollectionResults, findResults, &results); err != nil {
+		return FsckReport{}, span.Error(fmt.Errorf("error listing results: %w", err))
+	}
+	if results, err = filterNamespaces(ctx, allows, results, func(r Result) string { return r.Namespace }); err != nil {
+		return FsckReport{}, span.Error(err)
+	}
+	resultIDs := make(map[string]bool, len(results))
+	orphanedResultIDs := make(map[string]bool)
+	// The results that may still be saving their outputs are skipped, along
+	// with their outputs, so that a run in progress is not repaired
+	savingResultIDs := make(map[string]bool)
+	savingCutoff := time.Now().Add(-opts.PendingGracePeriod)
+	for _, result := range results {
+		resultIDs[result.ID] = true
+		if !runIDs[result.RunID] {
+			orphanedResultIDs[result.ID] = true
+		} else if result.Pending && result.Created.After(savingCutoff) {
+			savingResultIDs[result.ID] = true
+		}
+	}
+
+	var outputs []Output
+	findOutputs := FindOptions{Filter: filter, Sort: []string{"resultId", "name"}, Select: bson.D{{Key: "value", Value: 0}}}
+	if err := s.store.Find(ctx, CollectionOutputs, findOutputs, &outputs); err != nil {
+		return FsckReport{}, span.Error(fmt.Errorf("error listing outputs: %w", err))
+	}
+	if outputs, err = filterNamespaces(ctx, allows, outputs, func(o Output) string { return o.Namespace }); err != nil {
+		return FsckReport{}, span.Error(err)
+	}
+
+	var chunks []OutputChunk
+	findChunks := FindOptions{Filter: filter, Select: bson.D{{Key: "data", Value: 0}}}
+	if err := s.store.Find(ctx, CollectionOutputChunks, findChunks, &chunks); err != nil {
+		return FsckReport{}, span.Error(fmt.Errorf("error listing output chunks: %w", err))
+	}
+	if chunks, err = filterNamespaces(ctx, allows, chunks, func(c OutputChunk) string { return c.Namespace }); err != nil {
+		return FsckReport{}, span.Error(err)
+	}
+	chunkCounts := make(map[string]int)
+	for _, chunk := range chunks {
+		chunkCounts[chunk.ResultID+"/"+chunk.Name]++
+	}
+
+	var report FsckReport
+	var bigErr *multierror.Error
+	addProblem := func(problem FsckProblem, repair func() error) {
+		if opts.Repair {
+			if err := repair(); err != nil {
+				bigErr = multierror.Append(bigErr, fmt.Errorf("error repairing %s %s: %w", problem.Type, problem.Description, err))
+			} else {
+				problem.Repaired = true
+			}
+		}
+		report.Problems = append(report.Problems, problem)
+	}
+
+	for _, result := range results {
+		result := result
+		if savingResultIDs[result.ID] {
+			continue
+		}
+		if orphanedResultIDs[result.ID] {
+			addProblem(FsckProblem{
+				Type:         FsckOrphanedResult,
+				Namespace:    result.Namespace,
+				Installation: result.Installation,
+				RunID:        result.RunID,
+				ResultID:     result.ID,
+				Description:  fmt.Sprintf("the result %s belongs to run %s, which does not exist", result.ID, result.RunID),
+			}, func() error { return s.removeResult(ctx, result) })
+			continue
+		}
+
+		if result.Pending {
+			addProblem(FsckProblem{
+				Type:         FsckPendingResult,
+				Namespace:    result.Namespace,
+				Installation: result.Installation,
+				RunID:        result.RunID,
+				ResultID:     result.ID,
+				Description:  fmt.Sprintf("the result %s was not committed, some of its outputs may not have been saved", result.ID),
+			}, func() error { return s.repairPendingResult(ctx, result) })
+		}
+	}
+
+	outputKeys := make(map[string]bool, len(outputs))
+	for _, output := range outputs {
+		output := output
+		key := output.ResultID + "/" + output.Name
+		outputKeys[key] = true
+
+		// The outputs of an orphaned result are removed with the result
+		if orphanedResultIDs[output.ResultID] || savingResultIDs[output.ResultID] {
+			continue
+		}
+
+		if !resultIDs[output.ResultID] {
+			addProblem(FsckProblem{
+				Type:         FsckOrphanedOutput,
+				Namespace:    output.Namespace,
+				Installation: output.Installation,
+				RunID:        output.RunID,
+				ResultID:     output.ResultID,
+				Output:       output.Name,
+				Description:  fmt.Sprintf("the %s output belongs to result %s, which does not exist", output.Name, output.ResultID),
+			}, func() error { return s.removeOutput(ctx, output) })
+			continue
+		}
+
+		if output.IsChunked() && chunkCounts[key] != output.Chunks {
+			addProblem(FsckProblem{
+				Type:         FsckIncompleteOutput,
+				Namespace:    output.Namespace,
+				Installation: output.Installation,
+				RunID:        output.RunID,
+				ResultID:     output.ResultID,
+				Output:       output.Name,
+				Description:  fmt.Sprintf("the %s output of result %s has %d of %d chunks", output.Name, output.ResultID, chunkCounts[key], output.Chunks),
+			}, func() error { return s.removeOutput(ctx, output) })
+		}
+	}
+
+	orphanedChunks := make(map[string]OutputChunk)
+	for _, chunk := range chunks {
+		key := chunk.ResultID + "/" + chunk.Name
+		if !outputKeys[key] && !orphanedResultIDs[chunk.ResultID] && !savingResultIDs[chunk.ResultID] {
+			orphanedChunks[key] = chunk
+		}
+	}
+	keys := make([]string, 0, len(orphanedChunks))
+	for key := range orphanedChunks {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		chunk := orphanedChunks[key]
+		addProblem(FsckProblem{
+			Type:         FsckOrphanedOutputChunk,
+			Namespace:    chunk.Namespace,
+			Installation: chunk.Installation,
+			RunID:        chunk.RunID,
+			ResultID:     chunk.ResultID,
+			Output:       chunk.Name,
+			Description:  fmt.Sprintf("%d chunks of the %s output of result %s do not belong to an output", chunkCounts[key], chunk.Name, chunk.ResultID),
+		}, func() error { return s.removeOutputChunks(ctx, chunk.ResultID, chunk.Name) })
+	}
+
+	return report, span.Error(bigErr.ErrorOrNil())
+}
+
+// repairPendingResult commits a result whose outputs were not all saved, and
+// applies it to the installation when it is from the last run of the installation.
+func (s InstallationStore) repairPendingResult(ctx context.Context, result Result) error {
+	if result.Message == "" {
+		result.Message = PendingResultRepairedMessage
+	} else {
+		result.Message = fmt.Sprintf("%s\n%s", result.Message, PendingResultRepairedMessage)
+	}
+	if err := s.CommitResult(ctx, result); err != nil {
+		return err
+	}
+
+	lastRun, err := s.GetLastRun(ctx, result.Namespace, result.Installation)
+	if err != nil {
+		if errors.Is(err, ErrNotFound{}) {
+			return nil
+		}
+		return err
+	}
+	if lastRun.ID != result.RunID {
+		return nil
+	}
+
+	installation, err := s.GetInstallation(ctx, result.Namespace, result.Installation)
+	if err != nil {
+		if errors.Is(err, ErrNotFound{}) {
+			return nil
+		}
+		return err
+	}
+	installation.ApplyResult(lastRun, result)
+	return s.UpdateInstallation(ctx, installation)
+}
+
+// removeResult removes a result along with its outputs.
+func (s InstallationStore) removeResult(ctx context.Context, result Result) error {
+	removeChildDocs := RemoveOptions{
+		Filter: bson.M{"resultId": result.ID},
+		All:    true,
+	}
+	if err := s.store.Remove(ctx, CollectionOutputChunks, removeChildDocs); err != nil {
+		return err
+	}
+	if err := s.store.Remove(ctx, CollectionOutputs, removeChildDocs); err != nil {
+		return err
+	}
+	return s.store.Remove(ctx, CollectionResults, RemoveOptions{Filter: bson.M{"_id": result.ID}})
+}
+
+// removeOutput removes an output along with the chunks of its value.
+func (s InstallationStore) removeOutput(ctx context.Context, output Output) error {
+	if err := s.removeOutputChunks(ctx, output.ResultID, output.Name); err != nil {
+		return err
+	}
+	return s.store.Remove(ctx, CollectionOutputs, RemoveOptions{Filter: bson.M{"resultId": output.ResultID, "name": output.Name}})
+}
+
+func (s InstallationStore) removeOutputChunks(ctx context.Context, resultID string, name string) error {
+	removeChunks := RemoveOptions{
+		Filter: bson.M{"resultId": resultID, "name": name},
+		All:    true,
+	}
+	return s.store.Remove(ctx, CollectionOutputChunks, removeChunks)
+}
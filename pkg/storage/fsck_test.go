@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationStore_Fsck(t *testing.T) {
+	ctx := context.Background()
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	inst := cp.CreateInstallation(NewInstallation("dev", "mybuns"))
+
+	// A run that was saved completely
+	run1 := cp.CreateRun(inst.NewRun(cnab.ActionInstall))
+	result1 := cp.CreateResult(run1.NewResult(cnab.StatusSucceeded))
+	cp.CreateOutput(result1.NewOutput("connstr", []byte("mysql://localhost")))
+
+	// A run that was interrupted while its outputs were saved
+	run2 := cp.CreateRun(inst.NewRun(cnab.ActionUpgrade))
+	result2 := cp.CreateResult(run2.NewResult(cnab.StatusSucceeded), func(r *Result) { r.Pending = true })
+	cp.CreateOutput(result2.NewOutput("connstr", []byte("mysql://remotehost")))
+
+	// A result, and its output, whose run is missing
+	orphanedResult := cp.CreateResult(NewRun("dev", "mybuns").NewResult(cnab.StatusSucceeded))
+	cp.CreateOutput(orphanedResult.NewOutput("connstr", []byte("mysql://nowhere")))
+
+	// An output whose result is missing
+	orphanedOutput := cp.CreateOutput(run1.NewResult(cnab.StatusFailed).NewOutput("password", []byte("topsecret")))
+
+	// An output saved as a stream that is missing a chunk
+	incompleteOutput := cp.CreateOutput(result1.NewOutput("logs", nil), func(o *Output) { o.Chunks = 2 })
+	insertChunk := func(output Output, index int) {
+		chunk := output.NewChunk(index, []byte("chunk"))
+		err := cp.store.Insert(ctx, CollectionOutputChunks, InsertOptions{Documents: []interface{}{chunk}})
+		require.NoError(t, err)
+	}
+	insertChunk(incompleteOutput, 0)
+
+	// Chunks that do not belong to an output
+	orphanedChunks := result1.NewOutput("kubeconfig", nil)
+	insertChunk(orphanedChunks, 0)
+	insertChunk(orphanedChunks, 1)
+
+	wantProblems := []FsckProblem{
+		{Type: FsckPendingResult, Namespace: "dev", Installation: "mybuns", RunID: run2.ID, ResultID: result2.ID},
+		{Type: FsckOrphanedResult, Namespace: "dev", Installation: "mybuns", RunID: orphanedResult.RunID, ResultID: orphanedResult.ID},
+		{Type: FsckIncompleteOutput, Namespace: "dev", Installation: "mybuns", RunID: run1.ID, ResultID: result1.ID, Output: "logs"},
+		{Type: FsckOrphanedOutput, Namespace: "dev", Installation: "mybuns", RunID: run1.ID, ResultID: orphanedOutput.ResultID, Output: "password"},
+		{Type: FsckOrphanedOutputChunk, Namespace: "dev", Installation: "mybuns", RunID: run1.ID, ResultID: result1.ID, Output: "kubeconfig"},
+	}
+	summarize := func(report FsckReport, repaired bool) []FsckProblem {
+		problems := make([]FsckProblem, len(report.Problems))
+		for i, problem := range report.Problems {
+			assert.NotEmpty(t, problem.Description)
+			assert.Equal(t, repaired, problem.Repaired)
+			problem.Description = ""
+			problem.Repaired = false
+			problems[i] = problem
+		}
+		return problems
+	}
+
+	t.Run("check", func(t *testing.T) {
+		report, err := cp.Fsck(ctx, FsckOptions{Namespace: "*"})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, wantProblems, summarize(report, false))
+		assert.Equal(t, len(wantProblems), report.Unrepaired())
+
+		report, err = cp.Fsck(ctx, FsckOptions{Namespace: "test"})
+		require.NoError(t, err)
+		assert.Empty(t, report.Problems, "only the data in the namespace should be checked")
+	})
+
+	t.Run("grace period", func(t *testing.T) {
+		report, err := cp.Fsck(ctx, FsckOptions{Namespace: "*", PendingGracePeriod: time.Hour})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, wantProblems[1:], summarize(report, false),
+			"a result that may still be saving its outputs should not be checked")
+	})
+
+	t.Run("repair", func(t *testing.T) {
+		report, err := cp.Fsck(ctx, FsckOptions{Namespace: "dev", Repair: true})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, wantProblems, summarize(report, true))
+		assert.Equal(t, 0, report.Unrepaired())
+
+		gotResult, err := cp.GetResult(ctx, result2.ID)
+		require.NoError(t, err)
+		assert.False(t, gotResult.Pending, "the pending result should be committed")
+		assert.Equal(t, PendingResultRepairedMessage, gotResult.Message)
+
+		gotInst, err := cp.GetInstallation(ctx, "dev", "mybuns")
+		require.NoError(t, err)
+		assert.Equal(t, result2.ID, gotInst.Status.ResultID, "the repaired result should be applied to the installation")
+
+		_, err = cp.GetResult(ctx, orphanedResult.ID)
+		require.ErrorIs(t, err, ErrNotFound{})
+
+		outputs, err := cp.ListOutputs(ctx, result1.ID)
+		require.NoError(t, err)
+		require.Len(t, outputs, 1, "the incomplete output should be removed")
+		assert.Equal(t, "connstr", outputs[0].Name)
+
+		outputs, err = cp.ListOutputs(ctx, orphanedResult.ID)
+		require.NoError(t, err)
+		assert.Empty(t, outputs, "the outputs of the orphaned result should be removed")
+
+		chunks, err := cp.store.Count(ctx, CollectionOutputChunks, CountOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), chunks, "the orphaned chunks should be removed")
+
+		report, err = cp.Fsck(ctx, FsckOptions{Namespace: "*"})
+		require.NoError(t, err)
+		assert.Empty(t, report.Problems, "all problems should be repaired")
+	})
+}
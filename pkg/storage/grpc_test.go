@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"net"
+	"strings"
 	"testing"
 
 	"get.porter.sh/porter/pkg/portercontext"
@@ -18,10 +19,19 @@ import (
 )
 
 func TestRoundTripDataOverGRPC(t *testing.T) {
-	// Just check that we can round trip data through our storage grpc service
+	t.Run("unary", func(t *testing.T) {
+		testRoundTripDataOverGRPC(t, pluginstore.NewClient)
+	})
+
+	t.Run("streaming", func(t *testing.T) {
+		testRoundTripDataOverGRPC(t, pluginstore.NewStreamingClient)
+	})
+}
+
+// startGRPCStorage serves the test storage plugin over grpc and returns a client connected to it.
+func startGRPCStorage(t *testing.T, newClient func(proto.StorageProtocolClient) *pluginstore.GClient) *pluginstore.GClient {
 	c := portercontext.NewTestContext(t)
 	store := testplugin.NewTestStoragePlugin(c)
-	ctx := context.Background()
 
 	server := pluginstore.NewServer(c.Context, store)
 	addr := "localhost:"
@@ -30,16 +40,22 @@ func TestRoundTripDataOverGRPC(t *testing.T) {
 	grpcServer := grpc.NewServer()
 	proto.RegisterStorageProtocolServer(grpcServer, server)
 	go grpcServer.Serve(lis)
-	defer grpcServer.Stop()
+	t.Cleanup(grpcServer.Stop)
 
 	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
 	require.NoError(t, err)
-	defer conn.Close()
-	client := pluginstore.NewClient(proto.NewStorageProtocolClient(conn))
+	t.Cleanup(func() { conn.Close() })
+	return newClient(proto.NewStorageProtocolClient(conn))
+}
+
+func testRoundTripDataOverGRPC(t *testing.T, newClient func(proto.StorageProtocolClient) *pluginstore.GClient) {
+	// Just check that we can round trip data through our storage grpc service
+	ctx := context.Background()
+	client := startGRPCStorage(t, newClient)
 
 	// Add an index to support filtering
 	const collection = "things"
-	err = client.EnsureIndex(ctx, plugins.EnsureIndexOptions{Indices: []plugins.Index{
+	err := client.EnsureIndex(ctx, plugins.EnsureIndexOptions{Indices: []plugins.Index{
 		{Collection: collection, Keys: bson.D{{Key: "namespace", Value: 1}, {Key: "name", Value: 1}}},
 	}})
 	require.NoError(t, err)
@@ -116,3 +132,37 @@ func TestRoundTripDataOverGRPC(t *testing.T) {
 	// make sure the group function is picking the most recent output value with the same name
 	require.Contains(t, aggregateResults[0].Lookup("lastOutput").String(), "222")
 }
+
+func TestStreamLargeDocumentsOverGRPC(t *testing.T) {
+	// Documents larger than the maximum grpc message size (4MB) can only be sent by streaming them
+	ctx := context.Background()
+	client := startGRPCStorage(t, pluginstore.NewStreamingClient)
+
+	bigValue := strings.Repeat("a", 5*1024*1024)
+	err := client.Insert(ctx, plugins.InsertOptions{
+		Collection: CollectionOutputs,
+		Documents: []bson.M{
+			{"namespace": "dev", "installation": "test", "name": "big", "value": bigValue},
+			{"namespace": "dev", "installation": "test", "name": "small", "value": "b"},
+		},
+	})
+	require.NoError(t, err)
+
+	results, err := client.Find(ctx, plugins.FindOptions{
+		Collection: CollectionOutputs,
+		Filter:     bson.M{"namespace": "dev"},
+		Sort:       bson.D{{Key: "name", Value: 1}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, bigValue, results[0].Lookup("value").StringValue())
+	assert.Equal(t, "b", results[1].Lookup("value").StringValue())
+
+	results, err = client.Aggregate(ctx, plugins.AggregateOptions{
+		Collection: CollectionOutputs,
+		Pipeline:   []bson.D{{{Key: "$match", Value: bson.M{"name": "big"}}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, bigValue, results[0].Lookup("value").StringValue())
+}
@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+)
+
+const (
+	// IDStrategyULID generates ULIDs for the ids and revisions of runs and results.
+	IDStrategyULID = "ulid"
+
+	// IDStrategyUUIDv7 generates time-ordered UUIDs (version 7) for the ids and
+	// revisions of runs and results.
+	IDStrategyUUIDv7 = "uuidv7"
+
+	// IDStrategyNumeric generates ordered numeric ids and revisions for runs
+	// and results.
+	IDStrategyNumeric = "numeric"
+)
+
+// IDStrategy generates the identifiers of runs and results, and the revision
+// of an installation that is recorded on each run.
+//
+// Generated values must not collide when generated by multiple porter
+// processes at the same time. Values generated by a process should sort in the
+// order they were generated, but ordering across processes, or across
+// strategies, is not supported. Porter finds the most recent run or output by
+// when it was created instead of by its id, so that the strategy can be changed.
+type IDStrategy interface {
+	// NewID returns a new identifier for a run or result.
+	NewID() string
+
+	// NewRevision returns a new revision of an installation.
+	NewRevision() string
+}
+
+// NewIDStrategy returns the strategy with the specified name. When a name is
+// not specified, ULIDs are generated.
+func NewIDStrategy(name string) (IDStrategy, error) {
+	switch name {
+	case "", IDStrategyULID:
+		return ULIDStrategy{}, nil
+	case IDStrategyUUIDv7:
+		return &UUIDv7Strategy{}, nil
+	case IDStrategyNumeric:
+		return &NumericStrategy{}, nil
+	default:
+		return nil, fmt.Errorf("invalid id-strategy %q, allowed values are: %s, %s, %s", name, IDStrategyULID, IDStrategyUUIDv7, IDStrategyNumeric)
+	}
+}
+
+var _ IDStrategy = &ConfigIDStrategy{}
+
+// ConfigIDStrategy generates ids with the strategy set by the id-strategy
+// configuration. The configuration is read when an id is generated, so that
+// it reflects the loaded configuration.
+type ConfigIDStrategy struct {
+	config *config.Config
+
+	mu       sync.Mutex
+	name     string
+	strategy IDStrategy
+}
+
+// NewConfigIDStrategy creates a strategy that uses the id-strategy configuration.
+func NewConfigIDStrategy(c *config.Config) *ConfigIDStrategy {
+	return &ConfigIDStrategy{config: c}
+}
+
+func (s *ConfigIDStrategy) NewID() string {
+	return s.get().NewID()
+}
+
+func (s *ConfigIDStrategy) NewRevision() string {
+	return s.get().NewRevision()
+}
+
+// get returns the configured strategy, keeping the same strategy while the
+// configuration is unchanged so that it can guard against collisions.
+func (s *ConfigIDStrategy) get() IDStrategy {
+	var name string
+	if s.config != nil {
+		name = s.config.Data.IDStrategy
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.strategy == nil || s.name != name {
+		strategy, err := NewIDStrategy(name)
+		if err != nil {
+			// The configuration is validated when porter connects, fall back to the default
+			strategy = ULIDStrategy{}
+		}
+		s.name = name
+		s.strategy = strategy
+	}
+	return s.strategy
+}
+
+// ULIDStrategy generates ULIDs, which is the default strategy.
+type ULIDStrategy struct{}
+
+func (s ULIDStrategy) NewID() string {
+	return cnab.NewULID()
+}
+
+func (s ULIDStrategy) NewRevision() string {
+	return cnab.NewULID()
+}
+
+// UUIDv7Strategy generates version 7 UUIDs, which begin with a millisecond
+// timestamp followed by random data. A counter is stored in the bits following
+// the timestamp so that values generated within the same millisecond by this
+// process are still ordered.
+type UUIDv7Strategy struct {
+	mu       sync.Mutex
+	lastMs   int64
+	sequence uint16
+}
+
+func (s *UUIDv7Strategy) NewID() string {
+	return s.newUUID()
+}
+
+func (s *UUIDv7Strategy) NewRevision() string {
+	return s.newUUID()
+}
+
+func (s *UUIDv7Strategy) newUUID() string {
+	var u [16]byte
+	if _, err := rand.Read(u[6:]); err != nil {
+		panic(fmt.Errorf("error generating a random uuid: %w", err))
+	}
+
+	s.mu.Lock()
+	ms := time.Now().UnixMilli()
+	if ms <= s.lastMs {
+		// Keep values generated within the same millisecond, or after the
+		// clock moved backwards, in order by incrementing the 12-bit counter
+		ms = s.lastMs
+		s.sequence++
+		if s.sequence > 0x0FFF {
+			ms++
+			s.sequence = 0
+		}
+	} else {
+		s.sequence = binary.BigEndian.Uint16(u[6:8]) & 0x07FF // leave room to increment
+	}
+	s.lastMs = ms
+	seq := s.sequence
+	s.mu.Unlock()
+
+	// 48-bit big-endian timestamp in milliseconds
+	u[0] = byte(ms >> 40)
+	u[1] = byte(ms >> 32)
+	u[2] = byte(ms >> 24)
+	u[3] = byte(ms >> 16)
+	u[4] = byte(ms >> 8)
+	u[5] = byte(ms)
+
+	// Version 7 followed by the counter
+	binary.BigEndian.PutUint16(u[6:8], 0x7000|seq)
+
+	// Variant 10xx
+	u[8] = (u[8] & 0x3F) | 0x80
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// NumericStrategy generates numeric values for integration with systems that
+// require numeric revisions. Each value is the time it was generated, in
+// nanoseconds since the Unix epoch, zero-padded to 20 digits, followed by 4
+// random digits so that values generated by different porter processes do not
+// collide.
+type NumericStrategy struct{}
+
+// lastNumericTimestamp is the timestamp of the last numeric value generated by
+// this process. It is shared by every NumericStrategy, so that values
+// generated by the same process never collide.
+var lastNumericTimestamp int64
+
+// numericSuffixRange is the upper bound of the random suffix of a numeric value.
+var numericSuffixRange = big.NewInt(10000)
+
+func (s *NumericStrategy) NewID() string {
+	return s.newValue()
+}
+
+func (s *NumericStrategy) NewRevision() string {
+	return s.newValue()
+}
+
+func (s *NumericStrategy) newValue() string {
+	var ts int64
+	for {
+		last := atomic.LoadInt64(&lastNumericTimestamp)
+		ts = time.Now().UnixNano()
+		if ts <= last {
+			// Values generated by this process are always increasing
+			ts = last + 1
+		}
+		if atomic.CompareAndSwapInt64(&lastNumericTimestamp, last, ts) {
+			break
+		}
+	}
+
+	suffix, err := rand.Int(rand.Reader, numericSuffixRange)
+	if err != nil {
+		panic(fmt.Errorf("error generating a random numeric id: %w", err))
+	}
+	return fmt.Sprintf("%020d%04d", ts, suffix.Int64())
+}
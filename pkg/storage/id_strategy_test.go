@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIDStrategy(t *testing.T) {
+	testcases := []struct {
+		name    string
+		want    IDStrategy
+		wantErr string
+	}{
+		{name: "", want: ULIDStrategy{}},
+		{name: IDStrategyULID, want: ULIDStrategy{}},
+		{name: IDStrategyUUIDv7, want: &UUIDv7Strategy{}},
+		{name: IDStrategyNumeric, want: &NumericStrategy{}},
+		{name: "guid", wantErr: `invalid id-strategy "guid"`},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewIDStrategy(tc.name)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tc.want, s)
+		})
+	}
+}
+
+func TestIDStrategies(t *testing.T) {
+	testcases := []struct {
+		name    string
+		format  *regexp.Regexp
+		newFunc func() IDStrategy
+	}{
+		{name: IDStrategyULID, format: regexp.MustCompile(`^[0-9A-Z]{26}$`), newFunc: func() IDStrategy { return ULIDStrategy{} }},
+		{name: IDStrategyUUIDv7, format: regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), newFunc: func() IDStrategy { return &UUIDv7Strategy{} }},
+		{name: IDStrategyNumeric, format: regexp.MustCompile(`^[0-9]{24}$`), newFunc: func() IDStrategy { return &NumericStrategy{} }},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			s := tc.newFunc()
+
+			// Generate ids concurrently to check that they are unique
+			const count = 1000
+			ids := make([]string, count)
+			var wg sync.WaitGroup
+			for i := 0; i < count; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					ids[i] = s.NewID()
+				}(i)
+			}
+			wg.Wait()
+
+			unique := make(map[string]bool, count)
+			for _, id := range ids {
+				assert.Regexp(t, tc.format, id)
+				unique[id] = true
+			}
+			assert.Len(t, unique, count, "expected the generated ids to be unique")
+
+			// Values generated in sequence should sort in the order they were generated
+			var sequence []string
+			for i := 0; i < 100; i++ {
+				sequence = append(sequence, s.NewRevision())
+			}
+			assert.True(t, sort.StringsAreSorted(sequence), "expected the generated revisions to be ordered")
+		})
+	}
+}
+
+func TestNumericStrategy_SharedGuard(t *testing.T) {
+	// Strategies created separately in the same process should never collide
+	a, b := &NumericStrategy{}, &NumericStrategy{}
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		for _, s := range []IDStrategy{a, b} {
+			id := s.NewID()
+			require.False(t, seen[id], "expected the generated ids to be unique")
+			seen[id] = true
+		}
+	}
+}
+
+func TestConfigIDStrategy(t *testing.T) {
+	c := config.NewTestConfig(t)
+	s := NewConfigIDStrategy(c.Config)
+	assert.Regexp(t, `^[0-9A-Z]{26}$`, s.NewID(), "expected ULIDs by default")
+
+	c.Data.IDStrategy = IDStrategyUUIDv7
+	assert.Regexp(t, `^[0-9a-f]{8}-`, s.NewID(), "expected the configured strategy to be used")
+	assert.Same(t, s.get(), s.get(), "expected the strategy to be kept while the configuration is unchanged")
+
+	c.Data.IDStrategy = IDStrategyNumeric
+	assert.Regexp(t, `^[0-9]{24}$`, s.NewID(), "expected the strategy to change with the configuration")
+}
+
+func TestInstallationStore_SetIDStrategy(t *testing.T) {
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	inst := NewInstallation("dev", "mybuns")
+	run := cp.NewRun(inst, "install")
+	assert.Regexp(t, `^[0-9A-Z]{26}$`, run.ID, "expected ULIDs until a strategy is set")
+
+	cp.SetIDStrategy(&NumericStrategy{})
+	run = cp.NewRun(inst, "install")
+	assert.Equal(t, "install", run.Action)
+	assert.Regexp(t, `^[0-9]{24}$`, run.ID)
+	assert.Regexp(t, `^[0-9]{24}$`, run.Revision)
+
+	result := cp.NewResult(run, "succeeded")
+	assert.Equal(t, run.ID, result.RunID)
+	assert.Regexp(t, `^[0-9]{24}$`, result.ID)
+
+	// Stores do not share their strategy
+	other := NewTestInstallationProvider(t)
+	defer other.Close()
+	assert.Regexp(t, `^[0-9A-Z]{26}$`, other.NewRun(inst, "install").ID)
+}
+
+func TestInstallationStore_ChangeIDStrategy(t *testing.T) {
+	// Ids generated by another strategy do not sort after the existing ULIDs,
+	// for example numeric ids always sort before them. The most recent run and
+	// output should still be found.
+	testcases := map[string]IDStrategy{
+		IDStrategyUUIDv7:  &UUIDv7Strategy{},
+		IDStrategyNumeric: &NumericStrategy{},
+	}
+	for name, strategy := range testcases {
+		strategy := strategy
+		t.Run(name, func(t *testing.T) {
+			cp := NewTestInstallationProvider(t)
+			defer cp.Close()
+
+			ctx := context.Background()
+			inst := NewInstallation("dev", "mybuns")
+			created := time.Now().Add(-time.Hour)
+			saveRun := func(value string) Run {
+				run := cp.NewRun(inst, "upgrade")
+				run.Created = created
+				require.NoError(t, cp.InsertRun(ctx, run))
+
+				result := cp.NewResult(run, "succeeded")
+				result.Created = created
+				require.NoError(t, cp.InsertResult(ctx, result))
+
+				output := result.NewOutput("connstr", []byte(value))
+				output.Created = created
+				require.NoError(t, cp.InsertOutput(ctx, output))
+
+				logs := result.NewOutput("io.cnab.outputs.invocationImageLogs", []byte(value))
+				logs.Created = created
+				require.NoError(t, cp.InsertOutput(ctx, logs))
+
+				created = created.Add(time.Minute)
+				return run
+			}
+
+			first := saveRun("first")
+			cp.SetIDStrategy(strategy)
+			last := saveRun("last")
+
+			lastRun, err := cp.GetLastRun(ctx, inst.Namespace, inst.Name)
+			require.NoError(t, err)
+			assert.Equal(t, last.ID, lastRun.ID, "expected the most recent run")
+
+			lastOutput, err := cp.GetLastOutput(ctx, inst.Namespace, inst.Name, "connstr")
+			require.NoError(t, err)
+			assert.Equal(t, "last", string(lastOutput.Value), "expected the most recent output")
+
+			lastOutputs, err := cp.GetLastOutputs(ctx, inst.Namespace, inst.Name)
+			require.NoError(t, err)
+			connstr, ok := lastOutputs.GetByName("connstr")
+			require.True(t, ok, "expected the connstr output")
+			assert.Equal(t, "last", string(connstr.Value), "expected the most recent outputs")
+
+			lastLogs, ok, err := cp.GetLastLogs(ctx, inst.Namespace, inst.Name)
+			require.NoError(t, err)
+			require.True(t, ok, "expected logs")
+			assert.Equal(t, "last", lastLogs, "expected the logs of the most recent run")
+
+			runs, _, err := cp.ListRuns(ctx, inst.Namespace, inst.Name)
+			require.NoError(t, err)
+			require.Len(t, runs, 2)
+			assert.Equal(t, []string{first.ID, last.ID}, []string{runs[0].ID, runs[1].ID}, "expected the runs in the order they were created")
+
+			page, _, err := cp.ListRunsWithOptions(ctx, ListRunsOptions{Namespace: inst.Namespace, Installation: inst.Name, Limit: 1})
+			require.NoError(t, err)
+			require.Len(t, page, 1)
+			assert.Equal(t, last.ID, page[0].ID, "expected the page to start from the most recent run")
+		})
+	}
+}
@@ -189,6 +189,12 @@ func (i Installation) AddToTrace(ctx context.Context) {
 		attribute.String("installationDefinition", string(doc)))
 }
 
+// installationAttribute identifies an installation in a trace span, using the
+// same format as the installation attribute set by Installation.AddToTrace.
+func installationAttribute(namespace string, name string) attribute.KeyValue {
+	return attribute.String("installation", InstallationSpec{Namespace: namespace, Name: name}.String())
+}
+
 // InstallationStatus's purpose is to assist with making porter list be able to display everything
 // with a single database query. Do not replicate data available on Run and Result here.
 type InstallationStatus struct {
@@ -0,0 +1,535 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/secrets"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
+)
+
+// Pseudonyms maps the names in an installation archive to the pseudonyms that
+// replace them when the archive is anonymized. Reuse the same pseudonyms when
+// anonymizing several archives, so that the same name is always replaced with
+// the same pseudonym.
+type Pseudonyms struct {
+	// Namespaces maps namespaces to their pseudonym.
+	Namespaces map[string]string `json:"namespaces,omitempty"`
+
+	// Installations maps installation names to their pseudonym.
+	Installations map[string]string `json:"installations,omitempty"`
+
+	// Registries maps the registries of the bundle and images to their pseudonym.
+	Registries map[string]string `json:"registries,omitempty"`
+
+	// Parameters maps parameter names to their pseudonym.
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// Secrets maps the keys of sensitive values in the secret store to their
+	// pseudonym, because the keys include the names of the parameters and
+	// outputs.
+	Secrets map[string]string `json:"secrets,omitempty"`
+
+	// Credentials maps the names of the credentials of the bundle to their pseudonym.
+	Credentials map[string]string `json:"credentials,omitempty"`
+
+	// Outputs maps output names to their pseudonym.
+	Outputs map[string]string `json:"outputs,omitempty"`
+
+	// CredentialSets maps the names of credential sets to their pseudonym.
+	CredentialSets map[string]string `json:"credentialSets,omitempty"`
+
+	// ParameterSets maps the names of parameter sets to their pseudonym.
+	ParameterSets map[string]string `json:"parameterSets,omitempty"`
+
+	// EnvironmentVariables maps the environment variables that parameters and
+	// credentials are injected into to their pseudonym.
+	EnvironmentVariables map[string]string `json:"environmentVariables,omitempty"`
+
+	// Paths maps the files that parameters, credentials and outputs are
+	// written to in the invocation image to their pseudonym.
+	Paths map[string]string `json:"paths,omitempty"`
+}
+
+// pseudonym returns the pseudonym for a value, generating a new one with the
+// specified prefix the first time that the value is seen. Empty values, such
+// as the global namespace, are not replaced.
+func pseudonym(names *map[string]string, prefix string, value string) string {
+	if value == "" {
+		return ""
+	}
+	if *names == nil {
+		*names = map[string]string{}
+	}
+	if alias, ok := (*names)[value]; ok {
+		return alias
+	}
+
+	// Find the next unused pseudonym
+	used := make(map[string]bool, len(*names))
+	for _, alias := range *names {
+		used[alias] = true
+	}
+	for i := len(*names) + 1; ; i++ {
+		alias := fmt.Sprintf("%s-%d", prefix, i)
+		if !used[alias] {
+			(*names)[value] = alias
+			return alias
+		}
+	}
+}
+
+func (p *Pseudonyms) namespace(value string) string {
+	return pseudonym(&p.Namespaces, "namespace", value)
+}
+
+func (p *Pseudonyms) installation(value string) string {
+	return pseudonym(&p.Installations, "installation", value)
+}
+
+func (p *Pseudonyms) parameter(value string) string {
+	return pseudonym(&p.Parameters, "param", value)
+}
+
+func (p *Pseudonyms) secret(value string) string {
+	return pseudonym(&p.Secrets, "secret", value)
+}
+
+func (p *Pseudonyms) credential(value string) string {
+	return pseudonym(&p.Credentials, "credential", value)
+}
+
+// output returns the pseudonym of an output. The outputs defined by the CNAB
+// spec, such as the logs of the invocation image, are not replaced.
+func (p *Pseudonyms) output(value string) string {
+	if strings.HasPrefix(value, "io.cnab.") {
+		return value
+	}
+	return pseudonym(&p.Outputs, "output", value)
+}
+
+func (p *Pseudonyms) credentialSets(names []string) []string {
+	if names == nil {
+		return nil
+	}
+	aliases := make([]string, len(names))
+	for i, name := range names {
+		aliases[i] = pseudonym(&p.CredentialSets, "credential-set", name)
+	}
+	return aliases
+}
+
+// parameterSets replaces the names of parameter sets. The internal parameter
+// set of an installation is named after the installation.
+func (p *Pseudonyms) parameterSets(names []string) []string {
+	if names == nil {
+		return nil
+	}
+	aliases := make([]string, len(names))
+	for i, name := range names {
+		aliases[i] = p.parameterSetName(name)
+	}
+	return aliases
+}
+
+func (p *Pseudonyms) parameterSetName(name string) string {
+	if installation := strings.TrimPrefix(name, INTERNAL_PARAMETERER_SET+"-"); installation != name {
+		return INTERNAL_PARAMETERER_SET + "-" + p.installation(installation)
+	}
+	return pseudonym(&p.ParameterSets, "parameter-set", name)
+}
+
+// environmentVariable returns the pseudonym of an environment variable, which
+// is a valid environment variable name.
+func (p *Pseudonyms) environmentVariable(value string) string {
+	alias := pseudonym(&p.EnvironmentVariables, "env", value)
+	return strings.ToUpper(strings.ReplaceAll(alias, "-", "_"))
+}
+
+// filePath returns the pseudonym of a file in the invocation image, keeping
+// the directory when it is one of the directories defined by the CNAB spec.
+func (p *Pseudonyms) filePath(value string) string {
+	if value == "" {
+		return ""
+	}
+	alias := pseudonym(&p.Paths, "path", value)
+	if dir := path.Dir(value); strings.HasPrefix(dir, "/cnab/app/outputs") {
+		return path.Join("/cnab/app/outputs", alias)
+	}
+	return "/" + alias
+}
+
+func (p *Pseudonyms) location(loc bundle.Location) bundle.Location {
+	if loc.EnvironmentVariable != "" {
+		loc.EnvironmentVariable = p.environmentVariable(loc.EnvironmentVariable)
+	}
+	loc.Path = p.filePath(loc.Path)
+	return loc
+}
+
+// outputMetadata replaces the output names in the metadata of a result.
+func (p *Pseudonyms) outputMetadata(metadata cnab.OutputMetadata) cnab.OutputMetadata {
+	if metadata == nil {
+		return nil
+	}
+	out := make(cnab.OutputMetadata, len(metadata))
+	for name, values := range metadata {
+		out[p.output(name)] = values
+	}
+	return out
+}
+
+// parameterSetRevisions replaces the names of the parameter sets that a run
+// used, keeping when they were modified.
+func (p *Pseudonyms) parameterSetRevisions(revisions []ParameterSetRevision) []ParameterSetRevision {
+	if revisions == nil {
+		return nil
+	}
+	out := make([]ParameterSetRevision, len(revisions))
+	for i, revision := range revisions {
+		revision.Namespace = p.namespace(revision.Namespace)
+		revision.Name = p.parameterSetName(revision.Name)
+		out[i] = revision
+	}
+	return out
+}
+
+// outputDependencies replaces the names of the installations, parameters
+// and outputs that a run used to resolve its parameters.
+func (p *Pseudonyms) outputDependencies(deps []RunOutputDependency) []RunOutputDependency {
+	if deps == nil {
+		return nil
+	}
+	out := make([]RunOutputDependency, len(deps))
+	for i, dep := range deps {
+		dep.Parameter = p.parameter(dep.Parameter)
+		dep.Namespace = p.namespace(dep.Namespace)
+		dep.Installation = p.installation(dep.Installation)
+		dep.Output = p.output(dep.Output)
+		out[i] = dep
+	}
+	return out
+}
+
+// environment replaces the registries of the images that were used by a run.
+func (p *Pseudonyms) environment(env *RunEnvironment) *RunEnvironment {
+	if env == nil {
+		return nil
+	}
+	out := *env
+	if env.Images != nil {
+		out.Images = make(map[string]string, len(env.Images))
+		for name, ref := range env.Images {
+			out.Images[name] = p.reference(ref)
+		}
+	}
+	return &out
+}
+
+// dependencies replaces the registries of the bundles that a bundle depends on.
+func (p *Pseudonyms) dependencies(ext interface{}) (interface{}, bool) {
+	data, err := json.Marshal(ext)
+	if err != nil {
+		return nil, false
+	}
+	var deps map[string]interface{}
+	if err = json.Unmarshal(data, &deps); err != nil {
+		return nil, false
+	}
+
+	requires, _ := deps["requires"].(map[string]interface{})
+	for name, value := range requires {
+		dep, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if ref, ok := dep["bundle"].(string); ok {
+			dep["bundle"] = p.reference(ref)
+		}
+		requires[name] = dep
+	}
+	return deps, true
+}
+
+// registry returns the pseudonym of a registry, which is a valid domain name
+// so that anonymized references can still be parsed.
+func (p *Pseudonyms) registry(value string) string {
+	return pseudonym(&p.Registries, "registry", value) + ".example.com"
+}
+
+// reference replaces the registry of an OCI reference. References to Docker
+// Hub that do not specify the registry are not changed.
+func (p *Pseudonyms) reference(ref string) string {
+	i := strings.Index(ref, "/")
+	if i == -1 {
+		return ref
+	}
+
+	domain := ref[:i]
+	if !strings.ContainsAny(domain, ".:") && domain != "localhost" {
+		return ref
+	}
+	return p.registry(domain) + ref[i:]
+}
+
+// parameterSet replaces the names of the parameters, and the keys of the
+// sensitive values, in a parameter set. The namespace of the parameter set,
+// and the installation name in the name of the internal parameter set of an
+// installation, are replaced too.
+func (p *Pseudonyms) parameterSet(ps ParameterSet) ParameterSet {
+	ps.Namespace = p.namespace(ps.Namespace)
+	ps.Name = p.parameterSetName(ps.Name)
+	ps.Labels = nil
+
+	params := make([]secrets.Strategy, len(ps.Parameters))
+	for i, param := range ps.Parameters {
+		param.Name = p.parameter(param.Name)
+		if param.Source.Key == secrets.SourceSecret {
+			param.Source.Value = p.secret(param.Source.Value)
+		}
+		params[i] = param
+	}
+	ps.Parameters = params
+	return ps
+}
+
+// bundle replaces the registries of the images and dependencies, and the
+// names and destinations of the parameters, credentials and outputs, in a
+// bundle definition. The maintainers are removed, and so are the custom
+// extensions, except for the dependencies and the Porter stamp, because they
+// may include the original names. The porter manifest embedded in the stamp
+// is removed too.
+func (p *Pseudonyms) bundle(b bundle.Bundle) bundle.Bundle {
+	b.Maintainers = nil
+
+	invocationImages := make([]bundle.InvocationImage, len(b.InvocationImages))
+	for i, img := range b.InvocationImages {
+		img.Image = p.reference(img.Image)
+		invocationImages[i] = img
+	}
+	b.InvocationImages = invocationImages
+
+	if b.Images != nil {
+		images := make(map[string]bundle.Image, len(b.Images))
+		for name, img := range b.Images {
+			img.Image = p.reference(img.Image)
+			images[name] = img
+		}
+		b.Images = images
+	}
+
+	definitions := make(definition.Definitions, len(b.Definitions))
+	for name, def := range b.Definitions {
+		definitions[name] = def
+	}
+	// Definitions are usually named after the parameter or output, so rename them too
+	renamedDefinitions := map[string]string{}
+	renameDefinition := func(name string, alias string) string {
+		if renamed, ok := renamedDefinitions[name]; ok {
+			return renamed
+		}
+		if def, ok := definitions[name]; ok {
+			delete(definitions, name)
+			definitions[alias] = def
+			renamedDefinitions[name] = alias
+			return alias
+		}
+		return name
+	}
+
+	if b.Parameters != nil {
+		params := make(map[string]bundle.Parameter, len(b.Parameters))
+		for _, name := range sortedKeys(b.Parameters) {
+			param := b.Parameters[name]
+			alias := p.parameter(name)
+			if param.Definition == name {
+				param.Definition = renameDefinition(name, alias)
+			}
+			if param.Destination != nil {
+				dest := p.location(*param.Destination)
+				param.Destination = &dest
+			}
+			params[alias] = param
+		}
+		b.Parameters = params
+	}
+
+	if b.Outputs != nil {
+		outputs := make(map[string]bundle.Output, len(b.Outputs))
+		for _, name := range sortedKeys(b.Outputs) {
+			output := b.Outputs[name]
+			alias := p.output(name)
+			if output.Definition == name {
+				output.Definition = renameDefinition(name, alias)
+			} else if renamed, ok := renamedDefinitions[output.Definition]; ok {
+				output.Definition = renamed
+			}
+			output.Path = p.filePath(output.Path)
+			outputs[alias] = output
+		}
+		b.Outputs = outputs
+	}
+
+	if b.Definitions != nil {
+		b.Definitions = definitions
+	}
+
+	if b.Credentials != nil {
+		creds := make(map[string]bundle.Credential, len(b.Credentials))
+		for _, name := range sortedKeys(b.Credentials) {
+			cred := b.Credentials[name]
+			cred.Location = p.location(cred.Location)
+			creds[p.credential(name)] = cred
+		}
+		b.Credentials = creds
+	}
+
+	if b.Custom != nil {
+		custom := make(map[string]interface{}, 2)
+		if stamp, ok := b.Custom["sh.porter"].(map[string]interface{}); ok {
+			anonymizedStamp := make(map[string]interface{}, len(stamp))
+			for key, value := range stamp {
+				if key != "manifest" {
+					anonymizedStamp[key] = value
+				}
+			}
+			custom["sh.porter"] = anonymizedStamp
+		}
+		if deps, ok := b.Custom[cnab.DependenciesV1ExtensionKey]; ok {
+			if anonymizedDeps, ok := p.dependencies(deps); ok {
+				custom[cnab.DependenciesV1ExtensionKey] = anonymizedDeps
+			}
+		}
+		b.Custom = custom
+	}
+
+	return b
+}
+
+// Anonymize returns a copy of the archive where the namespaces, installation
+// names, registries, and the names of parameters, credentials, outputs,
+// credential sets and parameter sets are replaced with pseudonyms, so that
+// the history of an installation can be shared without revealing internal
+// names. Fields that can't be anonymized, such as labels, notes, messages,
+// logs, the values of outputs, and the CI metadata of runs, are removed. New
+// pseudonyms are added to the specified pseudonyms. Anonymized archives are
+// for troubleshooting and cannot be imported.
+func (a InstallationArchive) Anonymize(p *Pseudonyms) InstallationArchive {
+	inst := a.Installation
+	inst.Namespace = p.namespace(inst.Namespace)
+	inst.Name = p.installation(inst.Name)
+	inst.Bundle.Repository = p.reference(inst.Bundle.Repository)
+	inst.Custom = nil
+	inst.Labels = nil
+	inst.CredentialSets = p.credentialSets(inst.CredentialSets)
+	inst.ParameterSets = p.parameterSets(inst.ParameterSets)
+	inst.Parameters = p.parameterSet(inst.Parameters)
+	inst.Status.BundleReference = p.reference(inst.Status.BundleReference)
+	a.Installation = inst
+
+	runs := make([]Run, len(a.Runs))
+	for i, run := range a.Runs {
+		run.Namespace = p.namespace(run.Namespace)
+		run.Installation = p.installation(run.Installation)
+		run.BundleReference = p.reference(run.BundleReference)
+		// Expand the parameters set to their default value before the bundle
+		// is replaced, so that the defaults are anonymized too
+		run.Parameters = p.parameterSet(run.GetParameters())
+		run.Bundle = p.bundle(run.Bundle)
+		run.ParameterOverrides = p.parameterSet(run.ParameterOverrides)
+		run.CredentialSets = p.credentialSets(run.CredentialSets)
+		run.ParameterSets = p.parameterSets(run.ParameterSets)
+		run.ParameterSetRevisions = p.parameterSetRevisions(run.ParameterSetRevisions)
+		if run.EphemeralOutputs != nil {
+			ephemeral := make([]string, len(run.EphemeralOutputs))
+			for j, name := range run.EphemeralOutputs {
+				ephemeral[j] = p.output(name)
+			}
+			run.EphemeralOutputs = ephemeral
+		}
+		run.OutputDependencies = p.outputDependencies(run.OutputDependencies)
+		run.Environment = p.environment(run.Environment)
+		run.ChangeTicket = ""
+		run.RestoredSnapshot = ""
+		run.Labels = nil
+		run.Metadata = nil
+		run.Trigger = nil
+		run.Custom = nil
+		run.Notes = nil
+		runs[i] = run
+	}
+	a.Runs = runs
+
+	results := make([]Result, len(a.Results))
+	for i, result := range a.Results {
+		result.Namespace = p.namespace(result.Namespace)
+		result.Installation = p.installation(result.Installation)
+		result.Message = ""
+		result.OutputMetadata = p.outputMetadata(result.OutputMetadata)
+		result.Custom = nil
+		if result.ChangeTicketDelivery != nil {
+			delivery := *result.ChangeTicketDelivery
+			delivery.Ticket = ""
+			delivery.Error = ""
+			result.ChangeTicketDelivery = &delivery
+		}
+		if result.CredentialLeases != nil {
+			leases := make([]CredentialLease, len(result.CredentialLeases))
+			for j, lease := range result.CredentialLeases {
+				lease.Credential = p.credential(lease.Credential)
+				lease.LeaseID = ""
+				lease.Error = ""
+				leases[j] = lease
+			}
+			result.CredentialLeases = leases
+		}
+		results[i] = result
+	}
+	a.Results = results
+
+	outputs := make([]Output, len(a.Outputs))
+	for i, output := range a.Outputs {
+		output.Namespace = p.namespace(output.Namespace)
+		output.Installation = p.installation(output.Installation)
+		output.Name = p.output(output.Name)
+		output.Key = p.secret(output.Key)
+		// The value hash is kept, so that changes to the value can be seen
+		output.Value = nil
+		output.Chunks = 0
+		output.BlobStore = ""
+		output.BlobKey = ""
+		outputs[i] = output
+	}
+	a.Outputs = outputs
+
+	steps := make([]StepResult, len(a.StepResults))
+	for i, step := range a.StepResults {
+		step.Namespace = p.namespace(step.Namespace)
+		step.Installation = p.installation(step.Installation)
+		step.Description = ""
+		step.Error = ""
+		step.Logs = ""
+		steps[i] = step
+	}
+	a.StepResults = steps
+
+	a.SecretReferences = a.listSecretReferences()
+	a.Anonymized = true
+	return a
+}
+
+// sortedKeys returns the keys of a map in order, so that the pseudonyms are
+// assigned in the same order every time that a bundle is anonymized.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/secrets"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationArchive_Anonymize(t *testing.T) {
+	i := NewInstallation("acme-prod", "billing-db")
+	i.Bundle.Repository = "registry.acme.internal/team/mybuns"
+	i.Labels = map[string]string{"team": "acme-payments"}
+	i.CredentialSets = []string{"acme-azure"}
+	i.ParameterSets = []string{"billing-db-config"}
+	i.Parameters.Parameters = []secrets.Strategy{
+		{Name: "admin-password", Source: secrets.Source{Key: secrets.SourceSecret, Value: "billing-db-admin-password"}},
+	}
+
+	run := i.NewRun(cnab.ActionInstall)
+	run.BundleReference = "registry.acme.internal/team/mybuns:v1.0.0"
+	run.Bundle = bundle.Bundle{
+		Name:             "mybuns",
+		InvocationImages: []bundle.InvocationImage{{BaseImage: bundle.BaseImage{Image: "registry.acme.internal/team/mybuns-installer:v1.0.0"}}},
+		Images:           map[string]bundle.Image{"app": {BaseImage: bundle.BaseImage{Image: "localhost:5000/app:v1.0.0"}}},
+		Parameters: map[string]bundle.Parameter{"admin-password": {
+			Definition:  "admin-password",
+			Destination: &bundle.Location{EnvironmentVariable: "BILLING_ADMIN_PASSWORD", Path: "/etc/billing/admin-password"},
+		}},
+		Credentials: map[string]bundle.Credential{"acme-kubeconfig": {Location: bundle.Location{Path: "/home/acme/.kube/config"}}},
+		Outputs:     map[string]bundle.Output{"billing-connstr": {Definition: "admin-password", Path: "/cnab/app/outputs/billing-connstr"}},
+		Definitions: definition.Definitions{"admin-password": &definition.Schema{Type: "string"}},
+		Custom: map[string]interface{}{
+			"sh.porter": map[string]interface{}{"manifest": "bmFtZTogYmlsbGluZy1kYg==", "version": "v1.0.0"},
+			cnab.DependenciesV1ExtensionKey: map[string]interface{}{
+				"requires": map[string]interface{}{"db": map[string]interface{}{"bundle": "registry.acme.internal/team/mysql:v1"}},
+			},
+			cnab.ParameterSourcesExtensionKey: map[string]interface{}{"admin-password": "billing"},
+		},
+	}
+	run.Parameters.Parameters = i.Parameters.Parameters
+	run.CredentialSets = i.CredentialSets
+	run.ParameterSets = i.ParameterSets
+	run.Labels = i.Labels
+	run.ChangeTicket = "ACME-1234"
+	run.Metadata = &RunMetadata{Repository: "github.com/acme/billing", User: "acme-deployer"}
+	run.Trigger = &RunTrigger{Type: RunTriggerRegistryPush, Rule: "billing-patches", Reference: "registry.acme.com/billing:v1.0.1"}
+	run.Environment = &RunEnvironment{Images: map[string]string{"app": "registry.acme.internal/app@sha256:abc"}}
+	run.OutputDependencies = []RunOutputDependency{{Parameter: "admin-password", Namespace: "acme-prod", Installation: "billing-db", Output: "billing-connstr"}}
+	run.AddNote("billing release")
+	result := run.NewResult(cnab.StatusSucceeded)
+	result.Message = "billing-db is ready"
+	result.OutputMetadata.SetGeneratedByBundle("billing-connstr", true)
+	result.CredentialLeases = []CredentialLease{{Credential: "acme-kubeconfig", LeaseID: "acme/lease/1"}}
+	output := result.NewOutput("billing-connstr", []byte("billing-db.acme.internal:5432"))
+	output.Key = "billing-db-connstr"
+
+	archive := InstallationArchive{
+		SchemaType:    InstallationArchiveSchemaType,
+		SchemaVersion: InstallationSchemaVersion,
+		Installation:  i,
+		Runs:          []Run{run},
+		Results:       []Result{result},
+		Outputs:       []Output{output},
+		StepResults:   []StepResult{run.NewStepResult(StepResult{Mixin: "exec", Description: "Create the billing database", Logs: "acme"})},
+	}
+	archive.SecretReferences = archive.listSecretReferences()
+
+	var pseudonyms Pseudonyms
+	got := archive.Anonymize(&pseudonyms)
+
+	assert.Equal(t, "namespace-1", got.Installation.Namespace)
+	assert.Equal(t, "installation-1", got.Installation.Name)
+	assert.Equal(t, "registry-1.example.com/team/mybuns", got.Installation.Bundle.Repository)
+	assert.Equal(t, "internal-parameter-set-installation-1", got.Installation.Parameters.Name)
+	assert.Equal(t, []string{"credential-set-1"}, got.Installation.CredentialSets)
+	assert.Equal(t, []string{"parameter-set-1"}, got.Installation.ParameterSets)
+	assert.Nil(t, got.Installation.Labels)
+	assert.Equal(t, "param-1", got.Installation.Parameters.Parameters[0].Name)
+	assert.Equal(t, []string{"secret-1", "secret-2"}, got.SecretReferences)
+	assert.True(t, got.Anonymized)
+
+	gotRun := got.Runs[0]
+	assert.Equal(t, "installation-1", gotRun.Installation)
+	assert.Equal(t, "registry-1.example.com/team/mybuns:v1.0.0", gotRun.BundleReference)
+	assert.Equal(t, "registry-1.example.com/team/mybuns-installer:v1.0.0", gotRun.Bundle.InvocationImages[0].Image)
+	assert.Equal(t, "registry-2.example.com/app:v1.0.0", gotRun.Bundle.Images["app"].Image)
+	assert.Equal(t, map[string]bundle.Parameter{"param-1": {
+		Definition:  "param-1",
+		Destination: &bundle.Location{EnvironmentVariable: "ENV_1", Path: "/path-1"},
+	}}, gotRun.Bundle.Parameters)
+	assert.Equal(t, map[string]bundle.Credential{"credential-1": {Location: bundle.Location{Path: "/path-3"}}}, gotRun.Bundle.Credentials)
+	assert.Equal(t, bundle.Output{Definition: "param-1", Path: "/cnab/app/outputs/path-2"}, gotRun.Bundle.Outputs["output-1"], "outputs that share the definition of a parameter should be updated")
+	assert.Contains(t, gotRun.Bundle.Definitions, "param-1")
+	assert.Equal(t, map[string]interface{}{"version": "v1.0.0"}, gotRun.Bundle.Custom["sh.porter"], "the embedded manifest should be removed")
+	assert.NotContains(t, gotRun.Bundle.Custom, cnab.ParameterSourcesExtensionKey, "unknown extensions should be removed")
+	assert.Equal(t, []string{"credential-set-1"}, gotRun.CredentialSets)
+	assert.Equal(t, []string{"parameter-set-1"}, gotRun.ParameterSets)
+	assert.Equal(t, []RunOutputDependency{{Parameter: "param-1", Namespace: "namespace-1", Installation: "installation-1", Output: "output-1"}}, gotRun.OutputDependencies)
+	assert.Equal(t, map[string]string{"app": "registry-1.example.com/app@sha256:abc"}, gotRun.Environment.Images)
+	assert.Nil(t, gotRun.Metadata)
+	assert.Nil(t, gotRun.Trigger)
+	assert.Empty(t, gotRun.ChangeTicket)
+	assert.Equal(t, "namespace-1", got.Results[0].Namespace)
+	assert.Contains(t, got.Results[0].OutputMetadata, "output-1")
+	assert.Equal(t, "credential-1", got.Results[0].CredentialLeases[0].Credential)
+	assert.Equal(t, "installation-1", got.Outputs[0].Installation)
+	assert.Equal(t, "output-1", got.Outputs[0].Name)
+	assert.Equal(t, output.ValueHash, got.Outputs[0].ValueHash, "the hash of the value should be kept")
+	assert.Equal(t, "installation-1", got.StepResults[0].Installation)
+
+	data, err := json.Marshal(got)
+	require.NoError(t, err)
+	for _, name := range []string{"acme", "billing", "admin-password", "kube"} {
+		assert.NotContains(t, string(data), name, "the anonymized archive should not include the original names")
+	}
+
+	// Check that the original archive was not modified
+	assert.Equal(t, "billing-db", archive.Installation.Name)
+	assert.Equal(t, "admin-password", archive.Runs[0].Parameters.Parameters[0].Name)
+	assert.Contains(t, archive.Runs[0].Bundle.Parameters, "admin-password")
+
+	t.Run("stable pseudonyms", func(t *testing.T) {
+		other := archive
+		other.Installation = NewInstallation("acme-prod", "billing-api")
+		other.Runs, other.Results, other.Outputs, other.StepResults = nil, nil, nil, nil
+
+		// Start from the pseudonyms saved by a previous export
+		data, err := json.Marshal(pseudonyms)
+		require.NoError(t, err)
+		var saved Pseudonyms
+		require.NoError(t, json.Unmarshal(data, &saved))
+
+		got := other.Anonymize(&saved)
+		assert.Equal(t, "namespace-1", got.Installation.Namespace, "the same namespace should get the same pseudonym")
+		assert.Equal(t, "installation-2", got.Installation.Name)
+		assert.Equal(t, map[string]string{"billing-db": "installation-1", "billing-api": "installation-2"}, saved.Installations)
+	})
+
+	require.EqualError(t, got.Validate(), "the archive was anonymized and cannot be imported")
+}
+
+// TestInstallationArchive_Anonymize_AllFields fails when a field is added to
+// one of the documents in an archive, so that whoever adds it decides whether
+// it identifies the installation and updates Anonymize.
+func TestInstallationArchive_Anonymize_AllFields(t *testing.T) {
+	// The fields of each document that Anonymize replaces or removes, or that
+	// are safe to share as they are.
+	reviewed := map[reflect.Type][]string{
+		reflect.TypeOf(Installation{}): {"ID", "InstallationSpec", "Status"},
+		reflect.TypeOf(InstallationSpec{}): {
+			"SchemaVersion", "Name", "Namespace", "Uninstalled", "Bundle", "Custom", "Labels",
+			"CredentialSets", "ParameterSets", "Parameters", "Status",
+		},
+		reflect.TypeOf(OCIReferenceParts{}): {"Repository", "Version", "Digest", "Tag"},
+		reflect.TypeOf(InstallationStatus{}): {
+			"RunID", "Action", "ResultID", "ResultStatus", "Created", "Modified", "Installed", "Uninstalled",
+			"BundleReference", "BundleVersion", "BundleDigest",
+		},
+		reflect.TypeOf(Run{}): {
+			"SchemaVersion", "ID", "Created", "Namespace", "Installation", "Revision", "Action", "Bundle",
+			"BundleReference", "BundleDigest", "ParameterOverrides", "CredentialSets", "ParameterSets",
+			"ParameterSetRevisions", "EphemeralOutputs", "ChangeTicket", "Timeout", "RestoredSnapshot", "OutputDependencies", "Labels",
+			"Parameters", "Environment", "Metadata", "Trigger", "Custom", "Notes", "Heartbeat",
+		},
+		reflect.TypeOf(RunEnvironment{}):       {"PorterVersion", "PorterCommit", "Driver", "DriverVersion", "Mixins", "Images"},
+		reflect.TypeOf(RunOutputDependency{}):  {"Parameter", "Namespace", "Installation", "Output"},
+		reflect.TypeOf(ParameterSetRevision{}): {"Namespace", "Name", "Modified"},
+		reflect.TypeOf(Result{}): {
+			"SchemaVersion", "ID", "Created", "Namespace", "Installation", "RunID", "Message", "Status",
+			"OutputMetadata", "Custom", "ChangeTicketDelivery", "CredentialLeases", "Pending",
+		},
+		reflect.TypeOf(ChangeTicketDelivery{}): {"Ticket", "Status", "Attempts", "StatusCode", "Error", "Completed"},
+		reflect.TypeOf(CredentialLease{}):      {"Credential", "LeaseID", "Issued", "Revoked", "RevokedAt", "Error"},
+		reflect.TypeOf(Output{}): {
+			"SchemaVersion", "Name", "Namespace", "Installation", "RunID", "ResultID", "Created", "Key", "Value", "Chunks",
+			"Size", "BlobStore", "BlobKey", "ValueHash",
+		},
+		reflect.TypeOf(StepResult{}): {
+			"Namespace", "Installation", "RunID", "Index", "Description", "Mixin", "Started", "Stopped", "Status",
+			"ExitCode", "Error", "Logs", "LogsTruncated",
+		},
+	}
+
+	for docType, fields := range reviewed {
+		known := make(map[string]bool, len(fields))
+		for _, field := range fields {
+			known[field] = true
+		}
+		for i := 0; i < docType.NumField(); i++ {
+			field := docType.Field(i)
+			assert.True(t, known[field.Name], "%s.%s is not handled by Anonymize. Anonymize or remove it when it can identify the installation, then add it to this test", docType.Name(), field.Name)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"get.porter.sh/porter/pkg/secrets"
+	"github.com/cnabio/cnab-go/schema"
+)
+
+// InstallationArchiveSchemaType is the schemaType of an installation archive.
+const InstallationArchiveSchemaType = "InstallationArchive"
+
+// InstallationArchive is a self-contained copy of an installation and its
+// history, used to move an installation between storage backends. Sensitive
+// values are not included, the archive references them by their key in the
+// secret store instead.
+type InstallationArchive struct {
+	// SchemaType of the document, always InstallationArchiveSchemaType.
+	SchemaType string `json:"schemaType"`
+
+	// SchemaVersion of the installation documents in the archive.
+	SchemaVersion schema.Version `json:"schemaVersion"`
+
+	// Installation that was exported.
+	Installation Installation `json:"installation"`
+
+	// Runs of the installation, sorted by ID.
+	Runs []Run `json:"runs,omitempty"`
+
+	// Results of the runs, sorted by ID.
+	Results []Result `json:"results,omitempty"`
+
+	// Outputs generated by the runs. The value of outputs that were saved
+	// as a stream is included inline.
+	Outputs []Output `json:"outputs,omitempty"`
+
+	// StepResults of the runs.
+	StepResults []StepResult `json:"stepResults,omitempty"`
+
+	// SecretReferences are the keys of the sensitive values, in the secret
+	// store, that are referenced by the installation and its history. They
+	// must be available in the secret store used after the archive is imported.
+	SecretReferences []string `json:"secretReferences,omitempty"`
+
+	// Anonymized indicates that the names in the archive were replaced with
+	// pseudonyms. Anonymized archives cannot be imported.
+	Anonymized bool `json:"anonymized,omitempty"`
+}
+
+// ExportInstallation copies an installation and all of its runs, results,
+// outputs and step results into an archive.
+func (s InstallationStore) ExportInstallation(ctx context.Context, namespace string, name string) (InstallationArchive, error) {
+	inst, err := s.GetInstallation(ctx, namespace, name)
+	if err != nil {
+		return InstallationArchive{}, err
+	}
+
+	archive := InstallationArchive{
+		SchemaType:    InstallationArchiveSchemaType,
+		SchemaVersion: InstallationSchemaVersion,
+		Installation:  inst,
+	}
+
+	runs, results, err := s.ListRuns(ctx, namespace, name)
+	if err != nil {
+		return InstallationArchive{}, fmt.Errorf("error listing the runs of installation %s/%s: %w", namespace, name, err)
+	}
+	archive.Runs = runs
+
+	for _, run := range runs {
+		archive.Results = append(archive.Results, results[run.ID]...)
+
+		steps, err := s.ListStepResults(ctx, run.ID)
+		if err != nil {
+			return InstallationArchive{}, fmt.Errorf("error listing the step results of run %s: %w", run.ID, err)
+		}
+		archive.StepResults = append(archive.StepResults, steps...)
+	}
+	sort.Slice(archive.Results, func(i, j int) bool {
+		return archive.Results[i].ID < archive.Results[j].ID
+	})
+
+	for _, result := range archive.Results {
+		outputs, err := s.ListOutputs(ctx, result.ID)
+		if err != nil {
+			return InstallationArchive{}, fmt.Errorf("error listing the outputs of result %s: %w", result.ID, err)
+		}
+
+		for _, output := range outputs {
+			output, err = ReadOutputValue(ctx, s, output)
+			if err != nil {
+				return InstallationArchive{}, fmt.Errorf("error reading the value of output %s of result %s: %w", output.Name, result.ID, err)
+			}
+			archive.Outputs = append(archive.Outputs, output)
+		}
+	}
+
+	archive.SecretReferences = archive.listSecretReferences()
+	return archive, nil
+}
+
+// ImportInstallation saves the installation, and its history, from an archive
+// created with ExportInstallation. The installation must not already exist.
+func (s InstallationStore) ImportInstallation(ctx context.Context, archive InstallationArchive) error {
+	if err := checkWritable(s.store, CollectionInstallations, "insert"); err != nil {
+		return err
+	}
+
+	if err := archive.Validate(); err != nil {
+		return err
+	}
+
+	inst := archive.Installation
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionInstallations, inst.Namespace, inst.Name); err != nil {
+		return err
+	}
+
+	_, err := s.GetInstallation(ctx, inst.Namespace, inst.Name)
+	if err == nil {
+		return fmt.Errorf("installation %s already exists", inst)
+	} else if !errors.Is(err, ErrNotFound{}) {
+		return err
+	}
+
+	if err := s.InsertInstallation(ctx, inst); err != nil {
+		return fmt.Errorf("error saving installation %s: %w", inst, err)
+	}
+
+	for _, run := range archive.Runs {
+		if err := s.InsertRun(ctx, run); err != nil {
+			return fmt.Errorf("error saving run %s: %w", run.ID, err)
+		}
+	}
+
+	for _, result := range archive.Results {
+		if err := s.InsertResult(ctx, result); err != nil {
+			return fmt.Errorf("error saving result %s: %w", result.ID, err)
+		}
+	}
+
+	for _, output := range archive.Outputs {
+		// Save large outputs in chunks, so that they fit within the document size limits of the backing store
+		if len(output.Value) > OutputChunkSize {
+			err = s.InsertOutputStream(ctx, output, bytes.NewReader(output.Value))
+		} else {
+			err = s.InsertOutput(ctx, output)
+		}
+		if err != nil {
+			return fmt.Errorf("error saving output %s of result %s: %w", output.Name, output.ResultID, err)
+		}
+	}
+
+	if err := s.InsertStepResults(ctx, archive.StepResults); err != nil {
+		return fmt.Errorf("error saving the step results: %w", err)
+	}
+
+	return nil
+}
+
+// Validate that the archive can be imported.
+func (a InstallationArchive) Validate() error {
+	if a.SchemaType != InstallationArchiveSchemaType {
+		return fmt.Errorf("invalid schemaType %q, expected %s", a.SchemaType, InstallationArchiveSchemaType)
+	}
+
+	if a.SchemaVersion != InstallationSchemaVersion {
+		return fmt.Errorf("the archive was exported with installation schema version %s but this version of Porter supports %s. Export the installation with the same version of Porter that is importing it", a.SchemaVersion, InstallationSchemaVersion)
+	}
+
+	if a.Anonymized {
+		return errors.New("the archive was anonymized and cannot be imported")
+	}
+
+	if a.Installation.Name == "" {
+		return errors.New("the archive does not contain an installation")
+	}
+
+	inst := a.Installation
+	for _, run := range a.Runs {
+		if run.Namespace != inst.Namespace || run.Installation != inst.Name {
+			return fmt.Errorf("run %s belongs to installation %s/%s instead of %s", run.ID, run.Namespace, run.Installation, inst)
+		}
+	}
+	for _, result := range a.Results {
+		if result.Namespace != inst.Namespace || result.Installation != inst.Name {
+			return fmt.Errorf("result %s belongs to installation %s/%s instead of %s", result.ID, result.Namespace, result.Installation, inst)
+		}
+	}
+	for _, output := range a.Outputs {
+		if output.Namespace != inst.Namespace || output.Installation != inst.Name {
+			return fmt.Errorf("output %s of result %s belongs to installation %s/%s instead of %s", output.Name, output.ResultID, output.Namespace, output.Installation, inst)
+		}
+	}
+
+	return nil
+}
+
+// listSecretReferences returns the sorted keys of the sensitive values in the
+// secret store that are referenced by the archive.
+func (a InstallationArchive) listSecretReferences() []string {
+	keys := map[string]struct{}{}
+	addParameters := func(params ParameterSet) {
+		for _, param := range params.Parameters {
+			if param.Source.Key == secrets.SourceSecret {
+				keys[param.Source.Value] = struct{}{}
+			}
+		}
+	}
+
+	addParameters(a.Installation.Parameters)
+	for _, run := range a.Runs {
+		addParameters(run.GetParameters())
+		addParameters(run.ParameterOverrides)
+	}
+	for _, output := range a.Outputs {
+		if output.Key != "" {
+			keys[output.Key] = struct{}{}
+		}
+	}
+
+	refs := make([]string, 0, len(keys))
+	for key := range keys {
+		refs = append(refs, key)
+	}
+	sort.Strings(refs)
+	return refs
+}
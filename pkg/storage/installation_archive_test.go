@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationStore_ExportImportInstallation(t *testing.T) {
+	ctx := context.Background()
+	src := NewTestInstallationProvider(t)
+	defer src.Close()
+
+	i := src.CreateInstallation(NewInstallation("dev", "mybuns"), func(i *Installation) {
+		i.Parameters.Parameters = []secrets.Strategy{
+			{Name: "password", Source: secrets.Source{Key: secrets.SourceSecret, Value: "mybuns-password"}},
+		}
+	})
+	run := src.CreateRun(i.NewRun(cnab.ActionInstall))
+	result := src.CreateResult(run.NewResult(cnab.StatusSucceeded))
+	src.CreateOutput(result.NewOutput("endpoint", []byte("db.example.com")))
+	src.CreateOutput(result.NewOutput("connstr", nil), func(o *Output) {
+		o.Key = "mybuns-connstr"
+	})
+	kubeconfig := bytes.Repeat([]byte("0123456789"), OutputChunkSize/5) // 2 chunks
+	require.NoError(t, src.InsertOutputStream(ctx, result.NewOutput("kubeconfig", nil), bytes.NewReader(kubeconfig)))
+	require.NoError(t, src.InsertStepResults(ctx, []StepResult{run.NewStepResult(StepResult{Index: 0, Mixin: "exec", Status: StepStatusSucceeded})}))
+
+	archive, err := src.ExportInstallation(ctx, "dev", "mybuns")
+	require.NoError(t, err)
+	assert.Equal(t, InstallationArchiveSchemaType, archive.SchemaType)
+	require.Len(t, archive.Runs, 1)
+	require.Len(t, archive.Results, 1)
+	require.Len(t, archive.Outputs, 3)
+	require.Len(t, archive.StepResults, 1)
+	assert.Equal(t, []string{"mybuns-connstr", "mybuns-password"}, archive.SecretReferences)
+
+	dest := NewTestInstallationProvider(t)
+	defer dest.Close()
+	require.NoError(t, dest.ImportInstallation(ctx, archive))
+
+	gotInst, err := dest.GetInstallation(ctx, "dev", "mybuns")
+	require.NoError(t, err)
+	assert.Equal(t, i.ID, gotInst.ID)
+
+	gotRun, err := dest.GetLastRun(ctx, "dev", "mybuns")
+	require.NoError(t, err)
+	assert.Equal(t, run.ID, gotRun.ID)
+
+	outputs, err := dest.GetLastOutputs(ctx, "dev", "mybuns")
+	require.NoError(t, err)
+	require.Equal(t, 3, outputs.Len())
+	gotKubeconfig, ok := outputs.GetByName("kubeconfig")
+	require.True(t, ok)
+	assert.True(t, gotKubeconfig.IsChunked(), "large outputs should be saved in chunks")
+	gotKubeconfig, err = ReadOutputValue(ctx, dest, gotKubeconfig)
+	require.NoError(t, err)
+	assert.Equal(t, kubeconfig, gotKubeconfig.Value)
+
+	steps, err := dest.ListStepResults(ctx, run.ID)
+	require.NoError(t, err)
+	assert.Len(t, steps, 1)
+
+	err = dest.ImportInstallation(ctx, archive)
+	require.ErrorContains(t, err, "installation dev/mybuns already exists")
+}
+
+func TestInstallationArchive_Validate(t *testing.T) {
+	i := NewInstallation("dev", "mybuns")
+	valid := InstallationArchive{
+		SchemaType:    InstallationArchiveSchemaType,
+		SchemaVersion: InstallationSchemaVersion,
+		Installation:  i,
+		Runs:          []Run{i.NewRun(cnab.ActionInstall)},
+	}
+	require.NoError(t, valid.Validate())
+
+	archive := valid
+	archive.SchemaType = "Installation"
+	require.ErrorContains(t, archive.Validate(), "invalid schemaType")
+
+	archive = valid
+	archive.SchemaVersion = "1.0.0"
+	require.ErrorContains(t, archive.Validate(), "installation schema version 1.0.0")
+
+	archive = valid
+	archive.Runs = []Run{NewInstallation("dev", "other").NewRun(cnab.ActionInstall)}
+	require.ErrorContains(t, archive.Validate(), "belongs to installation dev/other")
+}
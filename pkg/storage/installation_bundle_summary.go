@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"get.porter.sh/porter/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// BundleInstallationSummary is the number of installations of a bundle, and
+// how many installations are at each version of the bundle.
+type BundleInstallationSummary struct {
+	// Repository of the bundle.
+	Repository string `json:"_id"`
+
+	// Count of the installations of the bundle.
+	Count int `json:"count"`
+
+	// OldestModified is when the least recently modified installation was last modified.
+	OldestModified time.Time `json:"oldestModified"`
+
+	// NewestModified is when the most recently modified installation was last modified.
+	NewestModified time.Time `json:"newestModified"`
+
+	// Versions of the bundle that are installed.
+	Versions []BundleVersionSummary `json:"versions"`
+}
+
+// BundleVersionSummary is the number of installations at a version of a bundle.
+type BundleVersionSummary struct {
+	// Version of the bundle that was last run by the installations. Empty when
+	// the installations have not been run.
+	Version string `json:"version"`
+
+	// Count of the installations at the version.
+	Count int `json:"count"`
+
+	// OldestModified is when the least recently modified installation was last modified.
+	OldestModified time.Time `json:"oldestModified"`
+
+	// NewestModified is when the most recently modified installation was last modified.
+	NewestModified time.Time `json:"newestModified"`
+}
+
+// SummarizeInstallationsByBundle groups the installations that match the
+// list options by bundle, counting the installations at each version of the
+// bundle. The installations are grouped by the storage backend, the skip and
+// limit options apply to the bundles. Bundles are sorted by repository, and
+// versions are sorted in the order returned by the storage backend.
+func (s InstallationStore) SummarizeInstallationsByBundle(ctx context.Context, listOptions ListOptions) ([]BundleInstallationSummary, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	findOpts := listOptions.ToFindOptions()
+	if listOptions.Namespace == "*" {
+		// The counts combine installations from several namespaces, so only
+		// summarize the namespaces that may be read
+		namespaces, err := authorizedNamespaces(ctx, s.store, s.authz, CollectionInstallations, findOpts.Filter)
+		if err != nil {
+			return nil, span.Error(err)
+		}
+		if namespaces != nil {
+			findOpts.Filter["namespace"] = bson.M{"$in": namespaces}
+		}
+	} else if err := authorize(ctx, s.authz, VerbRead, CollectionInstallations, listOptions.Namespace, ""); err != nil {
+		return nil, span.Error(err)
+	}
+
+	pipeline := []bson.D{
+		// Select the installations to summarize
+		{{Key: "$match", Value: findOpts.Filter}},
+		// Count the installations by the version of the bundle that was last run
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "repository", Value: "$bundle.repository"},
+				{Key: "version", Value: "$status.bundleVersion"},
+			}},
+			{Key: "count", Value: bson.M{"$sum": 1}},
+			{Key: "oldestModified", Value: bson.M{"$min": "$status.modified"}},
+			{Key: "newestModified", Value: bson.M{"$max": "$status.modified"}},
+		}}},
+		{{Key: "$sort", Value: bson.D{
+			{Key: "_id.repository", Value: 1},
+			{Key: "_id.version", Value: 1},
+		}}},
+		// Roll up the versions of each bundle
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$_id.repository"},
+			{Key: "count", Value: bson.M{"$sum": "$count"}},
+			{Key: "oldestModified", Value: bson.M{"$min": "$oldestModified"}},
+			{Key: "newestModified", Value: bson.M{"$max": "$newestModified"}},
+			{Key: "versions", Value: bson.M{"$push": bson.D{
+				{Key: "version", Value: "$_id.version"},
+				{Key: "count", Value: "$count"},
+				{Key: "oldestModified", Value: "$oldestModified"},
+				{Key: "newestModified", Value: "$newestModified"},
+			}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+	if listOptions.Skip > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$skip", Value: listOptions.Skip}})
+	}
+	if listOptions.Limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: listOptions.Limit}})
+	}
+
+	var out []BundleInstallationSummary
+	err := s.store.Aggregate(ctx, CollectionInstallations, AggregateOptions{Pipeline: pipeline}, &out)
+	return out, span.Error(err)
+}
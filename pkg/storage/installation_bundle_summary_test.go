@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationStore_SummarizeInstallationsByBundle(t *testing.T) {
+	ctx := context.Background()
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	createInstallation := func(namespace string, name string, repository string, version string, modified time.Time) {
+		cp.CreateInstallation(NewInstallation(namespace, name), func(i *Installation) {
+			i.Bundle.Repository = repository
+			i.Status.BundleVersion = version
+			i.Status.Modified = modified
+		})
+	}
+	createInstallation("dev", "mysql1", "example.com/mysql", "0.1.0", start)
+	createInstallation("dev", "mysql2", "example.com/mysql", "0.2.0", start.Add(2*time.Hour))
+	createInstallation("test", "mysql3", "example.com/mysql", "0.2.0", start.Add(time.Hour))
+	createInstallation("dev", "redis", "example.com/redis", "1.0.0", start.Add(3*time.Hour))
+	createInstallation("dev", "redis-new", "example.com/redis", "", start.Add(4*time.Hour))
+
+	t.Run("all namespaces", func(t *testing.T) {
+		summaries, err := cp.SummarizeInstallationsByBundle(ctx, ListOptions{Namespace: "*"})
+		require.NoError(t, err)
+		require.Len(t, summaries, 2)
+
+		mysql := summaries[0]
+		assert.Equal(t, "example.com/mysql", mysql.Repository)
+		assert.Equal(t, 3, mysql.Count)
+		assert.True(t, start.Equal(mysql.OldestModified), "unexpected oldest modified %s", mysql.OldestModified)
+		assert.True(t, start.Add(2*time.Hour).Equal(mysql.NewestModified), "unexpected newest modified %s", mysql.NewestModified)
+		require.Len(t, mysql.Versions, 2)
+		assert.Equal(t, "0.1.0", mysql.Versions[0].Version)
+		assert.Equal(t, 1, mysql.Versions[0].Count)
+		assert.Equal(t, "0.2.0", mysql.Versions[1].Version)
+		assert.Equal(t, 2, mysql.Versions[1].Count)
+		assert.True(t, start.Add(time.Hour).Equal(mysql.Versions[1].OldestModified), "unexpected oldest modified %s", mysql.Versions[1].OldestModified)
+
+		redis := summaries[1]
+		assert.Equal(t, "example.com/redis", redis.Repository)
+		assert.Equal(t, 2, redis.Count)
+		require.Len(t, redis.Versions, 2)
+		assert.Equal(t, "", redis.Versions[0].Version, "installations that have not been run should be counted without a version")
+	})
+
+	t.Run("filtered", func(t *testing.T) {
+		summaries, err := cp.SummarizeInstallationsByBundle(ctx, ListOptions{Namespace: "test"})
+		require.NoError(t, err)
+		require.Len(t, summaries, 1)
+		assert.Equal(t, 1, summaries[0].Count)
+	})
+
+	t.Run("paginated", func(t *testing.T) {
+		summaries, err := cp.SummarizeInstallationsByBundle(ctx, ListOptions{Namespace: "*", Skip: 1, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, summaries, 1)
+		assert.Equal(t, "example.com/redis", summaries[0].Repository)
+	})
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// InstallationNode identifies an installation in the dependency graph.
+type InstallationNode struct {
+	Namespace string `json:"namespace" yaml:"namespace"`
+	Name      string `json:"name" yaml:"name"`
+}
+
+func (n InstallationNode) String() string {
+	if n.Namespace == "" {
+		return n.Name
+	}
+	return n.Namespace + "/" + n.Name
+}
+
+// InstallationEdge records that an installation, the consumer, used an output
+// of another installation, the producer, to set one of its parameters.
+type InstallationEdge struct {
+	// Consumer is the installation that used the output.
+	Consumer InstallationNode `json:"consumer" yaml:"consumer"`
+
+	// Parameter of the consumer that was set with the output.
+	Parameter string `json:"parameter" yaml:"parameter"`
+
+	// Producer is the installation that generated the output.
+	Producer InstallationNode `json:"producer" yaml:"producer"`
+
+	// Output is the name of the output.
+	Output string `json:"output" yaml:"output"`
+}
+
+// InstallationGraph is the graph of the dependencies between installations,
+// formed by the outputs that installations use to set their parameters.
+type InstallationGraph struct {
+	Nodes []InstallationNode `json:"nodes" yaml:"nodes"`
+	Edges []InstallationEdge `json:"edges" yaml:"edges"`
+}
+
+// AddNode adds an installation to the graph, when it isn't already present.
+func (g *InstallationGraph) AddNode(node InstallationNode) {
+	for _, n := range g.Nodes {
+		if n == node {
+			return
+		}
+	}
+	g.Nodes = append(g.Nodes, node)
+}
+
+// AddEdge adds a dependency to the graph, along with the installations that it
+// connects, when it isn't already present.
+func (g *InstallationGraph) AddEdge(edge InstallationEdge) {
+	g.AddNode(edge.Consumer)
+	g.AddNode(edge.Producer)
+	for _, e := range g.Edges {
+		if e == edge {
+			return
+		}
+	}
+	g.Edges = append(g.Edges, edge)
+}
+
+// Consumers returns the dependencies on the outputs of the installation, that
+// is, what consumes the installation's outputs.
+func (g InstallationGraph) Consumers(node InstallationNode) []InstallationEdge {
+	var edges []InstallationEdge
+	for _, e := range g.Edges {
+		if e.Producer == node {
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// Dependencies returns the dependencies of the installation on the outputs of
+// other installations.
+func (g InstallationGraph) Dependencies(node InstallationNode) []InstallationEdge {
+	var edges []InstallationEdge
+	for _, e := range g.Edges {
+		if e.Consumer == node {
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// Sort orders the nodes by namespace and name, and the edges by their
+// consumer, parameter and producer, so that the graph is printed consistently.
+func (g *InstallationGraph) Sort() {
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		return g.Nodes[i].String() < g.Nodes[j].String()
+	})
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.Consumer != b.Consumer {
+			return a.Consumer.String() < b.Consumer.String()
+		}
+		if a.Parameter != b.Parameter {
+			return a.Parameter < b.Parameter
+		}
+		return a.Producer.String() < b.Producer.String()
+	})
+}
+
+// GetRunEdges returns the dependencies of a run on the outputs of other
+// installations, from the parameters that were resolved with the bundle's
+// installation output parameter sources.
+func GetRunEdges(run Run) []InstallationEdge {
+	consumer := InstallationNode{Namespace: run.Namespace, Name: run.Installation}
+
+	var edges []InstallationEdge
+	for _, dep := range run.OutputDependencies {
+		edges = append(edges, InstallationEdge{
+			Consumer:  consumer,
+			Parameter: dep.Parameter,
+			Producer:  InstallationNode{Namespace: dep.Namespace, Name: dep.Installation},
+			Output:    dep.Output,
+		})
+	}
+	return edges
+}
+
+// BuildInstallationGraph builds the dependency graph of the installations that
+// match the list options, from the most recent run of each installation.
+// Installations that have been uninstalled no longer depend on the outputs of
+// other installations, but may still be the producer of an output.
+func BuildInstallationGraph(ctx context.Context, installations InstallationProvider, opts ListOptions) (InstallationGraph, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	list, err := installations.ListInstallations(ctx, opts)
+	if err != nil {
+		return InstallationGraph{}, span.Error(fmt.Errorf("could not list installations: %w", err))
+	}
+
+	var graph InstallationGraph
+	for _, inst := range list {
+		graph.AddNode(InstallationNode{Namespace: inst.Namespace, Name: inst.Name})
+		if inst.Status.RunID == "" || inst.IsUninstalled() {
+			continue
+		}
+
+		run, err := installations.GetRun(ctx, inst.Status.RunID)
+		if err != nil {
+			return InstallationGraph{}, span.Error(fmt.Errorf("could not retrieve the last run of installation %s: %w", inst, err))
+		}
+
+		for _, edge := range GetRunEdges(run) {
+			graph.AddEdge(edge)
+		}
+	}
+
+	graph.Sort()
+	return graph, nil
+}
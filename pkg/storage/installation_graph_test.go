@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRunEdges(t *testing.T) {
+	run := NewInstallation("dev", "myapp").NewRun(cnab.ActionInstall)
+	run.OutputDependencies = []RunOutputDependency{
+		{Parameter: "dns-zone", Namespace: "dev", Installation: "dns", Output: "zone"},
+		{Parameter: "vpc-id", Namespace: "platform", Installation: "network", Output: "vpc-id"},
+	}
+
+	myapp := InstallationNode{Namespace: "dev", Name: "myapp"}
+	want := []InstallationEdge{
+		{Consumer: myapp, Parameter: "dns-zone", Producer: InstallationNode{Namespace: "dev", Name: "dns"}, Output: "zone"},
+		{Consumer: myapp, Parameter: "vpc-id", Producer: InstallationNode{Namespace: "platform", Name: "network"}, Output: "vpc-id"},
+	}
+	assert.Equal(t, want, GetRunEdges(run))
+}
+
+func TestBuildInstallationGraph(t *testing.T) {
+	ctx := context.Background()
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	network := cp.CreateInstallation(NewInstallation("platform", "network"))
+	run := cp.CreateRun(network.NewRun(cnab.ActionInstall))
+	cp.CreateResult(run.NewResult(cnab.StatusSucceeded))
+
+	addConsumer := func(namespace string, name string, deps ...RunOutputDependency) {
+		i := NewInstallation(namespace, name)
+		run := i.NewRun(cnab.ActionInstall)
+		run.OutputDependencies = deps
+		i.Status.RunID = run.ID
+		cp.CreateInstallation(i)
+		cp.CreateRun(run)
+	}
+	vpc := RunOutputDependency{Parameter: "vpc-id", Namespace: "platform", Installation: "network", Output: "vpc-id"}
+	addConsumer("dev", "myapp", vpc)
+	addConsumer("test", "myapp", vpc)
+	addConsumer("dev", "cache")
+
+	graph, err := BuildInstallationGraph(ctx, cp, ListOptions{Namespace: "*"})
+	require.NoError(t, err)
+
+	platformNetwork := InstallationNode{Namespace: "platform", Name: "network"}
+	devApp := InstallationNode{Namespace: "dev", Name: "myapp"}
+	testApp := InstallationNode{Namespace: "test", Name: "myapp"}
+	assert.Equal(t, []InstallationNode{
+		{Namespace: "dev", Name: "cache"}, devApp, platformNetwork, testApp,
+	}, graph.Nodes)
+
+	consumers := graph.Consumers(platformNetwork)
+	require.Len(t, consumers, 2)
+	assert.Equal(t, devApp, consumers[0].Consumer)
+	assert.Equal(t, testApp, consumers[1].Consumer)
+
+	deps := graph.Dependencies(devApp)
+	require.Len(t, deps, 1)
+	assert.Equal(t, platformNetwork, deps[0].Producer)
+	assert.Empty(t, graph.Dependencies(platformNetwork))
+
+	t.Run("filtered by namespace", func(t *testing.T) {
+		graph, err := BuildInstallationGraph(ctx, cp, ListOptions{Namespace: "test"})
+		require.NoError(t, err)
+		assert.Equal(t, []InstallationNode{platformNetwork, testApp}, graph.Nodes, "expected the producers from other namespaces to be included")
+		assert.Len(t, graph.Edges, 1)
+	})
+}
@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CollectionInstallationLocks holds the locks that prevent an installation
+// from being modified concurrently.
+const CollectionInstallationLocks = "installation_locks"
+
+// ErrInstallationLocked indicates that the installation is locked by another
+// operation. You can test for this error using
+// errors.Is(err, storage.ErrInstallationLocked).
+var ErrInstallationLocked = errors.New("the installation is locked")
+
+// ErrInstallationLockLost indicates that a lock expired and was acquired by
+// another operation, or was removed, before it was renewed.
+var ErrInstallationLockLost = errors.New("the installation lock was lost")
+
+// InstallationLock is an advisory lock on an installation, which prevents
+// concurrent operations, such as two upgrades, from racing on the
+// installation and its runs. The lock is a lease that expires unless it is
+// renewed, so that a lock held by a process that crashed does not block the
+// installation forever.
+type InstallationLock struct {
+	// ID of the lock, which is derived from the namespace and name of the
+	// installation so that there is only one lock per installation.
+	ID string `json:"_id"`
+
+	// Namespace of the installation.
+	Namespace string `json:"namespace"`
+
+	// Installation name.
+	Installation string `json:"installation"`
+
+	// Owner describes who holds the lock, for example the user, host and process.
+	Owner string `json:"owner"`
+
+	// Token uniquely identifies this acquisition of the lock, so that an
+	// expired lock that was acquired by someone else is not released or renewed.
+	Token string `json:"token"`
+
+	// Acquired is when the lock was acquired.
+	Acquired time.Time `json:"acquired"`
+
+	// Expires is when the lock expires unless it is renewed.
+	Expires time.Time `json:"expires"`
+}
+
+// IsExpired indicates if the lock has expired.
+func (l InstallationLock) IsExpired(now time.Time) bool {
+	return !now.Before(l.Expires)
+}
+
+func (l InstallationLock) String() string {
+	if l.Namespace == "" {
+		return l.Installation
+	}
+	return l.Namespace + "/" + l.Installation
+}
+
+// installationLockID returns the ID of the lock of an installation.
+func installationLockID(namespace string, installation string) string {
+	return namespace + "/" + installation
+}
+
+// InstallationLockedError is returned when an installation is locked by
+// another operation.
+type InstallationLockedError struct {
+	// Lock that is held by the other operation.
+	Lock InstallationLock
+}
+
+func (e InstallationLockedError) Error() string {
+	return fmt.Sprintf("installation %s is locked by %s, which started modifying it at %s. Wait for the other operation to complete and try again. The lock expires at %s if it is not renewed",
+		e.Lock, e.Lock.Owner, e.Lock.Acquired.Format(time.RFC3339), e.Lock.Expires.Format(time.RFC3339))
+}
+
+func (e InstallationLockedError) Is(target error) bool {
+	return target == ErrInstallationLocked
+}
+
+// AcquireInstallationLock locks an installation for the owner until the lock
+// is released, or it expires after the ttl unless it is renewed. When the
+// installation is already locked, an InstallationLockedError is returned. An
+// expired lock is replaced.
+func (s InstallationStore) AcquireInstallationLock(ctx context.Context, namespace string, installation string, owner string, ttl time.Duration) (InstallationLock, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := checkWritable(s.store, CollectionInstallationLocks, "insert"); err != nil {
+		return InstallationLock{}, span.Error(err)
+	}
+
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionInstallations, namespace, installation); err != nil {
+		return InstallationLock{}, span.Error(err)
+	}
+
+	now := time.Now()
+	lock := InstallationLock{
+		ID:           installationLockID(namespace, installation),
+		Namespace:    namespace,
+		Installation: installation,
+		Owner:        owner,
+		Token:        cnab.NewULID(),
+		Acquired:     now,
+		Expires:      now.Add(ttl),
+	}
+
+	// The lock is inserted with a well-known id, so only one insert succeeds
+	// even when several processes try to acquire the lock at the same time.
+	// Retry once after removing an expired lock.
+	for attempt := 0; attempt < 2; attempt++ {
+		insertErr := s.store.Insert(ctx, CollectionInstallationLocks, InsertOptions{Documents: []interface{}{lock}})
+		if insertErr == nil {
+			return lock, nil
+		}
+		if !errors.Is(insertErr, ErrConflict{}) {
+			return InstallationLock{}, span.Error(fmt.Errorf("could not acquire the lock on installation %s: %w", lock, insertErr))
+		}
+
+		existing, err := s.getInstallationLock(ctx, lock.ID)
+		if errors.Is(err, ErrNotFound{}) {
+			// The lock was released after the insert failed
+			continue
+		} else if err != nil {
+			return InstallationLock{}, span.Error(fmt.Errorf("could not acquire the lock on installation %s: %w", lock, insertErr))
+		}
+
+		if !existing.IsExpired(time.Now()) {
+			return InstallationLock{}, InstallationLockedError{Lock: existing}
+		}
+
+		span.Debugf("Replacing the expired lock on installation %s held by %s", lock, existing.Owner)
+		err = s.removeInstallationLock(ctx, existing)
+		if err != nil {
+			return InstallationLock{}, span.Error(fmt.Errorf("could not remove the expired lock on installation %s: %w", lock, err))
+		}
+	}
+
+	existing, err := s.getInstallationLock(ctx, lock.ID)
+	if err != nil {
+		return InstallationLock{}, span.Error(fmt.Errorf("could not acquire the lock on installation %s: %w", lock, err))
+	}
+	return InstallationLock{}, InstallationLockedError{Lock: existing}
+}
+
+// RenewInstallationLock extends a lock so that it expires after the ttl.
+// ErrInstallationLockLost is returned when the lock is no longer held.
+func (s InstallationStore) RenewInstallationLock(ctx context.Context, lock InstallationLock, ttl time.Duration) (InstallationLock, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := checkWritable(s.store, CollectionInstallationLocks, "update"); err != nil {
+		return lock, span.Error(err)
+	}
+
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionInstallations, lock.Namespace, lock.Installation); err != nil {
+		return lock, span.Error(err)
+	}
+
+	existing, err := s.getInstallationLock(ctx, lock.ID)
+	if errors.Is(err, ErrNotFound{}) {
+		return lock, ErrInstallationLockLost
+	} else if err != nil {
+		return lock, span.Error(err)
+	}
+	if existing.Token != lock.Token {
+		return lock, ErrInstallationLockLost
+	}
+
+	lock.Expires = time.Now().Add(ttl)
+	opts := UpdateOptions{
+		Filter:   bson.M{"_id": lock.ID, "token": lock.Token},
+		Document: lock,
+	}
+	return lock, span.Error(s.store.Update(ctx, CollectionInstallationLocks, opts))
+}
+
+// ReleaseInstallationLock releases a lock. Releasing a lock that is no longer
+// held, because it expired and was acquired by another operation, does not
+// release the other operation's lock.
+func (s InstallationStore) ReleaseInstallationLock(ctx context.Context, lock InstallationLock) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := checkWritable(s.store, CollectionInstallationLocks, "remove"); err != nil {
+		return span.Error(err)
+	}
+
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionInstallations, lock.Namespace, lock.Installation); err != nil {
+		return span.Error(err)
+	}
+
+	return span.Error(s.removeInstallationLock(ctx, lock))
+}
+
+func (s InstallationStore) getInstallationLock(ctx context.Context, id string) (InstallationLock, error) {
+	var lock InstallationLock
+	opts := FindOptions{
+		Filter: bson.M{"_id": id},
+	}
+	err := s.store.FindOne(ctx, CollectionInstallationLocks, opts, &lock)
+	return lock, err
+}
+
+func (s InstallationStore) removeInstallationLock(ctx context.Context, lock InstallationLock) error {
+	opts := RemoveOptions{
+		Filter: bson.M{"_id": lock.ID, "token": lock.Token},
+	}
+	return s.store.Remove(ctx, CollectionInstallationLocks, opts)
+}
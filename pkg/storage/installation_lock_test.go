@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationStore_InstallationLock(t *testing.T) {
+	ctx := context.Background()
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	lock, err := cp.AcquireInstallationLock(ctx, "dev", "mysql", "alice", time.Minute)
+	require.NoError(t, err, "AcquireInstallationLock failed")
+	assert.Equal(t, "dev/mysql", lock.String())
+	assert.Equal(t, "alice", lock.Owner)
+	assert.NotEmpty(t, lock.Token)
+
+	t.Run("locked", func(t *testing.T) {
+		_, err := cp.AcquireInstallationLock(ctx, "dev", "mysql", "bob", time.Minute)
+		require.ErrorIs(t, err, ErrInstallationLocked)
+		require.ErrorContains(t, err, "installation dev/mysql is locked by alice")
+
+		var lockedErr InstallationLockedError
+		require.True(t, errors.As(err, &lockedErr))
+		assert.Equal(t, lock.Token, lockedErr.Lock.Token)
+	})
+
+	t.Run("other installations are not locked", func(t *testing.T) {
+		other, err := cp.AcquireInstallationLock(ctx, "test", "mysql", "bob", time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, cp.ReleaseInstallationLock(ctx, other))
+	})
+
+	t.Run("renew", func(t *testing.T) {
+		renewed, err := cp.RenewInstallationLock(ctx, lock, time.Hour)
+		require.NoError(t, err, "RenewInstallationLock failed")
+		assert.True(t, renewed.Expires.After(lock.Expires), "the lock should expire later after it is renewed")
+		lock = renewed
+	})
+
+	require.NoError(t, cp.ReleaseInstallationLock(ctx, lock), "ReleaseInstallationLock failed")
+
+	t.Run("renew a released lock", func(t *testing.T) {
+		_, err := cp.RenewInstallationLock(ctx, lock, time.Hour)
+		require.ErrorIs(t, err, ErrInstallationLockLost)
+	})
+
+	t.Run("acquire after release", func(t *testing.T) {
+		next, err := cp.AcquireInstallationLock(ctx, "dev", "mysql", "bob", time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, cp.ReleaseInstallationLock(ctx, next))
+	})
+}
+
+func TestInstallationStore_AcquireInstallationLock_Expired(t *testing.T) {
+	ctx := context.Background()
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	// Simulate a lock held by a process that crashed
+	expired, err := cp.AcquireInstallationLock(ctx, "dev", "mysql", "alice", -time.Second)
+	require.NoError(t, err)
+
+	lock, err := cp.AcquireInstallationLock(ctx, "dev", "mysql", "bob", time.Minute)
+	require.NoError(t, err, "an expired lock should be replaced")
+	assert.Equal(t, "bob", lock.Owner)
+
+	// The previous owner must not be able to renew or release the new lock
+	_, err = cp.RenewInstallationLock(ctx, expired, time.Minute)
+	require.ErrorIs(t, err, ErrInstallationLockLost)
+	require.NoError(t, cp.ReleaseInstallationLock(ctx, expired))
+
+	_, err = cp.AcquireInstallationLock(ctx, "dev", "mysql", "carol", time.Minute)
+	require.ErrorIs(t, err, ErrInstallationLocked, "releasing an expired lock should not release the lock acquired by someone else")
+}
@@ -2,10 +2,20 @@ package storage
 
 import (
 	"context"
+	"io"
+	"time"
 )
 
 // InstallationProvider is an interface for interacting with Porter's claim data.
 type InstallationProvider interface {
+	// NewRun creates a run of the installation, with an id and revision
+	// generated by the store's id strategy.
+	NewRun(installation Installation, action string) Run
+
+	// NewResult creates a result of the run, with an id generated by the
+	// store's id strategy.
+	NewResult(run Run, status string) Result
+
 	// InsertInstallation saves a new Installation document.
 	InsertInstallation(ctx context.Context, installation Installation) error
 
@@ -15,15 +25,35 @@ type InstallationProvider interface {
 	// InsertResult saves a new Result document.
 	InsertResult(ctx context.Context, result Result) error
 
-	// InsertOutput saves a new Output document.
+	// CommitResult saves a Result document that was inserted as pending,
+	// after its outputs are saved.
+	CommitResult(ctx context.Context, result Result) error
+
+	// InsertOutput saves a new Output document. Values that exceed the
+	// output's size limit are saved to a blob store.
 	InsertOutput(ctx context.Context, output Output) error
 
+	// InsertStepResults saves the results of the steps executed by a run.
+	InsertStepResults(ctx context.Context, steps []StepResult) error
+
+	// InsertOutputStream saves a new Output document, reading its value from
+	// the reader and storing it in chunks.
+	InsertOutputStream(ctx context.Context, output Output, value io.Reader) error
+
+	// OpenOutputStream returns a reader for the value of an Output, retrieving
+	// the value in chunks when it was saved as a stream, or from the blob
+	// store when it was offloaded.
+	OpenOutputStream(ctx context.Context, output Output) (io.ReadCloser, error)
+
 	// UpdateInstallation saves changes to an existing Installation document.
 	UpdateInstallation(ctx context.Context, installation Installation) error
 
 	// UpsertRun saves changes a Run document, creating it if it doesn't already exist.
 	UpsertRun(ctx context.Context, run Run) error
 
+	// UpdateRunHeartbeat records that a run is still in progress.
+	UpdateRunHeartbeat(ctx context.Context, runID string, heartbeat time.Time) error
+
 	// UpsertInstallation saves an Installation document, creating it if it doesn't already exist.
 	UpsertInstallation(ctx context.Context, installation Installation) error
 
@@ -37,15 +67,27 @@ type InstallationProvider interface {
 	// ListInstallations returns Installations sorted in ascending order by the namespace and then name.
 	ListInstallations(ctx context.Context, listOption ListOptions) ([]Installation, error)
 
-	// ListRuns returns Run documents sorted in ascending order by ID.
+	// SummarizeInstallationsByBundle returns the number of installations of
+	// each bundle, and of each version of the bundle, sorted by the bundle repository.
+	SummarizeInstallationsByBundle(ctx context.Context, listOptions ListOptions) ([]BundleInstallationSummary, error)
+
+	// ListRuns returns Run documents sorted in ascending order by when they were created.
 	ListRuns(ctx context.Context, namespace string, installation string) ([]Run, map[string][]Result, error)
 
+	// ListRunsWithOptions returns the Run documents that match the options,
+	// sorted in ascending order by when they were created.
+	ListRunsWithOptions(ctx context.Context, opts ListRunsOptions) ([]Run, map[string][]Result, error)
+
 	// ListResults returns Result documents sorted in ascending order by ID.
 	ListResults(ctx context.Context, runID string) ([]Result, error)
 
 	// ListOutputs returns Output documents sorted in ascending order by name.
 	ListOutputs(ctx context.Context, resultID string) ([]Output, error)
 
+	// ListStepResults returns the StepResult documents of a run, sorted in the
+	// order that the steps were executed.
+	ListStepResults(ctx context.Context, runID string) ([]StepResult, error)
+
 	// GetRun returns a Run document by ID.
 	GetRun(ctx context.Context, id string) (Run, error)
 
@@ -59,16 +101,81 @@ type InstallationProvider interface {
 	// Output associated with the installation.
 	GetLastOutput(ctx context.Context, namespace string, installation string, name string) (Output, error)
 
+	// GetSharedOutput returns the most recent value (last) of the specified
+	// Output of an installation, on behalf of an installation in the requesting
+	// namespace. Reading an output from another namespace must be allowed by
+	// the access-control hook, otherwise ErrAccessDenied is returned.
+	GetSharedOutput(ctx context.Context, requestingNamespace string, namespace string, installation string, name string) (Output, error)
+
+	// FindOutputs returns the most recent value (last) of an Output for each
+	// installation that exposes it, sorted by namespace and installation.
+	FindOutputs(ctx context.Context, opts FindOutputsOptions) ([]Output, error)
+
 	// GetLastOutputs returns the most recent (last) value of each Output
 	// associated with the installation.
 	GetLastOutputs(ctx context.Context, namespace string, installation string) (Outputs, error)
 
+	// GetOutputHistory returns every value of an Output associated with the
+	// installation, sorted from oldest to newest.
+	GetOutputHistory(ctx context.Context, namespace string, installation string, name string) ([]OutputHistoryEntry, error)
+
 	// RemoveInstallation by its name.
 	RemoveInstallation(ctx context.Context, namespace string, name string) error
 
+	// PruneRuns removes the runs of an Installation, and their results and outputs,
+	// that are not kept by the retention policy. The pruned runs are returned.
+	PruneRuns(ctx context.Context, opts PruneRunsOptions) ([]Run, error)
+
+	// Fsck checks that results and outputs were saved completely and are not
+	// orphaned, optionally repairing the problems that are found.
+	Fsck(ctx context.Context, opts FsckOptions) (FsckReport, error)
+
+	// VerifyLedger checks that the run ledger of an installation has not
+	// been tampered with.
+	VerifyLedger(ctx context.Context, namespace string, installation string) (LedgerReport, error)
+
+	// MigrateRuns migrates the run documents saved with an older schema to
+	// the current schema, reporting the status of each run.
+	MigrateRuns(ctx context.Context, opts MigrateRunsOptions) (RunMigrationReport, error)
+
 	// GetLogs returns the logs from the specified Run.
 	GetLogs(ctx context.Context, runID string) (logs string, hasLogs bool, err error)
 
+	// ListRunLogs returns the logs recorded for the runs that match the options.
+	ListRunLogs(ctx context.Context, opts ListLogsOptions) ([]RunLogs, error)
+
 	// GetLastLogs returns the logs from the last run of an Installation.
 	GetLastLogs(ctx context.Context, namespace string, installation string) (logs string, hasLogs bool, err error)
+
+	// ExportInstallation copies an Installation and its runs, results, outputs
+	// and step results into a self-contained archive.
+	ExportInstallation(ctx context.Context, namespace string, name string) (InstallationArchive, error)
+
+	// ImportInstallation saves an Installation and its history from an archive
+	// created by ExportInstallation.
+	ImportInstallation(ctx context.Context, archive InstallationArchive) error
+
+	// InsertSnapshot saves a new snapshot of the outputs of an installation.
+	InsertSnapshot(ctx context.Context, snapshot InstallationSnapshot) error
+
+	// GetSnapshot returns the snapshot of an installation with the specified name.
+	GetSnapshot(ctx context.Context, namespace string, installation string, name string) (InstallationSnapshot, error)
+
+	// ListSnapshots returns the snapshots of an installation.
+	ListSnapshots(ctx context.Context, namespace string, installation string) ([]InstallationSnapshot, error)
+
+	// GetSnapshotOutput returns an output captured by a snapshot.
+	GetSnapshotOutput(ctx context.Context, snapshot InstallationSnapshot, name string) (Output, error)
+
+	// AcquireInstallationLock locks an installation for the owner, so that it
+	// is not modified concurrently, until the lock is released or it expires
+	// after the ttl. An InstallationLockedError is returned when the
+	// installation is already locked.
+	AcquireInstallationLock(ctx context.Context, namespace string, installation string, owner string, ttl time.Duration) (InstallationLock, error)
+
+	// RenewInstallationLock extends a lock so that it expires after the ttl.
+	RenewInstallationLock(ctx context.Context, lock InstallationLock, ttl time.Duration) (InstallationLock, error)
+
+	// ReleaseInstallationLock releases a lock acquired with AcquireInstallationLock.
+	ReleaseInstallationLock(ctx context.Context, lock InstallationLock) error
 }
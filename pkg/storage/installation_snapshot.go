@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CollectionSnapshots holds the snapshots of the outputs of installations.
+const CollectionSnapshots = "snapshots"
+
+var _ Document = InstallationSnapshot{}
+
+// InstallationSnapshot is a named point-in-time copy of the outputs of an
+// installation, including the state of the bundle, which can be restored when
+// the bundle is upgraded or invoked. The snapshot references the outputs that
+// were current when it was taken, instead of copying their values, and the
+// runs that generated them are kept when runs are pruned.
+type InstallationSnapshot struct {
+	// ID of the snapshot.
+	ID string `json:"_id"`
+
+	// Name of the snapshot, which is unique for the installation.
+	Name string `json:"name"`
+
+	// Namespace of the installation.
+	Namespace string `json:"namespace"`
+
+	// Installation name.
+	Installation string `json:"installation"`
+
+	// Created timestamp of the snapshot.
+	Created time.Time `json:"created"`
+
+	// BundleReference is the bundle that the installation was using when the
+	// snapshot was taken.
+	BundleReference string `json:"bundleReference,omitempty"`
+
+	// RunID of the most recent run of the installation when the snapshot was taken.
+	RunID string `json:"runId,omitempty"`
+
+	// Outputs captured by the snapshot.
+	Outputs []SnapshotOutput `json:"outputs,omitempty"`
+}
+
+// SnapshotOutput identifies the value of an output that is captured by a snapshot.
+type SnapshotOutput struct {
+	// Name of the output.
+	Name string `json:"name"`
+
+	// RunID of the run that generated the output.
+	RunID string `json:"runId"`
+
+	// ResultID of the result that generated the output.
+	ResultID string `json:"resultId"`
+}
+
+// NewInstallationSnapshot creates a snapshot of the specified outputs of an installation.
+func NewInstallationSnapshot(installation Installation, name string, outputs Outputs) InstallationSnapshot {
+	snapshot := InstallationSnapshot{
+		ID:              cnab.NewULID(),
+		Name:            name,
+		Namespace:       installation.Namespace,
+		Installation:    installation.Name,
+		Created:         time.Now(),
+		BundleReference: installation.Status.BundleReference,
+		RunID:           installation.Status.RunID,
+	}
+	for _, output := range outputs.Value() {
+		snapshot.Outputs = append(snapshot.Outputs, SnapshotOutput{
+			Name:     output.Name,
+			RunID:    output.RunID,
+			ResultID: output.ResultID,
+		})
+	}
+	return snapshot
+}
+
+func (s InstallationSnapshot) DefaultDocumentFilter() map[string]interface{} {
+	return map[string]interface{}{"namespace": s.Namespace, "installation": s.Installation, "name": s.Name}
+}
+
+func (s InstallationSnapshot) String() string {
+	return s.Name
+}
+
+// GetOutput returns the output captured by the snapshot with the specified name.
+func (s InstallationSnapshot) GetOutput(name string) (SnapshotOutput, bool) {
+	for _, output := range s.Outputs {
+		if output.Name == name {
+			return output, true
+		}
+	}
+	return SnapshotOutput{}, false
+}
+
+// InsertSnapshot saves a new snapshot. An error is returned when the
+// installation already has a snapshot with the same name.
+func (s InstallationStore) InsertSnapshot(ctx context.Context, snapshot InstallationSnapshot) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := checkWritable(s.store, CollectionSnapshots, "insert"); err != nil {
+		return span.Error(err)
+	}
+
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionSnapshots, snapshot.Namespace, snapshot.Installation); err != nil {
+		return span.Error(err)
+	}
+
+	_, err := s.GetSnapshot(ctx, snapshot.Namespace, snapshot.Installation, snapshot.Name)
+	if err == nil {
+		return span.Error(fmt.Errorf("installation %s/%s already has a snapshot named %s", snapshot.Namespace, snapshot.Installation, snapshot.Name))
+	} else if !errors.Is(err, ErrNotFound{}) {
+		return span.Error(err)
+	}
+
+	opts := InsertOptions{Documents: []interface{}{snapshot}}
+	return span.Error(s.store.Insert(ctx, CollectionSnapshots, opts))
+}
+
+// GetSnapshot returns the snapshot of an installation with the specified
+// name. A SnapshotNotFoundError, listing the available snapshots, is returned
+// when the snapshot does not exist.
+func (s InstallationStore) GetSnapshot(ctx context.Context, namespace string, installation string, name string) (InstallationSnapshot, error) {
+	var out InstallationSnapshot
+	if err := authorize(ctx, s.authz, VerbRead, CollectionSnapshots, namespace, installation); err != nil {
+		return out, err
+	}
+
+	opts := FindOptions{
+		Filter: bson.M{
+			"namespace":    namespace,
+			"installation": installation,
+			"name":         name,
+		},
+	}
+	err := s.store.FindOne(ctx, CollectionSnapshots, opts, &out)
+	if errors.Is(err, ErrNotFound{}) {
+		snapshots, listErr := s.ListSnapshots(ctx, namespace, installation)
+		if listErr != nil {
+			return out, err
+		}
+		return out, SnapshotNotFoundError{
+			Name:         name,
+			Installation: namespace + "/" + installation,
+			Available:    sortedSnapshotNames(snapshots),
+		}
+	}
+	return out, err
+}
+
+// ListSnapshots returns the snapshots of an installation, oldest first.
+func (s InstallationStore) ListSnapshots(ctx context.Context, namespace string, installation string) ([]InstallationSnapshot, error) {
+	var out []InstallationSnapshot
+	if err := authorize(ctx, s.authz, VerbRead, CollectionSnapshots, namespace, installation); err != nil {
+		return nil, err
+	}
+
+	opts := FindOptions{
+		Sort: []string{"_id"},
+		Filter: bson.M{
+			"namespace":    namespace,
+			"installation": installation,
+		},
+	}
+	err := s.store.Find(ctx, CollectionSnapshots, opts, &out)
+	return out, err
+}
+
+// GetSnapshotOutput returns an output captured by a snapshot. The value of
+// the output is not read when it was saved as a stream, or resolved from the
+// secret store when it is sensitive.
+func (s InstallationStore) GetSnapshotOutput(ctx context.Context, snapshot InstallationSnapshot, name string) (Output, error) {
+	ref, ok := snapshot.GetOutput(name)
+	if !ok {
+		return Output{}, ErrNotFound{Collection: CollectionOutputs, Item: name}
+	}
+
+	var out Output
+	opts := FindOptions{
+		Filter: bson.M{
+			"resultId": ref.ResultID,
+			"name":     ref.Name,
+		},
+	}
+	if err := s.store.FindOne(ctx, CollectionOutputs, opts, &out); err != nil {
+		return Output{}, err
+	}
+	if err := authorizeFetched(ctx, s.authz, CollectionOutputs, out.Namespace, out.Installation); err != nil {
+		return Output{}, err
+	}
+	return out, nil
+}
+
+// SnapshotNotFoundError is returned when an installation does not have a
+// snapshot with the requested name.
+type SnapshotNotFoundError struct {
+	// Name of the snapshot.
+	Name string
+
+	// Installation that does not have the snapshot, formatted as namespace/name.
+	Installation string
+
+	// Available snapshots of the installation.
+	Available []string
+}
+
+func (e SnapshotNotFoundError) Error() string {
+	if len(e.Available) == 0 {
+		return fmt.Sprintf("snapshot %s not found, installation %s does not have any snapshots", e.Name, e.Installation)
+	}
+	return fmt.Sprintf("snapshot %s not found for installation %s, available snapshots: %s", e.Name, e.Installation, strings.Join(e.Available, ", "))
+}
+
+func (e SnapshotNotFoundError) Is(target error) bool {
+	_, ok := target.(ErrNotFound)
+	return ok
+}
+
+// snapshotRunIDs returns the ids of the runs that generated outputs that are
+// captured by the snapshots of an installation.
+func (s InstallationStore) snapshotRunIDs(ctx context.Context, namespace string, installation string) (map[string]bool, error) {
+	snapshots, err := s.ListSnapshots(ctx, namespace, installation)
+	if err != nil {
+		return nil, err
+	}
+
+	runIDs := make(map[string]bool)
+	for _, snapshot := range snapshots {
+		for _, output := range snapshot.Outputs {
+			runIDs[output.RunID] = true
+		}
+	}
+	return runIDs, nil
+}
+
+// sortedSnapshotNames returns the names of the snapshots, sorted alphabetically.
+func sortedSnapshotNames(snapshots []InstallationSnapshot) []string {
+	names := make([]string, len(snapshots))
+	for i, snapshot := range snapshots {
+		names[i] = snapshot.Name
+	}
+	sort.Strings(names)
+	return names
+}
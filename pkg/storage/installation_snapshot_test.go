@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationStore_Snapshots(t *testing.T) {
+	ctx := context.Background()
+	cp := generateInstallationData(t)
+	defer cp.Close()
+
+	foo, err := cp.GetInstallation(ctx, "dev", "foo")
+	require.NoError(t, err)
+	outputs, err := cp.GetLastOutputs(ctx, "dev", "foo")
+	require.NoError(t, err)
+
+	snapshot := NewInstallationSnapshot(foo, "before-migration", outputs)
+	require.NoError(t, cp.InsertSnapshot(ctx, snapshot), "InsertSnapshot failed")
+	assert.Equal(t, foo.Status.RunID, snapshot.RunID)
+	assert.Len(t, snapshot.Outputs, outputs.Len())
+
+	t.Run("duplicate name", func(t *testing.T) {
+		err := cp.InsertSnapshot(ctx, NewInstallationSnapshot(foo, "before-migration", outputs))
+		require.EqualError(t, err, "installation dev/foo already has a snapshot named before-migration")
+	})
+
+	t.Run("get", func(t *testing.T) {
+		got, err := cp.GetSnapshot(ctx, "dev", "foo", "before-migration")
+		require.NoError(t, err, "GetSnapshot failed")
+		assert.Equal(t, snapshot.ID, got.ID)
+		assert.Equal(t, snapshot.Outputs, got.Outputs)
+
+		output, err := cp.GetSnapshotOutput(ctx, got, "output1")
+		require.NoError(t, err, "GetSnapshotOutput failed")
+		assert.Equal(t, "upgrade output1", string(output.Value))
+
+		_, err = cp.GetSnapshotOutput(ctx, got, "missing")
+		require.ErrorIs(t, err, ErrNotFound{})
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := cp.GetSnapshot(ctx, "dev", "foo", "missing")
+		require.ErrorIs(t, err, ErrNotFound{})
+		require.EqualError(t, err, "snapshot missing not found for installation dev/foo, available snapshots: before-migration")
+
+		_, err = cp.GetSnapshot(ctx, "dev", "bar", "missing")
+		require.EqualError(t, err, "snapshot missing not found, installation dev/bar does not have any snapshots")
+	})
+
+	t.Run("remove installation", func(t *testing.T) {
+		require.NoError(t, cp.RemoveInstallation(ctx, "dev", "foo"))
+
+		snapshots, err := cp.ListSnapshots(ctx, "dev", "foo")
+		require.NoError(t, err)
+		assert.Empty(t, snapshots, "expected the snapshots to be removed with the installation")
+	})
+}
+
+func TestInstallationStore_PruneRuns_KeepsSnapshots(t *testing.T) {
+	ctx := context.Background()
+	cp := generateInstallationData(t)
+	defer cp.Close()
+
+	runs, _, err := cp.ListRuns(ctx, "dev", "foo")
+	require.NoError(t, err)
+	require.Equal(t, cnab.ActionInstall, runs[0].Action)
+	installRun := runs[0]
+
+	// Snapshot the outputs generated by the install run
+	results, err := cp.ListResults(ctx, installRun.ID)
+	require.NoError(t, err)
+	outputs, err := cp.ListOutputs(ctx, results[0].ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, outputs)
+	foo, err := cp.GetInstallation(ctx, "dev", "foo")
+	require.NoError(t, err)
+	require.NoError(t, cp.InsertSnapshot(ctx, NewInstallationSnapshot(foo, "after-install", NewOutputs(outputs))))
+
+	opts := PruneRunsOptions{Namespace: "dev", Installation: "foo", Policy: RetentionPolicy{KeepLast: 1}}
+	pruned, err := cp.PruneRuns(ctx, opts)
+	require.NoError(t, err, "PruneRuns failed")
+	require.Len(t, pruned, 1, "expected the install run to be kept because a snapshot references its outputs")
+	assert.Equal(t, "test", pruned[0].Action)
+
+	_, err = cp.GetRun(ctx, installRun.ID)
+	require.NoError(t, err, "expected the install run to be kept")
+}
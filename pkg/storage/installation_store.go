@@ -2,7 +2,10 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"time"
 
 	"get.porter.sh/porter/pkg/tracing"
 	"go.mongodb.org/mongo-driver/bson"
@@ -22,18 +25,84 @@ type InstallationStore struct {
 	store   Store
 	encrypt EncryptionHandler
 	decrypt EncryptionHandler
+	access  OutputAccessControl
+	offload OutputOffload
+	authz   Authorizer
+	ledger  RunLedger
+	ids     IDStrategy
+
+	// runMigrations migrate the run documents saved with an older schema as they are read.
+	runMigrations RunMigrations
 }
 
 // NewInstallationStore creates a persistent store for installations using the specified
-// backing datastore.
+// backing datastore. Outputs are not shared across namespaces until an access-control
+// hook is set with SetOutputAccessControl, and the size of outputs is not
+// limited until an offload hook is set with SetOutputOffload.
 func NewInstallationStore(datastore Store) InstallationStore {
 	return InstallationStore{
 		store:   datastore,
 		encrypt: noOpEncryptionHandler,
 		decrypt: noOpEncryptionHandler,
+		access:  NamespacePolicyAccessControl{},
+		offload: &ConfigOutputOffload{},
+		ids:     ULIDStrategy{},
 	}
 }
 
+// SetOutputAccessControl sets the hook that authorizes reading outputs across namespaces.
+func (s *InstallationStore) SetOutputAccessControl(access OutputAccessControl) {
+	s.access = access
+}
+
+// SetAuthorizer sets the hook that authorizes reading and changing the
+// documents in a namespace.
+func (s *InstallationStore) SetAuthorizer(authz Authorizer) {
+	s.authz = authz
+}
+
+// SetRunLedger sets the hook that records runs and results in the run
+// ledger. Runs and results are not recorded until it is set.
+func (s *InstallationStore) SetRunLedger(ledger RunLedger) {
+	s.ledger = ledger
+}
+
+// SetRunMigrations sets the migrators that are applied to the run documents
+// saved with an older schema when they are read. Until they are set, only runs
+// saved with the current schema can be read.
+func (s *InstallationStore) SetRunMigrations(migrations RunMigrations) {
+	s.runMigrations = migrations
+}
+
+// SetOutputOffload sets the hook that saves output values that exceed their
+// size limit to a blob store.
+func (s *InstallationStore) SetOutputOffload(offload OutputOffload) {
+	s.offload = offload
+}
+
+// SetIDStrategy sets the strategy used to generate the ids and revisions of
+// new runs and results. ULIDs are generated until it is set.
+func (s *InstallationStore) SetIDStrategy(ids IDStrategy) {
+	s.ids = ids
+}
+
+// NewRun creates a run of the installation, with an id and revision generated
+// by the store's id strategy.
+func (s InstallationStore) NewRun(installation Installation, action string) Run {
+	run := installation.NewRun(action)
+	run.ID = s.ids.NewID()
+	run.Revision = s.ids.NewRevision()
+	return run
+}
+
+// NewResult creates a result of the run, with an id generated by the store's
+// id strategy.
+func (s InstallationStore) NewResult(run Run, status string) Result {
+	result := run.NewResult(status)
+	result.ID = s.ids.NewID()
+	return result
+}
+
 // EnsureInstallationIndices created indices on the installations collection.
 func EnsureInstallationIndices(ctx context.Context, store Store) error {
 	ctx, span := tracing.StartSpan(ctx)
@@ -45,18 +114,38 @@ func EnsureInstallationIndices(ctx context.Context, store Store) error {
 		Indices: []Index{
 			// query installations by a namespace (list) or namespace + name (get)
 			{Collection: CollectionInstallations, Keys: []string{"namespace", "name"}, Unique: true},
+			// query the run ledger of an installation in order
+			{Collection: CollectionLedger, Keys: []string{"namespace", "installation", "sequence"}, Unique: true},
 			// query runs by installation (list)
 			{Collection: CollectionRuns, Keys: []string{"namespace", "installation"}},
+			// query runs by when they were created (porter logs search, list runs, get the last run)
+			{Collection: CollectionRuns, Keys: []string{"namespace", "installation", "createdAt", "_id"}},
 			// query results by installation (delete or batch get)
 			{Collection: CollectionResults, Keys: []string{"namespace", "installation"}},
 			// query results by run (list)
 			{Collection: CollectionResults, Keys: []string{"runId"}},
 			// query most recent outputs by run (porter installation run show, when we list outputs)
-			{Collection: CollectionOutputs, Keys: []string{"namespace", "installation", "-resultId"}},
+			{Collection: CollectionOutputs, Keys: []string{"namespace", "installation", "-createdAt", "-resultId"}},
 			// query outputs by result (list)
 			{Collection: CollectionOutputs, Keys: []string{"resultId", "name"}, Unique: true},
 			// query most recent outputs by name for an installation
-			{Collection: CollectionOutputs, Keys: []string{"namespace", "installation", "name", "-resultId"}},
+			{Collection: CollectionOutputs, Keys: []string{"namespace", "installation", "name", "-createdAt", "-resultId"}},
+			// query the most recent outputs by name across installations (porter installation outputs find)
+			{Collection: CollectionOutputs, Keys: []string{"name", "namespace", "installation", "-createdAt", "-resultId"}},
+			// query the logs of runs (porter logs search)
+			{Collection: CollectionOutputs, Keys: []string{"runId", "name", "-resultId"}},
+			// query the chunks of an output value in order
+			{Collection: CollectionOutputChunks, Keys: []string{"resultId", "name", "index"}, Unique: true},
+			// query output chunks by installation (delete)
+			{Collection: CollectionOutputChunks, Keys: []string{"namespace", "installation"}},
+			// query the log and output chunks of runs (prune)
+			{Collection: CollectionOutputChunks, Keys: []string{"runId", "name", "index"}},
+			// query step results by run (list)
+			{Collection: CollectionStepResults, Keys: []string{"runId", "index"}, Unique: true},
+			// query step results by installation (delete)
+			{Collection: CollectionStepResults, Keys: []string{"namespace", "installation"}},
+			// query snapshots by installation (list) or installation + name (get)
+			{Collection: CollectionSnapshots, Keys: []string{"namespace", "installation", "name"}, Unique: true},
 		},
 	}
 
@@ -70,44 +159,14 @@ func (s InstallationStore) ListInstallations(ctx context.Context, listOptions Li
 
 	var out []Installation
 	err := s.store.Find(ctx, CollectionInstallations, listOptions.ToFindOptions(), &out)
-	return out, err
-}
-
-func (s InstallationStore) ListRuns(ctx context.Context, namespace string, installation string) ([]Run, map[string][]Result, error) {
-	var runs []Run
-	var err error
-	var results []Result
-
-	opts := FindOptions{
-		Sort: []string{"_id"},
-		Filter: bson.M{
-			"namespace":    namespace,
-			"installation": installation,
-		},
-	}
-	err = s.store.Find(ctx, CollectionRuns, opts, &runs)
 	if err != nil {
-		return nil, nil, err
-	}
-
-	err = s.store.Find(ctx, CollectionResults, opts, &results)
-	if err != nil {
-		return runs, nil, err
-	}
-
-	resultsMap := make(map[string][]Result, len(runs))
-
-	for _, run := range runs {
-		resultsMap[run.ID] = []Result{}
-	}
-
-	for _, res := range results {
-		if _, ok := resultsMap[res.RunID]; ok {
-			resultsMap[res.RunID] = append(resultsMap[res.RunID], res)
-		}
+		return nil, err
 	}
+	return filterAuthorized(ctx, s.authz, CollectionInstallations, out, func(i Installation) string { return i.Namespace })
+}
 
-	return runs, resultsMap, err
+func (s InstallationStore) ListRuns(ctx context.Context, namespace string, installation string) ([]Run, map[string][]Result, error) {
+	return s.ListRunsWithOptions(ctx, ListRunsOptions{Namespace: namespace, Installation: installation})
 }
 
 func (s InstallationStore) ListResults(ctx context.Context, runID string) ([]Result, error) {
@@ -115,11 +174,15 @@ func (s InstallationStore) ListResults(ctx context.Context, runID string) ([]Res
 	opts := FindOptions{
 		Sort: []string{"_id"},
 		Filter: bson.M{
-			"runId": runID,
+			"runId":   runID,
+			"pending": committedFilter,
 		},
 	}
 	err := s.store.Find(ctx, CollectionResults, opts, &out)
-	return out, err
+	if err != nil {
+		return nil, err
+	}
+	return filterAuthorized(ctx, s.authz, CollectionResults, out, func(r Result) string { return r.Namespace })
 }
 
 func (s InstallationStore) ListOutputs(ctx context.Context, resultID string) ([]Output, error) {
@@ -131,7 +194,10 @@ func (s InstallationStore) ListOutputs(ctx context.Context, resultID string) ([]
 		},
 	}
 	err := s.store.Find(ctx, CollectionOutputs, opts, &out)
-	return out, err
+	if err != nil {
+		return nil, err
+	}
+	return filterAuthorized(ctx, s.authz, CollectionOutputs, out, func(o Output) string { return o.Namespace })
 }
 
 func (s InstallationStore) FindInstallations(ctx context.Context, findOpts FindOptions) ([]Installation, error) {
@@ -140,10 +206,17 @@ func (s InstallationStore) FindInstallations(ctx context.Context, findOpts FindO
 
 	var out []Installation
 	err := s.store.Find(ctx, CollectionInstallations, findOpts, &out)
-	return out, err
+	if err != nil {
+		return nil, err
+	}
+	return filterAuthorized(ctx, s.authz, CollectionInstallations, out, func(i Installation) string { return i.Namespace })
 }
 
 func (s InstallationStore) GetInstallation(ctx context.Context, namespace string, name string) (Installation, error) {
+	if err := authorize(ctx, s.authz, VerbRead, CollectionInstallations, namespace, name); err != nil {
+		return Installation{}, err
+	}
+
 	var out Installation
 
 	opts := FindOptions{
@@ -157,23 +230,46 @@ func (s InstallationStore) GetInstallation(ctx context.Context, namespace string
 }
 
 func (s InstallationStore) GetRun(ctx context.Context, id string) (Run, error) {
-	var out Run
+	var doc json.RawMessage
 	opts := GetOptions{ID: id}
-	err := s.store.Get(ctx, CollectionRuns, opts, &out)
-	return out, err
+	if err := s.store.Get(ctx, CollectionRuns, opts, &doc); err != nil {
+		return Run{}, err
+	}
+	run, err := s.runMigrations.decodeRun(doc)
+	if err != nil {
+		return Run{}, err
+	}
+	if err = authorizeFetched(ctx, s.authz, CollectionRuns, run.Namespace, run.Installation); err != nil {
+		return Run{}, err
+	}
+	return run, nil
 }
 
 func (s InstallationStore) GetResult(ctx context.Context, id string) (Result, error) {
 	var out Result
 	opts := GetOptions{ID: id}
-	err := s.store.Get(ctx, CollectionResults, opts, &out)
-	return out, err
+	if err := s.store.Get(ctx, CollectionResults, opts, &out); err != nil {
+		return Result{}, err
+	}
+	if err := authorizeFetched(ctx, s.authz, CollectionResults, out.Namespace, out.Installation); err != nil {
+		return Result{}, err
+	}
+	return out, nil
 }
 
 func (s InstallationStore) GetLastRun(ctx context.Context, namespace string, installation string) (Run, error) {
-	var out []Run
+	ctx, span := tracing.StartSpan(ctx, installationAttribute(namespace, installation))
+	defer span.EndSpan()
+
+	if err := authorize(ctx, s.authz, VerbRead, CollectionRuns, namespace, installation); err != nil {
+		return Run{}, span.Error(err)
+	}
+
+	// Sort by when the run was created, because the ids are only ordered
+	// when they were generated with the same id strategy.
+	var out []json.RawMessage
 	opts := FindOptions{
-		Sort:  []string{"-_id"},
+		Sort:  []string{"-createdAt", "-_id"},
 		Limit: 1,
 		Filter: bson.M{
 			"namespace":    namespace,
@@ -182,18 +278,23 @@ func (s InstallationStore) GetLastRun(ctx context.Context, namespace string, ins
 	}
 	err := s.store.Find(ctx, CollectionRuns, opts, &out)
 	if err != nil {
-		return Run{}, err
+		return Run{}, span.Error(err)
 	}
 	if len(out) == 0 {
 		return Run{}, ErrNotFound{Collection: CollectionRuns}
 	}
-	return out[0], err
+	run, err := s.runMigrations.decodeRun(out[0])
+	return run, span.Error(err)
 }
 
 func (s InstallationStore) GetLastOutput(ctx context.Context, namespace string, installation string, name string) (Output, error) {
+	if err := authorize(ctx, s.authz, VerbRead, CollectionOutputs, namespace, installation); err != nil {
+		return Output{}, err
+	}
+
 	var out Output
 	opts := FindOptions{
-		Sort:  []string{"-_id"},
+		Sort:  []string{"-createdAt", "-resultId"},
 		Limit: 1,
 		Filter: bson.M{
 			"namespace":    namespace,
@@ -205,7 +306,29 @@ func (s InstallationStore) GetLastOutput(ctx context.Context, namespace string,
 	return out, err
 }
 
+func (s InstallationStore) GetSharedOutput(ctx context.Context, requestingNamespace string, namespace string, installation string, name string) (Output, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := s.access.AuthorizeOutputAccess(ctx, requestingNamespace, namespace, installation, name); err != nil {
+		return Output{}, span.Error(err)
+	}
+
+	output, err := s.GetLastOutput(ctx, namespace, installation, name)
+	if err != nil && !errors.Is(err, ErrNotFound{}) {
+		return Output{}, span.Error(err)
+	}
+	return output, err
+}
+
 func (s InstallationStore) GetLastOutputs(ctx context.Context, namespace string, installation string) (Outputs, error) {
+	ctx, span := tracing.StartSpan(ctx, installationAttribute(namespace, installation))
+	defer span.EndSpan()
+
+	if err := authorize(ctx, s.authz, VerbRead, CollectionOutputs, namespace, installation); err != nil {
+		return Outputs{}, span.Error(err)
+	}
+
 	var groupedOutputs []struct {
 		ID         string `json:"_id"`
 		LastOutput Output `json:"lastOutput"`
@@ -222,6 +345,7 @@ func (s InstallationStore) GetLastOutputs(ctx context.Context, namespace string,
 				{Key: "namespace", Value: 1},
 				{Key: "installation", Value: 1},
 				{Key: "name", Value: 1},
+				{Key: "createdAt", Value: -1},
 				{Key: "resultId", Value: -1},
 			}}},
 			// Group them by output name and select the last value for each output
@@ -238,7 +362,7 @@ func (s InstallationStore) GetLastOutputs(ctx context.Context, namespace string,
 		lastOutputs[i] = groupedOutput.LastOutput
 	}
 
-	return NewOutputs(lastOutputs), err
+	return NewOutputs(lastOutputs), span.Error(err)
 }
 
 func (s InstallationStore) GetLogs(ctx context.Context, runID string) (string, bool, error) {
@@ -252,16 +376,23 @@ func (s InstallationStore) GetLogs(ctx context.Context, runID string) (string, b
 		Limit: 1,
 	}
 	err := s.store.FindOne(ctx, CollectionOutputs, opts, &out)
+	if err == nil {
+		err = authorizeFetched(ctx, s.authz, CollectionOutputs, out.Namespace, out.Installation)
+	}
 	if errors.Is(err, ErrNotFound{}) {
 		return "", false, nil
+	} else if err != nil {
+		return "", false, err
 	}
+
+	out, err = ReadOutputValue(ctx, s, out)
 	return string(out.Value), err == nil, err
 }
 
 func (s InstallationStore) GetLastLogs(ctx context.Context, namespace string, installation string) (string, bool, error) {
 	var out Output
 	opts := FindOptions{
-		Sort: []string{"-resultId"}, // get logs from the last result for a run
+		Sort: []string{"-createdAt", "-resultId"}, // get logs from the last result for a run
 		Filter: bson.M{
 			"namespace":    namespace,
 			"installation": installation,
@@ -272,66 +403,231 @@ func (s InstallationStore) GetLastLogs(ctx context.Context, namespace string, in
 	err := s.store.FindOne(ctx, CollectionOutputs, opts, &out)
 	if errors.Is(err, ErrNotFound{}) {
 		return "", false, nil
+	} else if err != nil {
+		return "", false, err
 	}
+	if err = authorize(ctx, s.authz, VerbRead, CollectionOutputs, out.Namespace, out.Installation); err != nil {
+		return "", false, err
+	}
+
+	out, err = ReadOutputValue(ctx, s, out)
 	return string(out.Value), err == nil, err
 }
 
 func (s InstallationStore) InsertInstallation(ctx context.Context, installation Installation) error {
+	ctx, span := tracing.StartSpan(ctx, installationAttribute(installation.Namespace, installation.Name))
+	defer span.EndSpan()
+
+	if err := checkWritable(s.store, CollectionInstallations, "insert"); err != nil {
+		return span.Error(err)
+	}
+
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionInstallations, installation.Namespace, installation.Name); err != nil {
+		return span.Error(err)
+	}
+
 	installation.SchemaVersion = InstallationSchemaVersion
 	opts := InsertOptions{
 		Documents: []interface{}{installation},
 	}
-	return s.store.Insert(ctx, CollectionInstallations, opts)
+	return span.Error(s.store.Insert(ctx, CollectionInstallations, opts))
 }
 
 func (s InstallationStore) InsertRun(ctx context.Context, run Run) error {
+	ctx, span := tracing.StartSpan(ctx, run.TraceAttributes()...)
+	defer span.EndSpan()
+
+	if err := checkWritable(s.store, CollectionRuns, "insert"); err != nil {
+		return span.Error(err)
+	}
+
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionRuns, run.Namespace, run.Installation); err != nil {
+		return span.Error(err)
+	}
+
+	run.CompactParameters()
+	recordHash, err := runLedgerHash(run)
+	if err != nil {
+		return span.Error(err)
+	}
+
 	opts := InsertOptions{
 		Documents: []interface{}{run},
 	}
-	return s.store.Insert(ctx, CollectionRuns, opts)
+	return span.Error(s.saveWithLedger(ctx, LedgerKindRun, run.Namespace, run.Installation, run.ID, recordHash, func() error {
+		return s.store.Insert(ctx, CollectionRuns, opts)
+	}))
 }
 
 func (s InstallationStore) InsertResult(ctx context.Context, result Result) error {
+	ctx, span := tracing.StartSpan(ctx, result.TraceAttributes()...)
+	defer span.EndSpan()
+
+	if err := checkWritable(s.store, CollectionResults, "insert"); err != nil {
+		return span.Error(err)
+	}
+
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionResults, result.Namespace, result.Installation); err != nil {
+		return span.Error(err)
+	}
+
+	recordHash, err := resultLedgerHash(result)
+	if err != nil {
+		return span.Error(err)
+	}
+
 	opts := InsertOptions{
 		Documents: []interface{}{result},
 	}
-	return s.store.Insert(ctx, CollectionResults, opts)
+	return span.Error(s.saveWithLedger(ctx, LedgerKindResult, result.Namespace, result.Installation, result.ID, recordHash, func() error {
+		return s.store.Insert(ctx, CollectionResults, opts)
+	}))
 }
 
+// CommitResult saves a result that was inserted as pending, after its outputs are saved.
+func (s InstallationStore) CommitResult(ctx context.Context, result Result) error {
+	ctx, span := tracing.StartSpan(ctx, result.TraceAttributes()...)
+	defer span.EndSpan()
+
+	if err := checkWritable(s.store, CollectionResults, "update"); err != nil {
+		return span.Error(err)
+	}
+
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionResults, result.Namespace, result.Installation); err != nil {
+		return span.Error(err)
+	}
+
+	result.Pending = false
+	recordHash, err := resultLedgerHash(result)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	opts := UpdateOptions{
+		Document: result,
+	}
+	return span.Error(s.saveWithLedger(ctx, LedgerKindResult, result.Namespace, result.Installation, result.ID, recordHash, func() error {
+		return s.store.Update(ctx, CollectionResults, opts)
+	}))
+}
+
+// InsertOutput saves a new Output document. A value that exceeds the output's
+// size limit is saved to the blob store, and the document references the blob.
 func (s InstallationStore) InsertOutput(ctx context.Context, output Output) error {
+	ctx, span := tracing.StartSpan(ctx, output.TraceAttributes()...)
+	defer span.EndSpan()
+
+	if err := checkWritable(s.store, CollectionOutputs, "insert"); err != nil {
+		return span.Error(err)
+	}
+
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionOutputs, output.Namespace, output.Installation); err != nil {
+		return span.Error(err)
+	}
+
+	// Outputs saved as a stream have already been hashed, and sensitive outputs are never hashed
+	if output.ValueHash == "" && output.Key == "" && output.Chunks == 0 && !output.IsOffloaded() {
+		output.ValueHash = HashOutputValue(output.Value)
+	}
+
+	output, err := s.offloadOutput(ctx, output)
+	if err != nil {
+		return span.Error(err)
+	}
+
 	opts := InsertOptions{
 		Documents: []interface{}{output},
 	}
-	return s.store.Insert(ctx, CollectionOutputs, opts)
+	err = s.store.Insert(ctx, CollectionOutputs, opts)
+	if err != nil && output.IsOffloaded() {
+		// Do not leave behind a blob that is not referenced by an output
+		if removeErr := s.removeOutputBlob(ctx, output); removeErr != nil {
+			return span.Error(fmt.Errorf("%w\nerror removing the saved value of output %s: %s", err, output.Name, removeErr))
+		}
+	}
+	return span.Error(err)
 }
 
 func (s InstallationStore) UpdateInstallation(ctx context.Context, installation Installation) error {
+	ctx, span := tracing.StartSpan(ctx, installationAttribute(installation.Namespace, installation.Name))
+	defer span.EndSpan()
+
+	if err := checkWritable(s.store, CollectionInstallations, "update"); err != nil {
+		return span.Error(err)
+	}
+
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionInstallations, installation.Namespace, installation.Name); err != nil {
+		return span.Error(err)
+	}
+
 	installation.SchemaVersion = InstallationSchemaVersion
 	opts := UpdateOptions{
 		Document: installation,
 	}
-	return s.store.Update(ctx, CollectionInstallations, opts)
+	return span.Error(s.store.Update(ctx, CollectionInstallations, opts))
 }
 
 func (s InstallationStore) UpsertRun(ctx context.Context, run Run) error {
+	ctx, span := tracing.StartSpan(ctx, run.TraceAttributes()...)
+	defer span.EndSpan()
+
+	if err := checkWritable(s.store, CollectionRuns, "update"); err != nil {
+		return span.Error(err)
+	}
+
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionRuns, run.Namespace, run.Installation); err != nil {
+		return span.Error(err)
+	}
+
+	run.CompactParameters()
+	recordHash, err := runLedgerHash(run)
+	if err != nil {
+		return span.Error(err)
+	}
+
 	opts := UpdateOptions{
 		Upsert:   true,
 		Document: run,
 	}
-	return s.store.Update(ctx, CollectionRuns, opts)
+	return span.Error(s.saveWithLedger(ctx, LedgerKindRun, run.Namespace, run.Installation, run.ID, recordHash, func() error {
+		return s.store.Update(ctx, CollectionRuns, opts)
+	}))
 }
 
 func (s InstallationStore) UpsertInstallation(ctx context.Context, installation Installation) error {
+	ctx, span := tracing.StartSpan(ctx, installationAttribute(installation.Namespace, installation.Name))
+	defer span.EndSpan()
+
+	if err := checkWritable(s.store, CollectionInstallations, "update"); err != nil {
+		return span.Error(err)
+	}
+
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionInstallations, installation.Namespace, installation.Name); err != nil {
+		return span.Error(err)
+	}
+
 	installation.SchemaVersion = InstallationSchemaVersion
 	opts := UpdateOptions{
 		Upsert:   true,
 		Document: installation,
 	}
-	return s.store.Update(ctx, CollectionInstallations, opts)
+	return span.Error(s.store.Update(ctx, CollectionInstallations, opts))
 }
 
 // RemoveInstallation and all associated data.
 func (s InstallationStore) RemoveInstallation(ctx context.Context, namespace string, name string) error {
+	if err := checkWritable(s.store, CollectionInstallations, "remove"); err != nil {
+		return err
+	}
+
+	if err := authorize(ctx, s.authz, VerbDelete, CollectionInstallations, namespace, name); err != nil {
+		return err
+	}
+
+	if s.ledger != nil && s.ledger.Enabled() {
+		return errLedgerRemove
+	}
+
 	removeInstallation := RemoveOptions{
 		Filter: bson.M{
 			"namespace": namespace,
@@ -364,15 +660,145 @@ func (s InstallationStore) RemoveInstallation(ctx context.Context, namespace str
 		return err
 	}
 
+	// Delete the values of outputs that were saved to a blob store
+	err = s.removeOutputBlobs(ctx, removeChildDocs.Filter)
+	if err != nil {
+		return err
+	}
+
 	// Delete outputs
 	err = s.store.Remove(ctx, CollectionOutputs, removeChildDocs)
 	if err != nil {
 		return err
 	}
 
+	// Delete the chunks of outputs that were saved as a stream
+	err = s.store.Remove(ctx, CollectionOutputChunks, removeChildDocs)
+	if err != nil {
+		return err
+	}
+
+	// Delete step results
+	err = s.store.Remove(ctx, CollectionStepResults, removeChildDocs)
+	if err != nil {
+		return err
+	}
+
+	// Delete snapshots
+	err = s.store.Remove(ctx, CollectionSnapshots, removeChildDocs)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// PruneRunsOptions are the options for removing runs of an installation.
+type PruneRunsOptions struct {
+	// Namespace of the installation.
+	Namespace string
+
+	// Installation name.
+	Installation string
+
+	// Policy selects the runs to keep.
+	Policy RetentionPolicy
+
+	// DryRun returns the runs that would be pruned without removing them.
+	DryRun bool
+}
+
+// PruneRuns removes the runs of an installation, along with their results and
+// outputs, that are not kept by the retention policy.
+func (s InstallationStore) PruneRuns(ctx context.Context, opts PruneRunsOptions) ([]Run, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := opts.Policy.Validate(); err != nil {
+		return nil, span.Error(err)
+	}
+
+	if !opts.DryRun {
+		if err := checkWritable(s.store, CollectionRuns, "remove"); err != nil {
+			return nil, span.Error(err)
+		}
+	}
+
+	if err := authorize(ctx, s.authz, VerbDelete, CollectionRuns, opts.Namespace, opts.Installation); err != nil {
+		return nil, span.Error(err)
+	}
+
+	if s.ledger != nil && s.ledger.Enabled() {
+		return nil, span.Error(errLedgerPrune)
+	}
+
+	runs, results, err := s.ListRuns(ctx, opts.Namespace, opts.Installation)
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
+	outputs, err := s.GetLastOutputs(ctx, opts.Namespace, opts.Installation)
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
+	prune := opts.Policy.SelectRunsToPrune(time.Now(), runs, results, outputs)
+
+	// Keep the runs that generated the outputs captured by a snapshot, so that it can be restored
+	snapshotRuns, err := s.snapshotRunIDs(ctx, opts.Namespace, opts.Installation)
+	if err != nil {
+		return nil, span.Error(fmt.Errorf("error listing the snapshots of installation %s/%s: %w", opts.Namespace, opts.Installation, err))
+	}
+	kept := prune[:0]
+	for _, run := range prune {
+		if !snapshotRuns[run.ID] {
+			kept = append(kept, run)
+		}
+	}
+	prune = kept
+
+	if len(prune) == 0 || opts.DryRun {
+		return prune, nil
+	}
+
+	runIDs := make([]string, len(prune))
+	for i, run := range prune {
+		runIDs[i] = run.ID
+	}
+	span.Debugf("Pruning %d runs of installation %s/%s", len(runIDs), opts.Namespace, opts.Installation)
+
+	// Remove the outputs and results first, so that a failure doesn't leave behind documents without a run
+	removeChildDocs := RemoveOptions{
+		Filter: bson.M{"runId": bson.M{"$in": runIDs}},
+		All:    true,
+	}
+	if err = s.store.Remove(ctx, CollectionOutputChunks, removeChildDocs); err != nil {
+		return nil, span.Error(fmt.Errorf("error removing the output chunks of the pruned runs: %w", err))
+	}
+	if err = s.store.Remove(ctx, CollectionStepResults, removeChildDocs); err != nil {
+		return nil, span.Error(fmt.Errorf("error removing the step results of the pruned runs: %w", err))
+	}
+	if err = s.removeOutputBlobs(ctx, removeChildDocs.Filter); err != nil {
+		return nil, span.Error(fmt.Errorf("error removing the offloaded outputs of the pruned runs: %w", err))
+	}
+	if err = s.store.Remove(ctx, CollectionOutputs, removeChildDocs); err != nil {
+		return nil, span.Error(fmt.Errorf("error removing the outputs of the pruned runs: %w", err))
+	}
+	if err = s.store.Remove(ctx, CollectionResults, removeChildDocs); err != nil {
+		return nil, span.Error(fmt.Errorf("error removing the results of the pruned runs: %w", err))
+	}
+
+	removeRuns := RemoveOptions{
+		Filter: bson.M{"_id": bson.M{"$in": runIDs}},
+		All:    true,
+	}
+	if err = s.store.Remove(ctx, CollectionRuns, removeRuns); err != nil {
+		return nil, span.Error(fmt.Errorf("error removing the pruned runs: %w", err))
+	}
+
+	return prune, nil
+}
+
 // EncryptionHandler is a function that transforms data by encrypting or decrypting it.
 type EncryptionHandler func([]byte) ([]byte, error)
 
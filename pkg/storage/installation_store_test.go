@@ -5,8 +5,10 @@ import (
 	"testing"
 
 	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/secrets"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/cnabio/cnab-go/secrets/host"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
@@ -268,6 +270,87 @@ func TestInstallationStorageProvider_DeleteInstallation(t *testing.T) {
 	require.ErrorIs(t, err, ErrNotFound{})
 }
 
+func TestInstallationStorageProvider_PruneRuns(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("dry run", func(t *testing.T) {
+		cp := generateInstallationData(t)
+		defer cp.Close()
+
+		opts := PruneRunsOptions{Namespace: "dev", Installation: "foo", Policy: RetentionPolicy{KeepLast: 1}, DryRun: true}
+		pruned, err := cp.PruneRuns(ctx, opts)
+		require.NoError(t, err, "PruneRuns failed")
+		require.Len(t, pruned, 2, "expected the install and test runs to be selected")
+
+		runs, _, err := cp.ListRuns(ctx, "dev", "foo")
+		require.NoError(t, err, "ListRuns failed")
+		assert.Len(t, runs, 4, "expected no runs to be removed during a dry run")
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		cp := generateInstallationData(t)
+		defer cp.Close()
+
+		allRuns, _, err := cp.ListRuns(ctx, "dev", "foo")
+		require.NoError(t, err, "ListRuns failed")
+		for _, run := range allRuns {
+			step := run.NewStepResult(StepResult{Index: 0, Mixin: "exec", Status: StepStatusSucceeded})
+			require.NoError(t, cp.InsertStepResults(ctx, []StepResult{step}), "InsertStepResults failed")
+		}
+
+		opts := PruneRunsOptions{Namespace: "dev", Installation: "foo", Policy: RetentionPolicy{KeepLast: 1}}
+		pruned, err := cp.PruneRuns(ctx, opts)
+		require.NoError(t, err, "PruneRuns failed")
+		require.Len(t, pruned, 2)
+		assert.Equal(t, cnab.ActionInstall, pruned[0].Action)
+		assert.Equal(t, "test", pruned[1].Action)
+
+		runs, results, err := cp.ListRuns(ctx, "dev", "foo")
+		require.NoError(t, err, "ListRuns failed")
+		require.Len(t, runs, 2)
+		assert.Equal(t, cnab.ActionUpgrade, runs[0].Action, "expected the upgrade run to be kept because it has the last value of the outputs")
+		assert.Equal(t, cnab.ActionUninstall, runs[1].Action, "expected the most recent run to be kept")
+		assert.Len(t, results, 2)
+
+		for _, run := range pruned {
+			runResults, err := cp.ListResults(ctx, run.ID)
+			require.NoError(t, err, "ListResults failed")
+			assert.Empty(t, runResults, "expected the results of the pruned runs to be removed")
+
+			steps, err := cp.ListStepResults(ctx, run.ID)
+			require.NoError(t, err, "ListStepResults failed")
+			assert.Empty(t, steps, "expected the step results of the pruned runs to be removed")
+		}
+
+		steps, err := cp.ListStepResults(ctx, runs[0].ID)
+		require.NoError(t, err, "ListStepResults failed")
+		assert.Len(t, steps, 1, "expected the step results of the kept runs to be kept")
+
+		outputs, err := cp.GetLastOutputs(ctx, "dev", "foo")
+		require.NoError(t, err, "GetLastOutputs failed")
+		output1, ok := outputs.GetByName("output1")
+		require.True(t, ok, "expected the last value of output1 to be kept")
+		assert.Equal(t, "upgrade output1", string(output1.Value))
+
+		var installOutputs []Output
+		err = cp.store.Find(ctx, CollectionOutputs, FindOptions{Filter: bson.M{"runId": pruned[0].ID}}, &installOutputs)
+		require.NoError(t, err)
+		assert.Empty(t, installOutputs, "expected the outputs of the pruned runs to be removed")
+
+		installations, err := cp.ListInstallations(ctx, ListOptions{Namespace: "dev"})
+		require.NoError(t, err, "ListInstallations failed")
+		assert.Len(t, installations, 3, "expected the installations to not be modified")
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		cp := generateInstallationData(t)
+		defer cp.Close()
+
+		_, err := cp.PruneRuns(ctx, PruneRunsOptions{Namespace: "dev", Installation: "foo"})
+		require.ErrorContains(t, err, "at least one rule must be specified")
+	})
+}
+
 func TestInstallationStorageProvider_Run(t *testing.T) {
 	cp := generateInstallationData(t)
 
@@ -324,6 +407,38 @@ func TestInstallationStorageProvider_Run(t *testing.T) {
 	})
 }
 
+func TestInstallationStorageProvider_RunParameters(t *testing.T) {
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	inst := cp.CreateInstallation(NewInstallation("dev", "mybuns"))
+	run := inst.NewRun(cnab.ActionInstall)
+	run.Bundle = bundle.Bundle{
+		Definitions: definition.Definitions{
+			"region": {Type: "string", Default: "eastus"},
+		},
+		Parameters: map[string]bundle.Parameter{
+			"region": {Definition: "region"},
+			"zone":   {Definition: "region"},
+		},
+	}
+	run.Parameters = NewParameterSet(run.Namespace, run.Installation,
+		ValueStrategy("region", "eastus"),
+		ValueStrategy("zone", "eastus-1"))
+	cp.CreateRun(run)
+	assert.Len(t, run.Parameters.Parameters, 2, "InsertRun should not modify the caller's parameters")
+
+	storedRun, err := cp.GetRun(context.Background(), run.ID)
+	require.NoError(t, err, "GetRun failed")
+	require.Len(t, storedRun.Parameters.Parameters, 1, "only parameters that differ from the bundle defaults should be saved")
+	assert.Equal(t, "zone", storedRun.Parameters.Parameters[0].Name)
+	params := storedRun.GetParameters().Parameters
+	require.Len(t, params, 2, "the full set of parameters should be reconstructed from the saved run")
+	assert.Equal(t, "zone", params[0].Name)
+	assert.Equal(t, "region", params[1].Name)
+	assert.Equal(t, secrets.Source{Key: host.SourceValue, Value: "eastus"}, params[1].Source)
+}
+
 func TestInstallationStorageProvider_Results(t *testing.T) {
 	cp := generateInstallationData(t)
 	defer cp.Close()
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LabelOperator is the comparison that a LabelRequirement makes against a label.
+type LabelOperator string
+
+const (
+	// LabelEquals requires that the label is set to the value.
+	LabelEquals LabelOperator = "="
+
+	// LabelNotEquals requires that the label is not set to the value,
+	// including when the label is not set at all.
+	LabelNotEquals LabelOperator = "!="
+
+	// LabelExists requires that the label is set, with any value.
+	LabelExists LabelOperator = "exists"
+
+	// LabelDoesNotExist requires that the label is not set.
+	LabelDoesNotExist LabelOperator = "!"
+)
+
+// labelKeyPattern restricts label keys to characters that are safe to use in
+// the path of a document field, e.g. team or ticket-id. A period would be
+// interpreted as a nested field.
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_/-]*[A-Za-z0-9])?$`)
+
+// LabelRequirement is a single condition of a LabelSelector.
+type LabelRequirement struct {
+	// Key of the label.
+	Key string
+
+	// Operator used to compare the label with the Value.
+	Operator LabelOperator
+
+	// Value of the label, used with the LabelEquals and LabelNotEquals operators.
+	Value string
+}
+
+func (r LabelRequirement) String() string {
+	switch r.Operator {
+	case LabelExists:
+		return r.Key
+	case LabelDoesNotExist:
+		return "!" + r.Key
+	default:
+		return r.Key + string(r.Operator) + r.Value
+	}
+}
+
+// Matches determines if the labels meet the requirement.
+func (r LabelRequirement) Matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case LabelEquals:
+		return ok && value == r.Value
+	case LabelNotEquals:
+		return !ok || value != r.Value
+	case LabelExists:
+		return ok
+	case LabelDoesNotExist:
+		return !ok
+	}
+	return false
+}
+
+// toFilter returns the query for documents whose labels meet the requirement.
+func (r LabelRequirement) toFilter() map[string]interface{} {
+	field := "labels." + r.Key
+	switch r.Operator {
+	case LabelNotEquals:
+		return map[string]interface{}{field: map[string]interface{}{"$ne": r.Value}}
+	case LabelExists:
+		return map[string]interface{}{field: map[string]interface{}{"$exists": true}}
+	case LabelDoesNotExist:
+		return map[string]interface{}{field: map[string]interface{}{"$exists": false}}
+	default:
+		return map[string]interface{}{field: r.Value}
+	}
+}
+
+// LabelSelector selects documents by their labels. A document is selected
+// when its labels meet all the requirements.
+type LabelSelector []LabelRequirement
+
+// ParseLabelSelector parses a list of label requirements, each formatted as
+// KEY=VALUE, KEY!=VALUE, KEY (the label is set) or !KEY (the label is not set).
+// Requirements may also be combined in a single comma separated value, for
+// example team=red,env!=prod.
+func ParseLabelSelector(raw []string) (LabelSelector, error) {
+	var selector LabelSelector
+	for _, value := range raw {
+		for _, term := range strings.Split(value, ",") {
+			term = strings.TrimSpace(term)
+			if term == "" {
+				continue
+			}
+
+			req, err := parseLabelRequirement(term)
+			if err != nil {
+				return nil, err
+			}
+			selector = append(selector, req)
+		}
+	}
+	return selector, nil
+}
+
+func parseLabelRequirement(term string) (LabelRequirement, error) {
+	var req LabelRequirement
+	if i := strings.Index(term, "!="); i >= 0 {
+		req = LabelRequirement{Key: term[:i], Operator: LabelNotEquals, Value: term[i+2:]}
+	} else if i = strings.Index(term, "="); i >= 0 {
+		// Accept == as well, the same as kubectl
+		req = LabelRequirement{Key: term[:i], Operator: LabelEquals, Value: strings.TrimPrefix(term[i+1:], "=")}
+	} else if strings.HasPrefix(term, "!") {
+		req = LabelRequirement{Key: term[1:], Operator: LabelDoesNotExist}
+	} else {
+		req = LabelRequirement{Key: term, Operator: LabelExists}
+	}
+
+	req.Key = strings.TrimSpace(req.Key)
+	req.Value = strings.TrimSpace(req.Value)
+	if !labelKeyPattern.MatchString(req.Key) {
+		return LabelRequirement{}, fmt.Errorf("invalid label selector %q: the label key must start and end with a letter or number, and may only contain letters, numbers, '-', '_' and '/'", term)
+	}
+	return req, nil
+}
+
+func (s LabelSelector) String() string {
+	terms := make([]string, len(s))
+	for i, r := range s {
+		terms[i] = r.String()
+	}
+	return strings.Join(terms, ",")
+}
+
+// Matches determines if the labels meet all the requirements of the selector.
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// ApplyToFilter adds the requirements of the selector to a query filter.
+func (s LabelSelector) ApplyToFilter(filter map[string]interface{}) {
+	if len(s) == 0 {
+		return
+	}
+
+	// Requirements are combined with $and, since several may apply to the same label
+	conditions := make([]interface{}, 0, len(s))
+	if existing, ok := filter["$and"].([]interface{}); ok {
+		conditions = append(conditions, existing...)
+	}
+	for _, r := range s {
+		conditions = append(conditions, r.toFilter())
+	}
+	filter["$and"] = conditions
+}
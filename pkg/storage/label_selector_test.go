@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	testcases := []struct {
+		name    string
+		raw     []string
+		want    LabelSelector
+		wantErr string
+	}{
+		{name: "empty", raw: nil, want: nil},
+		{name: "equals", raw: []string{"team=red"}, want: LabelSelector{{Key: "team", Operator: LabelEquals, Value: "red"}}},
+		{name: "double equals", raw: []string{"team==red"}, want: LabelSelector{{Key: "team", Operator: LabelEquals, Value: "red"}}},
+		{name: "empty value", raw: []string{"team="}, want: LabelSelector{{Key: "team", Operator: LabelEquals}}},
+		{name: "not equals", raw: []string{"env!=prod"}, want: LabelSelector{{Key: "env", Operator: LabelNotEquals, Value: "prod"}}},
+		{name: "exists", raw: []string{"ticket"}, want: LabelSelector{{Key: "ticket", Operator: LabelExists}}},
+		{name: "does not exist", raw: []string{"!ticket"}, want: LabelSelector{{Key: "ticket", Operator: LabelDoesNotExist}}},
+		{name: "comma separated", raw: []string{"team=red, !ticket"}, want: LabelSelector{
+			{Key: "team", Operator: LabelEquals, Value: "red"},
+			{Key: "ticket", Operator: LabelDoesNotExist},
+		}},
+		{name: "invalid key", raw: []string{"app.name=mysql"}, wantErr: `invalid label selector "app.name=mysql"`},
+		{name: "missing key", raw: []string{"=red"}, wantErr: `invalid label selector "=red"`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseLabelSelector(tc.raw)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestLabelSelector_Matches(t *testing.T) {
+	selector, err := ParseLabelSelector([]string{"team=red", "env!=prod", "ticket", "!skip"})
+	require.NoError(t, err)
+	assert.Equal(t, "team=red,env!=prod,ticket,!skip", selector.String())
+
+	assert.True(t, selector.Matches(map[string]string{"team": "red", "ticket": "OPS-1"}))
+	assert.True(t, selector.Matches(map[string]string{"team": "red", "env": "dev", "ticket": ""}))
+	assert.False(t, selector.Matches(map[string]string{"team": "red", "env": "prod", "ticket": "OPS-1"}))
+	assert.False(t, selector.Matches(map[string]string{"team": "red"}))
+	assert.False(t, selector.Matches(map[string]string{"team": "red", "ticket": "OPS-1", "skip": "true"}))
+	assert.True(t, LabelSelector(nil).Matches(nil), "an empty selector should match everything")
+}
@@ -0,0 +1,625 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/failure"
+	"get.porter.sh/porter/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var _ Document = LedgerEntry{}
+
+// CollectionLedger is the collection of the entries in the run ledger.
+const CollectionLedger = "ledger"
+
+// Kinds of records in the run ledger.
+const (
+	LedgerKindRun    = "run"
+	LedgerKindResult = "result"
+)
+
+// LedgerEntry records a run or result of an installation in the run ledger.
+// Each entry is chained to the previous entry for the installation with a
+// hash, so that a run or result that is changed, removed or added without
+// Porter is detected when the ledger is verified.
+type LedgerEntry struct {
+	// ID of the entry, made from the installation and the sequence of the entry.
+	ID string `json:"_id"`
+
+	// Namespace of the installation.
+	Namespace string `json:"namespace"`
+
+	// Installation name.
+	Installation string `json:"installation"`
+
+	// Sequence of the entry in the ledger for the installation, starting at 1.
+	Sequence int64 `json:"sequence"`
+
+	// Kind of record, run or result.
+	Kind string `json:"kind"`
+
+	// RecordID is the ID of the run or result.
+	RecordID string `json:"recordId"`
+
+	// RecordHash is the hash of the fields of the run or result that do not
+	// change after it is saved.
+	RecordHash string `json:"recordHash"`
+
+	// PreviousHash is the hash of the previous entry for the installation.
+	PreviousHash string `json:"previousHash"`
+
+	// Hash of the entry, including the hash of the previous entry.
+	Hash string `json:"hash"`
+
+	// Signature of the hash of the entry, base64 encoded.
+	Signature string `json:"signature,omitempty"`
+
+	// Created timestamp of the entry.
+	Created time.Time `json:"created"`
+}
+
+func (e LedgerEntry) DefaultDocumentFilter() map[string]interface{} {
+	return map[string]interface{}{"_id": e.ID}
+}
+
+// ComputeHash returns the hash of the entry, from every field except the
+// hash and the signature.
+func (e LedgerEntry) ComputeHash() string {
+	fields := []string{
+		strconv.FormatInt(e.Sequence, 10),
+		e.Namespace,
+		e.Installation,
+		e.Kind,
+		e.RecordID,
+		e.RecordHash,
+		e.PreviousHash,
+		strconv.FormatInt(e.Created.UnixMilli(), 10),
+	}
+	return hashLedgerValue([]byte(strings.Join(fields, "\n")))
+}
+
+func hashLedgerValue(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// ledgerMutableFields are the fields of a run or result document that are
+// changed without a new entry in the ledger: the schema version is changed by
+// migrations, and the heartbeat is updated while a bundle is running.
+var ledgerMutableFields = []string{"schemaVersion", "heartbeat"}
+
+// hashLedgerRecord returns the hash of the canonical form of a run or result
+// document. Every field is hashed, except for ledgerMutableFields. The
+// document is canonicalized so that it has the same hash after it is read
+// back from any storage plugin: object keys are sorted, empty values are
+// removed, and timestamps are converted to UTC and truncated to
+// milliseconds, the precision of the timestamps saved by the storage plugins.
+func hashLedgerRecord(record interface{}) (string, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling the record for the run ledger: %w", err)
+	}
+
+	var doc map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&doc); err != nil {
+		return "", fmt.Errorf("error reading the record for the run ledger: %w", err)
+	}
+	for _, field := range ledgerMutableFields {
+		delete(doc, field)
+	}
+
+	data, err = json.Marshal(canonicalLedgerValue(doc))
+	if err != nil {
+		return "", fmt.Errorf("error marshaling the record for the run ledger: %w", err)
+	}
+	return hashLedgerValue(data), nil
+}
+
+// canonicalLedgerValue converts a value decoded from JSON to its canonical
+// form, returning nil for empty values.
+func canonicalLedgerValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if item = canonicalLedgerValue(item); item != nil {
+				out[key] = item
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return out
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = canonicalLedgerValue(item)
+		}
+		return out
+	case string:
+		if v == "" {
+			return nil
+		}
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			if t.IsZero() {
+				return nil
+			}
+			return t.UTC().Truncate(time.Millisecond).Format(time.RFC3339Nano)
+		}
+		return v
+	case bool:
+		if !v {
+			return nil
+		}
+		return v
+	case json.Number:
+		if v == "0" {
+			return nil
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// runLedgerHash returns the hash of a run, with its parameters compacted the
+// same way as when the run is saved.
+func runLedgerHash(run Run) (string, error) {
+	run.CompactParameters()
+	return hashLedgerRecord(run)
+}
+
+func resultLedgerHash(result Result) (string, error) {
+	return hashLedgerRecord(result)
+}
+
+// RunLedger decides if runs and results are recorded in the run ledger, and
+// signs and verifies the entries in the ledger.
+type RunLedger interface {
+	// Enabled determines if runs and results are recorded in the ledger.
+	Enabled() bool
+
+	// Sign returns the signature of the hash of an entry, or nil when the
+	// entries are not signed.
+	Sign(ctx context.Context, hash string) ([]byte, error)
+
+	// Verify checks the signature of the hash of an entry.
+	Verify(ctx context.Context, hash string, signature []byte) error
+
+	// RequiresSignature determines if every entry must be signed.
+	RequiresSignature() bool
+}
+
+var _ RunLedger = ConfigRunLedger{}
+
+// ConfigRunLedger is the RunLedger configured with the run-ledger section of
+// the Porter config file.
+type ConfigRunLedger struct {
+	config *config.Config
+}
+
+// NewConfigRunLedger creates a RunLedger using the run-ledger section of the config.
+func NewConfigRunLedger(c *config.Config) ConfigRunLedger {
+	return ConfigRunLedger{config: c}
+}
+
+func (l ConfigRunLedger) Enabled() bool {
+	return l.config != nil && l.config.Data.RunLedger.Enabled
+}
+
+func (l ConfigRunLedger) RequiresSignature() bool {
+	return l.config != nil && l.config.Data.RunLedger.IsSigned()
+}
+
+func (l ConfigRunLedger) Sign(ctx context.Context, hash string) ([]byte, error) {
+	cfg := l.config.Data.RunLedger
+	switch {
+	case cfg.SigningKey != "":
+		data, err := l.readKey(cfg.SigningKey)
+		if err != nil {
+			return nil, err
+		}
+		key, err := parseLedgerPrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid run ledger signing key %s: %w", cfg.SigningKey, err)
+		}
+		return signLedgerHash(key, []byte(hash))
+	case len(cfg.SignCommand) > 0:
+		var stdout, stderr bytes.Buffer
+		cmd := l.config.NewCommand(ctx, cfg.SignCommand[0], cfg.SignCommand[1:]...)
+		cmd.Stdin = strings.NewReader(hash)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("the run ledger sign command %s failed: %w\n%s", cfg.SignCommand[0], err, stderr.String())
+		}
+		return decodeLedgerSignature(stdout.Bytes()), nil
+	}
+	return nil, nil
+}
+
+func (l ConfigRunLedger) Verify(ctx context.Context, hash string, signature []byte) error {
+	keyPath := l.config.Data.RunLedger.PublicKey
+	if keyPath == "" {
+		return errors.New("no public key is configured with run-ledger.public-key to verify the signature")
+	}
+
+	data, err := l.readKey(keyPath)
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("invalid run ledger public key %s: the key is not PEM encoded", keyPath)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("invalid run ledger public key %s: %w", keyPath, err)
+	}
+	return verifyLedgerSignature(key, []byte(hash), signature)
+}
+
+// readKey reads a key file, relative to PORTER_HOME when the path is not absolute.
+func (l ConfigRunLedger) readKey(keyPath string) ([]byte, error) {
+	if !filepath.IsAbs(keyPath) {
+		home, err := l.config.GetHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		keyPath = filepath.Join(home, keyPath)
+	}
+
+	data, err := l.config.FileSystem.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the run ledger key %s: %w", keyPath, err)
+	}
+	return data, nil
+}
+
+// decodeLedgerSignature decodes a signature printed by a sign command, which
+// may be base64 encoded.
+func decodeLedgerSignature(data []byte) []byte {
+	if sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data))); err == nil {
+		return sig
+	}
+	return data
+}
+
+func parseLedgerPrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("the key is not PEM encoded")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey, ed25519.PrivateKey, *rsa.PrivateKey:
+		return k.(crypto.Signer), nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T, only ECDSA, Ed25519 and RSA keys are supported", key)
+	}
+}
+
+// signLedgerHash signs the contents. ECDSA and RSA signatures are over the
+// SHA-256 digest of the contents, like signatures created by cosign sign-blob.
+func signLedgerHash(key crypto.Signer, contents []byte) ([]byte, error) {
+	digest := sha256.Sum256(contents)
+
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		return ecdsa.SignASN1(rand.Reader, k, digest[:])
+	case ed25519.PrivateKey:
+		return ed25519.Sign(k, contents), nil
+	case *rsa.PrivateKey:
+		return rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
+func verifyLedgerSignature(key crypto.PublicKey, contents []byte, sig []byte) error {
+	digest := sha256.Sum256(contents)
+
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, digest[:], sig) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, contents, sig) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig)
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// appendToLedger records a run or result in the run ledger of its
+// installation. It is called before the run or result is saved, so that a
+// record is never saved without being in the ledger, and returns a function
+// that removes the entry when the record could not be saved.
+func (s InstallationStore) appendToLedger(ctx context.Context, kind string, namespace string, installation string, recordID string, recordHash string) (func() error, error) {
+	rollback := func() error { return nil }
+	if s.ledger == nil || !s.ledger.Enabled() {
+		return rollback, nil
+	}
+
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	var last []LedgerEntry
+	opts := FindOptions{
+		Sort:  []string{"-sequence"},
+		Limit: 1,
+		Filter: bson.M{
+			"namespace":    namespace,
+			"installation": installation,
+		},
+	}
+	if err := s.store.Find(ctx, CollectionLedger, opts, &last); err != nil {
+		return rollback, span.Error(fmt.Errorf("could not read the run ledger of installation %s/%s: %w", namespace, installation, err))
+	}
+
+	entry := LedgerEntry{
+		Namespace:    namespace,
+		Installation: installation,
+		Sequence:     1,
+		Kind:         kind,
+		RecordID:     recordID,
+		RecordHash:   recordHash,
+		Created:      time.Now().UTC().Truncate(time.Millisecond),
+	}
+	if len(last) > 0 {
+		entry.Sequence = last[0].Sequence + 1
+		entry.PreviousHash = last[0].Hash
+	}
+	entry.ID = fmt.Sprintf("%s/%s/%d", namespace, installation, entry.Sequence)
+	entry.Hash = entry.ComputeHash()
+
+	sig, err := s.ledger.Sign(ctx, entry.Hash)
+	if err != nil {
+		return rollback, span.Error(fmt.Errorf("could not sign the run ledger entry for %s %s: %w", kind, recordID, err))
+	}
+	if sig != nil {
+		entry.Signature = base64.StdEncoding.EncodeToString(sig)
+	}
+
+	// The id of the entry includes its sequence, so when two entries are
+	// appended at the same time, only one is saved
+	err = s.store.Insert(ctx, CollectionLedger, InsertOptions{Documents: []interface{}{entry}})
+	if err != nil {
+		return rollback, span.Error(fmt.Errorf("could not record %s %s in the run ledger: %w", kind, recordID, err))
+	}
+
+	rollback = func() error {
+		removeOpts := RemoveOptions{Filter: bson.M{"_id": entry.ID, "hash": entry.Hash}}
+		if err := s.store.Remove(ctx, CollectionLedger, removeOpts); err != nil {
+			return fmt.Errorf("could not remove the run ledger entry for %s %s: %w", kind, recordID, err)
+		}
+		return nil
+	}
+	return rollback, nil
+}
+
+// saveWithLedger records a run or result in the run ledger, then saves it with
+// the save function. The ledger entry is removed when the record can't be saved.
+func (s InstallationStore) saveWithLedger(ctx context.Context, kind string, namespace string, installation string, recordID string, recordHash string, save func() error) error {
+	rollback, err := s.appendToLedger(ctx, kind, namespace, installation, recordID, recordHash)
+	if err != nil {
+		return err
+	}
+
+	if err = save(); err != nil {
+		if rollbackErr := rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w\n%s", err, rollbackErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// LedgerReport is the result of verifying the run ledger of an installation.
+type LedgerReport struct {
+	// Namespace of the installation.
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// Installation name.
+	Installation string `json:"installation" yaml:"installation"`
+
+	// Entries is the number of entries in the ledger.
+	Entries int `json:"entries" yaml:"entries"`
+
+	// Signed is the number of entries with a valid signature.
+	Signed int `json:"signed" yaml:"signed"`
+
+	// Problems found in the ledger.
+	Problems []LedgerProblem `json:"problems" yaml:"problems"`
+}
+
+// Valid determines if no problems were found in the ledger.
+func (r LedgerReport) Valid() bool {
+	return len(r.Problems) == 0
+}
+
+// LedgerProblem is a problem found when verifying the run ledger.
+type LedgerProblem struct {
+	// Sequence of the entry with the problem, or 0 when the record is not in the ledger.
+	Sequence int64 `json:"sequence" yaml:"sequence"`
+
+	// Kind of record, run or result.
+	Kind string `json:"kind" yaml:"kind"`
+
+	// RecordID is the ID of the run or result.
+	RecordID string `json:"recordId" yaml:"recordId"`
+
+	// Description of the problem.
+	Description string `json:"description" yaml:"description"`
+}
+
+// VerifyLedger checks that the run ledger of an installation is chained
+// correctly, that the signatures of its entries are valid, and that the runs
+// and results of the installation match the ledger.
+func (s InstallationStore) VerifyLedger(ctx context.Context, namespace string, installation string) (LedgerReport, error) {
+	ctx, span := tracing.StartSpan(ctx, installationAttribute(namespace, installation))
+	defer span.EndSpan()
+
+	report := LedgerReport{Namespace: namespace, Installation: installation}
+	if err := authorize(ctx, s.authz, VerbRead, CollectionLedger, namespace, installation); err != nil {
+		return report, span.Error(err)
+	}
+	if s.ledger == nil {
+		return report, span.Error(errors.New("the run ledger is not configured"))
+	}
+
+	var entries []LedgerEntry
+	opts := FindOptions{
+		Sort: []string{"sequence"},
+		Filter: bson.M{
+			"namespace":    namespace,
+			"installation": installation,
+		},
+	}
+	if err := s.store.Find(ctx, CollectionLedger, opts, &entries); err != nil {
+		return report, span.Error(fmt.Errorf("could not read the run ledger of installation %s/%s: %w", namespace, installation, err))
+	}
+	report.Entries = len(entries)
+
+	runs, results, err := s.ListRuns(ctx, namespace, installation)
+	if err != nil {
+		return report, span.Error(err)
+	}
+	recordHashes := make(map[string]string, len(runs))
+	for _, run := range runs {
+		if recordHashes[run.ID], err = runLedgerHash(run); err != nil {
+			return report, span.Error(err)
+		}
+		for _, result := range results[run.ID] {
+			if recordHashes[result.ID], err = resultLedgerHash(result); err != nil {
+				return report, span.Error(err)
+			}
+		}
+	}
+
+	// A record is entered in the ledger again each time it is changed, so it
+	// is compared with its last entry
+	lastEntry := make(map[string]int64, len(entries))
+	for _, entry := range entries {
+		lastEntry[entry.RecordID] = entry.Sequence
+	}
+
+	addProblem := func(entry LedgerEntry, description string, args ...interface{}) {
+		report.Problems = append(report.Problems, LedgerProblem{
+			Sequence:    entry.Sequence,
+			Kind:        entry.Kind,
+			RecordID:    entry.RecordID,
+			Description: fmt.Sprintf(description, args...),
+		})
+	}
+
+	recorded := make(map[string]bool, len(entries))
+	previous := LedgerEntry{}
+	for _, entry := range entries {
+		recorded[entry.RecordID] = true
+
+		if entry.Sequence != previous.Sequence+1 {
+			addProblem(entry, "the entries after sequence %d and before this entry were removed", previous.Sequence)
+		}
+		if entry.PreviousHash != previous.Hash {
+			addProblem(entry, "the entry is not chained to the previous entry")
+		}
+		if entry.ComputeHash() != entry.Hash {
+			addProblem(entry, "the entry was modified")
+		}
+
+		if entry.Signature == "" {
+			if s.ledger.RequiresSignature() {
+				addProblem(entry, "the entry is not signed")
+			}
+		} else if sig, err := base64.StdEncoding.DecodeString(entry.Signature); err != nil {
+			addProblem(entry, "the signature is not base64 encoded")
+		} else if err = s.ledger.Verify(ctx, entry.Hash, sig); err != nil {
+			addProblem(entry, "the signature could not be verified: %s", err)
+		} else {
+			report.Signed++
+		}
+
+		recordHash, ok := recordHashes[entry.RecordID]
+		if !ok {
+			if lastEntry[entry.RecordID] == entry.Sequence {
+				addProblem(entry, "the %s was removed", entry.Kind)
+			}
+		} else if lastEntry[entry.RecordID] == entry.Sequence && recordHash != entry.RecordHash {
+			addProblem(entry, "the %s was modified", entry.Kind)
+		}
+
+		previous = entry
+	}
+
+	// Every run and result must be in the ledger, including when the ledger
+	// is empty, so that removing the ledger is detected
+	checkRecorded := func(kind string, id string) {
+		if _, ok := lastEntry[id]; !ok {
+			report.Problems = append(report.Problems, LedgerProblem{
+				Kind:        kind,
+				RecordID:    id,
+				Description: fmt.Sprintf("the %s is not recorded in the ledger", kind),
+			})
+		}
+	}
+	for _, run := range runs {
+		checkRecorded(LedgerKindRun, run.ID)
+		for _, result := range results[run.ID] {
+			checkRecorded(LedgerKindResult, result.ID)
+		}
+	}
+
+	return report, nil
+}
+
+// errLedgerPrune is returned when runs are pruned while the run ledger is enabled.
+var errLedgerPrune = failure.PolicyDenied(errors.New("runs cannot be pruned while the run ledger is enabled, because the ledger must keep every run"))
+
+// errLedgerRemove is returned when an installation is removed while the run ledger is enabled.
+var errLedgerRemove = failure.PolicyDenied(errors.New("installations cannot be removed while the run ledger is enabled, because the ledger must keep every run"))
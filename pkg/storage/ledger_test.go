@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/failure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func newLedgerTestProvider(t *testing.T) (*config.TestConfig, *TestInstallationProvider) {
+	tc := config.NewTestConfig(t)
+	tc.Data.RunLedger.Enabled = true
+	cp := NewTestInstallationProviderFor(t, NewTestStore(tc))
+	cp.SetRunLedger(NewConfigRunLedger(tc.Config))
+	t.Cleanup(func() { cp.Close() })
+	return tc, cp
+}
+
+// writeLedgerKeys generates a key pair for signing the run ledger, and saves
+// it to the test file system.
+func writeLedgerKeys(t *testing.T, tc *config.TestConfig, name string) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	privBytes, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	pubBytes, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+
+	require.NoError(t, tc.FileSystem.WriteFile("/keys/"+name+".key", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privBytes}), 0600))
+	require.NoError(t, tc.FileSystem.WriteFile("/keys/"+name+".pub", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubBytes}), 0600))
+}
+
+func createLedgerHistory(cp *TestInstallationProvider) (Run, Result) {
+	inst := cp.CreateInstallation(NewInstallation("dev", "mysql"))
+	run := cp.CreateRun(inst.NewRun(cnab.ActionInstall))
+	result := cp.CreateResult(run.NewResult(cnab.StatusSucceeded))
+	return run, result
+}
+
+func TestInstallationStore_VerifyLedger(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("valid", func(t *testing.T) {
+		_, cp := newLedgerTestProvider(t)
+		createLedgerHistory(cp)
+
+		report, err := cp.VerifyLedger(ctx, "dev", "mysql")
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Entries)
+		assert.True(t, report.Valid(), "%v", report.Problems)
+	})
+
+	t.Run("run modified", func(t *testing.T) {
+		_, cp := newLedgerTestProvider(t)
+		run, _ := createLedgerHistory(cp)
+
+		run.Action = cnab.ActionUninstall
+		require.NoError(t, cp.store.Update(ctx, CollectionRuns, UpdateOptions{Document: run}))
+
+		report, err := cp.VerifyLedger(ctx, "dev", "mysql")
+		require.NoError(t, err)
+		require.Len(t, report.Problems, 1)
+		assert.Equal(t, run.ID, report.Problems[0].RecordID)
+		assert.Equal(t, "the run was modified", report.Problems[0].Description)
+	})
+
+	t.Run("any field modified", func(t *testing.T) {
+		_, cp := newLedgerTestProvider(t)
+		run, result := createLedgerHistory(cp)
+
+		run.ChangeTicket = "CHG0001"
+		require.NoError(t, cp.store.Update(ctx, CollectionRuns, UpdateOptions{Document: run}))
+		result.Message = "all good"
+		require.NoError(t, cp.store.Update(ctx, CollectionResults, UpdateOptions{Document: result}))
+
+		report, err := cp.VerifyLedger(ctx, "dev", "mysql")
+		require.NoError(t, err)
+		require.Len(t, report.Problems, 2)
+		assert.Equal(t, "the run was modified", report.Problems[0].Description)
+		assert.Equal(t, "the result was modified", report.Problems[1].Description)
+	})
+
+	t.Run("changes recorded", func(t *testing.T) {
+		_, cp := newLedgerTestProvider(t)
+		run, _ := createLedgerHistory(cp)
+
+		run.AddNote("approved by the change board")
+		require.NoError(t, cp.UpsertRun(ctx, run))
+		require.NoError(t, cp.UpdateRunHeartbeat(ctx, run.ID, time.Now()))
+
+		report, err := cp.VerifyLedger(ctx, "dev", "mysql")
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Entries, "the note should be recorded in a new entry")
+		assert.True(t, report.Valid(), "%v", report.Problems)
+	})
+
+	t.Run("empty ledger", func(t *testing.T) {
+		tc, cp := newLedgerTestProvider(t)
+		tc.Data.RunLedger.Enabled = false
+		createLedgerHistory(cp)
+		tc.Data.RunLedger.Enabled = true
+
+		report, err := cp.VerifyLedger(ctx, "dev", "mysql")
+		require.NoError(t, err)
+		assert.Equal(t, 0, report.Entries)
+		require.Len(t, report.Problems, 2, "runs and results without a ledger should fail verification")
+		assert.Equal(t, "the run is not recorded in the ledger", report.Problems[0].Description)
+		assert.Equal(t, "the result is not recorded in the ledger", report.Problems[1].Description)
+	})
+
+	t.Run("record not saved", func(t *testing.T) {
+		_, cp := newLedgerTestProvider(t)
+		run, _ := createLedgerHistory(cp)
+
+		// Inserting the same run again fails, and the entry for it is removed
+		require.Error(t, cp.InsertRun(ctx, run))
+
+		report, err := cp.VerifyLedger(ctx, "dev", "mysql")
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Entries)
+		assert.True(t, report.Valid(), "%v", report.Problems)
+	})
+
+	t.Run("entry removed", func(t *testing.T) {
+		_, cp := newLedgerTestProvider(t)
+		createLedgerHistory(cp)
+		cp.CreateRun(NewInstallation("dev", "mysql").NewRun(cnab.ActionUpgrade))
+
+		require.NoError(t, cp.store.Remove(ctx, CollectionLedger, RemoveOptions{Filter: bson.M{"sequence": 2}}))
+
+		report, err := cp.VerifyLedger(ctx, "dev", "mysql")
+		require.NoError(t, err)
+		descriptions := make([]string, 0, len(report.Problems))
+		for _, problem := range report.Problems {
+			descriptions = append(descriptions, problem.Description)
+		}
+		assert.Contains(t, descriptions, "the entries after sequence 1 and before this entry were removed")
+		assert.Contains(t, descriptions, "the entry is not chained to the previous entry")
+		assert.Contains(t, descriptions, "the result is not recorded in the ledger")
+	})
+
+	t.Run("entry modified", func(t *testing.T) {
+		_, cp := newLedgerTestProvider(t)
+		createLedgerHistory(cp)
+
+		var entries []LedgerEntry
+		require.NoError(t, cp.store.Find(ctx, CollectionLedger, FindOptions{Sort: []string{"sequence"}}, &entries))
+		entries[1].Kind = LedgerKindRun
+		require.NoError(t, cp.store.Update(ctx, CollectionLedger, UpdateOptions{Document: entries[1]}))
+
+		report, err := cp.VerifyLedger(ctx, "dev", "mysql")
+		require.NoError(t, err)
+		require.NotEmpty(t, report.Problems)
+		assert.Equal(t, "the entry was modified", report.Problems[0].Description)
+	})
+
+	t.Run("signed", func(t *testing.T) {
+		tc, cp := newLedgerTestProvider(t)
+		writeLedgerKeys(t, tc, "ledger")
+		writeLedgerKeys(t, tc, "other")
+		tc.Data.RunLedger.SigningKey = "/keys/ledger.key"
+		tc.Data.RunLedger.PublicKey = "/keys/ledger.pub"
+		createLedgerHistory(cp)
+
+		report, err := cp.VerifyLedger(ctx, "dev", "mysql")
+		require.NoError(t, err)
+		assert.True(t, report.Valid(), "%v", report.Problems)
+		assert.Equal(t, 2, report.Signed)
+
+		tc.Data.RunLedger.PublicKey = "/keys/other.pub"
+		report, err = cp.VerifyLedger(ctx, "dev", "mysql")
+		require.NoError(t, err)
+		require.Len(t, report.Problems, 2)
+		assert.Contains(t, report.Problems[0].Description, "the signature could not be verified")
+	})
+}
+
+func TestInstallationStore_PruneRuns_Ledger(t *testing.T) {
+	_, cp := newLedgerTestProvider(t)
+	createLedgerHistory(cp)
+
+	_, err := cp.PruneRuns(context.Background(), PruneRunsOptions{Namespace: "dev", Installation: "mysql", Policy: RetentionPolicy{KeepLast: 1}})
+	require.Error(t, err)
+	assert.True(t, failure.Is(err, failure.ClassPolicyDenied))
+}
+
+func TestInstallationStore_RemoveInstallation_Ledger(t *testing.T) {
+	_, cp := newLedgerTestProvider(t)
+	createLedgerHistory(cp)
+
+	err := cp.RemoveInstallation(context.Background(), "dev", "mysql")
+	require.Error(t, err)
+	assert.True(t, failure.Is(err, failure.ClassPolicyDenied))
+
+	_, err = cp.GetInstallation(context.Background(), "dev", "mysql")
+	require.NoError(t, err, "the installation should not be removed")
+}
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ListLogsOptions are the filters used to select the runs whose logs are
+// searched.
+type ListLogsOptions struct {
+	// Namespace of the installations.
+	Namespace string
+
+	// Installation name. When empty, the runs of every installation in the
+	// namespace are selected.
+	Installation string
+
+	// Since limits the results to runs created at or after the time.
+	Since time.Time
+}
+
+// ToFindOptions converts the options into a query against the runs collection.
+func (o ListLogsOptions) ToFindOptions() FindOptions {
+	filter := bson.M{"namespace": o.Namespace}
+	if o.Installation != "" {
+		filter["installation"] = o.Installation
+	}
+
+	// Compare against the date the run was created, which is saved in UTC.
+	// Runs saved before the run schema 1.2.0 only have the date once they are
+	// migrated with porter storage migrate.
+	if !o.Since.IsZero() {
+		filter["createdAt"] = bson.M{"$gte": o.Since.UTC()}
+	}
+
+	return FindOptions{
+		Sort:   []string{"_id"},
+		Filter: filter,
+	}
+}
+
+// RunLogs are the logs recorded for a run.
+type RunLogs struct {
+	Run Run
+
+	// Logs is the output that holds the logs persisted for the run, or nil
+	// when the logs of the run were not persisted. Read the value with
+	// InstallationProvider.OpenOutputStream.
+	Logs *Output
+
+	// Steps are the results of the steps executed by the run, sorted by index.
+	Steps []StepResult
+}
+
+// ListRunLogs returns the logs recorded for the runs that match the options,
+// sorted in ascending order by run ID. The runs, their logs and their steps are
+// each selected with a single query.
+func (s InstallationStore) ListRunLogs(ctx context.Context, opts ListLogsOptions) ([]RunLogs, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	var docs []json.RawMessage
+	if err := s.store.Find(ctx, CollectionRuns, opts.ToFindOptions(), &docs); err != nil {
+		return nil, span.Error(err)
+	}
+	runs := make([]Run, len(docs))
+	for i, doc := range docs {
+		var err error
+		if runs[i], err = s.runMigrations.decodeRun(doc); err != nil {
+			return nil, span.Error(fmt.Errorf("error reading run: %w", err))
+		}
+	}
+	runs, err := filterAuthorized(ctx, s.authz, CollectionRuns, runs, func(r Run) string { return r.Namespace })
+	if err != nil {
+		return nil, span.Error(err)
+	}
+	if len(runs) == 0 {
+		return nil, nil
+	}
+
+	runIDs := make([]string, len(runs))
+	for i, run := range runs {
+		runIDs[i] = run.ID
+	}
+	byRun := bson.M{"$in": runIDs}
+
+	// Select the logs from the last result of each run
+	var outputs []Output
+	findLogs := FindOptions{
+		Sort:   []string{"runId", "-resultId"},
+		Filter: bson.M{"runId": byRun, "name": cnab.OutputInvocationImageLogs},
+	}
+	if err = s.store.Find(ctx, CollectionOutputs, findLogs, &outputs); err != nil {
+		return nil, span.Error(err)
+	}
+	if outputs, err = filterAuthorized(ctx, s.authz, CollectionOutputs, outputs, func(o Output) string { return o.Namespace }); err != nil {
+		return nil, span.Error(err)
+	}
+	logs := make(map[string]*Output, len(outputs))
+	for i := range outputs {
+		if _, ok := logs[outputs[i].RunID]; !ok {
+			logs[outputs[i].RunID] = &outputs[i]
+		}
+	}
+
+	var steps []StepResult
+	findSteps := FindOptions{
+		Sort:   []string{"runId", "index"},
+		Filter: bson.M{"runId": byRun},
+	}
+	if err = s.store.Find(ctx, CollectionStepResults, findSteps, &steps); err != nil {
+		return nil, span.Error(err)
+	}
+	if steps, err = filterAuthorized(ctx, s.authz, CollectionStepResults, steps, func(r StepResult) string { return r.Namespace }); err != nil {
+		return nil, span.Error(err)
+	}
+	stepsByRun := make(map[string][]StepResult, len(runs))
+	for _, step := range steps {
+		stepsByRun[step.RunID] = append(stepsByRun[step.RunID], step)
+	}
+
+	results := make([]RunLogs, len(runs))
+	for i, run := range runs {
+		results[i] = RunLogs{Run: run, Logs: logs[run.ID], Steps: stepsByRun[run.ID]}
+	}
+	return results, nil
+}
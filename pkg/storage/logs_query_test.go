@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestListLogsOptions_ToFindOptions(t *testing.T) {
+	since := time.Date(2026, time.March, 4, 10, 0, 0, 0, time.FixedZone("CST", -6*60*60))
+
+	opts := ListLogsOptions{Namespace: "dev", Since: since}.ToFindOptions()
+	assert.Equal(t, bson.M{
+		"namespace": "dev",
+		"createdAt": bson.M{"$gte": since.UTC()},
+	}, opts.Filter, "expected every installation in the namespace to be selected")
+
+	opts = ListLogsOptions{Namespace: "dev", Installation: "mybuns"}.ToFindOptions()
+	assert.Equal(t, bson.M{"namespace": "dev", "installation": "mybuns"}, opts.Filter)
+}
+
+func TestInstallationStore_ListRunLogs(t *testing.T) {
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+	ctx := context.Background()
+
+	foo := cp.CreateInstallation(NewInstallation("dev", "foo"))
+	fooRun := cp.CreateRun(foo.NewRun(cnab.ActionInstall))
+	fooResult := cp.CreateResult(fooRun.NewResult(cnab.StatusFailed))
+	cp.CreateOutput(fooResult.NewOutput(cnab.OutputInvocationImageLogs, []byte("install logs")))
+	retryResult := cp.CreateResult(fooRun.NewResult(cnab.StatusSucceeded))
+	cp.CreateOutput(retryResult.NewOutput(cnab.OutputInvocationImageLogs, []byte("retry logs")))
+
+	oldRun := cp.CreateRun(foo.NewRun(cnab.ActionUpgrade), func(r *Run) {
+		r.Created = time.Now().Add(-30 * 24 * time.Hour)
+	})
+
+	bar := cp.CreateInstallation(NewInstallation("dev", "bar"))
+	barRun := cp.CreateRun(bar.NewRun(cnab.ActionInstall))
+	require.NoError(t, cp.InsertStepResults(ctx, []StepResult{
+		barRun.NewStepResult(StepResult{Index: 1, Description: "Upgrade", Logs: "upgrading"}),
+		barRun.NewStepResult(StepResult{Index: 0, Description: "Backup", Logs: "backing up"}),
+	}))
+
+	other := cp.CreateInstallation(NewInstallation("prod", "foo"))
+	cp.CreateRun(other.NewRun(cnab.ActionInstall))
+
+	listRuns := func(logs []RunLogs) []string {
+		ids := make([]string, len(logs))
+		for i, l := range logs {
+			ids[i] = l.Run.ID
+		}
+		return ids
+	}
+
+	t.Run("namespace", func(t *testing.T) {
+		logs, err := cp.ListRunLogs(ctx, ListLogsOptions{Namespace: "dev"})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{fooRun.ID, oldRun.ID, barRun.ID}, listRuns(logs))
+
+		for _, l := range logs {
+			switch l.Run.ID {
+			case fooRun.ID:
+				require.NotNil(t, l.Logs, "expected the logs of the run to be found")
+				assert.Equal(t, retryResult.ID, l.Logs.ResultID, "expected the logs from the last result of the run")
+			case barRun.ID:
+				assert.Nil(t, l.Logs, "the logs of the run were not persisted")
+				require.Len(t, l.Steps, 2)
+				assert.Equal(t, "Backup", l.Steps[0].Description, "expected the steps to be sorted by index")
+			}
+		}
+	})
+
+	t.Run("installation since", func(t *testing.T) {
+		logs, err := cp.ListRunLogs(ctx, ListLogsOptions{Namespace: "dev", Installation: "foo", Since: time.Now().Add(-7 * 24 * time.Hour)})
+		require.NoError(t, err)
+		assert.Equal(t, []string{fooRun.ID}, listRuns(logs), "expected the old run to be excluded")
+	})
+
+	t.Run("no runs", func(t *testing.T) {
+		logs, err := cp.ListRunLogs(ctx, ListLogsOptions{Namespace: "test"})
+		require.NoError(t, err)
+		assert.Empty(t, logs)
+	})
+}
@@ -7,6 +7,7 @@ import (
 
 	"get.porter.sh/porter/pkg"
 	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/failure"
 	"get.porter.sh/porter/pkg/storage"
 	"get.porter.sh/porter/pkg/tracing"
 )
@@ -19,6 +20,7 @@ const (
 
 var _ storage.Store = &storage.PluginAdapter{}
 var _ storage.Store = &Manager{}
+var _ storage.ReadOnlyStore = &Manager{}
 
 // Manager handles high level functions over Porter's storage systems such as
 // migrating data formats.
@@ -98,6 +100,11 @@ Once your data has been backed up, run the following command to perform the migr
 		}
 		m.initialized = true
 
+		// Indices are only created when they are missing, which is a write
+		if m.Data.ReadOnly {
+			return nil
+		}
+
 		err := storage.EnsureInstallationIndices(ctx, m.store)
 		if err != nil {
 			return err
@@ -112,6 +119,16 @@ Once your data has been backed up, run the following command to perform the migr
 		if err != nil {
 			return err
 		}
+
+		err = storage.EnsureNamespaceIndices(ctx, m.store)
+		if err != nil {
+			return err
+		}
+
+		err = storage.EnsureScheduleIndices(ctx, m.store)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -138,6 +155,9 @@ func (m *Manager) Count(ctx context.Context, collection string, opts storage.Cou
 }
 
 func (m *Manager) EnsureIndex(ctx context.Context, opts storage.EnsureIndexOptions) error {
+	if err := m.checkWritable("", "ensure indices for"); err != nil {
+		return err
+	}
 	if err := m.Connect(ctx); err != nil {
 		return err
 	}
@@ -166,6 +186,9 @@ func (m *Manager) Get(ctx context.Context, collection string, opts storage.GetOp
 }
 
 func (m *Manager) Insert(ctx context.Context, collection string, opts storage.InsertOptions) error {
+	if err := m.checkWritable(collection, "insert"); err != nil {
+		return err
+	}
 	if err := m.Connect(ctx); err != nil {
 		return err
 	}
@@ -173,6 +196,9 @@ func (m *Manager) Insert(ctx context.Context, collection string, opts storage.In
 }
 
 func (m *Manager) Patch(ctx context.Context, collection string, opts storage.PatchOptions) error {
+	if err := m.checkWritable(collection, "patch"); err != nil {
+		return err
+	}
 	if err := m.Connect(ctx); err != nil {
 		return err
 	}
@@ -180,6 +206,9 @@ func (m *Manager) Patch(ctx context.Context, collection string, opts storage.Pat
 }
 
 func (m *Manager) Remove(ctx context.Context, collection string, opts storage.RemoveOptions) error {
+	if err := m.checkWritable(collection, "remove"); err != nil {
+		return err
+	}
 	if err := m.Connect(ctx); err != nil {
 		return err
 	}
@@ -187,12 +216,29 @@ func (m *Manager) Remove(ctx context.Context, collection string, opts storage.Re
 }
 
 func (m *Manager) Update(ctx context.Context, collection string, opts storage.UpdateOptions) error {
+	if err := m.checkWritable(collection, "update"); err != nil {
+		return err
+	}
 	if err := m.Connect(ctx); err != nil {
 		return err
 	}
 	return m.store.Update(ctx, collection, opts)
 }
 
+// IsReadOnly determines if storage is in read-only mode, so that the
+// installation store can reject a change before it has other side effects.
+func (m *Manager) IsReadOnly() bool {
+	return m.Data.ReadOnly
+}
+
+// checkWritable returns ErrReadOnly when storage is in read-only mode.
+func (m *Manager) checkWritable(collection string, operation string) error {
+	if m.IsReadOnly() {
+		return storage.ErrReadOnly{Collection: collection, Operation: operation}
+	}
+	return nil
+}
+
 // loadSchema parses the schema file at the root of PORTER_HOME. This file (when present) contains
 // a list of the current version of each of Porter's storage systems.
 func (m *Manager) loadSchema(ctx context.Context) error {
@@ -210,7 +256,7 @@ func (m *Manager) loadSchema(ctx context.Context) error {
 				return nil
 			}
 		}
-		return fmt.Errorf("could not read storage schema document: %w", err)
+		return failure.StorageUnavailable(fmt.Errorf("could not read storage schema document: %w", err))
 	}
 
 	m.schema = schema
@@ -229,6 +275,10 @@ func (m *Manager) Migrate(ctx context.Context, opts storage.MigrateOptions) erro
 		return fmt.Errorf("cannot call storage.Manager.Migrate before calling Initialize and passing a storage.Sanitizer")
 	}
 
+	if err := m.checkWritable("", "migrate storage"); err != nil {
+		return err
+	}
+
 	m.reset()
 
 	// Let us call connect and not have it kick us out because the schema is out-of-date
@@ -256,6 +306,26 @@ func (m *Manager) Migrate(ctx context.Context, opts storage.MigrateOptions) erro
 	return nil
 }
 
+// DescribeStorage queries the storage backend for the collections of documents
+// stored by Porter, their size and indexes. The database does not need to be
+// migrated to the current schema first.
+func (m *Manager) DescribeStorage(ctx context.Context) (storage.StorageDescription, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	m.allowOutOfDateSchema = true
+	defer func() {
+		m.allowOutOfDateSchema = false
+	}()
+
+	if err := m.Connect(ctx); err != nil {
+		return storage.StorageDescription{}, span.Error(err)
+	}
+
+	desc, err := storage.DescribeStorage(ctx, m.store, m.schema)
+	return desc, span.Error(err)
+}
+
 // When there is no schema, and no existing storage data, create an initial
 // schema file and allow the operation to continue. Don't require a
 // migration.
@@ -288,6 +358,12 @@ func (m *Manager) initEmptyPorterHome(ctx context.Context) (bool, error) {
 		return false, err
 	}
 
+	// Use the current schema without saving it when storage is read-only
+	if m.Data.ReadOnly {
+		m.schema = storage.NewSchema()
+		return true, nil
+	}
+
 	return true, m.WriteSchema(ctx)
 }
 
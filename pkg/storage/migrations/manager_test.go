@@ -243,3 +243,37 @@ func TestParameterStorage_NoMigrationRequiredForEmptyHome(t *testing.T) {
 	require.NoError(t, err, "List failed")
 	assert.Empty(t, names, "Expected an empty list of parameters since porter home is new")
 }
+
+func TestManager_ReadOnly(t *testing.T) {
+	ctx := context.Background()
+	c := config.NewTestConfig(t)
+	c.Data.ReadOnly = true
+	m := NewTestManager(c)
+	defer m.Close()
+
+	err := m.Insert(ctx, storage.CollectionInstallations, storage.InsertOptions{Documents: []interface{}{storage.NewInstallation("dev", "mybuns")}})
+	require.ErrorIs(t, err, storage.ErrReadOnly{})
+	assert.EqualError(t, err, "cannot insert documents in the installations collection because storage is in read-only mode")
+
+	err = m.Update(ctx, storage.CollectionInstallations, storage.UpdateOptions{Document: storage.NewInstallation("dev", "mybuns"), Upsert: true})
+	require.ErrorIs(t, err, storage.ErrReadOnly{})
+
+	err = m.Remove(ctx, storage.CollectionInstallations, storage.RemoveOptions{All: true})
+	require.ErrorIs(t, err, storage.ErrReadOnly{})
+
+	err = m.Patch(ctx, storage.CollectionInstallations, storage.PatchOptions{})
+	require.ErrorIs(t, err, storage.ErrReadOnly{})
+
+	err = m.Migrate(ctx, storage.MigrateOptions{})
+	require.ErrorIs(t, err, storage.ErrReadOnly{})
+
+	// Reads still work, without saving the schema to the empty database
+	count, err := m.Count(ctx, storage.CollectionInstallations, storage.CountOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+	assert.Equal(t, storage.NewSchema(), m.schema)
+
+	var schema storage.Schema
+	err = m.store.Get(ctx, CollectionConfig, storage.GetOptions{ID: "schema"}, &schema)
+	require.ErrorIs(t, err, storage.ErrNotFound{}, "the schema should not be saved in read-only mode")
+}
@@ -277,11 +277,12 @@ func (m *Migration) migrateClaim(ctx context.Context, inst *storage.Installation
 	}
 
 	// Sanitize sensitive values on the source claim
-	bun := cnab.ExtendedBundle{Bundle: run.Bundle}
+	bun := cnab.NewBundle(run.Bundle)
 	run.Parameters.Parameters, err = m.sanitizer.CleanParameters(ctx, run.Parameters.Parameters, bun, run.ID)
 	if err != nil {
 		return span.Error(err)
 	}
+	run.CompactParameters()
 
 	// Find all results associated with the run
 	resultIDs, err := m.listItems("results", run.ID)
@@ -320,7 +321,7 @@ func convertClaimToRun(inst storage.Installation, data []byte) (storage.Run, err
 	}
 
 	dest := storage.Run{
-		SchemaVersion:   storage.InstallationSchemaVersion,
+		SchemaVersion:   storage.RunSchemaVersion,
 		ID:              src.ID,
 		Created:         src.Created,
 		Namespace:       inst.Namespace,
@@ -384,7 +385,7 @@ func convertResult(run storage.Run, data []byte) (storage.Result, error) {
 	}
 
 	dest := storage.Result{
-		SchemaVersion:  run.SchemaVersion,
+		SchemaVersion:  storage.InstallationSchemaVersion,
 		ID:             src.ID,
 		Created:        src.Created,
 		Namespace:      run.Namespace,
@@ -414,7 +415,7 @@ func (m *Migration) migrateOutput(ctx context.Context, run storage.Run, result s
 	}
 
 	// Sanitize sensitive outputs
-	bun := cnab.ExtendedBundle{Bundle: run.Bundle}
+	bun := cnab.NewBundle(run.Bundle)
 	output, err = m.sanitizer.CleanOutput(ctx, output, bun)
 	if err != nil {
 		return span.Error(err)
@@ -442,6 +443,7 @@ func convertOutput(result storage.Result, outputKey string, data []byte) (storag
 		Installation:  result.Installation,
 		RunID:         result.RunID,
 		ResultID:      result.ID,
+		Created:       result.Created,
 		Value:         data,
 	}
 
@@ -39,7 +39,7 @@ func TestConvertClaimToRun(t *testing.T) {
 	require.NoError(t, err, "error converting claim")
 
 	assert.Equal(t, "01G1VJGY43HT3KZN82DS6DDPWK", run.ID, "incorrect run id")
-	assert.Equal(t, storage.InstallationSchemaVersion, run.SchemaVersion, "incorrect schema version, should be the current schema supported by porter")
+	assert.Equal(t, storage.RunSchemaVersion, run.SchemaVersion, "incorrect schema version, should be the current schema supported by porter")
 	assert.Equal(t, "hello1", run.Installation, "incorrect installation name")
 	assert.Equal(t, "01G1VJGY43HT3KZN82DSJY4NNB", run.Revision, "incorrect revision")
 	assert.Equal(t, "2022-04-29T16:09:42.65907-05:00", run.Created.Format(time.RFC3339Nano), "incorrect created timestamp")
@@ -175,7 +175,7 @@ func validateMigratedInstallations(ctx context.Context, t *testing.T, c *config.
 	assert.Len(t, runs, 5, "expected 5 runs") // dry-run, failed install, successful install, upgrade, uninstall
 
 	lastRun := runs[4]
-	assert.Equal(t, storage.InstallationSchemaVersion, lastRun.SchemaVersion, "incorrect run schema version")
+	assert.Equal(t, storage.RunSchemaVersion, lastRun.SchemaVersion, "incorrect run schema version")
 	assert.Equal(t, "01G1VJQJV0RN5AW5BSZHNTVYTV", lastRun.ID, "incorrect run id")
 	assert.Equal(t, "01G1VJQJV0RN5AW5BSZNJ1G6R7", lastRun.Revision, "incorrect run revision")
 	assert.Equal(t, inst.Namespace, lastRun.Namespace, "incorrect run namespace")
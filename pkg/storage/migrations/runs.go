@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"encoding/json"
+
+	"get.porter.sh/porter/pkg/storage"
+)
+
+// RunMigrations migrate the run documents saved by previous versions of Porter
+// v1 to storage.RunSchemaVersion. Runs are migrated when they are read, and
+// saved when porter storage migrate is run without --old-home. Add a migrator
+// here whenever the schema of the run document changes, and update
+// storage.RunSchemaVersion.
+var RunMigrations = storage.RunMigrations{
+	{
+		From:        "",
+		To:          "1.0.2",
+		Description: "Set the schema version of runs that were saved without one",
+		Migrate:     noChanges,
+	},
+	{
+		From:        "1.0.0-alpha.1",
+		To:          "1.0.1",
+		Description: "Runs saved by the Porter v1 prereleases use the same document as 1.0.1",
+		Migrate:     noChanges,
+	},
+	{
+		From:        "1.0.1",
+		To:          "1.0.2",
+		Description: "The installation document changed in 1.0.2, the run document is unchanged",
+		Migrate:     noChanges,
+	},
+	{
+		From:        "1.0.2",
+		To:          "1.1.0",
+		Description: "Remove the parameters that are set to the default value defined by the bundle",
+		Migrate:     compactRunParameters,
+	},
+	{
+		From:        "1.1.0",
+		To:          "1.2.0",
+		Description: "Save the time the run was created as a date, so that runs can be filtered by when they were created",
+		// The date is added when the migrated run is saved
+		Migrate: noChanges,
+	},
+}
+
+func noChanges(doc map[string]interface{}) error {
+	return nil
+}
+
+// compactRunParameters removes the parameters of a run document that are set
+// to the default value defined by the bundle, which are restored when the run
+// is read with Run.GetParameters.
+func compactRunParameters(doc map[string]interface{}) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	var run storage.Run
+	if err = json.Unmarshal(data, &run); err != nil {
+		return err
+	}
+	run.CompactParameters()
+
+	params, err := json.Marshal(run.Parameters)
+	if err != nil {
+		return err
+	}
+	var compacted interface{}
+	if err = json.Unmarshal(params, &compacted); err != nil {
+		return err
+	}
+	doc["parameters"] = compacted
+	return nil
+}
@@ -0,0 +1,72 @@
+package migrations
+
+import (
+	"encoding/json"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/cnabio/cnab-go/schema"
+	"github.com/cnabio/cnab-go/secrets/host"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunMigrations_Validate(t *testing.T) {
+	require.NoError(t, RunMigrations.Validate())
+}
+
+func TestRunMigrations(t *testing.T) {
+	// The resolved value of a parameter is not saved with the run
+	valueSource := func(name string, value string) secrets.Strategy {
+		return secrets.Strategy{Name: name, Source: secrets.Source{Key: host.SourceValue, Value: value}}
+	}
+
+	run := storage.NewRun("dev", "mybuns")
+	run.Action = cnab.ActionInstall
+	run.Bundle = bundle.Bundle{
+		Definitions: definition.Definitions{
+			"string": &definition.Schema{Type: "string", Default: "eastus"},
+		},
+		Parameters: map[string]bundle.Parameter{
+			"region": {Definition: "string"},
+			"zone":   {Definition: "string"},
+		},
+	}
+	run.Parameters.Parameters = []secrets.Strategy{valueSource("region", "eastus"), valueSource("zone", "westus")}
+
+	// Runs saved by each version of Porter v1 are migrated to the current schema
+	for _, version := range []string{"", "1.0.0-alpha.1", "1.0.1", "1.0.2"} {
+		t.Run(version, func(t *testing.T) {
+			data, err := json.Marshal(run)
+			require.NoError(t, err)
+			var doc map[string]interface{}
+			require.NoError(t, json.Unmarshal(data, &doc))
+			if version == "" {
+				delete(doc, "schemaVersion")
+			} else {
+				doc["schemaVersion"] = version
+			}
+
+			from, err := RunMigrations.Migrate(doc)
+			require.NoError(t, err)
+			assert.Equal(t, schema.Version(version), from)
+			assert.Equal(t, string(storage.RunSchemaVersion), doc["schemaVersion"])
+
+			data, err = json.Marshal(doc)
+			require.NoError(t, err)
+			var migrated storage.Run
+			require.NoError(t, json.Unmarshal(data, &migrated))
+			assert.Equal(t, []secrets.Strategy{valueSource("zone", "westus")}, migrated.Parameters.Parameters,
+				"the parameters set to their default should be removed")
+
+			restored := migrated.GetParameters().Parameters
+			require.Len(t, restored, 2, "the removed parameters should be restored when the run is read")
+			assert.Equal(t, "region", restored[1].Name)
+			assert.Equal(t, "eastus", restored[1].Source.Value)
+		})
+	}
+}
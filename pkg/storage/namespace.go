@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cnabio/cnab-go/schema"
+)
+
+var _ Document = Namespace{}
+
+// Namespace describes a namespace in which installations, credential sets and
+// parameter sets are defined. Namespaces do not need to be created before they
+// are used, a namespace without a document behaves the same as one with only
+// a name. Other subsystems read the namespace document to apply defaults and
+// protections to the resources in the namespace.
+type Namespace struct {
+	NamespaceSpec `yaml:",inline"`
+	Status        NamespaceStatus `json:"status" yaml:"status" toml:"status"`
+}
+
+// NamespaceSpec represents the set of user-modifiable fields on a Namespace.
+type NamespaceSpec struct {
+	// SchemaVersion is the version of the namespace schema.
+	SchemaVersion schema.Version `json:"schemaVersion" yaml:"schemaVersion" toml:"schemaVersion"`
+
+	// Name of the namespace. Immutable.
+	Name string `json:"name" yaml:"name" toml:"name"`
+
+	// Description of the namespace and how it is used.
+	Description string `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
+
+	// Owners of the namespace, such as a team name or email address.
+	Owners []string `json:"owners,omitempty" yaml:"owners,omitempty" toml:"owners,omitempty"`
+
+	// Labels applied to the namespace.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty" toml:"labels,omitempty"`
+
+	// DefaultCredentialSets are used by new installations in the namespace
+	// when credential sets are not specified.
+	DefaultCredentialSets []string `json:"defaultCredentialSets,omitempty" yaml:"defaultCredentialSets,omitempty" toml:"defaultCredentialSets,omitempty"`
+
+	// DefaultParameterSets are used by new installations in the namespace
+	// when parameter sets are not specified.
+	DefaultParameterSets []string `json:"defaultParameterSets,omitempty" yaml:"defaultParameterSets,omitempty" toml:"defaultParameterSets,omitempty"`
+
+	// Protection prevents accidental deletion of the namespace and its
+	// installations.
+	Protection NamespaceProtection `json:"protection,omitempty" yaml:"protection,omitempty" toml:"protection,omitempty"`
+}
+
+// NamespaceProtection flags prevent resources from being deleted unless the
+// deletion is forced.
+type NamespaceProtection struct {
+	// Deletion prevents the namespace from being deleted.
+	Deletion bool `json:"deletion,omitempty" yaml:"deletion,omitempty" toml:"deletion,omitempty"`
+
+	// Installations prevents the installations in the namespace from being
+	// deleted.
+	Installations bool `json:"installations,omitempty" yaml:"installations,omitempty" toml:"installations,omitempty"`
+}
+
+// NamespaceStatus contains additional status metadata that has been set by Porter.
+type NamespaceStatus struct {
+	// Created timestamp.
+	Created time.Time `json:"created" yaml:"created" toml:"created"`
+
+	// Modified timestamp.
+	Modified time.Time `json:"modified" yaml:"modified" toml:"modified"`
+}
+
+// NewNamespace creates a new Namespace with the required fields initialized.
+func NewNamespace(name string) Namespace {
+	now := time.Now()
+	return Namespace{
+		NamespaceSpec: NamespaceSpec{
+			SchemaVersion: NamespaceSchemaVersion,
+			Name:          name,
+		},
+		Status: NamespaceStatus{
+			Created:  now,
+			Modified: now,
+		},
+	}
+}
+
+func (n Namespace) DefaultDocumentFilter() map[string]interface{} {
+	return map[string]interface{}{"name": n.Name}
+}
+
+// Validate the namespace document.
+func (n Namespace) Validate() error {
+	if NamespaceSchemaVersion != n.SchemaVersion {
+		if n.SchemaVersion == "" {
+			n.SchemaVersion = "(none)"
+		}
+		return fmt.Errorf("invalid schemaVersion provided: %s. This version of Porter is compatible with %s.", n.SchemaVersion, NamespaceSchemaVersion)
+	}
+
+	if n.Name == "" {
+		return errors.New("the namespace name is required")
+	}
+	if n.Name == "*" {
+		return errors.New("* is not a valid namespace name, it is used to select all namespaces")
+	}
+	return nil
+}
+
+func (n Namespace) String() string {
+	return n.Name
+}
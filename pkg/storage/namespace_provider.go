@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"context"
+)
+
+// NamespaceProvider is Porter's interface for managing namespaces.
+type NamespaceProvider interface {
+	// InsertNamespace saves a new namespace document.
+	InsertNamespace(ctx context.Context, ns Namespace) error
+
+	// ListNamespaces returns the namespace documents that match the specified
+	// name and labels. The namespace field of the options is ignored.
+	ListNamespaces(ctx context.Context, listOptions ListOptions) ([]Namespace, error)
+
+	// GetNamespace returns the document for the named namespace.
+	GetNamespace(ctx context.Context, name string) (Namespace, error)
+
+	// UpsertNamespace saves the namespace document, creating it if it does
+	// not exist.
+	UpsertNamespace(ctx context.Context, ns Namespace) error
+
+	// RemoveNamespace deletes the document for the named namespace. The
+	// resources defined in the namespace are not removed.
+	RemoveNamespace(ctx context.Context, name string) error
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+
+	"get.porter.sh/porter/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var _ NamespaceProvider = &NamespaceStore{}
+
+const (
+	CollectionNamespaces = "namespaces"
+)
+
+// NamespaceStore is a wrapper around Porter's datastore
+// providing typed access to namespace documents.
+type NamespaceStore struct {
+	Documents Store
+}
+
+func NewNamespaceStore(storage Store) *NamespaceStore {
+	return &NamespaceStore{
+		Documents: storage,
+	}
+}
+
+// EnsureNamespaceIndices creates indices on the namespaces collection.
+func EnsureNamespaceIndices(ctx context.Context, store Store) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	span.Debug("Initializing namespaces collection indices")
+
+	indices := EnsureIndexOptions{
+		Indices: []Index{
+			// query namespaces by name
+			{Collection: CollectionNamespaces, Keys: []string{"name"}, Unique: true},
+		},
+	}
+	err := store.EnsureIndex(ctx, indices)
+	return span.Error(err)
+}
+
+func (s NamespaceStore) InsertNamespace(ctx context.Context, ns Namespace) error {
+	ns.SchemaVersion = NamespaceSchemaVersion
+	opts := InsertOptions{
+		Documents: []interface{}{ns},
+	}
+	return s.Documents.Insert(ctx, CollectionNamespaces, opts)
+}
+
+func (s NamespaceStore) ListNamespaces(ctx context.Context, listOptions ListOptions) ([]Namespace, error) {
+	// Namespace documents are not defined in a namespace
+	listOptions.Namespace = "*"
+
+	var out []Namespace
+	err := s.Documents.Find(ctx, CollectionNamespaces, listOptions.ToFindOptions(), &out)
+	return out, err
+}
+
+func (s NamespaceStore) GetNamespace(ctx context.Context, name string) (Namespace, error) {
+	var out Namespace
+	opts := FindOptions{
+		Filter: map[string]interface{}{
+			"name": name,
+		},
+	}
+	err := s.Documents.FindOne(ctx, CollectionNamespaces, opts, &out)
+	return out, err
+}
+
+func (s NamespaceStore) UpsertNamespace(ctx context.Context, ns Namespace) error {
+	ns.SchemaVersion = NamespaceSchemaVersion
+	opts := UpdateOptions{
+		Document: ns,
+		Upsert:   true,
+	}
+	return s.Documents.Update(ctx, CollectionNamespaces, opts)
+}
+
+func (s NamespaceStore) RemoveNamespace(ctx context.Context, name string) error {
+	opts := RemoveOptions{
+		Filter: bson.M{
+			"name": name,
+		},
+	}
+	return s.Documents.Remove(ctx, CollectionNamespaces, opts)
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespaceStorage_CRUD(t *testing.T) {
+	ctx := context.Background()
+	tc := config.NewTestConfig(t)
+	store := NewTestStore(tc)
+	defer store.Close()
+	require.NoError(t, EnsureNamespaceIndices(ctx, store))
+	ns := NewNamespaceStore(store)
+
+	dev := NewNamespace("dev")
+	dev.Description = "Development environments"
+	dev.Owners = []string{"platform-team"}
+	dev.Labels = map[string]string{"env": "dev"}
+	dev.DefaultCredentialSets = []string{"kubeconfig"}
+	require.NoError(t, ns.InsertNamespace(ctx, dev))
+
+	prod := NewNamespace("prod")
+	prod.Protection.Deletion = true
+	require.NoError(t, ns.InsertNamespace(ctx, prod))
+
+	err := ns.InsertNamespace(ctx, NewNamespace("dev"))
+	require.Error(t, err, "namespace names should be unique")
+
+	got, err := ns.GetNamespace(ctx, "dev")
+	require.NoError(t, err)
+	assert.Equal(t, "Development environments", got.Description)
+	assert.Equal(t, []string{"platform-team"}, got.Owners)
+	assert.Equal(t, []string{"kubeconfig"}, got.DefaultCredentialSets)
+
+	list, err := ns.ListNamespaces(ctx, ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list, 2, "the namespace of the list options should be ignored")
+	assert.Equal(t, "dev", list[0].Name)
+	assert.Equal(t, "prod", list[1].Name)
+
+	list, err = ns.ListNamespaces(ctx, ListOptions{Labels: map[string]string{"env": "dev"}})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "dev", list[0].Name)
+
+	prod.Protection.Deletion = false
+	require.NoError(t, ns.UpsertNamespace(ctx, prod))
+	got, err = ns.GetNamespace(ctx, "prod")
+	require.NoError(t, err)
+	assert.False(t, got.Protection.Deletion)
+
+	require.NoError(t, ns.RemoveNamespace(ctx, "dev"))
+	_, err = ns.GetNamespace(ctx, "dev")
+	require.ErrorIs(t, err, ErrNotFound{})
+}
+
+func TestNamespace_Validate(t *testing.T) {
+	require.NoError(t, NewNamespace("dev").Validate())
+
+	ns := NewNamespace("")
+	assert.ErrorContains(t, ns.Validate(), "name is required")
+
+	ns = NewNamespace("*")
+	assert.ErrorContains(t, ns.Validate(), "not a valid namespace name")
+
+	ns = NewNamespace("dev")
+	ns.SchemaVersion = ""
+	assert.ErrorContains(t, ns.Validate(), "invalid schemaVersion provided: (none)")
+}
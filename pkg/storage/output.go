@@ -1,11 +1,16 @@
 package storage
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"sort"
+	"time"
 
 	"get.porter.sh/porter/pkg/cnab"
 	"github.com/cnabio/cnab-go/bundle/definition"
 	"github.com/cnabio/cnab-go/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var _ Document = Output{}
@@ -18,15 +23,66 @@ type Output struct {
 	RunID         string         `json:"runId"`
 	ResultID      string         `json:"resultId"`
 
+	// Created timestamp of the output.
+	Created time.Time `json:"created"`
+
 	// Key holds the secret key to retrieve a sensitive output value
 	Key   string `json:"key"`
 	Value []byte `json:"value"`
+
+	// Chunks is the number of OutputChunk documents that hold the value of an
+	// output that was saved as a stream. When it is set, Value is empty.
+	Chunks int `json:"chunks,omitempty"`
+
+	// Size is the number of bytes in the value of an output that was saved as
+	// a stream, or to a blob store.
+	Size int64 `json:"size,omitempty"`
+
+	// BlobStore is the type of blob store that holds the value of an output
+	// that exceeded its size limit. When it is set, Value is empty.
+	BlobStore string `json:"blobStore,omitempty"`
+
+	// BlobKey identifies the blob that holds the value of the output in the blob store.
+	BlobKey string `json:"blobKey,omitempty"`
+
+	// ValueHash is the digest of the value, formatted as sha256:HEX, used to
+	// find the installations that expose an output value without reading it.
+	// It is not set for sensitive outputs.
+	ValueHash string `json:"valueHash,omitempty"`
+}
+
+// HashOutputValue returns the digest of an output value, in the same format
+// as Output.ValueHash.
+func HashOutputValue(value []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(value))
+}
+
+// TraceAttributes identify the output in a trace span. The value of the
+// output is never included.
+func (o Output) TraceAttributes() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		installationAttribute(o.Namespace, o.Installation),
+		attribute.String("runId", o.RunID),
+		attribute.String("resultId", o.ResultID),
+		attribute.String("output", o.Name),
+	}
 }
 
 func (o Output) DefaultDocumentFilter() map[string]interface{} {
 	return map[string]interface{}{"resultId": o.ResultID, "name": o.Name}
 }
 
+// nativeFields saves the time the output was created as a date in UTC, so
+// that the most recent output can be found regardless of how the result ids
+// were generated. Outputs saved before the time was recorded do not have the
+// date, and sort before the outputs that do.
+func (o Output) nativeFields() bson.M {
+	if o.Created.IsZero() {
+		return bson.M{}
+	}
+	return bson.M{"createdAt": o.Created.UTC()}
+}
+
 // GetSchema returns the schema for the output from the specified bundle, or
 // false if the schema is not defined.
 func (o Output) GetSchema(b cnab.ExtendedBundle) (definition.Schema, bool) {
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"get.porter.sh/porter/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// OutputHistoryEntry is a value of an output, along with when the result that
+// generated it was recorded.
+type OutputHistoryEntry struct {
+	Output
+
+	// Created is when the result that generated the value was recorded.
+	Created time.Time
+}
+
+// GetOutputHistory returns every value of an output of the installation,
+// sorted from oldest to newest. The values of sensitive outputs, and of outputs
+// that were saved as a stream, are not resolved.
+func (s InstallationStore) GetOutputHistory(ctx context.Context, namespace string, installation string, name string) ([]OutputHistoryEntry, error) {
+	ctx, span := tracing.StartSpan(ctx, installationAttribute(namespace, installation), attribute.String("output", name))
+	defer span.EndSpan()
+
+	if err := authorize(ctx, s.authz, VerbRead, CollectionOutputs, namespace, installation); err != nil {
+		return nil, span.Error(err)
+	}
+
+	var outputs []Output
+	opts := FindOptions{
+		Sort: []string{"resultId"},
+		Filter: bson.M{
+			"namespace":    namespace,
+			"installation": installation,
+			"name":         name,
+		},
+	}
+	if err := s.store.Find(ctx, CollectionOutputs, opts, &outputs); err != nil {
+		return nil, span.Error(err)
+	}
+	if len(outputs) == 0 {
+		return nil, ErrNotFound{Collection: CollectionOutputs, Item: name}
+	}
+
+	resultIDs := make([]string, 0, len(outputs))
+	for _, o := range outputs {
+		resultIDs = append(resultIDs, o.ResultID)
+	}
+
+	var results []Result
+	opts = FindOptions{
+		Filter: bson.M{
+			"_id":     bson.M{"$in": resultIDs},
+			"pending": committedFilter,
+		},
+	}
+	if err := s.store.Find(ctx, CollectionResults, opts, &results); err != nil {
+		return nil, span.Error(err)
+	}
+	created := make(map[string]time.Time, len(results))
+	for _, r := range results {
+		created[r.ID] = r.Created
+	}
+
+	// The outputs of pending results may still be changing, so only the
+	// outputs of committed results are included
+	history := make([]OutputHistoryEntry, 0, len(outputs))
+	for _, o := range outputs {
+		if resultCreated, ok := created[o.ResultID]; ok {
+			history = append(history, OutputHistoryEntry{Output: o, Created: resultCreated})
+		}
+	}
+	if len(history) == 0 {
+		return nil, ErrNotFound{Collection: CollectionOutputs, Item: name}
+	}
+
+	// Result IDs are not always generated in order, so sort by when the result was recorded
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Created.Before(history[j].Created)
+	})
+	return history, nil
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationStore_GetOutputHistory(t *testing.T) {
+	ctx := context.Background()
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	i := cp.CreateInstallation(NewInstallation("dev", "mysql"))
+	var results []Result
+	for _, value := range []string{"v1", "v2"} {
+		run := cp.CreateRun(i.NewRun(cnab.ActionUpgrade))
+		result := cp.CreateResult(run.NewResult(cnab.StatusSucceeded))
+		cp.CreateOutput(result.NewOutput("version", []byte(value)))
+		cp.CreateOutput(result.NewOutput("connstr", []byte("mysql://"+value)))
+		results = append(results, result)
+	}
+
+	history, err := cp.GetOutputHistory(ctx, "dev", "mysql", "version")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	for i, h := range history {
+		assert.Equal(t, "version", h.Name)
+		assert.Equal(t, results[i].ID, h.ResultID)
+		assert.Equal(t, results[i].RunID, h.RunID)
+		assert.Equal(t, results[i].Created.Unix(), h.Created.Unix())
+	}
+	assert.Equal(t, "v1", string(history[0].Value))
+	assert.Equal(t, "v2", string(history[1].Value))
+
+	_, err = cp.GetOutputHistory(ctx, "dev", "mysql", "missing")
+	require.ErrorIs(t, err, ErrNotFound{})
+
+	// The outputs of a result that is still being saved are not included
+	run := cp.CreateRun(i.NewRun(cnab.ActionUpgrade))
+	pending := cp.CreateResult(run.NewResult(cnab.StatusSucceeded), func(r *Result) { r.Pending = true })
+	cp.CreateOutput(pending.NewOutput("version", []byte("v3")))
+	cp.CreateOutput(pending.NewOutput("port", []byte("3306")))
+
+	history, err = cp.GetOutputHistory(ctx, "dev", "mysql", "version")
+	require.NoError(t, err)
+	assert.Len(t, history, 2, "the outputs of pending results should not be included")
+
+	_, err = cp.GetOutputHistory(ctx, "dev", "mysql", "port")
+	require.ErrorIs(t, err, ErrNotFound{}, "an output that only a pending result has should not be found")
+}
+
+func TestInstallationStore_GetOutputHistory_SortedByCreated(t *testing.T) {
+	ctx := context.Background()
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	// The ids of the results do not sort in the order they were created
+	i := cp.CreateInstallation(NewInstallation("dev", "mysql"))
+	start := time.Now()
+	for n, tc := range []struct{ id, value string }{{"b", "v1"}, {"a", "v2"}} {
+		run := cp.CreateRun(i.NewRun(cnab.ActionUpgrade))
+		result := run.NewResult(cnab.StatusSucceeded)
+		result.ID = tc.id
+		result.Created = start.Add(time.Duration(n) * time.Minute)
+		cp.CreateResult(result)
+		cp.CreateOutput(result.NewOutput("version", []byte(tc.value)))
+	}
+
+	history, err := cp.GetOutputHistory(ctx, "dev", "mysql", "version")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "v1", string(history[0].Value), "expected the values sorted by when the result was created")
+	assert.Equal(t, "v2", string(history[1].Value))
+}
+
+func TestInstallationStore_ListResults_Pending(t *testing.T) {
+	ctx := context.Background()
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	i := cp.CreateInstallation(NewInstallation("dev", "mysql"))
+	run := cp.CreateRun(i.NewRun(cnab.ActionInstall))
+	running := cp.CreateResult(run.NewResult(cnab.StatusRunning))
+	cp.CreateResult(run.NewResult(cnab.StatusSucceeded), func(r *Result) { r.Pending = true })
+
+	results, err := cp.ListResults(ctx, run.ID)
+	require.NoError(t, err)
+	require.Len(t, results, 1, "pending results should not be listed")
+	assert.Equal(t, running.ID, results[0].ID)
+
+	_, runResults, err := cp.ListRuns(ctx, "dev", "mysql")
+	require.NoError(t, err)
+	require.Len(t, runResults[run.ID], 1, "pending results should not be listed with the run")
+	assert.Equal(t, running.ID, runResults[run.ID][0].ID)
+
+	runs, _, err := cp.ListRunsWithOptions(ctx, ListRunsOptions{Namespace: "dev", Installation: "mysql", Status: cnab.StatusSucceeded})
+	require.NoError(t, err)
+	assert.Empty(t, runs, "the status of a run should not come from a pending result")
+}
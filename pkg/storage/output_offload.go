@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/storage/blobs"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// OutputOffload is the storage hook that saves the values of outputs that
+// exceed their size limit to a blob store, instead of the database.
+type OutputOffload interface {
+	// GetMaxOutputSize returns the maximum number of bytes of the output's
+	// value that are saved in the database, and false when it is not limited.
+	GetMaxOutputSize(output Output) (int64, bool, error)
+
+	// GetBlobStore returns the blob store that holds offloaded output values.
+	GetBlobStore() (blobs.Store, error)
+}
+
+var _ OutputOffload = &ConfigOutputOffload{}
+
+// ConfigOutputOffload offloads output values using the output-storage
+// settings in Porter's configuration.
+type ConfigOutputOffload struct {
+	config *config.Config
+
+	mu    sync.Mutex
+	store blobs.Store
+}
+
+// NewConfigOutputOffload creates a hook that limits the size of outputs with
+// the output-storage configuration. The configuration is read when an output
+// is saved, so that it reflects the loaded configuration.
+func NewConfigOutputOffload(c *config.Config) *ConfigOutputOffload {
+	return &ConfigOutputOffload{config: c}
+}
+
+func (o *ConfigOutputOffload) GetMaxOutputSize(output Output) (int64, bool, error) {
+	if o.config == nil {
+		return 0, false, nil
+	}
+	return o.config.Data.OutputStorage.GetMaxSize(output.Name)
+}
+
+// GetBlobStore creates the configured blob store the first time it is used.
+func (o *ConfigOutputOffload) GetBlobStore() (blobs.Store, error) {
+	if o.config == nil {
+		return nil, errors.New("output-storage is not configured")
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.store == nil {
+		store, err := blobs.New(o.config)
+		if err != nil {
+			return nil, err
+		}
+		o.store = store
+	}
+	return o.store, nil
+}
+
+// IsOffloaded determines if the output's value is saved in a blob store, and
+// must be read with InstallationProvider.OpenOutputStream.
+func (o Output) IsOffloaded() bool {
+	return o.BlobKey != ""
+}
+
+// newBlobKey returns the key of the blob that holds the output's value.
+func (o Output) newBlobKey() string {
+	namespace := o.Namespace
+	if namespace == "" {
+		namespace = "global"
+	}
+	return path.Join(namespace, o.Installation, o.ResultID, o.Name)
+}
+
+// offloadOutput saves the value of an output that exceeds its size limit to
+// the blob store, returning the output with a reference to the blob instead
+// of the value. Sensitive outputs are not offloaded because their value is
+// saved in the secret store.
+func (s InstallationStore) offloadOutput(ctx context.Context, output Output) (Output, error) {
+	if output.Key != "" || output.IsChunked() || output.IsOffloaded() {
+		return output, nil
+	}
+
+	maxSize, limited, err := s.offload.GetMaxOutputSize(output)
+	if err != nil {
+		return output, err
+	}
+	if !limited || int64(len(output.Value)) <= maxSize {
+		return output, nil
+	}
+
+	return s.putOutputBlob(ctx, output, bytes.NewReader(output.Value), int64(len(output.Value)))
+}
+
+// putOutputBlob saves the value of the output to the blob store. The size is
+// the number of bytes in the value, or -1 when it is not known.
+func (s InstallationStore) putOutputBlob(ctx context.Context, output Output, value io.Reader, size int64) (Output, error) {
+	store, err := s.offload.GetBlobStore()
+	if err != nil {
+		return output, fmt.Errorf("error saving the value of output %s to a blob store: %w", output.Name, err)
+	}
+
+	counter := &countingReader{r: value}
+	key := output.newBlobKey()
+	if err = store.Put(ctx, key, counter, size); err != nil {
+		return output, err
+	}
+
+	output.Value = nil
+	output.BlobStore = store.Type()
+	output.BlobKey = key
+	output.Size = counter.n
+	return output, nil
+}
+
+// openOutputBlob returns a reader for the value of an offloaded output.
+func (s InstallationStore) openOutputBlob(ctx context.Context, output Output) (io.ReadCloser, error) {
+	store, err := s.getOutputBlobStore(output)
+	if err != nil {
+		return nil, err
+	}
+	return store.Open(ctx, output.BlobKey)
+}
+
+// removeOutputBlob deletes the blob that holds the value of an offloaded output.
+func (s InstallationStore) removeOutputBlob(ctx context.Context, output Output) error {
+	store, err := s.getOutputBlobStore(output)
+	if err != nil {
+		return err
+	}
+	return store.Delete(ctx, output.BlobKey)
+}
+
+// getOutputBlobStore returns the blob store that holds the value of an
+// offloaded output, and an error when the configured blob store is not the
+// one to which the value was saved.
+func (s InstallationStore) getOutputBlobStore(output Output) (blobs.Store, error) {
+	store, err := s.offload.GetBlobStore()
+	if err != nil {
+		return nil, fmt.Errorf("error accessing the value of output %s in the %s blob store: %w", output.Name, output.BlobStore, err)
+	}
+	if store.Type() != output.BlobStore {
+		return nil, fmt.Errorf("the value of output %s was saved to the %s blob store, but output-storage.blob-store is configured to use %s",
+			output.Name, output.BlobStore, store.Type())
+	}
+	return store, nil
+}
+
+// removeOutputBlobs deletes the blobs of the offloaded outputs that match the
+// filter, before the output documents are removed.
+func (s InstallationStore) removeOutputBlobs(ctx context.Context, filter bson.M) error {
+	offloadedFilter := bson.M{"blobKey": bson.M{"$exists": true}}
+	for k, v := range filter {
+		offloadedFilter[k] = v
+	}
+
+	var outputs []Output
+	if err := s.store.Find(ctx, CollectionOutputs, FindOptions{Filter: offloadedFilter}, &outputs); err != nil {
+		return err
+	}
+	for _, output := range outputs {
+		if err := s.removeOutputBlob(ctx, output); err != nil {
+			return fmt.Errorf("error removing the value of output %s of installation %s/%s: %w", output.Name, output.Namespace, output.Installation, err)
+		}
+	}
+	return nil
+}
+
+// countingReader counts the number of bytes read.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
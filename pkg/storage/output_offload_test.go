@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOffloadTestProvider creates an installation provider that saves outputs
+// larger than 10 bytes to a filesystem blob store in the test filesystem.
+func newOffloadTestProvider(t *testing.T) (*TestInstallationProvider, *config.TestConfig) {
+	tc := config.NewTestConfig(t)
+	tc.SetHomeDir("/home/myuser/.porter")
+	tc.Data.OutputStorage = config.OutputStorageConfig{
+		MaxSize: "10B",
+		Limits:  []config.OutputSizeLimit{{Output: "small", MaxSize: "100B"}},
+	}
+
+	cp := NewTestInstallationProviderFor(t, NewTestStore(tc))
+	cp.SetOutputOffload(NewConfigOutputOffload(tc.Config))
+	return cp, tc
+}
+
+func TestInstallationStore_OffloadOutput(t *testing.T) {
+	ctx := context.Background()
+	cp, tc := newOffloadTestProvider(t)
+	defer cp.Close()
+
+	i := cp.CreateInstallation(NewInstallation("dev", "mybuns"))
+	run := cp.CreateRun(i.NewRun(cnab.ActionInstall))
+	result := cp.CreateResult(run.NewResult(cnab.StatusSucceeded))
+
+	t.Run("exceeds the limit", func(t *testing.T) {
+		cp.CreateOutput(result.NewOutput("kubeconfig", []byte("a large kubeconfig")))
+
+		output, err := cp.GetLastOutput(ctx, "dev", "mybuns", "kubeconfig")
+		require.NoError(t, err)
+		assert.True(t, output.IsOffloaded(), "expected the value to be saved to the blob store")
+		assert.Empty(t, output.Value, "the value should not be stored on the output document")
+		assert.Equal(t, config.BlobStoreFilesystem, output.BlobStore)
+		assert.Equal(t, "dev/mybuns/"+result.ID+"/kubeconfig", output.BlobKey)
+		assert.Equal(t, int64(18), output.Size)
+
+		blobPath := "/home/myuser/.porter/outputs/dev/mybuns/" + result.ID + "/kubeconfig"
+		exists, _ := tc.FileSystem.Exists(blobPath)
+		assert.True(t, exists, "expected the blob to be saved in PORTER_HOME/outputs")
+
+		output, err = ReadOutputValue(ctx, cp, output)
+		require.NoError(t, err)
+		assert.Equal(t, "a large kubeconfig", string(output.Value))
+		assert.False(t, output.IsOffloaded())
+	})
+
+	t.Run("within the limit", func(t *testing.T) {
+		cp.CreateOutput(result.NewOutput("port", []byte("8080")))
+
+		output, err := cp.GetLastOutput(ctx, "dev", "mybuns", "port")
+		require.NoError(t, err)
+		assert.False(t, output.IsOffloaded())
+		assert.Equal(t, "8080", string(output.Value))
+	})
+
+	t.Run("limit for the output", func(t *testing.T) {
+		cp.CreateOutput(result.NewOutput("small", []byte("larger than the default limit")))
+
+		output, err := cp.GetLastOutput(ctx, "dev", "mybuns", "small")
+		require.NoError(t, err)
+		assert.False(t, output.IsOffloaded(), "the limit for the output should take precedence over max-size")
+	})
+
+	t.Run("sensitive", func(t *testing.T) {
+		output := result.NewOutput("password", nil)
+		output.Key = "secret-key"
+		cp.CreateOutput(output)
+
+		output, err := cp.GetLastOutput(ctx, "dev", "mybuns", "password")
+		require.NoError(t, err)
+		assert.False(t, output.IsOffloaded(), "sensitive outputs are saved to the secret store")
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		value := strings.Repeat("0123456789", 100)
+		err := cp.InsertOutputStream(ctx, result.NewOutput("logs", nil), strings.NewReader(value))
+		require.NoError(t, err)
+
+		output, err := cp.GetLastOutput(ctx, "dev", "mybuns", "logs")
+		require.NoError(t, err)
+		assert.True(t, output.IsOffloaded(), "expected the value to be saved to the blob store")
+		assert.False(t, output.IsChunked(), "an offloaded value should not be saved in chunks")
+		assert.Equal(t, int64(len(value)), output.Size)
+
+		r, err := cp.OpenOutputStream(ctx, output)
+		require.NoError(t, err)
+		defer r.Close()
+		got, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, value, string(got))
+	})
+
+	t.Run("stream within the limit", func(t *testing.T) {
+		err := cp.InsertOutputStream(ctx, result.NewOutput("token", nil), bytes.NewReader([]byte("abc")))
+		require.NoError(t, err)
+
+		output, err := cp.GetLastOutput(ctx, "dev", "mybuns", "token")
+		require.NoError(t, err)
+		assert.False(t, output.IsOffloaded())
+
+		output, err = ReadOutputValue(ctx, cp, output)
+		require.NoError(t, err)
+		assert.Equal(t, "abc", string(output.Value))
+	})
+
+	t.Run("blob store changed", func(t *testing.T) {
+		output, err := cp.GetLastOutput(ctx, "dev", "mybuns", "kubeconfig")
+		require.NoError(t, err)
+		output.BlobStore = config.BlobStoreS3
+
+		_, err = cp.OpenOutputStream(ctx, output)
+		require.EqualError(t, err, "the value of output kubeconfig was saved to the s3 blob store, but output-storage.blob-store is configured to use filesystem")
+	})
+
+	t.Run("remove installation", func(t *testing.T) {
+		require.NoError(t, cp.RemoveInstallation(ctx, "dev", "mybuns"))
+
+		exists, _ := tc.FileSystem.Exists("/home/myuser/.porter/outputs/dev/mybuns/" + result.ID + "/kubeconfig")
+		assert.False(t, exists, "the blobs of the installation's outputs should be removed")
+	})
+}
+
+func TestInstallationStore_PruneRuns_OffloadedOutputs(t *testing.T) {
+	ctx := context.Background()
+	cp, tc := newOffloadTestProvider(t)
+	defer cp.Close()
+
+	i := cp.CreateInstallation(NewInstallation("dev", "mybuns"))
+	old := cp.CreateRun(i.NewRun(cnab.ActionInstall), func(r *Run) { r.Created = time.Now().Add(-time.Hour) })
+	oldResult := cp.CreateResult(old.NewResult(cnab.StatusSucceeded))
+	cp.CreateOutput(oldResult.NewOutput("kubeconfig", []byte("the old kubeconfig")))
+
+	latest := cp.CreateRun(i.NewRun(cnab.ActionUpgrade))
+	latestResult := cp.CreateResult(latest.NewResult(cnab.StatusSucceeded))
+	cp.CreateOutput(latestResult.NewOutput("kubeconfig", []byte("the new kubeconfig")))
+
+	pruned, err := cp.PruneRuns(ctx, PruneRunsOptions{Namespace: "dev", Installation: "mybuns", Policy: RetentionPolicy{KeepLast: 1}})
+	require.NoError(t, err)
+	require.Len(t, pruned, 1)
+	assert.Equal(t, old.ID, pruned[0].ID)
+
+	exists, _ := tc.FileSystem.Exists("/home/myuser/.porter/outputs/dev/mybuns/" + oldResult.ID + "/kubeconfig")
+	assert.False(t, exists, "the blob of the pruned run's output should be removed")
+	exists, _ = tc.FileSystem.Exists("/home/myuser/.porter/outputs/dev/mybuns/" + latestResult.ID + "/kubeconfig")
+	assert.True(t, exists, "the blob of the kept run's output should not be removed")
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"get.porter.sh/porter/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// FindOutputsOptions are the filters used to find the installations that
+// expose an output.
+type FindOutputsOptions struct {
+	// Name of the output.
+	Name string
+
+	// Namespace limits the results to installations in the namespace. Use * to
+	// include every namespace.
+	Namespace string
+
+	// ValueHash limits the results to installations whose most recent value of
+	// the output has the digest, formatted as sha256:HEX.
+	ValueHash string
+}
+
+// ToAggregateOptions converts the options into an aggregation against the
+// outputs collection, that selects the most recent value of the output for each
+// installation before it is compared to the value hash.
+func (o FindOutputsOptions) ToAggregateOptions() AggregateOptions {
+	filter := bson.M{"name": o.Name}
+	if o.Namespace != "*" {
+		filter["namespace"] = o.Namespace
+	}
+
+	pipeline := []bson.D{
+		// List the outputs with the name
+		{{Key: "$match", Value: filter}},
+		// Reverse sort them (newest on top)
+		{{Key: "$sort", Value: bson.D{
+			{Key: "name", Value: 1},
+			{Key: "namespace", Value: 1},
+			{Key: "installation", Value: 1},
+			{Key: "resultId", Value: -1},
+		}}},
+		// Group them by installation and select the last value of the output
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "namespace", Value: "$namespace"},
+				{Key: "installation", Value: "$installation"},
+			}},
+			{Key: "lastOutput", Value: bson.M{"$first": "$$ROOT"}},
+		}}},
+	}
+	if o.ValueHash != "" {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"lastOutput.valueHash": o.ValueHash}}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$sort", Value: bson.D{
+		{Key: "lastOutput.namespace", Value: 1},
+		{Key: "lastOutput.installation", Value: 1},
+	}}})
+
+	return AggregateOptions{Pipeline: pipeline}
+}
+
+func (s InstallationStore) FindOutputs(ctx context.Context, opts FindOutputsOptions) ([]Output, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if opts.Name == "" {
+		return nil, span.Error(errors.New("the name of the output is required"))
+	}
+
+	var groupedOutputs []struct {
+		LastOutput Output `json:"lastOutput"`
+	}
+	if err := s.store.Aggregate(ctx, CollectionOutputs, opts.ToAggregateOptions(), &groupedOutputs); err != nil {
+		return nil, span.Error(err)
+	}
+
+	if opts.Namespace != "*" {
+		if err := authorize(ctx, s.authz, VerbRead, CollectionOutputs, opts.Namespace, ""); err != nil {
+			return nil, span.Error(err)
+		}
+	}
+
+	outputs := make([]Output, len(groupedOutputs))
+	for i, groupedOutput := range groupedOutputs {
+		outputs[i] = groupedOutput.LastOutput
+	}
+	outputs, err := filterAuthorized(ctx, s.authz, CollectionOutputs, outputs, func(o Output) string { return o.Namespace })
+	return outputs, span.Error(err)
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationStore_FindOutputs(t *testing.T) {
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	ctx := context.Background()
+	createOutput := func(namespace string, installation string, value string) {
+		inst := cp.CreateInstallation(NewInstallation(namespace, installation))
+		run := cp.CreateRun(inst.NewRun(cnab.ActionInstall))
+		result := cp.CreateResult(run.NewResult(cnab.StatusSucceeded))
+		cp.CreateOutput(result.NewOutput("cluster", []byte(value)))
+	}
+	createOutput("dev", "foo", "prod-east")
+	createOutput("dev", "foo", "prod-west")
+	createOutput("dev", "bar", "prod-east")
+	createOutput("prod", "baz", "prod-east")
+
+	listInstallations := func(outputs []Output) []string {
+		names := make([]string, len(outputs))
+		for i, output := range outputs {
+			names[i] = output.Namespace + "/" + output.Installation
+		}
+		return names
+	}
+
+	t.Run("by name", func(t *testing.T) {
+		outputs, err := cp.FindOutputs(ctx, FindOutputsOptions{Name: "cluster", Namespace: "dev"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"dev/bar", "dev/foo"}, listInstallations(outputs))
+		assert.Equal(t, "prod-west", string(outputs[1].Value), "expected the most recent value of the output")
+	})
+
+	t.Run("by value hash", func(t *testing.T) {
+		outputs, err := cp.FindOutputs(ctx, FindOutputsOptions{Name: "cluster", Namespace: "*", ValueHash: HashOutputValue([]byte("prod-east"))})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"dev/bar", "prod/baz"}, listInstallations(outputs),
+			"expected only the installations whose most recent value matches")
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		outputs, err := cp.FindOutputs(ctx, FindOutputsOptions{Name: "kubeconfig", Namespace: "*"})
+		require.NoError(t, err)
+		assert.Empty(t, outputs)
+	})
+
+	t.Run("name required", func(t *testing.T) {
+		_, err := cp.FindOutputs(ctx, FindOutputsOptions{Namespace: "*"})
+		require.EqualError(t, err, "the name of the output is required")
+	})
+}
+
+func TestInstallationStore_OutputValueHash(t *testing.T) {
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	ctx := context.Background()
+	inst := cp.CreateInstallation(NewInstallation("dev", "foo"))
+	run := cp.CreateRun(inst.NewRun(cnab.ActionInstall))
+	result := cp.CreateResult(run.NewResult(cnab.StatusSucceeded))
+
+	cp.CreateOutput(result.NewOutput("plain", []byte("prod-east")))
+	require.NoError(t, cp.InsertOutputStream(ctx, result.NewOutput("streamed", nil), strings.NewReader("prod-east")))
+	cp.CreateOutput(result.NewOutput("sensitive", nil), func(o *Output) { o.Key = run.ID + "-sensitive" })
+
+	want := "sha256:5fb8fce78382d28d746e0af6610c85c3bbf7ef8934a36c5bc2124fee3cab8f58"
+	for _, name := range []string{"plain", "streamed"} {
+		output, err := cp.GetLastOutput(ctx, "dev", "foo", name)
+		require.NoError(t, err)
+		assert.Equal(t, want, output.ValueHash, "expected the %s output to be hashed", name)
+	}
+
+	output, err := cp.GetLastOutput(ctx, "dev", "foo", "sensitive")
+	require.NoError(t, err)
+	assert.Empty(t, output.ValueHash, "sensitive outputs must not be hashed")
+}
@@ -0,0 +1,259 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"get.porter.sh/porter/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CollectionOutputChunks stores the values of outputs that were saved as a stream.
+const CollectionOutputChunks = "outputchunks"
+
+// OutputChunkSize is the maximum number of bytes of an output value that are
+// stored in a single document. Documents are limited in size by the backing
+// store, 16MB for mongodb, so large values are split across multiple
+// documents.
+const OutputChunkSize = 1024 * 1024
+
+var _ Document = OutputChunk{}
+
+// OutputChunk is a part of the value of an Output that was saved as a stream.
+type OutputChunk struct {
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	Installation string `json:"installation"`
+	RunID        string `json:"runId"`
+	ResultID     string `json:"resultId"`
+
+	// Index of the chunk in the output value, starting at 0.
+	Index int `json:"index"`
+
+	// Data is the part of the output value stored in this chunk.
+	Data []byte `json:"data"`
+}
+
+func (c OutputChunk) DefaultDocumentFilter() map[string]interface{} {
+	return map[string]interface{}{"resultId": c.ResultID, "name": c.Name, "index": c.Index}
+}
+
+// NewChunk creates a chunk holding part of the output's value.
+func (o Output) NewChunk(index int, data []byte) OutputChunk {
+	return OutputChunk{
+		Name:         o.Name,
+		Namespace:    o.Namespace,
+		Installation: o.Installation,
+		RunID:        o.RunID,
+		ResultID:     o.ResultID,
+		Index:        index,
+		Data:         data,
+	}
+}
+
+// IsChunked determines if the output's value is stored in chunks, and must be
+// read with InstallationProvider.OpenOutputStream.
+func (o Output) IsChunked() bool {
+	return o.Chunks > 0
+}
+
+// InsertOutputStream saves a new Output document, reading its value from the
+// reader and storing it in chunks so that the value is never held in memory
+// in its entirety. A value that exceeds the output's size limit is saved to
+// the blob store instead.
+func (s InstallationStore) InsertOutputStream(ctx context.Context, output Output, value io.Reader) error {
+	ctx, span := tracing.StartSpan(ctx, output.TraceAttributes()...)
+	defer span.EndSpan()
+
+	// Check that the output can be saved before any chunks are saved
+	if err := checkWritable(s.store, CollectionOutputs, "insert"); err != nil {
+		return span.Error(err)
+	}
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionOutputs, output.Namespace, output.Installation); err != nil {
+		return span.Error(err)
+	}
+
+	output.Value = nil
+	output.Chunks = 0
+	output.Size = 0
+
+	// Hash the value as it is read. Sensitive outputs are saved with an empty
+	// value and are not hashed.
+	hasher := sha256.New()
+	if output.Key == "" {
+		value = io.TeeReader(value, hasher)
+	}
+
+	if output.Key == "" {
+		maxSize, limited, err := s.offload.GetMaxOutputSize(output)
+		if err != nil {
+			return err
+		}
+		if limited {
+			// Read just past the limit to determine if the value exceeds it
+			var head bytes.Buffer
+			n, err := io.CopyN(&head, value, maxSize+1)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return fmt.Errorf("error reading the value of output %s: %w", output.Name, err)
+			}
+			value = io.MultiReader(&head, value)
+
+			if n > maxSize {
+				if output, err = s.putOutputBlob(ctx, output, value, -1); err != nil {
+					return err
+				}
+				output.ValueHash = formatValueHash(hasher)
+				return s.InsertOutput(ctx, output)
+			}
+		}
+	}
+
+	buf := make([]byte, OutputChunkSize)
+	for {
+		n, err := io.ReadFull(value, buf)
+		if n > 0 {
+			chunk := output.NewChunk(output.Chunks, append([]byte(nil), buf[:n]...))
+			if insertErr := s.store.Insert(ctx, CollectionOutputChunks, InsertOptions{Documents: []interface{}{chunk}}); insertErr != nil {
+				return s.abortOutputStream(ctx, output, fmt.Errorf("error saving chunk %d of output %s: %w", chunk.Index, output.Name, insertErr))
+			}
+			output.Chunks++
+			output.Size += int64(n)
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return s.abortOutputStream(ctx, output, fmt.Errorf("error reading the value of output %s: %w", output.Name, err))
+		}
+	}
+
+	// An empty value is stored on the output document directly
+	if output.Chunks == 0 {
+		output.Value = []byte{}
+	}
+	if output.Key == "" {
+		output.ValueHash = formatValueHash(hasher)
+	}
+
+	if err := s.InsertOutput(ctx, output); err != nil {
+		return s.abortOutputStream(ctx, output, err)
+	}
+	return nil
+}
+
+// abortOutputStream removes the chunks saved for an output that could not be
+// saved, and returns the original error.
+func (s InstallationStore) abortOutputStream(ctx context.Context, output Output, err error) error {
+	removeChunks := RemoveOptions{
+		Filter: bson.M{"resultId": output.ResultID, "name": output.Name},
+		All:    true,
+	}
+	if removeErr := s.store.Remove(ctx, CollectionOutputChunks, removeChunks); removeErr != nil {
+		return fmt.Errorf("%w\nerror removing the saved chunks of output %s: %s", err, output.Name, removeErr)
+	}
+	return err
+}
+
+// OpenOutputStream returns a reader for the value of an output. The chunks of
+// an output value are retrieved from the backing store one at a time as the
+// value is read.
+func (s InstallationStore) OpenOutputStream(ctx context.Context, output Output) (io.ReadCloser, error) {
+	if err := authorize(ctx, s.authz, VerbRead, CollectionOutputs, output.Namespace, output.Installation); err != nil {
+		return nil, err
+	}
+
+	if output.IsOffloaded() {
+		return s.openOutputBlob(ctx, output)
+	}
+	if !output.IsChunked() {
+		return io.NopCloser(bytes.NewReader(output.Value)), nil
+	}
+
+	return &outputChunkReader{ctx: ctx, store: s.store, output: output}, nil
+}
+
+// outputChunkReader reads the value of an output from its chunks.
+type outputChunkReader struct {
+	ctx    context.Context
+	store  Store
+	output Output
+
+	// next is the index of the next chunk to retrieve.
+	next int
+
+	// buf is the unread data of the current chunk.
+	buf []byte
+}
+
+func (r *outputChunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.next >= r.output.Chunks {
+			return 0, io.EOF
+		}
+
+		var chunk OutputChunk
+		opts := FindOptions{
+			Filter: bson.M{
+				"resultId": r.output.ResultID,
+				"name":     r.output.Name,
+				"index":    r.next,
+			},
+		}
+		if err := r.store.FindOne(r.ctx, CollectionOutputChunks, opts, &chunk); err != nil {
+			return 0, fmt.Errorf("error retrieving chunk %d of %d of output %s: %w", r.next+1, r.output.Chunks, r.output.Name, err)
+		}
+		r.buf = chunk.Data
+		r.next++
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *outputChunkReader) Close() error {
+	r.buf = nil
+	r.next = r.output.Chunks
+	return nil
+}
+
+// ReadOutputValue reads the entire value of an output into the output's
+// Value field, retrieving it from its chunks when the value was saved as a
+// stream, or from the blob store when it was offloaded. Prefer
+// InstallationProvider.OpenOutputStream for large values.
+func ReadOutputValue(ctx context.Context, installations InstallationProvider, output Output) (Output, error) {
+	if !output.IsChunked() && !output.IsOffloaded() {
+		return output, nil
+	}
+
+	r, err := installations.OpenOutputStream(ctx, output)
+	if err != nil {
+		return output, err
+	}
+	defer r.Close()
+
+	var buf bytes.Buffer
+	buf.Grow(int(output.Size))
+	if _, err = io.Copy(&buf, r); err != nil {
+		return output, err
+	}
+
+	output.Value = buf.Bytes()
+	output.Chunks = 0
+	output.Size = 0
+	output.BlobStore = ""
+	output.BlobKey = ""
+	return output, nil
+}
+
+// formatValueHash formats the digest of a value read through the hash, in the
+// same format as Output.ValueHash.
+func formatValueHash(h hash.Hash) string {
+	return fmt.Sprintf("sha256:%x", h.Sum(nil))
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationStore_OutputStream(t *testing.T) {
+	ctx := context.Background()
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	i := cp.CreateInstallation(NewInstallation("dev", "mybuns"))
+	run := cp.CreateRun(i.NewRun(cnab.ActionInstall))
+	result := cp.CreateResult(run.NewResult(cnab.StatusSucceeded))
+
+	countChunks := func(t *testing.T) int64 {
+		count, err := cp.Count(ctx, CollectionOutputChunks, CountOptions{})
+		require.NoError(t, err)
+		return count
+	}
+
+	t.Run("large value", func(t *testing.T) {
+		value := bytes.Repeat([]byte("0123456789"), OutputChunkSize/4) // 2.5 chunks
+		err := cp.InsertOutputStream(ctx, result.NewOutput("kubeconfig", nil), bytes.NewReader(value))
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), countChunks(t))
+
+		output, err := cp.GetLastOutput(ctx, "dev", "mybuns", "kubeconfig")
+		require.NoError(t, err)
+		assert.True(t, output.IsChunked(), "expected the output to be saved in chunks")
+		assert.Equal(t, 3, output.Chunks)
+		assert.Equal(t, int64(len(value)), output.Size)
+		assert.Empty(t, output.Value, "the value should not be stored on the output document")
+
+		r, err := cp.OpenOutputStream(ctx, output)
+		require.NoError(t, err)
+		defer r.Close()
+		require.NoError(t, iotest.TestReader(r, value))
+
+		output, err = ReadOutputValue(ctx, cp, output)
+		require.NoError(t, err)
+		assert.Equal(t, value, output.Value)
+		assert.False(t, output.IsChunked())
+	})
+
+	t.Run("empty value", func(t *testing.T) {
+		err := cp.InsertOutputStream(ctx, result.NewOutput("empty", nil), bytes.NewReader(nil))
+		require.NoError(t, err)
+
+		output, err := cp.GetLastOutput(ctx, "dev", "mybuns", "empty")
+		require.NoError(t, err)
+		assert.False(t, output.IsChunked())
+
+		r, err := cp.OpenOutputStream(ctx, output)
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Empty(t, data)
+	})
+
+	t.Run("failed read", func(t *testing.T) {
+		before := countChunks(t)
+		value := io.MultiReader(bytes.NewReader(make([]byte, OutputChunkSize+1)), iotest.ErrReader(errors.New("connection reset")))
+		err := cp.InsertOutputStream(ctx, result.NewOutput("broken", nil), value)
+		require.ErrorContains(t, err, "connection reset")
+		assert.Equal(t, before, countChunks(t), "the saved chunks should be removed when the output cannot be saved")
+
+		_, err = cp.GetLastOutput(ctx, "dev", "mybuns", "broken")
+		require.ErrorIs(t, err, ErrNotFound{})
+	})
+
+	t.Run("remove installation", func(t *testing.T) {
+		require.NoError(t, cp.RemoveInstallation(ctx, "dev", "mybuns"))
+		assert.Zero(t, countChunks(t), "the output chunks should be removed with the installation")
+	})
+}
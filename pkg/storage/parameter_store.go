@@ -9,6 +9,8 @@ import (
 	"get.porter.sh/porter/pkg/tracing"
 	"github.com/cnabio/cnab-go/secrets/host"
 	"github.com/hashicorp/go-multierror"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var _ ParameterSetProvider = &ParameterStore{}
@@ -22,6 +24,12 @@ const (
 type ParameterStore struct {
 	Documents Store
 	Secrets   secrets.Store
+
+	// authz authorizes reading and changing the parameter sets in a namespace.
+	authz Authorizer
+
+	// audit is notified every time a sensitive parameter is resolved.
+	audit secrets.AuditHook
 }
 
 func NewParameterStore(storage Store, secrets secrets.Store) *ParameterStore {
@@ -31,6 +39,18 @@ func NewParameterStore(storage Store, secrets secrets.Store) *ParameterStore {
 	}
 }
 
+// SetAuthorizer sets the hook that authorizes reading and changing the
+// parameter sets in a namespace.
+func (s *ParameterStore) SetAuthorizer(authz Authorizer) {
+	s.authz = authz
+}
+
+// SetAuditHook sets the hook that is notified every time a parameter is
+// resolved from the secret store or decrypted with a key management service.
+func (s *ParameterStore) SetAuditHook(hook secrets.AuditHook) {
+	s.audit = hook
+}
+
 // EnsureParameterIndices creates indices on the parameters collection.
 func EnsureParameterIndices(ctx context.Context, store Store) error {
 	ctx, span := tracing.StartSpan(ctx)
@@ -51,12 +71,25 @@ func (s ParameterStore) GetDataStore() Store {
 	return s.Documents
 }
 
+// ResolveAll resolves the values of the parameters in the parameter set,
+// including the parameters that it inherits from other parameter sets.
 func (s ParameterStore) ResolveAll(ctx context.Context, params ParameterSet) (secrets.Set, error) {
+	ctx, span := tracing.StartSpan(ctx, attribute.String("parameterSet", params.String()))
+	defer span.EndSpan()
+
+	params, err := s.FlattenParameterSet(ctx, params)
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
 	resolvedParams := make(secrets.Set)
 	var resolveErrors error
 
 	for _, param := range params.Parameters {
 		value, err := s.Secrets.Resolve(ctx, param.Source.Key, param.Source.Value)
+		if auditErr := auditStrategy(ctx, s.audit, secrets.AuditKindParameter, params.Namespace, installationFromParameterSet(params), param, err); auditErr != nil {
+			return nil, span.Error(auditErr)
+		}
 		if err != nil {
 			resolveErrors = multierror.Append(resolveErrors, fmt.Errorf("unable to resolve parameter %s.%s from %s %s: %w", params.Name, param.Name, param.Source.Key, param.Source.Value, err))
 		}
@@ -67,8 +100,89 @@ func (s ParameterStore) ResolveAll(ctx context.Context, params ParameterSet) (se
 	return resolvedParams, resolveErrors
 }
 
+// FlattenParameterSet returns the parameter set with the parameters that it
+// inherits from other parameter sets. Inherited parameter sets are found in the
+// parameter set's namespace, falling back to the global namespace. Parameter
+// sets are inherited in order, so a parameter from a later parameter set
+// overrides the same parameter from an earlier one, and the parameters defined
+// in the parameter set override every inherited parameter.
+func (s ParameterStore) FlattenParameterSet(ctx context.Context, params ParameterSet) (ParameterSet, error) {
+	if len(params.Inherits) == 0 {
+		return params, nil
+	}
+
+	flattened, err := s.flattenParameterSet(ctx, params, nil)
+	if err != nil {
+		return ParameterSet{}, err
+	}
+
+	params.Inherits = nil
+	params.Parameters = flattened
+	return params, nil
+}
+
+// flattenParameterSet returns the parameters of the parameter set, merged with
+// the parameters that it inherits. The chain is the parameter sets that are
+// being flattened, and is used to detect a cycle.
+func (s ParameterStore) flattenParameterSet(ctx context.Context, params ParameterSet, chain []string) ([]secrets.Strategy, error) {
+	chain = append(chain, params.String())
+
+	var merged []secrets.Strategy
+	index := make(map[string]int)
+	merge := func(overrides []secrets.Strategy) {
+		for _, param := range overrides {
+			if i, ok := index[param.Name]; ok {
+				merged[i] = param
+				continue
+			}
+			index[param.Name] = len(merged)
+			merged = append(merged, param)
+		}
+	}
+
+	for _, name := range params.Inherits {
+		parent, err := s.getInheritedParameterSet(ctx, params.Namespace, name)
+		if err != nil {
+			return nil, fmt.Errorf("parameter set %s inherits %s: %w", params, name, err)
+		}
+
+		for _, link := range chain {
+			if link == parent.String() {
+				return nil, fmt.Errorf("parameter set %s cannot inherit %s because it would create a cycle: %s -> %s", params, parent, strings.Join(chain, " -> "), parent)
+			}
+		}
+
+		inherited, err := s.flattenParameterSet(ctx, parent, chain)
+		if err != nil {
+			return nil, err
+		}
+		merge(inherited)
+	}
+
+	merge(params.Parameters)
+	return merged, nil
+}
+
+// getInheritedParameterSet finds an inherited parameter set in the namespace,
+// falling back to the global namespace.
+func (s ParameterStore) getInheritedParameterSet(ctx context.Context, namespace string, name string) (ParameterSet, error) {
+	var out ParameterSet
+	opts := FindOptions{
+		Sort: []string{"-namespace"},
+		Filter: bson.M{
+			"name": name,
+			"$or": []bson.M{
+				{"namespace": ""},
+				{"namespace": namespace},
+			},
+		},
+	}
+	err := s.Documents.FindOne(ctx, CollectionParameters, opts, &out)
+	return out, err
+}
+
 func (s ParameterStore) Validate(ctx context.Context, params ParameterSet) error {
-	validSources := []string{secrets.SourceSecret, host.SourceValue, host.SourceEnv, host.SourcePath, host.SourceCommand}
+	validSources := []string{secrets.SourceSecret, secrets.SourceKMS, host.SourceValue, host.SourceEnv, host.SourcePath, host.SourceCommand}
 	var errors error
 
 	for _, cs := range params.Parameters {
@@ -92,6 +206,10 @@ func (s ParameterStore) Validate(ctx context.Context, params ParameterSet) error
 }
 
 func (s ParameterStore) InsertParameterSet(ctx context.Context, params ParameterSet) error {
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionParameters, params.Namespace, params.Name); err != nil {
+		return err
+	}
+
 	params.SchemaVersion = ParameterSetSchemaVersion
 	opts := InsertOptions{
 		Documents: []interface{}{params},
@@ -102,10 +220,17 @@ func (s ParameterStore) InsertParameterSet(ctx context.Context, params Parameter
 func (s ParameterStore) ListParameterSets(ctx context.Context, listOptions ListOptions) ([]ParameterSet, error) {
 	var out []ParameterSet
 	err := s.Documents.Find(ctx, CollectionParameters, listOptions.ToFindOptions(), &out)
-	return out, err
+	if err != nil {
+		return nil, err
+	}
+	return filterAuthorized(ctx, s.authz, CollectionParameters, out, func(set ParameterSet) string { return set.Namespace })
 }
 
 func (s ParameterStore) GetParameterSet(ctx context.Context, namespace string, name string) (ParameterSet, error) {
+	if err := authorize(ctx, s.authz, VerbRead, CollectionParameters, namespace, name); err != nil {
+		return ParameterSet{}, err
+	}
+
 	var out ParameterSet
 	opts := FindOptions{
 		Filter: map[string]interface{}{
@@ -118,6 +243,10 @@ func (s ParameterStore) GetParameterSet(ctx context.Context, namespace string, n
 }
 
 func (s ParameterStore) UpdateParameterSet(ctx context.Context, params ParameterSet) error {
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionParameters, params.Namespace, params.Name); err != nil {
+		return err
+	}
+
 	params.SchemaVersion = ParameterSetSchemaVersion
 	opts := UpdateOptions{
 		Document: params,
@@ -126,6 +255,10 @@ func (s ParameterStore) UpdateParameterSet(ctx context.Context, params Parameter
 }
 
 func (s ParameterStore) UpsertParameterSet(ctx context.Context, params ParameterSet) error {
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionParameters, params.Namespace, params.Name); err != nil {
+		return err
+	}
+
 	params.SchemaVersion = ParameterSetSchemaVersion
 	opts := UpdateOptions{
 		Document: params,
@@ -135,6 +268,10 @@ func (s ParameterStore) UpsertParameterSet(ctx context.Context, params Parameter
 }
 
 func (s ParameterStore) RemoveParameterSet(ctx context.Context, namespace string, name string) error {
+	if err := authorize(ctx, s.authz, VerbDelete, CollectionParameters, namespace, name); err != nil {
+		return err
+	}
+
 	opts := RemoveOptions{
 		Namespace: namespace,
 		Name:      name,
@@ -144,6 +144,70 @@ func TestParameterStorage_ResolveAll(t *testing.T) {
 	})
 }
 
+func TestParameterStorage_ResolveAll_Inherits(t *testing.T) {
+	ctx := context.Background()
+	paramStore := NewTestParameterProvider(t)
+	defer paramStore.Close()
+
+	paramStore.AddSecret("base-region", "us-east-1")
+	paramStore.AddSecret("base-size", "small")
+	paramStore.AddSecret("prod-size", "large")
+	paramStore.AddSecret("app-replicas", "3")
+
+	base := NewParameterSet("", "base",
+		secrets.Strategy{Name: "region", Source: secrets.Source{Key: "secret", Value: "base-region"}},
+		secrets.Strategy{Name: "size", Source: secrets.Source{Key: "secret", Value: "base-size"}})
+	require.NoError(t, paramStore.InsertParameterSet(ctx, base))
+
+	prod := NewParameterSet("prod", "prod-overrides",
+		secrets.Strategy{Name: "size", Source: secrets.Source{Key: "secret", Value: "prod-size"}})
+	prod.Inherits = []string{"base"}
+	require.NoError(t, paramStore.InsertParameterSet(ctx, prod))
+
+	app := NewParameterSet("prod", "app",
+		secrets.Strategy{Name: "replicas", Source: secrets.Source{Key: "secret", Value: "app-replicas"}})
+	app.Inherits = []string{"base", "prod-overrides"}
+
+	resolved, err := paramStore.ResolveAll(ctx, app)
+	require.NoError(t, err)
+	wantParams := secrets.Set{
+		"region":   "us-east-1",
+		"size":     "large",
+		"replicas": "3",
+	}
+	require.Equal(t, wantParams, resolved, "later parameter sets should override earlier ones, and the parameter set's own parameters should override inherited ones")
+
+	flattened, err := paramStore.FlattenParameterSet(ctx, app)
+	require.NoError(t, err)
+	require.Empty(t, flattened.Inherits, "the flattened parameter set should not inherit other parameter sets")
+	var names []string
+	for _, param := range flattened.Parameters {
+		names = append(names, param.Name)
+	}
+	require.Equal(t, []string{"region", "size", "replicas"}, names, "the parameters should be listed in a deterministic order")
+
+	t.Run("missing parameter set", func(t *testing.T) {
+		missing := NewParameterSet("prod", "missing")
+		missing.Inherits = []string{"oops"}
+
+		_, err := paramStore.ResolveAll(ctx, missing)
+		require.ErrorContains(t, err, "parameter set prod/missing inherits oops")
+		require.ErrorIs(t, err, ErrNotFound{})
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		a := NewParameterSet("prod", "a")
+		a.Inherits = []string{"b"}
+		require.NoError(t, paramStore.InsertParameterSet(ctx, a))
+		b := NewParameterSet("prod", "b")
+		b.Inherits = []string{"a"}
+		require.NoError(t, paramStore.InsertParameterSet(ctx, b))
+
+		_, err := paramStore.ResolveAll(ctx, a)
+		require.EqualError(t, err, "parameter set prod/b cannot inherit prod/a because it would create a cycle: prod/a -> prod/b -> prod/a")
+	})
+}
+
 func TestParameterStorage_Validate(t *testing.T) {
 	t.Run("valid sources", func(t *testing.T) {
 		s := ParameterStore{}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -33,6 +34,13 @@ type ParameterSetSpec struct {
 	// Labels applied to the parameter set.
 	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty" toml:"labels,omitempty"`
 
+	// Inherits is a list of the names of parameter sets whose parameters are
+	// included in this parameter set. Parameter sets are inherited in order,
+	// so a parameter in a later parameter set overrides one from an earlier
+	// parameter set, and the parameters defined in this parameter set override
+	// all inherited parameters.
+	Inherits []string `json:"inherits,omitempty" yaml:"inherits,omitempty" toml:"inherits,omitempty"`
+
 	// Parameters is a list of parameter specs.
 	Parameters []secrets.Strategy `json:"parameters" yaml:"parameters" toml:"parameters"`
 }
@@ -46,6 +54,29 @@ type ParameterSetStatus struct {
 	Modified time.Time `json:"modified" yaml:"modified" toml:"modified"`
 }
 
+// ParameterSetRevision identifies the revision of a parameter set that was used
+// by a run. Parameter sets are updated in place, so the revision is identified
+// by when the parameter set was last modified.
+type ParameterSetRevision struct {
+	// Namespace of the parameter set.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+
+	// Name of the parameter set.
+	Name string `json:"name" yaml:"name"`
+
+	// Modified timestamp of the parameter set when it was used.
+	Modified time.Time `json:"modified" yaml:"modified"`
+}
+
+// GetRevision returns a reference to the current revision of the parameter set.
+func (s ParameterSet) GetRevision() ParameterSetRevision {
+	return ParameterSetRevision{
+		Namespace: s.Namespace,
+		Name:      s.Name,
+		Modified:  s.Status.Modified,
+	}
+}
+
 // NewParameterSet creates a new ParameterSet with the required fields initialized.
 func NewParameterSet(namespace string, name string, params ...secrets.Strategy) ParameterSet {
 	now := time.Now()
@@ -81,6 +112,15 @@ func (s ParameterSet) Validate() error {
 		}
 		return fmt.Errorf("invalid schemaVersion provided: %s. This version of Porter is compatible with %s.", s.SchemaVersion, ParameterSetSchemaVersion)
 	}
+
+	for _, name := range s.Inherits {
+		if name == "" {
+			return errors.New("invalid inherits: the name of an inherited parameter set is required")
+		}
+		if name == s.Name {
+			return fmt.Errorf("invalid inherits: parameter set %s cannot inherit itself", s.Name)
+		}
+	}
 	return nil
 }
 
@@ -13,6 +13,10 @@ type ParameterSetProvider interface {
 	// ResolveAll parameter values in the parameter set.
 	ResolveAll(ctx context.Context, params ParameterSet) (secrets.Set, error)
 
+	// FlattenParameterSet returns the parameter set with the parameters that it
+	// inherits from other parameter sets.
+	FlattenParameterSet(ctx context.Context, params ParameterSet) (ParameterSet, error)
+
 	// Validate the parameter set is defined properly.
 	Validate(ctx context.Context, params ParameterSet) error
 
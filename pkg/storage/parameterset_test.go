@@ -38,3 +38,23 @@ func TestParameterSet_String(t *testing.T) {
 		assert.Equal(t, "dev/myparams", ps.String())
 	})
 }
+
+func TestParameterSet_Validate_Inherits(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		ps := NewParameterSet("dev", "myparams")
+		ps.Inherits = []string{"base"}
+		assert.NoError(t, ps.Validate())
+	})
+
+	t.Run("inherits itself", func(t *testing.T) {
+		ps := NewParameterSet("dev", "myparams")
+		ps.Inherits = []string{"myparams"}
+		assert.EqualError(t, ps.Validate(), "invalid inherits: parameter set myparams cannot inherit itself")
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		ps := NewParameterSet("dev", "myparams")
+		ps.Inherits = []string{""}
+		assert.EqualError(t, ps.Validate(), "invalid inherits: the name of an inherited parameter set is required")
+	})
+}
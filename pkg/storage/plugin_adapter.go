@@ -3,10 +3,12 @@ package storage
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 
+	"get.porter.sh/porter/pkg/metrics"
 	"get.porter.sh/porter/pkg/storage/plugins"
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -40,23 +42,29 @@ func (a PluginAdapter) Close() error {
 
 func (a PluginAdapter) Aggregate(ctx context.Context, collection string, opts AggregateOptions, out interface{}) error {
 	rawResults, err := a.plugin.Aggregate(ctx, opts.ToPluginOptions(collection))
+	metrics.CountStorageQuery("aggregate", collection, len(rawResults), err)
 	if err != nil {
-		return err
+		return a.handleError(err, collection)
 	}
 
 	return a.unmarshalSlice(rawResults, out)
 }
 
 func (a PluginAdapter) EnsureIndex(ctx context.Context, opts EnsureIndexOptions) error {
-	return a.plugin.EnsureIndex(ctx, opts.ToPluginOptions())
+	err := a.plugin.EnsureIndex(ctx, opts.ToPluginOptions())
+	metrics.CountStorageError("ensure-index", "", err)
+	return err
 }
 
 func (a PluginAdapter) Count(ctx context.Context, collection string, opts CountOptions) (int64, error) {
-	return a.plugin.Count(ctx, opts.ToPluginOptions(collection))
+	count, err := a.plugin.Count(ctx, opts.ToPluginOptions(collection))
+	metrics.CountStorageQuery("count", collection, 0, err)
+	return count, a.handleError(err, collection)
 }
 
 func (a PluginAdapter) Find(ctx context.Context, collection string, opts FindOptions, out interface{}) error {
 	rawResults, err := a.plugin.Find(ctx, opts.ToPluginOptions(collection))
+	metrics.CountStorageQuery("find", collection, len(rawResults), err)
 	if err != nil {
 		return a.handleError(err, collection)
 
@@ -69,6 +77,7 @@ func (a PluginAdapter) Find(ctx context.Context, collection string, opts FindOpt
 // ErrNotFound when no results are returned.
 func (a PluginAdapter) FindOne(ctx context.Context, collection string, opts FindOptions, out interface{}) error {
 	rawResults, err := a.plugin.Find(ctx, opts.ToPluginOptions(collection))
+	metrics.CountStorageQuery("find", collection, len(rawResults), err)
 	if err != nil {
 		return a.handleError(err, collection)
 	}
@@ -164,16 +173,19 @@ func (a PluginAdapter) Insert(ctx context.Context, collection string, opts Inser
 	}
 
 	err = a.plugin.Insert(ctx, pluginOpts)
+	metrics.CountStorageQuery("insert", collection, 0, err)
 	return a.handleError(err, collection)
 }
 
 func (a PluginAdapter) Patch(ctx context.Context, collection string, opts PatchOptions) error {
 	err := a.plugin.Patch(ctx, opts.ToPluginOptions(collection))
+	metrics.CountStorageQuery("patch", collection, 0, err)
 	return a.handleError(err, collection)
 }
 
 func (a PluginAdapter) Remove(ctx context.Context, collection string, opts RemoveOptions) error {
 	err := a.plugin.Remove(ctx, opts.ToPluginOptions(collection))
+	metrics.CountStorageQuery("remove", collection, 0, err)
 	return a.handleError(err, collection)
 }
 
@@ -183,47 +195,30 @@ func (a PluginAdapter) Update(ctx context.Context, collection string, opts Updat
 		return err
 	}
 	err = a.plugin.Update(ctx, pluginOpts)
+	metrics.CountStorageQuery("update", collection, 0, err)
 	return a.handleError(err, collection)
 }
 
-// handleError unwraps errors returned from a plugin (which due to the round trip
-// through the plugin framework means the original typed error may not be the right type anymore
-// and turns it back into a well known error such as NotFound.
+// handleError turns errors returned from a plugin into a well known error,
+// such as ErrNotFound, based on the class of error reported by the plugin.
+// Plugins built before errors were classified only report a missing document
+// in the message, so that is checked last.
 func (a PluginAdapter) handleError(err error, collection string) error {
-	if err != nil && strings.Contains(strings.ToLower(err.Error()), "not found") {
-		return ErrNotFound{Collection: collection}
+	// Errors encrypting documents may mention a secret that was not found, but the document itself may exist
+	if errors.As(err, &EncryptionError{}) {
+		return err
 	}
-	return err
-}
 
-// ErrNotFound indicates that the requested document was not found.
-// You can test for this error using errors.Is(err, storage.ErrNotFound{})
-type ErrNotFound struct {
-	Collection string
-	Item       string
-}
-
-func (e ErrNotFound) Error() string {
-	var docType string
-	switch e.Collection {
-	case "installations":
-		docType = "Installation"
-	case "runs":
-		docType = "Run"
-	case "results":
-		docType = "Result"
-	case "output":
-		docType = "Output"
-	case "credentials", "parameters":
-		if len(e.Item) > 0 {
-			docType = e.Item
-		}
+	err = plugins.ClassifyError(err)
+	switch {
+	case errors.Is(err, plugins.ErrNotFound):
+		return ErrNotFound{Collection: collection}
+	case errors.Is(err, plugins.ErrConflict):
+		return ErrConflict{Collection: collection, Err: err}
+	case errors.Is(err, plugins.ErrUnauthorized):
+		return ErrUnauthorized{Collection: collection, Err: err}
+	case err != nil && strings.Contains(strings.ToLower(err.Error()), "not found"):
+		return ErrNotFound{Collection: collection}
 	}
-
-	return fmt.Sprintf("%s not found", docType)
-}
-
-func (e ErrNotFound) Is(err error) bool {
-	_, ok := err.(ErrNotFound)
-	return ok
+	return err
 }
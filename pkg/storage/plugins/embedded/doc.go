@@ -0,0 +1,6 @@
+// Package embedded implements the plugins.StorageProtocol interface, saving
+// data to a single file in PORTER_HOME with bbolt, or only keeping it in
+// memory. It uses the same query semantics as the mongodb plugin, so that
+// Porter can be used on a laptop, in CI, or in an air-gapped environment
+// without running mongodb.
+package embedded
@@ -0,0 +1,52 @@
+package embedded
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"get.porter.sh/porter/pkg/storage/pluginstore"
+	"github.com/hashicorp/go-plugin"
+	"github.com/mitchellh/mapstructure"
+)
+
+// PluginKey is the identifier of the internal embedded storage plugin.
+const PluginKey = plugins.PluginInterface + ".porter.embedded"
+
+// DefaultDatabaseFile is the name of the file in PORTER_HOME that stores the
+// data when a path is not configured.
+const DefaultDatabaseFile = "porter.db"
+
+// PluginConfig supported by the embedded plugin as defined in porter.yaml
+type PluginConfig struct {
+	// Path to the database file. Defaults to PORTER_HOME/porter.db.
+	Path string `mapstructure:"path,omitempty"`
+
+	// InMemory keeps the data in memory, and discards it when the plugin stops.
+	InMemory bool `mapstructure:"in-memory,omitempty"`
+
+	// Timeout in seconds to wait for another porter process to release the database file.
+	Timeout int `mapstructure:"timeout,omitempty"`
+}
+
+// NewPlugin creates an instance of the storage.porter.embedded plugin
+func NewPlugin(c *config.Config, rawCfg interface{}) (plugin.Plugin, error) {
+	cfg := PluginConfig{
+		Timeout: 10,
+	}
+	if err := mapstructure.Decode(rawCfg, &cfg); err != nil {
+		return nil, fmt.Errorf("error reading plugin configuration: %w", err)
+	}
+
+	if cfg.Path == "" && !cfg.InMemory {
+		home, err := c.GetHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Path = filepath.Join(home, DefaultDatabaseFile)
+	}
+
+	store := NewStore(cfg)
+	return pluginstore.NewPlugin(c.Context, store), nil
+}
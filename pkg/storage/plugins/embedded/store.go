@@ -0,0 +1,440 @@
+package embedded
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"get.porter.sh/porter/pkg/storage/plugins/inmemory"
+	"get.porter.sh/porter/pkg/tracing"
+	bolt "go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var _ plugins.StorageProtocol = &Store{}
+
+var (
+	// metaBucket holds the revision of the database, which is incremented on every write.
+	metaBucket = []byte("meta")
+
+	// revisionKey is the key of the revision in the meta bucket.
+	revisionKey = []byte("revision")
+
+	// collectionsBucket holds a nested bucket for each collection.
+	collectionsBucket = []byte("collections")
+
+	// documentsBucket is nested in a collection's bucket and holds its
+	// documents, keyed by their _id.
+	documentsBucket = []byte("documents")
+
+	// indicesKey is the key in a collection's bucket of its indices.
+	indicesKey = []byte("indices")
+)
+
+// Store implements the Porter plugin.StoragePlugin interface, saving documents
+// to a bbolt database file.
+//
+// Queries are evaluated by an in-memory emulator of mongodb that is loaded
+// from the file. The file is locked only for the duration of each operation,
+// so that multiple porter commands can use the same file, and the data is
+// reloaded when another process has changed it.
+type Store struct {
+	mu sync.Mutex
+
+	path     string
+	inMemory bool
+	timeout  time.Duration
+
+	// data that was loaded from the file.
+	data *inmemory.Store
+
+	// loaded indicates that data was loaded from the file, and is not stale.
+	loaded bool
+
+	// revision of the file that data was loaded from.
+	revision uint64
+}
+
+// NewStore creates a new storage engine that saves data to a bbolt database file.
+func NewStore(cfg PluginConfig) *Store {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 // default to 10 seconds
+	}
+	return &Store{
+		path:     cfg.Path,
+		inMemory: cfg.InMemory,
+		timeout:  time.Duration(timeout) * time.Second,
+		data:     inmemory.NewStore(),
+	}
+}
+
+// Close releases the data loaded from the database file. The file is closed
+// after each operation, so there is nothing else to release.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.loaded = false
+	return s.data.Close()
+}
+
+// RemoveDatabase removes all collections, deleting the database file.
+func (s *Store) RemoveDatabase(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.loaded = false
+	if err := s.data.RemoveDatabase(ctx); err != nil {
+		return err
+	}
+
+	if s.inMemory {
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not remove database file %s: %w", s.path, err)
+	}
+	return nil
+}
+
+func (s *Store) Aggregate(ctx context.Context, opts plugins.AggregateOptions) ([]bson.Raw, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	var results []bson.Raw
+	err := s.read(ctx, func() error {
+		var err error
+		results, err = s.data.Aggregate(ctx, opts)
+		return err
+	})
+	return results, span.Error(err)
+}
+
+func (s *Store) EnsureIndex(ctx context.Context, opts plugins.EnsureIndexOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	collections := make([]string, len(opts.Indices))
+	for i, idx := range opts.Indices {
+		collections[i] = idx.Collection
+	}
+
+	err := s.write(ctx, collections, func() error {
+		return s.data.EnsureIndex(ctx, opts)
+	})
+	return span.Error(err)
+}
+
+func (s *Store) Count(ctx context.Context, opts plugins.CountOptions) (int64, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	var count int64
+	err := s.read(ctx, func() error {
+		var err error
+		count, err = s.data.Count(ctx, opts)
+		return err
+	})
+	return count, span.Error(err)
+}
+
+func (s *Store) Find(ctx context.Context, opts plugins.FindOptions) ([]bson.Raw, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	var results []bson.Raw
+	err := s.read(ctx, func() error {
+		var err error
+		results, err = s.data.Find(ctx, opts)
+		return err
+	})
+	return results, span.Error(err)
+}
+
+func (s *Store) Insert(ctx context.Context, opts plugins.InsertOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	err := s.write(ctx, []string{opts.Collection}, func() error {
+		return s.data.Insert(ctx, opts)
+	})
+	return span.Error(err)
+}
+
+func (s *Store) Patch(ctx context.Context, opts plugins.PatchOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	err := s.write(ctx, []string{opts.Collection}, func() error {
+		return s.data.Patch(ctx, opts)
+	})
+	return span.Error(err)
+}
+
+func (s *Store) Remove(ctx context.Context, opts plugins.RemoveOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	err := s.write(ctx, []string{opts.Collection}, func() error {
+		return s.data.Remove(ctx, opts)
+	})
+	return span.Error(err)
+}
+
+func (s *Store) Update(ctx context.Context, opts plugins.UpdateOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	err := s.write(ctx, []string{opts.Collection}, func() error {
+		return s.data.Update(ctx, opts)
+	})
+	return span.Error(err)
+}
+
+// read runs a query against the data, after loading any changes made to the
+// database file by another process.
+func (s *Store) read(ctx context.Context, query func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.inMemory {
+		return query()
+	}
+
+	if _, err := os.Stat(s.path); errors.Is(err, os.ErrNotExist) {
+		// Nothing has been saved yet
+		return query()
+	}
+
+	db, err := s.open(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err = db.View(s.load); err != nil {
+		return err
+	}
+	return query()
+}
+
+// write changes the data, and saves the collections that were changed to the
+// database file. The file is locked until the changes are saved so that
+// concurrent writes from other processes are not lost.
+func (s *Store) write(ctx context.Context, collections []string, change func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.inMemory {
+		return change()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("could not create the directory for database file %s: %w", s.path, err)
+	}
+
+	db, err := s.open(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Save the collections even when the change failed, because a failed
+	// insert keeps the documents inserted before the failure, like mongodb.
+	var changeErr error
+	err = db.Update(func(tx *bolt.Tx) error {
+		if err := s.load(tx); err != nil {
+			return err
+		}
+
+		changeErr = change()
+		for _, name := range collections {
+			if err := s.saveCollection(tx, name); err != nil {
+				return err
+			}
+		}
+
+		meta, err := tx.CreateBucketIfNotExists(metaBucket)
+		if err != nil {
+			return err
+		}
+		s.revision++
+		return meta.Put(revisionKey, encodeUint64(s.revision))
+	})
+	if err != nil {
+		// The data no longer matches the file, reload it on the next operation
+		s.loaded = false
+		return fmt.Errorf("could not save to database file %s: %w", s.path, err)
+	}
+
+	return changeErr
+}
+
+// open the database file, waiting for other processes to release it.
+func (s *Store) open(ctx context.Context, readOnly bool) (*bolt.DB, error) {
+	log := tracing.LoggerFromContext(ctx)
+	log.SetAttributes(attribute.String("database-file", s.path))
+
+	db, err := bolt.Open(s.path, 0600, &bolt.Options{Timeout: s.timeout, ReadOnly: readOnly})
+	if err != nil {
+		if errors.Is(err, bolt.ErrTimeout) {
+			return nil, fmt.Errorf("timed out waiting for another porter process to release database file %s", s.path)
+		}
+		return nil, fmt.Errorf("could not open database file %s: %w", s.path, err)
+	}
+	return db, nil
+}
+
+// load the collections from the database file when it was changed since they
+// were last loaded.
+func (s *Store) load(tx *bolt.Tx) error {
+	revision := uint64(0)
+	if meta := tx.Bucket(metaBucket); meta != nil {
+		revision = decodeUint64(meta.Get(revisionKey))
+	}
+	if s.loaded && revision == s.revision {
+		return nil
+	}
+
+	data := inmemory.NewStore()
+	if collections := tx.Bucket(collectionsBucket); collections != nil {
+		err := collections.ForEach(func(name []byte, _ []byte) error {
+			c, err := loadCollection(collections.Bucket(name), string(name))
+			if err != nil {
+				return err
+			}
+			return data.ImportCollection(c)
+		})
+		if err != nil {
+			return fmt.Errorf("could not load database file %s: %w", s.path, err)
+		}
+	}
+
+	s.data = data
+	s.loaded = true
+	s.revision = revision
+	return nil
+}
+
+func loadCollection(b *bolt.Bucket, name string) (inmemory.CollectionData, error) {
+	c := inmemory.CollectionData{Name: name}
+
+	if rawIndices := b.Get(indicesKey); rawIndices != nil {
+		var indices struct {
+			Indices []plugins.Index `bson:"indices"`
+		}
+		if err := bson.Unmarshal(rawIndices, &indices); err != nil {
+			return c, fmt.Errorf("could not read the indices of the %s collection: %w", name, err)
+		}
+		c.Indices = indices.Indices
+	}
+
+	if docs := b.Bucket(documentsBucket); docs != nil {
+		err := docs.ForEach(func(_ []byte, doc []byte) error {
+			// The value is only valid for the life of the transaction
+			c.Documents = append(c.Documents, append(bson.Raw(nil), doc...))
+			return nil
+		})
+		if err != nil {
+			return c, err
+		}
+	}
+
+	return c, nil
+}
+
+// saveCollection saves the changes to the documents and indices of a
+// collection to the database file. Each document is keyed by its _id, so only
+// the documents that were added, changed or removed are written.
+func (s *Store) saveCollection(tx *bolt.Tx, name string) error {
+	c, err := s.data.ExportCollection(name)
+	if err != nil {
+		return err
+	}
+
+	collections, err := tx.CreateBucketIfNotExists(collectionsBucket)
+	if err != nil {
+		return err
+	}
+	b, err := collections.CreateBucketIfNotExists([]byte(name))
+	if err != nil {
+		return fmt.Errorf("could not save the %s collection: %w", name, err)
+	}
+
+	rawIndices, err := bson.Marshal(bson.M{"indices": c.Indices})
+	if err != nil {
+		return fmt.Errorf("could not save the indices of the %s collection: %w", name, err)
+	}
+	if !bytes.Equal(b.Get(indicesKey), rawIndices) {
+		if err = b.Put(indicesKey, rawIndices); err != nil {
+			return err
+		}
+	}
+
+	docs, err := b.CreateBucketIfNotExists(documentsBucket)
+	if err != nil {
+		return err
+	}
+	keep := make(map[string]struct{}, len(c.Documents))
+	for _, doc := range c.Documents {
+		key, err := documentKey(doc)
+		if err != nil {
+			return fmt.Errorf("could not save a document in the %s collection: %w", name, err)
+		}
+		keep[string(key)] = struct{}{}
+
+		if bytes.Equal(docs.Get(key), doc) {
+			continue
+		}
+		if err = docs.Put(key, doc); err != nil {
+			return fmt.Errorf("could not save a document in the %s collection: %w", name, err)
+		}
+	}
+
+	// Remove the documents that are no longer in the collection
+	cur := docs.Cursor()
+	for key, _ := cur.First(); key != nil; {
+		if _, ok := keep[string(key)]; ok {
+			key, _ = cur.Next()
+			continue
+		}
+		if err = cur.Delete(); err != nil {
+			return fmt.Errorf("could not remove a document from the %s collection: %w", name, err)
+		}
+		// Delete moves the cursor to the next document
+		key, _ = cur.Seek(key)
+	}
+	return nil
+}
+
+// documentKey returns the key of a document in the documents bucket, which is
+// its _id, including the bson type so that ids of different types don't collide.
+func documentKey(doc bson.Raw) ([]byte, error) {
+	id, err := doc.LookupErr("_id")
+	if err != nil {
+		return nil, errors.New("the document does not have an _id")
+	}
+	return append([]byte{byte(id.Type)}, id.Value...), nil
+}
+
+func encodeUint64(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+func decodeUint64(b []byte) uint64 {
+	if len(b) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
@@ -0,0 +1,147 @@
+package embedded
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const testCollection = "things"
+
+func TestStore_PersistsData(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), DefaultDatabaseFile)
+
+	s := NewStore(PluginConfig{Path: path})
+	defer s.Close()
+
+	err := s.EnsureIndex(ctx, plugins.EnsureIndexOptions{Indices: []plugins.Index{
+		{Collection: testCollection, Keys: bson.D{{Key: "name", Value: 1}}, Unique: true},
+	}})
+	require.NoError(t, err)
+
+	err = s.Insert(ctx, plugins.InsertOptions{Collection: testCollection, Documents: []bson.M{
+		{"_id": "1", "name": "mysql", "version": 3},
+		{"_id": "2", "name": "redis", "version": 1},
+	}})
+	require.NoError(t, err)
+	require.FileExists(t, path)
+
+	// Another process using the same file sees the data and indices
+	other := NewStore(PluginConfig{Path: path})
+	defer other.Close()
+
+	results, err := other.Find(ctx, plugins.FindOptions{Collection: testCollection, Filter: bson.M{"name": "redis"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "2", results[0].Lookup("_id").StringValue())
+	assert.Equal(t, int32(1), results[0].Lookup("version").Int32())
+
+	err = other.Insert(ctx, plugins.InsertOptions{Collection: testCollection, Documents: []bson.M{{"_id": "3", "name": "mysql"}}})
+	require.Error(t, err)
+	assert.True(t, mongo.IsDuplicateKeyError(err), "the unique index should be loaded from the file")
+
+	// Changes made by another process are reloaded
+	err = other.Update(ctx, plugins.UpdateOptions{Collection: testCollection, Filter: bson.M{"_id": "1"}, Document: bson.M{"_id": "1", "name": "mysql", "version": 4}})
+	require.NoError(t, err)
+
+	count, err := s.Count(ctx, plugins.CountOptions{Collection: testCollection, Filter: bson.M{"version": 4}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestStore_SavesDocumentsByID(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), DefaultDatabaseFile)
+
+	s := NewStore(PluginConfig{Path: path})
+	defer s.Close()
+
+	err := s.Insert(ctx, plugins.InsertOptions{Collection: testCollection, Documents: []bson.M{
+		{"_id": "1", "name": "mysql"},
+		{"_id": "2", "name": "redis"},
+		{"_id": "3", "name": "mongo"},
+	}})
+	require.NoError(t, err)
+
+	err = s.Remove(ctx, plugins.RemoveOptions{Collection: testCollection, Filter: bson.M{"_id": bson.M{"$in": []string{"1", "2"}}}, All: true})
+	require.NoError(t, err)
+
+	// Read the file directly to check which documents were kept
+	db, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true})
+	require.NoError(t, err)
+	defer db.Close()
+
+	var ids []string
+	err = db.View(func(tx *bolt.Tx) error {
+		docs := tx.Bucket(collectionsBucket).Bucket([]byte(testCollection)).Bucket(documentsBucket)
+		return docs.ForEach(func(key []byte, doc []byte) error {
+			id := bson.Raw(doc).Lookup("_id")
+			assert.Equal(t, append([]byte{byte(id.Type)}, id.Value...), key, "documents should be keyed by their _id")
+			ids = append(ids, id.StringValue())
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3"}, ids, "removed documents should be deleted from the file")
+}
+
+func TestStore_FailedInsertKeepsEarlierDocuments(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), DefaultDatabaseFile)
+
+	s := NewStore(PluginConfig{Path: path})
+	defer s.Close()
+
+	err := s.Insert(ctx, plugins.InsertOptions{Collection: testCollection, Documents: []bson.M{
+		{"_id": "1", "name": "mysql"},
+		{"_id": "1", "name": "redis"},
+	}})
+	require.True(t, mongo.IsDuplicateKeyError(err), "expected a duplicate key error, got %v", err)
+
+	other := NewStore(PluginConfig{Path: path})
+	defer other.Close()
+	count, err := other.Count(ctx, plugins.CountOptions{Collection: testCollection})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count, "the document inserted before the failure should be saved, like mongodb")
+}
+
+func TestStore_RemoveDatabase(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), DefaultDatabaseFile)
+
+	s := NewStore(PluginConfig{Path: path})
+	defer s.Close()
+
+	err := s.Insert(ctx, plugins.InsertOptions{Collection: testCollection, Documents: []bson.M{{"name": "mysql"}}})
+	require.NoError(t, err)
+
+	require.NoError(t, s.RemoveDatabase(ctx))
+	require.NoFileExists(t, path)
+
+	count, err := s.Count(ctx, plugins.CountOptions{Collection: testCollection})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestStore_InMemory(t *testing.T) {
+	ctx := context.Background()
+
+	s := NewStore(PluginConfig{InMemory: true})
+	defer s.Close()
+
+	err := s.Insert(ctx, plugins.InsertOptions{Collection: testCollection, Documents: []bson.M{{"name": "mysql"}}})
+	require.NoError(t, err)
+
+	count, err := s.Count(ctx, plugins.CountOptions{Collection: testCollection})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Empty(t, s.path, "no file should be used in memory mode")
+}
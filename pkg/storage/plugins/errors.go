@@ -0,0 +1,71 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+var (
+	// ErrNotFound is returned by a storage plugin when the collection or
+	// document does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrConflict is returned by a storage plugin when a document can't be
+	// saved because it conflicts with an existing document, such as a
+	// document with the same unique key.
+	ErrConflict = errors.New("conflict")
+
+	// ErrUnauthorized is returned by a storage plugin when the storage
+	// backend rejected the credentials, or they do not grant access to the
+	// data.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrUnavailable is returned by a storage plugin when the storage
+	// backend could not be reached, for example because the server is down
+	// or the network is unavailable.
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// Codes returned by mongodb for each class of error.
+// See https://github.com/mongodb/mongo/blob/master/src/mongo/base/error_codes.yml
+const (
+	mongoCodeUnauthorized         = 13
+	mongoCodeAuthenticationFailed = 18
+	mongoCodeNamespaceNotFound    = 26
+)
+
+// ClassifyError wraps an error returned by a storage plugin with ErrNotFound,
+// ErrConflict, ErrUnauthorized or ErrUnavailable, so that it can be tested
+// with errors.Is.
+// Errors from the mongodb driver are classified by their error code. Errors
+// that are already classified, or that do not belong to a class, are
+// returned unchanged.
+func ClassifyError(err error) error {
+	if err == nil || errors.Is(err, ErrNotFound) || errors.Is(err, ErrConflict) || errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrUnavailable) {
+		return err
+	}
+
+	var selectionErr topology.ServerSelectionError
+	if mongo.IsNetworkError(err) || errors.As(err, &selectionErr) {
+		return fmt.Errorf("%s: %w", err, ErrUnavailable)
+	}
+
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%s: %w", err, ErrConflict)
+	}
+
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		switch {
+		case serverErr.HasErrorCode(mongoCodeNamespaceNotFound):
+			return fmt.Errorf("%s: %w", err, ErrNotFound)
+		case serverErr.HasErrorCode(mongoCodeUnauthorized), serverErr.HasErrorCode(mongoCodeAuthenticationFailed):
+			return fmt.Errorf("%s: %w", err, ErrUnauthorized)
+		}
+	}
+
+	return err
+}
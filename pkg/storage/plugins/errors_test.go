@@ -0,0 +1,45 @@
+package plugins
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/description"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+func TestClassifyError(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "duplicate key", err: mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "E11000 duplicate key error"}}}, want: ErrConflict},
+		{name: "namespace not found", err: mongo.CommandError{Code: 26, Name: "NamespaceNotFound"}, want: ErrNotFound},
+		{name: "unauthorized", err: mongo.CommandError{Code: 13, Name: "Unauthorized"}, want: ErrUnauthorized},
+		{name: "authentication failed", err: mongo.CommandError{Code: 18, Name: "AuthenticationFailed"}, want: ErrUnauthorized},
+		{name: "server selection", err: topology.ServerSelectionError{Desc: description.Topology{}}, want: ErrUnavailable},
+		{name: "already classified", err: ErrNotFound, want: ErrNotFound},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ClassifyError(tc.err)
+			assert.ErrorIs(t, err, tc.want)
+			assert.Contains(t, err.Error(), tc.err.Error(), "the original message should be kept")
+		})
+	}
+
+	t.Run("unclassified", func(t *testing.T) {
+		err := errors.New("oops")
+		assert.Equal(t, err, ClassifyError(err))
+		assert.Nil(t, ClassifyError(nil))
+	})
+}
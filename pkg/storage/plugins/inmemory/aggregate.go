@@ -0,0 +1,297 @@
+package inmemory
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// aggregate runs an aggregation pipeline against a set of documents.
+// See https://docs.mongodb.com/manual/reference/operator/aggregation-pipeline/
+func aggregate(docs []bson.D, pipeline []bson.D) ([]bson.D, error) {
+	var err error
+	for _, stage := range pipeline {
+		if len(stage) != 1 {
+			return nil, fmt.Errorf("(Location40323) A pipeline stage specification object must contain exactly one field.")
+		}
+
+		name, spec := stage[0].Key, stage[0].Value
+		switch name {
+		case "$match":
+			docs, err = aggregateMatch(docs, spec)
+		case "$sort":
+			docs, err = aggregateSort(docs, spec)
+		case "$skip":
+			if typeRank(spec) != 2 {
+				return nil, fmt.Errorf("(Location15972) invalid argument to $skip stage: Expected a number in: $skip: %v", spec)
+			}
+			docs, err = paginate(docs, int64(toFloat(spec)), 0)
+		case "$limit":
+			if typeRank(spec) != 2 || toFloat(spec) <= 0 {
+				return nil, fmt.Errorf("(Location15958) the limit must be positive")
+			}
+			docs, err = paginate(docs, 0, int64(toFloat(spec)))
+		case "$project":
+			docs, err = aggregateProject(docs, spec)
+		case "$group":
+			docs, err = aggregateGroup(docs, spec)
+		case "$count":
+			field, ok := spec.(string)
+			if !ok || field == "" || strings.HasPrefix(field, "$") {
+				return nil, fmt.Errorf("(Location40156) the count field must be a non-empty string that does not start with $")
+			}
+			docs = []bson.D{{{Key: field, Value: int32(len(docs))}}}
+		case "$collStats", "$indexStats":
+			return nil, fmt.Errorf("(Location40602) %s is only valid as the first stage in a pipeline", name)
+		default:
+			return nil, fmt.Errorf("(Location40324) Unrecognized pipeline stage name: '%s'", name)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+func aggregateMatch(docs []bson.D, spec interface{}) ([]bson.D, error) {
+	f, err := newFilter(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []bson.D
+	for _, doc := range docs {
+		matched, err := f.Matches(doc)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			results = append(results, doc)
+		}
+	}
+	return results, nil
+}
+
+func aggregateSort(docs []bson.D, spec interface{}) ([]bson.D, error) {
+	sortSpec, ok := spec.(bson.D)
+	if !ok || len(sortSpec) == 0 {
+		return nil, fmt.Errorf("(Location15976) $sort stage must have at least one sort key")
+	}
+
+	sorted := make([]bson.D, len(docs))
+	copy(sorted, docs)
+	return sorted, sortDocuments(sorted, sortSpec)
+}
+
+func aggregateProject(docs []bson.D, spec interface{}) ([]bson.D, error) {
+	projectSpec, ok := spec.(bson.D)
+	if !ok || len(projectSpec) == 0 {
+		return nil, fmt.Errorf("(Location51272) $project requires at least one output field")
+	}
+
+	p, err := newProjection(projectSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]bson.D, len(docs))
+	for i, doc := range docs {
+		results[i] = p.Apply(doc)
+	}
+	return results, nil
+}
+
+// group holds the state of a single group while evaluating a $group stage.
+type group struct {
+	id     interface{}
+	fields bson.D
+	counts map[string]int
+}
+
+func aggregateGroup(docs []bson.D, spec interface{}) ([]bson.D, error) {
+	groupSpec, ok := spec.(bson.D)
+	if !ok {
+		return nil, fmt.Errorf("(Location15947) a group's fields must be specified in an object")
+	}
+
+	idExpr, ok := getField(groupSpec, "_id")
+	if !ok {
+		return nil, fmt.Errorf("(Location15955) a group specification must include an _id")
+	}
+
+	// Validate the accumulators before processing any documents
+	type accumulator struct {
+		field    string
+		operator string
+		expr     interface{}
+	}
+	var accumulators []accumulator
+	for _, field := range groupSpec {
+		if field.Key == "_id" {
+			continue
+		}
+		acc, ok := field.Value.(bson.D)
+		if !ok || len(acc) != 1 {
+			return nil, fmt.Errorf("(Location40234) The field '%s' must be an accumulator object", field.Key)
+		}
+		switch acc[0].Key {
+		case "$first", "$last", "$sum", "$avg", "$min", "$max", "$push", "$addToSet", "$count":
+		default:
+			return nil, fmt.Errorf("(Location15952) unknown group operator '%s'", acc[0].Key)
+		}
+		accumulators = append(accumulators, accumulator{field: field.Key, operator: acc[0].Key, expr: acc[0].Value})
+	}
+
+	// Track the groups in the order they were first seen
+	var groups []*group
+	for _, doc := range docs {
+		id := evaluateExpression(doc, idExpr)
+
+		var g *group
+		for _, existing := range groups {
+			if typeRank(existing.id) == typeRank(id) && compareValues(existing.id, id) == 0 {
+				g = existing
+				break
+			}
+		}
+		if g == nil {
+			g = &group{id: id, counts: map[string]int{}}
+			groups = append(groups, g)
+		}
+
+		for _, acc := range accumulators {
+			value := evaluateExpression(doc, acc.expr)
+			current, initialized := getField(g.fields, acc.field)
+
+			switch acc.operator {
+			case "$first":
+				if !initialized {
+					g.fields = setField(g.fields, acc.field, value)
+				}
+			case "$last":
+				g.fields = setField(g.fields, acc.field, value)
+			case "$count":
+				g.fields = setField(g.fields, acc.field, int32(g.counts[acc.field]+1))
+				g.counts[acc.field]++
+			case "$sum", "$avg":
+				if !initialized {
+					current = int32(0)
+				}
+				if typeRank(value) == 2 {
+					current = addNumbers(current, value)
+					g.counts[acc.field]++
+				}
+				g.fields = setField(g.fields, acc.field, current)
+			case "$min", "$max":
+				if typeRank(value) == 1 {
+					if !initialized {
+						g.fields = setField(g.fields, acc.field, nil)
+					}
+					continue
+				}
+				c := compareValues(value, current)
+				if !initialized || typeRank(current) == 1 || (acc.operator == "$min" && c < 0) || (acc.operator == "$max" && c > 0) {
+					g.fields = setField(g.fields, acc.field, value)
+				}
+			case "$push", "$addToSet":
+				items, _ := current.(bson.A)
+				if items == nil {
+					items = bson.A{}
+				}
+				if acc.operator == "$addToSet" && containsValue(items, value) {
+					continue
+				}
+				g.fields = setField(g.fields, acc.field, append(items, value))
+			}
+		}
+	}
+
+	// mongodb does not guarantee the order of groups, but in practice it returns
+	// the most recently created group first, so we do the same
+	results := make([]bson.D, len(groups))
+	for i, g := range groups {
+		result := bson.D{{Key: "_id", Value: g.id}}
+		for _, acc := range accumulators {
+			value, _ := getField(g.fields, acc.field)
+			if acc.operator == "$avg" {
+				if n := g.counts[acc.field]; n > 0 {
+					value = toFloat(value) / float64(n)
+				} else {
+					value = nil
+				}
+			}
+			result = append(result, bson.E{Key: acc.field, Value: value})
+		}
+		results[len(groups)-1-i] = result
+	}
+	return results, nil
+}
+
+// evaluateExpression evaluates an aggregation expression against a document,
+// supporting $$ROOT, field paths such as $name, and literal values.
+func evaluateExpression(doc bson.D, expr interface{}) interface{} {
+	switch e := expr.(type) {
+	case string:
+		if e == "$$ROOT" || e == "$$CURRENT" {
+			return doc
+		}
+		if strings.HasPrefix(e, "$") {
+			value, _ := getPathValue(doc, strings.TrimPrefix(e, "$"))
+			return value
+		}
+		return e
+	case bson.D:
+		result := make(bson.D, 0, len(e))
+		for _, field := range e {
+			result = append(result, bson.E{Key: field.Key, Value: evaluateExpression(doc, field.Value)})
+		}
+		return result
+	default:
+		return e
+	}
+}
+
+// addNumbers adds two numbers, widening the result type like mongodb.
+func addNumbers(a interface{}, b interface{}) interface{} {
+	switch {
+	case isFloat(a) || isFloat(b):
+		return toFloat(a) + toFloat(b)
+	case isInt64(a) || isInt64(b):
+		return int64(toFloat(a)) + int64(toFloat(b))
+	default:
+		sum := int64(toFloat(a)) + int64(toFloat(b))
+		if sum > int64(^uint32(0)>>1) || sum < -int64(^uint32(0)>>1)-1 {
+			return sum
+		}
+		return int32(sum)
+	}
+}
+
+func isFloat(value interface{}) bool {
+	switch value.(type) {
+	case float32, float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isInt64(value interface{}) bool {
+	switch value.(type) {
+	case int64, int, uint, uint32, uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+func containsValue(items bson.A, value interface{}) bool {
+	for _, item := range items {
+		if typeRank(item) == typeRank(value) && compareValues(item, value) == 0 {
+			return true
+		}
+	}
+	return false
+}
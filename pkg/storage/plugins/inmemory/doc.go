@@ -0,0 +1,5 @@
+// Package inmemory implements the plugins.StorageProtocol interface, storing data
+// in memory. It emulates the query, sort, projection, aggregation and index
+// semantics of the mongodb plugin so that it can be used in place of mongodb
+// in unit tests.
+package inmemory
@@ -0,0 +1,91 @@
+package inmemory
+
+import (
+	"fmt"
+	"sort"
+
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CollectionData is the contents of a collection, its documents and indices.
+// It is used to save the data kept by the store and restore it later.
+type CollectionData struct {
+	// Name of the collection.
+	Name string
+
+	// Documents in the collection, in the order that they were inserted.
+	Documents []bson.Raw
+
+	// Indices defined on the collection, excluding the default index on _id.
+	Indices []plugins.Index
+}
+
+// CollectionNames returns the names of the collections in the store, sorted by name.
+func (s *Store) CollectionNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.collections))
+	for name := range s.collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExportCollection returns a copy of the documents and indices in a collection.
+func (s *Store) ExportCollection(name string) (CollectionData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := CollectionData{Name: name}
+	c, ok := s.collections[name]
+	if !ok {
+		return data, nil
+	}
+
+	for _, idx := range c.indices {
+		if idx.name == "_id_" {
+			continue
+		}
+		data.Indices = append(data.Indices, plugins.Index{Collection: name, Keys: copyDocument(idx.keys), Unique: idx.unique})
+	}
+
+	docs, err := marshalResults(c.docs)
+	if err != nil {
+		return CollectionData{}, fmt.Errorf("could not export the %s collection: %w", name, err)
+	}
+	data.Documents = docs
+	return data, nil
+}
+
+// ImportCollection replaces the documents and indices of a collection.
+func (s *Store) ImportCollection(data CollectionData) error {
+	docs := make([]bson.D, len(data.Documents))
+	for i, raw := range data.Documents {
+		var doc bson.D
+		if err := bson.Unmarshal(raw, &doc); err != nil {
+			return fmt.Errorf("could not import document %d into the %s collection: %w", i, data.Name, err)
+		}
+		docs[i] = doc
+	}
+
+	indices := make([]index, 0, len(data.Indices))
+	for _, idx := range data.Indices {
+		keys, err := normalizeDocument(idx.Keys)
+		if err != nil {
+			return fmt.Errorf("could not import an index into the %s collection: %w", data.Name, err)
+		}
+		indices = append(indices, index{name: indexName(keys), keys: keys, unique: idx.Unique})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.collections, data.Name)
+	c := s.getCollection(data.Name)
+	c.indices = append(c.indices, indices...)
+	c.docs = docs
+	return nil
+}
@@ -0,0 +1,329 @@
+package inmemory
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// filter is a compiled mongodb query filter document.
+// See https://docs.mongodb.com/manual/core/document/#std-label-document-query-filter
+type filter struct {
+	query bson.D
+}
+
+// newFilter validates and prepares a query filter for evaluation.
+func newFilter(query interface{}) (filter, error) {
+	doc, err := normalizeDocument(query)
+	if err != nil {
+		return filter{}, fmt.Errorf("invalid query filter: %w", err)
+	}
+
+	f := filter{query: doc}
+
+	// Evaluate against an empty document so that invalid queries are reported
+	// even when the collection is empty, just like mongodb.
+	if _, err = f.Matches(bson.D{}); err != nil {
+		return filter{}, err
+	}
+	return f, nil
+}
+
+// Matches determines if the document satisfies the filter.
+func (f filter) Matches(doc bson.D) (bool, error) {
+	return matchQuery(doc, f.query)
+}
+
+func matchQuery(doc bson.D, query bson.D) (bool, error) {
+	// Evaluate every clause so that errors are found consistently regardless of the data
+	result := true
+	for _, clause := range query {
+		var matched bool
+		var err error
+		if strings.HasPrefix(clause.Key, "$") {
+			matched, err = matchLogicalOperator(doc, clause.Key, clause.Value)
+		} else {
+			matched, err = matchField(doc, clause.Key, clause.Value)
+		}
+		if err != nil {
+			return false, err
+		}
+		result = result && matched
+	}
+	return result, nil
+}
+
+func matchLogicalOperator(doc bson.D, operator string, value interface{}) (bool, error) {
+	switch operator {
+	case "$and", "$or", "$nor":
+		clauses, ok := value.(bson.A)
+		if !ok || len(clauses) == 0 {
+			return false, fmt.Errorf("(BadValue) %s must be a nonempty array", operator)
+		}
+
+		results := make([]bool, len(clauses))
+		for i, clause := range clauses {
+			subquery, ok := clause.(bson.D)
+			if !ok {
+				return false, fmt.Errorf("(BadValue) $and/$or/$nor entries need to be full objects")
+			}
+			matched, err := matchQuery(doc, subquery)
+			if err != nil {
+				return false, err
+			}
+			results[i] = matched
+		}
+
+		switch operator {
+		case "$and":
+			return allTrue(results), nil
+		case "$or":
+			return anyTrue(results), nil
+		default: // $nor
+			return !anyTrue(results), nil
+		}
+	case "$comment":
+		return true, nil
+	default:
+		return false, fmt.Errorf("(BadValue) unknown top level operator: %s", operator)
+	}
+}
+
+func matchField(doc bson.D, path string, condition interface{}) (bool, error) {
+	if ops, ok := condition.(bson.D); ok && isOperatorDocument(ops) {
+		return matchOperators(doc, path, ops)
+	}
+
+	if regex, ok := condition.(primitive.Regex); ok {
+		return matchRegex(doc, path, regex.Pattern, regex.Options)
+	}
+
+	return matchEquals(doc, path, condition), nil
+}
+
+// isOperatorDocument determines if the condition is an operator expression,
+// e.g. {$gt: 1}, instead of an embedded document to match.
+func isOperatorDocument(doc bson.D) bool {
+	return len(doc) > 0 && strings.HasPrefix(doc[0].Key, "$")
+}
+
+func matchOperators(doc bson.D, path string, ops bson.D) (bool, error) {
+	result := true
+	for i := 0; i < len(ops); i++ {
+		op := ops[i]
+		var matched bool
+		var err error
+
+		switch op.Key {
+		case "$eq":
+			matched = matchEquals(doc, path, op.Value)
+		case "$ne":
+			matched = !matchEquals(doc, path, op.Value)
+		case "$gt", "$gte", "$lt", "$lte":
+			matched = matchComparison(doc, path, op.Key, op.Value)
+		case "$in":
+			matched, err = matchIn(doc, path, op.Value)
+		case "$nin":
+			matched, err = matchIn(doc, path, op.Value)
+			matched = !matched
+		case "$exists":
+			_, found := lookupPath(doc, path)
+			matched = found == isTruthy(op.Value)
+		case "$regex":
+			options := ""
+			for _, other := range ops {
+				if other.Key == "$options" {
+					options = toString(other.Value)
+				}
+			}
+			switch pattern := op.Value.(type) {
+			case string:
+				matched, err = matchRegex(doc, path, pattern, options)
+			case primitive.Regex:
+				if options == "" {
+					options = pattern.Options
+				}
+				matched, err = matchRegex(doc, path, pattern.Pattern, options)
+			default:
+				err = fmt.Errorf("(BadValue) $regex has to be a string")
+			}
+		case "$options":
+			if _, ok := getField(ops, "$regex"); !ok {
+				err = fmt.Errorf("(BadValue) $options needs a $regex")
+			}
+			matched = true
+		case "$not":
+			switch inner := op.Value.(type) {
+			case bson.D:
+				if !isOperatorDocument(inner) {
+					err = fmt.Errorf("(BadValue) $not cannot have an embedded document")
+					break
+				}
+				matched, err = matchOperators(doc, path, inner)
+				matched = !matched
+			case primitive.Regex:
+				matched, err = matchRegex(doc, path, inner.Pattern, inner.Options)
+				matched = !matched
+			default:
+				err = fmt.Errorf("(BadValue) $not needs a regex or a document")
+			}
+		case "$all":
+			values, ok := op.Value.(bson.A)
+			if !ok {
+				err = fmt.Errorf("(BadValue) $all needs an array")
+				break
+			}
+			matched = true
+			for _, value := range values {
+				if !matchEquals(doc, path, value) {
+					matched = false
+				}
+			}
+		case "$size":
+			if typeRank(op.Value) != 2 {
+				err = fmt.Errorf("(BadValue) $size needs a number")
+				break
+			}
+			value, _ := getPathValue(doc, path)
+			arr, ok := value.(bson.A)
+			matched = ok && float64(len(arr)) == toFloat(op.Value)
+		default:
+			err = fmt.Errorf("(BadValue) unknown operator: %s", op.Key)
+		}
+
+		if err != nil {
+			return false, err
+		}
+		result = result && matched
+	}
+	return result, nil
+}
+
+// matchEquals checks if any of the values at the path are equal to the specified value.
+// Missing fields are considered equal to null.
+func matchEquals(doc bson.D, path string, value interface{}) bool {
+	candidates, found := lookupPath(doc, path)
+	if !found {
+		return typeRank(value) == 1
+	}
+
+	for _, candidate := range candidates {
+		if typeRank(candidate) == typeRank(value) && compareValues(candidate, value) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// matchComparison implements $gt, $gte, $lt and $lte. Following mongodb's
+// type bracketing, values are only compared against values of the same type.
+func matchComparison(doc bson.D, path string, operator string, value interface{}) bool {
+	candidates, found := lookupPath(doc, path)
+	if !found {
+		// Missing fields are treated as null
+		if typeRank(value) != 1 {
+			return false
+		}
+		return operator == "$gte" || operator == "$lte"
+	}
+
+	for _, candidate := range candidates {
+		if typeRank(candidate) != typeRank(value) {
+			continue
+		}
+
+		c := compareValues(candidate, value)
+		var matched bool
+		switch operator {
+		case "$gt":
+			matched = c > 0
+		case "$gte":
+			matched = c >= 0
+		case "$lt":
+			matched = c < 0
+		case "$lte":
+			matched = c <= 0
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func matchIn(doc bson.D, path string, value interface{}) (bool, error) {
+	values, ok := value.(bson.A)
+	if !ok {
+		return false, fmt.Errorf("(BadValue) $in needs an array")
+	}
+
+	for _, v := range values {
+		if regex, ok := v.(primitive.Regex); ok {
+			matched, err := matchRegex(doc, path, regex.Pattern, regex.Options)
+			if err != nil {
+				return false, err
+			}
+			if matched {
+				return true, nil
+			}
+			continue
+		}
+
+		if matchEquals(doc, path, v) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func matchRegex(doc bson.D, path string, pattern string, options string) (bool, error) {
+	flags := ""
+	for _, opt := range options {
+		switch opt {
+		case 'i', 'm', 's':
+			flags += string(opt)
+		case 'x':
+			// Extended mode ignores whitespace in the pattern
+			pattern = strings.Join(strings.Fields(pattern), "")
+		default:
+			return false, fmt.Errorf("(BadValue) invalid flag in regex options: %c", opt)
+		}
+	}
+	if flags != "" {
+		pattern = fmt.Sprintf("(?%s)%s", flags, pattern)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, fmt.Errorf("(BadValue) Regular expression is invalid: %w", err)
+	}
+
+	candidates, _ := lookupPath(doc, path)
+	for _, candidate := range candidates {
+		if s, ok := candidate.(string); ok && re.MatchString(s) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func allTrue(values []bool) bool {
+	for _, v := range values {
+		if !v {
+			return false
+		}
+	}
+	return true
+}
+
+func anyTrue(values []bool) bool {
+	for _, v := range values {
+		if v {
+			return true
+		}
+	}
+	return false
+}
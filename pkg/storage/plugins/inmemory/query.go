@@ -0,0 +1,254 @@
+package inmemory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// sortDocuments sorts documents in place using a mongodb sort document,
+// e.g. {"namespace": 1, "resultId": -1}. Documents that compare as equal
+// retain their natural (insertion) order.
+func sortDocuments(docs []bson.D, spec bson.D) error {
+	if len(spec) == 0 {
+		return nil
+	}
+
+	orders := make([]int, len(spec))
+	for i, key := range spec {
+		if typeRank(key.Value) != 2 {
+			return fmt.Errorf("(BadValue) Illegal key in $sort specification: %s: %v", key.Key, key.Value)
+		}
+		switch toFloat(key.Value) {
+		case 1:
+			orders[i] = 1
+		case -1:
+			orders[i] = -1
+		default:
+			return fmt.Errorf("(BadValue) $sort key ordering must be 1 (for ascending) or -1 (for descending)")
+		}
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool {
+		for k, key := range spec {
+			a := sortValue(docs[i], key.Key, orders[k])
+			b := sortValue(docs[j], key.Key, orders[k])
+			if c := compareValues(a, b) * orders[k]; c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+// sortValue selects the value used to sort a document by a field. When the
+// field is an array, mongodb uses the smallest element for an ascending sort
+// and the largest element for a descending sort.
+func sortValue(doc bson.D, path string, order int) interface{} {
+	value, ok := getPathValue(doc, path)
+	if !ok {
+		return nil
+	}
+
+	arr, ok := value.(bson.A)
+	if !ok || len(arr) == 0 {
+		return value
+	}
+
+	selected := arr[0]
+	for _, item := range arr[1:] {
+		if compareValues(item, selected)*order < 0 {
+			selected = item
+		}
+	}
+	return selected
+}
+
+// paginate applies skip and limit to a set of sorted documents.
+func paginate(docs []bson.D, skip int64, limit int64) ([]bson.D, error) {
+	if skip < 0 {
+		return nil, fmt.Errorf("(BadValue) skip value must be non-negative, but received: %d", skip)
+	}
+	if skip >= int64(len(docs)) {
+		return nil, nil
+	}
+	docs = docs[skip:]
+
+	// A negative limit is treated the same as a positive limit, returning a single batch of results
+	if limit < 0 {
+		limit = -limit
+	}
+	if limit > 0 && limit < int64(len(docs)) {
+		docs = docs[:limit]
+	}
+	return docs, nil
+}
+
+// projection is a parsed mongodb projection document.
+// See https://docs.mongodb.com/manual/tutorial/project-fields-from-query-results/
+type projection struct {
+	// include is true for an inclusion projection and false for an exclusion projection.
+	include bool
+
+	// excludeID indicates that the _id field should be excluded.
+	excludeID bool
+
+	// fields is a tree of the projected field paths.
+	fields projectionTree
+}
+
+// projectionTree is a tree of field names. Leaves are represented with a nil
+// subtree.
+type projectionTree map[string]projectionTree
+
+func (t projectionTree) add(path string) error {
+	parts := strings.SplitN(path, ".", 2)
+	child, exists := t[parts[0]]
+	if len(parts) == 1 {
+		t[parts[0]] = nil
+		return nil
+	}
+
+	if exists && child == nil {
+		return fmt.Errorf("(Location31250) Path collision at %s", path)
+	}
+	if child == nil {
+		child = projectionTree{}
+		t[parts[0]] = child
+	}
+	return child.add(parts[1])
+}
+
+// newProjection parses a projection document.
+func newProjection(spec bson.D) (*projection, error) {
+	if len(spec) == 0 {
+		return nil, nil
+	}
+
+	doc, err := normalizeDocument(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid projection: %w", err)
+	}
+
+	p := &projection{fields: projectionTree{}}
+	modeSet := false
+	for _, field := range doc {
+		included := isTruthy(field.Value)
+
+		if field.Key == "_id" {
+			if !included {
+				p.excludeID = true
+			}
+			continue
+		}
+
+		if !modeSet {
+			p.include = included
+			modeSet = true
+		} else if p.include != included {
+			if p.include {
+				return nil, fmt.Errorf("(Location31254) Cannot do exclusion on field %s in inclusion projection", field.Key)
+			}
+			return nil, fmt.Errorf("(Location31253) Cannot do inclusion on field %s in exclusion projection", field.Key)
+		}
+
+		if err := p.fields.add(field.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	if !modeSet {
+		// Only _id was specified, so either only _id is returned or everything else is
+		p.include = !p.excludeID
+	}
+
+	return p, nil
+}
+
+// Apply the projection to a document, returning a new document.
+func (p *projection) Apply(doc bson.D) bson.D {
+	if p == nil {
+		return doc
+	}
+
+	var result bson.D
+	if p.include {
+		result = includeFields(doc, p.fields, true)
+	} else {
+		result = excludeFields(doc, p.fields)
+	}
+
+	if p.excludeID {
+		result = removeField(result, "_id")
+	}
+	return result
+}
+
+func includeFields(doc bson.D, fields projectionTree, topLevel bool) bson.D {
+	result := bson.D{}
+	for _, elem := range doc {
+		subtree, selected := fields[elem.Key]
+		if topLevel && elem.Key == "_id" && !selected {
+			result = append(result, elem)
+			continue
+		}
+		if !selected {
+			continue
+		}
+
+		if subtree == nil {
+			result = append(result, elem)
+			continue
+		}
+
+		switch v := elem.Value.(type) {
+		case bson.D:
+			result = append(result, bson.E{Key: elem.Key, Value: includeFields(v, subtree, false)})
+		case bson.A:
+			items := bson.A{}
+			for _, item := range v {
+				if itemDoc, ok := item.(bson.D); ok {
+					items = append(items, includeFields(itemDoc, subtree, false))
+				}
+			}
+			result = append(result, bson.E{Key: elem.Key, Value: items})
+		}
+	}
+	return result
+}
+
+func excludeFields(doc bson.D, fields projectionTree) bson.D {
+	result := bson.D{}
+	for _, elem := range doc {
+		subtree, selected := fields[elem.Key]
+		if !selected {
+			result = append(result, elem)
+			continue
+		}
+
+		if subtree == nil {
+			continue
+		}
+
+		switch v := elem.Value.(type) {
+		case bson.D:
+			result = append(result, bson.E{Key: elem.Key, Value: excludeFields(v, subtree)})
+		case bson.A:
+			items := make(bson.A, len(v))
+			for i, item := range v {
+				if itemDoc, ok := item.(bson.D); ok {
+					items[i] = excludeFields(itemDoc, subtree)
+				} else {
+					items[i] = item
+				}
+			}
+			result = append(result, bson.E{Key: elem.Key, Value: items})
+		default:
+			result = append(result, elem)
+		}
+	}
+	return result
+}
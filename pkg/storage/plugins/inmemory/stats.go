@@ -0,0 +1,112 @@
+package inmemory
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// isCollectionStage determines if a pipeline stage describes the collection
+// itself, instead of the documents in the collection.
+func isCollectionStage(stage bson.D) bool {
+	if len(stage) != 1 {
+		return false
+	}
+	return stage[0].Key == "$collStats" || stage[0].Key == "$indexStats"
+}
+
+// describeCollection evaluates a $collStats or $indexStats stage, which must
+// be the first stage in the pipeline. The caller must hold the store lock.
+func (s *Store) describeCollection(collectionName string, stage bson.D) ([]bson.D, error) {
+	name, spec := stage[0].Key, stage[0].Value
+	c, exists := s.collections[collectionName]
+
+	switch name {
+	case "$collStats":
+		specDoc, ok := spec.(bson.D)
+		if !ok {
+			return nil, fmt.Errorf("(Location5447000) $collStats must take a nested object but found: %v", spec)
+		}
+
+		result := bson.D{{Key: "ns", Value: s.database + "." + collectionName}}
+		for _, option := range specDoc {
+			switch option.Key {
+			case "count":
+				if !exists {
+					return nil, collectionNotFound(s.database, collectionName)
+				}
+				result = append(result, bson.E{Key: "count", Value: int64(len(c.docs))})
+			case "storageStats":
+				if !exists {
+					return nil, collectionNotFound(s.database, collectionName)
+				}
+				stats, err := c.storageStats()
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, bson.E{Key: "storageStats", Value: stats})
+			default:
+				return nil, fmt.Errorf("(Location40415) BSON field '$collStats.%s' is an unknown field.", option.Key)
+			}
+		}
+		return []bson.D{result}, nil
+	case "$indexStats":
+		// Unlike $collStats, $indexStats does not return an error when the collection does not exist
+		if !exists {
+			return nil, nil
+		}
+
+		results := make([]bson.D, len(c.indices))
+		for i, idx := range c.indices {
+			spec := bson.D{{Key: "v", Value: int32(2)}, {Key: "key", Value: idx.keys}, {Key: "name", Value: idx.name}}
+			if idx.unique {
+				spec = append(spec, bson.E{Key: "unique", Value: true})
+			}
+			results[i] = bson.D{
+				{Key: "name", Value: idx.name},
+				{Key: "key", Value: idx.keys},
+				{Key: "accesses", Value: bson.D{{Key: "ops", Value: int64(0)}}},
+				{Key: "spec", Value: spec},
+			}
+		}
+		return results, nil
+	}
+
+	return nil, fmt.Errorf("(Location40324) Unrecognized pipeline stage name: '%s'", name)
+}
+
+// storageStats returns the size of the documents in the collection, in the
+// same format as the storageStats returned by $collStats.
+func (c *collection) storageStats() (bson.D, error) {
+	var size int64
+	for _, doc := range c.docs {
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		size += int64(len(data))
+	}
+
+	stats := bson.D{
+		{Key: "size", Value: size},
+		{Key: "count", Value: int64(len(c.docs))},
+	}
+	if len(c.docs) > 0 {
+		stats = append(stats, bson.E{Key: "avgObjSize", Value: size / int64(len(c.docs))})
+	}
+	return append(stats,
+		bson.E{Key: "storageSize", Value: size},
+		bson.E{Key: "nindexes", Value: int32(len(c.indices))},
+		// Indices are not stored separately from the documents in memory
+		bson.E{Key: "totalIndexSize", Value: int64(0)},
+	), nil
+}
+
+func collectionNotFound(database string, collection string) error {
+	return mongo.CommandError{
+		Code:    26,
+		Name:    "NamespaceNotFound",
+		Message: fmt.Sprintf("Unable to retrieve storageStats in $collStats stage :: caused by :: Collection [%s.%s] not found.", database, collection),
+	}
+}
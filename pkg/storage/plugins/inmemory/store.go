@@ -0,0 +1,579 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"get.porter.sh/porter/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var _ plugins.StorageProtocol = &Store{}
+
+// Store implements the Porter plugin.StoragePlugin interface, keeping all
+// documents in memory. Errors returned by the store use the same types and
+// messages as the mongodb driver so that code handling errors, such as
+// mongo.IsDuplicateKeyError, behaves the same as it does against mongodb.
+type Store struct {
+	mu          sync.Mutex
+	database    string
+	collections map[string]*collection
+}
+
+// collection holds the documents and indices for a single collection.
+type collection struct {
+	name    string
+	docs    []bson.D
+	indices []index
+}
+
+// index on a collection.
+type index struct {
+	name   string
+	keys   bson.D
+	unique bool
+}
+
+// NewStore creates a new storage engine that keeps data in memory.
+func NewStore() *Store {
+	return &Store{
+		database:    "porter",
+		collections: map[string]*collection{},
+	}
+}
+
+// Close releases all the stored data.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.collections = map[string]*collection{}
+	return nil
+}
+
+// RemoveDatabase removes all collections.
+func (s *Store) RemoveDatabase(ctx context.Context) error {
+	return s.Close()
+}
+
+func (s *Store) getCollection(name string) *collection {
+	c, ok := s.collections[name]
+	if !ok {
+		c = &collection{
+			name: name,
+			// mongodb always has a unique index on _id
+			indices: []index{{name: "_id_", keys: bson.D{{Key: "_id", Value: int32(1)}}, unique: true}},
+		}
+		s.collections[name] = c
+	}
+	return c
+}
+
+func (s *Store) Aggregate(ctx context.Context, opts plugins.AggregateOptions) ([]bson.Raw, error) {
+	_, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	pipeline := make([]bson.D, len(opts.Pipeline))
+	for i, stage := range opts.Pipeline {
+		normalized, err := normalizeDocument(stage)
+		if err != nil {
+			return nil, span.Error(fmt.Errorf("invalid pipeline stage: %w", err))
+		}
+		pipeline[i] = normalized
+	}
+
+	s.mu.Lock()
+	var docs []bson.D
+	if len(pipeline) > 0 && isCollectionStage(pipeline[0]) {
+		var err error
+		docs, err = s.describeCollection(opts.Collection, pipeline[0])
+		if err != nil {
+			s.mu.Unlock()
+			return nil, span.Error(err)
+		}
+		pipeline = pipeline[1:]
+	} else {
+		docs = s.snapshot(opts.Collection)
+	}
+	s.mu.Unlock()
+
+	results, err := aggregate(docs, pipeline)
+	if err != nil {
+		return nil, span.Error(err)
+	}
+	return marshalResults(results)
+}
+
+// EnsureIndex makes sure that the specified indexes exist and are
+// defined appropriately.
+func (s *Store) EnsureIndex(ctx context.Context, opts plugins.EnsureIndexOptions) error {
+	_, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, idx := range opts.Indices {
+		keys, err := normalizeDocument(idx.Keys)
+		if err != nil {
+			return span.Error(fmt.Errorf("invalid index specified: %w", err))
+		}
+		if len(keys) == 0 {
+			return span.Error(fmt.Errorf("invalid index specified: (BadValue) Index keys cannot be empty."))
+		}
+
+		newIndex := index{name: indexName(keys), keys: keys, unique: idx.Unique}
+		c := s.getCollection(idx.Collection)
+
+		exists := false
+		for _, existing := range c.indices {
+			if existing.name != newIndex.name {
+				continue
+			}
+			if existing.unique != newIndex.unique {
+				return span.Error(fmt.Errorf("invalid index specified: %w", mongo.CommandError{
+					Code:    85,
+					Name:    "IndexOptionsConflict",
+					Message: fmt.Sprintf("Index with name: %s already exists with different options", newIndex.name),
+				}))
+			}
+			exists = true
+		}
+		if exists {
+			continue
+		}
+
+		// Creating a unique index fails when the collection already has duplicate values
+		if newIndex.unique {
+			for i := range c.docs {
+				if dupe := c.findDuplicate(newIndex, c.docs[i], i); dupe != nil {
+					return span.Error(fmt.Errorf("invalid index specified: %w", mongo.CommandError{
+						Code:    11000,
+						Name:    "DuplicateKey",
+						Message: s.duplicateKeyMessage(c, newIndex, c.docs[i]),
+					}))
+				}
+			}
+		}
+
+		c.indices = append(c.indices, newIndex)
+	}
+
+	return nil
+}
+
+func (s *Store) Count(ctx context.Context, opts plugins.CountOptions) (int64, error) {
+	_, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	f, err := newFilter(opts.Filter)
+	if err != nil {
+		return 0, span.Error(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int64
+	for _, doc := range s.getCollection(opts.Collection).docs {
+		matched, err := f.Matches(doc)
+		if err != nil {
+			return 0, span.Error(err)
+		}
+		if matched {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *Store) Find(ctx context.Context, opts plugins.FindOptions) ([]bson.Raw, error) {
+	_, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	f, err := newFilter(opts.Filter)
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
+	p, err := newProjection(opts.Select)
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
+	sortSpec, err := normalizeDocument(opts.Sort)
+	if err != nil {
+		return nil, span.Error(fmt.Errorf("invalid sort: %w", err))
+	}
+
+	s.mu.Lock()
+	docs := s.snapshot(opts.Collection)
+	s.mu.Unlock()
+
+	var results []bson.D
+	for _, doc := range docs {
+		matched, err := f.Matches(doc)
+		if err != nil {
+			return nil, span.Error(err)
+		}
+		if matched {
+			results = append(results, doc)
+		}
+	}
+
+	if err = sortDocuments(results, sortSpec); err != nil {
+		return nil, span.Error(err)
+	}
+
+	results, err = paginate(results, opts.Skip, opts.Limit)
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
+	for i, doc := range results {
+		results[i] = p.Apply(doc)
+	}
+
+	return marshalResults(results)
+}
+
+func (s *Store) Insert(ctx context.Context, opts plugins.InsertOptions) error {
+	_, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.getCollection(opts.Collection)
+	for i, rawDoc := range opts.Documents {
+		doc, err := normalizeDocument(rawDoc)
+		if err != nil {
+			return span.Error(err)
+		}
+		doc = ensureID(doc)
+
+		// Inserts are ordered, so stop at the first failure, keeping the documents inserted before it
+		if err = s.checkUniqueIndices(c, doc, -1); err != nil {
+			return span.Error(mongo.BulkWriteException{
+				WriteErrors: []mongo.BulkWriteError{{WriteError: mongo.WriteError{Index: i, Code: 11000, Message: err.Error()}}},
+			})
+		}
+		c.docs = append(c.docs, doc)
+	}
+
+	return nil
+}
+
+func (s *Store) Patch(ctx context.Context, opts plugins.PatchOptions) error {
+	_, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	f, err := newFilter(opts.QueryDocument)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	transformation, err := normalizeDocument(opts.Transformation)
+	if err != nil {
+		return span.Error(fmt.Errorf("invalid transformation: %w", err))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.getCollection(opts.Collection)
+	i, err := c.findFirst(f)
+	if err != nil || i < 0 {
+		// Patching a document that doesn't exist is not an error
+		return span.Error(err)
+	}
+
+	patched, err := applyTransformation(copyDocument(c.docs[i]), transformation)
+	if err != nil {
+		return span.Error(mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 9, Message: err.Error()}}})
+	}
+
+	if err = s.replace(c, i, patched); err != nil {
+		return span.Error(err)
+	}
+	return nil
+}
+
+func (s *Store) Remove(ctx context.Context, opts plugins.RemoveOptions) error {
+	_, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	f, err := newFilter(opts.Filter)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.getCollection(opts.Collection)
+	var kept []bson.D
+	removed := false
+	for _, doc := range c.docs {
+		if removed && !opts.All {
+			kept = append(kept, doc)
+			continue
+		}
+
+		matched, err := f.Matches(doc)
+		if err != nil {
+			return span.Error(err)
+		}
+		if matched {
+			removed = true
+			continue
+		}
+		kept = append(kept, doc)
+	}
+	c.docs = kept
+
+	return nil
+}
+
+func (s *Store) Update(ctx context.Context, opts plugins.UpdateOptions) error {
+	_, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	f, err := newFilter(opts.Filter)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	doc, err := normalizeDocument(opts.Document)
+	if err != nil {
+		return span.Error(err)
+	}
+	for _, field := range doc {
+		if strings.HasPrefix(field.Key, "$") {
+			return span.Error(fmt.Errorf("replacement document cannot contain keys beginning with '$'"))
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.getCollection(opts.Collection)
+	i, err := c.findFirst(f)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	if i < 0 {
+		if !opts.Upsert {
+			return nil
+		}
+
+		// When upserting, mongodb uses the _id from the filter if the document doesn't define one
+		if _, ok := getField(doc, "_id"); !ok {
+			if id, ok := getField(f.query, "_id"); ok {
+				if ops, isDoc := id.(bson.D); !isDoc || !isOperatorDocument(ops) {
+					doc = append(bson.D{{Key: "_id", Value: id}}, doc...)
+				}
+			}
+		}
+		doc = ensureID(doc)
+		if err = s.checkUniqueIndices(c, doc, -1); err != nil {
+			return span.Error(mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: err.Error()}}})
+		}
+		c.docs = append(c.docs, doc)
+		return nil
+	}
+
+	// The _id field is immutable, keep the original value
+	existingID, _ := getField(c.docs[i], "_id")
+	if newID, ok := getField(doc, "_id"); ok {
+		if typeRank(newID) != typeRank(existingID) || compareValues(newID, existingID) != 0 {
+			return span.Error(mongo.WriteException{WriteErrors: mongo.WriteErrors{{
+				Code:    66,
+				Message: "After applying the update, the (immutable) field '_id' was found to have been altered",
+			}}})
+		}
+	} else {
+		doc = append(bson.D{{Key: "_id", Value: existingID}}, doc...)
+	}
+
+	return span.Error(s.replace(c, i, doc))
+}
+
+// snapshot returns a copy of the documents in a collection.
+func (s *Store) snapshot(collectionName string) []bson.D {
+	c := s.getCollection(collectionName)
+	docs := make([]bson.D, len(c.docs))
+	for i, doc := range c.docs {
+		docs[i] = copyDocument(doc)
+	}
+	return docs
+}
+
+// replace the document at the specified position, enforcing unique indices.
+func (s *Store) replace(c *collection, i int, doc bson.D) error {
+	if err := s.checkUniqueIndices(c, doc, i); err != nil {
+		return mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: err.Error()}}}
+	}
+	c.docs[i] = doc
+	return nil
+}
+
+// checkUniqueIndices returns an error when the document would violate a
+// unique index. The document at position skip is ignored, which allows for
+// checking a replacement document.
+func (s *Store) checkUniqueIndices(c *collection, doc bson.D, skip int) error {
+	for _, idx := range c.indices {
+		if !idx.unique {
+			continue
+		}
+		if dupe := c.findDuplicate(idx, doc, skip); dupe != nil {
+			return fmt.Errorf("%s", s.duplicateKeyMessage(c, idx, doc))
+		}
+	}
+	return nil
+}
+
+// findDuplicate looks for another document with the same index key values.
+func (c *collection) findDuplicate(idx index, doc bson.D, skip int) bson.D {
+	key := indexKey(idx, doc)
+	for i, existing := range c.docs {
+		if i == skip {
+			continue
+		}
+		if compareValues(indexKey(idx, existing), key) == 0 {
+			return existing
+		}
+	}
+	return nil
+}
+
+// findFirst returns the position of the first document matching the filter,
+// or -1 when no documents match.
+func (c *collection) findFirst(f filter) (int, error) {
+	for i, doc := range c.docs {
+		matched, err := f.Matches(doc)
+		if err != nil {
+			return -1, err
+		}
+		if matched {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+func (s *Store) duplicateKeyMessage(c *collection, idx index, doc bson.D) string {
+	values := make([]string, len(idx.keys))
+	for i, key := range idx.keys {
+		value, _ := getPathValue(doc, key.Key)
+		values[i] = fmt.Sprintf("%s: %s", key.Key, formatValue(value))
+	}
+	return fmt.Sprintf("E11000 duplicate key error collection: %s.%s index: %s dup key: { %s }",
+		s.database, c.name, idx.name, strings.Join(values, ", "))
+}
+
+// indexKey builds the key for a document in an index. Missing fields are indexed as null.
+func indexKey(idx index, doc bson.D) bson.A {
+	key := make(bson.A, len(idx.keys))
+	for i, field := range idx.keys {
+		key[i], _ = getPathValue(doc, field.Key)
+	}
+	return key
+}
+
+// indexName generates the default name of an index, following the mongodb convention, e.g. namespace_1_name_1.
+func indexName(keys bson.D) string {
+	parts := make([]string, 0, len(keys)*2)
+	for _, key := range keys {
+		parts = append(parts, key.Key, fmt.Sprint(key.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ensureID generates an _id for the document if one is not defined.
+func ensureID(doc bson.D) bson.D {
+	if _, ok := getField(doc, "_id"); ok {
+		return doc
+	}
+	return append(bson.D{{Key: "_id", Value: primitive.NewObjectID()}}, doc...)
+}
+
+// applyTransformation applies update operators such as $set to a document.
+func applyTransformation(doc bson.D, transformation bson.D) (bson.D, error) {
+	if len(transformation) == 0 {
+		return nil, fmt.Errorf("Update document requires atomic operators")
+	}
+
+	var err error
+	for _, op := range transformation {
+		fields, ok := op.Value.(bson.D)
+		if !ok {
+			return nil, fmt.Errorf("Modifiers operate on fields but we found type %T instead", op.Value)
+		}
+
+		for _, field := range fields {
+			if field.Key == "_id" {
+				return nil, fmt.Errorf("Performing an update on the path '_id' would modify the immutable field '_id'")
+			}
+
+			switch op.Key {
+			case "$set":
+				doc, err = setPathValue(doc, field.Key, field.Value)
+			case "$unset":
+				doc = unsetPathValue(doc, field.Key)
+			case "$inc":
+				current, _ := getPathValue(doc, field.Key)
+				if current == nil {
+					current = int32(0)
+				}
+				if typeRank(current) != 2 || typeRank(field.Value) != 2 {
+					return nil, fmt.Errorf("Cannot apply $inc to a value of non-numeric type")
+				}
+				doc, err = setPathValue(doc, field.Key, addNumbers(current, field.Value))
+			case "$push":
+				current, _ := getPathValue(doc, field.Key)
+				items, ok := current.(bson.A)
+				if current != nil && !ok {
+					return nil, fmt.Errorf("The field '%s' must be an array but is of type %T", field.Key, current)
+				}
+				doc, err = setPathValue(doc, field.Key, append(items, field.Value))
+			default:
+				return nil, fmt.Errorf("Unknown modifier: %s. Expected a valid update modifier or pipeline-style update specified as an array", op.Key)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return doc, nil
+}
+
+func marshalResults(docs []bson.D) ([]bson.Raw, error) {
+	// Match the mongodb plugin, which returns nil when there are no results
+	var results []bson.Raw
+	for _, doc := range docs {
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, data)
+	}
+	return results, nil
+}
@@ -0,0 +1,359 @@
+package inmemory
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const testCollection = "things"
+
+func newTestStore(t *testing.T) *Store {
+	s := NewStore()
+	t.Cleanup(func() { s.Close() })
+
+	err := s.Insert(context.Background(), plugins.InsertOptions{
+		Collection: testCollection,
+		Documents: []bson.M{
+			{"_id": "1", "namespace": "dev", "name": "mysql", "version": 3, "labels": bson.M{"team": "red"}, "tags": []string{"db", "sql"}},
+			{"_id": "2", "namespace": "dev", "name": "redis", "version": 1.5, "labels": bson.M{"team": "blue"}, "tags": []string{"cache"}},
+			{"_id": "3", "namespace": "staging", "name": "mysql", "version": 2, "labels": bson.M{"team": "red"}},
+			{"_id": "4", "namespace": "", "name": "postgres", "version": "latest"},
+		},
+	})
+	require.NoError(t, err, "Insert failed")
+	return s
+}
+
+func findIDs(t *testing.T, s *Store, opts plugins.FindOptions) ([]string, error) {
+	opts.Collection = testCollection
+	results, err := s.Find(context.Background(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.Lookup("_id").StringValue()
+	}
+	return ids, nil
+}
+
+func TestStore_Find_Filter(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name    string
+		filter  bson.M
+		wantIDs []string
+		wantErr string
+	}{
+		{name: "all", filter: nil, wantIDs: []string{"1", "2", "3", "4"}},
+		{name: "equals", filter: bson.M{"namespace": "dev"}, wantIDs: []string{"1", "2"}},
+		{name: "nested field", filter: bson.M{"labels.team": "red"}, wantIDs: []string{"1", "3"}},
+		{name: "array element", filter: bson.M{"tags": "sql"}, wantIDs: []string{"1"}},
+		{name: "missing field equals null", filter: bson.M{"tags": nil}, wantIDs: []string{"3", "4"}},
+		{name: "regex", filter: bson.M{"name": bson.M{"$regex": "^my"}}, wantIDs: []string{"1", "3"}},
+		{name: "regex options", filter: bson.M{"name": bson.M{"$regex": "REDIS", "$options": "i"}}, wantIDs: []string{"2"}},
+		{name: "numeric comparison across types", filter: bson.M{"version": bson.M{"$gte": 2}}, wantIDs: []string{"1", "3"}},
+		{name: "type bracketing", filter: bson.M{"version": bson.M{"$lt": "z"}}, wantIDs: []string{"4"}},
+		{name: "in", filter: bson.M{"name": bson.M{"$in": []string{"redis", "postgres"}}}, wantIDs: []string{"2", "4"}},
+		{name: "nin", filter: bson.M{"name": bson.M{"$nin": []string{"mysql"}}}, wantIDs: []string{"2", "4"}},
+		{name: "ne", filter: bson.M{"namespace": bson.M{"$ne": "dev"}}, wantIDs: []string{"3", "4"}},
+		{name: "exists", filter: bson.M{"labels": bson.M{"$exists": false}}, wantIDs: []string{"4"}},
+		{name: "or", filter: bson.M{"$or": []bson.M{{"namespace": ""}, {"namespace": "staging"}}}, wantIDs: []string{"3", "4"}},
+		{name: "and", filter: bson.M{"$and": []bson.M{{"namespace": "dev"}, {"labels.team": "blue"}}}, wantIDs: []string{"2"}},
+		{name: "unknown operator", filter: bson.M{"name": bson.M{"$oops": 1}}, wantErr: "(BadValue) unknown operator: $oops"},
+		{name: "unknown top level operator", filter: bson.M{"$oops": 1}, wantErr: "(BadValue) unknown top level operator: $oops"},
+		{name: "empty or", filter: bson.M{"$or": []bson.M{}}, wantErr: "(BadValue) $or must be a nonempty array"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := newTestStore(t)
+			ids, err := findIDs(t, s, plugins.FindOptions{Filter: tc.filter})
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantIDs, ids)
+		})
+	}
+}
+
+func TestStore_Find_SortAndPaginate(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+
+	ids, err := findIDs(t, s, plugins.FindOptions{Sort: bson.D{{Key: "name", Value: 1}, {Key: "namespace", Value: -1}}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3", "1", "4", "2"}, ids)
+
+	ids, err = findIDs(t, s, plugins.FindOptions{Sort: bson.D{{Key: "version", Value: -1}}, Skip: 1, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "3"}, ids, "strings sort after numbers")
+
+	ids, err = findIDs(t, s, plugins.FindOptions{Skip: 10})
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+
+	_, err = findIDs(t, s, plugins.FindOptions{Skip: -1})
+	require.ErrorContains(t, err, "skip value must be non-negative")
+
+	_, err = findIDs(t, s, plugins.FindOptions{Sort: bson.D{{Key: "name", Value: 2}}})
+	require.ErrorContains(t, err, "$sort key ordering must be 1 (for ascending) or -1 (for descending)")
+}
+
+func TestStore_Find_Select(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	results, err := s.Find(ctx, plugins.FindOptions{
+		Collection: testCollection,
+		Filter:     bson.M{"_id": "1"},
+		Select:     bson.D{{Key: "name", Value: 1}, {Key: "labels.team", Value: 1}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	var got bson.M
+	require.NoError(t, bson.Unmarshal(results[0], &got))
+	assert.Equal(t, bson.M{"_id": "1", "name": "mysql", "labels": bson.M{"team": "red"}}, got, "inclusion projections include _id by default")
+
+	results, err = s.Find(ctx, plugins.FindOptions{
+		Collection: testCollection,
+		Filter:     bson.M{"_id": "4"},
+		Select:     bson.D{{Key: "version", Value: false}, {Key: "_id", Value: 0}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	got = nil
+	require.NoError(t, bson.Unmarshal(results[0], &got))
+	assert.Equal(t, bson.M{"namespace": "", "name": "postgres"}, got)
+
+	_, err = s.Find(ctx, plugins.FindOptions{
+		Collection: testCollection,
+		Select:     bson.D{{Key: "name", Value: 1}, {Key: "labels", Value: 0}},
+	})
+	require.EqualError(t, err, "(Location31254) Cannot do exclusion on field labels in inclusion projection")
+}
+
+func TestStore_Count(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	count, err := s.Count(context.Background(), plugins.CountOptions{Collection: testCollection, Filter: bson.M{"name": "mysql"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestStore_UniqueIndex(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	err := s.EnsureIndex(ctx, plugins.EnsureIndexOptions{Indices: []plugins.Index{
+		{Collection: testCollection, Keys: bson.D{{Key: "namespace", Value: 1}, {Key: "name", Value: 1}}, Unique: true},
+	}})
+	require.NoError(t, err)
+
+	err = s.Insert(ctx, plugins.InsertOptions{
+		Collection: testCollection,
+		Documents:  []bson.M{{"namespace": "dev", "name": "mysql"}},
+	})
+	require.True(t, mongo.IsDuplicateKeyError(err), "expected a duplicate key error, got %v", err)
+	assert.Contains(t, err.Error(), `E11000 duplicate key error collection: porter.things index: namespace_1_name_1 dup key: { namespace: "dev", name: "mysql" }`)
+
+	err = s.Update(ctx, plugins.UpdateOptions{
+		Collection: testCollection,
+		Filter:     bson.M{"_id": "2"},
+		Document:   bson.M{"namespace": "dev", "name": "mysql"},
+	})
+	require.True(t, mongo.IsDuplicateKeyError(err), "expected a duplicate key error, got %v", err)
+
+	err = s.Insert(ctx, plugins.InsertOptions{
+		Collection: testCollection,
+		Documents:  []bson.M{{"_id": "1"}},
+	})
+	require.True(t, mongo.IsDuplicateKeyError(err), "_id should always be unique")
+
+	// Changing the options on an existing index is an error
+	err = s.EnsureIndex(ctx, plugins.EnsureIndexOptions{Indices: []plugins.Index{
+		{Collection: testCollection, Keys: bson.D{{Key: "namespace", Value: 1}, {Key: "name", Value: 1}}},
+	}})
+	require.ErrorContains(t, err, "Index with name: namespace_1_name_1 already exists with different options")
+
+	// Creating a unique index on duplicate data is an error
+	err = s.EnsureIndex(ctx, plugins.EnsureIndexOptions{Indices: []plugins.Index{
+		{Collection: testCollection, Keys: bson.D{{Key: "name", Value: 1}}, Unique: true},
+	}})
+	require.True(t, mongo.IsDuplicateKeyError(err), "expected a duplicate key error, got %v", err)
+}
+
+func TestStore_UpdatePatchRemove(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	// Upsert uses the _id from the filter
+	err := s.Update(ctx, plugins.UpdateOptions{
+		Collection: testCollection,
+		Filter:     bson.M{"_id": "5"},
+		Document:   bson.M{"namespace": "prod", "name": "mysql"},
+		Upsert:     true,
+	})
+	require.NoError(t, err)
+	ids, err := findIDs(t, s, plugins.FindOptions{Filter: bson.M{"namespace": "prod"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"5"}, ids)
+
+	err = s.Patch(ctx, plugins.PatchOptions{
+		Collection:     testCollection,
+		QueryDocument:  bson.M{"_id": "5"},
+		Transformation: bson.D{{Key: "$set", Value: bson.D{{Key: "labels.team", Value: "green"}}}, {Key: "$inc", Value: bson.D{{Key: "revision", Value: 1}}}},
+	})
+	require.NoError(t, err)
+	count, err := s.Count(ctx, plugins.CountOptions{Collection: testCollection, Filter: bson.M{"labels.team": "green", "revision": 1}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	err = s.Patch(ctx, plugins.PatchOptions{
+		Collection:     testCollection,
+		QueryDocument:  bson.M{"_id": "5"},
+		Transformation: bson.D{{Key: "$rename", Value: bson.D{{Key: "name", Value: "title"}}}},
+	})
+	require.ErrorContains(t, err, "Unknown modifier: $rename")
+
+	// Remove only deletes the first match unless All is set
+	err = s.Remove(ctx, plugins.RemoveOptions{Collection: testCollection, Filter: bson.M{"name": "mysql"}})
+	require.NoError(t, err)
+	ids, err = findIDs(t, s, plugins.FindOptions{Filter: bson.M{"name": "mysql"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3", "5"}, ids)
+
+	err = s.Remove(ctx, plugins.RemoveOptions{Collection: testCollection, Filter: bson.M{"name": "mysql"}, All: true})
+	require.NoError(t, err)
+	ids, err = findIDs(t, s, plugins.FindOptions{Filter: bson.M{"name": "mysql"}})
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestStore_Aggregate(t *testing.T) {
+	t.Parallel()
+
+	s := newTestStore(t)
+	results, err := s.Aggregate(context.Background(), plugins.AggregateOptions{
+		Collection: testCollection,
+		Pipeline: []bson.D{
+			{{Key: "$match", Value: bson.M{"labels.team": bson.M{"$exists": true}}}},
+			{{Key: "$sort", Value: bson.D{{Key: "version", Value: -1}}}},
+			{{Key: "$group", Value: bson.D{
+				{Key: "_id", Value: "$name"},
+				{Key: "latest", Value: bson.M{"$first": "$$ROOT"}},
+				{Key: "count", Value: bson.M{"$sum": 1}},
+			}}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	var got []bson.M
+	for _, result := range results {
+		var doc bson.M
+		require.NoError(t, bson.Unmarshal(result, &doc))
+		got = append(got, doc)
+	}
+	assert.Equal(t, "redis", got[0]["_id"])
+	assert.Equal(t, "mysql", got[1]["_id"])
+	assert.Equal(t, int32(2), got[1]["count"])
+	assert.Equal(t, "1", got[1]["latest"].(bson.M)["_id"], "$first should select the highest version after sorting")
+
+	_, err = s.Aggregate(context.Background(), plugins.AggregateOptions{
+		Collection: testCollection,
+		Pipeline:   []bson.D{{{Key: "$oops", Value: bson.M{}}}},
+	})
+	require.EqualError(t, err, "(Location40324) Unrecognized pipeline stage name: '$oops'")
+}
+
+func TestStore_Aggregate_CollectionStats(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := newTestStore(t)
+	require.NoError(t, s.EnsureIndex(ctx, plugins.EnsureIndexOptions{Indices: []plugins.Index{
+		{Collection: testCollection, Keys: bson.D{{Key: "namespace", Value: 1}, {Key: "name", Value: -1}}},
+	}}))
+
+	results, err := s.Aggregate(ctx, plugins.AggregateOptions{
+		Collection: testCollection,
+		Pipeline:   []bson.D{{{Key: "$collStats", Value: bson.D{{Key: "storageStats", Value: bson.D{}}}}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	stats := results[0].Lookup("storageStats").Document()
+	assert.Equal(t, int64(4), stats.Lookup("count").Int64())
+	assert.Greater(t, stats.Lookup("size").Int64(), int64(0))
+
+	_, err = s.Aggregate(ctx, plugins.AggregateOptions{
+		Collection: "missing",
+		Pipeline:   []bson.D{{{Key: "$collStats", Value: bson.D{{Key: "count", Value: bson.D{}}}}}},
+	})
+	require.ErrorContains(t, err, "Collection [porter.missing] not found")
+
+	results, err = s.Aggregate(ctx, plugins.AggregateOptions{
+		Collection: testCollection,
+		Pipeline:   []bson.D{{{Key: "$indexStats", Value: bson.D{}}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "_id_", results[0].Lookup("name").StringValue())
+	assert.Equal(t, "namespace_1_name_-1", results[1].Lookup("name").StringValue())
+
+	_, err = s.Aggregate(ctx, plugins.AggregateOptions{
+		Collection: testCollection,
+		Pipeline: []bson.D{
+			{{Key: "$match", Value: bson.M{}}},
+			{{Key: "$indexStats", Value: bson.D{}}},
+		},
+	})
+	require.EqualError(t, err, "(Location40602) $indexStats is only valid as the first stage in a pipeline")
+}
+
+func TestStore_ExportImportCollection(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	err := s.EnsureIndex(ctx, plugins.EnsureIndexOptions{Indices: []plugins.Index{
+		{Collection: testCollection, Keys: bson.D{{Key: "namespace", Value: 1}, {Key: "name", Value: 1}}, Unique: true},
+	}})
+	require.NoError(t, err)
+
+	data, err := s.ExportCollection(testCollection)
+	require.NoError(t, err)
+	assert.Len(t, data.Documents, 4)
+	require.Len(t, data.Indices, 1, "the default index on _id should not be exported")
+
+	restored := NewStore()
+	defer restored.Close()
+	require.NoError(t, restored.ImportCollection(data))
+	assert.Equal(t, []string{testCollection}, restored.CollectionNames())
+
+	ids, err := findIDs(t, restored, plugins.FindOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3", "4"}, ids, "the documents should keep their order")
+
+	err = restored.Insert(ctx, plugins.InsertOptions{Collection: testCollection, Documents: []bson.M{{"namespace": "dev", "name": "redis"}}})
+	assert.True(t, mongo.IsDuplicateKeyError(err), "the unique index should be restored")
+}
@@ -0,0 +1,430 @@
+package inmemory
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// normalizeDocument converts a document into its canonical representation, a
+// bson.D with nested documents represented as bson.D and arrays as bson.A,
+// by round tripping it through bson. This ensures that the values we compare
+// have the same types that mongodb would see.
+func normalizeDocument(doc interface{}) (bson.D, error) {
+	if doc == nil {
+		return bson.D{}, nil
+	}
+
+	// Treat typed nils, such as a nil bson.D or bson.M, as an empty document
+	switch v := reflect.ValueOf(doc); v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Pointer:
+		if v.IsNil() {
+			return bson.D{}, nil
+		}
+	}
+
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal document to bson: %w", err)
+	}
+
+	var result bson.D
+	if err = bson.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("could not unmarshal document from bson: %w", err)
+	}
+	return result, nil
+}
+
+// copyDocument makes a deep copy of a document so that callers can't modify
+// the stored data.
+func copyDocument(doc bson.D) bson.D {
+	result := make(bson.D, len(doc))
+	for i, elem := range doc {
+		result[i] = bson.E{Key: elem.Key, Value: copyValue(elem.Value)}
+	}
+	return result
+}
+
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.D:
+		return copyDocument(v)
+	case bson.A:
+		result := make(bson.A, len(v))
+		for i, item := range v {
+			result[i] = copyValue(item)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// getField returns the value of a top-level field in a document.
+func getField(doc bson.D, key string) (interface{}, bool) {
+	for _, elem := range doc {
+		if elem.Key == key {
+			return elem.Value, true
+		}
+	}
+	return nil, false
+}
+
+// setField sets the value of a top-level field in a document, appending the
+// field when it isn't already defined.
+func setField(doc bson.D, key string, value interface{}) bson.D {
+	for i, elem := range doc {
+		if elem.Key == key {
+			doc[i].Value = value
+			return doc
+		}
+	}
+	return append(doc, bson.E{Key: key, Value: value})
+}
+
+// removeField removes a top-level field from a document.
+func removeField(doc bson.D, key string) bson.D {
+	for i, elem := range doc {
+		if elem.Key == key {
+			return append(doc[:i], doc[i+1:]...)
+		}
+	}
+	return doc
+}
+
+// lookupPath finds the candidate values for a dotted path in a document.
+// Following mongodb semantics, when the path traverses an array, each element
+// of the array is considered. Arrays found at the end of the path are returned
+// along with each of their elements so that a filter may match either the
+// entire array or one of its items. The boolean return value indicates if
+// the path was found at all.
+func lookupPath(doc bson.D, path string) ([]interface{}, bool) {
+	return lookupPathParts(doc, strings.Split(path, "."))
+}
+
+func lookupPathParts(value interface{}, parts []string) ([]interface{}, bool) {
+	if len(parts) == 0 {
+		if arr, ok := value.(bson.A); ok {
+			return append([]interface{}{arr}, arr...), true
+		}
+		return []interface{}{value}, true
+	}
+
+	switch v := value.(type) {
+	case bson.D:
+		fieldValue, ok := getField(v, parts[0])
+		if !ok {
+			return nil, false
+		}
+		return lookupPathParts(fieldValue, parts[1:])
+	case bson.A:
+		var results []interface{}
+		found := false
+
+		// Support referencing an array element by its index, e.g. items.0.name
+		var index int
+		if _, err := fmt.Sscanf(parts[0], "%d", &index); err == nil && fmt.Sprint(index) == parts[0] {
+			if index >= 0 && index < len(v) {
+				if items, ok := lookupPathParts(v[index], parts[1:]); ok {
+					results = append(results, items...)
+					found = true
+				}
+			}
+		}
+
+		for _, item := range v {
+			if _, isDoc := item.(bson.D); !isDoc {
+				continue
+			}
+			if items, ok := lookupPathParts(item, parts); ok {
+				results = append(results, items...)
+				found = true
+			}
+		}
+		return results, found
+	default:
+		return nil, false
+	}
+}
+
+// getPathValue returns the value stored at a dotted path without expanding arrays.
+func getPathValue(doc bson.D, path string) (interface{}, bool) {
+	var current interface{} = doc
+	for _, part := range strings.Split(path, ".") {
+		d, ok := current.(bson.D)
+		if !ok {
+			return nil, false
+		}
+		current, ok = getField(d, part)
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setPathValue sets the value at a dotted path, creating intermediate
+// documents as necessary.
+func setPathValue(doc bson.D, path string, value interface{}) (bson.D, error) {
+	parts := strings.SplitN(path, ".", 2)
+	if len(parts) == 1 {
+		return setField(doc, path, value), nil
+	}
+
+	child, ok := getField(doc, parts[0])
+	if !ok || child == nil {
+		child = bson.D{}
+	}
+	childDoc, ok := child.(bson.D)
+	if !ok {
+		return nil, fmt.Errorf("Cannot create field '%s' in element {%s: %v}", parts[1], parts[0], child)
+	}
+
+	childDoc, err := setPathValue(childDoc, parts[1], value)
+	if err != nil {
+		return nil, err
+	}
+	return setField(doc, parts[0], childDoc), nil
+}
+
+// unsetPathValue removes the value at a dotted path.
+func unsetPathValue(doc bson.D, path string) bson.D {
+	parts := strings.SplitN(path, ".", 2)
+	if len(parts) == 1 {
+		return removeField(doc, path)
+	}
+
+	child, ok := getField(doc, parts[0])
+	if !ok {
+		return doc
+	}
+	childDoc, ok := child.(bson.D)
+	if !ok {
+		return doc
+	}
+	return setField(doc, parts[0], unsetPathValue(childDoc, parts[1]))
+}
+
+// typeRank orders bson types according to the mongodb comparison order.
+// See https://www.mongodb.com/docs/manual/reference/bson-type-comparison-order/
+func typeRank(value interface{}) int {
+	switch value.(type) {
+	case primitive.MinKey:
+		return 0
+	case nil, primitive.Null, primitive.Undefined:
+		return 1
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, primitive.Decimal128:
+		return 2
+	case string, primitive.Symbol:
+		return 3
+	case bson.D, bson.M, map[string]interface{}:
+		return 4
+	case bson.A, []interface{}:
+		return 5
+	case primitive.Binary, []byte:
+		return 6
+	case primitive.ObjectID:
+		return 7
+	case bool:
+		return 8
+	case primitive.DateTime, time.Time:
+		return 9
+	case primitive.Timestamp:
+		return 10
+	case primitive.Regex:
+		return 11
+	case primitive.MaxKey:
+		return 100
+	default:
+		return 50
+	}
+}
+
+// compareValues compares two values using the mongodb comparison order,
+// returning -1, 0, or 1.
+func compareValues(a interface{}, b interface{}) int {
+	rankA, rankB := typeRank(a), typeRank(b)
+	if rankA != rankB {
+		return compareInts(int64(rankA), int64(rankB))
+	}
+
+	switch rankA {
+	case 2:
+		return compareFloats(toFloat(a), toFloat(b))
+	case 3:
+		return strings.Compare(toString(a), toString(b))
+	case 4:
+		docA, docB := toDocument(a), toDocument(b)
+		for i := 0; i < len(docA) && i < len(docB); i++ {
+			if c := strings.Compare(docA[i].Key, docB[i].Key); c != 0 {
+				return c
+			}
+			if c := compareValues(docA[i].Value, docB[i].Value); c != 0 {
+				return c
+			}
+		}
+		return compareInts(int64(len(docA)), int64(len(docB)))
+	case 5:
+		arrA, arrB := toArray(a), toArray(b)
+		for i := 0; i < len(arrA) && i < len(arrB); i++ {
+			if c := compareValues(arrA[i], arrB[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(int64(len(arrA)), int64(len(arrB)))
+	case 6:
+		return bytes.Compare(toBytes(a), toBytes(b))
+	case 7:
+		idA, idB := a.(primitive.ObjectID), b.(primitive.ObjectID)
+		return bytes.Compare(idA[:], idB[:])
+	case 8:
+		boolA, boolB := a.(bool), b.(bool)
+		if boolA == boolB {
+			return 0
+		} else if !boolA {
+			return -1
+		}
+		return 1
+	case 9:
+		return compareInts(toTime(a).UnixNano(), toTime(b).UnixNano())
+	case 10:
+		tsA, tsB := a.(primitive.Timestamp), b.(primitive.Timestamp)
+		return primitive.CompareTimestamp(tsA, tsB)
+	case 11:
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	default:
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+}
+
+func compareInts(a int64, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareFloats(a float64, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	case primitive.Decimal128:
+		var f float64
+		fmt.Sscanf(v.String(), "%g", &f)
+		return f
+	default:
+		return 0
+	}
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case primitive.Symbol:
+		return string(v)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func toDocument(value interface{}) bson.D {
+	switch v := value.(type) {
+	case bson.D:
+		return v
+	default:
+		doc, _ := normalizeDocument(v)
+		return doc
+	}
+}
+
+func toArray(value interface{}) bson.A {
+	switch v := value.(type) {
+	case bson.A:
+		return v
+	case []interface{}:
+		return bson.A(v)
+	default:
+		return nil
+	}
+}
+
+func toBytes(value interface{}) []byte {
+	switch v := value.(type) {
+	case primitive.Binary:
+		return v.Data
+	case []byte:
+		return v
+	default:
+		return nil
+	}
+}
+
+func toTime(value interface{}) time.Time {
+	switch v := value.(type) {
+	case primitive.DateTime:
+		return v.Time()
+	case time.Time:
+		return v
+	default:
+		return time.Time{}
+	}
+}
+
+// isTruthy determines if a value is considered true in a projection or
+// sort specification.
+func isTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	default:
+		if typeRank(v) == 2 {
+			return toFloat(v) != 0
+		}
+		return true
+	}
+}
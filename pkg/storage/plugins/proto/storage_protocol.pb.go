@@ -958,6 +958,120 @@ func (*UpdateResponse) Descriptor() ([]byte, []int) {
 	return file_pkg_storage_plugins_proto_storage_protocol_proto_rawDescGZIP(), []int{17}
 }
 
+// DocumentChunk is a portion of a bson encoded document that is streamed
+// between porter and the plugin.
+type DocumentChunk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data []byte `protobuf:"bytes,1,opt,name=Data,proto3" json:"Data,omitempty"`
+	// EndOfDocument is set on the last chunk of a document.
+	EndOfDocument bool `protobuf:"varint,2,opt,name=EndOfDocument,proto3" json:"EndOfDocument,omitempty"`
+}
+
+func (x *DocumentChunk) Reset() {
+	*x = DocumentChunk{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_storage_plugins_proto_storage_protocol_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DocumentChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DocumentChunk) ProtoMessage() {}
+
+func (x *DocumentChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_storage_plugins_proto_storage_protocol_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DocumentChunk.ProtoReflect.Descriptor instead.
+func (*DocumentChunk) Descriptor() ([]byte, []int) {
+	return file_pkg_storage_plugins_proto_storage_protocol_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DocumentChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *DocumentChunk) GetEndOfDocument() bool {
+	if x != nil {
+		return x.EndOfDocument
+	}
+	return false
+}
+
+// InsertStreamRequest is a chunk of a document to insert into a collection.
+type InsertStreamRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Collection string         `protobuf:"bytes,1,opt,name=Collection,proto3" json:"Collection,omitempty"`
+	Chunk      *DocumentChunk `protobuf:"bytes,2,opt,name=Chunk,proto3" json:"Chunk,omitempty"`
+}
+
+func (x *InsertStreamRequest) Reset() {
+	*x = InsertStreamRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_storage_plugins_proto_storage_protocol_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InsertStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InsertStreamRequest) ProtoMessage() {}
+
+func (x *InsertStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_storage_plugins_proto_storage_protocol_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InsertStreamRequest.ProtoReflect.Descriptor instead.
+func (*InsertStreamRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_storage_plugins_proto_storage_protocol_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *InsertStreamRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *InsertStreamRequest) GetChunk() *DocumentChunk {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
 var File_pkg_storage_plugins_proto_storage_protocol_proto protoreflect.FileDescriptor
 
 var file_pkg_storage_plugins_proto_storage_protocol_proto_rawDesc = []byte{
@@ -1057,7 +1171,18 @@ var file_pkg_storage_plugins_proto_storage_protocol_proto_rawDesc = []byte{
 	0x0f, 0x0a, 0x0d, 0x50, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
 	0x22, 0x10, 0x0a, 0x0e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
 	0x73, 0x65, 0x22, 0x10, 0x0a, 0x0e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x32, 0xf5, 0x03, 0x0a, 0x0f, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x49, 0x0a, 0x0d, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74,
+	0x43, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x44, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x04, 0x44, 0x61, 0x74, 0x61, 0x12, 0x24, 0x0a, 0x0d, 0x45, 0x6e, 0x64,
+	0x4f, 0x66, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0d, 0x45, 0x6e, 0x64, 0x4f, 0x66, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x22,
+	0x63, 0x0a, 0x13, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x43, 0x6f, 0x6c, 0x6c,
+	0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2c, 0x0a, 0x05, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e,
+	0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x52, 0x05, 0x43,
+	0x68, 0x75, 0x6e, 0x6b, 0x32, 0xc4, 0x05, 0x0a, 0x0f, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65,
 	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x48, 0x0a, 0x0b, 0x45, 0x6e, 0x73, 0x75,
 	0x72, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1b, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e,
 	0x73, 0x2e, 0x45, 0x6e, 0x73, 0x75, 0x72, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x52, 0x65, 0x71,
@@ -1088,11 +1213,24 @@ var file_pkg_storage_plugins_proto_storage_protocol_proto_rawDesc = []byte{
 	0x65, 0x12, 0x39, 0x0a, 0x06, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x16, 0x2e, 0x70, 0x6c,
 	0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75,
 	0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x55, 0x70,
-	0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x30, 0x5a, 0x2e,
-	0x67, 0x65, 0x74, 0x2e, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x72, 0x2e, 0x73, 0x68, 0x2f, 0x70, 0x6f,
-	0x72, 0x74, 0x65, 0x72, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65,
-	0x2f, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x64, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x46, 0x0a, 0x0f,
+	0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12,
+	0x19, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67,
+	0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x6c, 0x75,
+	0x67, 0x69, 0x6e, 0x73, 0x2e, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x43, 0x68, 0x75,
+	0x6e, 0x6b, 0x30, 0x01, 0x12, 0x3c, 0x0a, 0x0a, 0x46, 0x69, 0x6e, 0x64, 0x53, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x12, 0x14, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x46, 0x69, 0x6e,
+	0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69,
+	0x6e, 0x73, 0x2e, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x43, 0x68, 0x75, 0x6e, 0x6b,
+	0x30, 0x01, 0x12, 0x47, 0x0a, 0x0c, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x74, 0x53, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x12, 0x1c, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x49, 0x6e, 0x73,
+	0x65, 0x72, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x17, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x49, 0x6e, 0x73, 0x65, 0x72,
+	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x42, 0x30, 0x5a, 0x2e, 0x67,
+	0x65, 0x74, 0x2e, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x72, 0x2e, 0x73, 0x68, 0x2f, 0x70, 0x6f, 0x72,
+	0x74, 0x65, 0x72, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2f,
+	0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1107,7 +1245,7 @@ func file_pkg_storage_plugins_proto_storage_protocol_proto_rawDescGZIP() []byte
 	return file_pkg_storage_plugins_proto_storage_protocol_proto_rawDescData
 }
 
-var file_pkg_storage_plugins_proto_storage_protocol_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_pkg_storage_plugins_proto_storage_protocol_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
 var file_pkg_storage_plugins_proto_storage_protocol_proto_goTypes = []interface{}{
 	(*EnsureIndexRequest)(nil),  // 0: plugins.EnsureIndexRequest
 	(*Index)(nil),               // 1: plugins.Index
@@ -1127,44 +1265,53 @@ var file_pkg_storage_plugins_proto_storage_protocol_proto_goTypes = []interface{
 	(*PatchResponse)(nil),       // 15: plugins.PatchResponse
 	(*RemoveResponse)(nil),      // 16: plugins.RemoveResponse
 	(*UpdateResponse)(nil),      // 17: plugins.UpdateResponse
-	(*structpb.Struct)(nil),     // 18: google.protobuf.Struct
+	(*DocumentChunk)(nil),       // 18: plugins.DocumentChunk
+	(*InsertStreamRequest)(nil), // 19: plugins.InsertStreamRequest
+	(*structpb.Struct)(nil),     // 20: google.protobuf.Struct
 }
 var file_pkg_storage_plugins_proto_storage_protocol_proto_depIdxs = []int32{
 	1,  // 0: plugins.EnsureIndexRequest.Indices:type_name -> plugins.Index
-	18, // 1: plugins.Index.Keys:type_name -> google.protobuf.Struct
+	20, // 1: plugins.Index.Keys:type_name -> google.protobuf.Struct
 	3,  // 2: plugins.AggregateRequest.Pipeline:type_name -> plugins.Stage
-	18, // 3: plugins.Stage.Steps:type_name -> google.protobuf.Struct
-	18, // 4: plugins.CountRequest.Filter:type_name -> google.protobuf.Struct
-	18, // 5: plugins.FindRequest.Sort:type_name -> google.protobuf.Struct
-	18, // 6: plugins.FindRequest.Select:type_name -> google.protobuf.Struct
-	18, // 7: plugins.FindRequest.Filter:type_name -> google.protobuf.Struct
-	18, // 8: plugins.InsertRequest.Documents:type_name -> google.protobuf.Struct
-	18, // 9: plugins.PatchRequest.QueryDocument:type_name -> google.protobuf.Struct
-	18, // 10: plugins.PatchRequest.Transformation:type_name -> google.protobuf.Struct
-	18, // 11: plugins.RemoveRequest.Filter:type_name -> google.protobuf.Struct
-	18, // 12: plugins.UpdateRequest.Filter:type_name -> google.protobuf.Struct
-	18, // 13: plugins.UpdateRequest.Document:type_name -> google.protobuf.Struct
-	0,  // 14: plugins.StorageProtocol.EnsureIndex:input_type -> plugins.EnsureIndexRequest
-	2,  // 15: plugins.StorageProtocol.Aggregate:input_type -> plugins.AggregateRequest
-	4,  // 16: plugins.StorageProtocol.Count:input_type -> plugins.CountRequest
-	5,  // 17: plugins.StorageProtocol.Find:input_type -> plugins.FindRequest
-	6,  // 18: plugins.StorageProtocol.Insert:input_type -> plugins.InsertRequest
-	7,  // 19: plugins.StorageProtocol.Patch:input_type -> plugins.PatchRequest
-	8,  // 20: plugins.StorageProtocol.Remove:input_type -> plugins.RemoveRequest
-	9,  // 21: plugins.StorageProtocol.Update:input_type -> plugins.UpdateRequest
-	10, // 22: plugins.StorageProtocol.EnsureIndex:output_type -> plugins.EnsureIndexResponse
-	11, // 23: plugins.StorageProtocol.Aggregate:output_type -> plugins.AggregateResponse
-	12, // 24: plugins.StorageProtocol.Count:output_type -> plugins.CountResponse
-	13, // 25: plugins.StorageProtocol.Find:output_type -> plugins.FindResponse
-	14, // 26: plugins.StorageProtocol.Insert:output_type -> plugins.InsertResponse
-	15, // 27: plugins.StorageProtocol.Patch:output_type -> plugins.PatchResponse
-	16, // 28: plugins.StorageProtocol.Remove:output_type -> plugins.RemoveResponse
-	17, // 29: plugins.StorageProtocol.Update:output_type -> plugins.UpdateResponse
-	22, // [22:30] is the sub-list for method output_type
-	14, // [14:22] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	20, // 3: plugins.Stage.Steps:type_name -> google.protobuf.Struct
+	20, // 4: plugins.CountRequest.Filter:type_name -> google.protobuf.Struct
+	20, // 5: plugins.FindRequest.Sort:type_name -> google.protobuf.Struct
+	20, // 6: plugins.FindRequest.Select:type_name -> google.protobuf.Struct
+	20, // 7: plugins.FindRequest.Filter:type_name -> google.protobuf.Struct
+	20, // 8: plugins.InsertRequest.Documents:type_name -> google.protobuf.Struct
+	20, // 9: plugins.PatchRequest.QueryDocument:type_name -> google.protobuf.Struct
+	20, // 10: plugins.PatchRequest.Transformation:type_name -> google.protobuf.Struct
+	20, // 11: plugins.RemoveRequest.Filter:type_name -> google.protobuf.Struct
+	20, // 12: plugins.UpdateRequest.Filter:type_name -> google.protobuf.Struct
+	20, // 13: plugins.UpdateRequest.Document:type_name -> google.protobuf.Struct
+	18, // 14: plugins.InsertStreamRequest.Chunk:type_name -> plugins.DocumentChunk
+	0,  // 15: plugins.StorageProtocol.EnsureIndex:input_type -> plugins.EnsureIndexRequest
+	2,  // 16: plugins.StorageProtocol.Aggregate:input_type -> plugins.AggregateRequest
+	4,  // 17: plugins.StorageProtocol.Count:input_type -> plugins.CountRequest
+	5,  // 18: plugins.StorageProtocol.Find:input_type -> plugins.FindRequest
+	6,  // 19: plugins.StorageProtocol.Insert:input_type -> plugins.InsertRequest
+	7,  // 20: plugins.StorageProtocol.Patch:input_type -> plugins.PatchRequest
+	8,  // 21: plugins.StorageProtocol.Remove:input_type -> plugins.RemoveRequest
+	9,  // 22: plugins.StorageProtocol.Update:input_type -> plugins.UpdateRequest
+	2,  // 23: plugins.StorageProtocol.AggregateStream:input_type -> plugins.AggregateRequest
+	5,  // 24: plugins.StorageProtocol.FindStream:input_type -> plugins.FindRequest
+	19, // 25: plugins.StorageProtocol.InsertStream:input_type -> plugins.InsertStreamRequest
+	10, // 26: plugins.StorageProtocol.EnsureIndex:output_type -> plugins.EnsureIndexResponse
+	11, // 27: plugins.StorageProtocol.Aggregate:output_type -> plugins.AggregateResponse
+	12, // 28: plugins.StorageProtocol.Count:output_type -> plugins.CountResponse
+	13, // 29: plugins.StorageProtocol.Find:output_type -> plugins.FindResponse
+	14, // 30: plugins.StorageProtocol.Insert:output_type -> plugins.InsertResponse
+	15, // 31: plugins.StorageProtocol.Patch:output_type -> plugins.PatchResponse
+	16, // 32: plugins.StorageProtocol.Remove:output_type -> plugins.RemoveResponse
+	17, // 33: plugins.StorageProtocol.Update:output_type -> plugins.UpdateResponse
+	18, // 34: plugins.StorageProtocol.AggregateStream:output_type -> plugins.DocumentChunk
+	18, // 35: plugins.StorageProtocol.FindStream:output_type -> plugins.DocumentChunk
+	14, // 36: plugins.StorageProtocol.InsertStream:output_type -> plugins.InsertResponse
+	26, // [26:37] is the sub-list for method output_type
+	15, // [15:26] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_pkg_storage_plugins_proto_storage_protocol_proto_init() }
@@ -1389,6 +1536,30 @@ func file_pkg_storage_plugins_proto_storage_protocol_proto_init() {
 				return nil
 			}
 		}
+		file_pkg_storage_plugins_proto_storage_protocol_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DocumentChunk); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_storage_plugins_proto_storage_protocol_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InsertStreamRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1396,7 +1567,7 @@ func file_pkg_storage_plugins_proto_storage_protocol_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_storage_plugins_proto_storage_protocol_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   18,
+			NumMessages:   20,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
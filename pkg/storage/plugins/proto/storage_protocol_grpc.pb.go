@@ -30,6 +30,10 @@ type StorageProtocolClient interface {
 	Patch(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*PatchResponse, error)
 	Remove(ctx context.Context, in *RemoveRequest, opts ...grpc.CallOption) (*RemoveResponse, error)
 	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
+	// The streaming methods below were added in protocol version 4.
+	AggregateStream(ctx context.Context, in *AggregateRequest, opts ...grpc.CallOption) (StorageProtocol_AggregateStreamClient, error)
+	FindStream(ctx context.Context, in *FindRequest, opts ...grpc.CallOption) (StorageProtocol_FindStreamClient, error)
+	InsertStream(ctx context.Context, opts ...grpc.CallOption) (StorageProtocol_InsertStreamClient, error)
 }
 
 type storageProtocolClient struct {
@@ -112,6 +116,104 @@ func (c *storageProtocolClient) Update(ctx context.Context, in *UpdateRequest, o
 	return out, nil
 }
 
+func (c *storageProtocolClient) AggregateStream(ctx context.Context, in *AggregateRequest, opts ...grpc.CallOption) (StorageProtocol_AggregateStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StorageProtocol_ServiceDesc.Streams[0], "/plugins.StorageProtocol/AggregateStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &storageProtocolAggregateStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StorageProtocol_AggregateStreamClient interface {
+	Recv() (*DocumentChunk, error)
+	grpc.ClientStream
+}
+
+type storageProtocolAggregateStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *storageProtocolAggregateStreamClient) Recv() (*DocumentChunk, error) {
+	m := new(DocumentChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *storageProtocolClient) FindStream(ctx context.Context, in *FindRequest, opts ...grpc.CallOption) (StorageProtocol_FindStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StorageProtocol_ServiceDesc.Streams[1], "/plugins.StorageProtocol/FindStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &storageProtocolFindStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StorageProtocol_FindStreamClient interface {
+	Recv() (*DocumentChunk, error)
+	grpc.ClientStream
+}
+
+type storageProtocolFindStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *storageProtocolFindStreamClient) Recv() (*DocumentChunk, error) {
+	m := new(DocumentChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *storageProtocolClient) InsertStream(ctx context.Context, opts ...grpc.CallOption) (StorageProtocol_InsertStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StorageProtocol_ServiceDesc.Streams[2], "/plugins.StorageProtocol/InsertStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &storageProtocolInsertStreamClient{stream}
+	return x, nil
+}
+
+type StorageProtocol_InsertStreamClient interface {
+	Send(*InsertStreamRequest) error
+	CloseAndRecv() (*InsertResponse, error)
+	grpc.ClientStream
+}
+
+type storageProtocolInsertStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *storageProtocolInsertStreamClient) Send(m *InsertStreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *storageProtocolInsertStreamClient) CloseAndRecv() (*InsertResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(InsertResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // StorageProtocolServer is the server API for StorageProtocol service.
 // All implementations must embed UnimplementedStorageProtocolServer
 // for forward compatibility
@@ -124,6 +226,10 @@ type StorageProtocolServer interface {
 	Patch(context.Context, *PatchRequest) (*PatchResponse, error)
 	Remove(context.Context, *RemoveRequest) (*RemoveResponse, error)
 	Update(context.Context, *UpdateRequest) (*UpdateResponse, error)
+	// The streaming methods below were added in protocol version 4.
+	AggregateStream(*AggregateRequest, StorageProtocol_AggregateStreamServer) error
+	FindStream(*FindRequest, StorageProtocol_FindStreamServer) error
+	InsertStream(StorageProtocol_InsertStreamServer) error
 	mustEmbedUnimplementedStorageProtocolServer()
 }
 
@@ -155,6 +261,15 @@ func (UnimplementedStorageProtocolServer) Remove(context.Context, *RemoveRequest
 func (UnimplementedStorageProtocolServer) Update(context.Context, *UpdateRequest) (*UpdateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
 }
+func (UnimplementedStorageProtocolServer) AggregateStream(*AggregateRequest, StorageProtocol_AggregateStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method AggregateStream not implemented")
+}
+func (UnimplementedStorageProtocolServer) FindStream(*FindRequest, StorageProtocol_FindStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method FindStream not implemented")
+}
+func (UnimplementedStorageProtocolServer) InsertStream(StorageProtocol_InsertStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method InsertStream not implemented")
+}
 func (UnimplementedStorageProtocolServer) mustEmbedUnimplementedStorageProtocolServer() {}
 
 // UnsafeStorageProtocolServer may be embedded to opt out of forward compatibility for this service.
@@ -312,6 +427,74 @@ func _StorageProtocol_Update_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StorageProtocol_AggregateStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AggregateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StorageProtocolServer).AggregateStream(m, &storageProtocolAggregateStreamServer{stream})
+}
+
+type StorageProtocol_AggregateStreamServer interface {
+	Send(*DocumentChunk) error
+	grpc.ServerStream
+}
+
+type storageProtocolAggregateStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *storageProtocolAggregateStreamServer) Send(m *DocumentChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _StorageProtocol_FindStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FindRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StorageProtocolServer).FindStream(m, &storageProtocolFindStreamServer{stream})
+}
+
+type StorageProtocol_FindStreamServer interface {
+	Send(*DocumentChunk) error
+	grpc.ServerStream
+}
+
+type storageProtocolFindStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *storageProtocolFindStreamServer) Send(m *DocumentChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _StorageProtocol_InsertStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(StorageProtocolServer).InsertStream(&storageProtocolInsertStreamServer{stream})
+}
+
+type StorageProtocol_InsertStreamServer interface {
+	SendAndClose(*InsertResponse) error
+	Recv() (*InsertStreamRequest, error)
+	grpc.ServerStream
+}
+
+type storageProtocolInsertStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *storageProtocolInsertStreamServer) SendAndClose(m *InsertResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *storageProtocolInsertStreamServer) Recv() (*InsertStreamRequest, error) {
+	m := new(InsertStreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // StorageProtocol_ServiceDesc is the grpc.ServiceDesc for StorageProtocol service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -352,6 +535,22 @@ var StorageProtocol_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _StorageProtocol_Update_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "AggregateStream",
+			Handler:       _StorageProtocol_AggregateStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "FindStream",
+			Handler:       _StorageProtocol_FindStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "InsertStream",
+			Handler:       _StorageProtocol_InsertStream_Handler,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "pkg/storage/plugins/proto/storage_protocol.proto",
 }
@@ -7,5 +7,15 @@ const (
 	PluginInterface = "storage"
 
 	// PluginProtocolVersion is the currently supported plugin protocol version for storage.
-	PluginProtocolVersion = 3
+	PluginProtocolVersion = StreamingPluginProtocolVersion
+
+	// StreamingPluginProtocolVersion is the first protocol version that streams
+	// documents between porter and the plugin, so that large documents and
+	// query results are not limited by the maximum size of a gRPC message.
+	StreamingPluginProtocolVersion = 4
+
+	// MinPluginProtocolVersion is the oldest protocol version that porter
+	// negotiates with a storage plugin. Plugins that only support this version
+	// use unary calls for every operation.
+	MinPluginProtocolVersion = 3
 )
@@ -3,9 +3,13 @@ package testplugin
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"get.porter.sh/porter/pkg/portercontext"
 	"get.porter.sh/porter/pkg/storage/plugins"
+	"get.porter.sh/porter/pkg/storage/plugins/embedded"
+	"get.porter.sh/porter/pkg/storage/plugins/inmemory"
 	"get.porter.sh/porter/pkg/storage/plugins/mongodb"
 	"get.porter.sh/porter/pkg/storage/plugins/mongodb_docker"
 	"get.porter.sh/porter/tests"
@@ -17,10 +21,27 @@ var (
 	_ plugins.StorageProtocol = &TestStoragePlugin{}
 )
 
-// TestStoragePlugin is a test helper that implements a storage plugin backed by a
-// mongodb instance that saves data to a temporary directory.
+// UseMongoDBEnvVar is the environment variable that, when set to true, runs
+// tests against mongodb instead of the in-memory storage emulator.
+const UseMongoDBEnvVar = "PORTER_TEST_MONGODB"
+
+// UseEmbeddedEnvVar is the environment variable that, when set to true, runs
+// tests against the embedded storage plugin, saving data to a temporary file.
+const UseEmbeddedEnvVar = "PORTER_TEST_EMBEDDED"
+
+// testStore is the set of operations that the test plugin needs from its backing store.
+type testStore interface {
+	plugins.StorageProtocol
+	RemoveDatabase(ctx context.Context) error
+	Close() error
+}
+
+// TestStoragePlugin is a test helper that implements a storage plugin backed by an
+// in-memory emulator of mongodb. When PORTER_TEST_MONGODB=true, it is backed by a
+// mongodb instance that saves data to a temporary directory instead, and when
+// PORTER_TEST_EMBEDDED=true, by the embedded plugin saving to a temporary file.
 type TestStoragePlugin struct {
-	store    *mongodb.Store
+	store    testStore
 	tc       *portercontext.TestContext
 	database string
 }
@@ -37,6 +58,16 @@ func (s *TestStoragePlugin) Connect(ctx context.Context) error {
 		return nil
 	}
 
+	if os.Getenv(UseEmbeddedEnvVar) == "true" {
+		s.store = embedded.NewStore(embedded.PluginConfig{Path: filepath.Join(s.tc.T.TempDir(), embedded.DefaultDatabaseFile)})
+		return nil
+	}
+
+	if os.Getenv(UseMongoDBEnvVar) != "true" {
+		s.store = inmemory.NewStore()
+		return nil
+	}
+
 	s.database = tests.GenerateDatabaseName(s.tc.T.Name())
 
 	// Try to connect to a dev instance of mongo, otherwise run a one off mongo instance
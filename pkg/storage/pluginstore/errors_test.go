@@ -0,0 +1,51 @@
+package pluginstore
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatusError_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+		want     error
+	}{
+		{name: "not found", err: fmt.Errorf("collection missing: %w", plugins.ErrNotFound), wantCode: codes.NotFound, want: plugins.ErrNotFound},
+		{name: "duplicate key", err: mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "E11000 duplicate key error"}}}, wantCode: codes.AlreadyExists, want: plugins.ErrConflict},
+		{name: "unauthorized", err: fmt.Errorf("bad password: %w", plugins.ErrUnauthorized), wantCode: codes.PermissionDenied, want: plugins.ErrUnauthorized},
+		{name: "unavailable", err: fmt.Errorf("server down: %w", plugins.ErrUnavailable), wantCode: codes.Unavailable, want: plugins.ErrUnavailable},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			statusErr := toStatusError(tc.err)
+			assert.Equal(t, tc.wantCode, status.Code(statusErr))
+
+			err := fromStatusError(statusErr)
+			assert.ErrorIs(t, err, tc.want)
+			assert.Contains(t, err.Error(), tc.err.Error(), "the message from the plugin should be kept")
+		})
+	}
+
+	t.Run("unclassified", func(t *testing.T) {
+		err := errors.New("oops")
+		assert.Equal(t, err, toStatusError(err))
+		assert.Equal(t, err, fromStatusError(err))
+		assert.Nil(t, toStatusError(nil))
+		assert.Nil(t, fromStatusError(nil))
+	})
+}
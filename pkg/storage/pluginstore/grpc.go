@@ -15,10 +15,21 @@ var _ plugins.StorageProtocol = &GClient{}
 // GClient is a gRPC implementation of the storage client.
 type GClient struct {
 	client proto.StorageProtocolClient
+
+	// streaming indicates that the plugin supports streaming documents,
+	// which was added in protocol version 4.
+	streaming bool
 }
 
+// NewClient creates a client for a plugin that only supports unary calls.
 func NewClient(client proto.StorageProtocolClient) *GClient {
-	return &GClient{client}
+	return &GClient{client: client}
+}
+
+// NewStreamingClient creates a client that streams documents to and from the
+// plugin.
+func NewStreamingClient(client proto.StorageProtocolClient) *GClient {
+	return &GClient{client: client, streaming: true}
 }
 
 func (m *GClient) EnsureIndex(ctx context.Context, opts plugins.EnsureIndexOptions) error {
@@ -35,7 +46,7 @@ func (m *GClient) EnsureIndex(ctx context.Context, opts plugins.EnsureIndexOptio
 	}
 
 	_, err := m.client.EnsureIndex(ctx, req)
-	return err
+	return fromStatusError(err)
 }
 
 func (m *GClient) Aggregate(ctx context.Context, opts plugins.AggregateOptions) ([]bson.Raw, error) {
@@ -43,9 +54,13 @@ func (m *GClient) Aggregate(ctx context.Context, opts plugins.AggregateOptions)
 		Collection: opts.Collection,
 		Pipeline:   NewPipeline(opts.Pipeline),
 	}
+	if m.streaming {
+		return m.aggregateStream(ctx, req)
+	}
+
 	resp, err := m.client.Aggregate(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, fromStatusError(err)
 	}
 
 	results := convertToBsonRawList(resp.Results)
@@ -58,7 +73,7 @@ func (m *GClient) Count(ctx context.Context, opts plugins.CountOptions) (int64,
 		Filter:     FromMap(opts.Filter),
 	})
 	if err != nil {
-		return 0, err
+		return 0, fromStatusError(err)
 	}
 	return resp.Count, nil
 }
@@ -72,9 +87,13 @@ func (m *GClient) Find(ctx context.Context, opts plugins.FindOptions) ([]bson.Ra
 		Select:     FromOrderedMap(opts.Select),
 		Filter:     FromMap(opts.Filter),
 	}
+	if m.streaming {
+		return m.findStream(ctx, req)
+	}
+
 	resp, err := m.client.Find(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, fromStatusError(err)
 	}
 
 	results := convertToBsonRawList(resp.Results)
@@ -82,12 +101,16 @@ func (m *GClient) Find(ctx context.Context, opts plugins.FindOptions) ([]bson.Ra
 }
 
 func (m *GClient) Insert(ctx context.Context, opts plugins.InsertOptions) error {
+	if m.streaming {
+		return m.insertStream(ctx, opts)
+	}
+
 	req := &proto.InsertRequest{
 		Collection: opts.Collection,
 		Documents:  FromMapList(opts.Documents),
 	}
 	_, err := m.client.Insert(ctx, req)
-	return err
+	return fromStatusError(err)
 }
 
 func (m *GClient) Patch(ctx context.Context, opts plugins.PatchOptions) error {
@@ -97,7 +120,7 @@ func (m *GClient) Patch(ctx context.Context, opts plugins.PatchOptions) error {
 		Transformation: FromOrderedMap(opts.Transformation),
 	}
 	_, err := m.client.Patch(ctx, req)
-	return err
+	return fromStatusError(err)
 }
 
 func (m *GClient) Remove(ctx context.Context, opts plugins.RemoveOptions) error {
@@ -107,7 +130,7 @@ func (m *GClient) Remove(ctx context.Context, opts plugins.RemoveOptions) error
 		All:        opts.All,
 	}
 	_, err := m.client.Remove(ctx, req)
-	return err
+	return fromStatusError(err)
 }
 
 func (m *GClient) Update(ctx context.Context, opts plugins.UpdateOptions) error {
@@ -118,7 +141,7 @@ func (m *GClient) Update(ctx context.Context, opts plugins.UpdateOptions) error
 		Document:   FromMap(opts.Document),
 	}
 	_, err := m.client.Update(ctx, req)
-	return err
+	return fromStatusError(err)
 }
 
 // GServer is a gRPC wrapper around a StorageProtocol plugin
@@ -146,7 +169,7 @@ func (m *GServer) EnsureIndex(ctx context.Context, request *proto.EnsureIndexReq
 	}
 
 	err := m.impl.EnsureIndex(ctx, opts)
-	return &proto.EnsureIndexResponse{}, err
+	return &proto.EnsureIndexResponse{}, toStatusError(err)
 }
 
 func (m *GServer) Aggregate(ctx context.Context, request *proto.AggregateRequest) (*proto.AggregateResponse, error) {
@@ -160,7 +183,7 @@ func (m *GServer) Aggregate(ctx context.Context, request *proto.AggregateRequest
 	for i := range results {
 		resp.Results[i] = results[i]
 	}
-	return resp, err
+	return resp, toStatusError(err)
 }
 
 func (m *GServer) Count(ctx context.Context, req *proto.CountRequest) (*proto.CountResponse, error) {
@@ -169,7 +192,7 @@ func (m *GServer) Count(ctx context.Context, req *proto.CountRequest) (*proto.Co
 		Filter:     AsMap(req.Filter),
 	}
 	count, err := m.impl.Count(ctx, opts)
-	return &proto.CountResponse{Count: count}, err
+	return &proto.CountResponse{Count: count}, toStatusError(err)
 }
 
 func (m *GServer) Find(ctx context.Context, request *proto.FindRequest) (*proto.FindResponse, error) {
@@ -188,7 +211,7 @@ func (m *GServer) Find(ctx context.Context, request *proto.FindRequest) (*proto.
 		resp.Results[i] = results[i]
 	}
 
-	return resp, err
+	return resp, toStatusError(err)
 }
 
 func (m *GServer) Insert(ctx context.Context, request *proto.InsertRequest) (*proto.InsertResponse, error) {
@@ -198,7 +221,7 @@ func (m *GServer) Insert(ctx context.Context, request *proto.InsertRequest) (*pr
 	}
 
 	err := m.impl.Insert(ctx, opts)
-	return &proto.InsertResponse{}, err
+	return &proto.InsertResponse{}, toStatusError(err)
 }
 
 func (m *GServer) Patch(ctx context.Context, request *proto.PatchRequest) (*proto.PatchResponse, error) {
@@ -209,7 +232,7 @@ func (m *GServer) Patch(ctx context.Context, request *proto.PatchRequest) (*prot
 	}
 
 	err := m.impl.Patch(ctx, opts)
-	return &proto.PatchResponse{}, err
+	return &proto.PatchResponse{}, toStatusError(err)
 }
 
 func (m *GServer) Remove(ctx context.Context, request *proto.RemoveRequest) (*proto.RemoveResponse, error) {
@@ -220,7 +243,7 @@ func (m *GServer) Remove(ctx context.Context, request *proto.RemoveRequest) (*pr
 	}
 
 	err := m.impl.Remove(ctx, opts)
-	return &proto.RemoveResponse{}, err
+	return &proto.RemoveResponse{}, toStatusError(err)
 }
 
 func (m *GServer) Update(ctx context.Context, request *proto.UpdateRequest) (*proto.UpdateResponse, error) {
@@ -232,7 +255,7 @@ func (m *GServer) Update(ctx context.Context, request *proto.UpdateRequest) (*pr
 	}
 
 	err := m.impl.Update(ctx, opts)
-	return &proto.UpdateResponse{}, err
+	return &proto.UpdateResponse{}, toStatusError(err)
 }
 
 func NewPipeline(src []bson.D) []*proto.Stage {
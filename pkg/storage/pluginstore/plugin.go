@@ -17,6 +17,10 @@ type Plugin struct {
 	plugin.Plugin
 	impl    plugins.StorageProtocol
 	context *portercontext.Context
+
+	// protocolVersion negotiated with the plugin, which determines if the
+	// client can stream documents.
+	protocolVersion int
 }
 
 // NewPlugin creates an instance of a storage plugin.
@@ -34,5 +38,9 @@ func (p Plugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
 }
 
 func (p Plugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, conn *grpc.ClientConn) (interface{}, error) {
-	return &GClient{client: proto.NewStorageProtocolClient(conn)}, nil
+	client := proto.NewStorageProtocolClient(conn)
+	if p.protocolVersion < plugins.StreamingPluginProtocolVersion {
+		return NewClient(client), nil
+	}
+	return NewStreamingClient(client), nil
 }
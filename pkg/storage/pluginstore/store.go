@@ -6,9 +6,11 @@ import (
 	"io"
 
 	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/failure"
 	"get.porter.sh/porter/pkg/plugins/pluggable"
 	"get.porter.sh/porter/pkg/storage/plugins"
 	"get.porter.sh/porter/pkg/tracing"
+	"github.com/hashicorp/go-plugin"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -24,6 +26,10 @@ type Store struct {
 	*config.Config
 	plugin plugins.StorageProtocol
 	conn   *pluggable.PluginConnection
+
+	// storageName is the name of the storage account to use instead of
+	// the default storage account.
+	storageName string
 }
 
 func NewStore(c *config.Config) *Store {
@@ -32,11 +38,24 @@ func NewStore(c *config.Config) *Store {
 	}
 }
 
+// NewStoreFor creates a store that uses the named storage account from the
+// config file, instead of the default storage account.
+func NewStoreFor(c *config.Config, storageName string) *Store {
+	return &Store{
+		Config:      c,
+		storageName: storageName,
+	}
+}
+
 // NewStoragePluginConfig for porter home storage.
 func NewStoragePluginConfig() pluggable.PluginTypeConfig {
 	return pluggable.PluginTypeConfig{
 		Interface: plugins.PluginInterface,
-		Plugin:    &Plugin{},
+		Plugin:    &Plugin{protocolVersion: plugins.PluginProtocolVersion},
+		CompatibleVersions: map[uint]plugin.Plugin{
+			// Fall back to unary calls when the plugin does not support streaming
+			plugins.MinPluginProtocolVersion: &Plugin{protocolVersion: plugins.MinPluginProtocolVersion},
+		},
 		GetDefaultPluggable: func(c *config.Config) string {
 			return c.Data.DefaultStorage
 		},
@@ -62,11 +81,16 @@ func (s *Store) Connect(ctx context.Context) error {
 	defer span.EndSpan()
 
 	pluginType := NewStoragePluginConfig()
+	if s.storageName != "" {
+		pluginType.GetDefaultPluggable = func(c *config.Config) string {
+			return s.storageName
+		}
+	}
 
 	l := pluggable.NewPluginLoader(s.Config)
 	conn, err := l.Load(ctx, pluginType)
 	if err != nil {
-		return span.Error(fmt.Errorf("could not load %s plugin: %w", pluginType.Interface, err))
+		return span.Error(failure.StorageUnavailable(fmt.Errorf("could not load %s plugin: %w", pluginType.Interface, err)))
 	}
 	s.conn = conn
 
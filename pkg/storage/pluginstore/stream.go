@@ -0,0 +1,184 @@
+package pluginstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"get.porter.sh/porter/pkg/storage/plugins/proto"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// documentChunkSize is the maximum number of bytes of a document sent in a
+// single message when streaming documents. It is well below the default
+// maximum gRPC message size of 4MB.
+const documentChunkSize = 1024 * 1024
+
+func (m *GClient) aggregateStream(ctx context.Context, req *proto.AggregateRequest) ([]bson.Raw, error) {
+	stream, err := m.client.AggregateStream(ctx, req)
+	if err != nil {
+		return nil, fromStatusError(err)
+	}
+	return receiveDocuments(stream.Recv)
+}
+
+func (m *GClient) findStream(ctx context.Context, req *proto.FindRequest) ([]bson.Raw, error) {
+	stream, err := m.client.FindStream(ctx, req)
+	if err != nil {
+		return nil, fromStatusError(err)
+	}
+	return receiveDocuments(stream.Recv)
+}
+
+func (m *GClient) insertStream(ctx context.Context, opts plugins.InsertOptions) error {
+	stream, err := m.client.InsertStream(ctx)
+	if err != nil {
+		return fromStatusError(err)
+	}
+
+	for _, doc := range opts.Documents {
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("error marshaling document into the %s collection: %w", opts.Collection, err)
+		}
+
+		err = sendDocument(data, func(chunk *proto.DocumentChunk) error {
+			return stream.Send(&proto.InsertStreamRequest{Collection: opts.Collection, Chunk: chunk})
+		})
+		if err != nil {
+			// The server closed the stream, the real error is returned when we close our side
+			if _, closeErr := stream.CloseAndRecv(); closeErr != nil {
+				return fromStatusError(closeErr)
+			}
+			return fromStatusError(err)
+		}
+	}
+
+	_, err = stream.CloseAndRecv()
+	return fromStatusError(err)
+}
+
+func (m *GServer) AggregateStream(request *proto.AggregateRequest, stream proto.StorageProtocol_AggregateStreamServer) error {
+	opts := plugins.AggregateOptions{
+		Collection: request.Collection,
+		Pipeline:   AsOrderedMapList(request.Pipeline),
+	}
+
+	results, err := m.impl.Aggregate(stream.Context(), opts)
+	if err != nil {
+		return toStatusError(err)
+	}
+	return sendDocuments(results, stream.Send)
+}
+
+func (m *GServer) FindStream(request *proto.FindRequest, stream proto.StorageProtocol_FindStreamServer) error {
+	opts := plugins.FindOptions{
+		Collection: request.Collection,
+		Sort:       AsOrderedMap(request.Sort),
+		Skip:       request.Skip,
+		Limit:      request.Limit,
+		Select:     AsOrderedMap(request.Select),
+		Filter:     AsMap(request.Filter),
+	}
+
+	results, err := m.impl.Find(stream.Context(), opts)
+	if err != nil {
+		return toStatusError(err)
+	}
+	return sendDocuments(results, stream.Send)
+}
+
+func (m *GServer) InsertStream(stream proto.StorageProtocol_InsertStreamServer) error {
+	opts := plugins.InsertOptions{}
+
+	var buf []byte
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		opts.Collection = req.Collection
+		buf = append(buf, req.Chunk.GetData()...)
+		if !req.Chunk.GetEndOfDocument() {
+			continue
+		}
+
+		var doc bson.M
+		if err = bson.Unmarshal(buf, &doc); err != nil {
+			return fmt.Errorf("error unmarshaling document into the %s collection: %w", opts.Collection, err)
+		}
+		opts.Documents = append(opts.Documents, doc)
+		buf = nil
+	}
+	if len(buf) > 0 {
+		return fmt.Errorf("the stream ended before the last document into the %s collection was complete", opts.Collection)
+	}
+
+	if err := m.impl.Insert(stream.Context(), opts); err != nil {
+		return toStatusError(err)
+	}
+	return stream.SendAndClose(&proto.InsertResponse{})
+}
+
+// sendDocuments streams each document in chunks.
+func sendDocuments(docs []bson.Raw, send func(*proto.DocumentChunk) error) error {
+	for _, doc := range docs {
+		if err := sendDocument(doc, send); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendDocument streams a document in chunks, flagging the last chunk so that
+// the receiver knows where the document ends.
+func sendDocument(doc []byte, send func(*proto.DocumentChunk) error) error {
+	for offset := 0; ; offset += documentChunkSize {
+		end := offset + documentChunkSize
+		if end > len(doc) {
+			end = len(doc)
+		}
+
+		chunk := &proto.DocumentChunk{Data: doc[offset:end], EndOfDocument: end == len(doc)}
+		if err := send(chunk); err != nil {
+			return err
+		}
+
+		if chunk.EndOfDocument {
+			return nil
+		}
+	}
+}
+
+// receiveDocuments reassembles the documents streamed in chunks until the
+// stream ends.
+func receiveDocuments(recv func() (*proto.DocumentChunk, error)) ([]bson.Raw, error) {
+	results := make([]bson.Raw, 0)
+	var buf []byte
+	for {
+		chunk, err := recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fromStatusError(err)
+		}
+
+		buf = append(buf, chunk.Data...)
+		if chunk.EndOfDocument {
+			results = append(results, buf)
+			buf = nil
+		}
+	}
+	if len(buf) > 0 {
+		return nil, errors.New("the stream ended before the last document was complete")
+	}
+
+	return results, nil
+}
@@ -0,0 +1,55 @@
+package pluginstore
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"get.porter.sh/porter/pkg/storage/plugins/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSendDocuments(t *testing.T) {
+	bigDoc := bytes.Repeat([]byte("a"), 2*documentChunkSize+10)
+	smallDoc := []byte("b")
+
+	var chunks []*proto.DocumentChunk
+	err := sendDocuments([]bson.Raw{bigDoc, smallDoc}, func(chunk *proto.DocumentChunk) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, chunks, 4, "the big document should be split into 3 chunks")
+	assert.False(t, chunks[0].EndOfDocument)
+	assert.False(t, chunks[1].EndOfDocument)
+	assert.True(t, chunks[2].EndOfDocument)
+	assert.Len(t, chunks[2].Data, 10)
+	assert.True(t, chunks[3].EndOfDocument)
+
+	i := 0
+	docs, err := receiveDocuments(func() (*proto.DocumentChunk, error) {
+		if i >= len(chunks) {
+			return nil, io.EOF
+		}
+		i++
+		return chunks[i-1], nil
+	})
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, bigDoc, []byte(docs[0]))
+	assert.Equal(t, smallDoc, []byte(docs[1]))
+}
+
+func TestReceiveDocuments_IncompleteDocument(t *testing.T) {
+	sent := false
+	_, err := receiveDocuments(func() (*proto.DocumentChunk, error) {
+		if sent {
+			return nil, io.EOF
+		}
+		sent = true
+		return &proto.DocumentChunk{Data: []byte("a")}, nil
+	})
+	require.EqualError(t, err, "the stream ended before the last document was complete")
+}
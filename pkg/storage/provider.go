@@ -14,6 +14,10 @@ type Provider interface {
 
 	// Migrate executes a migration on any/all of Porter's storage sub-systems.
 	Migrate(ctx context.Context, opts MigrateOptions) error
+
+	// DescribeStorage queries the storage backend for the collections of
+	// documents stored by Porter, their size and indexes.
+	DescribeStorage(ctx context.Context) (StorageDescription, error)
 }
 
 // MigrateOptions are the set of available options to configure a storage data migration
@@ -169,6 +169,9 @@ func (o InsertOptions) ToPluginOptions(collection string) (plugins.InsertOptions
 	if err != nil {
 		return plugins.InsertOptions{}, nil
 	}
+	for i, doc := range o.Documents {
+		addNativeFields(doc, docs[i])
+	}
 
 	return plugins.InsertOptions{
 		Collection: collection,
@@ -258,6 +261,7 @@ func (o UpdateOptions) ToPluginOptions(collection string) (plugins.UpdateOptions
 	if err != nil {
 		return plugins.UpdateOptions{}, nil
 	}
+	addNativeFields(o.Document, doc)
 
 	return plugins.UpdateOptions{
 		Collection: collection,
@@ -274,6 +278,26 @@ type Document interface {
 	DefaultDocumentFilter() map[string]interface{}
 }
 
+// nativeDocument is implemented by documents with fields that are saved using
+// a bson type that has no json representation, such as a date, so that the
+// fields can be compared by queries.
+type nativeDocument interface {
+	// nativeFields returns the fields to add to the converted document.
+	nativeFields() bson.M
+}
+
+// addNativeFields adds the native fields of a typed document to its raw
+// representation.
+func addNativeFields(in interface{}, raw map[string]interface{}) {
+	doc, ok := in.(nativeDocument)
+	if !ok || raw == nil {
+		return
+	}
+	for key, value := range doc.nativeFields() {
+		raw[key] = value
+	}
+}
+
 // converts a set of typed documents to a raw representation using maps
 // by way of the type's json representation. This ensures that any
 // json marshal logic is used when serializing documents to the database.
@@ -300,6 +324,10 @@ type ListOptions struct {
 	// Labels is used to filter result list based on a key-value pair.
 	Labels map[string]string
 
+	// LabelSelector filters the result list to documents with labels that meet
+	// all the requirements of the selector.
+	LabelSelector LabelSelector
+
 	// Skip is the number of results to skip past and exclude from the results.
 	Skip int64
 
@@ -309,7 +337,7 @@ type ListOptions struct {
 
 // ToFindOptions builds a query for a list of documents with these conditions:
 // * sorted in ascending order by namespace first and then name
-// * filtered by matching namespace, name contains substring, and labels contain all matches
+// * filtered by matching namespace, name contains substring, labels contain all matches, and labels match the selector
 // * skipped and limited to a certain number of result
 func (o ListOptions) ToFindOptions() FindOptions {
 	filter := make(map[string]interface{}, 3)
@@ -322,6 +350,7 @@ func (o ListOptions) ToFindOptions() FindOptions {
 	for k, v := range o.Labels {
 		filter["labels."+k] = v
 	}
+	o.LabelSelector.ApplyToFilter(filter)
 
 	return FindOptions{
 		Sort:   []string{"namespace", "name"},
@@ -97,3 +97,22 @@ func TestListOptions_ToFindOptions(t *testing.T) {
 	gotOpts := opts.ToFindOptions()
 	require.Equal(t, wantOpts, gotOpts)
 }
+
+func TestListOptions_ToFindOptions_LabelSelector(t *testing.T) {
+	opts := ListOptions{
+		Namespace: "*",
+		LabelSelector: LabelSelector{
+			{Key: "team", Operator: LabelEquals, Value: "red"},
+			{Key: "env", Operator: LabelNotEquals, Value: "prod"},
+		},
+	}
+
+	gotOpts := opts.ToFindOptions()
+	wantFilter := primitive.M{
+		"$and": []interface{}{
+			map[string]interface{}{"labels.team": "red"},
+			map[string]interface{}{"labels.env": map[string]interface{}{"$ne": "prod"}},
+		},
+	}
+	require.Equal(t, wantFilter, gotOpts.Filter)
+}
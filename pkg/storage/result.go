@@ -5,6 +5,8 @@ import (
 
 	"get.porter.sh/porter/pkg/cnab"
 	"github.com/cnabio/cnab-go/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var _ Document = Result{}
@@ -40,6 +42,86 @@ type Result struct {
 
 	// Custom extension data applicable to a given runtime.
 	Custom interface{} `json:"custom,omitempty"`
+
+	// ChangeTicketDelivery records the outcome of updating the run's change
+	// ticket in the external change-management system.
+	ChangeTicketDelivery *ChangeTicketDelivery `json:"changeTicketDelivery,omitempty"`
+
+	// CredentialLeases are the credentials that were issued for the run by
+	// the secrets plugin, and the outcome of revoking them.
+	CredentialLeases []CredentialLease `json:"credentialLeases,omitempty"`
+
+	// Pending indicates that the outputs of the result are still being saved.
+	// The result is committed once all of its outputs are saved, so a result
+	// that remains pending was interrupted and is repaired by porter storage fsck.
+	// Pending results are not included when the results of a run are listed.
+	Pending bool `json:"pending,omitempty"`
+}
+
+// committedFilter matches the results that are not pending.
+var committedFilter = bson.M{"$ne": true}
+
+// CredentialLease is a credential that was issued for a single run by the
+// secrets plugin, and revoked when the run completed.
+type CredentialLease struct {
+	// Credential is the name of the bundle credential that was issued.
+	Credential string `json:"credential"`
+
+	// LeaseID identifies the issued credential in the secrets plugin.
+	LeaseID string `json:"leaseId"`
+
+	// Issued timestamp of the credential.
+	Issued time.Time `json:"issued"`
+
+	// Revoked indicates that the credential was revoked.
+	Revoked bool `json:"revoked"`
+
+	// RevokedAt timestamp of the revocation.
+	RevokedAt time.Time `json:"revokedAt,omitempty"`
+
+	// Error returned by the secrets plugin when the credential could not be revoked.
+	Error string `json:"error,omitempty"`
+}
+
+const (
+	// ChangeTicketDelivered indicates that the change ticket was updated.
+	ChangeTicketDelivered = "delivered"
+
+	// ChangeTicketDeliveryFailed indicates that the change ticket could not
+	// be updated after all attempts were exhausted.
+	ChangeTicketDeliveryFailed = "failed"
+)
+
+// ChangeTicketDelivery is the status of a request to update a change ticket
+// with the result of a run.
+type ChangeTicketDelivery struct {
+	// Ticket is the ID of the change ticket that was updated.
+	Ticket string `json:"ticket"`
+
+	// Status of the delivery, either ChangeTicketDelivered or ChangeTicketDeliveryFailed.
+	Status string `json:"status"`
+
+	// Attempts is the number of requests made to the change-management system.
+	Attempts int `json:"attempts"`
+
+	// StatusCode is the HTTP status code returned by the last request.
+	StatusCode int `json:"statusCode,omitempty"`
+
+	// Error from the last request, when the delivery failed.
+	Error string `json:"error,omitempty"`
+
+	// Completed timestamp of the last request.
+	Completed time.Time `json:"completed"`
+}
+
+// TraceAttributes identify the result in a trace span.
+func (r Result) TraceAttributes() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		installationAttribute(r.Namespace, r.Installation),
+		attribute.String("runId", r.RunID),
+		attribute.String("resultId", r.ID),
+		attribute.String("status", r.Status),
+	}
 }
 
 func (r Result) DefaultDocumentFilter() map[string]interface{} {
@@ -62,6 +144,7 @@ func (r Result) NewOutput(name string, data []byte) Output {
 		Installation:  r.Installation,
 		RunID:         r.RunID,
 		ResultID:      r.ID,
+		Created:       time.Now(),
 		Value:         data,
 	}
 }
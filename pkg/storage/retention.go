@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+)
+
+// RetentionPolicy determines which runs of an installation are kept when the
+// installation's runs are pruned. A run is kept when it matches any of the
+// rules in the policy.
+//
+// Regardless of the policy, the following runs are never pruned because
+// Porter relies upon them:
+//   - The installation's most recent run.
+//   - Runs that have not completed.
+//   - Runs that generated the most recent value of an output, which may be
+//     used as the source of a parameter or a dependency's output.
+type RetentionPolicy struct {
+	// KeepLast is the number of most recent runs to keep.
+	KeepLast int
+
+	// KeepNewerThan keeps runs that were created within the specified duration.
+	KeepNewerThan time.Duration
+
+	// KeepLastSuccessfulPerAction keeps the most recent successful run of each
+	// action, for example the last successful install and upgrade.
+	KeepLastSuccessfulPerAction bool
+}
+
+// Validate that the policy has at least one rule, so that pruning does not
+// unintentionally remove an installation's history.
+func (p RetentionPolicy) Validate() error {
+	if p.KeepLast < 0 {
+		return fmt.Errorf("invalid retention policy: the number of runs to keep cannot be negative: %d", p.KeepLast)
+	}
+	if p.KeepNewerThan < 0 {
+		return fmt.Errorf("invalid retention policy: the age of runs to keep cannot be negative: %s", p.KeepNewerThan)
+	}
+	if p.KeepLast == 0 && p.KeepNewerThan == 0 && !p.KeepLastSuccessfulPerAction {
+		return errors.New("invalid retention policy: at least one rule must be specified to select the runs to keep")
+	}
+	return nil
+}
+
+// SelectRunsToPrune applies the retention policy to the runs of an
+// installation, returning the runs that should be removed sorted from oldest
+// to newest.
+// The results are the results of each run keyed by the run ID, and the outputs
+// are the most recent value of each of the installation's outputs.
+func (p RetentionPolicy) SelectRunsToPrune(now time.Time, runs []Run, results map[string][]Result, outputs Outputs) []Run {
+	if len(runs) == 0 {
+		return nil
+	}
+
+	// Sort newest to oldest so that the rules can stop at the first match.
+	// Run IDs are not always generated in order, so sort by when the run was created.
+	sorted := make([]Run, len(runs))
+	copy(sorted, runs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].Created.Equal(sorted[j].Created) {
+			return sorted[i].Created.After(sorted[j].Created)
+		}
+		return sorted[i].ID > sorted[j].ID
+	})
+
+	keep := make(map[string]bool, len(sorted))
+
+	// Always keep the most recent run, it represents the current state of the installation
+	keep[sorted[0].ID] = true
+
+	// Always keep runs that generated the last value of an output
+	for _, o := range outputs.Value() {
+		keep[o.RunID] = true
+	}
+
+	lastSuccessful := make(map[string]bool)
+	for i, run := range sorted {
+		runResults := results[run.ID]
+
+		// Always keep runs that haven't completed
+		if !isRunCompleted(runResults) {
+			keep[run.ID] = true
+		}
+
+		if i < p.KeepLast {
+			keep[run.ID] = true
+		}
+
+		if p.KeepNewerThan > 0 && now.Sub(run.Created) < p.KeepNewerThan {
+			keep[run.ID] = true
+		}
+
+		if p.KeepLastSuccessfulPerAction && !lastSuccessful[run.Action] && isRunSuccessful(runResults) {
+			lastSuccessful[run.Action] = true
+			keep[run.ID] = true
+		}
+	}
+
+	var prune []Run
+	for i := len(sorted) - 1; i >= 0; i-- {
+		if !keep[sorted[i].ID] {
+			prune = append(prune, sorted[i])
+		}
+	}
+	return prune
+}
+
+// isRunCompleted determines if the run has a result with a final status.
+func isRunCompleted(results []Result) bool {
+	for _, result := range results {
+		switch result.Status {
+		case cnab.StatusRunning, cnab.StatusPending:
+			continue
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// isRunSuccessful determines if the run has a successful result.
+func isRunSuccessful(results []Result) bool {
+	for _, result := range results {
+		if result.Status == cnab.StatusSucceeded {
+			return true
+		}
+	}
+	return false
+}
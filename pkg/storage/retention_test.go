@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionPolicy_Validate(t *testing.T) {
+	testcases := []struct {
+		name    string
+		policy  RetentionPolicy
+		wantErr string
+	}{
+		{name: "keep last", policy: RetentionPolicy{KeepLast: 5}},
+		{name: "keep newer than", policy: RetentionPolicy{KeepNewerThan: time.Hour}},
+		{name: "keep last successful", policy: RetentionPolicy{KeepLastSuccessfulPerAction: true}},
+		{name: "no rules", policy: RetentionPolicy{}, wantErr: "at least one rule must be specified"},
+		{name: "negative count", policy: RetentionPolicy{KeepLast: -1}, wantErr: "the number of runs to keep cannot be negative"},
+		{name: "negative age", policy: RetentionPolicy{KeepNewerThan: -time.Hour}, wantErr: "the age of runs to keep cannot be negative"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Validate()
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetentionPolicy_SelectRunsToPrune(t *testing.T) {
+	now := time.Now()
+	inst := NewInstallation("dev", "mybun")
+
+	// Create runs from oldest to newest, with IDs that sort in the same order
+	newRun := func(action string, age time.Duration, status string) (Run, []Result) {
+		run := inst.NewRun(action)
+		run.Created = now.Add(-age)
+		var results []Result
+		if status != "" {
+			results = append(results, run.NewResult(status))
+		}
+		return run, results
+	}
+
+	var runs []Run
+	results := map[string][]Result{}
+	add := func(action string, age time.Duration, status string) Run {
+		run, runResults := newRun(action, age, status)
+		runs = append(runs, run)
+		results[run.ID] = runResults
+		return run
+	}
+
+	install := add(cnab.ActionInstall, 72*time.Hour, cnab.StatusSucceeded)
+	upgrade1 := add(cnab.ActionUpgrade, 48*time.Hour, cnab.StatusSucceeded)
+	upgrade2 := add(cnab.ActionUpgrade, 36*time.Hour, cnab.StatusFailed)
+	stuck := add(cnab.ActionUpgrade, 24*time.Hour, cnab.StatusRunning)
+	upgrade3 := add(cnab.ActionUpgrade, 2*time.Hour, cnab.StatusFailed)
+	upgrade4 := add(cnab.ActionUpgrade, time.Hour, cnab.StatusFailed)
+
+	runIDs := func(runs []Run) []string {
+		ids := make([]string, len(runs))
+		for i, run := range runs {
+			ids[i] = run.ID
+		}
+		return ids
+	}
+
+	t.Run("keep last", func(t *testing.T) {
+		p := RetentionPolicy{KeepLast: 2}
+		pruned := p.SelectRunsToPrune(now, runs, results, Outputs{})
+		assert.Equal(t, runIDs([]Run{install, upgrade1, upgrade2}), runIDs(pruned), "expected the runs that haven't completed to be kept")
+	})
+
+	t.Run("keep newer than", func(t *testing.T) {
+		p := RetentionPolicy{KeepNewerThan: 40 * time.Hour}
+		pruned := p.SelectRunsToPrune(now, runs, results, Outputs{})
+		assert.Equal(t, runIDs([]Run{install, upgrade1}), runIDs(pruned))
+	})
+
+	t.Run("keep last successful per action", func(t *testing.T) {
+		p := RetentionPolicy{KeepLastSuccessfulPerAction: true}
+		pruned := p.SelectRunsToPrune(now, runs, results, Outputs{})
+		assert.Equal(t, runIDs([]Run{upgrade2, upgrade3}), runIDs(pruned), "expected the last run to always be kept")
+	})
+
+	t.Run("keep runs with the last outputs", func(t *testing.T) {
+		p := RetentionPolicy{KeepLast: 1}
+		outputs := NewOutputs([]Output{{Name: "connstr", RunID: install.ID}})
+		pruned := p.SelectRunsToPrune(now, runs, results, outputs)
+		assert.Equal(t, runIDs([]Run{upgrade1, upgrade2, upgrade3}), runIDs(pruned))
+		assert.NotContains(t, runIDs(pruned), stuck.ID)
+		assert.NotContains(t, runIDs(pruned), upgrade4.ID)
+	})
+
+	t.Run("sorted by created", func(t *testing.T) {
+		// The IDs of the runs do not sort in the order they were created
+		older, olderResults := newRun(cnab.ActionUpgrade, 2*time.Hour, cnab.StatusSucceeded)
+		newer, newerResults := newRun(cnab.ActionUpgrade, time.Hour, cnab.StatusSucceeded)
+		older.ID, newer.ID = "b", "a"
+		unordered := []Run{newer, older}
+		unorderedResults := map[string][]Result{older.ID: olderResults, newer.ID: newerResults}
+
+		p := RetentionPolicy{KeepLast: 1}
+		pruned := p.SelectRunsToPrune(now, unordered, unorderedResults, Outputs{})
+		assert.Equal(t, []string{older.ID}, runIDs(pruned), "expected the most recently created run to be kept")
+	})
+
+	t.Run("no runs", func(t *testing.T) {
+		p := RetentionPolicy{KeepLast: 1}
+		assert.Empty(t, p.SelectRunsToPrune(now, nil, nil, Outputs{}))
+	})
+}
@@ -3,11 +3,16 @@ package storage
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/secrets"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-go/schema"
+	"github.com/cnabio/cnab-go/secrets/host"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var _ Document = Run{}
@@ -44,8 +49,8 @@ type Run struct {
 	// BundleReference is the canonical reference to the bundle used in the action.
 	BundleReference string `json:"bundleReference"`
 
-	// BundleDigest is the digest of the bundle.
-	// TODO(carolynvs): populate this
+	// BundleDigest is the digest that BundleReference resolved to when the
+	// run was created. It is empty when the bundle was not from a registry.
 	BundleDigest string `json:"bundleDigest"`
 
 	// ParameterOverrides are the key/value parameter overrides (taking precedence over
@@ -58,15 +63,81 @@ type Run struct {
 	// ParameterSets is the list of parameter set names used during the run.
 	ParameterSets []string `json:"parameterSets,omitempty"`
 
+	// ParameterSetRevisions identify the revision of each parameter set used
+	// during the run, so that the values that the run used can be told apart
+	// from later changes to the parameter sets.
+	ParameterSetRevisions []ParameterSetRevision `json:"parameterSetRevisions,omitempty"`
+
+	// EphemeralOutputs is the list of output names that are printed after the
+	// run but are never persisted. This includes outputs declared ephemeral by
+	// the bundle and any requested by the user when the bundle was executed.
+	EphemeralOutputs []string `json:"ephemeralOutputs,omitempty"`
+
+	// ChangeTicket is the ID of a ticket in an external change-management
+	// system that is updated with the outcome of the run when it completes.
+	ChangeTicket string `json:"changeTicket,omitempty"`
+
+	// Timeout is the maximum amount of time that the bundle was allowed to
+	// run before it was stopped, for example 30m0s. It is empty when the run
+	// was not timed out.
+	Timeout string `json:"timeout,omitempty"`
+
+	// RestoredSnapshot is the name of the snapshot of the installation whose
+	// outputs were used to resolve the parameters of the run, instead of the
+	// most recent outputs of the installation.
+	RestoredSnapshot string `json:"restoredSnapshot,omitempty"`
+
+	// OutputDependencies are the parameters of the run that were resolved
+	// from the outputs of other installations, using the installation
+	// output parameter sources of the bundle.
+	OutputDependencies []RunOutputDependency `json:"outputDependencies,omitempty"`
+
+	// Labels applied to the run, such as the ticket or team that requested the
+	// change. Labels are not encrypted so that runs may be queried by label.
+	Labels map[string]string `json:"labels,omitempty"`
+
 	// Parameters is the full set of parameters that's being used during the
 	// current run.
 	// This includes internal parameters, parameter sources, values from parameter sets, etc.
 	// Any sensitive data will be sannitized before saving to the database.
+	// Parameters that are set to their default value are not saved to the
+	// database, use GetParameters to retrieve the full set of parameters.
 	Parameters ParameterSet `json:"parameters,omitempty"`
 
+	// Environment is the executor environment that ran the bundle, such as
+	// the version of Porter and the driver. Runs recorded by older versions of
+	// Porter do not have an environment.
+	Environment *RunEnvironment `json:"environment,omitempty"`
+
+	// Metadata describes where the run was started from, such as the CI
+	// pipeline and commit, when it was captured.
+	Metadata *RunMetadata `json:"metadata,omitempty"`
+
+	// Trigger is the event that started the run, when the run was started
+	// automatically, such as by an auto-upgrade rule.
+	Trigger *RunTrigger `json:"trigger,omitempty"`
+
 	// Custom extension data applicable to a given runtime.
 	// TODO(carolynvs): remove custom and populate it in ToCNAB
 	Custom interface{} `json:"custom"`
+
+	// Notes are user provided annotations added to the run after it was executed.
+	Notes []RunNote `json:"notes,omitempty"`
+
+	// Heartbeat is updated periodically while the bundle is running, so that
+	// a run that was interrupted, for example because Porter was killed, can
+	// be detected. Runs recorded by older versions of Porter do not have a
+	// heartbeat.
+	Heartbeat time.Time `json:"heartbeat,omitempty"`
+}
+
+// RunNote is a timestamped note attached to a run by a user.
+type RunNote struct {
+	// Created timestamp of the note.
+	Created time.Time `json:"created" yaml:"created"`
+
+	// Note is the text of the note.
+	Note string `json:"note" yaml:"note"`
 }
 
 // rawRun is an alias for Run that does not have a json marshal functions defined,
@@ -111,10 +182,16 @@ func (r Run) DefaultDocumentFilter() map[string]interface{} {
 	return map[string]interface{}{"_id": r.ID}
 }
 
+// nativeFields saves the time the run was created as a date in UTC, so that
+// runs can be filtered by when they were created.
+func (r Run) nativeFields() bson.M {
+	return bson.M{"createdAt": r.Created.UTC()}
+}
+
 // NewRun creates a run with default values initialized.
 func NewRun(namespace string, installation string) Run {
 	return Run{
-		SchemaVersion: InstallationSchemaVersion,
+		SchemaVersion: RunSchemaVersion,
 		ID:            cnab.NewULID(),
 		Revision:      cnab.NewULID(),
 		Created:       time.Now(),
@@ -124,6 +201,13 @@ func NewRun(namespace string, installation string) Run {
 	}
 }
 
+// AddNote attaches a timestamped note to the run.
+func (r *Run) AddNote(note string) RunNote {
+	n := RunNote{Created: time.Now(), Note: note}
+	r.Notes = append(r.Notes, n)
+	return n
+}
+
 // ShouldRecord the current run in the Installation history.
 // Runs are only recorded for actions that modify the bundle resources,
 // or for stateful actions. Stateless actions do not require an existing
@@ -139,7 +223,7 @@ func (r Run) ShouldRecord() bool {
 		stateful = !action.Stateless
 	}
 
-	bun := cnab.ExtendedBundle{Bundle: r.Bundle}
+	bun := cnab.NewBundle(r.Bundle)
 	for _, outputDef := range r.Bundle.Outputs {
 		if outputDef.AppliesTo(r.Action) && !bun.IsInternalOutput(outputDef.Definition) {
 			hasOutput = true
@@ -150,6 +234,15 @@ func (r Run) ShouldRecord() bool {
 	return modifies || stateful || hasOutput
 }
 
+// TraceAttributes identify the run in a trace span.
+func (r Run) TraceAttributes() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		installationAttribute(r.Namespace, r.Installation),
+		attribute.String("runId", r.ID),
+		attribute.String("action", r.Action),
+	}
+}
+
 // ToCNAB associated with the Run.
 func (r Run) ToCNAB() cnab.Claim {
 	return cnab.Claim{
@@ -167,13 +260,88 @@ func (r Run) ToCNAB() cnab.Claim {
 	}
 }
 
+// CompactParameters removes the parameters that are set to the default value
+// defined by the bundle, so that only the parameters that differ from the
+// defaults are saved with the run. Sensitive parameters, which are saved to the
+// secret store, are always kept. Use GetParameters to reconstruct the full set
+// of parameters.
+func (r *Run) CompactParameters() {
+	defaults := r.getParameterDefaults()
+	if len(defaults) == 0 {
+		return
+	}
+
+	// Copy the parameters so that the caller's parameter set isn't modified
+	params := make([]secrets.Strategy, 0, len(r.Parameters.Parameters))
+	for _, param := range r.Parameters.Parameters {
+		if defaultValue, ok := defaults[param.Name]; ok && param.Source.Key == host.SourceValue && param.Source.Value == defaultValue {
+			continue
+		}
+		params = append(params, param)
+	}
+	r.Parameters.Parameters = params
+}
+
+// GetParameters returns the full set of parameters used by the run, restoring
+// the parameters that were removed by CompactParameters because they were set
+// to the default value defined by the bundle.
+func (r Run) GetParameters() ParameterSet {
+	defaults := r.getParameterDefaults()
+	for _, param := range r.Parameters.Parameters {
+		delete(defaults, param.Name)
+	}
+	if len(defaults) == 0 {
+		return r.Parameters
+	}
+
+	set := r.Parameters
+	set.Parameters = make([]secrets.Strategy, len(r.Parameters.Parameters), len(r.Parameters.Parameters)+len(defaults))
+	copy(set.Parameters, r.Parameters.Parameters)
+
+	// The bundle always resolves a parameter to its default when it applies
+	// to the action and is not set, so any that are missing used their default.
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		set.Parameters = append(set.Parameters, ValueStrategy(name, defaults[name]))
+	}
+
+	return set
+}
+
+// getParameterDefaults returns the default values of the parameters that
+// apply to the run's action, converted to their string representation.
+func (r Run) getParameterDefaults() map[string]string {
+	defaults := make(map[string]string)
+	for name, param := range r.Bundle.Parameters {
+		if !param.AppliesTo(r.Action) {
+			continue
+		}
+
+		def, ok := r.Bundle.Definitions[param.Definition]
+		if !ok || def.Default == nil {
+			continue
+		}
+
+		value, err := cnab.WriteParameterToString(name, def.Default)
+		if err != nil {
+			continue
+		}
+		defaults[name] = value
+	}
+	return defaults
+}
+
 // TypedParameterValues returns parameters values that have been converted to
 // its typed value based on its bundle definition.
 func (r Run) TypedParameterValues() map[string]interface{} {
 	bun := cnab.NewBundle(r.Bundle)
 	value := make(map[string]interface{})
 
-	for _, param := range r.Parameters.Parameters {
+	for _, param := range r.GetParameters().Parameters {
 		v, err := bun.ConvertParameterValue(param.Name, param.Value)
 		if err != nil {
 			value[param.Name] = param.Value
@@ -195,6 +363,16 @@ func (r Run) TypedParameterValues() map[string]interface{} {
 
 }
 
+// IsEphemeralOutput determines if the output should be printed but never
+// persisted, either because the bundle declares it ephemeral or because the
+// user requested it when the bundle was executed.
+func (r Run) IsEphemeralOutput(output string) bool {
+	if cnab.EphemeralOutputs(r.EphemeralOutputs).Contains(output) {
+		return true
+	}
+	return cnab.NewBundle(r.Bundle).IsEphemeralOutput(output)
+}
+
 // NewRun creates a result for the current Run.
 func (r Run) NewResult(status string) Result {
 	result := NewResult()
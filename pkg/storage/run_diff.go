@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+
+	"get.porter.sh/porter/pkg/secrets"
+	"github.com/cnabio/cnab-go/secrets/host"
+)
+
+// RunDiff describes what changed between two runs of an installation.
+// Only the fields that changed are populated.
+type RunDiff struct {
+	// Namespace of the installation.
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// Installation name.
+	Installation string `json:"installation" yaml:"installation"`
+
+	// Before is the ID of the run that the changes are relative to.
+	Before string `json:"before" yaml:"before"`
+
+	// After is the ID of the run that is compared against Before.
+	After string `json:"after" yaml:"after"`
+
+	// Action executed by each run.
+	Action *ValueDiff `json:"action,omitempty" yaml:"action,omitempty"`
+
+	// BundleReference used by each run.
+	BundleReference *ValueDiff `json:"bundleReference,omitempty" yaml:"bundleReference,omitempty"`
+
+	// BundleDigest used by each run.
+	BundleDigest *ValueDiff `json:"bundleDigest,omitempty" yaml:"bundleDigest,omitempty"`
+
+	// CredentialSets that were added or removed.
+	CredentialSets *ListDiff `json:"credentialSets,omitempty" yaml:"credentialSets,omitempty"`
+
+	// ParameterSets that were added or removed.
+	ParameterSets *ListDiff `json:"parameterSets,omitempty" yaml:"parameterSets,omitempty"`
+
+	// ParameterOverrides that changed, sorted by name.
+	ParameterOverrides []NamedValueDiff `json:"parameterOverrides,omitempty" yaml:"parameterOverrides,omitempty"`
+
+	// Outputs that changed, sorted by name.
+	Outputs []NamedValueDiff `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+}
+
+// ValueDiff is a value that changed between two runs.
+type ValueDiff struct {
+	Before string `json:"before" yaml:"before"`
+	After  string `json:"after" yaml:"after"`
+}
+
+// ListDiff is the items that were added to or removed from a list between two runs.
+type ListDiff struct {
+	Added   []string `json:"added,omitempty" yaml:"added,omitempty"`
+	Removed []string `json:"removed,omitempty" yaml:"removed,omitempty"`
+}
+
+// NamedValueDiff is a named value, such as a parameter or output, that changed
+// between two runs. Before or After is nil when the value was only defined by
+// one of the runs.
+type NamedValueDiff struct {
+	Name   string  `json:"name" yaml:"name"`
+	Before *string `json:"before,omitempty" yaml:"before,omitempty"`
+	After  *string `json:"after,omitempty" yaml:"after,omitempty"`
+
+	// Sensitive indicates that the value is sensitive, and must be masked
+	// before the diff is displayed.
+	Sensitive bool `json:"sensitive,omitempty" yaml:"sensitive,omitempty"`
+}
+
+// HasChanges returns true when there are any differences between the runs.
+func (d RunDiff) HasChanges() bool {
+	return d.Action != nil || d.BundleReference != nil || d.BundleDigest != nil ||
+		d.CredentialSets != nil || d.ParameterSets != nil ||
+		len(d.ParameterOverrides) > 0 || len(d.Outputs) > 0
+}
+
+// DiffRuns compares two runs of an installation, along with the outputs
+// generated by each run, and returns what changed from the before run to
+// the after run.
+//
+// Sensitive values are stored in the secret store under a key unique to each
+// run, so they are compared by their resolved values in secretValues, keyed
+// by the secret key, such as the values returned by
+// Sanitizer.RestoreRunSecrets. The diff contains the raw values of the
+// sensitive parameter overrides and outputs that changed, mask them before
+// displaying the diff.
+func DiffRuns(before Run, beforeOutputs Outputs, after Run, afterOutputs Outputs, secretValues map[string]string) RunDiff {
+	diff := RunDiff{
+		Namespace:    after.Namespace,
+		Installation: after.Installation,
+		Before:       before.ID,
+		After:        after.ID,
+	}
+
+	diff.Action = diffValue(before.Action, after.Action)
+	diff.BundleReference = diffValue(before.BundleReference, after.BundleReference)
+	diff.BundleDigest = diffValue(before.BundleDigest, after.BundleDigest)
+	diff.CredentialSets = diffList(before.CredentialSets, after.CredentialSets)
+	diff.ParameterSets = diffList(before.ParameterSets, after.ParameterSets)
+	diff.ParameterOverrides = diffNamedValues(
+		runParameterValues(before.ID, before.ParameterOverrides.Parameters, secretValues),
+		runParameterValues(after.ID, after.ParameterOverrides.Parameters, secretValues))
+	diff.Outputs = diffNamedValues(runOutputValues(beforeOutputs, secretValues), runOutputValues(afterOutputs, secretValues))
+
+	return diff
+}
+
+func diffValue(before string, after string) *ValueDiff {
+	if before == after {
+		return nil
+	}
+	return &ValueDiff{Before: before, After: after}
+}
+
+func diffList(before []string, after []string) *ListDiff {
+	beforeItems := make(map[string]bool, len(before))
+	for _, item := range before {
+		beforeItems[item] = true
+	}
+	afterItems := make(map[string]bool, len(after))
+	for _, item := range after {
+		afterItems[item] = true
+	}
+
+	var diff ListDiff
+	for _, item := range after {
+		if !beforeItems[item] {
+			diff.Added = append(diff.Added, item)
+		}
+	}
+	for _, item := range before {
+		if !afterItems[item] {
+			diff.Removed = append(diff.Removed, item)
+		}
+	}
+
+	if len(diff.Added) == 0 && len(diff.Removed) == 0 {
+		return nil
+	}
+	return &diff
+}
+
+// diffableValue is the value of a parameter or output that is compared in a RunDiff.
+type diffableValue struct {
+	value     string
+	sensitive bool
+}
+
+func diffNamedValues(before map[string]diffableValue, after map[string]diffableValue) []NamedValueDiff {
+	names := make([]string, 0, len(before)+len(after))
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var diffs []NamedValueDiff
+	for _, name := range names {
+		b, hasBefore := before[name]
+		a, hasAfter := after[name]
+
+		if hasBefore && hasAfter && b.value == a.value {
+			continue
+		}
+
+		d := NamedValueDiff{Name: name, Sensitive: b.sensitive || a.sensitive}
+		if hasBefore {
+			d.Before = &b.value
+		}
+		if hasAfter {
+			d.After = &a.value
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs
+}
+
+// runParameterValues returns the comparable values of the parameters of a run.
+// Parameters that were saved to the secret store by the sanitizer are
+// sensitive, and compared by their resolved value.
+func runParameterValues(runID string, params []secrets.Strategy, secretValues map[string]string) map[string]diffableValue {
+	values := make(map[string]diffableValue, len(params))
+	for _, param := range params {
+		if param.Source == sanitizedParam(param, runID).Source {
+			values[param.Name] = diffableValue{value: secretValues[param.Source.Value], sensitive: true}
+			continue
+		}
+
+		value := param.Source.Value
+		if param.Source.Key != host.SourceValue {
+			value = fmt.Sprintf("%s:%s", param.Source.Key, param.Source.Value)
+		}
+		values[param.Name] = diffableValue{value: value}
+	}
+	return values
+}
+
+// runOutputValues returns the comparable values of the outputs of a run.
+// Outputs that were saved to the secret store are sensitive, and compared by
+// their resolved value. Outputs that were saved as a stream are compared by
+// their size.
+func runOutputValues(outputs Outputs, secretValues map[string]string) map[string]diffableValue {
+	values := make(map[string]diffableValue, outputs.Len())
+	for _, output := range outputs.Value() {
+		switch {
+		case output.Key != "":
+			values[output.Name] = diffableValue{value: secretValues[output.Key], sensitive: true}
+		case output.Chunks > 0:
+			values[output.Name] = diffableValue{value: fmt.Sprintf("<%d bytes>", output.Size)}
+		default:
+			values[output.Name] = diffableValue{value: string(output.Value)}
+		}
+	}
+	return values
+}
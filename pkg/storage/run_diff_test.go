@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"testing"
+
+	"get.porter.sh/porter/pkg/secrets"
+	"github.com/cnabio/cnab-go/secrets/host"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffRuns(t *testing.T) {
+	before := NewRun("dev", "mybuns")
+	before.Action = "install"
+	before.BundleReference = "example.com/mybuns:v1.0.0"
+	before.BundleDigest = "sha256:abc"
+	before.CredentialSets = []string{"kubeconfig", "azure"}
+	before.ParameterSets = []string{"dev"}
+	before.ParameterOverrides = NewParameterSet("dev", "mybuns",
+		secrets.Strategy{Name: "replicas", Source: secrets.Source{Key: host.SourceValue, Value: "1"}},
+		secrets.Strategy{Name: "region", Source: secrets.Source{Key: host.SourceValue, Value: "eastus"}},
+		secrets.Strategy{Name: "logLevel", Source: secrets.Source{Key: "env", Value: "LOG_LEVEL"}},
+	)
+	before.ParameterOverrides.Parameters = append(before.ParameterOverrides.Parameters,
+		sanitizedParam(secrets.Strategy{Name: "password"}, before.ID))
+
+	after := NewRun("dev", "mybuns")
+	after.Action = "upgrade"
+	after.BundleReference = "example.com/mybuns:v1.1.0"
+	after.BundleDigest = "sha256:def"
+	after.CredentialSets = []string{"kubeconfig", "aws"}
+	after.ParameterSets = []string{"dev"}
+	after.ParameterOverrides = NewParameterSet("dev", "mybuns",
+		secrets.Strategy{Name: "replicas", Source: secrets.Source{Key: host.SourceValue, Value: "3"}},
+		secrets.Strategy{Name: "region", Source: secrets.Source{Key: host.SourceValue, Value: "eastus"}},
+		secrets.Strategy{Name: "debug", Source: secrets.Source{Key: host.SourceValue, Value: "true"}},
+	)
+	after.ParameterOverrides.Parameters = append(after.ParameterOverrides.Parameters,
+		sanitizedParam(secrets.Strategy{Name: "password"}, after.ID),
+		sanitizedParam(secrets.Strategy{Name: "token"}, after.ID))
+
+	beforeResult := before.NewResult("succeeded")
+	beforeOutputs := NewOutputs([]Output{
+		beforeResult.NewOutput("host", []byte("10.0.0.1")),
+		beforeResult.NewOutput("port", []byte("8080")),
+		sanitizedOutput(beforeResult.NewOutput("connstr", nil)),
+	})
+
+	afterResult := after.NewResult("succeeded")
+	afterOutputs := NewOutputs([]Output{
+		afterResult.NewOutput("host", []byte("10.0.0.2")),
+		afterResult.NewOutput("port", []byte("8080")),
+		sanitizedOutput(afterResult.NewOutput("connstr", nil)),
+	})
+
+	secretValues := map[string]string{
+		before.ID + "-password": "mypassword",
+		after.ID + "-password":  "rotated",
+		after.ID + "-token":     "mytoken",
+		before.ID + "-connstr":  "server=db",
+		after.ID + "-connstr":   "server=db",
+	}
+
+	diff := DiffRuns(before, beforeOutputs, after, afterOutputs, secretValues)
+	require.True(t, diff.HasChanges())
+
+	assert.Equal(t, "dev", diff.Namespace)
+	assert.Equal(t, "mybuns", diff.Installation)
+	assert.Equal(t, before.ID, diff.Before)
+	assert.Equal(t, after.ID, diff.After)
+	assert.Equal(t, &ValueDiff{Before: "install", After: "upgrade"}, diff.Action)
+	assert.Equal(t, &ValueDiff{Before: "example.com/mybuns:v1.0.0", After: "example.com/mybuns:v1.1.0"}, diff.BundleReference)
+	assert.Equal(t, &ValueDiff{Before: "sha256:abc", After: "sha256:def"}, diff.BundleDigest)
+	assert.Equal(t, &ListDiff{Added: []string{"aws"}, Removed: []string{"azure"}}, diff.CredentialSets)
+	assert.Nil(t, diff.ParameterSets, "the parameter sets did not change")
+
+	strPtr := func(value string) *string { return &value }
+	wantParams := []NamedValueDiff{
+		{Name: "debug", After: strPtr("true")},
+		{Name: "logLevel", Before: strPtr("env:LOG_LEVEL")},
+		{Name: "password", Before: strPtr("mypassword"), After: strPtr("rotated"), Sensitive: true},
+		{Name: "replicas", Before: strPtr("1"), After: strPtr("3")},
+		{Name: "token", After: strPtr("mytoken"), Sensitive: true},
+	}
+	assert.Equal(t, wantParams, diff.ParameterOverrides)
+
+	wantOutputs := []NamedValueDiff{
+		{Name: "host", Before: strPtr("10.0.0.1"), After: strPtr("10.0.0.2")},
+	}
+	assert.Equal(t, wantOutputs, diff.Outputs, "the sensitive output did not change")
+}
+
+func TestDiffRuns_NoChanges(t *testing.T) {
+	before := NewRun("dev", "mybuns")
+	before.Action = "upgrade"
+	before.CredentialSets = []string{"kubeconfig"}
+
+	after := NewRun("dev", "mybuns")
+	after.Action = "upgrade"
+	after.CredentialSets = []string{"kubeconfig"}
+
+	diff := DiffRuns(before, NewOutputs(nil), after, NewOutputs(nil), nil)
+	assert.False(t, diff.HasChanges())
+}
@@ -0,0 +1,110 @@
+package storage
+
+// RunEnvironment describes the executor environment that ran a bundle, so that
+// the environments of a passing and a failing run can be compared.
+type RunEnvironment struct {
+	// PorterVersion is the version of Porter that executed the bundle.
+	PorterVersion string `json:"porterVersion,omitempty" yaml:"porterVersion,omitempty"`
+
+	// PorterCommit is the commit of Porter that executed the bundle.
+	PorterCommit string `json:"porterCommit,omitempty" yaml:"porterCommit,omitempty"`
+
+	// Driver is the name of the driver that ran the invocation image.
+	Driver string `json:"driver,omitempty" yaml:"driver,omitempty"`
+
+	// DriverVersion is the version reported by the driver, such as the
+	// version of the Docker engine, when it is known.
+	DriverVersion string `json:"driverVersion,omitempty" yaml:"driverVersion,omitempty"`
+
+	// Mixins is the version of each mixin used to build the bundle, keyed by
+	// the name of the mixin.
+	Mixins map[string]string `json:"mixins,omitempty" yaml:"mixins,omitempty"`
+
+	// Images is the digest of each image in the bundle, keyed by the name of
+	// the image. The invocation image is named invocationImage. When the
+	// digest of an image is unknown, its reference is used instead.
+	Images map[string]string `json:"images,omitempty" yaml:"images,omitempty"`
+}
+
+// RunEnvironmentDiff describes what changed between the environments of two
+// runs of an installation. Only the fields that changed are populated.
+type RunEnvironmentDiff struct {
+	// Namespace of the installation.
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// Installation name.
+	Installation string `json:"installation" yaml:"installation"`
+
+	// Before is the ID of the run that the changes are relative to.
+	Before string `json:"before" yaml:"before"`
+
+	// After is the ID of the run that is compared against Before.
+	After string `json:"after" yaml:"after"`
+
+	// PorterVersion that executed each run.
+	PorterVersion *ValueDiff `json:"porterVersion,omitempty" yaml:"porterVersion,omitempty"`
+
+	// PorterCommit that executed each run.
+	PorterCommit *ValueDiff `json:"porterCommit,omitempty" yaml:"porterCommit,omitempty"`
+
+	// Driver that executed each run.
+	Driver *ValueDiff `json:"driver,omitempty" yaml:"driver,omitempty"`
+
+	// DriverVersion that executed each run.
+	DriverVersion *ValueDiff `json:"driverVersion,omitempty" yaml:"driverVersion,omitempty"`
+
+	// Mixins whose version changed, sorted by name.
+	Mixins []NamedValueDiff `json:"mixins,omitempty" yaml:"mixins,omitempty"`
+
+	// Images whose digest changed, sorted by name.
+	Images []NamedValueDiff `json:"images,omitempty" yaml:"images,omitempty"`
+}
+
+// HasChanges returns true when there are any differences between the environments.
+func (d RunEnvironmentDiff) HasChanges() bool {
+	return d.PorterVersion != nil || d.PorterCommit != nil || d.Driver != nil || d.DriverVersion != nil ||
+		len(d.Mixins) > 0 || len(d.Images) > 0
+}
+
+// DiffRunEnvironments compares the recorded environments of two runs of an
+// installation and returns what changed from the before run to the after run.
+// A run without a recorded environment is compared as an empty environment.
+func DiffRunEnvironments(before Run, after Run) RunEnvironmentDiff {
+	diff := RunEnvironmentDiff{
+		Namespace:    after.Namespace,
+		Installation: after.Installation,
+		Before:       before.ID,
+		After:        after.ID,
+	}
+
+	var beforeEnv, afterEnv RunEnvironment
+	if before.Environment != nil {
+		beforeEnv = *before.Environment
+	}
+	if after.Environment != nil {
+		afterEnv = *after.Environment
+	}
+
+	diff.PorterVersion = diffValue(beforeEnv.PorterVersion, afterEnv.PorterVersion)
+	diff.PorterCommit = diffValue(beforeEnv.PorterCommit, afterEnv.PorterCommit)
+	diff.Driver = diffValue(beforeEnv.Driver, afterEnv.Driver)
+	diff.DriverVersion = diffValue(beforeEnv.DriverVersion, afterEnv.DriverVersion)
+	diff.Mixins = diffStringMaps(beforeEnv.Mixins, afterEnv.Mixins)
+	diff.Images = diffStringMaps(beforeEnv.Images, afterEnv.Images)
+
+	return diff
+}
+
+// diffStringMaps returns the entries that were added, removed or changed,
+// sorted by name.
+func diffStringMaps(before map[string]string, after map[string]string) []NamedValueDiff {
+	toDiffable := func(values map[string]string) map[string]diffableValue {
+		result := make(map[string]diffableValue, len(values))
+		for name, value := range values {
+			result[name] = diffableValue{value: value}
+		}
+		return result
+	}
+
+	return diffNamedValues(toDiffable(before), toDiffable(after))
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffRunEnvironments(t *testing.T) {
+	before := NewRun("dev", "mybuns")
+	before.Environment = &RunEnvironment{
+		PorterVersion: "v1.0.0",
+		PorterCommit:  "abc123",
+		Driver:        "docker",
+		DriverVersion: "20.10.7",
+		Mixins:        map[string]string{"exec": "v1.0.0", "helm3": "v0.1.15"},
+		Images:        map[string]string{"invocationImage": "sha256:aaa", "api": "sha256:bbb"},
+	}
+
+	after := NewRun("dev", "mybuns")
+	after.Environment = &RunEnvironment{
+		PorterVersion: "v1.0.1",
+		PorterCommit:  "def456",
+		Driver:        "docker",
+		DriverVersion: "20.10.7",
+		Mixins:        map[string]string{"exec": "v1.0.0", "helm3": "v0.1.16", "kubernetes": "v1.0.0"},
+		Images:        map[string]string{"invocationImage": "sha256:ccc"},
+	}
+
+	diff := DiffRunEnvironments(before, after)
+	require.True(t, diff.HasChanges())
+
+	assert.Equal(t, "dev", diff.Namespace)
+	assert.Equal(t, "mybuns", diff.Installation)
+	assert.Equal(t, before.ID, diff.Before)
+	assert.Equal(t, after.ID, diff.After)
+	assert.Equal(t, &ValueDiff{Before: "v1.0.0", After: "v1.0.1"}, diff.PorterVersion)
+	assert.Equal(t, &ValueDiff{Before: "abc123", After: "def456"}, diff.PorterCommit)
+	assert.Nil(t, diff.Driver, "the driver did not change")
+	assert.Nil(t, diff.DriverVersion, "the driver version did not change")
+
+	strPtr := func(value string) *string { return &value }
+	wantMixins := []NamedValueDiff{
+		{Name: "helm3", Before: strPtr("v0.1.15"), After: strPtr("v0.1.16")},
+		{Name: "kubernetes", After: strPtr("v1.0.0")},
+	}
+	assert.Equal(t, wantMixins, diff.Mixins)
+
+	wantImages := []NamedValueDiff{
+		{Name: "api", Before: strPtr("sha256:bbb")},
+		{Name: "invocationImage", Before: strPtr("sha256:aaa"), After: strPtr("sha256:ccc")},
+	}
+	assert.Equal(t, wantImages, diff.Images)
+}
+
+func TestDiffRunEnvironments_NoChanges(t *testing.T) {
+	env := &RunEnvironment{
+		PorterVersion: "v1.0.0",
+		Driver:        "docker",
+		Mixins:        map[string]string{"exec": "v1.0.0"},
+		Images:        map[string]string{"invocationImage": "sha256:aaa"},
+	}
+
+	before := NewRun("dev", "mybuns")
+	before.Environment = env
+	after := NewRun("dev", "mybuns")
+	after.Environment = env
+
+	diff := DiffRunEnvironments(before, after)
+	assert.False(t, diff.HasChanges())
+}
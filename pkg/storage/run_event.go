@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// RunEventStarted is emitted when the bundle starts running.
+	RunEventStarted = "started"
+
+	// RunEventLog is emitted for each line written by the bundle.
+	RunEventLog = "log"
+
+	// RunEventStepStarted is emitted when the runtime starts a step of the action.
+	RunEventStepStarted = "stepStarted"
+
+	// RunEventStepCompleted is emitted when the runtime completes a step of the action.
+	RunEventStepCompleted = "stepCompleted"
+
+	// RunEventOutputCaptured is emitted for each output generated by the bundle.
+	RunEventOutputCaptured = "output"
+
+	// RunEventResult is emitted when the run completes.
+	RunEventResult = "result"
+
+	// RunEventError is emitted by porter api serve when the command fails,
+	// including failures that happen before or after the bundle is run.
+	RunEventError = "error"
+)
+
+// RunEventMarker prefixes the lines written by the Porter runtime to the output
+// of the bundle that contain an event, instead of a log message.
+const RunEventMarker = "::porter-event::"
+
+// RunEvent is a structured event that reports the progress of a run as it
+// happens, so that other tools can follow a run without parsing its logs.
+type RunEvent struct {
+	// Type of event, for example RunEventLog.
+	Type string `json:"type"`
+
+	// Time that the event occurred.
+	Time time.Time `json:"time"`
+
+	// Namespace of the installation.
+	Namespace string `json:"namespace"`
+
+	// Installation name.
+	Installation string `json:"installation"`
+
+	// Action that is running.
+	Action string `json:"action"`
+
+	// RunID of the run.
+	RunID string `json:"runId"`
+
+	// Stream that a log message was written to, either stdout or stderr.
+	Stream string `json:"stream,omitempty"`
+
+	// Message logged by the bundle.
+	Message string `json:"message,omitempty"`
+
+	// Step that started or completed.
+	Step *StepResult `json:"step,omitempty"`
+
+	// Output generated by the bundle.
+	Output *RunEventOutput `json:"output,omitempty"`
+
+	// Status of the run when it completes.
+	Status string `json:"status,omitempty"`
+
+	// Error message when the run failed.
+	Error string `json:"error,omitempty"`
+}
+
+// RunEventOutput describes an output generated by the bundle.
+type RunEventOutput struct {
+	// Name of the output.
+	Name string `json:"name"`
+
+	// Sensitive indicates that the value of the output is not included in the event.
+	Sensitive bool `json:"sensitive"`
+
+	// Value of the output, unless it is sensitive.
+	Value string `json:"value,omitempty"`
+}
+
+// FormatRunEvent formats an event written by the Porter runtime to the output
+// of the bundle, so that it can be identified with ParseRunEvent.
+func FormatRunEvent(event RunEvent) (string, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+	return RunEventMarker + string(data), nil
+}
+
+// ParseRunEvent parses an event written by the Porter runtime to the output of
+// the bundle. The second return value is false when the line is not an event.
+func ParseRunEvent(line string) (RunEvent, bool) {
+	if !strings.HasPrefix(line, RunEventMarker) {
+		return RunEvent{}, false
+	}
+
+	var event RunEvent
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, RunEventMarker)), &event); err != nil {
+		return RunEvent{}, false
+	}
+	return event, true
+}
+
+// RunEventWriter writes the events of a run as newline delimited json.
+// It is safe for concurrent use.
+type RunEventWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+	run Run
+}
+
+// NewRunEventWriter creates a writer for the events of a run.
+func NewRunEventWriter(out io.Writer, run Run) *RunEventWriter {
+	return &RunEventWriter{out: out, run: run}
+}
+
+// Emit writes an event, setting the details of the run on the event, and the
+// time when it is not set.
+func (w *RunEventWriter) Emit(event RunEvent) error {
+	event.Namespace = w.run.Namespace
+	event.Installation = w.run.Installation
+	event.Action = w.run.Action
+	event.RunID = w.run.ID
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = w.out.Write(append(data, '\n'))
+	return err
+}
+
+// LogWriter returns a writer that emits each line written to it as a log
+// event for the stream. Lines containing an event written by the Porter
+// runtime are emitted as that event instead. Call Close to emit the last line
+// when it does not end with a newline.
+func (w *RunEventWriter) LogWriter(stream string) io.WriteCloser {
+	return &runEventLogWriter{events: w, stream: stream}
+}
+
+type runEventLogWriter struct {
+	events *RunEventWriter
+	stream string
+	buf    bytes.Buffer
+}
+
+func (l *runEventLogWriter) Write(p []byte) (int, error) {
+	l.buf.Write(p)
+	for {
+		i := bytes.IndexByte(l.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+
+		line := strings.TrimSuffix(string(l.buf.Next(i+1)), "\n")
+		if err := l.emit(line); err != nil {
+			return len(p), err
+		}
+	}
+}
+
+func (l *runEventLogWriter) Close() error {
+	if l.buf.Len() == 0 {
+		return nil
+	}
+	line := l.buf.String()
+	l.buf.Reset()
+	return l.emit(line)
+}
+
+func (l *runEventLogWriter) emit(line string) error {
+	line = strings.TrimSuffix(line, "\r")
+	if event, ok := ParseRunEvent(line); ok {
+		return l.events.Emit(event)
+	}
+	return l.events.Emit(RunEvent{Type: RunEventLog, Stream: l.stream, Message: line})
+}
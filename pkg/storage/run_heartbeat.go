@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// RunHeartbeatInterval is how often Porter updates the heartbeat of a run
+// while the bundle is running.
+const RunHeartbeatInterval = 30 * time.Second
+
+// RunStaleTimeout is how long after its last heartbeat a run that has not
+// completed is considered interrupted.
+const RunStaleTimeout = 5 * time.Minute
+
+// LastHeartbeat returns when the run last reported that it was in progress.
+// Runs without a heartbeat use when the run was created.
+func (r Run) LastHeartbeat() time.Time {
+	if r.Heartbeat.IsZero() {
+		return r.Created
+	}
+	return r.Heartbeat
+}
+
+// IsStale indicates if a run with the status of its most recent result has
+// not completed, and its heartbeat was not updated within the timeout, so
+// the process running the bundle was likely interrupted.
+func (r Run) IsStale(status string, now time.Time, timeout time.Duration) bool {
+	if status != cnab.StatusRunning && status != cnab.StatusPending {
+		return false
+	}
+	return now.Sub(r.LastHeartbeat()) > timeout
+}
+
+// UpdateRunHeartbeat records that a run is still in progress. Only the
+// heartbeat is modified, so that changes made to the run at the same time,
+// such as a note, are not overwritten.
+func (s InstallationStore) UpdateRunHeartbeat(ctx context.Context, runID string, heartbeat time.Time) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := checkWritable(s.store, CollectionRuns, "patch"); err != nil {
+		return span.Error(err)
+	}
+
+	if err := s.authorizeRun(ctx, VerbWrite, runID); err != nil {
+		return span.Error(err)
+	}
+
+	opts := PatchOptions{
+		QueryDocument: bson.M{"_id": runID},
+		// Timestamps are stored in the same format as the rest of the run
+		Transformation: bson.D{{Key: "$set", Value: bson.D{{Key: "heartbeat", Value: heartbeat.Format(time.RFC3339Nano)}}}},
+	}
+	return span.Error(s.store.Patch(ctx, CollectionRuns, opts))
+}
+
+// authorizeRun checks that the subject may perform an action on a run that is
+// identified only by its id. A run that the subject may not read is reported
+// as not found.
+func (s InstallationStore) authorizeRun(ctx context.Context, verb AuthorizationVerb, runID string) error {
+	if s.authz == nil {
+		return nil
+	}
+
+	var run struct {
+		Namespace    string `json:"namespace"`
+		Installation string `json:"installation"`
+	}
+	opts := FindOptions{
+		Filter: bson.M{"_id": runID},
+		Select: bson.D{{Key: "namespace", Value: 1}, {Key: "installation", Value: 1}},
+	}
+	if err := s.store.FindOne(ctx, CollectionRuns, opts, &run); err != nil {
+		return err
+	}
+	if err := authorizeFetched(ctx, s.authz, CollectionRuns, run.Namespace, run.Installation); err != nil {
+		return err
+	}
+	if verb == VerbRead {
+		return nil
+	}
+	return authorize(ctx, s.authz, verb, CollectionRuns, run.Namespace, run.Installation)
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_IsStale(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	testcases := []struct {
+		name      string
+		created   time.Time
+		heartbeat time.Time
+		status    string
+		wantStale bool
+	}{
+		{name: "recent heartbeat", created: now.Add(-time.Hour), heartbeat: now.Add(-time.Minute), status: cnab.StatusRunning, wantStale: false},
+		{name: "old heartbeat", created: now.Add(-time.Hour), heartbeat: now.Add(-10 * time.Minute), status: cnab.StatusRunning, wantStale: true},
+		{name: "pending", created: now.Add(-time.Hour), heartbeat: now.Add(-10 * time.Minute), status: cnab.StatusPending, wantStale: true},
+		{name: "no heartbeat, recently created", created: now.Add(-time.Minute), status: cnab.StatusRunning, wantStale: false},
+		{name: "no heartbeat, created long ago", created: now.Add(-time.Hour), status: cnab.StatusRunning, wantStale: true},
+		{name: "completed", created: now.Add(-time.Hour), heartbeat: now.Add(-10 * time.Minute), status: cnab.StatusFailed, wantStale: false},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			run := Run{Created: tc.created, Heartbeat: tc.heartbeat}
+			assert.Equal(t, tc.wantStale, run.IsStale(tc.status, now, RunStaleTimeout))
+		})
+	}
+}
+
+func TestInstallationStore_UpdateRunHeartbeat(t *testing.T) {
+	ctx := context.Background()
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	installation := cp.CreateInstallation(NewInstallation("dev", "mysql"))
+	run := cp.CreateRun(installation.NewRun(cnab.ActionInstall), func(r *Run) { r.AddNote("in progress") })
+
+	heartbeat := time.Now().Add(time.Minute)
+	require.NoError(t, cp.UpdateRunHeartbeat(ctx, run.ID, heartbeat), "UpdateRunHeartbeat failed")
+
+	got, err := cp.GetRun(ctx, run.ID)
+	require.NoError(t, err)
+	assert.True(t, heartbeat.Equal(got.Heartbeat), "expected the heartbeat to be updated, got %s", got.Heartbeat)
+	require.Len(t, got.Notes, 1, "the rest of the run should not be modified")
+	assert.Equal(t, run.Action, got.Action)
+}
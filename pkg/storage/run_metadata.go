@@ -0,0 +1,30 @@
+package storage
+
+// RunMetadata describes where a run was started from, such as the CI pipeline
+// and commit that produced a deployment. It is captured from well-known
+// environment variables when the run is created.
+type RunMetadata struct {
+	// Source is the name of the detector that captured the metadata, such as
+	// github-actions or gitlab-ci.
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+
+	// Commit is the revision of the source code, such as a git SHA.
+	Commit string `json:"commit,omitempty" yaml:"commit,omitempty"`
+
+	// Branch or tag that was built.
+	Branch string `json:"branch,omitempty" yaml:"branch,omitempty"`
+
+	// Repository that contains the source code.
+	Repository string `json:"repository,omitempty" yaml:"repository,omitempty"`
+
+	// PipelineURL links to the CI job or pipeline that started the run.
+	PipelineURL string `json:"pipelineUrl,omitempty" yaml:"pipelineUrl,omitempty"`
+
+	// User that started the run, or triggered the pipeline.
+	User string `json:"user,omitempty" yaml:"user,omitempty"`
+}
+
+// IsEmpty returns true when no metadata was captured.
+func (m RunMetadata) IsEmpty() bool {
+	return m == RunMetadata{}
+}
@@ -0,0 +1,313 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/cnabio/cnab-go/schema"
+	"github.com/hashicorp/go-multierror"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// RunMigrationPending is the status of a run document that should be
+	// migrated, when the migration is a dry run.
+	RunMigrationPending = "pending"
+
+	// RunMigrationMigrated is the status of a run document that was migrated
+	// to the current schema.
+	RunMigrationMigrated = "migrated"
+
+	// RunMigrationFailed is the status of a run document that could not be migrated.
+	RunMigrationFailed = "failed"
+)
+
+// RunMigrator migrates a run document from one schema version to the next.
+type RunMigrator struct {
+	// From is the schema version of the documents that are migrated.
+	From schema.Version
+
+	// To is the schema version of the documents after they are migrated.
+	To schema.Version
+
+	// Description of the changes made to the document.
+	Description string
+
+	// Migrate updates the document in place. The schema version of the
+	// document is updated after the migrator is applied.
+	Migrate func(doc map[string]interface{}) error
+}
+
+// RunMigrations are the migrators for run documents, in the order that they
+// are applied. A document is migrated by applying each migrator, starting with
+// the one for the schema version of the document, until it reaches
+// RunSchemaVersion. The migrators for the run documents saved by previous
+// versions of Porter are defined in the migrations package, and are set with
+// InstallationStore.SetRunMigrations.
+type RunMigrations []RunMigrator
+
+// Validate that each schema version has a single migrator, and that every
+// version can be migrated to RunSchemaVersion.
+func (m RunMigrations) Validate() error {
+	from := make(map[schema.Version]bool, len(m))
+	for _, migrator := range m {
+		if from[migrator.From] {
+			return fmt.Errorf("more than one run migrator is defined for schema version %q", migrator.From)
+		}
+		from[migrator.From] = true
+
+		if migrator.Migrate == nil {
+			return fmt.Errorf("the run migrator from schema version %q does not define Migrate", migrator.From)
+		}
+	}
+
+	for _, migrator := range m {
+		if _, err := m.path(migrator.From); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// path returns the migrators that are applied, in order, to migrate a
+// document from the specified schema version to RunSchemaVersion.
+func (m RunMigrations) path(from schema.Version) ([]RunMigrator, error) {
+	var path []RunMigrator
+	version := from
+	for version != RunSchemaVersion {
+		migrator, ok := m.find(version)
+		if !ok {
+			return nil, fmt.Errorf("no migration is defined for runs with schema version %q to the supported schema version %s", version, RunSchemaVersion)
+		}
+
+		// Guard against migrators that loop back to a previous version
+		if len(path) == len(m) {
+			return nil, fmt.Errorf("the run migrators from schema version %q do not reach the supported schema version %s", from, RunSchemaVersion)
+		}
+		path = append(path, migrator)
+		version = migrator.To
+	}
+	return path, nil
+}
+
+func (m RunMigrations) find(from schema.Version) (RunMigrator, bool) {
+	for _, migrator := range m {
+		if migrator.From == from {
+			return migrator, true
+		}
+	}
+	return RunMigrator{}, false
+}
+
+// Migrate a run document to RunSchemaVersion, returning the schema
+// version of the document before it was migrated.
+func (m RunMigrations) Migrate(doc map[string]interface{}) (schema.Version, error) {
+	from := schema.Version(fmt.Sprint(doc["schemaVersion"]))
+	if doc["schemaVersion"] == nil {
+		from = ""
+	}
+
+	path, err := m.path(from)
+	if err != nil {
+		return from, err
+	}
+
+	for _, migrator := range path {
+		if err := migrator.Migrate(doc); err != nil {
+			return from, fmt.Errorf("error migrating run from schema version %q to %s: %w", migrator.From, migrator.To, err)
+		}
+		doc["schemaVersion"] = string(migrator.To)
+	}
+	return from, nil
+}
+
+// decodeRun converts a run document to a Run, migrating documents saved with
+// an older schema to the current schema. An error is returned when the
+// document cannot be migrated, for example because it was saved by a newer
+// version of Porter.
+func (m RunMigrations) decodeRun(data json.RawMessage) (Run, error) {
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return Run{}, err
+	}
+	if run.SchemaVersion == RunSchemaVersion {
+		return run, nil
+	}
+
+	migrated, _, err := m.migrateRun(data)
+	if err != nil {
+		return Run{}, fmt.Errorf("error migrating run %s: %w", run.ID, err)
+	}
+	return migrated, nil
+}
+
+// migrateRun migrates a run document to the current schema, returning the
+// migrated run and the schema version of the document before it was migrated.
+func (m RunMigrations) migrateRun(data json.RawMessage) (Run, schema.Version, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return Run{}, "", fmt.Errorf("error parsing the run document: %w", err)
+	}
+
+	from, err := m.Migrate(doc)
+	if err != nil {
+		return Run{}, from, err
+	}
+
+	migratedData, err := json.Marshal(doc)
+	if err != nil {
+		return Run{}, from, fmt.Errorf("error marshaling the migrated run document: %w", err)
+	}
+
+	var run Run
+	if err = json.Unmarshal(migratedData, &run); err != nil {
+		return Run{}, from, fmt.Errorf("the migrated run document is invalid: %w", err)
+	}
+	return run, from, nil
+}
+
+// MigrateRunsOptions are the options for migrating the run documents to the
+// current schema.
+type MigrateRunsOptions struct {
+	// Namespace of the runs to migrate. Use * to migrate all namespaces.
+	Namespace string
+
+	// DryRun reports the runs that would be migrated without saving them.
+	DryRun bool
+}
+
+// RunMigrationStatus is the outcome of migrating a run document.
+type RunMigrationStatus struct {
+	// Namespace of the installation.
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// Installation name.
+	Installation string `json:"installation" yaml:"installation"`
+
+	// RunID of the migrated run.
+	RunID string `json:"runId" yaml:"runId"`
+
+	// From is the schema version of the document before it was migrated.
+	From schema.Version `json:"from" yaml:"from"`
+
+	// To is the schema version of the document after it was migrated.
+	To schema.Version `json:"to" yaml:"to"`
+
+	// Status of the migration, for example RunMigrationMigrated.
+	Status string `json:"status" yaml:"status"`
+
+	// Error message when the document could not be migrated.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// RunMigrationReport lists the run documents that were migrated.
+type RunMigrationReport struct {
+	// Runs that used an older schema version.
+	Runs []RunMigrationStatus `json:"runs" yaml:"runs"`
+}
+
+// Failed returns the number of runs that could not be migrated.
+func (r RunMigrationReport) Failed() int {
+	var count int
+	for _, run := range r.Runs {
+		if run.Status == RunMigrationFailed {
+			count++
+		}
+	}
+	return count
+}
+
+// MigrateRuns migrates the run documents saved with an older schema to the
+// current schema, and saves them. Runs are also migrated when they are read,
+// so this is only required to update the stored documents. Every run is
+// attempted, and the status of each run is included in the report.
+func (s InstallationStore) MigrateRuns(ctx context.Context, opts MigrateRunsOptions) (RunMigrationReport, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if !opts.DryRun {
+		if err := checkWritable(s.store, CollectionRuns, "update"); err != nil {
+			return RunMigrationReport{}, span.Error(err)
+		}
+	}
+
+	filter := bson.M{"schemaVersion": bson.M{"$ne": RunSchemaVersion}}
+	if opts.Namespace != "*" {
+		if err := authorize(ctx, s.authz, VerbWrite, CollectionRuns, opts.Namespace, ""); err != nil {
+			return RunMigrationReport{}, span.Error(err)
+		}
+		filter["namespace"] = opts.Namespace
+	}
+	// Skip the runs in namespaces that the subject may not change
+	allowed := newNamespaceAuthorizer(s.authz, VerbWrite, CollectionRuns)
+
+	var docs []json.RawMessage
+	findOpts := FindOptions{Filter: filter, Sort: []string{"_id"}}
+	if err := s.store.Find(ctx, CollectionRuns, findOpts, &docs); err != nil {
+		return RunMigrationReport{}, span.Error(fmt.Errorf("error listing runs: %w", err))
+	}
+
+	report := RunMigrationReport{Runs: make([]RunMigrationStatus, 0, len(docs))}
+	var bigErr *multierror.Error
+	for _, doc := range docs {
+		var header struct {
+			ID           string `json:"_id"`
+			Namespace    string `json:"namespace"`
+			Installation string `json:"installation"`
+		}
+		if err := json.Unmarshal(doc, &header); err != nil {
+			err = fmt.Errorf("error parsing the run document: %w", err)
+			report.Runs = append(report.Runs, RunMigrationStatus{To: RunSchemaVersion, Status: RunMigrationFailed, Error: err.Error()})
+			bigErr = multierror.Append(bigErr, err)
+			continue
+		}
+		if ok, err := allowed.Allows(ctx, header.Namespace); err != nil {
+			return RunMigrationReport{}, span.Error(err)
+		} else if !ok {
+			continue
+		}
+
+		status := RunMigrationStatus{
+			Namespace:    header.Namespace,
+			Installation: header.Installation,
+			RunID:        header.ID,
+			To:           RunSchemaVersion,
+		}
+
+		run, from, err := s.runMigrations.migrateRun(doc)
+		status.From = from
+		if err == nil && !opts.DryRun {
+			err = s.saveMigratedRun(ctx, run)
+		}
+
+		switch {
+		case err != nil:
+			status.Status = RunMigrationFailed
+			status.Error = err.Error()
+			bigErr = multierror.Append(bigErr, fmt.Errorf("error migrating run %s: %w", status.RunID, err))
+		case opts.DryRun:
+			status.Status = RunMigrationPending
+		default:
+			status.Status = RunMigrationMigrated
+		}
+		report.Runs = append(report.Runs, status)
+	}
+
+	return report, span.Error(bigErr.ErrorOrNil())
+}
+
+// saveMigratedRun saves a run that was migrated to the current schema, and
+// records the migrated run in the run ledger. The parameters are compacted
+// the same way as when a run is inserted.
+func (s InstallationStore) saveMigratedRun(ctx context.Context, run Run) error {
+	run.CompactParameters()
+	recordHash, err := runLedgerHash(run)
+	if err != nil {
+		return err
+	}
+	return s.saveWithLedger(ctx, LedgerKindRun, run.Namespace, run.Installation, run.ID, recordHash, func() error {
+		return s.store.Update(ctx, CollectionRuns, UpdateOptions{Document: run})
+	})
+}
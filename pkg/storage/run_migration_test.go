@@ -0,0 +1,250 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/cnabio/cnab-go/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRunMigrations migrate runs from 0.9.0, where the change ticket was named
+// ticket, through 1.0.0 to the current schema.
+var testRunMigrations = RunMigrations{
+	{
+		From: "0.9.0",
+		To:   "1.0.0",
+		Migrate: func(doc map[string]interface{}) error {
+			doc["changeTicket"] = doc["ticket"]
+			delete(doc, "ticket")
+			return nil
+		},
+	},
+	{
+		From: "1.0.0",
+		To:   RunSchemaVersion,
+		Migrate: func(doc map[string]interface{}) error {
+			doc["labels"] = map[string]interface{}{"migrated": "true"}
+			return nil
+		},
+	},
+}
+
+func TestRunMigrations_Validate(t *testing.T) {
+	noop := func(map[string]interface{}) error { return nil }
+
+	testcases := []struct {
+		name       string
+		migrations RunMigrations
+		wantErr    string
+	}{
+		{name: "chain", migrations: testRunMigrations},
+		{name: "duplicate", wantErr: "more than one run migrator", migrations: RunMigrations{
+			{From: "1.0.0", To: RunSchemaVersion, Migrate: noop},
+			{From: "1.0.0", To: "1.0.1", Migrate: noop},
+		}},
+		{name: "missing migrate", wantErr: "does not define Migrate", migrations: RunMigrations{
+			{From: "1.0.0", To: RunSchemaVersion},
+		}},
+		{name: "gap", wantErr: `no migration is defined for runs with schema version "1.0.1"`, migrations: RunMigrations{
+			{From: "1.0.0", To: "1.0.1", Migrate: noop},
+		}},
+		{name: "loop", wantErr: "do not reach the supported schema version", migrations: RunMigrations{
+			{From: "1.0.0", To: "1.0.1", Migrate: noop},
+			{From: "1.0.1", To: "1.0.0", Migrate: noop},
+		}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.migrations.Validate()
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunMigrations_Migrate(t *testing.T) {
+	doc := map[string]interface{}{"schemaVersion": "0.9.0", "ticket": "CHG-123"}
+	from, err := testRunMigrations.Migrate(doc)
+	require.NoError(t, err)
+	assert.Equal(t, schema.Version("0.9.0"), from)
+	assert.Equal(t, map[string]interface{}{
+		"schemaVersion": string(RunSchemaVersion),
+		"changeTicket":  "CHG-123",
+		"labels":        map[string]interface{}{"migrated": "true"},
+	}, doc, "each migrator should be applied in order")
+
+	doc = map[string]interface{}{"schemaVersion": "2.0.0"}
+	_, err = testRunMigrations.Migrate(doc)
+	require.ErrorContains(t, err, `no migration is defined for runs with schema version "2.0.0"`)
+}
+
+func TestInstallationStore_MigrateRuns(t *testing.T) {
+	ctx := context.Background()
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+	cp.InstallationStore.runMigrations = testRunMigrations
+
+	inst := cp.CreateInstallation(NewInstallation("dev", "mybuns"))
+	current := cp.CreateRun(inst.NewRun("install"))
+
+	// Save runs with an older, and an unsupported, schema version
+	insertRun := func(schemaVersion string) Run {
+		run := inst.NewRun("upgrade")
+		data, err := json.Marshal(run)
+		require.NoError(t, err)
+		var doc map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &doc))
+		doc["schemaVersion"] = schemaVersion
+		doc["ticket"] = "CHG-123"
+		err = cp.store.Insert(ctx, CollectionRuns, InsertOptions{Documents: []interface{}{doc}})
+		require.NoError(t, err)
+		return run
+	}
+	old := insertRun("0.9.0")
+	unsupported := insertRun("2.0.0")
+
+	t.Run("lazy", func(t *testing.T) {
+		run, err := cp.GetRun(ctx, old.ID)
+		require.NoError(t, err)
+		assert.Equal(t, RunSchemaVersion, run.SchemaVersion)
+		assert.Equal(t, "CHG-123", run.ChangeTicket, "the run should be migrated when it is read")
+
+		_, err = cp.GetRun(ctx, unsupported.ID)
+		require.ErrorContains(t, err, "error migrating run "+unsupported.ID, "runs that cannot be migrated should not be read")
+		require.ErrorContains(t, err, `no migration is defined for runs with schema version "2.0.0"`)
+
+		_, _, err = cp.ListRuns(ctx, "dev", "mybuns")
+		require.ErrorContains(t, err, "error migrating run "+unsupported.ID)
+	})
+
+	wantStatus := func(status string) []RunMigrationStatus {
+		failed := RunMigrationStatus{Namespace: "dev", Installation: "mybuns", RunID: unsupported.ID, From: "2.0.0", To: RunSchemaVersion, Status: RunMigrationFailed}
+		return []RunMigrationStatus{
+			{Namespace: "dev", Installation: "mybuns", RunID: old.ID, From: "0.9.0", To: RunSchemaVersion, Status: status},
+			failed,
+		}
+	}
+	clearErrors := func(report RunMigrationReport) []RunMigrationStatus {
+		runs := make([]RunMigrationStatus, len(report.Runs))
+		for i, run := range report.Runs {
+			if run.Status == RunMigrationFailed {
+				assert.Contains(t, run.Error, "no migration is defined")
+			}
+			run.Error = ""
+			runs[i] = run
+		}
+		return runs
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		report, err := cp.MigrateRuns(ctx, MigrateRunsOptions{Namespace: "*", DryRun: true})
+		require.ErrorContains(t, err, "error migrating run "+unsupported.ID)
+		assert.Equal(t, wantStatus(RunMigrationPending), clearErrors(report))
+		assert.Equal(t, 1, report.Failed())
+
+		count, err := cp.store.Count(ctx, CollectionRuns, CountOptions{Filter: map[string]interface{}{"schemaVersion": "0.9.0"}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count, "the run should not be saved during a dry run")
+	})
+
+	t.Run("migrate", func(t *testing.T) {
+		report, err := cp.MigrateRuns(ctx, MigrateRunsOptions{Namespace: "test"})
+		require.NoError(t, err)
+		assert.Empty(t, report.Runs, "only the runs in the namespace should be migrated")
+
+		report, err = cp.MigrateRuns(ctx, MigrateRunsOptions{Namespace: "dev"})
+		require.Error(t, err)
+		assert.Equal(t, wantStatus(RunMigrationMigrated), clearErrors(report))
+
+		var doc map[string]interface{}
+		err = cp.store.Get(ctx, CollectionRuns, GetOptions{ID: old.ID}, &doc)
+		require.NoError(t, err)
+		assert.Equal(t, string(RunSchemaVersion), doc["schemaVersion"], "the migrated run should be saved")
+		assert.Equal(t, "CHG-123", doc["changeTicket"])
+		assert.NotContains(t, doc, "ticket")
+		assert.Contains(t, doc, "createdAt", "the date the run was created should be saved with the migrated run")
+
+		got, err := cp.GetRun(ctx, current.ID)
+		require.NoError(t, err)
+		assert.Equal(t, current.ID, got.ID, "runs that use the current schema should not be changed")
+		assert.Empty(t, got.Labels)
+	})
+}
+
+func TestInstallationStore_MigrateRuns_CompactParameters(t *testing.T) {
+	ctx := context.Background()
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+	cp.InstallationStore.runMigrations = testRunMigrations
+
+	// Older versions of Porter saved every parameter, including the ones set
+	// to their default value
+	run := NewRun("dev", "mybuns")
+	run.Bundle = bundle.Bundle{
+		Definitions: definition.Definitions{
+			"port":   &definition.Schema{Type: "integer", Default: 8080},
+			"region": &definition.Schema{Type: "string", Default: "eastus"},
+		},
+		Parameters: map[string]bundle.Parameter{
+			"port":   {Definition: "port"},
+			"region": {Definition: "region"},
+		},
+	}
+	run.Parameters = NewParameterSet(run.Namespace, run.Installation,
+		ValueStrategy("port", "8080"),
+		ValueStrategy("region", "westus"),
+	)
+	data, err := json.Marshal(run)
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	doc["schemaVersion"] = "0.9.0"
+	require.NoError(t, cp.store.Insert(ctx, CollectionRuns, InsertOptions{Documents: []interface{}{doc}}))
+
+	report, err := cp.MigrateRuns(ctx, MigrateRunsOptions{Namespace: "*"})
+	require.NoError(t, err)
+	require.Len(t, report.Runs, 1)
+	assert.Equal(t, RunMigrationMigrated, report.Runs[0].Status)
+
+	var saved struct {
+		Parameters struct {
+			Parameters []map[string]interface{} `json:"parameters"`
+		} `json:"parameters"`
+	}
+	require.NoError(t, cp.store.Get(ctx, CollectionRuns, GetOptions{ID: run.ID}, &saved))
+	require.Len(t, saved.Parameters.Parameters, 1, "parameters set to their default should not be saved with the migrated run")
+	assert.Equal(t, "region", saved.Parameters.Parameters[0]["name"])
+
+	got, err := cp.GetRun(ctx, run.ID)
+	require.NoError(t, err)
+	params := map[string]string{}
+	for _, param := range got.GetParameters().Parameters {
+		params[param.Name] = param.Source.Value
+	}
+	assert.Equal(t, map[string]string{"port": "8080", "region": "westus"}, params, "the parameters set to their default should be restored")
+}
+
+func TestInstallationStore_MigrateRuns_InvalidDocument(t *testing.T) {
+	ctx := context.Background()
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+	cp.InstallationStore.runMigrations = testRunMigrations
+
+	doc := map[string]interface{}{"_id": "01INVALID", "schemaVersion": "0.9.0", "namespace": 5}
+	require.NoError(t, cp.store.Insert(ctx, CollectionRuns, InsertOptions{Documents: []interface{}{doc}}))
+
+	report, err := cp.MigrateRuns(ctx, MigrateRunsOptions{Namespace: "*"})
+	require.ErrorContains(t, err, "error parsing the run document")
+	require.Len(t, report.Runs, 1)
+	assert.Equal(t, RunMigrationFailed, report.Runs[0].Status)
+	assert.Equal(t, 1, report.Failed(), "runs that cannot be parsed should be reported as failed")
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"sort"
+)
+
+// RunOutputDependency records that a parameter of a run was resolved from an
+// output of another installation.
+type RunOutputDependency struct {
+	// Parameter is the name of the parameter that was resolved.
+	Parameter string `json:"parameter"`
+
+	// Namespace of the installation that generated the output.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Installation is the name of the installation that generated the output.
+	Installation string `json:"installation"`
+
+	// Output is the name of the output.
+	Output string `json:"output"`
+}
+
+// String returns the output that the parameter was resolved from, formatted
+// as [NAMESPACE/]INSTALLATION/OUTPUT.
+func (d RunOutputDependency) String() string {
+	if d.Namespace == "" {
+		return d.Installation + "/" + d.Output
+	}
+	return d.Namespace + "/" + d.Installation + "/" + d.Output
+}
+
+// SortOutputDependencies returns the output dependencies sorted by the name
+// of the parameter.
+func SortOutputDependencies(deps map[string]RunOutputDependency) []RunOutputDependency {
+	if len(deps) == 0 {
+		return nil
+	}
+
+	sorted := make([]RunOutputDependency, 0, len(deps))
+	for _, dep := range deps {
+		sorted = append(sorted, dep)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Parameter < sorted[j].Parameter
+	})
+	return sorted
+}
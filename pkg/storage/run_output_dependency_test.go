@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortOutputDependencies(t *testing.T) {
+	deps := map[string]RunOutputDependency{
+		"vpc": {Parameter: "vpc", Namespace: "platform", Installation: "network", Output: "vpc-id"},
+		"dns": {Parameter: "dns", Installation: "dns", Output: "zone"},
+	}
+
+	sorted := SortOutputDependencies(deps)
+	want := []RunOutputDependency{
+		{Parameter: "dns", Installation: "dns", Output: "zone"},
+		{Parameter: "vpc", Namespace: "platform", Installation: "network", Output: "vpc-id"},
+	}
+	assert.Equal(t, want, sorted)
+	assert.Equal(t, "dns/zone", sorted[0].String())
+	assert.Equal(t, "platform/network/vpc-id", sorted[1].String())
+
+	assert.Nil(t, SortOutputDependencies(nil))
+}
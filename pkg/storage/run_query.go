@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"get.porter.sh/porter/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ListRunsOptions are the filters and paging options used to list the runs
+// of an installation.
+type ListRunsOptions struct {
+	// Namespace of the installation.
+	Namespace string
+
+	// Installation name.
+	Installation string
+
+	// Action limits the results to runs of the action, e.g. upgrade.
+	Action string
+
+	// Status limits the results to runs whose most recent result has the status,
+	// e.g. failed.
+	Status string
+
+	// Since limits the results to runs created at or after the time.
+	Since time.Time
+
+	// Until limits the results to runs created before the time.
+	Until time.Time
+
+	// LabelSelector limits the results to runs with matching labels.
+	LabelSelector LabelSelector
+
+	// Skip is the number of runs to skip, counting back from the most recent run.
+	Skip int64
+
+	// Limit is the maximum number of runs to return.
+	Limit int64
+}
+
+// ToFindOptions converts the options into a query against the runs collection.
+// When the options page through the runs, the most recent runs are selected first.
+func (o ListRunsOptions) ToFindOptions() FindOptions {
+	filter := bson.M{
+		"namespace":    o.Namespace,
+		"installation": o.Installation,
+	}
+	if o.Action != "" {
+		filter["action"] = o.Action
+	}
+
+	// Compare against the date the run was created, which is saved in UTC.
+	// Runs saved before the run schema 1.2.0 only have the date once they are
+	// migrated with porter storage migrate.
+	created := bson.M{}
+	if !o.Since.IsZero() {
+		created["$gte"] = o.Since.UTC()
+	}
+	if !o.Until.IsZero() {
+		created["$lt"] = o.Until.UTC()
+	}
+	if len(created) > 0 {
+		filter["createdAt"] = created
+	}
+	o.LabelSelector.ApplyToFilter(filter)
+
+	// Sort by when the run was created, with the id as a tiebreaker, because
+	// the ids are only ordered when they were generated with the same id strategy.
+	opts := FindOptions{
+		Sort:   []string{"createdAt", "_id"},
+		Filter: filter,
+		Skip:   o.Skip,
+		Limit:  o.Limit,
+	}
+	if o.isPaged() {
+		opts.Sort = []string{"-createdAt", "-_id"}
+	}
+	return opts
+}
+
+func (o ListRunsOptions) isPaged() bool {
+	return o.Skip > 0 || o.Limit > 0
+}
+
+func (o ListRunsOptions) isFiltered() bool {
+	return o.isPaged() || o.Action != "" || o.Status != "" || !o.Since.IsZero() || !o.Until.IsZero() || len(o.LabelSelector) > 0
+}
+
+// ListRunsWithOptions returns the runs of an installation that match the
+// options, sorted in ascending order by when they were created, along with the
+// results of each run. The runs are filtered and paged by the storage backend.
+func (s InstallationStore) ListRunsWithOptions(ctx context.Context, opts ListRunsOptions) ([]Run, map[string][]Result, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	findOpts := opts.ToFindOptions()
+	if opts.Status != "" {
+		runIDs, err := s.findRunIDsByStatus(ctx, opts)
+		if err != nil {
+			return nil, nil, span.Error(err)
+		}
+		findOpts.Filter["_id"] = bson.M{"$in": runIDs}
+	}
+
+	var docs []json.RawMessage
+	err := s.store.Find(ctx, CollectionRuns, findOpts, &docs)
+	if err != nil {
+		return nil, nil, span.Error(err)
+	}
+	runs := make([]Run, len(docs))
+	for i, doc := range docs {
+		if runs[i], err = s.runMigrations.decodeRun(doc); err != nil {
+			return nil, nil, span.Error(fmt.Errorf("error reading run: %w", err))
+		}
+	}
+	runs, err = filterAuthorized(ctx, s.authz, CollectionRuns, runs, func(r Run) string { return r.Namespace })
+	if err != nil {
+		return nil, nil, span.Error(err)
+	}
+	if opts.isPaged() {
+		// The page was selected starting from the most recent run, put it back in order
+		for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+			runs[i], runs[j] = runs[j], runs[i]
+		}
+	}
+
+	resultsMap := make(map[string][]Result, len(runs))
+	runIDs := make([]string, len(runs))
+	for i, run := range runs {
+		resultsMap[run.ID] = []Result{}
+		runIDs[i] = run.ID
+	}
+	if len(runs) == 0 {
+		return runs, resultsMap, nil
+	}
+
+	resultOpts := FindOptions{
+		Sort: []string{"_id"},
+		Filter: bson.M{
+			"namespace":    opts.Namespace,
+			"installation": opts.Installation,
+			"pending":      committedFilter,
+		},
+	}
+	if opts.isFiltered() {
+		// Only retrieve the results of the selected runs
+		resultOpts.Filter["runId"] = bson.M{"$in": runIDs}
+	}
+	var results []Result
+	err = s.store.Find(ctx, CollectionResults, resultOpts, &results)
+	if err != nil {
+		return runs, nil, span.Error(err)
+	}
+
+	for _, res := range results {
+		if _, ok := resultsMap[res.RunID]; ok {
+			resultsMap[res.RunID] = append(resultsMap[res.RunID], res)
+		}
+	}
+
+	return runs, resultsMap, nil
+}
+
+// findRunIDsByStatus returns the IDs of the runs of an installation whose most
+// recent result has the requested status. The most recent result is selected
+// by when it was created, because result IDs are not always generated in order.
+func (s InstallationStore) findRunIDsByStatus(ctx context.Context, opts ListRunsOptions) ([]string, error) {
+	findOpts := FindOptions{
+		Sort: []string{"_id"},
+		Filter: bson.M{
+			"namespace":    opts.Namespace,
+			"installation": opts.Installation,
+			"pending":      committedFilter,
+		},
+		Select: bson.D{{Key: "runId", Value: 1}, {Key: "status", Value: 1}, {Key: "created", Value: 1}},
+	}
+	var results []Result
+	if err := s.store.Find(ctx, CollectionResults, findOpts, &results); err != nil {
+		return nil, err
+	}
+
+	var runIDs []string
+	latest := make(map[string]Result, len(results))
+	for _, result := range results {
+		last, ok := latest[result.RunID]
+		if !ok {
+			runIDs = append(runIDs, result.RunID)
+		}
+		if !ok || !result.Created.Before(last.Created) {
+			latest[result.RunID] = result
+		}
+	}
+
+	matched := make([]string, 0, len(runIDs))
+	for _, runID := range runIDs {
+		if latest[runID].Status == opts.Status {
+			matched = append(matched, runID)
+		}
+	}
+	return matched, nil
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationStore_ListRunsWithOptions(t *testing.T) {
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	start := time.Now().Add(-time.Hour)
+	createRun := func(action string, created time.Time, statuses ...string) Run {
+		run := NewRun("dev", "mysql")
+		run.Action = action
+		run.Created = created
+		cp.CreateRun(run)
+		for _, status := range statuses {
+			cp.CreateResult(run.NewResult(status))
+		}
+		return run
+	}
+	install := createRun(cnab.ActionInstall, start, cnab.StatusRunning, cnab.StatusSucceeded)
+	upgrade1 := createRun(cnab.ActionUpgrade, start.Add(10*time.Minute), cnab.StatusRunning, cnab.StatusFailed)
+	upgrade2 := createRun(cnab.ActionUpgrade, start.Add(20*time.Minute), cnab.StatusRunning, cnab.StatusSucceeded)
+	upgrade3 := createRun(cnab.ActionUpgrade, start.Add(30*time.Minute), cnab.StatusRunning)
+
+	// A run of another installation should never be returned
+	other := NewRun("dev", "wordpress")
+	other.Action = cnab.ActionUpgrade
+	cp.CreateRun(other)
+	cp.CreateResult(other.NewResult(cnab.StatusFailed))
+
+	testcases := []struct {
+		name    string
+		opts    ListRunsOptions
+		wantIDs []string
+	}{
+		{name: "no filters", wantIDs: []string{install.ID, upgrade1.ID, upgrade2.ID, upgrade3.ID}},
+		{name: "action", opts: ListRunsOptions{Action: cnab.ActionUpgrade}, wantIDs: []string{upgrade1.ID, upgrade2.ID, upgrade3.ID}},
+		{name: "status of last result", opts: ListRunsOptions{Status: cnab.StatusSucceeded}, wantIDs: []string{install.ID, upgrade2.ID}},
+		{name: "status of earlier result", opts: ListRunsOptions{Status: cnab.StatusRunning}, wantIDs: []string{upgrade3.ID}},
+		{name: "action and status", opts: ListRunsOptions{Action: cnab.ActionUpgrade, Status: cnab.StatusFailed}, wantIDs: []string{upgrade1.ID}},
+		{name: "since", opts: ListRunsOptions{Since: start.Add(10 * time.Minute)}, wantIDs: []string{upgrade1.ID, upgrade2.ID, upgrade3.ID}},
+		{name: "until", opts: ListRunsOptions{Until: start.Add(10 * time.Minute)}, wantIDs: []string{install.ID}},
+		{name: "since in another time zone", opts: ListRunsOptions{Since: start.Add(10 * time.Minute).In(time.FixedZone("UTC+5", 5*60*60))}, wantIDs: []string{upgrade1.ID, upgrade2.ID, upgrade3.ID}},
+		{name: "since and until", opts: ListRunsOptions{Since: start.Add(5 * time.Minute), Until: start.Add(25 * time.Minute)}, wantIDs: []string{upgrade1.ID, upgrade2.ID}},
+		{name: "limit", opts: ListRunsOptions{Limit: 2}, wantIDs: []string{upgrade2.ID, upgrade3.ID}},
+		{name: "skip and limit", opts: ListRunsOptions{Skip: 1, Limit: 2}, wantIDs: []string{upgrade1.ID, upgrade2.ID}},
+		{name: "skip past the end", opts: ListRunsOptions{Skip: 10}, wantIDs: []string{}},
+		{name: "filter and limit", opts: ListRunsOptions{Action: cnab.ActionUpgrade, Status: cnab.StatusSucceeded, Limit: 1}, wantIDs: []string{upgrade2.ID}},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tc.opts.Namespace = "dev"
+			tc.opts.Installation = "mysql"
+			runs, results, err := cp.ListRunsWithOptions(context.Background(), tc.opts)
+			require.NoError(t, err)
+
+			gotIDs := make([]string, len(runs))
+			for i, run := range runs {
+				gotIDs[i] = run.ID
+				assert.Len(t, results[run.ID], len(mustListResults(t, cp, run.ID)), "expected all the results of run %s", run.ID)
+			}
+			assert.Equal(t, tc.wantIDs, gotIDs, "expected the runs in ascending order")
+			assert.Len(t, results, len(runs), "expected only the results of the selected runs")
+		})
+	}
+}
+
+func TestInstallationStore_ListRunsWithOptions_StatusByCreated(t *testing.T) {
+	cp := NewTestInstallationProvider(t)
+	defer cp.Close()
+
+	// The ids of the results are not in the order they were created
+	run := NewRun("dev", "mysql")
+	cp.CreateRun(run)
+	running := run.NewResult(cnab.StatusRunning)
+	running.ID = "b"
+	cp.CreateResult(running)
+	failed := run.NewResult(cnab.StatusFailed)
+	failed.ID = "a"
+	failed.Created = running.Created.Add(time.Second)
+	cp.CreateResult(failed)
+
+	opts := ListRunsOptions{Namespace: "dev", Installation: "mysql", Status: cnab.StatusFailed}
+	runs, _, err := cp.ListRunsWithOptions(context.Background(), opts)
+	require.NoError(t, err)
+	require.Len(t, runs, 1, "the status of the run should be the most recently created result")
+	assert.Equal(t, run.ID, runs[0].ID)
+
+	opts.Status = cnab.StatusRunning
+	runs, _, err = cp.ListRunsWithOptions(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
+
+func mustListResults(t *testing.T, cp *TestInstallationProvider, runID string) []Result {
+	results, err := cp.ListResults(context.Background(), runID)
+	require.NoError(t, err)
+	return results
+}
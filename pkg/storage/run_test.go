@@ -12,8 +12,36 @@ import (
 	"github.com/cnabio/cnab-go/bundle/definition"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+func TestRun_TraceAttributes(t *testing.T) {
+	run := NewRun("dev", "mybuns")
+	run.Action = cnab.ActionUpgrade
+	result := run.NewResult(cnab.StatusSucceeded)
+	output := result.NewOutput("password", []byte("topsecret"))
+
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("installation", "dev/mybuns"),
+		attribute.String("runId", run.ID),
+		attribute.String("action", cnab.ActionUpgrade),
+	}, run.TraceAttributes())
+
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("installation", "dev/mybuns"),
+		attribute.String("runId", run.ID),
+		attribute.String("resultId", result.ID),
+		attribute.String("status", cnab.StatusSucceeded),
+	}, result.TraceAttributes())
+
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("installation", "dev/mybuns"),
+		attribute.String("runId", run.ID),
+		attribute.String("resultId", result.ID),
+		attribute.String("output", "password"),
+	}, output.TraceAttributes(), "the value of the output should not be traced")
+}
+
 func TestRun_NewResultFrom(t *testing.T) {
 	run := NewRun("dev", "mybuns")
 	cnabResult := cnab.Result{
@@ -198,6 +226,64 @@ func TestRun_TypedParameterValues(t *testing.T) {
 	}
 }
 
+func TestRun_CompactParameters(t *testing.T) {
+	bun := bundle.Bundle{
+		Definitions: definition.Definitions{
+			"port": &definition.Schema{
+				Type:    "integer",
+				Default: 8080,
+			},
+			"region": &definition.Schema{
+				Type:    "string",
+				Default: "eastus",
+			},
+			"password": &definition.Schema{
+				Type:      "string",
+				Default:   "changeme",
+				WriteOnly: &[]bool{true}[0],
+			},
+			"name": &definition.Schema{
+				Type: "string",
+			},
+		},
+		Parameters: map[string]bundle.Parameter{
+			"port":     {Definition: "port"},
+			"region":   {Definition: "region"},
+			"password": {Definition: "password"},
+			"name":     {Definition: "name"},
+			"cleanup":  {Definition: "port", ApplyTo: []string{"uninstall"}},
+		},
+	}
+
+	run := NewRun("dev", "mybuns")
+	run.Action = cnab.ActionInstall
+	run.Bundle = bun
+	run.Parameters = NewParameterSet(run.Namespace, run.Installation,
+		ValueStrategy("port", "8080"),
+		ValueStrategy("region", "westus"),
+		ValueStrategy("name", "porter-test"),
+		sanitizedParam(secrets.Strategy{Name: "password"}, run.ID),
+	)
+	full := run.Parameters.Parameters
+
+	run.CompactParameters()
+	wantCompact := []secrets.Strategy{
+		ValueStrategy("region", "westus"),
+		ValueStrategy("name", "porter-test"),
+		sanitizedParam(secrets.Strategy{Name: "password"}, run.ID),
+	}
+	assert.Equal(t, wantCompact, run.Parameters.Parameters, "only parameters that differ from their default should be kept")
+	assert.Len(t, full, 4, "the original parameters should not be modified")
+
+	params := run.GetParameters()
+	assert.ElementsMatch(t, full, params.Parameters, "the parameters set to their default should be restored")
+	assert.Equal(t, wantCompact, run.Parameters.Parameters, "GetParameters should not modify the run")
+
+	typed := run.TypedParameterValues()
+	assert.Equal(t, 8080, typed["port"])
+	assert.NotContains(t, typed, "cleanup", "parameters that don't apply to the action should not be restored")
+}
+
 func TestRun_MarshalJSON(t *testing.T) {
 	// Verify that when a run is marshaled that the bundle field is saved as an escaped json string
 	r1 := Run{ID: "foo", Bundle: exampleBundle}
@@ -213,3 +299,35 @@ func TestRun_MarshalJSON(t *testing.T) {
 
 	assert.Equal(t, r1, r2, "The run did not survive the round trip")
 }
+
+func TestRun_AddNote(t *testing.T) {
+	r := NewRun("dev", "mysql")
+
+	n := r.AddNote("rolled back manually, see INC-123")
+	assert.Equal(t, "rolled back manually, see INC-123", n.Note)
+	assert.False(t, n.Created.IsZero(), "the note should be timestamped")
+	require.Len(t, r.Notes, 1)
+	assert.Equal(t, n, r.Notes[0])
+
+	data, err := json.Marshal(r)
+	require.NoError(t, err, "Marshal failed")
+
+	var r2 Run
+	require.NoError(t, json.Unmarshal(data, &r2), "Unmarshal failed")
+	require.Len(t, r2.Notes, 1, "the notes should be persisted with the run")
+	assert.Equal(t, n.Note, r2.Notes[0].Note)
+}
+
+func TestRun_IsEphemeralOutput(t *testing.T) {
+	r := NewRun("dev", "mysql")
+	r.Bundle = bundle.Bundle{
+		Custom: map[string]interface{}{
+			cnab.EphemeralOutputsExtensionKey: []interface{}{"bootstrap-token"},
+		},
+	}
+	r.EphemeralOutputs = []string{"admin-password"}
+
+	assert.True(t, r.IsEphemeralOutput("bootstrap-token"), "outputs declared ephemeral by the bundle should not be persisted")
+	assert.True(t, r.IsEphemeralOutput("admin-password"), "outputs requested ephemeral by the user should not be persisted")
+	assert.False(t, r.IsEphemeralOutput("connection-string"))
+}
@@ -0,0 +1,33 @@
+package storage
+
+import "time"
+
+// RunTriggerRegistryPush is the type of trigger recorded on runs started
+// because a new version of the bundle was pushed to a registry.
+const RunTriggerRegistryPush = "registry-push"
+
+// RunTrigger describes the event that started a run automatically, instead of
+// a user running a porter command.
+type RunTrigger struct {
+	// Type of event, for example registry-push.
+	Type string `json:"type" yaml:"type"`
+
+	// Rule is the name of the auto-upgrade rule that started the run.
+	Rule string `json:"rule,omitempty" yaml:"rule,omitempty"`
+
+	// Source identifies the sender of the event, for example harbor, acr or ghcr.
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+
+	// EventID is the identifier that the sender assigned to the event, when
+	// the sender provides one.
+	EventID string `json:"eventId,omitempty" yaml:"eventId,omitempty"`
+
+	// Reference is the bundle reference that was pushed.
+	Reference string `json:"reference,omitempty" yaml:"reference,omitempty"`
+
+	// Digest of the bundle that was pushed.
+	Digest string `json:"digest,omitempty" yaml:"digest,omitempty"`
+
+	// Received is when Porter received the event.
+	Received time.Time `json:"received" yaml:"received"`
+}
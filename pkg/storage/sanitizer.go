@@ -1,28 +1,90 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"sort"
+	"strings"
 
 	"get.porter.sh/porter/pkg/cnab"
 	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/tracing"
 	"github.com/cnabio/cnab-go/secrets/host"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// RedactedLogValue replaces the sensitive values found in the logs captured
+// from a bundle.
+const RedactedLogValue = "*****"
+
 // Sanitizer identifies sensitive data in a database record, and replaces it with
 // a reference to a secret created by the service in an external secret store.
 type Sanitizer struct {
 	parameter ParameterSetProvider
 	secrets   secrets.Store
+	policy    SensitivityPolicy
+	audit     secrets.AuditHook
 }
 
 // NewSanitizer creates a new service for sanitizing sensitive data and save them
-// to a secret store.
+// to a secret store. Only the parameters and outputs marked sensitive by the
+// bundle are sanitized until a policy is set with SetSensitivityPolicy, and
+// sensitive outputs are not audited until a hook is set with SetAuditHook.
 func NewSanitizer(parameterstore ParameterSetProvider, secretstore secrets.Store) *Sanitizer {
 	return &Sanitizer{
 		parameter: parameterstore,
 		secrets:   secretstore,
+		policy:    ConfigSensitivityPolicy{},
+		audit:     secrets.NoopAuditHook{},
+	}
+}
+
+// SetSensitivityPolicy sets the hook that identifies sensitive parameters and
+// outputs in addition to those marked sensitive by the bundle.
+func (s *Sanitizer) SetSensitivityPolicy(policy SensitivityPolicy) {
+	s.policy = policy
+}
+
+// SetAuditHook sets the hook that is notified every time a sensitive output is
+// resolved from the secret store. Parameters and credentials are audited by
+// their stores when they are resolved.
+func (s *Sanitizer) SetAuditHook(hook secrets.AuditHook) {
+	s.audit = hook
+}
+
+// ApplySensitivityPolicy returns a copy of the bundle that also treats the
+// parameters identified by the sensitivity policy as sensitive.
+func (s *Sanitizer) ApplySensitivityPolicy(bun cnab.ExtendedBundle) (cnab.ExtendedBundle, error) {
+	var sensitive []string
+	for _, param := range bun.ListParameters() {
+		if param.Sensitive {
+			continue
+		}
+		matched, err := s.policy.IsSensitiveParameter(bun, param.Name)
+		if err != nil {
+			return bun, err
+		}
+		if matched {
+			sensitive = append(sensitive, param.Name)
+		}
+	}
+
+	if len(sensitive) == 0 {
+		return bun, nil
+	}
+	return bun.WithSensitiveParameters(sensitive...), nil
+}
+
+// IsOutputSensitive determines if the output is marked sensitive by the bundle
+// or by the sensitivity policy.
+func (s *Sanitizer) IsOutputSensitive(bun cnab.ExtendedBundle, name string) (bool, error) {
+	sensitive, err := bun.IsOutputSensitive(name)
+	if err != nil || sensitive {
+		return sensitive, err
 	}
+	return s.policy.IsSensitiveOutput(bun, name)
 }
 
 // CleanRawParameters clears out sensitive data in raw parameter values (resolved parameter values stored on a Run) before
@@ -54,15 +116,25 @@ func (s *Sanitizer) CleanRawParameters(ctx context.Context, params map[string]in
 // The id argument is used to associate the reference key with the corresponding
 // run or installation record in porter's database.
 func (s *Sanitizer) CleanParameters(ctx context.Context, dirtyParams []secrets.Strategy, bun cnab.ExtendedBundle, id string) ([]secrets.Strategy, error) {
+	ctx, span := tracing.StartSpan(ctx, attribute.String("id", id))
+	defer span.EndSpan()
+
+	bun, err := s.ApplySensitivityPolicy(bun)
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
 	cleanedParams := make([]secrets.Strategy, 0, len(dirtyParams))
+	var sensitiveValues []secrets.Secret
 	for _, param := range dirtyParams {
 		// Store sensitive hard-coded values in a secret store
 		if param.Source.Key == host.SourceValue && bun.IsSensitiveParameter(param.Name) {
 			cleaned := sanitizedParam(param, id)
-			err := s.secrets.Create(ctx, cleaned.Source.Key, cleaned.Source.Value, cleaned.Value)
-			if err != nil {
-				return nil, fmt.Errorf("failed to save sensitive param to secrete store: %w", err)
-			}
+			sensitiveValues = append(sensitiveValues, secrets.Secret{
+				KeyName:  cleaned.Source.Key,
+				KeyValue: cleaned.Source.Value,
+				Value:    cleaned.Value,
+			})
 
 			cleanedParams = append(cleanedParams, cleaned)
 		} else { // All other parameters are safe to use without cleaning
@@ -70,6 +142,12 @@ func (s *Sanitizer) CleanParameters(ctx context.Context, dirtyParams []secrets.S
 		}
 	}
 
+	// Save all the sensitive values at once, avoiding a round trip to the
+	// secret store for each parameter when the store supports it
+	if err := secrets.CreateMultiple(ctx, s.secrets, sensitiveValues); err != nil {
+		return nil, span.Error(fmt.Errorf("failed to save sensitive param to secrete store: %w", err))
+	}
+
 	if len(cleanedParams) == 0 {
 		return nil, nil
 	}
@@ -99,11 +177,118 @@ func sanitizedParam(param secrets.Strategy, id string) secrets.Strategy {
 	return param
 }
 
+// CleanInstallationSecrets removes the sensitive parameter values of an
+// installation that were saved to the secret store by CleanParameters. It
+// should be called when the installation is deleted, so that the values do not
+// remain in the secret store indefinitely.
+func (s *Sanitizer) CleanInstallationSecrets(ctx context.Context, inst Installation) error {
+	ctx, span := tracing.StartSpan(ctx, installationAttribute(inst.Namespace, inst.Name))
+	defer span.EndSpan()
+
+	return span.Error(s.deleteSecrets(ctx, sanitizedParamKeys(inst.Parameters.Parameters, inst.ID)))
+}
+
+// CleanRunSecrets removes the sensitive parameter and output values of a run
+// that were saved to the secret store by the sanitizer. It should be called
+// when the run is deleted, so that the values do not remain in the secret
+// store indefinitely.
+func (s *Sanitizer) CleanRunSecrets(ctx context.Context, run Run) error {
+	ctx, span := tracing.StartSpan(ctx, run.TraceAttributes()...)
+	defer span.EndSpan()
+
+	keys := sanitizedParamKeys(run.GetParameters().Parameters, run.ID)
+	keys = append(keys, sanitizedParamKeys(run.ParameterOverrides.Parameters, run.ID)...)
+
+	// Sensitive outputs are always saved using the same key, so they can be
+	// identified from the bundle without looking up the output records.
+	bun := cnab.NewBundle(run.Bundle)
+	for name := range run.Bundle.Outputs {
+		if bun.IsEphemeralOutput(name) {
+			continue
+		}
+		if sensitive, err := s.IsOutputSensitive(bun, name); err != nil || !sensitive {
+			continue
+		}
+		keys = append(keys, sanitizedOutput(Output{RunID: run.ID, Name: name}).Key)
+	}
+
+	return span.Error(s.deleteSecrets(ctx, keys))
+}
+
+// sanitizedParamKeys returns the keys of the secrets that the sanitizer created
+// for the parameters of a record. Parameters that reference a secret provided
+// by the user are not included, because they are not owned by porter.
+func sanitizedParamKeys(params []secrets.Strategy, id string) []string {
+	var keys []string
+	for _, param := range params {
+		if param.Source == sanitizedParam(param, id).Source {
+			keys = append(keys, param.Source.Value)
+		}
+	}
+	return keys
+}
+
+// RestoreRunSecrets resolves the values of the parameter overrides and the
+// outputs of a run that were saved to the secret store when the run was
+// sanitized, keyed by their secret key. The outputs are the outputs generated
+// by the run.
+func (s *Sanitizer) RestoreRunSecrets(ctx context.Context, run Run, outputs Outputs) (map[string]string, error) {
+	ctx, span := tracing.StartSpan(ctx, attribute.String("run", run.ID))
+	defer span.EndSpan()
+
+	values := make(map[string]string)
+	for _, param := range run.ParameterOverrides.Parameters {
+		if param.Source != sanitizedParam(param, run.ID).Source {
+			continue
+		}
+
+		value, err := s.secrets.Resolve(ctx, secrets.SourceSecret, param.Source.Value)
+		if auditErr := auditStrategy(ctx, s.audit, secrets.AuditKindParameter, run.Namespace, run.Installation, param, err); auditErr != nil {
+			return nil, span.Error(auditErr)
+		}
+		if err != nil {
+			return nil, span.Error(fmt.Errorf("failed to resolve parameter %q using key %q: %w", param.Name, param.Source.Value, err))
+		}
+		values[param.Source.Value] = value
+	}
+
+	for _, output := range outputs.Value() {
+		if output.Key == "" {
+			continue
+		}
+
+		restored, err := s.RestoreOutput(ctx, output)
+		if err != nil {
+			return nil, span.Error(fmt.Errorf("failed to resolve output %q using key %q: %w", output.Name, output.Key, err))
+		}
+		values[output.Key] = string(restored.Value)
+	}
+	return values, nil
+}
+
+// deleteSecrets removes each key from the secret store, skipping duplicates.
+func (s *Sanitizer) deleteSecrets(ctx context.Context, keys []string) error {
+	deleted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if deleted[key] {
+			continue
+		}
+		if err := s.secrets.Delete(ctx, secrets.SourceSecret, key); err != nil {
+			return fmt.Errorf("failed to remove sensitive value %s from the secret store: %w", key, err)
+		}
+		deleted[key] = true
+	}
+	return nil
+}
+
 // RestoreParameterSet resolves the raw parameter data from a secrets store.
 func (s *Sanitizer) RestoreParameterSet(ctx context.Context, pset ParameterSet, bun cnab.ExtendedBundle) (map[string]interface{}, error) {
+	ctx, span := tracing.StartSpan(ctx, attribute.String("parameterSet", pset.String()))
+	defer span.EndSpan()
+
 	params, err := s.parameter.ResolveAll(ctx, pset)
 	if err != nil {
-		return nil, err
+		return nil, span.Error(err)
 	}
 
 	resolved := make(map[string]interface{})
@@ -120,20 +305,30 @@ func (s *Sanitizer) RestoreParameterSet(ctx context.Context, pset ParameterSet,
 
 }
 
-// CleanOutput clears data that's defined as sensitive on the bundle definition
-// by storing the raw data into a secret store and store it's reference key onto
-// the output record.
+// CleanOutput clears data that's defined as sensitive on the bundle definition,
+// or by the sensitivity policy, by storing the raw data into a secret store and store it's reference key onto
+// the output record. The value of an ephemeral output is cleared without
+// saving it to the secret store.
 func (s *Sanitizer) CleanOutput(ctx context.Context, output Output, bun cnab.ExtendedBundle) (Output, error) {
+	ctx, span := tracing.StartSpan(ctx, output.TraceAttributes()...)
+	defer span.EndSpan()
+
 	// Skip outputs not defined in the bundle, e.g. io.cnab.outputs.invocationImageLogs
 	_, ok := output.GetSchema(bun)
 	if !ok {
 		return output, nil
 	}
 
-	sensitive, err := bun.IsOutputSensitive(output.Name)
+	// Ephemeral outputs must never live in any store
+	if bun.IsEphemeralOutput(output.Name) {
+		output.Value = nil
+		return output, nil
+	}
+
+	sensitive, err := s.IsOutputSensitive(bun, output.Name)
 	if err != nil {
 		output.Value = nil
-		return output, err
+		return output, span.Error(err)
 	}
 
 	if !sensitive {
@@ -145,7 +340,7 @@ func (s *Sanitizer) CleanOutput(ctx context.Context, output Output, bun cnab.Ext
 
 	err = s.secrets.Create(ctx, secrets.SourceSecret, secretOt.Key, string(output.Value))
 	if err != nil {
-		return secretOt, err
+		return secretOt, span.Error(err)
 	}
 
 	return secretOt, nil
@@ -179,11 +374,156 @@ func (s *Sanitizer) RestoreOutput(ctx context.Context, output Output) (Output, e
 	if output.Key == "" {
 		return output, nil
 	}
+
+	ctx, span := tracing.StartSpan(ctx, output.TraceAttributes()...)
+	defer span.EndSpan()
+
 	resolved, err := s.secrets.Resolve(ctx, secrets.SourceSecret, string(output.Key))
+	if auditErr := s.auditOutput(ctx, output, err); auditErr != nil {
+		return output, span.Error(auditErr)
+	}
 	if err != nil {
-		return output, err
+		return output, span.Error(err)
 	}
 
 	output.Value = []byte(resolved)
 	return output, nil
 }
+
+// CleanOutputStream is the streaming equivalent of CleanOutput. It returns the
+// sanitized output along with a reader for the value that should be saved to
+// the installation store, which is empty when the value must not be saved.
+// Secret stores do not support streaming, so the value of a sensitive output
+// is read into memory before it is saved to the secret store.
+func (s *Sanitizer) CleanOutputStream(ctx context.Context, output Output, value io.Reader, bun cnab.ExtendedBundle) (Output, io.Reader, error) {
+	ctx, span := tracing.StartSpan(ctx, output.TraceAttributes()...)
+	defer span.EndSpan()
+
+	// Skip outputs not defined in the bundle, e.g. io.cnab.outputs.invocationImageLogs
+	_, ok := output.GetSchema(bun)
+	if !ok {
+		return output, value, nil
+	}
+
+	// Ephemeral outputs must never live in any store
+	if bun.IsEphemeralOutput(output.Name) {
+		output.Value = nil
+		return output, bytes.NewReader(nil), nil
+	}
+
+	sensitive, err := s.IsOutputSensitive(bun, output.Name)
+	if err != nil {
+		output.Value = nil
+		return output, bytes.NewReader(nil), err
+	}
+
+	if !sensitive {
+		return output, value, nil
+	}
+
+	secretOt := sanitizedOutput(output)
+
+	data, err := io.ReadAll(value)
+	if err != nil {
+		return secretOt, bytes.NewReader(nil), fmt.Errorf("error reading the value of output %s: %w", output.Name, err)
+	}
+
+	err = s.secrets.Create(ctx, secrets.SourceSecret, secretOt.Key, string(data))
+	return secretOt, bytes.NewReader(nil), span.Error(err)
+}
+
+// RestoreOutputStream is the streaming equivalent of RestoreOutput. It returns
+// a reader for the raw value of the output, given a reader for the value saved
+// in the installation store. When the output is sensitive, the saved value is
+// closed and the raw value is read from the secret store instead.
+func (s *Sanitizer) RestoreOutputStream(ctx context.Context, output Output, value io.ReadCloser) (io.ReadCloser, error) {
+	if output.Key == "" {
+		return value, nil
+	}
+	value.Close()
+
+	ctx, span := tracing.StartSpan(ctx, output.TraceAttributes()...)
+	defer span.EndSpan()
+
+	resolved, err := s.secrets.Resolve(ctx, secrets.SourceSecret, output.Key)
+	if auditErr := s.auditOutput(ctx, output, err); auditErr != nil {
+		return nil, span.Error(auditErr)
+	}
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
+	return io.NopCloser(strings.NewReader(resolved)), nil
+}
+
+// auditOutput records that a sensitive output was resolved from the secret
+// store, and whether resolving it failed.
+func (s *Sanitizer) auditOutput(ctx context.Context, output Output, resolveErr error) error {
+	event := newAuditEvent(secrets.AuditKindOutput, output.Name, output.Key, resolveErr)
+	event.Namespace = output.Namespace
+	event.Installation = output.Installation
+	event.RunID = output.RunID
+	if err := s.audit.Audit(ctx, event); err != nil {
+		return fmt.Errorf("error auditing the sensitive output %s: %w", output.Name, err)
+	}
+	return nil
+}
+
+// SensitiveRunValues returns the values of the sensitive parameters of a run.
+// A parameter is sensitive when it is marked sensitive by the bundle or by the
+// sensitivity policy, or when it was saved to the secret store when the run
+// was sanitized, for example because its value came from a sensitive output.
+// The params are the resolved values of the parameters that were passed to
+// the bundle.
+func (s *Sanitizer) SensitiveRunValues(run Run, params map[string]interface{}) ([]string, error) {
+	bun, err := s.ApplySensitivityPolicy(cnab.NewBundle(run.Bundle))
+	if err != nil {
+		return nil, err
+	}
+
+	sensitive := make(map[string]bool, len(params))
+	for name := range params {
+		sensitive[name] = bun.IsSensitiveParameter(name)
+	}
+	for _, param := range run.GetParameters().Parameters {
+		if param.Source.Key == secrets.SourceSecret {
+			sensitive[param.Name] = true
+		}
+	}
+
+	values := make([]string, 0, len(params))
+	for name, isSensitive := range sensitive {
+		value, ok := params[name]
+		if !isSensitive || !ok || value == nil {
+			continue
+		}
+		if str, ok := value.(string); ok {
+			values = append(values, str)
+		} else {
+			values = append(values, fmt.Sprintf("%v", value))
+		}
+	}
+	return values, nil
+}
+
+// RedactLogs replaces each of the sensitive values in the logs captured from
+// a bundle with RedactedLogValue, so that the values are not persisted with
+// the run.
+func (s *Sanitizer) RedactLogs(logs string, sensitiveValues []string) string {
+	// Replace the longest values first, so that a value that contains another
+	// sensitive value is redacted completely
+	values := make([]string, 0, len(sensitiveValues))
+	for _, value := range sensitiveValues {
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+
+	for _, value := range values {
+		logs = strings.ReplaceAll(logs, value, RedactedLogValue)
+	}
+	return logs
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"sort"
+
+	"get.porter.sh/porter/pkg/cnab"
+)
+
+const (
+	// SanitizedValueParameter is the kind of a SanitizedValue for a parameter.
+	SanitizedValueParameter = "parameter"
+
+	// SanitizedValueOutput is the kind of a SanitizedValue for an output.
+	SanitizedValueOutput = "output"
+
+	// SensitiveByBundle indicates that the bundle marks the value as sensitive.
+	SensitiveByBundle = "bundle"
+
+	// SensitiveByPolicy indicates that a sensitivity policy marks the value as sensitive.
+	SensitiveByPolicy = "policy"
+)
+
+// SanitizedValue describes a sensitive parameter or output of a run, and the
+// key of the secret that the sanitizer saves its value to.
+type SanitizedValue struct {
+	// Kind of value, either parameter or output.
+	Kind string `json:"kind" yaml:"kind"`
+
+	// Name of the parameter or output.
+	Name string `json:"name" yaml:"name"`
+
+	// SensitiveBy is what marks the value as sensitive, either bundle or policy.
+	SensitiveBy string `json:"sensitiveBy" yaml:"sensitiveBy"`
+
+	// Key of the secret in the secret store.
+	Key string `json:"key" yaml:"key"`
+
+	// Saved indicates that the value is already saved in the secret store
+	// with the key, instead of in Porter's database, for example it is false
+	// when the value was stored before a sensitivity policy applied to it.
+	// Outputs that the run did not generate are never saved.
+	Saved bool `json:"saved" yaml:"saved"`
+}
+
+// ReportSensitiveValues lists the parameters and outputs of a run that the
+// sanitizer treats as sensitive, with the key of the secret that each value is
+// saved to. Nothing is written to the secret store, so the report may be used
+// to preview which values are externalized before adopting a secret store or
+// a sensitivity policy. The outputs are the output records of the run, which
+// determine if the sensitive outputs were saved to the secret store.
+func (s *Sanitizer) ReportSensitiveValues(run Run, outputs Outputs) ([]SanitizedValue, error) {
+	bun := cnab.NewBundle(run.Bundle)
+	withPolicy, err := s.ApplySensitivityPolicy(bun)
+	if err != nil {
+		return nil, err
+	}
+
+	var report []SanitizedValue
+	for _, param := range run.GetParameters().Parameters {
+		sensitiveBy := ""
+		if bun.IsSensitiveParameter(param.Name) {
+			sensitiveBy = SensitiveByBundle
+		} else if withPolicy.IsSensitiveParameter(param.Name) {
+			sensitiveBy = SensitiveByPolicy
+		}
+
+		if sensitiveBy == "" {
+			continue
+		}
+
+		sanitized := sanitizedParam(param, run.ID)
+		report = append(report, SanitizedValue{
+			Kind:        SanitizedValueParameter,
+			Name:        param.Name,
+			SensitiveBy: sensitiveBy,
+			Key:         sanitized.Source.Value,
+			Saved:       param.Source == sanitized.Source,
+		})
+	}
+
+	for name, def := range run.Bundle.Outputs {
+		if !def.AppliesTo(run.Action) || bun.IsEphemeralOutput(name) {
+			continue
+		}
+
+		sensitiveBy := ""
+		if sensitive, err := bun.IsOutputSensitive(name); err != nil {
+			return nil, err
+		} else if sensitive {
+			sensitiveBy = SensitiveByBundle
+		} else if sensitive, err = s.policy.IsSensitiveOutput(bun, name); err != nil {
+			return nil, err
+		} else if sensitive {
+			sensitiveBy = SensitiveByPolicy
+		}
+
+		if sensitiveBy == "" {
+			continue
+		}
+
+		sanitized := sanitizedOutput(Output{RunID: run.ID, Name: name})
+		output, generated := outputs.GetByName(name)
+
+		report = append(report, SanitizedValue{
+			Kind:        SanitizedValueOutput,
+			Name:        name,
+			SensitiveBy: sensitiveBy,
+			Key:         sanitized.Key,
+			Saved:       generated && output.Key == sanitized.Key,
+		})
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		if report[i].Kind != report[j].Kind {
+			return report[i].Kind == SanitizedValueParameter
+		}
+		return report[i].Name < report[j].Name
+	})
+	return report, nil
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/hashicorp/go-multierror"
+)
+
+// RotateSecretsFilter selects the installations whose secrets are rotated.
+type RotateSecretsFilter struct {
+	// Namespace of the installations. Use * to rotate the secrets of
+	// installations in all namespaces.
+	Namespace string
+
+	// Installation name. When empty, the secrets of every installation in the
+	// namespace are rotated.
+	Installation string
+}
+
+// RotateSecretsResult summarizes the secrets that were rotated.
+type RotateSecretsResult struct {
+	// Rotated are the keys of the secrets that were copied to the new secret store.
+	Rotated []string
+
+	// Failed are the keys of the secrets that could not be copied to the new
+	// secret store.
+	Failed []string
+}
+
+// Rotate copies the sensitive parameter and output values that the sanitizer
+// saved for the selected installations, and their runs, from the old secret
+// store to the new secret store. The secrets are created with the same keys,
+// so the records in Porter's database do not change and resolve the values
+// from the new secret store once it is configured as the default. Values that
+// reference a secret provided by the user are not copied, and the old secret
+// store is not modified, so it can be removed after the rotation is verified.
+// Every secret is attempted, and the errors are returned together.
+func (s *Sanitizer) Rotate(ctx context.Context, installations InstallationProvider, oldStore secrets.Store, newStore secrets.Store, filter RotateSecretsFilter) (RotateSecretsResult, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	var result RotateSecretsResult
+	keys, err := s.listSanitizedKeys(ctx, installations, filter)
+	if err != nil {
+		return result, span.Error(err)
+	}
+
+	var bigErr *multierror.Error
+	for _, key := range keys {
+		value, err := oldStore.Resolve(ctx, secrets.SourceSecret, key)
+		if err != nil {
+			result.Failed = append(result.Failed, key)
+			bigErr = multierror.Append(bigErr, fmt.Errorf("could not resolve secret %s from the old secret store: %w", key, err))
+			continue
+		}
+
+		if err = newStore.Create(ctx, secrets.SourceSecret, key, value); err != nil {
+			result.Failed = append(result.Failed, key)
+			bigErr = multierror.Append(bigErr, fmt.Errorf("could not create secret %s in the new secret store: %w", key, err))
+			continue
+		}
+		result.Rotated = append(result.Rotated, key)
+	}
+
+	return result, span.Error(bigErr.ErrorOrNil())
+}
+
+// listSanitizedKeys returns the keys of the secrets that the sanitizer saved
+// for the selected installations and their runs, without duplicates.
+func (s *Sanitizer) listSanitizedKeys(ctx context.Context, installations InstallationProvider, filter RotateSecretsFilter) ([]string, error) {
+	insts, err := installations.ListInstallations(ctx, ListOptions{
+		Namespace: filter.Namespace,
+		Name:      filter.Installation,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list installations: %w", err)
+	}
+
+	var keys []string
+	seen := map[string]bool{}
+	addKeys := func(newKeys ...string) {
+		for _, key := range newKeys {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	for _, inst := range insts {
+		// The name filter matches a substring, only rotate the requested installation
+		if filter.Installation != "" && inst.Name != filter.Installation {
+			continue
+		}
+
+		addKeys(sanitizedParamKeys(inst.Parameters.Parameters, inst.ID)...)
+
+		runs, results, err := installations.ListRuns(ctx, inst.Namespace, inst.Name)
+		if err != nil {
+			return nil, fmt.Errorf("could not list runs of installation %s: %w", inst.String(), err)
+		}
+
+		for _, run := range runs {
+			addKeys(sanitizedParamKeys(run.GetParameters().Parameters, run.ID)...)
+			addKeys(sanitizedParamKeys(run.ParameterOverrides.Parameters, run.ID)...)
+
+			for _, res := range results[run.ID] {
+				outputs, err := installations.ListOutputs(ctx, res.ID)
+				if err != nil {
+					return nil, fmt.Errorf("could not list outputs of result %s: %w", res.ID, err)
+				}
+				for _, output := range outputs {
+					if output.Key != "" {
+						addKeys(output.Key)
+					}
+				}
+			}
+		}
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,102 @@
+package storage_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/porter"
+	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizer_Rotate(t *testing.T) {
+	c := portercontext.New()
+	bun, err := cnab.LoadBundle(c, filepath.Join("../porter/testdata/bundle.json"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	r := porter.NewTestPorter(t)
+	defer r.Close()
+
+	// A secret provided by the user is referenced by the run but is not owned by porter
+	require.NoError(t, r.TestSecrets.Create(ctx, secrets.SourceSecret, "user-password", "mypassword"))
+
+	createInstallation := func(namespace string, name string) (storage.Installation, storage.Run, storage.Output) {
+		inst := storage.NewInstallation(namespace, name)
+		inst.Parameters.Parameters, err = r.TestSanitizer.CleanParameters(ctx, []secrets.Strategy{
+			storage.ValueStrategy("my-second-param", "installation-secret"),
+		}, bun, inst.ID)
+		require.NoError(t, err)
+		inst = r.TestInstallations.CreateInstallation(inst)
+
+		run := inst.NewRun(cnab.ActionInstall)
+		run.Bundle = bun.Bundle
+		run.Parameters.Parameters, err = r.TestSanitizer.CleanRawParameters(ctx, map[string]interface{}{
+			"my-first-param":  1,
+			"my-second-param": "run-secret",
+		}, bun, run.ID)
+		require.NoError(t, err)
+		run.Parameters.Parameters = append(run.Parameters.Parameters, secrets.Strategy{
+			Name:   "password",
+			Source: secrets.Source{Key: secrets.SourceSecret, Value: "user-password"},
+		})
+		run = r.TestInstallations.CreateRun(run)
+
+		result := r.TestInstallations.CreateResult(run.NewResult(cnab.StatusSucceeded))
+		output := r.CreateOutput(result.NewOutput("my-first-output", []byte("output-secret")), bun)
+		return inst, run, output
+	}
+	inst, run, output := createInstallation("dev", "mybuns")
+	otherInst, _, _ := createInstallation("dev", "mybuns-other")
+	testInst, _, _ := createInstallation("test", "mybuns")
+
+	t.Run("rotate an installation", func(t *testing.T) {
+		newStore := secrets.NewTestSecretsProvider()
+		filter := storage.RotateSecretsFilter{Namespace: "dev", Installation: "mybuns"}
+		result, err := r.TestSanitizer.Rotate(ctx, r.TestInstallations, r.TestSecrets, newStore, filter)
+		require.NoError(t, err)
+
+		wantKeys := []string{inst.ID + "-my-second-param", run.ID + "-my-second-param", output.Key}
+		assert.ElementsMatch(t, wantKeys, result.Rotated)
+		assert.Empty(t, result.Failed)
+
+		wantValues := []string{"installation-secret", "run-secret", "output-secret"}
+		for i, key := range wantKeys {
+			value, err := newStore.Resolve(ctx, secrets.SourceSecret, key)
+			require.NoError(t, err, "secret %s should be created in the new secret store", key)
+			assert.Equal(t, wantValues[i], value)
+
+			_, err = r.TestSecrets.Resolve(ctx, secrets.SourceSecret, key)
+			require.NoError(t, err, "secret %s should not be removed from the old secret store", key)
+		}
+
+		_, err = newStore.Resolve(ctx, secrets.SourceSecret, "user-password")
+		require.Error(t, err, "secrets that are not owned by porter should not be rotated")
+		_, err = newStore.Resolve(ctx, secrets.SourceSecret, otherInst.ID+"-my-second-param")
+		require.Error(t, err, "installations that do not match the filter should not be rotated")
+	})
+
+	t.Run("rotate all namespaces", func(t *testing.T) {
+		newStore := secrets.NewTestSecretsProvider()
+		result, err := r.TestSanitizer.Rotate(ctx, r.TestInstallations, r.TestSecrets, newStore, storage.RotateSecretsFilter{Namespace: "*"})
+		require.NoError(t, err)
+		assert.Len(t, result.Rotated, 9)
+		assert.Contains(t, result.Rotated, testInst.ID+"-my-second-param")
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		require.NoError(t, r.TestSecrets.Delete(ctx, secrets.SourceSecret, output.Key))
+
+		newStore := secrets.NewTestSecretsProvider()
+		filter := storage.RotateSecretsFilter{Namespace: "dev", Installation: "mybuns"}
+		result, err := r.TestSanitizer.Rotate(ctx, r.TestInstallations, r.TestSecrets, newStore, filter)
+		require.ErrorContains(t, err, "could not resolve secret "+output.Key)
+		assert.Equal(t, []string{output.Key}, result.Failed)
+		assert.Len(t, result.Rotated, 2, "the remaining secrets should still be rotated")
+	})
+}
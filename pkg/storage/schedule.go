@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"get.porter.sh/porter/pkg/cron"
+	"github.com/cnabio/cnab-go/schema"
+)
+
+var _ Document = Schedule{}
+
+// Schedule runs an action on an installation on a recurring schedule, for
+// example a custom backup action every night. Schedules are run by the
+// porter agent, which creates a run of the action each time the schedule is
+// due.
+type Schedule struct {
+	ScheduleSpec `yaml:",inline"`
+	Status       ScheduleStatus `json:"status" yaml:"status" toml:"status"`
+}
+
+// ScheduleSpec represents the set of user-modifiable fields on a Schedule.
+type ScheduleSpec struct {
+	// SchemaVersion is the version of the schedule schema.
+	SchemaVersion schema.Version `json:"schemaVersion" yaml:"schemaVersion" toml:"schemaVersion"`
+
+	// Name of the schedule. Immutable.
+	Name string `json:"name" yaml:"name" toml:"name"`
+
+	// Namespace of the schedule and the installation. Immutable.
+	Namespace string `json:"namespace" yaml:"namespace" toml:"namespace"`
+
+	// Installation on which the action is run.
+	Installation string `json:"installation" yaml:"installation" toml:"installation"`
+
+	// Action to run, usually a custom action defined by the bundle.
+	Action string `json:"action" yaml:"action" toml:"action"`
+
+	// Cron expression that determines when the action is run, for example
+	// "0 2 * * *" or "@daily". Times are evaluated in UTC.
+	Cron string `json:"cron" yaml:"cron" toml:"cron"`
+
+	// Parameters passed to the action, as NAME=VALUE, which override the
+	// parameters of the installation. Parameters are stored unencrypted, use
+	// a parameter set for sensitive values.
+	Parameters []string `json:"parameters,omitempty" yaml:"parameters,omitempty" toml:"parameters,omitempty"`
+
+	// ParameterSets used by the action. When empty, the parameter sets of the
+	// installation are used.
+	ParameterSets []string `json:"parameterSets,omitempty" yaml:"parameterSets,omitempty" toml:"parameterSets,omitempty"`
+
+	// CredentialSets used by the action. When empty, the credential sets of
+	// the installation are used.
+	CredentialSets []string `json:"credentialSets,omitempty" yaml:"credentialSets,omitempty" toml:"credentialSets,omitempty"`
+
+	// Disabled schedules are not run by the agent.
+	Disabled bool `json:"disabled,omitempty" yaml:"disabled,omitempty" toml:"disabled,omitempty"`
+}
+
+// ScheduleStatus contains additional status metadata that has been set by Porter.
+type ScheduleStatus struct {
+	// Created timestamp.
+	Created time.Time `json:"created" yaml:"created" toml:"created"`
+
+	// Modified timestamp.
+	Modified time.Time `json:"modified" yaml:"modified" toml:"modified"`
+
+	// NextRun is when the agent next runs the action.
+	NextRun time.Time `json:"nextRun" yaml:"nextRun" toml:"nextRun"`
+
+	// LastRun is when the agent last ran the action.
+	LastRun *time.Time `json:"lastRun,omitempty" yaml:"lastRun,omitempty" toml:"lastRun,omitempty"`
+
+	// LastRunID is the id of the run created the last time that the action was run.
+	LastRunID string `json:"lastRunId,omitempty" yaml:"lastRunId,omitempty" toml:"lastRunId,omitempty"`
+
+	// LastError is the error returned the last time that the action was run.
+	// It is empty when the last run succeeded.
+	LastError string `json:"lastError,omitempty" yaml:"lastError,omitempty" toml:"lastError,omitempty"`
+}
+
+// NewSchedule creates a new Schedule with the required fields initialized.
+func NewSchedule(namespace string, name string) Schedule {
+	now := time.Now()
+	return Schedule{
+		ScheduleSpec: ScheduleSpec{
+			SchemaVersion: ScheduleSchemaVersion,
+			Namespace:     namespace,
+			Name:          name,
+		},
+		Status: ScheduleStatus{
+			Created:  now,
+			Modified: now,
+		},
+	}
+}
+
+func (s Schedule) DefaultDocumentFilter() map[string]interface{} {
+	return map[string]interface{}{"namespace": s.Namespace, "name": s.Name}
+}
+
+// Validate the schedule document.
+func (s Schedule) Validate() error {
+	if ScheduleSchemaVersion != s.SchemaVersion {
+		if s.SchemaVersion == "" {
+			s.SchemaVersion = "(none)"
+		}
+		return fmt.Errorf("invalid schemaVersion provided: %s. This version of Porter is compatible with %s.", s.SchemaVersion, ScheduleSchemaVersion)
+	}
+
+	if s.Name == "" {
+		return errors.New("the schedule name is required")
+	}
+	if s.Installation == "" {
+		return errors.New("the installation is required")
+	}
+	if s.Action == "" {
+		return errors.New("the action is required")
+	}
+	if _, err := cron.Parse(s.Cron); err != nil {
+		return fmt.Errorf("invalid cron expression for schedule %s: %w", s.Name, err)
+	}
+	return nil
+}
+
+// IsDue determines if the action should be run at the specified time.
+func (s Schedule) IsDue(now time.Time) bool {
+	return !s.Disabled && !s.Status.NextRun.IsZero() && !s.Status.NextRun.After(now)
+}
+
+// ScheduleNext sets when the action is next run, after the specified time.
+func (s *Schedule) ScheduleNext(after time.Time) error {
+	expr, err := cron.Parse(s.Cron)
+	if err != nil {
+		return fmt.Errorf("invalid cron expression for schedule %s: %w", s.Name, err)
+	}
+	s.Status.NextRun = expr.Next(after.UTC())
+	return nil
+}
+
+func (s Schedule) String() string {
+	return fmt.Sprintf("%s/%s", s.Namespace, s.Name)
+}
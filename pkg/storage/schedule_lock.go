@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CollectionScheduleLocks holds the locks that prevent a schedule from being
+// run by more than one agent at the same time.
+const CollectionScheduleLocks = "schedule_locks"
+
+// ErrScheduleLocked indicates that the schedule is being run by another agent.
+// You can test for this error using errors.Is(err, storage.ErrScheduleLocked).
+var ErrScheduleLocked = errors.New("the schedule is locked")
+
+// ErrScheduleLockLost indicates that a lock expired and was acquired by
+// another agent, or was removed, before it was renewed.
+var ErrScheduleLockLost = errors.New("the schedule lock was lost")
+
+// ScheduleLock is an advisory lock on a schedule, which is held by the agent
+// that runs the schedule, so that agents that check for due schedules at the
+// same time do not run it twice. The lock is a lease that expires unless it is
+// renewed, so that a lock held by an agent that crashed does not block the
+// schedule forever.
+type ScheduleLock struct {
+	// ID of the lock, which is derived from the namespace and name of the
+	// schedule so that there is only one lock per schedule.
+	ID string `json:"_id"`
+
+	// Namespace of the schedule.
+	Namespace string `json:"namespace"`
+
+	// Schedule name.
+	Schedule string `json:"schedule"`
+
+	// Owner describes who holds the lock, for example the user, host and process.
+	Owner string `json:"owner"`
+
+	// Token uniquely identifies this acquisition of the lock, so that an
+	// expired lock that was acquired by someone else is not released or renewed.
+	Token string `json:"token"`
+
+	// Acquired is when the lock was acquired.
+	Acquired time.Time `json:"acquired"`
+
+	// Expires is when the lock expires unless it is renewed.
+	Expires time.Time `json:"expires"`
+}
+
+// IsExpired indicates if the lock has expired.
+func (l ScheduleLock) IsExpired(now time.Time) bool {
+	return !now.Before(l.Expires)
+}
+
+func (l ScheduleLock) String() string {
+	if l.Namespace == "" {
+		return l.Schedule
+	}
+	return l.Namespace + "/" + l.Schedule
+}
+
+// ScheduleLockedError is returned when a schedule is locked by another agent.
+type ScheduleLockedError struct {
+	// Lock that is held by the other agent.
+	Lock ScheduleLock
+}
+
+func (e ScheduleLockedError) Error() string {
+	return fmt.Sprintf("schedule %s is locked by %s, which started running it at %s. The lock expires at %s if it is not renewed",
+		e.Lock, e.Lock.Owner, e.Lock.Acquired.Format(time.RFC3339), e.Lock.Expires.Format(time.RFC3339))
+}
+
+func (e ScheduleLockedError) Is(target error) bool {
+	return target == ErrScheduleLocked
+}
+
+// AcquireScheduleLock locks a schedule for the owner until the lock is
+// released, or it expires after the ttl unless it is renewed. When the
+// schedule is already locked, a ScheduleLockedError is returned. An expired
+// lock is replaced.
+func (s ScheduleStore) AcquireScheduleLock(ctx context.Context, namespace string, name string, owner string, ttl time.Duration) (ScheduleLock, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionSchedules, namespace, name); err != nil {
+		return ScheduleLock{}, span.Error(err)
+	}
+
+	now := time.Now()
+	lock := ScheduleLock{
+		ID:        namespace + "/" + name,
+		Namespace: namespace,
+		Schedule:  name,
+		Owner:     owner,
+		Token:     cnab.NewULID(),
+		Acquired:  now,
+		Expires:   now.Add(ttl),
+	}
+
+	// The lock is inserted with a well-known id, so only one insert succeeds
+	// even when several agents try to acquire the lock at the same time.
+	// Retry once after removing an expired lock.
+	for attempt := 0; attempt < 2; attempt++ {
+		insertErr := s.Documents.Insert(ctx, CollectionScheduleLocks, InsertOptions{Documents: []interface{}{lock}})
+		if insertErr == nil {
+			return lock, nil
+		}
+		if !errors.Is(insertErr, ErrConflict{}) {
+			return ScheduleLock{}, span.Error(fmt.Errorf("could not acquire the lock on schedule %s: %w", lock, insertErr))
+		}
+
+		existing, err := s.getScheduleLock(ctx, lock.ID)
+		if errors.Is(err, ErrNotFound{}) {
+			// The lock was released after the insert failed
+			continue
+		} else if err != nil {
+			return ScheduleLock{}, span.Error(fmt.Errorf("could not acquire the lock on schedule %s: %w", lock, insertErr))
+		}
+
+		if !existing.IsExpired(time.Now()) {
+			return ScheduleLock{}, ScheduleLockedError{Lock: existing}
+		}
+
+		span.Debugf("Replacing the expired lock on schedule %s held by %s", lock, existing.Owner)
+		if err = s.removeScheduleLock(ctx, existing); err != nil {
+			return ScheduleLock{}, span.Error(fmt.Errorf("could not remove the expired lock on schedule %s: %w", lock, err))
+		}
+	}
+
+	existing, err := s.getScheduleLock(ctx, lock.ID)
+	if err != nil {
+		return ScheduleLock{}, span.Error(fmt.Errorf("could not acquire the lock on schedule %s: %w", lock, err))
+	}
+	return ScheduleLock{}, ScheduleLockedError{Lock: existing}
+}
+
+// RenewScheduleLock extends a lock so that it expires after the ttl.
+// ErrScheduleLockLost is returned when the lock is no longer held.
+func (s ScheduleStore) RenewScheduleLock(ctx context.Context, lock ScheduleLock, ttl time.Duration) (ScheduleLock, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionSchedules, lock.Namespace, lock.Schedule); err != nil {
+		return lock, span.Error(err)
+	}
+
+	existing, err := s.getScheduleLock(ctx, lock.ID)
+	if errors.Is(err, ErrNotFound{}) {
+		return lock, ErrScheduleLockLost
+	} else if err != nil {
+		return lock, span.Error(err)
+	}
+	if existing.Token != lock.Token {
+		return lock, ErrScheduleLockLost
+	}
+
+	lock.Expires = time.Now().Add(ttl)
+	opts := UpdateOptions{
+		Filter:   bson.M{"_id": lock.ID, "token": lock.Token},
+		Document: lock,
+	}
+	return lock, span.Error(s.Documents.Update(ctx, CollectionScheduleLocks, opts))
+}
+
+// ReleaseScheduleLock releases a lock. Releasing a lock that is no longer
+// held, because it expired and was acquired by another agent, does not
+// release the other agent's lock.
+func (s ScheduleStore) ReleaseScheduleLock(ctx context.Context, lock ScheduleLock) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionSchedules, lock.Namespace, lock.Schedule); err != nil {
+		return span.Error(err)
+	}
+
+	return span.Error(s.removeScheduleLock(ctx, lock))
+}
+
+func (s ScheduleStore) getScheduleLock(ctx context.Context, id string) (ScheduleLock, error) {
+	var lock ScheduleLock
+	opts := FindOptions{
+		Filter: bson.M{"_id": id},
+	}
+	err := s.Documents.FindOne(ctx, CollectionScheduleLocks, opts, &lock)
+	return lock, err
+}
+
+func (s ScheduleStore) removeScheduleLock(ctx context.Context, lock ScheduleLock) error {
+	opts := RemoveOptions{
+		Filter: bson.M{"_id": lock.ID, "token": lock.Token},
+	}
+	return s.Documents.Remove(ctx, CollectionScheduleLocks, opts)
+}
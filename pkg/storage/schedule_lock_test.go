@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleStore_ScheduleLock(t *testing.T) {
+	ctx := context.Background()
+	tc := config.NewTestConfig(t)
+	store := NewTestStore(tc)
+	defer store.Close()
+	ss := NewScheduleStore(store)
+
+	lock, err := ss.AcquireScheduleLock(ctx, "dev", "mysql-backup", "alice", time.Minute)
+	require.NoError(t, err, "AcquireScheduleLock failed")
+	assert.Equal(t, "dev/mysql-backup", lock.String())
+	assert.NotEmpty(t, lock.Token)
+
+	t.Run("locked", func(t *testing.T) {
+		_, err := ss.AcquireScheduleLock(ctx, "dev", "mysql-backup", "bob", time.Minute)
+		require.ErrorIs(t, err, ErrScheduleLocked)
+		require.ErrorContains(t, err, "schedule dev/mysql-backup is locked by alice")
+
+		var lockedErr ScheduleLockedError
+		require.True(t, errors.As(err, &lockedErr))
+		assert.Equal(t, lock.Token, lockedErr.Lock.Token)
+	})
+
+	t.Run("renew", func(t *testing.T) {
+		renewed, err := ss.RenewScheduleLock(ctx, lock, time.Hour)
+		require.NoError(t, err, "RenewScheduleLock failed")
+		assert.True(t, renewed.Expires.After(lock.Expires), "the lock should expire later after it is renewed")
+		lock = renewed
+	})
+
+	require.NoError(t, ss.ReleaseScheduleLock(ctx, lock), "ReleaseScheduleLock failed")
+
+	t.Run("renew a released lock", func(t *testing.T) {
+		_, err := ss.RenewScheduleLock(ctx, lock, time.Hour)
+		require.ErrorIs(t, err, ErrScheduleLockLost)
+	})
+
+	t.Run("replace an expired lock", func(t *testing.T) {
+		_, err := ss.AcquireScheduleLock(ctx, "dev", "mysql-backup", "bob", -time.Second)
+		require.NoError(t, err)
+
+		next, err := ss.AcquireScheduleLock(ctx, "dev", "mysql-backup", "carol", time.Minute)
+		require.NoError(t, err, "an expired lock should be replaced")
+		assert.Equal(t, "carol", next.Owner)
+		require.NoError(t, ss.ReleaseScheduleLock(ctx, next))
+	})
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// ScheduleProvider is Porter's interface for managing schedules.
+type ScheduleProvider interface {
+	// InsertSchedule saves a new schedule document.
+	InsertSchedule(ctx context.Context, schedule Schedule) error
+
+	// ListSchedules returns the schedules that match the specified namespace
+	// and name. Use the namespace * to list schedules in all namespaces.
+	ListSchedules(ctx context.Context, listOptions ListOptions) ([]Schedule, error)
+
+	// GetSchedule returns the named schedule.
+	GetSchedule(ctx context.Context, namespace string, name string) (Schedule, error)
+
+	// UpdateSchedule saves changes to an existing schedule.
+	UpdateSchedule(ctx context.Context, schedule Schedule) error
+
+	// RemoveSchedule deletes the named schedule.
+	RemoveSchedule(ctx context.Context, namespace string, name string) error
+
+	// AcquireScheduleLock locks a schedule for the owner, so that it is not run
+	// by more than one agent at the same time, until the lock is released or it
+	// expires after the ttl. A ScheduleLockedError is returned when the
+	// schedule is already locked.
+	AcquireScheduleLock(ctx context.Context, namespace string, name string, owner string, ttl time.Duration) (ScheduleLock, error)
+
+	// RenewScheduleLock extends a lock so that it expires after the ttl.
+	RenewScheduleLock(ctx context.Context, lock ScheduleLock, ttl time.Duration) (ScheduleLock, error)
+
+	// ReleaseScheduleLock releases a lock acquired with AcquireScheduleLock.
+	ReleaseScheduleLock(ctx context.Context, lock ScheduleLock) error
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+
+	"get.porter.sh/porter/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var _ ScheduleProvider = &ScheduleStore{}
+
+const (
+	CollectionSchedules = "schedules"
+)
+
+// ScheduleStore is a wrapper around Porter's datastore
+// providing typed access to schedule documents.
+type ScheduleStore struct {
+	Documents Store
+
+	// authz authorizes reading and changing the schedules in a namespace.
+	authz Authorizer
+}
+
+func NewScheduleStore(storage Store) *ScheduleStore {
+	return &ScheduleStore{
+		Documents: storage,
+	}
+}
+
+// SetAuthorizer sets the hook that authorizes reading and changing the
+// schedules in a namespace.
+func (s *ScheduleStore) SetAuthorizer(authz Authorizer) {
+	s.authz = authz
+}
+
+// EnsureScheduleIndices creates indices on the schedules collection.
+func EnsureScheduleIndices(ctx context.Context, store Store) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	span.Debug("Initializing schedules collection indices")
+
+	indices := EnsureIndexOptions{
+		Indices: []Index{
+			// query schedules by namespace + name
+			{Collection: CollectionSchedules, Keys: []string{"namespace", "name"}, Unique: true},
+			// query the schedules that are due
+			{Collection: CollectionSchedules, Keys: []string{"status.nextRun"}},
+		},
+	}
+	err := store.EnsureIndex(ctx, indices)
+	return span.Error(err)
+}
+
+func (s ScheduleStore) InsertSchedule(ctx context.Context, schedule Schedule) error {
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionSchedules, schedule.Namespace, schedule.Name); err != nil {
+		return err
+	}
+
+	schedule.SchemaVersion = ScheduleSchemaVersion
+	opts := InsertOptions{
+		Documents: []interface{}{schedule},
+	}
+	return s.Documents.Insert(ctx, CollectionSchedules, opts)
+}
+
+func (s ScheduleStore) ListSchedules(ctx context.Context, listOptions ListOptions) ([]Schedule, error) {
+	var out []Schedule
+	if err := s.Documents.Find(ctx, CollectionSchedules, listOptions.ToFindOptions(), &out); err != nil {
+		return nil, err
+	}
+	return filterAuthorized(ctx, s.authz, CollectionSchedules, out, func(schedule Schedule) string { return schedule.Namespace })
+}
+
+func (s ScheduleStore) GetSchedule(ctx context.Context, namespace string, name string) (Schedule, error) {
+	if err := authorize(ctx, s.authz, VerbRead, CollectionSchedules, namespace, name); err != nil {
+		return Schedule{}, err
+	}
+
+	var out Schedule
+	opts := GetOptions{Namespace: namespace, Name: name}
+	err := s.Documents.FindOne(ctx, CollectionSchedules, opts.ToFindOptions(), &out)
+	return out, err
+}
+
+func (s ScheduleStore) UpdateSchedule(ctx context.Context, schedule Schedule) error {
+	if err := authorize(ctx, s.authz, VerbWrite, CollectionSchedules, schedule.Namespace, schedule.Name); err != nil {
+		return err
+	}
+
+	schedule.SchemaVersion = ScheduleSchemaVersion
+	opts := UpdateOptions{
+		Document: schedule,
+	}
+	return s.Documents.Update(ctx, CollectionSchedules, opts)
+}
+
+func (s ScheduleStore) RemoveSchedule(ctx context.Context, namespace string, name string) error {
+	if err := authorize(ctx, s.authz, VerbDelete, CollectionSchedules, namespace, name); err != nil {
+		return err
+	}
+
+	opts := RemoveOptions{
+		Filter: bson.M{
+			"namespace": namespace,
+			"name":      name,
+		},
+	}
+	return s.Documents.Remove(ctx, CollectionSchedules, opts)
+}
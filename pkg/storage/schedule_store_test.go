@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleStorage_CRUD(t *testing.T) {
+	ctx := context.Background()
+	tc := config.NewTestConfig(t)
+	store := NewTestStore(tc)
+	defer store.Close()
+	require.NoError(t, EnsureScheduleIndices(ctx, store))
+	ss := NewScheduleStore(store)
+
+	backup := NewSchedule("dev", "mysql-backup")
+	backup.Installation = "mysql"
+	backup.Action = "backup"
+	backup.Cron = "@daily"
+	backup.Parameters = []string{"retention=30d"}
+	require.NoError(t, ss.InsertSchedule(ctx, backup))
+
+	vacuum := NewSchedule("prod", "mysql-vacuum")
+	vacuum.Installation = "mysql"
+	vacuum.Action = "vacuum"
+	vacuum.Cron = "0 3 * * sun"
+	require.NoError(t, ss.InsertSchedule(ctx, vacuum))
+
+	err := ss.InsertSchedule(ctx, NewSchedule("dev", "mysql-backup"))
+	require.Error(t, err, "schedule names should be unique within a namespace")
+
+	got, err := ss.GetSchedule(ctx, "dev", "mysql-backup")
+	require.NoError(t, err)
+	assert.Equal(t, "backup", got.Action)
+	assert.Equal(t, []string{"retention=30d"}, got.Parameters)
+
+	list, err := ss.ListSchedules(ctx, ListOptions{Namespace: "dev"})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "mysql-backup", list[0].Name)
+
+	list, err = ss.ListSchedules(ctx, ListOptions{Namespace: "*"})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	got.Disabled = true
+	require.NoError(t, ss.UpdateSchedule(ctx, got))
+	got, err = ss.GetSchedule(ctx, "dev", "mysql-backup")
+	require.NoError(t, err)
+	assert.True(t, got.Disabled)
+
+	require.NoError(t, ss.RemoveSchedule(ctx, "dev", "mysql-backup"))
+	_, err = ss.GetSchedule(ctx, "dev", "mysql-backup")
+	require.ErrorIs(t, err, ErrNotFound{})
+}
+
+func TestSchedule_Validate(t *testing.T) {
+	schedule := NewSchedule("dev", "mysql-backup")
+	schedule.Installation = "mysql"
+	schedule.Action = "backup"
+	schedule.Cron = "0 2 * * *"
+	require.NoError(t, schedule.Validate())
+
+	schedule.Cron = "0 25 * * *"
+	require.ErrorContains(t, schedule.Validate(), "invalid cron expression for schedule mysql-backup")
+
+	schedule.Cron = "0 2 * * *"
+	schedule.Action = ""
+	require.ErrorContains(t, schedule.Validate(), "the action is required")
+
+	schedule.Action = "backup"
+	schedule.SchemaVersion = ""
+	require.ErrorContains(t, schedule.Validate(), "invalid schemaVersion provided: (none)")
+}
+
+func TestSchedule_IsDue(t *testing.T) {
+	now := time.Date(2026, time.March, 4, 10, 17, 0, 0, time.UTC)
+
+	schedule := NewSchedule("dev", "mysql-backup")
+	schedule.Cron = "@daily"
+	assert.False(t, schedule.IsDue(now), "a schedule that was never scheduled should not be due")
+
+	require.NoError(t, schedule.ScheduleNext(now))
+	assert.Equal(t, time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC), schedule.Status.NextRun)
+	assert.False(t, schedule.IsDue(now))
+	assert.True(t, schedule.IsDue(schedule.Status.NextRun))
+	assert.True(t, schedule.IsDue(schedule.Status.NextRun.Add(time.Hour)))
+
+	schedule.Disabled = true
+	assert.False(t, schedule.IsDue(schedule.Status.NextRun), "a disabled schedule should not be due")
+}
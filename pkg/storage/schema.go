@@ -11,6 +11,12 @@ const (
 	// for all installation documents: installations, runs, results and outputs.
 	InstallationSchemaVersion = schema.Version("1.0.2")
 
+	// RunSchemaVersion represents the version associated with the schema for
+	// run documents. Starting with 1.1.0, parameters that are set to the
+	// default value defined by the bundle are not saved with the run, and
+	// starting with 1.2.0 the time the run was created is also saved as a date.
+	RunSchemaVersion = schema.Version("1.2.0")
+
 	// CredentialSetSchemaVersion represents the version associated with the schema
 	// credential set documents.
 	CredentialSetSchemaVersion = schema.Version("1.0.1")
@@ -18,6 +24,14 @@ const (
 	// ParameterSetSchemaVersion represents the version associated with the schema
 	// for parameter set documents.
 	ParameterSetSchemaVersion = schema.Version("1.0.1")
+
+	// NamespaceSchemaVersion represents the version associated with the schema
+	// for namespace documents.
+	NamespaceSchemaVersion = schema.Version("1.0.0")
+
+	// ScheduleSchemaVersion represents the version associated with the schema
+	// for schedule documents.
+	ScheduleSchemaVersion = schema.Version("1.0.0")
 )
 
 type Schema struct {
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/secrets"
+)
+
+// isAuditedSource determines if reading a value from the source is a read of
+// a sensitive value that must be audited, either from the secret store or
+// decrypted with a key management service.
+func isAuditedSource(source string) bool {
+	return source == secrets.SourceSecret || source == secrets.SourceKMS
+}
+
+// auditStrategy records that the sensitive value of a parameter or credential
+// was resolved, and whether resolving it failed. Values from other sources,
+// such as environment variables, are not audited.
+func auditStrategy(ctx context.Context, hook secrets.AuditHook, kind string, namespace string, installation string, strategy secrets.Strategy, resolveErr error) error {
+	if hook == nil || !isAuditedSource(strategy.Source.Key) {
+		return nil
+	}
+
+	event := newAuditEvent(kind, strategy.Name, strategy.Source.Value, resolveErr)
+	event.Namespace = namespace
+	event.Installation = installation
+	if err := hook.Audit(ctx, event); err != nil {
+		return fmt.Errorf("error auditing the sensitive %s %s: %w", kind, strategy.Name, err)
+	}
+	return nil
+}
+
+// installationFromParameterSet returns the name of the installation that owns
+// an internal parameter set, or an empty string for a parameter set created
+// by the user.
+func installationFromParameterSet(pset ParameterSet) string {
+	installation := strings.TrimPrefix(pset.Name, INTERNAL_PARAMETERER_SET+"-")
+	if installation == pset.Name {
+		return ""
+	}
+	return installation
+}
+
+func newAuditEvent(kind string, name string, key string, resolveErr error) secrets.AuditEvent {
+	event := secrets.AuditEvent{
+		Time:      time.Now(),
+		Action:    secrets.AuditActionResolve,
+		Kind:      kind,
+		Name:      name,
+		SecretKey: key,
+	}
+	if resolveErr != nil {
+		event.Error = resolveErr.Error()
+	}
+	return event
+}
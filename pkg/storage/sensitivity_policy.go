@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"fmt"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+)
+
+// SensitivityPolicy is the sanitizer hook that identifies parameters and
+// outputs that are sensitive, in addition to those marked sensitive by the
+// bundle.
+type SensitivityPolicy interface {
+	// IsSensitiveParameter determines if the parameter of the bundle is sensitive.
+	IsSensitiveParameter(bun cnab.ExtendedBundle, name string) (bool, error)
+
+	// IsSensitiveOutput determines if the output of the bundle is sensitive.
+	IsSensitiveOutput(bun cnab.ExtendedBundle, name string) (bool, error)
+}
+
+var _ SensitivityPolicy = ConfigSensitivityPolicy{}
+
+// ConfigSensitivityPolicy identifies sensitive parameters and outputs using the
+// sensitivity-policies defined in Porter's configuration.
+type ConfigSensitivityPolicy struct {
+	config *config.Config
+}
+
+// NewConfigSensitivityPolicy creates a sensitivity hook that applies the
+// sensitivity policies in the configuration. The policies are read each time
+// a value is checked, so that they reflect the loaded configuration.
+func NewConfigSensitivityPolicy(c *config.Config) ConfigSensitivityPolicy {
+	return ConfigSensitivityPolicy{config: c}
+}
+
+func (p ConfigSensitivityPolicy) IsSensitiveParameter(bun cnab.ExtendedBundle, name string) (bool, error) {
+	if p.config == nil {
+		return false, nil
+	}
+
+	for _, policy := range p.config.Data.SensitivityPolicies {
+		matched, err := policy.MatchesParameter(bun.Name, name)
+		if err != nil {
+			return false, fmt.Errorf("could not check if parameter %s is sensitive: %w", name, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p ConfigSensitivityPolicy) IsSensitiveOutput(bun cnab.ExtendedBundle, name string) (bool, error) {
+	if p.config == nil {
+		return false, nil
+	}
+
+	for _, policy := range p.config.Data.SensitivityPolicies {
+		matched, err := policy.MatchesOutput(bun.Name, name)
+		if err != nil {
+			return false, fmt.Errorf("could not check if output %s is sensitive: %w", name, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CollectionStepResults stores the results of each step executed during a run.
+const CollectionStepResults = "stepresults"
+
+// StepResultsOutput is the name of the internal bundle output where the
+// Porter runtime reports the results of the steps that it executed.
+const StepResultsOutput = "porter-step-results"
+
+// StepLogsLimit is the maximum number of bytes of the logs of a step that are
+// recorded. When a step writes more than this, only the end of the logs is kept.
+const StepLogsLimit = 4 * 1024
+
+const (
+	// StepStatusSucceeded indicates that the step completed successfully.
+	StepStatusSucceeded = "succeeded"
+
+	// StepStatusFailed indicates that the step failed.
+	StepStatusFailed = "failed"
+)
+
+var _ Document = StepResult{}
+
+// StepResult is the outcome of executing a single step of a bundle action.
+type StepResult struct {
+	// Namespace of the installation.
+	Namespace string `json:"namespace"`
+
+	// Installation name that owns this step result.
+	Installation string `json:"installation"`
+
+	// RunID of the run that executed the step.
+	RunID string `json:"runId"`
+
+	// Index of the step in the action, starting at 0.
+	Index int `json:"index"`
+
+	// Description of the step.
+	Description string `json:"description,omitempty"`
+
+	// Mixin that executed the step.
+	Mixin string `json:"mixin"`
+
+	// Started timestamp of the step.
+	Started time.Time `json:"started"`
+
+	// Stopped timestamp of the step.
+	Stopped time.Time `json:"stopped"`
+
+	// Status of the step, either StepStatusSucceeded or StepStatusFailed.
+	Status string `json:"status"`
+
+	// ExitCode of the mixin when it failed.
+	ExitCode int `json:"exitCode,omitempty"`
+
+	// Error returned by the mixin when it failed.
+	Error string `json:"error,omitempty"`
+
+	// Logs written by the mixin, truncated to the last StepLogsLimit bytes.
+	Logs string `json:"logs,omitempty"`
+
+	// LogsTruncated indicates that the beginning of the logs was not recorded.
+	LogsTruncated bool `json:"logsTruncated,omitempty"`
+}
+
+func (s StepResult) DefaultDocumentFilter() map[string]interface{} {
+	return map[string]interface{}{"runId": s.RunID, "index": s.Index}
+}
+
+// Duration of the step.
+func (s StepResult) Duration() time.Duration {
+	return s.Stopped.Sub(s.Started)
+}
+
+// NewStepResult creates a step result for the run, with the details of the step
+// reported by the Porter runtime.
+func (r Run) NewStepResult(step StepResult) StepResult {
+	step.Namespace = r.Namespace
+	step.Installation = r.Installation
+	step.RunID = r.ID
+	return step
+}
+
+// InsertStepResults saves the results of the steps executed by a run.
+func (s InstallationStore) InsertStepResults(ctx context.Context, steps []StepResult) error {
+	if len(steps) == 0 {
+		return nil
+	}
+
+	if err := checkWritable(s.store, CollectionStepResults, "insert"); err != nil {
+		return err
+	}
+
+	docs := make([]interface{}, len(steps))
+	for i, step := range steps {
+		if err := authorize(ctx, s.authz, VerbWrite, CollectionStepResults, step.Namespace, step.Installation); err != nil {
+			return err
+		}
+		docs[i] = step
+	}
+	return s.store.Insert(ctx, CollectionStepResults, InsertOptions{Documents: docs})
+}
+
+// ListStepResults returns the results of the steps executed by a run, sorted by
+// the order in which the steps were executed.
+func (s InstallationStore) ListStepResults(ctx context.Context, runID string) ([]StepResult, error) {
+	var out []StepResult
+	opts := FindOptions{
+		Sort: []string{"index"},
+		Filter: bson.M{
+			"runId": runID,
+		},
+	}
+	if err := s.store.Find(ctx, CollectionStepResults, opts, &out); err != nil {
+		return nil, err
+	}
+	return filterAuthorized(ctx, s.authz, CollectionStepResults, out, func(r StepResult) string { return r.Namespace })
+}
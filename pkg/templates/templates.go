@@ -35,6 +35,12 @@ func (t *Templates) GetManifest() ([]byte, error) {
 	return t.fs.ReadFile("templates/create/porter.yaml")
 }
 
+// GetImportManifest returns the porter.yaml template used to scaffold a bundle
+// for a deployment imported from another tool, such as helm or terraform.
+func (t *Templates) GetImportManifest(source string) ([]byte, error) {
+	return t.fs.ReadFile(fmt.Sprintf("templates/import/%s/porter.yaml", source))
+}
+
 // GetHelpers returns a helpers.sh template file for use in new bundles.
 func (t *Templates) GetManifestHelpers() ([]byte, error) {
 	return t.fs.ReadFile("templates/create/helpers.sh")
@@ -0,0 +1,5 @@
+// Package tools installs the command-line tools, such as kubectl, helm and
+// terraform, that a bundle pins to a specific version in its manifest. The
+// same installer is used for every bundle so that mixins do not need to
+// download the tools themselves.
+package tools
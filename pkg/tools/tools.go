@@ -0,0 +1,364 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"get.porter.sh/porter/pkg/portercontext"
+	"github.com/hashicorp/go-multierror"
+)
+
+const (
+	// InstallDir is the directory in the invocation image where the tools are installed.
+	InstallDir = "/usr/local/bin"
+
+	// formatBinary is a tool that is downloaded as a single executable.
+	formatBinary = "binary"
+
+	// formatTarGz is a tool that is downloaded as a gzipped tarball.
+	formatTarGz = "tar.gz"
+
+	// formatZip is a tool that is downloaded as a zip archive.
+	formatZip = "zip"
+)
+
+var (
+	checksumRegex = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+	// versionRegex limits the characters in a version, since it is used in
+	// the Dockerfile instructions that download the tool.
+	versionRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.+_-]*$`)
+
+	// architectures are the values of TARGETARCH that a checksum may be
+	// specified for.
+	architectures = []string{"386", "amd64", "arm", "arm64", "ppc64le", "s390x"}
+)
+
+// packageManager installs the packages needed to download the tools.
+type packageManager struct {
+	// Command that is checked to detect the package manager.
+	Command string
+
+	// Install is the command that installs the packages.
+	Install string
+}
+
+// packageManagers are the package managers that are detected in the base
+// image of the invocation image, in the order they are checked.
+var packageManagers = []packageManager{
+	{Command: "apt-get", Install: "apt-get update && apt-get install -y"},
+	{Command: "apk", Install: "apk add --no-cache"},
+	{Command: "dnf", Install: "dnf install -y"},
+	{Command: "microdnf", Install: "microdnf install -y"},
+	{Command: "yum", Install: "yum install -y"},
+}
+
+// Tool describes how to download a tool and check its version.
+type Tool struct {
+	// Name of the tool, which is also the name of its executable.
+	Name string
+
+	// VersionPrefix is prepended to the version in the download url, such as v
+	// for v1.27.3.
+	VersionPrefix string
+
+	// DownloadURL of the tool. VERSION and ARCH are replaced with the pinned
+	// version and the architecture of the invocation image.
+	DownloadURL string
+
+	// Format of the download: binary, tar.gz or zip.
+	Format string
+
+	// ArchivePath is the path of the executable in the archive. ARCH is
+	// replaced with the architecture of the invocation image.
+	ArchivePath string
+
+	// VersionArgs are the arguments that print the version of the tool.
+	VersionArgs []string
+}
+
+// knownTools are the tools that may be pinned in the manifest.
+var knownTools = map[string]Tool{
+	"kubectl": {
+		Name:          "kubectl",
+		VersionPrefix: "v",
+		DownloadURL:   "https://dl.k8s.io/release/VERSION/bin/linux/ARCH/kubectl",
+		Format:        formatBinary,
+		VersionArgs:   []string{"version", "--client"},
+	},
+	"helm": {
+		Name:          "helm",
+		VersionPrefix: "v",
+		DownloadURL:   "https://get.helm.sh/helm-VERSION-linux-ARCH.tar.gz",
+		Format:        formatTarGz,
+		ArchivePath:   "linux-ARCH/helm",
+		VersionArgs:   []string{"version", "--short"},
+	},
+	"terraform": {
+		Name:        "terraform",
+		DownloadURL: "https://releases.hashicorp.com/terraform/VERSION/terraform_VERSION_linux_ARCH.zip",
+		Format:      formatZip,
+		ArchivePath: "terraform",
+		VersionArgs: []string{"version"},
+	},
+}
+
+// Get returns the definition of a tool that may be pinned.
+func Get(name string) (Tool, bool) {
+	t, ok := knownTools[name]
+	return t, ok
+}
+
+// Names returns the sorted names of the tools that may be pinned.
+func Names() []string {
+	names := make([]string, 0, len(knownTools))
+	for name := range knownTools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Pin is a tool, and the version of it, that is installed into the invocation image.
+type Pin struct {
+	// Name of the tool, for example kubectl.
+	Name string `yaml:"name"`
+
+	// Version of the tool, for example v1.27.3.
+	Version string `yaml:"version"`
+
+	// Checksums are the optional sha256 digests of the download, in the
+	// format sha256:HEX, keyed by the architecture of the invocation image,
+	// for example amd64. When checksums are specified, building the image for
+	// an architecture without a checksum fails.
+	Checksums map[string]string `yaml:"checksums,omitempty"`
+}
+
+// Validate the tool pin.
+func (p Pin) Validate() error {
+	if p.Name == "" {
+		return errors.New("tool name is required")
+	}
+	if _, ok := Get(p.Name); !ok {
+		return fmt.Errorf("tool %s is not supported, the supported tools are: %s", p.Name, strings.Join(Names(), ", "))
+	}
+	if p.Version == "" {
+		return fmt.Errorf("tool %s must specify a version", p.Name)
+	}
+	if !versionRegex.MatchString(p.Version) {
+		return fmt.Errorf("invalid version %q for tool %s", p.Version, p.Name)
+	}
+	for _, arch := range p.checksumArchitectures() {
+		if !isArchitecture(arch) {
+			return fmt.Errorf("invalid architecture %q for the checksum of tool %s, the supported architectures are: %s", arch, p.Name, strings.Join(architectures, ", "))
+		}
+		if checksum := p.Checksums[arch]; !checksumRegex.MatchString(checksum) {
+			return fmt.Errorf("invalid %s checksum %q for tool %s, the checksum must be in the format sha256:HEX", arch, checksum, p.Name)
+		}
+	}
+	return nil
+}
+
+// checksumArchitectures returns the sorted architectures that the tool has a
+// checksum for.
+func (p Pin) checksumArchitectures() []string {
+	archs := make([]string, 0, len(p.Checksums))
+	for arch := range p.Checksums {
+		archs = append(archs, arch)
+	}
+	sort.Strings(archs)
+	return archs
+}
+
+func isArchitecture(arch string) bool {
+	for _, a := range architectures {
+		if a == arch {
+			return true
+		}
+	}
+	return false
+}
+
+// GetVersion returns the version of the tool as it appears in the download url.
+func (p Pin) GetVersion() string {
+	t, _ := Get(p.Name)
+	return t.VersionPrefix + strings.TrimPrefix(p.Version, "v")
+}
+
+// EnvVar is the name of the environment variable that holds the pinned
+// version of the tool in the invocation image, for example PORTER_TOOL_KUBECTL_VERSION.
+func EnvVar(name string) string {
+	name = strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	return fmt.Sprintf("PORTER_TOOL_%s_VERSION", name)
+}
+
+// ValidatePins checks each tool pin, and that a tool is only pinned once.
+func ValidatePins(pins []Pin) error {
+	var result error
+	seen := make(map[string]bool, len(pins))
+	for _, p := range pins {
+		if err := p.Validate(); err != nil {
+			result = multierror.Append(result, err)
+			continue
+		}
+		if seen[p.Name] {
+			result = multierror.Append(result, fmt.Errorf("tool %s is pinned more than once", p.Name))
+		}
+		seen[p.Name] = true
+	}
+	return result
+}
+
+// DockerfileLines returns the Dockerfile instructions that install the pinned
+// tools into the invocation image, and export their versions as environment
+// variables. The instructions use the TARGETARCH build argument so that the
+// tools match the platform of the image. The packages needed to download the
+// tools are installed with the package manager of the base image: apt-get,
+// apk, dnf, microdnf or yum.
+func DockerfileLines(pins []Pin) ([]string, error) {
+	if len(pins) == 0 {
+		return nil, nil
+	}
+	if err := ValidatePins(pins); err != nil {
+		return nil, err
+	}
+
+	packages := []string{"ca-certificates", "curl"}
+	for _, p := range pins {
+		if t, _ := Get(p.Name); t.Format == formatZip {
+			packages = append(packages, "unzip")
+			break
+		}
+	}
+
+	lines := []string{
+		"ARG TARGETARCH",
+		installPackagesLine(packages),
+	}
+	for _, p := range pins {
+		lines = append(lines, installLine(p))
+	}
+	for _, p := range pins {
+		lines = append(lines, fmt.Sprintf("ENV %s=%s", EnvVar(p.Name), p.GetVersion()))
+	}
+	return lines, nil
+}
+
+// installPackagesLine returns the RUN instruction that installs the packages
+// with the first package manager found in the base image, failing the build
+// when none of the supported package managers are found.
+func installPackagesLine(packages []string) string {
+	pkgs := strings.Join(packages, " ")
+	cmds := make([]string, 0, len(packageManagers)+1)
+	for i, pm := range packageManagers {
+		keyword := "elif"
+		if i == 0 {
+			keyword = "if"
+		}
+		cmds = append(cmds, fmt.Sprintf("%s command -v %s >/dev/null 2>&1; then %s %s;", keyword, pm.Command, pm.Install, pkgs))
+	}
+	cmds = append(cmds, fmt.Sprintf(`else echo "could not find a supported package manager to install %s" >&2 && exit 1; fi`, pkgs))
+	return "RUN " + strings.Join(cmds, " \\\n    ")
+}
+
+// installLine returns the RUN instruction that downloads and installs a tool.
+func installLine(p Pin) string {
+	t, _ := Get(p.Name)
+	replacer := strings.NewReplacer("VERSION", p.GetVersion(), "ARCH", "${TARGETARCH}")
+	url := replacer.Replace(t.DownloadURL)
+	dest := InstallDir + "/" + t.Name
+
+	download := "/tmp/" + t.Name
+	if t.Format == formatBinary {
+		download = dest
+	} else {
+		download += "." + t.Format
+	}
+
+	cmds := []string{fmt.Sprintf("curl -fsSLo %s %s", download, url)}
+	if len(p.Checksums) > 0 {
+		cmds = append(cmds, checksumCommands(p, download)...)
+	}
+
+	switch t.Format {
+	case formatTarGz:
+		archivePath := replacer.Replace(t.ArchivePath)
+		cmds = append(cmds,
+			fmt.Sprintf("tar -xzf %s -C /tmp %s", download, archivePath),
+			fmt.Sprintf("mv /tmp/%s %s", archivePath, dest),
+			fmt.Sprintf("rm -rf %s /tmp/%s", download, strings.SplitN(archivePath, "/", 2)[0]))
+	case formatZip:
+		cmds = append(cmds,
+			fmt.Sprintf("unzip -o %s %s -d %s", download, t.ArchivePath, InstallDir),
+			fmt.Sprintf("rm %s", download))
+	}
+	cmds = append(cmds, fmt.Sprintf("chmod +x %s", dest))
+
+	return "RUN " + strings.Join(cmds, " && \\\n    ")
+}
+
+// checksumCommands returns the commands that select the checksum of the tool
+// for TARGETARCH, and check the download against it.
+func checksumCommands(p Pin, download string) []string {
+	cases := make([]string, 0, len(p.Checksums)+1)
+	for _, arch := range p.checksumArchitectures() {
+		cases = append(cases, fmt.Sprintf("%s) CHECKSUM=%s ;;", arch, strings.TrimPrefix(p.Checksums[arch], "sha256:")))
+	}
+	cases = append(cases, fmt.Sprintf(`*) echo "tool %s does not have a checksum for ${TARGETARCH}" >&2 && exit 1 ;;`, p.Name))
+
+	return []string{
+		fmt.Sprintf(`case "${TARGETARCH}" in %s esac`, strings.Join(cases, " ")),
+		fmt.Sprintf(`echo "${CHECKSUM}  %s" | sha256sum -c -`, download),
+	}
+}
+
+// Verify checks that the tools installed in the invocation image report the
+// version that was pinned in the manifest.
+func Verify(ctx context.Context, cxt *portercontext.Context, pins []Pin) error {
+	var result error
+	for _, p := range pins {
+		t, ok := Get(p.Name)
+		if !ok {
+			result = multierror.Append(result, fmt.Errorf("tool %s is not supported", p.Name))
+			continue
+		}
+
+		cmd := cxt.NewCommand(ctx, t.Name, t.VersionArgs...)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("could not check the version of tool %s: %w", p.Name, err))
+			continue
+		}
+
+		want := "v" + strings.TrimPrefix(p.Version, "v")
+		if !containsVersion(string(output), want) {
+			result = multierror.Append(result, fmt.Errorf("tool %s is pinned to version %s but the installed version reports: %s", p.Name, p.Version, strings.TrimSpace(string(output))))
+		}
+	}
+	return result
+}
+
+// containsVersion returns true when the output includes the version, and the
+// version is not only a prefix of a longer version such as v1.2.30 for v1.2.3.
+func containsVersion(output string, version string) bool {
+	for i := strings.Index(output, version); i >= 0; {
+		end := i + len(version)
+		if end == len(output) || !isVersionChar(output[end]) {
+			return true
+		}
+		next := strings.Index(output[end:], version)
+		if next < 0 {
+			return false
+		}
+		i = end + next
+	}
+	return false
+}
+
+func isVersionChar(c byte) bool {
+	return c >= '0' && c <= '9' || c == '.'
+}
@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePins(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name    string
+		pins    []Pin
+		wantErr string
+	}{
+		{name: "valid", pins: []Pin{{Name: "kubectl", Version: "v1.27.3"}, {Name: "helm", Version: "3.12.0", Checksums: map[string]string{"amd64": "sha256:" + strings.Repeat("a", 64)}}}},
+		{name: "unsupported tool", pins: []Pin{{Name: "jq", Version: "1.6"}}, wantErr: "tool jq is not supported, the supported tools are: helm, kubectl, terraform"},
+		{name: "missing version", pins: []Pin{{Name: "kubectl"}}, wantErr: "tool kubectl must specify a version"},
+		{name: "invalid version", pins: []Pin{{Name: "kubectl", Version: "v1 && rm -rf /"}}, wantErr: `invalid version "v1 && rm -rf /" for tool kubectl`},
+		{name: "invalid checksum", pins: []Pin{{Name: "kubectl", Version: "v1.27.3", Checksums: map[string]string{"amd64": "md5:abc"}}}, wantErr: `invalid amd64 checksum "md5:abc" for tool kubectl`},
+		{name: "invalid architecture", pins: []Pin{{Name: "kubectl", Version: "v1.27.3", Checksums: map[string]string{"x86_64": "sha256:" + strings.Repeat("a", 64)}}}, wantErr: `invalid architecture "x86_64" for the checksum of tool kubectl`},
+		{name: "duplicate", pins: []Pin{{Name: "kubectl", Version: "v1.27.3"}, {Name: "kubectl", Version: "v1.26.0"}}, wantErr: "tool kubectl is pinned more than once"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidatePins(tc.pins)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestPin_GetVersion(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "v1.27.3", Pin{Name: "kubectl", Version: "1.27.3"}.GetVersion(), "kubectl versions should have a v prefix")
+	assert.Equal(t, "v3.12.0", Pin{Name: "helm", Version: "v3.12.0"}.GetVersion())
+	assert.Equal(t, "1.5.0", Pin{Name: "terraform", Version: "v1.5.0"}.GetVersion(), "terraform versions should not have a v prefix")
+}
+
+func TestEnvVar(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "PORTER_TOOL_KUBECTL_VERSION", EnvVar("kubectl"))
+	assert.Equal(t, "PORTER_TOOL_MY_TOOL_VERSION", EnvVar("my-tool"))
+}
+
+func TestDockerfileLines(t *testing.T) {
+	t.Parallel()
+
+	lines, err := DockerfileLines(nil)
+	require.NoError(t, err)
+	assert.Empty(t, lines, "no instructions should be generated when no tools are pinned")
+
+	lines, err = DockerfileLines([]Pin{{Name: "helm", Version: "v3.12.0"}})
+	require.NoError(t, err)
+	want := []string{
+		"ARG TARGETARCH",
+		"RUN if command -v apt-get >/dev/null 2>&1; then apt-get update && apt-get install -y ca-certificates curl; \\\n" +
+			"    elif command -v apk >/dev/null 2>&1; then apk add --no-cache ca-certificates curl; \\\n" +
+			"    elif command -v dnf >/dev/null 2>&1; then dnf install -y ca-certificates curl; \\\n" +
+			"    elif command -v microdnf >/dev/null 2>&1; then microdnf install -y ca-certificates curl; \\\n" +
+			"    elif command -v yum >/dev/null 2>&1; then yum install -y ca-certificates curl; \\\n" +
+			"    else echo \"could not find a supported package manager to install ca-certificates curl\" >&2 && exit 1; fi",
+		"RUN curl -fsSLo /tmp/helm.tar.gz https://get.helm.sh/helm-v3.12.0-linux-${TARGETARCH}.tar.gz && \\\n" +
+			"    tar -xzf /tmp/helm.tar.gz -C /tmp linux-${TARGETARCH}/helm && \\\n" +
+			"    mv /tmp/linux-${TARGETARCH}/helm /usr/local/bin/helm && \\\n" +
+			"    rm -rf /tmp/helm.tar.gz /tmp/linux-${TARGETARCH} && \\\n" +
+			"    chmod +x /usr/local/bin/helm",
+		"ENV PORTER_TOOL_HELM_VERSION=v3.12.0",
+	}
+	assert.Equal(t, want, lines)
+
+	lines, err = DockerfileLines([]Pin{{Name: "kubectl", Version: "v1.27.3", Checksums: map[string]string{
+		"arm64": "sha256:" + strings.Repeat("b", 64),
+		"amd64": "sha256:" + strings.Repeat("a", 64),
+	}}})
+	require.NoError(t, err)
+	require.Len(t, lines, 4)
+	wantInstall := "RUN curl -fsSLo /usr/local/bin/kubectl https://dl.k8s.io/release/v1.27.3/bin/linux/${TARGETARCH}/kubectl && \\\n" +
+		"    case \"${TARGETARCH}\" in amd64) CHECKSUM=" + strings.Repeat("a", 64) + " ;; arm64) CHECKSUM=" + strings.Repeat("b", 64) + " ;; " +
+		"*) echo \"tool kubectl does not have a checksum for ${TARGETARCH}\" >&2 && exit 1 ;; esac && \\\n" +
+		"    echo \"${CHECKSUM}  /usr/local/bin/kubectl\" | sha256sum -c - && \\\n" +
+		"    chmod +x /usr/local/bin/kubectl"
+	assert.Equal(t, wantInstall, lines[2], "the checksum for the architecture of the image should be checked")
+
+	_, err = DockerfileLines([]Pin{{Name: "jq", Version: "1.6"}})
+	require.ErrorContains(t, err, "tool jq is not supported")
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name    string
+		output  string
+		wantErr string
+	}{
+		{name: "matching version", output: "Client Version: v1.27.3\nKustomize Version: v5.0.1"},
+		{name: "different version", output: "Client Version: v1.26.0", wantErr: "tool kubectl is pinned to version 1.27.3 but the installed version reports: Client Version: v1.26.0"},
+		{name: "longer version", output: "Client Version: v1.27.30", wantErr: "tool kubectl is pinned to version 1.27.3"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := portercontext.NewTestContext(t)
+			c.Setenv(test.ExpectedCommandEnv, "kubectl version --client")
+			c.Setenv(test.ExpectedCommandOutputEnv, tc.output)
+
+			err := Verify(context.Background(), c.Context, []Pin{{Name: "kubectl", Version: "1.27.3"}})
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
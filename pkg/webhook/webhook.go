@@ -0,0 +1,48 @@
+// Package webhook sends json documents to webhooks, such as the notifications
+// about runs, change tickets, and audit events.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Send makes a single request to a webhook with a json body, and returns the
+// status code of the response. An error is returned when the request fails or
+// the response is not successful. The url is not included in the error, since
+// webhook urls often include a token, such as Slack incoming webhooks.
+func Send(ctx context.Context, client *http.Client, method string, webhookURL string, headers map[string]string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return 0, fmt.Errorf("error creating the webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return 0, fmt.Errorf("error calling the webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, fmt.Errorf("the webhook returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return resp.StatusCode, nil
+}
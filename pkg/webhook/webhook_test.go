@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSend(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		var gotBody string
+		var gotHeaders http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			gotBody = string(data)
+			gotHeaders = r.Header
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer server.Close()
+
+		status, err := Send(ctx, server.Client(), http.MethodPost, server.URL, map[string]string{"Authorization": "Bearer abc123"}, []byte(`{"status":"failed"}`))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, status)
+		assert.Equal(t, `{"status":"failed"}`, gotBody)
+		assert.Equal(t, "application/json", gotHeaders.Get("Content-Type"))
+		assert.Equal(t, "Bearer abc123", gotHeaders.Get("Authorization"))
+	})
+
+	t.Run("unsuccessful response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		status, err := Send(ctx, server.Client(), http.MethodPost, server.URL+"/services/T000/B000/topsecret", nil, nil)
+		require.EqualError(t, err, "the webhook returned 401 Unauthorized: unauthorized")
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("request failed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		webhookURL := server.URL + "/services/T000/B000/topsecret"
+		server.Close()
+
+		_, err := Send(ctx, http.DefaultClient, http.MethodPost, webhookURL, nil, nil)
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "topsecret", "the webhook url should not be included in the error")
+	})
+}
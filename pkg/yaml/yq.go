@@ -112,6 +112,23 @@ func (e *Editor) SetValue(path string, value string) error {
 	return nil
 }
 
+// SetNodeValue sets the value at the specified path to the yaml
+// representation of value, which may be a scalar, list or map.
+func (e *Editor) SetNodeValue(path string, value interface{}) error {
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return fmt.Errorf("could not encode the value for path %q: %w", path, err)
+	}
+
+	cmd := yqlib.UpdateCommand{Command: "update", Path: path, Value: &node, Overwrite: true}
+	err := e.yq.Update(e.node, cmd, true)
+	if err != nil {
+		return fmt.Errorf("could not update path %q: %w", path, err)
+	}
+
+	return nil
+}
+
 func (e *Editor) DeleteNode(path string) error {
 	cmd := yqlib.UpdateCommand{Command: "delete", Path: path}
 	err := e.yq.Update(e.node, cmd, true)